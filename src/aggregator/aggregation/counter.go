@@ -137,3 +137,38 @@ func (c *Counter) Annotation() []byte {
 
 // Close closes the counter.
 func (c *Counter) Close() {}
+
+// CounterState is the persistable state of a counter.
+type CounterState struct {
+	LastAt     time.Time
+	Annotation []byte
+	Sum        int64
+	SumSq      int64
+	Count      int64
+	Max        int64
+	Min        int64
+}
+
+// State returns the state of the counter.
+func (c *Counter) State() CounterState {
+	return CounterState{
+		LastAt:     c.lastAt,
+		Annotation: c.annotation,
+		Sum:        c.sum,
+		SumSq:      c.sumSq,
+		Count:      c.count,
+		Max:        c.max,
+		Min:        c.min,
+	}
+}
+
+// SetState restores the counter to the given state.
+func (c *Counter) SetState(state CounterState) {
+	c.lastAt = state.LastAt
+	c.annotation = state.Annotation
+	c.sum = state.Sum
+	c.sumSq = state.SumSq
+	c.count = state.Count
+	c.max = state.Max
+	c.min = state.Min
+}
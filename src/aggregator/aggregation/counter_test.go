@@ -85,3 +85,22 @@ func TestCounterCustomAggregationType(t *testing.T) {
 		}
 	}
 }
+
+func TestCounterStateRoundTrip(t *testing.T) {
+	opts := NewOptions(instrument.NewOptions())
+	opts.HasExpensiveAggregations = true
+	c := NewCounter(opts)
+	for i := 1; i <= 100; i++ {
+		c.Update(time.Unix(int64(i), 0), int64(i), []byte("foo"))
+	}
+
+	restored := NewCounter(opts)
+	restored.SetState(c.State())
+	require.Equal(t, c, restored)
+
+	for _, aggType := range []aggregation.Type{
+		aggregation.Sum, aggregation.SumSq, aggregation.Count, aggregation.Min, aggregation.Max,
+	} {
+		require.Equal(t, c.ValueOf(aggType), restored.ValueOf(aggType))
+	}
+}
@@ -171,3 +171,41 @@ func (g *Gauge) Annotation() []byte {
 
 // Close closes the gauge.
 func (g *Gauge) Close() {}
+
+// GaugeState is the persistable state of a gauge.
+type GaugeState struct {
+	LastAt     time.Time
+	Annotation []byte
+	Sum        float64
+	SumSq      float64
+	Count      int64
+	Max        float64
+	Min        float64
+	Last       float64
+}
+
+// State returns the state of the gauge.
+func (g *Gauge) State() GaugeState {
+	return GaugeState{
+		LastAt:     g.lastAt,
+		Annotation: g.annotation,
+		Sum:        g.sum,
+		SumSq:      g.sumSq,
+		Count:      g.count,
+		Max:        g.max,
+		Min:        g.min,
+		Last:       g.last,
+	}
+}
+
+// SetState restores the gauge to the given state.
+func (g *Gauge) SetState(state GaugeState) {
+	g.lastAt = state.LastAt
+	g.annotation = state.Annotation
+	g.sum = state.Sum
+	g.sumSq = state.SumSq
+	g.count = state.Count
+	g.max = state.Max
+	g.min = state.Min
+	g.last = state.Last
+}
@@ -191,3 +191,21 @@ func TestGaugeLastOutOfOrderValues(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, int64(2), counter.Value())
 }
+
+func TestGaugeStateRoundTrip(t *testing.T) {
+	opts := NewOptions(instrument.NewOptions())
+	opts.HasExpensiveAggregations = true
+	g := NewGauge(opts)
+	for i := 1.0; i <= 100.0; i++ {
+		g.Update(time.Unix(int64(i), 0), i, []byte("foo"))
+	}
+
+	restored := NewGauge(opts)
+	restored.SetState(g.State())
+	require.Equal(t, g, restored)
+	require.Equal(t, 100.0, restored.Last())
+
+	restored.Update(time.Unix(101, 0), 101.0, nil)
+	require.Equal(t, 101.0, restored.Last())
+	require.Equal(t, 101.0, restored.ValueOf(aggregation.Count))
+}
@@ -29,3 +29,10 @@ type Sample struct {
 	delta    int64   // delta between min rank and max rank
 	idx      int32
 }
+
+// SampleState is the persistable state of a sampled value.
+type SampleState struct {
+	Value    float64
+	NumRanks int64
+	Delta    int64
+}
@@ -206,6 +206,45 @@ func (s *Stream) Close() {
 	s.streamPool.Put(s)
 }
 
+// Samples flushes the stream and returns the state of its samples in order,
+// which can be used to restore the stream with RestoreSamples.
+func (s *Stream) Samples() []SampleState {
+	s.Flush()
+	res := make([]SampleState, 0, s.samples.Len())
+	for curr := s.samples.Front(); curr != nil; curr = curr.next {
+		res = append(res, SampleState{
+			Value:    curr.value,
+			NumRanks: curr.numRanks,
+			Delta:    curr.delta,
+		})
+	}
+	return res
+}
+
+// RestoreSamples resets the stream to the given sample states, which must be
+// sorted by value as returned by Samples.
+func (s *Stream) RestoreSamples(samples []SampleState) {
+	s.bufMore.Reset()
+	s.bufLess.Reset()
+	s.samples.Reset()
+	s.insertCursor = nil
+	s.compressCursor = nil
+	s.insertAndCompressCounter = 0
+	s.numValues = 0
+	s.compressMinRank = 0
+
+	for _, state := range samples {
+		sample := s.samples.Acquire()
+		sample.value = state.Value
+		sample.numRanks = state.NumRanks
+		sample.delta = state.Delta
+		s.samples.PushBack(sample)
+		s.numValues += state.NumRanks
+	}
+	s.insertCursor = s.samples.Front()
+	s.flushed = false
+}
+
 // quantilesFromBuf calculates quantiles from buffer if there were too few samples to compress
 func (s *Stream) quantilesFromBuf() {
 	var (
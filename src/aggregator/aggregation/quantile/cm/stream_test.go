@@ -153,6 +153,37 @@ func TestStreamClose(t *testing.T) {
 	require.True(t, s.closed)
 }
 
+func TestStreamRestoreSamples(t *testing.T) {
+	opts := testStreamOptions()
+	s := NewStream(opts)
+	s.ResetSetData(testQuantiles)
+	for i := 0; i < 1000; i++ {
+		s.Add(float64(i))
+	}
+	samples := s.Samples()
+	require.True(t, len(samples) > 0)
+
+	restored := NewStream(opts)
+	restored.ResetSetData(testQuantiles)
+	restored.RestoreSamples(samples)
+	restored.Flush()
+
+	require.Equal(t, s.Min(), restored.Min())
+	require.Equal(t, s.Max(), restored.Max())
+	for _, q := range testQuantiles {
+		require.Equal(t, s.Quantile(q), restored.Quantile(q))
+	}
+
+	// Values added after restoring are merged with the restored samples.
+	for i := 1000; i < 2000; i++ {
+		restored.Add(float64(i))
+	}
+	restored.Flush()
+	require.Equal(t, 0.0, restored.Min())
+	require.Equal(t, 1999.0, restored.Max())
+	require.InDelta(t, 1000.0, restored.Quantile(0.5), 40.0)
+}
+
 func testStreamWithIncreasingSamples(t *testing.T, opts Options) {
 	numSamples := 100000
 	s := NewStream(opts)
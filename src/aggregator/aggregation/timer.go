@@ -161,3 +161,35 @@ func (t *Timer) Annotation() []byte {
 func (t *Timer) Close() {
 	t.stream.Close()
 }
+
+// TimerState is the persistable state of a timer.
+type TimerState struct {
+	LastAt     time.Time
+	Annotation []byte
+	Count      int64
+	Sum        float64
+	SumSq      float64
+	Samples    []cm.SampleState
+}
+
+// State returns the state of the timer.
+func (t *Timer) State() TimerState {
+	return TimerState{
+		LastAt:     t.lastAt,
+		Annotation: t.annotation,
+		Count:      t.count,
+		Sum:        t.sum,
+		SumSq:      t.sumSq,
+		Samples:    t.stream.Samples(),
+	}
+}
+
+// SetState restores the timer to the given state.
+func (t *Timer) SetState(state TimerState) {
+	t.lastAt = state.LastAt
+	t.annotation = state.Annotation
+	t.count = state.Count
+	t.sum = state.Sum
+	t.sumSq = state.SumSq
+	t.stream.RestoreSamples(state.Samples)
+}
@@ -220,3 +220,22 @@ func TestTimerReturnsLastNonEmptyAnnotation(t *testing.T) {
 
 	require.Equal(t, []byte("second"), timer.Annotation())
 }
+
+func TestTimerStateRoundTrip(t *testing.T) {
+	opts := NewOptions(instrument.NewOptions())
+	opts.HasExpensiveAggregations = true
+	timer := NewTimer(testQuantiles, testStreamOptions(), opts)
+	samples, _ := getTimerSamples(1000, nil, testQuantiles)
+	timer.AddBatch(time.Unix(1, 0), samples, []byte("foo"))
+
+	restored := NewTimer(testQuantiles, testStreamOptions(), opts)
+	restored.SetState(timer.State())
+
+	require.Equal(t, timer.LastAt(), restored.LastAt())
+	require.Equal(t, timer.Annotation(), restored.Annotation())
+	for _, aggType := range testAggTypes {
+		require.Equal(t, timer.ValueOf(aggType), restored.ValueOf(aggType))
+	}
+	timer.Close()
+	restored.Close()
+}
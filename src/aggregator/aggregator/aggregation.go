@@ -22,12 +22,23 @@ package aggregator
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/m3db/m3/src/aggregator/aggregation"
 	"github.com/m3db/m3/src/metrics/metric/unaggregated"
 )
 
+var errNoAggregationState = errors.New("aggregation state is missing")
+
+// aggregationState is the persistable state of a counter, timer or gauge
+// aggregation, exactly one of which is set.
+type aggregationState struct {
+	counter *aggregation.CounterState
+	timer   *aggregation.TimerState
+	gauge   *aggregation.GaugeState
+}
+
 // counterAggregation is a counter aggregation.
 type counterAggregation struct {
 	aggregation.Counter
@@ -49,6 +60,19 @@ func (a *counterAggregation) AddUnion(t time.Time, mu unaggregated.MetricUnion)
 	a.Counter.Update(t, mu.CounterVal, mu.Annotation)
 }
 
+func (a *counterAggregation) SnapshotState() aggregationState {
+	state := a.Counter.State()
+	return aggregationState{counter: &state}
+}
+
+func (a *counterAggregation) RestoreState(state aggregationState) error {
+	if state.counter == nil {
+		return fmt.Errorf("unable to restore counter: %w", errNoAggregationState)
+	}
+	a.Counter.SetState(*state.counter)
+	return nil
+}
+
 // timerAggregation is a timer aggregation.
 type timerAggregation struct {
 	aggregation.Timer
@@ -70,6 +94,19 @@ func (a *timerAggregation) AddUnion(timestamp time.Time, mu unaggregated.MetricU
 	a.Timer.AddBatch(timestamp, mu.BatchTimerVal, mu.Annotation)
 }
 
+func (a *timerAggregation) SnapshotState() aggregationState {
+	state := a.Timer.State()
+	return aggregationState{timer: &state}
+}
+
+func (a *timerAggregation) RestoreState(state aggregationState) error {
+	if state.timer == nil {
+		return fmt.Errorf("unable to restore timer: %w", errNoAggregationState)
+	}
+	a.Timer.SetState(*state.timer)
+	return nil
+}
+
 // gaugeAggregation is a gauge aggregation.
 type gaugeAggregation struct {
 	aggregation.Gauge
@@ -91,3 +128,16 @@ func (a *gaugeAggregation) UpdateVal(t time.Time, value float64, prevValue float
 func (a *gaugeAggregation) AddUnion(t time.Time, mu unaggregated.MetricUnion) {
 	a.Gauge.Update(t, mu.GaugeVal, mu.Annotation)
 }
+
+func (a *gaugeAggregation) SnapshotState() aggregationState {
+	state := a.Gauge.State()
+	return aggregationState{gauge: &state}
+}
+
+func (a *gaugeAggregation) RestoreState(state aggregationState) error {
+	if state.gauge == nil {
+		return fmt.Errorf("unable to restore gauge: %w", errNoAggregationState)
+	}
+	a.Gauge.SetState(*state.gauge)
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"context"
 	"errors"
 	"math"
+	"os"
 	"strconv"
 	"sync"
 	"time"
@@ -111,6 +112,7 @@ type aggregator struct {
 	passthroughWriter writer.Writer
 	adminClient       client.AdminClient
 	resignTimeout     time.Duration
+	snapshotOpts      SnapshotOptions
 
 	shardSetID         uint32
 	shardSetOpen       bool
@@ -121,6 +123,7 @@ type aggregator struct {
 	state              aggregatorState
 	sleepFn            sleepFn
 	shardsPendingClose atomic.Int32
+	lastSnapshotAt     atomic.Int64
 	metrics            aggregatorMetrics
 	logger             *zap.Logger
 }
@@ -146,6 +149,7 @@ func NewAggregator(opts Options) Aggregator {
 		passthroughWriter: opts.PassthroughWriter(),
 		adminClient:       opts.AdminClient(),
 		resignTimeout:     opts.ResignTimeout(),
+		snapshotOpts:      opts.SnapshotOptions(),
 		sleepFn:           time.Sleep,
 		metrics:           newAggregatorMetrics(scope, timerOpts, opts.MaxAllowedForwardingDelayFn()),
 		logger:            logger,
@@ -161,6 +165,14 @@ func (agg *aggregator) Open() error {
 	if agg.state != aggregatorNotOpen {
 		return errAggregatorAlreadyOpenOrClosed
 	}
+	if agg.snapshotOpts.Enabled() {
+		if err := agg.snapshotOpts.Validate(); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(agg.snapshotOpts.Directory(), 0o755); err != nil {
+			return err
+		}
+	}
 	if err := agg.placementManager.Open(); err != nil {
 		return err
 	}
@@ -184,6 +196,14 @@ func (agg *aggregator) Open() error {
 	// closed, it's fine to ignore the result of the placement update, as applying
 	// the change only affects the current aggregator that is being closed anyway.
 	go agg.placementTick()
+
+	if agg.snapshotOpts.Enabled() {
+		// NB: snapshot tick periodically persists the aggregation state of
+		// the owned shards so it can be restored if the aggregator restarts
+		// before the aggregated data are flushed.
+		agg.lastSnapshotAt.Store(agg.nowFn().UnixNano())
+		go agg.snapshotTick()
+	}
 	agg.state = aggregatorOpen
 	return nil
 }
@@ -391,7 +411,14 @@ func (agg *aggregator) Close() error {
 	// currently running flush completes, and updates the shared shard flush
 	// times map in etcd, allowing the follower that will be promoted to leader
 	// to avoid re-computing and re-flushing this data.
-	return agg.flushManager.Close()
+	err := agg.flushManager.Close()
+
+	// NB: take a final snapshot so data that have not been flushed yet are not
+	// lost if the aggregator is restarted.
+	if agg.snapshotOpts.Enabled() {
+		agg.snapshotShards(agg.shardsWithLock())
+	}
+	return err
 }
 
 func (agg *aggregator) shardFor(id id.RawID) (*aggregatorShard, error) {
@@ -608,6 +635,11 @@ func (agg *aggregator) updateShardsWithLock(
 		} else {
 			incoming[shardID] = newAggregatorShard(shardID, agg.opts)
 			agg.metrics.shards.add.Inc(1)
+			if agg.state == aggregatorNotOpen && agg.snapshotOpts.Enabled() {
+				// Only restore snapshots on startup, as snapshots of shards
+				// acquired later were taken before another instance owned them.
+				agg.restoreShard(incoming[shardID])
+			}
 		}
 
 		incoming[shardID].SetRedirectToShardID(shard.RedirectToShardID())
@@ -737,6 +769,99 @@ func (agg *aggregator) tickInternal() {
 	}
 }
 
+// shardsWithLock returns the shards currently owned by the aggregator.
+func (agg *aggregator) shardsWithLock() []*aggregatorShard {
+	shards := make([]*aggregatorShard, 0, len(agg.shardIDs))
+	for _, shardID := range agg.shardIDs {
+		if shard := agg.shards[shardID]; shard != nil {
+			shards = append(shards, shard)
+		}
+	}
+	return shards
+}
+
+func (agg *aggregator) snapshotTick() {
+	ticker := time.NewTicker(agg.snapshotOpts.Interval())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		agg.RLock()
+		if agg.state != aggregatorOpen {
+			agg.RUnlock()
+			return
+		}
+		shards := agg.shardsWithLock()
+		agg.RUnlock()
+
+		agg.snapshotShards(shards)
+	}
+}
+
+func (agg *aggregator) snapshotShards(shards []*aggregatorShard) {
+	var (
+		m          = agg.metrics.snapshot
+		dir        = agg.snapshotOpts.Directory()
+		start      = agg.nowFn()
+		numBytes   int64
+		numEntries int
+		numErrors  int
+	)
+	for _, shard := range shards {
+		res, err := shard.Snapshot(dir)
+		if err == errAggregatorShardClosed {
+			continue
+		}
+		if err != nil {
+			numErrors++
+			m.errors.Inc(1)
+			agg.logger.Error("could not snapshot shard",
+				zap.Uint32("shard", shard.ID()), zap.Error(err))
+			continue
+		}
+		numBytes += res.numBytes
+		numEntries += res.numEntries
+	}
+
+	now := agg.nowFn()
+	m.duration.Record(now.Sub(start))
+	m.size.Update(float64(numBytes))
+	m.entries.Update(float64(numEntries))
+	if numErrors == 0 {
+		m.success.Inc(1)
+		agg.lastSnapshotAt.Store(start.UnixNano())
+	}
+	m.age.Update(now.Sub(time.Unix(0, agg.lastSnapshotAt.Load())).Seconds())
+}
+
+func (agg *aggregator) restoreShard(shard *aggregatorShard) {
+	m := agg.metrics.snapshot
+	res, restored, err := shard.Restore(agg.snapshotOpts.Directory(), agg.snapshotOpts.MaxAge())
+	if err != nil {
+		// NB: a snapshot that fails to restore midway leaves the entries
+		// restored so far in place, which is no worse than not restoring.
+		m.restoreErrors.Inc(1)
+		agg.logger.Error("could not restore shard snapshot",
+			zap.Uint32("shard", shard.ID()), zap.Error(err))
+	}
+	age := agg.nowFn().Sub(res.snapshotAt)
+	if !restored {
+		if err == nil && !res.snapshotAt.IsZero() {
+			m.restoreStale.Inc(1)
+			agg.logger.Info("skipped restoring stale shard snapshot",
+				zap.Uint32("shard", shard.ID()), zap.Duration("age", age))
+		}
+		return
+	}
+	m.restored.Inc(1)
+	m.restoredEntries.Inc(int64(res.numEntries))
+	m.restoredAge.Record(age)
+	agg.logger.Info("restored shard snapshot",
+		zap.Uint32("shard", shard.ID()),
+		zap.Int("entries", res.numEntries),
+		zap.Int64("bytes", res.numBytes),
+		zap.Duration("age", age))
+}
+
 type aggregatorAddMetricSuccessMetrics struct {
 	success        tally.Counter
 	successLatency tally.Timer
@@ -1159,6 +1284,36 @@ func newAggregatorShardSetIDMetrics(scope tally.Scope) aggregatorShardSetIDMetri
 	}
 }
 
+type aggregatorSnapshotMetrics struct {
+	success         tally.Counter
+	errors          tally.Counter
+	duration        tally.Timer
+	age             tally.Gauge
+	size            tally.Gauge
+	entries         tally.Gauge
+	restored        tally.Counter
+	restoredEntries tally.Counter
+	restoredAge     tally.Timer
+	restoreStale    tally.Counter
+	restoreErrors   tally.Counter
+}
+
+func newAggregatorSnapshotMetrics(scope tally.Scope) aggregatorSnapshotMetrics {
+	return aggregatorSnapshotMetrics{
+		success:         scope.Counter("success"),
+		errors:          scope.Counter("errors"),
+		duration:        scope.Timer("duration"),
+		age:             scope.Gauge("age-seconds"),
+		size:            scope.Gauge("size-bytes"),
+		entries:         scope.Gauge("entries"),
+		restored:        scope.Counter("restored"),
+		restoredEntries: scope.Counter("restored-entries"),
+		restoredAge:     scope.Timer("restored-age"),
+		restoreStale:    scope.Counter("restore-stale"),
+		restoreErrors:   scope.Counter("restore-errors"),
+	}
+}
+
 type aggregatorMetrics struct {
 	counters       tally.Counter
 	timers         tally.Counter
@@ -1176,6 +1331,7 @@ type aggregatorMetrics struct {
 	shards         aggregatorShardsMetrics
 	shardSetID     aggregatorShardSetIDMetrics
 	tick           aggregatorTickMetrics
+	snapshot       aggregatorSnapshotMetrics
 }
 
 func newAggregatorMetrics(
@@ -1191,6 +1347,7 @@ func newAggregatorMetrics(
 	shardsScope := scope.SubScope("shards")
 	shardSetIDScope := scope.SubScope("shard-set-id")
 	tickScope := scope.SubScope("tick")
+	snapshotScope := scope.SubScope("snapshot")
 	return aggregatorMetrics{
 		counters:       scope.Counter("counters"),
 		timers:         scope.Counter("timers"),
@@ -1208,6 +1365,7 @@ func newAggregatorMetrics(
 		shards:         newAggregatorShardsMetrics(shardsScope),
 		shardSetID:     newAggregatorShardSetIDMetrics(shardSetIDScope),
 		tick:           newAggregatorTickMetrics(tickScope),
+		snapshot:       newAggregatorSnapshotMetrics(snapshotScope),
 	}
 }
 
@@ -64,6 +64,7 @@ const (
 	listTypeLabel                             = "list-type"
 	resolutionLabel                           = "resolution"
 	flushTypeLabel                            = "flush-type"
+	lateArrivalPolicyLabel                    = "late-arrival-policy"
 )
 
 var (
@@ -153,6 +154,13 @@ type metricElem interface {
 	// will be deleted once its aggregated values have been flushed.
 	MarkAsTombstoned()
 
+	// snapshot returns the state of the aggregation windows that have not
+	// been consumed yet.
+	snapshot() (elemSnapshot, error)
+
+	// restore restores previously snapshotted aggregation windows.
+	restore(windows []aggregationSnapshot) error
+
 	// Close closes the element.
 	Close()
 }
@@ -186,6 +194,8 @@ type elemBase struct {
 	onForwardedAggregationWrittenFn onForwardedAggregationDoneFn
 	metrics                         *elemMetrics
 	bufferForPastTimedMetricFn      BufferForPastTimedMetricFn
+	lateArrivalFn                   LateArrivalFn
+	lateArrival                     LateArrival
 	listType                        metricListType
 
 	// Mutable states.
@@ -256,13 +266,21 @@ func (f *flushState) close() {
 type writeMetrics struct {
 	writes        tally.Counter
 	updatedValues tally.Counter
+	// count of values arriving for already flushed aggregations, by late arrival policy.
+	lateArrivals [int(numLateArrivalPolicies)]tally.Counter
 }
 
 func newWriteMetrics(scope tally.Scope) writeMetrics {
-	return writeMetrics{
+	m := writeMetrics{
 		updatedValues: scope.Counter("updated-values"),
 		writes:        scope.Counter("writes"),
 	}
+	for i := 0; i < int(numLateArrivalPolicies); i++ {
+		m.lateArrivals[i] = scope.
+			Tagged(map[string]string{lateArrivalPolicyLabel: LateArrivalPolicy(i).String()}).
+			Counter("late-arrivals")
+	}
+	return m
 }
 
 // flushMetrics are the metrics produced by a flush task processing the metric element.
@@ -428,6 +446,7 @@ func newElemBase(opts ElemOptions) elemBase {
 		aggOpts:                    opts.aggregationOpts,
 		metrics:                    opts.elemMetrics,
 		bufferForPastTimedMetricFn: opts.aggregatorOpts.BufferForPastTimedMetricFn(),
+		lateArrivalFn:              opts.aggregatorOpts.LateArrivalFn(),
 		flushMetricsCache:          make(map[flushKey]*flushMetrics),
 	}
 }
@@ -457,6 +476,7 @@ func (e *elemBase) resetSetData(data ElemData, useDefaultAggregation bool) error
 	}
 	e.id = data.ID
 	e.sp = data.StoragePolicy
+	e.lateArrival = e.lateArrivalFn(data.StoragePolicy)
 	e.aggTypes = data.AggTypes
 	e.useDefaultAggregation = useDefaultAggregation
 	e.aggOpts.ResetSetData(data.AggTypes)
@@ -499,6 +519,12 @@ func (e *elemBase) ForwardedAggregationKey() (aggregationKey, bool) {
 	}, true
 }
 
+// recordLateArrival records a value arriving for an already flushed aggregation
+// that has not been closed yet.
+func (e *elemBase) recordLateArrival() {
+	e.writeMetrics.lateArrivals[e.lateArrival.Policy].Inc(1)
+}
+
 // MarkAsTombstoned marks an element as tombstoned, which means this element
 // will be deleted once its aggregated values have been flushed.
 func (e *elemBase) MarkAsTombstoned() {
@@ -21,13 +21,16 @@
 package aggregator
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/uber-go/tally"
+	"go.uber.org/zap"
 
 	"github.com/m3db/m3/src/x/clock"
 )
@@ -117,7 +120,10 @@ type flushManager struct {
 	checkEvery    time.Duration
 	jitterEnabled bool
 	maxJitterFn   FlushJitterFn
+	flushOffsets  map[time.Duration]FlushOffset
 	electionMgr   ElectionManager
+	placementMgr  PlacementManager
+	logger        *zap.Logger
 	leaderOpts    FlushManagerOptions
 	followerOpts  FlushManagerOptions
 
@@ -151,12 +157,20 @@ func NewFlushManager(opts FlushManagerOptions) FlushManager {
 	followerMgrInstrumentOpts := instrumentOpts.SetMetricsScope(followerMgrScope)
 	followerOpts := opts.SetInstrumentOptions(followerMgrInstrumentOpts)
 
+	flushOffsets := make(map[time.Duration]FlushOffset, len(opts.FlushOffsets()))
+	for _, offset := range opts.FlushOffsets() {
+		flushOffsets[offset.FlushInterval] = offset
+	}
+
 	mgr := &flushManager{
 		scope:         scope,
 		checkEvery:    opts.CheckEvery(),
 		jitterEnabled: opts.JitterEnabled(),
 		maxJitterFn:   opts.MaxJitterFn(),
+		flushOffsets:  flushOffsets,
 		electionMgr:   opts.ElectionManager(),
+		placementMgr:  opts.PlacementManager(),
+		logger:        instrumentOpts.Logger(),
 		leaderOpts:    leaderOpts,
 		followerOpts:  followerOpts,
 		rand:          rand,
@@ -293,6 +307,10 @@ func (mgr *flushManager) findOrCreateBucketWithLock(l flushingMetricList) (*flus
 }
 
 func (mgr *flushManager) computeFlushIntervalOffset(flushInterval time.Duration) time.Duration {
+	if flushOffset, ok := mgr.flushOffsets[flushInterval]; ok {
+		return flushOffset.Offset + mgr.shardSetJitter(flushInterval, flushOffset.MaxJitter)
+	}
+
 	if !mgr.jitterEnabled {
 		// If jittering is disabled, we compute the offset between the current time
 		// and the aligned time and use that as the bucket offset.
@@ -308,6 +326,33 @@ func (mgr *flushManager) computeFlushIntervalOffset(flushInterval time.Duration)
 	return time.Duration(jitterNanos)
 }
 
+// shardSetJitter returns a jitter in [0, maxJitter) that is stable for the shard
+// set owned by this instance and the flush interval so that the leader and the
+// follower of a shard set flush the same data at the same time.
+func (mgr *flushManager) shardSetJitter(flushInterval, maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+
+	var shardSetID uint32
+	if mgr.placementMgr != nil {
+		instance, err := mgr.placementMgr.Instance()
+		if err == nil {
+			shardSetID = instance.ShardSetID()
+		} else {
+			mgr.logger.Warn("unable to determine shard set for flush offset, using default shard set",
+				zap.Duration("flushInterval", flushInterval), zap.Error(err))
+		}
+	}
+
+	var buf [12]byte
+	binary.LittleEndian.PutUint32(buf[:4], shardSetID)
+	binary.LittleEndian.PutUint64(buf[4:], uint64(flushInterval))
+	h := fnv.New64a()
+	_, _ = h.Write(buf[:])
+	return time.Duration(h.Sum64() % uint64(maxJitter))
+}
+
 // NB(xichen): apparently timer.Reset() is more difficult to use than I originally
 // anticipated. For now I'm simply waking up every second to check for updates. Maybe
 // when I have more time I'll spend a few hours to get timer.Reset() right and switch
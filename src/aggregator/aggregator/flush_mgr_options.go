@@ -35,6 +35,8 @@ const (
 	defaultJitterEnabled         = true
 	defaultMaxBufferSize         = 5 * time.Minute
 	defaultForcedFlushWindowSize = 10 * time.Second
+
+	defaultStaleFlushTimesRetention = 24 * time.Hour
 )
 
 var defaultWorkerPoolSize = int(math.Max(float64(runtime.GOMAXPROCS(0)/8), 1.0))
@@ -42,6 +44,18 @@ var defaultWorkerPoolSize = int(math.Max(float64(runtime.GOMAXPROCS(0)/8), 1.0))
 // FlushJitterFn determines the jitter based on the flush interval.
 type FlushJitterFn func(flushInterval time.Duration) time.Duration
 
+// FlushOffset configures the flush offset of standard metric lists with a
+// given flush interval, i.e. a storage policy resolution. The offset of such
+// lists is Offset plus a jitter in [0, MaxJitter) derived from the shard set
+// of the instance and the flush interval, so that the leader and follower of a
+// shard set, and restarted instances, flush the same data at the same offset
+// while different shard sets spread their flushes over the interval.
+type FlushOffset struct {
+	FlushInterval time.Duration
+	Offset        time.Duration
+	MaxJitter     time.Duration
+}
+
 // FlushManagerOptions provide a set of options for the flush manager.
 type FlushManagerOptions interface {
 	// SetClockOptions sets the clock options.
@@ -74,6 +88,13 @@ type FlushManagerOptions interface {
 	// MaxJitterFn returns the max flush jittering function.
 	MaxJitterFn() FlushJitterFn
 
+	// SetFlushOffsets sets the flush offsets for specific flush intervals, which
+	// take precedence over jittering for lists with those flush intervals.
+	SetFlushOffsets(value []FlushOffset) FlushManagerOptions
+
+	// FlushOffsets returns the flush offsets for specific flush intervals.
+	FlushOffsets() []FlushOffset
+
 	// SetWorkerPool sets the worker pool.
 	SetWorkerPool(value sync.WorkerPool) FlushManagerOptions
 
@@ -119,6 +140,17 @@ type FlushManagerOptions interface {
 
 	// BufferForPastTimedMetric returns the size of the buffer for timed metrics in the past.
 	BufferForPastTimedMetric() time.Duration
+
+	// SetStaleFlushTimesRetention sets how long the flush times of shards no longer
+	// owned and of flushers that no longer exist are retained after they were last
+	// flushed before they are pruned from the persisted flush times, zero disables
+	// pruning.
+	SetStaleFlushTimesRetention(value time.Duration) FlushManagerOptions
+
+	// StaleFlushTimesRetention returns how long the flush times of shards no longer
+	// owned and of flushers that no longer exist are retained after they were last
+	// flushed before they are pruned from the persisted flush times.
+	StaleFlushTimesRetention() time.Duration
 }
 
 type flushManagerOptions struct {
@@ -127,6 +159,7 @@ type flushManagerOptions struct {
 	checkEvery            time.Duration
 	jitterEnabled         bool
 	maxJitterFn           FlushJitterFn
+	flushOffsets          []FlushOffset
 	workerPool            sync.WorkerPool
 	placementManager      PlacementManager
 	electionManager       ElectionManager
@@ -135,6 +168,7 @@ type flushManagerOptions struct {
 	forcedFlushWindowSize time.Duration
 
 	bufferForPastTimedMetric time.Duration
+	staleFlushTimesRetention time.Duration
 }
 
 // NewFlushManagerOptions create a new set of flush manager options.
@@ -151,6 +185,7 @@ func NewFlushManagerOptions() FlushManagerOptions {
 		forcedFlushWindowSize: defaultForcedFlushWindowSize,
 
 		bufferForPastTimedMetric: defaultTimedMetricBuffer,
+		staleFlushTimesRetention: defaultStaleFlushTimesRetention,
 	}
 }
 
@@ -204,6 +239,16 @@ func (o *flushManagerOptions) MaxJitterFn() FlushJitterFn {
 	return o.maxJitterFn
 }
 
+func (o *flushManagerOptions) SetFlushOffsets(value []FlushOffset) FlushManagerOptions {
+	opts := *o
+	opts.flushOffsets = value
+	return &opts
+}
+
+func (o *flushManagerOptions) FlushOffsets() []FlushOffset {
+	return o.flushOffsets
+}
+
 func (o *flushManagerOptions) SetWorkerPool(value sync.WorkerPool) FlushManagerOptions {
 	opts := *o
 	opts.workerPool = value
@@ -273,3 +318,13 @@ func (o *flushManagerOptions) SetBufferForPastTimedMetric(value time.Duration) F
 func (o *flushManagerOptions) BufferForPastTimedMetric() time.Duration {
 	return o.bufferForPastTimedMetric
 }
+
+func (o *flushManagerOptions) SetStaleFlushTimesRetention(value time.Duration) FlushManagerOptions {
+	opts := *o
+	opts.staleFlushTimesRetention = value
+	return &opts
+}
+
+func (o *flushManagerOptions) StaleFlushTimesRetention() time.Duration {
+	return o.staleFlushTimesRetention
+}
@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/placement"
 	"github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/watch"
 
@@ -651,6 +652,51 @@ func TestFlushManagerComputeFlushIntervalOffsetJitterDisabled(t *testing.T) {
 	}
 }
 
+func TestFlushManagerComputeFlushIntervalOffsetFlushOffsets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newMgr := func(shardSetID uint32) *flushManager {
+		placementMgr := NewMockPlacementManager(ctrl)
+		placementMgr.EXPECT().Instance().
+			Return(placement.NewInstance().SetShardSetID(shardSetID), nil).
+			AnyTimes()
+		opts := NewFlushManagerOptions().
+			SetJitterEnabled(true).
+			SetMaxJitterFn(func(interval time.Duration) time.Duration { return interval }).
+			SetPlacementManager(placementMgr).
+			SetFlushOffsets([]FlushOffset{
+				{FlushInterval: 10 * time.Second, Offset: 2 * time.Second, MaxJitter: 3 * time.Second},
+				{FlushInterval: time.Minute, Offset: 5 * time.Second},
+			})
+		mgr := NewFlushManager(opts).(*flushManager)
+		mgr.randFn = func(n int64) int64 { return n / 2 }
+		return mgr
+	}
+
+	mgr := newMgr(1)
+	offset := mgr.computeFlushIntervalOffset(10 * time.Second)
+	require.True(t, offset >= 2*time.Second)
+	require.True(t, offset < 5*time.Second)
+	require.Equal(t, 5*time.Second, mgr.computeFlushIntervalOffset(time.Minute))
+
+	// Intervals without a flush offset use jittering.
+	require.Equal(t, 5*time.Minute, mgr.computeFlushIntervalOffset(10*time.Minute))
+
+	// The offset is stable for a shard set, e.g. across the leader and follower.
+	require.Equal(t, offset, newMgr(1).computeFlushIntervalOffset(10*time.Second))
+
+	// Different shard sets spread their flushes within the max jitter.
+	offsets := make(map[time.Duration]struct{})
+	for i := uint32(0); i < 16; i++ {
+		offset := newMgr(i).computeFlushIntervalOffset(10 * time.Second)
+		require.True(t, offset >= 2*time.Second)
+		require.True(t, offset < 5*time.Second)
+		offsets[offset] = struct{}{}
+	}
+	require.True(t, len(offsets) > 1)
+}
+
 func testFlushManager(t *testing.T, ctrl *gomock.Controller) (*flushManager, *time.Time) {
 	opts, now := testFlushManagerOptions(t, ctrl)
 	return NewFlushManager(opts).(*flushManager), now
@@ -23,6 +23,8 @@ package aggregator
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 
 	schema "github.com/m3db/m3/src/aggregator/generated/proto/flush"
@@ -93,6 +95,9 @@ type flushTimesManagerMetrics struct {
 	flushTimesUnmarshalErrors tally.Counter
 	flushTimesPersistSync     instrument.MethodMetrics
 	flushTimesPersistAsync    instrument.MethodMetrics
+	cacheLoaded               tally.Counter
+	cacheLoadErrors           tally.Counter
+	cacheWriteErrors          tally.Counter
 }
 
 func newFlushTimesManagerMetrics(
@@ -108,6 +113,9 @@ func newFlushTimesManagerMetrics(
 		flushTimesUnmarshalErrors: scope.Counter("flush-times-unmarshal-errors"),
 		flushTimesPersistAsync:    buildMethodMetrics("async"),
 		flushTimesPersistSync:     buildMethodMetrics("sync"),
+		cacheLoaded:               scope.Counter("flush-times-cache-loaded"),
+		cacheLoadErrors:           scope.Counter("flush-times-cache-load-errors"),
+		cacheWriteErrors:          scope.Counter("flush-times-cache-write-errors"),
 	}
 }
 
@@ -120,10 +128,12 @@ type flushTimesManager struct {
 	flushTimesKeyFmt         string
 	flushTimesStore          kv.Store
 	flushTimesPersistRetrier retry.Retrier
+	flushTimesCacheDir       string
 
 	state               flushTimesManagerState
 	doneCh              chan struct{}
 	flushTimesKey       string
+	cacheFilePath       string
 	proto               *schema.ShardSetFlushTimes
 	flushTimesWatchable watch.Watchable
 	persistWatchable    watch.Watchable
@@ -139,6 +149,7 @@ func NewFlushTimesManager(opts FlushTimesManagerOptions) FlushTimesManager {
 		flushTimesKeyFmt:         opts.FlushTimesKeyFmt(),
 		flushTimesStore:          opts.FlushTimesStore(),
 		flushTimesPersistRetrier: opts.FlushTimesPersistRetrier(),
+		flushTimesCacheDir:       opts.FlushTimesCacheDir(),
 		metrics: newFlushTimesManagerMetrics(instrumentOpts.MetricsScope(),
 			instrumentOpts.TimerOptions()),
 	}
@@ -171,6 +182,10 @@ func (mgr *flushTimesManager) Open(shardSetID uint32) error {
 		return errFlushTimesManagerAlreadyOpenOrClosed
 	}
 	mgr.flushTimesKey = fmt.Sprintf(mgr.flushTimesKeyFmt, shardSetID)
+	if mgr.flushTimesCacheDir != "" {
+		mgr.cacheFilePath = flushTimesCacheFilePath(mgr.flushTimesCacheDir, shardSetID)
+		mgr.loadCacheWithLock()
+	}
 	flushTimesWatch, err := mgr.flushTimesStore.Watch(mgr.flushTimesKey)
 	if err != nil {
 		return err
@@ -270,6 +285,7 @@ func (mgr *flushTimesManager) resetWithLock() {
 	mgr.state = flushTimesManagerNotOpen
 	mgr.doneCh = make(chan struct{})
 	mgr.flushTimesKey = ""
+	mgr.cacheFilePath = ""
 	mgr.proto = nil
 	mgr.flushTimesWatchable = watch.NewWatchable()
 	mgr.persistWatchable = watch.NewWatchable()
@@ -301,6 +317,62 @@ func (mgr *flushTimesManager) watchFlushTimes(flushTimesWatch kv.ValueWatch) {
 		mgr.proto = &proto
 		mgr.Unlock()
 		mgr.flushTimesWatchable.Update(&proto)
+		mgr.writeCache(&proto)
+	}
+}
+
+// flushTimesCacheFilePath returns the path of the flush times cache file of
+// a shard set.
+func flushTimesCacheFilePath(dir string, shardSetID uint32) string {
+	return filepath.Join(dir, fmt.Sprintf("flush-times-%d.pb", shardSetID))
+}
+
+// loadCacheWithLock loads the cached flush times so they are available before
+// they are read from kv, which replaces them once read.
+func (mgr *flushTimesManager) loadCacheWithLock() {
+	data, err := os.ReadFile(mgr.cacheFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	var proto schema.ShardSetFlushTimes
+	if err == nil {
+		err = proto.Unmarshal(data)
+	}
+	if err != nil {
+		mgr.metrics.cacheLoadErrors.Inc(1)
+		mgr.logger.Error("flush times cache load error",
+			zap.String("cacheFilePath", mgr.cacheFilePath),
+			zap.Error(err),
+		)
+		return
+	}
+
+	mgr.proto = &proto
+	mgr.flushTimesWatchable.Update(&proto)
+	mgr.metrics.cacheLoaded.Inc(1)
+}
+
+// writeCache replaces the cached flush times atomically.
+func (mgr *flushTimesManager) writeCache(proto *schema.ShardSetFlushTimes) {
+	if mgr.cacheFilePath == "" {
+		return
+	}
+
+	tmpPath := mgr.cacheFilePath + ".tmp"
+	data, err := proto.Marshal()
+	if err == nil {
+		err = os.WriteFile(tmpPath, data, 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, mgr.cacheFilePath)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		mgr.metrics.cacheWriteErrors.Inc(1)
+		mgr.logger.Error("flush times cache write error",
+			zap.String("cacheFilePath", mgr.cacheFilePath),
+			zap.Error(err),
+		)
 	}
 }
 
@@ -62,6 +62,14 @@ type FlushTimesManagerOptions interface {
 
 	// FlushTimesPersistRetrier returns the retrier for persisting flush times.
 	FlushTimesPersistRetrier() retry.Retrier
+
+	// SetFlushTimesCacheDir sets the directory the latest flush times are cached
+	// in so they are available on startup before they are read from kv, empty
+	// disables caching.
+	SetFlushTimesCacheDir(value string) FlushTimesManagerOptions
+
+	// FlushTimesCacheDir returns the directory the latest flush times are cached in.
+	FlushTimesCacheDir() string
 }
 
 type flushTimesManagerOptions struct {
@@ -70,6 +78,7 @@ type flushTimesManagerOptions struct {
 	flushTimesKeyFmt         string
 	flushTimesStore          kv.Store
 	flushTimesPersistRetrier retry.Retrier
+	flushTimesCacheDir       string
 }
 
 // NewFlushTimesManagerOptions create a new set of flush times manager options.
@@ -131,3 +140,13 @@ func (o *flushTimesManagerOptions) SetFlushTimesPersistRetrier(value retry.Retri
 func (o *flushTimesManagerOptions) FlushTimesPersistRetrier() retry.Retrier {
 	return o.flushTimesPersistRetrier
 }
+
+func (o *flushTimesManagerOptions) SetFlushTimesCacheDir(value string) FlushTimesManagerOptions {
+	opts := *o
+	opts.flushTimesCacheDir = value
+	return &opts
+}
+
+func (o *flushTimesManagerOptions) FlushTimesCacheDir() string {
+	return o.flushTimesCacheDir
+}
@@ -22,6 +22,7 @@ package aggregator
 
 import (
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -194,6 +195,36 @@ func TestFlushTimesManagerStoreSyncSuccess(t *testing.T) {
 	require.Equal(t, *testFlushTimesProto, res)
 }
 
+func TestFlushTimesManagerCache(t *testing.T) {
+	dir := t.TempDir()
+
+	mgr, store := testFlushTimesManager()
+	mgr.flushTimesCacheDir = dir
+	require.NoError(t, mgr.Open(testShardSetID))
+
+	// Update the flush times and wait for them to be cached.
+	_, err := store.Set(testFlushTimesKey, testFlushTimesProto)
+	require.NoError(t, err)
+	cacheFilePath := flushTimesCacheFilePath(dir, testShardSetID)
+	for {
+		if _, err := os.Stat(cacheFilePath); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, mgr.Close())
+
+	// The cached flush times are available on open before any are in kv.
+	mgr, _ = testFlushTimesManager()
+	mgr.flushTimesCacheDir = dir
+	require.NoError(t, mgr.Open(testShardSetID))
+	defer mgr.Close()
+
+	res, err := mgr.Get()
+	require.NoError(t, err)
+	require.Equal(t, *testFlushTimesProto, *res)
+}
+
 func TestFlushTimesManagerCloseClosed(t *testing.T) {
 	mgr, _ := testFlushTimesManager()
 	require.Equal(t, errFlushTimesManagerNotOpenOrClosed, mgr.Close())
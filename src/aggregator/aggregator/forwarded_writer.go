@@ -156,6 +156,7 @@ type forwardedWriter struct {
 	aggregations       map[idKey]*forwardedAggregation // Aggregations for each forward metric id
 	metrics            forwardedWriterMetrics
 	aggregationMetrics *forwardedAggregationMetrics
+	lateArrivalFn      LateArrivalFn
 	nowFn              clock.NowFn
 }
 
@@ -171,6 +172,7 @@ func newForwardedWriter(
 		aggregations:       make(map[idKey]*forwardedAggregation),
 		metrics:            newForwardedWriterMetrics(scope),
 		aggregationMetrics: newForwardedAggregationMetrics(scope.SubScope("aggregations")),
+		lateArrivalFn:      opts.LateArrivalFn(),
 		nowFn:              opts.ClockOptions().NowFn(),
 	}
 }
@@ -301,7 +303,11 @@ type forwardedAggregationWithKey struct {
 	// versions are kept around for the lifetime of the timed aggregation. they are expired once the timed aggregation
 	// expires.
 	versions map[xtime.UnixNano]uint32
-	nowFn    clock.NowFn
+	// versionAllWrites is set when flushed aggregations may be amended by late arrivals
+	// and resent without resendEnabled, in which case every write advances the version
+	// so that the next aggregation stage applies a resend as an update.
+	versionAllWrites bool
+	nowFn            clock.NowFn
 }
 
 func (agg *forwardedAggregationWithKey) reset() {
@@ -364,9 +370,10 @@ type forwardedAggregation struct {
 
 	byKey    []forwardedAggregationWithKey
 	metrics  *forwardedAggregationMetrics
-	writeFn  writeForwardedMetricFn
-	onDoneFn onForwardedAggregationDoneFn
-	nowFn    clock.NowFn
+	writeFn       writeForwardedMetricFn
+	onDoneFn      onForwardedAggregationDoneFn
+	lateArrivalFn LateArrivalFn
+	nowFn         clock.NowFn
 }
 
 func (w *forwardedWriter) newForwardedAggregation(metricType metric.Type, metricID id.RawID) *forwardedAggregation {
@@ -375,9 +382,10 @@ func (w *forwardedWriter) newForwardedAggregation(metricType metric.Type, metric
 		metricID:   metricID,
 		shard:      w.shard,
 		client:     w.client,
-		byKey:      make([]forwardedAggregationWithKey, 0, 2),
-		metrics:    w.aggregationMetrics,
-		nowFn:      w.nowFn,
+		byKey:         make([]forwardedAggregationWithKey, 0, 2),
+		metrics:       w.aggregationMetrics,
+		lateArrivalFn: w.lateArrivalFn,
+		nowFn:         w.nowFn,
 	}
 	agg.writeFn = agg.write
 	agg.onDoneFn = agg.onDone
@@ -413,12 +421,13 @@ func (agg *forwardedAggregation) add(metric Registerable) error {
 		return nil
 	}
 	aggregation := forwardedAggregationWithKey{
-		key:         key,
-		totalRefCnt: 1,
-		currRefCnt:  0,
-		buckets:     make(forwardedAggregationBuckets, 0, 2),
-		versions:    make(map[xtime.UnixNano]uint32),
-		nowFn:       agg.nowFn,
+		key:              key,
+		totalRefCnt:      1,
+		currRefCnt:       0,
+		buckets:          make(forwardedAggregationBuckets, 0, 2),
+		versions:         make(map[xtime.UnixNano]uint32),
+		versionAllWrites: agg.lateArrivalFn(key.storagePolicy).Policy != DropLateArrivalPolicy,
+		nowFn:            agg.nowFn,
 	}
 	agg.byKey = append(agg.byKey, aggregation)
 	agg.metrics.added.Inc(1)
@@ -488,7 +497,7 @@ func (agg *forwardedAggregation) onDone(key aggregationKey, expiredTimes []xtime
 			}
 
 			var version uint32
-			if b.resendEnabled {
+			if b.resendEnabled || agg.byKey[idx].versionAllWrites {
 				version = versions[b.timeNanos]
 				versions[b.timeNanos] = version + 1
 			}
@@ -50,7 +50,10 @@ type lockedGaugeAggregation struct {
 	dirty         bool
 	// resendEnabled is allowed to change while an aggregation is open, so it must be behind the lock.
 	resendEnabled bool
-	closed        bool
+	// flushed is set once the aggregation has been consumed by a flush, after which
+	// values arriving without resendEnabled are late arrivals.
+	flushed bool
+	closed  bool
 }
 
 type timedGauge struct {
@@ -144,6 +147,9 @@ func (e *GaugeElem) AddUnion(timestamp time.Time, mu unaggregated.MetricUnion, r
 		}
 		return errAggregationClosed
 	}
+	if lockedAgg.flushed && !lockedAgg.resendEnabled && !resendEnabled {
+		e.recordLateArrival()
+	}
 	lockedAgg.aggregation.AddUnion(timestamp, mu)
 	lockedAgg.dirty = true
 	lockedAgg.lastUpdatedAt = xtime.Now()
@@ -164,6 +170,9 @@ func (e *GaugeElem) AddValue(timestamp time.Time, value float64, annotation []by
 		lockedAgg.mtx.Unlock()
 		return errAggregationClosed
 	}
+	if lockedAgg.flushed && !lockedAgg.resendEnabled {
+		e.recordLateArrival()
+	}
 	lockedAgg.aggregation.Add(timestamp, value, annotation)
 	lockedAgg.dirty = true
 	lockedAgg.lastUpdatedAt = xtime.Now()
@@ -203,6 +212,9 @@ func (e *GaugeElem) AddUnique(
 		lockedAgg.mtx.Unlock()
 		return errDuplicateForwardingSource
 	}
+	if lockedAgg.flushed && !lockedAgg.resendEnabled && !metadata.ResendEnabled {
+		e.recordLateArrival()
+	}
 	versionsSeen.Set(version)
 
 	if metric.Version > 0 {
@@ -239,12 +251,16 @@ func (e *GaugeElem) expireValuesWithLock(
 
 	currAgg := e.values[e.minStartTime]
 	resendExpire := targetNanos - int64(e.bufferForPastTimedMetricFn(resolution))
+	lateArrivalExpire := targetNanos - int64(e.lateArrival.gracePeriod())
 	for isEarlierThanFn(int64(currAgg.startAt), resolution, targetNanos) {
 		if e.flushState[currAgg.startAt].latestResendEnabled {
 			// if resend enabled we want to keep this value until it is outside the buffer past period.
 			if !isEarlierThanFn(int64(currAgg.startAt), resolution, resendExpire) {
 				break
 			}
+		} else if !isEarlierThanFn(int64(currAgg.startAt), resolution, lateArrivalExpire) {
+			// otherwise keep this value open to late arrivals until it is outside the grace period.
+			break
 		}
 
 		// close the agg to prevent any more writes.
@@ -503,6 +519,9 @@ func (e *GaugeElem) appendConsumeStateWithLock(
 	}
 	cState.annotation = raggregation.MaybeReplaceAnnotation(cState.annotation, agg.lockedAgg.aggregation.Annotation())
 	agg.lockedAgg.dirty = false
+	if cState.dirty {
+		agg.lockedAgg.flushed = true
+	}
 	agg.lockedAgg.mtx.Unlock()
 
 	// update with everything else.
@@ -526,6 +545,77 @@ func (e *GaugeElem) appendConsumeStateWithLock(
 	return toConsume, true
 }
 
+// snapshot returns the state of the aggregation windows that have been
+// updated since they were last consumed and have not been closed yet.
+func (e *GaugeElem) snapshot() (elemSnapshot, error) {
+	e.RLock()
+	if e.closed {
+		e.RUnlock()
+		return elemSnapshot{}, errElemClosed
+	}
+	snapshot := elemSnapshot{
+		listType: e.listType,
+		windows:  make([]aggregationSnapshot, 0, len(e.dirty)),
+	}
+	for startAt, timedAgg := range e.values {
+		lockedAgg := timedAgg.lockedAgg
+		lockedAgg.mtx.Lock()
+		if lockedAgg.closed || !lockedAgg.dirty {
+			lockedAgg.mtx.Unlock()
+			continue
+		}
+		window := aggregationSnapshot{
+			startAt:       startAt,
+			lastUpdatedAt: lockedAgg.lastUpdatedAt,
+			resendEnabled: lockedAgg.resendEnabled,
+			state:         lockedAgg.aggregation.SnapshotState(),
+		}
+		if lockedAgg.sourcesSeen != nil {
+			window.sourcesSeen = make(map[uint32][]uint, len(lockedAgg.sourcesSeen))
+			for sourceID, versionsSeen := range lockedAgg.sourcesSeen {
+				window.sourcesSeen[sourceID] = versionsFromBitSet(versionsSeen)
+			}
+		}
+		lockedAgg.mtx.Unlock()
+		snapshot.windows = append(snapshot.windows, window)
+	}
+	e.RUnlock()
+	return snapshot, nil
+}
+
+// restore restores previously snapshotted aggregation windows, which are
+// marked dirty so they are consumed by the next flush.
+func (e *GaugeElem) restore(windows []aggregationSnapshot) error {
+	for _, window := range windows {
+		lockedAgg, err := e.findOrCreate(int64(window.startAt), createAggregationOptions{
+			initSourceSet: window.sourcesSeen != nil,
+		})
+		if err != nil {
+			return err
+		}
+		lockedAgg.mtx.Lock()
+		if lockedAgg.closed {
+			lockedAgg.mtx.Unlock()
+			return errAggregationClosed
+		}
+		if err := lockedAgg.aggregation.RestoreState(window.state); err != nil {
+			lockedAgg.mtx.Unlock()
+			return err
+		}
+		for sourceID, versions := range window.sourcesSeen {
+			if lockedAgg.sourcesSeen == nil {
+				break
+			}
+			lockedAgg.sourcesSeen[sourceID] = bitSetFromVersions(versions)
+		}
+		lockedAgg.dirty = true
+		lockedAgg.lastUpdatedAt = window.lastUpdatedAt
+		lockedAgg.resendEnabled = window.resendEnabled
+		lockedAgg.mtx.Unlock()
+	}
+	return nil
+}
+
 // Close closes the element.
 func (e *GaugeElem) Close() {
 	e.Lock()
@@ -756,13 +846,20 @@ func (e *GaugeElem) processValue(
 		expectedProcessingTime = cState.lastUpdatedAt.Truncate(resolution).Add(resolution)
 	)
 	fState := e.flushState[cState.startAt]
-	if cState.dirty && fState.flushed && !cState.resendEnabled {
+	if cState.dirty && fState.flushed && !cState.resendEnabled && e.lateArrival.Policy == DropLateArrivalPolicy {
 		cState := cState
 		instrument.EmitAndLogInvariantViolation(e.opts.InstrumentOptions(), func(l *zap.Logger) {
 			l.Error("reflushing aggregation without resendEnabled", zap.Any("consumeState", cState))
 		})
 	}
 
+	// reflushing an aggregation without resendEnabled means it was amended by late arrivals, which are
+	// flushed as per the late arrival policy and forwarded with a higher version.
+	sp, resendEnabled := e.sp, cState.resendEnabled
+	if fState.flushed && !cState.resendEnabled && e.lateArrival.Policy != DropLateArrivalPolicy {
+		sp, resendEnabled = e.lateArrival.storagePolicy(e.sp), true
+	}
+
 	for aggTypeIdx, aggType := range e.aggTypes {
 		var extraDp transformation.Datapoint
 		value := cState.values[aggTypeIdx]
@@ -860,16 +957,16 @@ func (e *GaugeElem) processValue(
 				switch e.idPrefixSuffixType {
 				case NoPrefixNoSuffix:
 					flushLocalFn(nil, e.id, nil, point.TimeNanos, point.Value, cState.annotation,
-						e.sp)
+						sp)
 				case WithPrefixWithSuffix:
 					flushLocalFn(e.FullPrefix(e.opts), e.id, e.TypeStringFor(e.aggTypesOpts, aggType),
-						point.TimeNanos, point.Value, cState.annotation, e.sp)
+						point.TimeNanos, point.Value, cState.annotation, sp)
 				}
 			}
 		} else {
 			forwardedAggregationKey, _ := e.ForwardedAggregationKey()
 			flushForwardedFn(e.writeForwardedMetricFn, forwardedAggregationKey,
-				int64(timestamp), value, prevValue, cState.annotation, cState.resendEnabled)
+				int64(timestamp), value, prevValue, cState.annotation, resendEnabled)
 		}
 		// add latenessAllowed and jitter to the timestamp of the aggregation, since those should not be
 		// counted towards the processing lag.
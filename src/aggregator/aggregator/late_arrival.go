@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/policy"
+)
+
+// LateArrivalPolicy determines how values arriving for an aggregation window
+// that has already been flushed are handled.
+type LateArrivalPolicy int
+
+const (
+	// DropLateArrivalPolicy closes windows once flushed so late values are dropped
+	// and the flushed window is left as is. It is the default policy.
+	DropLateArrivalPolicy LateArrivalPolicy = iota
+
+	// ReflushLateArrivalPolicy adds late values to the flushed window and flushes
+	// the amended aggregate again. Forwarded aggregates are resent with a higher
+	// version so the next aggregation stage updates its window in place.
+	ReflushLateArrivalPolicy
+
+	// CorrectionsLateArrivalPolicy adds late values to the flushed window and
+	// flushes the amended aggregate with the corrections storage policy, leaving
+	// the originally flushed value untouched. Forwarded aggregates are resent
+	// as with ReflushLateArrivalPolicy since the next stage owns their storage.
+	CorrectionsLateArrivalPolicy
+
+	numLateArrivalPolicies
+)
+
+var validLateArrivalPolicies = []LateArrivalPolicy{
+	DropLateArrivalPolicy,
+	ReflushLateArrivalPolicy,
+	CorrectionsLateArrivalPolicy,
+}
+
+func (p LateArrivalPolicy) String() string {
+	switch p {
+	case DropLateArrivalPolicy:
+		return "drop"
+	case ReflushLateArrivalPolicy:
+		return "reflush"
+	case CorrectionsLateArrivalPolicy:
+		return "corrections"
+	}
+	return "unknown"
+}
+
+// MarshalYAML marshals a LateArrivalPolicy.
+func (p LateArrivalPolicy) MarshalYAML() (interface{}, error) {
+	return p.String(), nil
+}
+
+// UnmarshalYAML unmarshals a LateArrivalPolicy into a valid policy from string.
+func (p *LateArrivalPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	if str == "" {
+		*p = DropLateArrivalPolicy
+		return nil
+	}
+	strs := make([]string, 0, len(validLateArrivalPolicies))
+	for _, valid := range validLateArrivalPolicies {
+		if str == valid.String() {
+			*p = valid
+			return nil
+		}
+		strs = append(strs, "'"+valid.String()+"'")
+	}
+	return fmt.Errorf(
+		"invalid LateArrivalPolicy '%s' valid policies are: %s", str, strings.Join(strs, ", "),
+	)
+}
+
+// LateArrival is the late arrival handling for a storage policy.
+type LateArrival struct {
+	// Policy is how late values are handled.
+	Policy LateArrivalPolicy
+
+	// GracePeriod is how long a flushed aggregation window keeps accepting late
+	// values. A zero grace period closes windows as soon as they are flushed.
+	// It is ignored by DropLateArrivalPolicy.
+	GracePeriod time.Duration
+
+	// CorrectionsStoragePolicy is the storage policy amended aggregates are
+	// flushed with under CorrectionsLateArrivalPolicy.
+	CorrectionsStoragePolicy policy.StoragePolicy
+}
+
+// LateArrivalFn returns the late arrival handling for a storage policy.
+type LateArrivalFn func(sp policy.StoragePolicy) LateArrival
+
+func defaultLateArrivalFn(policy.StoragePolicy) LateArrival {
+	return LateArrival{Policy: DropLateArrivalPolicy}
+}
+
+// gracePeriod returns how long flushed windows accept late values.
+func (l LateArrival) gracePeriod() time.Duration {
+	if l.Policy == DropLateArrivalPolicy {
+		return 0
+	}
+	return l.GracePeriod
+}
+
+// storagePolicy returns the storage policy amended aggregates of a window
+// flushed under the given storage policy are written with.
+func (l LateArrival) storagePolicy(sp policy.StoragePolicy) policy.StoragePolicy {
+	if l.Policy == CorrectionsLateArrivalPolicy {
+		return l.CorrectionsStoragePolicy
+	}
+	return sp
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	maggregation "github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/metrics/metric/unaggregated"
+	"github.com/m3db/m3/src/metrics/pipeline/applied"
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestLateArrivalPolicyUnmarshalYAML(t *testing.T) {
+	type S struct {
+		A LateArrivalPolicy
+	}
+
+	tests := []struct {
+		input    []byte
+		expected LateArrivalPolicy
+	}{
+		{
+			input:    []byte("a: reflush\n"),
+			expected: ReflushLateArrivalPolicy,
+		},
+		{
+			input:    []byte("a: drop\n"),
+			expected: DropLateArrivalPolicy,
+		},
+		{
+			input:    []byte("a: corrections\n"),
+			expected: CorrectionsLateArrivalPolicy,
+		},
+	}
+
+	for _, test := range tests {
+		var s S
+		err := yaml.Unmarshal(test.input, &s)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, s.A)
+	}
+
+	var s S
+	require.Error(t, yaml.Unmarshal([]byte("a: bogus\n"), &s))
+}
+
+func TestGaugeElemLateArrival(t *testing.T) {
+	var (
+		isEarlierThanFn    = isStandardMetricEarlierThan
+		timestampNanosFn   = standardMetricTimestampNanos
+		gracePeriod        = 20 * time.Second
+		correctionsSP      = policy.NewStoragePolicy(10*time.Second, testStoragePolicy.Resolution().Precision, 48*time.Hour)
+		lateVal            = testGaugeVals[1] - 1.0
+		expectedFirstFlush = append(
+			expectedLocalMetricsForGauge(testAlignedStarts[1], testStoragePolicy, maggregation.DefaultTypes),
+			expectedLocalMetricsForGauge(testAlignedStarts[2], testStoragePolicy, maggregation.DefaultTypes)...)
+	)
+
+	tests := []struct {
+		policy       LateArrivalPolicy
+		expectedSP   policy.StoragePolicy
+		expectedLate int64
+	}{
+		{
+			policy:       ReflushLateArrivalPolicy,
+			expectedSP:   testStoragePolicy,
+			expectedLate: 1,
+		},
+		{
+			policy:       CorrectionsLateArrivalPolicy,
+			expectedSP:   correctionsSP,
+			expectedLate: 1,
+		},
+		{
+			policy: DropLateArrivalPolicy,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.policy.String(), func(t *testing.T) {
+			scope := tally.NewTestScope("", nil)
+			opts := newTestOptions().
+				SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope)).
+				SetLateArrivalFn(func(policy.StoragePolicy) LateArrival {
+					return LateArrival{
+						Policy:                   test.policy,
+						GracePeriod:              gracePeriod,
+						CorrectionsStoragePolicy: correctionsSP,
+					}
+				})
+			elemData := testGaugeData
+			elemData.Pipeline = applied.DefaultPipeline
+			e := testGaugeElemWithData(t, testAlignedStarts[:len(testAlignedStarts)-1], testGaugeVals, elemData,
+				opts, false)
+
+			// Flush both values.
+			localFn, localRes := testFlushLocalMetricFn()
+			forwardFn, _ := testFlushForwardedMetricFn()
+			onForwardedFlushedFn, _ := testOnForwardedFlushedFn()
+			require.False(t,
+				e.Consume(testAlignedStarts[2], isEarlierThanFn, timestampNanosFn, standardMetricTargetNanos,
+					localFn, forwardFn, onForwardedFlushedFn, 0, consumeType))
+			require.Equal(t, expectedFirstFlush, *localRes)
+
+			// Write a late value for the last flushed value.
+			mu := unaggregated.MetricUnion{
+				GaugeVal: lateVal,
+			}
+			err := e.AddUnion(time.Unix(0, testAlignedStarts[1]), mu, false)
+			if test.policy == DropLateArrivalPolicy {
+				// drop does not keep flushed values open.
+				require.Equal(t, errAggregationClosed, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			localFn, localRes = testFlushLocalMetricFn()
+			require.False(t,
+				e.Consume(testAlignedStarts[2], isEarlierThanFn, timestampNanosFn, standardMetricTargetNanos,
+					localFn, forwardFn, onForwardedFlushedFn, 0, consumeType))
+			if test.policy == DropLateArrivalPolicy {
+				require.Equal(t, 0, len(*localRes))
+			} else {
+				require.Equal(t,
+					expectedLocalMetricsForGaugeWithVal(testAlignedStarts[2], lateVal, test.expectedSP,
+						maggregation.DefaultTypes),
+					*localRes)
+			}
+
+			var late int64
+			for _, c := range scope.Snapshot().Counters() {
+				if c.Name() == "late-arrivals" && c.Tags()[lateArrivalPolicyLabel] == test.policy.String() {
+					late += c.Value()
+				}
+			}
+			require.Equal(t, test.expectedLate, late)
+
+			// Values are closed to late arrivals once outside the grace period.
+			localFn, _ = testFlushLocalMetricFn()
+			require.False(t,
+				e.Consume(time.Unix(0, testAlignedStarts[2]).Add(gracePeriod).UnixNano(), isEarlierThanFn,
+					timestampNanosFn, standardMetricTargetNanos, localFn, forwardFn, onForwardedFlushedFn, 0,
+					consumeType))
+			require.Equal(t, errAggregationClosed, e.AddUnion(time.Unix(0, testAlignedStarts[1]), mu, false))
+		})
+	}
+}
@@ -51,6 +51,8 @@ type leaderFlushManagerMetrics struct {
 	queueSize             tally.Gauge
 	getShardsError        tally.Counter
 	flushTimesUpdateError tally.Counter
+	prunedFlushTimes      tally.Counter
+	prunedShards          tally.Counter
 	standard              leaderFlusherMetrics
 	forwarded             leaderFlusherMetrics
 	timed                 leaderFlusherMetrics
@@ -64,6 +66,8 @@ func newLeaderFlushManagerMetrics(scope tally.Scope) leaderFlushManagerMetrics {
 		queueSize:             scope.Gauge("queue-size"),
 		getShardsError:        scope.Counter("get-shards-error"),
 		flushTimesUpdateError: scope.Counter("flush-times-update-error"),
+		prunedFlushTimes:      scope.Counter("pruned-flush-times"),
+		prunedShards:          scope.Counter("pruned-shards"),
 		standard:              newLeaderFlusherMetrics(standardScope),
 		forwarded:             newLeaderFlusherMetrics(forwardedScope),
 		timed:                 newLeaderFlusherMetrics(timedScope),
@@ -72,6 +76,14 @@ func newLeaderFlushManagerMetrics(scope tally.Scope) leaderFlushManagerMetrics {
 
 type randFn func(int64) int64
 
+// flushTimesKey identifies the flush time of a single flusher of a shard.
+type flushTimesKey struct {
+	shard             uint32
+	listType          metricListType
+	resolution        int64
+	numForwardedTimes int32
+}
+
 type leaderFlushManager struct {
 	sync.RWMutex
 
@@ -81,14 +93,18 @@ type leaderFlushManager struct {
 	placementManager  PlacementManager
 	flushTimesManager FlushTimesManager
 	maxBufferSize     time.Duration
+	staleRetention    time.Duration
 	logger            *zap.Logger
 	scope             tally.Scope
 
 	doneCh         <-chan struct{}
 	flushTimes     flushMetadataHeap
 	flushedByShard map[uint32]*schema.ShardFlushTimes
-	flushTask      *leaderFlushTask
-	metrics        leaderFlushManagerMetrics
+	// updated is the set of flush times updated by the latest update, flush
+	// times not in it belong to shards or flushers that no longer exist.
+	updated   map[flushTimesKey]struct{}
+	flushTask *leaderFlushTask
+	metrics   leaderFlushManagerMetrics
 }
 
 func newLeaderFlushManager(
@@ -105,10 +121,12 @@ func newLeaderFlushManager(
 		placementManager:  opts.PlacementManager(),
 		flushTimesManager: opts.FlushTimesManager(),
 		maxBufferSize:     opts.MaxBufferSize(),
+		staleRetention:    opts.StaleFlushTimesRetention(),
 		logger:            instrumentOpts.Logger(),
 		scope:             scope,
 		doneCh:            doneCh,
 		flushedByShard:    make(map[uint32]*schema.ShardFlushTimes, defaultInitialFlushCapacity),
+		updated:           make(map[flushTimesKey]struct{}, defaultInitialFlushCapacity),
 		metrics:           newLeaderFlushManagerMetrics(scope),
 	}
 	mgr.flushTask = &leaderFlushTask{
@@ -271,6 +289,7 @@ func (mgr *leaderFlushManager) prepareFlushTimesWithLock(
 ) *schema.ShardSetFlushTimes {
 	// Update internal flush times to the latest flush times of all the flushers in the buckets.
 	mgr.updateFlushTimesWithLock(buckets, shards)
+	mgr.pruneFlushTimesWithLock()
 
 	// Make a copy of the updated flush times for asynchronous persistence.
 	cloned := cloneFlushTimesByShard(mgr.flushedByShard)
@@ -285,11 +304,15 @@ func (mgr *leaderFlushManager) updateFlushTimesWithLock(
 	for _, shardFlushTimes := range mgr.flushedByShard {
 		shardFlushTimes.Tombstoned = true
 	}
+	for key := range mgr.updated {
+		delete(mgr.updated, key)
+	}
 	for _, bucket := range buckets {
 		bucketID := bucket.bucketID
 		switch bucketID.listType {
 		case standardMetricListType:
 			mgr.updateStandardFlushTimesWithLock(
+				standardMetricListType,
 				bucketID.standard.resolution,
 				bucket.flushers,
 				getStandardFlushTimesByResolutionFn,
@@ -300,6 +323,7 @@ func (mgr *leaderFlushManager) updateFlushTimesWithLock(
 			mgr.updateForwardedFlushTimesWithLock(bucketID.forwarded, bucket.flushers, shards)
 		case timedMetricListType:
 			mgr.updateStandardFlushTimesWithLock(
+				timedMetricListType,
 				bucketID.timed.resolution,
 				bucket.flushers,
 				getTimedFlushTimesByResolutionFn,
@@ -313,6 +337,7 @@ func (mgr *leaderFlushManager) updateFlushTimesWithLock(
 }
 
 func (mgr *leaderFlushManager) updateStandardFlushTimesWithLock(
+	listType metricListType,
 	resolution time.Duration,
 	flushers []flushingMetricList,
 	getFlushTimesByResolutionFn getFlushTimesByResolutionFn,
@@ -324,6 +349,11 @@ func (mgr *leaderFlushManager) updateStandardFlushTimesWithLock(
 		flushTimesByResolution := getFlushTimesByResolutionFn(flushTimes)
 		flushTimesByResolution[int64(resolution)] = flusher.LastFlushedNanos()
 		flushTimes.Tombstoned = false
+		mgr.markUpdatedWithLock(flushTimesKey{
+			shard:      flusher.Shard(),
+			listType:   listType,
+			resolution: int64(resolution),
+		})
 	}
 
 	// Assign flush times to redirected shards.
@@ -346,6 +376,11 @@ func (mgr *leaderFlushManager) updateStandardFlushTimesWithLock(
 			flushTimesByResolution := getFlushTimesByResolutionFn(flushTimes)
 			flushTimesByResolution[int64(resolution)] = redirectToFlushTime
 			flushTimes.Tombstoned = flushTimes.Tombstoned && redirectToFlushTimes.Tombstoned
+			mgr.markUpdatedWithLock(flushTimesKey{
+				shard:      shard.ID(),
+				listType:   listType,
+				resolution: int64(resolution),
+			})
 		}
 	}
 
@@ -366,6 +401,12 @@ func (mgr *leaderFlushManager) updateForwardedFlushTimesWithLock(
 		forwardedFlushTimes := mgr.getOrCreateForwarderFlushTimesForResolutionWithLock(flushTimes, resolution)
 		forwardedFlushTimes.ByNumForwardedTimes[numForwardedTimes] = flusher.LastFlushedNanos()
 		flushTimes.Tombstoned = false
+		mgr.markUpdatedWithLock(flushTimesKey{
+			shard:             flusher.Shard(),
+			listType:          forwardedMetricListType,
+			resolution:        resolution,
+			numForwardedTimes: numForwardedTimes,
+		})
 	}
 
 	// Assign flush times to redirected shards.
@@ -390,12 +431,88 @@ func (mgr *leaderFlushManager) updateForwardedFlushTimesWithLock(
 			forwardedFlushTimes := mgr.getOrCreateForwarderFlushTimesForResolutionWithLock(flushTimes, resolution)
 			forwardedFlushTimes.ByNumForwardedTimes[numForwardedTimes] = redirectToFlushTime
 			flushTimes.Tombstoned = flushTimes.Tombstoned && redirectToFlushTimes.Tombstoned
+			mgr.markUpdatedWithLock(flushTimesKey{
+				shard:             shard.ID(),
+				listType:          forwardedMetricListType,
+				resolution:        resolution,
+				numForwardedTimes: numForwardedTimes,
+			})
 		}
 	}
 
 	mgr.metrics.forwarded.updateFlushTimes.Inc(int64(len(flushers)))
 }
 
+func (mgr *leaderFlushManager) markUpdatedWithLock(key flushTimesKey) {
+	mgr.updated[key] = struct{}{}
+}
+
+// pruneFlushTimesWithLock removes the flush times that were not updated by the
+// latest update and were last flushed longer than the stale retention ago, i.e.
+// those of shards no longer owned and of flushers that no longer exist such as
+// those of removed policies, and the shards left without any flush times. They
+// are retained for a while so followers can still check them when taking over.
+func (mgr *leaderFlushManager) pruneFlushTimesWithLock() {
+	if mgr.staleRetention <= 0 {
+		return
+	}
+
+	staleBeforeNanos := mgr.nowNanos() - mgr.staleRetention.Nanoseconds()
+	isStale := func(key flushTimesKey, lastFlushedNanos int64) bool {
+		if _, ok := mgr.updated[key]; ok {
+			return false
+		}
+		return lastFlushedNanos < staleBeforeNanos
+	}
+
+	var numPruned int64
+	pruneByResolution := func(
+		shardID uint32,
+		listType metricListType,
+		flushTimesByResolution map[int64]int64,
+	) {
+		for resolution, lastFlushedNanos := range flushTimesByResolution {
+			key := flushTimesKey{shard: shardID, listType: listType, resolution: resolution}
+			if isStale(key, lastFlushedNanos) {
+				delete(flushTimesByResolution, resolution)
+				numPruned++
+			}
+		}
+	}
+
+	for shardID, flushTimes := range mgr.flushedByShard {
+		pruneByResolution(shardID, standardMetricListType, flushTimes.StandardByResolution)
+		pruneByResolution(shardID, timedMetricListType, flushTimes.TimedByResolution)
+		for resolution, forwardedFlushTimes := range flushTimes.ForwardedByResolution {
+			for numForwardedTimes, lastFlushedNanos := range forwardedFlushTimes.ByNumForwardedTimes {
+				key := flushTimesKey{
+					shard:             shardID,
+					listType:          forwardedMetricListType,
+					resolution:        resolution,
+					numForwardedTimes: numForwardedTimes,
+				}
+				if isStale(key, lastFlushedNanos) {
+					delete(forwardedFlushTimes.ByNumForwardedTimes, numForwardedTimes)
+					numPruned++
+				}
+			}
+			if len(forwardedFlushTimes.ByNumForwardedTimes) == 0 {
+				delete(flushTimes.ForwardedByResolution, resolution)
+			}
+		}
+
+		if flushTimes.Tombstoned &&
+			len(flushTimes.StandardByResolution) == 0 &&
+			len(flushTimes.TimedByResolution) == 0 &&
+			len(flushTimes.ForwardedByResolution) == 0 {
+			delete(mgr.flushedByShard, shardID)
+			mgr.metrics.prunedShards.Inc(1)
+		}
+	}
+
+	mgr.metrics.prunedFlushTimes.Inc(numPruned)
+}
+
 func (mgr *leaderFlushManager) getOrCreateFlushTimesWithLock(shardID uint32) *schema.ShardFlushTimes {
 	flushTimes, exists := mgr.flushedByShard[shardID]
 	if !exists {
@@ -387,6 +387,53 @@ func TestLeaderFlushManagerPrepareWithRedirectedShard(t *testing.T) {
 	validateFlushMetadataHeap(t, expectedFlushTimes1, mgr.flushTimes)
 }
 
+func TestLeaderFlushManagerPrunesStaleFlushTimes(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now    = time.Unix(3663, 0).Add(25 * time.Hour)
+		doneCh = make(chan struct{})
+		opts   = NewFlushManagerOptions().SetJitterEnabled(false)
+	)
+
+	mgr := newLeaderFlushManager(doneCh, opts).(*leaderFlushManager)
+	mgr.nowFn = func() time.Time { return now }
+
+	// The 10s resolution of shard 0 no longer has a flusher, shard 9 is no
+	// longer owned and both were last flushed longer than the retention ago.
+	// Shard 10 is no longer owned but was flushed recently.
+	shard0 := newShardFlushTimes()
+	shard0.StandardByResolution[int64(10*time.Second)] = time.Unix(1000, 0).UnixNano()
+	shard0.ForwardedByResolution[int64(10*time.Second)] = &schema.ForwardedFlushTimesForResolution{
+		ByNumForwardedTimes: map[int32]int64{1: time.Unix(1000, 0).UnixNano()},
+	}
+	shard9 := newShardFlushTimes()
+	shard9.StandardByResolution[int64(time.Second)] = time.Unix(1000, 0).UnixNano()
+	shard10 := newShardFlushTimes()
+	shard10.StandardByResolution[int64(time.Second)] = now.Add(-time.Hour).UnixNano()
+	mgr.flushedByShard[0] = shard0
+	mgr.flushedByShard[9] = shard9
+	mgr.flushedByShard[10] = shard10
+
+	flushTimes := mgr.prepareFlushTimesWithLock(testFlushBuckets(ctrl, true), nil)
+
+	expected := cloneFlushTimesByShard(testFlushTimes.ByShard)
+	expected[10] = &schema.ShardFlushTimes{
+		StandardByResolution: map[int64]int64{
+			int64(time.Second): now.Add(-time.Hour).UnixNano(),
+		},
+		Tombstoned: true,
+	}
+	validateShardSetFlushTimes(t, &schema.ShardSetFlushTimes{ByShard: expected}, flushTimes)
+
+	// Once past the retention shard 10 is pruned too, while the flush times of
+	// existing flushers are never pruned however old.
+	mgr.staleRetention = time.Nanosecond
+	flushTimes = mgr.prepareFlushTimesWithLock(testFlushBuckets(ctrl, true), nil)
+	validateShardSetFlushTimes(t, testFlushTimes, flushTimes)
+}
+
 func TestLeaderFlushManagerOnBucketAdded(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
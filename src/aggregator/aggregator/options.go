@@ -261,6 +261,14 @@ type Options interface {
 	// BufferForPastTimedMetricFn returns the size fn of the buffer for timed metrics in the past.
 	BufferForPastTimedMetricFn() BufferForPastTimedMetricFn
 
+	// SetLateArrivalFn sets the fn that determines how values arriving for already
+	// flushed aggregation windows are handled per storage policy.
+	SetLateArrivalFn(value LateArrivalFn) Options
+
+	// LateArrivalFn returns the fn that determines how values arriving for already
+	// flushed aggregation windows are handled per storage policy.
+	LateArrivalFn() LateArrivalFn
+
 	// SetBufferForFutureTimedMetric sets the size of the buffer for timed metrics in the future.
 	SetBufferForFutureTimedMetric(value time.Duration) Options
 
@@ -347,6 +355,12 @@ type Options interface {
 	// SetWritesIgnoreCutoffCutover sets a flag controlling whether cutoff/cutover timestamps
 	// are ignored for incoming writes.
 	SetWritesIgnoreCutoffCutover(value bool) Options
+
+	// SetSnapshotOptions sets the aggregation state snapshot options.
+	SetSnapshotOptions(value SnapshotOptions) Options
+
+	// SnapshotOptions returns the aggregation state snapshot options.
+	SnapshotOptions() SnapshotOptions
 }
 
 type options struct {
@@ -380,6 +394,7 @@ type options struct {
 	maxAllowedForwardingDelayFn      MaxAllowedForwardingDelayFn
 	bufferForPastTimedMetric         time.Duration
 	bufferForPastTimedMetricFn       BufferForPastTimedMetricFn
+	lateArrivalFn                    LateArrivalFn
 	bufferForFutureTimedMetric       time.Duration
 	maxNumCachedSourceSets           int
 	discardNaNAggregatedValues       bool
@@ -392,6 +407,7 @@ type options struct {
 	timedMetricsFlushOffsetEnabled   bool
 	featureFlagBundlesParsed         []FeatureFlagBundleParsed
 	writesIgnoreCutoffCutover        bool
+	snapshotOpts                     SnapshotOptions
 
 	// Derived options.
 	fullCounterPrefix []byte
@@ -430,10 +446,12 @@ func NewOptions(clockOpts clock.Options) Options {
 		maxAllowedForwardingDelayFn:      defaultMaxAllowedForwardingDelayFn,
 		bufferForPastTimedMetric:         defaultTimedMetricBuffer,
 		bufferForPastTimedMetricFn:       defaultBufferForPastTimedMetricFn,
+		lateArrivalFn:                    defaultLateArrivalFn,
 		bufferForFutureTimedMetric:       defaultTimedMetricBuffer,
 		maxNumCachedSourceSets:           defaultMaxNumCachedSourceSets,
 		discardNaNAggregatedValues:       defaultDiscardNaNAggregatedValues,
 		verboseErrors:                    defaultVerboseErrors,
+		snapshotOpts:                     NewSnapshotOptions(),
 	}
 
 	// Initialize pools.
@@ -739,6 +757,16 @@ func (o *options) BufferForPastTimedMetricFn() BufferForPastTimedMetricFn {
 	return o.bufferForPastTimedMetricFn
 }
 
+func (o *options) SetLateArrivalFn(value LateArrivalFn) Options {
+	opts := *o
+	opts.lateArrivalFn = value
+	return &opts
+}
+
+func (o *options) LateArrivalFn() LateArrivalFn {
+	return o.lateArrivalFn
+}
+
 func (o *options) SetBufferForFutureTimedMetric(value time.Duration) Options {
 	opts := *o
 	opts.bufferForFutureTimedMetric = value
@@ -931,6 +959,16 @@ func (o *options) SetWritesIgnoreCutoffCutover(value bool) Options {
 	return &opts
 }
 
+func (o *options) SetSnapshotOptions(value SnapshotOptions) Options {
+	opts := *o
+	opts.snapshotOpts = value
+	return &opts
+}
+
+func (o *options) SnapshotOptions() SnapshotOptions {
+	return o.snapshotOpts
+}
+
 func defaultMaxAllowedForwardingDelayFn(
 	resolution time.Duration,
 	numForwardedTimes int,
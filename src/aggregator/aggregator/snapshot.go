@@ -0,0 +1,889 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	stdhash "hash"
+	"hash/adler32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	raggregation "github.com/m3db/m3/src/aggregator/aggregation"
+	"github.com/m3db/m3/src/aggregator/aggregation/quantile/cm"
+	"github.com/m3db/m3/src/aggregator/hash"
+	"github.com/m3db/m3/src/metrics/generated/proto/pipelinepb"
+	"github.com/m3db/m3/src/metrics/generated/proto/policypb"
+	"github.com/m3db/m3/src/metrics/metric"
+	metricid "github.com/m3db/m3/src/metrics/metric/id"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/willf/bitset"
+)
+
+const (
+	// snapshotFormatVersion is the current version of the shard snapshot
+	// format. Readers reject snapshots written with a newer version.
+	snapshotFormatVersion = 1
+
+	snapshotFileSuffix   = ".snapshot"
+	snapshotChecksumSize = 4
+)
+
+var (
+	snapshotMagic = []byte("M3AS")
+
+	errSnapshotTooShort          = errors.New("snapshot is too short")
+	errSnapshotChecksumMismatch  = errors.New("snapshot checksum mismatch")
+	errSnapshotInvalidMagic      = errors.New("snapshot has invalid magic bytes")
+	errSnapshotUnexpectedEOF     = errors.New("snapshot ended unexpectedly")
+	errSnapshotUnsupportedFormat = errors.New("snapshot format version is not supported")
+	errSnapshotShardMismatch     = errors.New("snapshot belongs to a different shard")
+)
+
+// aggregationSnapshot is the persistable state of an aggregation window.
+type aggregationSnapshot struct {
+	startAt       xtime.UnixNano
+	lastUpdatedAt xtime.UnixNano
+	resendEnabled bool
+	// sourcesSeen maps the source IDs of forwarded metrics to the versions
+	// seen, and is nil for aggregations that do not track sources.
+	sourcesSeen map[uint32][]uint
+	state       aggregationState
+}
+
+// elemSnapshot is the persistable state of an element.
+type elemSnapshot struct {
+	listType metricListType
+	windows  []aggregationSnapshot
+}
+
+// aggregationValueSnapshot is the persistable state of an aggregation of an entry.
+type aggregationValueSnapshot struct {
+	key           aggregationKey
+	resendEnabled bool
+	elem          elemSnapshot
+}
+
+// entrySnapshot is the persistable state of an entry.
+type entrySnapshot struct {
+	metricType          metricType
+	metricCategory      metricCategory
+	id                  metricid.RawID
+	cutoverNanos        int64
+	hasDefaultMetadatas bool
+	aggregations        []aggregationValueSnapshot
+}
+
+func (s entrySnapshot) numWindows() int {
+	var n int
+	for _, agg := range s.aggregations {
+		n += len(agg.elem.windows)
+	}
+	return n
+}
+
+// shardSnapshotHeader is the header of a shard snapshot.
+type shardSnapshotHeader struct {
+	version    uint64
+	shard      uint32
+	snapshotAt time.Time
+}
+
+func versionsFromBitSet(bs *bitset.BitSet) []uint {
+	versions := make([]uint, 0, bs.Count())
+	for i, ok := bs.NextSet(0); ok; i, ok = bs.NextSet(i + 1) {
+		versions = append(versions, i)
+	}
+	return versions
+}
+
+func bitSetFromVersions(versions []uint) *bitset.BitSet {
+	bs := bitset.New(defaultNumVersions)
+	for _, v := range versions {
+		bs.Set(v)
+	}
+	return bs
+}
+
+// snapshot returns the state of the entry, and false if none of its
+// aggregations has windows that have not been consumed yet.
+func (e *Entry) snapshot(key entryKey) (entrySnapshot, bool, error) {
+	e.mtx.RLock()
+	defer e.mtx.RUnlock()
+
+	if e.closed || len(e.aggregations) == 0 {
+		return entrySnapshot{}, false, nil
+	}
+	snapshot := entrySnapshot{
+		metricType:          key.metricType,
+		metricCategory:      key.metricCategory,
+		id:                  e.aggregations[0].elem.Value.(metricElem).ID(),
+		cutoverNanos:        e.cutoverNanos,
+		hasDefaultMetadatas: e.hasDefaultMetadatas,
+		aggregations:        make([]aggregationValueSnapshot, 0, len(e.aggregations)),
+	}
+	for _, agg := range e.aggregations {
+		elemState, err := agg.elem.Value.(metricElem).snapshot()
+		if err == errElemClosed {
+			continue
+		}
+		if err != nil {
+			return entrySnapshot{}, false, err
+		}
+		snapshot.aggregations = append(snapshot.aggregations, aggregationValueSnapshot{
+			key:           agg.key,
+			resendEnabled: agg.resendEnabled,
+			elem:          elemState,
+		})
+	}
+	return snapshot, snapshot.numWindows() > 0, nil
+}
+
+// restore recreates the aggregations of the entry from a snapshot.
+func (e *Entry) restore(snapshot entrySnapshot) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if e.closed {
+		return errEntryClosed
+	}
+	elemID := e.maybeCopyIDWithLock(snapshot.id)
+	for _, agg := range snapshot.aggregations {
+		listID, err := snapshotListID(agg)
+		if err != nil {
+			return err
+		}
+		e.aggregations, err = e.addNewAggregationKeyWithLock(metric.Type(snapshot.metricType), elemID,
+			agg.key, listID, e.aggregations, agg.resendEnabled)
+		if err != nil {
+			return err
+		}
+		value, idx := e.aggregations.get(agg.key)
+		if idx < 0 {
+			continue
+		}
+		if err := value.elem.Value.(metricElem).restore(agg.elem.windows); err != nil {
+			return err
+		}
+	}
+	e.cutoverNanos = snapshot.cutoverNanos
+	e.hasDefaultMetadatas = snapshot.hasDefaultMetadatas
+	return nil
+}
+
+func snapshotListID(agg aggregationValueSnapshot) (metricListID, error) {
+	resolution := agg.key.storagePolicy.Resolution().Window
+	switch agg.elem.listType {
+	case standardMetricListType:
+		return standardMetricListID{resolution: resolution}.toMetricListID(), nil
+	case forwardedMetricListType:
+		return forwardedMetricListID{
+			resolution:        resolution,
+			numForwardedTimes: agg.key.numForwardedTimes,
+		}.toMetricListID(), nil
+	case timedMetricListType:
+		return timedMetricListID{resolution: resolution}.toMetricListID(), nil
+	default:
+		return metricListID{}, fmt.Errorf("unknown metric list type: %v", agg.elem.listType)
+	}
+}
+
+// snapshot writes the state of all entries with aggregation windows that
+// have not been consumed yet, returning the number of entries written.
+func (m *metricMap) snapshot(w *shardSnapshotWriter) (int, error) {
+	// NB: hold the deletion lock so expired entries are not removed from the
+	// entry list while it is being iterated over.
+	m.entryListDelLock.Lock()
+	defer m.entryListDelLock.Unlock()
+
+	var (
+		numEntries int
+		err        error
+	)
+	m.forEachEntry(func(entry hashedEntry) {
+		if err != nil {
+			return
+		}
+		snapshot, ok, snapshotErr := entry.entry.snapshot(entry.key)
+		if snapshotErr != nil {
+			err = snapshotErr
+			return
+		}
+		if !ok {
+			return
+		}
+		if err = w.writeEntry(snapshot); err == nil {
+			numEntries++
+		}
+	})
+	return numEntries, err
+}
+
+// restore recreates the entries of a snapshot, bypassing the new metric
+// rate limit, returning the number of entries restored.
+func (m *metricMap) restore(r *shardSnapshotReader) (int, error) {
+	var numEntries int
+	for {
+		snapshot, ok, err := r.readEntry()
+		if err != nil {
+			return numEntries, err
+		}
+		if !ok {
+			return numEntries, nil
+		}
+		key := entryKey{
+			idHash:         hash.Murmur3Hash128(snapshot.id),
+			metricType:     snapshot.metricType,
+			metricCategory: snapshot.metricCategory,
+		}
+		entry, err := m.findOrCreateForRestore(key)
+		if err != nil {
+			return numEntries, err
+		}
+		err = entry.restore(snapshot)
+		entry.DecWriter()
+		if err != nil {
+			return numEntries, err
+		}
+		numEntries++
+	}
+}
+
+func (m *metricMap) findOrCreateForRestore(key entryKey) (*Entry, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.closed {
+		return nil, errMetricMapClosed
+	}
+	entry, found := m.lookupEntryWithLock(key)
+	if !found {
+		entry = m.entryPool.Get()
+		entry.ResetSetData(m.metricLists, m.runtimeOpts, m.opts)
+		m.entries[key] = m.entryList.PushBack(hashedEntry{
+			key:   key,
+			entry: entry,
+		})
+		m.metrics.newEntries.Inc(1)
+	}
+	entry.IncWriter()
+	return entry, nil
+}
+
+// shardSnapshotResult is the result of snapshotting or restoring a shard.
+type shardSnapshotResult struct {
+	numEntries int
+	numBytes   int64
+	snapshotAt time.Time
+}
+
+// snapshotFilePath returns the path of the snapshot file of a shard.
+func snapshotFilePath(dir string, shard uint32) string {
+	return filepath.Join(dir, "shard-"+strconv.Itoa(int(shard))+snapshotFileSuffix)
+}
+
+// Snapshot writes the aggregation state of the shard to its snapshot file
+// in the given directory, replacing the previous snapshot atomically.
+func (s *aggregatorShard) Snapshot(dir string) (shardSnapshotResult, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if s.closed {
+		return shardSnapshotResult{}, errAggregatorShardClosed
+	}
+
+	var (
+		path       = snapshotFilePath(dir, s.shard)
+		tmpPath    = path + ".tmp"
+		snapshotAt = s.nowFn()
+	)
+	fd, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return shardSnapshotResult{}, err
+	}
+	w := newShardSnapshotWriter(fd)
+	res, err := s.writeSnapshot(w, snapshotAt)
+	if err == nil {
+		err = fd.Sync()
+	}
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return shardSnapshotResult{}, err
+	}
+	return res, nil
+}
+
+func (s *aggregatorShard) writeSnapshot(
+	w *shardSnapshotWriter,
+	snapshotAt time.Time,
+) (shardSnapshotResult, error) {
+	if err := w.writeHeader(shardSnapshotHeader{
+		version:    snapshotFormatVersion,
+		shard:      s.shard,
+		snapshotAt: snapshotAt,
+	}); err != nil {
+		return shardSnapshotResult{}, err
+	}
+	numEntries, err := s.metricMap.snapshot(w)
+	if err != nil {
+		return shardSnapshotResult{}, err
+	}
+	if err := w.close(); err != nil {
+		return shardSnapshotResult{}, err
+	}
+	return shardSnapshotResult{
+		numEntries: numEntries,
+		numBytes:   w.numBytes,
+		snapshotAt: snapshotAt,
+	}, nil
+}
+
+// Restore restores the aggregation state of the shard from its snapshot
+// file in the given directory if it was taken no longer than maxAge ago.
+// It returns false if there is no snapshot or the snapshot is too old.
+func (s *aggregatorShard) Restore(dir string, maxAge time.Duration) (shardSnapshotResult, bool, error) {
+	data, err := os.ReadFile(snapshotFilePath(dir, s.shard))
+	if os.IsNotExist(err) {
+		return shardSnapshotResult{}, false, nil
+	}
+	if err != nil {
+		return shardSnapshotResult{}, false, err
+	}
+	r, err := newShardSnapshotReader(data)
+	if err != nil {
+		return shardSnapshotResult{}, false, err
+	}
+	header, err := r.readHeader()
+	if err != nil {
+		return shardSnapshotResult{}, false, err
+	}
+	if header.shard != s.shard {
+		return shardSnapshotResult{}, false, fmt.Errorf("%w: expected %d, actual %d",
+			errSnapshotShardMismatch, s.shard, header.shard)
+	}
+	res := shardSnapshotResult{
+		numBytes:   int64(len(data)),
+		snapshotAt: header.snapshotAt,
+	}
+	if s.nowFn().Sub(header.snapshotAt) > maxAge {
+		return res, false, nil
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+	if s.closed {
+		return res, false, errAggregatorShardClosed
+	}
+	res.numEntries, err = s.metricMap.restore(r)
+	return res, true, err
+}
+
+// shardSnapshotWriter encodes a shard snapshot, which consists of a header,
+// a sequence of length-prefixed entries terminated by an empty entry, and an
+// adler32 checksum of everything preceding it.
+type shardSnapshotWriter struct {
+	w        *bufio.Writer
+	digest   stdhash.Hash32
+	numBytes int64
+	buf      []byte
+	scratch  [binary.MaxVarintLen64]byte
+}
+
+func newShardSnapshotWriter(w io.Writer) *shardSnapshotWriter {
+	return &shardSnapshotWriter{
+		w:      bufio.NewWriter(w),
+		digest: adler32.New(),
+	}
+}
+
+func (w *shardSnapshotWriter) write(b []byte) error {
+	_, _ = w.digest.Write(b)
+	w.numBytes += int64(len(b))
+	_, err := w.w.Write(b)
+	return err
+}
+
+func (w *shardSnapshotWriter) writeHeader(header shardSnapshotHeader) error {
+	w.buf = append(w.buf[:0], snapshotMagic...)
+	w.buf = w.appendUvarint(w.buf, header.version)
+	w.buf = w.appendUvarint(w.buf, uint64(header.shard))
+	w.buf = w.appendTime(w.buf, header.snapshotAt)
+	return w.write(w.buf)
+}
+
+func (w *shardSnapshotWriter) writeEntry(snapshot entrySnapshot) error {
+	payload, err := w.encodeEntry(snapshot)
+	if err != nil {
+		return err
+	}
+	n := binary.PutUvarint(w.scratch[:], uint64(len(payload)))
+	if err := w.write(w.scratch[:n]); err != nil {
+		return err
+	}
+	return w.write(payload)
+}
+
+func (w *shardSnapshotWriter) close() error {
+	n := binary.PutUvarint(w.scratch[:], 0)
+	if err := w.write(w.scratch[:n]); err != nil {
+		return err
+	}
+	var checksum [snapshotChecksumSize]byte
+	binary.BigEndian.PutUint32(checksum[:], w.digest.Sum32())
+	w.numBytes += snapshotChecksumSize
+	if _, err := w.w.Write(checksum[:]); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *shardSnapshotWriter) encodeEntry(snapshot entrySnapshot) ([]byte, error) {
+	b := w.buf[:0]
+	b = w.appendUvarint(b, uint64(snapshot.metricType))
+	b = w.appendUvarint(b, uint64(snapshot.metricCategory))
+	b = w.appendBytes(b, snapshot.id)
+	b = w.appendVarint(b, snapshot.cutoverNanos)
+	b = w.appendBool(b, snapshot.hasDefaultMetadatas)
+	b = w.appendUvarint(b, uint64(len(snapshot.aggregations)))
+	for _, agg := range snapshot.aggregations {
+		var err error
+		if b, err = w.appendAggregationKey(b, agg.key); err != nil {
+			return nil, err
+		}
+		b = w.appendBool(b, agg.resendEnabled)
+		b = w.appendUvarint(b, uint64(agg.elem.listType))
+		b = w.appendUvarint(b, uint64(len(agg.elem.windows)))
+		for _, window := range agg.elem.windows {
+			if b, err = w.appendWindow(b, metric.Type(snapshot.metricType), window); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.buf = b
+	return b, nil
+}
+
+func (w *shardSnapshotWriter) appendAggregationKey(b []byte, key aggregationKey) ([]byte, error) {
+	b = w.appendUvarint(b, uint64(len(key.aggregationID)))
+	for _, v := range key.aggregationID {
+		b = w.appendUvarint(b, v)
+	}
+	var policyPB policypb.StoragePolicy
+	if err := key.storagePolicy.ToProto(&policyPB); err != nil {
+		return nil, err
+	}
+	policyBytes, err := policyPB.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	b = w.appendBytes(b, policyBytes)
+	var pipelinePB pipelinepb.AppliedPipeline
+	if err := key.pipeline.ToProto(&pipelinePB); err != nil {
+		return nil, err
+	}
+	pipelineBytes, err := pipelinePB.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	b = w.appendBytes(b, pipelineBytes)
+	b = w.appendUvarint(b, uint64(key.numForwardedTimes))
+	b = w.appendUvarint(b, uint64(key.idPrefixSuffixType))
+	return b, nil
+}
+
+func (w *shardSnapshotWriter) appendWindow(
+	b []byte,
+	metricType metric.Type,
+	window aggregationSnapshot,
+) ([]byte, error) {
+	b = w.appendVarint(b, int64(window.startAt))
+	b = w.appendVarint(b, int64(window.lastUpdatedAt))
+	b = w.appendBool(b, window.resendEnabled)
+	if window.sourcesSeen == nil {
+		b = w.appendUvarint(b, 0)
+	} else {
+		// NB: encode the number of sources plus one to distinguish an empty
+		// source set from an aggregation that does not track sources.
+		b = w.appendUvarint(b, uint64(len(window.sourcesSeen))+1)
+		for sourceID, versions := range window.sourcesSeen {
+			b = w.appendUvarint(b, uint64(sourceID))
+			b = w.appendUvarint(b, uint64(len(versions)))
+			for _, v := range versions {
+				b = w.appendUvarint(b, uint64(v))
+			}
+		}
+	}
+
+	state := window.state
+	switch {
+	case metricType == metric.CounterType && state.counter != nil:
+		c := state.counter
+		b = w.appendTime(b, c.LastAt)
+		b = w.appendBytes(b, c.Annotation)
+		b = w.appendVarint(b, c.Sum)
+		b = w.appendVarint(b, c.SumSq)
+		b = w.appendVarint(b, c.Count)
+		b = w.appendVarint(b, c.Max)
+		b = w.appendVarint(b, c.Min)
+	case metricType == metric.TimerType && state.timer != nil:
+		t := state.timer
+		b = w.appendTime(b, t.LastAt)
+		b = w.appendBytes(b, t.Annotation)
+		b = w.appendVarint(b, t.Count)
+		b = w.appendFloat64(b, t.Sum)
+		b = w.appendFloat64(b, t.SumSq)
+		b = w.appendUvarint(b, uint64(len(t.Samples)))
+		for _, sample := range t.Samples {
+			b = w.appendFloat64(b, sample.Value)
+			b = w.appendVarint(b, sample.NumRanks)
+			b = w.appendVarint(b, sample.Delta)
+		}
+	case metricType == metric.GaugeType && state.gauge != nil:
+		g := state.gauge
+		b = w.appendTime(b, g.LastAt)
+		b = w.appendBytes(b, g.Annotation)
+		b = w.appendFloat64(b, g.Sum)
+		b = w.appendFloat64(b, g.SumSq)
+		b = w.appendVarint(b, g.Count)
+		b = w.appendFloat64(b, g.Max)
+		b = w.appendFloat64(b, g.Min)
+		b = w.appendFloat64(b, g.Last)
+	default:
+		return nil, fmt.Errorf("unable to snapshot %v: %w", metricType, errNoAggregationState)
+	}
+	return b, nil
+}
+
+func (w *shardSnapshotWriter) appendUvarint(b []byte, v uint64) []byte {
+	n := binary.PutUvarint(w.scratch[:], v)
+	return append(b, w.scratch[:n]...)
+}
+
+func (w *shardSnapshotWriter) appendVarint(b []byte, v int64) []byte {
+	n := binary.PutVarint(w.scratch[:], v)
+	return append(b, w.scratch[:n]...)
+}
+
+func (w *shardSnapshotWriter) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+func (w *shardSnapshotWriter) appendFloat64(b []byte, v float64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], math.Float64bits(v))
+	return append(b, buf[:]...)
+}
+
+func (w *shardSnapshotWriter) appendBytes(b []byte, v []byte) []byte {
+	b = w.appendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func (w *shardSnapshotWriter) appendTime(b []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return w.appendVarint(b, 0)
+	}
+	return w.appendVarint(b, t.UnixNano())
+}
+
+// shardSnapshotReader decodes a shard snapshot written by shardSnapshotWriter.
+type shardSnapshotReader struct {
+	data []byte
+	err  error
+}
+
+func newShardSnapshotReader(data []byte) (*shardSnapshotReader, error) {
+	if len(data) < len(snapshotMagic)+snapshotChecksumSize {
+		return nil, errSnapshotTooShort
+	}
+	var (
+		payload  = data[:len(data)-snapshotChecksumSize]
+		expected = binary.BigEndian.Uint32(data[len(data)-snapshotChecksumSize:])
+	)
+	if adler32.Checksum(payload) != expected {
+		return nil, errSnapshotChecksumMismatch
+	}
+	return &shardSnapshotReader{data: payload}, nil
+}
+
+func (r *shardSnapshotReader) readHeader() (shardSnapshotHeader, error) {
+	if !bytes.HasPrefix(r.data, snapshotMagic) {
+		return shardSnapshotHeader{}, errSnapshotInvalidMagic
+	}
+	r.data = r.data[len(snapshotMagic):]
+	header := shardSnapshotHeader{version: r.uvarint()}
+	if r.err == nil && header.version > snapshotFormatVersion {
+		return shardSnapshotHeader{}, fmt.Errorf("%w: %d", errSnapshotUnsupportedFormat, header.version)
+	}
+	header.shard = uint32(r.uvarint())
+	header.snapshotAt = r.time()
+	return header, r.err
+}
+
+// readEntry reads the next entry, returning false once all entries are read.
+func (r *shardSnapshotReader) readEntry() (entrySnapshot, bool, error) {
+	size := r.uvarint()
+	if r.err != nil {
+		return entrySnapshot{}, false, r.err
+	}
+	if size == 0 {
+		return entrySnapshot{}, false, nil
+	}
+	if uint64(len(r.data)) < size {
+		return entrySnapshot{}, false, errSnapshotUnexpectedEOF
+	}
+	entry := &shardSnapshotReader{data: r.data[:size]}
+	r.data = r.data[size:]
+	snapshot := entry.entry()
+	return snapshot, true, entry.err
+}
+
+func (r *shardSnapshotReader) entry() entrySnapshot {
+	snapshot := entrySnapshot{
+		metricType:     metricType(r.uvarint()),
+		metricCategory: metricCategory(r.uvarint()),
+	}
+	// NB: copy the id so the entry does not hold on to the snapshot buffer.
+	snapshot.id = append(metricid.RawID(nil), r.bytes()...)
+	snapshot.cutoverNanos = r.varint()
+	snapshot.hasDefaultMetadatas = r.bool()
+	numAggregations := r.length()
+	snapshot.aggregations = make([]aggregationValueSnapshot, 0, numAggregations)
+	for i := 0; i < numAggregations && r.err == nil; i++ {
+		agg := aggregationValueSnapshot{key: r.aggregationKey()}
+		agg.resendEnabled = r.bool()
+		agg.elem.listType = metricListType(r.uvarint())
+		numWindows := r.length()
+		agg.elem.windows = make([]aggregationSnapshot, 0, numWindows)
+		for j := 0; j < numWindows && r.err == nil; j++ {
+			agg.elem.windows = append(agg.elem.windows, r.window(metric.Type(snapshot.metricType)))
+		}
+		snapshot.aggregations = append(snapshot.aggregations, agg)
+	}
+	return snapshot
+}
+
+func (r *shardSnapshotReader) aggregationKey() aggregationKey {
+	var key aggregationKey
+	numWords := r.length()
+	if r.err == nil && numWords != len(key.aggregationID) {
+		r.err = fmt.Errorf("unexpected aggregation id length: %d", numWords)
+		return key
+	}
+	for i := 0; i < numWords && r.err == nil; i++ {
+		key.aggregationID[i] = r.uvarint()
+	}
+	var policyPB policypb.StoragePolicy
+	if err := policyPB.Unmarshal(r.bytes()); err != nil && r.err == nil {
+		r.err = err
+	}
+	if err := key.storagePolicy.FromProto(policyPB); err != nil && r.err == nil {
+		r.err = err
+	}
+	var pipelinePB pipelinepb.AppliedPipeline
+	if err := pipelinePB.Unmarshal(r.bytes()); err != nil && r.err == nil {
+		r.err = err
+	}
+	if err := key.pipeline.FromProto(pipelinePB); err != nil && r.err == nil {
+		r.err = err
+	}
+	key.numForwardedTimes = int(r.uvarint())
+	key.idPrefixSuffixType = IDPrefixSuffixType(r.uvarint())
+	return key
+}
+
+func (r *shardSnapshotReader) window(metricType metric.Type) aggregationSnapshot {
+	window := aggregationSnapshot{
+		startAt:       xtime.UnixNano(r.varint()),
+		lastUpdatedAt: xtime.UnixNano(r.varint()),
+		resendEnabled: r.bool(),
+	}
+	if numSources := r.length(); numSources > 0 {
+		window.sourcesSeen = make(map[uint32][]uint, numSources-1)
+		for i := 0; i < numSources-1 && r.err == nil; i++ {
+			sourceID := uint32(r.uvarint())
+			numVersions := r.length()
+			versions := make([]uint, 0, numVersions)
+			for j := 0; j < numVersions && r.err == nil; j++ {
+				versions = append(versions, uint(r.uvarint()))
+			}
+			window.sourcesSeen[sourceID] = versions
+		}
+	}
+
+	switch metricType {
+	case metric.CounterType:
+		window.state.counter = &raggregation.CounterState{
+			LastAt:     r.time(),
+			Annotation: r.annotation(),
+			Sum:        r.varint(),
+			SumSq:      r.varint(),
+			Count:      r.varint(),
+			Max:        r.varint(),
+			Min:        r.varint(),
+		}
+	case metric.TimerType:
+		state := &raggregation.TimerState{
+			LastAt:     r.time(),
+			Annotation: r.annotation(),
+			Count:      r.varint(),
+			Sum:        r.float64(),
+			SumSq:      r.float64(),
+		}
+		numSamples := r.length()
+		state.Samples = make([]cm.SampleState, 0, numSamples)
+		for i := 0; i < numSamples && r.err == nil; i++ {
+			state.Samples = append(state.Samples, cm.SampleState{
+				Value:    r.float64(),
+				NumRanks: r.varint(),
+				Delta:    r.varint(),
+			})
+		}
+		window.state.timer = state
+	case metric.GaugeType:
+		window.state.gauge = &raggregation.GaugeState{
+			LastAt:     r.time(),
+			Annotation: r.annotation(),
+			Sum:        r.float64(),
+			SumSq:      r.float64(),
+			Count:      r.varint(),
+			Max:        r.float64(),
+			Min:        r.float64(),
+			Last:       r.float64(),
+		}
+	default:
+		if r.err == nil {
+			r.err = errInvalidMetricType
+		}
+	}
+	return window
+}
+
+func (r *shardSnapshotReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errSnapshotUnexpectedEOF
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *shardSnapshotReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = errSnapshotUnexpectedEOF
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+// length reads a length, bounded by the remaining data to guard against
+// allocating huge buffers for corrupt snapshots.
+func (r *shardSnapshotReader) length() int {
+	v := r.uvarint()
+	if r.err == nil && v > uint64(len(r.data))+1 {
+		r.err = errSnapshotUnexpectedEOF
+		return 0
+	}
+	return int(v)
+}
+
+func (r *shardSnapshotReader) bool() bool {
+	if r.err != nil {
+		return false
+	}
+	if len(r.data) < 1 {
+		r.err = errSnapshotUnexpectedEOF
+		return false
+	}
+	v := r.data[0] != 0
+	r.data = r.data[1:]
+	return v
+}
+
+func (r *shardSnapshotReader) float64() float64 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) < 8 {
+		r.err = errSnapshotUnexpectedEOF
+		return 0
+	}
+	v := math.Float64frombits(binary.BigEndian.Uint64(r.data))
+	r.data = r.data[8:]
+	return v
+}
+
+func (r *shardSnapshotReader) bytes() []byte {
+	size := r.length()
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < size {
+		r.err = errSnapshotUnexpectedEOF
+		return nil
+	}
+	v := r.data[:size]
+	r.data = r.data[size:]
+	return v
+}
+
+func (r *shardSnapshotReader) annotation() []byte {
+	v := r.bytes()
+	if len(v) == 0 {
+		return nil
+	}
+	return append([]byte(nil), v...)
+}
+
+func (r *shardSnapshotReader) time() time.Time {
+	nanos := r.varint()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
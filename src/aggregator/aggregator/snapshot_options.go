@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	defaultSnapshotInterval = 10 * time.Second
+	defaultSnapshotMaxAge   = 2 * time.Minute
+)
+
+var (
+	errNoSnapshotDirectory       = errors.New("snapshot directory is not set")
+	errNonPositiveSnapshotPeriod = errors.New("snapshot interval must be positive")
+)
+
+// SnapshotOptions provide a set of options for snapshotting the in-memory
+// aggregation state of each shard to local disk so it survives restarts.
+type SnapshotOptions interface {
+	// Validate validates the snapshot options.
+	Validate() error
+
+	// SetEnabled sets whether snapshotting is enabled.
+	SetEnabled(value bool) SnapshotOptions
+
+	// Enabled returns whether snapshotting is enabled.
+	Enabled() bool
+
+	// SetDirectory sets the directory snapshots are written to.
+	SetDirectory(value string) SnapshotOptions
+
+	// Directory returns the directory snapshots are written to.
+	Directory() string
+
+	// SetInterval sets the interval between snapshots.
+	SetInterval(value time.Duration) SnapshotOptions
+
+	// Interval returns the interval between snapshots.
+	Interval() time.Duration
+
+	// SetMaxAge sets the maximum age of a snapshot for it to be restored on
+	// startup. Older snapshots are ignored since their aggregation windows
+	// have most likely been flushed by another instance.
+	SetMaxAge(value time.Duration) SnapshotOptions
+
+	// MaxAge returns the maximum age of a snapshot for it to be restored on startup.
+	MaxAge() time.Duration
+}
+
+type snapshotOptions struct {
+	enabled   bool
+	directory string
+	interval  time.Duration
+	maxAge    time.Duration
+}
+
+// NewSnapshotOptions creates a new set of snapshot options.
+func NewSnapshotOptions() SnapshotOptions {
+	return &snapshotOptions{
+		interval: defaultSnapshotInterval,
+		maxAge:   defaultSnapshotMaxAge,
+	}
+}
+
+func (o *snapshotOptions) Validate() error {
+	if !o.enabled {
+		return nil
+	}
+	if o.directory == "" {
+		return errNoSnapshotDirectory
+	}
+	if o.interval <= 0 {
+		return errNonPositiveSnapshotPeriod
+	}
+	return nil
+}
+
+func (o *snapshotOptions) SetEnabled(value bool) SnapshotOptions {
+	opts := *o
+	opts.enabled = value
+	return &opts
+}
+
+func (o *snapshotOptions) Enabled() bool {
+	return o.enabled
+}
+
+func (o *snapshotOptions) SetDirectory(value string) SnapshotOptions {
+	opts := *o
+	opts.directory = value
+	return &opts
+}
+
+func (o *snapshotOptions) Directory() string {
+	return o.directory
+}
+
+func (o *snapshotOptions) SetInterval(value time.Duration) SnapshotOptions {
+	opts := *o
+	opts.interval = value
+	return &opts
+}
+
+func (o *snapshotOptions) Interval() time.Duration {
+	return o.interval
+}
+
+func (o *snapshotOptions) SetMaxAge(value time.Duration) SnapshotOptions {
+	opts := *o
+	opts.maxAge = value
+	return &opts
+}
+
+func (o *snapshotOptions) MaxAge() time.Duration {
+	return o.maxAge
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregator
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregatorShardSnapshotRestore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testOptions(ctrl)
+	shard := newAggregatorShard(testShard, opts)
+	require.NoError(t, shard.AddUntimed(testCounter, testDefaultStagedMetadatas))
+	require.NoError(t, shard.AddUntimed(testBatchTimer, testDefaultStagedMetadatas))
+	require.NoError(t, shard.AddUntimed(testGauge, testDefaultStagedMetadatas))
+	require.NoError(t, shard.AddForwarded(testForwardedMetric, testForwardMetadata))
+
+	dir := testSnapshotDir(t)
+	defer os.RemoveAll(dir)
+	res, err := shard.Snapshot(dir)
+	require.NoError(t, err)
+	require.Equal(t, 4, res.numEntries)
+	require.True(t, res.numBytes > 0)
+
+	info, err := os.Stat(snapshotFilePath(dir, testShard))
+	require.NoError(t, err)
+	require.Equal(t, res.numBytes, info.Size())
+
+	restored := newAggregatorShard(testShard, opts)
+	restoredRes, ok, err := restored.Restore(dir, time.Hour)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 4, restoredRes.numEntries)
+	require.Equal(t, res.snapshotAt.UnixNano(), restoredRes.snapshotAt.UnixNano())
+
+	expected := testEncodedEntrySnapshots(t, shard.metricMap)
+	require.Equal(t, 4, len(expected))
+	require.Equal(t, expected, testEncodedEntrySnapshots(t, restored.metricMap))
+
+	// Forwarded metrics already seen before the snapshot are deduplicated, the
+	// entry swallows duplicates so the restored state must be left untouched.
+	require.NoError(t, restored.AddForwarded(testForwardedMetric, testForwardMetadata))
+	require.Equal(t, expected, testEncodedEntrySnapshots(t, restored.metricMap))
+}
+
+func TestAggregatorShardRestoreStaleSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testOptions(ctrl)
+	shard := newAggregatorShard(testShard, opts)
+	require.NoError(t, shard.AddUntimed(testCounter, testDefaultStagedMetadatas))
+
+	dir := testSnapshotDir(t)
+	defer os.RemoveAll(dir)
+	_, err := shard.Snapshot(dir)
+	require.NoError(t, err)
+
+	restored := newAggregatorShard(testShard, opts)
+	restored.nowFn = func() time.Time { return time.Now().Add(time.Hour) }
+	res, ok, err := restored.Restore(dir, time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.False(t, res.snapshotAt.IsZero())
+	require.Equal(t, 0, len(restored.metricMap.entries))
+}
+
+func TestAggregatorShardRestoreNoSnapshot(t *testing.T) {
+	shard := newAggregatorShard(testShard, newTestOptions())
+	dir := testSnapshotDir(t)
+	defer os.RemoveAll(dir)
+
+	res, ok, err := shard.Restore(dir, time.Hour)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.True(t, res.snapshotAt.IsZero())
+}
+
+func TestAggregatorShardRestoreCorruptSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testOptions(ctrl)
+	shard := newAggregatorShard(testShard, opts)
+	require.NoError(t, shard.AddUntimed(testCounter, testDefaultStagedMetadatas))
+
+	dir := testSnapshotDir(t)
+	defer os.RemoveAll(dir)
+	_, err := shard.Snapshot(dir)
+	require.NoError(t, err)
+
+	path := snapshotFilePath(dir, testShard)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(path, data, 0o644))
+
+	restored := newAggregatorShard(testShard, opts)
+	_, ok, err := restored.Restore(dir, time.Hour)
+	require.Equal(t, errSnapshotChecksumMismatch, err)
+	require.False(t, ok)
+	require.Equal(t, 0, len(restored.metricMap.entries))
+}
+
+func TestShardSnapshotReaderUnsupportedVersion(t *testing.T) {
+	var buf bytes.Buffer
+	w := newShardSnapshotWriter(&buf)
+	require.NoError(t, w.writeHeader(shardSnapshotHeader{
+		version:    snapshotFormatVersion + 1,
+		shard:      testShard,
+		snapshotAt: time.Now(),
+	}))
+	require.NoError(t, w.close())
+
+	r, err := newShardSnapshotReader(buf.Bytes())
+	require.NoError(t, err)
+	_, err = r.readHeader()
+	require.True(t, errors.Is(err, errSnapshotUnsupportedFormat))
+}
+
+func testSnapshotDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	return dir
+}
+
+func testEncodedEntrySnapshots(t *testing.T, m *metricMap) map[entryKey][]byte {
+	res := make(map[entryKey][]byte)
+	m.forEachEntry(func(entry hashedEntry) {
+		snapshot, ok, err := entry.entry.snapshot(entry.key)
+		require.NoError(t, err)
+		if !ok {
+			return
+		}
+		for _, agg := range snapshot.aggregations {
+			windows := agg.elem.windows
+			sort.Slice(windows, func(i, j int) bool {
+				return windows[i].startAt < windows[j].startAt
+			})
+		}
+		b, err := newShardSnapshotWriter(ioutil.Discard).encodeEntry(snapshot)
+		require.NoError(t, err)
+		res[entry.key] = append([]byte(nil), b...)
+	})
+	return res
+}
@@ -215,6 +215,7 @@ type ConnectionConfiguration struct {
 	ReconnectThresholdMultiplier int                  `yaml:"reconnectThresholdMultiplier"`
 	MaxReconnectDuration         *time.Duration       `yaml:"maxReconnectDuration"`
 	WriteRetries                 *retry.Configuration `yaml:"writeRetries"`
+	ProtocolHandshake            *bool                `yaml:"protocolHandshake"`
 }
 
 // NewConnectionOptions creates new connection options.
@@ -245,6 +246,9 @@ func (c *ConnectionConfiguration) NewConnectionOptions(scope tally.Scope) Connec
 		retryOpts := c.WriteRetries.NewOptions(scope)
 		opts = opts.SetWriteRetryOptions(retryOpts)
 	}
+	if c.ProtocolHandshake != nil {
+		opts = opts.SetProtocolHandshake(*c.ProtocolHandshake)
+	}
 	return opts
 }
 
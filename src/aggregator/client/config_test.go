@@ -70,6 +70,7 @@ queueDropType: oldest
 connection:
   connectionTimeout: 1s
   connectionKeepAlive: true
+  protocolHandshake: true
   writeTimeout: 1s
   initReconnectThreshold: 2
   maxReconnectThreshold: 5000
@@ -110,6 +111,7 @@ func TestConfigUnmarshal(t *testing.T) {
 	require.Equal(t, DropOldest, *cfg.QueueDropType)
 	require.Equal(t, time.Second, cfg.Connection.ConnectionTimeout)
 	require.Equal(t, true, *cfg.Connection.ConnectionKeepAlive)
+	require.Equal(t, true, *cfg.Connection.ProtocolHandshake)
 	require.Equal(t, time.Second, cfg.Connection.WriteTimeout)
 	require.Equal(t, 2, cfg.Connection.InitReconnectThreshold)
 	require.Equal(t, 5000, cfg.Connection.MaxReconnectThreshold)
@@ -160,6 +162,7 @@ func TestNewClientOptions(t *testing.T) {
 	require.Equal(t, DropOldest, opts.QueueDropType())
 	require.Equal(t, time.Second, opts.ConnectionOptions().ConnectionTimeout())
 	require.Equal(t, true, opts.ConnectionOptions().ConnectionKeepAlive())
+	require.Equal(t, true, opts.ConnectionOptions().ProtocolHandshake())
 	require.Equal(t, time.Second, opts.ConnectionOptions().WriteTimeout())
 	require.Equal(t, 2, opts.ConnectionOptions().InitReconnectThreshold())
 	require.Equal(t, 5000, opts.ConnectionOptions().MaxReconnectThreshold())
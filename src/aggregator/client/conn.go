@@ -21,13 +21,18 @@
 package client
 
 import (
+	"bufio"
 	"context"
 	"errors"
+	"io"
 	"math/rand"
 	"net"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/m3db/m3/src/metrics/encoding"
+	"github.com/m3db/m3/src/metrics/encoding/protobuf"
 	"github.com/m3db/m3/src/x/clock"
 	xio "github.com/m3db/m3/src/x/io"
 	xnet "github.com/m3db/m3/src/x/net"
@@ -41,9 +46,11 @@ const (
 )
 
 var (
-	errNoActiveConnection = errors.New("no active connection")
-	errInvalidConnection  = errors.New("connection is invalid")
-	uninitWriter          uninitializedWriter
+	errNoActiveConnection      = errors.New("no active connection")
+	errInvalidConnection       = errors.New("connection is invalid")
+	errNoCommonProtocolVersion = errors.New("no protocol version supported by both client and server")
+	errUnexpectedHandshake     = errors.New("unexpected reply to protocol handshake")
+	uninitWriter               uninitializedWriter
 )
 
 type (
@@ -58,6 +65,7 @@ type connection struct {
 	metrics                 connectionMetrics
 	writeRetryOpts          retry.Options
 	writer                  xio.ResettableWriter
+	rwOpts                  xio.Options
 	connectWithLockFn       connectWithLockFn
 	sleepFn                 sleepFn
 	nowFn                   clock.NowFn
@@ -76,6 +84,7 @@ type connection struct {
 	numFailures             int
 	mtx                     sync.Mutex
 	keepAlive               bool
+	handshake               bool
 	dialer                  xnet.ContextDialerFn
 }
 
@@ -86,6 +95,7 @@ func newConnection(addr string, opts ConnectionOptions) *connection {
 		connTimeout:    opts.ConnectionTimeout(),
 		writeTimeout:   opts.WriteTimeout(),
 		keepAlive:      opts.ConnectionKeepAlive(),
+		handshake:      opts.ProtocolHandshake(),
 		initThreshold:  opts.InitReconnectThreshold(),
 		multiplier:     opts.ReconnectThresholdMultiplier(),
 		maxThreshold:   opts.MaxReconnectThreshold(),
@@ -96,6 +106,7 @@ func newConnection(addr string, opts ConnectionOptions) *connection {
 		nowFn:          opts.ClockOptions().NowFn(),
 		sleepFn:        time.Sleep,
 		threshold:      opts.InitReconnectThreshold(),
+		rwOpts:         opts.RWOptions(),
 		writer: opts.RWOptions().ResettableWriterFn()(
 			uninitWriter,
 			xio.ResettableWriterOptions{WriteBufferSize: 0},
@@ -184,6 +195,30 @@ func (c *connection) connectWithLock() error {
 		return err
 	}
 
+	version := encoding.LegacyProtocolVersion
+	if c.handshake {
+		reply, err := c.handshakeWithLock(conn)
+		switch {
+		case err == nil && reply.Version == 0:
+			conn.Close() // nolint: errcheck
+			c.metrics.handshakeError.Inc(1)
+			return errNoCommonProtocolVersion
+		case err == nil:
+			version = reply.Version
+		default:
+			// NB: servers that predate protocol negotiation close connections
+			// starting with a handshake, fall back to the legacy protocol on a
+			// new connection.
+			conn.Close() // nolint: errcheck
+			c.metrics.handshakeFallback.Inc(1)
+			if conn, err = c.dialContext(ctx, c.addr); err != nil {
+				c.metrics.connectError.Inc(1)
+				return err
+			}
+		}
+	}
+	c.metrics.connections(version).Inc(1)
+
 	// N.B.: If using a custom dialer which doesn't return *net.TCPConn, users are responsible for TCP keep alive options
 	// themselves.
 	if tcpConn, ok := conn.(keepAlivable); ok {
@@ -216,6 +251,55 @@ func (c *connection) dialContext(ctx context.Context, addr string) (net.Conn, er
 	return dialer.DialContext(ctx, tcpProtocol, addr)
 }
 
+// handshakeWithLock negotiates the protocol version of the connection with
+// the server and returns the reply of the server.
+func (c *connection) handshakeWithLock(conn net.Conn) (encoding.ProtocolHandshake, error) {
+	var reply encoding.ProtocolHandshake
+	if err := conn.SetDeadline(c.nowFn().Add(c.connTimeout)); err != nil {
+		return reply, err
+	}
+
+	enc := protobuf.NewUnaggregatedEncoder(protobuf.NewUnaggregatedOptions())
+	if err := enc.EncodeMessage(encoding.UnaggregatedMessageUnion{
+		Type: encoding.ProtocolHandshakeType,
+		ProtocolHandshake: encoding.ProtocolHandshake{
+			MinVersion:   encoding.LegacyProtocolVersion,
+			MaxVersion:   encoding.CurrentProtocolVersion,
+			Capabilities: encoding.DefaultCapabilities(),
+		},
+	}); err != nil {
+		return reply, err
+	}
+	buf := enc.Relinquish()
+	defer buf.Close()
+
+	w := c.rwOpts.ResettableWriterFn()(conn, xio.ResettableWriterOptions{})
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return reply, err
+	}
+	if err := w.Flush(); err != nil {
+		return reply, err
+	}
+
+	r := c.rwOpts.ResettableReaderFn()(conn, xio.ResettableReaderOptions{})
+	it := protobuf.NewUnaggregatedIterator(bufio.NewReader(r), protobuf.NewUnaggregatedOptions())
+	defer it.Close()
+
+	if !it.Next() {
+		if err := it.Err(); err != nil {
+			return reply, err
+		}
+		return reply, io.EOF
+	}
+	if it.Current().Type != encoding.ProtocolHandshakeType {
+		return reply, errUnexpectedHandshake
+	}
+	reply = it.Current().ProtocolHandshake
+
+	// NB: writes set their own deadline, the handshake is the only read.
+	return reply, conn.SetDeadline(time.Time{})
+}
+
 func (c *connection) checkReconnectWithLock() error {
 	// If we haven't accumulated enough failures to warrant another reconnect
 	// and we haven't past the maximum duration since the last time we attempted
@@ -283,6 +367,9 @@ type connectionMetrics struct {
 	writeRetries          tally.Counter
 	setKeepAliveError     tally.Counter
 	setWriteDeadlineError tally.Counter
+	handshakeError        tally.Counter
+	handshakeFallback     tally.Counter
+	scope                 tally.Scope
 }
 
 func newConnectionMetrics(scope tally.Scope) connectionMetrics {
@@ -296,9 +383,21 @@ func newConnectionMetrics(scope tally.Scope) connectionMetrics {
 			Counter(errorMetric),
 		setWriteDeadlineError: scope.Tagged(map[string]string{errorMetricType: "set-write-deadline"}).
 			Counter(errorMetric),
+		handshakeError: scope.Tagged(map[string]string{errorMetricType: "handshake"}).
+			Counter(errorMetric),
+		handshakeFallback: scope.Counter("handshake-fallback"),
+		scope:             scope,
 	}
 }
 
+// connections returns the counter of connections established with the
+// protocol version.
+func (m connectionMetrics) connections(version uint32) tally.Counter {
+	return m.scope.Tagged(map[string]string{
+		"protocol-version": strconv.FormatUint(uint64(version), 10),
+	}).Counter("connections")
+}
+
 type uninitializedWriter struct{}
 
 func (u uninitializedWriter) Write(p []byte) (int, error) { return 0, errInvalidConnection }
@@ -43,6 +43,7 @@ const (
 	defaultWriteRetryMaxBackoff         = time.Second
 	defaultWriteRetryMaxRetries         = 1
 	defaultWriteRetryJitterEnabled      = true
+	defaultProtocolHandshake            = false
 )
 
 // ConnectionOptions provides a set of options for tcp connections.
@@ -123,6 +124,15 @@ type ConnectionOptions interface {
 	ContextDialer() xnet.ContextDialerFn
 	// SetContextDialer sets ContextDialer() -- see that method.
 	SetContextDialer(dialer xnet.ContextDialerFn) ConnectionOptions
+
+	// SetProtocolHandshake sets whether to negotiate the protocol version with
+	// the server when establishing connections, servers that do not support
+	// negotiation fall back to the legacy protocol.
+	SetProtocolHandshake(value bool) ConnectionOptions
+
+	// ProtocolHandshake returns whether to negotiate the protocol version with
+	// the server when establishing connections.
+	ProtocolHandshake() bool
 }
 
 type connectionOptions struct {
@@ -137,6 +147,7 @@ type connectionOptions struct {
 	maxThreshold   int
 	multiplier     int
 	connKeepAlive  bool
+	handshake      bool
 	dialer         xnet.ContextDialerFn
 }
 
@@ -160,6 +171,7 @@ func NewConnectionOptions() ConnectionOptions {
 		maxDuration:    defaultMaxReconnectDuration,
 		writeRetryOpts: defaultWriteRetryOpts,
 		rwOpts:         xio.NewOptions(),
+		handshake:      defaultProtocolHandshake,
 		dialer:         nil, // Will default to net.Dialer{}.DialContext
 	}
 }
@@ -284,3 +296,13 @@ func (o *connectionOptions) SetContextDialer(dialer xnet.ContextDialerFn) Connec
 	opts.dialer = dialer
 	return &opts
 }
+
+func (o *connectionOptions) SetProtocolHandshake(value bool) ConnectionOptions {
+	opts := *o
+	opts.handshake = value
+	return &opts
+}
+
+func (o *connectionOptions) ProtocolHandshake() bool {
+	return o.handshake
+}
@@ -21,16 +21,21 @@
 package client
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/metrics/encoding"
+	"github.com/m3db/m3/src/metrics/encoding/protobuf"
 	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
 
 	"github.com/golang/mock/gomock"
 	"github.com/leanovate/gopter"
@@ -38,6 +43,7 @@ import (
 	"github.com/leanovate/gopter/prop"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 const (
@@ -405,6 +411,113 @@ func TestConnectWriteToServer(t *testing.T) {
 	require.Nil(t, conn.conn)
 }
 
+func TestConnectProtocolHandshake(t *testing.T) {
+	data := []byte("foobar")
+
+	l, err := net.Listen(tcpProtocol, testLocalServerAddr)
+	require.NoError(t, err)
+	defer l.Close() // nolint: errcheck
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		conn, err := l.Accept()
+		require.NoError(t, err)
+		defer conn.Close() // nolint: errcheck
+
+		r := bufio.NewReader(conn)
+		it := protobuf.NewUnaggregatedIterator(r, protobuf.NewUnaggregatedOptions())
+		require.True(t, it.Next())
+		msg := it.Current()
+		require.Equal(t, encoding.ProtocolHandshakeType, msg.Type)
+		require.Equal(t, encoding.LegacyProtocolVersion, msg.ProtocolHandshake.MinVersion)
+		require.Equal(t, encoding.CurrentProtocolVersion, msg.ProtocolHandshake.MaxVersion)
+
+		enc := protobuf.NewUnaggregatedEncoder(protobuf.NewUnaggregatedOptions())
+		require.NoError(t, enc.EncodeMessage(encoding.UnaggregatedMessageUnion{
+			Type: encoding.ProtocolHandshakeType,
+			ProtocolHandshake: encoding.NegotiateProtocol(
+				msg.ProtocolHandshake,
+				encoding.LegacyProtocolVersion,
+				encoding.CurrentProtocolVersion,
+				encoding.DefaultCapabilities(),
+			),
+		}))
+		_, err = conn.Write(enc.Relinquish().Bytes())
+		require.NoError(t, err)
+
+		// The client writes immediately after the handshake.
+		buf := make([]byte, len(data))
+		_, err = io.ReadFull(r, buf)
+		require.NoError(t, err)
+		require.Equal(t, data, buf)
+	}()
+
+	scope := tally.NewTestScope("", nil)
+	opts := testConnectionOptions().
+		SetProtocolHandshake(true).
+		SetConnectionTimeout(time.Minute).
+		SetInitReconnectThreshold(0).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+	conn := newConnection(l.Addr().String(), opts)
+	require.NoError(t, conn.Write(data))
+	wg.Wait()
+	conn.Close()
+
+	counters := scope.Snapshot().Counters()
+	c, ok := counters["connections+protocol-version=2"]
+	require.True(t, ok)
+	require.Equal(t, int64(1), c.Value())
+}
+
+func TestConnectProtocolHandshakeFallback(t *testing.T) {
+	data := []byte("foobar")
+
+	l, err := net.Listen(tcpProtocol, testLocalServerAddr)
+	require.NoError(t, err)
+	defer l.Close() // nolint: errcheck
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		// Servers that do not understand the handshake close the connection.
+		conn, err := l.Accept()
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+
+		conn, err = l.Accept()
+		require.NoError(t, err)
+		defer conn.Close() // nolint: errcheck
+		buf := make([]byte, len(data))
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		require.Equal(t, data, buf)
+	}()
+
+	scope := tally.NewTestScope("", nil)
+	opts := testConnectionOptions().
+		SetProtocolHandshake(true).
+		SetConnectionTimeout(time.Minute).
+		SetInitReconnectThreshold(0).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+	conn := newConnection(l.Addr().String(), opts)
+	require.NoError(t, conn.Write(data))
+	wg.Wait()
+	conn.Close()
+
+	counters := scope.Snapshot().Counters()
+	c, ok := counters["handshake-fallback+"]
+	require.True(t, ok)
+	require.Equal(t, int64(1), c.Value())
+	c, ok = counters["connections+protocol-version=1"]
+	require.True(t, ok)
+	require.Equal(t, int64(1), c.Value())
+}
+
 func testConnectionOptions() ConnectionOptions {
 	return NewConnectionOptions().
 		SetClockOptions(clock.NewOptions()).
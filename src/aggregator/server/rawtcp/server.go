@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -46,6 +47,7 @@ import (
 
 const (
 	unknownRemoteHostAddress = "<unknown>"
+	protocolVersionTag       = "protocol-version"
 )
 
 // NewServer creates a new raw TCP server.
@@ -62,9 +64,11 @@ type handlerMetrics struct {
 	addTimedErrors           tally.Counter
 	addForwardedErrors       tally.Counter
 	addPassthroughErrors     tally.Counter
+	handshakeErrors          tally.Counter
 	unknownErrorTypeErrors   tally.Counter
 	decodeErrors             tally.Counter
 	errLogRateLimited        tally.Counter
+	scope                    tally.Scope
 }
 
 func newHandlerMetrics(scope tally.Scope) handlerMetrics {
@@ -74,12 +78,22 @@ func newHandlerMetrics(scope tally.Scope) handlerMetrics {
 		addTimedErrors:           scope.Counter("add-timed-errors"),
 		addForwardedErrors:       scope.Counter("add-forwarded-errors"),
 		addPassthroughErrors:     scope.Counter("add-passthrough-errors"),
+		handshakeErrors:          scope.Counter("handshake-errors"),
 		unknownErrorTypeErrors:   scope.Counter("unknown-error-type-errors"),
 		decodeErrors:             scope.Counter("decode-errors"),
 		errLogRateLimited:        scope.Counter("error-log-rate-limited"),
+		scope:                    scope,
 	}
 }
 
+// connections returns the counter of connections using the protocol version,
+// a zero version is a handshake without any version supported by both sides.
+func (m handlerMetrics) connections(version uint32) tally.Counter {
+	return m.scope.Tagged(map[string]string{
+		protocolVersionTag: strconv.FormatUint(uint64(version), 10),
+	}).Counter("connections")
+}
+
 type handler struct {
 	sync.Mutex
 
@@ -135,10 +149,19 @@ func (s *handler) Handle(conn net.Conn) {
 		timedMetadata       metadata.TimedMetadata
 		passthroughMetric   aggregated.Metric
 		passthroughMetadata policy.StoragePolicy
+		handshake           encoding.ProtocolHandshake
+		firstMessage        = true
 		err                 error
 	)
 	for it.Next() {
 		current := it.Current()
+		if firstMessage && current.Type != encoding.ProtocolHandshakeType {
+			// NB: clients that predate protocol negotiation start sending
+			// metrics right away.
+			s.metrics.connections(encoding.LegacyProtocolVersion).Inc(1)
+		}
+		firstMessage = false
+
 		switch current.Type {
 		case encoding.CounterWithMetadatasType:
 			untimedMetric = current.CounterWithMetadatas.Counter.ToUnion()
@@ -175,6 +198,9 @@ func (s *handler) Handle(conn net.Conn) {
 			passthroughMetric.Annotation = current.PassthroughMetricWithMetadata.Annotation
 			passthroughMetadata = current.PassthroughMetricWithMetadata.StoragePolicy
 			err = s.aggregator.AddPassthrough(passthroughMetric, passthroughMetadata)
+		case encoding.ProtocolHandshakeType:
+			handshake = current.ProtocolHandshake
+			err = s.handshake(conn, handshake)
 		default:
 			err = newUnknownMessageTypeError(current.Type)
 		}
@@ -241,6 +267,14 @@ func (s *handler) Handle(conn net.Conn) {
 					zap.Float64("value", timedMetric.Value),
 					zap.Error(err),
 				)
+			case encoding.ProtocolHandshakeType:
+				s.metrics.handshakeErrors.Inc(1)
+				s.log.Error("error replying to protocol handshake",
+					zap.String("remoteAddress", remoteAddress),
+					zap.Uint32("minVersion", handshake.MinVersion),
+					zap.Uint32("maxVersion", handshake.MaxVersion),
+					zap.Error(err),
+				)
 			default:
 				// make the linter happy.
 				s.log.Error("unknown message type for error. this cannot happen")
@@ -259,6 +293,37 @@ func (s *handler) Handle(conn net.Conn) {
 	}
 }
 
+// handshake replies to the protocol handshake of a client with the protocol
+// version and capabilities to use on the connection.
+func (s *handler) handshake(conn net.Conn, h encoding.ProtocolHandshake) error {
+	reply := encoding.NegotiateProtocol(
+		h,
+		encoding.LegacyProtocolVersion,
+		encoding.CurrentProtocolVersion,
+		encoding.DefaultCapabilities(),
+	)
+	s.metrics.connections(reply.Version).Inc(1)
+
+	enc := protobuf.NewUnaggregatedEncoder(s.protobufItOpts)
+	if err := enc.EncodeMessage(encoding.UnaggregatedMessageUnion{
+		Type:              encoding.ProtocolHandshakeType,
+		ProtocolHandshake: reply,
+	}); err != nil {
+		return err
+	}
+
+	buf := enc.Relinquish()
+	defer buf.Close()
+
+	// NB: the reply is written the same way the client writes to the
+	// connection, which may be compressed.
+	w := s.opts.RWOptions().ResettableWriterFn()(conn, xio.ResettableWriterOptions{})
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
 func (s *handler) Close() {
 	// NB(cw) Do not close s.aggregator here because it's shared between
 	// the raw TCP server and the http server, and it will be closed on
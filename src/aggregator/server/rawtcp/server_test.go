@@ -21,6 +21,7 @@
 package rawtcp
 
 import (
+	"bufio"
 	"errors"
 	"net"
 	"sync"
@@ -274,6 +275,53 @@ func TestRawTCPServerHandleUnaggregatedProtobufEncoding(t *testing.T) {
 	require.True(t, cmp.Equal(expectedResult, snapshot, testCmpOpts...), expectedResult, snapshot)
 }
 
+func TestRawTCPServerHandleProtocolHandshake(t *testing.T) {
+	agg := capture.NewAggregator()
+	h := NewHandler(agg, testServerOptions())
+
+	listener, err := net.Listen("tcp", testListenAddress)
+	require.NoError(t, err)
+
+	s := xserver.NewServer(testListenAddress, h, xserver.NewOptions())
+	require.NoError(t, s.Serve(listener))
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close() // nolint: errcheck
+
+	encoder := protobuf.NewUnaggregatedEncoder(protobuf.NewUnaggregatedOptions())
+	require.NoError(t, encoder.EncodeMessage(encoding.UnaggregatedMessageUnion{
+		Type: encoding.ProtocolHandshakeType,
+		ProtocolHandshake: encoding.ProtocolHandshake{
+			MinVersion:   encoding.LegacyProtocolVersion,
+			MaxVersion:   encoding.CurrentProtocolVersion + 1,
+			Capabilities: []string{encoding.CounterCapability, "unknown"},
+		},
+	}))
+	require.NoError(t, encoder.EncodeMessage(encoding.UnaggregatedMessageUnion{
+		Type:                 encoding.CounterWithMetadatasType,
+		CounterWithMetadatas: testCounterWithMetadatas,
+	}))
+	_, err = conn.Write(encoder.Relinquish().Bytes())
+	require.NoError(t, err)
+
+	it := protobuf.NewUnaggregatedIterator(bufio.NewReader(conn), protobuf.NewUnaggregatedOptions())
+	defer it.Close()
+	require.True(t, it.Next())
+	reply := it.Current()
+	require.Equal(t, encoding.ProtocolHandshakeType, reply.Type)
+	require.Equal(t, encoding.CurrentProtocolVersion, reply.ProtocolHandshake.Version)
+	require.Equal(t, []string{encoding.CounterCapability}, reply.ProtocolHandshake.Capabilities)
+
+	// Metrics sent after the handshake are processed as usual.
+	for agg.NumMetricsAdded() < 1 {
+		time.Sleep(50 * time.Millisecond)
+	}
+	snapshot := agg.Snapshot()
+	require.Equal(t, 1, len(snapshot.CountersWithMetadatas))
+}
+
 func TestHandle_Errors(t *testing.T) {
 	cases := []struct {
 		name   string
@@ -34,23 +34,29 @@ import (
 
 	"github.com/m3db/m3/src/cluster/client"
 	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/audit"
 	etcdkv "github.com/m3db/m3/src/cluster/kv/etcd"
 	"github.com/m3db/m3/src/cluster/services"
 	etcdheartbeat "github.com/m3db/m3/src/cluster/services/heartbeat/etcd"
 	"github.com/m3db/m3/src/cluster/services/leader"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/retry"
 
 	"github.com/uber-go/tally"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 const (
 	hierarchySeparator = "/"
 	internalPrefix     = "_"
 	cacheFileSeparator = "_"
-	cacheFileSuffix    = ".json"
+	cacheFileSuffix    = ".pb"
+	// legacyCacheFileSuffix is the suffix of the JSON cache files written by
+	// previous versions, which are converted once.
+	legacyCacheFileSuffix = ".json"
 	// TODO deprecate this once all keys are migrated to per service namespace
 	kvPrefix = "_kv"
 
@@ -87,18 +93,28 @@ func NewEtcdConfigServiceClient(opts Options) (*csclient, error) {
 	scope := opts.InstrumentOptions().
 		MetricsScope().
 		Tagged(map[string]string{"service": opts.Service()})
+	kvScope := scope.Tagged(map[string]string{"config_service": "kv"})
+
+	// NB: the limiter is shared by the kv stores of all zones, namespaces and
+	// environments since they are all served by the same etcd clusters.
+	var kvLimiter ratelimit.Limiter
+	if limit := opts.RateLimit(); !limit.Unlimited() {
+		kvLimiter = ratelimit.NewLimiter("etcd-kv", limit, ratelimit.NewOptions().
+			SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(kvScope)))
+	}
 
 	return &csclient{
-		opts:    opts,
-		sdOpts:  opts.ServicesOptions(),
-		kvScope: scope.Tagged(map[string]string{"config_service": "kv"}),
-		sdScope: scope.Tagged(map[string]string{"config_service": "sd"}),
-		hbScope: scope.Tagged(map[string]string{"config_service": "hb"}),
-		clis:    make(map[string]*clientv3.Client),
-		logger:  opts.InstrumentOptions().Logger(),
-		newFn:   newClient,
-		retrier: retry.NewRetrier(opts.RetryOptions()),
-		stores:  make(map[string]kv.TxnStore),
+		opts:      opts,
+		sdOpts:    opts.ServicesOptions(),
+		kvScope:   kvScope,
+		kvLimiter: kvLimiter,
+		sdScope:   scope.Tagged(map[string]string{"config_service": "sd"}),
+		hbScope:   scope.Tagged(map[string]string{"config_service": "hb"}),
+		clis:      make(map[string]*clientv3.Client),
+		logger:    opts.InstrumentOptions().Logger(),
+		newFn:     newClient,
+		retrier:   retry.NewRetrier(opts.RetryOptions()),
+		stores:    make(map[string]kv.TxnStore),
 	}, nil
 }
 
@@ -120,6 +136,9 @@ type csclient struct {
 	newFn   newClientFn
 	retrier retry.Retrier
 
+	// kvLimiter limits the requests of all kv stores, nil if unlimited.
+	kvLimiter ratelimit.Limiter
+
 	storeLock sync.Mutex
 	stores    map[string]kv.TxnStore
 }
@@ -155,7 +174,11 @@ func (c *csclient) TxnStore(opts kv.OverrideOptions) (kv.TxnStore, error) {
 func (c *csclient) createServices(opts services.OverrideOptions) (services.Services, error) {
 	nOpts := opts.NamespaceOptions()
 	cacheFileExtraFields := []string{nOpts.PlacementNamespace(), nOpts.MetadataNamespace()}
-	return services.NewServices(c.sdOpts.
+	sdOpts := c.sdOpts
+	if c.opts.ReadOnly() {
+		sdOpts = sdOpts.SetReadOnly(true)
+	}
+	return services.NewServices(sdOpts.
 		SetHeartbeatGen(c.heartbeatGen()).
 		SetKVGen(c.kvGen(c.cacheFileFn(cacheFileExtraFields...))).
 		SetLeaderGen(c.leaderGen()).
@@ -188,11 +211,13 @@ func (c *csclient) newkvOptions(
 	opts kv.OverrideOptions,
 	cacheFileFn cacheFileForZoneFn,
 ) etcdkv.Options {
+	zoneCacheFileFn := cacheFileFn(opts.Zone())
 	kvOpts := etcdkv.NewOptions().
 		SetInstrumentsOptions(c.opts.InstrumentOptions().
 			SetLogger(c.logger).
 			SetMetricsScope(c.kvScope)).
-		SetCacheFileFn(cacheFileFn(opts.Zone())).
+		SetCacheFileFn(zoneCacheFileFn).
+		SetLegacyCacheFileFn(legacyCacheFileFn(zoneCacheFileFn)).
 		SetWatchWithRevision(c.opts.WatchWithRevision()).
 		SetNewDirectoryMode(c.opts.NewDirectoryMode()).
 		SetEnableFastGets(c.opts.EnableFastGets()).
@@ -200,7 +225,26 @@ func (c *csclient) newkvOptions(
 		SetRequestTimeout(c.opts.RequestTimeout()).
 		SetWatchChanInitTimeout(c.opts.WatchChanInitTimeout()).
 		SetWatchChanCheckInterval(c.opts.WatchChanCheckInterval()).
-		SetWatchChanResetInterval(c.opts.WatchChanResetInterval())
+		SetWatchChanResetInterval(c.opts.WatchChanResetInterval()).
+		SetRateLimiter(c.kvLimiter).
+		SetCircuitBreakerWindow(c.opts.CircuitBreakerWindow()).
+		SetReadOnly(c.opts.ReadOnly())
+
+	if interval := c.opts.CircuitBreakerProbeInterval(); interval > 0 {
+		kvOpts = kvOpts.SetCircuitBreakerProbeInterval(interval)
+	}
+
+	if compression := c.opts.ValueCompression(); compression != "" {
+		kvOpts = kvOpts.SetValueCompression(compression)
+	}
+
+	if threshold := c.opts.ValueCompressionThreshold(); threshold > 0 {
+		kvOpts = kvOpts.SetValueCompressionThreshold(threshold)
+	}
+
+	if size := c.opts.ValueChunkSize(); size > 0 {
+		kvOpts = kvOpts.SetValueChunkSize(size)
+	}
 
 	if ns := opts.Namespace(); ns != "" {
 		kvOpts = kvOpts.SetPrefix(kvOpts.ApplyPrefix(ns))
@@ -235,6 +279,11 @@ func (c *csclient) txnGen(
 	if store, err = etcdkv.NewStore(cli, c.newkvOptions(opts, cacheFileFn)); err != nil {
 		return nil, err
 	}
+	if auditOpts := c.opts.KVAuditOptions(); auditOpts != nil {
+		if store, err = audit.NewTxnStore(store, opts, auditOpts); err != nil {
+			return nil, err
+		}
+	}
 
 	c.stores[key] = store
 	return store, nil
@@ -369,6 +418,16 @@ func newConfigFromCluster(rnd randInt63N, cluster Cluster) (clientv3.Config, err
 		cfg.PermitWithoutStream = true
 	}
 
+	if opts := cluster.AuthOptions(); opts.Username() != "" {
+		creds, err := newAuthCredentials(opts, instrument.NewOptions())
+		if err != nil {
+			return clientv3.Config{}, err
+		}
+		dialOpts := make([]grpc.DialOption, 0, len(cfg.DialOptions)+3)
+		dialOpts = append(dialOpts, cfg.DialOptions...)
+		cfg.DialOptions = append(dialOpts, creds.dialOptions()...)
+	}
+
 	return cfg, nil
 }
 
@@ -387,6 +446,18 @@ func (c *csclient) cacheFileFn(extraFields ...string) cacheFileForZoneFn {
 	}
 }
 
+// legacyCacheFileFn returns the paths of the JSON cache files written by
+// previous versions in place of the cache files.
+func legacyCacheFileFn(fn etcdkv.CacheFileFn) etcdkv.CacheFileFn {
+	return func(namespace string) string {
+		file := fn(namespace)
+		if file == "" {
+			return ""
+		}
+		return strings.TrimSuffix(file, cacheFileSuffix) + legacyCacheFileSuffix
+	}
+}
+
 func fileName(parts ...string) string {
 	// get non-empty parts
 	idx := 0
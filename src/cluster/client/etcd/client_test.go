@@ -21,18 +21,23 @@
 package etcd
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/audit"
+	etcdkv "github.com/m3db/m3/src/cluster/kv/etcd"
 	"github.com/m3db/m3/src/cluster/services"
 	integration "github.com/m3db/m3/src/integration/resources/docker/dockerexternal/etcdintegration"
+	"github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/retry"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
 
@@ -239,22 +244,117 @@ func TestCacheFileForZone(t *testing.T) {
 
 	cs.opts = cs.opts.SetCacheDir("/cacheDir")
 	kvOpts = cs.newkvOptions(newOverrideOpts("z1", "", ""), cs.cacheFileFn())
-	require.Equal(t, "/cacheDir/test_app_z1.json", kvOpts.CacheFileFn()(kvOpts.Prefix()))
+	require.Equal(t, "/cacheDir/test_app_z1.pb", kvOpts.CacheFileFn()(kvOpts.Prefix()))
+	require.Equal(t, "/cacheDir/test_app_z1.json", kvOpts.LegacyCacheFileFn()(kvOpts.Prefix()))
 
 	kvOpts = cs.newkvOptions(newOverrideOpts("z1", "namespace", ""), cs.cacheFileFn())
-	require.Equal(t, "/cacheDir/namespace_test_app_z1.json", kvOpts.CacheFileFn()(kvOpts.Prefix()))
+	require.Equal(t, "/cacheDir/namespace_test_app_z1.pb", kvOpts.CacheFileFn()(kvOpts.Prefix()))
 
 	kvOpts = cs.newkvOptions(newOverrideOpts("z1", "namespace", ""), cs.cacheFileFn())
-	require.Equal(t, "/cacheDir/namespace_test_app_z1.json", kvOpts.CacheFileFn()(kvOpts.Prefix()))
+	require.Equal(t, "/cacheDir/namespace_test_app_z1.pb", kvOpts.CacheFileFn()(kvOpts.Prefix()))
 
 	kvOpts = cs.newkvOptions(newOverrideOpts("z1", "namespace", "env"), cs.cacheFileFn())
-	require.Equal(t, "/cacheDir/namespace_env_test_app_z1.json", kvOpts.CacheFileFn()(kvOpts.Prefix()))
+	require.Equal(t, "/cacheDir/namespace_env_test_app_z1.pb", kvOpts.CacheFileFn()(kvOpts.Prefix()))
 
 	kvOpts = cs.newkvOptions(newOverrideOpts("z1", "namespace", ""), cs.cacheFileFn("f1", "", "f2"))
-	require.Equal(t, "/cacheDir/namespace_test_app_z1_f1_f2.json", kvOpts.CacheFileFn()(kvOpts.Prefix()))
+	require.Equal(t, "/cacheDir/namespace_test_app_z1_f1_f2.pb", kvOpts.CacheFileFn()(kvOpts.Prefix()))
 
 	kvOpts = cs.newkvOptions(newOverrideOpts("z2", "", ""), cs.cacheFileFn("/r2/m3agg"))
-	require.Equal(t, "/cacheDir/test_app_z2__r2_m3agg.json", kvOpts.CacheFileFn()(kvOpts.Prefix()))
+	require.Equal(t, "/cacheDir/test_app_z2__r2_m3agg.pb", kvOpts.CacheFileFn()(kvOpts.Prefix()))
+}
+
+func TestKVOptionsRateLimitAndCircuitBreaker(t *testing.T) {
+	c, err := NewConfigServiceClient(testOptions())
+	require.NoError(t, err)
+	cs := c.(*csclient)
+
+	kvOpts := cs.newkvOptions(newOverrideOpts("z1", "", ""), cs.cacheFileFn())
+	require.Nil(t, kvOpts.RateLimiter())
+	require.Equal(t, time.Duration(0), kvOpts.CircuitBreakerWindow())
+
+	c, err = NewConfigServiceClient(testOptions().
+		SetRateLimit(ratelimit.Limit{Rate: 100}).
+		SetCircuitBreakerWindow(time.Minute).
+		SetCircuitBreakerProbeInterval(5 * time.Second))
+	require.NoError(t, err)
+	cs = c.(*csclient)
+
+	// All the kv stores share the same limiter.
+	kvOpts1 := cs.newkvOptions(newOverrideOpts("z1", "", ""), cs.cacheFileFn())
+	kvOpts2 := cs.newkvOptions(newOverrideOpts("z2", "namespace", ""), cs.cacheFileFn())
+	require.NotNil(t, kvOpts1.RateLimiter())
+	require.True(t, kvOpts1.RateLimiter() == kvOpts2.RateLimiter())
+	require.Equal(t, time.Minute, kvOpts1.CircuitBreakerWindow())
+	require.Equal(t, 5*time.Second, kvOpts1.CircuitBreakerProbeInterval())
+	require.NoError(t, kvOpts1.Validate())
+}
+
+func TestKVOptionsValueCompressionAndChunking(t *testing.T) {
+	c, err := NewConfigServiceClient(testOptions())
+	require.NoError(t, err)
+	cs := c.(*csclient)
+
+	kvOpts := cs.newkvOptions(newOverrideOpts("z1", "", ""), cs.cacheFileFn())
+	require.Equal(t, etcdkv.NoValueCompression, kvOpts.ValueCompression())
+
+	c, err = NewConfigServiceClient(testOptions().
+		SetValueCompression(etcdkv.SnappyValueCompression).
+		SetValueCompressionThreshold(1024).
+		SetValueChunkSize(1 << 20))
+	require.NoError(t, err)
+	cs = c.(*csclient)
+
+	kvOpts = cs.newkvOptions(newOverrideOpts("z1", "", ""), cs.cacheFileFn())
+	require.Equal(t, etcdkv.SnappyValueCompression, kvOpts.ValueCompression())
+	require.Equal(t, 1024, kvOpts.ValueCompressionThreshold())
+	require.Equal(t, 1<<20, kvOpts.ValueChunkSize())
+	require.NoError(t, kvOpts.Validate())
+}
+
+func TestKVAudit(t *testing.T) {
+	_, err := NewConfigServiceClient(testOptions().SetKVAuditOptions(audit.NewOptions()))
+	require.Error(t, err)
+
+	var stores []kv.Store
+	auditOpts := audit.NewOptions().
+		SetSinkFn(func(store kv.Store) (audit.Sink, error) {
+			stores = append(stores, store)
+			return audit.NewLogSink(zap.NewNop()), nil
+		})
+	cs, err := NewConfigServiceClient(testOptions().SetKVAuditOptions(auditOpts))
+	require.NoError(t, err)
+
+	store1, err := cs.Txn()
+	require.NoError(t, err)
+	require.Len(t, stores, 1)
+	require.NotEqual(t, stores[0], store1)
+
+	store2, err := cs.KV()
+	require.NoError(t, err)
+	require.Equal(t, store1, store2)
+	require.Len(t, stores, 1)
+}
+
+func TestReadOnly(t *testing.T) {
+	c, err := NewConfigServiceClient(testOptions())
+	require.NoError(t, err)
+	cs := c.(*csclient)
+	require.False(t, cs.newkvOptions(newOverrideOpts("z1", "", ""), cs.cacheFileFn()).ReadOnly())
+
+	c, err = NewConfigServiceClient(testOptions().SetReadOnly(true))
+	require.NoError(t, err)
+	cs = c.(*csclient)
+	require.True(t, cs.newkvOptions(newOverrideOpts("z1", "", ""), cs.cacheFileFn()).ReadOnly())
+
+	store, err := cs.KV()
+	require.NoError(t, err)
+	_, err = store.Delete("foo")
+	require.True(t, errors.Is(err, kv.ErrReadOnly))
+
+	sd, err := cs.Services(nil)
+	require.NoError(t, err)
+	err = sd.SetMetadata(services.NewServiceID().SetName("m3db").SetZone("zone1"), services.NewMetadata())
+	require.True(t, errors.Is(err, kv.ErrReadOnly))
 }
 
 func TestSanitizeKVOverrideOptions(t *testing.T) {
@@ -437,6 +537,26 @@ func Test_newConfigFromCluster(t *testing.T) {
 
 		assert.Len(t, etcdCfg.DialOptions, 1)
 	})
+
+	t.Run("adds auth dial options", func(t *testing.T) {
+		clusterCfg := newFullConfig()
+		clusterCfg.DialOptions = []grpc.DialOption{grpc.WithNoProxy()}
+		clusterCfg.Auth = &AuthConfig{Username: "user", Password: "password"}
+		etcdCfg, err := newConfigFromCluster(testRnd, clusterCfg.NewCluster())
+		require.NoError(t, err)
+
+		// NB: the etcd client's own authentication is not used.
+		assert.Equal(t, "", etcdCfg.Username)
+		assert.Len(t, etcdCfg.DialOptions, 4)
+		assert.Len(t, clusterCfg.DialOptions, 1)
+	})
+
+	t.Run("unresolvable password", func(t *testing.T) {
+		clusterCfg := newFullConfig()
+		clusterCfg.Auth = &AuthConfig{Username: "user", Password: "secret://env/M3_TEST_MISSING"}
+		_, err := newConfigFromCluster(testRnd, clusterCfg.NewCluster())
+		require.Error(t, err)
+	})
 }
 
 func Test_cryptoRandInt63n(t *testing.T) {
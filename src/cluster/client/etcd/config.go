@@ -21,6 +21,7 @@
 package etcd
 
 import (
+	"errors"
 	"os"
 	"time"
 
@@ -28,17 +29,30 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/client/kvstore"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/audit"
+	"github.com/m3db/m3/src/cluster/kv/consul"
+	etcdkv "github.com/m3db/m3/src/cluster/kv/etcd"
+	"github.com/m3db/m3/src/cluster/kv/zookeeper"
 	"github.com/m3db/m3/src/cluster/services"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/retry"
 )
 
+var (
+	errMultipleKVBackends = errors.New("only one of zookeeper and consul can be configured")
+	errReadOnlyKVBackend  = errors.New("read only is only supported with the etcd backend")
+)
+
 // ClusterConfig is the config for a zoned etcd cluster.
 type ClusterConfig struct {
 	Zone      string           `yaml:"zone"`
 	Endpoints []string         `yaml:"endpoints"`
 	KeepAlive *KeepAliveConfig `yaml:"keepAlive"`
 	TLS       *TLSConfig       `yaml:"tls"`
+	Auth      *AuthConfig      `yaml:"auth"`
 	// AutoSyncInterval configures the etcd client's AutoSyncInterval
 	// (go.etcd.io/etcd/client/v3@v3.6.0-alpha.0/config.go:32).
 	// By default, it is 1m.
@@ -67,7 +81,8 @@ func (c ClusterConfig) NewCluster() Cluster {
 		SetEndpoints(c.Endpoints).
 		SetDialOptions(c.DialOptions).
 		SetKeepAliveOptions(keepAliveOpts).
-		SetTLSOptions(c.TLS.newOptions())
+		SetTLSOptions(c.TLS.newOptions()).
+		SetAuthOptions(c.Auth.newOptions())
 
 	// Autosync should *always* be on, unless the user very explicitly requests it to be off. They can do this via a
 	// negative value (in which case we can assume they know what they're doing).
@@ -88,6 +103,16 @@ type TLSConfig struct {
 	CrtPath   string `yaml:"crtPath"`
 	CACrtPath string `yaml:"caCrtPath"`
 	KeyPath   string `yaml:"keyPath"`
+
+	// Crt, Key and CACrt are PEM encoded certificates and keys, typically
+	// secret references such as secret://vault/secret/data/m3/etcd#key, used
+	// instead of the certificate and key paths. The client certificate and key
+	// secrets are re-resolved every RefreshInterval so that rotated
+	// certificates are used for new connections.
+	Crt             string        `yaml:"crt" secret:"refresh"`
+	Key             string        `yaml:"key" secret:"refresh"`
+	CACrt           string        `yaml:"caCrt" secret:"refresh"`
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
 }
 
 func (c *TLSConfig) newOptions() TLSOptions {
@@ -96,10 +121,42 @@ func (c *TLSConfig) newOptions() TLSOptions {
 		return opts
 	}
 
-	return opts.
+	opts = opts.
 		SetCrtPath(c.CrtPath).
 		SetKeyPath(c.KeyPath).
-		SetCACrtPath(c.CACrtPath)
+		SetCACrtPath(c.CACrtPath).
+		SetCrt(c.Crt).
+		SetKey(c.Key).
+		SetCACrt(c.CACrt)
+	if c.RefreshInterval > 0 {
+		opts = opts.SetRefreshInterval(c.RefreshInterval)
+	}
+	return opts
+}
+
+// AuthConfig is the config for etcd authentication.
+type AuthConfig struct {
+	Username string `yaml:"username"`
+	// Password is typically a secret reference such as
+	// secret://env/ETCD_PASSWORD, it is re-resolved every RefreshInterval so
+	// that a rotated password is used when a new auth token is required.
+	Password        string        `yaml:"password" secret:"refresh"`
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+}
+
+func (c *AuthConfig) newOptions() AuthOptions {
+	opts := NewAuthOptions()
+	if c == nil {
+		return opts
+	}
+
+	opts = opts.
+		SetUsername(c.Username).
+		SetPassword(c.Password)
+	if c.RefreshInterval > 0 {
+		opts = opts.SetRefreshInterval(c.RefreshInterval)
+	}
+	return opts
 }
 
 // KeepAliveConfig configures keepAlive behavior.
@@ -138,11 +195,117 @@ type Configuration struct {
 	// EnableFastGets trades consistency for latency and throughput using clientv3.WithSerializable()
 	// on etcd ops.
 	EnableFastGets bool `yaml:"enableFastGets"`
+
+	// RateLimit limits the requests to etcd of all the kv stores of the client.
+	RateLimit ratelimit.Configuration `yaml:"rateLimit"`
+	// CircuitBreaker fails requests to etcd fast once it is unresponsive.
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuitBreaker"`
+	// ValueCompression compresses large values written to etcd.
+	ValueCompression ValueCompressionConfig `yaml:"valueCompression"`
+	// ValueChunkSize is the size in bytes from which values are split into
+	// chunks, for values exceeding the etcd request size limit even once
+	// compressed. Chunked values can only be read by clients that support
+	// value chunking, so enable it once all of them do.
+	ValueChunkSize int `yaml:"valueChunkSize"`
+	// KVAudit records the mutations of the kv stores of the client, including
+	// placement changes, along with who made them.
+	KVAudit *audit.Configuration `yaml:"kvAudit"`
+	// ReadOnly rejects every mutation made through the client, so that tools
+	// such as dashboards can be given cluster credentials without risking
+	// accidental placement edits. It is not supported with zookeeper or consul.
+	ReadOnly bool `yaml:"readOnly"`
+	// ZooKeeper backs the client with zookeeper instead of the etcd clusters,
+	// the kv stores of the client do not support transactions and services
+	// do not support leader election.
+	ZooKeeper *zookeeper.Configuration `yaml:"zookeeper"`
+	// Consul backs the client with consul instead of the etcd clusters, with
+	// the same limitations as zookeeper.
+	Consul *consul.Configuration `yaml:"consul"`
+}
+
+// CircuitBreakerConfig configures the circuit breaker of the kv stores.
+type CircuitBreakerConfig struct {
+	// Window is how long requests must have been failing for before they
+	// fail fast, zero disables the circuit breaker.
+	Window time.Duration `yaml:"window"`
+	// ProbeInterval is the interval at which a request is let through to
+	// probe etcd while requests fail fast.
+	ProbeInterval time.Duration `yaml:"probeInterval"`
+}
+
+// ValueCompressionConfig configures the compression of the values written to
+// etcd by the kv stores. Compressed values can only be read by clients that
+// support value compression, so enable it once all of them do.
+type ValueCompressionConfig struct {
+	// Type is the compression, one of none, snappy or zstd, defaults to none.
+	Type etcdkv.ValueCompression `yaml:"type"`
+	// Threshold is the size in bytes from which values are compressed.
+	Threshold int `yaml:"threshold"`
 }
 
 // NewClient creates a new config service client.
 func (cfg Configuration) NewClient(iopts instrument.Options) (client.Client, error) {
-	return NewConfigServiceClient(cfg.NewOptions().SetInstrumentOptions(iopts))
+	if cfg.ZooKeeper != nil && cfg.Consul != nil {
+		return nil, errMultipleKVBackends
+	}
+	if cfg.ReadOnly && (cfg.ZooKeeper != nil || cfg.Consul != nil) {
+		return nil, errReadOnlyKVBackend
+	}
+	if cfg.ZooKeeper != nil {
+		return cfg.newZooKeeperClient(iopts)
+	}
+	if cfg.Consul != nil {
+		return cfg.newConsulClient(iopts)
+	}
+
+	opts := cfg.NewOptions().SetInstrumentOptions(iopts)
+	if cfg.KVAudit != nil && cfg.KVAudit.Enabled {
+		// NB: the producer of an m3msg audit sink needs a client before the
+		// audited client exists, so it gets a separate client which is not
+		// audited.
+		auditOpts, err := cfg.KVAudit.NewOptions(func() (client.Client, error) {
+			return NewConfigServiceClient(opts)
+		}, iopts)
+		if err != nil {
+			return nil, err
+		}
+		opts = opts.SetKVAuditOptions(auditOpts)
+	}
+	return NewConfigServiceClient(opts)
+}
+
+func (cfg Configuration) newZooKeeperClient(iopts instrument.Options) (client.Client, error) {
+	zkConn, err := cfg.ZooKeeper.Connect(iopts)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		zkClient = zookeeper.NewClient(zkConn)
+		zkOpts   = cfg.ZooKeeper.NewOptions(iopts)
+	)
+	return kvstore.NewClient(func(prefix string) (kv.Store, error) {
+		opts := zkOpts
+		if prefix != "" {
+			opts = opts.SetPrefix(opts.ApplyPrefix(prefix))
+		}
+		return zookeeper.NewStore(zkClient, opts)
+	}, cfg.Env, cfg.SDConfig.NewOptions(), iopts), nil
+}
+
+func (cfg Configuration) newConsulClient(iopts instrument.Options) (client.Client, error) {
+	consulOpts := cfg.Consul.NewOptions(iopts)
+	if err := consulOpts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return kvstore.NewClient(func(prefix string) (kv.Store, error) {
+		opts := consulOpts
+		if prefix != "" {
+			opts = opts.SetPrefix(opts.ApplyPrefix(prefix))
+		}
+		return consul.NewStore(opts)
+	}, cfg.Env, cfg.SDConfig.NewOptions(), iopts), nil
 }
 
 // NewOptions returns a new Options.
@@ -156,7 +319,14 @@ func (cfg Configuration) NewOptions() Options {
 		SetServicesOptions(cfg.SDConfig.NewOptions()).
 		SetWatchWithRevision(cfg.WatchWithRevision).
 		SetEnableFastGets(cfg.EnableFastGets).
-		SetRetryOptions(cfg.Retry.NewOptions(tally.NoopScope))
+		SetRetryOptions(cfg.Retry.NewOptions(tally.NoopScope)).
+		SetRateLimit(cfg.RateLimit.Limit()).
+		SetCircuitBreakerWindow(cfg.CircuitBreaker.Window).
+		SetCircuitBreakerProbeInterval(cfg.CircuitBreaker.ProbeInterval).
+		SetValueCompression(cfg.ValueCompression.Type).
+		SetValueCompressionThreshold(cfg.ValueCompression.Threshold).
+		SetValueChunkSize(cfg.ValueChunkSize).
+		SetReadOnly(cfg.ReadOnly)
 
 	if cfg.RequestTimeout > 0 {
 		opts = opts.SetRequestTimeout(cfg.RequestTimeout)
@@ -25,6 +25,11 @@ import (
 	"testing"
 	"time"
 
+	etcdkv "github.com/m3db/m3/src/cluster/kv/etcd"
+	"github.com/m3db/m3/src/cluster/kv/zookeeper"
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/ratelimit"
+
 	"github.com/stretchr/testify/require"
 	yaml "gopkg.in/yaml.v2"
 )
@@ -47,6 +52,44 @@ timeout: 1s
 	require.Equal(t, time.Second, opts.KeepAliveTimeout())
 }
 
+func TestClusterConfigSecrets(t *testing.T) {
+	const cfgStr = `
+zone: z1
+endpoints:
+  - etcd1:2379
+auth:
+  username: m3
+  password: secret://env/ETCD_PASSWORD
+  refreshInterval: 30s
+tls:
+  crt: secret://vault/secret/data/m3/etcd#crt
+  key: secret://vault/secret/data/m3/etcd#key
+  caCrt: secret://file/etc/m3/etcd-ca.pem
+  refreshInterval: 5m
+`
+
+	var cfg ClusterConfig
+	require.NoError(t, yaml.Unmarshal([]byte(cfgStr), &cfg))
+
+	cluster := cfg.NewCluster()
+	authOpts := cluster.AuthOptions()
+	require.Equal(t, "m3", authOpts.Username())
+	require.Equal(t, "secret://env/ETCD_PASSWORD", authOpts.Password())
+	require.Equal(t, 30*time.Second, authOpts.RefreshInterval())
+
+	tlsOpts := cluster.TLSOptions()
+	require.Equal(t, "secret://vault/secret/data/m3/etcd#crt", tlsOpts.Crt())
+	require.Equal(t, "secret://vault/secret/data/m3/etcd#key", tlsOpts.Key())
+	require.Equal(t, "secret://file/etc/m3/etcd-ca.pem", tlsOpts.CACrt())
+	require.Equal(t, 5*time.Minute, tlsOpts.RefreshInterval())
+
+	// Clusters without auth use the default refresh interval.
+	cluster = ClusterConfig{Zone: "z1"}.NewCluster()
+	require.Equal(t, "", cluster.AuthOptions().Username())
+	require.Equal(t, defaultSecretRefreshInterval, cluster.AuthOptions().RefreshInterval())
+	require.Equal(t, defaultSecretRefreshInterval, cluster.TLSOptions().RefreshInterval())
+}
+
 func TestConfig(t *testing.T) {
 	const testConfig = `
 env: env1
@@ -83,6 +126,21 @@ etcdClusters:
       caCrtPath: foo_ca.pem
 m3sd:
   initTimeout: 10s
+rateLimit:
+  rate: 100
+  burst: 10
+circuitBreaker:
+  window: 30s
+  probeInterval: 5s
+valueCompression:
+  type: zstd
+  threshold: 1024
+valueChunkSize: 1048576
+readOnly: true
+retry:
+  maxRetries: 3
+  budget:
+    ratio: 0.2
 `
 
 	var cfg Configuration
@@ -127,6 +185,16 @@ m3sd:
 	require.Equal(t, 10*time.Second, *cfg.SDConfig.InitTimeout)
 
 	opts := cfg.NewOptions()
+	require.Equal(t, ratelimit.Limit{Rate: 100, Burst: 10}, opts.RateLimit())
+	require.Equal(t, 30*time.Second, opts.CircuitBreakerWindow())
+	require.Equal(t, 5*time.Second, opts.CircuitBreakerProbeInterval())
+	require.Equal(t, etcdkv.ZstdValueCompression, opts.ValueCompression())
+	require.Equal(t, 1024, opts.ValueCompressionThreshold())
+	require.Equal(t, 1048576, opts.ValueChunkSize())
+	require.True(t, opts.ReadOnly())
+	require.Equal(t, 3, opts.RetryOptions().MaxRetries())
+	require.NotNil(t, opts.RetryOptions().Budget())
+
 	cluster1, exists := opts.ClusterForZone("z1")
 	require.True(t, exists)
 	keepAliveOpts := cluster1.KeepAliveOptions()
@@ -188,3 +256,57 @@ func TestConfig_negativeAutosync(t *testing.T) {
 	}.NewCluster()
 	require.Equal(t, time.Duration(-5), cluster.AutoSyncInterval())
 }
+
+func TestZooKeeperConfig(t *testing.T) {
+	const cfgStr = `
+service: test
+env: test
+zookeeper:
+  servers:
+    - zk1:2181
+    - zk2:2181
+  sessionTimeout: 5s
+  root: /m3
+`
+	var cfg Configuration
+	require.NoError(t, yaml.Unmarshal([]byte(cfgStr), &cfg))
+	require.NotNil(t, cfg.ZooKeeper)
+	require.Equal(t, []string{"zk1:2181", "zk2:2181"}, cfg.ZooKeeper.Servers)
+	require.Equal(t, 5*time.Second, cfg.ZooKeeper.SessionTimeout)
+	require.Equal(t, "/m3", cfg.ZooKeeper.Root)
+
+	cfg.ZooKeeper.Servers = nil
+	_, err := cfg.NewClient(instrument.NewOptions())
+	require.Error(t, err)
+}
+
+func TestConsulConfig(t *testing.T) {
+	const cfgStr = `
+service: test
+env: test
+consul:
+  address: http://consul:8500
+  prefix: m3
+  requestTimeout: 5s
+`
+	var cfg Configuration
+	require.NoError(t, yaml.Unmarshal([]byte(cfgStr), &cfg))
+	require.NotNil(t, cfg.Consul)
+	require.Equal(t, "http://consul:8500", cfg.Consul.Address)
+	require.Equal(t, "m3", cfg.Consul.Prefix)
+	require.Equal(t, 5*time.Second, cfg.Consul.RequestTimeout)
+
+	c, err := cfg.NewClient(instrument.NewOptions())
+	require.NoError(t, err)
+	_, err = c.Txn()
+	require.Error(t, err)
+
+	cfg.ReadOnly = true
+	_, err = cfg.NewClient(instrument.NewOptions())
+	require.Equal(t, errReadOnlyKVBackend, err)
+	cfg.ReadOnly = false
+
+	cfg.ZooKeeper = &zookeeper.Configuration{Servers: []string{"zk:2181"}}
+	_, err = cfg.NewClient(instrument.NewOptions())
+	require.Equal(t, errMultipleKVBackends, err)
+}
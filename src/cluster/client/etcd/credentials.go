@@ -0,0 +1,276 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/secrets"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const authenticateMethod = "/etcdserverpb.Auth/Authenticate"
+
+type authenticateFn func(
+	ctx context.Context,
+	cc *grpc.ClientConn,
+	username, password string,
+) (string, error)
+
+// authCredentials authenticates with etcd using a password secret that is
+// re-resolved periodically. The etcd client's own authentication is not used
+// since its credentials are fixed when it is created, instead a new auth token
+// is obtained when the password is rotated or the current token is rejected.
+type authCredentials struct {
+	username     string
+	password     *secrets.Value
+	authenticate authenticateFn
+
+	// authLock serializes authentication so that concurrent requests with an
+	// expired token authenticate once.
+	authLock sync.Mutex
+
+	sync.RWMutex
+	authenticated bool
+	token         string
+	tokenPassword []byte
+}
+
+func newAuthCredentials(opts AuthOptions, iOpts instrument.Options) (*authCredentials, error) {
+	password, err := secrets.NewValue(context.Background(), secrets.DefaultResolver(),
+		opts.Password(), opts.RefreshInterval(), iOpts)
+	if err != nil {
+		return nil, err
+	}
+	return &authCredentials{
+		username:     opts.Username(),
+		password:     password,
+		authenticate: authenticate,
+	}, nil
+}
+
+func authenticate(
+	ctx context.Context,
+	cc *grpc.ClientConn,
+	username, password string,
+) (string, error) {
+	resp, err := pb.NewAuthClient(cc).Authenticate(ctx, &pb.AuthenticateRequest{
+		Name:     username,
+		Password: password,
+	})
+	if err != nil {
+		if rpctypes.Error(err) == rpctypes.ErrAuthNotEnabled {
+			return "", nil
+		}
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+func (a *authCredentials) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithPerRPCCredentials(a),
+		grpc.WithChainUnaryInterceptor(a.unaryInterceptor),
+		grpc.WithChainStreamInterceptor(a.streamInterceptor),
+	}
+}
+
+// GetRequestMetadata attaches the auth token to requests.
+func (a *authCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	token := a.currentToken()
+	if token == "" {
+		return nil, nil
+	}
+	return map[string]string{rpctypes.TokenFieldNameGRPC: token}, nil
+}
+
+// RequireTransportSecurity returns false since etcd auth tokens may be sent
+// without TLS, as with the etcd client's own authentication.
+func (a *authCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+func (a *authCredentials) currentToken() string {
+	a.RLock()
+	defer a.RUnlock()
+	return a.token
+}
+
+// ensureToken authenticates if not yet authenticated, if the password has been
+// rotated since the token was obtained or if the rejected token is current.
+func (a *authCredentials) ensureToken(ctx context.Context, cc *grpc.ClientConn, rejected *string) error {
+	password := a.password.Get(ctx)
+
+	a.authLock.Lock()
+	defer a.authLock.Unlock()
+
+	a.RLock()
+	valid := a.authenticated && bytes.Equal(a.tokenPassword, password) &&
+		(rejected == nil || *rejected != a.token)
+	a.RUnlock()
+	if valid {
+		return nil
+	}
+
+	token, err := a.authenticate(ctx, cc, a.username, string(password))
+	if err != nil {
+		return err
+	}
+
+	a.Lock()
+	a.authenticated = true
+	a.token = token
+	a.tokenPassword = password
+	a.Unlock()
+	return nil
+}
+
+func (a *authCredentials) unaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if method == authenticateMethod {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	if err := a.ensureToken(ctx, cc, nil); err != nil {
+		return err
+	}
+	token := a.currentToken()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if !isInvalidAuthTokenError(err) {
+		return err
+	}
+
+	// Retry once with a new token, the token may have expired or have been
+	// revoked when the password was rotated.
+	if err := a.ensureToken(ctx, cc, &token); err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (a *authCredentials) streamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	if err := a.ensureToken(ctx, cc, nil); err != nil {
+		return nil, err
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+func isInvalidAuthTokenError(err error) bool {
+	switch rpctypes.Error(err) {
+	case rpctypes.ErrInvalidAuthToken, rpctypes.ErrAuthOldRevision:
+		return true
+	}
+	return false
+}
+
+// rotatingCertificate is a client certificate whose certificate and key
+// secrets are re-resolved periodically.
+type rotatingCertificate struct {
+	cert   *secrets.Value
+	key    *secrets.Value
+	logger *zap.Logger
+
+	sync.Mutex
+	certPEM []byte
+	keyPEM  []byte
+	current *tls.Certificate
+}
+
+func newRotatingCertificate(
+	cert, key string,
+	refreshInterval time.Duration,
+) (*rotatingCertificate, error) {
+	var (
+		ctx      = context.Background()
+		resolver = secrets.DefaultResolver()
+		iOpts    = instrument.NewOptions()
+	)
+	certValue, err := secrets.NewValue(ctx, resolver, cert, refreshInterval, iOpts)
+	if err != nil {
+		return nil, err
+	}
+	keyValue, err := secrets.NewValue(ctx, resolver, key, refreshInterval, iOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &rotatingCertificate{
+		cert:   certValue,
+		key:    keyValue,
+		logger: iOpts.Logger(),
+	}
+	if _, err := c.clientCertificate(nil); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// clientCertificate returns the current client certificate, a rotated key
+// pair that can not be parsed, e.g. since only one of the certificate and key
+// have been rotated so far, continues to use the previous certificate.
+func (c *rotatingCertificate) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	ctx := context.Background()
+	certPEM, keyPEM := c.cert.Get(ctx), c.key.Get(ctx)
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.current != nil && bytes.Equal(certPEM, c.certPEM) && bytes.Equal(keyPEM, c.keyPEM) {
+		return c.current, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		if c.current == nil {
+			return nil, err
+		}
+		// NB: the rotated key pair is not parsed again until either changes.
+		c.certPEM, c.keyPEM = certPEM, keyPEM
+		c.logger.Error("could not load rotated client certificate, using previous certificate",
+			zap.Error(err))
+		return c.current, nil
+	}
+
+	c.certPEM, c.keyPEM, c.current = certPEM, keyPEM, &cert
+	return c.current, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"google.golang.org/grpc"
+)
+
+func testSecretValue(t *testing.T, env map[string]string, ref string) *secrets.Value {
+	resolver := secrets.NewResolver(map[string]secrets.Provider{
+		secrets.EnvProviderName: secrets.NewEnvProvider(func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		}),
+	})
+	// NB: a nanosecond refresh interval re-resolves the secret on every read.
+	v, err := secrets.NewValue(context.Background(), resolver, ref, time.Nanosecond,
+		instrument.NewOptions())
+	require.NoError(t, err)
+	return v
+}
+
+func TestAuthCredentials(t *testing.T) {
+	var (
+		env           = map[string]string{"PASSWORD": "first"}
+		authenticated []string
+		tokens        []string
+	)
+	creds := &authCredentials{
+		username: "user",
+		password: testSecretValue(t, env, "secret://env/PASSWORD"),
+		authenticate: func(_ context.Context, _ *grpc.ClientConn, username, password string) (string, error) {
+			require.Equal(t, "user", username)
+			authenticated = append(authenticated, password)
+			return "token-" + password, nil
+		},
+	}
+
+	var invokeErr error
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, err := creds.GetRequestMetadata(ctx)
+		require.NoError(t, err)
+		tokens = append(tokens, md[rpctypes.TokenFieldNameGRPC])
+		err, invokeErr = invokeErr, nil
+		return err
+	}
+	invoke := func() error {
+		return creds.unaryInterceptor(context.Background(), "/etcdserverpb.KV/Range",
+			nil, nil, nil, invoker)
+	}
+
+	// Authenticates once for the first request.
+	require.NoError(t, invoke())
+	require.NoError(t, invoke())
+	assert.Equal(t, []string{"first"}, authenticated)
+	assert.Equal(t, []string{"token-first", "token-first"}, tokens)
+
+	// Rejected tokens are replaced and the request is retried.
+	invokeErr = rpctypes.ErrGRPCInvalidAuthToken
+	require.NoError(t, invoke())
+	assert.Equal(t, []string{"first", "first"}, authenticated)
+
+	// A rotated password authenticates again.
+	env["PASSWORD"] = "second"
+	tokens = nil
+	require.NoError(t, invoke())
+	assert.Equal(t, []string{"first", "first", "second"}, authenticated)
+	assert.Equal(t, []string{"token-second"}, tokens)
+
+	// Other errors are returned as is.
+	invokeErr = errors.New("unavailable")
+	require.Equal(t, errors.New("unavailable"), invoke())
+
+	// Authenticate requests are not intercepted.
+	require.NoError(t, creds.unaryInterceptor(context.Background(), authenticateMethod,
+		nil, nil, nil, func(context.Context, string, interface{}, interface{},
+			*grpc.ClientConn, ...grpc.CallOption) error {
+			return nil
+		}))
+	assert.Len(t, authenticated, 3)
+}
+
+func TestAuthCredentialsStream(t *testing.T) {
+	creds := &authCredentials{
+		username: "user",
+		password: testSecretValue(t, nil, "password"),
+		authenticate: func(context.Context, *grpc.ClientConn, string, string) (string, error) {
+			return "", errors.New("authentication failed")
+		},
+	}
+
+	_, err := creds.streamInterceptor(context.Background(), &grpc.StreamDesc{}, nil, "/etcdserverpb.Watch/Watch",
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			t.Fatal("stream should not be created")
+			return nil, nil
+		})
+	require.Error(t, err)
+}
+
+func testKeyPair(t *testing.T, commonName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestRotatingCertificate(t *testing.T) {
+	firstCert, firstKey := testKeyPair(t, "first")
+	secondCert, secondKey := testKeyPair(t, "second")
+
+	env := map[string]string{"CRT": firstCert, "KEY": firstKey}
+	c := &rotatingCertificate{
+		cert:   testSecretValue(t, env, "secret://env/CRT"),
+		key:    testSecretValue(t, env, "secret://env/KEY"),
+		logger: instrument.NewOptions().Logger(),
+	}
+
+	commonName := func() string {
+		cert, err := c.clientCertificate(nil)
+		require.NoError(t, err)
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return parsed.Subject.CommonName
+	}
+	require.Equal(t, "first", commonName())
+
+	// A partially rotated key pair keeps using the previous certificate.
+	env["CRT"] = secondCert
+	require.Equal(t, "first", commonName())
+
+	env["KEY"] = secondKey
+	require.Equal(t, "second", commonName())
+}
+
+func TestTLSOptionsConfigSecrets(t *testing.T) {
+	_, err := NewTLSOptions().SetCrt("secret://env/M3_TEST_MISSING").Config()
+	require.Error(t, err)
+
+	_, err = NewTLSOptions().SetCrt("not a certificate").SetKey("not a key").
+		SetCACrt("not a certificate").Config()
+	require.Error(t, err)
+}
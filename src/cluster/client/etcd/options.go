@@ -21,6 +21,7 @@
 package etcd
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -30,9 +31,13 @@ import (
 	"os"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/kv/audit"
+	etcdkv "github.com/m3db/m3/src/cluster/kv/etcd"
 	"github.com/m3db/m3/src/cluster/services"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/retry"
+	"github.com/m3db/m3/src/x/secrets"
 
 	"google.golang.org/grpc"
 )
@@ -58,6 +63,8 @@ const (
 	defaultRetryJitter         = true
 
 	defaultDirectoryMode = os.FileMode(0755)
+
+	defaultSecretRefreshInterval = time.Minute
 )
 
 type keepAliveOptions struct {
@@ -115,13 +122,20 @@ func (o *keepAliveOptions) SetKeepAliveTimeout(value time.Duration) KeepAliveOpt
 
 // NewTLSOptions creates a set of TLS Options.
 func NewTLSOptions() TLSOptions {
-	return tlsOptions{}
+	return tlsOptions{
+		refreshInterval: defaultSecretRefreshInterval,
+	}
 }
 
 type tlsOptions struct {
 	cert string
 	key  string
 	ca   string
+
+	certPEM         string
+	keyPEM          string
+	caPEM           string
+	refreshInterval time.Duration
 }
 
 func (o tlsOptions) CrtPath() string {
@@ -149,30 +163,136 @@ func (o tlsOptions) SetCACrtPath(ca string) TLSOptions {
 	return o
 }
 
+func (o tlsOptions) Crt() string {
+	return o.certPEM
+}
+
+func (o tlsOptions) SetCrt(cert string) TLSOptions {
+	o.certPEM = cert
+	return o
+}
+
+func (o tlsOptions) Key() string {
+	return o.keyPEM
+}
+
+func (o tlsOptions) SetKey(key string) TLSOptions {
+	o.keyPEM = key
+	return o
+}
+
+func (o tlsOptions) CACrt() string {
+	return o.caPEM
+}
+
+func (o tlsOptions) SetCACrt(ca string) TLSOptions {
+	o.caPEM = ca
+	return o
+}
+
+func (o tlsOptions) RefreshInterval() time.Duration {
+	return o.refreshInterval
+}
+
+func (o tlsOptions) SetRefreshInterval(value time.Duration) TLSOptions {
+	o.refreshInterval = value
+	return o
+}
+
 func (o tlsOptions) Config() (*tls.Config, error) {
-	if o.cert == "" {
+	if o.cert == "" && o.certPEM == "" {
 		// By default we should use nil config instead of empty config.
 		return nil, nil
 	}
 
+	caPool, err := o.caPool()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: false,
+		RootCAs:            caPool,
+	}
+
+	if o.certPEM != "" {
+		cert, err := newRotatingCertificate(o.certPEM, o.keyPEM, o.refreshInterval)
+		if err != nil {
+			return nil, err
+		}
+		cfg.GetClientCertificate = cert.clientCertificate
+		return cfg, nil
+	}
+
 	cert, err := tls.LoadX509KeyPair(o.cert, o.key)
 	if err != nil {
 		return nil, err
 	}
-	caCert, err := ioutil.ReadFile(o.ca)
+	cfg.Certificates = []tls.Certificate{cert}
+	return cfg, nil
+}
+
+func (o tlsOptions) caPool() (*x509.CertPool, error) {
+	var (
+		caCert []byte
+		source = "file " + o.ca
+		err    error
+	)
+	if o.caPEM != "" {
+		source = "CA certificate secret"
+		caCert, err = secrets.DefaultResolver().Resolve(context.Background(), o.caPEM)
+	} else {
+		caCert, err = ioutil.ReadFile(o.ca)
+	}
 	if err != nil {
 		return nil, err
 	}
+
 	caPool := x509.NewCertPool()
 	if ok := caPool.AppendCertsFromPEM(caCert); !ok {
-		return nil, fmt.Errorf("can't read PEM-formatted certificates from file %s as root CA pool", o.ca)
+		return nil, fmt.Errorf("can't read PEM-formatted certificates from %s as root CA pool", source)
 	}
-	return &tls.Config{
-		MinVersion:         tls.VersionTLS12,
-		InsecureSkipVerify: false,
-		Certificates:       []tls.Certificate{cert},
-		RootCAs:            caPool,
-	}, nil
+	return caPool, nil
+}
+
+// NewAuthOptions creates a set of auth Options.
+func NewAuthOptions() AuthOptions {
+	return authOptions{
+		refreshInterval: defaultSecretRefreshInterval,
+	}
+}
+
+type authOptions struct {
+	username        string
+	password        string
+	refreshInterval time.Duration
+}
+
+func (o authOptions) Username() string {
+	return o.username
+}
+
+func (o authOptions) SetUsername(value string) AuthOptions {
+	o.username = value
+	return o
+}
+
+func (o authOptions) Password() string {
+	return o.password
+}
+
+func (o authOptions) SetPassword(value string) AuthOptions {
+	o.password = value
+	return o
+}
+
+func (o authOptions) RefreshInterval() time.Duration {
+	return o.refreshInterval
+}
+
+func (o authOptions) SetRefreshInterval(value time.Duration) AuthOptions {
+	o.refreshInterval = value
+	return o
 }
 
 // NewOptions creates a set of Options.
@@ -212,6 +332,14 @@ type options struct {
 	iopts                  instrument.Options
 	retryOpts              retry.Options
 	newDirectoryMode       os.FileMode
+	rateLimit              ratelimit.Limit
+	cbWindow               time.Duration
+	cbProbeInterval        time.Duration
+	valueCompression       etcdkv.ValueCompression
+	valueCompressionThresh int
+	valueChunkSize         int
+	kvAuditOpts            audit.Options
+	readOnly               bool
 }
 
 func (o options) Validate() error {
@@ -243,6 +371,34 @@ func (o options) Validate() error {
 		return errors.New("invalid request timeout")
 	}
 
+	if o.cbWindow < 0 {
+		return errors.New("invalid circuit breaker window")
+	}
+
+	if o.cbProbeInterval < 0 {
+		return errors.New("invalid circuit breaker probe interval")
+	}
+
+	if o.valueCompression != "" {
+		if err := o.valueCompression.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if o.valueCompressionThresh < 0 {
+		return errors.New("invalid value compression threshold")
+	}
+
+	if o.valueChunkSize < 0 {
+		return errors.New("invalid value chunk size")
+	}
+
+	if o.kvAuditOpts != nil {
+		if err := o.kvAuditOpts.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -410,6 +566,7 @@ func NewCluster() Cluster {
 		dialTimeout:      defaultDialTimeout,
 		keepAliveOpts:    NewKeepAliveOptions(),
 		tlsOpts:          NewTLSOptions(),
+		authOpts:         NewAuthOptions(),
 	}
 }
 
@@ -418,6 +575,7 @@ type cluster struct {
 	endpoints        []string
 	keepAliveOpts    KeepAliveOptions
 	tlsOpts          TLSOptions
+	authOpts         AuthOptions
 	autoSyncInterval time.Duration
 	dialTimeout      time.Duration
 	dialOptions      []grpc.DialOption
@@ -459,6 +617,15 @@ func (c cluster) SetTLSOptions(opts TLSOptions) Cluster {
 	return c
 }
 
+func (c cluster) AuthOptions() AuthOptions {
+	return c.authOpts
+}
+
+func (c cluster) SetAuthOptions(opts AuthOptions) Cluster {
+	c.authOpts = opts
+	return c
+}
+
 func (c cluster) AutoSyncInterval() time.Duration {
 	return c.autoSyncInterval
 }
@@ -488,3 +655,81 @@ func (c cluster) SetDialOptions(opts []grpc.DialOption) Cluster {
 	c.dialOptions = opts
 	return c
 }
+
+//nolint:gocritic
+func (o options) RateLimit() ratelimit.Limit {
+	return o.rateLimit
+}
+
+//nolint:gocritic
+func (o options) SetRateLimit(l ratelimit.Limit) Options {
+	o.rateLimit = l
+	return o
+}
+
+//nolint:gocritic
+func (o options) CircuitBreakerWindow() time.Duration {
+	return o.cbWindow
+}
+
+//nolint:gocritic
+func (o options) SetCircuitBreakerWindow(t time.Duration) Options {
+	o.cbWindow = t
+	return o
+}
+
+//nolint:gocritic
+func (o options) CircuitBreakerProbeInterval() time.Duration {
+	return o.cbProbeInterval
+}
+
+//nolint:gocritic
+func (o options) SetCircuitBreakerProbeInterval(t time.Duration) Options {
+	o.cbProbeInterval = t
+	return o
+}
+
+func (o options) ValueCompression() etcdkv.ValueCompression {
+	return o.valueCompression
+}
+
+func (o options) SetValueCompression(c etcdkv.ValueCompression) Options {
+	o.valueCompression = c
+	return o
+}
+
+func (o options) ValueCompressionThreshold() int {
+	return o.valueCompressionThresh
+}
+
+func (o options) SetValueCompressionThreshold(threshold int) Options {
+	o.valueCompressionThresh = threshold
+	return o
+}
+
+func (o options) ValueChunkSize() int {
+	return o.valueChunkSize
+}
+
+func (o options) SetValueChunkSize(size int) Options {
+	o.valueChunkSize = size
+	return o
+}
+
+func (o options) KVAuditOptions() audit.Options {
+	return o.kvAuditOpts
+}
+
+func (o options) SetKVAuditOptions(opts audit.Options) Options {
+	o.kvAuditOpts = opts
+	return o
+}
+
+func (o options) ReadOnly() bool {
+	return o.readOnly
+}
+
+func (o options) SetReadOnly(readOnly bool) Options {
+	o.readOnly = readOnly
+	return o
+}
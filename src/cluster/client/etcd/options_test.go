@@ -97,6 +97,31 @@ func TestTLSOptions(t *testing.T) {
 	assert.Equal(t, "cert", aOpts.CrtPath())
 	assert.Equal(t, "key", aOpts.KeyPath())
 	assert.Equal(t, "ca", aOpts.CACrtPath())
+
+	assert.Equal(t, "", aOpts.Crt())
+	assert.Equal(t, "", aOpts.Key())
+	assert.Equal(t, "", aOpts.CACrt())
+	assert.Equal(t, defaultSecretRefreshInterval, aOpts.RefreshInterval())
+
+	aOpts = aOpts.SetCrt("secret://env/CRT").SetKey("secret://env/KEY").
+		SetCACrt("secret://env/CA").SetRefreshInterval(time.Hour)
+	assert.Equal(t, "secret://env/CRT", aOpts.Crt())
+	assert.Equal(t, "secret://env/KEY", aOpts.Key())
+	assert.Equal(t, "secret://env/CA", aOpts.CACrt())
+	assert.Equal(t, time.Hour, aOpts.RefreshInterval())
+}
+
+func TestAuthOptions(t *testing.T) {
+	aOpts := NewAuthOptions()
+	assert.Equal(t, "", aOpts.Username())
+	assert.Equal(t, "", aOpts.Password())
+	assert.Equal(t, defaultSecretRefreshInterval, aOpts.RefreshInterval())
+
+	aOpts = aOpts.SetUsername("user").SetPassword("secret://env/PASSWORD").
+		SetRefreshInterval(time.Hour)
+	assert.Equal(t, "user", aOpts.Username())
+	assert.Equal(t, "secret://env/PASSWORD", aOpts.Password())
+	assert.Equal(t, time.Hour, aOpts.RefreshInterval())
 }
 
 func TestOptions(t *testing.T) {
@@ -104,7 +129,9 @@ func TestOptions(t *testing.T) {
 	assert.Equal(t, "", opts.Zone())
 	assert.Equal(t, "", opts.Env())
 	assert.Equal(t,
-		services.NewOptions().SetInstrumentsOptions(opts.ServicesOptions().InstrumentsOptions()),
+		services.NewOptions().
+			SetInstrumentsOptions(opts.ServicesOptions().InstrumentsOptions()).
+			SetAdvertisementRetryOptions(opts.ServicesOptions().AdvertisementRetryOptions()),
 		opts.ServicesOptions())
 	assert.Equal(t, "", opts.CacheDir())
 	assert.Equal(t, "", opts.Service())
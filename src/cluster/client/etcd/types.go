@@ -26,8 +26,11 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/kv/audit"
+	etcdkv "github.com/m3db/m3/src/cluster/kv/etcd"
 	"github.com/m3db/m3/src/cluster/services"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/retry"
 
 	"google.golang.org/grpc"
@@ -92,6 +95,56 @@ type Options interface {
 	SetNewDirectoryMode(fm os.FileMode) Options
 	NewDirectoryMode() os.FileMode
 
+	// RateLimit is the limit of the requests to etcd shared by all the kv
+	// stores of the client
+	RateLimit() ratelimit.Limit
+	// SetRateLimit sets the RateLimit
+	SetRateLimit(l ratelimit.Limit) Options
+
+	// CircuitBreakerWindow is how long requests to etcd must have been failing
+	// for before they fail fast, zero disables the circuit breaker
+	CircuitBreakerWindow() time.Duration
+	// SetCircuitBreakerWindow sets the CircuitBreakerWindow
+	SetCircuitBreakerWindow(t time.Duration) Options
+
+	// CircuitBreakerProbeInterval is the interval at which a request is let
+	// through to probe etcd while requests fail fast, zero uses the default
+	// of the kv stores
+	CircuitBreakerProbeInterval() time.Duration
+	// SetCircuitBreakerProbeInterval sets the CircuitBreakerProbeInterval
+	SetCircuitBreakerProbeInterval(t time.Duration) Options
+
+	// ValueCompression is the compression of the values written to etcd by
+	// the kv stores of the client, empty uses the default of the kv stores
+	ValueCompression() etcdkv.ValueCompression
+	// SetValueCompression sets the ValueCompression
+	SetValueCompression(c etcdkv.ValueCompression) Options
+
+	// ValueCompressionThreshold is the size in bytes from which values are
+	// compressed, zero uses the default of the kv stores
+	ValueCompressionThreshold() int
+	// SetValueCompressionThreshold sets the ValueCompressionThreshold
+	SetValueCompressionThreshold(threshold int) Options
+
+	// ValueChunkSize is the size in bytes from which values are split into
+	// chunks by the kv stores of the client, zero disables chunking
+	ValueChunkSize() int
+	// SetValueChunkSize sets the ValueChunkSize
+	SetValueChunkSize(size int) Options
+
+	// KVAuditOptions are the options of the auditing of the mutations of the
+	// kv stores of the client, nil disables auditing
+	KVAuditOptions() audit.Options
+	// SetKVAuditOptions sets the KVAuditOptions
+	SetKVAuditOptions(opts audit.Options) Options
+
+	// ReadOnly makes the kv stores and services of the client reject every
+	// mutation with a *kv.ReadOnlyError, for clients that must never edit
+	// placements or other cluster state
+	ReadOnly() bool
+	// SetReadOnly sets the ReadOnly
+	SetReadOnly(readOnly bool) Options
+
 	Validate() error
 }
 
@@ -141,9 +194,44 @@ type TLSOptions interface {
 	CACrtPath() string
 	SetCACrtPath(string) TLSOptions
 
+	// Crt is the PEM encoded client certificate, or a secret reference to
+	// it, used instead of the certificate at CrtPath.
+	Crt() string
+	SetCrt(string) TLSOptions
+
+	// Key is the PEM encoded client key, or a secret reference to it.
+	Key() string
+	SetKey(string) TLSOptions
+
+	// CACrt is the PEM encoded CA certificate, or a secret reference to it,
+	// used instead of the CA certificate at CACrtPath.
+	CACrt() string
+	SetCACrt(string) TLSOptions
+
+	// RefreshInterval is how often the client certificate and key secret
+	// references are re-resolved so that rotated certificates are used.
+	RefreshInterval() time.Duration
+	SetRefreshInterval(time.Duration) TLSOptions
+
 	Config() (*tls.Config, error)
 }
 
+// AuthOptions defines the options for etcd authentication.
+type AuthOptions interface {
+	// Username is the etcd user, authentication is disabled if empty.
+	Username() string
+	SetUsername(string) AuthOptions
+
+	// Password is the password of the etcd user, or a secret reference to it.
+	Password() string
+	SetPassword(string) AuthOptions
+
+	// RefreshInterval is how often the password secret reference is
+	// re-resolved so that rotated passwords are used.
+	RefreshInterval() time.Duration
+	SetRefreshInterval(time.Duration) AuthOptions
+}
+
 // Cluster defines the configuration for a etcd cluster.
 type Cluster interface {
 	Zone() string
@@ -158,6 +246,9 @@ type Cluster interface {
 	TLSOptions() TLSOptions
 	SetTLSOptions(TLSOptions) Cluster
 
+	AuthOptions() AuthOptions
+	SetAuthOptions(AuthOptions) Cluster
+
 	AutoSyncInterval() time.Duration
 
 	// SetAutoSyncInterval sets the etcd client to autosync cluster endpoints periodically. This defaults to
@@ -0,0 +1,157 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package kvstore provides a cluster client backed by a kv store other than
+// etcd, such as consul or zookeeper.
+package kvstore
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	hierarchySeparator = "/"
+	internalPrefix     = "_"
+	kvPrefix           = "_kv"
+)
+
+var (
+	errTxnNotSupported       = errors.New("transactions are not supported by the kv store")
+	errHeartbeatNotSupported = errors.New("heartbeats are not supported by the kv store")
+	errLeaderNotSupported    = errors.New("leader election is not supported by the kv store")
+	errInvalidNamespace      = errors.New("invalid namespace")
+)
+
+// StoreFn creates a kv store whose keys are prefixed with the prefix, an
+// empty prefix leaves the keys as is.
+type StoreFn func(prefix string) (kv.Store, error)
+
+// NewClient creates a cluster client whose kv stores are created by the
+// store function. Stores are not zoned, and transactions, heartbeats and
+// leader election are not supported.
+func NewClient(
+	storeFn StoreFn,
+	env string,
+	sdOpts services.Options,
+	iopts instrument.Options,
+) client.Client {
+	return &kvclient{
+		storeFn: storeFn,
+		env:     env,
+		sdOpts:  sdOpts,
+		iopts:   iopts,
+		stores:  make(map[string]kv.Store),
+	}
+}
+
+type kvclient struct {
+	sync.Mutex
+
+	storeFn StoreFn
+	env     string
+	sdOpts  services.Options
+	iopts   instrument.Options
+	stores  map[string]kv.Store
+}
+
+func (c *kvclient) Services(opts services.OverrideOptions) (services.Services, error) {
+	if opts == nil {
+		opts = services.NewOverrideOptions()
+	}
+	iopts := c.iopts.SetMetricsScope(c.iopts.MetricsScope().SubScope("services"))
+	return services.NewServices(c.sdOpts.
+		SetKVGen(func(zone string) (kv.Store, error) {
+			return c.store("")
+		}).
+		SetHeartbeatGen(func(sid services.ServiceID) (services.HeartbeatService, error) {
+			return nil, errHeartbeatNotSupported
+		}).
+		SetLeaderGen(func(sid services.ServiceID, eo services.ElectionOptions) (services.LeaderService, error) {
+			return nil, errLeaderNotSupported
+		}).
+		SetNamespaceOptions(opts.NamespaceOptions()).
+		SetInstrumentsOptions(iopts),
+	)
+}
+
+func (c *kvclient) KV() (kv.Store, error) {
+	return c.Store(kv.NewOverrideOptions())
+}
+
+func (c *kvclient) Txn() (kv.TxnStore, error) {
+	return nil, errTxnNotSupported
+}
+
+func (c *kvclient) Store(opts kv.OverrideOptions) (kv.Store, error) {
+	namespace := opts.Namespace()
+	if namespace == "" {
+		namespace = kvPrefix
+	} else if err := validateTopLevelNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	env := opts.Environment()
+	if env == "" {
+		env = c.env
+	}
+
+	prefix := namespace
+	if env != "" {
+		prefix += hierarchySeparator + env
+	}
+	return c.store(prefix)
+}
+
+func (c *kvclient) TxnStore(opts kv.OverrideOptions) (kv.TxnStore, error) {
+	return nil, errTxnNotSupported
+}
+
+func (c *kvclient) store(prefix string) (kv.Store, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if store, ok := c.stores[prefix]; ok {
+		return store, nil
+	}
+	store, err := c.storeFn(prefix)
+	if err != nil {
+		return nil, err
+	}
+	c.stores[prefix] = store
+	return store, nil
+}
+
+func validateTopLevelNamespace(namespace string) error {
+	if namespace == "" || namespace == hierarchySeparator {
+		return errInvalidNamespace
+	}
+	if strings.HasPrefix(namespace, internalPrefix) ||
+		strings.HasPrefix(namespace, hierarchySeparator+internalPrefix) {
+		return errInvalidNamespace
+	}
+	return nil
+}
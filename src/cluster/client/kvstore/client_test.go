@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientStores(t *testing.T) {
+	var prefixes []string
+	c := NewClient(func(prefix string) (kv.Store, error) {
+		prefixes = append(prefixes, prefix)
+		return mem.NewStore(), nil
+	}, "env", services.NewOptions(), instrument.NewOptions())
+
+	s1, err := c.KV()
+	require.NoError(t, err)
+	s2, err := c.Store(kv.NewOverrideOptions())
+	require.NoError(t, err)
+	require.Equal(t, s1, s2)
+
+	_, err = c.Store(kv.NewOverrideOptions().SetNamespace("ns").SetEnvironment("other"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"_kv/env", "ns/other"}, prefixes)
+
+	_, err = c.Store(kv.NewOverrideOptions().SetNamespace("_ns"))
+	require.Equal(t, errInvalidNamespace, err)
+
+	_, err = c.Txn()
+	require.Equal(t, errTxnNotSupported, err)
+	_, err = c.TxnStore(kv.NewOverrideOptions())
+	require.Equal(t, errTxnNotSupported, err)
+}
+
+func TestClientServices(t *testing.T) {
+	c := NewClient(func(prefix string) (kv.Store, error) {
+		return mem.NewStore(), nil
+	}, "env", services.NewOptions(), instrument.NewOptions())
+
+	svcs, err := c.Services(nil)
+	require.NoError(t, err)
+
+	sid := services.NewServiceID().SetName("svc").SetZone("zone")
+	_, err = svcs.HeartbeatService(sid)
+	require.Equal(t, errHeartbeatNotSupported, err)
+
+	_, err = svcs.LeaderService(sid, services.NewElectionOptions())
+	require.Equal(t, errLeaderNotSupported, err)
+}
@@ -46,9 +46,12 @@ func NewWatchManager(opts Options) (WatchManager, error) {
 			etcdWatchCreate: scope.Counter("etcd-watch-create"),
 			etcdWatchError:  scope.Counter("etcd-watch-error"),
 			etcdWatchReset:  scope.Counter("etcd-watch-reset"),
+			etcdWatchGap:    scope.Counter("etcd-watch-gap"),
 		},
 		updateFn:      opts.UpdateFn(),
 		tickAndStopFn: opts.TickAndStopFn(),
+		watchGapFn:    opts.WatchGapFn(),
+		watchLagFn:    opts.WatchLagFn(),
 	}, nil
 }
 
@@ -59,15 +62,22 @@ type manager struct {
 
 	updateFn      UpdateFn
 	tickAndStopFn TickAndStopFn
+	watchGapFn    WatchGapFn
+	watchLagFn    WatchLagFn
 }
 
 type metrics struct {
 	etcdWatchCreate tally.Counter
 	etcdWatchError  tally.Counter
 	etcdWatchReset  tally.Counter
+	etcdWatchGap    tally.Counter
 }
 
 func (w *manager) watchChanWithTimeout(key string, rev int64) (clientv3.WatchChan, context.CancelFunc, error) {
+	if err := w.waitRateLimiter(); err != nil {
+		return nil, func() {}, fmt.Errorf("etcd watch create rate limited for key: %s: %w", key, err)
+	}
+
 	doneCh := make(chan struct{})
 
 	ctx, cancelFn := context.WithCancel(clientv3.WithRequireLeader(context.Background()))
@@ -116,12 +126,47 @@ func (w *manager) watchChanWithTimeout(key string, rev int64) (clientv3.WatchCha
 	}
 }
 
+// waitRateLimiter waits for the rate limiter of watch creations if any, so
+// that watches recreated at the same time, e.g. after an etcd restart, do not
+// overload etcd.
+func (w *manager) waitRateLimiter() error {
+	l := w.opts.RateLimiter()
+	if l == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.opts.WatchChanInitTimeout())
+	defer cancel()
+	return l.WaitN(ctx, 1)
+}
+
+// handleWatchGap is called when the revision to resume a watch from has been
+// compacted, it refreshes the value with a fresh get since intermediate
+// updates may have been missed and notifies the WatchGapFn if set.
+func (w *manager) handleWatchGap(logger *zap.Logger, key string, fromRev, compactRev int64) {
+	w.m.etcdWatchGap.Inc(1)
+	logger.Warn("watch revision compacted, updates may have been missed",
+		zap.Int64("from_revision", fromRev),
+		zap.Int64("compact_revision", compactRev))
+
+	if err := w.updateFn(key, nil); err != nil {
+		logger.Error("failed to get value for key after watch gap", zap.Error(err))
+	}
+
+	if w.watchGapFn != nil {
+		w.watchGapFn(key, fromRev, compactRev)
+	}
+}
+
 func (w *manager) Watch(key string) {
 	var (
 		ticker = time.NewTicker(w.opts.WatchChanCheckInterval())
 		logger = w.logger.With(zap.String("watch_key", key))
 		rnd    = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
 
+		// revOverride is the revision to resume the watch from when it is
+		// recreated, it is advanced past the last seen revision so that
+		// updates made while the watch was disconnected are not lost.
 		revOverride          int64
 		firstUpdateSucceeded bool
 		watchChan            clientv3.WatchChan
@@ -186,6 +231,7 @@ func (w *manager) Watch(key string) {
 				)
 				w.m.etcdWatchError.Inc(1)
 				if err == rpctypes.ErrCompacted {
+					w.handleWatchGap(logger, key, revOverride, r.CompactRevision)
 					revOverride = r.CompactRevision
 					logger.Warn("compacted; recreating watch at revision",
 						zap.Int64("revision", revOverride))
@@ -195,20 +241,22 @@ func (w *manager) Watch(key string) {
 
 				resetWatchWithSleep()
 				continue
-			} else if r.IsProgressNotify() {
-				if r.CompactRevision > revOverride {
-					// we only care about last event as this watchmanager implementation does not support
-					// watching key ranges, only single keys.
-					// set revOverride to minimum non-compacted revision if watch was
-					// initialized with an older rev., since we really don't care about history.
-					// this may help recover faster (one less retry) on connection loss/leader change
-					// around compaction, if we were watching on a revision that's already compacted.
-					revOverride = r.CompactRevision
-				}
+			}
+
+			// checkpoint the last seen revision so a recreated watch resumes
+			// right after it instead of at the current revision.
+			revOverride = resumeRevision(r, revOverride)
+			if r.IsProgressNotify() {
 				// Do not call updateFn on ProgressNotify as it happens periodically with no update events
 				continue
 			}
 
+			if w.watchLagFn != nil && len(r.Events) > 0 {
+				// NB: the header revision is the revision of the store when the
+				// response was sent, a lagging watch receives events well behind it.
+				w.watchLagFn(key, r.Header.Revision-r.Events[len(r.Events)-1].Kv.ModRevision)
+			}
+
 			if err = w.updateFn(key, r.Events); err != nil {
 				logger.Error("received notification for key, but failed to get value", zap.Error(err))
 			}
@@ -220,3 +268,20 @@ func (w *manager) Watch(key string) {
 		}
 	}
 }
+
+// resumeRevision returns the revision a watch should be resumed from after
+// receiving the watch response.
+func resumeRevision(r clientv3.WatchResponse, rev int64) int64 {
+	var next int64
+	if n := len(r.Events); n > 0 {
+		next = r.Events[n-1].Kv.ModRevision + 1
+	} else if r.IsProgressNotify() {
+		// progress notifications are only sent once all events up to the
+		// header revision have been delivered.
+		next = r.Header.Revision + 1
+	}
+	if next > rev {
+		return next
+	}
+	return rev
+}
@@ -30,6 +30,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"golang.org/x/net/context"
 
@@ -113,6 +115,35 @@ func TestWatchSimple(t *testing.T) {
 	require.Equal(t, lastRead, atomic.LoadInt32(updateCalled))
 }
 
+func TestWatchLag(t *testing.T) {
+	wh, ec, updateCalled, shouldStop, doneCh, closer := testSetup(t)
+	defer closer()
+	integration.WaitClientV3(t, ec)
+
+	var lagCalled, lastLag int64
+	wh.watchLagFn = func(key string, lag int64) {
+		require.Equal(t, "foo", key)
+		atomic.StoreInt64(&lastLag, lag)
+		atomic.AddInt64(&lagCalled, 1)
+	}
+
+	go wh.Watch("foo")
+
+	time.Sleep(3 * wh.opts.WatchChanInitTimeout())
+
+	_, err := ec.Put(context.Background(), "foo", "v")
+	require.NoError(t, err)
+
+	require.True(t, clock.WaitUntil(func() bool {
+		return atomic.LoadInt32(updateCalled) == 1
+	}, 30*time.Second))
+	require.Equal(t, int64(1), atomic.LoadInt64(&lagCalled))
+	require.Equal(t, int64(0), atomic.LoadInt64(&lastLag))
+
+	atomic.AddInt32(shouldStop, 1)
+	<-doneCh
+}
+
 func TestWatchRecreate(t *testing.T) {
 	wh, ecluster, updateCalled, shouldStop, doneCh, closer := testCluster(t)
 	defer closer()
@@ -284,6 +315,13 @@ func TestWatchCompactedRevision(t *testing.T) {
 	ts := tally.NewTestScope("", nil)
 	errC := ts.Counter("errors")
 	wh.m.etcdWatchError = errC
+	wh.m.etcdWatchGap = ts.Counter("gaps")
+
+	var gapCompactRev int64
+	wh.watchGapFn = func(key string, _, compactRev int64) {
+		require.Equal(t, "foo", key)
+		atomic.StoreInt64(&gapCompactRev, compactRev)
+	}
 
 	var compactRev int64
 	for i := 1; i <= 10; i++ {
@@ -302,8 +340,10 @@ func TestWatchCompactedRevision(t *testing.T) {
 
 	go wh.Watch("foo")
 
+	// created notification, fresh get after the gap, created notification
+	// and the event at the compacted revision.
 	require.True(t, clock.WaitUntil(func() bool {
-		return atomic.LoadInt32(updateCalled) == 3
+		return atomic.LoadInt32(updateCalled) == 4
 	}, 30*time.Second))
 
 	lastRead := atomic.LoadInt32(updateCalled)
@@ -315,11 +355,69 @@ func TestWatchCompactedRevision(t *testing.T) {
 
 	errN := ts.Snapshot().Counters()["errors+"].Value()
 	assert.Equal(t, int64(1), errN, "expected to encounter watch error")
+	gapN := ts.Snapshot().Counters()["gaps+"].Value()
+	assert.Equal(t, int64(1), gapN, "expected to encounter watch gap")
+	assert.Equal(t, compactRev, atomic.LoadInt64(&gapCompactRev))
 
 	atomic.AddInt32(shouldStop, 1)
 	<-doneCh
 }
 
+func TestResumeRevision(t *testing.T) {
+	event := func(rev int64) *clientv3.Event {
+		return &clientv3.Event{Kv: &mvccpb.KeyValue{ModRevision: rev}}
+	}
+
+	tests := []struct {
+		name     string
+		resp     clientv3.WatchResponse
+		rev      int64
+		expected int64
+	}{
+		{
+			name: "events",
+			resp: clientv3.WatchResponse{
+				Header: etcdserverpb.ResponseHeader{Revision: 20},
+				Events: []*clientv3.Event{event(7), event(9)},
+			},
+			rev:      5,
+			expected: 10,
+		},
+		{
+			name: "progress notify",
+			resp: clientv3.WatchResponse{
+				Header: etcdserverpb.ResponseHeader{Revision: 20},
+			},
+			rev:      10,
+			expected: 21,
+		},
+		{
+			name: "created notify",
+			resp: clientv3.WatchResponse{
+				Header:  etcdserverpb.ResponseHeader{Revision: 20},
+				Created: true,
+			},
+			rev:      5,
+			expected: 5,
+		},
+		{
+			name: "stale events",
+			resp: clientv3.WatchResponse{
+				Header: etcdserverpb.ResponseHeader{Revision: 20},
+				Events: []*clientv3.Event{event(3)},
+			},
+			rev:      10,
+			expected: 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, resumeRevision(test.resp, test.rev))
+		})
+	}
+}
+
 func testCluster(t *testing.T) (
 	*manager,
 	*integration.Cluster,
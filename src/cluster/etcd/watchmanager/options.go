@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/ratelimit"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
@@ -57,11 +58,14 @@ type options struct {
 	client        *clientv3.Client
 	updateFn      UpdateFn
 	tickAndStopFn TickAndStopFn
+	watchGapFn    WatchGapFn
+	watchLagFn    WatchLagFn
 
 	wopts                  []clientv3.OpOption
 	watchChanCheckInterval time.Duration
 	watchChanResetInterval time.Duration
 	watchChanInitTimeout   time.Duration
+	rateLimiter            ratelimit.Limiter
 	iopts                  instrument.Options
 }
 
@@ -125,6 +129,26 @@ func (o *options) SetTickAndStopFn(f TickAndStopFn) Options {
 	return &opts
 }
 
+func (o *options) WatchGapFn() WatchGapFn {
+	return o.watchGapFn
+}
+
+func (o *options) SetWatchGapFn(f WatchGapFn) Options {
+	opts := *o
+	opts.watchGapFn = f
+	return &opts
+}
+
+func (o *options) WatchLagFn() WatchLagFn {
+	return o.watchLagFn
+}
+
+func (o *options) SetWatchLagFn(f WatchLagFn) Options {
+	opts := *o
+	opts.watchLagFn = f
+	return &opts
+}
+
 func (o *options) WatchOptions() []clientv3.OpOption {
 	return o.wopts
 }
@@ -135,6 +159,16 @@ func (o *options) SetWatchOptions(options []clientv3.OpOption) Options {
 	return &opts
 }
 
+func (o *options) RateLimiter() ratelimit.Limiter {
+	return o.rateLimiter
+}
+
+func (o *options) SetRateLimiter(l ratelimit.Limiter) Options {
+	opts := *o
+	opts.rateLimiter = l
+	return &opts
+}
+
 func (o *options) InstrumentsOptions() instrument.Options {
 	return o.iopts
 }
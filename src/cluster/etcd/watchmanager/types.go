@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/ratelimit"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
@@ -37,6 +38,15 @@ type WatchManager interface {
 // UpdateFn is called when an event on the watch channel happens
 type UpdateFn func(key string, events []*clientv3.Event) error
 
+// WatchGapFn is called when a watch could not be resumed from the last seen
+// revision because it has been compacted, updates between fromRev and
+// compactRev may have been missed
+type WatchGapFn func(key string, fromRev, compactRev int64)
+
+// WatchLagFn is called with the number of revisions the events received on
+// the watch channel lag behind the revision of the store when they were sent
+type WatchLagFn func(key string, lag int64)
+
 // TickAndStopFn is called every once a while
 // to check and stop the watch if needed
 type TickAndStopFn func(key string) bool
@@ -58,6 +68,18 @@ type Options interface {
 	// SetTickAndStopFn sets the TickAndStopFn
 	SetTickAndStopFn(f TickAndStopFn) Options
 
+	// WatchGapFn is the function called when updates on a key may have been
+	// missed because the revision to resume the watch from has been compacted
+	WatchGapFn() WatchGapFn
+	// SetWatchGapFn sets the WatchGapFn
+	SetWatchGapFn(f WatchGapFn) Options
+
+	// WatchLagFn is the function called with the revision lag of the events
+	// received on a key
+	WatchLagFn() WatchLagFn
+	// SetWatchLagFn sets the WatchLagFn
+	SetWatchLagFn(f WatchLagFn) Options
+
 	// WatchOptions is a set of options for the etcd watch
 	WatchOptions() []clientv3.OpOption
 	// SetWatchOptions sets the WatchOptions
@@ -79,6 +101,11 @@ type Options interface {
 	// SetWatchChanInitTimeout sets the WatchChanInitTimeout
 	SetWatchChanInitTimeout(t time.Duration) Options
 
+	// RateLimiter is the limiter of watch creations, nil if unlimited
+	RateLimiter() ratelimit.Limiter
+	// SetRateLimiter sets the RateLimiter
+	SetRateLimiter(l ratelimit.Limiter) Options
+
 	// InstrumentsOptions is the instrument options
 	InstrumentsOptions() instrument.Options
 	// SetInstrumentsOptions sets the InstrumentsOptions
@@ -32,6 +32,9 @@
 		KeyValueUpdateResult
 		QueryLimits
 		QueryLimit
+		KeyValueCache
+		CachedValue
+		Lease
 */
 package kvpb
 
@@ -196,11 +199,94 @@ func (m *QueryLimit) GetForceWaited() bool {
 	return false
 }
 
+type KeyValueCache struct {
+	Values []*CachedValue `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
+}
+
+func (m *KeyValueCache) Reset()                    { *m = KeyValueCache{} }
+func (m *KeyValueCache) String() string            { return proto.CompactTextString(m) }
+func (*KeyValueCache) ProtoMessage()               {}
+func (*KeyValueCache) Descriptor() ([]byte, []int) { return fileDescriptorKv, []int{4} }
+
+func (m *KeyValueCache) GetValues() []*CachedValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type CachedValue struct {
+	Key      string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value    []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Version  int64  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Revision int64  `protobuf:"varint,4,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (m *CachedValue) Reset()                    { *m = CachedValue{} }
+func (m *CachedValue) String() string            { return proto.CompactTextString(m) }
+func (*CachedValue) ProtoMessage()               {}
+func (*CachedValue) Descriptor() ([]byte, []int) { return fileDescriptorKv, []int{5} }
+
+func (m *CachedValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *CachedValue) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *CachedValue) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *CachedValue) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type Lease struct {
+	Owner          string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	ExpiresAtNanos int64  `protobuf:"varint,2,opt,name=expiresAtNanos,proto3" json:"expiresAtNanos,omitempty"`
+}
+
+func (m *Lease) Reset()                    { *m = Lease{} }
+func (m *Lease) String() string            { return proto.CompactTextString(m) }
+func (*Lease) ProtoMessage()               {}
+func (*Lease) Descriptor() ([]byte, []int) { return fileDescriptorKv, []int{6} }
+
+func (m *Lease) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Lease) GetExpiresAtNanos() int64 {
+	if m != nil {
+		return m.ExpiresAtNanos
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*KeyValueUpdate)(nil), "kvpb.KeyValueUpdate")
 	proto.RegisterType((*KeyValueUpdateResult)(nil), "kvpb.KeyValueUpdateResult")
 	proto.RegisterType((*QueryLimits)(nil), "kvpb.QueryLimits")
 	proto.RegisterType((*QueryLimit)(nil), "kvpb.QueryLimit")
+	proto.RegisterType((*KeyValueCache)(nil), "kvpb.KeyValueCache")
+	proto.RegisterType((*CachedValue)(nil), "kvpb.CachedValue")
+	proto.RegisterType((*Lease)(nil), "kvpb.Lease")
 }
 func (m *KeyValueUpdate) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
@@ -384,6 +470,105 @@ func (m *QueryLimit) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *KeyValueCache) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeyValueCache) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, msg := range m.Values {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintKv(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *CachedValue) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CachedValue) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintKv(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Value) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintKv(dAtA, i, uint64(len(m.Value)))
+		i += copy(dAtA[i:], m.Value)
+	}
+	if m.Version != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintKv(dAtA, i, uint64(m.Version))
+	}
+	if m.Revision != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintKv(dAtA, i, uint64(m.Revision))
+	}
+	return i, nil
+}
+
+func (m *Lease) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Lease) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintKv(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if m.ExpiresAtNanos != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintKv(dAtA, i, uint64(m.ExpiresAtNanos))
+	}
+	return i, nil
+}
+
 func encodeVarintKv(dAtA []byte, offset int, v uint64) int {
 	for v >= 1<<7 {
 		dAtA[offset] = uint8(v&0x7f | 0x80)
@@ -468,6 +653,51 @@ func (m *QueryLimit) Size() (n int) {
 	return n
 }
 
+func (m *KeyValueCache) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, e := range m.Values {
+			l = e.Size()
+			n += 1 + l + sovKv(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *CachedValue) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovKv(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovKv(uint64(l))
+	}
+	if m.Version != 0 {
+		n += 1 + sovKv(uint64(m.Version))
+	}
+	if m.Revision != 0 {
+		n += 1 + sovKv(uint64(m.Revision))
+	}
+	return n
+}
+
+func (m *Lease) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovKv(uint64(l))
+	}
+	if m.ExpiresAtNanos != 0 {
+		n += 1 + sovKv(uint64(m.ExpiresAtNanos))
+	}
+	return n
+}
+
 func sovKv(x uint64) (n int) {
 	for {
 		n++
@@ -1056,6 +1286,333 @@ func (m *QueryLimit) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *KeyValueCache) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowKv
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeyValueCache: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeyValueCache: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthKv
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, &CachedValue{})
+			if err := m.Values[len(m.Values)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipKv(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthKv
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CachedValue) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowKv
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CachedValue: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CachedValue: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthKv
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthKv
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = append(m.Value[:0], dAtA[iNdEx:postIndex]...)
+			if m.Value == nil {
+				m.Value = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Revision", wireType)
+			}
+			m.Revision = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Revision |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipKv(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthKv
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Lease) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowKv
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Lease: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Lease: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthKv
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiresAtNanos", wireType)
+			}
+			m.ExpiresAtNanos = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpiresAtNanos |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipKv(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthKv
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipKv(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -1166,30 +1723,36 @@ func init() {
 }
 
 var fileDescriptorKv = []byte{
-	// 391 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0x4d, 0xab, 0xd3, 0x40,
-	0x14, 0x35, 0xe6, 0xf9, 0x78, 0xef, 0x16, 0x35, 0x0c, 0x0f, 0xe9, 0x2a, 0x84, 0xa0, 0xd0, 0x55,
-	0x06, 0xec, 0xd6, 0x55, 0xd1, 0x95, 0x15, 0x74, 0x8a, 0x1f, 0x0b, 0x37, 0x93, 0x99, 0xdb, 0x1a,
-	0x32, 0xc9, 0x94, 0xcc, 0xa4, 0x36, 0xbf, 0xc0, 0xad, 0x0b, 0x7f, 0x94, 0x4b, 0x7f, 0x82, 0xd4,
-	0x3f, 0x22, 0x33, 0x29, 0xb4, 0x95, 0xd6, 0x76, 0x13, 0xee, 0x39, 0xb9, 0xe7, 0xdc, 0x3b, 0x87,
-	0x0b, 0x2f, 0x16, 0x85, 0xfd, 0xd2, 0xe6, 0x99, 0xd0, 0x15, 0xad, 0xc6, 0x32, 0xa7, 0xd5, 0x98,
-	0x9a, 0x46, 0x50, 0xa1, 0x5a, 0x63, 0xb1, 0xa1, 0x0b, 0xac, 0xb1, 0xe1, 0x16, 0x25, 0x5d, 0x36,
-	0xda, 0x6a, 0x5a, 0xae, 0x96, 0x39, 0x2d, 0x57, 0x99, 0x47, 0xe4, 0xca, 0xc1, 0xf4, 0x2d, 0x3c,
-	0x7a, 0x8d, 0xdd, 0x07, 0xae, 0x5a, 0x7c, 0xbf, 0x94, 0xdc, 0x22, 0x89, 0x20, 0x2c, 0xb1, 0x1b,
-	0x06, 0x49, 0x30, 0xba, 0x65, 0xae, 0x24, 0x77, 0xf0, 0x60, 0xe5, 0x1a, 0x86, 0xf7, 0x3d, 0xd7,
-	0x03, 0xf2, 0x04, 0xae, 0x85, 0xae, 0xaa, 0xc2, 0x0e, 0xc3, 0x24, 0x18, 0xdd, 0xb0, 0x2d, 0x4a,
-	0xa7, 0x70, 0x77, 0xe8, 0xc8, 0xd0, 0xb4, 0xca, 0x1e, 0xf1, 0x8d, 0x20, 0xd4, 0x4a, 0x6e, 0x5d,
-	0x5d, 0xe9, 0x98, 0x1a, 0xbf, 0x7a, 0xc3, 0x5b, 0xe6, 0xca, 0xf4, 0x5b, 0x08, 0x83, 0x77, 0x2d,
-	0x36, 0xdd, 0xb4, 0xa8, 0x0a, 0x6b, 0xc8, 0x27, 0x88, 0x2b, 0xbe, 0x66, 0x28, 0xb0, 0xb6, 0xaa,
-	0x73, 0x7f, 0x0a, 0x94, 0x33, 0xf7, 0x35, 0x13, 0xa5, 0x45, 0x69, 0xfc, 0x80, 0xc1, 0xf3, 0x28,
-	0x73, 0xcf, 0xcb, 0x76, 0x52, 0x76, 0x46, 0x47, 0xe6, 0xf0, 0xec, 0x54, 0xc7, 0xcb, 0xc2, 0x94,
-	0x93, 0xce, 0xa2, 0x61, 0xc8, 0xfb, 0x7d, 0x8f, 0x0d, 0xb8, 0x4c, 0x4e, 0x3e, 0x43, 0xf2, 0xbf,
-	0x46, 0x3f, 0x22, 0x3c, 0x31, 0xe2, 0xac, 0xf2, 0x78, 0x3e, 0x6f, 0xd0, 0x72, 0xc9, 0x2d, 0xf7,
-	0xde, 0x57, 0x97, 0xe7, 0xb3, 0xaf, 0x4b, 0x7f, 0x04, 0x00, 0xbb, 0x76, 0x77, 0x14, 0xca, 0x15,
-	0x3e, 0xef, 0x90, 0xf5, 0x80, 0x8c, 0xe0, 0xb1, 0xd2, 0xba, 0xcc, 0xb9, 0x28, 0x67, 0x28, 0x74,
-	0x2d, 0x8d, 0x8f, 0x2b, 0x64, 0xff, 0xd2, 0xe4, 0x29, 0x3c, 0x9c, 0xeb, 0x46, 0xe0, 0xab, 0xb5,
-	0x40, 0x94, 0x28, 0xb7, 0x57, 0x74, 0x48, 0x92, 0x04, 0x06, 0x9e, 0xf8, 0xc8, 0x0b, 0x8b, 0xfd,
-	0xee, 0x37, 0x6c, 0x9f, 0x9a, 0x44, 0x3f, 0x37, 0x71, 0xf0, 0x6b, 0x13, 0x07, 0xbf, 0x37, 0x71,
-	0xf0, 0xfd, 0x4f, 0x7c, 0x2f, 0xbf, 0xf6, 0xf7, 0x3d, 0xfe, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x1c,
-	0x39, 0x9e, 0xf7, 0x1f, 0x03, 0x00, 0x00,
+	// 484 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x8d, 0x53, 0xcb, 0x6e, 0x13, 0x31,
+	0x14, 0x25, 0x4c, 0x9a, 0x86, 0x1b, 0x5a, 0x52, 0xab, 0x42, 0x11, 0x8b, 0x28, 0x1a, 0x01, 0x2a,
+	0x9b, 0x8c, 0x44, 0x77, 0x55, 0x37, 0x04, 0xba, 0x22, 0x20, 0x70, 0xc5, 0x63, 0xc1, 0xc6, 0xe3,
+	0xb9, 0x6d, 0xad, 0x99, 0x8c, 0x23, 0xdb, 0x93, 0x66, 0xbe, 0x80, 0x2d, 0x0b, 0x3e, 0x8a, 0x25,
+	0x9f, 0x50, 0x95, 0x1f, 0xc1, 0xf6, 0x4c, 0x68, 0x5a, 0xa5, 0xa4, 0x0b, 0x5b, 0xf7, 0x9c, 0xfb,
+	0xb2, 0xaf, 0x8f, 0xe1, 0xf0, 0x54, 0x98, 0xb3, 0x22, 0x1e, 0x72, 0x39, 0x89, 0x26, 0xfb, 0x49,
+	0x6c, 0xb7, 0x48, 0x2b, 0x1e, 0xf1, 0xac, 0xd0, 0x06, 0x55, 0x74, 0x8a, 0x39, 0x2a, 0x66, 0x30,
+	0x89, 0xa6, 0x4a, 0x1a, 0x19, 0xa5, 0xb3, 0x69, 0x6c, 0xb7, 0xa1, 0x47, 0xa4, 0xe9, 0x60, 0xf8,
+	0x01, 0xb6, 0xdf, 0x62, 0xf9, 0x99, 0x65, 0x05, 0x7e, 0x9a, 0x26, 0x36, 0x98, 0x74, 0x21, 0x48,
+	0xb1, 0xec, 0x35, 0x06, 0x8d, 0xbd, 0x07, 0xd4, 0x99, 0x64, 0x17, 0x36, 0x66, 0x2e, 0xa0, 0x77,
+	0xdf, 0x73, 0x15, 0x20, 0x8f, 0xa1, 0x65, 0x1b, 0x4f, 0x84, 0xe9, 0x05, 0x96, 0x6e, 0xd3, 0x1a,
+	0x85, 0x63, 0xd8, 0xbd, 0x5e, 0x91, 0xa2, 0x2e, 0x32, 0xb3, 0xa2, 0xae, 0x65, 0x64, 0x96, 0xd4,
+	0x55, 0x9d, 0xe9, 0x98, 0x1c, 0xcf, 0x7d, 0x41, 0xcb, 0x58, 0x33, 0xfc, 0x1e, 0x40, 0xe7, 0x63,
+	0x81, 0xaa, 0x1c, 0x0b, 0x5b, 0x5c, 0x93, 0xaf, 0xd0, 0x9f, 0xb0, 0x39, 0x45, 0x8e, 0xb9, 0xc9,
+	0x4a, 0xe7, 0x11, 0x98, 0x1c, 0xbb, 0x5d, 0x8f, 0x32, 0xc9, 0x53, 0xed, 0x1b, 0x74, 0x5e, 0x76,
+	0x87, 0xee, 0x7a, 0xc3, 0xab, 0x54, 0xba, 0x26, 0x8f, 0x9c, 0xc0, 0xb3, 0xdb, 0x22, 0xde, 0x08,
+	0x9d, 0x8e, 0x4a, 0x83, 0x9a, 0x22, 0xab, 0xce, 0xbb, 0xaa, 0xc1, 0xdd, 0xd2, 0xc9, 0x37, 0x18,
+	0xfc, 0x2f, 0xd0, 0xb7, 0x08, 0x6e, 0x69, 0xb1, 0x36, 0x73, 0xf5, 0x7c, 0xde, 0xa1, 0x61, 0xf6,
+	0x25, 0x98, 0xaf, 0xdd, 0xbc, 0xfb, 0x7c, 0x96, 0xf3, 0xc2, 0x9f, 0x0d, 0x80, 0xab, 0x70, 0x27,
+	0x8a, 0xcc, 0x19, 0x7e, 0xde, 0x01, 0xad, 0x00, 0xd9, 0x83, 0x47, 0x99, 0x94, 0x69, 0xcc, 0x78,
+	0x7a, 0x8c, 0x5c, 0xe6, 0x89, 0xf6, 0xe3, 0x0a, 0xe8, 0x4d, 0x9a, 0x3c, 0x85, 0xad, 0x13, 0xa9,
+	0x38, 0x1e, 0xcd, 0x39, 0x62, 0x82, 0x49, 0xad, 0xa2, 0xeb, 0x24, 0x19, 0x40, 0xc7, 0x13, 0x5f,
+	0x98, 0xb0, 0x3a, 0xf6, 0x67, 0x6f, 0xd3, 0x65, 0x2a, 0x3c, 0x80, 0xad, 0x85, 0xdc, 0x5e, 0x33,
+	0x7e, 0x86, 0xe4, 0x05, 0xb4, 0xbc, 0x40, 0x9d, 0x12, 0x02, 0x7b, 0xd3, 0x9d, 0xea, 0xa6, 0xde,
+	0x99, 0xf8, 0x38, 0x5a, 0x07, 0x84, 0x29, 0x74, 0x96, 0xe8, 0x75, 0xca, 0x7f, 0xb8, 0x50, 0x7e,
+	0x0f, 0x36, 0x67, 0xa8, 0xb4, 0x90, 0xb9, 0x3f, 0x74, 0x40, 0x17, 0x90, 0x3c, 0x81, 0xb6, 0xc2,
+	0x99, 0xf0, 0xae, 0xa6, 0x77, 0xfd, 0xc3, 0xe1, 0x11, 0x6c, 0x8c, 0x91, 0x69, 0x74, 0x45, 0xe5,
+	0xb9, 0xfd, 0x99, 0x75, 0xa3, 0x0a, 0x90, 0xe7, 0xb0, 0x8d, 0xf3, 0xa9, 0x50, 0xa8, 0x5f, 0x99,
+	0xf7, 0x2c, 0x97, 0x8b, 0xc1, 0xdd, 0x60, 0x47, 0xdd, 0x5f, 0x97, 0xfd, 0xc6, 0x6f, 0xbb, 0x2e,
+	0xec, 0xfa, 0xf1, 0xa7, 0x7f, 0x2f, 0x6e, 0xf9, 0xff, 0xbc, 0xff, 0x17, 0x97, 0xfc, 0x4b, 0x71,
+	0x0f, 0x04, 0x00, 0x00,
 }
@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"errors"
+	"fmt"
+
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/kv"
+	producerconfig "github.com/m3db/m3/src/msg/producer/config"
+	"github.com/m3db/m3/src/x/instrument"
+	xio "github.com/m3db/m3/src/x/io"
+
+	"go.uber.org/zap"
+)
+
+// SinkType is the type of a sink.
+type SinkType string
+
+const (
+	// LogSinkType logs audit records.
+	LogSinkType SinkType = "log"
+	// KVSinkType appends audit records to a key of the audited kv store.
+	KVSinkType SinkType = "kv"
+	// M3MsgSinkType produces audit records to an m3msg topic.
+	M3MsgSinkType SinkType = "m3msg"
+)
+
+const (
+	defaultKVSinkKey        = "_kv_audit"
+	defaultKVSinkMaxRecords = 1000
+)
+
+var (
+	errNoSinkProducer  = errors.New("kv audit m3msg sink requires a producer")
+	errNoClusterClient = errors.New("kv audit m3msg sink requires a cluster client")
+)
+
+// Configuration is the configuration of kv mutation auditing.
+type Configuration struct {
+	// Enabled enables kv mutation auditing.
+	Enabled bool `yaml:"enabled"`
+
+	// Identity is the identity of the process recorded with its mutations,
+	// defaults to the hostname.
+	Identity string `yaml:"identity"`
+
+	// MaxDiffChanges is the maximum number of field changes recorded in the
+	// diff of a value.
+	MaxDiffChanges *int `yaml:"maxDiffChanges"`
+
+	// Sink configures where audit records are written to.
+	Sink SinkConfiguration `yaml:"sink"`
+}
+
+// SinkConfiguration is the configuration of the sink of audit records.
+type SinkConfiguration struct {
+	// Type is the type of the sink, defaults to log.
+	Type SinkType `yaml:"type"`
+
+	// Key is the key audit records are appended to by a kv sink, in the kv
+	// store of the mutations, defaults to _kv_audit.
+	Key string `yaml:"key"`
+
+	// MaxRecords is the number of most recent audit records kept by a kv
+	// sink, defaults to 1000.
+	MaxRecords int `yaml:"maxRecords"`
+
+	// Producer configures the producer of an m3msg sink.
+	Producer *producerconfig.ProducerConfiguration `yaml:"producer"`
+}
+
+// NewOptions returns new options of audited stores. The cluster client fn is
+// only called by m3msg sinks, to create the producer of the sink, and must
+// return a client whose mutations are not audited.
+func (c Configuration) NewOptions(
+	clusterClientFn func() (clusterclient.Client, error),
+	iOpts instrument.Options,
+) (Options, error) {
+	sinkFn, err := c.Sink.NewSinkFn(clusterClientFn, iOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := NewOptions().
+		SetSinkFn(sinkFn).
+		SetInstrumentOptions(iOpts)
+	if c.Identity != "" {
+		opts = opts.SetIdentity(c.Identity)
+	}
+	if c.MaxDiffChanges != nil {
+		opts = opts.SetMaxDiffChanges(*c.MaxDiffChanges)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// NewSinkFn returns a new sink fn, the log and m3msg sinks are shared by all
+// the audited stores while each store gets its own kv sink.
+func (c SinkConfiguration) NewSinkFn(
+	clusterClientFn func() (clusterclient.Client, error),
+	iOpts instrument.Options,
+) (SinkFn, error) {
+	switch c.Type {
+	case "", LogSinkType:
+		sink := NewLogSink(iOpts.Logger().With(zap.String("component", "kv-audit")))
+		return func(kv.Store) (Sink, error) { return sink, nil }, nil
+	case KVSinkType:
+		key := c.Key
+		if key == "" {
+			key = defaultKVSinkKey
+		}
+		maxRecords := c.MaxRecords
+		if maxRecords <= 0 {
+			maxRecords = defaultKVSinkMaxRecords
+		}
+		return func(store kv.Store) (Sink, error) {
+			return NewKVSink(store, key, maxRecords), nil
+		}, nil
+	case M3MsgSinkType:
+		if c.Producer == nil {
+			return nil, errNoSinkProducer
+		}
+		if clusterClientFn == nil {
+			return nil, errNoClusterClient
+		}
+		clusterClient, err := clusterClientFn()
+		if err != nil {
+			return nil, err
+		}
+		p, err := c.Producer.NewProducer(clusterClient, iOpts, xio.NewOptions())
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Init(); err != nil {
+			return nil, err
+		}
+		sink := NewProducerSink(p)
+		return func(kv.Store) (Sink, error) { return sink, nil }, nil
+	default:
+		return nil, fmt.Errorf("unknown kv audit sink type: %s", c.Type)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestConfiguration(t *testing.T) {
+	const cfgStr = `
+enabled: true
+identity: alice
+maxDiffChanges: 10
+sink:
+  type: kv
+  maxRecords: 5
+`
+	var cfg Configuration
+	require.NoError(t, yaml.Unmarshal([]byte(cfgStr), &cfg))
+	require.True(t, cfg.Enabled)
+	require.Equal(t, KVSinkType, cfg.Sink.Type)
+
+	opts, err := cfg.NewOptions(nil, instrument.NewOptions())
+	require.NoError(t, err)
+	require.Equal(t, "alice", opts.Identity())
+	require.Equal(t, 10, opts.MaxDiffChanges())
+
+	sink, err := opts.SinkFn()(mem.NewStore())
+	require.NoError(t, err)
+	kvSink, ok := sink.(*kvSink)
+	require.True(t, ok)
+	require.Equal(t, defaultKVSinkKey, kvSink.key)
+	require.Equal(t, 5, kvSink.maxRecords)
+}
+
+func TestSinkConfigurationErrors(t *testing.T) {
+	_, err := SinkConfiguration{Type: M3MsgSinkType}.NewSinkFn(nil, instrument.NewOptions())
+	require.Equal(t, errNoSinkProducer, err)
+
+	_, err = SinkConfiguration{Type: "unknown"}.NewSinkFn(nil, instrument.NewOptions())
+	require.Error(t, err)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// diff returns the changes of the fields from the old value to the new value,
+// at most maxChanges of them, and whether changes were dropped. Values are
+// compared through their JSON encoding so that any generated protobuf type is
+// supported without registering it, a nil old value has no fields.
+func diff(oldValue, newValue proto.Message, maxChanges int) ([]Change, bool, error) {
+	oldFields, err := fields(oldValue)
+	if err != nil {
+		return nil, false, err
+	}
+	newFields, err := fields(newValue)
+	if err != nil {
+		return nil, false, err
+	}
+
+	paths := make([]string, 0, len(oldFields)+len(newFields))
+	for path := range oldFields {
+		paths = append(paths, path)
+	}
+	for path := range newFields {
+		if _, ok := oldFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var changes []Change
+	for _, path := range paths {
+		oldField, inOld := oldFields[path]
+		newField, inNew := newFields[path]
+		if inOld && inNew && oldField == newField {
+			continue
+		}
+		if len(changes) == maxChanges {
+			return changes, true, nil
+		}
+		changes = append(changes, Change{Path: path, Old: oldField, New: newField})
+	}
+	return changes, false, nil
+}
+
+// fields returns the JSON encoded leaf fields of the value by path.
+func fields(m proto.Message) (map[string]string, error) {
+	result := make(map[string]string)
+	if m == nil {
+		return result, nil
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	// NB: keep numbers as is rather than converting them to floats, which
+	// would lose the precision of large integers.
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if err := flatten("", v, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func flatten(path string, v interface{}, result map[string]string) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range v {
+			elemPath := k
+			if path != "" {
+				elemPath = path + "." + k
+			}
+			if err := flatten(elemPath, elem, result); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			if err := flatten(fmt.Sprintf("%s[%d]", path, i), elem, result); err != nil {
+				return err
+			}
+		}
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		result[path] = string(b)
+	}
+	return nil
+}
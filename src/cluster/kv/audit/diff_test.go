@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	changes, truncated, err := diff(
+		&commonpb.StringArrayProto{Values: []string{"a", "b", "c"}},
+		&commonpb.StringArrayProto{Values: []string{"a", "d"}},
+		10,
+	)
+	require.NoError(t, err)
+	require.False(t, truncated)
+	require.Equal(t, []Change{
+		{Path: "values[1]", Old: `"b"`, New: `"d"`},
+		{Path: "values[2]", Old: `"c"`},
+	}, changes)
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	changes, truncated, err := diff(
+		&commonpb.Int64Proto{Value: 1},
+		&commonpb.Int64Proto{Value: 1},
+		10,
+	)
+	require.NoError(t, err)
+	require.False(t, truncated)
+	require.Empty(t, changes)
+}
+
+func TestDiffTruncated(t *testing.T) {
+	changes, truncated, err := diff(
+		nil,
+		&commonpb.StringArrayProto{Values: []string{"a", "b", "c"}},
+		2,
+	)
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Equal(t, []Change{
+		{Path: "values[0]", New: `"a"`},
+		{Path: "values[1]", New: `"b"`},
+	}, changes)
+}
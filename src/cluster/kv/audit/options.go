@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"errors"
+	"os"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const defaultMaxDiffChanges = 100
+
+var (
+	errNilSinkFn             = errors.New("nil kv audit sink fn")
+	errEmptyIdentity         = errors.New("empty kv audit identity")
+	errInvalidMaxDiffChanges = errors.New("kv audit max diff changes must not be negative")
+)
+
+type options struct {
+	sinkFn         SinkFn
+	identity       string
+	maxDiffChanges int
+	clockOpts      clock.Options
+	iOpts          instrument.Options
+}
+
+// NewOptions returns new options of an audited store, the identity defaults
+// to the hostname.
+func NewOptions() Options {
+	identity, err := os.Hostname()
+	if err != nil {
+		identity = "unknown"
+	}
+	return &options{
+		identity:       identity,
+		maxDiffChanges: defaultMaxDiffChanges,
+		clockOpts:      clock.NewOptions(),
+		iOpts:          instrument.NewOptions(),
+	}
+}
+
+func (o *options) SetSinkFn(value SinkFn) Options {
+	opts := *o
+	opts.sinkFn = value
+	return &opts
+}
+
+func (o *options) SinkFn() SinkFn {
+	return o.sinkFn
+}
+
+func (o *options) SetIdentity(value string) Options {
+	opts := *o
+	opts.identity = value
+	return &opts
+}
+
+func (o *options) Identity() string {
+	return o.identity
+}
+
+func (o *options) SetMaxDiffChanges(value int) Options {
+	opts := *o
+	opts.maxDiffChanges = value
+	return &opts
+}
+
+func (o *options) MaxDiffChanges() int {
+	return o.maxDiffChanges
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.iOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.iOpts
+}
+
+func (o *options) Validate() error {
+	if o.sinkFn == nil {
+		return errNilSinkFn
+	}
+	if o.identity == "" {
+		return errEmptyIdentity
+	}
+	if o.maxDiffChanges < 0 {
+		return errInvalidMaxDiffChanges
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/msg/producer"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// maxWriteAttempts is the number of times a kv sink attempts to append a
+// record when the audit key is concurrently updated by other clients.
+const maxWriteAttempts = 10
+
+var errTooManyConflicts = errors.New("too many conflicting kv audit log updates")
+
+// NewLogSink returns a sink that logs audit records.
+func NewLogSink(logger *zap.Logger) Sink {
+	return &logSink{logger: logger}
+}
+
+type logSink struct {
+	logger *zap.Logger
+}
+
+func (s *logSink) Write(r Record) error {
+	s.logger.Info("audited kv mutation",
+		zap.Time("time", r.Time),
+		zap.String("identity", r.Identity),
+		zap.String("operation", string(r.Operation)),
+		zap.String("zone", r.Zone),
+		zap.String("environment", r.Environment),
+		zap.String("namespace", r.Namespace),
+		zap.String("key", r.Key),
+		zap.Int("oldVersion", r.OldVersion),
+		zap.Int("newVersion", r.NewVersion),
+		zap.Any("diff", r.Diff),
+		zap.Bool("diffTruncated", r.DiffTruncated))
+	return nil
+}
+
+func (s *logSink) Close() error {
+	return nil
+}
+
+// NewKVSink returns a sink that appends audit records as JSON to a key of the
+// kv store, keeping only the most recent maxRecords records.
+func NewKVSink(store kv.Store, key string, maxRecords int) Sink {
+	return &kvSink{store: store, key: key, maxRecords: maxRecords}
+}
+
+type kvSink struct {
+	store      kv.Store
+	key        string
+	maxRecords int
+}
+
+func (s *kvSink) Write(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt < maxWriteAttempts; attempt++ {
+		var (
+			values  []string
+			version int
+		)
+		value, err := s.store.Get(s.key)
+		switch err {
+		case nil:
+			var proto commonpb.StringArrayProto
+			if err := value.Unmarshal(&proto); err != nil {
+				return err
+			}
+			values, version = proto.Values, value.Version()
+		case kv.ErrNotFound:
+		default:
+			return err
+		}
+
+		values = append(values, string(b))
+		if len(values) > s.maxRecords {
+			values = values[len(values)-s.maxRecords:]
+		}
+
+		proto := &commonpb.StringArrayProto{Values: values}
+		if version == 0 {
+			_, err = s.store.SetIfNotExists(s.key, proto)
+		} else {
+			_, err = s.store.CheckAndSet(s.key, version, proto)
+		}
+		if err == kv.ErrAlreadyExists || err == kv.ErrVersionMismatch {
+			continue
+		}
+		return err
+	}
+
+	return errTooManyConflicts
+}
+
+func (s *kvSink) Close() error {
+	return nil
+}
+
+// NewProducerSink returns a sink that produces audit records as JSON to an
+// m3msg topic, records are spread evenly across the shards of the topic. The
+// producer must be initialized and is closed with the sink.
+func NewProducerSink(p producer.Producer) Sink {
+	return &producerSink{p: p, next: atomic.NewUint32(0)}
+}
+
+type producerSink struct {
+	p    producer.Producer
+	next *atomic.Uint32
+}
+
+func (s *producerSink) Write(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	var shard uint32
+	if n := s.p.NumShards(); n > 0 {
+		shard = s.next.Inc() % n
+	}
+	return s.p.Produce(&recordMessage{shard: shard, data: b})
+}
+
+func (s *producerSink) Close() error {
+	// NB: wait for the buffered records to be consumed so that none are lost
+	// on shutdown.
+	s.p.Close(producer.WaitForConsumption)
+	return nil
+}
+
+// recordMessage is an audit record produced to m3msg.
+type recordMessage struct {
+	shard uint32
+	data  []byte
+}
+
+var _ producer.Message = (*recordMessage)(nil)
+
+func (m *recordMessage) Shard() uint32 {
+	return m.shard
+}
+
+func (m *recordMessage) Bytes() []byte {
+	return m.data
+}
+
+func (m *recordMessage) Size() int {
+	return len(m.data)
+}
+
+func (m *recordMessage) Finalize(producer.FinalizeReason) {}
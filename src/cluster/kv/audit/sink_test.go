@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVSink(t *testing.T) {
+	store := mem.NewStore()
+	sink := NewKVSink(store, "audit", 2)
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, sink.Write(Record{Identity: "alice", Key: key}))
+	}
+	require.NoError(t, sink.Close())
+
+	v, err := store.Get("audit")
+	require.NoError(t, err)
+	require.Equal(t, 3, v.Version())
+
+	var proto commonpb.StringArrayProto
+	require.NoError(t, v.Unmarshal(&proto))
+	require.Len(t, proto.Values, 2)
+
+	var keys []string
+	for _, value := range proto.Values {
+		var r Record
+		require.NoError(t, json.Unmarshal([]byte(value), &r))
+		require.Equal(t, "alice", r.Identity)
+		keys = append(keys, r.Key)
+	}
+	require.Equal(t, []string{"b", "c"}, keys)
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+type storeMetrics struct {
+	audited    tally.Counter
+	readErrors tally.Counter
+	diffErrors tally.Counter
+	sinkErrors tally.Counter
+}
+
+func newStoreMetrics(scope tally.Scope) storeMetrics {
+	return storeMetrics{
+		audited:    scope.Counter("audited"),
+		readErrors: scope.Counter("read-errors"),
+		diffErrors: scope.Counter("diff-errors"),
+		sinkErrors: scope.Counter("sink-errors"),
+	}
+}
+
+// store records the mutations of the underlying store, reads are passed
+// through as is.
+type store struct {
+	kv.Store
+
+	txnStore       kv.TxnStore
+	prevStore      kv.PreviousValueStore
+	sink           Sink
+	identity       string
+	zone           string
+	environment    string
+	namespace      string
+	maxDiffChanges int
+	nowFn          clock.NowFn
+	logger         *zap.Logger
+	metrics        storeMetrics
+}
+
+// NewTxnStore returns a transactional kv store recording the mutations of the
+// given store to the sink returned by the sink fn of the options. The store
+// options identify the store in the records and may be nil. Mutations are
+// recorded once they succeed, failing to record them does not fail them. The
+// values replaced by sets are taken from the sets themselves if the store is
+// a kv.PreviousValueStore and are read ahead of the sets otherwise.
+func NewTxnStore(
+	s kv.TxnStore,
+	storeOpts kv.OverrideOptions,
+	opts Options,
+) (kv.TxnStore, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	sink, err := opts.SinkFn()(s)
+	if err != nil {
+		return nil, err
+	}
+
+	iOpts := opts.InstrumentOptions()
+	prevStore, _ := s.(kv.PreviousValueStore)
+	audited := &store{
+		Store:          s,
+		txnStore:       s,
+		prevStore:      prevStore,
+		sink:           sink,
+		identity:       opts.Identity(),
+		maxDiffChanges: opts.MaxDiffChanges(),
+		nowFn:          opts.ClockOptions().NowFn(),
+		logger:         iOpts.Logger(),
+		metrics:        newStoreMetrics(iOpts.MetricsScope().SubScope("kv-audit")),
+	}
+	if storeOpts != nil {
+		audited.zone = storeOpts.Zone()
+		audited.environment = storeOpts.Environment()
+		audited.namespace = storeOpts.Namespace()
+	}
+	return audited, nil
+}
+
+func (s *store) Set(key string, v proto.Message) (int, error) {
+	var (
+		version int
+		old     kv.Value
+		err     error
+	)
+	if s.prevStore != nil {
+		version, old, err = s.prevStore.SetReturningPrevious(key, v)
+	} else {
+		old = s.current(key)
+		version, err = s.Store.Set(key, v)
+	}
+	if err != nil {
+		return version, err
+	}
+	s.audit(SetOperation, key, old, version, v)
+	return version, nil
+}
+
+func (s *store) SetWithTTL(key string, v proto.Message, ttl time.Duration) (int, error) {
+	var (
+		version int
+		old     kv.Value
+		err     error
+	)
+	if s.prevStore != nil {
+		version, old, err = s.prevStore.SetWithTTLReturningPrevious(key, v, ttl)
+	} else {
+		old = s.current(key)
+		version, err = s.Store.SetWithTTL(key, v, ttl)
+	}
+	if err != nil {
+		return version, err
+	}
+	s.audit(SetWithTTLOperation, key, old, version, v)
+	return version, nil
+}
+
+func (s *store) SetIfNotExists(key string, v proto.Message) (int, error) {
+	version, err := s.Store.SetIfNotExists(key, v)
+	if err != nil {
+		return version, err
+	}
+	s.audit(SetIfNotExistsOperation, key, nil, version, v)
+	return version, nil
+}
+
+func (s *store) CheckAndSet(key string, version int, v proto.Message) (int, error) {
+	var (
+		newVersion int
+		old        kv.Value
+		err        error
+	)
+	if s.prevStore != nil {
+		newVersion, old, err = s.prevStore.CheckAndSetReturningPrevious(key, version, v)
+	} else {
+		old = s.current(key)
+		newVersion, err = s.Store.CheckAndSet(key, version, v)
+	}
+	if err != nil {
+		return newVersion, err
+	}
+	s.audit(CheckAndSetOperation, key, old, newVersion, v)
+	return newVersion, nil
+}
+
+func (s *store) Delete(key string) (kv.Value, error) {
+	old, err := s.Store.Delete(key)
+	if err != nil {
+		return old, err
+	}
+	s.audit(DeleteOperation, key, old, 0, nil)
+	return old, nil
+}
+
+func (s *store) DeleteIfVersionMatches(key string, version int) (kv.Value, error) {
+	old, err := s.Store.DeleteIfVersionMatches(key, version)
+	if err != nil {
+		return old, err
+	}
+	s.audit(DeleteIfVersionMatchesOperation, key, old, 0, nil)
+	return old, nil
+}
+
+func (s *store) Commit(conditions []kv.Condition, ops []kv.Op) (kv.Response, error) {
+	var (
+		keys   = make([]string, 0, len(ops))
+		values = make(map[string]proto.Message, len(ops))
+	)
+	for _, op := range ops {
+		if setOp, ok := op.(kv.SetOp); ok {
+			keys = append(keys, setOp.Key())
+			values[setOp.Key()] = setOp.Value
+		}
+	}
+	olds, err := s.Store.GetMany(keys)
+	if err != nil {
+		s.metrics.readErrors.Inc(1)
+		s.logger.Warn("unable to read values before kv transaction for audit", zap.Error(err))
+		olds = nil
+	}
+
+	resp, err := s.txnStore.Commit(conditions, ops)
+	if err != nil {
+		return resp, err
+	}
+	for _, opr := range resp.Responses() {
+		value, ok := values[opr.Key()]
+		if opr.Type() != kv.OpSet || !ok {
+			continue
+		}
+		version, _ := opr.Value().(int)
+		s.audit(CommitOperation, opr.Key(), olds[opr.Key()], version, value)
+	}
+	return resp, nil
+}
+
+// current returns the current value of the key to record the mutation of the
+// key from, or nil if the key does not exist or can not be read.
+func (s *store) current(key string) kv.Value {
+	v, err := s.Store.Get(key)
+	if err == kv.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		s.metrics.readErrors.Inc(1)
+		s.logger.Warn("unable to read value before kv mutation for audit",
+			zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	return v
+}
+
+// audit records a mutation of the key from the old value to the new value
+// written at the new version, the new value is nil for deletions.
+func (s *store) audit(
+	op Operation,
+	key string,
+	old kv.Value,
+	newVersion int,
+	newValue proto.Message,
+) {
+	r := Record{
+		Time:        s.nowFn(),
+		Identity:    s.identity,
+		Operation:   op,
+		Zone:        s.zone,
+		Environment: s.environment,
+		Namespace:   s.namespace,
+		Key:         key,
+		NewVersion:  newVersion,
+	}
+	if old != nil {
+		r.OldVersion = old.Version()
+	}
+
+	if newValue != nil {
+		var oldValue proto.Message
+		if old != nil {
+			// NB: decode the old value as the type of the new value, values
+			// of a key are expected to keep the same type.
+			oldValue = proto.Clone(newValue)
+			oldValue.Reset()
+			if err := old.Unmarshal(oldValue); err != nil {
+				oldValue = nil
+				s.metrics.diffErrors.Inc(1)
+			}
+		}
+		changes, truncated, err := diff(oldValue, newValue, s.maxDiffChanges)
+		if err != nil {
+			s.metrics.diffErrors.Inc(1)
+		}
+		r.Diff, r.DiffTruncated = changes, truncated
+	}
+
+	s.metrics.audited.Inc(1)
+	if err := s.sink.Write(r); err != nil {
+		s.metrics.sinkErrors.Inc(1)
+		s.logger.Error("unable to write kv audit record",
+			zap.String("key", key), zap.String("operation", string(op)), zap.Error(err))
+	}
+}
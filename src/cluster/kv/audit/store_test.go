@@ -0,0 +1,288 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+type testSink struct {
+	sync.Mutex
+
+	records []Record
+}
+
+func (s *testSink) Write(r Record) error {
+	s.Lock()
+	s.records = append(s.records, r)
+	s.Unlock()
+	return nil
+}
+
+func (s *testSink) Close() error {
+	return nil
+}
+
+func (s *testSink) Records() []Record {
+	s.Lock()
+	defer s.Unlock()
+	return append([]Record(nil), s.records...)
+}
+
+// previousValueStore returns the values replaced by sets from the sets and
+// fails reads, so that the values replaced by sets can only come from them.
+type previousValueStore struct {
+	kv.TxnStore
+}
+
+func (s previousValueStore) Get(key string) (kv.Value, error) {
+	return nil, errors.New("unexpected read")
+}
+
+func (s previousValueStore) previous(key string) (kv.Value, error) {
+	prev, err := s.TxnStore.Get(key)
+	if err == kv.ErrNotFound {
+		return nil, nil
+	}
+	return prev, err
+}
+
+func (s previousValueStore) SetReturningPrevious(key string, v proto.Message) (int, kv.Value, error) {
+	prev, err := s.previous(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	version, err := s.TxnStore.Set(key, v)
+	return version, prev, err
+}
+
+func (s previousValueStore) SetWithTTLReturningPrevious(
+	key string,
+	v proto.Message,
+	ttl time.Duration,
+) (int, kv.Value, error) {
+	prev, err := s.previous(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	version, err := s.TxnStore.SetWithTTL(key, v, ttl)
+	return version, prev, err
+}
+
+func (s previousValueStore) CheckAndSetReturningPrevious(
+	key string,
+	version int,
+	v proto.Message,
+) (int, kv.Value, error) {
+	prev, err := s.previous(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	newVersion, err := s.TxnStore.CheckAndSet(key, version, v)
+	return newVersion, prev, err
+}
+
+func testStore(t *testing.T) (kv.TxnStore, *testSink) {
+	return testStoreWithUnderlying(t, mem.NewStore())
+}
+
+func testStoreWithUnderlying(t *testing.T, underlying kv.TxnStore) (kv.TxnStore, *testSink) {
+	now := time.Unix(1600000000, 0)
+	sink := &testSink{}
+	opts := NewOptions().
+		SetIdentity("alice").
+		SetClockOptions(clock.NewOptions().SetNowFn(func() time.Time { return now })).
+		SetSinkFn(func(kv.Store) (Sink, error) { return sink, nil })
+	storeOpts := kv.NewOverrideOptions().
+		SetZone("zone").
+		SetEnvironment("env").
+		SetNamespace("ns")
+
+	s, err := NewTxnStore(underlying, storeOpts, opts)
+	require.NoError(t, err)
+	return s, sink
+}
+
+func TestStoreSet(t *testing.T) {
+	s, sink := testStore(t)
+
+	version, err := s.Set("foo", &commonpb.StringProto{Value: "a"})
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	version, err = s.Set("foo", &commonpb.StringProto{Value: "b"})
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+
+	records := sink.Records()
+	require.Len(t, records, 2)
+	require.Equal(t, Record{
+		Time:        time.Unix(1600000000, 0),
+		Identity:    "alice",
+		Operation:   SetOperation,
+		Zone:        "zone",
+		Environment: "env",
+		Namespace:   "ns",
+		Key:         "foo",
+		OldVersion:  0,
+		NewVersion:  1,
+		Diff:        []Change{{Path: "value", New: `"a"`}},
+	}, records[0])
+	require.Equal(t, 1, records[1].OldVersion)
+	require.Equal(t, 2, records[1].NewVersion)
+	require.Equal(t, []Change{{Path: "value", Old: `"a"`, New: `"b"`}}, records[1].Diff)
+
+	// Reads are not recorded.
+	v, err := s.Get("foo")
+	require.NoError(t, err)
+	require.Equal(t, 2, v.Version())
+	require.Len(t, sink.Records(), 2)
+}
+
+func TestStoreSetIfNotExistsAndCheckAndSet(t *testing.T) {
+	s, sink := testStore(t)
+
+	_, err := s.SetIfNotExists("foo", &commonpb.StringArrayProto{Values: []string{"a"}})
+	require.NoError(t, err)
+
+	_, err = s.SetIfNotExists("foo", &commonpb.StringArrayProto{Values: []string{"b"}})
+	require.Equal(t, kv.ErrAlreadyExists, err)
+
+	_, err = s.CheckAndSet("foo", 2, &commonpb.StringArrayProto{Values: []string{"b"}})
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	version, err := s.CheckAndSet("foo", 1, &commonpb.StringArrayProto{Values: []string{"a", "b"}})
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+
+	// Failed mutations are not recorded.
+	records := sink.Records()
+	require.Len(t, records, 2)
+	require.Equal(t, SetIfNotExistsOperation, records[0].Operation)
+	require.Equal(t, 0, records[0].OldVersion)
+	require.Equal(t, 1, records[0].NewVersion)
+	require.Equal(t, []Change{{Path: "values[0]", New: `"a"`}}, records[0].Diff)
+
+	require.Equal(t, CheckAndSetOperation, records[1].Operation)
+	require.Equal(t, 1, records[1].OldVersion)
+	require.Equal(t, 2, records[1].NewVersion)
+	require.Equal(t, []Change{{Path: "values[1]", New: `"b"`}}, records[1].Diff)
+}
+
+func TestStorePreviousValuesFromSets(t *testing.T) {
+	s, sink := testStoreWithUnderlying(t, previousValueStore{TxnStore: mem.NewStore()})
+
+	_, err := s.Set("foo", &commonpb.StringProto{Value: "a"})
+	require.NoError(t, err)
+	_, err = s.SetWithTTL("foo", &commonpb.StringProto{Value: "b"}, time.Minute)
+	require.NoError(t, err)
+	_, err = s.CheckAndSet("foo", 2, &commonpb.StringProto{Value: "c"})
+	require.NoError(t, err)
+
+	records := sink.Records()
+	require.Len(t, records, 3)
+	require.Equal(t, 0, records[0].OldVersion)
+	require.Equal(t, []Change{{Path: "value", New: `"a"`}}, records[0].Diff)
+	require.Equal(t, 1, records[1].OldVersion)
+	require.Equal(t, []Change{{Path: "value", Old: `"a"`, New: `"b"`}}, records[1].Diff)
+	require.Equal(t, 2, records[2].OldVersion)
+	require.Equal(t, []Change{{Path: "value", Old: `"b"`, New: `"c"`}}, records[2].Diff)
+}
+
+func TestStoreDelete(t *testing.T) {
+	s, sink := testStore(t)
+
+	_, err := s.Set("foo", &commonpb.StringProto{Value: "a"})
+	require.NoError(t, err)
+	_, err = s.Set("bar", &commonpb.StringProto{Value: "a"})
+	require.NoError(t, err)
+
+	_, err = s.Delete("foo")
+	require.NoError(t, err)
+
+	_, err = s.DeleteIfVersionMatches("bar", 2)
+	require.Error(t, err)
+
+	_, err = s.DeleteIfVersionMatches("bar", 1)
+	require.NoError(t, err)
+
+	records := sink.Records()
+	require.Len(t, records, 4)
+	require.Equal(t, DeleteOperation, records[2].Operation)
+	require.Equal(t, "foo", records[2].Key)
+	require.Equal(t, 1, records[2].OldVersion)
+	require.Equal(t, 0, records[2].NewVersion)
+	require.Nil(t, records[2].Diff)
+
+	require.Equal(t, DeleteIfVersionMatchesOperation, records[3].Operation)
+	require.Equal(t, "bar", records[3].Key)
+	require.Equal(t, 1, records[3].OldVersion)
+}
+
+func TestStoreCommit(t *testing.T) {
+	s, sink := testStore(t)
+
+	_, err := s.Set("foo", &commonpb.StringProto{Value: "a"})
+	require.NoError(t, err)
+
+	_, err = s.Commit(
+		[]kv.Condition{kv.NewCondition().
+			SetKey("foo").
+			SetValue(1).
+			SetCompareType(kv.CompareEqual).
+			SetTargetType(kv.TargetVersion)},
+		[]kv.Op{
+			kv.NewSetOp("foo", &commonpb.StringProto{Value: "b"}),
+			kv.NewSetOp("bar", &commonpb.StringProto{Value: "c"}),
+		},
+	)
+	require.NoError(t, err)
+
+	records := sink.Records()
+	require.Len(t, records, 3)
+	require.Equal(t, CommitOperation, records[1].Operation)
+	require.Equal(t, "foo", records[1].Key)
+	require.Equal(t, 1, records[1].OldVersion)
+	require.Equal(t, 2, records[1].NewVersion)
+	require.Equal(t, []Change{{Path: "value", Old: `"a"`, New: `"b"`}}, records[1].Diff)
+
+	require.Equal(t, CommitOperation, records[2].Operation)
+	require.Equal(t, "bar", records[2].Key)
+	require.Equal(t, 0, records[2].OldVersion)
+	require.Equal(t, 1, records[2].NewVersion)
+	require.Equal(t, []Change{{Path: "value", New: `"c"`}}, records[2].Diff)
+}
+
+func TestNewTxnStoreInvalidOptions(t *testing.T) {
+	_, err := NewTxnStore(mem.NewStore(), nil, NewOptions())
+	require.Equal(t, errNilSinkFn, err)
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package audit provides a kv store wrapper recording every mutation of the
+// store with the identity of the caller, the versions of the key and a diff of
+// the decoded value, so that changes to runtime configuration such as
+// placements can be traced back to who made them.
+package audit
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Operation is a kv store mutation.
+type Operation string
+
+// List of audited operations.
+const (
+	SetOperation                    Operation = "set"
+	SetWithTTLOperation             Operation = "set_with_ttl"
+	SetIfNotExistsOperation         Operation = "set_if_not_exists"
+	CheckAndSetOperation            Operation = "check_and_set"
+	DeleteOperation                 Operation = "delete"
+	DeleteIfVersionMatchesOperation Operation = "delete_if_version_matches"
+	CommitOperation                 Operation = "commit"
+)
+
+// Record is the audit record of a kv mutation.
+type Record struct {
+	// Time is when the mutation was made.
+	Time time.Time `json:"time"`
+	// Identity is the identity of the caller.
+	Identity  string    `json:"identity"`
+	Operation Operation `json:"operation"`
+	// Zone, Environment and Namespace identify the kv store mutated.
+	Zone        string `json:"zone,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Key         string `json:"key"`
+	// OldVersion is the version of the key before the mutation, zero if the
+	// key did not exist. It is read before the mutation so a concurrent
+	// mutation by another client may be made in between.
+	OldVersion int `json:"oldVersion"`
+	// NewVersion is the version of the key after the mutation, zero if the
+	// key was deleted.
+	NewVersion int `json:"newVersion"`
+	// Diff are the changes of the fields of the decoded value, it is not
+	// recorded for deletions since the type of the value is unknown.
+	Diff []Change `json:"diff,omitempty"`
+	// DiffTruncated is true if changes were dropped from the diff.
+	DiffTruncated bool `json:"diffTruncated,omitempty"`
+}
+
+// Change is the change of a field of a value, the old and new values are
+// JSON encoded and empty if the field was added or removed.
+type Change struct {
+	Path string `json:"path"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// Sink is where audit records are written to.
+type Sink interface {
+	// Write writes the audit record.
+	Write(r Record) error
+
+	// Close closes the sink.
+	Close() error
+}
+
+// SinkFn returns the sink of the audit records of mutations of the given
+// store, which is not audited itself so that sinks can write to it.
+type SinkFn func(store kv.Store) (Sink, error)
+
+// Options are the options of an audited store.
+type Options interface {
+	// SetSinkFn sets the function returning the sink of the audit records.
+	SetSinkFn(value SinkFn) Options
+
+	// SinkFn returns the function returning the sink of the audit records.
+	SinkFn() SinkFn
+
+	// SetIdentity sets the identity of the caller recorded with mutations.
+	SetIdentity(value string) Options
+
+	// Identity returns the identity of the caller recorded with mutations.
+	Identity() string
+
+	// SetMaxDiffChanges sets the maximum number of changes recorded in the
+	// diff of a value.
+	SetMaxDiffChanges(value int) Options
+
+	// MaxDiffChanges returns the maximum number of changes recorded in the
+	// diff of a value.
+	MaxDiffChanges() int
+
+	// SetClockOptions sets the clock options.
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+
+	// Validate validates the options.
+	Validate() error
+}
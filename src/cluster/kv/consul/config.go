@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consul
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Configuration is the config for the consul kv store.
+type Configuration struct {
+	Address            string        `yaml:"address"`
+	Token              string        `yaml:"token"`
+	Prefix             string        `yaml:"prefix"`
+	RequestTimeout     time.Duration `yaml:"requestTimeout"`
+	WatchWaitTime      time.Duration `yaml:"watchWaitTime"`
+	WatchRetryInterval time.Duration `yaml:"watchRetryInterval"`
+}
+
+// NewOptions creates the consul kv store Options from the configuration.
+func (cfg Configuration) NewOptions(iopts instrument.Options) Options {
+	opts := NewOptions().
+		SetToken(cfg.Token).
+		SetPrefix(cfg.Prefix).
+		SetInstrumentsOptions(iopts)
+	if cfg.Address != "" {
+		opts = opts.SetAddress(cfg.Address)
+	}
+	if cfg.RequestTimeout > 0 {
+		opts = opts.SetRequestTimeout(cfg.RequestTimeout)
+	}
+	if cfg.WatchWaitTime > 0 {
+		opts = opts.SetWatchWaitTime(cfg.WatchWaitTime)
+	}
+	if cfg.WatchRetryInterval > 0 {
+		opts = opts.SetWatchRetryInterval(cfg.WatchRetryInterval)
+	}
+	return opts
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consul
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultAddress            = "http://127.0.0.1:8500"
+	defaultRequestTimeout     = 10 * time.Second
+	defaultWatchWaitTime      = 5 * time.Minute
+	defaultWatchRetryInterval = 10 * time.Second
+)
+
+// Options are options for the client of the consul kv store
+type Options interface {
+	// Address is the address of the consul agent, e.g. http://127.0.0.1:8500
+	Address() string
+	// SetAddress sets the Address
+	SetAddress(address string) Options
+
+	// Token is the ACL token sent with every request, if any
+	Token() string
+	// SetToken sets the Token
+	SetToken(token string) Options
+
+	// HTTPClient is the http client used for requests to consul
+	HTTPClient() *http.Client
+	// SetHTTPClient sets the HTTPClient
+	SetHTTPClient(client *http.Client) Options
+
+	// RequestTimeout is the timeout for consul requests
+	RequestTimeout() time.Duration
+	// SetRequestTimeout sets the RequestTimeout
+	SetRequestTimeout(t time.Duration) Options
+
+	// WatchWaitTime is the maximum duration of a blocking query used to
+	// watch for updates
+	WatchWaitTime() time.Duration
+	// SetWatchWaitTime sets the WatchWaitTime
+	SetWatchWaitTime(t time.Duration) Options
+
+	// WatchRetryInterval is the delay before retrying a failed blocking query
+	WatchRetryInterval() time.Duration
+	// SetWatchRetryInterval sets the WatchRetryInterval
+	SetWatchRetryInterval(t time.Duration) Options
+
+	// Prefix is the prefix for each key
+	Prefix() string
+	// SetPrefix sets the prefix
+	SetPrefix(s string) Options
+	// ApplyPrefix applies the prefix to the key
+	ApplyPrefix(key string) string
+
+	// InstrumentsOptions is the instrument options
+	InstrumentsOptions() instrument.Options
+	// SetInstrumentsOptions sets the InstrumentsOptions
+	SetInstrumentsOptions(iopts instrument.Options) Options
+
+	// Validate validates the Options
+	Validate() error
+}
+
+type options struct {
+	address            string
+	token              string
+	httpClient         *http.Client
+	requestTimeout     time.Duration
+	watchWaitTime      time.Duration
+	watchRetryInterval time.Duration
+	prefix             string
+	iopts              instrument.Options
+}
+
+// NewOptions creates a sane default Option
+func NewOptions() Options {
+	o := options{}
+	return o.SetAddress(defaultAddress).
+		SetHTTPClient(http.DefaultClient).
+		SetRequestTimeout(defaultRequestTimeout).
+		SetWatchWaitTime(defaultWatchWaitTime).
+		SetWatchRetryInterval(defaultWatchRetryInterval).
+		SetInstrumentsOptions(instrument.NewOptions())
+}
+
+func (o options) Validate() error {
+	if o.address == "" {
+		return errors.New("no consul address")
+	}
+
+	if o.httpClient == nil {
+		return errors.New("no http client")
+	}
+
+	if o.iopts == nil {
+		return errors.New("no instrument options")
+	}
+
+	if o.requestTimeout <= 0 {
+		return errors.New("invalid request timeout")
+	}
+
+	if o.watchWaitTime <= 0 {
+		return errors.New("invalid watch wait time")
+	}
+
+	if o.watchRetryInterval <= 0 {
+		return errors.New("invalid watch retry interval")
+	}
+
+	return nil
+}
+
+func (o options) Address() string {
+	return o.address
+}
+
+func (o options) SetAddress(address string) Options {
+	o.address = address
+	return o
+}
+
+func (o options) Token() string {
+	return o.token
+}
+
+func (o options) SetToken(token string) Options {
+	o.token = token
+	return o
+}
+
+func (o options) HTTPClient() *http.Client {
+	return o.httpClient
+}
+
+func (o options) SetHTTPClient(client *http.Client) Options {
+	o.httpClient = client
+	return o
+}
+
+func (o options) RequestTimeout() time.Duration {
+	return o.requestTimeout
+}
+
+func (o options) SetRequestTimeout(t time.Duration) Options {
+	o.requestTimeout = t
+	return o
+}
+
+func (o options) WatchWaitTime() time.Duration {
+	return o.watchWaitTime
+}
+
+func (o options) SetWatchWaitTime(t time.Duration) Options {
+	o.watchWaitTime = t
+	return o
+}
+
+func (o options) WatchRetryInterval() time.Duration {
+	return o.watchRetryInterval
+}
+
+func (o options) SetWatchRetryInterval(t time.Duration) Options {
+	o.watchRetryInterval = t
+	return o
+}
+
+func (o options) Prefix() string {
+	return o.prefix
+}
+
+func (o options) SetPrefix(prefix string) Options {
+	o.prefix = prefix
+	return o
+}
+
+func (o options) ApplyPrefix(key string) string {
+	if o.prefix == "" {
+		return key
+	}
+	return fmt.Sprintf("%s/%s", o.prefix, key)
+}
+
+func (o options) InstrumentsOptions() instrument.Options {
+	return o.iopts
+}
+
+func (o options) SetInstrumentsOptions(iopts instrument.Options) Options {
+	o.iopts = iopts
+	return o
+}
@@ -0,0 +1,715 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// consulVersionZero is the version of a key that does not exist.
+	consulVersionZero = 0
+
+	// minSessionTTL is the minimum ttl of a consul session.
+	minSessionTTL = 10 * time.Second
+
+	indexHeader = "X-Consul-Index"
+	tokenHeader = "X-Consul-Token"
+
+	kvPathPrefix      = "/v1/kv/"
+	txnPath           = "/v1/txn"
+	sessionCreatePath = "/v1/session/create"
+)
+
+var (
+	errHistoryNotSupported = errors.New("history is not supported by the consul kv store")
+	errEmptyTxnResults     = errors.New("unexpected: no results for consul transaction")
+	errEmptySessionID      = errors.New("unexpected: no id for created consul session")
+)
+
+// kvPair is a key value pair of the consul kv api, versions of keys are
+// their ModifyIndex.
+type kvPair struct {
+	Key         string
+	Value       []byte
+	ModifyIndex uint64
+	Session     string `json:",omitempty"`
+}
+
+type txnKVOp struct {
+	Verb    string
+	Key     string
+	Value   []byte `json:",omitempty"`
+	Index   uint64 `json:",omitempty"`
+	Session string `json:",omitempty"`
+}
+
+type txnOp struct {
+	KV *txnKVOp
+}
+
+type txnResult struct {
+	KV *kvPair
+}
+
+type txnOpError struct {
+	OpIndex int
+	What    string
+}
+
+type txnResponse struct {
+	Results []txnResult
+	Errors  []txnOpError
+}
+
+type sessionRequest struct {
+	TTL       string
+	Behavior  string
+	LockDelay string
+}
+
+type sessionResponse struct {
+	ID string
+}
+
+// txnFailedError is returned when a consul transaction is rolled back
+// because one of its ops failed.
+type txnFailedError struct {
+	opIndex int
+	what    string
+}
+
+func (e txnFailedError) Error() string {
+	return fmt.Sprintf("consul transaction op %d failed: %s", e.opIndex, e.what)
+}
+
+func isTxnOpFailed(err error, opIndex int) bool {
+	var txnErr txnFailedError
+	return errors.As(err, &txnErr) && txnErr.opIndex == opIndex
+}
+
+// NewStore creates a kv store based on the consul kv api, versions of keys
+// are their consul ModifyIndex and watches are served by blocking queries.
+func NewStore(opts Options) (kv.Store, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	scope := opts.InstrumentsOptions().MetricsScope()
+	return &client{
+		opts:             opts,
+		httpClient:       opts.HTTPClient(),
+		logger:           opts.InstrumentsOptions().Logger(),
+		watchables:       map[string]kv.ValueWatchable{},
+		prefixWatchables: map[string]kv.PrefixWatchable{},
+		m: clientMetrics{
+			consulGetError:     scope.Counter("consul-get-error"),
+			consulTxnError:     scope.Counter("consul-txn-error"),
+			consulSessionError: scope.Counter("consul-session-error"),
+			consulWatchError:   scope.Counter("consul-watch-error"),
+		},
+	}, nil
+}
+
+type client struct {
+	sync.RWMutex
+
+	opts             Options
+	httpClient       *http.Client
+	logger           *zap.Logger
+	m                clientMetrics
+	watchables       map[string]kv.ValueWatchable
+	prefixWatchables map[string]kv.PrefixWatchable
+}
+
+type clientMetrics struct {
+	consulGetError     tally.Counter
+	consulTxnError     tally.Counter
+	consulSessionError tally.Counter
+	consulWatchError   tally.Counter
+}
+
+func (c *client) Get(key string) (kv.Value, error) {
+	pair, err := c.getPair(c.opts.ApplyPrefix(key))
+	if err != nil {
+		return nil, err
+	}
+	if pair == nil {
+		return nil, kv.ErrNotFound
+	}
+	return newValue(pair.Value, pair.ModifyIndex), nil
+}
+
+func (c *client) getPair(key string) (*kvPair, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	var pairs []kvPair
+	status, _, err := c.request(ctx, http.MethodGet, kvPath(key), nil, nil, &pairs)
+	if err != nil {
+		c.m.consulGetError.Inc(1)
+		return nil, err
+	}
+	if status == http.StatusNotFound || len(pairs) == 0 {
+		return nil, nil
+	}
+	return &pairs[0], nil
+}
+
+// GetMany retrieves the values of the keys one at a time since a consul transaction
+// fails as soon as one of the keys does not exist.
+func (c *client) GetMany(keys []string) (map[string]kv.Value, error) {
+	values := make(map[string]kv.Value, len(keys))
+	for _, key := range keys {
+		v, err := c.Get(key)
+		if err == kv.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = v
+	}
+	return values, nil
+}
+
+// History is not supported since consul does not keep previous values.
+func (c *client) History(key string, from, to int) ([]kv.Value, error) {
+	return nil, errHistoryNotSupported
+}
+
+// ListKeys lists all the keys with the prefix with a single keys query and
+// pages through them in memory since consul can not page through keys.
+func (c *client) ListKeys(prefix string, pageSize int, pageToken string) (kv.KeyPage, error) {
+	if err := kv.ValidateListKeys(prefix, pageSize, pageToken); err != nil {
+		return kv.KeyPage{}, err
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	var (
+		keys  []string
+		query = url.Values{}
+	)
+	query.Set("keys", "true")
+	if _, _, err := c.request(ctx, http.MethodGet, kvPath(c.opts.ApplyPrefix(prefix)), query, nil, &keys); err != nil {
+		c.m.consulGetError.Inc(1)
+		return kv.KeyPage{}, err
+	}
+
+	for i, key := range keys {
+		keys[i] = c.stripPrefix(key)
+	}
+	return kv.NewKeyPage(keys, prefix, pageSize, pageToken)
+}
+
+func (c *client) Watch(key string) (kv.ValueWatch, error) {
+	newKey := c.opts.ApplyPrefix(key)
+	c.Lock()
+	defer c.Unlock()
+
+	watchable, ok := c.watchables[newKey]
+	if !ok {
+		watchable = kv.NewValueWatchable()
+		c.watchables[newKey] = watchable
+
+		go c.poll(kvPath(newKey), false, func() bool {
+			return c.tickAndStop(newKey)
+		}, func(pairs []kvPair) {
+			c.update(watchable, pairs)
+		})
+	}
+	// NB: subscribe while holding the lock so the poll loop can not clean up
+	// the watchable before the watch is registered.
+	_, w, err := watchable.Watch()
+	return w, err
+}
+
+func (c *client) WatchFromVersion(key string, version int) (kv.ValueWatch, error) {
+	w, err := c.Watch(key)
+	if err != nil {
+		return nil, err
+	}
+	return kv.NewValueWatchFromVersion(w, version), nil
+}
+
+func (c *client) WatchPrefix(prefix string) (kv.PrefixWatch, error) {
+	newPrefix := c.opts.ApplyPrefix(prefix)
+	c.Lock()
+	defer c.Unlock()
+
+	watchable, ok := c.prefixWatchables[newPrefix]
+	if !ok {
+		watchable = kv.NewPrefixWatchable()
+		c.prefixWatchables[newPrefix] = watchable
+
+		go c.poll(kvPath(newPrefix), true, func() bool {
+			return c.tickAndStopPrefix(newPrefix)
+		}, func(pairs []kvPair) {
+			c.updatePrefix(watchable, pairs)
+		})
+	}
+	_, w, err := watchable.Watch()
+	return w, err
+}
+
+// poll runs blocking queries against the path until stopFn returns true and
+// calls updateFn with the returned key value pairs every time the consul
+// index of the path changes.
+func (c *client) poll(
+	path string,
+	recurse bool,
+	stopFn func() bool,
+	updateFn func(pairs []kvPair),
+) {
+	var index uint64
+	for {
+		var pairs []kvPair
+		status, newIndex, err := c.blockingGet(path, recurse, index, &pairs)
+		if stopFn() {
+			return
+		}
+		if err != nil {
+			c.m.consulWatchError.Inc(1)
+			c.logger.Error("consul blocking query failed",
+				zap.String("path", path), zap.Error(err))
+			time.Sleep(c.opts.WatchRetryInterval())
+			continue
+		}
+		if newIndex == index {
+			// the blocking query timed out without any change.
+			continue
+		}
+
+		index = nextIndex(index, newIndex)
+		if status == http.StatusNotFound {
+			pairs = nil
+		}
+		updateFn(pairs)
+	}
+}
+
+// nextIndex returns the index for the next blocking query following the
+// recommendations of consul, resetting the index if it goes backwards and
+// never blocking on a zero index.
+func nextIndex(prev, next uint64) uint64 {
+	if next < prev {
+		return 0
+	}
+	if next < 1 {
+		return 1
+	}
+	return next
+}
+
+func (c *client) blockingGet(
+	path string,
+	recurse bool,
+	index uint64,
+	out interface{},
+) (int, uint64, error) {
+	wait := c.opts.WatchWaitTime()
+	query := url.Values{}
+	query.Set("index", strconv.FormatUint(index, 10))
+	query.Set("wait", wait.String())
+	if recurse {
+		query.Set("recurse", "true")
+	}
+
+	// NB: consul adds a jitter of up to wait/16 to the blocking query.
+	ctx, cancel := context.WithTimeout(context.Background(),
+		wait+wait/16+c.opts.RequestTimeout())
+	defer cancel()
+
+	return c.request(ctx, http.MethodGet, path, query, nil, out)
+}
+
+func (c *client) update(watchable kv.ValueWatchable, pairs []kvPair) {
+	curValue := watchable.Get()
+	if len(pairs) == 0 {
+		// At deletion, just update the watch to nil.
+		if curValue != nil {
+			watchable.Update(nil)
+		}
+		return
+	}
+
+	nv := newValue(pairs[0].Value, pairs[0].ModifyIndex)
+	if curValue == nil || nv.IsNewer(curValue) {
+		watchable.Update(nv)
+	}
+}
+
+func (c *client) updatePrefix(watchable kv.PrefixWatchable, pairs []kvPair) {
+	values := make(map[string]kv.Value, len(pairs))
+	for _, pair := range pairs {
+		values[c.stripPrefix(pair.Key)] = newValue(pair.Value, pair.ModifyIndex)
+	}
+	watchable.Update(values)
+}
+
+func (c *client) stripPrefix(key string) string {
+	if c.opts.Prefix() == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, c.opts.Prefix()+"/")
+}
+
+func (c *client) tickAndStop(key string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	watchable, ok := c.watchables[key]
+	if !ok {
+		return true
+	}
+	if watchable.NumWatches() != 0 {
+		return false
+	}
+
+	watchable.Close()
+	delete(c.watchables, key)
+	return true
+}
+
+func (c *client) tickAndStopPrefix(prefix string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	watchable, ok := c.prefixWatchables[prefix]
+	if !ok {
+		return true
+	}
+	if watchable.NumWatches() != 0 {
+		return false
+	}
+
+	watchable.Close()
+	delete(c.prefixWatchables, prefix)
+	return true
+}
+
+func (c *client) Set(key string, v proto.Message) (int, error) {
+	return c.set(c.opts.ApplyPrefix(key), v, nil, "")
+}
+
+// SetWithTTL locks the key with a new consul session which deletes the key
+// once the session is invalidated, the ttl is rounded up to whole seconds and
+// to the minimum session ttl of consul, which may also wait up to twice the
+// ttl before invalidating the session.
+func (c *client) SetWithTTL(key string, v proto.Message, ttl time.Duration) (int, error) {
+	if ttl <= 0 {
+		return 0, kv.ErrInvalidTTL
+	}
+
+	session, err := c.createSession(ttl)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.set(c.opts.ApplyPrefix(key), v, nil, session)
+}
+
+func (c *client) SetIfNotExists(key string, v proto.Message) (int, error) {
+	version, err := c.CheckAndSet(key, consulVersionZero, v)
+	if err == kv.ErrVersionMismatch {
+		err = kv.ErrAlreadyExists
+	}
+	return version, err
+}
+
+func (c *client) CheckAndSet(key string, version int, v proto.Message) (int, error) {
+	key = c.opts.ApplyPrefix(key)
+	newVersion, err := c.set(key, v, checkVersionOp(key, version), "")
+	if isTxnOpFailed(err, 0) {
+		return 0, kv.ErrVersionMismatch
+	}
+	return newVersion, err
+}
+
+// set sets the value of the key in a transaction, guarded by the check op if
+// any, and locks the key with the session if any. The lock held on the key by
+// the session of a previous SetWithTTL is released so the key is no longer
+// deleted once that session is invalidated.
+func (c *client) set(key string, v proto.Message, check *txnKVOp, session string) (int, error) {
+	value, err := proto.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+
+	prev, err := c.getPair(key)
+	if err != nil {
+		return 0, err
+	}
+
+	var ops []txnOp
+	if check != nil {
+		ops = append(ops, txnOp{KV: check})
+	}
+	if prev != nil && prev.Session != "" {
+		ops = append(ops, txnOp{KV: &txnKVOp{Verb: "unlock", Key: key, Session: prev.Session}})
+	}
+	setOp := &txnKVOp{Verb: "set", Key: key, Value: value}
+	if session != "" {
+		setOp.Verb = "lock"
+		setOp.Session = session
+	}
+	ops = append(ops, txnOp{KV: setOp})
+
+	results, err := c.txn(ops)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(results[len(results)-1].ModifyIndex), nil
+}
+
+func (c *client) Delete(key string) (kv.Value, error) {
+	key = c.opts.ApplyPrefix(key)
+	results, err := c.txn([]txnOp{
+		{KV: &txnKVOp{Verb: "get", Key: key}},
+		{KV: &txnKVOp{Verb: "delete", Key: key}},
+	})
+	if isTxnOpFailed(err, 0) {
+		return nil, kv.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prev := results[len(results)-1]
+	return newValue(prev.Value, prev.ModifyIndex), nil
+}
+
+func (c *client) DeleteIfVersionMatches(key string, version int) (kv.Value, error) {
+	key = c.opts.ApplyPrefix(key)
+	if version == consulVersionZero {
+		// NB: a zero version matches a key that does not exist, so there is
+		// nothing to delete either way.
+		pair, err := c.getPair(key)
+		if err != nil {
+			return nil, err
+		}
+		if pair != nil {
+			return nil, kv.ErrVersionMismatch
+		}
+		return nil, kv.ErrNotFound
+	}
+
+	results, err := c.txn([]txnOp{
+		{KV: checkVersionOp(key, version)},
+		{KV: &txnKVOp{Verb: "get", Key: key}},
+		{KV: &txnKVOp{Verb: "delete", Key: key}},
+	})
+	if isTxnOpFailed(err, 0) {
+		return nil, kv.ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prev := results[len(results)-1]
+	return newValue(prev.Value, prev.ModifyIndex), nil
+}
+
+func checkVersionOp(key string, version int) *txnKVOp {
+	if version == consulVersionZero {
+		return &txnKVOp{Verb: "check-not-exists", Key: key}
+	}
+	return &txnKVOp{Verb: "check-index", Key: key, Index: uint64(version)}
+}
+
+// txn applies the ops in a consul transaction and returns the key value
+// pairs of the ops that return one, consul blanks out the values of all ops
+// but gets.
+func (c *client) txn(ops []txnOp) ([]*kvPair, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	var resp txnResponse
+	status, _, err := c.request(ctx, http.MethodPut, txnPath, nil, ops, &resp)
+	if err != nil {
+		c.m.consulTxnError.Inc(1)
+		return nil, err
+	}
+	if status == http.StatusConflict {
+		if len(resp.Errors) == 0 {
+			return nil, txnFailedError{opIndex: -1, what: "rolled back"}
+		}
+		return nil, txnFailedError{opIndex: resp.Errors[0].OpIndex, what: resp.Errors[0].What}
+	}
+
+	results := make([]*kvPair, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if r.KV != nil {
+			results = append(results, r.KV)
+		}
+	}
+	if len(results) == 0 {
+		return nil, errEmptyTxnResults
+	}
+	return results, nil
+}
+
+func (c *client) createSession(ttl time.Duration) (string, error) {
+	if ttl < minSessionTTL {
+		ttl = minSessionTTL
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	var (
+		req = sessionRequest{
+			TTL:      fmt.Sprintf("%ds", int64(math.Ceil(ttl.Seconds()))),
+			Behavior: "delete",
+			// allow the key to be set again right after the session expires.
+			LockDelay: "0s",
+		}
+		resp sessionResponse
+	)
+	_, _, err := c.request(ctx, http.MethodPut, sessionCreatePath, nil, req, &resp)
+	if err == nil && resp.ID == "" {
+		err = errEmptySessionID
+	}
+	if err != nil {
+		c.m.consulSessionError.Inc(1)
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// request sends a request to consul and decodes the response into out, it
+// returns the status code and the consul index of the response. Not found
+// and conflict responses are not treated as errors.
+func (c *client) request(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	in interface{},
+	out interface{},
+) (int, uint64, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	u := c.opts.Address() + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return 0, 0, err
+	}
+	if token := c.opts.Token(); token != "" {
+		req.Header.Set(tokenHeader, token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	var index uint64
+	if h := resp.Header.Get(indexHeader); h != "" {
+		if index, err = strconv.ParseUint(h, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid consul index %q: %w", h, err)
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusConflict:
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return 0, 0, err
+			}
+		}
+		return resp.StatusCode, index, nil
+	case http.StatusNotFound:
+		return resp.StatusCode, index, nil
+	default:
+		msg, _ := io.ReadAll(resp.Body)
+		return 0, 0, fmt.Errorf("unexpected consul response status %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+}
+
+func (c *client) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.opts.RequestTimeout())
+}
+
+func kvPath(key string) string {
+	return kvPathPrefix + (&url.URL{Path: key}).EscapedPath()
+}
+
+type value struct {
+	data    []byte
+	version uint64
+}
+
+func newValue(data []byte, version uint64) *value {
+	return &value{
+		data:    data,
+		version: version,
+	}
+}
+
+func (v *value) IsNewer(other kv.Value) bool {
+	return v.Version() > other.Version()
+}
+
+func (v *value) Unmarshal(msg proto.Message) error {
+	return proto.Unmarshal(v.data, msg)
+}
+
+func (v *value) Version() int {
+	return int(v.version)
+}
+
+func (v *value) IsStale() bool {
+	return false
+}
@@ -0,0 +1,487 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package consul
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/kvtest"
+	"github.com/m3db/m3/src/cluster/kv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSetCheckAndSet(t *testing.T) {
+	store, _, closer := testStore(t)
+	defer closer()
+
+	_, err := store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	version, err := store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+
+	value, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar1", version)
+	require.False(t, value.IsStale())
+
+	_, err = store.CheckAndSet("foo", version+1, genProto("bar2"))
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	newVersion, err := store.CheckAndSet("foo", version, genProto("bar2"))
+	require.NoError(t, err)
+	require.True(t, newVersion > version)
+
+	value, err = store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar2", newVersion)
+
+	_, err = store.SetIfNotExists("foo", genProto("bar3"))
+	require.Equal(t, kv.ErrAlreadyExists, err)
+
+	version, err = store.SetIfNotExists("baz", genProto("bar3"))
+	require.NoError(t, err)
+
+	value, err = store.Get("baz")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar3", version)
+
+	_, err = store.History("foo", 0, 10)
+	require.Equal(t, errHistoryNotSupported, err)
+}
+
+func TestDelete(t *testing.T) {
+	store, _, closer := testStore(t)
+	defer closer()
+
+	_, err := store.Delete("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	version, err := store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+
+	prev, err := store.Delete("foo")
+	require.NoError(t, err)
+	verifyValue(t, prev, "bar1", version)
+
+	_, err = store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	_, err = store.DeleteIfVersionMatches("foo", 0)
+	require.Equal(t, kv.ErrNotFound, err)
+
+	version, err = store.Set("foo", genProto("bar2"))
+	require.NoError(t, err)
+
+	_, err = store.DeleteIfVersionMatches("foo", 0)
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	_, err = store.DeleteIfVersionMatches("foo", version+1)
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	prev, err = store.DeleteIfVersionMatches("foo", version)
+	require.NoError(t, err)
+	verifyValue(t, prev, "bar2", version)
+
+	_, err = store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+}
+
+func TestSetWithTTL(t *testing.T) {
+	store, server, closer := testStore(t)
+	defer closer()
+
+	_, err := store.SetWithTTL("foo", genProto("bar1"), 0)
+	require.Equal(t, kv.ErrInvalidTTL, err)
+
+	version, err := store.SetWithTTL("foo", genProto("bar1"), time.Second)
+	require.NoError(t, err)
+
+	value, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar1", version)
+
+	session := server.lastSession()
+	require.Equal(t, "10s", server.sessionTTL(session))
+
+	server.expireSession(session)
+	_, err = store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	_, err = store.SetWithTTL("foo", genProto("bar2"), 15*time.Second)
+	require.NoError(t, err)
+	session = server.lastSession()
+	require.Equal(t, "15s", server.sessionTTL(session))
+
+	// Setting the key again releases it from the session.
+	version, err = store.Set("foo", genProto("bar3"))
+	require.NoError(t, err)
+
+	server.expireSession(session)
+	value, err = store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar3", version)
+}
+
+func TestWatch(t *testing.T) {
+	store, _, closer := testStore(t)
+	defer closer()
+
+	w, err := store.Watch("foo")
+	require.NoError(t, err)
+	require.Nil(t, w.Get())
+
+	version, err := store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+
+	<-w.C()
+	verifyValue(t, w.Get(), "bar1", version)
+
+	version, err = store.Set("foo", genProto("bar2"))
+	require.NoError(t, err)
+
+	<-w.C()
+	verifyValue(t, w.Get(), "bar2", version)
+
+	_, err = store.Delete("foo")
+	require.NoError(t, err)
+
+	<-w.C()
+	require.Nil(t, w.Get())
+
+	w.Close()
+}
+
+func TestWatchPrefix(t *testing.T) {
+	store, _, closer := testStore(t)
+	defer closer()
+
+	version1, err := store.Set("foo/a", genProto("bar1"))
+	require.NoError(t, err)
+
+	w, err := store.WatchPrefix("foo/")
+	require.NoError(t, err)
+
+	<-w.C()
+	values := w.Get()
+	require.Len(t, values, 1)
+	verifyValue(t, values["foo/a"], "bar1", version1)
+
+	version2, err := store.Set("foo/b", genProto("bar2"))
+	require.NoError(t, err)
+	_, err = store.Set("other", genProto("bar3"))
+	require.NoError(t, err)
+
+	for len(w.Get()) != 2 {
+		<-w.C()
+	}
+	values = w.Get()
+	verifyValue(t, values["foo/a"], "bar1", version1)
+	verifyValue(t, values["foo/b"], "bar2", version2)
+
+	w.Close()
+}
+
+func TestListKeys(t *testing.T) {
+	store, _, closer := testStore(t)
+	defer closer()
+
+	for _, key := range []string{"ns/b", "ns/a", "ns/c", "other"} {
+		_, err := store.Set(key, genProto(key))
+		require.NoError(t, err)
+	}
+
+	page, err := store.ListKeys("ns/", 2, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/a", "ns/b"}, page.Keys)
+	require.Equal(t, "ns/b", page.NextPageToken)
+
+	page, err = store.ListKeys("ns/", 2, page.NextPageToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/c"}, page.Keys)
+	require.Empty(t, page.NextPageToken)
+
+	page, err = store.ListKeys("", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/a", "ns/b", "ns/c", "other"}, page.Keys)
+
+	page, err = store.ListKeys("missing/", 10, "")
+	require.NoError(t, err)
+	require.Empty(t, page.Keys)
+}
+
+func TestNextIndex(t *testing.T) {
+	require.Equal(t, uint64(5), nextIndex(3, 5))
+	require.Equal(t, uint64(0), nextIndex(5, 3))
+	require.Equal(t, uint64(1), nextIndex(0, 0))
+}
+
+func testStore(t *testing.T) (kv.Store, *fakeConsul, func()) {
+	server := newFakeConsul()
+	httpServer := httptest.NewServer(server)
+
+	opts := NewOptions().
+		SetAddress(httpServer.URL).
+		SetPrefix("test").
+		SetWatchWaitTime(time.Second).
+		SetWatchRetryInterval(10 * time.Millisecond)
+	store, err := NewStore(opts)
+	require.NoError(t, err)
+
+	return store, server, httpServer.Close
+}
+
+func verifyValue(t *testing.T, v kv.Value, value string, version int) {
+	var testMsg kvtest.Foo
+	err := v.Unmarshal(&testMsg)
+	require.NoError(t, err)
+	require.Equal(t, value, testMsg.Msg)
+	require.Equal(t, version, v.Version())
+}
+
+func genProto(msg string) proto.Message {
+	return &kvtest.Foo{Msg: msg}
+}
+
+// fakeConsul implements the subset of the consul http api used by the store.
+type fakeConsul struct {
+	sync.Mutex
+
+	index    uint64
+	pairs    map[string]kvPair
+	sessions map[string]string
+	session  string
+	changed  chan struct{}
+}
+
+func newFakeConsul() *fakeConsul {
+	return &fakeConsul{
+		index:    1,
+		pairs:    make(map[string]kvPair),
+		sessions: make(map[string]string),
+		changed:  make(chan struct{}),
+	}
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, kvPathPrefix):
+		f.get(w, r)
+	case r.Method == http.MethodPut && r.URL.Path == txnPath:
+		f.txn(w, r)
+	case r.Method == http.MethodPut && r.URL.Path == sessionCreatePath:
+		f.createSession(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeConsul) get(w http.ResponseWriter, r *http.Request) {
+	var (
+		key     = strings.TrimPrefix(r.URL.Path, kvPathPrefix)
+		recurse = r.URL.Query().Get("recurse") == "true"
+		keys    = r.URL.Query().Get("keys") == "true"
+	)
+	if index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); index > 0 {
+		wait, err := time.ParseDuration(r.URL.Query().Get("wait"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.Lock()
+		changed, current := f.changed, f.index
+		f.Unlock()
+		if index >= current {
+			select {
+			case <-changed:
+			case <-time.After(wait):
+			}
+		}
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	var pairs []kvPair
+	for k, pair := range f.pairs {
+		if k == key || ((recurse || keys) && strings.HasPrefix(k, key)) {
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Key < pairs[j].Key
+	})
+
+	w.Header().Set(indexHeader, strconv.FormatUint(f.index, 10))
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if keys {
+		names := make([]string, 0, len(pairs))
+		for _, pair := range pairs {
+			names = append(names, pair.Key)
+		}
+		json.NewEncoder(w).Encode(names) // nolint:errcheck
+		return
+	}
+	json.NewEncoder(w).Encode(pairs) // nolint:errcheck
+}
+
+func (f *fakeConsul) txn(w http.ResponseWriter, r *http.Request) {
+	var ops []txnOp
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	var (
+		index   = f.index + 1
+		pairs   = make(map[string]kvPair, len(f.pairs))
+		results []txnResult
+	)
+	for k, pair := range f.pairs {
+		pairs[k] = pair
+	}
+	for i, op := range ops {
+		pair, exists := pairs[op.KV.Key]
+		var (
+			failure string
+			result  *kvPair
+		)
+		switch op.KV.Verb {
+		case "get":
+			if !exists {
+				failure = "key doesn't exist"
+				break
+			}
+			result = &pair
+		case "check-index":
+			if !exists || pair.ModifyIndex != op.KV.Index {
+				failure = "current modify index does not match"
+			}
+		case "check-not-exists":
+			if exists {
+				failure = "key already exists"
+			}
+		case "set", "lock":
+			if op.KV.Verb == "lock" && pair.Session != "" && pair.Session != op.KV.Session {
+				failure = "key is locked by another session"
+				break
+			}
+			pair = kvPair{Key: op.KV.Key, Value: op.KV.Value, ModifyIndex: index, Session: pair.Session}
+			if op.KV.Verb == "lock" {
+				pair.Session = op.KV.Session
+			}
+			pairs[op.KV.Key] = pair
+			result = &kvPair{Key: pair.Key, ModifyIndex: pair.ModifyIndex, Session: pair.Session}
+		case "unlock":
+			if !exists || pair.Session != op.KV.Session {
+				failure = "key is not locked by the session"
+				break
+			}
+			pair.Session = ""
+			pair.ModifyIndex = index
+			pairs[op.KV.Key] = pair
+			result = &kvPair{Key: pair.Key, ModifyIndex: pair.ModifyIndex}
+		case "delete":
+			delete(pairs, op.KV.Key)
+		default:
+			failure = fmt.Sprintf("unknown verb %s", op.KV.Verb)
+		}
+
+		if failure != "" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(txnResponse{ // nolint:errcheck
+				Errors: []txnOpError{{OpIndex: i, What: failure}},
+			})
+			return
+		}
+		if result != nil {
+			results = append(results, txnResult{KV: result})
+		}
+	}
+
+	f.commitWithLock(index, pairs)
+	json.NewEncoder(w).Encode(txnResponse{Results: results}) // nolint:errcheck
+}
+
+func (f *fakeConsul) createSession(w http.ResponseWriter, r *http.Request) {
+	var req sessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	f.session = fmt.Sprintf("session-%d", len(f.sessions))
+	f.sessions[f.session] = req.TTL
+	json.NewEncoder(w).Encode(sessionResponse{ID: f.session}) // nolint:errcheck
+}
+
+func (f *fakeConsul) commitWithLock(index uint64, pairs map[string]kvPair) {
+	f.index = index
+	f.pairs = pairs
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) lastSession() string {
+	f.Lock()
+	defer f.Unlock()
+	return f.session
+}
+
+func (f *fakeConsul) sessionTTL(session string) string {
+	f.Lock()
+	defer f.Unlock()
+	return f.sessions[session]
+}
+
+// expireSession invalidates the session and deletes the keys it holds.
+func (f *fakeConsul) expireSession(session string) {
+	f.Lock()
+	defer f.Unlock()
+
+	pairs := make(map[string]kvPair, len(f.pairs))
+	for k, pair := range f.pairs {
+		if pair.Session != session {
+			pairs[k] = pair
+		}
+	}
+	f.commitWithLock(f.index+1, pairs)
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encrypted
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Encrypted values are prefixed with a header byte identifying how they were
+// encrypted. Like the header bytes of values compressed or chunked by the etcd
+// store, which use the values 1 to 3, they carry the protobuf wire type 7
+// which is not a valid wire type, so plaintext protobuf values never start
+// with one.
+const (
+	headerAESGCM   byte = 4<<3 | 7
+	headerEnvelope byte = 5<<3 | 7
+)
+
+const (
+	// dataKeySize is the size of the data keys used for envelope encryption,
+	// selecting AES-256.
+	dataKeySize = 32
+
+	// defaultDataKeyCacheSize is the number of decrypted data keys cached by
+	// the envelope encrypter so that reading the same value repeatedly, for
+	// instance on every watch update, does not call the key manager each time.
+	defaultDataKeyCacheSize = 1024
+)
+
+var (
+	errInvalidKeySize      = errors.New("aes key must be 16, 24 or 32 bytes")
+	errNilKeyManager       = errors.New("nil key manager")
+	errCiphertextTooShort  = errors.New("ciphertext too short")
+	errUnexpectedHeader    = errors.New("value is not encrypted with this encrypter")
+	errInvalidEncryptedKey = errors.New("invalid encrypted data key")
+)
+
+// Encrypter encrypts and decrypts kv values.
+type Encrypter interface {
+	// Encrypt encrypts the plaintext and authenticates the additional data,
+	// which must be provided again to decrypt the returned ciphertext.
+	Encrypt(plaintext, additionalData []byte) ([]byte, error)
+
+	// Decrypt decrypts a ciphertext returned by Encrypt.
+	Decrypt(ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// KeyManager generates and decrypts the data keys used for envelope
+// encryption. It is typically backed by a key management service holding the
+// key encryption key, which never leaves the service.
+type KeyManager interface {
+	// GenerateDataKey returns a new random 32 byte data key along with the
+	// data key encrypted by the key encryption key.
+	GenerateDataKey() (key []byte, encryptedKey []byte, err error)
+
+	// DecryptDataKey decrypts a data key encrypted by GenerateDataKey.
+	DecryptDataKey(encryptedKey []byte) ([]byte, error)
+}
+
+// IsEncrypted returns whether a value stored in kv has been encrypted.
+func IsEncrypted(value []byte) bool {
+	if len(value) == 0 {
+		return false
+	}
+	return value[0] == headerAESGCM || value[0] == headerEnvelope
+}
+
+type aesGCMEncrypter struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMEncrypter returns an Encrypter encrypting values with AES-GCM using
+// the given 16, 24 or 32 byte key, selecting AES-128, AES-192 or AES-256.
+func NewAESGCMEncrypter(key []byte) (Encrypter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCMEncrypter{aead: aead}, nil
+}
+
+func (e *aesGCMEncrypter) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	return seal(e.aead, []byte{headerAESGCM}, plaintext, additionalData)
+}
+
+func (e *aesGCMEncrypter) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || ciphertext[0] != headerAESGCM {
+		return nil, errUnexpectedHeader
+	}
+	return open(e.aead, ciphertext[1:], additionalData)
+}
+
+type envelopeEncrypter struct {
+	sync.Mutex
+
+	keyManager    KeyManager
+	cacheSize     int
+	decryptedKeys map[string]cipher.AEAD
+}
+
+// NewEnvelopeEncrypter returns an Encrypter encrypting each value with AES-GCM
+// using a new data key generated by the key manager, and storing the data key
+// encrypted by the key manager alongside the value.
+func NewEnvelopeEncrypter(keyManager KeyManager) (Encrypter, error) {
+	if keyManager == nil {
+		return nil, errNilKeyManager
+	}
+	return &envelopeEncrypter{
+		keyManager:    keyManager,
+		cacheSize:     defaultDataKeyCacheSize,
+		decryptedKeys: make(map[string]cipher.AEAD),
+	}, nil
+}
+
+func (e *envelopeEncrypter) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	key, encryptedKey, err := e.keyManager.GenerateDataKey()
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate data key: %w", err)
+	}
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("data key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, 1+binary.MaxVarintLen64+len(encryptedKey))
+	prefix[0] = headerEnvelope
+	n := 1 + binary.PutUvarint(prefix[1:], uint64(len(encryptedKey)))
+	n += copy(prefix[n:], encryptedKey)
+	return seal(aead, prefix[:n], plaintext, additionalData)
+}
+
+func (e *envelopeEncrypter) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || ciphertext[0] != headerEnvelope {
+		return nil, errUnexpectedHeader
+	}
+	ciphertext = ciphertext[1:]
+
+	keyLen, n := binary.Uvarint(ciphertext)
+	if n <= 0 || keyLen == 0 || keyLen > uint64(len(ciphertext)-n) {
+		return nil, errInvalidEncryptedKey
+	}
+	encryptedKey := ciphertext[n : n+int(keyLen)]
+	aead, err := e.dataKey(encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, ciphertext[n+int(keyLen):], additionalData)
+}
+
+func (e *envelopeEncrypter) dataKey(encryptedKey []byte) (cipher.AEAD, error) {
+	e.Lock()
+	aead, ok := e.decryptedKeys[string(encryptedKey)]
+	e.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	key, err := e.keyManager.DecryptDataKey(encryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt data key: %w", err)
+	}
+	aead, err = newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Lock()
+	if len(e.decryptedKeys) >= e.cacheSize {
+		// NB: evict an arbitrary key, values are only decrypted again when
+		// updated so keeping the most recently used keys matters little.
+		for k := range e.decryptedKeys {
+			delete(e.decryptedKeys, k)
+			break
+		}
+	}
+	e.decryptedKeys[string(encryptedKey)] = aead
+	e.Unlock()
+	return aead, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, errInvalidKeySize
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns the prefix followed by a random nonce and the ciphertext.
+func seal(aead cipher.AEAD, prefix, plaintext, additionalData []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	out := make([]byte, len(prefix)+nonceSize, len(prefix)+nonceSize+len(plaintext)+aead.Overhead())
+	copy(out, prefix)
+	nonce := out[len(prefix):]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+	return aead.Seal(out, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(ciphertext) < nonceSize+aead.Overhead() {
+		return nil, errCiphertextTooShort
+	}
+	return aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], additionalData)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encrypted
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESGCMEncrypter(t *testing.T) {
+	_, err := NewAESGCMEncrypter(make([]byte, 10))
+	require.Equal(t, errInvalidKeySize, err)
+
+	e, err := NewAESGCMEncrypter(testKey(t))
+	require.NoError(t, err)
+
+	plaintext := []byte("secret")
+	ciphertext, err := e.Encrypt(plaintext, []byte("key"))
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(ciphertext))
+	require.False(t, bytes.Contains(ciphertext, plaintext))
+
+	// nonces are random so encrypting again yields a different ciphertext.
+	other, err := e.Encrypt(plaintext, []byte("key"))
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertext, other)
+
+	decrypted, err := e.Decrypt(ciphertext, []byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	_, err = e.Decrypt(ciphertext, []byte("other"))
+	require.Error(t, err)
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = e.Decrypt(tampered, []byte("key"))
+	require.Error(t, err)
+
+	_, err = e.Decrypt(ciphertext[:5], []byte("key"))
+	require.Equal(t, errCiphertextTooShort, err)
+
+	wrongKey, err := NewAESGCMEncrypter(testKey(t))
+	require.NoError(t, err)
+	_, err = wrongKey.Decrypt(ciphertext, []byte("key"))
+	require.Error(t, err)
+}
+
+func TestEnvelopeEncrypter(t *testing.T) {
+	_, err := NewEnvelopeEncrypter(nil)
+	require.Equal(t, errNilKeyManager, err)
+
+	km := newTestKeyManager(t)
+	e, err := NewEnvelopeEncrypter(km)
+	require.NoError(t, err)
+
+	plaintext := []byte("secret")
+	ciphertext, err := e.Encrypt(plaintext, []byte("key"))
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(ciphertext))
+	require.False(t, bytes.Contains(ciphertext, plaintext))
+	require.Equal(t, 1, km.generated)
+
+	for i := 0; i < 3; i++ {
+		decrypted, err := e.Decrypt(ciphertext, []byte("key"))
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	}
+	// the decrypted data key is cached.
+	require.Equal(t, 1, km.decrypted)
+
+	_, err = e.Decrypt(ciphertext, []byte("other"))
+	require.Error(t, err)
+
+	// a new encrypter has to decrypt the data key again.
+	e, err = NewEnvelopeEncrypter(km)
+	require.NoError(t, err)
+	decrypted, err := e.Decrypt(ciphertext, []byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+	require.Equal(t, 2, km.decrypted)
+
+	km.err = errors.New("unavailable")
+	e, err = NewEnvelopeEncrypter(km)
+	require.NoError(t, err)
+	_, err = e.Decrypt(ciphertext, []byte("key"))
+	require.True(t, errors.Is(err, km.err))
+	_, err = e.Encrypt(plaintext, []byte("key"))
+	require.True(t, errors.Is(err, km.err))
+
+	_, err = e.Decrypt([]byte{headerEnvelope, 0xff}, []byte("key"))
+	require.Equal(t, errInvalidEncryptedKey, err)
+}
+
+func TestEncryptersRejectEachOthersValues(t *testing.T) {
+	aesGCM, err := NewAESGCMEncrypter(testKey(t))
+	require.NoError(t, err)
+	envelope, err := NewEnvelopeEncrypter(newTestKeyManager(t))
+	require.NoError(t, err)
+
+	ciphertext, err := aesGCM.Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+	_, err = envelope.Decrypt(ciphertext, nil)
+	require.Equal(t, errUnexpectedHeader, err)
+
+	ciphertext, err = envelope.Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+	_, err = aesGCM.Decrypt(ciphertext, nil)
+	require.Equal(t, errUnexpectedHeader, err)
+}
+
+func TestIsEncrypted(t *testing.T) {
+	require.False(t, IsEncrypted(nil))
+	require.False(t, IsEncrypted([]byte{0x0a, 0x01, 'a'}))
+	require.True(t, IsEncrypted([]byte{headerAESGCM}))
+	require.True(t, IsEncrypted([]byte{headerEnvelope}))
+}
+
+func testKey(t *testing.T) []byte {
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+// testKeyManager wraps data keys with a key encryption key held in memory.
+type testKeyManager struct {
+	kek       Encrypter
+	err       error
+	generated int
+	decrypted int
+}
+
+func newTestKeyManager(t *testing.T) *testKeyManager {
+	kek, err := NewAESGCMEncrypter(testKey(t))
+	require.NoError(t, err)
+	return &testKeyManager{kek: kek}
+}
+
+func (m *testKeyManager) GenerateDataKey() ([]byte, []byte, error) {
+	if m.err != nil {
+		return nil, nil, m.err
+	}
+	m.generated++
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	encryptedKey, err := m.kek.Encrypt(key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, encryptedKey, nil
+}
+
+func (m *testKeyManager) DecryptDataKey(encryptedKey []byte) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.decrypted++
+	return m.kek.Decrypt(encryptedKey, nil)
+}
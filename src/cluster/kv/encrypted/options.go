@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encrypted
+
+import "errors"
+
+var errNilEncrypter = errors.New("nil encrypter")
+
+// Options are the options of an encrypted store.
+type Options interface {
+	// SetEncrypter sets the encrypter of the values.
+	SetEncrypter(value Encrypter) Options
+
+	// Encrypter returns the encrypter of the values.
+	Encrypter() Encrypter
+
+	// SetAllowPlaintextReads sets whether values which are not encrypted are
+	// read as is, which allows encrypting existing keys by setting them again.
+	SetAllowPlaintextReads(value bool) Options
+
+	// AllowPlaintextReads returns whether values which are not encrypted are
+	// read as is.
+	AllowPlaintextReads() bool
+
+	// Validate validates the options.
+	Validate() error
+}
+
+type options struct {
+	encrypter           Encrypter
+	allowPlaintextReads bool
+}
+
+// NewOptions returns new options of an encrypted store.
+func NewOptions() Options {
+	return &options{}
+}
+
+func (o *options) SetEncrypter(value Encrypter) Options {
+	opts := *o
+	opts.encrypter = value
+	return &opts
+}
+
+func (o *options) Encrypter() Encrypter {
+	return o.encrypter
+}
+
+func (o *options) SetAllowPlaintextReads(value bool) Options {
+	opts := *o
+	opts.allowPlaintextReads = value
+	return &opts
+}
+
+func (o *options) AllowPlaintextReads() bool {
+	return o.allowPlaintextReads
+}
+
+func (o *options) Validate() error {
+	if o.encrypter == nil {
+		return errNilEncrypter
+	}
+	return nil
+}
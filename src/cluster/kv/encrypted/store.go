@@ -0,0 +1,309 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package encrypted provides a kv store wrapper encrypting values before they
+// are written to the underlying store, so that sensitive runtime configuration
+// is not stored in plaintext.
+package encrypted
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+
+	"github.com/golang/protobuf/proto"
+)
+
+var errNotEncrypted = errors.New("value is not encrypted")
+
+type store struct {
+	store               kv.Store
+	encrypter           Encrypter
+	allowPlaintextReads bool
+}
+
+// NewStore returns a kv store encrypting the values written to the given
+// store and decrypting the values read from it. The key of a value is
+// authenticated along with it, so a value copied to another key can not be
+// read.
+func NewStore(s kv.Store, opts Options) (kv.Store, error) {
+	return newStore(s, opts)
+}
+
+func newStore(s kv.Store, opts Options) (*store, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &store{
+		store:               s,
+		encrypter:           opts.Encrypter(),
+		allowPlaintextReads: opts.AllowPlaintextReads(),
+	}, nil
+}
+
+func (s *store) Get(key string) (kv.Value, error) {
+	v, err := s.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.newValue(key, v), nil
+}
+
+func (s *store) GetMany(keys []string) (map[string]kv.Value, error) {
+	values, err := s.store.GetMany(keys)
+	if err != nil {
+		return nil, err
+	}
+	return s.newValues(values), nil
+}
+
+func (s *store) Watch(key string) (kv.ValueWatch, error) {
+	w, err := s.store.Watch(key)
+	if err != nil {
+		return nil, err
+	}
+	return &valueWatch{ValueWatch: w, key: key, store: s}, nil
+}
+
+func (s *store) WatchFromVersion(key string, version int) (kv.ValueWatch, error) {
+	w, err := s.store.WatchFromVersion(key, version)
+	if err != nil {
+		return nil, err
+	}
+	return &valueWatch{ValueWatch: w, key: key, store: s}, nil
+}
+
+func (s *store) WatchPrefix(prefix string) (kv.PrefixWatch, error) {
+	w, err := s.store.WatchPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &prefixWatch{PrefixWatch: w, store: s}, nil
+}
+
+func (s *store) Set(key string, v proto.Message) (int, error) {
+	sealed, err := s.seal(key, v)
+	if err != nil {
+		return 0, err
+	}
+	return s.store.Set(key, sealed)
+}
+
+func (s *store) SetWithTTL(key string, v proto.Message, ttl time.Duration) (int, error) {
+	sealed, err := s.seal(key, v)
+	if err != nil {
+		return 0, err
+	}
+	return s.store.SetWithTTL(key, sealed, ttl)
+}
+
+func (s *store) SetIfNotExists(key string, v proto.Message) (int, error) {
+	sealed, err := s.seal(key, v)
+	if err != nil {
+		return 0, err
+	}
+	return s.store.SetIfNotExists(key, sealed)
+}
+
+func (s *store) CheckAndSet(key string, version int, v proto.Message) (int, error) {
+	sealed, err := s.seal(key, v)
+	if err != nil {
+		return 0, err
+	}
+	return s.store.CheckAndSet(key, version, sealed)
+}
+
+func (s *store) Delete(key string) (kv.Value, error) {
+	v, err := s.store.Delete(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.newValue(key, v), nil
+}
+
+func (s *store) DeleteIfVersionMatches(key string, version int) (kv.Value, error) {
+	v, err := s.store.DeleteIfVersionMatches(key, version)
+	if err != nil {
+		return nil, err
+	}
+	return s.newValue(key, v), nil
+}
+
+func (s *store) History(key string, from, to int) ([]kv.Value, error) {
+	values, err := s.store.History(key, from, to)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]kv.Value, 0, len(values))
+	for _, v := range values {
+		res = append(res, s.newValue(key, v))
+	}
+	return res, nil
+}
+
+func (s *store) ListKeys(prefix string, pageSize int, pageToken string) (kv.KeyPage, error) {
+	return s.store.ListKeys(prefix, pageSize, pageToken)
+}
+
+func (s *store) seal(key string, v proto.Message) (*sealedValue, error) {
+	plaintext, err := proto.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := s.encrypter.Encrypt(plaintext, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt value of key %s: %w", key, err)
+	}
+	return &sealedValue{data: ciphertext}, nil
+}
+
+func (s *store) open(key string, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		if s.allowPlaintextReads {
+			return data, nil
+		}
+		return nil, fmt.Errorf("unable to read value of key %s: %w", key, errNotEncrypted)
+	}
+	plaintext, err := s.encrypter.Decrypt(data, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt value of key %s: %w", key, err)
+	}
+	return plaintext, nil
+}
+
+func (s *store) newValue(key string, v kv.Value) kv.Value {
+	if v == nil {
+		return nil
+	}
+	return &value{Value: v, key: key, store: s}
+}
+
+func (s *store) newValues(values map[string]kv.Value) map[string]kv.Value {
+	if values == nil {
+		return nil
+	}
+	res := make(map[string]kv.Value, len(values))
+	for key, v := range values {
+		res[key] = s.newValue(key, v)
+	}
+	return res
+}
+
+type txnStore struct {
+	*store
+
+	txnStore kv.TxnStore
+}
+
+// NewTxnStore returns a transactional kv store encrypting the values written
+// to the given store like NewStore, including the values set by transactions.
+func NewTxnStore(s kv.TxnStore, opts Options) (kv.TxnStore, error) {
+	encrypted, err := newStore(s, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &txnStore{store: encrypted, txnStore: s}, nil
+}
+
+func (s *txnStore) Commit(conditions []kv.Condition, ops []kv.Op) (kv.Response, error) {
+	sealedOps := make([]kv.Op, 0, len(ops))
+	for _, op := range ops {
+		setOp, ok := op.(kv.SetOp)
+		if !ok {
+			sealedOps = append(sealedOps, op)
+			continue
+		}
+		sealed, err := s.seal(setOp.Key(), setOp.Value)
+		if err != nil {
+			return nil, err
+		}
+		sealedOps = append(sealedOps, kv.NewSetOp(setOp.Key(), sealed))
+	}
+	return s.txnStore.Commit(conditions, sealedOps)
+}
+
+// value decrypts the value read from the underlying store when unmarshalled.
+type value struct {
+	kv.Value
+
+	key   string
+	store *store
+}
+
+func (v *value) Unmarshal(msg proto.Message) error {
+	var sealed sealedValue
+	if err := v.Value.Unmarshal(&sealed); err != nil {
+		return err
+	}
+	plaintext, err := v.store.open(v.key, sealed.data)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(plaintext, msg)
+}
+
+func (v *value) IsNewer(other kv.Value) bool {
+	if o, ok := other.(*value); ok {
+		other = o.Value
+	}
+	return v.Value.IsNewer(other)
+}
+
+type valueWatch struct {
+	kv.ValueWatch
+
+	key   string
+	store *store
+}
+
+func (w *valueWatch) Get() kv.Value {
+	return w.store.newValue(w.key, w.ValueWatch.Get())
+}
+
+type prefixWatch struct {
+	kv.PrefixWatch
+
+	store *store
+}
+
+func (w *prefixWatch) Get() map[string]kv.Value {
+	return w.store.newValues(w.PrefixWatch.Get())
+}
+
+// sealedValue is the message written to the underlying store, it marshals to
+// the encrypted value as is.
+type sealedValue struct {
+	data []byte
+}
+
+func (m *sealedValue) Reset()         { m.data = nil }
+func (m *sealedValue) String() string { return fmt.Sprintf("sealedValue{%d bytes}", len(m.data)) }
+func (m *sealedValue) ProtoMessage()  {}
+
+func (m *sealedValue) Marshal() ([]byte, error) {
+	return m.data, nil
+}
+
+func (m *sealedValue) Unmarshal(data []byte) error {
+	m.data = append(m.data[:0], data...)
+	return nil
+}
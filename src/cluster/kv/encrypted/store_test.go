@@ -0,0 +1,220 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encrypted
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreGetAndSet(t *testing.T) {
+	inner, s := testStore(t)
+
+	_, err := s.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	version, err := s.Set("foo", &commonpb.StringProto{Value: "secret"})
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+	requireValue(t, s, "foo", "secret", 1)
+	requireSealed(t, inner, "foo")
+
+	version, err = s.CheckAndSet("foo", 1, &commonpb.StringProto{Value: "other"})
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+	requireValue(t, s, "foo", "other", 2)
+	requireSealed(t, inner, "foo")
+
+	_, err = s.SetIfNotExists("foo", &commonpb.StringProto{Value: "secret"})
+	require.Equal(t, kv.ErrAlreadyExists, err)
+
+	_, err = s.SetIfNotExists("bar", &commonpb.StringProto{Value: "secret"})
+	require.NoError(t, err)
+	requireValue(t, s, "bar", "secret", 1)
+
+	values, err := s.GetMany([]string{"foo", "bar", "baz"})
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	requireStringValue(t, values["foo"], "other")
+	requireStringValue(t, values["bar"], "secret")
+
+	history, err := s.History("foo", 1, 3)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	requireStringValue(t, history[0], "secret")
+	requireStringValue(t, history[1], "other")
+
+	v, err := s.Delete("foo")
+	require.NoError(t, err)
+	requireStringValue(t, v, "other")
+}
+
+func TestStoreValueBoundToKey(t *testing.T) {
+	inner, s := testStore(t)
+
+	_, err := s.Set("foo", &commonpb.StringProto{Value: "secret"})
+	require.NoError(t, err)
+
+	// copy the encrypted value of foo to bar.
+	v, err := inner.Get("foo")
+	require.NoError(t, err)
+	var sealed sealedValue
+	require.NoError(t, v.Unmarshal(&sealed))
+	_, err = inner.Set("bar", &sealed)
+	require.NoError(t, err)
+
+	v, err = s.Get("bar")
+	require.NoError(t, err)
+	require.Error(t, v.Unmarshal(&commonpb.StringProto{}))
+}
+
+func TestStorePlaintextReads(t *testing.T) {
+	inner := mem.NewStore()
+	_, err := inner.Set("foo", &commonpb.StringProto{Value: "plaintext"})
+	require.NoError(t, err)
+
+	s, err := NewStore(inner, testOptions(t))
+	require.NoError(t, err)
+	v, err := s.Get("foo")
+	require.NoError(t, err)
+	err = v.Unmarshal(&commonpb.StringProto{})
+	require.True(t, errors.Is(err, errNotEncrypted))
+
+	s, err = NewStore(inner, testOptions(t).SetAllowPlaintextReads(true))
+	require.NoError(t, err)
+	requireValue(t, s, "foo", "plaintext", 1)
+
+	// setting the key again encrypts it.
+	_, err = s.Set("foo", &commonpb.StringProto{Value: "plaintext"})
+	require.NoError(t, err)
+	requireValue(t, s, "foo", "plaintext", 2)
+	requireSealed(t, inner, "foo")
+}
+
+func TestStoreWatch(t *testing.T) {
+	_, s := testStore(t)
+
+	w, err := s.Watch("foo")
+	require.NoError(t, err)
+	defer w.Close()
+
+	prefixWatch, err := s.WatchPrefix("f")
+	require.NoError(t, err)
+	defer prefixWatch.Close()
+
+	_, err = s.Set("foo", &commonpb.StringProto{Value: "secret"})
+	require.NoError(t, err)
+
+	<-w.C()
+	requireStringValue(t, w.Get(), "secret")
+
+	for {
+		<-prefixWatch.C()
+		if v, ok := prefixWatch.Get()["foo"]; ok {
+			requireStringValue(t, v, "secret")
+			break
+		}
+	}
+
+	_, err = s.Set("foo", &commonpb.StringProto{Value: "other"})
+	require.NoError(t, err)
+	<-w.C()
+	v := w.Get()
+	requireStringValue(t, v, "other")
+
+	versionWatch, err := s.WatchFromVersion("foo", 1)
+	require.NoError(t, err)
+	defer versionWatch.Close()
+	<-versionWatch.C()
+	requireStringValue(t, versionWatch.Get(), "other")
+	require.False(t, versionWatch.Get().IsNewer(v))
+}
+
+func TestTxnStoreCommit(t *testing.T) {
+	inner := mem.NewStore()
+	s, err := NewTxnStore(inner, testOptions(t))
+	require.NoError(t, err)
+
+	_, err = s.Commit(
+		[]kv.Condition{
+			kv.NewCondition().
+				SetCompareType(kv.CompareEqual).
+				SetTargetType(kv.TargetVersion).
+				SetKey("foo").
+				SetValue(0),
+		},
+		[]kv.Op{
+			kv.NewSetOp("foo", &commonpb.StringProto{Value: "secret"}),
+			kv.NewSetOp("bar", &commonpb.StringProto{Value: "other"}),
+		},
+	)
+	require.NoError(t, err)
+
+	requireValue(t, s, "foo", "secret", 1)
+	requireValue(t, s, "bar", "other", 1)
+	requireSealed(t, inner, "foo")
+	requireSealed(t, inner, "bar")
+}
+
+func TestNewStoreValidatesOptions(t *testing.T) {
+	_, err := NewStore(mem.NewStore(), NewOptions())
+	require.Equal(t, errNilEncrypter, err)
+}
+
+func testOptions(t *testing.T) Options {
+	e, err := NewAESGCMEncrypter(testKey(t))
+	require.NoError(t, err)
+	return NewOptions().SetEncrypter(e)
+}
+
+func testStore(t *testing.T) (kv.Store, kv.Store) {
+	inner := mem.NewStore()
+	s, err := NewStore(inner, testOptions(t))
+	require.NoError(t, err)
+	return inner, s
+}
+
+func requireValue(t *testing.T, s kv.Store, key, expected string, version int) {
+	v, err := s.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, version, v.Version())
+	requireStringValue(t, v, expected)
+}
+
+func requireStringValue(t *testing.T, v kv.Value, expected string) {
+	var msg commonpb.StringProto
+	require.NoError(t, v.Unmarshal(&msg))
+	require.Equal(t, expected, msg.Value)
+}
+
+func requireSealed(t *testing.T, inner kv.Store, key string) {
+	v, err := inner.Get(key)
+	require.NoError(t, err)
+	var sealed sealedValue
+	require.NoError(t, v.Unmarshal(&sealed))
+	require.True(t, IsEncrypted(sealed.data))
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConditionFailure describes a transaction condition that does not hold.
+type ConditionFailure struct {
+	// Condition is the condition that does not hold.
+	Condition Condition
+	// ActualVersion is the current version of the condition key, zero if the
+	// key does not exist.
+	ActualVersion int
+}
+
+// ConditionCheckFailedError is returned when a transaction is not committed
+// since some of its conditions do not hold. It matches ErrConditionCheckFailed
+// with errors.Is.
+type ConditionCheckFailedError struct {
+	// Failures are the conditions that do not hold, in the order they were
+	// given to the transaction.
+	Failures []ConditionFailure
+}
+
+// NewConditionCheckFailedError returns a new condition check failed error.
+func NewConditionCheckFailedError(failures []ConditionFailure) *ConditionCheckFailedError {
+	return &ConditionCheckFailedError{Failures: failures}
+}
+
+func (e *ConditionCheckFailedError) Error() string {
+	if len(e.Failures) == 0 {
+		return ErrConditionCheckFailed.Error()
+	}
+
+	failures := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		failures = append(failures, fmt.Sprintf("key %s expected version %v, actual %d",
+			f.Condition.Key(), f.Condition.Value(), f.ActualVersion))
+	}
+	return fmt.Sprintf("%s: %s", ErrConditionCheckFailed.Error(), strings.Join(failures, ", "))
+}
+
+func (e *ConditionCheckFailedError) Unwrap() error {
+	return ErrConditionCheckFailed
+}
+
+// ReadOnlyError is returned when a mutation is rejected since the store is
+// read only. It matches ErrReadOnly with errors.Is.
+type ReadOnlyError struct {
+	// Op is the name of the rejected mutation.
+	Op string
+	// Key is the key of the rejected mutation, empty for transactions.
+	Key string
+}
+
+// NewReadOnlyError returns a new read only error.
+func NewReadOnlyError(op, key string) *ReadOnlyError {
+	return &ReadOnlyError{Op: op, Key: key}
+}
+
+func (e *ReadOnlyError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s: rejected %s", ErrReadOnly.Error(), e.Op)
+	}
+	return fmt.Sprintf("%s: rejected %s of key %s", ErrReadOnly.Error(), e.Op, e.Key)
+}
+
+func (e *ReadOnlyError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// ConditionHolds returns whether a version condition holds for the actual
+// version of its key.
+func ConditionHolds(condition Condition, actualVersion int) bool {
+	switch expected := condition.Value().(type) {
+	case int:
+		return expected == actualVersion
+	case int64:
+		return expected == int64(actualVersion)
+	default:
+		return false
+	}
+}
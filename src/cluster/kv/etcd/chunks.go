@@ -0,0 +1,335 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+)
+
+// Values larger than the chunk size are split into chunks written to sub
+// keys, and the key is set to a manifest referencing them. The manifest is
+// prefixed with a header byte which, like the compression header bytes,
+// carries the invalid protobuf wire type 7.
+const valueHeaderChunked byte = 3<<3 | 7
+
+// chunkKeyPrefix is the reserved sub path of the store under which the chunks
+// are written, chunks are not listed by prefix gets, watches and key listings
+// and the keys under it can not be mutated through the store.
+const chunkKeyPrefix = "_chunks/"
+
+var (
+	errInvalidChunkManifest = errors.New("invalid chunk manifest")
+	errMissingChunks        = errors.New("missing chunks of chunked value")
+	errReservedKey          = errors.New("key is reserved for the chunks of chunked values")
+)
+
+// chunkManifest references the chunks of a chunked value.
+type chunkManifest struct {
+	// id is unique to each chunked write so that the chunks of a new value
+	// never overwrite the chunks of the value it replaces.
+	id        uint64
+	numChunks int
+	size      int
+}
+
+func (m chunkManifest) marshal() []byte {
+	b := make([]byte, 1, 1+3*binary.MaxVarintLen64)
+	b[0] = valueHeaderChunked
+	b = appendUvarint(b, m.id)
+	b = appendUvarint(b, uint64(m.numChunks))
+	return appendUvarint(b, uint64(m.size))
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func isChunkManifest(value []byte) bool {
+	return len(value) > 0 && value[0] == valueHeaderChunked
+}
+
+func unmarshalChunkManifest(value []byte) (chunkManifest, error) {
+	if !isChunkManifest(value) {
+		return chunkManifest{}, errInvalidChunkManifest
+	}
+
+	r := bytes.NewReader(value[1:])
+	id, err := binary.ReadUvarint(r)
+	if err != nil {
+		return chunkManifest{}, errInvalidChunkManifest
+	}
+	numChunks, err := binary.ReadUvarint(r)
+	if err != nil {
+		return chunkManifest{}, errInvalidChunkManifest
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return chunkManifest{}, errInvalidChunkManifest
+	}
+	return chunkManifest{id: id, numChunks: int(numChunks), size: int(size)}, nil
+}
+
+// chunksPrefix returns the prefix of the keys of the chunks of a chunked
+// write to the key, both keys including the store prefix.
+func (c *client) chunksPrefix(key string, id uint64) string {
+	return c.opts.ApplyPrefix(fmt.Sprintf("%s%s/%016x/", chunkKeyPrefix, c.stripPrefix(key), id))
+}
+
+// NB: chunk indexes are zero padded so that the chunks are listed in order.
+func (c *client) chunkKey(key string, id uint64, i int) string {
+	return fmt.Sprintf("%s%08d", c.chunksPrefix(key, id), i)
+}
+
+// isChunkKey returns whether the key, including the store prefix, is the key
+// of a chunk.
+func (c *client) isChunkKey(key string) bool {
+	return isReservedKey(c.stripPrefix(key))
+}
+
+// parseChunkKey returns the key, including the store prefix, and the id of
+// the chunked write of the key of a chunk.
+func (c *client) parseChunkKey(chunkKey string) (string, uint64, bool) {
+	key := strings.TrimPrefix(c.stripPrefix(chunkKey), chunkKeyPrefix)
+	// NB: keys may have slashes of their own, so trim the chunk index and
+	// the id from the end.
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return "", 0, false
+	}
+	key = key[:i]
+	if i = strings.LastIndex(key, "/"); i < 0 {
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(key[i+1:], 16, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return c.opts.ApplyPrefix(key[:i]), id, true
+}
+
+// isReservedKey returns whether the key, without the store prefix, is under
+// the reserved sub path of the chunks.
+func isReservedKey(key string) bool {
+	return strings.HasPrefix(key, chunkKeyPrefix)
+}
+
+// writeChunks writes the chunks of a value larger than the chunk size and
+// returns the manifest to set the key to, smaller values are returned as is.
+// The chunks are written by requests of their own ahead of the manifest
+// since etcd limits the size of whole transactions, the value is committed
+// once the manifest referencing them is set. The chunks are written with the
+// options of the put of the manifest, such as its lease.
+func (c *client) writeChunks(
+	ctx context.Context,
+	key string,
+	value []byte,
+	opts ...clientv3.OpOption,
+) ([]byte, error) {
+	chunkSize := c.opts.ValueChunkSize()
+	if chunkSize <= 0 || len(value) <= chunkSize {
+		return value, nil
+	}
+
+	var idBytes [8]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		return nil, err
+	}
+	m := chunkManifest{
+		id:        binary.LittleEndian.Uint64(idBytes[:]),
+		numChunks: (len(value) + chunkSize - 1) / chunkSize,
+		size:      len(value),
+	}
+
+	for i := 0; i < m.numChunks; i++ {
+		end := (i + 1) * chunkSize
+		if end > len(value) {
+			end = len(value)
+		}
+		chunk := value[i*chunkSize : end]
+		if _, err := c.kv.Put(ctx, c.chunkKey(key, m.id, i), string(chunk), opts...); err != nil {
+			c.m.etcdPutError.Inc(1)
+			c.deleteChunks(ctx, key, m)
+			return nil, err
+		}
+	}
+	return m.marshal(), nil
+}
+
+// readValue returns the value of the key read from etcd, reassembling it
+// from its chunks if it is chunked. The chunks are read at the revision of
+// the manifest so that the chunks of a value being replaced are still read.
+func (c *client) readValue(ctx context.Context, pair *mvccpb.KeyValue) (*value, error) {
+	if !isChunkManifest(pair.Value) {
+		return newValue(pair.Value, pair.Version, pair.ModRevision), nil
+	}
+
+	m, err := unmarshalChunkManifest(pair.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := c.kv.Get(ctx, c.chunksPrefix(string(pair.Key), m.id),
+		clientv3.WithPrefix(), clientv3.WithRev(pair.ModRevision))
+	if err != nil {
+		c.m.etcdGetError.Inc(1)
+		return nil, err
+	}
+	if len(r.Kvs) != m.numChunks {
+		return nil, errMissingChunks
+	}
+
+	val := make([]byte, 0, m.size)
+	for _, chunk := range r.Kvs {
+		val = append(val, chunk.Value...)
+	}
+	if len(val) != m.size {
+		return nil, errMissingChunks
+	}
+	return newValue(val, pair.Version, pair.ModRevision), nil
+}
+
+// deleteReplacedChunks deletes the chunks of a value that was replaced or
+// deleted, if it was chunked.
+func (c *client) deleteReplacedChunks(ctx context.Context, prev *mvccpb.KeyValue) {
+	if prev == nil {
+		return
+	}
+	c.deleteValueChunks(ctx, string(prev.Key), prev.Value)
+}
+
+// deleteValueChunks deletes the chunks of the value of the key, if it is
+// chunked.
+func (c *client) deleteValueChunks(ctx context.Context, key string, value []byte) {
+	if !isChunkManifest(value) {
+		return
+	}
+
+	m, err := unmarshalChunkManifest(value)
+	if err != nil {
+		return
+	}
+	c.deleteChunks(ctx, key, m)
+}
+
+// deleteChunks deletes the chunks of a chunked write, chunks that could not
+// be deleted are left behind without affecting the value of the key.
+func (c *client) deleteChunks(ctx context.Context, key string, m chunkManifest) {
+	if _, err := c.kv.Delete(ctx, c.chunksPrefix(key, m.id), clientv3.WithPrefix()); err != nil {
+		c.logger.Warn("could not delete chunks of value",
+			zap.String("key", key), zap.Error(err))
+	}
+}
+
+// collectChunksEvery collects the chunks which are no longer referenced by
+// their key every interval.
+func (c *client) collectChunksEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// NB: the first collection only records the revision it collected as of,
+	// nothing was written before it.
+	var maxRev int64
+	for range ticker.C {
+		ctx, cancel := c.context()
+		rev, err := c.collectChunks(ctx, maxRev)
+		cancel()
+		if err != nil {
+			c.logger.Warn("could not collect chunks of values", zap.Error(err))
+			continue
+		}
+		maxRev = rev
+	}
+}
+
+// collectChunks deletes the chunks of chunked writes which are not referenced
+// by the manifest of their key. These are left behind by writes which failed
+// without it being known whether the manifest was set and by chunk deletes
+// which failed. Only the chunks created as of the revision of the previous
+// collection are deleted so that the chunks of writes in flight, which have
+// yet to set their manifest, are never deleted. It returns the revision of
+// the store the collection is as of.
+func (c *client) collectChunks(ctx context.Context, maxRev int64) (int64, error) {
+	var r *clientv3.GetResponse
+	finish, err := c.begin(ctx, opCollectChunks, chunkKeyPrefix)
+	if err == nil {
+		r, err = c.kv.Get(ctx, c.opts.ApplyPrefix(chunkKeyPrefix),
+			clientv3.WithPrefix(), clientv3.WithKeysOnly())
+		finish(err)
+	}
+	if err != nil {
+		c.m.etcdGetError.Inc(1)
+		return 0, err
+	}
+
+	type chunkedWrite struct {
+		key string
+		id  uint64
+	}
+	var (
+		writes []chunkedWrite
+		seen   = make(map[chunkedWrite]struct{})
+	)
+	for _, pair := range r.Kvs {
+		if pair.CreateRevision > maxRev {
+			continue
+		}
+		key, id, ok := c.parseChunkKey(string(pair.Key))
+		if !ok {
+			continue
+		}
+		w := chunkedWrite{key: key, id: id}
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+		writes = append(writes, w)
+	}
+
+	for _, w := range writes {
+		manifest, err := c.kv.Get(ctx, w.key)
+		if err != nil {
+			c.m.etcdGetError.Inc(1)
+			return 0, err
+		}
+		if len(manifest.Kvs) > 0 && isChunkManifest(manifest.Kvs[0].Value) {
+			m, err := unmarshalChunkManifest(manifest.Kvs[0].Value)
+			if err == nil && m.id == w.id {
+				continue
+			}
+		}
+		c.deleteChunks(ctx, w.key, chunkManifest{id: w.id})
+	}
+	return r.Header.Revision, nil
+}
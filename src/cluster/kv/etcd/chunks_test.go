@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkManifestRoundTrip(t *testing.T) {
+	m := chunkManifest{id: 0xdeadbeefcafe, numChunks: 12, size: 12 << 20}
+
+	b := m.marshal()
+	require.True(t, isChunkManifest(b))
+
+	actual, err := unmarshalChunkManifest(b)
+	require.NoError(t, err)
+	require.Equal(t, m, actual)
+}
+
+func TestUnmarshalChunkManifestInvalid(t *testing.T) {
+	_, err := unmarshalChunkManifest(nil)
+	require.Equal(t, errInvalidChunkManifest, err)
+
+	_, err = unmarshalChunkManifest([]byte{valueHeaderZstd, 1, 2, 3})
+	require.Equal(t, errInvalidChunkManifest, err)
+
+	// Truncated manifest.
+	b := chunkManifest{id: 1, numChunks: 2, size: 3}.marshal()
+	_, err = unmarshalChunkManifest(b[:len(b)-1])
+	require.Equal(t, errInvalidChunkManifest, err)
+}
+
+func TestChunkKeys(t *testing.T) {
+	c := &client{opts: NewOptions().SetPrefix("test")}
+
+	keys := make([]string, 0, 12)
+	for i := 0; i < 12; i++ {
+		key := c.chunkKey("test/ns/foo", 42, i)
+		require.True(t, c.isChunkKey(key))
+		keys = append(keys, key)
+	}
+	// Chunks are listed in order under the reserved sub path of the store.
+	require.True(t, sort.StringsAreSorted(keys))
+	require.Equal(t, "test/_chunks/ns/foo/000000000000002a/00000011", keys[11])
+	require.False(t, c.isChunkKey("test/ns/foo"))
+
+	key, id, ok := c.parseChunkKey(keys[11])
+	require.True(t, ok)
+	require.Equal(t, "test/ns/foo", key)
+	require.Equal(t, uint64(42), id)
+
+	_, _, ok = c.parseChunkKey("test/_chunks/foo")
+	require.False(t, ok)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+var errCircuitBreakerOpen = errors.New("etcd has been unresponsive, failing fast")
+
+// circuitBreaker fails requests fast once every request to etcd has failed
+// for a window, so that clients do not pile up requests on an unresponsive
+// etcd cluster. While open a single request per probe interval is let through
+// to probe etcd, the first one to succeed closes the circuit breaker.
+type circuitBreaker struct {
+	sync.Mutex
+
+	window        time.Duration
+	probeInterval time.Duration
+	nowFn         func() time.Time
+	failingSince  time.Time
+	lastProbe     time.Time
+
+	open     tally.Gauge
+	rejected tally.Counter
+}
+
+func newCircuitBreaker(window, probeInterval time.Duration, scope tally.Scope) *circuitBreaker {
+	return &circuitBreaker{
+		window:        window,
+		probeInterval: probeInterval,
+		nowFn:         time.Now,
+		open:          scope.Gauge("etcd-circuit-breaker-open"),
+		rejected:      scope.Counter("etcd-circuit-breaker-rejected"),
+	}
+}
+
+// allow returns errCircuitBreakerOpen if the request must fail fast.
+func (b *circuitBreaker) allow() error {
+	if b.window <= 0 {
+		return nil
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.nowFn()
+	if b.failingSince.IsZero() || now.Sub(b.failingSince) < b.window {
+		return nil
+	}
+	b.open.Update(1)
+	if now.Sub(b.lastProbe) >= b.probeInterval {
+		b.lastProbe = now
+		return nil
+	}
+	b.rejected.Inc(1)
+	return errCircuitBreakerOpen
+}
+
+// record records the outcome of a request that was allowed.
+func (b *circuitBreaker) record(err error) {
+	if b.window <= 0 {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if err == nil {
+		if !b.failingSince.IsZero() {
+			b.failingSince = time.Time{}
+			b.open.Update(0)
+		}
+		return
+	}
+	if b.failingSince.IsZero() {
+		b.failingSince = b.nowFn()
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		now     = time.Unix(0, 0)
+		scope   = tally.NewTestScope("", nil)
+		b       = newCircuitBreaker(time.Minute, 10*time.Second, scope)
+		errTest = errors.New("unavailable")
+	)
+	b.nowFn = func() time.Time { return now }
+
+	// Requests are allowed until they have been failing for the window.
+	require.NoError(t, b.allow())
+	b.record(errTest)
+	now = now.Add(30 * time.Second)
+	require.NoError(t, b.allow())
+	b.record(errTest)
+
+	// A single probe is let through per probe interval once open.
+	now = now.Add(30 * time.Second)
+	require.NoError(t, b.allow())
+	require.Equal(t, errCircuitBreakerOpen, b.allow())
+	b.record(errTest)
+	now = now.Add(5 * time.Second)
+	require.Equal(t, errCircuitBreakerOpen, b.allow())
+	now = now.Add(5 * time.Second)
+	require.NoError(t, b.allow())
+
+	// A successful probe closes the circuit breaker.
+	b.record(nil)
+	require.NoError(t, b.allow())
+	require.NoError(t, b.allow())
+
+	snapshot := scope.Snapshot()
+	require.Equal(t, int64(2), snapshot.Counters()["etcd-circuit-breaker-rejected+"].Value())
+	require.Equal(t, float64(0), snapshot.Gauges()["etcd-circuit-breaker-open+"].Value())
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var (
+		now = time.Unix(0, 0)
+		b   = newCircuitBreaker(0, time.Second, tally.NoopScope)
+	)
+	b.nowFn = func() time.Time { return now }
+
+	b.record(errors.New("unavailable"))
+	now = now.Add(time.Hour)
+	require.NoError(t, b.allow())
+	require.NoError(t, b.allow())
+}
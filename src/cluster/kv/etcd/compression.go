@@ -0,0 +1,142 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ValueCompression is the compression of the values written to etcd.
+type ValueCompression string
+
+const (
+	// NoValueCompression writes values uncompressed.
+	NoValueCompression ValueCompression = "none"
+	// SnappyValueCompression compresses values with snappy.
+	SnappyValueCompression ValueCompression = "snappy"
+	// ZstdValueCompression compresses values with zstd.
+	ZstdValueCompression ValueCompression = "zstd"
+)
+
+// Validate validates the value compression.
+func (c ValueCompression) Validate() error {
+	switch c {
+	case NoValueCompression, SnappyValueCompression, ZstdValueCompression:
+		return nil
+	default:
+		return fmt.Errorf("invalid value compression: %s", c)
+	}
+}
+
+// Compressed values are prefixed with a header byte identifying the codec.
+// The header bytes carry the protobuf wire type 7 which is not a valid wire
+// type, so uncompressed protobuf values written by older clients never start
+// with one and are read as is. The header bytes 4<<3|7 and 5<<3|7 are
+// taken by values encrypted by the kv/encrypted store.
+const (
+	valueHeaderSnappy byte = 1<<3 | 7
+	valueHeaderZstd   byte = 2<<3 | 7
+)
+
+var (
+	zstdEncoderOnce sync.Once
+	zstdEncoder     *zstd.Encoder
+	zstdEncoderErr  error
+
+	zstdDecoderOnce sync.Once
+	zstdDecoder     *zstd.Decoder
+	zstdDecoderErr  error
+)
+
+func getZstdEncoder() (*zstd.Encoder, error) {
+	zstdEncoderOnce.Do(func() {
+		zstdEncoder, zstdEncoderErr = zstd.NewWriter(nil,
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	})
+	return zstdEncoder, zstdEncoderErr
+}
+
+func getZstdDecoder() (*zstd.Decoder, error) {
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, zstdDecoderErr = zstd.NewReader(nil,
+			zstd.WithDecoderConcurrency(1))
+	})
+	return zstdDecoder, zstdDecoderErr
+}
+
+// encodeValue compresses the value if it is at least threshold bytes and
+// compressing it saves space, otherwise it is returned as is.
+func encodeValue(
+	value []byte,
+	compression ValueCompression,
+	threshold int,
+) ([]byte, error) {
+	if len(value) < threshold {
+		return value, nil
+	}
+
+	var encoded []byte
+	switch compression {
+	case SnappyValueCompression:
+		encoded = make([]byte, 1+snappy.MaxEncodedLen(len(value)))
+		encoded[0] = valueHeaderSnappy
+		encoded = encoded[:1+len(snappy.Encode(encoded[1:], value))]
+	case ZstdValueCompression:
+		enc, err := getZstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+		encoded = enc.EncodeAll(value, []byte{valueHeaderZstd})
+	default:
+		return value, nil
+	}
+
+	if len(encoded) >= len(value) {
+		return value, nil
+	}
+	return encoded, nil
+}
+
+// decodeValue decompresses the value if it is compressed, values written
+// uncompressed are returned as is.
+func decodeValue(value []byte) ([]byte, error) {
+	if len(value) == 0 {
+		return value, nil
+	}
+
+	switch value[0] {
+	case valueHeaderSnappy:
+		return snappy.Decode(nil, value[1:])
+	case valueHeaderZstd:
+		dec, err := getZstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(value[1:], nil)
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/kvtest"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueCompressionValidate(t *testing.T) {
+	require.NoError(t, NoValueCompression.Validate())
+	require.NoError(t, SnappyValueCompression.Validate())
+	require.NoError(t, ZstdValueCompression.Validate())
+	require.Error(t, ValueCompression("lz4").Validate())
+}
+
+func TestEncodeDecodeValue(t *testing.T) {
+	value, err := proto.Marshal(&kvtest.Foo{Msg: string(bytes.Repeat([]byte("foo"), 1024))})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		compression ValueCompression
+		header      byte
+	}{
+		{compression: SnappyValueCompression, header: valueHeaderSnappy},
+		{compression: ZstdValueCompression, header: valueHeaderZstd},
+	} {
+		t.Run(string(tc.compression), func(t *testing.T) {
+			encoded, err := encodeValue(value, tc.compression, 0)
+			require.NoError(t, err)
+			require.Equal(t, tc.header, encoded[0])
+			require.True(t, len(encoded) < len(value))
+
+			decoded, err := decodeValue(encoded)
+			require.NoError(t, err)
+			require.Equal(t, value, decoded)
+		})
+	}
+}
+
+func TestEncodeValueUncompressed(t *testing.T) {
+	value, err := proto.Marshal(&kvtest.Foo{Msg: string(bytes.Repeat([]byte("foo"), 1024))})
+	require.NoError(t, err)
+
+	// Compression disabled.
+	encoded, err := encodeValue(value, NoValueCompression, 0)
+	require.NoError(t, err)
+	require.Equal(t, value, encoded)
+
+	// Value smaller than the threshold.
+	encoded, err = encodeValue(value, ZstdValueCompression, len(value)+1)
+	require.NoError(t, err)
+	require.Equal(t, value, encoded)
+
+	// Compression does not save space.
+	random := make([]byte, 64)
+	_, err = rand.Read(random)
+	require.NoError(t, err)
+	encoded, err = encodeValue(random, SnappyValueCompression, 0)
+	require.NoError(t, err)
+	require.Equal(t, random, encoded)
+}
+
+func TestDecodeValueUncompressed(t *testing.T) {
+	value, err := proto.Marshal(&kvtest.Foo{Msg: "foo"})
+	require.NoError(t, err)
+
+	decoded, err := decodeValue(value)
+	require.NoError(t, err)
+	require.Equal(t, value, decoded)
+
+	decoded, err = decodeValue(nil)
+	require.NoError(t, err)
+	require.Empty(t, decoded)
+}
+
+func TestDecodeValueCorrupt(t *testing.T) {
+	_, err := decodeValue([]byte{valueHeaderSnappy, 0xff, 0xff, 0xff})
+	require.Error(t, err)
+
+	_, err = decodeValue([]byte{valueHeaderZstd, 0xff, 0xff, 0xff})
+	require.Error(t, err)
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/uber-go/tally"
+)
+
+const (
+	opGet                    = "get"
+	opGetMany                = "get-many"
+	opGetPrefix              = "get-prefix"
+	opHistory                = "history"
+	opListKeys               = "list-keys"
+	opSet                    = "set"
+	opCheckAndSet            = "check-and-set"
+	opDelete                 = "delete"
+	opDeleteIfVersionMatches = "delete-if-version-matches"
+	opCommit                 = "commit"
+	opLeaseGrant             = "lease-grant"
+	opLeaseRevoke            = "lease-revoke"
+	opCollectChunks          = "collect-chunks"
+
+	operationTag = "operation"
+	keyPrefixTag = "key-prefix"
+
+	spanNamePrefix = "kv.etcd."
+	spanKeyTag     = "key"
+)
+
+var watchLagBuckets = tally.ValueBuckets{0, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+type opKey struct {
+	op        string
+	keyPrefix string
+}
+
+type opMetrics struct {
+	latency tally.Histogram
+	errors  tally.Counter
+}
+
+// keyMetrics instruments the etcd operations of the store per operation and
+// key prefix, the key prefix is made of the first path components of the key
+// to bound the number of time series.
+type keyMetrics struct {
+	sync.RWMutex
+
+	scope     tally.Scope
+	tracer    opentracing.Tracer
+	depth     int
+	ops       map[opKey]opMetrics
+	watchLags map[string]tally.Histogram
+}
+
+func newKeyMetrics(scope tally.Scope, tracer opentracing.Tracer, depth int) *keyMetrics {
+	if tracer == nil {
+		tracer = opentracing.NoopTracer{}
+	}
+	return &keyMetrics{
+		scope:     scope,
+		tracer:    tracer,
+		depth:     depth,
+		ops:       make(map[opKey]opMetrics),
+		watchLags: make(map[string]tally.Histogram),
+	}
+}
+
+// start starts a span for the operation on the key, which must not include
+// the store prefix, and returns a function to finish the span and record the
+// latency and error of the operation.
+func (m *keyMetrics) start(op, key string) func(err error) {
+	var (
+		start = time.Now()
+		sp    = m.tracer.StartSpan(spanNamePrefix + op)
+	)
+	sp.SetTag(spanKeyTag, key)
+	return func(err error) {
+		om := m.opMetrics(op, m.keyPrefix(key))
+		om.latency.RecordDuration(time.Since(start))
+		if err != nil {
+			om.errors.Inc(1)
+			ext.Error.Set(sp, true)
+			sp.LogFields(log.Error(err))
+		}
+		sp.Finish()
+	}
+}
+
+// watchLag records the number of revisions the events received by the watch
+// on the key lag behind the revision of the store.
+func (m *keyMetrics) watchLag(key string, lag int64) {
+	keyPrefix := m.keyPrefix(key)
+
+	m.RLock()
+	h, ok := m.watchLags[keyPrefix]
+	m.RUnlock()
+	if !ok {
+		m.Lock()
+		if h, ok = m.watchLags[keyPrefix]; !ok {
+			h = m.scope.Tagged(map[string]string{keyPrefixTag: keyPrefix}).
+				Histogram("etcd-watch-lag", watchLagBuckets)
+			m.watchLags[keyPrefix] = h
+		}
+		m.Unlock()
+	}
+
+	h.RecordValue(float64(lag))
+}
+
+func (m *keyMetrics) opMetrics(op, keyPrefix string) opMetrics {
+	k := opKey{op: op, keyPrefix: keyPrefix}
+
+	m.RLock()
+	om, ok := m.ops[k]
+	m.RUnlock()
+	if ok {
+		return om
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if om, ok = m.ops[k]; ok {
+		return om
+	}
+
+	scope := m.scope.Tagged(map[string]string{
+		operationTag: op,
+		keyPrefixTag: keyPrefix,
+	})
+	om = opMetrics{
+		latency: scope.Histogram("etcd-op-latency", instrument.SparseHistogramTimerHistogramBuckets()),
+		errors:  scope.Counter("etcd-op-errors"),
+	}
+	m.ops[k] = om
+	return om
+}
+
+// keyPrefix returns the first depth path components of the key.
+func (m *keyMetrics) keyPrefix(key string) string {
+	key = strings.TrimPrefix(key, "/")
+	parts := strings.SplitN(key, "/", m.depth+1)
+	if len(parts) > m.depth {
+		parts = parts[:m.depth]
+	}
+	return strings.Join(parts, "/")
+}
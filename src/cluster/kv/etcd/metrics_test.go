@@ -0,0 +1,95 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package etcd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestKeyMetricsKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key      string
+		depth    int
+		expected string
+	}{
+		{key: "foo", depth: 1, expected: "foo"},
+		{key: "foo/bar/baz", depth: 1, expected: "foo"},
+		{key: "/foo/bar/baz", depth: 1, expected: "foo"},
+		{key: "foo/bar/baz", depth: 2, expected: "foo/bar"},
+		{key: "foo/bar", depth: 3, expected: "foo/bar"},
+		{key: "", depth: 1, expected: ""},
+	}
+
+	for _, test := range tests {
+		m := newKeyMetrics(tally.NoopScope, nil, test.depth)
+		require.Equal(t, test.expected, m.keyPrefix(test.key), test.key)
+	}
+}
+
+func TestKeyMetricsStart(t *testing.T) {
+	var (
+		scope  = tally.NewTestScope("", nil)
+		tracer = mocktracer.New()
+		m      = newKeyMetrics(scope, tracer, 1)
+	)
+
+	m.start(opGet, "foo/bar")(nil)
+	m.start(opGet, "foo/baz")(nil)
+	m.start(opSet, "foo/bar")(errors.New("boom"))
+
+	snapshot := scope.Snapshot()
+	counters := snapshot.Counters()
+	require.Equal(t, int64(1), counters["etcd-op-errors+key-prefix=foo,operation=set"].Value())
+
+	histograms := snapshot.Histograms()
+	var numGets int64
+	for _, v := range histograms["etcd-op-latency+key-prefix=foo,operation=get"].Durations() {
+		numGets += v
+	}
+	require.Equal(t, int64(2), numGets)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 3)
+	require.Equal(t, "kv.etcd.get", spans[0].OperationName)
+	require.Equal(t, "foo/bar", spans[0].Tag(spanKeyTag))
+	require.Nil(t, spans[0].Tag("error"))
+	require.Equal(t, "kv.etcd.set", spans[2].OperationName)
+	require.Equal(t, true, spans[2].Tag("error"))
+}
+
+func TestKeyMetricsWatchLag(t *testing.T) {
+	var (
+		scope = tally.NewTestScope("", nil)
+		m     = newKeyMetrics(scope, nil, 1)
+	)
+
+	m.watchLag("foo/bar", 0)
+	m.watchLag("foo/baz", 3)
+
+	values := scope.Snapshot().Histograms()["etcd-watch-lag+key-prefix=foo"].Values()
+	require.Equal(t, int64(1), values[0])
+	require.Equal(t, int64(1), values[5])
+}
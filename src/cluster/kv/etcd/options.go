@@ -27,19 +27,31 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/retry"
 )
 
 var (
-	defaultRequestTimeout         = 10 * time.Second
-	defaultWatchChanCheckInterval = 10 * time.Second
-	defaultWatchChanResetInterval = 10 * time.Second
-	defaultWatchChanInitTimeout   = 10 * time.Second
-	defaultRetryOptions           = retry.NewOptions().SetMaxRetries(5)
-	defaultCacheFileFn            = func(string) string { return "" }
-	defaultNewDirectoryMode       = os.FileMode(0755)
+	defaultRequestTimeout              = 10 * time.Second
+	defaultWatchChanCheckInterval      = 10 * time.Second
+	defaultWatchChanResetInterval      = 10 * time.Second
+	defaultWatchChanInitTimeout        = 10 * time.Second
+	defaultCacheFileFn                 = func(string) string { return "" }
+	defaultNewDirectoryMode            = os.FileMode(0755)
+	defaultMetricsKeyPrefixDepth       = 1
+	defaultCircuitBreakerProbeInterval = time.Second
+	defaultRetryMaxBackoff             = 30 * time.Second
+	defaultValueCompressionThreshold   = 4096
+	defaultValueChunkGCInterval        = 10 * time.Minute
 )
 
+// NB: retries back off exponentially with jitter so that clients retrying at
+// the same time, e.g. after an etcd restart, are spread out.
+var defaultRetryOptions = retry.NewOptions().
+	SetMaxRetries(5).
+	SetMaxBackoff(defaultRetryMaxBackoff).
+	SetJitter(true)
+
 // CacheFileFn is a function to generate cache file path
 type CacheFileFn func(namespace string) string
 
@@ -100,9 +112,73 @@ type Options interface {
 	// SetCacheFileDir sets the CacheFileDir
 	SetCacheFileFn(fn CacheFileFn) Options
 
+	// LegacyCacheFileFn is the path of the JSON cache file written by
+	// previous versions, which is converted to the cache file once if
+	// there is no cache file yet.
+	LegacyCacheFileFn() CacheFileFn
+	// SetLegacyCacheFileFn sets the LegacyCacheFileFn
+	SetLegacyCacheFileFn(fn CacheFileFn) Options
+
 	SetNewDirectoryMode(fm os.FileMode) Options
 	NewDirectoryMode() os.FileMode
 
+	// MetricsKeyPrefixDepth is the number of path components of a key the
+	// operation metrics are tagged with, it bounds the number of time series
+	MetricsKeyPrefixDepth() int
+	// SetMetricsKeyPrefixDepth sets the MetricsKeyPrefixDepth
+	SetMetricsKeyPrefixDepth(depth int) Options
+
+	// RateLimiter is the limiter of the requests to etcd, nil if unlimited
+	RateLimiter() ratelimit.Limiter
+	// SetRateLimiter sets the RateLimiter
+	SetRateLimiter(l ratelimit.Limiter) Options
+
+	// CircuitBreakerWindow is how long requests to etcd must have been failing
+	// for before they fail fast, zero disables the circuit breaker
+	CircuitBreakerWindow() time.Duration
+	// SetCircuitBreakerWindow sets the CircuitBreakerWindow
+	SetCircuitBreakerWindow(t time.Duration) Options
+
+	// CircuitBreakerProbeInterval is the interval at which a request is let
+	// through to probe etcd while requests fail fast
+	CircuitBreakerProbeInterval() time.Duration
+	// SetCircuitBreakerProbeInterval sets the CircuitBreakerProbeInterval
+	SetCircuitBreakerProbeInterval(t time.Duration) Options
+
+	// ValueCompression is the compression of the values written to etcd,
+	// compressed values are read regardless of it. Only enable compression
+	// once every reader of the keys supports it
+	ValueCompression() ValueCompression
+	// SetValueCompression sets the ValueCompression
+	SetValueCompression(c ValueCompression) Options
+
+	// ValueCompressionThreshold is the size in bytes from which values are
+	// compressed, smaller values are written uncompressed
+	ValueCompressionThreshold() int
+	// SetValueCompressionThreshold sets the ValueCompressionThreshold
+	SetValueCompressionThreshold(threshold int) Options
+
+	// ValueChunkSize is the size in bytes from which values, once compressed,
+	// are split into chunks stored under sub keys, zero disables chunking.
+	// Only enable chunking once every reader of the keys supports it
+	ValueChunkSize() int
+	// SetValueChunkSize sets the ValueChunkSize
+	SetValueChunkSize(size int) Options
+
+	// ValueChunkGCInterval is the interval at which the chunks of chunked
+	// writes which are not referenced by their key, left behind by writes
+	// that failed, are deleted. Chunks are deleted once they are older than
+	// an interval so it must exceed the request timeout, zero disables it
+	ValueChunkGCInterval() time.Duration
+	// SetValueChunkGCInterval sets the ValueChunkGCInterval
+	SetValueChunkGCInterval(interval time.Duration) Options
+
+	// ReadOnly rejects every mutation of the store with a *kv.ReadOnlyError,
+	// reads and watches are unaffected
+	ReadOnly() bool
+	// SetReadOnly sets the ReadOnly
+	SetReadOnly(readOnly bool) Options
+
 	// Validate validates the Options
 	Validate() error
 }
@@ -118,7 +194,17 @@ type options struct {
 	watchWithRevision      int64
 	enableFastGets         bool
 	cacheFileFn            CacheFileFn
+	legacyCacheFileFn      CacheFileFn
 	newDirectoryMode       os.FileMode
+	metricsKeyPrefixDepth  int
+	rateLimiter            ratelimit.Limiter
+	cbWindow               time.Duration
+	cbProbeInterval        time.Duration
+	valueCompression       ValueCompression
+	valueCompressionThresh int
+	valueChunkSize         int
+	valueChunkGCInterval   time.Duration
+	readOnly               bool
 }
 
 // NewOptions creates a sane default Option
@@ -131,7 +217,13 @@ func NewOptions() Options {
 		SetWatchChanResetInterval(defaultWatchChanResetInterval).
 		SetWatchChanInitTimeout(defaultWatchChanInitTimeout).
 		SetCacheFileFn(defaultCacheFileFn).
-		SetNewDirectoryMode(defaultNewDirectoryMode)
+		SetLegacyCacheFileFn(defaultCacheFileFn).
+		SetNewDirectoryMode(defaultNewDirectoryMode).
+		SetMetricsKeyPrefixDepth(defaultMetricsKeyPrefixDepth).
+		SetCircuitBreakerProbeInterval(defaultCircuitBreakerProbeInterval).
+		SetValueCompression(NoValueCompression).
+		SetValueCompressionThreshold(defaultValueCompressionThreshold).
+		SetValueChunkGCInterval(defaultValueChunkGCInterval)
 }
 
 func (o options) Validate() error {
@@ -159,6 +251,34 @@ func (o options) Validate() error {
 		return errors.New("invalid request timeout")
 	}
 
+	if o.metricsKeyPrefixDepth <= 0 {
+		return errors.New("invalid metrics key prefix depth")
+	}
+
+	if o.cbWindow < 0 {
+		return errors.New("invalid circuit breaker window")
+	}
+
+	if o.cbProbeInterval <= 0 {
+		return errors.New("invalid circuit breaker probe interval")
+	}
+
+	if err := o.valueCompression.Validate(); err != nil {
+		return err
+	}
+
+	if o.valueCompressionThresh < 0 {
+		return errors.New("invalid value compression threshold")
+	}
+
+	if o.valueChunkSize < 0 {
+		return errors.New("invalid value chunk size")
+	}
+
+	if o.valueChunkGCInterval < 0 {
+		return errors.New("invalid value chunk gc interval")
+	}
+
 	return nil
 }
 
@@ -245,6 +365,15 @@ func (o options) SetCacheFileFn(fn CacheFileFn) Options {
 	return o
 }
 
+func (o options) LegacyCacheFileFn() CacheFileFn {
+	return o.legacyCacheFileFn
+}
+
+func (o options) SetLegacyCacheFileFn(fn CacheFileFn) Options {
+	o.legacyCacheFileFn = fn
+	return o
+}
+
 func (o options) Prefix() string {
 	return o.prefix
 }
@@ -269,3 +398,84 @@ func (o options) SetNewDirectoryMode(fm os.FileMode) Options {
 func (o options) NewDirectoryMode() os.FileMode {
 	return o.newDirectoryMode
 }
+
+func (o options) MetricsKeyPrefixDepth() int {
+	return o.metricsKeyPrefixDepth
+}
+
+func (o options) SetMetricsKeyPrefixDepth(depth int) Options {
+	o.metricsKeyPrefixDepth = depth
+	return o
+}
+
+func (o options) RateLimiter() ratelimit.Limiter {
+	return o.rateLimiter
+}
+
+func (o options) SetRateLimiter(l ratelimit.Limiter) Options {
+	o.rateLimiter = l
+	return o
+}
+
+func (o options) CircuitBreakerWindow() time.Duration {
+	return o.cbWindow
+}
+
+func (o options) SetCircuitBreakerWindow(t time.Duration) Options {
+	o.cbWindow = t
+	return o
+}
+
+func (o options) CircuitBreakerProbeInterval() time.Duration {
+	return o.cbProbeInterval
+}
+
+func (o options) SetCircuitBreakerProbeInterval(t time.Duration) Options {
+	o.cbProbeInterval = t
+	return o
+}
+
+func (o options) ValueCompression() ValueCompression {
+	return o.valueCompression
+}
+
+func (o options) SetValueCompression(c ValueCompression) Options {
+	o.valueCompression = c
+	return o
+}
+
+func (o options) ValueCompressionThreshold() int {
+	return o.valueCompressionThresh
+}
+
+func (o options) SetValueCompressionThreshold(threshold int) Options {
+	o.valueCompressionThresh = threshold
+	return o
+}
+
+func (o options) ValueChunkSize() int {
+	return o.valueChunkSize
+}
+
+func (o options) SetValueChunkSize(size int) Options {
+	o.valueChunkSize = size
+	return o
+}
+
+func (o options) ValueChunkGCInterval() time.Duration {
+	return o.valueChunkGCInterval
+}
+
+func (o options) SetValueChunkGCInterval(interval time.Duration) Options {
+	o.valueChunkGCInterval = interval
+	return o
+}
+
+func (o options) ReadOnly() bool {
+	return o.readOnly
+}
+
+func (o options) SetReadOnly(readOnly bool) Options {
+	o.readOnly = readOnly
+	return o
+}
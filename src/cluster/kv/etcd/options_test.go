@@ -21,7 +21,6 @@
 package etcd
 
 import (
-	"math"
 	"testing"
 	"time"
 
@@ -36,10 +35,25 @@ func TestOptions(t *testing.T) {
 	assert.Equal(t, defaultWatchChanResetInterval, opts.WatchChanCheckInterval())
 	assert.Equal(t, defaultWatchChanInitTimeout, opts.WatchChanInitTimeout())
 	assert.False(t, opts.EnableFastGets())
+	assert.Equal(t, defaultMetricsKeyPrefixDepth, opts.MetricsKeyPrefixDepth())
+	assert.Error(t, opts.SetMetricsKeyPrefixDepth(0).Validate())
 	ropts := opts.RetryOptions()
 	assert.Equal(t, true, ropts.Jitter())
 	assert.Equal(t, time.Second, ropts.InitialBackoff())
 	assert.EqualValues(t, 2, ropts.BackoffFactor())
 	assert.EqualValues(t, 5, ropts.MaxRetries())
-	assert.Equal(t, time.Duration(math.MaxInt64), ropts.MaxBackoff())
+	assert.Equal(t, defaultRetryMaxBackoff, ropts.MaxBackoff())
+	assert.Nil(t, opts.RateLimiter())
+	assert.Equal(t, time.Duration(0), opts.CircuitBreakerWindow())
+	assert.Equal(t, defaultCircuitBreakerProbeInterval, opts.CircuitBreakerProbeInterval())
+	assert.Error(t, opts.SetCircuitBreakerWindow(-time.Second).Validate())
+	assert.Error(t, opts.SetCircuitBreakerProbeInterval(0).Validate())
+	assert.Equal(t, NoValueCompression, opts.ValueCompression())
+	assert.Equal(t, defaultValueCompressionThreshold, opts.ValueCompressionThreshold())
+	assert.Error(t, opts.SetValueCompression("lz4").Validate())
+	assert.Error(t, opts.SetValueCompressionThreshold(-1).Validate())
+	assert.Equal(t, 0, opts.ValueChunkSize())
+	assert.Error(t, opts.SetValueChunkSize(-1).Validate())
+	assert.Equal(t, defaultValueChunkGCInterval, opts.ValueChunkGCInterval())
+	assert.Error(t, opts.SetValueChunkGCInterval(-time.Second).Validate())
 }
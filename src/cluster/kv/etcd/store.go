@@ -24,23 +24,37 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/m3db/m3/src/cluster/etcd/watchmanager"
+	"github.com/m3db/m3/src/cluster/generated/proto/kvpb"
 	"github.com/m3db/m3/src/cluster/kv"
 	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/fault"
+	"github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/retry"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/uber-go/tally"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 	"golang.org/x/net/context"
 )
 
-const etcdVersionZero = 0
+const (
+	etcdVersionZero = 0
+
+	// maxTxnOps is the default limit of etcd on the number of ops in a
+	// transaction.
+	maxTxnOps = 128
+)
 
 var (
 	noopCancel               func()
@@ -55,21 +69,27 @@ func NewStore(etcdKV *clientv3.Client, opts Options) (kv.TxnStore, error) {
 	scope := opts.InstrumentsOptions().MetricsScope()
 
 	store := &client{
-		opts:           opts,
-		kv:             etcdKV,
-		watchables:     map[string]kv.ValueWatchable{},
-		retrier:        retry.NewRetrier(opts.RetryOptions()),
-		logger:         opts.InstrumentsOptions().Logger(),
-		cacheFile:      opts.CacheFileFn()(opts.Prefix()),
-		cache:          newCache(),
-		cacheUpdatedCh: make(chan struct{}, 1),
+		opts:             opts,
+		kv:               etcdKV,
+		watchables:       map[string]kv.ValueWatchable{},
+		prefixWatchables: map[string]kv.PrefixWatchable{},
+		retrier:          retry.NewRetrier(opts.RetryOptions()),
+		logger:           opts.InstrumentsOptions().Logger(),
+		cacheFile:        opts.CacheFileFn()(opts.Prefix()),
+		legacyCacheFile:  opts.LegacyCacheFileFn()(opts.Prefix()),
+		cache:            newCache(),
+		cacheUpdatedCh:   make(chan struct{}, 1),
 		m: clientMetrics{
 			etcdGetError:   scope.Counter("etcd-get-error"),
 			etcdPutError:   scope.Counter("etcd-put-error"),
+			etcdLeaseError: scope.Counter("etcd-lease-error"),
 			etcdTnxError:   scope.Counter("etcd-tnx-error"),
 			diskWriteError: scope.Counter("disk-write-error"),
 			diskReadError:  scope.Counter("disk-read-error"),
 		},
+		km:      newKeyMetrics(scope, opts.InstrumentsOptions().Tracer(), opts.MetricsKeyPrefixDepth()),
+		limiter: opts.RateLimiter(),
+		breaker: newCircuitBreaker(opts.CircuitBreakerWindow(), opts.CircuitBreakerProbeInterval(), scope),
 	}
 
 	clientWatchOpts := []clientv3.OpOption{
@@ -88,10 +108,12 @@ func NewStore(etcdKV *clientv3.Client, opts Options) (kv.TxnStore, error) {
 		SetClient(etcdKV).
 		SetUpdateFn(store.update).
 		SetTickAndStopFn(store.tickAndStop).
+		SetWatchLagFn(store.watchLag).
 		SetWatchOptions(clientWatchOpts).
 		SetWatchChanCheckInterval(opts.WatchChanCheckInterval()).
 		SetWatchChanInitTimeout(opts.WatchChanInitTimeout()).
 		SetWatchChanResetInterval(opts.WatchChanResetInterval()).
+		SetRateLimiter(opts.RateLimiter()).
 		SetInstrumentsOptions(opts.InstrumentsOptions())
 
 	wm, err := watchmanager.NewWatchManager(wOpts)
@@ -101,6 +123,20 @@ func NewStore(etcdKV *clientv3.Client, opts Options) (kv.TxnStore, error) {
 
 	store.wm = wm
 
+	prefixClientWatchOpts := make([]clientv3.OpOption, 0, len(clientWatchOpts)+1)
+	prefixClientWatchOpts = append(prefixClientWatchOpts, clientWatchOpts...)
+	prefixClientWatchOpts = append(prefixClientWatchOpts, clientv3.WithPrefix())
+
+	prefixWM, err := watchmanager.NewWatchManager(wOpts.
+		SetUpdateFn(store.updatePrefix).
+		SetTickAndStopFn(store.tickAndStopPrefix).
+		SetWatchOptions(prefixClientWatchOpts))
+	if err != nil {
+		return nil, err
+	}
+
+	store.prefixWM = prefixWM
+
 	if store.cacheFile != "" {
 		if err := store.initCache(opts.NewDirectoryMode()); err != nil {
 			store.logger.Warn("could not load cache from file", zap.String("file", store.cacheFile), zap.Error(err))
@@ -116,35 +152,50 @@ func NewStore(etcdKV *clientv3.Client, opts Options) (kv.TxnStore, error) {
 			}
 		}()
 	}
+
+	if opts.ValueChunkSize() > 0 && opts.ValueChunkGCInterval() > 0 && !opts.ReadOnly() {
+		go store.collectChunksEvery(opts.ValueChunkGCInterval())
+	}
 	return store, nil
 }
 
 type client struct {
 	sync.RWMutex
 
-	opts           Options
-	kv             *clientv3.Client
-	watchables     map[string]kv.ValueWatchable
-	retrier        retry.Retrier
-	logger         *zap.Logger
-	m              clientMetrics
-	cache          *valueCache
-	cacheFile      string
-	cacheUpdatedCh chan struct{}
-
-	wm watchmanager.WatchManager
+	opts            Options
+	kv              *clientv3.Client
+	watchables      map[string]kv.ValueWatchable
+	retrier         retry.Retrier
+	logger          *zap.Logger
+	m               clientMetrics
+	km              *keyMetrics
+	limiter         ratelimit.Limiter
+	breaker         *circuitBreaker
+	cache           *valueCache
+	cacheFile       string
+	legacyCacheFile string
+	cacheUpdatedCh  chan struct{}
+
+	// prefixWatchables are keyed by prefix and watched by prefixWM, values
+	// delivered to prefix watches are not persisted in the cache.
+	prefixWatchables map[string]kv.PrefixWatchable
+
+	wm       watchmanager.WatchManager
+	prefixWM watchmanager.WatchManager
 }
 
 type clientMetrics struct {
 	etcdGetError   tally.Counter
 	etcdPutError   tally.Counter
+	etcdLeaseError tally.Counter
 	etcdTnxError   tally.Counter
 	diskWriteError tally.Counter
 	diskReadError  tally.Counter
 }
 
 // Get returns the latest value from etcd store and only fall back to
-// in-memory cache if the remote store is unavailable
+// in-memory cache if the remote store is unavailable, values served from
+// the cache are marked as stale
 func (c *client) Get(key string) (kv.Value, error) {
 	return c.get(c.opts.ApplyPrefix(key))
 }
@@ -157,7 +208,14 @@ func (c *client) get(key string) (kv.Value, error) {
 	if c.opts.EnableFastGets() {
 		opts = append(opts, clientv3.WithSerializable())
 	}
-	r, err := c.kv.Get(ctx, key, opts...)
+	var r *clientv3.GetResponse
+	finish, err := c.begin(ctx, opGet, c.stripPrefix(key))
+	if err == nil {
+		if err = fault.Inject(fault.KVGet); err == nil {
+			r, err = c.kv.Get(ctx, key, opts...)
+		}
+		finish(err)
+	}
 	if err != nil {
 		c.m.etcdGetError.Inc(1)
 		cachedV, ok := c.getCache(key)
@@ -172,13 +230,81 @@ func (c *client) get(key string) (kv.Value, error) {
 		return nil, kv.ErrNotFound
 	}
 
-	v := newValue(r.Kvs[0].Value, r.Kvs[0].Version, r.Kvs[0].ModRevision)
+	v, err := c.readValue(ctx, r.Kvs[0])
+	if err != nil {
+		return nil, err
+	}
 
 	c.mergeCache(key, v)
 
 	return v, nil
 }
 
+// GetMany retrieves the values of the keys with a single etcd transaction per
+// maxTxnOps keys. If etcd is unavailable the values are served from the cache
+// only if all the keys of the transaction are cached.
+func (c *client) GetMany(keys []string) (map[string]kv.Value, error) {
+	values := make(map[string]kv.Value, len(keys))
+	for start := 0; start < len(keys); start += maxTxnOps {
+		end := start + maxTxnOps
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := c.getMany(keys[start:end], values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (c *client) getMany(keys []string, values map[string]kv.Value) error {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	ops := make([]clientv3.Op, len(keys))
+	for i, key := range keys {
+		ops[i] = clientv3.OpGet(c.opts.ApplyPrefix(key))
+	}
+
+	var r *clientv3.TxnResponse
+	// NB: the transaction is attributed to the prefix of its first key.
+	finish, err := c.begin(ctx, opGetMany, keys[0])
+	if err == nil {
+		if err = fault.Inject(fault.KVGet); err == nil {
+			r, err = c.kv.Txn(ctx).Then(ops...).Commit()
+		}
+		finish(err)
+	}
+	if err != nil {
+		c.m.etcdGetError.Inc(1)
+		for _, key := range keys {
+			cachedV, ok := c.getCache(c.opts.ApplyPrefix(key))
+			if !ok {
+				return err
+			}
+			values[key] = cachedV
+		}
+		return nil
+	}
+
+	for i, key := range keys {
+		newKey := c.opts.ApplyPrefix(key)
+		rangeResp := r.Responses[i].GetResponseRange()
+		if rangeResp == nil || len(rangeResp.Kvs) == 0 {
+			c.deleteCache(newKey) // delete cache entry if it exists
+			continue
+		}
+
+		v, err := c.readValue(ctx, rangeResp.Kvs[0])
+		if err != nil {
+			return err
+		}
+		c.mergeCache(newKey, v)
+		values[key] = v
+	}
+	return nil
+}
+
 func (c *client) History(key string, from, to int) ([]kv.Value, error) {
 	if from > to || from < 0 || to < 0 {
 		return nil, errInvalidHistoryVersion
@@ -188,12 +314,10 @@ func (c *client) History(key string, from, to int) ([]kv.Value, error) {
 		return nil, nil
 	}
 
-	newKey := c.opts.ApplyPrefix(key)
-
 	ctx, cancel := c.context()
 	defer cancel()
 
-	r, err := c.kv.Get(ctx, newKey)
+	r, err := c.getHistory(ctx, key)
 	if err != nil {
 		return nil, err
 	}
@@ -222,14 +346,18 @@ func (c *client) History(key string, from, to int) ([]kv.Value, error) {
 
 	if version < to {
 		// put it in the last element of the result
-		res[version-from] = newValue(latestKV.Value, latestKV.Version, modRev)
+		v, err := c.readValue(ctx, latestKV)
+		if err != nil {
+			return nil, err
+		}
+		res[version-from] = v
 	}
 
 	for version > from {
 		ctx, cancel := c.context()
 		defer cancel()
 
-		r, err = c.kv.Get(ctx, newKey, clientv3.WithRev(modRev-1))
+		r, err = c.getHistory(ctx, key, clientv3.WithRev(modRev-1))
 		if err != nil {
 			return nil, err
 		}
@@ -243,13 +371,92 @@ func (c *client) History(key string, from, to int) ([]kv.Value, error) {
 		modRev = v.ModRevision
 		version = int(v.Version)
 		if version < to {
-			res[version-from] = newValue(v.Value, v.Version, v.ModRevision)
+			res[version-from], err = c.readValue(ctx, v)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return res, nil
 }
 
+// getHistory gets a revision of the key for History, each request is admitted
+// and instrumented individually.
+func (c *client) getHistory(
+	ctx context.Context,
+	key string,
+	opts ...clientv3.OpOption,
+) (*clientv3.GetResponse, error) {
+	var r *clientv3.GetResponse
+	finish, err := c.begin(ctx, opHistory, key)
+	if err == nil {
+		if err = fault.Inject(fault.KVGet); err == nil {
+			r, err = c.kv.Get(ctx, c.opts.ApplyPrefix(key), opts...)
+		}
+		finish(err)
+	}
+	if err != nil {
+		c.m.etcdGetError.Inc(1)
+		return nil, err
+	}
+	return r, nil
+}
+
+// ListKeys lists a page of keys with a single range request which reads the
+// keys only. The chunks of chunked values are kept under a reserved sub path
+// of the store and are not listed, so a page may have fewer keys than the
+// page size when the prefix includes the sub path.
+func (c *client) ListKeys(prefix string, pageSize int, pageToken string) (kv.KeyPage, error) {
+	if err := kv.ValidateListKeys(prefix, pageSize, pageToken); err != nil {
+		return kv.KeyPage{}, err
+	}
+
+	newPrefix := c.opts.ApplyPrefix(prefix)
+	start := newPrefix
+	if pageToken != "" {
+		// NB: the smallest key following the last key of the previous page.
+		start = c.opts.ApplyPrefix(pageToken) + "\x00"
+	}
+	end := clientv3.GetPrefixRangeEnd(newPrefix)
+	if start == "" {
+		// NB: etcd ranges from the smallest key with a zero byte key.
+		start = "\x00"
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	var r *clientv3.GetResponse
+	finish, err := c.begin(ctx, opListKeys, prefix)
+	if err == nil {
+		if err = fault.Inject(fault.KVGet); err == nil {
+			r, err = c.kv.Get(ctx, start,
+				clientv3.WithRange(end),
+				clientv3.WithKeysOnly(),
+				clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend),
+				clientv3.WithLimit(int64(pageSize)))
+		}
+		finish(err)
+	}
+	if err != nil {
+		c.m.etcdGetError.Inc(1)
+		return kv.KeyPage{}, err
+	}
+
+	page := kv.KeyPage{Keys: make([]string, 0, len(r.Kvs))}
+	for _, pair := range r.Kvs {
+		if c.isChunkKey(string(pair.Key)) {
+			continue
+		}
+		page.Keys = append(page.Keys, c.stripPrefix(string(pair.Key)))
+	}
+	if r.More && len(r.Kvs) > 0 {
+		page.NextPageToken = c.stripPrefix(string(r.Kvs[len(r.Kvs)-1].Key))
+	}
+	return page, nil
+}
+
 func (c *client) processCondition(condition kv.Condition) (clientv3.Cmp, error) {
 	var cmp clientv3.Cmp
 	switch condition.TargetType() {
@@ -270,27 +477,44 @@ func (c *client) processCondition(condition kv.Condition) (clientv3.Cmp, error)
 	return clientv3.Compare(cmp, compareStr, condition.Value()), nil
 }
 
-func (c *client) processOp(op kv.Op) (clientv3.Op, error) {
+// processOp returns the etcd op of the op and the value it writes, if any.
+func (c *client) processOp(ctx context.Context, op kv.Op) (clientv3.Op, []byte, error) {
 	switch op.Type() {
 	case kv.OpSet:
 		opSet := op.(kv.SetOp)
 
-		value, err := proto.Marshal(opSet.Value)
+		value, err := c.marshal(opSet.Value)
+		if err != nil {
+			return emptyOp, nil, err
+		}
+
+		if isReservedKey(opSet.Key()) {
+			return emptyOp, nil, errReservedKey
+		}
+		key := c.opts.ApplyPrefix(opSet.Key())
+		value, err = c.writeChunks(ctx, key, value)
 		if err != nil {
-			return emptyOp, err
+			return emptyOp, nil, err
 		}
 
 		return clientv3.OpPut(
-			c.opts.ApplyPrefix(opSet.Key()),
+			key,
 			string(value),
 			clientv3.WithPrevKV(),
-		), nil
+		), value, nil
 	default:
-		return emptyOp, kv.ErrUnknownOpType
+		return emptyOp, nil, kv.ErrUnknownOpType
 	}
 }
 
 func (c *client) Commit(conditions []kv.Condition, ops []kv.Op) (kv.Response, error) {
+	if c.opts.ReadOnly() {
+		return nil, kv.NewReadOnlyError("commit", "")
+	}
+	if err := fault.Inject(fault.KVWrite); err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := c.context()
 	defer cancel()
 
@@ -308,27 +532,63 @@ func (c *client) Commit(conditions []kv.Condition, ops []kv.Op) (kv.Response, er
 
 	txn = txn.If(cmps...)
 
+	// NB: the transaction is attributed to the key of its first op.
+	var opKey string
+	if len(ops) > 0 {
+		opKey = ops[0].Key()
+	}
+	finish, err := c.begin(ctx, opCommit, opKey)
+	if err != nil {
+		return nil, err
+	}
+
 	etcdOps := make([]clientv3.Op, len(ops))
 	opResponses := make([]kv.OpResponse, len(ops))
+	// NB: the chunks of values written by the transaction are deleted if it
+	// definitely does not commit.
+	written := make(map[string][]byte, len(ops))
+	deleteWrittenChunks := func() {
+		for key, value := range written {
+			c.deleteValueChunks(ctx, key, value)
+		}
+	}
 	for i, op := range ops {
-		etcdOp, err := c.processOp(op)
+		etcdOp, value, err := c.processOp(ctx, op)
 		if err != nil {
+			finish(err)
+			deleteWrittenChunks()
 			return nil, err
 		}
 
 		etcdOps[i] = etcdOp
 		opResponses[i] = kv.NewOpResponse(op)
+		if value != nil {
+			written[c.opts.ApplyPrefix(op.Key())] = value
+		}
 	}
 
 	txn = txn.Then(etcdOps...)
 
+	// NB: read the condition keys if the conditions do not hold so that the
+	// failed conditions can be reported as of the time of the transaction.
+	elseOps := make([]clientv3.Op, len(conditions))
+	for i, condition := range conditions {
+		elseOps[i] = clientv3.OpGet(c.opts.ApplyPrefix(condition.Key()))
+	}
+
+	txn = txn.Else(elseOps...)
+
 	r, err := txn.Commit()
+	finish(err)
 	if err != nil {
+		// NB: the transaction may have committed, chunks it left behind are
+		// collected once it is known they are not referenced.
 		c.m.etcdTnxError.Inc(1)
 		return nil, err
 	}
 	if !r.Succeeded {
-		return nil, kv.ErrConditionCheckFailed
+		deleteWrittenChunks()
+		return nil, conditionCheckFailedError(conditions, r.Responses)
 	}
 
 	for i := range r.Responses {
@@ -341,6 +601,7 @@ func (c *client) Commit(conditions []kv.Condition, ops []kv.Op) (kv.Response, er
 			}
 
 			if res.PrevKv != nil {
+				c.deleteReplacedChunks(ctx, res.PrevKv)
 				opr = opr.SetValue(int(res.PrevKv.Version + 1))
 			} else {
 				opr = opr.SetValue(etcdVersionZero + 1)
@@ -369,6 +630,29 @@ func (c *client) Watch(key string) (kv.ValueWatch, error) {
 	return w, err
 }
 
+func (c *client) WatchFromVersion(key string, version int) (kv.ValueWatch, error) {
+	w, err := c.Watch(key)
+	if err != nil {
+		return nil, err
+	}
+	return kv.NewValueWatchFromVersion(w, version), nil
+}
+
+func (c *client) WatchPrefix(prefix string) (kv.PrefixWatch, error) {
+	newPrefix := c.opts.ApplyPrefix(prefix)
+	c.Lock()
+	watchable, ok := c.prefixWatchables[newPrefix]
+	if !ok {
+		watchable = kv.NewPrefixWatchable()
+		c.prefixWatchables[newPrefix] = watchable
+
+		go c.prefixWM.Watch(newPrefix)
+	}
+	c.Unlock()
+	_, w, err := watchable.Watch()
+	return w, err
+}
+
 func (c *client) getFromKVStore(key string) (kv.Value, error) {
 	var (
 		nv  kv.Value
@@ -388,16 +672,22 @@ func (c *client) getFromKVStore(key string) (kv.Value, error) {
 	return nv, nil
 }
 
-func (c *client) getFromEtcdEvents(key string, events []*clientv3.Event) kv.Value {
+func (c *client) getFromEtcdEvents(key string, events []*clientv3.Event) (kv.Value, error) {
 	lastEvent := events[len(events)-1]
 	if lastEvent.Type == clientv3.EventTypeDelete {
 		c.deleteCache(key)
-		return nil
+		return nil, nil
 	}
 
-	nv := newValue(lastEvent.Kv.Value, lastEvent.Kv.Version, lastEvent.Kv.ModRevision)
+	ctx, cancel := c.context()
+	defer cancel()
+
+	nv, err := c.readValue(ctx, lastEvent.Kv)
+	if err != nil {
+		return nil, err
+	}
 	c.mergeCache(key, nv)
-	return nv
+	return nv, nil
 }
 
 func (c *client) update(key string, events []*clientv3.Event) error {
@@ -409,7 +699,16 @@ func (c *client) update(key string, events []*clientv3.Event) error {
 			return nil
 		}
 	} else {
-		nv = c.getFromEtcdEvents(key, events)
+		var err error
+		if nv, err = c.getFromEtcdEvents(key, events); err != nil {
+			// NB: the chunks of a chunked value may have been compacted away
+			// if the event is old, fall back to the latest value.
+			c.logger.Warn("could not read value from watch event, getting latest value",
+				zap.String("key", key), zap.Error(err))
+			if nv, err = c.getFromKVStore(key); err != nil {
+				return err
+			}
+		}
 	}
 
 	c.RLock()
@@ -438,6 +737,115 @@ func (c *client) update(key string, events []*clientv3.Event) error {
 	return nil
 }
 
+// getPrefixFromKVStore returns the values of all keys under the prefix keyed
+// by their key without the store prefix.
+func (c *client) getPrefixFromKVStore(prefix string) (map[string]kv.Value, error) {
+	var values map[string]kv.Value
+	err := c.retrier.Attempt(func() error {
+		ctx, cancel := c.context()
+		defer cancel()
+
+		finish, err := c.begin(ctx, opGetPrefix, c.stripPrefix(prefix))
+		if err != nil {
+			return err
+		}
+		r, err := c.kv.Get(ctx, prefix, clientv3.WithPrefix())
+		finish(err)
+		if err != nil {
+			c.m.etcdGetError.Inc(1)
+			return err
+		}
+
+		values = make(map[string]kv.Value, len(r.Kvs))
+		for _, pair := range r.Kvs {
+			if c.isChunkKey(string(pair.Key)) {
+				continue
+			}
+			v, err := c.readValue(ctx, pair)
+			if err != nil {
+				return err
+			}
+			values[c.stripPrefix(string(pair.Key))] = v
+		}
+		return nil
+	})
+	return values, err
+}
+
+func (c *client) updatePrefix(prefix string, events []*clientv3.Event) error {
+	c.RLock()
+	w, ok := c.prefixWatchables[prefix]
+	c.RUnlock()
+	if !ok {
+		return fmt.Errorf("unexpected: no watchable found for prefix: %s", prefix)
+	}
+
+	if len(events) == 0 {
+		// This is triggered by initializing a new watch, load all the values
+		// under the prefix since the watch only delivers subsequent changes.
+		values, err := c.getPrefixFromKVStore(prefix)
+		if err != nil {
+			return err
+		}
+		return w.Update(values)
+	}
+
+	// NB: the values are shared with watches, so apply the events to a copy.
+	curValues := w.Get()
+	values := make(map[string]kv.Value, len(curValues))
+	for k, v := range curValues {
+		values[k] = v
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	for _, event := range events {
+		if c.isChunkKey(string(event.Kv.Key)) {
+			continue
+		}
+		var (
+			key      = c.stripPrefix(string(event.Kv.Key))
+			curValue = values[key]
+			nv       = newValue(event.Kv.Value, event.Kv.Version, event.Kv.ModRevision)
+		)
+		// Skip events older than the current value, which may have been loaded
+		// after the event was received when initializing the watch.
+		if curValue != nil && !nv.IsNewer(curValue) {
+			continue
+		}
+		if event.Type == clientv3.EventTypeDelete {
+			delete(values, key)
+			continue
+		}
+		v, err := c.readValue(ctx, event.Kv)
+		if err != nil {
+			// NB: the chunks of a chunked value may have been compacted away
+			// if the event is old, reload all the values under the prefix.
+			c.logger.Warn("could not read value from watch event, reloading prefix",
+				zap.String("prefix", prefix), zap.Error(err))
+			if values, err = c.getPrefixFromKVStore(prefix); err != nil {
+				return err
+			}
+			return w.Update(values)
+		}
+		values[key] = v
+	}
+
+	return w.Update(values)
+}
+
+func (c *client) watchLag(key string, lag int64) {
+	c.km.watchLag(c.stripPrefix(key), lag)
+}
+
+func (c *client) stripPrefix(key string) string {
+	if c.opts.Prefix() == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, c.opts.Prefix()+"/")
+}
+
 func (c *client) tickAndStop(key string) bool {
 	// fast path
 	c.RLock()
@@ -472,30 +880,215 @@ func (c *client) tickAndStop(key string) bool {
 	return true
 }
 
+func (c *client) tickAndStopPrefix(prefix string) bool {
+	c.Lock()
+	defer c.Unlock()
+	watchable, ok := c.prefixWatchables[prefix]
+	if !ok {
+		c.logger.Warn("unexpected: prefix is already cleaned up", zap.String("prefix", prefix))
+		return true
+	}
+
+	if watchable.NumWatches() != 0 {
+		return false
+	}
+
+	watchable.Close()
+	delete(c.prefixWatchables, prefix)
+	return true
+}
+
+func conditionCheckFailedError(
+	conditions []kv.Condition,
+	responses []*etcdserverpb.ResponseOp,
+) error {
+	var failures []kv.ConditionFailure
+	for i, condition := range conditions {
+		var actualVersion int
+		if i < len(responses) {
+			if r := responses[i].GetResponseRange(); r != nil && len(r.Kvs) > 0 {
+				actualVersion = int(r.Kvs[0].Version)
+			}
+		}
+
+		if !kv.ConditionHolds(condition, actualVersion) {
+			failures = append(failures, kv.ConditionFailure{
+				Condition:     condition,
+				ActualVersion: actualVersion,
+			})
+		}
+	}
+	return kv.NewConditionCheckFailedError(failures)
+}
+
 func (c *client) Set(key string, v proto.Message) (int, error) {
+	version, _, err := c.setKey(key, v, false)
+	return version, err
+}
+
+// SetReturningPrevious returns the value replaced by the set from the
+// previous key value etcd returns with the put.
+func (c *client) SetReturningPrevious(key string, v proto.Message) (int, kv.Value, error) {
+	return c.setKey(key, v, true)
+}
+
+func (c *client) setKey(key string, v proto.Message, readPrev bool) (int, kv.Value, error) {
+	if c.opts.ReadOnly() {
+		return 0, nil, kv.NewReadOnlyError("set", key)
+	}
+	if err := fault.Inject(fault.KVWrite); err != nil {
+		return 0, nil, err
+	}
+
 	ctx, cancel := c.context()
 	defer cancel()
 
-	value, err := proto.Marshal(v)
+	return c.set(ctx, key, v, readPrev)
+}
+
+// SetWithTTL attaches the key to a new etcd lease which deletes the key when
+// it expires, the ttl is rounded up to whole seconds and etcd may extend it
+// to its minimum lease ttl. Leases of previous sets of the key are left to
+// expire on their own since the key is no longer attached to them, the lease
+// of a set that fails is revoked.
+func (c *client) SetWithTTL(key string, v proto.Message, ttl time.Duration) (int, error) {
+	version, _, err := c.setKeyWithTTL(key, v, ttl, false)
+	return version, err
+}
+
+// SetWithTTLReturningPrevious returns the value replaced by the set from
+// the previous key value etcd returns with the put.
+func (c *client) SetWithTTLReturningPrevious(
+	key string,
+	v proto.Message,
+	ttl time.Duration,
+) (int, kv.Value, error) {
+	return c.setKeyWithTTL(key, v, ttl, true)
+}
+
+func (c *client) setKeyWithTTL(
+	key string,
+	v proto.Message,
+	ttl time.Duration,
+	readPrev bool,
+) (int, kv.Value, error) {
+	if c.opts.ReadOnly() {
+		return 0, nil, kv.NewReadOnlyError("set with ttl", key)
+	}
+	if ttl <= 0 {
+		return 0, nil, kv.ErrInvalidTTL
+	}
+	if err := fault.Inject(fault.KVWrite); err != nil {
+		return 0, nil, err
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	ttlSeconds := int64(math.Ceil(ttl.Seconds()))
+	finish, err := c.begin(ctx, opLeaseGrant, key)
+	if err != nil {
+		return 0, nil, err
+	}
+	lease, err := c.kv.Grant(ctx, ttlSeconds)
+	finish(err)
+	if err != nil {
+		c.m.etcdLeaseError.Inc(1)
+		return 0, nil, err
+	}
+
+	version, prev, err := c.set(ctx, key, v, readPrev, clientv3.WithLease(lease.ID))
+	if err != nil {
+		c.revokeLease(key, lease.ID)
+		return 0, nil, err
+	}
+	return version, prev, nil
+}
+
+// revokeLease revokes the lease of a set that failed so that it does not
+// linger until it expires, which also deletes the key if the set did go
+// through. Leases that could not be revoked are left to expire.
+func (c *client) revokeLease(key string, id clientv3.LeaseID) {
+	// NB: the request context of the set may have expired.
+	ctx, cancel := c.context()
+	defer cancel()
+
+	finish, err := c.begin(ctx, opLeaseRevoke, key)
+	if err == nil {
+		_, err = c.kv.Revoke(ctx, id)
+		finish(err)
+	}
+	if err != nil {
+		c.m.etcdLeaseError.Inc(1)
+		c.logger.Warn("could not revoke lease of failed set",
+			zap.String("key", key), zap.Int64("lease", int64(id)), zap.Error(err))
+	}
+}
+
+func (c *client) set(
+	ctx context.Context,
+	key string,
+	v proto.Message,
+	readPrev bool,
+	opts ...clientv3.OpOption,
+) (int, kv.Value, error) {
+	if isReservedKey(key) {
+		return 0, nil, errReservedKey
+	}
+	value, err := c.marshal(v)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
-	r, err := c.kv.Put(ctx, c.opts.ApplyPrefix(key), string(value), clientv3.WithPrevKV())
+	finish, err := c.begin(ctx, opSet, key)
+	if err != nil {
+		return 0, nil, err
+	}
+	key = c.opts.ApplyPrefix(key)
+	value, err = c.writeChunks(ctx, key, value, opts...)
+	if err != nil {
+		finish(err)
+		return 0, nil, err
+	}
+	opts = append(opts, clientv3.WithPrevKV())
+	r, err := c.kv.Put(ctx, key, string(value), opts...)
+	finish(err)
 	if err != nil {
+		// NB: the put may have gone through, chunks it left behind are
+		// collected once it is known they are not referenced.
 		c.m.etcdPutError.Inc(1)
-		return 0, err
+		return 0, nil, err
 	}
 
 	// if there is no prev kv, means this is the first version of the key
 	if r.PrevKv == nil {
-		return etcdVersionZero + 1, nil
+		return etcdVersionZero + 1, nil, nil
+	}
+
+	var prev kv.Value
+	if readPrev {
+		prev = c.readPrevious(ctx, r.PrevKv)
 	}
+	c.deleteReplacedChunks(ctx, r.PrevKv)
+	return int(r.PrevKv.Version + 1), prev, nil
+}
 
-	return int(r.PrevKv.Version + 1), nil
+// readPrevious returns the value replaced by a set which went through, or
+// nil if it can not be read.
+func (c *client) readPrevious(ctx context.Context, pair *mvccpb.KeyValue) kv.Value {
+	v, err := c.readValue(ctx, pair)
+	if err != nil {
+		c.logger.Warn("could not read value replaced by set",
+			zap.String("key", string(pair.Key)), zap.Error(err))
+		return nil
+	}
+	return v
 }
 
 func (c *client) SetIfNotExists(key string, v proto.Message) (int, error) {
+	if c.opts.ReadOnly() {
+		return 0, kv.NewReadOnlyError("set if not exists", key)
+	}
 	version, err := c.CheckAndSet(key, etcdVersionZero, v)
 	if err == kv.ErrVersionMismatch {
 		err = kv.ErrAlreadyExists
@@ -504,37 +1097,104 @@ func (c *client) SetIfNotExists(key string, v proto.Message) (int, error) {
 }
 
 func (c *client) CheckAndSet(key string, version int, v proto.Message) (int, error) {
+	newVersion, _, err := c.checkAndSet(key, version, v, false)
+	return newVersion, err
+}
+
+// CheckAndSetReturningPrevious returns the value replaced by the set from
+// the previous key value etcd returns with the put.
+func (c *client) CheckAndSetReturningPrevious(
+	key string,
+	version int,
+	v proto.Message,
+) (int, kv.Value, error) {
+	return c.checkAndSet(key, version, v, true)
+}
+
+func (c *client) checkAndSet(
+	key string,
+	version int,
+	v proto.Message,
+	readPrev bool,
+) (int, kv.Value, error) {
+	if c.opts.ReadOnly() {
+		return 0, nil, kv.NewReadOnlyError("check and set", key)
+	}
+	if err := fault.Inject(fault.KVWrite); err != nil {
+		return 0, nil, err
+	}
+
+	if isReservedKey(key) {
+		return 0, nil, errReservedKey
+	}
+
 	ctx, cancel := c.context()
 	defer cancel()
 
-	value, err := proto.Marshal(v)
+	value, err := c.marshal(v)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
 
+	finish, err := c.begin(ctx, opCheckAndSet, key)
+	if err != nil {
+		return 0, nil, err
+	}
 	key = c.opts.ApplyPrefix(key)
+	value, err = c.writeChunks(ctx, key, value)
+	if err != nil {
+		finish(err)
+		return 0, nil, err
+	}
 	r, err := c.kv.Txn(ctx).
 		If(clientv3.Compare(clientv3.Version(key), kv.CompareEqual.String(), version)).
-		Then(clientv3.OpPut(key, string(value))).
+		Then(clientv3.OpPut(key, string(value), clientv3.WithPrevKV())).
 		Commit()
+	finish(err)
 	if err != nil {
+		// NB: the transaction may have committed, chunks it left behind are
+		// collected once it is known they are not referenced.
 		c.m.etcdTnxError.Inc(1)
-		return 0, err
+		return 0, nil, err
 	}
 	if !r.Succeeded {
-		return 0, kv.ErrVersionMismatch
+		c.deleteValueChunks(ctx, key, value)
+		return 0, nil, kv.ErrVersionMismatch
 	}
 
-	return version + 1, nil
+	var prev kv.Value
+	if putResp := r.Responses[0].GetResponsePut(); putResp != nil && putResp.PrevKv != nil {
+		if readPrev {
+			prev = c.readPrevious(ctx, putResp.PrevKv)
+		}
+		c.deleteReplacedChunks(ctx, putResp.PrevKv)
+	}
+	return version + 1, prev, nil
 }
 
 func (c *client) Delete(key string) (kv.Value, error) {
+	if c.opts.ReadOnly() {
+		return nil, kv.NewReadOnlyError("delete", key)
+	}
+	if err := fault.Inject(fault.KVWrite); err != nil {
+		return nil, err
+	}
+
+	if isReservedKey(key) {
+		return nil, errReservedKey
+	}
+
 	ctx, cancel := c.context()
 	defer cancel()
 
+	finish, err := c.begin(ctx, opDelete, key)
+	if err != nil {
+		return nil, err
+	}
 	key = c.opts.ApplyPrefix(key)
 
 	r, err := c.kv.Delete(ctx, key, clientv3.WithPrevKV())
+	finish(err)
 	if err != nil {
 		return nil, err
 	}
@@ -543,10 +1203,66 @@ func (c *client) Delete(key string) (kv.Value, error) {
 		return nil, kv.ErrNotFound
 	}
 
-	prevKV := newValue(r.PrevKvs[0].Value, r.PrevKvs[0].Version, r.PrevKvs[0].ModRevision)
+	c.deleteCache(key)
+
+	prevKV, err := c.readValue(ctx, r.PrevKvs[0])
+	c.deleteReplacedChunks(ctx, r.PrevKvs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return prevKV, nil
+}
+
+func (c *client) DeleteIfVersionMatches(key string, version int) (kv.Value, error) {
+	if c.opts.ReadOnly() {
+		return nil, kv.NewReadOnlyError("delete if version matches", key)
+	}
+	if err := fault.Inject(fault.KVWrite); err != nil {
+		return nil, err
+	}
+
+	if isReservedKey(key) {
+		return nil, errReservedKey
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	finish, err := c.begin(ctx, opDeleteIfVersionMatches, key)
+	if err != nil {
+		return nil, err
+	}
+	key = c.opts.ApplyPrefix(key)
+
+	r, err := c.kv.Txn(ctx).
+		If(clientv3.Compare(clientv3.Version(key), kv.CompareEqual.String(), version)).
+		Then(clientv3.OpDelete(key, clientv3.WithPrevKV())).
+		Commit()
+	finish(err)
+	if err != nil {
+		c.m.etcdTnxError.Inc(1)
+		return nil, err
+	}
+	if !r.Succeeded {
+		return nil, kv.ErrVersionMismatch
+	}
+
+	// NB: a zero version matches a key that does not exist.
+	deleteResp := r.Responses[0].GetResponseDeleteRange()
+	if deleteResp == nil || deleteResp.Deleted == 0 {
+		return nil, kv.ErrNotFound
+	}
 
 	c.deleteCache(key)
 
+	prev := deleteResp.PrevKvs[0]
+	prevKV, err := c.readValue(ctx, prev)
+	c.deleteReplacedChunks(ctx, prev)
+	if err != nil {
+		return nil, err
+	}
+
 	return prevKV, nil
 }
 
@@ -568,8 +1284,15 @@ func (c *client) getCache(key string) (kv.Value, bool) {
 	c.cache.RLock()
 	v, ok := c.cache.Values[key]
 	c.cache.RUnlock()
+	if !ok {
+		return nil, false
+	}
 
-	return v, ok
+	// NB: the cached value is only served when etcd is unavailable so it may
+	// be out of date, return a copy marked as stale.
+	stale := *v
+	stale.stale = true
+	return &stale, true
 }
 
 func (c *client) mergeCache(key string, v *value) {
@@ -593,16 +1316,8 @@ func (c *client) notifyCacheUpdate() {
 }
 
 func (c *client) writeCacheToFile() error {
-	file, err := os.Create(c.cacheFile)
-	if err != nil {
-		c.m.diskWriteError.Inc(1)
-		c.logger.Warn("error creating cache file", zap.String("file", c.cacheFile), zap.Error(err))
-		return fmt.Errorf("invalid cache file: %s", c.cacheFile)
-	}
-
-	encoder := json.NewEncoder(file)
 	c.cache.RLock()
-	err = encoder.Encode(c.cache)
+	data, err := c.cache.toProto().Marshal()
 	c.cache.RUnlock()
 
 	if err != nil {
@@ -611,14 +1326,42 @@ func (c *client) writeCacheToFile() error {
 		return err
 	}
 
-	if err = file.Close(); err != nil {
+	// Write to a temporary file first and rename it over the cache file so
+	// the cache file is never left partially written.
+	tmpFile := c.cacheFile + ".tmp"
+	if err := writeFileSync(tmpFile, data, 0o644); err != nil {
+		c.m.diskWriteError.Inc(1)
+		c.logger.Warn("error writing cache file", zap.String("file", tmpFile), zap.Error(err))
+		return fmt.Errorf("invalid cache file: %s", c.cacheFile)
+	}
+
+	if err := os.Rename(tmpFile, c.cacheFile); err != nil {
 		c.m.diskWriteError.Inc(1)
-		c.logger.Warn("error closing cache file", zap.String("file", c.cacheFile), zap.Error(err))
+		c.logger.Warn("error renaming cache file", zap.String("file", c.cacheFile), zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
+// writeFileSync writes the data to the file and syncs it to disk, so that the
+// file is complete once renamed even if the host crashes.
+func writeFileSync(name string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func (c *client) createCacheDir(fm os.FileMode) error {
 	path := path.Dir(c.opts.CacheFileFn()(c.opts.Prefix()))
 	if err := os.MkdirAll(path, fm); err != nil {
@@ -643,23 +1386,98 @@ func (c *client) initCache(fm os.FileMode) error {
 		c.m.diskWriteError.Inc(1)
 		return fmt.Errorf("error creating cache directory: %s", err)
 	}
-	file, err := os.Open(c.cacheFile)
+	data, err := os.ReadFile(c.cacheFile)
+	if os.IsNotExist(err) && c.legacyCacheFile != "" {
+		if _, statErr := os.Stat(c.legacyCacheFile); statErr == nil {
+			return c.initCacheFromLegacyFile()
+		}
+	}
 	if err != nil {
 		c.m.diskReadError.Inc(1)
 		return fmt.Errorf("error opening cache file %s: %v", c.cacheFile, err)
 	}
 
 	// Read bootstrap file
-	decoder := json.NewDecoder(file)
-
-	if err := decoder.Decode(c.cache); err != nil {
+	var pb kvpb.KeyValueCache
+	if err := pb.Unmarshal(data); err != nil {
 		c.m.diskReadError.Inc(1)
 		return fmt.Errorf("error reading cache file %s: %v", c.cacheFile, err)
 	}
 
+	c.cache.Lock()
+	c.cache.fromProto(&pb)
+	c.cache.Unlock()
+
+	return nil
+}
+
+// initCacheFromLegacyFile loads the cache from the JSON cache file written by
+// previous versions and converts it to the cache file, the JSON cache file is
+// removed once converted so that it is only ever loaded once.
+func (c *client) initCacheFromLegacyFile() error {
+	data, err := os.ReadFile(c.legacyCacheFile)
+	if err != nil {
+		c.m.diskReadError.Inc(1)
+		return fmt.Errorf("error opening cache file %s: %v", c.legacyCacheFile, err)
+	}
+
+	var legacy legacyValueCache
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		c.m.diskReadError.Inc(1)
+		return fmt.Errorf("error reading cache file %s: %v", c.legacyCacheFile, err)
+	}
+
+	c.cache.Lock()
+	for key, v := range legacy.Values {
+		c.cache.Values[key] = newValue(v.Value, v.Version, v.Revision)
+	}
+	c.cache.Unlock()
+
+	if err := c.writeCacheToFile(); err != nil {
+		return err
+	}
+	if err := os.Remove(c.legacyCacheFile); err != nil {
+		c.logger.Warn("error removing converted cache file",
+			zap.String("file", c.legacyCacheFile), zap.Error(err))
+	}
+
+	c.logger.Info("converted cache file",
+		zap.String("from", c.legacyCacheFile),
+		zap.String("to", c.cacheFile))
 	return nil
 }
 
+// begin admits a request of the operation on the key to etcd, it fails fast
+// if the circuit breaker is open and waits for the rate limiter otherwise.
+// The returned function must be called with the outcome of the request.
+func (c *client) begin(ctx context.Context, op, key string) (func(err error), error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	if c.limiter != nil {
+		if err := c.limiter.WaitN(ctx, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	finish := c.km.start(op, key)
+	return func(err error) {
+		finish(err)
+		c.breaker.record(err)
+	}, nil
+}
+
+// marshal marshals the message and compresses it if value compression is
+// enabled.
+func (c *client) marshal(v proto.Message) ([]byte, error) {
+	value, err := proto.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeValue(value, c.opts.ValueCompression(), c.opts.ValueCompressionThreshold())
+}
+
 func (c *client) context() (context.Context, context.CancelFunc) {
 	ctx := context.Background()
 	cancel := noopCancel
@@ -673,17 +1491,51 @@ func (c *client) context() (context.Context, context.CancelFunc) {
 type valueCache struct {
 	sync.RWMutex
 
-	Values map[string]*value `json:"values"`
+	Values map[string]*value
 }
 
 func newCache() *valueCache {
 	return &valueCache{Values: make(map[string]*value)}
 }
 
+// legacyValueCache is the cache as persisted as JSON by previous versions.
+type legacyValueCache struct {
+	Values map[string]legacyValue `json:"values"`
+}
+
+type legacyValue struct {
+	Value    []byte `json:"value"`
+	Version  int64  `json:"version"`
+	Revision int64  `json:"revision"`
+}
+
+func (c *valueCache) toProto() *kvpb.KeyValueCache {
+	pb := &kvpb.KeyValueCache{
+		Values: make([]*kvpb.CachedValue, 0, len(c.Values)),
+	}
+	for key, v := range c.Values {
+		pb.Values = append(pb.Values, &kvpb.CachedValue{
+			Key:      key,
+			Value:    v.Val,
+			Version:  v.Ver,
+			Revision: v.Rev,
+		})
+	}
+	return pb
+}
+
+func (c *valueCache) fromProto(pb *kvpb.KeyValueCache) {
+	for _, v := range pb.Values {
+		c.Values[v.Key] = newValue(v.Value, v.Version, v.Revision)
+	}
+}
+
 type value struct {
-	Val []byte `json:"value"`
-	Ver int64  `json:"version"`
-	Rev int64  `json:"revision"`
+	Val []byte
+	Ver int64
+	Rev int64
+
+	stale bool
 }
 
 func newValue(val []byte, ver, rev int64) *value {
@@ -697,6 +1549,11 @@ func newValue(val []byte, ver, rev int64) *value {
 func (c *value) IsNewer(other kv.Value) bool {
 	othervalue, ok := other.(*value)
 	if ok {
+		if c.Rev == othervalue.Rev {
+			// A value read from etcd replaces the same value served from the
+			// cache so that it is no longer marked as stale.
+			return othervalue.stale && !c.stale
+		}
 		return c.Rev > othervalue.Rev
 	}
 
@@ -704,11 +1561,18 @@ func (c *value) IsNewer(other kv.Value) bool {
 }
 
 func (c *value) Unmarshal(v proto.Message) error {
-	err := proto.Unmarshal(c.Val, v)
+	val, err := decodeValue(c.Val)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return proto.Unmarshal(val, v)
 }
 
 func (c *value) Version() int {
 	return int(c.Ver)
 }
+
+func (c *value) IsStale() bool {
+	return c.stale
+}
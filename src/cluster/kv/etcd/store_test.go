@@ -23,22 +23,28 @@ package etcd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/generated/proto/kvpb"
 	"github.com/m3db/m3/src/cluster/generated/proto/kvtest"
 	"github.com/m3db/m3/src/cluster/kv"
 	xclock "github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/fault"
+	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/retry"
 
 	"github.com/golang/protobuf/proto"
 	integration "github.com/m3db/m3/src/integration/resources/docker/dockerexternal/etcdintegration"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"golang.org/x/net/context"
 )
@@ -53,6 +59,14 @@ func TestValue(t *testing.T) {
 	require.True(t, v2.IsNewer(v1))
 	require.False(t, v1.IsNewer(v1))
 	require.False(t, v1.IsNewer(v2))
+
+	stale := newValue(nil, 2, 100)
+	stale.stale = true
+	require.True(t, stale.IsStale())
+	require.False(t, v1.IsStale())
+	require.True(t, v1.IsNewer(stale))
+	require.False(t, stale.IsNewer(v1))
+	require.True(t, v2.IsNewer(stale))
 }
 
 func TestGetAndSet(t *testing.T) {
@@ -83,6 +97,291 @@ func TestGetAndSet(t *testing.T) {
 	verifyValue(t, value, "bar2", 2)
 }
 
+func TestGetAndSetCompressed(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	opts = opts.
+		SetValueCompression(ZstdValueCompression).
+		SetValueCompressionThreshold(1024)
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	large := strings.Repeat("bar", 1024)
+	_, err = store.Set("large", genProto(large))
+	require.NoError(t, err)
+	_, err = store.Set("small", genProto("bar"))
+	require.NoError(t, err)
+
+	// Only values from the threshold are compressed.
+	r, err := ec.Get(context.Background(), opts.ApplyPrefix("large"))
+	require.NoError(t, err)
+	require.Equal(t, valueHeaderZstd, r.Kvs[0].Value[0])
+	require.True(t, len(r.Kvs[0].Value) < len(large))
+
+	r, err = ec.Get(context.Background(), opts.ApplyPrefix("small"))
+	require.NoError(t, err)
+	uncompressed, err := proto.Marshal(genProto("bar"))
+	require.NoError(t, err)
+	require.Equal(t, uncompressed, r.Kvs[0].Value)
+
+	value, err := store.Get("large")
+	require.NoError(t, err)
+	verifyValue(t, value, large, 1)
+
+	value, err = store.Get("small")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar", 1)
+
+	// Values written uncompressed by older clients are still read.
+	uncompressed, err = proto.Marshal(genProto(large))
+	require.NoError(t, err)
+	_, err = ec.Put(context.Background(), opts.ApplyPrefix("old"), string(uncompressed))
+	require.NoError(t, err)
+
+	value, err = store.Get("old")
+	require.NoError(t, err)
+	verifyValue(t, value, large, 1)
+}
+
+func TestGetAndSetChunked(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	opts = opts.SetValueChunkSize(1024)
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	w, err := store.Watch("foo")
+	require.NoError(t, err)
+
+	large := strings.Repeat("bar", 1024)
+	version, err := store.Set("foo", genProto(large))
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	// The key holds the manifest of the chunks.
+	r, err := ec.Get(context.Background(), opts.ApplyPrefix("foo"))
+	require.NoError(t, err)
+	require.True(t, isChunkManifest(r.Kvs[0].Value))
+	m, err := unmarshalChunkManifest(r.Kvs[0].Value)
+	require.NoError(t, err)
+	require.Equal(t, 4, m.numChunks)
+
+	value, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, large, 1)
+
+	<-w.C()
+	verifyValue(t, w.Get(), large, 1)
+
+	// Chunks are not listed under the prefix of the store.
+	values, err := store.(*client).getPrefixFromKVStore(opts.ApplyPrefix(""))
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+
+	// The chunks of replaced values are deleted.
+	larger := strings.Repeat("baz", 2048)
+	version, err = store.CheckAndSet("foo", 1, genProto(larger))
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+
+	value, err = store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, larger, 2)
+
+	<-w.C()
+	verifyValue(t, w.Get(), larger, 2)
+
+	chunks, err := ec.Get(context.Background(), store.(*client).chunksPrefix(opts.ApplyPrefix("foo"), m.id),
+		clientv3.WithPrefix(), clientv3.WithCountOnly())
+	require.NoError(t, err)
+	require.Equal(t, int64(0), chunks.Count)
+
+	// Small values are not chunked.
+	_, err = store.Set("foo", genProto("bar"))
+	require.NoError(t, err)
+	r, err = ec.Get(context.Background(), opts.ApplyPrefix("foo"))
+	require.NoError(t, err)
+	require.False(t, isChunkManifest(r.Kvs[0].Value))
+
+	// Deleting chunked values deletes their chunks.
+	_, err = store.Set("foo", genProto(large))
+	require.NoError(t, err)
+	prev, err := store.Delete("foo")
+	require.NoError(t, err)
+	verifyValue(t, prev, large, 4)
+
+	chunks, err = ec.Get(context.Background(), opts.ApplyPrefix(chunkKeyPrefix),
+		clientv3.WithPrefix(), clientv3.WithCountOnly())
+	require.NoError(t, err)
+	require.Equal(t, int64(0), chunks.Count)
+
+	// Keys under the reserved sub path of the chunks can not be mutated.
+	_, err = store.Set(chunkKeyPrefix+"foo", genProto("bar"))
+	require.Equal(t, errReservedKey, err)
+	_, err = store.Delete(chunkKeyPrefix + "foo")
+	require.Equal(t, errReservedKey, err)
+
+	w.Close()
+}
+
+func TestSetWithTTLChunked(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	opts = opts.SetValueChunkSize(1024)
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	_, err = store.SetWithTTL("foo", genProto(strings.Repeat("bar", 1024)), time.Minute)
+	require.NoError(t, err)
+
+	// The chunks expire along with the key.
+	r, err := ec.Get(context.Background(), opts.ApplyPrefix("foo"))
+	require.NoError(t, err)
+	require.NotZero(t, r.Kvs[0].Lease)
+
+	chunks, err := ec.Get(context.Background(), opts.ApplyPrefix(chunkKeyPrefix), clientv3.WithPrefix())
+	require.NoError(t, err)
+	require.Len(t, chunks.Kvs, 4)
+	for _, chunk := range chunks.Kvs {
+		require.Equal(t, r.Kvs[0].Lease, chunk.Lease)
+	}
+}
+
+func TestCollectChunks(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	opts = opts.SetValueChunkSize(1024).SetValueChunkGCInterval(0)
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+	c := store.(*client)
+
+	large := strings.Repeat("bar", 1024)
+	_, err = store.Set("foo", genProto(large))
+	require.NoError(t, err)
+
+	// Chunks written without their manifest being set, as when the outcome of
+	// a set is not known, are not referenced by the key.
+	value, err := c.marshal(genProto(large))
+	require.NoError(t, err)
+	_, err = c.writeChunks(context.Background(), opts.ApplyPrefix("foo"), value)
+	require.NoError(t, err)
+
+	countChunks := func() int64 {
+		chunks, err := ec.Get(context.Background(), opts.ApplyPrefix(chunkKeyPrefix),
+			clientv3.WithPrefix(), clientv3.WithCountOnly())
+		require.NoError(t, err)
+		return chunks.Count
+	}
+	require.Equal(t, int64(8), countChunks())
+
+	// Chunks written after the previous collection are not collected as
+	// their write may still be in flight.
+	rev, err := c.collectChunks(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), countChunks())
+
+	_, err = c.collectChunks(context.Background(), rev)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), countChunks())
+
+	v, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, v, large, 1)
+}
+
+func TestTxnChunked(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	opts = opts.SetValueChunkSize(1024)
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	large := strings.Repeat("bar", 1024)
+	_, err = store.Commit(
+		[]kv.Condition{
+			kv.NewCondition().
+				SetCompareType(kv.CompareEqual).
+				SetTargetType(kv.TargetVersion).
+				SetKey("foo").
+				SetValue(0),
+		},
+		[]kv.Op{kv.NewSetOp("foo", genProto(large))},
+	)
+	require.NoError(t, err)
+
+	value, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, large, 1)
+
+	// The chunks of a transaction that does not commit are deleted.
+	_, err = store.Commit(
+		[]kv.Condition{
+			kv.NewCondition().
+				SetCompareType(kv.CompareEqual).
+				SetTargetType(kv.TargetVersion).
+				SetKey("foo").
+				SetValue(0),
+		},
+		[]kv.Op{kv.NewSetOp("foo", genProto(strings.Repeat("baz", 1024)))},
+	)
+	require.Error(t, err)
+
+	chunks, err := ec.Get(context.Background(), opts.ApplyPrefix(chunkKeyPrefix),
+		clientv3.WithPrefix(), clientv3.WithCountOnly())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), chunks.Count)
+
+	value, err = store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, large, 1)
+}
+
+func TestGetMany(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	values, err := store.GetMany(nil)
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	keys := make([]string, 0, maxTxnOps+2)
+	for i := 0; i < maxTxnOps+1; i++ {
+		key := fmt.Sprintf("foo%d", i)
+		_, err = store.Set(key, genProto(key))
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	keys = append(keys, "missing")
+
+	// keys span more than one transaction.
+	values, err = store.GetMany(keys)
+	require.NoError(t, err)
+	require.Len(t, values, maxTxnOps+1)
+	for _, key := range keys[:maxTxnOps+1] {
+		verifyValue(t, values[key], key, 1)
+	}
+
+	closeFn()
+
+	// from cache
+	values, err = store.GetMany(keys[:2])
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	verifyValue(t, values["foo0"], "foo0", 1)
+	require.True(t, values["foo0"].IsStale())
+
+	// not all keys are cached
+	_, err = store.GetMany(keys)
+	require.Error(t, err)
+}
+
 func TestNoCache(t *testing.T) {
 	ec, opts, closeFn := testStore(t)
 
@@ -149,6 +448,53 @@ func TestCacheDirCreation(t *testing.T) {
 	require.Equal(t, 0, len(store.(*client).cacheUpdatedCh))
 }
 
+func TestCacheFromLegacyFile(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	tdir, err := ioutil.TempDir("", "m3tests")
+	require.NoError(t, err)
+	defer os.RemoveAll(tdir)
+
+	var (
+		cacheFile       = path.Join(tdir, "cache.pb")
+		legacyCacheFile = path.Join(tdir, "cache.json")
+	)
+	legacy, err := json.Marshal(map[string]interface{}{
+		"values": map[string]interface{}{
+			opts.ApplyPrefix("foo"): map[string]interface{}{
+				"value":    []byte("bar"),
+				"version":  2,
+				"revision": 7,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(legacyCacheFile, legacy, 0o644))
+
+	opts = opts.
+		SetCacheFileFn(func(string) string { return cacheFile }).
+		SetLegacyCacheFileFn(func(string) string { return legacyCacheFile })
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	v, ok := store.(*client).getCache(opts.ApplyPrefix("foo"))
+	require.True(t, ok)
+	require.Equal(t, 2, v.Version())
+	require.True(t, v.IsStale())
+
+	// The legacy cache file is converted once.
+	_, err = os.Stat(legacyCacheFile)
+	require.True(t, os.IsNotExist(err))
+	data, err := ioutil.ReadFile(cacheFile)
+	require.NoError(t, err)
+	var pb kvpb.KeyValueCache
+	require.NoError(t, pb.Unmarshal(data))
+	require.Len(t, pb.Values, 1)
+	require.Equal(t, []byte("bar"), pb.Values[0].Value)
+	require.Equal(t, int64(7), pb.Values[0].Revision)
+}
+
 func TestCache(t *testing.T) {
 	ec, opts, closeFn := testStore(t)
 
@@ -170,6 +516,7 @@ func TestCache(t *testing.T) {
 	value, err := store.Get("foo")
 	require.NoError(t, err)
 	verifyValue(t, value, "bar1", 1)
+	require.False(t, value.IsStale())
 	for {
 		// the notification should be picked up and trigger a sync
 		if len(store.(*client).cacheUpdatedCh) == 0 {
@@ -182,6 +529,7 @@ func TestCache(t *testing.T) {
 	value, err = store.Get("foo")
 	require.NoError(t, err)
 	verifyValue(t, value, "bar1", 1)
+	require.True(t, value.IsStale())
 	require.Equal(t, 0, len(store.(*client).cacheUpdatedCh))
 
 	// new store but with cache file
@@ -198,7 +546,15 @@ func TestCache(t *testing.T) {
 	value, err = store.Get("foo")
 	require.NoError(t, err)
 	verifyValue(t, value, "bar1", 1)
+	require.True(t, value.IsStale())
 	require.Equal(t, 0, len(store.(*client).cacheUpdatedCh))
+
+	// watches are initialized from the cache
+	w, err := store.Watch("foo")
+	require.NoError(t, err)
+	<-w.C()
+	verifyValue(t, w.Get(), "bar1", 1)
+	require.True(t, w.Get().IsStale())
 }
 
 func TestSetIfNotExist(t *testing.T) {
@@ -246,6 +602,248 @@ func TestCheckAndSet(t *testing.T) {
 	verifyValue(t, value, "bar", 2)
 }
 
+func TestOperationMetrics(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	scope := tally.NewTestScope("", nil)
+	store, err := NewStore(ec, opts.SetInstrumentsOptions(
+		instrument.NewOptions().SetMetricsScope(scope)))
+	require.NoError(t, err)
+
+	_, err = store.Set("foo/bar", genProto("bar"))
+	require.NoError(t, err)
+
+	_, err = store.CheckAndSet("foo/baz", 1, genProto("bar"))
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	_, err = store.Get("foo/bar")
+	require.NoError(t, err)
+
+	_, err = store.Get("qux")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	_, err = store.History("foo/bar", 0, 2)
+	require.NoError(t, err)
+
+	_, err = store.Commit(nil, []kv.Op{kv.NewSetOp("baz/qux", genProto("bar"))})
+	require.NoError(t, err)
+
+	snapshot := scope.Snapshot()
+	for _, key := range []string{
+		"etcd-op-latency+key-prefix=foo,operation=set",
+		"etcd-op-latency+key-prefix=foo,operation=check-and-set",
+		"etcd-op-latency+key-prefix=foo,operation=get",
+		"etcd-op-latency+key-prefix=qux,operation=get",
+		"etcd-op-latency+key-prefix=foo,operation=history",
+		"etcd-op-latency+key-prefix=baz,operation=commit",
+	} {
+		require.Contains(t, snapshot.Histograms(), key)
+	}
+
+	// a failed check or a missing key is not an etcd error.
+	for key, c := range snapshot.Counters() {
+		if strings.HasPrefix(key, "etcd-op-errors") {
+			require.Equal(t, int64(0), c.Value(), key)
+		}
+	}
+}
+
+func TestSetWithTTL(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	_, err = store.SetWithTTL("foo", genProto("bar"), 0)
+	require.Equal(t, kv.ErrInvalidTTL, err)
+
+	vw, err := store.Watch("foo")
+	require.NoError(t, err)
+
+	version, err := store.SetWithTTL("foo", genProto("bar"), time.Second)
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	<-vw.C()
+	verifyValue(t, vw.Get(), "bar", 1)
+
+	// The lease expiring surfaces as a delete.
+	select {
+	case <-vw.C():
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for key to expire")
+	}
+	require.Nil(t, vw.Get())
+
+	_, err = store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+}
+
+func TestSetReturningPrevious(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	opts = opts.SetValueChunkSize(1024)
+	s, err := NewStore(ec, opts)
+	require.NoError(t, err)
+	store := s.(kv.PreviousValueStore)
+
+	large := strings.Repeat("bar", 1024)
+	version, prev, err := store.SetReturningPrevious("foo", genProto(large))
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+	require.Nil(t, prev)
+
+	// Chunked values replaced by sets are returned whole.
+	version, prev, err = store.SetWithTTLReturningPrevious("foo", genProto("baz"), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+	verifyValue(t, prev, large, 1)
+
+	_, _, err = store.CheckAndSetReturningPrevious("foo", 1, genProto("qux"))
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	version, prev, err = store.CheckAndSetReturningPrevious("foo", 2, genProto("qux"))
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+	verifyValue(t, prev, "baz", 2)
+}
+
+func TestSetWithTTLRevokesLeaseOfFailedSet(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	// NB: etcd rejects requests larger than its max request size.
+	_, err = store.SetWithTTL("foo", genProto(strings.Repeat("bar", 1<<20)), time.Minute)
+	require.Error(t, err)
+
+	leases, err := ec.Leases(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, leases.Leases)
+}
+
+func TestListKeys(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	for _, key := range []string{"ns/b", "ns/a", "ns/c", "other"} {
+		_, err := store.Set(key, genProto(key))
+		require.NoError(t, err)
+	}
+
+	page, err := store.ListKeys("ns/", 2, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/a", "ns/b"}, page.Keys)
+	require.Equal(t, "ns/b", page.NextPageToken)
+
+	page, err = store.ListKeys("ns/", 2, page.NextPageToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/c"}, page.Keys)
+	require.Empty(t, page.NextPageToken)
+
+	page, err = store.ListKeys("", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/a", "ns/b", "ns/c", "other"}, page.Keys)
+
+	_, err = store.ListKeys("ns/", 0, "")
+	require.Equal(t, kv.ErrInvalidPageSize, err)
+
+	_, err = store.ListKeys("ns/", 2, "other")
+	require.Equal(t, kv.ErrInvalidPageToken, err)
+
+	// Chunks are not listed when the store has no prefix.
+	unprefixed, err := NewStore(ec, opts.SetPrefix("").SetValueChunkSize(1024))
+	require.NoError(t, err)
+	_, err = unprefixed.Set("large", genProto(strings.Repeat("bar", 1024)))
+	require.NoError(t, err)
+
+	var (
+		keys      []string
+		pageToken string
+	)
+	for {
+		page, err := unprefixed.ListKeys("", 2, pageToken)
+		require.NoError(t, err)
+		keys = append(keys, page.Keys...)
+		if pageToken = page.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	require.Equal(t, []string{"large", "test/ns/a", "test/ns/b", "test/ns/c", "test/other"}, keys)
+}
+
+func TestReadOnly(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	_, err = store.Set("foo", genProto("bar"))
+	require.NoError(t, err)
+
+	roStore, err := NewStore(ec, opts.SetReadOnly(true))
+	require.NoError(t, err)
+
+	value, err := roStore.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar", 1)
+
+	mutations := map[string]func() error{
+		"set": func() error {
+			_, err := roStore.Set("foo", genProto("baz"))
+			return err
+		},
+		"set with ttl": func() error {
+			_, err := roStore.SetWithTTL("foo", genProto("baz"), time.Second)
+			return err
+		},
+		"set if not exists": func() error {
+			_, err := roStore.SetIfNotExists("qux", genProto("baz"))
+			return err
+		},
+		"check and set": func() error {
+			_, err := roStore.CheckAndSet("foo", 1, genProto("baz"))
+			return err
+		},
+		"delete": func() error {
+			_, err := roStore.Delete("foo")
+			return err
+		},
+		"delete if version matches": func() error {
+			_, err := roStore.DeleteIfVersionMatches("foo", 1)
+			return err
+		},
+		"commit": func() error {
+			_, err := roStore.Commit(nil, []kv.Op{kv.NewSetOp("foo", genProto("baz"))})
+			return err
+		},
+	}
+	for op, fn := range mutations {
+		err := fn()
+		require.True(t, errors.Is(err, kv.ErrReadOnly), op)
+
+		var readOnlyErr *kv.ReadOnlyError
+		require.True(t, errors.As(err, &readOnlyErr), op)
+		require.Equal(t, op, readOnlyErr.Op)
+	}
+
+	value, err = store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar", 1)
+
+	_, err = store.Get("qux")
+	require.Equal(t, kv.ErrNotFound, err)
+}
+
 func TestWatchClose(t *testing.T) {
 	ec, opts, closeFn := testStore(t)
 	defer closeFn()
@@ -403,6 +1001,92 @@ func TestWatchFromNotExist(t *testing.T) {
 	w.Close()
 }
 
+func TestWatchPrefix(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	_, err = store.Set("foo/a", genProto("a1"))
+	require.NoError(t, err)
+
+	w, err := store.WatchPrefix("foo/")
+	require.NoError(t, err)
+
+	<-w.C()
+	values := w.Get()
+	require.Len(t, values, 1)
+	verifyValue(t, values["foo/a"], "a1", 1)
+
+	_, err = store.Set("bar", genProto("bar1"))
+	require.NoError(t, err)
+	_, err = store.Set("foo/b", genProto("b1"))
+	require.NoError(t, err)
+
+	<-w.C()
+	values = w.Get()
+	require.Len(t, values, 2)
+	verifyValue(t, values["foo/a"], "a1", 1)
+	verifyValue(t, values["foo/b"], "b1", 1)
+
+	_, err = store.Set("foo/a", genProto("a2"))
+	require.NoError(t, err)
+
+	<-w.C()
+	values = w.Get()
+	require.Len(t, values, 2)
+	verifyValue(t, values["foo/a"], "a2", 2)
+
+	_, err = store.Delete("foo/b")
+	require.NoError(t, err)
+
+	<-w.C()
+	values = w.Get()
+	require.Len(t, values, 1)
+	require.Contains(t, values, "foo/a")
+
+	w.Close()
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	scope := tally.NewTestScope("", nil)
+	store, err := NewStore(ec, opts.
+		SetInstrumentsOptions(instrument.NewOptions().SetMetricsScope(scope)).
+		SetCircuitBreakerWindow(time.Millisecond).
+		SetCircuitBreakerProbeInterval(time.Hour))
+	require.NoError(t, err)
+
+	_, err = store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+	value, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar1", 1)
+
+	injector := fault.NewInjector()
+	require.NoError(t, injector.Set(fault.KVGet, fault.Fault{Error: errors.New("unavailable")}))
+	fault.Enable(injector)
+	defer fault.Disable()
+
+	// The failure starts the window, the request after the window is let
+	// through as a probe and the requests that follow fail fast.
+	for i := 0; i < 3; i++ {
+		value, err = store.Get("foo")
+		require.NoError(t, err)
+		verifyValue(t, value, "bar1", 1)
+		time.Sleep(2 * time.Millisecond)
+	}
+	_, err = store.Set("foo", genProto("bar2"))
+	require.Equal(t, errCircuitBreakerOpen, err)
+
+	c, ok := scope.Snapshot().Counters()["etcd-circuit-breaker-rejected+"]
+	require.True(t, ok)
+	require.Equal(t, int64(2), c.Value())
+}
+
 func TestGetFromKvNotFound(t *testing.T) {
 	ec, opts, closeFn := testStore(t)
 	defer closeFn()
@@ -691,7 +1375,7 @@ func TestDelete_UpdateWatcherCache(t *testing.T) {
 	defer os.RemoveAll(clientCachePath)
 
 	getStore, err := NewStore(ec, opts.SetCacheFileFn(func(ns string) string {
-		nsFile := path.Join(clientCachePath, fmt.Sprintf("%s.json", ns))
+		nsFile := path.Join(clientCachePath, fmt.Sprintf("%s.pb", ns))
 		return nsFile
 	}))
 	require.NoError(t, err)
@@ -711,7 +1395,7 @@ func TestDelete_UpdateWatcherCache(t *testing.T) {
 		originalBytes []byte
 	)
 	require.True(t, xclock.WaitUntil(func() bool {
-		originalBytes, err = readCacheJSON(clientCachePath)
+		originalBytes, err = readCacheFile(clientCachePath)
 		return err == nil
 	}, time.Minute))
 
@@ -733,7 +1417,7 @@ func TestDelete_UpdateWatcherCache(t *testing.T) {
 		if len(getClient.cache.Values) != 0 {
 			return false
 		}
-		updatedBytes, err = readCacheJSON(clientCachePath)
+		updatedBytes, err = readCacheFile(clientCachePath)
 		if err != nil {
 			return false
 		}
@@ -776,6 +1460,52 @@ func TestDelete_TriggerWatch(t *testing.T) {
 	verifyValue(t, vw.Get(), "bar3", 1)
 }
 
+func TestDeleteIfVersionMatches(t *testing.T) {
+	ec, opts, closeFn := testStore(t)
+	defer closeFn()
+
+	store, err := NewStore(ec, opts)
+	require.NoError(t, err)
+
+	_, err = store.DeleteIfVersionMatches("foo", 0)
+	require.Equal(t, kv.ErrNotFound, err)
+
+	_, err = store.DeleteIfVersionMatches("foo", 1)
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	vw, err := store.Watch("foo")
+	require.NoError(t, err)
+
+	_, err = store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+	_, err = store.Set("foo", genProto("bar2"))
+	require.NoError(t, err)
+
+	require.True(t, xclock.WaitUntil(func() bool {
+		v := vw.Get()
+		return v != nil && v.Version() == 2
+	}, time.Minute))
+
+	_, err = store.DeleteIfVersionMatches("foo", 1)
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	v, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, v, "bar2", 2)
+
+	v, err = store.DeleteIfVersionMatches("foo", 2)
+	require.NoError(t, err)
+	verifyValue(t, v, "bar2", 2)
+
+	// Watches receive a final nil value once the key is deleted.
+	require.True(t, xclock.WaitUntil(func() bool {
+		return vw.Get() == nil
+	}, time.Minute))
+
+	_, err = store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+}
+
 func TestStaleDelete__FromGet(t *testing.T) {
 	integration.BeforeTestExternal(t)
 	// in this test we ensure clients who did not receive a delete for a key in
@@ -791,7 +1521,7 @@ func TestStaleDelete__FromGet(t *testing.T) {
 	})
 
 	setStore, err := NewStore(ec, opts.SetCacheFileFn(func(ns string) string {
-		return path.Join(serverCachePath, fmt.Sprintf("%s.json", ns))
+		return path.Join(serverCachePath, fmt.Sprintf("%s.pb", ns))
 	}))
 	require.NoError(t, err)
 
@@ -811,8 +1541,8 @@ func TestStaleDelete__FromGet(t *testing.T) {
 		cacheBytes []byte
 	)
 	require.True(t, xclock.WaitUntil(func() bool {
-		fileName, cacheBytes, err = readCacheJSONAndFilename(serverCachePath)
-		return err == nil && isValidJSON(cacheBytes)
+		fileName, cacheBytes, err = readCacheFileAndFilename(serverCachePath)
+		return err == nil && isValidCache(cacheBytes)
 	}, time.Minute), "timed out waiting to read cache file")
 	closeFn()
 
@@ -831,7 +1561,7 @@ func TestStaleDelete__FromGet(t *testing.T) {
 	require.NoError(t, f.Close())
 
 	require.True(t, xclock.WaitUntil(func() bool {
-		_, newBytes, err := readCacheJSONAndFilename(newServerCachePath)
+		_, newBytes, err := readCacheFileAndFilename(newServerCachePath)
 		return err == nil && bytes.Equal(cacheBytes, newBytes)
 	}, time.Minute), "timed out waiting to flush new cache file")
 
@@ -839,7 +1569,7 @@ func TestStaleDelete__FromGet(t *testing.T) {
 	ec2, opts, closeFn2 := testStore(t, withNoEtcdBeforeTestExternal())
 	defer closeFn2()
 	getStore, err := NewStore(ec2, opts.SetCacheFileFn(func(ns string) string {
-		nsFile := path.Join(newServerCachePath, fmt.Sprintf("%s.json", ns))
+		nsFile := path.Join(newServerCachePath, fmt.Sprintf("%s.pb", ns))
 		return nsFile
 	}))
 	require.NoError(t, err)
@@ -857,7 +1587,7 @@ func TestStaleDelete__FromGet(t *testing.T) {
 	require.Nil(t, v)
 
 	require.True(t, xclock.WaitUntil(func() bool {
-		_, updatedBytes, err := readCacheJSONAndFilename(newServerCachePath)
+		_, updatedBytes, err := readCacheFileAndFilename(newServerCachePath)
 		return err == nil && !bytes.Equal(cacheBytes, updatedBytes)
 	}, time.Minute), "timed out waiting to flush cache file delete")
 }
@@ -874,7 +1604,7 @@ func TestStaleDelete__FromWatch(t *testing.T) {
 	ec, opts, closeFn := testStore(t, withNoEtcdBeforeTestExternal())
 
 	setStore, err := NewStore(ec, opts.SetCacheFileFn(func(ns string) string {
-		return path.Join(serverCachePath, fmt.Sprintf("%s.json", ns))
+		return path.Join(serverCachePath, fmt.Sprintf("%s.pb", ns))
 	}))
 	require.NoError(t, err)
 
@@ -894,10 +1624,10 @@ func TestStaleDelete__FromWatch(t *testing.T) {
 		cacheBytes []byte
 	)
 	require.True(t, xclock.WaitUntil(func() bool {
-		fileName, cacheBytes, err = readCacheJSONAndFilename(serverCachePath)
-		// Need to make sure it is valid JSON to ensure we're not reading the
+		fileName, cacheBytes, err = readCacheFileAndFilename(serverCachePath)
+		// Need to make sure it is a valid cache to ensure we're not reading the
 		// bytes before they've been completely written out.
-		return err == nil && isValidJSON(cacheBytes)
+		return err == nil && isValidCache(cacheBytes)
 	}, time.Minute), "timed out waiting to read cache file")
 	closeFn()
 
@@ -916,15 +1646,15 @@ func TestStaleDelete__FromWatch(t *testing.T) {
 	require.NoError(t, f.Close())
 
 	require.True(t, xclock.WaitUntil(func() bool {
-		_, newBytes, err := readCacheJSONAndFilename(newServerCachePath)
-		return err == nil && bytes.Equal(cacheBytes, newBytes) && isValidJSON(newBytes)
+		_, newBytes, err := readCacheFileAndFilename(newServerCachePath)
+		return err == nil && bytes.Equal(cacheBytes, newBytes) && isValidCache(newBytes)
 	}, time.Minute), "timed out waiting to flush new cache file")
 
 	// create new etcd cluster
 	ec2, opts, closeFn2 := testStore(t, withNoEtcdBeforeTestExternal())
 	defer closeFn2()
 	getStore, err := NewStore(ec2, opts.SetCacheFileFn(func(ns string) string {
-		nsFile := path.Join(newServerCachePath, fmt.Sprintf("%s.json", ns))
+		nsFile := path.Join(newServerCachePath, fmt.Sprintf("%s.pb", ns))
 		return nsFile
 	}))
 	require.NoError(t, err)
@@ -944,16 +1674,16 @@ func TestStaleDelete__FromWatch(t *testing.T) {
 	require.Nil(t, w.Get())
 
 	require.True(t, xclock.WaitUntil(func() bool {
-		_, updatedBytes, err := readCacheJSONAndFilename(newServerCachePath)
+		_, updatedBytes, err := readCacheFileAndFilename(newServerCachePath)
 		return err == nil && !bytes.Equal(cacheBytes, updatedBytes)
 	}, time.Minute), "timed out waiting to flush cache file delete")
 	require.Equal(t, 0, len(getClient.cache.Values))
 	require.Nil(t, w.Get())
 }
 
-func isValidJSON(b []byte) bool {
-	var j interface{}
-	return json.Unmarshal(b, &j) == nil
+func isValidCache(b []byte) bool {
+	var cache kvpb.KeyValueCache
+	return len(b) > 0 && cache.Unmarshal(b) == nil
 }
 
 func TestTxn(t *testing.T) {
@@ -1045,6 +1775,7 @@ func TestTxn_ConditionFail(t *testing.T) {
 		[]kv.Op{kv.NewSetOp("foo", genProto("bar1"))},
 	)
 	require.Error(t, err)
+	require.True(t, errors.Is(err, kv.ErrConditionCheckFailed))
 
 	store.Set("key1", genProto("v1"))
 	store.Set("key2", genProto("v2"))
@@ -1064,6 +1795,15 @@ func TestTxn_ConditionFail(t *testing.T) {
 		[]kv.Op{kv.NewSetOp("foo", genProto("bar1"))},
 	)
 	require.Error(t, err)
+
+	var conflictErr *kv.ConditionCheckFailedError
+	require.True(t, errors.As(err, &conflictErr))
+	require.Len(t, conflictErr.Failures, 1)
+	require.Equal(t, "key2", conflictErr.Failures[0].Condition.Key())
+	require.Equal(t, 1, conflictErr.Failures[0].ActualVersion)
+
+	_, err = store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
 }
 
 func TestTxn_UnknownType(t *testing.T) {
@@ -1210,7 +1950,7 @@ func testStore(t *testing.T, testOpts ...testStoreOption) (*clientv3.Client, Opt
 	return ecluster.RandClient(), opts, closer
 }
 
-func readCacheJSONAndFilename(dirPath string) (string, []byte, error) {
+func readCacheFileAndFilename(dirPath string) (string, []byte, error) {
 	files, err := ioutil.ReadDir(dirPath)
 	if err != nil {
 		return "", nil, err
@@ -1231,7 +1971,7 @@ func readCacheJSONAndFilename(dirPath string) (string, []byte, error) {
 	return fileName, b, nil
 }
 
-func readCacheJSON(dirPath string) ([]byte, error) {
-	_, b, err := readCacheJSONAndFilename(dirPath)
+func readCacheFile(dirPath string) ([]byte, error) {
+	_, b, err := readCacheFileAndFilename(dirPath)
 	return b, err
 }
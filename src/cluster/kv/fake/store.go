@@ -22,6 +22,7 @@ package fake
 
 import (
 	"errors"
+	"time"
 
 	"github.com/m3db/m3/src/cluster/kv"
 
@@ -29,8 +30,9 @@ import (
 )
 
 // NewStore returns a fakeStore adhering to the kv.Store interface.
-// All methods except Watch are implemented. Implementation is not threadsafe
-// and should only be used for tests.
+// All methods except Watch, WatchFromVersion, WatchPrefix and SetWithTTL are
+// implemented.
+// Implementation is not threadsafe and should only be used for tests.
 func NewStore() kv.Store {
 	return &fakeStore{
 		store: make(map[string][]kv.Value),
@@ -50,10 +52,28 @@ func (f *fakeStore) Get(key string) (kv.Value, error) {
 	return value[len(value)-1], nil
 }
 
+func (f *fakeStore) GetMany(keys []string) (map[string]kv.Value, error) {
+	values := make(map[string]kv.Value, len(keys))
+	for _, key := range keys {
+		if value, ok := f.store[key]; ok {
+			values[key] = value[len(value)-1]
+		}
+	}
+	return values, nil
+}
+
 func (f *fakeStore) Watch(_ string) (kv.ValueWatch, error) {
 	panic("implement me")
 }
 
+func (f *fakeStore) WatchFromVersion(_ string, _ int) (kv.ValueWatch, error) {
+	panic("implement me")
+}
+
+func (f *fakeStore) WatchPrefix(_ string) (kv.PrefixWatch, error) {
+	panic("implement me")
+}
+
 func (f *fakeStore) Set(key string, v proto.Message) (int, error) {
 	oldVal, err := f.Get(key)
 	if err != nil && err != kv.ErrNotFound {
@@ -76,6 +96,10 @@ func (f *fakeStore) Set(key string, v proto.Message) (int, error) {
 	return newVer, nil
 }
 
+func (f *fakeStore) SetWithTTL(_ string, _ proto.Message, _ time.Duration) (int, error) {
+	panic("implement me")
+}
+
 func (f *fakeStore) SetIfNotExists(key string, v proto.Message) (int, error) {
 	_, err := f.Get(key)
 	if err == kv.ErrNotFound {
@@ -110,6 +134,20 @@ func (f *fakeStore) Delete(key string) (kv.Value, error) {
 	return val, nil
 }
 
+func (f *fakeStore) DeleteIfVersionMatches(key string, version int) (kv.Value, error) {
+	val, err := f.Get(key)
+	if err == kv.ErrNotFound && version != 0 {
+		return nil, kv.ErrVersionMismatch
+	} else if err != nil {
+		return nil, err
+	} else if val.Version() != version {
+		return nil, kv.ErrVersionMismatch
+	}
+	delete(f.store, key)
+
+	return val, nil
+}
+
 func (f *fakeStore) History(key string, from, to int) ([]kv.Value, error) {
 	if from > to || from < 0 || to < 0 {
 		return nil, errors.New("invalid history range")
@@ -130,6 +168,16 @@ func (f *fakeStore) History(key string, from, to int) ([]kv.Value, error) {
 	return vals[from:to], nil
 }
 
+func (f *fakeStore) ListKeys(prefix string, pageSize int, pageToken string) (kv.KeyPage, error) {
+	keys := make([]string, 0, len(f.store))
+	for key, vals := range f.store {
+		if len(vals) != 0 {
+			keys = append(keys, key)
+		}
+	}
+	return kv.NewKeyPage(keys, prefix, pageSize, pageToken)
+}
+
 type value struct {
 	Val []byte
 	Ver int64
@@ -146,6 +194,10 @@ func (c *value) IsNewer(other kv.Value) bool {
 	return c.Version() > other.Version()
 }
 
+func (c *value) IsStale() bool {
+	return false
+}
+
 func (c *value) Unmarshal(v proto.Message) error {
 	err := proto.Unmarshal(c.Val, v)
 	return err
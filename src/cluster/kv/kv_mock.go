@@ -26,6 +26,7 @@ package kv
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto"
@@ -68,6 +69,20 @@ func (mr *MockValueMockRecorder) IsNewer(other interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsNewer", reflect.TypeOf((*MockValue)(nil).IsNewer), other)
 }
 
+// IsStale mocks base method.
+func (m *MockValue) IsStale() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsStale")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsStale indicates an expected call of IsStale.
+func (mr *MockValueMockRecorder) IsStale() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsStale", reflect.TypeOf((*MockValue)(nil).IsStale))
+}
+
 // Unmarshal mocks base method.
 func (m *MockValue) Unmarshal(v proto.Message) error {
 	m.ctrl.T.Helper()
@@ -266,6 +281,176 @@ func (mr *MockValueWatchableMockRecorder) Watch() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockValueWatchable)(nil).Watch))
 }
 
+// MockPrefixWatch is a mock of PrefixWatch interface.
+type MockPrefixWatch struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrefixWatchMockRecorder
+}
+
+// MockPrefixWatchMockRecorder is the mock recorder for MockPrefixWatch.
+type MockPrefixWatchMockRecorder struct {
+	mock *MockPrefixWatch
+}
+
+// NewMockPrefixWatch creates a new mock instance.
+func NewMockPrefixWatch(ctrl *gomock.Controller) *MockPrefixWatch {
+	mock := &MockPrefixWatch{ctrl: ctrl}
+	mock.recorder = &MockPrefixWatchMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrefixWatch) EXPECT() *MockPrefixWatchMockRecorder {
+	return m.recorder
+}
+
+// C mocks base method.
+func (m *MockPrefixWatch) C() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "C")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// C indicates an expected call of C.
+func (mr *MockPrefixWatchMockRecorder) C() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "C", reflect.TypeOf((*MockPrefixWatch)(nil).C))
+}
+
+// Close mocks base method.
+func (m *MockPrefixWatch) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockPrefixWatchMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockPrefixWatch)(nil).Close))
+}
+
+// Get mocks base method.
+func (m *MockPrefixWatch) Get() map[string]Value {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get")
+	ret0, _ := ret[0].(map[string]Value)
+	return ret0
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPrefixWatchMockRecorder) Get() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPrefixWatch)(nil).Get))
+}
+
+// MockPrefixWatchable is a mock of PrefixWatchable interface.
+type MockPrefixWatchable struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrefixWatchableMockRecorder
+}
+
+// MockPrefixWatchableMockRecorder is the mock recorder for MockPrefixWatchable.
+type MockPrefixWatchableMockRecorder struct {
+	mock *MockPrefixWatchable
+}
+
+// NewMockPrefixWatchable creates a new mock instance.
+func NewMockPrefixWatchable(ctrl *gomock.Controller) *MockPrefixWatchable {
+	mock := &MockPrefixWatchable{ctrl: ctrl}
+	mock.recorder = &MockPrefixWatchableMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrefixWatchable) EXPECT() *MockPrefixWatchableMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockPrefixWatchable) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockPrefixWatchableMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockPrefixWatchable)(nil).Close))
+}
+
+// Get mocks base method.
+func (m *MockPrefixWatchable) Get() map[string]Value {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get")
+	ret0, _ := ret[0].(map[string]Value)
+	return ret0
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPrefixWatchableMockRecorder) Get() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPrefixWatchable)(nil).Get))
+}
+
+// IsClosed mocks base method.
+func (m *MockPrefixWatchable) IsClosed() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsClosed")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsClosed indicates an expected call of IsClosed.
+func (mr *MockPrefixWatchableMockRecorder) IsClosed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsClosed", reflect.TypeOf((*MockPrefixWatchable)(nil).IsClosed))
+}
+
+// NumWatches mocks base method.
+func (m *MockPrefixWatchable) NumWatches() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NumWatches")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// NumWatches indicates an expected call of NumWatches.
+func (mr *MockPrefixWatchableMockRecorder) NumWatches() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NumWatches", reflect.TypeOf((*MockPrefixWatchable)(nil).NumWatches))
+}
+
+// Update mocks base method.
+func (m *MockPrefixWatchable) Update(arg0 map[string]Value) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPrefixWatchableMockRecorder) Update(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPrefixWatchable)(nil).Update), arg0)
+}
+
+// Watch mocks base method.
+func (m *MockPrefixWatchable) Watch() (map[string]Value, PrefixWatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch")
+	ret0, _ := ret[0].(map[string]Value)
+	ret1, _ := ret[1].(PrefixWatch)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockPrefixWatchableMockRecorder) Watch() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockPrefixWatchable)(nil).Watch))
+}
+
 // MockOverrideOptions is a mock of OverrideOptions interface.
 type MockOverrideOptions struct {
 	ctrl     *gomock.Controller
@@ -440,6 +625,21 @@ func (mr *MockStoreMockRecorder) Delete(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStore)(nil).Delete), key)
 }
 
+// DeleteIfVersionMatches mocks base method.
+func (m *MockStore) DeleteIfVersionMatches(key string, version int) (Value, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIfVersionMatches", key, version)
+	ret0, _ := ret[0].(Value)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteIfVersionMatches indicates an expected call of DeleteIfVersionMatches.
+func (mr *MockStoreMockRecorder) DeleteIfVersionMatches(key, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIfVersionMatches", reflect.TypeOf((*MockStore)(nil).DeleteIfVersionMatches), key, version)
+}
+
 // Get mocks base method.
 func (m *MockStore) Get(key string) (Value, error) {
 	m.ctrl.T.Helper()
@@ -455,6 +655,21 @@ func (mr *MockStoreMockRecorder) Get(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStore)(nil).Get), key)
 }
 
+// GetMany mocks base method.
+func (m *MockStore) GetMany(keys []string) (map[string]Value, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMany", keys)
+	ret0, _ := ret[0].(map[string]Value)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMany indicates an expected call of GetMany.
+func (mr *MockStoreMockRecorder) GetMany(keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMany", reflect.TypeOf((*MockStore)(nil).GetMany), keys)
+}
+
 // History mocks base method.
 func (m *MockStore) History(key string, from, to int) ([]Value, error) {
 	m.ctrl.T.Helper()
@@ -470,6 +685,21 @@ func (mr *MockStoreMockRecorder) History(key, from, to interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockStore)(nil).History), key, from, to)
 }
 
+// ListKeys mocks base method.
+func (m *MockStore) ListKeys(prefix string, pageSize int, pageToken string) (KeyPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", prefix, pageSize, pageToken)
+	ret0, _ := ret[0].(KeyPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *MockStoreMockRecorder) ListKeys(prefix, pageSize, pageToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*MockStore)(nil).ListKeys), prefix, pageSize, pageToken)
+}
+
 // Set mocks base method.
 func (m *MockStore) Set(key string, v proto.Message) (int, error) {
 	m.ctrl.T.Helper()
@@ -500,6 +730,21 @@ func (mr *MockStoreMockRecorder) SetIfNotExists(key, v interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIfNotExists", reflect.TypeOf((*MockStore)(nil).SetIfNotExists), key, v)
 }
 
+// SetWithTTL mocks base method.
+func (m *MockStore) SetWithTTL(key string, v proto.Message, ttl time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWithTTL", key, v, ttl)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWithTTL indicates an expected call of SetWithTTL.
+func (mr *MockStoreMockRecorder) SetWithTTL(key, v, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWithTTL", reflect.TypeOf((*MockStore)(nil).SetWithTTL), key, v, ttl)
+}
+
 // Watch mocks base method.
 func (m *MockStore) Watch(key string) (ValueWatch, error) {
 	m.ctrl.T.Helper()
@@ -515,6 +760,36 @@ func (mr *MockStoreMockRecorder) Watch(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockStore)(nil).Watch), key)
 }
 
+// WatchFromVersion mocks base method.
+func (m *MockStore) WatchFromVersion(key string, version int) (ValueWatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchFromVersion", key, version)
+	ret0, _ := ret[0].(ValueWatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchFromVersion indicates an expected call of WatchFromVersion.
+func (mr *MockStoreMockRecorder) WatchFromVersion(key, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchFromVersion", reflect.TypeOf((*MockStore)(nil).WatchFromVersion), key, version)
+}
+
+// WatchPrefix mocks base method.
+func (m *MockStore) WatchPrefix(prefix string) (PrefixWatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchPrefix", prefix)
+	ret0, _ := ret[0].(PrefixWatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchPrefix indicates an expected call of WatchPrefix.
+func (mr *MockStoreMockRecorder) WatchPrefix(prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchPrefix", reflect.TypeOf((*MockStore)(nil).WatchPrefix), prefix)
+}
+
 // MockCondition is a mock of Condition interface.
 type MockCondition struct {
 	ctrl     *gomock.Controller
@@ -955,6 +1230,21 @@ func (mr *MockTxnStoreMockRecorder) Delete(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTxnStore)(nil).Delete), key)
 }
 
+// DeleteIfVersionMatches mocks base method.
+func (m *MockTxnStore) DeleteIfVersionMatches(key string, version int) (Value, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIfVersionMatches", key, version)
+	ret0, _ := ret[0].(Value)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteIfVersionMatches indicates an expected call of DeleteIfVersionMatches.
+func (mr *MockTxnStoreMockRecorder) DeleteIfVersionMatches(key, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIfVersionMatches", reflect.TypeOf((*MockTxnStore)(nil).DeleteIfVersionMatches), key, version)
+}
+
 // Get mocks base method.
 func (m *MockTxnStore) Get(key string) (Value, error) {
 	m.ctrl.T.Helper()
@@ -970,6 +1260,21 @@ func (mr *MockTxnStoreMockRecorder) Get(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTxnStore)(nil).Get), key)
 }
 
+// GetMany mocks base method.
+func (m *MockTxnStore) GetMany(keys []string) (map[string]Value, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMany", keys)
+	ret0, _ := ret[0].(map[string]Value)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMany indicates an expected call of GetMany.
+func (mr *MockTxnStoreMockRecorder) GetMany(keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMany", reflect.TypeOf((*MockTxnStore)(nil).GetMany), keys)
+}
+
 // History mocks base method.
 func (m *MockTxnStore) History(key string, from, to int) ([]Value, error) {
 	m.ctrl.T.Helper()
@@ -985,6 +1290,21 @@ func (mr *MockTxnStoreMockRecorder) History(key, from, to interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockTxnStore)(nil).History), key, from, to)
 }
 
+// ListKeys mocks base method.
+func (m *MockTxnStore) ListKeys(prefix string, pageSize int, pageToken string) (KeyPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", prefix, pageSize, pageToken)
+	ret0, _ := ret[0].(KeyPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *MockTxnStoreMockRecorder) ListKeys(prefix, pageSize, pageToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*MockTxnStore)(nil).ListKeys), prefix, pageSize, pageToken)
+}
+
 // Set mocks base method.
 func (m *MockTxnStore) Set(key string, v proto.Message) (int, error) {
 	m.ctrl.T.Helper()
@@ -1015,6 +1335,21 @@ func (mr *MockTxnStoreMockRecorder) SetIfNotExists(key, v interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIfNotExists", reflect.TypeOf((*MockTxnStore)(nil).SetIfNotExists), key, v)
 }
 
+// SetWithTTL mocks base method.
+func (m *MockTxnStore) SetWithTTL(key string, v proto.Message, ttl time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWithTTL", key, v, ttl)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWithTTL indicates an expected call of SetWithTTL.
+func (mr *MockTxnStoreMockRecorder) SetWithTTL(key, v, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWithTTL", reflect.TypeOf((*MockTxnStore)(nil).SetWithTTL), key, v, ttl)
+}
+
 // Watch mocks base method.
 func (m *MockTxnStore) Watch(key string) (ValueWatch, error) {
 	m.ctrl.T.Helper()
@@ -1029,3 +1364,104 @@ func (mr *MockTxnStoreMockRecorder) Watch(key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockTxnStore)(nil).Watch), key)
 }
+
+// WatchFromVersion mocks base method.
+func (m *MockTxnStore) WatchFromVersion(key string, version int) (ValueWatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchFromVersion", key, version)
+	ret0, _ := ret[0].(ValueWatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchFromVersion indicates an expected call of WatchFromVersion.
+func (mr *MockTxnStoreMockRecorder) WatchFromVersion(key, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchFromVersion", reflect.TypeOf((*MockTxnStore)(nil).WatchFromVersion), key, version)
+}
+
+// WatchPrefix mocks base method.
+func (m *MockTxnStore) WatchPrefix(prefix string) (PrefixWatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchPrefix", prefix)
+	ret0, _ := ret[0].(PrefixWatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchPrefix indicates an expected call of WatchPrefix.
+func (mr *MockTxnStoreMockRecorder) WatchPrefix(prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchPrefix", reflect.TypeOf((*MockTxnStore)(nil).WatchPrefix), prefix)
+}
+
+// MockPreviousValueStore is a mock of PreviousValueStore interface.
+type MockPreviousValueStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreviousValueStoreMockRecorder
+}
+
+// MockPreviousValueStoreMockRecorder is the mock recorder for MockPreviousValueStore.
+type MockPreviousValueStoreMockRecorder struct {
+	mock *MockPreviousValueStore
+}
+
+// NewMockPreviousValueStore creates a new mock instance.
+func NewMockPreviousValueStore(ctrl *gomock.Controller) *MockPreviousValueStore {
+	mock := &MockPreviousValueStore{ctrl: ctrl}
+	mock.recorder = &MockPreviousValueStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPreviousValueStore) EXPECT() *MockPreviousValueStoreMockRecorder {
+	return m.recorder
+}
+
+// CheckAndSetReturningPrevious mocks base method.
+func (m *MockPreviousValueStore) CheckAndSetReturningPrevious(key string, version int, v proto.Message) (int, Value, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckAndSetReturningPrevious", key, version, v)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(Value)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CheckAndSetReturningPrevious indicates an expected call of CheckAndSetReturningPrevious.
+func (mr *MockPreviousValueStoreMockRecorder) CheckAndSetReturningPrevious(key, version, v interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAndSetReturningPrevious", reflect.TypeOf((*MockPreviousValueStore)(nil).CheckAndSetReturningPrevious), key, version, v)
+}
+
+// SetReturningPrevious mocks base method.
+func (m *MockPreviousValueStore) SetReturningPrevious(key string, v proto.Message) (int, Value, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReturningPrevious", key, v)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(Value)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SetReturningPrevious indicates an expected call of SetReturningPrevious.
+func (mr *MockPreviousValueStoreMockRecorder) SetReturningPrevious(key, v interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReturningPrevious", reflect.TypeOf((*MockPreviousValueStore)(nil).SetReturningPrevious), key, v)
+}
+
+// SetWithTTLReturningPrevious mocks base method.
+func (m *MockPreviousValueStore) SetWithTTLReturningPrevious(key string, v proto.Message, ttl time.Duration) (int, Value, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWithTTLReturningPrevious", key, v, ttl)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(Value)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SetWithTTLReturningPrevious indicates an expected call of SetWithTTLReturningPrevious.
+func (mr *MockPreviousValueStoreMockRecorder) SetWithTTLReturningPrevious(key, v, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWithTTLReturningPrevious", reflect.TypeOf((*MockPreviousValueStore)(nil).SetWithTTLReturningPrevious), key, v, ttl)
+}
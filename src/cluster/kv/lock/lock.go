@@ -0,0 +1,235 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package lock provides leased locks backed by a kv.Store that can be used to
+// serialize operations across processes.
+//
+// A lock is a key holding a kvpb.Lease that names its owner and when the lease
+// expires. Locks are never deleted from the store, releasing a lock writes an
+// empty lease instead, so that the version of the key increases monotonically
+// and can be handed out as a fencing token: a holder with a lower token than
+// one already seen by a protected resource has lost the lock and its writes
+// should be rejected.
+//
+// Lease expiry is evaluated against the local clock of whoever attempts to
+// acquire the lock, so ttls should be large relative to expected clock skew.
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/kvpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/x/clock"
+)
+
+var (
+	// ErrLockHeld is returned when acquiring a lock whose lease is held by
+	// another owner and has not expired.
+	ErrLockHeld = errors.New("lock is held by another owner")
+
+	// ErrLockLost is returned when renewing or releasing a lock that has since
+	// been acquired by another owner.
+	ErrLockLost = errors.New("lock was acquired by another owner")
+
+	// ErrLockReleased is returned when renewing or releasing a lock that has
+	// already been released.
+	ErrLockReleased = errors.New("lock has already been released")
+
+	errInvalidName = errors.New("lock name must not be empty")
+	errInvalidTTL  = errors.New("lock ttl must be positive")
+)
+
+// Locker acquires leased locks.
+type Locker interface {
+	// Acquire acquires the named lock for the given ttl, it returns
+	// ErrLockHeld without blocking if the lock is held by another owner.
+	Acquire(name string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is a lock held until it is released or its lease expires.
+type Lock interface {
+	// Name returns the name of the lock.
+	Name() string
+
+	// Token returns the fencing token of the lock, tokens strictly increase
+	// each time the lock is acquired.
+	Token() int64
+
+	// Expiry returns when the lease on the lock expires.
+	Expiry() time.Time
+
+	// Renew extends the lease on the lock by its ttl from now.
+	Renew() error
+
+	// Release releases the lock.
+	Release() error
+}
+
+type locker struct {
+	store kv.Store
+	opts  Options
+	nowFn clock.NowFn
+}
+
+// NewLocker creates a Locker that stores locks in the given store.
+func NewLocker(store kv.Store, opts Options) (Locker, error) {
+	if opts == nil {
+		opts = NewOptions()
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &locker{
+		store: store,
+		opts:  opts,
+		nowFn: opts.ClockOptions().NowFn(),
+	}, nil
+}
+
+func (l *locker) Acquire(name string, ttl time.Duration) (Lock, error) {
+	if name == "" {
+		return nil, errInvalidName
+	}
+	if ttl <= 0 {
+		return nil, errInvalidTTL
+	}
+
+	var (
+		key    = l.opts.KeyPrefix() + name
+		expiry = l.nowFn().Add(ttl)
+		lease  = &kvpb.Lease{Owner: l.opts.Owner(), ExpiresAtNanos: expiry.UnixNano()}
+	)
+
+	version, err := l.set(key, lease)
+	if err == kv.ErrAlreadyExists || err == kv.ErrVersionMismatch {
+		// Lost the race against another owner acquiring the lock.
+		return nil, ErrLockHeld
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &lock{
+		locker:  l,
+		name:    name,
+		key:     key,
+		ttl:     ttl,
+		token:   int64(version),
+		version: version,
+		expiry:  expiry,
+	}, nil
+}
+
+// set writes the lease if the lock is free, returning the new version of the key.
+func (l *locker) set(key string, lease *kvpb.Lease) (int, error) {
+	value, err := l.store.Get(key)
+	if err == kv.ErrNotFound {
+		return l.store.SetIfNotExists(key, lease)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var existing kvpb.Lease
+	if err := value.Unmarshal(&existing); err != nil {
+		return 0, fmt.Errorf("unable to unmarshal lease for %s: %v", key, err)
+	}
+	if existing.Owner != "" && l.nowFn().UnixNano() < existing.ExpiresAtNanos {
+		return 0, ErrLockHeld
+	}
+
+	return l.store.CheckAndSet(key, value.Version(), lease)
+}
+
+type lock struct {
+	sync.Mutex
+
+	locker   *locker
+	name     string
+	key      string
+	ttl      time.Duration
+	token    int64
+	version  int
+	expiry   time.Time
+	released bool
+}
+
+func (l *lock) Name() string {
+	return l.name
+}
+
+func (l *lock) Token() int64 {
+	return l.token
+}
+
+func (l *lock) Expiry() time.Time {
+	l.Lock()
+	expiry := l.expiry
+	l.Unlock()
+	return expiry
+}
+
+func (l *lock) Renew() error {
+	l.Lock()
+	defer l.Unlock()
+
+	expiry := l.locker.nowFn().Add(l.ttl)
+	lease := &kvpb.Lease{Owner: l.locker.opts.Owner(), ExpiresAtNanos: expiry.UnixNano()}
+	if err := l.checkAndSetWithLock(lease); err != nil {
+		return err
+	}
+
+	l.expiry = expiry
+	return nil
+}
+
+func (l *lock) Release() error {
+	l.Lock()
+	defer l.Unlock()
+
+	if err := l.checkAndSetWithLock(&kvpb.Lease{}); err != nil {
+		return err
+	}
+
+	l.released = true
+	return nil
+}
+
+func (l *lock) checkAndSetWithLock(lease *kvpb.Lease) error {
+	if l.released {
+		return ErrLockReleased
+	}
+
+	version, err := l.locker.store.CheckAndSet(l.key, l.version, lease)
+	if err == kv.ErrVersionMismatch || err == kv.ErrNotFound {
+		return ErrLockLost
+	}
+	if err != nil {
+		return err
+	}
+
+	l.version = version
+	return nil
+}
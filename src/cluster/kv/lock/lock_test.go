@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocker(t *testing.T, store kv.Store, owner string, now *time.Time) Locker {
+	clockOpts := clock.NewOptions().SetNowFn(func() time.Time { return *now })
+	l, err := NewLocker(store, NewOptions().SetOwner(owner).SetClockOptions(clockOpts))
+	require.NoError(t, err)
+	return l
+}
+
+func TestAcquireRelease(t *testing.T) {
+	var (
+		store = mem.NewStore()
+		now   = time.Unix(1000, 0)
+		a     = newTestLocker(t, store, "a", &now)
+		b     = newTestLocker(t, store, "b", &now)
+	)
+
+	la, err := a.Acquire("ns-delete", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "ns-delete", la.Name())
+	require.Equal(t, now.Add(time.Minute), la.Expiry())
+
+	_, err = b.Acquire("ns-delete", time.Minute)
+	require.Equal(t, ErrLockHeld, err)
+
+	// Other locks are independent.
+	_, err = b.Acquire("other", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, la.Release())
+	require.Equal(t, ErrLockReleased, la.Release())
+	require.Equal(t, ErrLockReleased, la.Renew())
+
+	lb, err := b.Acquire("ns-delete", time.Minute)
+	require.NoError(t, err)
+	require.True(t, lb.Token() > la.Token())
+}
+
+func TestAcquireExpired(t *testing.T) {
+	var (
+		store = mem.NewStore()
+		now   = time.Unix(1000, 0)
+		a     = newTestLocker(t, store, "a", &now)
+		b     = newTestLocker(t, store, "b", &now)
+	)
+
+	la, err := a.Acquire("ns-delete", time.Minute)
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Second)
+	require.NoError(t, la.Renew())
+	require.Equal(t, now.Add(time.Minute), la.Expiry())
+
+	now = now.Add(59 * time.Second)
+	_, err = b.Acquire("ns-delete", time.Minute)
+	require.Equal(t, ErrLockHeld, err)
+
+	now = now.Add(time.Second)
+	lb, err := b.Acquire("ns-delete", time.Minute)
+	require.NoError(t, err)
+	require.True(t, lb.Token() > la.Token())
+
+	require.Equal(t, ErrLockLost, la.Renew())
+	require.Equal(t, ErrLockLost, la.Release())
+	require.NoError(t, lb.Release())
+}
+
+func TestAcquireInvalid(t *testing.T) {
+	l, err := NewLocker(mem.NewStore(), nil)
+	require.NoError(t, err)
+
+	_, err = l.Acquire("", time.Minute)
+	require.Error(t, err)
+
+	_, err = l.Acquire("ns-delete", 0)
+	require.Error(t, err)
+
+	_, err = NewLocker(mem.NewStore(), NewOptions().SetOwner(""))
+	require.Error(t, err)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/m3db/m3/src/x/clock"
+)
+
+const (
+	defaultKeyPrefix = "_lock/"
+)
+
+var (
+	errNoOwner        = errors.New("lock options must specify an owner")
+	errNoClockOptions = errors.New("lock options must specify clock options")
+)
+
+// Options are options for a Locker.
+type Options interface {
+	// Owner identifies the holder of the locks acquired by the Locker.
+	Owner() string
+	// SetOwner sets the Owner.
+	SetOwner(value string) Options
+
+	// KeyPrefix is the prefix prepended to the name of a lock to form its key.
+	KeyPrefix() string
+	// SetKeyPrefix sets the KeyPrefix.
+	SetKeyPrefix(value string) Options
+
+	// ClockOptions is the clock options used to compute lease expiry.
+	ClockOptions() clock.Options
+	// SetClockOptions sets the ClockOptions.
+	SetClockOptions(value clock.Options) Options
+
+	// Validate validates the Options.
+	Validate() error
+}
+
+type options struct {
+	owner     string
+	keyPrefix string
+	clockOpts clock.Options
+}
+
+// NewOptions returns the default Options, the owner defaults to the hostname
+// and pid of the current process.
+func NewOptions() Options {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return options{
+		owner:     fmt.Sprintf("%s-%d", host, os.Getpid()),
+		keyPrefix: defaultKeyPrefix,
+		clockOpts: clock.NewOptions(),
+	}
+}
+
+func (o options) Owner() string {
+	return o.owner
+}
+
+func (o options) SetOwner(value string) Options {
+	o.owner = value
+	return o
+}
+
+func (o options) KeyPrefix() string {
+	return o.keyPrefix
+}
+
+func (o options) SetKeyPrefix(value string) Options {
+	o.keyPrefix = value
+	return o
+}
+
+func (o options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o options) SetClockOptions(value clock.Options) Options {
+	o.clockOpts = value
+	return o
+}
+
+func (o options) Validate() error {
+	if o.owner == "" {
+		return errNoOwner
+	}
+	if o.clockOpts == nil {
+		return errNoClockOptions
+	}
+	return nil
+}
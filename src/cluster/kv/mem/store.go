@@ -22,7 +22,9 @@ package mem
 
 import (
 	"errors"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/m3db/m3/src/cluster/kv"
 
@@ -32,8 +34,10 @@ import (
 // NewStore returns a new in-process store that can be used for testing
 func NewStore() kv.TxnStore {
 	return &store{
-		values:     make(map[string][]*value),
-		watchables: make(map[string]kv.ValueWatchable),
+		values:           make(map[string][]*value),
+		watchables:       make(map[string]kv.ValueWatchable),
+		prefixWatchables: make(map[string]kv.PrefixWatchable),
+		expirations:      make(map[string]*time.Timer),
 	}
 }
 
@@ -62,6 +66,7 @@ type value struct {
 
 func (v value) Version() int                      { return v.version }
 func (v value) Unmarshal(msg proto.Message) error { return proto.Unmarshal(v.data, msg) }
+func (v value) IsStale() bool                     { return false }
 func (v value) IsNewer(other kv.Value) bool {
 	otherValue, ok := other.(*value)
 	if !ok {
@@ -76,9 +81,11 @@ func (v value) IsNewer(other kv.Value) bool {
 type store struct {
 	sync.RWMutex
 
-	revision   int
-	values     map[string][]*value
-	watchables map[string]kv.ValueWatchable
+	revision         int
+	values           map[string][]*value
+	watchables       map[string]kv.ValueWatchable
+	prefixWatchables map[string]kv.PrefixWatchable
+	expirations      map[string]*time.Timer
 }
 
 // IsMem lets asserting if given store is an in memory one.
@@ -94,6 +101,24 @@ func (s *store) Get(key string) (kv.Value, error) {
 	return s.getWithLock(key)
 }
 
+func (s *store) GetMany(keys []string) (map[string]kv.Value, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	values := make(map[string]kv.Value, len(keys))
+	for _, key := range keys {
+		val, err := s.getWithLock(key)
+		if err == kv.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = val
+	}
+	return values, nil
+}
+
 func (s *store) getWithLock(key string) (kv.Value, error) {
 	val, ok := s.values[key]
 	if !ok {
@@ -126,6 +151,35 @@ func (s *store) Watch(key string) (kv.ValueWatch, error) {
 	return watch, nil
 }
 
+func (s *store) WatchFromVersion(key string, version int) (kv.ValueWatch, error) {
+	w, err := s.Watch(key)
+	if err != nil {
+		return nil, err
+	}
+	return kv.NewValueWatchFromVersion(w, version), nil
+}
+
+func (s *store) WatchPrefix(prefix string) (kv.PrefixWatch, error) {
+	s.Lock()
+	watchable, ok := s.prefixWatchables[prefix]
+	if !ok {
+		watchable = kv.NewPrefixWatchable()
+		s.prefixWatchables[prefix] = watchable
+
+		values := make(map[string]kv.Value)
+		for key, val := range s.values {
+			if strings.HasPrefix(key, prefix) && len(val) != 0 {
+				values[key] = val[len(val)-1]
+			}
+		}
+		watchable.Update(values)
+	}
+	s.Unlock()
+
+	_, watch, _ := watchable.Watch()
+	return watch, nil
+}
+
 func (s *store) Set(key string, val proto.Message) (int, error) {
 	s.Lock()
 	defer s.Unlock()
@@ -150,6 +204,50 @@ func (s *store) setWithLock(key string, val proto.Message) (int, error) {
 	return newVersion, nil
 }
 
+func (s *store) SetWithTTL(key string, val proto.Message, ttl time.Duration) (int, error) {
+	if ttl <= 0 {
+		return 0, kv.ErrInvalidTTL
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	version, err := s.setWithLock(key, val)
+	if err != nil {
+		return 0, err
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		s.expire(key, timer)
+	})
+	s.expirations[key] = timer
+	return version, nil
+}
+
+func (s *store) expire(key string, timer *time.Timer) {
+	s.Lock()
+	defer s.Unlock()
+
+	// NB: the key may have been written since the timer fired.
+	if s.expirations[key] != timer {
+		return
+	}
+
+	delete(s.expirations, key)
+	s.updateWatchable(key, nil)
+	delete(s.values, key)
+}
+
+// stopExpiryWithLock stops the key from expiring, as writing a key without a
+// ttl does.
+func (s *store) stopExpiryWithLock(key string) {
+	if timer, ok := s.expirations[key]; ok {
+		timer.Stop()
+		delete(s.expirations, key)
+	}
+}
+
 func (s *store) SetIfNotExists(key string, val proto.Message) (int, error) {
 	data, err := proto.Marshal(val)
 	if err != nil {
@@ -192,6 +290,7 @@ func (s *store) CheckAndSet(key string, version int, val proto.Message) (int, er
 }
 
 func (s *store) updateInternalWithLock(key string, newVersion int, data []byte) {
+	s.stopExpiryWithLock(key)
 	s.revision++
 	fv := &value{
 		version:  newVersion,
@@ -212,6 +311,30 @@ func (s *store) Delete(key string) (kv.Value, error) {
 	}
 
 	prev := val[len(val)-1]
+	s.stopExpiryWithLock(key)
+	s.updateWatchable(key, nil)
+	delete(s.values, key)
+	return prev, nil
+}
+
+func (s *store) DeleteIfVersionMatches(key string, version int) (kv.Value, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	val, ok := s.values[key]
+	if !ok {
+		if version != 0 {
+			return nil, kv.ErrVersionMismatch
+		}
+		return nil, kv.ErrNotFound
+	}
+
+	prev := val[len(val)-1]
+	if prev.version != version {
+		return nil, kv.ErrVersionMismatch
+	}
+
+	s.stopExpiryWithLock(key)
 	s.updateWatchable(key, nil)
 	delete(s.values, key)
 	return prev, nil
@@ -250,29 +373,45 @@ func (s *store) History(key string, from, to int) ([]kv.Value, error) {
 	return res, nil
 }
 
+func (s *store) ListKeys(prefix string, pageSize int, pageToken string) (kv.KeyPage, error) {
+	s.RLock()
+	keys := make([]string, 0, len(s.values))
+	for key, vals := range s.values {
+		if len(vals) != 0 {
+			keys = append(keys, key)
+		}
+	}
+	s.RUnlock()
+
+	return kv.NewKeyPage(keys, prefix, pageSize, pageToken)
+}
+
 // NB(cw) When there is an error in one of the ops, the finished ops will not be rolled back
 func (s *store) Commit(conditions []kv.Condition, ops []kv.Op) (kv.Response, error) {
 	s.Lock()
 	defer s.Unlock()
 
+	var failures []kv.ConditionFailure
 	for _, condition := range conditions {
 		if condition.CompareType() != kv.CompareEqual || condition.TargetType() != kv.TargetVersion {
 			return nil, errors.New("invalid condition")
 		}
 
-		v, err := s.getWithLock(condition.Key())
-		expectedVersion := condition.Value().(int)
-		if err != nil {
-			if err == kv.ErrNotFound && expectedVersion == 0 {
-				continue
-			}
-			return nil, err
+		var actualVersion int
+		if v, err := s.getWithLock(condition.Key()); err == nil {
+			actualVersion = v.Version()
 		}
 
-		if expectedVersion != v.Version() {
-			return nil, kv.ErrConditionCheckFailed
+		if !kv.ConditionHolds(condition, actualVersion) {
+			failures = append(failures, kv.ConditionFailure{
+				Condition:     condition,
+				ActualVersion: actualVersion,
+			})
 		}
 	}
+	if len(failures) > 0 {
+		return nil, kv.NewConditionCheckFailedError(failures)
+	}
 
 	oprs := make([]kv.OpResponse, len(ops))
 	for i, op := range ops {
@@ -298,4 +437,23 @@ func (s *store) updateWatchable(key string, newVal kv.Value) {
 	if watchable, ok := s.watchables[key]; ok {
 		watchable.Update(newVal)
 	}
+
+	for prefix, watchable := range s.prefixWatchables {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		// NB: the values are shared with watches, so update a copy.
+		curValues := watchable.Get()
+		values := make(map[string]kv.Value, len(curValues)+1)
+		for k, v := range curValues {
+			values[k] = v
+		}
+		if newVal == nil {
+			delete(values, key)
+		} else {
+			values[key] = newVal
+		}
+		watchable.Update(values)
+	}
 }
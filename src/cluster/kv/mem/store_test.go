@@ -21,8 +21,10 @@
 package mem
 
 import (
+	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/m3db/m3/src/cluster/generated/proto/kvtest"
 	"github.com/m3db/m3/src/cluster/kv"
@@ -125,6 +127,44 @@ func TestStore(t *testing.T) {
 	require.Equal(t, "update3", read.Msg)
 }
 
+func TestStoreGetMany(t *testing.T) {
+	s := NewStore()
+
+	_, err := s.Set("foo", &kvtest.Foo{Msg: "first"})
+	require.NoError(t, err)
+	_, err = s.Set("bar", &kvtest.Foo{Msg: "second"})
+	require.NoError(t, err)
+
+	values, err := s.GetMany([]string{"foo", "bar", "baz"})
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+
+	var read kvtest.Foo
+	require.NoError(t, values["foo"].Unmarshal(&read))
+	require.Equal(t, "first", read.Msg)
+	require.NoError(t, values["bar"].Unmarshal(&read))
+	require.Equal(t, "second", read.Msg)
+}
+
+func TestStoreListKeys(t *testing.T) {
+	s := NewStore()
+
+	for _, key := range []string{"ns/b", "ns/a", "ns/c", "other"} {
+		_, err := s.Set(key, &kvtest.Foo{Msg: key})
+		require.NoError(t, err)
+	}
+	_, err := s.Delete("ns/c")
+	require.NoError(t, err)
+
+	page, err := s.ListKeys("ns/", 1, "")
+	require.NoError(t, err)
+	require.Equal(t, kv.KeyPage{Keys: []string{"ns/a"}, NextPageToken: "ns/a"}, page)
+
+	page, err = s.ListKeys("ns/", 1, page.NextPageToken)
+	require.NoError(t, err)
+	require.Equal(t, kv.KeyPage{Keys: []string{"ns/b"}}, page)
+}
+
 func TestStoreWatch(t *testing.T) {
 	s := NewStore()
 
@@ -186,6 +226,109 @@ func TestStoreWatch(t *testing.T) {
 	require.Equal(t, "third", foo.Msg)
 }
 
+func TestStoreWatchFromVersion(t *testing.T) {
+	s := NewStore()
+
+	_, err := s.Set("foo", &kvtest.Foo{Msg: "first"})
+	require.NoError(t, err)
+
+	w, err := s.WatchFromVersion("foo", 1)
+	require.NoError(t, err)
+
+	// the current version has already been processed.
+	select {
+	case <-w.C():
+		require.FailNow(t, "unexpected notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+	require.Nil(t, w.Get())
+
+	_, err = s.Set("foo", &kvtest.Foo{Msg: "second"})
+	require.NoError(t, err)
+
+	<-w.C()
+	var foo kvtest.Foo
+	require.NoError(t, w.Get().Unmarshal(&foo))
+	require.Equal(t, "second", foo.Msg)
+	require.Equal(t, 2, w.Get().Version())
+
+	w.Close()
+	_, ok := <-w.C()
+	require.False(t, ok)
+
+	// a watch from an older version is notified of the latest value.
+	w, err = s.WatchFromVersion("foo", 0)
+	require.NoError(t, err)
+	<-w.C()
+	require.Equal(t, 2, w.Get().Version())
+	w.Close()
+}
+
+func TestStoreWatchPrefix(t *testing.T) {
+	s := NewStore()
+
+	_, err := s.Set("foo/a", &kvtest.Foo{Msg: "a1"})
+	require.NoError(t, err)
+
+	w, err := s.WatchPrefix("foo/")
+	require.NoError(t, err)
+	<-w.C()
+	require.Len(t, w.Get(), 1)
+
+	_, err = s.Set("foo/b", &kvtest.Foo{Msg: "b1"})
+	require.NoError(t, err)
+	_, err = s.Set("bar/c", &kvtest.Foo{Msg: "c1"})
+	require.NoError(t, err)
+
+	<-w.C()
+	values := w.Get()
+	require.Len(t, values, 2)
+	var foo kvtest.Foo
+	require.NoError(t, values["foo/b"].Unmarshal(&foo))
+	require.Equal(t, "b1", foo.Msg)
+
+	_, err = s.Delete("foo/a")
+	require.NoError(t, err)
+
+	<-w.C()
+	values = w.Get()
+	require.Len(t, values, 1)
+	require.Contains(t, values, "foo/b")
+
+	w.Close()
+}
+
+func TestStoreSetWithTTL(t *testing.T) {
+	s := NewStore()
+
+	_, err := s.SetWithTTL("foo", &kvtest.Foo{Msg: "bar"}, 0)
+	require.Equal(t, kv.ErrInvalidTTL, err)
+
+	// Writing the key without a ttl stops it from expiring.
+	_, err = s.SetWithTTL("bar", &kvtest.Foo{Msg: "bar1"}, 10*time.Millisecond)
+	require.NoError(t, err)
+	_, err = s.Set("bar", &kvtest.Foo{Msg: "bar2"})
+	require.NoError(t, err)
+
+	w, err := s.Watch("foo")
+	require.NoError(t, err)
+
+	_, err = s.SetWithTTL("foo", &kvtest.Foo{Msg: "bar"}, 50*time.Millisecond)
+	require.NoError(t, err)
+	<-w.C()
+	require.Equal(t, 1, w.Get().Version())
+
+	<-w.C()
+	require.Nil(t, w.Get())
+
+	_, err = s.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	v, err := s.Get("bar")
+	require.NoError(t, err)
+	require.Equal(t, 2, v.Version())
+}
+
 func TestFakeStoreErrors(t *testing.T) {
 	s := NewStore()
 
@@ -282,6 +425,37 @@ func TestDelete(t *testing.T) {
 	require.True(t, newValue.IsNewer(v))
 }
 
+func TestDeleteIfVersionMatches(t *testing.T) {
+	s := NewStore()
+
+	_, err := s.DeleteIfVersionMatches("foo", 0)
+	require.Equal(t, kv.ErrNotFound, err)
+
+	_, err = s.DeleteIfVersionMatches("foo", 1)
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	_, err = s.Set("foo", &kvtest.Foo{Msg: "bar1"})
+	require.NoError(t, err)
+
+	w, err := s.Watch("foo")
+	require.NoError(t, err)
+	<-w.C()
+	require.Equal(t, 1, w.Get().Version())
+
+	_, err = s.DeleteIfVersionMatches("foo", 2)
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	val, err := s.DeleteIfVersionMatches("foo", 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, val.Version())
+
+	<-w.C()
+	require.Nil(t, w.Get())
+
+	_, err = s.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+}
+
 func TestTxn(t *testing.T) {
 	store := NewStore()
 
@@ -331,5 +505,11 @@ func TestTxn(t *testing.T) {
 		},
 	)
 	require.Error(t, err)
-	require.Equal(t, kv.ErrConditionCheckFailed, err)
+	require.True(t, errors.Is(err, kv.ErrConditionCheckFailed))
+
+	var conflictErr *kv.ConditionCheckFailedError
+	require.True(t, errors.As(err, &conflictErr))
+	require.Len(t, conflictErr.Failures, 1)
+	require.Equal(t, "key", conflictErr.Failures[0].Condition.Key())
+	require.Equal(t, 1, conflictErr.Failures[0].ActualVersion)
 }
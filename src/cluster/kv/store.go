@@ -22,6 +22,9 @@ package kv
 
 import (
 	"errors"
+	"sort"
+	"strings"
+	"sync"
 
 	xwatch "github.com/m3db/m3/src/x/watch"
 )
@@ -53,6 +56,112 @@ func (v *valueWatch) Get() Value {
 	return valueFromWatch(v.w.Get())
 }
 
+type versionValueWatch struct {
+	sync.RWMutex
+
+	w       ValueWatch
+	version int
+	c       chan struct{}
+	value   Value
+}
+
+// NewValueWatchFromVersion returns a ValueWatch which is only notified once
+// the value watched by w is no longer at the given version, after which it is
+// notified of every update of w. Closing it closes w.
+func NewValueWatchFromVersion(w ValueWatch, version int) ValueWatch {
+	vw := &versionValueWatch{
+		w:       w,
+		version: version,
+		c:       make(chan struct{}, 1),
+	}
+	go vw.run()
+	return vw
+}
+
+func (w *versionValueWatch) run() {
+	var caughtUp bool
+	for range w.w.C() {
+		v := w.w.Get()
+		if !caughtUp && !isPastVersion(v, w.version) {
+			continue
+		}
+		caughtUp = true
+
+		w.Lock()
+		w.value = v
+		w.Unlock()
+
+		select {
+		case w.c <- struct{}{}:
+		default:
+		}
+	}
+	close(w.c)
+}
+
+func (w *versionValueWatch) Close() {
+	w.w.Close()
+}
+
+func (w *versionValueWatch) C() <-chan struct{} {
+	return w.c
+}
+
+func (w *versionValueWatch) Get() Value {
+	w.RLock()
+	v := w.value
+	w.RUnlock()
+	return v
+}
+
+// isPastVersion returns whether the value has moved past the given version, a
+// nil value is a deletion of the key. A stale value at a lower version may be
+// served from a cache that is behind, it is not considered past the version.
+func isPastVersion(v Value, version int) bool {
+	if v == nil {
+		return true
+	}
+	if v.Version() == version {
+		return false
+	}
+	return v.Version() > version || !v.IsStale()
+}
+
+// ValidateListKeys validates the arguments of ListKeys, a page token is the
+// last key of the previous page so it must have the prefix.
+func ValidateListKeys(prefix string, pageSize int, pageToken string) error {
+	if pageSize <= 0 {
+		return ErrInvalidPageSize
+	}
+	if pageToken != "" && !strings.HasPrefix(pageToken, prefix) {
+		return ErrInvalidPageToken
+	}
+	return nil
+}
+
+// NewKeyPage returns the page listed by ListKeys out of all the keys of a
+// store, for stores which can not page through their keys. Keys without the
+// prefix are skipped.
+func NewKeyPage(keys []string, prefix string, pageSize int, pageToken string) (KeyPage, error) {
+	if err := ValidateListKeys(prefix, pageSize, pageToken); err != nil {
+		return KeyPage{}, err
+	}
+
+	var matched []string
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) && key > pageToken {
+			matched = append(matched, key)
+		}
+	}
+	sort.Strings(matched)
+
+	if len(matched) <= pageSize {
+		return KeyPage{Keys: matched}, nil
+	}
+	matched = matched[:pageSize]
+	return KeyPage{Keys: matched, NextPageToken: matched[pageSize-1]}, nil
+}
+
 type valueWatchable struct {
 	w xwatch.Watchable
 }
@@ -91,6 +200,73 @@ func (w *valueWatchable) Update(v Value) error {
 	return w.w.Update(v)
 }
 
+type prefixWatch struct {
+	w xwatch.Watch
+}
+
+// newPrefixWatch creates a new PrefixWatch
+func newPrefixWatch(w xwatch.Watch) PrefixWatch {
+	return &prefixWatch{w: w}
+}
+
+func (v *prefixWatch) Close() {
+	v.w.Close()
+}
+
+func (v *prefixWatch) C() <-chan struct{} {
+	return v.w.C()
+}
+
+func (v *prefixWatch) Get() map[string]Value {
+	return valuesFromWatch(v.w.Get())
+}
+
+type prefixWatchable struct {
+	w xwatch.Watchable
+}
+
+// NewPrefixWatchable creates a new PrefixWatchable
+func NewPrefixWatchable() PrefixWatchable {
+	return &prefixWatchable{w: xwatch.NewWatchable()}
+}
+
+func (w *prefixWatchable) IsClosed() bool {
+	return w.w.IsClosed()
+}
+
+func (w *prefixWatchable) Close() {
+	w.w.Close()
+}
+
+func (w *prefixWatchable) Get() map[string]Value {
+	return valuesFromWatch(w.w.Get())
+}
+
+func (w *prefixWatchable) Watch() (map[string]Value, PrefixWatch, error) {
+	values, watch, err := w.w.Watch()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return valuesFromWatch(values), newPrefixWatch(watch), nil
+}
+
+func (w *prefixWatchable) NumWatches() int {
+	return w.w.NumWatches()
+}
+
+func (w *prefixWatchable) Update(values map[string]Value) error {
+	return w.w.Update(values)
+}
+
+func valuesFromWatch(values interface{}) map[string]Value {
+	if values != nil {
+		return values.(map[string]Value)
+	}
+
+	return nil
+}
+
 func valueFromWatch(value interface{}) Value {
 	if value != nil {
 		return value.(Value)
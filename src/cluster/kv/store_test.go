@@ -22,8 +22,11 @@ package kv
 
 import (
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOptions(t *testing.T) {
@@ -41,3 +44,81 @@ func TestOptions(t *testing.T) {
 	// Now valid, should not have to specify a null logger yourself
 	assert.NoError(t, opts.Validate())
 }
+
+func TestValueWatchFromVersion(t *testing.T) {
+	watchable := NewValueWatchable()
+	require.NoError(t, watchable.Update(&testValue{version: 5}))
+
+	_, inner, err := watchable.Watch()
+	require.NoError(t, err)
+	w := NewValueWatchFromVersion(inner, 5)
+
+	// neither the processed version nor a stale older version are delivered.
+	require.NoError(t, watchable.Update(&testValue{version: 3, stale: true}))
+	requireNoNotification(t, w)
+	require.Nil(t, w.Get())
+
+	// a lower version means the key has been recreated.
+	require.NoError(t, watchable.Update(&testValue{version: 1}))
+	<-w.C()
+	require.Equal(t, 1, w.Get().Version())
+
+	// once caught up every update is delivered.
+	require.NoError(t, watchable.Update(nil))
+	<-w.C()
+	require.Nil(t, w.Get())
+
+	w.Close()
+	_, ok := <-w.C()
+	require.False(t, ok)
+}
+
+func TestIsPastVersion(t *testing.T) {
+	assert.True(t, isPastVersion(nil, 2))
+	assert.False(t, isPastVersion(&testValue{version: 2}, 2))
+	assert.False(t, isPastVersion(&testValue{version: 2, stale: true}, 2))
+	assert.True(t, isPastVersion(&testValue{version: 3}, 2))
+	assert.True(t, isPastVersion(&testValue{version: 3, stale: true}, 2))
+	assert.True(t, isPastVersion(&testValue{version: 1}, 2))
+	assert.False(t, isPastVersion(&testValue{version: 1, stale: true}, 2))
+}
+
+func TestNewKeyPage(t *testing.T) {
+	keys := []string{"ns/c", "other", "ns/a", "ns/b"}
+
+	page, err := NewKeyPage(keys, "ns/", 2, "")
+	require.NoError(t, err)
+	require.Equal(t, KeyPage{Keys: []string{"ns/a", "ns/b"}, NextPageToken: "ns/b"}, page)
+
+	page, err = NewKeyPage(keys, "ns/", 2, page.NextPageToken)
+	require.NoError(t, err)
+	require.Equal(t, KeyPage{Keys: []string{"ns/c"}}, page)
+
+	page, err = NewKeyPage(keys, "", 4, "")
+	require.NoError(t, err)
+	require.Equal(t, KeyPage{Keys: []string{"ns/a", "ns/b", "ns/c", "other"}}, page)
+
+	_, err = NewKeyPage(keys, "ns/", 0, "")
+	require.Equal(t, ErrInvalidPageSize, err)
+
+	_, err = NewKeyPage(keys, "ns/", 2, "other")
+	require.Equal(t, ErrInvalidPageToken, err)
+}
+
+func requireNoNotification(t *testing.T, w ValueWatch) {
+	select {
+	case <-w.C():
+		require.FailNow(t, "unexpected notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+type testValue struct {
+	version int
+	stale   bool
+}
+
+func (v *testValue) Unmarshal(proto.Message) error { return nil }
+func (v *testValue) Version() int                  { return v.version }
+func (v *testValue) IsNewer(other Value) bool      { return v.version > other.Version() }
+func (v *testValue) IsStale() bool                 { return v.stale }
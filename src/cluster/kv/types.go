@@ -22,6 +22,7 @@ package kv
 
 import (
 	"errors"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 )
@@ -53,8 +54,27 @@ var (
 	// ErrUnknownOpType is returned when an unknown OpType is requested
 	ErrUnknownOpType = errors.New("unknown op type")
 
-	// ErrConditionCheckFailed is returned when condition check failed
+	// ErrInvalidTTL is returned when attempting a SetWithTTL with a ttl that is
+	// not positive
+	ErrInvalidTTL = errors.New("ttl must be positive")
+
+	// ErrConditionCheckFailed is returned when condition check failed, the
+	// error returned by a transaction is a *ConditionCheckFailedError that
+	// wraps it and describes which conditions failed
 	ErrConditionCheckFailed = errors.New("condition check failed")
+
+	// ErrReadOnly is returned when attempting a mutation on a read only store,
+	// the error returned by the store is a *ReadOnlyError that wraps it and
+	// describes the rejected mutation
+	ErrReadOnly = errors.New("kv store is read only")
+
+	// ErrInvalidPageSize is returned when attempting a ListKeys with a page
+	// size that is not positive
+	ErrInvalidPageSize = errors.New("page size must be positive")
+
+	// ErrInvalidPageToken is returned when attempting a ListKeys with a page
+	// token that was not returned by a listing of the same prefix
+	ErrInvalidPageToken = errors.New("page token does not match the prefix")
 )
 
 // A Value provides access to a versioned value in the configuration store
@@ -67,6 +87,10 @@ type Value interface {
 
 	// IsNewer returns if this Value is newer than the other Value
 	IsNewer(other Value) bool
+
+	// IsStale returns true if this Value was served from a local cache because
+	// the remote store was unavailable, it may not be the latest Value
+	IsStale() bool
 }
 
 // ValueWatch provides updates to a Value
@@ -95,6 +119,33 @@ type ValueWatchable interface {
 	Close()
 }
 
+// PrefixWatch provides updates to the Values of all keys under a prefix
+type PrefixWatch interface {
+	// C returns the notification channel
+	C() <-chan struct{}
+	// Get returns the latest Values of all keys under the prefix by key, the
+	// returned map is shared and must not be modified
+	Get() map[string]Value
+	// Close stops watching for value updates
+	Close()
+}
+
+// PrefixWatchable can be watched for changes to the Values under a prefix
+type PrefixWatchable interface {
+	// Get returns the latest Values by key
+	Get() map[string]Value
+	// Watch returns the Values and a PrefixWatch that will be notified on updates
+	Watch() (map[string]Value, PrefixWatch, error)
+	// NumWatches returns the number of watches on the Watchable
+	NumWatches() int
+	// Update sets the Values and notify Watches
+	Update(map[string]Value) error
+	// IsClosed returns true if the Watchable is closed
+	IsClosed() bool
+	// Close stops watching for value updates
+	Close()
+}
+
 // OverrideOptions provides a set of options to override the default configurations of a KV store.
 type OverrideOptions interface {
 	// Zone returns the zone of the KV store.
@@ -124,14 +175,37 @@ type Store interface {
 	// Get retrieves the value for the given key
 	Get(key string) (Value, error)
 
+	// GetMany retrieves the values for the given keys in as few round trips to
+	// the store as possible, keys without a value are omitted from the result
+	GetMany(keys []string) (map[string]Value, error)
+
 	// Watch adds a watch for value updates for given key. This is a non-blocking
 	// call - a notification will be sent to ValueWatch.C() once a value is
 	// available
 	Watch(key string) (ValueWatch, error)
 
+	// WatchFromVersion adds a watch for value updates for given key like Watch
+	// but is only notified once the value is no longer at the given version,
+	// which is typically the last version processed by the caller. Versions in
+	// between are not replayed so the watch does not depend on whether they
+	// have been compacted, it is notified with the latest value. A non-stale
+	// value at a lower version is delivered since the key has been deleted and
+	// recreated since, and so is a deletion of the key
+	WatchFromVersion(key string, version int) (ValueWatch, error)
+
+	// WatchPrefix adds a watch for value updates of all keys under the given
+	// prefix. This is a non-blocking call - a notification will be sent to
+	// PrefixWatch.C() once the values are available
+	WatchPrefix(prefix string) (PrefixWatch, error)
+
 	// Set stores the value for the given key
 	Set(key string, v proto.Message) (int, error)
 
+	// SetWithTTL stores the value for the given key and deletes the key once
+	// the ttl elapses unless it is set again, which watches observe as a
+	// deletion. The ttl may be rounded up to the granularity of the store
+	SetWithTTL(key string, v proto.Message, ttl time.Duration) (int, error)
+
 	// SetIfNotExists sets the value for the given key only if no value already
 	// exists
 	SetIfNotExists(key string, v proto.Message) (int, error)
@@ -143,8 +217,28 @@ type Store interface {
 	// Delete deletes a key in the store and returns the last value before deletion
 	Delete(key string) (Value, error)
 
+	// DeleteIfVersionMatches deletes a key in the store if the current version
+	// matches the provided version and returns the last value before deletion
+	DeleteIfVersionMatches(key string, version int) (Value, error)
+
 	// History returns the value for a key in version range [from, to)
 	History(key string, from, to int) ([]Value, error)
+
+	// ListKeys lists the keys with the given prefix in ascending order, at
+	// most pageSize keys at a time. The listing starts with an empty page token
+	// and continues with the NextPageToken of the previous page until it is
+	// empty. Keys set or deleted between pages may or may not be listed
+	ListKeys(prefix string, pageSize int, pageToken string) (KeyPage, error)
+}
+
+// KeyPage is a page of the keys listed by ListKeys
+type KeyPage struct {
+	// Keys are the keys of the page in ascending order
+	Keys []string
+
+	// NextPageToken lists the next page when passed to ListKeys, it is empty
+	// for the last page
+	NextPageToken string
 }
 
 // TargetType is the type of the comparison target in the condition
@@ -229,5 +323,24 @@ type Response interface {
 type TxnStore interface {
 	Store
 
+	// Commit atomically applies the ops if all the conditions hold and returns
+	// the new versions of the keys set, otherwise it applies none of the ops
+	// and returns a *ConditionCheckFailedError
 	Commit([]Condition, []Op) (Response, error)
 }
+
+// PreviousValueStore is implemented by stores which return the value a set
+// replaced as part of the set itself, so that it need not be read separately
+type PreviousValueStore interface {
+	// SetReturningPrevious is Set returning the value it replaced, which is
+	// nil if the key did not exist
+	SetReturningPrevious(key string, v proto.Message) (int, Value, error)
+
+	// SetWithTTLReturningPrevious is SetWithTTL returning the value it
+	// replaced, which is nil if the key did not exist
+	SetWithTTLReturningPrevious(key string, v proto.Message, ttl time.Duration) (int, Value, error)
+
+	// CheckAndSetReturningPrevious is CheckAndSet returning the value it
+	// replaced, which is nil if the key did not exist
+	CheckAndSetReturningPrevious(key string, version int, v proto.Message) (int, Value, error)
+}
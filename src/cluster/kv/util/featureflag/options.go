@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package featureflag
+
+import (
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Options are the options for a feature flag registry.
+type Options interface {
+	// SetKVStore sets the kv store, flags keep their default values if nil.
+	SetKVStore(value kv.Store) Options
+
+	// KVStore returns the kv store.
+	KVStore() kv.Store
+
+	// SetKeyPrefix sets the prefix prepended to flag names to form kv keys.
+	SetKeyPrefix(value string) Options
+
+	// KeyPrefix returns the prefix prepended to flag names to form kv keys.
+	KeyPrefix() string
+
+	// SetInstanceID sets the ID of this instance used for percentage rollouts.
+	SetInstanceID(value string) Options
+
+	// InstanceID returns the ID of this instance used for percentage rollouts.
+	InstanceID() string
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+}
+
+type options struct {
+	store          kv.Store
+	keyPrefix      string
+	instanceID     string
+	instrumentOpts instrument.Options
+}
+
+// NewOptions returns new feature flag registry options.
+func NewOptions() Options {
+	return &options{
+		instrumentOpts: instrument.NewOptions(),
+	}
+}
+
+func (o *options) SetKVStore(value kv.Store) Options {
+	opts := *o
+	opts.store = value
+	return &opts
+}
+
+func (o *options) KVStore() kv.Store {
+	return o.store
+}
+
+func (o *options) SetKeyPrefix(value string) Options {
+	opts := *o
+	opts.keyPrefix = value
+	return &opts
+}
+
+func (o *options) KeyPrefix() string {
+	return o.keyPrefix
+}
+
+func (o *options) SetInstanceID(value string) Options {
+	opts := *o
+	opts.instanceID = value
+	return &opts
+}
+
+func (o *options) InstanceID() string {
+	return o.instanceID
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package featureflag
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/util"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// percentageBuckets is the number of buckets instances are hashed into
+	// for percentage rollouts, which allows hundredths of a percent.
+	percentageBuckets = 10000
+)
+
+type parseFn func(v kv.Value) (interface{}, error)
+
+type flagValue struct {
+	value   interface{}
+	source  Source
+	version int
+}
+
+type flag struct {
+	name         string
+	key          string
+	flagType     Type
+	defaultValue interface{}
+	parseFn      parseFn
+
+	current atomic.Value
+}
+
+func (f *flag) Name() string {
+	return f.name
+}
+
+func (f *flag) load() flagValue {
+	return f.current.Load().(flagValue)
+}
+
+func (f *flag) update(v kv.Value) {
+	if v == nil {
+		// The key does not exist or was deleted, use the default value.
+		f.current.Store(flagValue{value: f.defaultValue, source: DefaultSource})
+		return
+	}
+
+	value, err := f.parseFn(v)
+	if err != nil {
+		// Malformed and invalid values are logged by the parse function, keep
+		// the current value.
+		return
+	}
+	f.current.Store(flagValue{value: value, source: KVSource, version: v.Version()})
+}
+
+func (f *flag) state() State {
+	current := f.load()
+	return State{
+		Name:    f.name,
+		Type:    f.flagType,
+		Key:     f.key,
+		Value:   current.value,
+		Default: f.defaultValue,
+		Source:  current.source,
+		Version: current.version,
+	}
+}
+
+type boolFlag struct {
+	*flag
+}
+
+func (f boolFlag) Value() bool {
+	return f.load().value.(bool)
+}
+
+type intFlag struct {
+	*flag
+}
+
+func (f intFlag) Value() int64 {
+	return f.load().value.(int64)
+}
+
+type percentageFlag struct {
+	*flag
+
+	bucket uint32
+}
+
+func (f percentageFlag) Percentage() float64 {
+	return f.load().value.(float64)
+}
+
+func (f percentageFlag) Enabled() bool {
+	return float64(f.bucket) < f.Percentage()*percentageBuckets/100
+}
+
+type registry struct {
+	sync.Mutex
+
+	store      kv.Store
+	keyPrefix  string
+	instanceID string
+	logger     *zap.Logger
+	utilOpts   util.Options
+
+	flags    map[string]interface{}
+	watches  []kv.ValueWatch
+	closed   bool
+	closedCh chan struct{}
+}
+
+// NewRegistry returns a new feature flag registry.
+func NewRegistry(opts Options) Registry {
+	logger := opts.InstrumentOptions().Logger()
+	return &registry{
+		store:      opts.KVStore(),
+		keyPrefix:  opts.KeyPrefix(),
+		instanceID: opts.InstanceID(),
+		logger:     logger,
+		utilOpts:   util.NewOptions().SetLogger(logger),
+		flags:      make(map[string]interface{}),
+		closedCh:   make(chan struct{}),
+	}
+}
+
+func (r *registry) Bool(name string, defaultValue bool) (BoolFlag, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if existing, ok := r.flags[name]; ok {
+		if f, ok := existing.(boolFlag); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("%v: name=%s", errTypeMismatch, name)
+	}
+
+	key := r.key(name)
+	f, err := r.newFlagWithLock(name, BoolType, defaultValue, func(v kv.Value) (interface{}, error) {
+		return util.BoolFromValue(v, key, defaultValue, r.utilOpts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := boolFlag{flag: f}
+	r.flags[name] = result
+	return result, nil
+}
+
+func (r *registry) Int(name string, defaultValue int64) (IntFlag, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if existing, ok := r.flags[name]; ok {
+		if f, ok := existing.(intFlag); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("%v: name=%s", errTypeMismatch, name)
+	}
+
+	key := r.key(name)
+	f, err := r.newFlagWithLock(name, IntType, defaultValue, func(v kv.Value) (interface{}, error) {
+		return util.Int64FromValue(v, key, defaultValue, r.utilOpts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := intFlag{flag: f}
+	r.flags[name] = result
+	return result, nil
+}
+
+func (r *registry) Percentage(name string, defaultValue float64) (PercentageFlag, error) {
+	if err := validatePercentage(defaultValue); err != nil {
+		return nil, err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if existing, ok := r.flags[name]; ok {
+		if f, ok := existing.(percentageFlag); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("%v: name=%s", errTypeMismatch, name)
+	}
+
+	var (
+		key      = r.key(name)
+		utilOpts = r.utilOpts.SetValidateFn(validatePercentage)
+	)
+	f, err := r.newFlagWithLock(name, PercentageType, defaultValue, func(v kv.Value) (interface{}, error) {
+		return util.Float64FromValue(v, key, defaultValue, utilOpts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(r.instanceID))
+	_, _ = hash.Write([]byte(name))
+	result := percentageFlag{
+		flag:   f,
+		bucket: hash.Sum32() % percentageBuckets,
+	}
+	r.flags[name] = result
+	return result, nil
+}
+
+func (r *registry) Flags() []State {
+	r.Lock()
+	defer r.Unlock()
+
+	states := make([]State, 0, len(r.flags))
+	for _, f := range r.flags {
+		switch f := f.(type) {
+		case boolFlag:
+			states = append(states, f.state())
+		case intFlag:
+			states = append(states, f.state())
+		case percentageFlag:
+			state := f.state()
+			enabled := f.Enabled()
+			state.Enabled = &enabled
+			states = append(states, state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+func (r *registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.Flags()); err != nil {
+			r.logger.Error("unable to encode feature flags", zap.Error(err))
+		}
+	})
+}
+
+func (r *registry) Close() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.closed {
+		return
+	}
+	r.closed = true
+	close(r.closedCh)
+	for _, w := range r.watches {
+		w.Close()
+	}
+	r.watches = nil
+}
+
+func (r *registry) key(name string) string {
+	return r.keyPrefix + name
+}
+
+func (r *registry) newFlagWithLock(
+	name string,
+	flagType Type,
+	defaultValue interface{},
+	parse parseFn,
+) (*flag, error) {
+	if name == "" {
+		return nil, errInvalidFlagName
+	}
+	if r.closed {
+		return nil, errRegistryClosed
+	}
+
+	f := &flag{
+		name:         name,
+		key:          r.key(name),
+		flagType:     flagType,
+		defaultValue: defaultValue,
+		parseFn:      parse,
+	}
+	f.update(nil)
+	if r.store == nil {
+		return f, nil
+	}
+
+	v, err := r.store.Get(f.key)
+	if err != nil && err != kv.ErrNotFound {
+		return nil, fmt.Errorf("could not get feature flag: name=%s, err=%v", name, err)
+	}
+	f.update(v)
+
+	watch, err := r.store.Watch(f.key)
+	if err != nil {
+		return nil, fmt.Errorf("could not watch feature flag: name=%s, err=%v", name, err)
+	}
+	r.watches = append(r.watches, watch)
+
+	go func() {
+		for {
+			select {
+			case <-r.closedCh:
+				return
+			case _, ok := <-watch.C():
+				if !ok {
+					return
+				}
+				f.update(watch.Get())
+			}
+		}
+	}()
+
+	return f, nil
+}
+
+func validatePercentage(v interface{}) error {
+	if p := v.(float64); p < 0 || p > 100 {
+		return fmt.Errorf("%v: value=%f", errInvalidRollout, p)
+	}
+	return nil
+}
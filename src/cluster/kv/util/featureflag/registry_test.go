@@ -0,0 +1,215 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package featureflag
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+
+	"github.com/fortytw2/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func waitFor(t *testing.T, fn func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !fn() {
+		require.True(t, time.Now().Before(deadline), "condition not met in time")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRegistryBool(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	store := mem.NewStore()
+	r := NewRegistry(NewOptions().SetKVStore(store).SetKeyPrefix("test."))
+	defer r.Close()
+
+	f, err := r.Bool("foo", true)
+	require.NoError(t, err)
+	require.True(t, f.Value())
+
+	_, err = store.Set("test.foo", &commonpb.BoolProto{Value: false})
+	require.NoError(t, err)
+	waitFor(t, func() bool { return !f.Value() })
+
+	// Malformed updates are not applied.
+	_, err = store.Set("test.foo", &commonpb.StringProto{Value: "bar"})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	require.False(t, f.Value())
+
+	// Deleting the key reverts to the default value.
+	_, err = store.Delete("test.foo")
+	require.NoError(t, err)
+	waitFor(t, func() bool { return f.Value() })
+}
+
+func TestRegistryInitialValueFromKV(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	store := mem.NewStore()
+	_, err := store.Set("foo", &commonpb.Int64Proto{Value: 42})
+	require.NoError(t, err)
+
+	r := NewRegistry(NewOptions().SetKVStore(store))
+	defer r.Close()
+
+	f, err := r.Int("foo", 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), f.Value())
+
+	states := r.Flags()
+	require.Len(t, states, 1)
+	require.Equal(t, KVSource, states[0].Source)
+	require.Equal(t, int64(1), states[0].Default)
+}
+
+func TestRegistryNoKVStore(t *testing.T) {
+	r := NewRegistry(NewOptions())
+	defer r.Close()
+
+	f, err := r.Int("foo", 7)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), f.Value())
+}
+
+func TestRegistryReturnsExistingFlag(t *testing.T) {
+	r := NewRegistry(NewOptions())
+	defer r.Close()
+
+	f1, err := r.Bool("foo", true)
+	require.NoError(t, err)
+	f2, err := r.Bool("foo", false)
+	require.NoError(t, err)
+	require.Equal(t, f1, f2)
+	require.True(t, f2.Value())
+
+	_, err = r.Int("foo", 1)
+	require.Error(t, err)
+
+	_, err = r.Bool("", true)
+	require.Error(t, err)
+}
+
+func TestRegistryPercentage(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	store := mem.NewStore()
+
+	var (
+		flags    []PercentageFlag
+		closeFns []func()
+	)
+	for i := 0; i < 100; i++ {
+		r := NewRegistry(NewOptions().
+			SetKVStore(store).
+			SetInstanceID(fmt.Sprintf("instance-%d", i)))
+		closeFns = append(closeFns, r.Close)
+
+		f, err := r.Percentage("foo", 0)
+		require.NoError(t, err)
+		require.False(t, f.Enabled())
+		flags = append(flags, f)
+	}
+	defer func() {
+		for _, fn := range closeFns {
+			fn()
+		}
+	}()
+
+	_, err := store.Set("foo", &commonpb.Float64Proto{Value: 50})
+	require.NoError(t, err)
+	waitFor(t, func() bool {
+		for _, f := range flags {
+			if f.Percentage() != 50 {
+				return false
+			}
+		}
+		return true
+	})
+
+	var enabled []int
+	for i, f := range flags {
+		if f.Enabled() {
+			enabled = append(enabled, i)
+		}
+	}
+	require.True(t, len(enabled) > 20 && len(enabled) < 80,
+		"unexpected number of enabled instances: %d", len(enabled))
+
+	// Instances enabled at a lower percentage stay enabled at a higher one.
+	_, err = store.Set("foo", &commonpb.Float64Proto{Value: 75})
+	require.NoError(t, err)
+	waitFor(t, func() bool {
+		for _, f := range flags {
+			if f.Percentage() != 75 {
+				return false
+			}
+		}
+		return true
+	})
+	for _, i := range enabled {
+		require.True(t, flags[i].Enabled())
+	}
+
+	// Out of range percentages are not applied.
+	_, err = store.Set("foo", &commonpb.Float64Proto{Value: 150})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, float64(75), flags[0].Percentage())
+
+	_, err = NewRegistry(NewOptions()).Percentage("bar", -1)
+	require.Error(t, err)
+}
+
+func TestRegistryHandler(t *testing.T) {
+	r := NewRegistry(NewOptions().SetKeyPrefix("test."))
+	defer r.Close()
+
+	_, err := r.Bool("b", true)
+	require.NoError(t, err)
+	_, err = r.Percentage("a", 100)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var states []State
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	require.Len(t, states, 2)
+	require.Equal(t, "a", states[0].Name)
+	require.Equal(t, "test.a", states[0].Key)
+	require.Equal(t, PercentageType, states[0].Type)
+	require.NotNil(t, states[0].Enabled)
+	require.True(t, *states[0].Enabled)
+	require.Equal(t, "b", states[1].Name)
+	require.Equal(t, DefaultSource, states[1].Source)
+	require.Equal(t, true, states[1].Value)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package featureflag provides typed feature flags that are backed by kv and
+// updated as their kv values change.
+package featureflag
+
+import (
+	"errors"
+	"net/http"
+)
+
+var (
+	errRegistryClosed  = errors.New("feature flag registry is closed")
+	errTypeMismatch    = errors.New("feature flag already registered with a different type")
+	errInvalidFlagName = errors.New("feature flag name must not be empty")
+	errInvalidRollout  = errors.New("percentage rollout must be between 0 and 100")
+)
+
+// Type is the type of a feature flag.
+type Type string
+
+const (
+	// BoolType is the type of boolean flags.
+	BoolType Type = "bool"
+	// IntType is the type of integer flags.
+	IntType Type = "int"
+	// PercentageType is the type of flags rolled out to a percentage of
+	// instances.
+	PercentageType Type = "percentage"
+)
+
+// Source is where the effective value of a feature flag comes from.
+type Source string
+
+const (
+	// DefaultSource means the flag has no value in kv and uses its default.
+	DefaultSource Source = "default"
+	// KVSource means the flag uses the value set in kv.
+	KVSource Source = "kv"
+)
+
+// BoolFlag is a boolean feature flag.
+type BoolFlag interface {
+	// Name returns the name of the flag.
+	Name() string
+
+	// Value returns the current value of the flag.
+	Value() bool
+}
+
+// IntFlag is an integer feature flag.
+type IntFlag interface {
+	// Name returns the name of the flag.
+	Name() string
+
+	// Value returns the current value of the flag.
+	Value() int64
+}
+
+// PercentageFlag is a feature flag rolled out to a percentage of instances,
+// an instance is part of the rollout if the hash of its ID and the flag name
+// falls within the percentage so the same instances stay enabled as the
+// percentage grows.
+type PercentageFlag interface {
+	// Name returns the name of the flag.
+	Name() string
+
+	// Percentage returns the current rollout percentage between 0 and 100.
+	Percentage() float64
+
+	// Enabled returns whether the flag is enabled for this instance.
+	Enabled() bool
+}
+
+// State is the effective state of a feature flag.
+type State struct {
+	Name    string      `json:"name"`
+	Type    Type        `json:"type"`
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value"`
+	Default interface{} `json:"default"`
+	Source  Source      `json:"source"`
+	Version int         `json:"version,omitempty"`
+	Enabled *bool       `json:"enabled,omitempty"`
+}
+
+// Registry creates feature flags and keeps them up to date with kv. Flags
+// are cached so reading their value never goes to kv, and registering a flag
+// name again returns the existing flag.
+type Registry interface {
+	// Bool returns the boolean flag with the given name.
+	Bool(name string, defaultValue bool) (BoolFlag, error)
+
+	// Int returns the integer flag with the given name.
+	Int(name string, defaultValue int64) (IntFlag, error)
+
+	// Percentage returns the percentage rollout flag with the given name.
+	Percentage(name string, defaultValue float64) (PercentageFlag, error)
+
+	// Flags returns the effective state of all flags sorted by name.
+	Flags() []State
+
+	// Handler returns an HTTP handler listing the effective flags as JSON.
+	Handler() http.Handler
+
+	// Close stops watching kv for flag updates.
+	Close()
+}
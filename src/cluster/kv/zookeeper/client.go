@@ -0,0 +1,92 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zookeeper
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNoNode is returned by a Client when the znode does not exist.
+	ErrNoNode = errors.New("zookeeper: node does not exist")
+
+	// ErrNodeExists is returned by a Client when the znode already exists.
+	ErrNodeExists = errors.New("zookeeper: node already exists")
+
+	// ErrBadVersion is returned by a Client when the version of the znode does
+	// not match the expected version.
+	ErrBadVersion = errors.New("zookeeper: version conflict")
+)
+
+// Stat is the subset of the znode stat used by the store.
+type Stat struct {
+	// Version is the number of changes to the data of the znode.
+	Version int32
+	// Mzxid is the zxid of the change that last modified the znode.
+	Mzxid int64
+	// EphemeralOwner is non zero for ephemeral and ttl znodes.
+	EphemeralOwner int64
+}
+
+// Event is sent once on the channel of a watch when it fires, watches are
+// one shot and need to be registered again to observe further changes.
+type Event struct {
+	// Path is the path of the znode the watch was set on.
+	Path string
+	// Err is set if the watch was lost, e.g. when the session expired.
+	Err error
+}
+
+// Client is the subset of a ZooKeeper client used by the store, NewClient
+// adapts a go-zookeeper connection to it and maps its errors to ErrNoNode,
+// ErrNodeExists and ErrBadVersion.
+type Client interface {
+	// Get returns the data and stat of the znode.
+	Get(path string) ([]byte, Stat, error)
+
+	// GetW returns the data and stat of the znode and sets a watch that fires
+	// once the znode is modified or deleted.
+	GetW(path string) ([]byte, Stat, <-chan Event, error)
+
+	// ExistsW returns whether the znode exists and sets a watch that fires
+	// once the znode is created, modified or deleted.
+	ExistsW(path string) (bool, Stat, <-chan Event, error)
+
+	// Children returns the names of the children of the znode.
+	Children(path string) ([]string, error)
+
+	// ChildrenW returns the names of the children of the znode and sets a
+	// watch that fires once a child is created or deleted.
+	ChildrenW(path string) ([]string, <-chan Event, error)
+
+	// Create creates a persistent znode, or a ttl znode if the ttl is
+	// positive, with the data.
+	Create(path string, data []byte, ttl time.Duration) error
+
+	// Set sets the data of the znode if its version matches, a version of -1
+	// matches any version.
+	Set(path string, data []byte, version int32) (Stat, error)
+
+	// Delete deletes the znode if its version matches, a version of -1
+	// matches any version.
+	Delete(path string, version int32) error
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zookeeper
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/go-zookeeper/zk"
+	"go.uber.org/zap"
+)
+
+const defaultSessionTimeout = 10 * time.Second
+
+var errNoServers = errors.New("no zookeeper servers")
+
+// Configuration is the config for the zookeeper kv store.
+type Configuration struct {
+	Servers            []string      `yaml:"servers"`
+	SessionTimeout     time.Duration `yaml:"sessionTimeout"`
+	Root               string        `yaml:"root"`
+	Prefix             string        `yaml:"prefix"`
+	WatchCheckInterval time.Duration `yaml:"watchCheckInterval"`
+}
+
+// NewOptions creates the zookeeper kv store Options from the configuration.
+func (cfg Configuration) NewOptions(iopts instrument.Options) Options {
+	opts := NewOptions().
+		SetPrefix(cfg.Prefix).
+		SetInstrumentsOptions(iopts)
+	if cfg.Root != "" {
+		opts = opts.SetRoot(cfg.Root)
+	}
+	if cfg.WatchCheckInterval > 0 {
+		opts = opts.SetWatchCheckInterval(cfg.WatchCheckInterval)
+	}
+	return opts
+}
+
+// Connect connects to the zookeeper servers, the connection reconnects on its
+// own and should be closed once the store is no longer used.
+func (cfg Configuration) Connect(iopts instrument.Options) (*zk.Conn, error) {
+	if len(cfg.Servers) == 0 {
+		return nil, errNoServers
+	}
+	sessionTimeout := cfg.SessionTimeout
+	if sessionTimeout <= 0 {
+		sessionTimeout = defaultSessionTimeout
+	}
+	zkConn, _, err := zk.Connect(cfg.Servers, sessionTimeout,
+		zk.WithLogger(zkLogger{logger: iopts.Logger()}))
+	if err != nil {
+		return nil, err
+	}
+	return zkConn, nil
+}
+
+type zkLogger struct {
+	logger *zap.Logger
+}
+
+func (l zkLogger) Printf(format string, args ...interface{}) {
+	l.logger.Sugar().Infof(format, args...)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zookeeper
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+)
+
+var errWatchStopped = errors.New("zookeeper: watch stopped")
+
+type conn struct {
+	zk  *zk.Conn
+	acl []zk.ACL
+}
+
+// NewClient returns a Client backed by the ZooKeeper connection, ttl znodes
+// require the server to run with extended types enabled.
+func NewClient(zkConn *zk.Conn) Client {
+	return &conn{zk: zkConn, acl: zk.WorldACL(zk.PermAll)}
+}
+
+func (c *conn) Get(path string) ([]byte, Stat, error) {
+	data, stat, err := c.zk.Get(path)
+	if err != nil {
+		return nil, Stat{}, convertError(err)
+	}
+	return data, convertStat(stat), nil
+}
+
+func (c *conn) GetW(path string) ([]byte, Stat, <-chan Event, error) {
+	data, stat, ch, err := c.zk.GetW(path)
+	if err != nil {
+		return nil, Stat{}, nil, convertError(err)
+	}
+	return data, convertStat(stat), convertEvents(ch), nil
+}
+
+func (c *conn) ExistsW(path string) (bool, Stat, <-chan Event, error) {
+	exists, stat, ch, err := c.zk.ExistsW(path)
+	if err != nil {
+		return false, Stat{}, nil, convertError(err)
+	}
+	return exists, convertStat(stat), convertEvents(ch), nil
+}
+
+func (c *conn) Children(path string) ([]string, error) {
+	children, _, err := c.zk.Children(path)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return children, nil
+}
+
+func (c *conn) ChildrenW(path string) ([]string, <-chan Event, error) {
+	children, _, ch, err := c.zk.ChildrenW(path)
+	if err != nil {
+		return nil, nil, convertError(err)
+	}
+	return children, convertEvents(ch), nil
+}
+
+func (c *conn) Create(path string, data []byte, ttl time.Duration) error {
+	var err error
+	if ttl > 0 {
+		_, err = c.zk.CreateTTL(path, data, zk.FlagTTL, c.acl, ttl)
+	} else {
+		_, err = c.zk.Create(path, data, 0, c.acl)
+	}
+	return convertError(err)
+}
+
+func (c *conn) Set(path string, data []byte, version int32) (Stat, error) {
+	stat, err := c.zk.Set(path, data, version)
+	if err != nil {
+		return Stat{}, convertError(err)
+	}
+	return convertStat(stat), nil
+}
+
+func (c *conn) Delete(path string, version int32) error {
+	return convertError(c.zk.Delete(path, version))
+}
+
+func convertStat(stat *zk.Stat) Stat {
+	if stat == nil {
+		return Stat{}
+	}
+	return Stat{
+		Version:        stat.Version,
+		Mzxid:          stat.Mzxid,
+		EphemeralOwner: stat.EphemeralOwner,
+	}
+}
+
+func convertError(err error) error {
+	switch err {
+	case zk.ErrNoNode:
+		return ErrNoNode
+	case zk.ErrNodeExists:
+		return ErrNodeExists
+	case zk.ErrBadVersion:
+		return ErrBadVersion
+	default:
+		return err
+	}
+}
+
+// convertEvents forwards the single event of a zk watch, the zk client closes
+// the channel after sending it.
+func convertEvents(ch <-chan zk.Event) <-chan Event {
+	out := make(chan Event, 1)
+	go func() {
+		ev, ok := <-ch
+		switch {
+		case !ok:
+			out <- Event{Err: errWatchStopped}
+		case ev.Err != nil:
+			out <- Event{Path: ev.Path, Err: ev.Err}
+		case ev.Type == zk.EventNotWatching:
+			out <- Event{Path: ev.Path, Err: errWatchStopped}
+		default:
+			out <- Event{Path: ev.Path}
+		}
+	}()
+	return out
+}
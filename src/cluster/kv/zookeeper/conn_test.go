@@ -0,0 +1,77 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zookeeper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertError(t *testing.T) {
+	require.Equal(t, ErrNoNode, convertError(zk.ErrNoNode))
+	require.Equal(t, ErrNodeExists, convertError(zk.ErrNodeExists))
+	require.Equal(t, ErrBadVersion, convertError(zk.ErrBadVersion))
+	require.Equal(t, zk.ErrConnectionClosed, convertError(zk.ErrConnectionClosed))
+	require.NoError(t, convertError(nil))
+}
+
+func TestConvertEvents(t *testing.T) {
+	errLost := errors.New("lost")
+	for _, test := range []struct {
+		event    zk.Event
+		expected Event
+	}{
+		{
+			event:    zk.Event{Type: zk.EventNodeDataChanged, Path: "/a"},
+			expected: Event{Path: "/a"},
+		},
+		{
+			event:    zk.Event{Type: zk.EventNotWatching, Path: "/a", Err: errLost},
+			expected: Event{Path: "/a", Err: errLost},
+		},
+		{
+			event:    zk.Event{Type: zk.EventNotWatching, Path: "/a"},
+			expected: Event{Path: "/a", Err: errWatchStopped},
+		},
+	} {
+		ch := make(chan zk.Event, 1)
+		ch <- test.event
+		close(ch)
+		require.Equal(t, test.expected, <-convertEvents(ch))
+	}
+
+	ch := make(chan zk.Event)
+	close(ch)
+	require.Equal(t, Event{Err: errWatchStopped}, <-convertEvents(ch))
+}
+
+func TestConfiguration(t *testing.T) {
+	opts := Configuration{Root: "/root", Prefix: "p"}.NewOptions(nil)
+	require.Equal(t, "/root", opts.Root())
+	require.Equal(t, "p", opts.Prefix())
+	require.Equal(t, defaultWatchCheckInterval, opts.WatchCheckInterval())
+
+	_, err := Configuration{}.Connect(nil)
+	require.Equal(t, errNoServers, err)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zookeeper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultRoot               = "/m3"
+	defaultWatchCheckInterval = 10 * time.Second
+)
+
+// Options are options for the client of the zookeeper kv store
+type Options interface {
+	// Root is the path of the znode under which keys are stored
+	Root() string
+	// SetRoot sets the Root
+	SetRoot(root string) Options
+
+	// WatchCheckInterval is the interval to check if a watch is no longer
+	// subscribed and should be stopped, and to retry failed watches
+	WatchCheckInterval() time.Duration
+	// SetWatchCheckInterval sets the WatchCheckInterval
+	SetWatchCheckInterval(t time.Duration) Options
+
+	// Prefix is the prefix for each key
+	Prefix() string
+	// SetPrefix sets the prefix
+	SetPrefix(s string) Options
+	// ApplyPrefix applies the prefix to the key
+	ApplyPrefix(key string) string
+
+	// InstrumentsOptions is the instrument options
+	InstrumentsOptions() instrument.Options
+	// SetInstrumentsOptions sets the InstrumentsOptions
+	SetInstrumentsOptions(iopts instrument.Options) Options
+
+	// Validate validates the Options
+	Validate() error
+}
+
+type options struct {
+	root               string
+	watchCheckInterval time.Duration
+	prefix             string
+	iopts              instrument.Options
+}
+
+// NewOptions creates a sane default Option
+func NewOptions() Options {
+	o := options{}
+	return o.SetRoot(defaultRoot).
+		SetWatchCheckInterval(defaultWatchCheckInterval).
+		SetInstrumentsOptions(instrument.NewOptions())
+}
+
+func (o options) Validate() error {
+	if !strings.HasPrefix(o.root, "/") || (len(o.root) > 1 && strings.HasSuffix(o.root, "/")) {
+		return fmt.Errorf("invalid root path: %q", o.root)
+	}
+
+	if o.watchCheckInterval <= 0 {
+		return errors.New("invalid watch check interval")
+	}
+
+	if o.iopts == nil {
+		return errors.New("no instrument options")
+	}
+
+	return nil
+}
+
+func (o options) Root() string {
+	return o.root
+}
+
+func (o options) SetRoot(root string) Options {
+	o.root = root
+	return o
+}
+
+func (o options) WatchCheckInterval() time.Duration {
+	return o.watchCheckInterval
+}
+
+func (o options) SetWatchCheckInterval(t time.Duration) Options {
+	o.watchCheckInterval = t
+	return o
+}
+
+func (o options) Prefix() string {
+	return o.prefix
+}
+
+func (o options) SetPrefix(prefix string) Options {
+	o.prefix = prefix
+	return o
+}
+
+func (o options) ApplyPrefix(key string) string {
+	if o.prefix == "" {
+		return key
+	}
+	return fmt.Sprintf("%s/%s", o.prefix, key)
+}
+
+func (o options) InstrumentsOptions() instrument.Options {
+	return o.iopts
+}
+
+func (o options) SetInstrumentsOptions(iopts instrument.Options) Options {
+	o.iopts = iopts
+	return o
+}
@@ -0,0 +1,683 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zookeeper
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// anyVersion matches any version of a znode.
+	anyVersion = -1
+
+	// maxConflictRetries is the number of times a write is retried when the
+	// znode is concurrently created or deleted.
+	maxConflictRetries = 3
+)
+
+var (
+	errHistoryNotSupported = errors.New("history is not supported by the zookeeper kv store")
+	errTooManyConflicts    = errors.New("too many concurrent modifications of the znode")
+)
+
+// NewStore creates a kv store backed by the znodes under the root path of
+// the options. Keys are stored as children of the root, the version of a
+// key is its znode version plus one so that zero means the key does not
+// exist, and watches are served by zookeeper watches which are registered
+// again every time they fire.
+func NewStore(zkClient Client, opts Options) (kv.Store, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := createPath(zkClient, opts.Root()); err != nil {
+		return nil, err
+	}
+
+	scope := opts.InstrumentsOptions().MetricsScope()
+	return &client{
+		opts:             opts,
+		zk:               zkClient,
+		logger:           opts.InstrumentsOptions().Logger(),
+		watchables:       map[string]kv.ValueWatchable{},
+		prefixWatchables: map[string]kv.PrefixWatchable{},
+		m: clientMetrics{
+			zkGetError:   scope.Counter("zk-get-error"),
+			zkSetError:   scope.Counter("zk-set-error"),
+			zkWatchError: scope.Counter("zk-watch-error"),
+		},
+	}, nil
+}
+
+// createPath creates the znode and its parents if they do not exist yet.
+func createPath(zkClient Client, p string) error {
+	var current string
+	for _, part := range strings.Split(strings.TrimPrefix(p, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		current += "/" + part
+		if err := zkClient.Create(current, nil, 0); err != nil && err != ErrNodeExists {
+			return err
+		}
+	}
+	return nil
+}
+
+type client struct {
+	sync.RWMutex
+
+	opts             Options
+	zk               Client
+	logger           *zap.Logger
+	m                clientMetrics
+	watchables       map[string]kv.ValueWatchable
+	prefixWatchables map[string]kv.PrefixWatchable
+}
+
+type clientMetrics struct {
+	zkGetError   tally.Counter
+	zkSetError   tally.Counter
+	zkWatchError tally.Counter
+}
+
+func (c *client) Get(key string) (kv.Value, error) {
+	data, stat, err := c.zk.Get(c.nodePath(c.opts.ApplyPrefix(key)))
+	if err == ErrNoNode {
+		return nil, kv.ErrNotFound
+	}
+	if err != nil {
+		c.m.zkGetError.Inc(1)
+		return nil, err
+	}
+	return newValue(data, stat), nil
+}
+
+// GetMany retrieves the values of the keys one at a time since zookeeper has no
+// batch reads.
+func (c *client) GetMany(keys []string) (map[string]kv.Value, error) {
+	values := make(map[string]kv.Value, len(keys))
+	for _, key := range keys {
+		v, err := c.Get(key)
+		if err == kv.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = v
+	}
+	return values, nil
+}
+
+// History is not supported since zookeeper does not keep previous values.
+func (c *client) History(key string, from, to int) ([]kv.Value, error) {
+	return nil, errHistoryNotSupported
+}
+
+// ListKeys lists the children of the root and pages through them in memory
+// since zookeeper can not page through the children of a znode.
+func (c *client) ListKeys(prefix string, pageSize int, pageToken string) (kv.KeyPage, error) {
+	if err := kv.ValidateListKeys(prefix, pageSize, pageToken); err != nil {
+		return kv.KeyPage{}, err
+	}
+
+	names, err := c.zk.Children(c.opts.Root())
+	if err == ErrNoNode {
+		return kv.KeyPage{}, nil
+	}
+	if err != nil {
+		c.m.zkGetError.Inc(1)
+		return kv.KeyPage{}, err
+	}
+
+	newPrefix := c.opts.ApplyPrefix(prefix)
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		key, err := url.PathUnescape(name)
+		if err != nil || !strings.HasPrefix(key, newPrefix) {
+			continue
+		}
+		keys = append(keys, c.stripPrefix(key))
+	}
+	return kv.NewKeyPage(keys, prefix, pageSize, pageToken)
+}
+
+func (c *client) Watch(key string) (kv.ValueWatch, error) {
+	newKey := c.opts.ApplyPrefix(key)
+	c.Lock()
+	defer c.Unlock()
+
+	watchable, ok := c.watchables[newKey]
+	if !ok {
+		watchable = kv.NewValueWatchable()
+		c.watchables[newKey] = watchable
+
+		go c.watch(newKey, watchable)
+	}
+	// NB: subscribe while holding the lock so the watch loop can not clean up
+	// the watchable before the watch is registered.
+	_, w, err := watchable.Watch()
+	return w, err
+}
+
+// watch keeps a zookeeper watch registered on the znode of the key, getting
+// the latest value every time the watch fires, until there are no more
+// watches on the watchable.
+func (c *client) watch(key string, watchable kv.ValueWatchable) {
+	ticker := time.NewTicker(c.opts.WatchCheckInterval())
+	defer ticker.Stop()
+
+	var (
+		nodePath = c.nodePath(key)
+		eventCh  <-chan Event
+	)
+	for {
+		if eventCh == nil {
+			v, ch, err := c.getW(nodePath)
+			if err != nil {
+				c.m.zkWatchError.Inc(1)
+				c.logger.Error("could not watch znode, will retry",
+					zap.String("path", nodePath), zap.Error(err))
+			} else {
+				eventCh = ch
+				c.update(watchable, v)
+			}
+		}
+
+		// NB: a nil event channel blocks until the next tick which retries
+		// the failed watch.
+		select {
+		case event := <-eventCh:
+			// zookeeper watches are one shot so it is registered again.
+			eventCh = nil
+			if event.Err != nil {
+				c.logger.Warn("znode watch lost, registering it again",
+					zap.String("path", nodePath), zap.Error(event.Err))
+			}
+		case <-ticker.C:
+			if c.tickAndStop(key) {
+				return
+			}
+		}
+	}
+}
+
+// getW returns the value of the znode and sets a watch on it, the value is
+// nil if the znode does not exist in which case the watch fires once the
+// znode is created.
+func (c *client) getW(nodePath string) (kv.Value, <-chan Event, error) {
+	for i := 0; i < maxConflictRetries; i++ {
+		data, stat, ch, err := c.zk.GetW(nodePath)
+		if err == nil {
+			return newValue(data, stat), ch, nil
+		}
+		if err != ErrNoNode {
+			return nil, nil, err
+		}
+
+		exists, _, ch, err := c.zk.ExistsW(nodePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists {
+			return nil, ch, nil
+		}
+		// the znode was created in between, get it again.
+	}
+	return nil, nil, errTooManyConflicts
+}
+
+func (c *client) update(watchable kv.ValueWatchable, v kv.Value) {
+	curValue := watchable.Get()
+	if v == nil {
+		// At deletion, just update the watch to nil.
+		if curValue != nil {
+			watchable.Update(nil)
+		}
+		return
+	}
+
+	if curValue == nil || v.IsNewer(curValue) {
+		watchable.Update(v)
+	}
+}
+
+func (c *client) WatchFromVersion(key string, version int) (kv.ValueWatch, error) {
+	w, err := c.Watch(key)
+	if err != nil {
+		return nil, err
+	}
+	return kv.NewValueWatchFromVersion(w, version), nil
+}
+
+func (c *client) WatchPrefix(prefix string) (kv.PrefixWatch, error) {
+	newPrefix := c.opts.ApplyPrefix(prefix)
+	c.Lock()
+	defer c.Unlock()
+
+	watchable, ok := c.prefixWatchables[newPrefix]
+	if !ok {
+		watchable = kv.NewPrefixWatchable()
+		c.prefixWatchables[newPrefix] = watchable
+
+		w := &prefixWatcher{
+			c:         c,
+			prefix:    newPrefix,
+			watchable: watchable,
+			notifyCh:  make(chan struct{}, 1),
+			watching:  map[string]struct{}{},
+			values:    map[string]kv.Value{},
+		}
+		go w.run()
+	}
+	_, w, err := watchable.Watch()
+	return w, err
+}
+
+// prefixWatcher keeps a children watch on the root and a data watch on every
+// znode of a key with the prefix, registering each of them again once it
+// fires.
+type prefixWatcher struct {
+	sync.Mutex
+
+	c         *client
+	prefix    string
+	watchable kv.PrefixWatchable
+	notifyCh  chan struct{}
+	// watching is the set of znodes with a registered watch, the root is
+	// tracked as the empty name.
+	watching map[string]struct{}
+	values   map[string]kv.Value
+}
+
+func (w *prefixWatcher) run() {
+	ticker := time.NewTicker(w.c.opts.WatchCheckInterval())
+	defer ticker.Stop()
+
+	needsSync := true
+	for {
+		if needsSync {
+			if err := w.sync(); err != nil {
+				w.c.m.zkWatchError.Inc(1)
+				w.c.logger.Error("could not watch znodes with prefix, will retry",
+					zap.String("prefix", w.prefix), zap.Error(err))
+			} else {
+				needsSync = false
+			}
+		}
+
+		select {
+		case <-w.notifyCh:
+			needsSync = true
+		case <-ticker.C:
+			if w.c.tickAndStopPrefix(w.prefix) {
+				return
+			}
+		}
+	}
+}
+
+// sync registers the watches which fired since the last sync, refreshes the
+// values of their znodes and updates the watchable.
+func (w *prefixWatcher) sync() error {
+	root := w.c.opts.Root()
+	var names []string
+	if w.isWatching("") {
+		children, err := w.c.zk.Children(root)
+		if err != nil {
+			return err
+		}
+		names = children
+	} else {
+		children, ch, err := w.c.zk.ChildrenW(root)
+		if err != nil {
+			return err
+		}
+		names = children
+		w.forward("", ch)
+	}
+
+	current := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		key, err := url.PathUnescape(name)
+		if err != nil || !strings.HasPrefix(key, w.prefix) {
+			continue
+		}
+		current[name] = struct{}{}
+		if w.isWatching(name) {
+			// the value has not changed since the watch was registered.
+			continue
+		}
+
+		data, stat, ch, err := w.c.zk.GetW(path.Join(root, name))
+		if err == ErrNoNode {
+			delete(current, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		w.values[name] = newValue(data, stat)
+		w.forward(name, ch)
+	}
+
+	values := make(map[string]kv.Value, len(current))
+	for name, v := range w.values {
+		if _, ok := current[name]; !ok {
+			delete(w.values, name)
+			continue
+		}
+		key, _ := url.PathUnescape(name)
+		values[w.c.stripPrefix(key)] = v
+	}
+	w.watchable.Update(values)
+	return nil
+}
+
+func (w *prefixWatcher) isWatching(name string) bool {
+	w.Lock()
+	_, ok := w.watching[name]
+	w.Unlock()
+	return ok
+}
+
+// forward marks the znode as watched until its watch fires, at which point
+// the watcher is notified to sync again.
+func (w *prefixWatcher) forward(name string, ch <-chan Event) {
+	w.Lock()
+	w.watching[name] = struct{}{}
+	w.Unlock()
+
+	go func() {
+		<-ch
+		w.Lock()
+		delete(w.watching, name)
+		w.Unlock()
+
+		select {
+		case w.notifyCh <- struct{}{}:
+		default:
+		}
+	}()
+}
+
+func (c *client) stripPrefix(key string) string {
+	if c.opts.Prefix() == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, c.opts.Prefix()+"/")
+}
+
+func (c *client) tickAndStop(key string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	watchable, ok := c.watchables[key]
+	if !ok {
+		return true
+	}
+	if watchable.NumWatches() != 0 {
+		return false
+	}
+
+	watchable.Close()
+	delete(c.watchables, key)
+	return true
+}
+
+func (c *client) tickAndStopPrefix(prefix string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	watchable, ok := c.prefixWatchables[prefix]
+	if !ok {
+		return true
+	}
+	if watchable.NumWatches() != 0 {
+		return false
+	}
+
+	watchable.Close()
+	delete(c.prefixWatchables, prefix)
+	return true
+}
+
+// Set sets the value of the key, a ttl znode left by SetWithTTL is recreated
+// as a persistent znode which resets its version.
+func (c *client) Set(key string, v proto.Message) (int, error) {
+	data, err := proto.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+
+	nodePath := c.nodePath(c.opts.ApplyPrefix(key))
+	for i := 0; i < maxConflictRetries; i++ {
+		stat, err := c.zk.Set(nodePath, data, anyVersion)
+		if err == nil {
+			if stat.EphemeralOwner == 0 {
+				return versionOf(stat), nil
+			}
+			return c.recreate(nodePath, data, stat.Version, 0)
+		}
+		if err != ErrNoNode {
+			c.m.zkSetError.Inc(1)
+			return 0, err
+		}
+
+		err = c.zk.Create(nodePath, data, 0)
+		if err == nil {
+			return kv.UninitializedVersion + 1, nil
+		}
+		if err != ErrNodeExists {
+			c.m.zkSetError.Inc(1)
+			return 0, err
+		}
+	}
+	return 0, errTooManyConflicts
+}
+
+// SetWithTTL sets the value of the key as a ttl znode, which requires ttl
+// nodes to be enabled on the zookeeper servers. An existing znode is
+// recreated which resets its version.
+func (c *client) SetWithTTL(key string, v proto.Message, ttl time.Duration) (int, error) {
+	if ttl <= 0 {
+		return 0, kv.ErrInvalidTTL
+	}
+
+	data, err := proto.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.recreate(c.nodePath(c.opts.ApplyPrefix(key)), data, anyVersion, ttl)
+}
+
+// recreate deletes the znode if it matches the version and creates it again
+// with the data and ttl.
+func (c *client) recreate(nodePath string, data []byte, version int32, ttl time.Duration) (int, error) {
+	for i := 0; i < maxConflictRetries; i++ {
+		err := c.zk.Delete(nodePath, version)
+		if err != nil && err != ErrNoNode && err != ErrBadVersion {
+			c.m.zkSetError.Inc(1)
+			return 0, err
+		}
+
+		err = c.zk.Create(nodePath, data, ttl)
+		if err == nil {
+			return kv.UninitializedVersion + 1, nil
+		}
+		if err != ErrNodeExists {
+			c.m.zkSetError.Inc(1)
+			return 0, err
+		}
+		// the znode was created concurrently, replace it.
+		version = anyVersion
+	}
+	return 0, errTooManyConflicts
+}
+
+func (c *client) SetIfNotExists(key string, v proto.Message) (int, error) {
+	return c.CheckAndSet(key, kv.UninitializedVersion, v)
+}
+
+func (c *client) CheckAndSet(key string, version int, v proto.Message) (int, error) {
+	data, err := proto.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+
+	nodePath := c.nodePath(c.opts.ApplyPrefix(key))
+	if version == kv.UninitializedVersion {
+		err := c.zk.Create(nodePath, data, 0)
+		if err == ErrNodeExists {
+			return 0, kv.ErrAlreadyExists
+		}
+		if err != nil {
+			c.m.zkSetError.Inc(1)
+			return 0, err
+		}
+		return kv.UninitializedVersion + 1, nil
+	}
+
+	stat, err := c.zk.Set(nodePath, data, zkVersion(version))
+	if err == ErrBadVersion || err == ErrNoNode {
+		return 0, kv.ErrVersionMismatch
+	}
+	if err != nil {
+		c.m.zkSetError.Inc(1)
+		return 0, err
+	}
+	return versionOf(stat), nil
+}
+
+func (c *client) Delete(key string) (kv.Value, error) {
+	nodePath := c.nodePath(c.opts.ApplyPrefix(key))
+	for i := 0; i < maxConflictRetries; i++ {
+		data, stat, err := c.zk.Get(nodePath)
+		if err == ErrNoNode {
+			return nil, kv.ErrNotFound
+		}
+		if err != nil {
+			c.m.zkGetError.Inc(1)
+			return nil, err
+		}
+
+		err = c.zk.Delete(nodePath, stat.Version)
+		if err == nil {
+			return newValue(data, stat), nil
+		}
+		if err != ErrBadVersion && err != ErrNoNode {
+			c.m.zkSetError.Inc(1)
+			return nil, err
+		}
+		// the znode was modified in between, get it again.
+	}
+	return nil, errTooManyConflicts
+}
+
+func (c *client) DeleteIfVersionMatches(key string, version int) (kv.Value, error) {
+	nodePath := c.nodePath(c.opts.ApplyPrefix(key))
+	data, stat, err := c.zk.Get(nodePath)
+	if err == ErrNoNode {
+		return nil, kv.ErrNotFound
+	}
+	if err != nil {
+		c.m.zkGetError.Inc(1)
+		return nil, err
+	}
+	if versionOf(stat) != version {
+		return nil, kv.ErrVersionMismatch
+	}
+
+	err = c.zk.Delete(nodePath, stat.Version)
+	if err == ErrBadVersion || err == ErrNoNode {
+		return nil, kv.ErrVersionMismatch
+	}
+	if err != nil {
+		c.m.zkSetError.Inc(1)
+		return nil, err
+	}
+	return newValue(data, stat), nil
+}
+
+// nodePath returns the path of the znode of the key, keys are escaped so
+// that every key is a direct child of the root.
+func (c *client) nodePath(key string) string {
+	return path.Join(c.opts.Root(), url.PathEscape(key))
+}
+
+// versionOf returns the kv version of the znode, which is its znode version
+// plus one since the znode version of a created znode is zero.
+func versionOf(stat Stat) int {
+	return int(stat.Version) + 1
+}
+
+// zkVersion returns the znode version of the kv version.
+func zkVersion(version int) int32 {
+	return int32(version - 1)
+}
+
+type value struct {
+	data    []byte
+	version int
+	mzxid   int64
+}
+
+func newValue(data []byte, stat Stat) *value {
+	return &value{
+		data:    data,
+		version: versionOf(stat),
+		mzxid:   stat.Mzxid,
+	}
+}
+
+// IsNewer compares the zxids of the modifications if possible since the
+// version of a znode restarts when it is recreated.
+func (v *value) IsNewer(other kv.Value) bool {
+	if o, ok := other.(*value); ok {
+		return v.mzxid > o.mzxid
+	}
+	return v.version > other.Version()
+}
+
+func (v *value) Unmarshal(msg proto.Message) error {
+	return proto.Unmarshal(v.data, msg)
+}
+
+func (v *value) Version() int {
+	return v.version
+}
+
+func (v *value) IsStale() bool {
+	return false
+}
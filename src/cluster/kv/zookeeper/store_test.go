@@ -0,0 +1,514 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zookeeper
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/kvtest"
+	"github.com/m3db/m3/src/cluster/kv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+var errSessionExpired = errors.New("session expired")
+
+func TestGetSetCheckAndSet(t *testing.T) {
+	store, _ := testStore(t)
+
+	_, err := store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	version, err := store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	value, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar1", version)
+	require.False(t, value.IsStale())
+
+	_, err = store.CheckAndSet("foo", version+1, genProto("bar2"))
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	newVersion, err := store.CheckAndSet("foo", version, genProto("bar2"))
+	require.NoError(t, err)
+	require.Equal(t, version+1, newVersion)
+
+	value, err = store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar2", newVersion)
+
+	_, err = store.SetIfNotExists("foo", genProto("bar3"))
+	require.Equal(t, kv.ErrAlreadyExists, err)
+
+	version, err = store.SetIfNotExists("baz", genProto("bar3"))
+	require.NoError(t, err)
+
+	value, err = store.Get("baz")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar3", version)
+
+	_, err = store.History("foo", 0, 10)
+	require.Equal(t, errHistoryNotSupported, err)
+}
+
+func TestNodePath(t *testing.T) {
+	store, zk := testStore(t)
+
+	_, err := store.Set("foo/bar", genProto("bar1"))
+	require.NoError(t, err)
+
+	children, err := zk.Children("/m3")
+	require.NoError(t, err)
+	require.Equal(t, []string{"test%2Ffoo%2Fbar"}, children)
+}
+
+func TestDelete(t *testing.T) {
+	store, _ := testStore(t)
+
+	_, err := store.Delete("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	version, err := store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+
+	prev, err := store.Delete("foo")
+	require.NoError(t, err)
+	verifyValue(t, prev, "bar1", version)
+
+	_, err = store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+
+	_, err = store.DeleteIfVersionMatches("foo", 0)
+	require.Equal(t, kv.ErrNotFound, err)
+
+	version, err = store.Set("foo", genProto("bar2"))
+	require.NoError(t, err)
+
+	_, err = store.DeleteIfVersionMatches("foo", 0)
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	_, err = store.DeleteIfVersionMatches("foo", version+1)
+	require.Equal(t, kv.ErrVersionMismatch, err)
+
+	prev, err = store.DeleteIfVersionMatches("foo", version)
+	require.NoError(t, err)
+	verifyValue(t, prev, "bar2", version)
+
+	_, err = store.Get("foo")
+	require.Equal(t, kv.ErrNotFound, err)
+}
+
+func TestSetWithTTL(t *testing.T) {
+	store, zk := testStore(t)
+
+	_, err := store.SetWithTTL("foo", genProto("bar1"), 0)
+	require.Equal(t, kv.ErrInvalidTTL, err)
+
+	_, err = store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+	_, err = store.Set("foo", genProto("bar2"))
+	require.NoError(t, err)
+
+	// The persistent znode is recreated as a ttl znode.
+	version, err := store.SetWithTTL("foo", genProto("bar3"), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+	require.Equal(t, time.Minute, zk.ttl("/m3/test%2Ffoo"))
+
+	value, err := store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar3", version)
+
+	// Setting the key again makes the znode persistent.
+	version, err = store.Set("foo", genProto("bar4"))
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+	require.Equal(t, time.Duration(0), zk.ttl("/m3/test%2Ffoo"))
+
+	value, err = store.Get("foo")
+	require.NoError(t, err)
+	verifyValue(t, value, "bar4", version)
+}
+
+func TestIsNewer(t *testing.T) {
+	older := newValue(nil, Stat{Version: 5, Mzxid: 10})
+	newer := newValue(nil, Stat{Version: 0, Mzxid: 11})
+
+	require.True(t, newer.IsNewer(older))
+	require.False(t, older.IsNewer(newer))
+}
+
+func TestWatch(t *testing.T) {
+	store, _ := testStore(t)
+
+	w, err := store.Watch("foo")
+	require.NoError(t, err)
+	require.Nil(t, w.Get())
+
+	version, err := store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+
+	<-w.C()
+	verifyValue(t, w.Get(), "bar1", version)
+
+	version, err = store.Set("foo", genProto("bar2"))
+	require.NoError(t, err)
+
+	<-w.C()
+	verifyValue(t, w.Get(), "bar2", version)
+
+	_, err = store.Delete("foo")
+	require.NoError(t, err)
+
+	<-w.C()
+	require.Nil(t, w.Get())
+
+	w.Close()
+}
+
+func TestWatchSessionExpired(t *testing.T) {
+	store, zk := testStore(t)
+
+	version, err := store.Set("foo", genProto("bar1"))
+	require.NoError(t, err)
+
+	w, err := store.Watch("foo")
+	require.NoError(t, err)
+
+	<-w.C()
+	verifyValue(t, w.Get(), "bar1", version)
+
+	// The watch is registered again after it is lost.
+	zk.expireWatches()
+	for zk.numWatches() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	version, err = store.Set("foo", genProto("bar2"))
+	require.NoError(t, err)
+
+	<-w.C()
+	verifyValue(t, w.Get(), "bar2", version)
+
+	w.Close()
+}
+
+func TestWatchPrefix(t *testing.T) {
+	store, _ := testStore(t)
+
+	version1, err := store.Set("foo/a", genProto("bar1"))
+	require.NoError(t, err)
+
+	w, err := store.WatchPrefix("foo/")
+	require.NoError(t, err)
+
+	<-w.C()
+	values := w.Get()
+	require.Len(t, values, 1)
+	verifyValue(t, values["foo/a"], "bar1", version1)
+
+	version2, err := store.Set("foo/b", genProto("bar2"))
+	require.NoError(t, err)
+	_, err = store.Set("other", genProto("bar3"))
+	require.NoError(t, err)
+
+	for len(w.Get()) != 2 {
+		<-w.C()
+	}
+	values = w.Get()
+	verifyValue(t, values["foo/a"], "bar1", version1)
+	verifyValue(t, values["foo/b"], "bar2", version2)
+
+	// Changes to the value of an existing key are watched too.
+	version1, err = store.Set("foo/a", genProto("bar4"))
+	require.NoError(t, err)
+
+	for w.Get()["foo/a"].Version() != version1 {
+		<-w.C()
+	}
+	verifyValue(t, w.Get()["foo/a"], "bar4", version1)
+
+	_, err = store.Delete("foo/b")
+	require.NoError(t, err)
+
+	for len(w.Get()) != 1 {
+		<-w.C()
+	}
+
+	w.Close()
+}
+
+func TestListKeys(t *testing.T) {
+	store, _ := testStore(t)
+
+	for _, key := range []string{"ns/b", "ns/a", "ns/c", "other"} {
+		_, err := store.Set(key, genProto(key))
+		require.NoError(t, err)
+	}
+
+	page, err := store.ListKeys("ns/", 2, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/a", "ns/b"}, page.Keys)
+	require.Equal(t, "ns/b", page.NextPageToken)
+
+	page, err = store.ListKeys("ns/", 2, page.NextPageToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/c"}, page.Keys)
+	require.Empty(t, page.NextPageToken)
+
+	page, err = store.ListKeys("", 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ns/a", "ns/b", "ns/c", "other"}, page.Keys)
+
+	page, err = store.ListKeys("missing/", 10, "")
+	require.NoError(t, err)
+	require.Empty(t, page.Keys)
+}
+
+func testStore(t *testing.T) (kv.Store, *fakeZK) {
+	zk := newFakeZK()
+	opts := NewOptions().
+		SetPrefix("test").
+		SetWatchCheckInterval(10 * time.Millisecond)
+	store, err := NewStore(zk, opts)
+	require.NoError(t, err)
+
+	return store, zk
+}
+
+func verifyValue(t *testing.T, v kv.Value, value string, version int) {
+	var testMsg kvtest.Foo
+	err := v.Unmarshal(&testMsg)
+	require.NoError(t, err)
+	require.Equal(t, value, testMsg.Msg)
+	require.Equal(t, version, v.Version())
+}
+
+func genProto(msg string) proto.Message {
+	return &kvtest.Foo{Msg: msg}
+}
+
+type fakeNode struct {
+	data []byte
+	stat Stat
+	ttl  time.Duration
+}
+
+// fakeZK is an in memory Client with one shot watches.
+type fakeZK struct {
+	sync.Mutex
+
+	zxid         int64
+	nodes        map[string]*fakeNode
+	dataWatches  map[string][]chan Event
+	childWatches map[string][]chan Event
+}
+
+func newFakeZK() *fakeZK {
+	return &fakeZK{
+		nodes:        map[string]*fakeNode{},
+		dataWatches:  map[string][]chan Event{},
+		childWatches: map[string][]chan Event{},
+	}
+}
+
+func (z *fakeZK) Get(p string) ([]byte, Stat, error) {
+	z.Lock()
+	defer z.Unlock()
+
+	n, ok := z.nodes[p]
+	if !ok {
+		return nil, Stat{}, ErrNoNode
+	}
+	return n.data, n.stat, nil
+}
+
+func (z *fakeZK) GetW(p string) ([]byte, Stat, <-chan Event, error) {
+	z.Lock()
+	defer z.Unlock()
+
+	n, ok := z.nodes[p]
+	if !ok {
+		return nil, Stat{}, nil, ErrNoNode
+	}
+	return n.data, n.stat, z.addWatch(z.dataWatches, p), nil
+}
+
+func (z *fakeZK) ExistsW(p string) (bool, Stat, <-chan Event, error) {
+	z.Lock()
+	defer z.Unlock()
+
+	n, ok := z.nodes[p]
+	if !ok {
+		return false, Stat{}, z.addWatch(z.dataWatches, p), nil
+	}
+	return true, n.stat, z.addWatch(z.dataWatches, p), nil
+}
+
+func (z *fakeZK) Children(p string) ([]string, error) {
+	z.Lock()
+	defer z.Unlock()
+
+	return z.children(p)
+}
+
+func (z *fakeZK) ChildrenW(p string) ([]string, <-chan Event, error) {
+	z.Lock()
+	defer z.Unlock()
+
+	children, err := z.children(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	return children, z.addWatch(z.childWatches, p), nil
+}
+
+func (z *fakeZK) Create(p string, data []byte, ttl time.Duration) error {
+	z.Lock()
+	defer z.Unlock()
+
+	if _, ok := z.nodes[p]; ok {
+		return ErrNodeExists
+	}
+	if parent := path.Dir(p); parent != "/" {
+		if _, ok := z.nodes[parent]; !ok {
+			return ErrNoNode
+		}
+	}
+
+	z.zxid++
+	stat := Stat{Mzxid: z.zxid}
+	if ttl > 0 {
+		stat.EphemeralOwner = int64(ttl)
+	}
+	z.nodes[p] = &fakeNode{data: data, stat: stat, ttl: ttl}
+	z.fire(z.dataWatches, p)
+	z.fire(z.childWatches, path.Dir(p))
+	return nil
+}
+
+func (z *fakeZK) Set(p string, data []byte, version int32) (Stat, error) {
+	z.Lock()
+	defer z.Unlock()
+
+	n, ok := z.nodes[p]
+	if !ok {
+		return Stat{}, ErrNoNode
+	}
+	if version != anyVersion && version != n.stat.Version {
+		return Stat{}, ErrBadVersion
+	}
+
+	z.zxid++
+	n.data = data
+	n.stat.Version++
+	n.stat.Mzxid = z.zxid
+	z.fire(z.dataWatches, p)
+	return n.stat, nil
+}
+
+func (z *fakeZK) Delete(p string, version int32) error {
+	z.Lock()
+	defer z.Unlock()
+
+	n, ok := z.nodes[p]
+	if !ok {
+		return ErrNoNode
+	}
+	if version != anyVersion && version != n.stat.Version {
+		return ErrBadVersion
+	}
+
+	z.zxid++
+	delete(z.nodes, p)
+	z.fire(z.dataWatches, p)
+	z.fire(z.childWatches, path.Dir(p))
+	return nil
+}
+
+func (z *fakeZK) ttl(p string) time.Duration {
+	z.Lock()
+	defer z.Unlock()
+
+	return z.nodes[p].ttl
+}
+
+// expireWatches fires all watches with an error as if the session expired.
+func (z *fakeZK) expireWatches() {
+	z.Lock()
+	defer z.Unlock()
+
+	for _, watches := range []map[string][]chan Event{z.dataWatches, z.childWatches} {
+		for p, chs := range watches {
+			for _, ch := range chs {
+				ch <- Event{Path: p, Err: errSessionExpired}
+			}
+			delete(watches, p)
+		}
+	}
+}
+
+func (z *fakeZK) numWatches() int {
+	z.Lock()
+	defer z.Unlock()
+
+	var n int
+	for _, chs := range z.dataWatches {
+		n += len(chs)
+	}
+	return n
+}
+
+func (z *fakeZK) children(p string) ([]string, error) {
+	if _, ok := z.nodes[p]; !ok {
+		return nil, ErrNoNode
+	}
+
+	var children []string
+	for child := range z.nodes {
+		if path.Dir(child) == p {
+			children = append(children, path.Base(child))
+		}
+	}
+	sort.Strings(children)
+	return children, nil
+}
+
+func (z *fakeZK) addWatch(watches map[string][]chan Event, p string) <-chan Event {
+	ch := make(chan Event, 1)
+	watches[p] = append(watches[p], ch)
+	return ch
+}
+
+func (z *fakeZK) fire(watches map[string][]chan Event, p string) {
+	for _, ch := range watches[p] {
+		ch <- Event{Path: p}
+	}
+	delete(watches, p)
+}
@@ -32,15 +32,16 @@ import (
 
 // Configuration is configuration for placement options.
 type Configuration struct {
-	AllowPartialReplace *bool           `yaml:"allowPartialReplace"`
-	AllowAllZones       *bool           `yaml:"allowAllZones"`
-	AddAllCandidates    *bool           `yaml:"addAllCandidates"`
-	IsSharded           *bool           `yaml:"isSharded"`
-	ShardStateMode      *ShardStateMode `yaml:"shardStateMode"`
-	IsMirrored          *bool           `yaml:"isMirrored"`
-	SkipPortMirroring   *bool           `yaml:"skipPortMirroring"`
-	IsStaged            *bool           `yaml:"isStaged"`
-	ValidZone           *string         `yaml:"validZone"`
+	AllowPartialReplace        *bool           `yaml:"allowPartialReplace"`
+	AllowAllZones              *bool           `yaml:"allowAllZones"`
+	AddAllCandidates           *bool           `yaml:"addAllCandidates"`
+	IsSharded                  *bool           `yaml:"isSharded"`
+	ShardStateMode             *ShardStateMode `yaml:"shardStateMode"`
+	ShardStateHistoryMaxEvents *int            `yaml:"shardStateHistoryMaxEvents"`
+	IsMirrored                 *bool           `yaml:"isMirrored"`
+	SkipPortMirroring          *bool           `yaml:"skipPortMirroring"`
+	IsStaged                   *bool           `yaml:"isStaged"`
+	ValidZone                  *string         `yaml:"validZone"`
 }
 
 // NewOptions creates a placement options.
@@ -61,6 +62,9 @@ func (c *Configuration) NewOptions() Options {
 	if value := c.ShardStateMode; value != nil {
 		opts = opts.SetShardStateMode(*value)
 	}
+	if value := c.ShardStateHistoryMaxEvents; value != nil {
+		opts = opts.SetShardStateHistoryMaxEvents(*value)
+	}
 	if value := c.IsMirrored; value != nil {
 		opts = opts.SetIsMirrored(*value)
 	}
@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package history records a bounded history of the shard state transitions
+// of a placement so that they can be reconstructed after an incident.
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/shard"
+)
+
+// Event is a shard state transition of an instance.
+type Event struct {
+	Time             time.Time `json:"time"`
+	PlacementVersion int       `json:"placementVersion"`
+	InstanceID       string    `json:"instanceId"`
+	Shard            uint32    `json:"shard"`
+	// PrevState is empty if the shard was assigned to the instance.
+	PrevState string `json:"prevState,omitempty"`
+	// State is empty if the shard was removed from the instance.
+	State    string `json:"state,omitempty"`
+	SourceID string `json:"sourceId,omitempty"`
+	// Cause is the placement operation that caused the transition.
+	Cause string `json:"cause"`
+}
+
+// Query selects the events returned from the history.
+type Query struct {
+	// InstanceID selects the events of a single instance if set.
+	InstanceID string
+	// Shard selects the events of a single shard if set.
+	Shard *uint32
+	// Start selects the events at or after the time if set.
+	Start time.Time
+	// End selects the events before the time if set.
+	End time.Time
+	// Limit selects only the latest events if positive.
+	Limit int
+}
+
+// Matches returns whether the event is selected by the query.
+func (q Query) Matches(e Event) bool {
+	switch {
+	case q.InstanceID != "" && e.InstanceID != q.InstanceID:
+		return false
+	case q.Shard != nil && e.Shard != *q.Shard:
+		return false
+	case !q.Start.IsZero() && e.Time.Before(q.Start):
+		return false
+	case !q.End.IsZero() && !e.Time.Before(q.End):
+		return false
+	}
+	return true
+}
+
+// Store persists the shard state history of a placement.
+type Store interface {
+	// Record appends the shard state transitions from the prev placement to
+	// the curr placement caused by an operation, evicting the oldest events
+	// once the history is full.
+	Record(cause string, prev, curr placement.Placement) error
+
+	// Events returns the events selected by the query, oldest first.
+	Events(q Query) ([]Event, error)
+}
+
+// Transitions returns the shard state transitions from the prev placement to
+// the curr placement, ordered by instance ID and then by shard ID. A nil prev
+// placement is treated as an empty placement.
+func Transitions(
+	cause string,
+	prev, curr placement.Placement,
+	now time.Time,
+) []Event {
+	var (
+		prevShards = shardsByInstance(prev)
+		currShards = shardsByInstance(curr)
+		ids        = make([]string, 0, len(prevShards)+len(currShards))
+		version    int
+		events     []Event
+	)
+	if curr != nil {
+		version = curr.Version()
+	}
+	for id := range prevShards {
+		ids = append(ids, id)
+	}
+	for id := range currShards {
+		if _, ok := prevShards[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, instanceID := range ids {
+		var (
+			prevInstanceShards = prevShards[instanceID]
+			currInstanceShards = currShards[instanceID]
+			shardIDs           = make([]uint32, 0, len(prevInstanceShards)+len(currInstanceShards))
+		)
+		for id := range prevInstanceShards {
+			shardIDs = append(shardIDs, id)
+		}
+		for id := range currInstanceShards {
+			if _, ok := prevInstanceShards[id]; !ok {
+				shardIDs = append(shardIDs, id)
+			}
+		}
+		sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+		for _, id := range shardIDs {
+			var (
+				prevShard, inPrev = prevInstanceShards[id]
+				currShard, inCurr = currInstanceShards[id]
+				event             = Event{
+					Time:             now,
+					PlacementVersion: version,
+					InstanceID:       instanceID,
+					Shard:            id,
+					Cause:            cause,
+				}
+			)
+			if inPrev && inCurr && prevShard.State() == currShard.State() {
+				continue
+			}
+			if inPrev {
+				event.PrevState = prevShard.State().String()
+				event.SourceID = prevShard.SourceID()
+			}
+			if inCurr {
+				event.State = currShard.State().String()
+				event.SourceID = currShard.SourceID()
+			}
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+func shardsByInstance(p placement.Placement) map[string]map[uint32]shard.Shard {
+	if p == nil {
+		return nil
+	}
+	instances := p.Instances()
+	result := make(map[string]map[uint32]shard.Shard, len(instances))
+	for _, instance := range instances {
+		shards := instance.Shards().All()
+		byID := make(map[uint32]shard.Shard, len(shards))
+		for _, s := range shards {
+			byID[s.ID()] = s
+		}
+		result[instance.ID()] = byID
+	}
+	return result
+}
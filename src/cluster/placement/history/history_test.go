@@ -0,0 +1,110 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/shard"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInstance(id string, shards ...shard.Shard) placement.Instance {
+	return placement.NewEmptyInstance(id, "rack-"+id, "zone", id+":9000", 1).
+		SetShards(shard.NewShards(shards))
+}
+
+func newTestPlacement(version int, instances ...placement.Instance) placement.Placement {
+	return placement.NewPlacement().
+		SetInstances(instances).
+		SetShards([]uint32{0, 1, 2}).
+		SetReplicaFactor(1).
+		SetIsSharded(true).
+		SetVersion(version)
+}
+
+func TestTransitions(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	prev := newTestPlacement(1,
+		newTestInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Available)),
+		newTestInstance("i3",
+			shard.NewShard(2).SetState(shard.Leaving)),
+	)
+	curr := newTestPlacement(2,
+		newTestInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Leaving)),
+		newTestInstance("i2",
+			shard.NewShard(1).SetState(shard.Initializing).SetSourceID("i1")),
+	)
+
+	require.Equal(t, []Event{
+		{
+			Time:             now,
+			PlacementVersion: 2,
+			InstanceID:       "i1",
+			Shard:            1,
+			PrevState:        "Available",
+			State:            "Leaving",
+			Cause:            "replace_instances",
+		},
+		{
+			Time:             now,
+			PlacementVersion: 2,
+			InstanceID:       "i2",
+			Shard:            1,
+			State:            "Initializing",
+			SourceID:         "i1",
+			Cause:            "replace_instances",
+		},
+		{
+			Time:             now,
+			PlacementVersion: 2,
+			InstanceID:       "i3",
+			Shard:            2,
+			PrevState:        "Leaving",
+			Cause:            "replace_instances",
+		},
+	}, Transitions("replace_instances", prev, curr, now))
+
+	require.Len(t, Transitions("build_initial_placement", nil, curr, now), 3)
+	require.Empty(t, Transitions("set", curr, curr, now))
+}
+
+func TestQueryMatches(t *testing.T) {
+	var (
+		now     = time.Unix(1600000000, 0)
+		shardID = uint32(1)
+		event   = Event{Time: now, InstanceID: "i1", Shard: 1}
+	)
+
+	require.True(t, Query{}.Matches(event))
+	require.True(t, Query{InstanceID: "i1", Shard: &shardID}.Matches(event))
+	require.False(t, Query{InstanceID: "i2"}.Matches(event))
+	require.True(t, Query{Start: now, End: now.Add(time.Second)}.Matches(event))
+	require.False(t, Query{Start: now.Add(time.Second)}.Matches(event))
+	require.False(t, Query{End: now}.Matches(event))
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/placement"
+)
+
+// maxRecordAttempts is the number of times recording is attempted when the
+// history is concurrently updated, e.g. by several nodes marking their
+// shards available at once.
+const maxRecordAttempts = 10
+
+var errTooManyConflicts = errors.New("too many conflicting shard state history updates")
+
+type kvStore struct {
+	store kv.Store
+	key   string
+	opts  placement.Options
+}
+
+// NewKVStore returns a Store keeping the history as a single key in a kv
+// store, bounded by the ShardStateHistoryMaxEvents placement option.
+func NewKVStore(store kv.Store, key string, opts placement.Options) Store {
+	if opts == nil {
+		opts = placement.NewOptions()
+	}
+	return &kvStore{
+		store: store,
+		key:   key,
+		opts:  opts,
+	}
+}
+
+func (s *kvStore) Record(cause string, prev, curr placement.Placement) error {
+	maxEvents := s.opts.ShardStateHistoryMaxEvents()
+	if maxEvents <= 0 {
+		return nil
+	}
+
+	events := Transitions(cause, prev, curr, s.opts.NowFn()())
+	if len(events) == 0 {
+		return nil
+	}
+
+	encoded := make([]string, 0, len(events))
+	for _, event := range events {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, string(b))
+	}
+
+	for attempt := 0; attempt < maxRecordAttempts; attempt++ {
+		values, version, err := s.values()
+		if err != nil {
+			return err
+		}
+
+		values = append(values, encoded...)
+		if len(values) > maxEvents {
+			values = values[len(values)-maxEvents:]
+		}
+
+		value := &commonpb.StringArrayProto{Values: values}
+		if version == 0 {
+			_, err = s.store.SetIfNotExists(s.key, value)
+		} else {
+			_, err = s.store.CheckAndSet(s.key, version, value)
+		}
+		if err == kv.ErrAlreadyExists || err == kv.ErrVersionMismatch {
+			continue
+		}
+		return err
+	}
+
+	return errTooManyConflicts
+}
+
+func (s *kvStore) Events(q Query) ([]Event, error) {
+	values, _, err := s.values()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, value := range values {
+		var event Event
+		if err := json.Unmarshal([]byte(value), &event); err != nil {
+			return nil, fmt.Errorf("unable to decode shard state history event: %w", err)
+		}
+		if q.Matches(event) {
+			events = append(events, event)
+		}
+	}
+
+	if q.Limit > 0 && len(events) > q.Limit {
+		events = events[len(events)-q.Limit:]
+	}
+	return events, nil
+}
+
+// values returns the encoded events and the version of the key, which is
+// zero if the key does not exist yet.
+func (s *kvStore) values() ([]string, int, error) {
+	value, err := s.store.Get(s.key)
+	if err == kv.ErrNotFound {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var proto commonpb.StringArrayProto
+	if err := value.Unmarshal(&proto); err != nil {
+		return nil, 0, err
+	}
+	return proto.Values, value.Version(), nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/shard"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVStoreRecord(t *testing.T) {
+	var (
+		now  = time.Unix(1600000000, 0)
+		opts = placement.NewOptions().
+			SetShardStateHistoryMaxEvents(3).
+			SetNowFn(func() time.Time { return now })
+		store = NewKVStore(mem.NewStore(), "history", opts)
+	)
+
+	events, err := store.Events(Query{})
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	initial := newTestPlacement(1,
+		newTestInstance("i1",
+			shard.NewShard(0).SetState(shard.Initializing),
+			shard.NewShard(1).SetState(shard.Initializing)),
+	)
+	require.NoError(t, store.Record("build_initial_placement", nil, initial))
+
+	now = now.Add(time.Minute)
+	available := newTestPlacement(2,
+		newTestInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Available)),
+	)
+	require.NoError(t, store.Record("mark_instance_available", initial, available))
+
+	// The oldest event is evicted once the history is full.
+	events, err = store.Events(Query{})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	// Times are compared separately since they lose their location when
+	// decoded.
+	require.True(t, time.Unix(1600000000, 0).Equal(events[0].Time))
+	events[0].Time = time.Time{}
+	require.Equal(t, Event{
+		PlacementVersion: 1,
+		InstanceID:       "i1",
+		Shard:            1,
+		State:            "Initializing",
+		Cause:            "build_initial_placement",
+	}, events[0])
+	require.Equal(t, "mark_instance_available", events[2].Cause)
+	require.Equal(t, "Available", events[2].State)
+
+	shardID := uint32(0)
+	events, err = store.Events(Query{Shard: &shardID})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "Initializing", events[0].PrevState)
+
+	events, err = store.Events(Query{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, uint32(1), events[0].Shard)
+
+	// Operations that do not transition any shards are not recorded.
+	require.NoError(t, store.Record("set", available, available))
+	events, err = store.Events(Query{})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+}
+
+func TestKVStoreRecordDisabled(t *testing.T) {
+	var (
+		kvStore = mem.NewStore()
+		opts    = placement.NewOptions().SetShardStateHistoryMaxEvents(0)
+		store   = NewKVStore(kvStore, "history", opts)
+	)
+
+	p := newTestPlacement(1,
+		newTestInstance("i1", shard.NewShard(0).SetState(shard.Initializing)))
+	require.NoError(t, store.Record("build_initial_placement", nil, p))
+
+	events, err := store.Events(Query{})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
@@ -36,6 +36,9 @@ const (
 	// By default the zone of the hosts within a placement should match the zone
 	// that the placement was created with.
 	defaultAllowAllZones = false
+	// By default a bounded history of shard state transitions is kept for
+	// post-incident analysis.
+	defaultShardStateHistoryMaxEvents = 2048
 )
 
 type deploymentOptions struct {
@@ -61,6 +64,7 @@ func defaultShardValidationFn(s shard.Shard) error { return nil }
 
 type options struct {
 	shardStateMode      ShardStateMode
+	shardHistoryMax     int
 	iopts               instrument.Options
 	validZone           string
 	placementCutOverFn  TimeNanosFn
@@ -88,6 +92,7 @@ func NewOptions() Options {
 		allowPartialReplace: defaultAllowPartialReplace,
 		isSharded:           defaultIsSharded,
 		shardStateMode:      IncludeTransitionalShardStates,
+		shardHistoryMax:     defaultShardStateHistoryMaxEvents,
 		iopts:               instrument.NewOptions(),
 		placementCutOverFn:  defaultTimeNanosFn,
 		shardCutOverFn:      defaultTimeNanosFn,
@@ -253,6 +258,15 @@ func (o options) SetIsShardCutoffFn(fn ShardValidateFn) Options {
 	return o
 }
 
+func (o options) ShardStateHistoryMaxEvents() int {
+	return o.shardHistoryMax
+}
+
+func (o options) SetShardStateHistoryMaxEvents(value int) Options {
+	o.shardHistoryMax = value
+	return o
+}
+
 func (o options) NowFn() clock.NowFn {
 	return o.nowFn
 }
@@ -44,6 +44,7 @@ func TestPlacementOptions(t *testing.T) {
 		assert.False(t, o.AddAllCandidates())
 		assert.True(t, o.IsSharded())
 		assert.Equal(t, IncludeTransitionalShardStates, o.ShardStateMode())
+		assert.Equal(t, defaultShardStateHistoryMaxEvents, o.ShardStateHistoryMaxEvents())
 		assert.False(t, o.Dryrun())
 		assert.False(t, o.IsMirrored())
 		assert.False(t, o.IsStaged())
@@ -69,6 +70,9 @@ func TestPlacementOptions(t *testing.T) {
 		o = o.SetShardStateMode(StableShardStateOnly)
 		assert.Equal(t, StableShardStateOnly, o.ShardStateMode())
 
+		o = o.SetShardStateHistoryMaxEvents(0)
+		assert.Equal(t, 0, o.ShardStateHistoryMaxEvents())
+
 		o = o.SetDryrun(true)
 		assert.True(t, o.Dryrun())
 
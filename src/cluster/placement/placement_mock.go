@@ -1523,6 +1523,20 @@ func (mr *MockOptionsMockRecorder) SetShardStateMode(value interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShardStateMode", reflect.TypeOf((*MockOptions)(nil).SetShardStateMode), value)
 }
 
+// SetShardStateHistoryMaxEvents mocks base method.
+func (m *MockOptions) SetShardStateHistoryMaxEvents(value int) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShardStateHistoryMaxEvents", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetShardStateHistoryMaxEvents indicates an expected call of SetShardStateHistoryMaxEvents.
+func (mr *MockOptionsMockRecorder) SetShardStateHistoryMaxEvents(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShardStateHistoryMaxEvents", reflect.TypeOf((*MockOptions)(nil).SetShardStateHistoryMaxEvents), value)
+}
+
 // SetSkipPortMirroring mocks base method.
 func (m *MockOptions) SetSkipPortMirroring(v bool) Options {
 	m.ctrl.T.Helper()
@@ -1593,6 +1607,20 @@ func (mr *MockOptionsMockRecorder) ShardCutoverNanosFn() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShardCutoverNanosFn", reflect.TypeOf((*MockOptions)(nil).ShardCutoverNanosFn))
 }
 
+// ShardStateHistoryMaxEvents mocks base method.
+func (m *MockOptions) ShardStateHistoryMaxEvents() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShardStateHistoryMaxEvents")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// ShardStateHistoryMaxEvents indicates an expected call of ShardStateHistoryMaxEvents.
+func (mr *MockOptionsMockRecorder) ShardStateHistoryMaxEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShardStateHistoryMaxEvents", reflect.TypeOf((*MockOptions)(nil).ShardStateHistoryMaxEvents))
+}
+
 // ShardStateMode mocks base method.
 func (m *MockOptions) ShardStateMode() ShardStateMode {
 	m.ctrl.T.Helper()
@@ -0,0 +1,199 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package queue
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+var (
+	errApplierAlreadyOpen = errors.New("placement operation applier is already open")
+	errApplierClosed      = errors.New("placement operation applier is closed")
+	errNilLeader          = errors.New("nil leader")
+	errEmptyApplierID     = errors.New("empty applier id")
+)
+
+type applierMetrics struct {
+	succeeded   tally.Counter
+	failed      tally.Counter
+	applyErrors tally.Counter
+}
+
+func newApplierMetrics(scope tally.Scope) applierMetrics {
+	return applierMetrics{
+		succeeded:   scope.Counter("succeeded"),
+		failed:      scope.Counter("failed"),
+		applyErrors: scope.Counter("apply-errors"),
+	}
+}
+
+type applier struct {
+	sync.Mutex
+
+	queue    Queue
+	service  placement.Service
+	leader   Leader
+	id       string
+	interval time.Duration
+	logger   *zap.Logger
+	metrics  applierMetrics
+
+	opened  bool
+	closed  bool
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewApplier returns an Applier applying the operations of the queue with the
+// placement service while the leader is leading. The ID identifies the applier
+// in the operations it applies, e.g. its hostname.
+func NewApplier(
+	q Queue,
+	svc placement.Service,
+	leader Leader,
+	id string,
+	opts Options,
+) (Applier, error) {
+	if opts == nil {
+		opts = NewOptions()
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if leader == nil {
+		return nil, errNilLeader
+	}
+	if id == "" {
+		return nil, errEmptyApplierID
+	}
+
+	iOpts := opts.PlacementOptions().InstrumentOptions()
+	return &applier{
+		queue:    q,
+		service:  svc,
+		leader:   leader,
+		id:       id,
+		interval: opts.ApplyInterval(),
+		logger:   iOpts.Logger().With(zap.String("applier", id)),
+		metrics:  newApplierMetrics(iOpts.MetricsScope().SubScope("placement-operation-queue")),
+		closeCh:  make(chan struct{}),
+	}, nil
+}
+
+func (a *applier) Open() error {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.closed {
+		return errApplierClosed
+	}
+	if a.opened {
+		return errApplierAlreadyOpen
+	}
+	a.opened = true
+
+	a.wg.Add(1)
+	go a.applyUntilClosed()
+	return nil
+}
+
+func (a *applier) applyUntilClosed() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.closeCh:
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := a.ApplyPending(); err != nil {
+			a.logger.Error("unable to apply pending placement operations", zap.Error(err))
+		}
+	}
+}
+
+func (a *applier) ApplyPending() (int, error) {
+	var applied int
+	for a.leader.IsLeader() {
+		op, ok, err := a.queue.Claim(a.id)
+		if err != nil {
+			return applied, err
+		}
+		if !ok {
+			break
+		}
+
+		var version int
+		p, applyErr := op.apply(a.service)
+		if applyErr == nil && p != nil {
+			version = p.Version()
+		}
+		if _, err := a.queue.Finish(op.ID, version, applyErr); err != nil {
+			// NB: the operation stays in the applying status until the apply
+			// timeout elapses, after which it is marked as failed.
+			a.metrics.applyErrors.Inc(1)
+			return applied, err
+		}
+
+		applied++
+		if applyErr != nil {
+			a.metrics.failed.Inc(1)
+			a.logger.Warn("placement operation failed",
+				zap.String("id", op.ID),
+				zap.String("type", string(op.Type)),
+				zap.String("requestedBy", op.RequestedBy),
+				zap.Error(applyErr))
+			continue
+		}
+		a.metrics.succeeded.Inc(1)
+		a.logger.Info("placement operation applied",
+			zap.String("id", op.ID),
+			zap.String("type", string(op.Type)),
+			zap.String("requestedBy", op.RequestedBy),
+			zap.Int("placementVersion", version))
+	}
+	return applied, nil
+}
+
+func (a *applier) Close() error {
+	a.Lock()
+	if a.closed {
+		a.Unlock()
+		return errApplierClosed
+	}
+	a.closed = true
+	close(a.closeCh)
+	a.Unlock()
+
+	a.wg.Wait()
+	return nil
+}
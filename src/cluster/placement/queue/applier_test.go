@@ -0,0 +1,135 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/placementpb"
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestApplierApplyPending(t *testing.T) {
+	q, ps, _ := testQueue(t, NewOptions())
+	leader := &testLeader{}
+	a, err := NewApplier(q, ps, leader, "applier", NewOptions())
+	require.NoError(t, err)
+
+	add, err := q.Enqueue(Operation{
+		Type:        AddInstancesOperation,
+		RequestedBy: "alice",
+		Candidates:  []*placementpb.Instance{testInstanceProto(t, "i3")},
+	})
+	require.NoError(t, err)
+	remove, err := q.Enqueue(Operation{
+		Type:        RemoveInstancesOperation,
+		RequestedBy: "bob",
+		InstanceIDs: []string{"i2"},
+	})
+	require.NoError(t, err)
+
+	// only the leader applies operations.
+	applied, err := a.ApplyPending()
+	require.NoError(t, err)
+	require.Equal(t, 0, applied)
+
+	leader.leader.Store(true)
+	applied, err = a.ApplyPending()
+	require.NoError(t, err)
+	require.Equal(t, 2, applied)
+
+	p, err := ps.Placement()
+	require.NoError(t, err)
+	_, ok := p.Instance("i3")
+	require.True(t, ok)
+
+	add, err = q.Operation(add.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, add.Status)
+	require.Equal(t, "applier", add.AppliedBy)
+
+	remove, err = q.Operation(remove.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, remove.Status)
+	require.Equal(t, p.Version(), remove.PlacementVersion)
+	require.True(t, add.PlacementVersion < remove.PlacementVersion)
+
+	// an operation valid when enqueued fails if the placement changed since.
+	failing, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "carol"})
+	require.NoError(t, err)
+	require.NoError(t, ps.Delete())
+	applied, err = a.ApplyPending()
+	require.NoError(t, err)
+	require.Equal(t, 1, applied)
+
+	failing, err = q.Operation(failing.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, failing.Status)
+	require.NotEmpty(t, failing.Reason)
+}
+
+func TestApplierOpenClose(t *testing.T) {
+	opts := NewOptions().SetApplyInterval(10 * time.Millisecond)
+	q, ps, _ := testQueue(t, opts)
+	leader := &testLeader{}
+	leader.leader.Store(true)
+	a, err := NewApplier(q, ps, leader, "applier", opts)
+	require.NoError(t, err)
+
+	op, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "alice"})
+	require.NoError(t, err)
+
+	require.NoError(t, a.Open())
+	require.Equal(t, errApplierAlreadyOpen, a.Open())
+
+	require.True(t, clock.WaitUntil(func() bool {
+		op, err := q.Operation(op.ID)
+		require.NoError(t, err)
+		return op.Status == StatusSucceeded
+	}, 5*time.Second))
+
+	require.NoError(t, a.Close())
+	require.Equal(t, errApplierClosed, a.Close())
+	require.Equal(t, errApplierClosed, a.Open())
+}
+
+func TestNewApplierValidates(t *testing.T) {
+	q, ps, _ := testQueue(t, NewOptions())
+
+	_, err := NewApplier(q, ps, nil, "applier", NewOptions())
+	require.Equal(t, errNilLeader, err)
+	_, err = NewApplier(q, ps, &testLeader{}, "", NewOptions())
+	require.Equal(t, errEmptyApplierID, err)
+	_, err = NewApplier(q, ps, &testLeader{}, "applier", NewOptions().SetApplyInterval(0))
+	require.Error(t, err)
+}
+
+type testLeader struct {
+	leader atomic.Bool
+}
+
+func (l *testLeader) IsLeader() bool {
+	return l.leader.Load()
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package queue
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m3db/m3/src/cluster/placement"
+)
+
+var (
+	errNoInstanceIDs      = errors.New("operation requires instance ids")
+	errNoCandidates       = errors.New("operation requires candidate instances")
+	errSingleInstanceID   = errors.New("operation requires a single instance id")
+	errUnexpectedInstance = errors.New("operation does not take instances")
+	errNoRequester        = errors.New("operation requires the operator requesting it")
+)
+
+// operations are the placement operations queued operations are applied
+// with, they are implemented by both placement.Service and placement.Operator
+// so that operations can be validated against the current placement in
+// memory before they are queued.
+type operations interface {
+	AddReplica() (placement.Placement, error)
+	AddInstances(candidates []placement.Instance) (placement.Placement, []placement.Instance, error)
+	RemoveInstances(leavingInstanceIDs []string) (placement.Placement, error)
+	ReplaceInstances(
+		leavingInstanceIDs []string,
+		candidates []placement.Instance,
+	) (placement.Placement, []placement.Instance, error)
+	MarkInstanceAvailable(instanceID string) (placement.Placement, error)
+	MarkAllShardsAvailable() (placement.Placement, error)
+	BalanceShards() (placement.Placement, error)
+}
+
+var (
+	_ operations = placement.Service(nil)
+	_ operations = placement.Operator(nil)
+)
+
+// validate validates the arguments of the operation.
+func (op Operation) validate() error {
+	if op.RequestedBy == "" {
+		return errNoRequester
+	}
+
+	var needsIDs, needsCandidates bool
+	switch op.Type {
+	case AddInstancesOperation:
+		needsCandidates = true
+	case RemoveInstancesOperation:
+		needsIDs = true
+	case ReplaceInstancesOperation:
+		needsIDs, needsCandidates = true, true
+	case MarkInstanceAvailableOperation:
+		if len(op.InstanceIDs) != 1 {
+			return errSingleInstanceID
+		}
+		needsIDs = true
+	case AddReplicaOperation, MarkAllShardsAvailableOperation, BalanceShardsOperation:
+	default:
+		return fmt.Errorf("unknown operation type %q", op.Type)
+	}
+
+	switch {
+	case needsIDs && len(op.InstanceIDs) == 0:
+		return errNoInstanceIDs
+	case !needsIDs && len(op.InstanceIDs) != 0:
+		return errUnexpectedInstance
+	case needsCandidates && len(op.Candidates) == 0:
+		return errNoCandidates
+	case !needsCandidates && len(op.Candidates) != 0:
+		return errUnexpectedInstance
+	}
+
+	_, err := op.candidates()
+	return err
+}
+
+func (op Operation) candidates() ([]placement.Instance, error) {
+	if len(op.Candidates) == 0 {
+		return nil, nil
+	}
+	candidates := make([]placement.Instance, 0, len(op.Candidates))
+	for _, pb := range op.Candidates {
+		candidate, err := placement.NewInstanceFromProto(pb)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candidate instance: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// apply applies the operation and returns the resulting placement.
+func (op Operation) apply(o operations) (placement.Placement, error) {
+	candidates, err := op.candidates()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Type {
+	case AddInstancesOperation:
+		p, _, err := o.AddInstances(candidates)
+		return p, err
+	case RemoveInstancesOperation:
+		return o.RemoveInstances(op.InstanceIDs)
+	case ReplaceInstancesOperation:
+		p, _, err := o.ReplaceInstances(op.InstanceIDs, candidates)
+		return p, err
+	case AddReplicaOperation:
+		return o.AddReplica()
+	case MarkInstanceAvailableOperation:
+		if len(op.InstanceIDs) != 1 {
+			return nil, errSingleInstanceID
+		}
+		return o.MarkInstanceAvailable(op.InstanceIDs[0])
+	case MarkAllShardsAvailableOperation:
+		return o.MarkAllShardsAvailable()
+	case BalanceShardsOperation:
+		return o.BalanceShards()
+	default:
+		return nil, fmt.Errorf("unknown operation type %q", op.Type)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package queue
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+)
+
+const (
+	defaultMaxFinishedOperations = 100
+	defaultApplyInterval         = 10 * time.Second
+	defaultApplyTimeout          = 10 * time.Minute
+)
+
+var (
+	errNilPlacementOptions          = errors.New("nil placement options")
+	errInvalidMaxFinishedOperations = errors.New("max finished operations must not be negative")
+	errInvalidApplyInterval         = errors.New("apply interval must be positive")
+	errInvalidApplyTimeout          = errors.New("apply timeout must be positive")
+)
+
+type options struct {
+	placementOpts         placement.Options
+	requireApproval       bool
+	maxFinishedOperations int
+	applyInterval         time.Duration
+	applyTimeout          time.Duration
+}
+
+// NewOptions returns new options of a queue.
+func NewOptions() Options {
+	return &options{
+		placementOpts:         placement.NewOptions(),
+		maxFinishedOperations: defaultMaxFinishedOperations,
+		applyInterval:         defaultApplyInterval,
+		applyTimeout:          defaultApplyTimeout,
+	}
+}
+
+func (o *options) SetPlacementOptions(value placement.Options) Options {
+	opts := *o
+	opts.placementOpts = value
+	return &opts
+}
+
+func (o *options) PlacementOptions() placement.Options {
+	return o.placementOpts
+}
+
+func (o *options) SetRequireApproval(value bool) Options {
+	opts := *o
+	opts.requireApproval = value
+	return &opts
+}
+
+func (o *options) RequireApproval() bool {
+	return o.requireApproval
+}
+
+func (o *options) SetMaxFinishedOperations(value int) Options {
+	opts := *o
+	opts.maxFinishedOperations = value
+	return &opts
+}
+
+func (o *options) MaxFinishedOperations() int {
+	return o.maxFinishedOperations
+}
+
+func (o *options) SetApplyInterval(value time.Duration) Options {
+	opts := *o
+	opts.applyInterval = value
+	return &opts
+}
+
+func (o *options) ApplyInterval() time.Duration {
+	return o.applyInterval
+}
+
+func (o *options) SetApplyTimeout(value time.Duration) Options {
+	opts := *o
+	opts.applyTimeout = value
+	return &opts
+}
+
+func (o *options) ApplyTimeout() time.Duration {
+	return o.applyTimeout
+}
+
+func (o *options) Validate() error {
+	if o.placementOpts == nil {
+		return errNilPlacementOptions
+	}
+	if o.maxFinishedOperations < 0 {
+		return errInvalidMaxFinishedOperations
+	}
+	if o.applyInterval <= 0 {
+		return errInvalidApplyInterval
+	}
+	if o.applyTimeout <= 0 {
+		return errInvalidApplyTimeout
+	}
+	return nil
+}
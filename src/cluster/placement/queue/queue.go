@@ -0,0 +1,364 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/service"
+
+	"github.com/google/uuid"
+)
+
+// maxUpdateAttempts is the number of times updating the queue is attempted
+// when it is concurrently updated by other operators or the applier.
+const maxUpdateAttempts = 10
+
+var (
+	// ErrOperationNotFound is returned when no queued operation has the
+	// given ID.
+	ErrOperationNotFound = errors.New("placement operation not found")
+
+	// ErrSelfApproval is returned when an operator approves an operation
+	// they requested.
+	ErrSelfApproval = errors.New("placement operation must be approved by another operator")
+
+	errNoApprover        = errors.New("operation requires the approving operator")
+	errTooManyConflicts  = errors.New("too many conflicting placement operation queue updates")
+	errAbandonedApplying = errors.New("operation was not finished by its applier before the apply timeout, " +
+		"the placement may or may not have been updated")
+)
+
+type kvQueue struct {
+	store      kv.Store
+	key        string
+	placements placement.Storage
+	opts       Options
+}
+
+// NewKVQueue returns a Queue keeping the operations as a single key in a kv
+// store. Operations are validated against the placement read from the given
+// placement storage before they are queued.
+func NewKVQueue(
+	store kv.Store,
+	key string,
+	placements placement.Storage,
+	opts Options,
+) (Queue, error) {
+	if opts == nil {
+		opts = NewOptions()
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &kvQueue{
+		store:      store,
+		key:        key,
+		placements: placements,
+		opts:       opts,
+	}, nil
+}
+
+func (q *kvQueue) Enqueue(op Operation) (Operation, error) {
+	if err := op.validate(); err != nil {
+		return Operation{}, err
+	}
+	if err := q.dryRun(op); err != nil {
+		return Operation{}, fmt.Errorf("invalid placement operation: %w", err)
+	}
+
+	now := q.opts.PlacementOptions().NowFn()()
+	op.ID = uuid.NewString()
+	op.ApprovedBy, op.AppliedBy, op.ClosedBy, op.Reason = "", "", "", ""
+	op.PlacementVersion = 0
+	op.Status = StatusPending
+	if q.opts.RequireApproval() {
+		op.Status = StatusPendingApproval
+	}
+	op.EnqueuedAt, op.UpdatedAt = now, now
+
+	err := q.update(func(ops []Operation) ([]Operation, error) {
+		return append(ops, op), nil
+	})
+	if err != nil {
+		return Operation{}, err
+	}
+	return op, nil
+}
+
+// dryRun applies the operation to the current placement in memory.
+func (q *kvQueue) dryRun(op Operation) error {
+	curPlacement, err := q.placements.Placement()
+	if err != nil {
+		return err
+	}
+	operator := service.NewPlacementOperator(
+		curPlacement,
+		service.WithPlacementOptions(q.opts.PlacementOptions()),
+	)
+	_, err = op.apply(operator)
+	return err
+}
+
+func (q *kvQueue) Approve(id string, approver string) (Operation, error) {
+	if approver == "" {
+		return Operation{}, errNoApprover
+	}
+	return q.updateOperation(id, func(op *Operation) error {
+		if op.Status != StatusPendingApproval {
+			return fmt.Errorf("placement operation %s is %s, not pending approval", id, op.Status)
+		}
+		if op.RequestedBy == approver {
+			return ErrSelfApproval
+		}
+		op.Status = StatusPending
+		op.ApprovedBy = approver
+		return nil
+	})
+}
+
+func (q *kvQueue) Reject(id string, approver string, reason string) (Operation, error) {
+	if approver == "" {
+		return Operation{}, errNoApprover
+	}
+	return q.updateOperation(id, func(op *Operation) error {
+		if op.Status != StatusPendingApproval {
+			return fmt.Errorf("placement operation %s is %s, not pending approval", id, op.Status)
+		}
+		op.Status = StatusRejected
+		op.ClosedBy = approver
+		op.Reason = reason
+		return nil
+	})
+}
+
+func (q *kvQueue) Cancel(id string, operator string) (Operation, error) {
+	if operator == "" {
+		return Operation{}, errNoRequester
+	}
+	return q.updateOperation(id, func(op *Operation) error {
+		if op.Status != StatusPendingApproval && op.Status != StatusPending {
+			return fmt.Errorf("placement operation %s is %s and can no longer be cancelled", id, op.Status)
+		}
+		op.Status = StatusCancelled
+		op.ClosedBy = operator
+		return nil
+	})
+}
+
+func (q *kvQueue) Operation(id string) (Operation, error) {
+	ops, _, err := q.operations()
+	if err != nil {
+		return Operation{}, err
+	}
+	for _, op := range ops {
+		if op.ID == id {
+			return op, nil
+		}
+	}
+	return Operation{}, ErrOperationNotFound
+}
+
+func (q *kvQueue) Operations() ([]Operation, error) {
+	ops, _, err := q.operations()
+	return ops, err
+}
+
+func (q *kvQueue) Claim(applier string) (Operation, bool, error) {
+	var (
+		claimed Operation
+		found   bool
+	)
+	err := q.update(func(ops []Operation) ([]Operation, error) {
+		now := q.opts.PlacementOptions().NowFn()()
+		claimed, found = Operation{}, false
+
+		var (
+			next    = -1
+			changed bool
+		)
+		for i := range ops {
+			op := &ops[i]
+			switch op.Status {
+			case StatusApplying:
+				if now.Sub(op.UpdatedAt) < q.opts.ApplyTimeout() {
+					// Operations are applied one at a time.
+					return nil, nil
+				}
+				op.Status = StatusFailed
+				op.Reason = errAbandonedApplying.Error()
+				op.UpdatedAt = now
+				changed = true
+			case StatusPending:
+				if next < 0 {
+					next = i
+				}
+			}
+		}
+		if next >= 0 {
+			ops[next].Status = StatusApplying
+			ops[next].AppliedBy = applier
+			ops[next].UpdatedAt = now
+			claimed, found = ops[next], true
+			changed = true
+		}
+		if !changed {
+			return nil, nil
+		}
+		return ops, nil
+	})
+	if err != nil {
+		return Operation{}, false, err
+	}
+	return claimed, found, nil
+}
+
+func (q *kvQueue) Finish(id string, placementVersion int, applyErr error) (Operation, error) {
+	return q.updateOperation(id, func(op *Operation) error {
+		if op.Status != StatusApplying {
+			return fmt.Errorf("placement operation %s is %s, not applying", id, op.Status)
+		}
+		if applyErr != nil {
+			op.Status = StatusFailed
+			op.Reason = applyErr.Error()
+			return nil
+		}
+		op.Status = StatusSucceeded
+		op.PlacementVersion = placementVersion
+		return nil
+	})
+}
+
+// updateOperation updates the operation with the given ID.
+func (q *kvQueue) updateOperation(id string, fn func(op *Operation) error) (Operation, error) {
+	var updated Operation
+	err := q.update(func(ops []Operation) ([]Operation, error) {
+		for i := range ops {
+			if ops[i].ID != id {
+				continue
+			}
+			if err := fn(&ops[i]); err != nil {
+				return nil, err
+			}
+			ops[i].UpdatedAt = q.opts.PlacementOptions().NowFn()()
+			updated = ops[i]
+			return ops, nil
+		}
+		return nil, ErrOperationNotFound
+	})
+	if err != nil {
+		return Operation{}, err
+	}
+	return updated, nil
+}
+
+// update applies fn to the queued operations and writes the result, retrying
+// on conflicting updates. The queue is left as is if fn returns nil.
+func (q *kvQueue) update(fn func(ops []Operation) ([]Operation, error)) error {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		ops, version, err := q.operations()
+		if err != nil {
+			return err
+		}
+
+		ops, err = fn(ops)
+		if err != nil {
+			return err
+		}
+		if ops == nil {
+			return nil
+		}
+
+		value, err := q.encode(ops)
+		if err != nil {
+			return err
+		}
+		if version == 0 {
+			_, err = q.store.SetIfNotExists(q.key, value)
+		} else {
+			_, err = q.store.CheckAndSet(q.key, version, value)
+		}
+		if err == kv.ErrAlreadyExists || err == kv.ErrVersionMismatch {
+			continue
+		}
+		return err
+	}
+
+	return errTooManyConflicts
+}
+
+// encode encodes the operations, keeping only the most recently finished
+// operations.
+func (q *kvQueue) encode(ops []Operation) (*commonpb.StringArrayProto, error) {
+	var finished int
+	for _, op := range ops {
+		if op.Status.IsFinished() {
+			finished++
+		}
+	}
+	evict := finished - q.opts.MaxFinishedOperations()
+
+	values := make([]string, 0, len(ops))
+	for _, op := range ops {
+		if evict > 0 && op.Status.IsFinished() {
+			evict--
+			continue
+		}
+		b, err := json.Marshal(op)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	}
+	return &commonpb.StringArrayProto{Values: values}, nil
+}
+
+// operations returns the queued operations and the version of the key, which
+// is zero if the key does not exist yet.
+func (q *kvQueue) operations() ([]Operation, int, error) {
+	value, err := q.store.Get(q.key)
+	if err == kv.ErrNotFound {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var proto commonpb.StringArrayProto
+	if err := value.Unmarshal(&proto); err != nil {
+		return nil, 0, err
+	}
+
+	ops := make([]Operation, 0, len(proto.Values))
+	for _, v := range proto.Values {
+		var op Operation
+		if err := json.Unmarshal([]byte(v), &op); err != nil {
+			return nil, 0, fmt.Errorf("unable to decode placement operation: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, value.Version(), nil
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/placementpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/service"
+	"github.com/m3db/m3/src/cluster/placement/storage"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueEnqueueValidates(t *testing.T) {
+	q, _, _ := testQueue(t, NewOptions())
+
+	for _, op := range []Operation{
+		{Type: BalanceShardsOperation},
+		{Type: "unknown", RequestedBy: "alice"},
+		{Type: RemoveInstancesOperation, RequestedBy: "alice"},
+		{Type: AddInstancesOperation, RequestedBy: "alice"},
+		{Type: AddReplicaOperation, RequestedBy: "alice", InstanceIDs: []string{"i1"}},
+		{Type: MarkInstanceAvailableOperation, RequestedBy: "alice", InstanceIDs: []string{"i1", "i2"}},
+		// the dry run against the current placement fails.
+		{Type: RemoveInstancesOperation, RequestedBy: "alice", InstanceIDs: []string{"unknown"}},
+	} {
+		_, err := q.Enqueue(op)
+		require.Error(t, err, "type %s", op.Type)
+	}
+
+	ops, err := q.Operations()
+	require.NoError(t, err)
+	require.Empty(t, ops)
+
+	op, err := q.Enqueue(Operation{
+		Type:        AddInstancesOperation,
+		RequestedBy: "alice",
+		Candidates:  []*placementpb.Instance{testInstanceProto(t, "i3")},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, op.ID)
+	require.Equal(t, StatusPending, op.Status)
+	require.Equal(t, "alice", op.RequestedBy)
+	require.False(t, op.EnqueuedAt.IsZero())
+
+	stored, err := q.Operation(op.ID)
+	require.NoError(t, err)
+	require.Equal(t, op.ID, stored.ID)
+	require.Equal(t, "i3", stored.Candidates[0].Id)
+
+	_, err = q.Operation("unknown")
+	require.Equal(t, ErrOperationNotFound, err)
+}
+
+func TestQueueApproval(t *testing.T) {
+	q, _, _ := testQueue(t, NewOptions().SetRequireApproval(true))
+
+	op, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, StatusPendingApproval, op.Status)
+
+	// operations pending approval are not applied.
+	_, ok, err := q.Claim("applier")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = q.Approve(op.ID, "alice")
+	require.Equal(t, ErrSelfApproval, err)
+	_, err = q.Approve(op.ID, "")
+	require.Error(t, err)
+
+	op, err = q.Approve(op.ID, "bob")
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, op.Status)
+	require.Equal(t, "bob", op.ApprovedBy)
+
+	_, err = q.Approve(op.ID, "carol")
+	require.Error(t, err)
+	_, err = q.Reject(op.ID, "carol", "too late")
+	require.Error(t, err)
+
+	rejected, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "alice"})
+	require.NoError(t, err)
+	rejected, err = q.Reject(rejected.ID, "bob", "not now")
+	require.NoError(t, err)
+	require.Equal(t, StatusRejected, rejected.Status)
+	require.Equal(t, "bob", rejected.ClosedBy)
+	require.Equal(t, "not now", rejected.Reason)
+
+	cancelled, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "alice"})
+	require.NoError(t, err)
+	cancelled, err = q.Cancel(cancelled.ID, "alice")
+	require.NoError(t, err)
+	require.Equal(t, StatusCancelled, cancelled.Status)
+	_, err = q.Approve(cancelled.ID, "bob")
+	require.Error(t, err)
+
+	ops, err := q.Operations()
+	require.NoError(t, err)
+	require.Equal(t, []Status{StatusPending, StatusRejected, StatusCancelled}, statuses(ops))
+}
+
+func TestQueueClaimAppliesOneAtATime(t *testing.T) {
+	q, _, _ := testQueue(t, NewOptions())
+
+	first, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "alice"})
+	require.NoError(t, err)
+	second, err := q.Enqueue(Operation{Type: MarkAllShardsAvailableOperation, RequestedBy: "bob"})
+	require.NoError(t, err)
+
+	claimed, ok, err := q.Claim("applier")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, first.ID, claimed.ID)
+	require.Equal(t, StatusApplying, claimed.Status)
+	require.Equal(t, "applier", claimed.AppliedBy)
+
+	// the first operation is still being applied.
+	_, ok, err = q.Claim("other")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = q.Cancel(first.ID, "alice")
+	require.Error(t, err)
+
+	finished, err := q.Finish(first.ID, 3, nil)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, finished.Status)
+	require.Equal(t, 3, finished.PlacementVersion)
+
+	claimed, ok, err = q.Claim("applier")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, second.ID, claimed.ID)
+
+	finished, err = q.Finish(second.ID, 0, errors.New("boom"))
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, finished.Status)
+	require.Equal(t, "boom", finished.Reason)
+
+	_, err = q.Finish(second.ID, 0, nil)
+	require.Error(t, err)
+
+	_, ok, err = q.Claim("applier")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestQueueClaimAbandonedOperation(t *testing.T) {
+	now := time.Now()
+	opts := NewOptions().SetApplyTimeout(time.Minute)
+	opts = opts.SetPlacementOptions(opts.PlacementOptions().SetNowFn(func() time.Time { return now }))
+	q, _, _ := testQueue(t, opts)
+
+	first, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "alice"})
+	require.NoError(t, err)
+	second, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "alice"})
+	require.NoError(t, err)
+
+	_, ok, err := q.Claim("crashed")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	now = now.Add(time.Minute)
+	claimed, ok, err := q.Claim("applier")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, second.ID, claimed.ID)
+
+	abandoned, err := q.Operation(first.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, abandoned.Status)
+	require.Equal(t, errAbandonedApplying.Error(), abandoned.Reason)
+}
+
+func TestQueueKeepsRecentlyFinishedOperations(t *testing.T) {
+	q, _, _ := testQueue(t, NewOptions().SetMaxFinishedOperations(1))
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		op, err := q.Enqueue(Operation{Type: BalanceShardsOperation, RequestedBy: "alice"})
+		require.NoError(t, err)
+		ids = append(ids, op.ID)
+	}
+	for _, id := range ids[:2] {
+		_, err := q.Cancel(id, "alice")
+		require.NoError(t, err)
+	}
+
+	ops, err := q.Operations()
+	require.NoError(t, err)
+	require.Len(t, ops, 2)
+	require.Equal(t, ids[1], ops[0].ID)
+	require.Equal(t, ids[2], ops[1].ID)
+	require.Equal(t, []Status{StatusCancelled, StatusPending}, statuses(ops))
+}
+
+func TestOptionsValidate(t *testing.T) {
+	require.NoError(t, NewOptions().Validate())
+	require.Error(t, NewOptions().SetPlacementOptions(nil).Validate())
+	require.Error(t, NewOptions().SetMaxFinishedOperations(-1).Validate())
+	require.Error(t, NewOptions().SetApplyInterval(0).Validate())
+	require.Error(t, NewOptions().SetApplyTimeout(0).Validate())
+}
+
+func testQueue(t *testing.T, opts Options) (Queue, placement.Service, placement.Options) {
+	store := mem.NewStore()
+	pOpts := opts.PlacementOptions().SetValidZone("z1")
+	ps := service.NewPlacementService(
+		storage.NewPlacementStorage(store, "placement", pOpts),
+		service.WithPlacementOptions(pOpts),
+	)
+	_, err := ps.BuildInitialPlacement([]placement.Instance{
+		placement.NewEmptyInstance("i1", "r1", "z1", "endpoint1", 1),
+		placement.NewEmptyInstance("i2", "r2", "z1", "endpoint2", 1),
+	}, 8, 1)
+	require.NoError(t, err)
+
+	q, err := NewKVQueue(store, "placement_operations", ps, opts.SetPlacementOptions(pOpts))
+	require.NoError(t, err)
+	return q, ps, pOpts
+}
+
+func testInstanceProto(t *testing.T, id string) *placementpb.Instance {
+	pb, err := placement.NewEmptyInstance(id, "r-"+id, "z1", "endpoint-"+id, 1).Proto()
+	require.NoError(t, err)
+	return pb
+}
+
+func statuses(ops []Operation) []Status {
+	res := make([]Status, 0, len(ops))
+	for _, op := range ops {
+		res = append(res, op.Status)
+	}
+	return res
+}
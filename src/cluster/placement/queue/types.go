@@ -0,0 +1,205 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package queue provides a queue of placement operations so that placement
+// changes requested by different operators are validated, optionally approved
+// by a second operator, and applied one at a time by a single leader instead
+// of racing each other.
+package queue
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/placementpb"
+	"github.com/m3db/m3/src/cluster/placement"
+)
+
+// OperationType is the type of a placement operation.
+type OperationType string
+
+// List of supported operation types.
+const (
+	AddInstancesOperation           OperationType = "add_instances"
+	RemoveInstancesOperation        OperationType = "remove_instances"
+	ReplaceInstancesOperation       OperationType = "replace_instances"
+	AddReplicaOperation             OperationType = "add_replica"
+	MarkInstanceAvailableOperation  OperationType = "mark_instance_available"
+	MarkAllShardsAvailableOperation OperationType = "mark_all_shards_available"
+	BalanceShardsOperation          OperationType = "balance_shards"
+)
+
+// Status is the status of a queued operation.
+type Status string
+
+// List of operation statuses.
+const (
+	// StatusPendingApproval is the status of an operation waiting to be
+	// approved by a second operator.
+	StatusPendingApproval Status = "pending_approval"
+	// StatusPending is the status of an operation waiting to be applied.
+	StatusPending Status = "pending"
+	// StatusApplying is the status of the operation being applied.
+	StatusApplying Status = "applying"
+	// StatusSucceeded is the status of an operation applied successfully.
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed is the status of an operation which could not be applied.
+	StatusFailed Status = "failed"
+	// StatusRejected is the status of an operation rejected by an approver.
+	StatusRejected Status = "rejected"
+	// StatusCancelled is the status of an operation cancelled before it was
+	// applied.
+	StatusCancelled Status = "cancelled"
+)
+
+// IsFinished returns whether the status is final.
+func (s Status) IsFinished() bool {
+	switch s {
+	case StatusSucceeded, StatusFailed, StatusRejected, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Operation is a queued placement operation.
+type Operation struct {
+	// ID is assigned when the operation is enqueued.
+	ID   string        `json:"id"`
+	Type OperationType `json:"type"`
+	// InstanceIDs are the instances removed, replaced or marked available.
+	InstanceIDs []string `json:"instanceIds,omitempty"`
+	// Candidates are the instances to add or to replace instances with.
+	Candidates []*placementpb.Instance `json:"candidates,omitempty"`
+
+	RequestedBy string `json:"requestedBy"`
+	ApprovedBy  string `json:"approvedBy,omitempty"`
+	// AppliedBy identifies the applier which applied the operation.
+	AppliedBy string `json:"appliedBy,omitempty"`
+	// ClosedBy is the operator who rejected or cancelled the operation.
+	ClosedBy string `json:"closedBy,omitempty"`
+	// Reason describes why the operation was rejected or failed.
+	Reason string `json:"reason,omitempty"`
+
+	Status     Status    `json:"status"`
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	// PlacementVersion is the version of the placement written by the
+	// operation once it succeeded.
+	PlacementVersion int `json:"placementVersion,omitempty"`
+}
+
+// Queue is a persisted queue of placement operations.
+type Queue interface {
+	// Enqueue validates the operation against the current placement and
+	// queues it, pending approval if approvals are required.
+	Enqueue(op Operation) (Operation, error)
+
+	// Approve approves an operation pending approval, the approver must not
+	// be the operator who requested it.
+	Approve(id string, approver string) (Operation, error)
+
+	// Reject rejects an operation pending approval.
+	Reject(id string, approver string, reason string) (Operation, error)
+
+	// Cancel cancels an operation which has not been applied yet.
+	Cancel(id string, operator string) (Operation, error)
+
+	// Operation returns the operation with the given ID.
+	Operation(id string) (Operation, error)
+
+	// Operations returns the queued operations and the most recently finished
+	// ones, in the order they were enqueued.
+	Operations() ([]Operation, error)
+
+	// Claim marks the oldest pending operation as being applied by the given
+	// applier and returns it, or returns false if there is no pending
+	// operation or another operation is still being applied. An operation
+	// being applied for longer than the apply timeout is marked as failed.
+	Claim(applier string) (Operation, bool, error)
+
+	// Finish marks an operation being applied as succeeded with the version
+	// of the placement it wrote, or as failed if applyErr is not nil.
+	Finish(id string, placementVersion int, applyErr error) (Operation, error)
+}
+
+// Leader reports whether the process is the leader allowed to apply queued
+// operations, it is typically an elector.Elector.
+type Leader interface {
+	// IsLeader returns whether the process is currently the leader.
+	IsLeader() bool
+}
+
+// Applier applies the pending operations of a queue one at a time, in the
+// order they were enqueued, while it is the leader.
+type Applier interface {
+	// Open starts applying pending operations.
+	Open() error
+
+	// ApplyPending applies the pending operations until none is left and
+	// returns the number of operations applied, successfully or not.
+	ApplyPending() (int, error)
+
+	// Close stops applying pending operations.
+	Close() error
+}
+
+// Options are the options of a queue and its applier.
+type Options interface {
+	// SetPlacementOptions sets the options of the placement operations.
+	SetPlacementOptions(value placement.Options) Options
+
+	// PlacementOptions returns the options of the placement operations.
+	PlacementOptions() placement.Options
+
+	// SetRequireApproval sets whether operations must be approved by a
+	// second operator before they are applied.
+	SetRequireApproval(value bool) Options
+
+	// RequireApproval returns whether operations must be approved by a
+	// second operator before they are applied.
+	RequireApproval() bool
+
+	// SetMaxFinishedOperations sets the number of finished operations kept
+	// in the queue for status tracking.
+	SetMaxFinishedOperations(value int) Options
+
+	// MaxFinishedOperations returns the number of finished operations kept
+	// in the queue for status tracking.
+	MaxFinishedOperations() int
+
+	// SetApplyInterval sets the interval at which the applier checks for
+	// pending operations.
+	SetApplyInterval(value time.Duration) Options
+
+	// ApplyInterval returns the interval at which the applier checks for
+	// pending operations.
+	ApplyInterval() time.Duration
+
+	// SetApplyTimeout sets the duration after which an operation still being
+	// applied is considered abandoned, e.g. because its applier crashed, and
+	// marked as failed so the queue can make progress.
+	SetApplyTimeout(value time.Duration) Options
+
+	// ApplyTimeout returns the duration after which an operation still being
+	// applied is considered abandoned.
+	ApplyTimeout() time.Duration
+
+	// Validate validates the options.
+	Validate() error
+}
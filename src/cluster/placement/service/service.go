@@ -23,8 +23,10 @@ package service
 import (
 	"fmt"
 
+	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/cluster/placement"
 	"github.com/m3db/m3/src/cluster/placement/algo"
+	"github.com/m3db/m3/src/cluster/placement/history"
 	"github.com/m3db/m3/src/cluster/placement/selector"
 	"github.com/m3db/m3/src/cluster/shard"
 	"go.uber.org/zap"
@@ -46,9 +48,83 @@ func NewPlacementService(s placement.Storage, opts ...Option) placement.Service
 	}
 }
 
+// Set writes a placement and records the shard state transitions from the
+// current placement.
+func (ps *placementService) Set(p placement.Placement) (placement.Placement, error) {
+	prevPlacement, ok := ps.placementForHistory()
+	newPlacement, err := ps.Storage.Set(p)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		ps.recordHistory(causeSet, prevPlacement, newPlacement)
+	}
+	return newPlacement, nil
+}
+
+// CheckAndSet writes a placement if the current version matches the expected
+// version and records the shard state transitions from the current placement.
+func (ps *placementService) CheckAndSet(
+	p placement.Placement,
+	version int,
+) (placement.Placement, error) {
+	prevPlacement, ok := ps.placementForHistory()
+	newPlacement, err := ps.Storage.CheckAndSet(p, version)
+	if err != nil {
+		return nil, err
+	}
+	// The placement may have changed between reading and writing it.
+	if ok && prevPlacement != nil && prevPlacement.Version() == version {
+		ps.recordHistory(causeSet, prevPlacement, newPlacement)
+	}
+	return newPlacement, nil
+}
+
+// SetIfNotExist writes a placement if none exists and records the shard state
+// transitions of its shards.
+func (ps *placementService) SetIfNotExist(p placement.Placement) (placement.Placement, error) {
+	newPlacement, err := ps.Storage.SetIfNotExist(p)
+	if err != nil {
+		return nil, err
+	}
+	ps.recordHistory(causeSet, nil, newPlacement)
+	return newPlacement, nil
+}
+
+// placementForHistory returns the current placement to record shard state
+// transitions from, or false if they should not be recorded.
+func (ps *placementService) placementForHistory() (placement.Placement, bool) {
+	if ps.history == nil || ps.opts.Dryrun() {
+		return nil, false
+	}
+	p, err := ps.Storage.Placement()
+	if err == kv.ErrNotFound {
+		return nil, true
+	}
+	if err != nil {
+		return nil, false
+	}
+	return p, true
+}
+
+// Causes of shard state transitions recorded in the shard state history.
+const (
+	causeBuildInitialPlacement  = "build_initial_placement"
+	causeAddReplica             = "add_replica"
+	causeAddInstances           = "add_instances"
+	causeRemoveInstances        = "remove_instances"
+	causeReplaceInstances       = "replace_instances"
+	causeMarkShardsAvailable    = "mark_shards_available"
+	causeMarkInstanceAvailable  = "mark_instance_available"
+	causeMarkAllShardsAvailable = "mark_all_shards_available"
+	causeBalanceShards          = "balance_shards"
+	causeSet                    = "set"
+)
+
 type options struct {
 	placementAlgorithm placement.Algorithm
 	placementOpts      placement.Options
+	history            history.Store
 }
 
 // Option is an interface for PlacementService options.
@@ -82,6 +158,20 @@ func WithPlacementOptions(opts placement.Options) Option {
 	return &placementOptionsOption{opts: opts}
 }
 
+type historyOption struct {
+	history history.Store
+}
+
+func (a *historyOption) apply(opts *options) {
+	opts.history = a.history
+}
+
+// WithShardStateHistory sets the store PlacementService records the shard
+// state transitions of placement updates to.
+func WithShardStateHistory(h history.Store) Option {
+	return &historyOption{history: h}
+}
+
 func newPlacementServiceImpl(
 	storage minimalPlacementStorage,
 	opts ...Option,
@@ -108,6 +198,7 @@ func newPlacementServiceImpl(
 		opts:     o.placementOpts,
 		algo:     o.placementAlgorithm,
 		selector: instanceSelector,
+		history:  o.history,
 		logger:   o.placementOpts.InstrumentOptions().Logger(),
 	}
 }
@@ -139,6 +230,7 @@ type placementServiceImpl struct {
 	opts     placement.Options
 	algo     placement.Algorithm
 	selector placement.InstanceSelector
+	history  history.Store
 	logger   *zap.Logger
 }
 
@@ -174,7 +266,12 @@ func (ps *placementServiceImpl) BuildInitialPlacement(
 		return nil, err
 	}
 
-	return ps.store.SetIfNotExist(tempPlacement)
+	newPlacement, err := ps.store.SetIfNotExist(tempPlacement)
+	if err != nil {
+		return nil, err
+	}
+	ps.recordHistory(causeBuildInitialPlacement, nil, newPlacement)
+	return newPlacement, nil
 }
 
 func (ps *placementServiceImpl) AddReplica() (placement.Placement, error) {
@@ -196,7 +293,7 @@ func (ps *placementServiceImpl) AddReplica() (placement.Placement, error) {
 		return nil, err
 	}
 
-	return ps.store.CheckAndSet(tempPlacement, curPlacement.Version())
+	return ps.checkAndSet(causeAddReplica, curPlacement, tempPlacement)
 }
 
 func (ps *placementServiceImpl) AddInstances(
@@ -233,7 +330,7 @@ func (ps *placementServiceImpl) AddInstances(
 		addingInstances[i] = addingInstance
 	}
 
-	newPlacement, err := ps.store.CheckAndSet(tempPlacement, curPlacement.Version())
+	newPlacement, err := ps.checkAndSet(causeAddInstances, curPlacement, tempPlacement)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -259,7 +356,7 @@ func (ps *placementServiceImpl) RemoveInstances(instanceIDs []string) (placement
 		return nil, err
 	}
 
-	return ps.store.CheckAndSet(tempPlacement, curPlacement.Version())
+	return ps.checkAndSet(causeRemoveInstances, curPlacement, tempPlacement)
 }
 
 func (ps *placementServiceImpl) ReplaceInstances(
@@ -298,7 +395,7 @@ func (ps *placementServiceImpl) ReplaceInstances(
 		addedInstances = append(addedInstances, addedInstance)
 	}
 
-	newPlacement, err := ps.store.CheckAndSet(tempPlacement, curPlacement.Version())
+	newPlacement, err := ps.checkAndSet(causeReplaceInstances, curPlacement, tempPlacement)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -324,7 +421,7 @@ func (ps *placementServiceImpl) MarkShardsAvailable(instanceID string, shardIDs
 		return nil, err
 	}
 
-	return ps.store.CheckAndSet(tempPlacement, curPlacement.Version())
+	return ps.checkAndSet(causeMarkShardsAvailable, curPlacement, tempPlacement)
 }
 
 func (ps *placementServiceImpl) MarkInstanceAvailable(instanceID string) (placement.Placement, error) {
@@ -357,7 +454,7 @@ func (ps *placementServiceImpl) MarkInstanceAvailable(instanceID string) (placem
 		return nil, err
 	}
 
-	return ps.store.CheckAndSet(tempPlacement, curPlacement.Version())
+	return ps.checkAndSet(causeMarkInstanceAvailable, curPlacement, tempPlacement)
 }
 
 func (ps *placementServiceImpl) MarkAllShardsAvailable() (placement.Placement, error) {
@@ -382,7 +479,7 @@ func (ps *placementServiceImpl) MarkAllShardsAvailable() (placement.Placement, e
 		return nil, err
 	}
 
-	return ps.store.CheckAndSet(tempPlacement, curPlacement.Version())
+	return ps.checkAndSet(causeMarkAllShardsAvailable, curPlacement, tempPlacement)
 }
 
 func (ps *placementServiceImpl) BalanceShards() (placement.Placement, error) {
@@ -404,5 +501,34 @@ func (ps *placementServiceImpl) BalanceShards() (placement.Placement, error) {
 		return nil, err
 	}
 
-	return ps.store.CheckAndSet(tempPlacement, curPlacement.Version())
+	return ps.checkAndSet(causeBalanceShards, curPlacement, tempPlacement)
+}
+
+// checkAndSet writes the next placement if the current placement has not
+// changed and records the shard state transitions caused by the operation.
+func (ps *placementServiceImpl) checkAndSet(
+	cause string,
+	curPlacement, nextPlacement placement.Placement,
+) (placement.Placement, error) {
+	newPlacement, err := ps.store.CheckAndSet(nextPlacement, curPlacement.Version())
+	if err != nil {
+		return nil, err
+	}
+	ps.recordHistory(cause, curPlacement, newPlacement)
+	return newPlacement, nil
+}
+
+// recordHistory records shard state transitions on a best effort basis since
+// the placement has already been written.
+func (ps *placementServiceImpl) recordHistory(
+	cause string,
+	prevPlacement, newPlacement placement.Placement,
+) {
+	if ps.history == nil || ps.opts.Dryrun() {
+		return
+	}
+	if err := ps.history.Record(cause, prevPlacement, newPlacement); err != nil {
+		ps.logger.Warn("unable to record shard state history",
+			zap.String("cause", cause), zap.Error(err))
+	}
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/m3db/m3/src/cluster/kv/mem"
 	"github.com/m3db/m3/src/cluster/placement"
 	"github.com/m3db/m3/src/cluster/placement/algo"
+	"github.com/m3db/m3/src/cluster/placement/history"
 	"github.com/m3db/m3/src/cluster/placement/storage"
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/stretchr/testify/assert"
@@ -1007,6 +1008,58 @@ func TestBalanceShards(t *testing.T) {
 	assert.Equal(t, expectedInstances, p.Instances())
 }
 
+func TestShardStateHistory(t *testing.T) {
+	var (
+		opts         = placement.NewOptions().SetValidZone("z1")
+		store        = mem.NewStore()
+		shardHistory = history.NewKVStore(store, "history", opts)
+		ps           = NewPlacementService(storage.NewPlacementStorage(store, "placement", opts),
+			WithPlacementOptions(opts), WithShardStateHistory(shardHistory))
+	)
+
+	_, err := ps.BuildInitialPlacement(
+		[]placement.Instance{placement.NewEmptyInstance("i1", "r1", "z1", "endpoint1", 1)},
+		2, 1)
+	require.NoError(t, err)
+
+	p, err := ps.MarkInstanceAvailable("i1")
+	require.NoError(t, err)
+
+	// Placements written directly are recorded as well.
+	instance, ok := p.Clone().Instance("i1")
+	require.True(t, ok)
+	instance.Shards().Add(shard.NewShard(1).SetState(shard.Initializing))
+	_, err = ps.CheckAndSet(p.SetInstances([]placement.Instance{instance}), p.Version())
+	require.NoError(t, err)
+
+	events, err := shardHistory.Events(history.Query{})
+	require.NoError(t, err)
+
+	type transition struct {
+		shard     uint32
+		prevState string
+		state     string
+		cause     string
+	}
+	var transitions []transition
+	for _, e := range events {
+		require.Equal(t, "i1", e.InstanceID)
+		transitions = append(transitions, transition{
+			shard:     e.Shard,
+			prevState: e.PrevState,
+			state:     e.State,
+			cause:     e.Cause,
+		})
+	}
+	require.Equal(t, []transition{
+		{shard: 0, state: "Initializing", cause: causeBuildInitialPlacement},
+		{shard: 1, state: "Initializing", cause: causeBuildInitialPlacement},
+		{shard: 0, prevState: "Initializing", state: "Available", cause: causeMarkInstanceAvailable},
+		{shard: 1, prevState: "Initializing", state: "Available", cause: causeMarkInstanceAvailable},
+		{shard: 1, prevState: "Available", state: "Initializing", cause: causeSet},
+	}, transitions)
+}
+
 func newMockStorage() placement.Storage {
 	return storage.NewPlacementStorage(mem.NewStore(), "", nil)
 }
@@ -0,0 +1,236 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package simulation projects the load and data movement of placement changes
+// without applying them, for capacity planning.
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/algo"
+	"github.com/m3db/m3/src/cluster/shard"
+)
+
+var (
+	errNoChange          = errors.New("placement change adds or removes no instances")
+	errInvalidPacingRate = errors.New("pacing bytes per second must be positive")
+)
+
+// ShardLoad is the load of a single replica of a shard.
+type ShardLoad struct {
+	// Bytes is the size of the shard replica.
+	Bytes int64 `json:"bytes" yaml:"bytes"`
+
+	// WritesPerSecond is the write rate of the shard replica.
+	WritesPerSecond float64 `json:"writesPerSecond" yaml:"writesPerSecond"`
+}
+
+// ShardLoads are the loads of shards keyed by shard ID.
+type ShardLoads map[uint32]ShardLoad
+
+// Change is a placement change to simulate.
+type Change struct {
+	// Add are the instances to add to the placement.
+	Add []placement.Instance
+
+	// Remove are the IDs of the instances to remove from the placement.
+	Remove []string
+}
+
+// Pacing is how fast shard data is moved during a rebalance.
+type Pacing struct {
+	// BytesPerSecond is the rate each instance receives shard data at.
+	BytesPerSecond int64
+
+	// MaxConcurrentInstances is how many instances receive shard data at
+	// the same time, zero means all of them.
+	MaxConcurrentInstances int
+}
+
+// InstanceLoad is the load of an instance, counting its available and
+// initializing shards.
+type InstanceLoad struct {
+	ID              string  `json:"id"`
+	IsolationGroup  string  `json:"isolationGroup"`
+	Zone            string  `json:"zone"`
+	NumShards       int     `json:"numShards"`
+	Bytes           int64   `json:"bytes"`
+	WritesPerSecond float64 `json:"writesPerSecond"`
+
+	// IncomingBytes is the shard data the instance receives for the shards
+	// it starts initializing as part of the change.
+	IncomingBytes int64 `json:"incomingBytes"`
+}
+
+// Result is the projected outcome of a placement change.
+type Result struct {
+	// Placement is the placement after the change.
+	Placement placement.Placement `json:"-"`
+
+	// Current is the per instance load before the change.
+	Current []InstanceLoad `json:"current"`
+
+	// Projected is the per instance load once the change has completed.
+	Projected []InstanceLoad `json:"projected"`
+
+	// MovementBytes is the total shard data moved by the change.
+	MovementBytes int64 `json:"movementBytes"`
+
+	// RebalanceDuration is how long moving the shard data takes under the pacing.
+	RebalanceDuration time.Duration `json:"rebalanceDuration"`
+}
+
+// Simulate applies the change to the placement with the placement algorithm
+// for the given options and projects the resulting per instance load, data
+// movement and rebalance duration. The given placement is not modified.
+func Simulate(
+	p placement.Placement,
+	loads ShardLoads,
+	change Change,
+	pacing Pacing,
+	opts placement.Options,
+) (Result, error) {
+	if len(change.Add) == 0 && len(change.Remove) == 0 {
+		return Result{}, errNoChange
+	}
+	if pacing.BytesPerSecond <= 0 {
+		return Result{}, errInvalidPacingRate
+	}
+	if opts == nil {
+		opts = placement.NewOptions().
+			SetIsSharded(p.IsSharded()).
+			SetIsMirrored(p.IsMirrored())
+	}
+
+	var (
+		a       = algo.NewAlgorithm(opts)
+		updated = p.Clone()
+		err     error
+	)
+	if len(change.Remove) > 0 && len(change.Add) > 0 {
+		updated, err = a.ReplaceInstances(updated, change.Remove, change.Add)
+	} else if len(change.Remove) > 0 {
+		updated, err = a.RemoveInstances(updated, change.Remove)
+	} else {
+		updated, err = a.AddInstances(updated, change.Add)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("unable to apply placement change: %v", err)
+	}
+
+	initializing := make(map[string]map[uint32]struct{}, p.NumInstances())
+	for _, instance := range p.Instances() {
+		shards := make(map[uint32]struct{})
+		for _, s := range instance.Shards().ShardsForState(shard.Initializing) {
+			shards[s.ID()] = struct{}{}
+		}
+		initializing[instance.ID()] = shards
+	}
+
+	result := Result{
+		Placement: updated,
+		Current:   instanceLoads(p, loads, nil),
+		Projected: instanceLoads(updated, loads, initializing),
+	}
+	incoming := make([]int64, 0, len(result.Projected))
+	for _, load := range result.Projected {
+		result.MovementBytes += load.IncomingBytes
+		if load.IncomingBytes > 0 {
+			incoming = append(incoming, load.IncomingBytes)
+		}
+	}
+	result.RebalanceDuration = rebalanceDuration(incoming, pacing)
+	return result, nil
+}
+
+// instanceLoads returns the load of each instance in the placement, sorted by
+// instance ID. Leaving shards are excluded since they are gone once the
+// placement change completes. Initializing shards not in the previously initializing shards
+// of an instance count towards its incoming bytes.
+func instanceLoads(
+	p placement.Placement,
+	loads ShardLoads,
+	prevInitializing map[string]map[uint32]struct{},
+) []InstanceLoad {
+	instances := p.Instances()
+	res := make([]InstanceLoad, 0, len(instances))
+	for _, instance := range instances {
+		load := InstanceLoad{
+			ID:             instance.ID(),
+			IsolationGroup: instance.IsolationGroup(),
+			Zone:           instance.Zone(),
+		}
+		for _, s := range instance.Shards().All() {
+			if s.State() == shard.Leaving {
+				continue
+			}
+			shardLoad := loads[s.ID()]
+			load.NumShards++
+			load.Bytes += shardLoad.Bytes
+			load.WritesPerSecond += shardLoad.WritesPerSecond
+			if prevInitializing == nil || s.State() != shard.Initializing {
+				continue
+			}
+			if _, ok := prevInitializing[instance.ID()][s.ID()]; !ok {
+				load.IncomingBytes += shardLoad.Bytes
+			}
+		}
+		res = append(res, load)
+	}
+	return res
+}
+
+// rebalanceDuration returns how long streaming the incoming bytes of each
+// receiving instance takes, scheduling the largest transfers first onto at
+// most the max concurrent instances.
+func rebalanceDuration(incoming []int64, pacing Pacing) time.Duration {
+	if len(incoming) == 0 {
+		return 0
+	}
+	slots := pacing.MaxConcurrentInstances
+	if slots <= 0 || slots > len(incoming) {
+		slots = len(incoming)
+	}
+	sort.Slice(incoming, func(i, j int) bool {
+		return incoming[i] > incoming[j]
+	})
+	assigned := make([]int64, slots)
+	for _, bytes := range incoming {
+		least := 0
+		for i := range assigned {
+			if assigned[i] < assigned[least] {
+				least = i
+			}
+		}
+		assigned[least] += bytes
+	}
+	var max int64
+	for _, bytes := range assigned {
+		if bytes > max {
+			max = bytes
+		}
+	}
+	return time.Duration(float64(max) / float64(pacing.BytesPerSecond) * float64(time.Second))
+}
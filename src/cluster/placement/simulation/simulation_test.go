@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/algo"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testPlacement(t *testing.T) placement.Placement {
+	instances := []placement.Instance{
+		placement.NewEmptyInstance("i1", "r1", "z1", "endpoint1", 1),
+		placement.NewEmptyInstance("i2", "r2", "z1", "endpoint2", 1),
+		placement.NewEmptyInstance("i3", "r3", "z1", "endpoint3", 1),
+	}
+	shards := make([]uint32, 12)
+	for i := range shards {
+		shards[i] = uint32(i)
+	}
+	a := algo.NewAlgorithm(placement.NewOptions().SetIsSharded(true))
+	p, err := a.InitialPlacement(instances, shards, 1)
+	require.NoError(t, err)
+	p, _, err = a.MarkAllShardsAvailable(p)
+	require.NoError(t, err)
+	return p
+}
+
+func testShardLoads(p placement.Placement) ShardLoads {
+	loads := make(ShardLoads, p.NumShards())
+	for _, id := range p.Shards() {
+		loads[id] = ShardLoad{Bytes: 100, WritesPerSecond: 10}
+	}
+	return loads
+}
+
+func TestSimulateAddInstance(t *testing.T) {
+	p := testPlacement(t)
+	res, err := Simulate(p, testShardLoads(p), Change{
+		Add: []placement.Instance{placement.NewEmptyInstance("i4", "r4", "z1", "endpoint4", 1)},
+	}, Pacing{BytesPerSecond: 10}, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, 3, len(res.Current))
+	for _, load := range res.Current {
+		require.Equal(t, 4, load.NumShards)
+		require.Equal(t, int64(400), load.Bytes)
+		require.Equal(t, float64(40), load.WritesPerSecond)
+		require.Equal(t, int64(0), load.IncomingBytes)
+	}
+
+	require.Equal(t, 4, len(res.Projected))
+	for _, load := range res.Projected {
+		require.Equal(t, 3, load.NumShards)
+		require.Equal(t, int64(300), load.Bytes)
+		if load.ID == "i4" {
+			require.Equal(t, int64(300), load.IncomingBytes)
+		} else {
+			require.Equal(t, int64(0), load.IncomingBytes)
+		}
+	}
+	require.Equal(t, int64(300), res.MovementBytes)
+	require.Equal(t, 30*time.Second, res.RebalanceDuration)
+
+	// the simulated placement is not modified.
+	require.Equal(t, 3, p.NumInstances())
+	require.Equal(t, 4, res.Placement.NumInstances())
+}
+
+func TestSimulateRemoveInstance(t *testing.T) {
+	p := testPlacement(t)
+	res, err := Simulate(p, testShardLoads(p), Change{
+		Remove: []string{"i3"},
+	}, Pacing{BytesPerSecond: 10, MaxConcurrentInstances: 1}, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(400), res.MovementBytes)
+	for _, load := range res.Projected {
+		if load.ID == "i3" {
+			require.Equal(t, 0, load.NumShards)
+			continue
+		}
+		require.Equal(t, 6, load.NumShards)
+		require.Equal(t, int64(200), load.IncomingBytes)
+	}
+	// the two receiving instances stream one at a time.
+	require.Equal(t, 40*time.Second, res.RebalanceDuration)
+}
+
+func TestSimulateInvalid(t *testing.T) {
+	p := testPlacement(t)
+	_, err := Simulate(p, testShardLoads(p), Change{}, Pacing{BytesPerSecond: 10}, nil)
+	require.Equal(t, errNoChange, err)
+
+	_, err = Simulate(p, testShardLoads(p), Change{Remove: []string{"i1"}}, Pacing{}, nil)
+	require.Equal(t, errInvalidPacingRate, err)
+
+	_, err = Simulate(p, testShardLoads(p), Change{Remove: []string{"unknown"}}, Pacing{BytesPerSecond: 10}, nil)
+	require.Error(t, err)
+}
+
+func TestRebalanceDuration(t *testing.T) {
+	pacing := Pacing{BytesPerSecond: 1, MaxConcurrentInstances: 2}
+	require.Equal(t, time.Duration(0), rebalanceDuration(nil, pacing))
+	// largest first: 5 and 4 run concurrently, 3 follows 4.
+	require.Equal(t, 7*time.Second, rebalanceDuration([]int64{3, 5, 4}, pacing))
+
+	pacing.MaxConcurrentInstances = 0
+	require.Equal(t, 5*time.Second, rebalanceDuration([]int64{3, 5, 4}, pacing))
+}
@@ -313,6 +313,14 @@ type Options interface {
 	// SetShardStateMode sets ShardStateMode.
 	SetShardStateMode(value ShardStateMode) Options
 
+	// ShardStateHistoryMaxEvents returns the maximum number of shard state
+	// transitions kept in the shard state history of the placement, zero
+	// disables recording the history.
+	ShardStateHistoryMaxEvents() int
+
+	// SetShardStateHistoryMaxEvents sets ShardStateHistoryMaxEvents.
+	SetShardStateHistoryMaxEvents(value int) Options
+
 	// Dryrun will try to perform the placement operation but will not persist the final result.
 	Dryrun() bool
 
@@ -144,31 +144,11 @@ func ServiceWithAlgo(
 	now time.Time,
 	validationFn placement.ValidateFn,
 ) (placement.Service, placement.Algorithm, error) {
-	overrides := services.NewOverrideOptions()
-	switch opts.ServiceName {
-	case handleroptions.M3AggregatorServiceName:
-		overrides = overrides.
-			SetNamespaceOptions(
-				overrides.NamespaceOptions().
-					SetPlacementNamespace(m3AggregatorPlacementNamespace),
-			)
-	}
-
-	cs, err := clusterClient.Services(overrides)
+	cs, err := placementServices(clusterClient, opts)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if err := opts.Validate(); err != nil {
-		return nil, nil, err
-	}
-
-	if !handleroptions.IsAllowedService(opts.ServiceName) {
-		return nil, nil, fmt.Errorf(
-			"invalid service name: %s, must be one of: %v",
-			opts.ServiceName, handleroptions.AllowedServices())
-	}
-
 	sid := opts.ServiceID()
 	pOpts := pConfig.NewOptions().
 		SetValidZone(opts.ServiceZone).
@@ -228,6 +208,40 @@ func ServiceWithAlgo(
 	return ps, alg, nil
 }
 
+// placementServices returns the services client of the placement of the
+// service after validating the service options.
+func placementServices(
+	clusterClient clusterclient.Client,
+	opts handleroptions.ServiceOptions,
+) (services.Services, error) {
+	overrides := services.NewOverrideOptions()
+	switch opts.ServiceName {
+	case handleroptions.M3AggregatorServiceName:
+		overrides = overrides.
+			SetNamespaceOptions(
+				overrides.NamespaceOptions().
+					SetPlacementNamespace(m3AggregatorPlacementNamespace),
+			)
+	}
+
+	cs, err := clusterClient.Services(overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !handleroptions.IsAllowedService(opts.ServiceName) {
+		return nil, fmt.Errorf(
+			"invalid service name: %s, must be one of: %v",
+			opts.ServiceName, handleroptions.AllowedServices())
+	}
+
+	return cs, nil
+}
+
 // ConvertInstancesProto converts a slice of protobuf `Instance`s to `placement.Instance`s
 func ConvertInstancesProto(instancesProto []*placementpb.Instance) ([]placement.Instance, error) {
 	res := make([]placement.Instance, 0, len(instancesProto))
@@ -369,6 +383,36 @@ func MakeRoutes(
 		Methods: []string{SetHTTPMethod},
 	})
 
+	// Events
+	var (
+		eventsHandler = NewEventsHandler(opts)
+		eventsFn      = applyMiddleware(eventsHandler.ServeHTTP, defaults)
+	)
+	routes = append(routes, Route{
+		Paths: []string{
+			M3DBEventsURL,
+			M3AggEventsURL,
+			M3CoordinatorEventsURL,
+		},
+		Handler: eventsFn,
+		Methods: []string{EventsHTTPMethod},
+	})
+
+	// Shard history
+	var (
+		shardHistoryHandler = NewShardHistoryHandler(opts)
+		shardHistoryFn      = applyMiddleware(shardHistoryHandler.ServeHTTP, defaults)
+	)
+	routes = append(routes, Route{
+		Paths: []string{
+			M3DBShardHistoryURL,
+			M3AggShardHistoryURL,
+			M3CoordinatorShardHistoryURL,
+		},
+		Handler: shardHistoryFn,
+		Methods: []string{ShardHistoryHTTPMethod},
+	})
+
 	return routes
 }
 
@@ -0,0 +1,323 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placementhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placementhandler/handleroptions"
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/util/logging"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// EventsHTTPMethod is the HTTP method used with this resource.
+	EventsHTTPMethod = http.MethodGet
+
+	eventsPathName = "events"
+
+	defaultEventsKeepAliveInterval = 30 * time.Second
+)
+
+var (
+	// M3DBEventsURL is the url for the placement events handler (with the
+	// GET method) for the M3DB service.
+	M3DBEventsURL = path.Join(route.Prefix,
+		M3DBServicePlacementPathName, eventsPathName)
+
+	// M3AggEventsURL is the url for the placement events handler (with the
+	// GET method) for the M3Agg service.
+	M3AggEventsURL = path.Join(route.Prefix,
+		M3AggServicePlacementPathName, eventsPathName)
+
+	// M3CoordinatorEventsURL is the url for the placement events handler
+	// (with the GET method) for the M3Coordinator service.
+	M3CoordinatorEventsURL = path.Join(route.Prefix,
+		M3CoordinatorServicePlacementPathName, eventsPathName)
+
+	errStreamingUnsupported = errors.New("response writer does not support streaming")
+)
+
+// TopologyChangeEventType is the type of a topology change event.
+type TopologyChangeEventType string
+
+const (
+	// InstanceAddedEventType is emitted when an instance is added to the
+	// placement.
+	InstanceAddedEventType TopologyChangeEventType = "instance_added"
+	// InstanceRemovedEventType is emitted when an instance is removed from
+	// the placement.
+	InstanceRemovedEventType TopologyChangeEventType = "instance_removed"
+	// ShardAddedEventType is emitted when a shard is assigned to an instance.
+	ShardAddedEventType TopologyChangeEventType = "shard_added"
+	// ShardRemovedEventType is emitted when a shard is no longer assigned to
+	// an instance.
+	ShardRemovedEventType TopologyChangeEventType = "shard_removed"
+	// ShardStateChangedEventType is emitted when the state of a shard
+	// assigned to an instance transitions.
+	ShardStateChangedEventType TopologyChangeEventType = "shard_state_changed"
+)
+
+// TopologyChangeEvent is a structured change between two placement versions.
+type TopologyChangeEvent struct {
+	Type       TopologyChangeEventType `json:"type"`
+	Version    int                     `json:"version"`
+	InstanceID string                  `json:"instanceId"`
+	Shard      *uint32                 `json:"shard,omitempty"`
+	PrevState  string                  `json:"prevState,omitempty"`
+	State      string                  `json:"state,omitempty"`
+}
+
+// TopologyChangeEvents returns the events transitioning the prev placement
+// to the curr placement, ordered by instance ID and then by shard ID. A nil
+// prev placement is treated as an empty placement.
+func TopologyChangeEvents(prev, curr placement.Placement) []TopologyChangeEvent {
+	var (
+		prevInstances = instancesByID(prev)
+		currInstances = instancesByID(curr)
+		ids           = make([]string, 0, len(prevInstances)+len(currInstances))
+		version       int
+		events        []TopologyChangeEvent
+	)
+	if curr != nil {
+		version = curr.Version()
+	}
+	for id := range prevInstances {
+		ids = append(ids, id)
+	}
+	for id := range currInstances {
+		if _, ok := prevInstances[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		var (
+			prevInstance, inPrev = prevInstances[id]
+			currInstance, inCurr = currInstances[id]
+			prevShards           shard.Shards
+			currShards           shard.Shards
+		)
+		switch {
+		case !inPrev:
+			events = append(events, TopologyChangeEvent{
+				Type:       InstanceAddedEventType,
+				Version:    version,
+				InstanceID: id,
+			})
+			prevShards = shard.NewShards(nil)
+			currShards = currInstance.Shards()
+		case !inCurr:
+			prevShards = prevInstance.Shards()
+			currShards = shard.NewShards(nil)
+		default:
+			prevShards = prevInstance.Shards()
+			currShards = currInstance.Shards()
+		}
+
+		events = append(events, shardChangeEvents(version, id, prevShards, currShards)...)
+
+		if !inCurr {
+			events = append(events, TopologyChangeEvent{
+				Type:       InstanceRemovedEventType,
+				Version:    version,
+				InstanceID: id,
+			})
+		}
+	}
+
+	return events
+}
+
+func instancesByID(p placement.Placement) map[string]placement.Instance {
+	if p == nil {
+		return nil
+	}
+	instances := p.Instances()
+	result := make(map[string]placement.Instance, len(instances))
+	for _, instance := range instances {
+		result[instance.ID()] = instance
+	}
+	return result
+}
+
+func shardChangeEvents(
+	version int,
+	instanceID string,
+	prev, curr shard.Shards,
+) []TopologyChangeEvent {
+	ids := prev.AllIDs()
+	for _, id := range curr.AllIDs() {
+		if !prev.Contains(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var events []TopologyChangeEvent
+	for _, id := range ids {
+		var (
+			id                = id
+			prevShard, inPrev = prev.Shard(id)
+			currShard, inCurr = curr.Shard(id)
+		)
+		switch {
+		case !inPrev:
+			events = append(events, TopologyChangeEvent{
+				Type:       ShardAddedEventType,
+				Version:    version,
+				InstanceID: instanceID,
+				Shard:      &id,
+				State:      currShard.State().String(),
+			})
+		case !inCurr:
+			events = append(events, TopologyChangeEvent{
+				Type:       ShardRemovedEventType,
+				Version:    version,
+				InstanceID: instanceID,
+				Shard:      &id,
+				PrevState:  prevShard.State().String(),
+			})
+		case prevShard.State() != currShard.State():
+			events = append(events, TopologyChangeEvent{
+				Type:       ShardStateChangedEventType,
+				Version:    version,
+				InstanceID: instanceID,
+				Shard:      &id,
+				PrevState:  prevShard.State().String(),
+				State:      currShard.State().String(),
+			})
+		}
+	}
+	return events
+}
+
+// EventsHandler is the handler streaming topology change events derived
+// from the placement watch as server-sent events.
+type EventsHandler Handler
+
+// NewEventsHandler returns a new instance of EventsHandler.
+func NewEventsHandler(opts HandlerOptions) *EventsHandler {
+	return &EventsHandler{HandlerOptions: opts, nowFn: time.Now}
+}
+
+func (h *EventsHandler) ServeHTTP(
+	svc handleroptions.ServiceNameAndDefaults,
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	var (
+		ctx    = r.Context()
+		logger = logging.WithContext(ctx, h.instrumentOptions)
+	)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		xhttp.WriteError(w, errStreamingUnsupported)
+		return
+	}
+
+	opts := handleroptions.NewServiceOptions(svc, r.Header, h.m3AggServiceOptions)
+	service, err := Service(h.clusterClient, opts, h.placement, h.nowFn(), nil)
+	if err != nil {
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	// Events are relative to the placement at the time of subscription.
+	prev, err := service.Placement()
+	if err != nil && err != kv.ErrNotFound {
+		logger.Error("unable to get placement", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	watch, err := service.Watch()
+	if err != nil {
+		logger.Error("unable to watch placement", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+	defer watch.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(defaultEventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-watch.C():
+			curr, err := watch.Get()
+			if err != nil {
+				// The placement may not exist yet, keep waiting for updates.
+				logger.Warn("unable to get watched placement", zap.Error(err))
+				continue
+			}
+			if prev != nil && curr != nil && prev.Version() == curr.Version() {
+				continue
+			}
+
+			for _, event := range TopologyChangeEvents(prev, curr) {
+				if err := writeTopologyChangeEvent(w, event); err != nil {
+					logger.Warn("unable to write topology change event", zap.Error(err))
+					return
+				}
+			}
+			flusher.Flush()
+			prev = curr
+		}
+	}
+}
+
+func writeTopologyChangeEvent(w http.ResponseWriter, event TopologyChangeEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n",
+		event.Version, event.Type, data)
+	return err
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placementhandler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/service"
+	"github.com/m3db/m3/src/cluster/placement/storage"
+	"github.com/m3db/m3/src/cluster/placementhandler/handleroptions"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEventsInstance(id string, shards ...shard.Shard) placement.Instance {
+	return placement.NewEmptyInstance(id, "rack-"+id, "zone", id+":9000", 1).
+		SetShards(shard.NewShards(shards))
+}
+
+func newTestEventsPlacement(instances ...placement.Instance) placement.Placement {
+	return placement.NewPlacement().
+		SetInstances(instances).
+		SetShards([]uint32{0, 1}).
+		SetReplicaFactor(1).
+		SetIsSharded(true)
+}
+
+func TestTopologyChangeEvents(t *testing.T) {
+	shardID := func(id uint32) *uint32 { return &id }
+
+	prev := newTestEventsPlacement(
+		newTestEventsInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Available)),
+		newTestEventsInstance("i3",
+			shard.NewShard(2).SetState(shard.Leaving)),
+	).SetVersion(1)
+	curr := newTestEventsPlacement(
+		newTestEventsInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Leaving)),
+		newTestEventsInstance("i2",
+			shard.NewShard(1).SetState(shard.Initializing).SetSourceID("i1")),
+	).SetVersion(2)
+
+	require.Equal(t, []TopologyChangeEvent{
+		{
+			Type:       ShardStateChangedEventType,
+			Version:    2,
+			InstanceID: "i1",
+			Shard:      shardID(1),
+			PrevState:  "Available",
+			State:      "Leaving",
+		},
+		{
+			Type:       InstanceAddedEventType,
+			Version:    2,
+			InstanceID: "i2",
+		},
+		{
+			Type:       ShardAddedEventType,
+			Version:    2,
+			InstanceID: "i2",
+			Shard:      shardID(1),
+			State:      "Initializing",
+		},
+		{
+			Type:       ShardRemovedEventType,
+			Version:    2,
+			InstanceID: "i3",
+			Shard:      shardID(2),
+			PrevState:  "Leaving",
+		},
+		{
+			Type:       InstanceRemovedEventType,
+			Version:    2,
+			InstanceID: "i3",
+		},
+	}, TopologyChangeEvents(prev, curr))
+
+	require.Empty(t, TopologyChangeEvents(curr, curr))
+
+	// A missing previous placement reports every instance and shard as added.
+	require.Len(t, TopologyChangeEvents(nil, curr), 5)
+}
+
+func TestPlacementEventsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		store        = mem.NewStore()
+		mockClient   = client.NewMockClient(ctrl)
+		mockServices = services.NewMockServices(ctrl)
+	)
+	mockClient.EXPECT().Services(gomock.Any()).Return(mockServices, nil).AnyTimes()
+	mockServices.EXPECT().PlacementService(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, opts placement.Options) (placement.Service, error) {
+			return service.NewPlacementService(
+				storage.NewPlacementStorage(store, "", opts),
+				service.WithPlacementOptions(opts)), nil
+		},
+	).AnyTimes()
+
+	handlerOpts, err := NewHandlerOptions(
+		mockClient, placement.Configuration{}, nil, instrument.NewOptions())
+	require.NoError(t, err)
+
+	ps, err := Service(mockClient, handleroptions.NewServiceOptions(
+		handleroptions.ServiceNameAndDefaults{
+			ServiceName: handleroptions.M3DBServiceName,
+		}, nil, nil), placement.Configuration{}, time.Now(), nil)
+	require.NoError(t, err)
+
+	_, err = ps.Set(newTestEventsPlacement(
+		newTestEventsInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Available)),
+	))
+	require.NoError(t, err)
+
+	handler := applyMiddleware(NewEventsHandler(handlerOpts).ServeHTTP, nil)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + M3DBEventsURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	_, err = ps.Set(newTestEventsPlacement(
+		newTestEventsInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Leaving)),
+		newTestEventsInstance("i2",
+			shard.NewShard(1).SetState(shard.Initializing).SetSourceID("i1")),
+	))
+	require.NoError(t, err)
+
+	var (
+		reader = bufio.NewReader(resp.Body)
+		events []TopologyChangeEvent
+	)
+	for len(events) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var event TopologyChangeEvent
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		events = append(events, event)
+	}
+
+	require.Equal(t, ShardStateChangedEventType, events[0].Type)
+	require.Equal(t, "i1", events[0].InstanceID)
+	require.Equal(t, "Leaving", events[0].State)
+	require.Equal(t, InstanceAddedEventType, events[1].Type)
+	require.Equal(t, "i2", events[1].InstanceID)
+	require.Equal(t, ShardAddedEventType, events[2].Type)
+	require.Equal(t, "Initializing", events[2].State)
+	require.Equal(t, 2, events[2].Version)
+}
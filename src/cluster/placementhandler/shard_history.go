@@ -0,0 +1,178 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placementhandler
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement/history"
+	"github.com/m3db/m3/src/cluster/placementhandler/handleroptions"
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/util"
+	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ShardHistoryHTTPMethod is the HTTP method used with this resource.
+	ShardHistoryHTTPMethod = http.MethodGet
+
+	shardHistoryPathName = "shard_history"
+
+	shardHistoryInstanceParam = "instance"
+	shardHistoryShardParam    = "shard"
+	shardHistoryStartParam    = "start"
+	shardHistoryEndParam      = "end"
+	shardHistoryLimitParam    = "limit"
+)
+
+var (
+	// M3DBShardHistoryURL is the url for the shard history handler (with the
+	// GET method) for the M3DB service.
+	M3DBShardHistoryURL = path.Join(route.Prefix,
+		M3DBServicePlacementPathName, shardHistoryPathName)
+
+	// M3AggShardHistoryURL is the url for the shard history handler (with the
+	// GET method) for the M3Agg service.
+	M3AggShardHistoryURL = path.Join(route.Prefix,
+		M3AggServicePlacementPathName, shardHistoryPathName)
+
+	// M3CoordinatorShardHistoryURL is the url for the shard history handler
+	// (with the GET method) for the M3Coordinator service.
+	M3CoordinatorShardHistoryURL = path.Join(route.Prefix,
+		M3CoordinatorServicePlacementPathName, shardHistoryPathName)
+)
+
+// ShardHistoryResponse is the response of the shard history handler.
+type ShardHistoryResponse struct {
+	Events []history.Event `json:"events"`
+}
+
+// ShardHistoryHandler is the handler returning the recorded shard state
+// transitions of a placement.
+type ShardHistoryHandler Handler
+
+// NewShardHistoryHandler returns a new instance of ShardHistoryHandler.
+func NewShardHistoryHandler(opts HandlerOptions) *ShardHistoryHandler {
+	return &ShardHistoryHandler{HandlerOptions: opts}
+}
+
+func (h *ShardHistoryHandler) ServeHTTP(
+	svc handleroptions.ServiceNameAndDefaults,
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	var (
+		ctx    = r.Context()
+		logger = logging.WithContext(ctx, h.instrumentOptions)
+	)
+
+	query, err := parseShardHistoryQuery(r)
+	if err != nil {
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	opts := handleroptions.NewServiceOptions(svc, r.Header, h.m3AggServiceOptions)
+	cs, err := placementServices(h.clusterClient, opts)
+	if err != nil {
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	shardHistory, err := cs.ShardStateHistory(opts.ServiceID(), h.placement.NewOptions())
+	if err != nil {
+		logger.Error("unable to get shard state history", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	events, err := shardHistory.Events(query)
+	if err != nil {
+		logger.Error("unable to read shard state history", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+	if events == nil {
+		events = []history.Event{}
+	}
+
+	xhttp.WriteJSONResponse(w, ShardHistoryResponse{Events: events}, logger)
+}
+
+func parseShardHistoryQuery(r *http.Request) (history.Query, error) {
+	query := history.Query{
+		InstanceID: r.FormValue(shardHistoryInstanceParam),
+	}
+
+	if value := r.FormValue(shardHistoryShardParam); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return history.Query{}, xerrors.NewInvalidParamsError(
+				fmt.Errorf("could not parse %s: %w", shardHistoryShardParam, err))
+		}
+		shardID := uint32(id)
+		query.Shard = &shardID
+	}
+
+	start, err := parseShardHistoryTime(r, shardHistoryStartParam)
+	if err != nil {
+		return history.Query{}, err
+	}
+	query.Start = start
+
+	end, err := parseShardHistoryTime(r, shardHistoryEndParam)
+	if err != nil {
+		return history.Query{}, err
+	}
+	query.End = end
+
+	if value := r.FormValue(shardHistoryLimitParam); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return history.Query{}, xerrors.NewInvalidParamsError(
+				fmt.Errorf("could not parse %s: %w", shardHistoryLimitParam, err))
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+func parseShardHistoryTime(r *http.Request, param string) (time.Time, error) {
+	value := r.FormValue(param)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := util.ParseTimeString(value)
+	if err != nil {
+		return time.Time{}, xerrors.NewInvalidParamsError(
+			fmt.Errorf("could not parse %s: %w", param, err))
+	}
+	return t, nil
+}
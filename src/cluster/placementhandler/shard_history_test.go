@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placementhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/history"
+	"github.com/m3db/m3/src/cluster/placement/service"
+	"github.com/m3db/m3/src/cluster/placement/storage"
+	"github.com/m3db/m3/src/cluster/placementhandler/handleroptions"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardHistoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		store        = mem.NewStore()
+		mockClient   = client.NewMockClient(ctrl)
+		mockServices = services.NewMockServices(ctrl)
+		newHistory   = func(opts placement.Options) history.Store {
+			return history.NewKVStore(store, "history", opts)
+		}
+	)
+	mockClient.EXPECT().Services(gomock.Any()).Return(mockServices, nil).AnyTimes()
+	mockServices.EXPECT().PlacementService(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, opts placement.Options) (placement.Service, error) {
+			return service.NewPlacementService(
+				storage.NewPlacementStorage(store, "", opts),
+				service.WithPlacementOptions(opts),
+				service.WithShardStateHistory(newHistory(opts))), nil
+		},
+	).AnyTimes()
+	mockServices.EXPECT().ShardStateHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, opts placement.Options) (history.Store, error) {
+			return newHistory(opts), nil
+		},
+	).AnyTimes()
+
+	handlerOpts, err := NewHandlerOptions(
+		mockClient, placement.Configuration{}, nil, instrument.NewOptions())
+	require.NoError(t, err)
+
+	ps, err := Service(mockClient, handleroptions.NewServiceOptions(
+		handleroptions.ServiceNameAndDefaults{
+			ServiceName: handleroptions.M3DBServiceName,
+		}, nil, nil), placement.Configuration{}, time.Now(), nil)
+	require.NoError(t, err)
+
+	_, err = ps.Set(newTestEventsPlacement(
+		newTestEventsInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Available)),
+	))
+	require.NoError(t, err)
+	_, err = ps.Set(newTestEventsPlacement(
+		newTestEventsInstance("i1",
+			shard.NewShard(0).SetState(shard.Available),
+			shard.NewShard(1).SetState(shard.Leaving)),
+		newTestEventsInstance("i2",
+			shard.NewShard(1).SetState(shard.Initializing).SetSourceID("i1")),
+	))
+	require.NoError(t, err)
+
+	handler := applyMiddleware(NewShardHistoryHandler(handlerOpts).ServeHTTP, nil)
+
+	get := func(query string) (int, ShardHistoryResponse) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(ShardHistoryHTTPMethod, M3DBShardHistoryURL+query, nil)
+		handler.ServeHTTP(w, req)
+
+		var resp ShardHistoryResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp
+	}
+
+	code, resp := get("")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Events, 4)
+
+	code, resp = get("?shard=1&instance=i1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Events, 2)
+	require.Equal(t, "Available", resp.Events[1].PrevState)
+	require.Equal(t, "Leaving", resp.Events[1].State)
+	require.Equal(t, "set", resp.Events[1].Cause)
+
+	code, resp = get("?instance=i2&limit=1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Events, 1)
+	require.Equal(t, "i1", resp.Events[0].SourceID)
+
+	code, _ = get("?shard=foo")
+	require.Equal(t, http.StatusBadRequest, code)
+}
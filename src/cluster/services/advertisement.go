@@ -0,0 +1,208 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/x/retry"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// keepAlivesPerLivenessInterval is the minimum number of heartbeats sent
+	// per liveness interval so that an advertisement survives missed
+	// heartbeats, e.g. during GC pauses, before its lease expires.
+	keepAlivesPerLivenessInterval = 3
+)
+
+var (
+	errAdvertisementNotFound  = errors.New("instance is not being advertised")
+	errAdvertisementUnhealthy = errors.New("instance health check is failing")
+)
+
+// advertiser heartbeats an advertisement until it is stopped, retrying failed
+// heartbeats with backoff and tracking whether the advertisement is live, i.e.
+// has heartbeated successfully within the liveness interval.
+type advertiser struct {
+	sync.RWMutex
+
+	ad               Advertisement
+	instance         placement.Instance
+	hb               HeartbeatService
+	livenessInterval time.Duration
+	retrier          retry.Retrier
+	nowFn            func() time.Time
+	logger           *zap.Logger
+	metrics          advertiserMetrics
+	doneCh           chan struct{}
+
+	lastHeartbeat time.Time
+	lastErr       error
+}
+
+type advertiserMetrics struct {
+	heartbeatError tally.Counter
+	live           tally.Gauge
+}
+
+func newAdvertiserMetrics(scope tally.Scope) advertiserMetrics {
+	return advertiserMetrics{
+		heartbeatError: scope.Counter("heartbeat.error"),
+		live:           scope.Gauge("advertisement.live"),
+	}
+}
+
+func newAdvertiser(
+	ad Advertisement,
+	hb HeartbeatService,
+	m Metadata,
+	retrier retry.Retrier,
+	scope tally.Scope,
+	logger *zap.Logger,
+) *advertiser {
+	return &advertiser{
+		ad:               ad,
+		instance:         ad.PlacementInstance(),
+		hb:               hb,
+		livenessInterval: m.LivenessInterval(),
+		retrier:          retrier,
+		nowFn:            time.Now,
+		logger:           logger,
+		metrics:          newAdvertiserMetrics(scope),
+		doneCh:           make(chan struct{}),
+	}
+}
+
+// keepAliveInterval returns the interval between heartbeats, which is the
+// heartbeat interval unless that would send fewer than
+// keepAlivesPerLivenessInterval heartbeats per liveness interval.
+func keepAliveInterval(m Metadata) time.Duration {
+	interval := m.HeartbeatInterval()
+	if aggressive := m.LivenessInterval() / keepAlivesPerLivenessInterval; aggressive > 0 &&
+		(interval <= 0 || aggressive < interval) {
+		interval = aggressive
+	}
+	return interval
+}
+
+func (a *advertiser) run(interval time.Duration) {
+	a.tick()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.tick()
+		case <-a.doneCh:
+			return
+		}
+	}
+}
+
+func (a *advertiser) stop() {
+	close(a.doneCh)
+}
+
+func (a *advertiser) stopped() bool {
+	select {
+	case <-a.doneCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *advertiser) tick() {
+	defer a.reportLive()
+
+	if !isHealthy(a.ad) {
+		a.Lock()
+		a.lastErr = errAdvertisementUnhealthy
+		a.Unlock()
+		return
+	}
+
+	// NB: the heartbeat service re-registers the instance with a new lease if
+	// the current lease has expired, e.g. after a GC pause longer than the
+	// liveness interval, so failed heartbeats are retried with backoff rather
+	// than waiting for the next tick.
+	continueFn := func(int) bool {
+		return !a.stopped()
+	}
+	err := a.retrier.AttemptWhile(continueFn, func() error {
+		err := a.hb.Heartbeat(a.instance, a.livenessInterval)
+		if err != nil {
+			a.metrics.heartbeatError.Inc(1)
+		}
+		return err
+	})
+
+	a.Lock()
+	a.lastErr = err
+	if err == nil {
+		a.lastHeartbeat = a.nowFn()
+	}
+	a.Unlock()
+
+	if err != nil && !errors.Is(err, retry.ErrWhileConditionFalse) {
+		a.logger.Error("could not heartbeat service",
+			zap.String("service", a.ad.ServiceID().String()),
+			zap.String("instance", a.instance.ID()),
+			zap.Error(err))
+	}
+}
+
+// health returns an error if the advertisement is not live.
+func (a *advertiser) health() error {
+	a.RLock()
+	defer a.RUnlock()
+
+	if a.lastHeartbeat.IsZero() {
+		if a.lastErr != nil {
+			return fmt.Errorf("advertisement is not live, no successful heartbeat: %w", a.lastErr)
+		}
+		return errors.New("advertisement is not live, no heartbeat yet")
+	}
+
+	if since := a.nowFn().Sub(a.lastHeartbeat); since > a.livenessInterval {
+		if a.lastErr != nil {
+			return fmt.Errorf("advertisement is not live, last heartbeat %s ago: %w", since, a.lastErr)
+		}
+		return fmt.Errorf("advertisement is not live, last heartbeat %s ago", since)
+	}
+	return nil
+}
+
+func (a *advertiser) reportLive() {
+	live := 0.0
+	if a.health() == nil {
+		live = 1.0
+	}
+	a.metrics.live.Update(live)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/x/retry"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+func TestKeepAliveInterval(t *testing.T) {
+	tests := []struct {
+		liveness  time.Duration
+		heartbeat time.Duration
+		expected  time.Duration
+	}{
+		{liveness: 30 * time.Second, heartbeat: time.Second, expected: time.Second},
+		{liveness: 30 * time.Second, heartbeat: 20 * time.Second, expected: 10 * time.Second},
+		{liveness: 30 * time.Second, heartbeat: 0, expected: 10 * time.Second},
+		{liveness: 0, heartbeat: time.Second, expected: time.Second},
+	}
+
+	for _, test := range tests {
+		m := NewMetadata().
+			SetLivenessInterval(test.liveness).
+			SetHeartbeatInterval(test.heartbeat)
+		require.Equal(t, test.expected, keepAliveInterval(m))
+	}
+}
+
+func TestAdvertiserRetriesFailedHeartbeats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i1 := placement.NewInstance().SetID("i1")
+	hb := NewMockHeartbeatService(ctrl)
+	gomock.InOrder(
+		hb.EXPECT().Heartbeat(i1, time.Minute).Return(errors.New("lease expired")).Times(2),
+		hb.EXPECT().Heartbeat(i1, time.Minute).Return(nil),
+	)
+
+	a := newTestAdvertiser(i1, hb, testAdvertiserRetrier(3))
+	require.Error(t, a.health())
+
+	a.tick()
+	require.NoError(t, a.health())
+}
+
+func TestAdvertiserHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i1 := placement.NewInstance().SetID("i1")
+	hb := NewMockHeartbeatService(ctrl)
+	hbErr := errors.New("etcd unavailable")
+	gomock.InOrder(
+		hb.EXPECT().Heartbeat(i1, time.Minute).Return(nil),
+		hb.EXPECT().Heartbeat(i1, time.Minute).Return(hbErr).Times(2),
+	)
+
+	now := time.Now()
+	a := newTestAdvertiser(i1, hb, testAdvertiserRetrier(1))
+	a.nowFn = func() time.Time { return now }
+
+	a.tick()
+	require.NoError(t, a.health())
+
+	// A failed heartbeat does not make the advertisement unhealthy until the
+	// liveness interval has passed since the last successful heartbeat.
+	a.tick()
+	require.NoError(t, a.health())
+
+	now = now.Add(time.Minute + time.Second)
+	err := a.health()
+	require.Error(t, err)
+	require.True(t, errors.Is(err, hbErr))
+}
+
+func TestAdvertiserUnhealthyInstance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i1 := placement.NewInstance().SetID("i1")
+	hb := NewMockHeartbeatService(ctrl)
+
+	a := newTestAdvertiser(i1, hb, testAdvertiserRetrier(1))
+	a.ad = a.ad.SetHealth(func() error { return errors.New("unhealthy") })
+
+	a.tick()
+	err := a.health()
+	require.Error(t, err)
+	require.True(t, errors.Is(err, errAdvertisementUnhealthy))
+}
+
+func TestAdvertiserStopInterruptsRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i1 := placement.NewInstance().SetID("i1")
+	hb := NewMockHeartbeatService(ctrl)
+
+	a := newTestAdvertiser(i1, hb, testAdvertiserRetrier(3))
+	hb.EXPECT().Heartbeat(i1, time.Minute).DoAndReturn(
+		func(placement.Instance, time.Duration) error {
+			a.stop()
+			return errors.New("lease expired")
+		},
+	)
+
+	a.tick()
+	require.True(t, a.stopped())
+	require.Error(t, a.health())
+}
+
+func newTestAdvertiser(
+	instance placement.Instance,
+	hb HeartbeatService,
+	retrier retry.Retrier,
+) *advertiser {
+	ad := NewAdvertisement().
+		SetServiceID(NewServiceID().SetName("m3db")).
+		SetPlacementInstance(instance)
+	m := NewMetadata().
+		SetLivenessInterval(time.Minute).
+		SetHeartbeatInterval(10 * time.Second)
+	return newAdvertiser(ad, hb, m, retrier, tally.NoopScope, zap.NewNop())
+}
+
+func testAdvertiserRetrier(maxRetries int) retry.Retrier {
+	return retry.NewRetrier(retry.NewOptions().
+		SetInitialBackoff(time.Millisecond).
+		SetMaxBackoff(time.Millisecond).
+		SetMaxRetries(maxRetries).
+		SetJitter(false))
+}
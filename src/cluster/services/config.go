@@ -22,6 +22,10 @@ package services
 
 import (
 	"time"
+
+	"github.com/m3db/m3/src/x/retry"
+
+	"github.com/uber-go/tally"
 )
 
 // OverrideConfiguration configs the override options.
@@ -50,7 +54,8 @@ func (cfg NamespacesConfiguration) NewOptions() NamespaceOptions {
 
 // Configuration is the config for service options.
 type Configuration struct {
-	InitTimeout *time.Duration `yaml:"initTimeout"`
+	InitTimeout        *time.Duration       `yaml:"initTimeout"`
+	AdvertisementRetry *retry.Configuration `yaml:"advertisementRetry"`
 }
 
 // NewOptions creates an Option.
@@ -59,6 +64,9 @@ func (cfg Configuration) NewOptions() Options {
 	if cfg.InitTimeout != nil {
 		opts = opts.SetInitTimeout(*cfg.InitTimeout)
 	}
+	if cfg.AdvertisementRetry != nil {
+		opts = opts.SetAdvertisementRetryOptions(cfg.AdvertisementRetry.NewOptions(tally.NoopScope))
+	}
 	return opts
 }
 
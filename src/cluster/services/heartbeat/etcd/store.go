@@ -69,9 +69,10 @@ func NewStore(c *clientv3.Client, opts Options) (services.HeartbeatService, erro
 		logger:     opts.InstrumentsOptions().Logger(),
 		retrier:    retry.NewRetrier(opts.RetryOptions()),
 		m: clientMetrics{
-			etcdGetError:   scope.Counter("etcd-get-error"),
-			etcdPutError:   scope.Counter("etcd-put-error"),
-			etcdLeaseError: scope.Counter("etcd-lease-error"),
+			etcdGetError:     scope.Counter("etcd-get-error"),
+			etcdPutError:     scope.Counter("etcd-put-error"),
+			etcdLeaseError:   scope.Counter("etcd-lease-error"),
+			etcdLeaseRenewal: scope.Counter("etcd-lease-renewal"),
 		},
 
 		l:       c.Lease,
@@ -127,9 +128,10 @@ type client struct {
 }
 
 type clientMetrics struct {
-	etcdGetError   tally.Counter
-	etcdPutError   tally.Counter
-	etcdLeaseError tally.Counter
+	etcdGetError     tally.Counter
+	etcdPutError     tally.Counter
+	etcdLeaseError   tally.Counter
+	etcdLeaseRenewal tally.Counter
 }
 
 func (c *client) Heartbeat(instance placement.Instance, ttl time.Duration) error {
@@ -144,6 +146,11 @@ func (c *client) Heartbeat(instance placement.Instance, ttl time.Duration) error
 		if err == nil {
 			return nil
 		}
+		c.logger.Warn("could not keep alive heartbeat lease, re-registering instance",
+			zap.String("service", c.sid.String()),
+			zap.String("instance", instance.ID()),
+			zap.Error(err))
+		c.m.etcdLeaseRenewal.Inc(1)
 	}
 
 	ctx, cancel := c.context()
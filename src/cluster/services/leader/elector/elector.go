@@ -0,0 +1,341 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package elector
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/cluster/services/leader/campaign"
+	xwatch "github.com/m3db/m3/src/x/watch"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+var (
+	errElectorAlreadyOpen = errors.New("elector is already open")
+	errElectorClosed      = errors.New("elector is closed")
+	errElectorNotOpen     = errors.New("elector is not open")
+)
+
+type electorState int
+
+const (
+	electorNotOpen electorState = iota
+	electorOpen
+	electorClosed
+)
+
+type electorMetrics struct {
+	leader         tally.Gauge
+	campaignErrors tally.Counter
+	observeErrors  tally.Counter
+}
+
+func newElectorMetrics(scope tally.Scope) electorMetrics {
+	return electorMetrics{
+		leader:         scope.Gauge("leader"),
+		campaignErrors: scope.Counter("campaign-errors"),
+		observeErrors:  scope.Counter("observe-errors"),
+	}
+}
+
+type elector struct {
+	sync.RWMutex
+
+	svc           services.LeaderService
+	electionID    string
+	campaignOpts  services.CampaignOptions
+	retryInterval time.Duration
+	logger        *zap.Logger
+	metrics       electorMetrics
+	watchable     xwatch.Watchable
+
+	state    electorState
+	leader   bool
+	resigned bool
+	resumeCh chan struct{}
+	closeCh  chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewElector creates a new Elector campaigning in an election of the given
+// leader service.
+func NewElector(svc services.LeaderService, opts Options) (Elector, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	campaignOpts, err := services.NewCampaignOptions()
+	if err != nil {
+		return nil, err
+	}
+	if v := opts.LeaderValue(); v != "" {
+		campaignOpts = campaignOpts.SetLeaderValue(v)
+	}
+
+	iOpts := opts.InstrumentOptions()
+	return &elector{
+		svc:           svc,
+		electionID:    opts.ElectionID(),
+		campaignOpts:  campaignOpts,
+		retryInterval: opts.RetryInterval(),
+		logger:        iOpts.Logger().With(zap.String("electionID", opts.ElectionID())),
+		metrics:       newElectorMetrics(iOpts.MetricsScope().SubScope("elector")),
+		watchable:     xwatch.NewWatchable(),
+		resumeCh:      make(chan struct{}, 1),
+		closeCh:       make(chan struct{}),
+	}, nil
+}
+
+func (e *elector) Open() error {
+	e.Lock()
+	defer e.Unlock()
+
+	switch e.state {
+	case electorOpen:
+		return errElectorAlreadyOpen
+	case electorClosed:
+		return errElectorClosed
+	}
+
+	e.state = electorOpen
+	e.wg.Add(2)
+	go e.campaignUntilClosed()
+	go e.observeUntilClosed()
+	return nil
+}
+
+func (e *elector) IsLeader() bool {
+	e.RLock()
+	defer e.RUnlock()
+	return e.leader
+}
+
+func (e *elector) Leader() (string, error) {
+	return e.svc.Leader(e.electionID)
+}
+
+func (e *elector) Watch() (LeaderWatch, error) {
+	_, w, err := e.watchable.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return leaderWatch{Watch: w}, nil
+}
+
+func (e *elector) Resign() error {
+	e.Lock()
+	if e.state != electorOpen {
+		e.Unlock()
+		return errElectorNotOpen
+	}
+	e.resigned = true
+	e.Unlock()
+
+	// The campaign is ended by the leader service once resigned, the campaign
+	// loop observes the end of the campaign and waits for Campaign to be
+	// called before campaigning again.
+	if err := e.svc.Resign(e.electionID); err != nil {
+		return err
+	}
+	e.setLeader(false)
+	return nil
+}
+
+func (e *elector) Campaign() error {
+	e.Lock()
+	if e.state != electorOpen {
+		e.Unlock()
+		return errElectorNotOpen
+	}
+	e.resigned = false
+	e.Unlock()
+
+	select {
+	case e.resumeCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (e *elector) isResigned() bool {
+	e.RLock()
+	defer e.RUnlock()
+	return e.resigned
+}
+
+func (e *elector) Close() error {
+	e.Lock()
+	if e.state != electorOpen {
+		e.state = electorClosed
+		e.Unlock()
+		e.watchable.Close()
+		return nil
+	}
+	e.state = electorClosed
+	close(e.closeCh)
+	e.Unlock()
+
+	e.wg.Wait()
+	e.watchable.Close()
+	return nil
+}
+
+func (e *elector) setLeader(leader bool) {
+	e.Lock()
+	e.leader = leader
+	e.Unlock()
+
+	if leader {
+		e.metrics.leader.Update(1)
+	} else {
+		e.metrics.leader.Update(0)
+	}
+}
+
+// campaignUntilClosed campaigns for leadership, campaigning again whenever
+// the campaign ends unless resigned, until the elector is closed.
+func (e *elector) campaignUntilClosed() {
+	defer e.wg.Done()
+
+	for {
+		if !e.waitUntilNotResigned() {
+			return
+		}
+		if err := e.campaign(); err != nil {
+			e.metrics.campaignErrors.Inc(1)
+			e.logger.Error("elector campaign failed", zap.Error(err))
+		}
+		e.setLeader(false)
+
+		select {
+		case <-e.closeCh:
+			return
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// waitUntilNotResigned waits until Campaign is called if the elector resigned,
+// it returns false if the elector is closed in the meantime.
+func (e *elector) waitUntilNotResigned() bool {
+	for e.isResigned() {
+		select {
+		case <-e.closeCh:
+			return false
+		case <-e.resumeCh:
+		}
+	}
+	return true
+}
+
+func (e *elector) campaign() error {
+	statusCh, err := e.svc.Campaign(e.electionID, e.campaignOpts)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-e.closeCh:
+			// Resigning also cancels a campaign still waiting to be elected, in
+			// which case there is no leadership to give up and errors are moot.
+			wasLeader := e.IsLeader()
+			err := e.svc.Resign(e.electionID)
+			// NB: The status channel must be consumed until it is closed.
+			for range statusCh {
+			}
+			if !wasLeader {
+				return nil
+			}
+			return err
+		case status, ok := <-statusCh:
+			if !ok {
+				return nil
+			}
+			if status.State == campaign.Error {
+				e.metrics.campaignErrors.Inc(1)
+				e.logger.Error("elector campaign error", zap.Error(status.Err))
+			}
+			if status.State == campaign.Leader && e.isResigned() {
+				// NB: the campaign started as the elector resigned, give up
+				// the leadership it won.
+				if err := e.svc.Resign(e.electionID); err != nil {
+					e.metrics.campaignErrors.Inc(1)
+					e.logger.Error("elector resign failed", zap.Error(err))
+				}
+				continue
+			}
+			e.setLeader(status.State == campaign.Leader)
+		}
+	}
+}
+
+// observeUntilClosed forwards leader updates to watches, observing again
+// whenever the observation ends, until the elector is closed.
+func (e *elector) observeUntilClosed() {
+	defer e.wg.Done()
+
+	for {
+		leaderCh, err := e.svc.Observe(e.electionID)
+		if err != nil {
+			e.metrics.observeErrors.Inc(1)
+			e.logger.Error("elector observe failed", zap.Error(err))
+		} else {
+			e.forward(leaderCh)
+		}
+
+		select {
+		case <-e.closeCh:
+			return
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+func (e *elector) forward(leaderCh <-chan string) {
+	for {
+		select {
+		case <-e.closeCh:
+			return
+		case leader, ok := <-leaderCh:
+			if !ok {
+				return
+			}
+			if err := e.watchable.Update(leader); err != nil {
+				return
+			}
+		}
+	}
+}
+
+type leaderWatch struct {
+	xwatch.Watch
+}
+
+func (w leaderWatch) Get() string {
+	leader, _ := w.Watch.Get().(string)
+	return leader
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package elector
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/cluster/services/leader/campaign"
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() Options {
+	return NewOptions().
+		SetElectionID("test").
+		SetLeaderValue("host1").
+		SetRetryInterval(time.Minute)
+}
+
+func TestElectorCampaignAndResign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		svc      = services.NewMockLeaderService(ctrl)
+		statusCh = make(chan campaign.Status, 2)
+		leaderCh = make(chan string, 1)
+	)
+	statusCh <- campaign.NewStatus(campaign.Follower)
+	statusCh <- campaign.NewStatus(campaign.Leader)
+	leaderCh <- "host1"
+
+	svc.EXPECT().
+		Campaign("test", gomock.Any()).
+		DoAndReturn(func(_ string, opts services.CampaignOptions) (<-chan campaign.Status, error) {
+			require.Equal(t, "host1", opts.LeaderValue())
+			return statusCh, nil
+		})
+	svc.EXPECT().Observe("test").Return(leaderCh, nil)
+	svc.EXPECT().Resign("test").DoAndReturn(func(string) error {
+		statusCh <- campaign.NewStatus(campaign.Follower)
+		close(statusCh)
+		return nil
+	})
+	// Close may race with the campaign observing the end of the campaign.
+	svc.EXPECT().Resign("test").Return(nil).AnyTimes()
+
+	e, err := NewElector(svc, testOptions())
+	require.NoError(t, err)
+
+	w, err := e.Watch()
+	require.NoError(t, err)
+	defer w.Close()
+
+	require.NoError(t, e.Open())
+	require.Error(t, e.Open())
+
+	<-w.C()
+	require.Equal(t, "host1", w.Get())
+	require.True(t, clock.WaitUntil(e.IsLeader, time.Second))
+
+	require.NoError(t, e.Resign())
+	require.False(t, e.IsLeader())
+
+	require.NoError(t, e.Close())
+	require.Error(t, e.Open())
+	require.Error(t, e.Resign())
+}
+
+func TestElectorResignStopsCampaigningUntilCampaign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		svc       = services.NewMockLeaderService(ctrl)
+		statusChs = []chan campaign.Status{
+			make(chan campaign.Status, 1),
+			make(chan campaign.Status, 1),
+		}
+		campaigns int32
+	)
+	svc.EXPECT().
+		Campaign("test", gomock.Any()).
+		DoAndReturn(func(string, services.CampaignOptions) (<-chan campaign.Status, error) {
+			statusCh := statusChs[atomic.AddInt32(&campaigns, 1)-1]
+			statusCh <- campaign.NewStatus(campaign.Leader)
+			return statusCh, nil
+		}).
+		Times(2)
+	svc.EXPECT().Observe("test").Return(make(chan string), nil)
+	svc.EXPECT().Resign("test").DoAndReturn(func(string) error {
+		close(statusChs[atomic.LoadInt32(&campaigns)-1])
+		return nil
+	}).Times(2)
+
+	e, err := NewElector(svc, testOptions().SetRetryInterval(time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, e.Open())
+	require.True(t, clock.WaitUntil(e.IsLeader, time.Second))
+
+	require.NoError(t, e.Resign())
+	require.False(t, e.IsLeader())
+
+	// The elector does not campaign again on its own once resigned.
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(1), atomic.LoadInt32(&campaigns))
+	require.False(t, e.IsLeader())
+
+	require.NoError(t, e.Campaign())
+	require.True(t, clock.WaitUntil(e.IsLeader, time.Second))
+	require.Equal(t, int32(2), atomic.LoadInt32(&campaigns))
+
+	require.NoError(t, e.Close())
+	require.Error(t, e.Campaign())
+}
+
+func TestElectorCloseResignsLeadership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		svc      = services.NewMockLeaderService(ctrl)
+		statusCh = make(chan campaign.Status, 2)
+	)
+	statusCh <- campaign.NewStatus(campaign.Leader)
+
+	svc.EXPECT().Campaign("test", gomock.Any()).Return(statusCh, nil)
+	svc.EXPECT().Observe("test").Return(make(chan string), nil)
+	svc.EXPECT().Resign("test").DoAndReturn(func(string) error {
+		close(statusCh)
+		return nil
+	})
+
+	e, err := NewElector(svc, testOptions())
+	require.NoError(t, err)
+	require.NoError(t, e.Open())
+	require.True(t, clock.WaitUntil(e.IsLeader, time.Second))
+
+	require.NoError(t, e.Close())
+	require.False(t, e.IsLeader())
+}
+
+func TestElectorInvalidOptions(t *testing.T) {
+	_, err := NewElector(nil, testOptions().SetRetryInterval(0))
+	require.Error(t, err)
+
+	_, err = NewElector(nil, testOptions().SetInstrumentOptions(nil))
+	require.Error(t, err)
+}
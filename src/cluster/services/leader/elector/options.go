@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package elector
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultRetryInterval = 5 * time.Second
+)
+
+var (
+	errInvalidRetryInterval = errors.New("elector retry interval must be positive")
+	errNoInstrumentOptions  = errors.New("elector options must specify instrument options")
+)
+
+type options struct {
+	electionID    string
+	leaderValue   string
+	retryInterval time.Duration
+	iOpts         instrument.Options
+}
+
+// NewOptions returns the default Options.
+func NewOptions() Options {
+	return options{
+		retryInterval: defaultRetryInterval,
+		iOpts:         instrument.NewOptions(),
+	}
+}
+
+func (o options) ElectionID() string {
+	return o.electionID
+}
+
+func (o options) SetElectionID(value string) Options {
+	o.electionID = value
+	return o
+}
+
+func (o options) LeaderValue() string {
+	return o.leaderValue
+}
+
+func (o options) SetLeaderValue(value string) Options {
+	o.leaderValue = value
+	return o
+}
+
+func (o options) RetryInterval() time.Duration {
+	return o.retryInterval
+}
+
+func (o options) SetRetryInterval(value time.Duration) Options {
+	o.retryInterval = value
+	return o
+}
+
+func (o options) InstrumentOptions() instrument.Options {
+	return o.iOpts
+}
+
+func (o options) SetInstrumentOptions(value instrument.Options) Options {
+	o.iOpts = value
+	return o
+}
+
+func (o options) Validate() error {
+	if o.retryInterval <= 0 {
+		return errInvalidRetryInterval
+	}
+	if o.iOpts == nil {
+		return errNoInstrumentOptions
+	}
+	return nil
+}
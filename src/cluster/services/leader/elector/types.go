@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package elector provides a long running participant in a leader election
+// built on top of a services.LeaderService, so that components which need a
+// single active instance do not have to manage campaigns themselves.
+package elector
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Elector campaigns for leadership of an election until it is closed,
+// campaigning again whenever the campaign is lost unless it resigned.
+type Elector interface {
+	// Open starts campaigning and observing the leader of the election.
+	Open() error
+
+	// IsLeader returns whether the elector is currently the leader.
+	IsLeader() bool
+
+	// Leader returns the leader value of the current leader of the election.
+	Leader() (string, error)
+
+	// Watch returns a watch on the leader value of the election, the watch is
+	// notified whenever leadership changes hands.
+	Watch() (LeaderWatch, error)
+
+	// Resign gives up leadership if held and stops campaigning until Campaign
+	// is called, giving other candidates a chance to be elected.
+	Resign() error
+
+	// Campaign campaigns again after Resign.
+	Campaign() error
+
+	// Close resigns leadership if held and stops campaigning.
+	Close() error
+}
+
+// LeaderWatch provides updates to the leader of an election.
+type LeaderWatch interface {
+	// C returns the notification channel.
+	C() <-chan struct{}
+	// Get returns the leader value of the current leader, or an empty string
+	// if the leader is not yet known.
+	Get() string
+	// Close stops watching for leader updates.
+	Close()
+}
+
+// Options are options for an Elector.
+type Options interface {
+	// ElectionID is the ID of the election to campaign in.
+	ElectionID() string
+	// SetElectionID sets the ElectionID.
+	SetElectionID(value string) Options
+
+	// LeaderValue is the value announced to observers while leader, defaults
+	// to the hostname.
+	LeaderValue() string
+	// SetLeaderValue sets the LeaderValue.
+	SetLeaderValue(value string) Options
+
+	// RetryInterval is the interval to wait before campaigning or observing
+	// again after a campaign or observation ends.
+	RetryInterval() time.Duration
+	// SetRetryInterval sets the RetryInterval.
+	SetRetryInterval(value time.Duration) Options
+
+	// InstrumentOptions is the instrument options.
+	InstrumentOptions() instrument.Options
+	// SetInstrumentOptions sets the InstrumentOptions.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// Validate validates the Options.
+	Validate() error
+}
@@ -26,12 +26,17 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/retry"
 )
 
 const (
 	defaultInitTimeout   = 5 * time.Second
 	defaultLeaderTimeout = 10 * time.Second
 	defaultResignTimeout = 10 * time.Second
+
+	defaultAdvertisementInitialBackoff = 100 * time.Millisecond
+	defaultAdvertisementMaxBackoff     = time.Second
+	defaultAdvertisementMaxRetries     = 3
 )
 
 var (
@@ -39,6 +44,7 @@ var (
 	errNoHeartbeatGen     = errors.New("no HeartbeatGen function set")
 	errNoLeaderGen        = errors.New("no LeaderGen function set")
 	errInvalidInitTimeout = errors.New("negative init timeout for service watch")
+	errNoAdRetryOptions   = errors.New("no advertisement retry options set")
 )
 
 type options struct {
@@ -48,6 +54,8 @@ type options struct {
 	hbGen       HeartbeatGen
 	ldGen       LeaderGen
 	iopts       instrument.Options
+	adRetryOpts retry.Options
+	readOnly    bool
 }
 
 // NewOptions creates an Option
@@ -56,6 +64,10 @@ func NewOptions() Options {
 		iopts:       instrument.NewOptions(),
 		nOpts:       NewNamespaceOptions(),
 		initTimeout: defaultInitTimeout,
+		adRetryOpts: retry.NewOptions().
+			SetInitialBackoff(defaultAdvertisementInitialBackoff).
+			SetMaxBackoff(defaultAdvertisementMaxBackoff).
+			SetMaxRetries(defaultAdvertisementMaxRetries),
 	}
 }
 
@@ -76,6 +88,10 @@ func (o options) Validate() error {
 		return errInvalidInitTimeout
 	}
 
+	if o.adRetryOpts == nil {
+		return errNoAdRetryOptions
+	}
+
 	return nil
 }
 
@@ -88,6 +104,15 @@ func (o options) SetInitTimeout(t time.Duration) Options {
 	return o
 }
 
+func (o options) AdvertisementRetryOptions() retry.Options {
+	return o.adRetryOpts
+}
+
+func (o options) SetAdvertisementRetryOptions(opts retry.Options) Options {
+	o.adRetryOpts = opts
+	return o
+}
+
 func (o options) KVGen() KVGen {
 	return o.kvGen
 }
@@ -133,6 +158,15 @@ func (o options) SetNamespaceOptions(opts NamespaceOptions) Options {
 	return o
 }
 
+func (o options) ReadOnly() bool {
+	return o.readOnly
+}
+
+func (o options) SetReadOnly(readOnly bool) Options {
+	o.readOnly = readOnly
+	return o
+}
+
 // NewElectionOptions returns an empty ElectionOptions.
 func NewElectionOptions() ElectionOptions {
 	eo := electionOpts{
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package services
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/services/leader/campaign"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// readOnlyOptions wraps the generators of the options so that the stores and
+// services they generate reject mutations.
+func readOnlyOptions(opts Options) Options {
+	kvGen, hbGen, ldGen := opts.KVGen(), opts.HeartbeatGen(), opts.LeaderGen()
+	return opts.
+		SetKVGen(func(zone string) (kv.Store, error) {
+			store, err := kvGen(zone)
+			if err != nil {
+				return nil, err
+			}
+			return readOnlyStore{Store: store}, nil
+		}).
+		SetHeartbeatGen(func(sid ServiceID) (HeartbeatService, error) {
+			hb, err := hbGen(sid)
+			if err != nil {
+				return nil, err
+			}
+			return readOnlyHeartbeatService{HeartbeatService: hb, sid: sid}, nil
+		}).
+		SetLeaderGen(func(sid ServiceID, eo ElectionOptions) (LeaderService, error) {
+			ld, err := ldGen(sid, eo)
+			if err != nil {
+				return nil, err
+			}
+			return readOnlyLeaderService{LeaderService: ld}, nil
+		})
+}
+
+type readOnlyStore struct {
+	kv.Store
+}
+
+func (s readOnlyStore) Set(key string, _ proto.Message) (int, error) {
+	return 0, kv.NewReadOnlyError("set", key)
+}
+
+func (s readOnlyStore) SetWithTTL(key string, _ proto.Message, _ time.Duration) (int, error) {
+	return 0, kv.NewReadOnlyError("set with ttl", key)
+}
+
+func (s readOnlyStore) SetIfNotExists(key string, _ proto.Message) (int, error) {
+	return 0, kv.NewReadOnlyError("set if not exists", key)
+}
+
+func (s readOnlyStore) CheckAndSet(key string, _ int, _ proto.Message) (int, error) {
+	return 0, kv.NewReadOnlyError("check and set", key)
+}
+
+func (s readOnlyStore) Delete(key string) (kv.Value, error) {
+	return nil, kv.NewReadOnlyError("delete", key)
+}
+
+func (s readOnlyStore) DeleteIfVersionMatches(key string, _ int) (kv.Value, error) {
+	return nil, kv.NewReadOnlyError("delete if version matches", key)
+}
+
+type readOnlyHeartbeatService struct {
+	HeartbeatService
+
+	sid ServiceID
+}
+
+func (s readOnlyHeartbeatService) Heartbeat(instance placement.Instance, _ time.Duration) error {
+	return kv.NewReadOnlyError("heartbeat", adKey(s.sid, instance.ID()))
+}
+
+func (s readOnlyHeartbeatService) Delete(instance string) error {
+	return kv.NewReadOnlyError("delete heartbeat", adKey(s.sid, instance))
+}
+
+type readOnlyLeaderService struct {
+	LeaderService
+}
+
+func (s readOnlyLeaderService) Campaign(electionID string, _ CampaignOptions) (<-chan campaign.Status, error) {
+	return nil, kv.NewReadOnlyError("campaign", electionID)
+}
+
+func (s readOnlyLeaderService) Resign(electionID string) error {
+	return kv.NewReadOnlyError("resign", electionID)
+}
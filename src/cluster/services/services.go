@@ -30,10 +30,13 @@ import (
 	"github.com/m3db/m3/src/cluster/generated/proto/placementpb"
 	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/history"
+	"github.com/m3db/m3/src/cluster/placement/queue"
 	ps "github.com/m3db/m3/src/cluster/placement/service"
 	"github.com/m3db/m3/src/cluster/placement/storage"
 	"github.com/m3db/m3/src/cluster/shard"
 	xos "github.com/m3db/m3/src/x/os"
+	"github.com/m3db/m3/src/x/retry"
 	xwatch "github.com/m3db/m3/src/x/watch"
 
 	"github.com/uber-go/tally"
@@ -61,13 +64,17 @@ func NewServices(opts Options) (Services, error) {
 		return nil, err
 	}
 
+	if opts.ReadOnly() {
+		opts = readOnlyOptions(opts)
+	}
+
 	return &client{
 		opts:           opts,
 		placementKeyFn: keyFnWithNamespace(placementNamespace(opts.NamespaceOptions().PlacementNamespace())),
 		metadataKeyFn:  keyFnWithNamespace(metadataNamespace(opts.NamespaceOptions().MetadataNamespace())),
 		kvManagers:     make(map[string]*kvManager),
 		hbStores:       make(map[string]HeartbeatService),
-		adDoneChs:      make(map[string]chan struct{}),
+		advertisers:    make(map[string]*advertiser),
 		ldSvcs:         make(map[leaderKey]LeaderService),
 		logger:         opts.InstrumentsOptions().Logger(),
 		m:              opts.InstrumentsOptions().MetricsScope(),
@@ -83,7 +90,7 @@ type client struct {
 	kvManagers     map[string]*kvManager
 	hbStores       map[string]HeartbeatService
 	ldSvcs         map[leaderKey]LeaderService
-	adDoneChs      map[string]chan struct{}
+	advertisers    map[string]*advertiser
 	logger         *zap.Logger
 	m              tally.Scope
 }
@@ -153,12 +160,53 @@ func (c *client) PlacementService(sid ServiceID, opts placement.Options) (placem
 		return nil, err
 	}
 
+	psOpts := []ps.Option{ps.WithPlacementOptions(opts)}
+	if opts == nil || opts.ShardStateHistoryMaxEvents() > 0 {
+		psOpts = append(psOpts, ps.WithShardStateHistory(
+			history.NewKVStore(store, shardStateHistoryKey(c.placementKeyFn(sid)), opts)))
+	}
+
 	return ps.NewPlacementService(
 		storage.NewPlacementStorage(store, c.placementKeyFn(sid), opts),
-		ps.WithPlacementOptions(opts),
+		psOpts...,
 	), nil
 }
 
+func (c *client) ShardStateHistory(sid ServiceID, opts placement.Options) (history.Store, error) {
+	if err := validateServiceID(sid); err != nil {
+		return nil, err
+	}
+
+	store, err := c.opts.KVGen()(sid.Zone())
+	if err != nil {
+		return nil, err
+	}
+
+	return history.NewKVStore(store, shardStateHistoryKey(c.placementKeyFn(sid)), opts), nil
+}
+
+func (c *client) PlacementOperationQueue(sid ServiceID, opts queue.Options) (queue.Queue, error) {
+	if err := validateServiceID(sid); err != nil {
+		return nil, err
+	}
+
+	store, err := c.opts.KVGen()(sid.Zone())
+	if err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = queue.NewOptions()
+	}
+	placementKey := c.placementKeyFn(sid)
+	return queue.NewKVQueue(
+		store,
+		placementOperationQueueKey(placementKey),
+		storage.NewPlacementStorage(store, placementKey, opts.PlacementOptions()),
+		opts,
+	)
+}
+
 func (c *client) Advertise(ad Advertisement) error {
 	pi := ad.PlacementInstance()
 	if pi == nil {
@@ -169,6 +217,10 @@ func (c *client) Advertise(ad Advertisement) error {
 		return err
 	}
 
+	if c.opts.ReadOnly() {
+		return kv.NewReadOnlyError("advertise", adKey(ad.ServiceID(), pi.ID()))
+	}
+
 	m, err := c.Metadata(ad.ServiceID())
 	if err != nil {
 		return err
@@ -181,44 +233,31 @@ func (c *client) Advertise(ad Advertisement) error {
 
 	key := adKey(ad.ServiceID(), pi.ID())
 	c.Lock()
-	ch, ok := c.adDoneChs[key]
-	if ok {
+	if _, ok := c.advertisers[key]; ok {
 		c.Unlock()
 		return fmt.Errorf("service %s, instance %s is already being advertised", ad.ServiceID(), pi.ID())
 	}
-	ch = make(chan struct{})
-	c.adDoneChs[key] = ch
+	a := newAdvertiser(ad, hb, m, retry.NewRetrier(c.opts.AdvertisementRetryOptions()),
+		c.serviceTaggedScope(ad.ServiceID()), c.logger)
+	c.advertisers[key] = a
 	c.Unlock()
 
-	go func() {
-		sid := ad.ServiceID()
-		errCounter := c.serviceTaggedScope(sid).Counter("heartbeat.error")
-
-		tickFn := func() {
-			if isHealthy(ad) {
-				if err := hb.Heartbeat(pi, m.LivenessInterval()); err != nil {
-					c.logger.Error("could not heartbeat service",
-						zap.String("service", sid.String()),
-						zap.Error(err))
-					errCounter.Inc(1)
-				}
-			}
-		}
+	go a.run(keepAliveInterval(m))
+	return nil
+}
 
-		tickFn()
+func (c *client) AdvertisementHealth(sid ServiceID, id string) error {
+	if err := validateAdvertisement(sid, id); err != nil {
+		return err
+	}
 
-		ticker := time.NewTicker(m.HeartbeatInterval())
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				tickFn()
-			case <-ch:
-				return
-			}
-		}
-	}()
-	return nil
+	c.RLock()
+	a, ok := c.advertisers[adKey(sid, id)]
+	c.RUnlock()
+	if !ok {
+		return fmt.Errorf("service %s, instance %s: %w", sid, id, errAdvertisementNotFound)
+	}
+	return a.health()
 }
 
 func (c *client) Unadvertise(sid ServiceID, id string) error {
@@ -229,10 +268,10 @@ func (c *client) Unadvertise(sid ServiceID, id string) error {
 	key := adKey(sid, id)
 
 	c.Lock()
-	if ch, ok := c.adDoneChs[key]; ok {
+	if a, ok := c.advertisers[key]; ok {
 		// If this client is advertising the instance, stop it.
-		close(ch)
-		delete(c.adDoneChs, key)
+		a.stop()
+		delete(c.advertisers, key)
 	}
 	c.Unlock()
 
@@ -560,7 +599,8 @@ func filterInstances(s Service, ids []string) Service {
 	return NewService().
 		SetInstances(instances).
 		SetSharding(s.Sharding()).
-		SetReplication(s.Replication())
+		SetReplication(s.Replication()).
+		SetVersion(s.Version())
 }
 
 func filterInstancesWithWatch(s Service, hbw xwatch.Watch) Service {
@@ -711,13 +751,15 @@ func NewServiceFromPlacement(p placement.Placement, sid ServiceID) Service {
 	return NewService().
 		SetReplication(NewServiceReplication().SetReplicas(p.ReplicaFactor())).
 		SetSharding(NewServiceSharding().SetNumShards(p.NumShards()).SetIsSharded(p.IsSharded())).
-		SetInstances(serviceInstances)
+		SetInstances(serviceInstances).
+		SetVersion(p.Version())
 }
 
 type service struct {
 	instances   []ServiceInstance
 	replication ServiceReplication
 	sharding    ServiceSharding
+	version     int
 }
 
 func (s *service) Instance(instanceID string) (ServiceInstance, error) {
@@ -734,6 +776,8 @@ func (s *service) Sharding() ServiceSharding                    { return s.shard
 func (s *service) SetInstances(insts []ServiceInstance) Service { s.instances = insts; return s }
 func (s *service) SetReplication(r ServiceReplication) Service  { s.replication = r; return s }
 func (s *service) SetSharding(ss ServiceSharding) Service       { s.sharding = ss; return s }
+func (s *service) Version() int                                 { return s.version }
+func (s *service) SetVersion(v int) Service                     { s.version = v; return s }
 
 // NewServiceReplication creates a new ServiceReplication.
 func NewServiceReplication() ServiceReplication { return new(serviceReplication) }
@@ -777,6 +821,7 @@ func NewServiceInstanceFromProto(
 		SetServiceID(sid).
 		SetInstanceID(instance.Id).
 		SetEndpoint(instance.Endpoint).
+		SetZone(instance.Zone).
 		SetShards(shards), nil
 }
 
@@ -789,6 +834,7 @@ func NewServiceInstanceFromPlacementInstance(
 		SetServiceID(sid).
 		SetInstanceID(instance.ID()).
 		SetEndpoint(instance.Endpoint()).
+		SetZone(instance.Zone()).
 		SetShards(instance.Shards())
 }
 
@@ -796,15 +842,18 @@ type serviceInstance struct {
 	service  ServiceID
 	id       string
 	endpoint string
+	zone     string
 	shards   shard.Shards
 }
 
 func (i *serviceInstance) InstanceID() string                       { return i.id }
 func (i *serviceInstance) Endpoint() string                         { return i.endpoint }
+func (i *serviceInstance) Zone() string                             { return i.zone }
 func (i *serviceInstance) Shards() shard.Shards                     { return i.shards }
 func (i *serviceInstance) ServiceID() ServiceID                     { return i.service }
 func (i *serviceInstance) SetInstanceID(id string) ServiceInstance  { i.id = id; return i }
 func (i *serviceInstance) SetEndpoint(e string) ServiceInstance     { i.endpoint = e; return i }
+func (i *serviceInstance) SetZone(z string) ServiceInstance         { i.zone = z; return i }
 func (i *serviceInstance) SetShards(s shard.Shards) ServiceInstance { i.shards = s; return i }
 
 func (i *serviceInstance) SetServiceID(service ServiceID) ServiceInstance {
@@ -30,9 +30,12 @@ import (
 
 	"github.com/m3db/m3/src/cluster/generated/proto/metadatapb"
 	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/history"
+	"github.com/m3db/m3/src/cluster/placement/queue"
 	"github.com/m3db/m3/src/cluster/services/leader/campaign"
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/retry"
 	"github.com/m3db/m3/src/x/watch"
 
 	"github.com/golang/mock/gomock"
@@ -75,6 +78,20 @@ func (mr *MockServicesMockRecorder) Advertise(ad interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Advertise", reflect.TypeOf((*MockServices)(nil).Advertise), ad)
 }
 
+// AdvertisementHealth mocks base method.
+func (m *MockServices) AdvertisementHealth(service ServiceID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvertisementHealth", service, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvertisementHealth indicates an expected call of AdvertisementHealth.
+func (mr *MockServicesMockRecorder) AdvertisementHealth(service, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvertisementHealth", reflect.TypeOf((*MockServices)(nil).AdvertisementHealth), service, id)
+}
+
 // DeleteMetadata mocks base method.
 func (m *MockServices) DeleteMetadata(sid ServiceID) error {
 	m.ctrl.T.Helper()
@@ -134,6 +151,21 @@ func (mr *MockServicesMockRecorder) Metadata(sid interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metadata", reflect.TypeOf((*MockServices)(nil).Metadata), sid)
 }
 
+// PlacementOperationQueue mocks base method.
+func (m *MockServices) PlacementOperationQueue(sid ServiceID, opts queue.Options) (queue.Queue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlacementOperationQueue", sid, opts)
+	ret0, _ := ret[0].(queue.Queue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlacementOperationQueue indicates an expected call of PlacementOperationQueue.
+func (mr *MockServicesMockRecorder) PlacementOperationQueue(sid, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlacementOperationQueue", reflect.TypeOf((*MockServices)(nil).PlacementOperationQueue), sid, opts)
+}
+
 // PlacementService mocks base method.
 func (m *MockServices) PlacementService(sid ServiceID, popts placement.Options) (placement.Service, error) {
 	m.ctrl.T.Helper()
@@ -149,6 +181,21 @@ func (mr *MockServicesMockRecorder) PlacementService(sid, popts interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlacementService", reflect.TypeOf((*MockServices)(nil).PlacementService), sid, popts)
 }
 
+// ShardStateHistory mocks base method.
+func (m *MockServices) ShardStateHistory(sid ServiceID, popts placement.Options) (history.Store, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShardStateHistory", sid, popts)
+	ret0, _ := ret[0].(history.Store)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShardStateHistory indicates an expected call of ShardStateHistory.
+func (mr *MockServicesMockRecorder) ShardStateHistory(sid, popts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShardStateHistory", reflect.TypeOf((*MockServices)(nil).ShardStateHistory), sid, popts)
+}
+
 // Query mocks base method.
 func (m *MockServices) Query(service ServiceID, opts QueryOptions) (Service, error) {
 	m.ctrl.T.Helper()
@@ -230,6 +277,20 @@ func (m *MockOptions) EXPECT() *MockOptionsMockRecorder {
 	return m.recorder
 }
 
+// AdvertisementRetryOptions mocks base method.
+func (m *MockOptions) AdvertisementRetryOptions() retry.Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvertisementRetryOptions")
+	ret0, _ := ret[0].(retry.Options)
+	return ret0
+}
+
+// AdvertisementRetryOptions indicates an expected call of AdvertisementRetryOptions.
+func (mr *MockOptionsMockRecorder) AdvertisementRetryOptions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvertisementRetryOptions", reflect.TypeOf((*MockOptions)(nil).AdvertisementRetryOptions))
+}
+
 // HeartbeatGen mocks base method.
 func (m *MockOptions) HeartbeatGen() HeartbeatGen {
 	m.ctrl.T.Helper()
@@ -314,6 +375,34 @@ func (mr *MockOptionsMockRecorder) NamespaceOptions() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NamespaceOptions", reflect.TypeOf((*MockOptions)(nil).NamespaceOptions))
 }
 
+// ReadOnly mocks base method.
+func (m *MockOptions) ReadOnly() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOnly")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ReadOnly indicates an expected call of ReadOnly.
+func (mr *MockOptionsMockRecorder) ReadOnly() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOnly", reflect.TypeOf((*MockOptions)(nil).ReadOnly))
+}
+
+// SetAdvertisementRetryOptions mocks base method.
+func (m *MockOptions) SetAdvertisementRetryOptions(opts retry.Options) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAdvertisementRetryOptions", opts)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetAdvertisementRetryOptions indicates an expected call of SetAdvertisementRetryOptions.
+func (mr *MockOptionsMockRecorder) SetAdvertisementRetryOptions(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAdvertisementRetryOptions", reflect.TypeOf((*MockOptions)(nil).SetAdvertisementRetryOptions), opts)
+}
+
 // SetHeartbeatGen mocks base method.
 func (m *MockOptions) SetHeartbeatGen(gen HeartbeatGen) Options {
 	m.ctrl.T.Helper()
@@ -398,6 +487,20 @@ func (mr *MockOptionsMockRecorder) SetNamespaceOptions(opts interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNamespaceOptions", reflect.TypeOf((*MockOptions)(nil).SetNamespaceOptions), opts)
 }
 
+// SetReadOnly mocks base method.
+func (m *MockOptions) SetReadOnly(readOnly bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadOnly", readOnly)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetReadOnly indicates an expected call of SetReadOnly.
+func (mr *MockOptionsMockRecorder) SetReadOnly(readOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadOnly", reflect.TypeOf((*MockOptions)(nil).SetReadOnly), readOnly)
+}
+
 // Validate mocks base method.
 func (m *MockOptions) Validate() error {
 	m.ctrl.T.Helper()
@@ -713,6 +816,20 @@ func (mr *MockServiceMockRecorder) SetSharding(s interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSharding", reflect.TypeOf((*MockService)(nil).SetSharding), s)
 }
 
+// SetVersion mocks base method.
+func (m *MockService) SetVersion(v int) Service {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVersion", v)
+	ret0, _ := ret[0].(Service)
+	return ret0
+}
+
+// SetVersion indicates an expected call of SetVersion.
+func (mr *MockServiceMockRecorder) SetVersion(v interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVersion", reflect.TypeOf((*MockService)(nil).SetVersion), v)
+}
+
 // Sharding mocks base method.
 func (m *MockService) Sharding() ServiceSharding {
 	m.ctrl.T.Helper()
@@ -727,6 +844,20 @@ func (mr *MockServiceMockRecorder) Sharding() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sharding", reflect.TypeOf((*MockService)(nil).Sharding))
 }
 
+// Version mocks base method.
+func (m *MockService) Version() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockServiceMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockService)(nil).Version))
+}
+
 // MockServiceReplication is a mock of ServiceReplication interface.
 type MockServiceReplication struct {
 	ctrl     *gomock.Controller
@@ -978,6 +1109,34 @@ func (mr *MockServiceInstanceMockRecorder) SetShards(s interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShards", reflect.TypeOf((*MockServiceInstance)(nil).SetShards), s)
 }
 
+// SetZone mocks base method.
+func (m *MockServiceInstance) SetZone(z string) ServiceInstance {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetZone", z)
+	ret0, _ := ret[0].(ServiceInstance)
+	return ret0
+}
+
+// SetZone indicates an expected call of SetZone.
+func (mr *MockServiceInstanceMockRecorder) SetZone(z interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetZone", reflect.TypeOf((*MockServiceInstance)(nil).SetZone), z)
+}
+
+// Zone mocks base method.
+func (m *MockServiceInstance) Zone() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Zone")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Zone indicates an expected call of Zone.
+func (mr *MockServiceInstanceMockRecorder) Zone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Zone", reflect.TypeOf((*MockServiceInstance)(nil).Zone))
+}
+
 // Shards mocks base method.
 func (m *MockServiceInstance) Shards() shard.Shards {
 	m.ctrl.T.Helper()
@@ -32,6 +32,8 @@ import (
 	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/cluster/kv/mem"
 	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/history"
+	"github.com/m3db/m3/src/cluster/placement/queue"
 	"github.com/m3db/m3/src/cluster/placement/storage"
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/x/instrument"
@@ -361,6 +363,43 @@ func TestAdvertiseUnadvertise(t *testing.T) {
 	}
 }
 
+func TestAdvertisementHealth(t *testing.T) {
+	opts, _ := testSetup()
+
+	sd, err := NewServices(opts)
+	require.NoError(t, err)
+
+	sid := NewServiceID().SetName("m3db").SetZone("zone1")
+	err = sd.AdvertisementHealth(sid, "")
+	require.Equal(t, errNoInstanceID, err)
+
+	err = sd.AdvertisementHealth(sid, "i1")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, errAdvertisementNotFound))
+
+	err = sd.SetMetadata(
+		sid,
+		NewMetadata().
+			SetLivenessInterval(time.Hour).
+			SetHeartbeatInterval(30*time.Minute),
+	)
+	require.NoError(t, err)
+
+	ad := NewAdvertisement().
+		SetServiceID(sid).
+		SetPlacementInstance(placement.NewInstance().SetID("i1"))
+	require.NoError(t, sd.Advertise(ad))
+
+	// wait for the first heartbeat
+	for sd.AdvertisementHealth(sid, "i1") != nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	require.NoError(t, sd.Unadvertise(sid, "i1"))
+	err = sd.AdvertisementHealth(sid, "i1")
+	require.True(t, errors.Is(err, errAdvertisementNotFound))
+}
+
 func TestIsHealthy(t *testing.T) {
 	require.True(t, isHealthy(NewAdvertisement()))
 
@@ -390,10 +429,12 @@ func TestQueryIncludeUnhealthy(t *testing.T) {
 		placement.NewInstance().
 			SetID("i1").
 			SetEndpoint("e1").
+			SetZone("zone1").
 			SetShards(shard.NewShards([]shard.Shard{shard.NewShard(1).SetState(shard.Initializing)})),
 		placement.NewInstance().
 			SetID("i2").
 			SetEndpoint("e2").
+			SetZone("zone2").
 			SetShards(shard.NewShards([]shard.Shard{shard.NewShard(1).SetState(shard.Initializing)})),
 	}).SetShards([]uint32{1}).SetReplicaFactor(2).SetIsSharded(true)
 
@@ -407,6 +448,8 @@ func TestQueryIncludeUnhealthy(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, len(s.Instances()))
 	require.Equal(t, sid, s.Instances()[0].ServiceID())
+	require.Equal(t, "zone1", s.Instances()[0].Zone())
+	require.Equal(t, "zone2", s.Instances()[1].Zone())
 	require.Equal(t, 1, s.Sharding().NumShards())
 	require.Equal(t, 2, s.Replication().Replicas())
 }
@@ -950,6 +993,91 @@ func TestHeartbeatService(t *testing.T) {
 	assert.NotNil(t, hb)
 }
 
+func TestPlacementServiceShardStateHistory(t *testing.T) {
+	opts, _ := testSetup()
+
+	sd, err := NewServices(opts)
+	require.NoError(t, err)
+
+	sid := NewServiceID().SetName("m3db").SetZone("z1")
+	pOpts := placement.NewOptions().SetValidZone("z1")
+
+	ps, err := sd.PlacementService(sid, pOpts)
+	require.NoError(t, err)
+
+	_, err = ps.BuildInitialPlacement([]placement.Instance{
+		placement.NewEmptyInstance("i1", "r1", "z1", "endpoint1", 1),
+	}, 2, 1)
+	require.NoError(t, err)
+	_, err = ps.MarkInstanceAvailable("i1")
+	require.NoError(t, err)
+
+	h, err := sd.ShardStateHistory(sid, pOpts)
+	require.NoError(t, err)
+	events, err := h.Events(history.Query{})
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, "Available", events[3].State)
+
+	// Recording is disabled with a zero maximum number of events.
+	sid = sid.SetName("m3agg")
+	pOpts = pOpts.SetShardStateHistoryMaxEvents(0)
+	ps, err = sd.PlacementService(sid, pOpts)
+	require.NoError(t, err)
+	_, err = ps.BuildInitialPlacement([]placement.Instance{
+		placement.NewEmptyInstance("i1", "r1", "z1", "endpoint1", 1),
+	}, 2, 1)
+	require.NoError(t, err)
+
+	h, err = sd.ShardStateHistory(sid, placement.NewOptions())
+	require.NoError(t, err)
+	events, err = h.Events(history.Query{})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestPlacementOperationQueue(t *testing.T) {
+	opts, _ := testSetup()
+
+	sd, err := NewServices(opts)
+	require.NoError(t, err)
+
+	sid := NewServiceID().SetName("m3db").SetZone("z1")
+	pOpts := placement.NewOptions().SetValidZone("z1")
+
+	ps, err := sd.PlacementService(sid, pOpts)
+	require.NoError(t, err)
+	_, err = ps.BuildInitialPlacement([]placement.Instance{
+		placement.NewEmptyInstance("i1", "r1", "z1", "endpoint1", 1),
+		placement.NewEmptyInstance("i2", "r2", "z1", "endpoint2", 1),
+	}, 2, 1)
+	require.NoError(t, err)
+
+	q, err := sd.PlacementOperationQueue(sid, queue.NewOptions().SetPlacementOptions(pOpts))
+	require.NoError(t, err)
+
+	// the operation is validated against the placement of the service.
+	_, err = q.Enqueue(queue.Operation{
+		Type:        queue.RemoveInstancesOperation,
+		RequestedBy: "alice",
+		InstanceIDs: []string{"i3"},
+	})
+	require.Error(t, err)
+	op, err := q.Enqueue(queue.Operation{
+		Type:        queue.RemoveInstancesOperation,
+		RequestedBy: "alice",
+		InstanceIDs: []string{"i2"},
+	})
+	require.NoError(t, err)
+
+	q, err = sd.PlacementOperationQueue(sid, nil)
+	require.NoError(t, err)
+	ops, err := q.Operations()
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	require.Equal(t, op.ID, ops[0].ID)
+}
+
 func TestCacheCollisions_Heartbeat(t *testing.T) {
 	opts, _ := testSetup()
 
@@ -1044,6 +1172,84 @@ func TestLeaderService(t *testing.T) {
 		"should cache 6 unique client entries")
 }
 
+func TestReadOnly(t *testing.T) {
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	ld := NewMockLeaderService(mc)
+	ld.EXPECT().Leader("e1").Return("i1", nil)
+
+	opts, m := testSetup()
+	opts = opts.SetLeaderGen(func(sid ServiceID, eo ElectionOptions) (LeaderService, error) {
+		return ld, nil
+	})
+	sd, err := NewServices(opts)
+	require.NoError(t, err)
+
+	sid := NewServiceID().SetName("m3db").SetZone("z1")
+	meta := NewMetadata().
+		SetLivenessInterval(2 * time.Second).
+		SetHeartbeatInterval(time.Second)
+	require.NoError(t, sd.SetMetadata(sid, meta))
+
+	hb, err := m.genMockStore(sid)
+	require.NoError(t, err)
+	require.NoError(t, hb.Heartbeat(placement.NewInstance().SetID("i1"), time.Hour))
+
+	roSD, err := NewServices(opts.SetReadOnly(true))
+	require.NoError(t, err)
+
+	mGet, err := roSD.Metadata(sid)
+	require.NoError(t, err)
+	require.Equal(t, meta, mGet)
+
+	requireReadOnlyError := func(err error, op string) {
+		require.True(t, errors.Is(err, kv.ErrReadOnly), op)
+
+		var readOnlyErr *kv.ReadOnlyError
+		require.True(t, errors.As(err, &readOnlyErr), op)
+		require.Equal(t, op, readOnlyErr.Op)
+	}
+
+	requireReadOnlyError(roSD.SetMetadata(sid, meta), "set")
+	requireReadOnlyError(roSD.DeleteMetadata(sid), "delete")
+
+	i1 := placement.NewInstance().SetID("i1")
+	requireReadOnlyError(roSD.Advertise(NewAdvertisement().
+		SetServiceID(sid).
+		SetPlacementInstance(i1)), "advertise")
+	requireReadOnlyError(roSD.Unadvertise(sid, "i1"), "delete heartbeat")
+
+	roHB, err := roSD.HeartbeatService(sid)
+	require.NoError(t, err)
+	ids, err := roHB.Get()
+	require.NoError(t, err)
+	require.Equal(t, []string{"i1"}, ids)
+	requireReadOnlyError(roHB.Heartbeat(i1, time.Hour), "heartbeat")
+
+	pOpts := placement.NewOptions().SetValidZone("z1")
+	ps, err := roSD.PlacementService(sid, pOpts)
+	require.NoError(t, err)
+	_, err = ps.BuildInitialPlacement([]placement.Instance{
+		placement.NewEmptyInstance("i1", "r1", "z1", "endpoint1", 1),
+	}, 2, 1)
+	require.True(t, errors.Is(err, kv.ErrReadOnly))
+
+	ps, err = sd.PlacementService(sid, pOpts)
+	require.NoError(t, err)
+	_, err = ps.Placement()
+	require.Equal(t, kv.ErrNotFound, err)
+
+	roLD, err := roSD.LeaderService(sid, nil)
+	require.NoError(t, err)
+	leader, err := roLD.Leader("e1")
+	require.NoError(t, err)
+	require.Equal(t, "i1", leader)
+	_, err = roLD.Campaign("e1", nil)
+	requireReadOnlyError(err, "campaign")
+	requireReadOnlyError(roLD.Resign("e1"), "resign")
+}
+
 func TestServiceIDEqual(t *testing.T) {
 	sid := NewServiceID().SetName("name").SetEnvironment("env").SetZone("zone")
 	assert.Equal(t, "name", sid.Name())
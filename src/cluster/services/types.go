@@ -26,9 +26,12 @@ import (
 	"github.com/m3db/m3/src/cluster/generated/proto/metadatapb"
 	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/history"
+	"github.com/m3db/m3/src/cluster/placement/queue"
 	"github.com/m3db/m3/src/cluster/services/leader/campaign"
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/retry"
 	xwatch "github.com/m3db/m3/src/x/watch"
 )
 
@@ -40,6 +43,11 @@ type Services interface {
 	// Unadvertise indicates a given instance is no longer available.
 	Unadvertise(service ServiceID, id string) error
 
+	// AdvertisementHealth returns an error if the advertisement of the given
+	// instance by this client is not currently live, i.e. no heartbeat has
+	// succeeded within the liveness interval of the service.
+	AdvertisementHealth(service ServiceID, id string) error
+
 	// Query returns the topology for a given service.
 	Query(service ServiceID, opts QueryOptions) (Service, error)
 
@@ -58,6 +66,14 @@ type Services interface {
 	// PlacementService returns a client of placement.Service.
 	PlacementService(sid ServiceID, popts placement.Options) (placement.Service, error)
 
+	// ShardStateHistory returns the shard state history recorded by the
+	// placement service of the given service.
+	ShardStateHistory(sid ServiceID, popts placement.Options) (history.Store, error)
+
+	// PlacementOperationQueue returns the queue of placement operations of the
+	// given service, the operations are validated against its placement.
+	PlacementOperationQueue(sid ServiceID, opts queue.Options) (queue.Queue, error)
+
 	// HeartbeatService returns a heartbeat store for the given service.
 	HeartbeatService(service ServiceID) (HeartbeatService, error)
 
@@ -84,6 +100,13 @@ type Options interface {
 	// SetInitTimeout sets the InitTimeout.
 	SetInitTimeout(t time.Duration) Options
 
+	// AdvertisementRetryOptions is the retry options used when a heartbeat
+	// for an advertised instance fails.
+	AdvertisementRetryOptions() retry.Options
+
+	// SetAdvertisementRetryOptions sets the AdvertisementRetryOptions.
+	SetAdvertisementRetryOptions(opts retry.Options) Options
+
 	// KVGen is the function to generate a kv store for a given zone.
 	KVGen() KVGen
 
@@ -115,6 +138,14 @@ type Options interface {
 	// SetNamespaceOptions sets the NamespaceOptions.
 	SetNamespaceOptions(opts NamespaceOptions) Options
 
+	// ReadOnly rejects every mutation made through the client, including
+	// metadata, placement and heartbeat writes and leader campaigns, with a
+	// *kv.ReadOnlyError.
+	ReadOnly() bool
+
+	// SetReadOnly sets the ReadOnly.
+	SetReadOnly(readOnly bool) Options
+
 	// Validate validates the Options.
 	Validate() error
 }
@@ -178,6 +209,13 @@ type Service interface {
 
 	// SetSharding sets the service sharding description or nil if none
 	SetSharding(s ServiceSharding) Service
+
+	// Version returns the version of the placement the service was built
+	// from, zero if the service was not built from a stored placement.
+	Version() int
+
+	// SetVersion sets the version of the placement the service was built from.
+	SetVersion(v int) Service
 }
 
 // ServiceReplication describes the replication of a service.
@@ -224,6 +262,12 @@ type ServiceInstance interface {
 	// SetEndpoint sets the endpoint of the instance.
 	SetEndpoint(e string) ServiceInstance
 
+	// Zone returns the zone of the instance.
+	Zone() string
+
+	// SetZone sets the zone of the instance.
+	SetZone(z string) ServiceInstance
+
 	// Shards returns the shards of the instance.
 	Shards() shard.Shards
 
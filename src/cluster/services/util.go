@@ -32,6 +32,9 @@ const (
 	placementPrefix = "_sd.placement"
 	metadataPrefix  = "_sd.metadata"
 	keyFormat       = "%s/%s"
+
+	shardStateHistorySuffix       = "_shard_history"
+	placementOperationQueueSuffix = "_operations"
 )
 
 type keyFn func(sid ServiceID) string
@@ -58,6 +61,18 @@ func keyFnWithNamespace(namespace string) keyFn {
 	}
 }
 
+// shardStateHistoryKey returns the key of the shard state history kept next
+// to the placement key.
+func shardStateHistoryKey(placementKey string) string {
+	return placementKey + shardStateHistorySuffix
+}
+
+// placementOperationQueueKey returns the key of the placement operation queue
+// kept next to the placement key.
+func placementOperationQueueKey(placementKey string) string {
+	return placementKey + placementOperationQueueSuffix
+}
+
 func adKey(sid ServiceID, id string) string {
 	return fmt.Sprintf(keyFormat, serviceKey(sid), id)
 }
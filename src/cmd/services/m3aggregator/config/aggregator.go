@@ -55,8 +55,9 @@ import (
 )
 
 var (
-	errNoKVClientConfiguration = errors.New("no kv client configuration")
-	errEmptyJitterBucketList   = errors.New("empty jitter bucket list")
+	errNoKVClientConfiguration    = errors.New("no kv client configuration")
+	errEmptyJitterBucketList      = errors.New("empty jitter bucket list")
+	errNoCorrectionsStoragePolicy = errors.New("corrections late arrival policy requires a corrections storage policy")
 )
 
 var (
@@ -115,6 +116,10 @@ type AggregatorConfiguration struct {
 	// Amount of time we buffer timed metrics in the future.
 	BufferDurationForFutureTimedMetric time.Duration `yaml:"bufferDurationForFutureTimedMetric"`
 
+	// LateArrival configures how values arriving for already flushed aggregation
+	// windows are handled per storage policy.
+	LateArrival *lateArrivalConfiguration `yaml:"lateArrival"`
+
 	// Resign timeout.
 	ResignTimeout time.Duration `yaml:"resignTimeout"`
 
@@ -186,6 +191,10 @@ type AggregatorConfiguration struct {
 	// WritesIgnoreCutoffCutover allows accepting writes ignoring cutoff/cutover timestamp.
 	// Must be in sync with m3msg WriterConfiguration.IgnoreCutoffCutover.
 	WritesIgnoreCutoffCutover bool `yaml:"writesIgnoreCutoffCutover"`
+
+	// Snapshot configures periodic snapshots of the aggregation state to local
+	// disk, which are restored on startup to avoid gaps after restarts.
+	Snapshot *snapshotConfiguration `yaml:"snapshot"`
 }
 
 // InstanceIDType is the instance ID type that defines how the
@@ -359,6 +368,13 @@ func (c *AggregatorConfiguration) NewAggregatorOptions(
 	if c.BufferDurationForFutureTimedMetric != 0 {
 		opts = opts.SetBufferForFutureTimedMetric(c.BufferDurationForFutureTimedMetric)
 	}
+	if c.LateArrival != nil {
+		lateArrivalFn, err := c.LateArrival.NewLateArrivalFn()
+		if err != nil {
+			return nil, err
+		}
+		opts = opts.SetLateArrivalFn(lateArrivalFn)
+	}
 
 	// Set resign timeout.
 	if c.ResignTimeout != 0 {
@@ -503,6 +519,14 @@ func (c *AggregatorConfiguration) NewAggregatorOptions(
 
 	opts = opts.SetWritesIgnoreCutoffCutover(c.WritesIgnoreCutoffCutover)
 
+	if c.Snapshot != nil {
+		snapshotOpts := c.Snapshot.NewSnapshotOptions()
+		if err := snapshotOpts.Validate(); err != nil {
+			return nil, err
+		}
+		opts = opts.SetSnapshotOptions(snapshotOpts)
+	}
+
 	return opts, nil
 }
 
@@ -553,6 +577,74 @@ func bufferForPastTimedMetricFn(buffer time.Duration) aggregator.BufferForPastTi
 	}
 }
 
+// lateArrivalConfiguration contains configuration for late arrival handling.
+type lateArrivalConfiguration struct {
+	// Default is the late arrival handling for storage policies without an override.
+	Default lateArrivalPolicyConfiguration `yaml:"default"`
+
+	// StoragePolicies overrides the late arrival handling for specific storage policies.
+	StoragePolicies []storagePolicyLateArrivalConfiguration `yaml:"storagePolicies"`
+}
+
+type lateArrivalPolicyConfiguration struct {
+	// Policy is one of drop (default), reflush or corrections.
+	Policy aggregator.LateArrivalPolicy `yaml:"policy"`
+
+	// GracePeriod is how long flushed aggregation windows keep accepting late values.
+	GracePeriod time.Duration `yaml:"gracePeriod"`
+
+	// CorrectionsStoragePolicy is the storage policy amended aggregates are
+	// flushed with, required by the corrections policy.
+	CorrectionsStoragePolicy *policy.StoragePolicy `yaml:"correctionsStoragePolicy"`
+}
+
+type storagePolicyLateArrivalConfiguration struct {
+	StoragePolicy                  policy.StoragePolicy `yaml:"storagePolicy"`
+	lateArrivalPolicyConfiguration `yaml:",inline"`
+}
+
+func (c lateArrivalPolicyConfiguration) newLateArrival() (aggregator.LateArrival, error) {
+	if c.GracePeriod < 0 {
+		return aggregator.LateArrival{}, fmt.Errorf("negative late arrival grace period %s", c.GracePeriod)
+	}
+	lateArrival := aggregator.LateArrival{
+		Policy:      c.Policy,
+		GracePeriod: c.GracePeriod,
+	}
+	if c.Policy == aggregator.CorrectionsLateArrivalPolicy {
+		if c.CorrectionsStoragePolicy == nil {
+			return aggregator.LateArrival{}, errNoCorrectionsStoragePolicy
+		}
+		lateArrival.CorrectionsStoragePolicy = *c.CorrectionsStoragePolicy
+	}
+	return lateArrival, nil
+}
+
+func (c lateArrivalConfiguration) NewLateArrivalFn() (aggregator.LateArrivalFn, error) {
+	defaultLateArrival, err := c.Default.newLateArrival()
+	if err != nil {
+		return nil, err
+	}
+	lateArrivals := make(map[policy.StoragePolicy]aggregator.LateArrival, len(c.StoragePolicies))
+	for _, cfg := range c.StoragePolicies {
+		if _, ok := lateArrivals[cfg.StoragePolicy]; ok {
+			return nil, fmt.Errorf("duplicate late arrival configuration for storage policy %s", cfg.StoragePolicy)
+		}
+		lateArrival, err := cfg.newLateArrival()
+		if err != nil {
+			return nil, fmt.Errorf("invalid late arrival configuration for storage policy %s: %v",
+				cfg.StoragePolicy, err)
+		}
+		lateArrivals[cfg.StoragePolicy] = lateArrival
+	}
+	return func(sp policy.StoragePolicy) aggregator.LateArrival {
+		if lateArrival, ok := lateArrivals[sp]; ok {
+			return lateArrival
+		}
+		return defaultLateArrival
+	}, nil
+}
+
 // streamConfiguration contains configuration for quantile-related metric streams.
 type streamConfiguration struct {
 	// Error epsilon for quantile computation.
@@ -658,6 +750,10 @@ type flushTimesManagerConfiguration struct {
 
 	// Retrier for persisting flush times.
 	FlushTimesPersistRetrier retry.Configuration `yaml:"flushTimesPersistRetrier"`
+
+	// Directory flush times are cached in locally so they are available on
+	// startup before the first kv update is received.
+	CacheDir string `yaml:"cacheDir"`
 }
 
 func (c flushTimesManagerConfiguration) NewFlushTimesManager(
@@ -679,6 +775,9 @@ func (c flushTimesManagerConfiguration) NewFlushTimesManager(
 		SetFlushTimesKeyFmt(c.FlushTimesKeyFmt).
 		SetFlushTimesStore(store).
 		SetFlushTimesPersistRetrier(retrier)
+	if c.CacheDir != "" {
+		flushTimesManagerOpts = flushTimesManagerOpts.SetFlushTimesCacheDir(c.CacheDir)
+	}
 	return aggregator.NewFlushTimesManager(flushTimesManagerOpts), nil
 }
 
@@ -804,6 +903,10 @@ type flushManagerConfiguration struct {
 	// Buckets for determining max jitter amounts.
 	MaxJitters []jitterBucket `yaml:"maxJitters"`
 
+	// Flush offsets for specific storage policy resolutions, which take
+	// precedence over jittering for metrics with those resolutions.
+	FlushOffsets []flushOffsetConfiguration `yaml:"flushOffsets"`
+
 	// Number of workers per CPU.
 	NumWorkersPerCPU float64 `yaml:"numWorkersPerCPU" validate:"min=0.0,max=1.0"`
 
@@ -816,6 +919,37 @@ type flushManagerConfiguration struct {
 
 	// Window size for a forced flush.
 	ForcedFlushWindowSize time.Duration `yaml:"forcedFlushWindowSize"`
+
+	// Retention of flush times that are no longer updated by the leader.
+	StaleFlushTimesRetention time.Duration `yaml:"staleFlushTimesRetention"`
+}
+
+// snapshotConfiguration contains aggregation state snapshot configuration.
+type snapshotConfiguration struct {
+	// Whether snapshotting is enabled.
+	Enabled bool `yaml:"enabled"`
+
+	// Directory snapshots are written to, one file per shard.
+	Directory string `yaml:"directory"`
+
+	// Interval between snapshots.
+	Interval time.Duration `yaml:"interval"`
+
+	// Maximum age of a snapshot for it to be restored on startup.
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
+func (c snapshotConfiguration) NewSnapshotOptions() aggregator.SnapshotOptions {
+	opts := aggregator.NewSnapshotOptions().
+		SetEnabled(c.Enabled).
+		SetDirectory(c.Directory)
+	if c.Interval != 0 {
+		opts = opts.SetInterval(c.Interval)
+	}
+	if c.MaxAge != 0 {
+		opts = opts.SetMaxAge(c.MaxAge)
+	}
+	return opts
 }
 
 func (c flushManagerConfiguration) NewFlushManagerOptions(
@@ -844,6 +978,14 @@ func (c flushManagerConfiguration) NewFlushManagerOptions(
 		}
 		opts = opts.SetMaxJitterFn(maxJitterFn)
 	}
+	if c.FlushOffsets != nil {
+		flushOffsets, err := flushOffsetConfigurations(c.FlushOffsets).NewFlushOffsets()
+		if err != nil {
+			return nil, err
+		}
+		opts = opts.SetFlushOffsets(flushOffsets).
+			SetMaxJitterFn(maxJitterFnWithFlushOffsets(opts.MaxJitterFn(), flushOffsets))
+	}
 	if c.NumWorkersPerCPU != 0 {
 		runtimeCPU := float64(runtime.GOMAXPROCS(0))
 		numWorkers := c.NumWorkersPerCPU * runtimeCPU
@@ -861,6 +1003,9 @@ func (c flushManagerConfiguration) NewFlushManagerOptions(
 	if c.ForcedFlushWindowSize != 0 {
 		opts = opts.SetForcedFlushWindowSize(c.ForcedFlushWindowSize)
 	}
+	if c.StaleFlushTimesRetention != 0 {
+		opts = opts.SetStaleFlushTimesRetention(c.StaleFlushTimesRetention)
+	}
 	return opts, nil
 }
 
@@ -902,6 +1047,68 @@ func (b jitterBucketsByIntervalAscending) Less(i, j int) bool {
 	return b[i].FlushInterval < b[j].FlushInterval
 }
 
+// flushOffsetConfiguration configures the flush offset for metrics with a
+// given storage policy resolution. Metrics are flushed at the offset plus a
+// jitter up to maxJitter past the end of each resolution window, where the
+// jitter is stable for a shard set so the leader and follower flush the same
+// data at the same time. Flushed datapoints remain aligned to the resolution.
+type flushOffsetConfiguration struct {
+	Resolution time.Duration `yaml:"resolution" validate:"nonzero"`
+	Offset     time.Duration `yaml:"offset"`
+	MaxJitter  time.Duration `yaml:"maxJitter"`
+}
+
+type flushOffsetConfigurations []flushOffsetConfiguration
+
+func (c flushOffsetConfigurations) NewFlushOffsets() ([]aggregator.FlushOffset, error) {
+	var (
+		res  = make([]aggregator.FlushOffset, 0, len(c))
+		seen = make(map[time.Duration]struct{}, len(c))
+	)
+	for _, cfg := range c {
+		if _, ok := seen[cfg.Resolution]; ok {
+			return nil, fmt.Errorf("duplicate flush offset for resolution %s", cfg.Resolution)
+		}
+		seen[cfg.Resolution] = struct{}{}
+
+		if cfg.Offset < 0 || cfg.MaxJitter < 0 {
+			return nil, fmt.Errorf("negative flush offset or max jitter for resolution %s", cfg.Resolution)
+		}
+		if cfg.Offset+cfg.MaxJitter >= cfg.Resolution {
+			return nil, fmt.Errorf("flush offset %s plus max jitter %s must be less than resolution %s",
+				cfg.Offset, cfg.MaxJitter, cfg.Resolution)
+		}
+		res = append(res, aggregator.FlushOffset{
+			FlushInterval: cfg.Resolution,
+			Offset:        cfg.Offset,
+			MaxJitter:     cfg.MaxJitter,
+		})
+	}
+	return res, nil
+}
+
+// maxJitterFnWithFlushOffsets returns a max jitter function that accounts for
+// the configured flush offsets so that the max allowed forwarding delay covers
+// the largest offset metrics with those resolutions may be flushed at.
+func maxJitterFnWithFlushOffsets(
+	maxJitterFn aggregator.FlushJitterFn,
+	flushOffsets []aggregator.FlushOffset,
+) aggregator.FlushJitterFn {
+	maxOffsets := make(map[time.Duration]time.Duration, len(flushOffsets))
+	for _, offset := range flushOffsets {
+		maxOffsets[offset.FlushInterval] = offset.Offset + offset.MaxJitter
+	}
+	return func(interval time.Duration) time.Duration {
+		if maxOffset, ok := maxOffsets[interval]; ok {
+			return maxOffset
+		}
+		if maxJitterFn == nil {
+			return interval
+		}
+		return maxJitterFn(interval)
+	}
+}
+
 type metricPrefixSetter func(b []byte) aggregator.Options
 
 func setMetricPrefix(
@@ -24,6 +24,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/aggregator/aggregator"
+	"github.com/m3db/m3/src/metrics/policy"
+
 	"github.com/stretchr/testify/require"
 	yaml "gopkg.in/yaml.v2"
 )
@@ -59,6 +62,50 @@ func TestJitterBuckets(t *testing.T) {
 	}
 }
 
+func TestFlushOffsets(t *testing.T) {
+	config := `
+    - resolution: 10s
+      offset: 2s
+      maxJitter: 3s
+    - resolution: 1m
+      offset: 5s`
+
+	var cfgs flushOffsetConfigurations
+	require.NoError(t, yaml.Unmarshal([]byte(config), &cfgs))
+
+	flushOffsets, err := cfgs.NewFlushOffsets()
+	require.NoError(t, err)
+	require.Equal(t, []aggregator.FlushOffset{
+		{FlushInterval: 10 * time.Second, Offset: 2 * time.Second, MaxJitter: 3 * time.Second},
+		{FlushInterval: time.Minute, Offset: 5 * time.Second},
+	}, flushOffsets)
+
+	maxJitterFn := maxJitterFnWithFlushOffsets(func(interval time.Duration) time.Duration {
+		return interval / 2
+	}, flushOffsets)
+	require.Equal(t, 5*time.Second, maxJitterFn(10*time.Second))
+	require.Equal(t, 5*time.Second, maxJitterFn(time.Minute))
+	require.Equal(t, 5*time.Minute, maxJitterFn(10*time.Minute))
+}
+
+func TestFlushOffsetsInvalid(t *testing.T) {
+	_, err := flushOffsetConfigurations{
+		{Resolution: 10 * time.Second, Offset: 8 * time.Second, MaxJitter: 2 * time.Second},
+	}.NewFlushOffsets()
+	require.Error(t, err)
+
+	_, err = flushOffsetConfigurations{
+		{Resolution: 10 * time.Second, Offset: time.Second},
+		{Resolution: 10 * time.Second, Offset: 2 * time.Second},
+	}.NewFlushOffsets()
+	require.Error(t, err)
+
+	_, err = flushOffsetConfigurations{
+		{Resolution: 10 * time.Second, Offset: -time.Second},
+	}.NewFlushOffsets()
+	require.Error(t, err)
+}
+
 func TestMaxAllowedForwardingDelayFnJitterEnabled(t *testing.T) {
 	maxJitterFn := func(resolution time.Duration) time.Duration {
 		if resolution <= time.Second {
@@ -118,3 +165,57 @@ func TestMaxAllowedForwardingDelayFnJitterDisabled(t *testing.T) {
 		require.Equal(t, input.expected, fn(input.resolution, input.numForwardedTimes))
 	}
 }
+
+func TestLateArrivalFn(t *testing.T) {
+	config := `
+default:
+  policy: drop
+storagePolicies:
+  - storagePolicy: 10s:2d
+    policy: reflush
+    gracePeriod: 5m
+  - storagePolicy: 1m:40d
+    policy: corrections
+    gracePeriod: 1h
+    correctionsStoragePolicy: 1m:90d`
+
+	var cfg lateArrivalConfiguration
+	require.NoError(t, yaml.Unmarshal([]byte(config), &cfg))
+
+	lateArrivalFn, err := cfg.NewLateArrivalFn()
+	require.NoError(t, err)
+
+	require.Equal(t, aggregator.LateArrival{
+		Policy: aggregator.DropLateArrivalPolicy,
+	}, lateArrivalFn(policy.MustParseStoragePolicy("1h:1y")))
+	require.Equal(t, aggregator.LateArrival{
+		Policy:      aggregator.ReflushLateArrivalPolicy,
+		GracePeriod: 5 * time.Minute,
+	}, lateArrivalFn(policy.MustParseStoragePolicy("10s:2d")))
+	require.Equal(t, aggregator.LateArrival{
+		Policy:                   aggregator.CorrectionsLateArrivalPolicy,
+		GracePeriod:              time.Hour,
+		CorrectionsStoragePolicy: policy.MustParseStoragePolicy("1m:90d"),
+	}, lateArrivalFn(policy.MustParseStoragePolicy("1m:40d")))
+}
+
+func TestLateArrivalFnInvalid(t *testing.T) {
+	_, err := lateArrivalConfiguration{
+		Default: lateArrivalPolicyConfiguration{Policy: aggregator.CorrectionsLateArrivalPolicy},
+	}.NewLateArrivalFn()
+	require.Equal(t, errNoCorrectionsStoragePolicy, err)
+
+	_, err = lateArrivalConfiguration{
+		Default: lateArrivalPolicyConfiguration{GracePeriod: -time.Second},
+	}.NewLateArrivalFn()
+	require.Error(t, err)
+
+	sp := policy.MustParseStoragePolicy("10s:2d")
+	_, err = lateArrivalConfiguration{
+		StoragePolicies: []storagePolicyLateArrivalConfiguration{
+			{StoragePolicy: sp},
+			{StoragePolicy: sp},
+		},
+	}.NewLateArrivalFn()
+	require.Error(t, err)
+}
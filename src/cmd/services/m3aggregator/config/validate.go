@@ -0,0 +1,56 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/x/config/configvalidate"
+)
+
+// Check checks the aggregation policies of the configuration and adds the
+// problems found to the report.
+func (c *Configuration) Check(report *configvalidate.Report, _ configvalidate.Options) {
+	aggCfg := c.AggregatorOrDefault()
+	seen := make(map[policy.StoragePolicy]int, len(aggCfg.DefaultStoragePolicies))
+	for i, sp := range aggCfg.DefaultStoragePolicies {
+		path := fmt.Sprintf("aggregator.defaultStoragePolicies[%d]", i)
+		resolution, retention := sp.Resolution().Window, sp.Retention().Duration()
+		switch {
+		case resolution <= 0:
+			report.Addf(path, "storage policy %s requires a positive resolution", sp)
+		case retention < resolution:
+			report.Addf(path, "retention %s of storage policy %s is less than its resolution %s",
+				retention, sp, resolution)
+		case retention%resolution != 0:
+			report.Addf(path, "retention %s of storage policy %s is not a multiple of its resolution %s",
+				retention, sp, resolution)
+		}
+
+		if prev, ok := seen[sp]; ok {
+			report.Addf(path, "storage policy %s duplicates aggregator.defaultStoragePolicies[%d]",
+				sp, prev)
+			continue
+		}
+		seen[sp] = i
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/x/config/configvalidate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationCheck(t *testing.T) {
+	var (
+		valid       = policy.MustParseStoragePolicy("1m:40d")
+		tooShort    = policy.MustParseStoragePolicy("1h:30m")
+		notMultiple = policy.MustParseStoragePolicy("1m:90s")
+		cfg         = Configuration{
+			Aggregator: &AggregatorConfiguration{
+				DefaultStoragePolicies: []policy.StoragePolicy{
+					valid, tooShort, notMultiple, valid,
+				},
+			},
+		}
+		report configvalidate.Report
+	)
+	cfg.Check(&report, configvalidate.Options{})
+	require.Equal(t, []configvalidate.Problem{
+		{
+			Path: "aggregator.defaultStoragePolicies[1]",
+			Message: fmt.Sprintf("retention 30m0s of storage policy %s is less than its resolution 1h0m0s",
+				tooShort),
+		},
+		{
+			Path: "aggregator.defaultStoragePolicies[2]",
+			Message: fmt.Sprintf("retention 1m30s of storage policy %s is not a multiple of its resolution 1m0s",
+				notMultiple),
+		},
+		{
+			Path: "aggregator.defaultStoragePolicies[3]",
+			Message: fmt.Sprintf("storage policy %s duplicates aggregator.defaultStoragePolicies[0]",
+				valid),
+		},
+	}, report.Problems)
+}
@@ -23,14 +23,21 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 
 	"github.com/m3db/m3/src/aggregator/server"
 	"github.com/m3db/m3/src/cmd/services/m3aggregator/config"
 	xconfig "github.com/m3db/m3/src/x/config"
 	"github.com/m3db/m3/src/x/config/configflag"
+	"github.com/m3db/m3/src/x/config/configvalidate"
 )
 
 func main() {
+	if configvalidate.IsCommand(os.Args) {
+		var cfg config.Configuration
+		configvalidate.Main("m3aggregator", os.Args, &cfg, cfg.Check)
+	}
+
 	var cfgOpts configflag.Options
 	cfgOpts.Register()
 
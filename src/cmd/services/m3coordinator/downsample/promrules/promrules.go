@@ -0,0 +1,325 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package promrules translates Prometheus recording rule files into
+// downsample rollup rules.
+package promrules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
+	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/metrics/transformation"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var errNoStoragePolicies = errors.New("no storage policies for imported rules")
+
+// RuleGroups is a Prometheus rule file.
+type RuleGroups struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of Prometheus rules.
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a Prometheus recording or alerting rule.
+type Rule struct {
+	Record string            `yaml:"record"`
+	Alert  string            `yaml:"alert"`
+	Expr   string            `yaml:"expr"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+// RuleReport is the outcome of translating a single rule.
+type RuleReport struct {
+	Group      string `json:"group" yaml:"group"`
+	Record     string `json:"record,omitempty" yaml:"record,omitempty"`
+	Alert      string `json:"alert,omitempty" yaml:"alert,omitempty"`
+	Expr       string `json:"expr" yaml:"expr"`
+	Translated bool   `json:"translated" yaml:"translated"`
+	// Reason is why the rule was not translated, or how the translated rule
+	// differs from the recording rule.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Report is the compatibility report of a rule file import.
+type Report struct {
+	Translated int          `json:"translated" yaml:"translated"`
+	Skipped    int          `json:"skipped" yaml:"skipped"`
+	Rules      []RuleReport `json:"rules" yaml:"rules"`
+}
+
+// Import translates the recording rules of a Prometheus rule file into rollup
+// rules kept at the given storage policies. Rules that cannot be expressed as
+// rollup rules are skipped and listed in the report along with the reason.
+//
+// Supported recording rules aggregate a single selector, optionally wrapped in
+// rate or increase, with one of sum, min, max, count or avg, e.g.
+// sum by (service) (rate(http_requests_total{env="prod"}[1m])).
+func Import(
+	data []byte,
+	storagePolicies []downsample.StoragePolicyConfiguration,
+) (downsample.RulesConfiguration, Report, error) {
+	if len(storagePolicies) == 0 {
+		return downsample.RulesConfiguration{}, Report{}, errNoStoragePolicies
+	}
+
+	var groups RuleGroups
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return downsample.RulesConfiguration{}, Report{},
+			fmt.Errorf("unable to parse rule file: %v", err)
+	}
+
+	var (
+		rules  downsample.RulesConfiguration
+		report Report
+	)
+	for _, group := range groups.Groups {
+		for _, rule := range group.Rules {
+			ruleReport := RuleReport{
+				Group:  group.Name,
+				Record: rule.Record,
+				Alert:  rule.Alert,
+				Expr:   rule.Expr,
+			}
+			rollupRule, caveat, err := translate(rule, storagePolicies)
+			if err != nil {
+				ruleReport.Reason = err.Error()
+				report.Skipped++
+			} else {
+				rules.RollupRules = append(rules.RollupRules, rollupRule)
+				ruleReport.Translated = true
+				ruleReport.Reason = caveat
+				report.Translated++
+			}
+			report.Rules = append(report.Rules, ruleReport)
+		}
+	}
+	return rules, report, nil
+}
+
+// translate translates a recording rule into a rollup rule, returning any
+// caveat of the translation.
+func translate(
+	rule Rule,
+	storagePolicies []downsample.StoragePolicyConfiguration,
+) (downsample.RollupRuleConfiguration, string, error) {
+	if rule.Record == "" {
+		return downsample.RollupRuleConfiguration{}, "", errors.New("alerting rules are not translated")
+	}
+
+	expr, err := parser.ParseExpr(rule.Expr)
+	if err != nil {
+		return downsample.RollupRuleConfiguration{}, "", fmt.Errorf("unable to parse expression: %v", err)
+	}
+	agg, ok := unwrapParens(expr).(*parser.AggregateExpr)
+	if !ok {
+		return downsample.RollupRuleConfiguration{}, "",
+			errors.New("expression is not an aggregation of a selector")
+	}
+	aggType, ok := aggregationTypes[agg.Op]
+	if !ok {
+		return downsample.RollupRuleConfiguration{}, "",
+			fmt.Errorf("aggregation %s has no rollup equivalent", agg.Op)
+	}
+
+	var (
+		transforms []downsample.TransformConfiguration
+		caveats    []string
+		selector   *parser.VectorSelector
+	)
+	switch inner := unwrapParens(agg.Expr).(type) {
+	case *parser.VectorSelector:
+		selector = inner
+	case *parser.Call:
+		transformType, ok := transformationTypes[inner.Func.Name]
+		if !ok {
+			return downsample.RollupRuleConfiguration{}, "",
+				fmt.Errorf("function %s is not translatable", inner.Func.Name)
+		}
+		matrix, ok := unwrapParens(inner.Args[0]).(*parser.MatrixSelector)
+		if !ok {
+			return downsample.RollupRuleConfiguration{}, "",
+				fmt.Errorf("function %s does not take a range selector", inner.Func.Name)
+		}
+		selector = matrix.VectorSelector.(*parser.VectorSelector)
+		transforms = append(transforms, downsample.TransformConfiguration{
+			Transform: &downsample.TransformOperationConfiguration{Type: transformType},
+		})
+		caveats = append(caveats, fmt.Sprintf(
+			"%s over %s is computed over the storage policy resolution instead",
+			inner.Func.Name, matrix.Range))
+	default:
+		return downsample.RollupRuleConfiguration{}, "",
+			errors.New("expression is not an aggregation of a selector")
+	}
+	if agg.Op == parser.AVG {
+		caveats = append(caveats, "avg is the mean over the storage policy resolution")
+	}
+
+	filter, err := selectorFilter(selector)
+	if err != nil {
+		return downsample.RollupRuleConfiguration{}, "", err
+	}
+
+	rollup := &downsample.RollupOperationConfiguration{
+		MetricName:   rule.Record,
+		Aggregations: []aggregation.Type{aggType},
+	}
+	grouping := append([]string(nil), agg.Grouping...)
+	sort.Strings(grouping)
+	if agg.Without {
+		rollup.ExcludeBy = grouping
+	} else {
+		rollup.GroupBy = grouping
+	}
+	transforms = append(transforms, downsample.TransformConfiguration{Rollup: rollup})
+
+	names := make([]string, 0, len(rule.Labels))
+	for name := range rule.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tags := make([]downsample.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, downsample.Tag{Name: name, Value: rule.Labels[name]})
+	}
+
+	return downsample.RollupRuleConfiguration{
+		Name:            rule.Record,
+		Filter:          filter,
+		Transforms:      transforms,
+		StoragePolicies: storagePolicies,
+		Tags:            tags,
+	}, strings.Join(caveats, "; "), nil
+}
+
+var (
+	aggregationTypes = map[parser.ItemType]aggregation.Type{
+		parser.SUM:   aggregation.Sum,
+		parser.MIN:   aggregation.Min,
+		parser.MAX:   aggregation.Max,
+		parser.COUNT: aggregation.Count,
+		parser.AVG:   aggregation.Mean,
+	}
+	transformationTypes = map[string]transformation.Type{
+		"rate":     transformation.PerSecond,
+		"increase": transformation.Increase,
+	}
+)
+
+func unwrapParens(expr parser.Expr) parser.Expr {
+	for {
+		paren, ok := expr.(*parser.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.Expr
+	}
+}
+
+// selectorFilter returns the rule filter matching the series of a selector.
+func selectorFilter(selector *parser.VectorSelector) (string, error) {
+	if selector.OriginalOffset != 0 || selector.Timestamp != nil || selector.StartOrEnd != 0 {
+		return "", errors.New("offset and @ modifiers are not translatable")
+	}
+
+	var (
+		name    = selector.Name
+		filters []string
+	)
+	for _, m := range selector.LabelMatchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+			name = m.Value
+			continue
+		}
+		value, err := matcherFilterValue(m)
+		if err != nil {
+			return "", err
+		}
+		filters = append(filters, m.Name+":"+value)
+	}
+	if name == "" {
+		return "", errors.New("selector has no metric name")
+	}
+	if !isFilterLiteral(name) {
+		return "", fmt.Errorf("metric name %s is not translatable", name)
+	}
+	sort.Strings(filters)
+	return strings.Join(append([]string{labels.MetricName + ":" + name}, filters...), " "), nil
+}
+
+// matcherFilterValue returns the filter value of a label matcher, regexp
+// matchers are translated if they are either an alternation of literals or
+// a single literal containing .* wildcards.
+func matcherFilterValue(m *labels.Matcher) (string, error) {
+	var negate bool
+	switch m.Type {
+	case labels.MatchNotEqual, labels.MatchNotRegexp:
+		negate = true
+	}
+
+	var value string
+	switch m.Type {
+	case labels.MatchEqual, labels.MatchNotEqual:
+		if m.Value == "" || !isFilterLiteral(m.Value) {
+			return "", fmt.Errorf("matcher %s is not translatable", m)
+		}
+		value = m.Value
+	default:
+		alternatives := strings.Split(m.Value, "|")
+		for i, alt := range alternatives {
+			// substitute wildcards so that any other regexp syntax is rejected.
+			alt = strings.ReplaceAll(alt, ".*", "\x00")
+			if alt == "" || strings.ContainsAny(alt, `.+*?()[]{}^$\`) || !isFilterLiteral(alt) {
+				return "", fmt.Errorf("matcher %s is not translatable", m)
+			}
+			alternatives[i] = strings.ReplaceAll(alt, "\x00", "*")
+			if len(alternatives) > 1 && strings.Contains(alternatives[i], "*") {
+				// filters do not support wildcards within alternations.
+				return "", fmt.Errorf("matcher %s is not translatable", m)
+			}
+		}
+		value = alternatives[0]
+		if len(alternatives) > 1 {
+			value = "{" + strings.Join(alternatives, ",") + "}"
+		}
+	}
+	if negate {
+		value = "!" + value
+	}
+	return value, nil
+}
+
+// isFilterLiteral returns whether a value only matches itself in a rule filter.
+func isFilterLiteral(value string) bool {
+	return !strings.ContainsAny(value, " :*?![]{},")
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promrules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
+	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/metrics/transformation"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testStoragePolicies = []downsample.StoragePolicyConfiguration{
+	{Resolution: time.Minute, Retention: 40 * 24 * time.Hour},
+}
+
+const testRuleFile = `
+groups:
+  - name: http
+    interval: 1m
+    rules:
+      - record: service:http_requests:rate1m
+        expr: sum by (service, code) (rate(http_requests_total{env="prod",code=~"500|503"}[1m]))
+        labels:
+          team: web
+      - record: http_inflight:max
+        expr: max without (instance) (http_inflight{job!~"canary.*"})
+      - record: http_latency:p99
+        expr: histogram_quantile(0.99, sum by (le) (rate(http_latency_bucket[5m])))
+      - alert: HighErrorRate
+        expr: service:http_requests:rate1m > 10
+      - record: http_requests:topk
+        expr: topk(5, http_requests_total)
+      - record: http_requests:regexp
+        expr: sum(http_requests_total{path=~"/api/[a-z]+"})
+`
+
+func TestImport(t *testing.T) {
+	rules, report, err := Import([]byte(testRuleFile), testStoragePolicies)
+	require.NoError(t, err)
+
+	require.Equal(t, downsample.RulesConfiguration{
+		RollupRules: []downsample.RollupRuleConfiguration{
+			{
+				Name:   "service:http_requests:rate1m",
+				Filter: "__name__:http_requests_total code:{500,503} env:prod",
+				Transforms: []downsample.TransformConfiguration{
+					{
+						Transform: &downsample.TransformOperationConfiguration{
+							Type: transformation.PerSecond,
+						},
+					},
+					{
+						Rollup: &downsample.RollupOperationConfiguration{
+							MetricName:   "service:http_requests:rate1m",
+							GroupBy:      []string{"code", "service"},
+							Aggregations: []aggregation.Type{aggregation.Sum},
+						},
+					},
+				},
+				StoragePolicies: testStoragePolicies,
+				Tags:            []downsample.Tag{{Name: "team", Value: "web"}},
+			},
+			{
+				Name:   "http_inflight:max",
+				Filter: "__name__:http_inflight job:!canary*",
+				Transforms: []downsample.TransformConfiguration{
+					{
+						Rollup: &downsample.RollupOperationConfiguration{
+							MetricName:   "http_inflight:max",
+							ExcludeBy:    []string{"instance"},
+							Aggregations: []aggregation.Type{aggregation.Max},
+						},
+					},
+				},
+				StoragePolicies: testStoragePolicies,
+				Tags:            []downsample.Tag{},
+			},
+		},
+	}, rules)
+
+	require.Equal(t, 2, report.Translated)
+	require.Equal(t, 4, report.Skipped)
+	require.Equal(t, 6, len(report.Rules))
+	expected := []struct {
+		translated bool
+		reason     string
+	}{
+		{translated: true, reason: "rate over 1m0s is computed over the storage policy resolution instead"},
+		{translated: true},
+		{reason: "expression is not an aggregation of a selector"},
+		{reason: "alerting rules are not translated"},
+		{reason: "aggregation topk has no rollup equivalent"},
+		{reason: `matcher path=~"/api/[a-z]+" is not translatable`},
+	}
+	for i, e := range expected {
+		require.Equal(t, "http", report.Rules[i].Group)
+		require.Equal(t, e.translated, report.Rules[i].Translated, report.Rules[i].Expr)
+		require.Equal(t, e.reason, report.Rules[i].Reason, report.Rules[i].Expr)
+	}
+}
+
+func TestImportInvalid(t *testing.T) {
+	_, _, err := Import([]byte(testRuleFile), nil)
+	require.Equal(t, errNoStoragePolicies, err)
+
+	_, _, err = Import([]byte("groups: [}"), testStoragePolicies)
+	require.Error(t, err)
+}
+
+func TestImportedRulesAreValid(t *testing.T) {
+	rules, _, err := Import([]byte(testRuleFile), testStoragePolicies)
+	require.NoError(t, err)
+	for _, rule := range rules.RollupRules {
+		_, err := rule.Rule()
+		require.NoError(t, err)
+	}
+}
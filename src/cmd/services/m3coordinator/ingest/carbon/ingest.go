@@ -120,11 +120,17 @@ func NewIngester(
 	resourcePool.Init(func() interface{} {
 		return &lineResources{
 			name:       make([]byte, 0, maxResourcePoolNameSize),
+			mappedName: make([]byte, 0, maxResourcePoolNameSize),
 			datapoints: make([]ts.Datapoint, 1),
 			tags:       make([]models.Tag, 0, maxPooledTagsSize),
 		}
 	})
 
+	tagMappings, err := compileTagMappings(opts.IngesterConfig.TagMappings, tagOpts)
+	if err != nil {
+		return nil, err
+	}
+
 	scope := opts.InstrumentOptions.MetricsScope()
 	metrics, err := newCarbonIngesterMetrics(scope)
 	if err != nil {
@@ -136,6 +142,7 @@ func NewIngester(
 		opts:                 opts,
 		logger:               opts.InstrumentOptions.Logger(),
 		tagOpts:              tagOpts,
+		tagMappings:          tagMappings,
 		metrics:              metrics,
 		lineResourcesPool:    resourcePool,
 	}
@@ -152,6 +159,7 @@ type ingester struct {
 	logger               *zap.Logger
 	metrics              carbonIngesterMetrics
 	tagOpts              models.TagOptions
+	tagMappings          []tagMapping
 
 	lineResourcesPool pool.ObjectPool
 
@@ -430,6 +438,10 @@ func (i *ingester) writeWithOptions(
 		return err
 	}
 
+	if mapping, ok := matchTagMapping(i.tagMappings, tags.Tags); ok {
+		tags, resources.mappedName = mapping.apply(tags, resources.mappedName)
+	}
+
 	err = i.downsamplerAndWriter.Write(ctx, tags, resources.datapoints,
 		xtime.Second, nil, opts, ts.SourceTypeGraphite)
 	if err != nil {
@@ -612,6 +624,7 @@ func (i *ingester) getLineResources() *lineResources {
 
 func (i *ingester) putLineResources(l *lineResources) {
 	tooLargeForPool := cap(l.name) > maxResourcePoolNameSize ||
+		cap(l.mappedName) > maxResourcePoolNameSize ||
 		len(l.datapoints) > 1 || // We always write one datapoint at a time.
 		cap(l.datapoints) > 1 ||
 		cap(l.tags) > maxPooledTagsSize
@@ -622,6 +635,7 @@ func (i *ingester) putLineResources(l *lineResources) {
 
 	// Reset.
 	l.name = l.name[:0]
+	l.mappedName = l.mappedName[:0]
 	l.datapoints[0] = ts.Datapoint{}
 	for i := range l.tags {
 		// Free pointers.
@@ -634,6 +648,7 @@ func (i *ingester) putLineResources(l *lineResources) {
 
 type lineResources struct {
 	name       []byte
+	mappedName []byte
 	datapoints []ts.Datapoint
 	tags       []models.Tag
 }
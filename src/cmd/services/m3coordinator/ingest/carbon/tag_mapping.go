@@ -0,0 +1,201 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/graphite/graphite"
+	"github.com/m3db/m3/src/query/models"
+)
+
+const tagMappingWildcard = "*"
+
+var (
+	errTagMappingEmptyPrefix = errors.New("carbon tag mapping: prefix must be set")
+	errTagMappingNoTags      = errors.New("carbon tag mapping: tags must be set")
+)
+
+// tagMapping is a compiled carbon tag mapping that maps the path components
+// of metrics matching a prefix to tags.
+type tagMapping struct {
+	// prefix has a nil entry for each wildcard path component.
+	prefix [][]byte
+	// tags is sorted by path component position.
+	tags []mappedTag
+	name []byte
+}
+
+type mappedTag struct {
+	position int
+	name     []byte
+}
+
+func compileTagMappings(
+	mappings []config.CarbonIngesterTagMappingConfiguration,
+	opts models.TagOptions,
+) ([]tagMapping, error) {
+	compiled := make([]tagMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		if mapping.Prefix == "" {
+			return nil, errTagMappingEmptyPrefix
+		}
+		if len(mapping.Tags) == 0 {
+			return nil, errTagMappingNoTags
+		}
+
+		components := strings.Split(mapping.Prefix, string(carbonSeparatorByte))
+		prefix := make([][]byte, 0, len(components))
+		for _, component := range components {
+			if component == "" {
+				return nil, fmt.Errorf(
+					"carbon tag mapping: prefix has empty path component: prefix=%s",
+					mapping.Prefix)
+			}
+			if component == tagMappingWildcard {
+				prefix = append(prefix, nil)
+				continue
+			}
+			prefix = append(prefix, []byte(component))
+		}
+
+		tags := make([]mappedTag, 0, len(mapping.Tags))
+		names := make(map[string]struct{}, len(mapping.Tags))
+		for position, name := range mapping.Tags {
+			if position < 0 {
+				return nil, fmt.Errorf(
+					"carbon tag mapping: negative path component position: prefix=%s, position=%d",
+					mapping.Prefix, position)
+			}
+			if err := validateMappedTagName([]byte(name), opts); err != nil {
+				return nil, fmt.Errorf("carbon tag mapping: prefix=%s, position=%d: %w",
+					mapping.Prefix, position, err)
+			}
+			if _, ok := names[name]; ok {
+				return nil, fmt.Errorf(
+					"carbon tag mapping: tag mapped more than once: prefix=%s, tag=%s",
+					mapping.Prefix, name)
+			}
+			names[name] = struct{}{}
+			tags = append(tags, mappedTag{position: position, name: []byte(name)})
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			return tags[i].position < tags[j].position
+		})
+
+		var name []byte
+		if mapping.Name != "" {
+			name = []byte(mapping.Name)
+		}
+
+		compiled = append(compiled, tagMapping{
+			prefix: prefix,
+			tags:   tags,
+			name:   name,
+		})
+	}
+
+	return compiled, nil
+}
+
+func validateMappedTagName(name []byte, opts models.TagOptions) error {
+	if len(name) == 0 {
+		return errors.New("tag name must not be empty")
+	}
+	if _, ok := graphite.TagIndex(name); ok {
+		return fmt.Errorf("tag name must not be a graphite path tag: %s", name)
+	}
+	if bytes.Equal(name, opts.MetricName()) {
+		return fmt.Errorf("tag name must not be the metric name tag: %s", name)
+	}
+	return nil
+}
+
+// matchTagMapping returns the first mapping whose prefix matches the path
+// tags generated for a metric.
+func matchTagMapping(mappings []tagMapping, pathTags []models.Tag) (tagMapping, bool) {
+	for _, mapping := range mappings {
+		if mapping.match(pathTags) {
+			return mapping, true
+		}
+	}
+	return tagMapping{}, false
+}
+
+func (m tagMapping) match(pathTags []models.Tag) bool {
+	if len(pathTags) < len(m.prefix) {
+		return false
+	}
+	for i, component := range m.prefix {
+		if component != nil && !bytes.Equal(component, pathTags[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply adds the mapped tags and the metric name tag to the path tags
+// generated for a metric, nameBuf is used to generate the metric name when
+// not explicitly set and is returned for reuse.
+func (m tagMapping) apply(tags models.Tags, nameBuf []byte) (models.Tags, []byte) {
+	numPathTags := len(tags.Tags)
+	for _, tag := range m.tags {
+		if tag.position >= numPathTags {
+			break
+		}
+		tags.Tags = append(tags.Tags, models.Tag{
+			Name:  tag.name,
+			Value: tags.Tags[tag.position].Value,
+		})
+	}
+
+	name := m.name
+	if name == nil {
+		// Join the path components that are not mapped to tags.
+		nameBuf = nameBuf[:0]
+		next := 0
+		for i := 0; i < numPathTags; i++ {
+			if next < len(m.tags) && m.tags[next].position == i {
+				next++
+				continue
+			}
+			if len(nameBuf) > 0 {
+				nameBuf = append(nameBuf, '_')
+			}
+			nameBuf = append(nameBuf, tags.Tags[i].Value...)
+		}
+		name = nameBuf
+	}
+
+	if len(name) > 0 {
+		tags.Tags = append(tags.Tags, models.Tag{
+			Name:  tags.Opts.MetricName(),
+			Value: name,
+		})
+	}
+
+	return tags.Normalize(), nameBuf
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/graphite/graphite"
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileTagMappingsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  config.CarbonIngesterTagMappingConfiguration
+		expected string
+	}{
+		{
+			name:     "empty prefix",
+			mapping:  config.CarbonIngesterTagMappingConfiguration{Tags: map[int]string{0: "a"}},
+			expected: errTagMappingEmptyPrefix.Error(),
+		},
+		{
+			name:     "no tags",
+			mapping:  config.CarbonIngesterTagMappingConfiguration{Prefix: "servers"},
+			expected: errTagMappingNoTags.Error(),
+		},
+		{
+			name: "empty prefix component",
+			mapping: config.CarbonIngesterTagMappingConfiguration{
+				Prefix: "servers..cpu",
+				Tags:   map[int]string{1: "dc"},
+			},
+			expected: "carbon tag mapping: prefix has empty path component: prefix=servers..cpu",
+		},
+		{
+			name: "negative position",
+			mapping: config.CarbonIngesterTagMappingConfiguration{
+				Prefix: "servers",
+				Tags:   map[int]string{-1: "dc"},
+			},
+			expected: "carbon tag mapping: negative path component position: prefix=servers, position=-1",
+		},
+		{
+			name: "graphite path tag",
+			mapping: config.CarbonIngesterTagMappingConfiguration{
+				Prefix: "servers",
+				Tags:   map[int]string{1: "__g1__"},
+			},
+			expected: "carbon tag mapping: prefix=servers, position=1: " +
+				"tag name must not be a graphite path tag: __g1__",
+		},
+		{
+			name: "metric name tag",
+			mapping: config.CarbonIngesterTagMappingConfiguration{
+				Prefix: "servers",
+				Tags:   map[int]string{1: "__name__"},
+			},
+			expected: "carbon tag mapping: prefix=servers, position=1: " +
+				"tag name must not be the metric name tag: __name__",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileTagMappings(
+				[]config.CarbonIngesterTagMappingConfiguration{tt.mapping}, testTagOpts)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestTagMappingApply(t *testing.T) {
+	mappings, err := compileTagMappings([]config.CarbonIngesterTagMappingConfiguration{
+		{
+			Prefix: "servers.*.*.cpu",
+			Tags:   map[int]string{1: "dc", 2: "host"},
+		},
+		{
+			Prefix: "servers.*.*",
+			Tags:   map[int]string{1: "dc", 2: "host", 5: "extra"},
+			Name:   "server_metric",
+		},
+	}, testTagOpts)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		metric   string
+		expected []models.Tag
+	}{
+		{
+			name:   "generated name",
+			metric: "servers.dc1.host1.cpu.user",
+			expected: []models.Tag{
+				{Name: []byte("dc"), Value: []byte("dc1")},
+				{Name: []byte("host"), Value: []byte("host1")},
+				{Name: graphite.TagName(0), Value: []byte("servers")},
+				{Name: graphite.TagName(1), Value: []byte("dc1")},
+				{Name: graphite.TagName(2), Value: []byte("host1")},
+				{Name: graphite.TagName(3), Value: []byte("cpu")},
+				{Name: graphite.TagName(4), Value: []byte("user")},
+				{Name: []byte("__name__"), Value: []byte("servers_cpu_user")},
+			},
+		},
+		{
+			name:   "explicit name and missing position",
+			metric: "servers.dc1.host1.mem",
+			expected: []models.Tag{
+				{Name: []byte("dc"), Value: []byte("dc1")},
+				{Name: []byte("host"), Value: []byte("host1")},
+				{Name: graphite.TagName(0), Value: []byte("servers")},
+				{Name: graphite.TagName(1), Value: []byte("dc1")},
+				{Name: graphite.TagName(2), Value: []byte("host1")},
+				{Name: graphite.TagName(3), Value: []byte("mem")},
+				{Name: []byte("__name__"), Value: []byte("server_metric")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := mustGenerateTagsFromName(t, []byte(tt.metric))
+			mapping, ok := matchTagMapping(mappings, tags.Tags)
+			require.True(t, ok)
+
+			tags, _ = mapping.apply(tags, nil)
+			require.Equal(t, tt.expected, tags.Tags)
+			require.NoError(t, tags.Validate())
+			require.Equal(t, []byte(tt.metric), tags.ID())
+		})
+	}
+
+	_, ok := matchTagMapping(mappings, mustGenerateTagsFromName(t, []byte("servers.dc1")).Tags)
+	require.False(t, ok)
+	_, ok = matchTagMapping(mappings, mustGenerateTagsFromName(t, []byte("other.dc1.host1")).Tags)
+	require.False(t, ok)
+}
@@ -0,0 +1,722 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestkafka
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	errNoBrokers      = errors.New("kafka: no brokers reachable")
+	errClientClosed   = errors.New("kafka: client is closed")
+	errCorrelationID  = errors.New("kafka: response correlation ID mismatch")
+	errUnknownLeader  = errors.New("kafka: partition has no leader")
+	errUnknownBroker  = errors.New("kafka: partition leader is not a known broker")
+	errMaxResponseLen = errors.New("kafka: response exceeds maximum size")
+)
+
+const (
+	metadataAPIVersion        = 1
+	findCoordinatorAPIVersion = 0
+	listOffsetsAPIVersion     = 1
+	fetchAPIVersion           = 4
+	offsetFetchAPIVersion     = 1
+	offsetCommitAPIVersion    = 2
+	joinGroupAPIVersion       = 2
+	syncGroupAPIVersion       = 1
+	heartbeatAPIVersion       = 1
+	leaveGroupAPIVersion      = 1
+
+	latestOffsetTimestamp   = -1
+	earliestOffsetTimestamp = -2
+
+	maxResponseSize = 256 << 20
+
+	defaultClientID         = "m3coordinator"
+	defaultDialTimeout      = 10 * time.Second
+	defaultRequestTimeout   = 30 * time.Second
+	defaultFetchMaxWait     = 500 * time.Millisecond
+	defaultFetchMaxBytes    = 8 << 20
+	defaultSessionTimeout   = 30 * time.Second
+	defaultRebalanceTimeout = time.Minute
+)
+
+// FetchResult is the result of fetching records from a partition.
+type FetchResult struct {
+	// Records are the records fetched, in offset order.
+	Records []Record
+	// HighWatermark is the offset of the next record to be appended to the
+	// partition.
+	HighWatermark int64
+}
+
+// Client fetches records from the partitions of topics as a member of a
+// consumer group, which balances the partitions across its members and
+// stores the offsets they consume.
+type Client interface {
+	// Partitions returns the partitions of a topic.
+	Partitions(topic string) ([]int32, error)
+
+	// JoinGroup joins or rejoins the consumer group as a member subscribed
+	// to the topics and returns the partitions of each topic assigned to the
+	// member for the new generation of the group.
+	JoinGroup(ctx context.Context, topics []string) (map[string][]int32, error)
+
+	// Heartbeat keeps the membership of the consumer group alive, it returns
+	// an error for which isRebalanceError is true when the group is
+	// rebalancing and the member must stop consuming and rejoin.
+	Heartbeat() error
+
+	// LeaveGroup leaves the consumer group so that its partitions are
+	// assigned to the remaining members without waiting for the session to
+	// time out.
+	LeaveGroup() error
+
+	// CommittedOffset returns the offset committed by the consumer group for
+	// a partition, -1 if the group has not committed one.
+	CommittedOffset(topic string, partition int32) (int64, error)
+
+	// InitialOffset returns the earliest or latest offset of a partition.
+	InitialOffset(topic string, partition int32, initial InitialOffset) (int64, error)
+
+	// Fetch fetches records from a partition starting at an offset.
+	Fetch(ctx context.Context, topic string, partition int32, offset int64) (FetchResult, error)
+
+	// Commit commits the offset of the next record the consumer group will
+	// consume from a partition, as the member of the current generation.
+	Commit(topic string, partition int32, offset int64) error
+
+	// Close closes the client.
+	Close() error
+}
+
+// ClientOptions are the options for connecting to Kafka brokers, zero values
+// are replaced by defaults.
+type ClientOptions struct {
+	// Brokers are the addresses of the brokers used to discover the cluster.
+	Brokers []string
+	// ClientID identifies the client to the brokers.
+	ClientID string
+	// Group is the consumer group joined to be assigned partitions and that
+	// offsets are committed to.
+	Group string
+	// DialTimeout is the timeout for connecting to a broker.
+	DialTimeout time.Duration
+	// RequestTimeout is the timeout for a request, not including the time a
+	// fetch waits for records.
+	RequestTimeout time.Duration
+	// FetchMaxWait is how long a fetch waits for records to be available.
+	FetchMaxWait time.Duration
+	// FetchMaxBytes is the maximum bytes returned by a fetch.
+	FetchMaxBytes int32
+	// SessionTimeout is how long the consumer group waits for a heartbeat
+	// before removing the member and rebalancing its partitions.
+	SessionTimeout time.Duration
+	// RebalanceTimeout is how long the consumer group waits for members to
+	// rejoin when it rebalances.
+	RebalanceTimeout time.Duration
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type brokerConn struct {
+	sync.Mutex
+
+	conn          net.Conn
+	r             *bufio.Reader
+	correlationID int32
+}
+
+type brokerClient struct {
+	sync.Mutex
+
+	opts        ClientOptions
+	closed      bool
+	conns       map[string]*brokerConn
+	brokers     map[int32]string
+	leaders     map[topicPartition]int32
+	partitions  map[string][]int32
+	coordinator string
+	member      groupMembership
+}
+
+// NewClient returns a new client of the Kafka brokers.
+func NewClient(opts ClientOptions) (Client, error) {
+	if len(opts.Brokers) == 0 {
+		return nil, errNoBrokers
+	}
+	if opts.ClientID == "" {
+		opts.ClientID = defaultClientID
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = defaultDialTimeout
+	}
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = defaultRequestTimeout
+	}
+	if opts.FetchMaxWait <= 0 {
+		opts.FetchMaxWait = defaultFetchMaxWait
+	}
+	if opts.FetchMaxBytes <= 0 {
+		opts.FetchMaxBytes = defaultFetchMaxBytes
+	}
+	if opts.SessionTimeout <= 0 {
+		opts.SessionTimeout = defaultSessionTimeout
+	}
+	if opts.RebalanceTimeout <= 0 {
+		opts.RebalanceTimeout = defaultRebalanceTimeout
+	}
+	return &brokerClient{
+		opts:       opts,
+		conns:      make(map[string]*brokerConn),
+		brokers:    make(map[int32]string),
+		leaders:    make(map[topicPartition]int32),
+		partitions: make(map[string][]int32),
+		member:     groupMembership{generationID: -1},
+	}, nil
+}
+
+func (c *brokerClient) Partitions(topic string) ([]int32, error) {
+	if err := c.refreshMetadata(topic); err != nil {
+		return nil, err
+	}
+	c.Lock()
+	defer c.Unlock()
+	return append([]int32(nil), c.partitions[topic]...), nil
+}
+
+func (c *brokerClient) CommittedOffset(topic string, partition int32) (int64, error) {
+	var offset int64
+	err := c.withCoordinator(func(addr string) error {
+		e := &encoder{}
+		encodeOffsetFetchRequest(e, c.opts.Group, topic, partition)
+		d, err := c.roundTrip(addr, addr, apiKeyOffsetFetch, offsetFetchAPIVersion, e, 0)
+		if err != nil {
+			return err
+		}
+		offset, err = decodeOffsetFetchResponse(d, partition)
+		return err
+	})
+	return offset, err
+}
+
+func (c *brokerClient) Commit(topic string, partition int32, offset int64) error {
+	member := c.membership()
+	return c.withCoordinator(func(addr string) error {
+		e := &encoder{}
+		encodeOffsetCommitRequest(e, c.opts.Group, member, topic, partition, offset)
+		d, err := c.roundTrip(addr, addr, apiKeyOffsetCommit, offsetCommitAPIVersion, e, 0)
+		if err != nil {
+			return err
+		}
+		return c.groupError(member, decodeOffsetCommitResponse(d, partition))
+	})
+}
+
+func (c *brokerClient) JoinGroup(
+	ctx context.Context,
+	topics []string,
+) (map[string][]int32, error) {
+	var assignment map[string][]int32
+	err := c.withCoordinator(func(addr string) error {
+		// Joining blocks until every member rejoined or the rebalance timed
+		// out, use a separate connection closed if the context is canceled.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		connKey := addr + "/group"
+		stop := c.discardOnDone(ctx, connKey)
+		defer stop()
+
+		e := &encoder{}
+		encodeJoinGroupRequest(e, c.opts.Group,
+			int32(c.opts.SessionTimeout/time.Millisecond),
+			int32(c.opts.RebalanceTimeout/time.Millisecond),
+			c.membership().memberID, encodeSubscription(topics))
+		d, err := c.roundTrip(addr, connKey, apiKeyJoinGroup, joinGroupAPIVersion, e, c.opts.RebalanceTimeout)
+		if err != nil {
+			return err
+		}
+		join, err := decodeJoinGroupResponse(d)
+		if err != nil {
+			return c.groupError(c.membership(), err)
+		}
+		c.Lock()
+		c.member = join.groupMembership
+		c.Unlock()
+
+		var assignments []groupMember
+		if join.leaderID == join.memberID {
+			if assignments, err = c.assign(join.members); err != nil {
+				return err
+			}
+		}
+		e = &encoder{}
+		encodeSyncGroupRequest(e, c.opts.Group, join.groupMembership, assignments)
+		d, err = c.roundTrip(addr, connKey, apiKeySyncGroup, syncGroupAPIVersion, e, c.opts.RebalanceTimeout)
+		if err != nil {
+			return err
+		}
+		b, err := decodeSyncGroupResponse(d)
+		if err != nil {
+			return c.groupError(join.groupMembership, err)
+		}
+		assignment, err = decodeAssignment(b)
+		return err
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return assignment, err
+}
+
+// assign assigns the partitions of the topics the members of the group are
+// subscribed to, as the leader of the group.
+func (c *brokerClient) assign(members []groupMember) ([]groupMember, error) {
+	var (
+		subscriptions = make(map[string][]string, len(members))
+		partitions    = make(map[string][]int32)
+	)
+	for _, member := range members {
+		topics, err := decodeSubscription(member.metadata)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subscription of member %s: %w", member.memberID, err)
+		}
+		subscriptions[member.memberID] = topics
+		for _, topic := range topics {
+			if _, ok := partitions[topic]; ok {
+				continue
+			}
+			topicPartitions, err := c.Partitions(topic)
+			if err != nil {
+				return nil, err
+			}
+			partitions[topic] = topicPartitions
+		}
+	}
+
+	assignments := make([]groupMember, 0, len(members))
+	for memberID, assignment := range rangeAssign(subscriptions, partitions) {
+		assignments = append(assignments, groupMember{
+			memberID: memberID,
+			metadata: encodeAssignment(assignment),
+		})
+	}
+	return assignments, nil
+}
+
+func (c *brokerClient) Heartbeat() error {
+	member := c.membership()
+	return c.withCoordinator(func(addr string) error {
+		e := &encoder{}
+		encodeHeartbeatRequest(e, c.opts.Group, member)
+		d, err := c.roundTrip(addr, addr, apiKeyHeartbeat, heartbeatAPIVersion, e, 0)
+		if err != nil {
+			return err
+		}
+		return c.groupError(member, decodeGroupResponse(d))
+	})
+}
+
+func (c *brokerClient) LeaveGroup() error {
+	member := c.membership()
+	if member.memberID == "" {
+		return nil
+	}
+	c.Lock()
+	c.member = groupMembership{generationID: -1}
+	c.Unlock()
+	return c.withCoordinator(func(addr string) error {
+		e := &encoder{}
+		encodeLeaveGroupRequest(e, c.opts.Group, member)
+		d, err := c.roundTrip(addr, addr, apiKeyLeaveGroup, leaveGroupAPIVersion, e, 0)
+		if err != nil {
+			return err
+		}
+		return decodeGroupResponse(d)
+	})
+}
+
+func (c *brokerClient) membership() groupMembership {
+	c.Lock()
+	defer c.Unlock()
+	return c.member
+}
+
+// groupError forgets the member ID if the group no longer knows the member,
+// so that the next join is as a new member, and returns the error.
+func (c *brokerClient) groupError(member groupMembership, err error) error {
+	var kafkaErr KafkaError
+	if errors.As(err, &kafkaErr) && int16(kafkaErr) == errCodeUnknownMemberID {
+		c.Lock()
+		if c.member == member {
+			c.member = groupMembership{generationID: -1}
+		}
+		c.Unlock()
+	}
+	return err
+}
+
+func (c *brokerClient) InitialOffset(
+	topic string,
+	partition int32,
+	initial InitialOffset,
+) (int64, error) {
+	timestamp := int64(latestOffsetTimestamp)
+	if initial == EarliestOffset {
+		timestamp = earliestOffsetTimestamp
+	}
+	var offset int64
+	err := c.withLeader(topic, partition, func(addr string) error {
+		e := &encoder{}
+		encodeListOffsetsRequest(e, topic, partition, timestamp)
+		d, err := c.roundTrip(addr, addr, apiKeyListOffsets, listOffsetsAPIVersion, e, 0)
+		if err != nil {
+			return err
+		}
+		offset, err = decodeListOffsetsResponse(d, partition)
+		return err
+	})
+	return offset, err
+}
+
+func (c *brokerClient) Fetch(
+	ctx context.Context,
+	topic string,
+	partition int32,
+	offset int64,
+) (FetchResult, error) {
+	var result FetchResult
+	err := c.withLeader(topic, partition, func(addr string) error {
+		e := &encoder{}
+		encodeFetchRequest(e, topic, partition, offset,
+			int32(c.opts.FetchMaxWait/time.Millisecond), c.opts.FetchMaxBytes)
+
+		// Fetches block for up to the max wait, use a connection per partition
+		// so that partitions led by the same broker do not wait on each other.
+		connKey := addr + "/" + topic + "/" + strconv.Itoa(int(partition))
+		d, err := c.roundTrip(addr, connKey, apiKeyFetch, fetchAPIVersion, e, c.opts.FetchMaxWait)
+		if err != nil {
+			return err
+		}
+		resp, err := decodeFetchResponse(d, partition)
+		if err != nil {
+			return err
+		}
+		records, err := decodeRecordBatches(resp.records, offset, resp.aborted)
+		if err != nil {
+			return err
+		}
+		result = FetchResult{Records: records, HighWatermark: resp.highWatermark}
+		return nil
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	return result, err
+}
+
+func (c *brokerClient) Close() error {
+	c.Lock()
+	defer c.Unlock()
+	c.closed = true
+	var firstErr error
+	for key, conn := range c.conns {
+		if err := conn.conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.conns, key)
+	}
+	return firstErr
+}
+
+// withLeader calls the function with the address of the leader of a
+// partition, refreshing metadata and retrying once if leadership moved.
+func (c *brokerClient) withLeader(topic string, partition int32, fn func(addr string) error) error {
+	tp := topicPartition{topic: topic, partition: partition}
+	for attempt := 0; ; attempt++ {
+		c.Lock()
+		leader, ok := c.leaders[tp]
+		addr := c.brokers[leader]
+		c.Unlock()
+		if !ok || addr == "" {
+			if attempt > 0 {
+				if !ok {
+					return errUnknownLeader
+				}
+				return errUnknownBroker
+			}
+			if err := c.refreshMetadata(topic); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := fn(addr)
+		var kafkaErr KafkaError
+		if err == nil || attempt > 0 || !errors.As(err, &kafkaErr) || !kafkaErr.retriable() {
+			return err
+		}
+		if err := c.refreshMetadata(topic); err != nil {
+			return err
+		}
+	}
+}
+
+// withCoordinator calls the function with the address of the coordinator of
+// the consumer group, finding it again and retrying once if it moved.
+func (c *brokerClient) withCoordinator(fn func(addr string) error) error {
+	for attempt := 0; ; attempt++ {
+		c.Lock()
+		addr := c.coordinator
+		c.Unlock()
+		if addr == "" {
+			if err := c.findCoordinator(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := fn(addr)
+		var kafkaErr KafkaError
+		if err == nil || attempt > 0 || !errors.As(err, &kafkaErr) || !kafkaErr.retriable() {
+			return err
+		}
+		c.Lock()
+		c.coordinator = ""
+		c.Unlock()
+	}
+}
+
+func (c *brokerClient) findCoordinator() error {
+	return c.withAnyBroker(func(addr string) error {
+		e := &encoder{}
+		encodeFindCoordinatorRequest(e, c.opts.Group)
+		d, err := c.roundTrip(addr, addr, apiKeyFindCoordinator, findCoordinatorAPIVersion, e, 0)
+		if err != nil {
+			return err
+		}
+		b, err := decodeFindCoordinatorResponse(d)
+		if err != nil {
+			return err
+		}
+		c.Lock()
+		c.coordinator = b.addr
+		c.Unlock()
+		return nil
+	})
+}
+
+func (c *brokerClient) refreshMetadata(topic string) error {
+	return c.withAnyBroker(func(addr string) error {
+		e := &encoder{}
+		encodeMetadataRequest(e, []string{topic})
+		d, err := c.roundTrip(addr, addr, apiKeyMetadata, metadataAPIVersion, e, 0)
+		if err != nil {
+			return err
+		}
+		resp, err := decodeMetadataResponse(d)
+		if err != nil {
+			return err
+		}
+
+		c.Lock()
+		defer c.Unlock()
+		for _, b := range resp.brokers {
+			c.brokers[b.nodeID] = b.addr
+		}
+		for _, t := range resp.topics {
+			if err := kafkaError(t.errCode); err != nil {
+				return fmt.Errorf("could not get metadata for topic %s: %w", t.name, err)
+			}
+			partitions := make([]int32, 0, len(t.partitions))
+			for _, p := range t.partitions {
+				partitions = append(partitions, p.partition)
+				tp := topicPartition{topic: t.name, partition: p.partition}
+				if p.leader < 0 {
+					delete(c.leaders, tp)
+					continue
+				}
+				c.leaders[tp] = p.leader
+			}
+			c.partitions[t.name] = partitions
+		}
+		return nil
+	})
+}
+
+// withAnyBroker calls the function with the first of the known and seed
+// brokers it succeeds with.
+func (c *brokerClient) withAnyBroker(fn func(addr string) error) error {
+	c.Lock()
+	addrs := append([]string(nil), c.opts.Brokers...)
+	for _, addr := range c.brokers {
+		addrs = append(addrs, addr)
+	}
+	c.Unlock()
+
+	lastErr := errNoBrokers
+	for _, addr := range addrs {
+		err := fn(addr)
+		if err == nil {
+			return nil
+		}
+		var kafkaErr KafkaError
+		if errors.As(err, &kafkaErr) {
+			// The broker responded, another broker will respond the same.
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// roundTrip sends a request on the connection with the key to the broker at
+// the address and returns a decoder of the response body.
+func (c *brokerClient) roundTrip(
+	addr, connKey string,
+	apiKey, apiVersion int16,
+	body *encoder,
+	wait time.Duration,
+) (*decoder, error) {
+	conn, err := c.conn(addr, connKey)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.Lock()
+	defer conn.Unlock()
+
+	conn.correlationID++
+	req := &encoder{buf: make([]byte, 4, 4+64+len(body.buf))}
+	requestHeader(req, apiKey, apiVersion, conn.correlationID, c.opts.ClientID)
+	req.buf = append(req.buf, body.buf...)
+	binary.BigEndian.PutUint32(req.buf[:4], uint32(len(req.buf)-4))
+
+	resp, err := c.send(conn, req.buf, wait)
+	if err != nil {
+		// The connection is in an unknown state, discard it.
+		c.discard(connKey, conn)
+		return nil, err
+	}
+	d := &decoder{buf: resp}
+	if d.int32() != conn.correlationID {
+		c.discard(connKey, conn)
+		return nil, errCorrelationID
+	}
+	return d, nil
+}
+
+func (c *brokerClient) send(conn *brokerConn, req []byte, wait time.Duration) ([]byte, error) {
+	deadline := time.Now().Add(c.opts.RequestTimeout + wait)
+	if err := conn.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err := conn.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	var sizeBuf [4]byte
+	if _, err := io.ReadFull(conn.r, sizeBuf[:]); err != nil {
+		return nil, err
+	}
+	size := int(int32(binary.BigEndian.Uint32(sizeBuf[:])))
+	if size < 4 || size > maxResponseSize {
+		return nil, errMaxResponseLen
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(conn.r, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *brokerClient) conn(addr, key string) (*brokerConn, error) {
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return nil, errClientClosed
+	}
+	conn, ok := c.conns[key]
+	c.Unlock()
+	if ok {
+		return conn, nil
+	}
+
+	netConn, err := net.DialTimeout("tcp", addr, c.opts.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn = &brokerConn{conn: netConn, r: bufio.NewReader(netConn)}
+
+	c.Lock()
+	defer c.Unlock()
+	if c.closed {
+		netConn.Close() // nolint: errcheck
+		return nil, errClientClosed
+	}
+	if existing, ok := c.conns[key]; ok {
+		netConn.Close() // nolint: errcheck
+		return existing, nil
+	}
+	c.conns[key] = conn
+	return conn, nil
+}
+
+// discardOnDone discards the connection with the key when the context is
+// done, until the returned function is called.
+func (c *brokerClient) discardOnDone(ctx context.Context, key string) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Lock()
+			conn, ok := c.conns[key]
+			c.Unlock()
+			if ok {
+				c.discard(key, conn)
+			}
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (c *brokerClient) discard(key string, conn *brokerConn) {
+	conn.conn.Close() // nolint: errcheck
+	c.Lock()
+	if c.conns[key] == conn {
+		delete(c.conns, key)
+	}
+	c.Unlock()
+}
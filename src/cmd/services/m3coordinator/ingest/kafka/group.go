@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package ingestkafka
+
+import (
+	"sort"
+)
+
+const (
+	// consumerProtocolType is the protocol type of consumer groups whose
+	// members consume partitions assigned by the leader of the group.
+	consumerProtocolType = "consumer"
+	// rangeAssignorName is the name of the range assignor, which Kafka
+	// clients of other languages also implement.
+	rangeAssignorName = "range"
+)
+
+// encodeSubscription encodes the version zero consumer protocol
+// subscription of a member to topics.
+func encodeSubscription(topics []string) []byte {
+	e := &encoder{}
+	e.int16(0) // Version.
+	e.arrayLen(len(topics))
+	for _, topic := range topics {
+		e.string(topic)
+	}
+	e.bytes(nil) // User data.
+	return e.buf
+}
+
+// decodeSubscription decodes the topics of a consumer protocol subscription,
+// fields added by later versions are ignored.
+func decodeSubscription(b []byte) ([]string, error) {
+	d := &decoder{buf: b}
+	d.int16() // Version.
+	topics := make([]string, 0, 1)
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		topics = append(topics, d.string())
+	}
+	return topics, d.err
+}
+
+// encodeAssignment encodes the version zero consumer protocol assignment of
+// partitions to a member.
+func encodeAssignment(assignment map[string][]int32) []byte {
+	topics := make([]string, 0, len(assignment))
+	for topic := range assignment {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	e := &encoder{}
+	e.int16(0) // Version.
+	e.arrayLen(len(topics))
+	for _, topic := range topics {
+		e.string(topic)
+		e.arrayLen(len(assignment[topic]))
+		for _, partition := range assignment[topic] {
+			e.int32(partition)
+		}
+	}
+	e.bytes(nil) // User data.
+	return e.buf
+}
+
+// decodeAssignment decodes the partitions of a consumer protocol assignment,
+// members assigned no partitions may receive an empty assignment.
+func decodeAssignment(b []byte) (map[string][]int32, error) {
+	assignment := make(map[string][]int32)
+	if len(b) == 0 {
+		return assignment, nil
+	}
+	d := &decoder{buf: b}
+	d.int16() // Version.
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		topic := d.string()
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			assignment[topic] = append(assignment[topic], d.int32())
+		}
+	}
+	return assignment, d.err
+}
+
+// rangeAssign assigns the partitions of each topic to the members subscribed
+// to it, keyed by member ID, as the range assignor of the Java client does.
+// Members sorted by ID are each assigned a range of consecutive partitions,
+// the first members are assigned an extra partition when the partitions do
+// not divide evenly.
+func rangeAssign(
+	subscriptions map[string][]string,
+	partitions map[string][]int32,
+) map[string]map[string][]int32 {
+	var (
+		assignments = make(map[string]map[string][]int32, len(subscriptions))
+		subscribers = make(map[string][]string)
+	)
+	for memberID, topics := range subscriptions {
+		assignments[memberID] = make(map[string][]int32)
+		for _, topic := range topics {
+			subscribers[topic] = append(subscribers[topic], memberID)
+		}
+	}
+
+	for topic, members := range subscribers {
+		sort.Strings(members)
+		topicPartitions := append([]int32(nil), partitions[topic]...)
+		sort.Slice(topicPartitions, func(i, j int) bool {
+			return topicPartitions[i] < topicPartitions[j]
+		})
+
+		var (
+			perMember = len(topicPartitions) / len(members)
+			extra     = len(topicPartitions) % len(members)
+			start     = 0
+		)
+		for i, memberID := range members {
+			n := perMember
+			if i < extra {
+				n++
+			}
+			if n > 0 {
+				assignments[memberID][topic] = topicPartitions[start : start+n]
+			}
+			start += n
+		}
+	}
+	return assignments
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package ingestkafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionRoundTrip(t *testing.T) {
+	topics, err := decodeSubscription(encodeSubscription([]string{"metrics", "events"}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"metrics", "events"}, topics)
+}
+
+func TestAssignmentRoundTrip(t *testing.T) {
+	assignment := map[string][]int32{
+		"metrics": {0, 1, 2},
+		"events":  {3},
+	}
+	decoded, err := decodeAssignment(encodeAssignment(assignment))
+	require.NoError(t, err)
+	require.Equal(t, assignment, decoded)
+
+	// Members assigned no partitions may receive an empty assignment.
+	decoded, err = decodeAssignment(nil)
+	require.NoError(t, err)
+	require.Len(t, decoded, 0)
+}
+
+func TestRangeAssign(t *testing.T) {
+	assignments := rangeAssign(map[string][]string{
+		"c": {"metrics"},
+		"a": {"metrics", "events"},
+		"b": {"metrics", "events"},
+	}, map[string][]int32{
+		"metrics": {7, 6, 5, 4, 3, 2, 1, 0},
+		"events":  {0},
+	})
+
+	require.Equal(t, map[string]map[string][]int32{
+		"a": {"metrics": {0, 1, 2}, "events": {0}},
+		"b": {"metrics": {3, 4, 5}},
+		"c": {"metrics": {6, 7}},
+	}, assignments)
+}
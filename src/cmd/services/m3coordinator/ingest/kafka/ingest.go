@@ -0,0 +1,541 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package ingestkafka ingests metrics consumed from Kafka topics through the
+// same downsampling and write path as metrics received over HTTP.
+package ingestkafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/query/api/v1/handler/influxdb"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/retry"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	imodels "github.com/influxdata/influxdb/models"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+// Format is the format of the values of the records of a topic.
+type Format string
+
+const (
+	// PrometheusRemoteWriteFormat is a snappy compressed Prometheus remote
+	// write request per record, as sent to the remote write endpoint.
+	PrometheusRemoteWriteFormat Format = "prometheus_remote_write"
+	// InfluxLineProtocolFormat is one or more newline separated InfluxDB
+	// line protocol points per record with nanosecond timestamps.
+	InfluxLineProtocolFormat Format = "influx_line_protocol"
+)
+
+// InitialOffset is where a partition is consumed from when the consumer group
+// has not committed an offset for it.
+type InitialOffset string
+
+const (
+	// EarliestOffset consumes from the earliest retained record.
+	EarliestOffset InitialOffset = "earliest"
+	// LatestOffset consumes only records appended after the consumer starts.
+	LatestOffset InitialOffset = "latest"
+)
+
+const (
+	defaultCommitInterval    = 5 * time.Second
+	defaultHeartbeatInterval = 3 * time.Second
+	errorBackoff             = time.Second
+)
+
+var (
+	errNoClient               = errors.New("kafka ingester requires a client")
+	errNoDownsamplerAndWriter = errors.New("kafka ingester requires a downsampler and writer")
+	errNoTopics               = errors.New("kafka ingester requires at least one topic")
+)
+
+// TopicOptions are the options for consuming a topic.
+type TopicOptions struct {
+	// Name is the name of the topic.
+	Name string
+	// Format is the format of the values of the records of the topic.
+	Format Format
+}
+
+// Options configures the ingester, the initial offset defaults to the latest
+// offset, the commit interval to five seconds and the heartbeat interval to
+// three seconds.
+type Options struct {
+	Client               Client
+	DownsamplerAndWriter ingest.DownsamplerAndWriter
+	Topics               []TopicOptions
+	InitialOffset        InitialOffset
+	CommitInterval       time.Duration
+	HeartbeatInterval    time.Duration
+	RetryOptions         retry.Options
+	TagOptions           models.TagOptions
+	InstrumentOptions    instrument.Options
+}
+
+func (o Options) validate() error {
+	if o.Client == nil {
+		return errNoClient
+	}
+	if o.DownsamplerAndWriter == nil {
+		return errNoDownsamplerAndWriter
+	}
+	if len(o.Topics) == 0 {
+		return errNoTopics
+	}
+	seen := make(map[string]struct{}, len(o.Topics))
+	for _, topic := range o.Topics {
+		switch topic.Format {
+		case PrometheusRemoteWriteFormat, InfluxLineProtocolFormat:
+		default:
+			return fmt.Errorf("unknown format for topic %s: %q", topic.Name, topic.Format)
+		}
+		if _, ok := seen[topic.Name]; ok {
+			return fmt.Errorf("duplicate topic %s", topic.Name)
+		}
+		seen[topic.Name] = struct{}{}
+	}
+	switch o.InitialOffset {
+	case "", EarliestOffset, LatestOffset:
+	default:
+		return fmt.Errorf("unknown initial offset: %q", o.InitialOffset)
+	}
+	return nil
+}
+
+type ingestMetrics struct {
+	records            tally.Counter
+	writeSuccess       tally.Counter
+	writeError         tally.Counter
+	invalidRecord      tally.Counter
+	fetchError         tally.Counter
+	commitError        tally.Counter
+	offsetOutOfRange   tally.Counter
+	rebalances         tally.Counter
+	groupError         tally.Counter
+	assignedPartitions tally.Gauge
+}
+
+func newIngestMetrics(scope tally.Scope) ingestMetrics {
+	return ingestMetrics{
+		records:            scope.Counter("records"),
+		writeSuccess:       scope.Counter("write-success"),
+		writeError:         scope.Counter("write-error"),
+		invalidRecord:      scope.Counter("invalid-record"),
+		fetchError:         scope.Counter("fetch-error"),
+		commitError:        scope.Counter("commit-error"),
+		offsetOutOfRange:   scope.Counter("offset-out-of-range"),
+		rebalances:         scope.Counter("rebalances"),
+		groupError:         scope.Counter("group-error"),
+		assignedPartitions: scope.Gauge("assigned-partitions"),
+	}
+}
+
+// Ingester joins a consumer group to consume the partitions of Kafka topics
+// assigned to it and writes the metrics in the records, committing the
+// offsets of the records written to the consumer group. Each partition is
+// consumed sequentially so committed offsets never skip records that have
+// not been written, when the group rebalances the offsets consumed are
+// committed before rejoining so the next member assigned a partition
+// continues from them.
+type Ingester struct {
+	opts    Options
+	retrier retry.Retrier
+	tagOpts models.TagOptions
+	scope   tally.Scope
+	metrics ingestMetrics
+	logger  *zap.Logger
+	nowFn   func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewIngester returns a new Kafka ingester.
+func NewIngester(opts Options) (*Ingester, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	iOpts := opts.InstrumentOptions
+	if iOpts == nil {
+		iOpts = instrument.NewOptions()
+	}
+	retryOpts := opts.RetryOptions
+	if retryOpts == nil {
+		retryOpts = retry.NewOptions()
+	}
+	tagOpts := opts.TagOptions
+	if tagOpts == nil {
+		tagOpts = models.NewTagOptions()
+	}
+	if opts.InitialOffset == "" {
+		opts.InitialOffset = LatestOffset
+	}
+	if opts.CommitInterval <= 0 {
+		opts.CommitInterval = defaultCommitInterval
+	}
+	if opts.HeartbeatInterval <= 0 {
+		opts.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	scope := iOpts.MetricsScope()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Ingester{
+		opts:    opts,
+		retrier: retry.NewRetrier(retryOpts.SetMetricsScope(scope.SubScope("write-retry"))),
+		tagOpts: tagOpts,
+		scope:   scope,
+		metrics: newIngestMetrics(scope),
+		logger:  iOpts.Logger(),
+		nowFn:   time.Now,
+		ctx:     ctx,
+		cancel:  cancel,
+	}, nil
+}
+
+// Start joins the consumer group and starts consuming the partitions of the
+// topics assigned to the ingester, failing if any of the topics do not exist.
+func (i *Ingester) Start() error {
+	for _, topic := range i.opts.Topics {
+		if _, err := i.opts.Client.Partitions(topic.Name); err != nil {
+			i.Close() // nolint: errcheck
+			return fmt.Errorf("could not get partitions of topic %s: %w", topic.Name, err)
+		}
+	}
+
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		i.consume()
+	}()
+	return nil
+}
+
+// Close stops consuming, commits the offsets consumed, leaves the consumer
+// group and closes the client.
+func (i *Ingester) Close() error {
+	i.cancel()
+	i.wg.Wait()
+	return i.opts.Client.Close()
+}
+
+// consume joins the consumer group and consumes the partitions assigned to
+// the ingester until it is closed, rejoining whenever the group rebalances.
+func (i *Ingester) consume() {
+	topics := make([]string, 0, len(i.opts.Topics))
+	for _, topic := range i.opts.Topics {
+		topics = append(topics, topic.Name)
+	}
+
+	for i.ctx.Err() == nil {
+		assignment, err := i.opts.Client.JoinGroup(i.ctx, topics)
+		if err != nil {
+			if i.ctx.Err() != nil {
+				break
+			}
+			i.metrics.groupError.Inc(1)
+			i.logger.Error("could not join kafka consumer group", zap.Error(err))
+			if !isRebalanceError(err) {
+				i.backoff(i.ctx)
+			}
+			continue
+		}
+		i.metrics.rebalances.Inc(1)
+		i.consumeAssignment(assignment)
+	}
+
+	if err := i.opts.Client.LeaveGroup(); err != nil {
+		i.logger.Warn("could not leave kafka consumer group", zap.Error(err))
+	}
+}
+
+// consumeAssignment consumes the partitions assigned to the ingester until
+// the group rebalances or the ingester is closed, returning once every
+// partition consumer committed its offset and stopped.
+func (i *Ingester) consumeAssignment(assignment map[string][]int32) {
+	var (
+		ctx, cancel = context.WithCancel(i.ctx)
+		wg          sync.WaitGroup
+		assigned    int
+	)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	for _, topic := range i.opts.Topics {
+		partitions := assignment[topic.Name]
+		for _, partition := range partitions {
+			c := newPartitionConsumer(ctx, i, topic, partition)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.run()
+			}()
+		}
+		assigned += len(partitions)
+		i.logger.Info("consuming kafka topic",
+			zap.String("topic", topic.Name),
+			zap.String("format", string(topic.Format)),
+			zap.Int32s("partitions", partitions))
+	}
+	i.metrics.assignedPartitions.Update(float64(assigned))
+
+	ticker := time.NewTicker(i.opts.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := i.opts.Client.Heartbeat()
+		if err == nil {
+			continue
+		}
+		if isRebalanceError(err) {
+			i.logger.Info("kafka consumer group is rebalancing", zap.Error(err))
+			return
+		}
+		i.metrics.groupError.Inc(1)
+		i.logger.Error("could not send kafka consumer group heartbeat", zap.Error(err))
+	}
+}
+
+func (i *Ingester) backoff(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(errorBackoff):
+	}
+}
+
+type partitionConsumer struct {
+	ctx       context.Context
+	ingester  *Ingester
+	topic     TopicOptions
+	partition int32
+	logger    *zap.Logger
+	lag       tally.Gauge
+	offset    int64
+	committed int64
+}
+
+func newPartitionConsumer(
+	ctx context.Context,
+	ingester *Ingester,
+	topic TopicOptions,
+	partition int32,
+) *partitionConsumer {
+	tags := map[string]string{
+		"topic":     topic.Name,
+		"partition": strconv.Itoa(int(partition)),
+	}
+	return &partitionConsumer{
+		ctx:       ctx,
+		ingester:  ingester,
+		topic:     topic,
+		partition: partition,
+		logger: ingester.logger.With(
+			zap.String("topic", topic.Name),
+			zap.Int32("partition", partition)),
+		lag:       ingester.scope.Tagged(tags).Gauge("lag"),
+		offset:    -1,
+		committed: -1,
+	}
+}
+
+func (c *partitionConsumer) run() {
+	var (
+		i              = c.ingester
+		lastCommitTime = i.nowFn()
+	)
+	defer c.commit()
+
+	for c.ctx.Err() == nil {
+		if c.offset < 0 {
+			if err := c.resolveOffset(); err != nil {
+				i.metrics.fetchError.Inc(1)
+				c.logger.Error("could not resolve offset", zap.Error(err))
+				i.backoff(c.ctx)
+				continue
+			}
+		}
+
+		result, err := i.opts.Client.Fetch(c.ctx, c.topic.Name, c.partition, c.offset)
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return
+			}
+			var kafkaErr KafkaError
+			if errors.As(err, &kafkaErr) && int16(kafkaErr) == errCodeOffsetOutOfRange {
+				// Records were removed by retention before being consumed.
+				i.metrics.offsetOutOfRange.Inc(1)
+				c.logger.Warn("offset out of range, resetting to initial offset",
+					zap.Int64("offset", c.offset))
+				c.offset = -1
+				continue
+			}
+			i.metrics.fetchError.Inc(1)
+			c.logger.Error("could not fetch records", zap.Error(err))
+			i.backoff(c.ctx)
+			continue
+		}
+
+		for _, record := range result.Records {
+			if !c.write(record) {
+				// Stopped consuming the partition, the record is consumed
+				// again by its next consumer.
+				return
+			}
+			c.offset = record.Offset + 1
+		}
+		if lag := result.HighWatermark - c.offset; lag >= 0 {
+			c.lag.Update(float64(lag))
+		}
+
+		if now := i.nowFn(); now.Sub(lastCommitTime) >= i.opts.CommitInterval {
+			c.commit()
+			lastCommitTime = now
+		}
+	}
+}
+
+func (c *partitionConsumer) resolveOffset() error {
+	kafkaClient := c.ingester.opts.Client
+	offset, err := kafkaClient.CommittedOffset(c.topic.Name, c.partition)
+	if err != nil {
+		return err
+	}
+	if offset < 0 {
+		offset, err = kafkaClient.InitialOffset(c.topic.Name, c.partition,
+			c.ingester.opts.InitialOffset)
+		if err != nil {
+			return err
+		}
+	}
+	c.offset = offset
+	c.committed = offset
+	return nil
+}
+
+// write writes the metrics of a record, retrying until it succeeds or fails
+// with an error that is not retryable so that offsets are never committed
+// past records that could still be written. Returns false only if consuming
+// the partition stopped before the record was written.
+func (c *partitionConsumer) write(record Record) bool {
+	i := c.ingester
+	i.metrics.records.Inc(1)
+
+	iter, err := c.decode(record)
+	if err != nil {
+		i.metrics.invalidRecord.Inc(1)
+		c.logger.Error("could not decode record, skipping",
+			zap.Int64("offset", record.Offset), zap.Error(err))
+		return true
+	}
+
+	writeFn := func() error {
+		if err := iter.Reset(); err != nil {
+			return xerrors.NewNonRetryableError(err)
+		}
+		batchErr := i.opts.DownsamplerAndWriter.WriteBatch(c.ctx, iter, ingest.WriteOptions{})
+		if batchErr == nil {
+			return nil
+		}
+		for _, err := range batchErr.Errors() {
+			if !client.IsBadRequestError(err) && !xerrors.IsInvalidParams(err) {
+				return batchErr
+			}
+		}
+		// Retrying writes of invalid metrics will never succeed.
+		return xerrors.NewNonRetryableError(batchErr)
+	}
+	for {
+		err := i.retrier.AttemptContext(c.ctx, writeFn)
+		switch {
+		case err == nil:
+			i.metrics.writeSuccess.Inc(1)
+			return true
+		case c.ctx.Err() != nil:
+			return false
+		case xerrors.IsNonRetryableError(err):
+			i.metrics.invalidRecord.Inc(1)
+			c.logger.Error("could not write record, skipping",
+				zap.Int64("offset", record.Offset), zap.Error(err))
+			return true
+		}
+		i.metrics.writeError.Inc(1)
+		c.logger.Error("could not write record, retrying",
+			zap.Int64("offset", record.Offset), zap.Error(err))
+		i.backoff(c.ctx)
+	}
+}
+
+func (c *partitionConsumer) decode(record Record) (ingest.DownsampleAndWriteIter, error) {
+	switch c.topic.Format {
+	case PrometheusRemoteWriteFormat:
+		body, err := snappy.Decode(nil, record.Value)
+		if err != nil {
+			return nil, err
+		}
+		var req prompb.WriteRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		return remote.NewWriteRequestIter(&req, c.ingester.tagOpts, false)
+	case InfluxLineProtocolFormat:
+		points, err := imodels.ParsePointsWithPrecision(record.Value, record.Timestamp, "")
+		if err != nil {
+			return nil, err
+		}
+		return influxdb.NewPointsIter(points, c.ingester.tagOpts), nil
+	default:
+		return nil, fmt.Errorf("unknown format: %q", c.topic.Format)
+	}
+}
+
+func (c *partitionConsumer) commit() {
+	if c.offset < 0 || c.offset == c.committed {
+		return
+	}
+	err := c.ingester.opts.Client.Commit(c.topic.Name, c.partition, c.offset)
+	if err != nil {
+		c.ingester.metrics.commitError.Inc(1)
+		c.logger.Error("could not commit offset",
+			zap.Int64("offset", c.offset), zap.Error(err))
+		return
+	}
+	c.committed = c.offset
+}
@@ -0,0 +1,341 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestkafka
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/retry"
+)
+
+type fakeClient struct {
+	sync.Mutex
+	committed   map[int32]int64
+	records     map[int32][]Record
+	fetched     map[int32]bool
+	assignments [][]int32
+	joins       int
+	left        bool
+	heartbeats  chan error
+}
+
+// newFakeClient returns a fake client of the metrics topic, each join of the
+// consumer group is assigned the next of the assignments or every partition
+// once they are exhausted.
+func newFakeClient(records map[int32][]Record, assignments ...[]int32) *fakeClient {
+	return &fakeClient{
+		committed:   make(map[int32]int64),
+		records:     records,
+		fetched:     make(map[int32]bool),
+		assignments: assignments,
+		heartbeats:  make(chan error, 1),
+	}
+}
+
+func (c *fakeClient) Partitions(topic string) ([]int32, error) {
+	if topic != "metrics" {
+		return nil, KafkaError(errCodeUnknownTopicOrPartition)
+	}
+	var partitions []int32
+	for p := range c.records {
+		partitions = append(partitions, p)
+	}
+	return partitions, nil
+}
+
+func (c *fakeClient) JoinGroup(context.Context, []string) (map[string][]int32, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.joins++
+	if len(c.assignments) == 0 {
+		partitions, _ := c.Partitions("metrics")
+		return map[string][]int32{"metrics": partitions}, nil
+	}
+	assignment := c.assignments[0]
+	c.assignments = c.assignments[1:]
+	return map[string][]int32{"metrics": assignment}, nil
+}
+
+func (c *fakeClient) Heartbeat() error {
+	select {
+	case err := <-c.heartbeats:
+		return err
+	default:
+		return nil
+	}
+}
+
+func (c *fakeClient) LeaveGroup() error {
+	c.Lock()
+	defer c.Unlock()
+	c.left = true
+	return nil
+}
+
+func (c *fakeClient) CommittedOffset(_ string, partition int32) (int64, error) {
+	c.Lock()
+	defer c.Unlock()
+	if offset, ok := c.committed[partition]; ok {
+		return offset, nil
+	}
+	return -1, nil
+}
+
+func (c *fakeClient) InitialOffset(string, int32, InitialOffset) (int64, error) {
+	return 0, nil
+}
+
+func (c *fakeClient) Fetch(
+	ctx context.Context,
+	_ string,
+	partition int32,
+	_ int64,
+) (FetchResult, error) {
+	c.Lock()
+	fetched := c.fetched[partition]
+	c.fetched[partition] = true
+	records := c.records[partition]
+	c.Unlock()
+	if fetched {
+		<-ctx.Done()
+		return FetchResult{}, ctx.Err()
+	}
+	return FetchResult{
+		Records:       records,
+		HighWatermark: int64(len(records)),
+	}, nil
+}
+
+func (c *fakeClient) Commit(_ string, partition int32, offset int64) error {
+	c.Lock()
+	defer c.Unlock()
+	c.committed[partition] = offset
+	return nil
+}
+
+func (c *fakeClient) Close() error {
+	return nil
+}
+
+type testBatchError struct {
+	errs []error
+}
+
+func (e testBatchError) Error() string   { return e.errs[0].Error() }
+func (e testBatchError) Errors() []error { return e.errs }
+func (e testBatchError) LastError() error {
+	return e.errs[len(e.errs)-1]
+}
+
+func influxRecords(lines ...string) []Record {
+	records := make([]Record, 0, len(lines))
+	for i, line := range lines {
+		records = append(records, Record{
+			Offset:    int64(i),
+			Timestamp: time.Now(),
+			Value:     []byte(line),
+		})
+	}
+	return records
+}
+
+func newTestIngester(
+	t *testing.T,
+	kafkaClient Client,
+	writer ingest.DownsamplerAndWriter,
+	topics ...string,
+) *Ingester {
+	if len(topics) == 0 {
+		topics = []string{"metrics"}
+	}
+	topicOpts := make([]TopicOptions, 0, len(topics))
+	for _, topic := range topics {
+		topicOpts = append(topicOpts, TopicOptions{Name: topic, Format: InfluxLineProtocolFormat})
+	}
+	ingester, err := NewIngester(Options{
+		Client:               kafkaClient,
+		DownsamplerAndWriter: writer,
+		Topics:               topicOpts,
+		CommitInterval:       time.Hour,
+		HeartbeatInterval:    time.Millisecond,
+		RetryOptions: retry.NewOptions().
+			SetInitialBackoff(time.Millisecond).
+			SetMaxRetries(1),
+	})
+	require.NoError(t, err)
+	return ingester
+}
+
+func TestIngesterWritesRecordsAndCommitsOffsets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kafkaClient := newFakeClient(map[int32][]Record{
+		0: influxRecords("cpu,host=a value=1 1600000000000000000",
+			"cpu,host=b value=2 1600000000000000000"),
+		1: influxRecords("mem,host=a value=3 1600000000000000000"),
+	})
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		names []string
+	)
+	wg.Add(3)
+	writer := ingest.NewMockDownsamplerAndWriter(ctrl)
+	writer.EXPECT().WriteBatch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, iter ingest.DownsampleAndWriteIter, _ ingest.WriteOptions) ingest.BatchError {
+			defer wg.Done()
+			for iter.Next() {
+				name, ok := iter.Current().Tags.Name()
+				require.True(t, ok)
+				mu.Lock()
+				names = append(names, string(name))
+				mu.Unlock()
+			}
+			return nil
+		}).Times(3)
+
+	ingester := newTestIngester(t, kafkaClient, writer)
+	require.NoError(t, ingester.Start())
+	wg.Wait()
+	require.NoError(t, ingester.Close())
+
+	sort.Strings(names)
+	require.Equal(t, []string{"cpu_value", "cpu_value", "mem_value"}, names)
+	require.Equal(t, map[int32]int64{0: 2, 1: 1}, kafkaClient.committed)
+	require.True(t, kafkaClient.left)
+}
+
+func TestIngesterRejoinsWhenGroupRebalances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Partition 0 is assigned to the first generation and partition 1 to
+	// the generation after the group rebalanced.
+	kafkaClient := newFakeClient(map[int32][]Record{
+		0: influxRecords("cpu,host=a value=1 1600000000000000000",
+			"cpu,host=b value=2 1600000000000000000"),
+		1: influxRecords("mem,host=a value=3 1600000000000000000"),
+	}, []int32{0}, []int32{1})
+
+	var first, second sync.WaitGroup
+	first.Add(2)
+	second.Add(1)
+	writer := ingest.NewMockDownsamplerAndWriter(ctrl)
+	gomock.InOrder(
+		writer.EXPECT().WriteBatch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, ingest.DownsampleAndWriteIter, ingest.WriteOptions) ingest.BatchError {
+				first.Done()
+				return nil
+			}).Times(2),
+		writer.EXPECT().WriteBatch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, ingest.DownsampleAndWriteIter, ingest.WriteOptions) ingest.BatchError {
+				second.Done()
+				return nil
+			}),
+	)
+
+	ingester := newTestIngester(t, kafkaClient, writer)
+	require.NoError(t, ingester.Start())
+	first.Wait()
+
+	kafkaClient.heartbeats <- KafkaError(errCodeRebalanceInProgress)
+	second.Wait()
+	require.NoError(t, ingester.Close())
+
+	// The offsets of the first generation were committed before rejoining.
+	require.Equal(t, map[int32]int64{0: 2, 1: 1}, kafkaClient.committed)
+	require.Equal(t, 2, kafkaClient.joins)
+	require.True(t, kafkaClient.left)
+}
+
+func TestIngesterSkipsInvalidRecords(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kafkaClient := newFakeClient(map[int32][]Record{
+		0: influxRecords("not a valid line",
+			"cpu,host=a value=1 1600000000000000000",
+			"cpu,host=b value=2 1600000000000000000"),
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	writer := ingest.NewMockDownsamplerAndWriter(ctrl)
+	gomock.InOrder(
+		writer.EXPECT().WriteBatch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, ingest.DownsampleAndWriteIter, ingest.WriteOptions) ingest.BatchError {
+				defer wg.Done()
+				return testBatchError{errs: []error{
+					xerrors.NewInvalidParamsError(errors.New("invalid")),
+				}}
+			}),
+		writer.EXPECT().WriteBatch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, ingest.DownsampleAndWriteIter, ingest.WriteOptions) ingest.BatchError {
+				defer wg.Done()
+				return nil
+			}),
+	)
+
+	ingester := newTestIngester(t, kafkaClient, writer)
+	require.NoError(t, ingester.Start())
+	wg.Wait()
+	require.NoError(t, ingester.Close())
+
+	require.Equal(t, map[int32]int64{0: 3}, kafkaClient.committed)
+}
+
+func TestNewIngesterRequiresUniqueTopics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	writer := ingest.NewMockDownsamplerAndWriter(ctrl)
+	_, err := NewIngester(Options{
+		Client:               newFakeClient(nil),
+		DownsamplerAndWriter: writer,
+		Topics: []TopicOptions{
+			{Name: "metrics", Format: InfluxLineProtocolFormat},
+			{Name: "metrics", Format: PrometheusRemoteWriteFormat},
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestIngesterStartFailsForUnknownTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kafkaClient := newFakeClient(map[int32][]Record{0: nil})
+	writer := ingest.NewMockDownsamplerAndWriter(ctrl)
+	ingester := newTestIngester(t, kafkaClient, writer, "metrics", "unknown")
+	require.Error(t, ingester.Start())
+}
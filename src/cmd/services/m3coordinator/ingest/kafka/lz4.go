@@ -0,0 +1,223 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package ingestkafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	lz4FrameMagic = 0x184D2204
+
+	lz4FlagVersionMask     = 0xC0
+	lz4FlagVersion         = 0x40
+	lz4FlagBlockChecksum   = 0x10
+	lz4FlagContentSize     = 0x08
+	lz4FlagContentChecksum = 0x04
+	lz4FlagDictID          = 0x01
+
+	lz4BlockUncompressed = 0x80000000
+	lz4MinMatch          = 4
+)
+
+var errInvalidLZ4 = errors.New("kafka: invalid lz4 frame")
+
+// decodeLZ4 decodes records compressed in the LZ4 frame format. The header,
+// block and content checksums are not verified since the record batch CRC
+// already covers the compressed records.
+func decodeLZ4(src []byte) ([]byte, error) {
+	var dst []byte
+	for len(src) > 0 {
+		var err error
+		dst, src, err = decodeLZ4Frame(dst, src)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+// decodeLZ4Frame appends the decoded contents of the frame at the start of
+// src to dst, returning the bytes after the frame.
+func decodeLZ4Frame(dst, src []byte) ([]byte, []byte, error) {
+	if len(src) < 7 || binary.LittleEndian.Uint32(src) != lz4FrameMagic {
+		return nil, nil, errInvalidLZ4
+	}
+	flags, blockDescriptor := src[4], src[5]
+	if flags&lz4FlagVersionMask != lz4FlagVersion {
+		return nil, nil, fmt.Errorf("kafka: unsupported lz4 frame version %d", flags>>6)
+	}
+	if flags&lz4FlagDictID != 0 {
+		return nil, nil, errors.New("kafka: lz4 frames with dictionaries are not supported")
+	}
+	maxBlockSize, err := lz4MaxBlockSize(blockDescriptor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Magic, flags and block descriptor followed by the header checksum.
+	headerSize := 7
+	if flags&lz4FlagContentSize != 0 {
+		headerSize += 8
+	}
+	if len(src) < headerSize {
+		return nil, nil, errInvalidLZ4
+	}
+	src = src[headerSize:]
+
+	for {
+		if len(src) < 4 {
+			return nil, nil, errInvalidLZ4
+		}
+		blockSize := binary.LittleEndian.Uint32(src)
+		src = src[4:]
+		if blockSize == 0 {
+			// End mark.
+			break
+		}
+		uncompressed := blockSize&lz4BlockUncompressed != 0
+		blockSize &^= lz4BlockUncompressed
+		if int(blockSize) > len(src) || int(blockSize) > maxBlockSize {
+			return nil, nil, errInvalidLZ4
+		}
+		block := src[:blockSize]
+		src = src[blockSize:]
+		if flags&lz4FlagBlockChecksum != 0 {
+			if len(src) < 4 {
+				return nil, nil, errInvalidLZ4
+			}
+			src = src[4:]
+		}
+
+		if uncompressed {
+			dst = append(dst, block...)
+			continue
+		}
+		// Blocks may reference the previous blocks of the frame, which are
+		// already decoded in dst.
+		start := len(dst)
+		if dst, err = decodeLZ4Block(dst, block); err != nil {
+			return nil, nil, err
+		}
+		if len(dst)-start > maxBlockSize {
+			return nil, nil, errInvalidLZ4
+		}
+	}
+
+	if flags&lz4FlagContentChecksum != 0 {
+		if len(src) < 4 {
+			return nil, nil, errInvalidLZ4
+		}
+		src = src[4:]
+	}
+	return dst, src, nil
+}
+
+func lz4MaxBlockSize(blockDescriptor byte) (int, error) {
+	switch (blockDescriptor >> 4) & 0x07 {
+	case 4:
+		return 64 << 10, nil
+	case 5:
+		return 256 << 10, nil
+	case 6:
+		return 1 << 20, nil
+	case 7:
+		return 4 << 20, nil
+	default:
+		return 0, errInvalidLZ4
+	}
+}
+
+// decodeLZ4Block appends the decoded contents of an LZ4 block to dst. Each
+// sequence of the block is a token holding the literal and match lengths,
+// the literals and the offset of the match back from the end of dst, the
+// last sequence holds only literals.
+func decodeLZ4Block(dst, src []byte) ([]byte, error) {
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		literals := int(token >> 4)
+		if literals == 0x0F {
+			var err error
+			if literals, i, err = lz4Length(src, i, literals); err != nil {
+				return nil, err
+			}
+		}
+		if literals > len(src)-i {
+			return nil, errInvalidLZ4
+		}
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+		if i == len(src) {
+			break
+		}
+
+		if len(src)-i < 2 {
+			return nil, errInvalidLZ4
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, errInvalidLZ4
+		}
+
+		match := int(token & 0x0F)
+		if match == 0x0F {
+			var err error
+			if match, i, err = lz4Length(src, i, match); err != nil {
+				return nil, err
+			}
+		}
+		match += lz4MinMatch
+
+		// Matches may overlap the bytes they produce, copy in chunks of at
+		// most the offset.
+		pos := len(dst) - offset
+		for match > 0 {
+			n := match
+			if n > offset {
+				n = offset
+			}
+			dst = append(dst, dst[pos:pos+n]...)
+			pos += n
+			match -= n
+		}
+	}
+	return dst, nil
+}
+
+// lz4Length adds the bytes extending a literal or match length, each byte is
+// added until one is less than 255.
+func lz4Length(src []byte, i, length int) (int, int, error) {
+	for {
+		if i >= len(src) {
+			return 0, 0, errInvalidLZ4
+		}
+		b := src[i]
+		i++
+		length += int(b)
+		if b != 0xFF {
+			return length, i, nil
+		}
+	}
+}
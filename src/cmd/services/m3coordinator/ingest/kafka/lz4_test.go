@@ -0,0 +1,105 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package ingestkafka
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testLZ4Frame is the test line repeated 2000 times compressed by the lz4
+// command line tool into 64KiB dependent blocks with the content size and
+// block and content checksums.
+const testLZ4Frame = "" +
+	"04224d185c40b0300100000000009425010000fc076370752c686f73743d612076616c75653d3120" +
+	"31363001001f0a2700ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+	"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+	"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+	"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+	"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+	"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+	"ffffffffffffffffffffffffffffffffffffffffffffffffffc15076616c75652ffe1bb83a000000" +
+	"0ff0ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+	"ffffffffffffffffffffffc850303030300aebeb98ec000000008655d1a5"
+
+// encodeStoredLZ4Frame encodes an LZ4 frame of a single uncompressed block.
+func encodeStoredLZ4Frame(data []byte) []byte {
+	frame := []byte{0x04, 0x22, 0x4d, 0x18, lz4FlagVersion, 0x40, 0}
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(data))|lz4BlockUncompressed)
+	frame = append(frame, size[:]...)
+	frame = append(frame, data...)
+	return append(frame, 0, 0, 0, 0)
+}
+
+func TestDecodeLZ4(t *testing.T) {
+	frame, err := hex.DecodeString(testLZ4Frame)
+	require.NoError(t, err)
+
+	decoded, err := decodeLZ4(frame)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("cpu,host=a value=1 1600000000000000000\n", 2000), string(decoded))
+}
+
+func TestDecodeLZ4StoredBlocks(t *testing.T) {
+	// Concatenated frames are decoded in order.
+	frames := append(encodeStoredLZ4Frame([]byte("hello ")), encodeStoredLZ4Frame([]byte("world"))...)
+
+	decoded, err := decodeLZ4(frames)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(decoded))
+}
+
+func TestDecodeLZ4BlockOverlappingMatch(t *testing.T) {
+	// The literals "ab" followed by a match of 7 bytes at offset 2 which
+	// overlaps the bytes it produces, then the literal "c".
+	block := []byte{0x23, 'a', 'b', 0x02, 0x00, 0x10, 'c'}
+
+	decoded, err := decodeLZ4Block(nil, block)
+	require.NoError(t, err)
+	require.Equal(t, "ababababac", string(decoded))
+}
+
+func TestDecodeLZ4Invalid(t *testing.T) {
+	frame, err := hex.DecodeString(testLZ4Frame)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name  string
+		frame []byte
+	}{
+		{name: "magic", frame: append([]byte{0}, frame[1:]...)},
+		{name: "truncated", frame: frame[:len(frame)/2]},
+		{name: "missing end mark", frame: encodeStoredLZ4Frame([]byte("foo"))[:14]},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decodeLZ4(test.frame)
+			require.Error(t, err)
+		})
+	}
+
+	// Matches may not reference bytes before the start of the output.
+	_, err = decodeLZ4Block(nil, []byte{0x10, 'a', 0x02, 0x00})
+	require.Error(t, err)
+}
@@ -0,0 +1,657 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestkafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Kafka API keys of the requests made by the consumer.
+const (
+	apiKeyFetch           int16 = 1
+	apiKeyListOffsets     int16 = 2
+	apiKeyMetadata        int16 = 3
+	apiKeyOffsetCommit    int16 = 8
+	apiKeyOffsetFetch     int16 = 9
+	apiKeyFindCoordinator int16 = 10
+	apiKeyJoinGroup       int16 = 11
+	apiKeyHeartbeat       int16 = 12
+	apiKeyLeaveGroup      int16 = 13
+	apiKeySyncGroup       int16 = 14
+)
+
+// Kafka error codes handled by the consumer.
+const (
+	errCodeNone                    int16 = 0
+	errCodeOffsetOutOfRange        int16 = 1
+	errCodeUnknownTopicOrPartition int16 = 3
+	errCodeLeaderNotAvailable      int16 = 5
+	errCodeNotLeaderForPartition   int16 = 6
+	errCodeCoordinatorNotAvailable int16 = 15
+	errCodeCoordinatorLoading      int16 = 14
+	errCodeNotCoordinator          int16 = 16
+	errCodeIllegalGeneration       int16 = 22
+	errCodeUnknownMemberID         int16 = 25
+	errCodeRebalanceInProgress     int16 = 27
+)
+
+var errShortBuffer = errors.New("kafka: short buffer")
+
+// KafkaError is an error code returned by a Kafka broker.
+type KafkaError int16
+
+func (e KafkaError) Error() string {
+	return fmt.Sprintf("kafka: broker returned error code %d", int16(e))
+}
+
+// retriable returns whether the request may succeed once metadata has been
+// refreshed.
+func (e KafkaError) retriable() bool {
+	switch int16(e) {
+	case errCodeLeaderNotAvailable, errCodeNotLeaderForPartition,
+		errCodeCoordinatorNotAvailable, errCodeNotCoordinator,
+		errCodeCoordinatorLoading, errCodeUnknownTopicOrPartition:
+		return true
+	default:
+		return false
+	}
+}
+
+// rebalance returns whether the consumer group is rebalancing, or the member
+// is no longer part of the generation of the group, so it must rejoin.
+func (e KafkaError) rebalance() bool {
+	switch int16(e) {
+	case errCodeIllegalGeneration, errCodeUnknownMemberID, errCodeRebalanceInProgress:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRebalanceError returns whether the error requires the member to rejoin
+// the consumer group.
+func isRebalanceError(err error) bool {
+	var kafkaErr KafkaError
+	return errors.As(err, &kafkaErr) && kafkaErr.rebalance()
+}
+
+func kafkaError(code int16) error {
+	if code == errCodeNone {
+		return nil
+	}
+	return KafkaError(code)
+}
+
+// encoder encodes the primitive types of the Kafka protocol.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8) {
+	e.buf = append(e.buf, byte(v))
+}
+
+func (e *encoder) int16(v int16) {
+	e.buf = append(e.buf, byte(v>>8), byte(v))
+}
+
+func (e *encoder) int32(v int32) {
+	e.buf = append(e.buf, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func (e *encoder) int64(v int64) {
+	e.int32(int32(v >> 32))
+	e.int32(int32(v))
+}
+
+func (e *encoder) string(v string) {
+	e.int16(int16(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) nullableString(v *string) {
+	if v == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*v)
+}
+
+func (e *encoder) bytes(v []byte) {
+	e.int32(int32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) arrayLen(n int) {
+	e.int32(int32(n))
+}
+
+// decoder decodes the primitive types of the Kafka protocol, the first error
+// encountered is sticky and subsequent reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = errShortBuffer
+		return nil
+	}
+	v := d.buf[:n]
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) int8() int8 {
+	b := d.take(1)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+func (d *decoder) int16() int16 {
+	b := d.take(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *decoder) int32() int32 {
+	b := d.take(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (d *decoder) int64() int64 {
+	b := d.take(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.take(int(n))
+}
+
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(d.buf) && d.err == nil {
+		// Every element is at least a byte so the length is corrupt.
+		d.err = errShortBuffer
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errShortBuffer
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varBytes() []byte {
+	n := d.varint()
+	if n < 0 {
+		return nil
+	}
+	return d.take(int(n))
+}
+
+func (d *decoder) skip(n int) {
+	d.take(n)
+}
+
+// requestHeader encodes a version one request header.
+func requestHeader(
+	e *encoder,
+	apiKey, apiVersion int16,
+	correlationID int32,
+	clientID string,
+) {
+	e.int16(apiKey)
+	e.int16(apiVersion)
+	e.int32(correlationID)
+	e.string(clientID)
+}
+
+type broker struct {
+	nodeID int32
+	addr   string
+}
+
+type partitionMetadata struct {
+	partition int32
+	leader    int32
+	errCode   int16
+}
+
+type topicMetadata struct {
+	name       string
+	errCode    int16
+	partitions []partitionMetadata
+}
+
+type metadataResponse struct {
+	brokers []broker
+	topics  []topicMetadata
+}
+
+// encodeMetadataRequest encodes a version one metadata request.
+func encodeMetadataRequest(e *encoder, topics []string) {
+	e.arrayLen(len(topics))
+	for _, topic := range topics {
+		e.string(topic)
+	}
+}
+
+func decodeMetadataResponse(d *decoder) (metadataResponse, error) {
+	var resp metadataResponse
+	numBrokers := d.arrayLen()
+	for i := 0; i < numBrokers; i++ {
+		nodeID := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // Rack.
+		resp.brokers = append(resp.brokers, broker{
+			nodeID: nodeID,
+			addr:   fmt.Sprintf("%s:%d", host, port),
+		})
+	}
+	d.int32() // Controller ID.
+	numTopics := d.arrayLen()
+	for i := 0; i < numTopics; i++ {
+		topic := topicMetadata{errCode: d.int16(), name: d.string()}
+		d.int8() // Is internal.
+		numPartitions := d.arrayLen()
+		for j := 0; j < numPartitions; j++ {
+			p := partitionMetadata{errCode: d.int16(), partition: d.int32(), leader: d.int32()}
+			for k, n := 0, d.arrayLen(); k < n; k++ {
+				d.int32() // Replicas.
+			}
+			for k, n := 0, d.arrayLen(); k < n; k++ {
+				d.int32() // In sync replicas.
+			}
+			topic.partitions = append(topic.partitions, p)
+		}
+		resp.topics = append(resp.topics, topic)
+	}
+	return resp, d.err
+}
+
+// encodeFindCoordinatorRequest encodes a version zero find coordinator
+// request for a consumer group.
+func encodeFindCoordinatorRequest(e *encoder, group string) {
+	e.string(group)
+}
+
+func decodeFindCoordinatorResponse(d *decoder) (broker, error) {
+	errCode := d.int16()
+	nodeID := d.int32()
+	host := d.string()
+	port := d.int32()
+	if d.err != nil {
+		return broker{}, d.err
+	}
+	if err := kafkaError(errCode); err != nil {
+		return broker{}, err
+	}
+	return broker{nodeID: nodeID, addr: fmt.Sprintf("%s:%d", host, port)}, nil
+}
+
+// encodeListOffsetsRequest encodes a version one list offsets request for
+// the offset of a single partition at a timestamp, -1 for the latest offset
+// and -2 for the earliest.
+func encodeListOffsetsRequest(e *encoder, topic string, partition int32, timestamp int64) {
+	e.int32(-1) // Replica ID.
+	e.arrayLen(1)
+	e.string(topic)
+	e.arrayLen(1)
+	e.int32(partition)
+	e.int64(timestamp)
+}
+
+func decodeListOffsetsResponse(d *decoder, partition int32) (int64, error) {
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // Topic.
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			p := d.int32()
+			errCode := d.int16()
+			d.int64() // Timestamp.
+			offset := d.int64()
+			if d.err != nil {
+				return 0, d.err
+			}
+			if p != partition {
+				continue
+			}
+			if err := kafkaError(errCode); err != nil {
+				return 0, err
+			}
+			return offset, nil
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return 0, KafkaError(errCodeUnknownTopicOrPartition)
+}
+
+// encodeFetchRequest encodes a version four fetch request for a single
+// partition with read committed isolation.
+func encodeFetchRequest(
+	e *encoder,
+	topic string,
+	partition int32,
+	offset int64,
+	maxWaitMillis int32,
+	maxBytes int32,
+) {
+	e.int32(-1) // Replica ID.
+	e.int32(maxWaitMillis)
+	e.int32(1) // Min bytes.
+	e.int32(maxBytes)
+	e.int8(1) // Isolation level.
+	e.arrayLen(1)
+	e.string(topic)
+	e.arrayLen(1)
+	e.int32(partition)
+	e.int64(offset)
+	e.int32(maxBytes)
+}
+
+type fetchResponse struct {
+	highWatermark int64
+	aborted       []abortedTransaction
+	records       []byte
+}
+
+func decodeFetchResponse(d *decoder, partition int32) (fetchResponse, error) {
+	d.int32() // Throttle time.
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // Topic.
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			p := d.int32()
+			errCode := d.int16()
+			highWatermark := d.int64()
+			d.int64() // Last stable offset.
+			var aborted []abortedTransaction
+			for k, n := 0, d.arrayLen(); k < n; k++ {
+				aborted = append(aborted, abortedTransaction{
+					producerID:  d.int64(),
+					firstOffset: d.int64(),
+				})
+			}
+			records := d.bytes()
+			if d.err != nil {
+				return fetchResponse{}, d.err
+			}
+			if p != partition {
+				continue
+			}
+			if err := kafkaError(errCode); err != nil {
+				return fetchResponse{}, err
+			}
+			sort.Slice(aborted, func(a, b int) bool {
+				return aborted[a].firstOffset < aborted[b].firstOffset
+			})
+			return fetchResponse{
+				highWatermark: highWatermark,
+				aborted:       aborted,
+				records:       records,
+			}, nil
+		}
+	}
+	if d.err != nil {
+		return fetchResponse{}, d.err
+	}
+	return fetchResponse{}, KafkaError(errCodeUnknownTopicOrPartition)
+}
+
+// encodeOffsetFetchRequest encodes a version one offset fetch request for
+// the committed offset of a single partition.
+func encodeOffsetFetchRequest(e *encoder, group, topic string, partition int32) {
+	e.string(group)
+	e.arrayLen(1)
+	e.string(topic)
+	e.arrayLen(1)
+	e.int32(partition)
+}
+
+// decodeOffsetFetchResponse returns the committed offset, -1 if the group
+// has not committed an offset for the partition.
+func decodeOffsetFetchResponse(d *decoder, partition int32) (int64, error) {
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // Topic.
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			p := d.int32()
+			offset := d.int64()
+			d.string() // Metadata.
+			errCode := d.int16()
+			if d.err != nil {
+				return 0, d.err
+			}
+			if p != partition {
+				continue
+			}
+			if err := kafkaError(errCode); err != nil {
+				return 0, err
+			}
+			return offset, nil
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return -1, nil
+}
+
+// encodeOffsetCommitRequest encodes a version two offset commit request
+// committing the offset of a single partition by a member of a generation of
+// the consumer group.
+func encodeOffsetCommitRequest(
+	e *encoder,
+	group string,
+	member groupMembership,
+	topic string,
+	partition int32,
+	offset int64,
+) {
+	e.string(group)
+	e.int32(member.generationID)
+	e.string(member.memberID)
+	e.int64(-1) // Retention time, use the broker default.
+	e.arrayLen(1)
+	e.string(topic)
+	e.arrayLen(1)
+	e.int32(partition)
+	e.int64(offset)
+	e.nullableString(nil)
+}
+
+func decodeOffsetCommitResponse(d *decoder, partition int32) error {
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // Topic.
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			p := d.int32()
+			errCode := d.int16()
+			if d.err != nil {
+				return d.err
+			}
+			if p == partition {
+				return kafkaError(errCode)
+			}
+		}
+	}
+	return d.err
+}
+
+// groupMembership is the membership of a generation of a consumer group.
+type groupMembership struct {
+	memberID     string
+	generationID int32
+}
+
+type groupMember struct {
+	memberID string
+	metadata []byte
+}
+
+type joinGroupResponse struct {
+	groupMembership
+
+	leaderID string
+	members  []groupMember
+}
+
+// encodeJoinGroupRequest encodes a version two join group request for a
+// consumer using the range assignor, the metadata is the subscription of the
+// consumer.
+func encodeJoinGroupRequest(
+	e *encoder,
+	group string,
+	sessionTimeoutMillis int32,
+	rebalanceTimeoutMillis int32,
+	memberID string,
+	metadata []byte,
+) {
+	e.string(group)
+	e.int32(sessionTimeoutMillis)
+	e.int32(rebalanceTimeoutMillis)
+	e.string(memberID)
+	e.string(consumerProtocolType)
+	e.arrayLen(1)
+	e.string(rangeAssignorName)
+	e.bytes(metadata)
+}
+
+func decodeJoinGroupResponse(d *decoder) (joinGroupResponse, error) {
+	d.int32() // Throttle time.
+	errCode := d.int16()
+	resp := joinGroupResponse{
+		groupMembership: groupMembership{generationID: d.int32()},
+	}
+	d.string() // Protocol.
+	resp.leaderID = d.string()
+	resp.memberID = d.string()
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		resp.members = append(resp.members, groupMember{
+			memberID: d.string(),
+			metadata: d.bytes(),
+		})
+	}
+	if d.err != nil {
+		return joinGroupResponse{}, d.err
+	}
+	if err := kafkaError(errCode); err != nil {
+		return joinGroupResponse{}, err
+	}
+	return resp, nil
+}
+
+// encodeSyncGroupRequest encodes a version one sync group request, the
+// leader of the generation sends the assignments of every member.
+func encodeSyncGroupRequest(
+	e *encoder,
+	group string,
+	member groupMembership,
+	assignments []groupMember,
+) {
+	e.string(group)
+	e.int32(member.generationID)
+	e.string(member.memberID)
+	e.arrayLen(len(assignments))
+	for _, a := range assignments {
+		e.string(a.memberID)
+		e.bytes(a.metadata)
+	}
+}
+
+// decodeSyncGroupResponse returns the assignment of the member.
+func decodeSyncGroupResponse(d *decoder) ([]byte, error) {
+	d.int32() // Throttle time.
+	errCode := d.int16()
+	assignment := d.bytes()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err := kafkaError(errCode); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// encodeHeartbeatRequest encodes a version one heartbeat request.
+func encodeHeartbeatRequest(e *encoder, group string, member groupMembership) {
+	e.string(group)
+	e.int32(member.generationID)
+	e.string(member.memberID)
+}
+
+// encodeLeaveGroupRequest encodes a version one leave group request.
+func encodeLeaveGroupRequest(e *encoder, group string, member groupMembership) {
+	e.string(group)
+	e.string(member.memberID)
+}
+
+// decodeGroupResponse decodes a version one heartbeat or leave group
+// response, which only hold an error code.
+func decodeGroupResponse(d *decoder) error {
+	d.int32() // Throttle time.
+	errCode := d.int16()
+	if d.err != nil {
+		return d.err
+	}
+	return kafkaError(errCode)
+}
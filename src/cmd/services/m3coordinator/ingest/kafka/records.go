@@ -0,0 +1,242 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestkafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	recordBatchMagic = 2
+	// recordBatchHeaderSize is the size of a record batch up to and including
+	// the number of records.
+	recordBatchHeaderSize = 61
+
+	compressionCodecMask = 0x07
+	compressionNone      = 0
+	compressionGzip      = 1
+	compressionSnappy    = 2
+	compressionLZ4       = 3
+	compressionZstd      = 4
+
+	transactionalBatchFlag = 0x10
+	controlBatchFlag       = 0x20
+
+	// controlRecordAbort is the type in the key of the control record that
+	// marks the end of an aborted transaction.
+	controlRecordAbort = 0
+)
+
+var (
+	xerialSnappyMagic = []byte{0x82, 'S', 'N', 'A', 'P', 'P', 'Y', 0}
+
+	errUnsupportedMagic = errors.New("kafka: only record batches of magic 2 are supported")
+)
+
+// Record is a record consumed from a partition of a topic.
+type Record struct {
+	Offset    int64
+	Timestamp time.Time
+	Key       []byte
+	Value     []byte
+}
+
+// abortedTransaction is a transaction in the fetched range of a partition
+// that was aborted by its producer.
+type abortedTransaction struct {
+	producerID  int64
+	firstOffset int64
+}
+
+// decodeRecordBatches decodes the records of the record batches in a fetch
+// response at or after the offset, dropping the records of the aborted
+// transactions, which are sorted by first offset. Brokers may return a
+// partial batch at the end of the response which is ignored, it is fetched
+// again in full by the next fetch.
+func decodeRecordBatches(
+	buf []byte,
+	offset int64,
+	aborted []abortedTransaction,
+) ([]Record, error) {
+	var (
+		records []Record
+		// abortedProducers are the producers with an open aborted
+		// transaction, whose batches are dropped until its abort marker.
+		abortedProducers = make(map[int64]struct{})
+	)
+	for len(buf) >= 12 {
+		baseOffset := int64(binary.BigEndian.Uint64(buf[0:8]))
+		batchLength := int(int32(binary.BigEndian.Uint32(buf[8:12])))
+		if batchLength < recordBatchHeaderSize-12 || len(buf) < 12+batchLength {
+			break
+		}
+		batch := buf[:12+batchLength]
+		buf = buf[12+batchLength:]
+
+		if magic := int8(batch[16]); magic != recordBatchMagic {
+			return nil, errUnsupportedMagic
+		}
+		var (
+			attributes      = int16(binary.BigEndian.Uint16(batch[21:23]))
+			lastOffset      = baseOffset + int64(int32(binary.BigEndian.Uint32(batch[23:27])))
+			baseTimestamp   = int64(binary.BigEndian.Uint64(batch[27:35]))
+			producerID      = int64(binary.BigEndian.Uint64(batch[43:51]))
+			numRecords      = int(int32(binary.BigEndian.Uint32(batch[57:61])))
+			isTransactional = attributes&transactionalBatchFlag != 0
+		)
+		for len(aborted) > 0 && aborted[0].firstOffset <= lastOffset {
+			abortedProducers[aborted[0].producerID] = struct{}{}
+			aborted = aborted[1:]
+		}
+
+		body, err := decompressRecords(int(attributes&compressionCodecMask), batch[61:])
+		if err != nil {
+			return nil, err
+		}
+
+		if attributes&controlBatchFlag != 0 {
+			// Control batches mark transaction boundaries and hold no data,
+			// an abort marker closes the aborted transaction of the producer.
+			if isTransactional && isAbortMarker(body) {
+				delete(abortedProducers, producerID)
+			}
+			continue
+		}
+		if _, ok := abortedProducers[producerID]; ok && isTransactional {
+			continue
+		}
+
+		d := &decoder{buf: body}
+		for i := 0; i < numRecords; i++ {
+			length := d.varint()
+			rec := &decoder{buf: d.take(int(length))}
+			if d.err != nil {
+				return nil, fmt.Errorf("kafka: invalid record: %w", d.err)
+			}
+			rec.int8() // Attributes.
+			timestampDelta := rec.varint()
+			offsetDelta := rec.varint()
+			key := rec.varBytes()
+			value := rec.varBytes()
+			// Headers are not used.
+			if rec.err != nil {
+				return nil, fmt.Errorf("kafka: invalid record: %w", rec.err)
+			}
+
+			recordOffset := baseOffset + offsetDelta
+			if recordOffset < offset {
+				// Fetches return whole batches, skip records before the offset.
+				continue
+			}
+			records = append(records, Record{
+				Offset:    recordOffset,
+				Timestamp: time.Unix(0, (baseTimestamp+timestampDelta)*int64(time.Millisecond)),
+				Key:       key,
+				Value:     value,
+			})
+		}
+	}
+	return records, nil
+}
+
+// isAbortMarker returns whether the records of a control batch are an abort
+// marker, the key of a control record is a version followed by its type.
+func isAbortMarker(body []byte) bool {
+	d := &decoder{buf: body}
+	rec := &decoder{buf: d.take(int(d.varint()))}
+	rec.int8()   // Attributes.
+	rec.varint() // Timestamp delta.
+	rec.varint() // Offset delta.
+	key := &decoder{buf: rec.varBytes()}
+	key.int16() // Version.
+	recordType := key.int16()
+	return d.err == nil && rec.err == nil && key.err == nil &&
+		recordType == controlRecordAbort
+}
+
+func decompressRecords(codec int, body []byte) ([]byte, error) {
+	switch codec {
+	case compressionNone:
+		return body, nil
+	case compressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close() // nolint: errcheck
+		return ioutil.ReadAll(r)
+	case compressionSnappy:
+		return decodeSnappy(body)
+	case compressionZstd:
+		d, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer d.Close()
+		return d.DecodeAll(body, nil)
+	case compressionLZ4:
+		return decodeLZ4(body)
+	default:
+		return nil, fmt.Errorf("kafka: unknown compression codec %d", codec)
+	}
+}
+
+// decodeSnappy decodes snappy compressed records which producers based on the
+// Java client frame in the xerial format.
+func decodeSnappy(body []byte) ([]byte, error) {
+	if !bytes.HasPrefix(body, xerialSnappyMagic) {
+		return snappy.Decode(nil, body)
+	}
+
+	// Magic followed by a version and a compatible version.
+	headerSize := len(xerialSnappyMagic) + 8
+	if len(body) < headerSize {
+		return nil, errShortBuffer
+	}
+	body = body[headerSize:]
+	var result []byte
+	for len(body) > 0 {
+		if len(body) < 4 {
+			return nil, errShortBuffer
+		}
+		n := int(binary.BigEndian.Uint32(body[:4]))
+		body = body[4:]
+		if n < 0 || len(body) < n {
+			return nil, errShortBuffer
+		}
+		chunk, err := snappy.Decode(nil, body[:n])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, chunk...)
+		body = body[n:]
+	}
+	return result, nil
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestkafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+)
+
+type testRecord struct {
+	key   []byte
+	value []byte
+}
+
+func appendVarint(buf []byte, v int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+// encodeRecordBatch encodes a magic 2 record batch, the CRC is not verified
+// by the decoder and left empty.
+func encodeRecordBatch(
+	t *testing.T,
+	baseOffset int64,
+	baseTimestamp time.Time,
+	codec int16,
+	records []testRecord,
+) []byte {
+	return encodeProducerRecordBatch(t, baseOffset, baseTimestamp, codec, -1, records)
+}
+
+// encodeProducerRecordBatch encodes a magic 2 record batch with the
+// attributes of the batch and the ID of the producer that wrote it.
+func encodeProducerRecordBatch(
+	t *testing.T,
+	baseOffset int64,
+	baseTimestamp time.Time,
+	attributes int16,
+	producerID int64,
+	records []testRecord,
+) []byte {
+	codec := attributes & compressionCodecMask
+	var body []byte
+	for i, r := range records {
+		var rec []byte
+		rec = append(rec, 0) // Attributes.
+		rec = appendVarint(rec, int64(i))
+		rec = appendVarint(rec, int64(i))
+		if r.key == nil {
+			rec = appendVarint(rec, -1)
+		} else {
+			rec = appendVarint(rec, int64(len(r.key)))
+			rec = append(rec, r.key...)
+		}
+		rec = appendVarint(rec, int64(len(r.value)))
+		rec = append(rec, r.value...)
+		rec = appendVarint(rec, 0) // Headers.
+		body = appendVarint(body, int64(len(rec)))
+		body = append(body, rec...)
+	}
+
+	switch codec {
+	case compressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(body)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		body = buf.Bytes()
+	case compressionSnappy:
+		body = snappy.Encode(nil, body)
+	case compressionLZ4:
+		body = encodeStoredLZ4Frame(body)
+	}
+
+	ts := baseTimestamp.UnixNano() / int64(time.Millisecond)
+	e := &encoder{}
+	e.int64(baseOffset)
+	e.int32(int32(recordBatchHeaderSize - 12 + len(body)))
+	e.int32(0) // Partition leader epoch.
+	e.int8(recordBatchMagic)
+	e.int32(0) // CRC.
+	e.int16(attributes)
+	e.int32(int32(len(records) - 1))
+	e.int64(ts)
+	e.int64(ts + int64(len(records)-1))
+	e.int64(producerID)
+	e.int16(-1) // Producer epoch.
+	e.int32(-1) // Base sequence.
+	e.int32(int32(len(records)))
+	return append(e.buf, body...)
+}
+
+func TestDecodeRecordBatches(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	records := []testRecord{
+		{key: []byte("a"), value: []byte("foo")},
+		{value: []byte("bar")},
+		{key: []byte("c"), value: []byte("baz")},
+	}
+
+	for _, test := range []struct {
+		name  string
+		codec int16
+	}{
+		{name: "none", codec: compressionNone},
+		{name: "gzip", codec: compressionGzip},
+		{name: "snappy", codec: compressionSnappy},
+		{name: "lz4", codec: compressionLZ4},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf := encodeRecordBatch(t, 10, start, test.codec, records)
+
+			decoded, err := decodeRecordBatches(buf, 0, nil)
+			require.NoError(t, err)
+			require.Len(t, decoded, 3)
+			for i, r := range decoded {
+				require.Equal(t, int64(10+i), r.Offset)
+				require.True(t, start.Add(time.Duration(i)*time.Millisecond).Equal(r.Timestamp))
+				require.Equal(t, records[i].value, r.Value)
+			}
+			require.Equal(t, []byte("a"), decoded[0].Key)
+			require.Nil(t, decoded[1].Key)
+		})
+	}
+}
+
+func TestDecodeRecordBatchesSkipsRecordsBeforeOffset(t *testing.T) {
+	buf := encodeRecordBatch(t, 10, time.Now(), compressionNone, []testRecord{
+		{value: []byte("foo")},
+		{value: []byte("bar")},
+	})
+	buf = append(buf, encodeRecordBatch(t, 12, time.Now(), compressionNone, []testRecord{
+		{value: []byte("baz")},
+	})...)
+
+	decoded, err := decodeRecordBatches(buf, 11, nil)
+	require.NoError(t, err)
+	require.Len(t, decoded, 2)
+	require.Equal(t, int64(11), decoded[0].Offset)
+	require.Equal(t, []byte("bar"), decoded[0].Value)
+	require.Equal(t, int64(12), decoded[1].Offset)
+	require.Equal(t, []byte("baz"), decoded[1].Value)
+}
+
+func TestDecodeRecordBatchesIgnoresPartialBatch(t *testing.T) {
+	full := encodeRecordBatch(t, 0, time.Now(), compressionNone, []testRecord{
+		{value: []byte("foo")},
+	})
+	partial := encodeRecordBatch(t, 1, time.Now(), compressionNone, []testRecord{
+		{value: []byte("bar")},
+	})
+	buf := append(full, partial[:len(partial)-2]...)
+
+	decoded, err := decodeRecordBatches(buf, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	require.Equal(t, []byte("foo"), decoded[0].Value)
+}
+
+func TestDecodeRecordBatchesDropsAbortedTransactions(t *testing.T) {
+	var (
+		now           = time.Now()
+		transactional = int16(transactionalBatchFlag)
+		control       = int16(transactionalBatchFlag | controlBatchFlag)
+		marker        = func(recordType byte) []testRecord {
+			return []testRecord{{key: []byte{0, 0, 0, recordType}, value: []byte{0, 0, 0, 0, 0, 0}}}
+		}
+		buf []byte
+	)
+	// Producer 1 aborts its first transaction and commits its second,
+	// producer 2 commits a transaction interleaved with the aborted one.
+	buf = append(buf, encodeProducerRecordBatch(t, 0, now, transactional, 1,
+		[]testRecord{{value: []byte("aborted")}})...)
+	buf = append(buf, encodeProducerRecordBatch(t, 1, now, transactional, 2,
+		[]testRecord{{value: []byte("committed")}})...)
+	buf = append(buf, encodeProducerRecordBatch(t, 2, now, control, 1, marker(0))...)
+	buf = append(buf, encodeProducerRecordBatch(t, 3, now, control, 2, marker(1))...)
+	buf = append(buf, encodeProducerRecordBatch(t, 4, now, transactional, 1,
+		[]testRecord{{value: []byte("retried")}})...)
+	buf = append(buf, encodeProducerRecordBatch(t, 5, now, control, 1, marker(1))...)
+	buf = append(buf, encodeRecordBatch(t, 6, now, compressionNone,
+		[]testRecord{{value: []byte("idempotent")}})...)
+
+	decoded, err := decodeRecordBatches(buf, 0, []abortedTransaction{
+		{producerID: 1, firstOffset: 0},
+	})
+	require.NoError(t, err)
+	values := make([]string, 0, len(decoded))
+	for _, r := range decoded {
+		values = append(values, string(r.Value))
+	}
+	require.Equal(t, []string{"committed", "retried", "idempotent"}, values)
+}
+
+func TestDecodeSnappyXerialFraming(t *testing.T) {
+	var buf []byte
+	buf = append(buf, xerialSnappyMagic...)
+	buf = append(buf, 0, 0, 0, 1, 0, 0, 0, 1)
+	for _, chunk := range []string{"hello ", "world"} {
+		encoded := snappy.Encode(nil, []byte(chunk))
+		buf = append(buf, byte(len(encoded)>>24), byte(len(encoded)>>16),
+			byte(len(encoded)>>8), byte(len(encoded)))
+		buf = append(buf, encoded...)
+	}
+
+	decoded, err := decodeSnappy(buf)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(decoded))
+}
@@ -0,0 +1,569 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	kvutil "github.com/m3db/m3/src/cluster/kv/util"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/query/generated/proto/admin"
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// NamespaceProvisioningRecordKeyPrefix is the prefix of the KV key of the
+	// provisioning record of a tenant, a StringProto holding the record as
+	// JSON. The key of a tenant is the prefix followed by its tag value.
+	NamespaceProvisioningRecordKeyPrefix = "m3coordinator.namespace-provisioning.tenants."
+
+	// NamespaceProvisioningApprovalsKey is the KV key of a StringArrayProto of
+	// the tenant tag values approved for provisioning, only consulted when
+	// provisioning requires approval.
+	NamespaceProvisioningApprovalsKey = "m3coordinator.namespace-provisioning.approvals"
+
+	defaultNamespaceProvisioningRetryInterval = 30 * time.Second
+	namespaceProvisioningQueueSize            = 128
+	maxNamespaceProvisioningTrackedTenants    = 10000
+	maxNamespaceProvisioningTenantLength      = 64
+)
+
+var (
+	errNoNamespaceProvisioningTenantTag = errors.New("namespace provisioning requires a tenant tag")
+	errNoNamespaceProvisioningPrefix    = errors.New("namespace provisioning requires a namespace prefix")
+	errNoNamespaceProvisioningRetention = errors.New("namespace provisioning template requires a retention")
+	errNoNamespaceProvisioningQuota     = errors.New("namespace provisioning requires max namespaces to be set")
+	errNoNamespaceProvisioningKVStore   = errors.New("namespace provisioning requires a kv store")
+	errNoNamespaceProvisioningAdmin     = errors.New("namespace provisioning requires a namespace admin")
+)
+
+// NamespaceProvisioningConfiguration is the configuration for provisioning a
+// namespace from a template the first time a write for a new tenant is seen.
+// Every decision is recorded in KV, and provisioning can be gated on an
+// operator approving the tenant in KV.
+type NamespaceProvisioningConfiguration struct {
+	// Enabled enables namespace provisioning.
+	Enabled bool `yaml:"enabled"`
+
+	// TenantTag is the name of the tag that identifies the tenant of a series.
+	TenantTag string `yaml:"tenantTag"`
+
+	// NamespacePrefix is prepended to the tenant tag value to name the
+	// namespace of a tenant, e.g. "tenant_".
+	NamespacePrefix string `yaml:"namespacePrefix"`
+
+	// Template is the template provisioned namespaces are created from.
+	Template NamespaceTemplateConfiguration `yaml:"template"`
+
+	// MaxNamespaces is the maximum total number of namespaces in the cluster,
+	// no namespace is provisioned once it is reached.
+	MaxNamespaces int `yaml:"maxNamespaces" validate:"min=0"`
+
+	// RequireApproval only provisions tenants that are listed in the
+	// approvals KV key, other tenants are recorded as pending approval.
+	RequireApproval bool `yaml:"requireApproval"`
+
+	// RetryInterval is how often a tenant that is not provisioned yet is
+	// reconsidered, e.g. after it is approved, defaults to 30s.
+	RetryInterval time.Duration `yaml:"retryInterval"`
+}
+
+// NamespaceTemplateConfiguration is the template of a provisioned namespace.
+type NamespaceTemplateConfiguration struct {
+	// Retention is the retention of the namespace.
+	Retention time.Duration `yaml:"retention"`
+
+	// BlockSize is the block size of the namespace, defaults to the default
+	// namespace block size.
+	BlockSize time.Duration `yaml:"blockSize"`
+
+	// Resolution is the resolution tenants are expected to write at. It is
+	// recorded with the tenant but not registered as namespace aggregation
+	// options, since the coordinator routes aggregated writes by retention
+	// and resolution alone and tenant namespaces would conflict.
+	Resolution time.Duration `yaml:"resolution"`
+}
+
+// NamespaceProvisioningState is the state of a tenant's namespace.
+type NamespaceProvisioningState string
+
+const (
+	// NamespaceProvisioningPendingApproval is the state of a tenant waiting to
+	// be approved for provisioning.
+	NamespaceProvisioningPendingApproval NamespaceProvisioningState = "pending-approval"
+	// NamespaceProvisioningQuotaExceeded is the state of a tenant that could
+	// not be provisioned since the namespace quota is reached.
+	NamespaceProvisioningQuotaExceeded NamespaceProvisioningState = "quota-exceeded"
+	// NamespaceProvisioningProvisioned is the state of a tenant whose
+	// namespace exists.
+	NamespaceProvisioningProvisioned NamespaceProvisioningState = "provisioned"
+)
+
+// NamespaceProvisioningRecord is the record of a tenant kept in KV.
+type NamespaceProvisioningRecord struct {
+	Tenant     string                     `json:"tenant"`
+	Namespace  string                     `json:"namespace"`
+	State      NamespaceProvisioningState `json:"state"`
+	Retention  string                     `json:"retention"`
+	Resolution string                     `json:"resolution,omitempty"`
+	FirstSeen  time.Time                  `json:"firstSeen"`
+	UpdatedAt  time.Time                  `json:"updatedAt"`
+	UpdatedBy  string                     `json:"updatedBy,omitempty"`
+}
+
+// NamespaceAdmin lists and adds namespaces on behalf of a namespace
+// provisioner.
+type NamespaceAdmin interface {
+	// Namespaces returns the IDs of all namespaces.
+	Namespaces() ([]string, error)
+
+	// Add adds a namespace.
+	Add(req *admin.NamespaceAddRequest) error
+}
+
+// NamespaceProvisioner provisions a namespace from a template for each new
+// tenant seen in write traffic.
+type NamespaceProvisioner interface {
+	// TenantTag returns the name of the tag that identifies the tenant of a
+	// series.
+	TenantTag() []byte
+
+	// Observe notes a write for the tenant and provisions its namespace
+	// asynchronously if it is not provisioned yet.
+	Observe(tenant []byte)
+
+	// Close stops provisioning.
+	Close()
+}
+
+// NamespaceProvisionerOptions are the options for a namespace provisioner.
+type NamespaceProvisionerOptions struct {
+	// KVStore returns the KV store provisioning records and approvals are
+	// kept in, it is called on every provisioning attempt since it may be
+	// backed by a cluster client that is created asynchronously.
+	KVStore func() (kv.Store, error)
+	// Admin lists and adds namespaces.
+	Admin NamespaceAdmin
+	// InstrumentOptions are the instrument options.
+	InstrumentOptions instrument.Options
+	// NowFn is the now function, defaults to time.Now.
+	NowFn clock.NowFn
+}
+
+type namespaceProvisioner struct {
+	sync.RWMutex
+
+	tenantTag       []byte
+	prefix          string
+	template        NamespaceTemplateConfiguration
+	maxNamespaces   int
+	requireApproval bool
+	retryInterval   time.Duration
+
+	storeFn func() (kv.Store, error)
+	admin   NamespaceAdmin
+	nowFn   clock.NowFn
+	host    string
+	logger  *zap.Logger
+	metrics namespaceProvisioningMetrics
+
+	// provisioned are the tenants whose namespace exists, attempted are the
+	// last attempt times of all other tenants seen.
+	provisioned map[string]struct{}
+	attempted   map[string]time.Time
+
+	queue  chan string
+	closed chan struct{}
+}
+
+type namespaceProvisioningMetrics struct {
+	provisioned     tally.Counter
+	pendingApproval tally.Counter
+	quotaExceeded   tally.Counter
+	invalidTenant   tally.Counter
+	dropped         tally.Counter
+	errors          tally.Counter
+}
+
+func newNamespaceProvisioningMetrics(scope tally.Scope) namespaceProvisioningMetrics {
+	return namespaceProvisioningMetrics{
+		provisioned:     scope.Counter("provisioned"),
+		pendingApproval: scope.Counter("pending-approval"),
+		quotaExceeded:   scope.Counter("quota-exceeded"),
+		invalidTenant:   scope.Counter("invalid-tenant"),
+		dropped:         scope.Counter("dropped"),
+		errors:          scope.Counter("errors"),
+	}
+}
+
+// NewNamespaceProvisioner returns a new namespace provisioner.
+func (c NamespaceProvisioningConfiguration) NewNamespaceProvisioner(
+	opts NamespaceProvisionerOptions,
+) (NamespaceProvisioner, error) {
+	p, err := c.newNamespaceProvisioner(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	go p.run()
+	return p, nil
+}
+
+func (c NamespaceProvisioningConfiguration) newNamespaceProvisioner(
+	opts NamespaceProvisionerOptions,
+) (*namespaceProvisioner, error) {
+	if c.TenantTag == "" {
+		return nil, errNoNamespaceProvisioningTenantTag
+	}
+	if c.NamespacePrefix == "" {
+		return nil, errNoNamespaceProvisioningPrefix
+	}
+	if c.Template.Retention <= 0 {
+		return nil, errNoNamespaceProvisioningRetention
+	}
+	if c.MaxNamespaces <= 0 {
+		return nil, errNoNamespaceProvisioningQuota
+	}
+	if opts.KVStore == nil {
+		return nil, errNoNamespaceProvisioningKVStore
+	}
+	if opts.Admin == nil {
+		return nil, errNoNamespaceProvisioningAdmin
+	}
+
+	retryInterval := defaultNamespaceProvisioningRetryInterval
+	if c.RetryInterval > 0 {
+		retryInterval = c.RetryInterval
+	}
+
+	iOpts := opts.InstrumentOptions
+	if iOpts == nil {
+		iOpts = instrument.NewOptions()
+	}
+	nowFn := opts.NowFn
+	if nowFn == nil {
+		nowFn = time.Now
+	}
+
+	// NB: the host is only recorded to attribute decisions, so fall back to
+	// not recording it.
+	host, _ := os.Hostname()
+
+	return &namespaceProvisioner{
+		tenantTag:       []byte(c.TenantTag),
+		prefix:          c.NamespacePrefix,
+		template:        c.Template,
+		maxNamespaces:   c.MaxNamespaces,
+		requireApproval: c.RequireApproval,
+		retryInterval:   retryInterval,
+		storeFn:         opts.KVStore,
+		admin:           opts.Admin,
+		nowFn:           nowFn,
+		host:            host,
+		logger:          iOpts.Logger().With(zap.String("component", "namespace-provisioning")),
+		metrics:         newNamespaceProvisioningMetrics(iOpts.MetricsScope().SubScope("namespace-provisioning")),
+		provisioned:     make(map[string]struct{}),
+		attempted:       make(map[string]time.Time),
+		queue:           make(chan string, namespaceProvisioningQueueSize),
+		closed:          make(chan struct{}),
+	}, nil
+}
+
+func (p *namespaceProvisioner) TenantTag() []byte {
+	return p.tenantTag
+}
+
+func (p *namespaceProvisioner) Observe(tenant []byte) {
+	now := p.nowFn()
+	if !p.shouldAttempt(tenant, now) {
+		return
+	}
+	if !validNamespaceProvisioningTenant(tenant) {
+		p.metrics.invalidTenant.Inc(1)
+		return
+	}
+
+	value := string(tenant)
+	p.Lock()
+	if !p.shouldAttemptWithLock(value, now) {
+		p.Unlock()
+		return
+	}
+	if _, ok := p.attempted[value]; !ok && len(p.attempted) >= maxNamespaceProvisioningTrackedTenants {
+		// NB: bound the memory used by a flood of distinct tenant values.
+		p.Unlock()
+		p.metrics.dropped.Inc(1)
+		return
+	}
+	p.attempted[value] = now
+	p.Unlock()
+
+	select {
+	case p.queue <- value:
+	default:
+		p.metrics.dropped.Inc(1)
+	}
+}
+
+func (p *namespaceProvisioner) shouldAttempt(tenant []byte, now time.Time) bool {
+	p.RLock()
+	defer p.RUnlock()
+	return p.shouldAttemptWithLock(string(tenant), now)
+}
+
+func (p *namespaceProvisioner) shouldAttemptWithLock(tenant string, now time.Time) bool {
+	if _, ok := p.provisioned[tenant]; ok {
+		return false
+	}
+	last, ok := p.attempted[tenant]
+	return !ok || now.Sub(last) >= p.retryInterval
+}
+
+// validNamespaceProvisioningTenant returns whether a tenant tag value can be
+// used in a namespace name.
+func validNamespaceProvisioningTenant(tenant []byte) bool {
+	if len(tenant) == 0 || len(tenant) > maxNamespaceProvisioningTenantLength {
+		return false
+	}
+	for _, c := range tenant {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (p *namespaceProvisioner) run() {
+	for {
+		select {
+		case <-p.closed:
+			return
+		case tenant := <-p.queue:
+			if err := p.provision(tenant); err != nil {
+				p.metrics.errors.Inc(1)
+				p.logger.Error("could not provision namespace",
+					zap.String("tenant", tenant), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (p *namespaceProvisioner) provision(tenant string) error {
+	store, err := p.storeFn()
+	if err != nil {
+		return err
+	}
+
+	record, err := p.record(store, tenant)
+	if err != nil {
+		return err
+	}
+	if record.State == NamespaceProvisioningProvisioned {
+		p.markProvisioned(tenant)
+		return nil
+	}
+
+	if p.requireApproval {
+		approved, err := p.approved(store, tenant)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			p.metrics.pendingApproval.Inc(1)
+			return p.updateRecord(store, record, NamespaceProvisioningPendingApproval)
+		}
+	}
+
+	namespaces, err := p.admin.Namespaces()
+	if err != nil {
+		return err
+	}
+
+	exists := false
+	for _, id := range namespaces {
+		if id == record.Namespace {
+			exists = true
+			break
+		}
+	}
+
+	if !exists {
+		if len(namespaces) >= p.maxNamespaces {
+			p.metrics.quotaExceeded.Inc(1)
+			p.logger.Warn("namespace quota reached, not provisioning namespace",
+				zap.String("tenant", tenant),
+				zap.String("namespace", record.Namespace),
+				zap.Int("maxNamespaces", p.maxNamespaces))
+			return p.updateRecord(store, record, NamespaceProvisioningQuotaExceeded)
+		}
+
+		req, err := p.addRequest(record.Namespace)
+		if err != nil {
+			return err
+		}
+		if err := p.admin.Add(req); err != nil {
+			return fmt.Errorf("could not add namespace %s: %w", record.Namespace, err)
+		}
+
+		p.metrics.provisioned.Inc(1)
+		p.logger.Info("provisioned namespace",
+			zap.String("tenant", tenant),
+			zap.String("namespace", record.Namespace))
+	}
+
+	if err := p.updateRecord(store, record, NamespaceProvisioningProvisioned); err != nil {
+		return err
+	}
+	p.markProvisioned(tenant)
+	return nil
+}
+
+func (p *namespaceProvisioner) markProvisioned(tenant string) {
+	p.Lock()
+	p.provisioned[tenant] = struct{}{}
+	delete(p.attempted, tenant)
+	p.Unlock()
+}
+
+// record returns the provisioning record of the tenant, or a new record
+// without a state if there is none yet.
+func (p *namespaceProvisioner) record(
+	store kv.Store,
+	tenant string,
+) (NamespaceProvisioningRecord, error) {
+	key := NamespaceProvisioningRecordKeyPrefix + tenant
+	v, err := store.Get(key)
+	if err == kv.ErrNotFound {
+		record := NamespaceProvisioningRecord{
+			Tenant:    tenant,
+			Namespace: p.prefix + tenant,
+			Retention: p.template.Retention.String(),
+			FirstSeen: p.nowFn(),
+		}
+		if p.template.Resolution > 0 {
+			record.Resolution = p.template.Resolution.String()
+		}
+		return record, nil
+	}
+	if err != nil {
+		return NamespaceProvisioningRecord{}, err
+	}
+
+	encoded, err := kvutil.StringFromValue(v, key, "", nil)
+	if err != nil {
+		return NamespaceProvisioningRecord{}, err
+	}
+
+	var record NamespaceProvisioningRecord
+	if err := json.Unmarshal([]byte(encoded), &record); err != nil {
+		return NamespaceProvisioningRecord{}, fmt.Errorf(
+			"invalid namespace provisioning record %s: %w", key, err)
+	}
+	return record, nil
+}
+
+// updateRecord records a new state for the tenant, the record is left as is
+// if the state is unchanged so that retries do not write to KV.
+func (p *namespaceProvisioner) updateRecord(
+	store kv.Store,
+	record NamespaceProvisioningRecord,
+	state NamespaceProvisioningState,
+) error {
+	if record.State == state {
+		return nil
+	}
+
+	record.State = state
+	record.UpdatedAt = p.nowFn()
+	record.UpdatedBy = p.host
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	key := NamespaceProvisioningRecordKeyPrefix + record.Tenant
+	_, err = store.Set(key, &commonpb.StringProto{Value: string(encoded)})
+	return err
+}
+
+func (p *namespaceProvisioner) approved(store kv.Store, tenant string) (bool, error) {
+	v, err := store.Get(NamespaceProvisioningApprovalsKey)
+	if err == kv.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	approvals, err := kvutil.StringArrayFromValue(v, NamespaceProvisioningApprovalsKey, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	for _, approval := range approvals {
+		if approval == tenant {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *namespaceProvisioner) addRequest(name string) (*admin.NamespaceAddRequest, error) {
+	opts := namespace.NewOptions()
+
+	retentionOpts := opts.RetentionOptions().
+		SetRetentionPeriod(p.template.Retention)
+	indexOpts := opts.IndexOptions().
+		SetEnabled(true)
+	if blockSize := p.template.BlockSize; blockSize > 0 {
+		retentionOpts = retentionOpts.SetBlockSize(blockSize)
+		indexOpts = indexOpts.SetBlockSize(blockSize)
+	}
+
+	opts = opts.SetRetentionOptions(retentionOpts).
+		SetIndexOptions(indexOpts)
+
+	optsProto, err := namespace.OptionsToProto(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin.NamespaceAddRequest{
+		Name:    name,
+		Options: optsProto,
+	}, nil
+}
+
+func (p *namespaceProvisioner) Close() {
+	close(p.closed)
+}
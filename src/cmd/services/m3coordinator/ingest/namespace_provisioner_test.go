@@ -0,0 +1,231 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/query/generated/proto/admin"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNamespaceAdmin struct {
+	namespaces []string
+	added      []*admin.NamespaceAddRequest
+}
+
+func (a *testNamespaceAdmin) Namespaces() ([]string, error) {
+	return a.namespaces, nil
+}
+
+func (a *testNamespaceAdmin) Add(req *admin.NamespaceAddRequest) error {
+	a.added = append(a.added, req)
+	a.namespaces = append(a.namespaces, req.Name)
+	return nil
+}
+
+func newTestNamespaceProvisioner(
+	t *testing.T,
+	cfg NamespaceProvisioningConfiguration,
+	store kv.Store,
+	nsAdmin NamespaceAdmin,
+	now *time.Time,
+) *namespaceProvisioner {
+	cfg.TenantTag = "tenant"
+	cfg.NamespacePrefix = "tenant_"
+	cfg.Template = NamespaceTemplateConfiguration{
+		Retention:  48 * time.Hour,
+		BlockSize:  time.Hour,
+		Resolution: time.Minute,
+	}
+	if cfg.MaxNamespaces == 0 {
+		cfg.MaxNamespaces = 10
+	}
+
+	p, err := cfg.newNamespaceProvisioner(NamespaceProvisionerOptions{
+		KVStore: func() (kv.Store, error) { return store, nil },
+		Admin:   nsAdmin,
+		NowFn:   func() time.Time { return *now },
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func requireNamespaceProvisioningRecord(
+	t *testing.T,
+	store kv.Store,
+	tenant string,
+) NamespaceProvisioningRecord {
+	v, err := store.Get(NamespaceProvisioningRecordKeyPrefix + tenant)
+	require.NoError(t, err)
+
+	var value commonpb.StringProto
+	require.NoError(t, v.Unmarshal(&value))
+
+	var record NamespaceProvisioningRecord
+	require.NoError(t, json.Unmarshal([]byte(value.Value), &record))
+	return record
+}
+
+func TestNamespaceProvisioningConfigurationValidation(t *testing.T) {
+	opts := NamespaceProvisionerOptions{
+		KVStore: func() (kv.Store, error) { return mem.NewStore(), nil },
+		Admin:   &testNamespaceAdmin{},
+	}
+	valid := NamespaceProvisioningConfiguration{
+		TenantTag:       "tenant",
+		NamespacePrefix: "tenant_",
+		Template:        NamespaceTemplateConfiguration{Retention: time.Hour},
+		MaxNamespaces:   10,
+	}
+	_, err := valid.newNamespaceProvisioner(opts)
+	require.NoError(t, err)
+
+	for _, fn := range []func(c *NamespaceProvisioningConfiguration){
+		func(c *NamespaceProvisioningConfiguration) { c.TenantTag = "" },
+		func(c *NamespaceProvisioningConfiguration) { c.NamespacePrefix = "" },
+		func(c *NamespaceProvisioningConfiguration) { c.Template.Retention = 0 },
+		func(c *NamespaceProvisioningConfiguration) { c.MaxNamespaces = 0 },
+	} {
+		cfg := valid
+		fn(&cfg)
+		_, err := cfg.newNamespaceProvisioner(opts)
+		require.Error(t, err)
+	}
+}
+
+func TestNamespaceProvisionerProvisionsFromTemplate(t *testing.T) {
+	var (
+		now     = time.Now()
+		store   = mem.NewStore()
+		nsAdmin = &testNamespaceAdmin{namespaces: []string{"default"}}
+		p       = newTestNamespaceProvisioner(t,
+			NamespaceProvisioningConfiguration{}, store, nsAdmin, &now)
+	)
+
+	require.NoError(t, p.provision("acme"))
+
+	require.Len(t, nsAdmin.added, 1)
+	req := nsAdmin.added[0]
+	assert.Equal(t, "tenant_acme", req.Name)
+	md, err := namespace.ToMetadata(req.Name, req.Options)
+	require.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, md.Options().RetentionOptions().RetentionPeriod())
+	assert.Equal(t, time.Hour, md.Options().RetentionOptions().BlockSize())
+	assert.Equal(t, time.Hour, md.Options().IndexOptions().BlockSize())
+
+	record := requireNamespaceProvisioningRecord(t, store, "acme")
+	assert.Equal(t, NamespaceProvisioningProvisioned, record.State)
+	assert.Equal(t, "tenant_acme", record.Namespace)
+	assert.Equal(t, "1m0s", record.Resolution)
+
+	// Provisioned tenants are no longer tracked.
+	p.Observe([]byte("acme"))
+	assert.Len(t, p.queue, 0)
+
+	// The namespace is not added again if the record is lost.
+	p = newTestNamespaceProvisioner(t,
+		NamespaceProvisioningConfiguration{}, mem.NewStore(), nsAdmin, &now)
+	require.NoError(t, p.provision("acme"))
+	require.Len(t, nsAdmin.added, 1)
+}
+
+func TestNamespaceProvisionerRequiresApproval(t *testing.T) {
+	var (
+		now     = time.Now()
+		store   = mem.NewStore()
+		nsAdmin = &testNamespaceAdmin{}
+		p       = newTestNamespaceProvisioner(t, NamespaceProvisioningConfiguration{
+			RequireApproval: true,
+		}, store, nsAdmin, &now)
+	)
+
+	require.NoError(t, p.provision("acme"))
+	require.Len(t, nsAdmin.added, 0)
+	record := requireNamespaceProvisioningRecord(t, store, "acme")
+	assert.Equal(t, NamespaceProvisioningPendingApproval, record.State)
+	firstSeen := record.FirstSeen
+
+	_, err := store.Set(NamespaceProvisioningApprovalsKey,
+		&commonpb.StringArrayProto{Values: []string{"acme"}})
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, p.provision("acme"))
+	require.Len(t, nsAdmin.added, 1)
+	record = requireNamespaceProvisioningRecord(t, store, "acme")
+	assert.Equal(t, NamespaceProvisioningProvisioned, record.State)
+	assert.True(t, firstSeen.Equal(record.FirstSeen))
+	assert.True(t, now.Equal(record.UpdatedAt))
+}
+
+func TestNamespaceProvisionerQuota(t *testing.T) {
+	var (
+		now     = time.Now()
+		store   = mem.NewStore()
+		nsAdmin = &testNamespaceAdmin{namespaces: []string{"default"}}
+		p       = newTestNamespaceProvisioner(t, NamespaceProvisioningConfiguration{
+			MaxNamespaces: 2,
+		}, store, nsAdmin, &now)
+	)
+
+	require.NoError(t, p.provision("acme"))
+	require.NoError(t, p.provision("globex"))
+
+	require.Len(t, nsAdmin.added, 1)
+	assert.Equal(t, NamespaceProvisioningProvisioned,
+		requireNamespaceProvisioningRecord(t, store, "acme").State)
+	assert.Equal(t, NamespaceProvisioningQuotaExceeded,
+		requireNamespaceProvisioningRecord(t, store, "globex").State)
+}
+
+func TestNamespaceProvisionerObserve(t *testing.T) {
+	var (
+		now = time.Now()
+		p   = newTestNamespaceProvisioner(t, NamespaceProvisioningConfiguration{
+			RetryInterval: time.Minute,
+		}, mem.NewStore(), &testNamespaceAdmin{}, &now)
+	)
+
+	for _, invalid := range []string{"", "acme/prod", "acme.prod"} {
+		p.Observe([]byte(invalid))
+	}
+	assert.Len(t, p.queue, 0)
+
+	p.Observe([]byte("acme"))
+	p.Observe([]byte("acme"))
+	require.Len(t, p.queue, 1)
+	assert.Equal(t, "acme", <-p.queue)
+
+	// Tenants that are not provisioned are retried after the retry interval.
+	now = now.Add(time.Minute)
+	p.Observe([]byte("acme"))
+	require.Len(t, p.queue, 1)
+	assert.Equal(t, "acme", <-p.queue)
+}
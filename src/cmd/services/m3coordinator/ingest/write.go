@@ -115,6 +115,9 @@ type WriteOptions struct {
 type downsamplerAndWriterMetrics struct {
 	dropped metricsBySource
 	written metricsBySource
+
+	sampledWritten tally.Counter
+	sampledErrors  tally.Counter
 }
 
 type metricsBySource struct {
@@ -136,26 +139,34 @@ type downsamplerAndWriter struct {
 	store       storage.Storage
 	downsampler downsample.Downsampler
 	workerPool  xsync.PooledWorkerPool
+	sampler     WriteSampler
 
 	metrics downsamplerAndWriterMetrics
 }
 
-// NewDownsamplerAndWriter creates a new downsampler and writer.
+// NewDownsamplerAndWriter creates a new downsampler and writer. The write
+// sampler is optional and forks a sample of unaggregated writes into
+// additional namespaces when set.
 func NewDownsamplerAndWriter(
 	store storage.Storage,
 	downsampler downsample.Downsampler,
 	workerPool xsync.PooledWorkerPool,
+	sampler WriteSampler,
 	instrumentOpts instrument.Options,
 ) DownsamplerAndWriter {
 	scope := instrumentOpts.MetricsScope().SubScope("downsampler")
+	samplingScope := scope.SubScope("write-sampling")
 
 	return &downsamplerAndWriter{
 		store:       store,
 		downsampler: downsampler,
 		workerPool:  workerPool,
+		sampler:     sampler,
 		metrics: downsamplerAndWriterMetrics{
-			dropped: newMetricsBySource(scope, "metrics_dropped"),
-			written: newMetricsBySource(scope, "metrics_written"),
+			dropped:        newMetricsBySource(scope, "metrics_dropped"),
+			written:        newMetricsBySource(scope, "metrics_written"),
+			sampledWritten: samplingScope.Counter("metrics_written"),
+			sampledErrors:  samplingScope.Counter("errors"),
 		},
 	}
 }
@@ -202,11 +213,62 @@ func (d *downsamplerAndWriter) Write(
 		if err != nil {
 			multiErr = multiErr.Add(err)
 		}
+		d.writeSampled(ctx, tags, datapoints, unit, annotation, nil)
 	}
 
 	return multiErr.FinalError()
 }
 
+// writeSampled forks the write into the storage policies of any write sampling
+// rules that sample the series. Sampled writes are best effort, failures are
+// only reported via metrics and never fail the original write. If a wait group
+// is provided the writes are done asynchronously and tracked by it.
+func (d *downsamplerAndWriter) writeSampled(
+	ctx context.Context,
+	tags models.Tags,
+	datapoints ts.Datapoints,
+	unit xtime.Unit,
+	annotation []byte,
+	wg *sync.WaitGroup,
+) {
+	if d.sampler == nil {
+		return
+	}
+
+	storagePolicies := d.sampler.SampledStoragePolicies(nil, tags)
+	for _, p := range storagePolicies {
+		p := p // Capture for lambda.
+		write := func() {
+			writeQuery, err := storage.NewWriteQuery(storage.WriteQueryOptions{
+				Tags:       tags,
+				Datapoints: datapoints,
+				Unit:       unit,
+				Annotation: annotation,
+				Attributes: storageAttributesFromPolicy(p),
+			})
+			if err == nil {
+				err = d.store.Write(ctx, writeQuery)
+			}
+			if err != nil {
+				d.metrics.sampledErrors.Inc(1)
+				return
+			}
+			d.metrics.sampledWritten.Inc(1)
+		}
+
+		if wg == nil {
+			write()
+			continue
+		}
+
+		wg.Add(1)
+		d.workerPool.Go(func() {
+			write()
+			wg.Done()
+		})
+	}
+}
+
 func (d *downsamplerAndWriter) shouldWrite(
 	overrides WriteOptions,
 ) bool {
@@ -474,6 +536,8 @@ func (d *downsamplerAndWriter) WriteBatch(
 					wg.Done()
 				})
 			}
+
+			d.writeSampled(ctx, value.Tags, value.Datapoints, value.Unit, value.Annotation, &wg)
 		}
 	}
 
@@ -0,0 +1,373 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	kvutil "github.com/m3db/m3/src/cluster/kv/util"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/sampler"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+const (
+	// WriteAuditEnabledKey is the KV key of a BoolProto that enables or
+	// disables write auditing at runtime.
+	WriteAuditEnabledKey = "m3coordinator.write-audit.enabled"
+
+	// WriteAuditMatchersKey is the KV key of a StringArrayProto of write audit
+	// matchers, each matcher uses the same syntax as a write sampling filter.
+	WriteAuditMatchersKey = "m3coordinator.write-audit.matchers"
+
+	defaultWriteAuditMaxLogsPerSecond = 10
+	writeAuditKVRetryInterval         = 10 * time.Second
+)
+
+var errEmptyWriteAuditMatcher = errors.New("write audit matcher must not be empty")
+
+// WriteAuditConfiguration is the configuration for logging exemplar series
+// of incoming writes that match a set of audit matchers, e.g. to track down
+// the producer of a cardinality explosion. The enabled flag and the matchers
+// can be overridden at runtime through KV.
+type WriteAuditConfiguration struct {
+	// Enabled enables write auditing when not overridden in KV.
+	Enabled bool `yaml:"enabled"`
+
+	// Matchers are the audit matchers used when not overridden in KV, each is a
+	// space separated filter of label name to label value glob patterns, e.g.
+	// "__name__:http_requests_* pod:*". A series is audited if it matches any.
+	Matchers []string `yaml:"matchers"`
+
+	// SampleRate is the fraction of matching series that are considered for
+	// logging, defaults to all of them.
+	SampleRate *sampler.Rate `yaml:"sampleRate"`
+
+	// MaxLogsPerSecond is the maximum number of audit records logged per
+	// second, defaults to 10.
+	MaxLogsPerSecond int `yaml:"maxLogsPerSecond" validate:"min=0"`
+}
+
+// WriteAuditSource describes where an audited write came from and how it
+// is written.
+type WriteAuditSource struct {
+	// RemoteAddr is the remote address of the writer.
+	RemoteAddr string
+	// Identity is the identity the writer presented, if any.
+	Identity string
+	// Options are the write options applied to the write.
+	Options WriteOptions
+}
+
+// WriteAuditor logs a rate limited sample of written series that match a set
+// of audit matchers.
+type WriteAuditor interface {
+	// Enabled returns whether write auditing is currently enabled.
+	Enabled() bool
+
+	// Audit logs an audit record for the series if it matches any of the
+	// audit matchers and is sampled.
+	Audit(tags models.Tags, source WriteAuditSource)
+
+	// Close stops watching for runtime updates.
+	Close()
+}
+
+// WriteAuditorOptions are the options for a write auditor.
+type WriteAuditorOptions struct {
+	// KVStore returns the KV store to watch for runtime updates, it is
+	// retried until it succeeds since it may be backed by a cluster client
+	// that is created asynchronously. Runtime updates are disabled if nil.
+	KVStore func() (kv.Store, error)
+	// InstrumentOptions are the instrument options.
+	InstrumentOptions instrument.Options
+	// NowFn is the now function, defaults to time.Now.
+	NowFn clock.NowFn
+}
+
+type writeAuditor struct {
+	sync.RWMutex
+
+	enabled  *atomic.Bool
+	matchers [][]tagValueFilter
+
+	defaultEnabled  bool
+	defaultMatchers []string
+
+	sampler *sampler.Sampler
+	limiter *writeAuditLimiter
+	logger  *zap.Logger
+	metrics writeAuditMetrics
+
+	closed chan struct{}
+}
+
+type writeAuditMetrics struct {
+	matched     tally.Counter
+	logged      tally.Counter
+	rateLimited tally.Counter
+}
+
+func newWriteAuditMetrics(scope tally.Scope) writeAuditMetrics {
+	return writeAuditMetrics{
+		matched:     scope.Counter("matched"),
+		logged:      scope.Counter("logged"),
+		rateLimited: scope.Counter("rate-limited"),
+	}
+}
+
+// NewWriteAuditor returns a new write auditor.
+func (c WriteAuditConfiguration) NewWriteAuditor(
+	opts WriteAuditorOptions,
+) (WriteAuditor, error) {
+	matchers, err := newWriteAuditMatchers(c.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleRate := sampler.Rate(1)
+	if c.SampleRate != nil {
+		sampleRate = *c.SampleRate
+	}
+	s, err := sampler.NewSampler(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	maxLogsPerSecond := defaultWriteAuditMaxLogsPerSecond
+	if c.MaxLogsPerSecond > 0 {
+		maxLogsPerSecond = c.MaxLogsPerSecond
+	}
+
+	iOpts := opts.InstrumentOptions
+	if iOpts == nil {
+		iOpts = instrument.NewOptions()
+	}
+	nowFn := opts.NowFn
+	if nowFn == nil {
+		nowFn = time.Now
+	}
+
+	a := &writeAuditor{
+		enabled:         atomic.NewBool(c.Enabled),
+		matchers:        matchers,
+		defaultEnabled:  c.Enabled,
+		defaultMatchers: c.Matchers,
+		sampler:         s,
+		limiter:         newWriteAuditLimiter(int64(maxLogsPerSecond), nowFn),
+		logger:          iOpts.Logger().With(zap.String("component", "write-audit")),
+		metrics:         newWriteAuditMetrics(iOpts.MetricsScope().SubScope("write-audit")),
+		closed:          make(chan struct{}),
+	}
+
+	if opts.KVStore != nil {
+		go a.watchKV(opts.KVStore)
+	}
+
+	return a, nil
+}
+
+func newWriteAuditMatchers(matchers []string) ([][]tagValueFilter, error) {
+	result := make([][]tagValueFilter, 0, len(matchers))
+	for _, matcher := range matchers {
+		// NB: an empty matcher matches every series, which is never what an
+		// audit is after and would log every write up to the rate limit.
+		valueFilters, err := newTagValueFilters(matcher)
+		if err == nil && len(valueFilters) == 0 {
+			err = errEmptyWriteAuditMatcher
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid write audit matcher %s: %w", matcher, err)
+		}
+		result = append(result, valueFilters)
+	}
+	return result, nil
+}
+
+func (a *writeAuditor) watchKV(storeFn func() (kv.Store, error)) {
+	var store kv.Store
+	for {
+		var err error
+		store, err = storeFn()
+		if err == nil {
+			break
+		}
+		a.logger.Warn("could not get kv store for write audit, retrying",
+			zap.Duration("retryInterval", writeAuditKVRetryInterval),
+			zap.Error(err))
+		select {
+		case <-a.closed:
+			return
+		case <-time.After(writeAuditKVRetryInterval):
+		}
+	}
+
+	if err := a.watchKey(store, WriteAuditEnabledKey, a.updateEnabled); err != nil {
+		a.logger.Error("could not watch write audit enabled key", zap.Error(err))
+	}
+	if err := a.watchKey(store, WriteAuditMatchersKey, a.updateMatchers); err != nil {
+		a.logger.Error("could not watch write audit matchers key", zap.Error(err))
+	}
+}
+
+func (a *writeAuditor) watchKey(
+	store kv.Store,
+	key string,
+	update func(v kv.Value) error,
+) error {
+	watch, err := store.Watch(key)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer watch.Close()
+		for {
+			select {
+			case <-a.closed:
+				return
+			case _, ok := <-watch.C():
+				if !ok {
+					return
+				}
+				if err := update(watch.Get()); err != nil {
+					a.logger.Error("invalid write audit update",
+						zap.String("key", key), zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (a *writeAuditor) updateEnabled(v kv.Value) error {
+	enabled, err := kvutil.BoolFromValue(v, WriteAuditEnabledKey, a.defaultEnabled, nil)
+	if err != nil {
+		return err
+	}
+	a.enabled.Store(enabled)
+	return nil
+}
+
+func (a *writeAuditor) updateMatchers(v kv.Value) error {
+	values, err := kvutil.StringArrayFromValue(v, WriteAuditMatchersKey, a.defaultMatchers, nil)
+	if err != nil {
+		return err
+	}
+	matchers, err := newWriteAuditMatchers(values)
+	if err != nil {
+		return err
+	}
+
+	a.Lock()
+	a.matchers = matchers
+	a.Unlock()
+	return nil
+}
+
+func (a *writeAuditor) Enabled() bool {
+	return a.enabled.Load()
+}
+
+func (a *writeAuditor) Audit(tags models.Tags, source WriteAuditSource) {
+	if !a.matches(tags) {
+		return
+	}
+
+	a.metrics.matched.Inc(1)
+	if !a.sampler.Sample() {
+		return
+	}
+	if !a.limiter.allow() {
+		a.metrics.rateLimited.Inc(1)
+		return
+	}
+
+	a.metrics.logged.Inc(1)
+	a.logger.Info("audited write",
+		zap.Stringer("labels", tags),
+		zap.String("remoteAddr", source.RemoteAddr),
+		zap.String("identity", source.Identity),
+		zap.Strings("storagePolicies", writeAuditStoragePolicies(source.Options)),
+		zap.Bool("downsampleOverride", source.Options.DownsampleOverride))
+}
+
+func (a *writeAuditor) matches(tags models.Tags) bool {
+	a.RLock()
+	defer a.RUnlock()
+	for _, matcher := range a.matchers {
+		if matchesTagValueFilters(matcher, tags) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *writeAuditor) Close() {
+	close(a.closed)
+}
+
+// writeAuditStoragePolicies describes the namespaces a write is applied to.
+func writeAuditStoragePolicies(opts WriteOptions) []string {
+	if !opts.WriteOverride {
+		return []string{"unaggregated"}
+	}
+	policies := make([]string, 0, len(opts.WriteStoragePolicies))
+	for _, p := range opts.WriteStoragePolicies {
+		policies = append(policies, p.String())
+	}
+	return policies
+}
+
+// writeAuditLimiter allows a fixed number of events per wall clock second.
+type writeAuditLimiter struct {
+	limit  int64
+	nowFn  clock.NowFn
+	second *atomic.Int64
+	count  *atomic.Int64
+}
+
+func newWriteAuditLimiter(limit int64, nowFn clock.NowFn) *writeAuditLimiter {
+	return &writeAuditLimiter{
+		limit:  limit,
+		nowFn:  nowFn,
+		second: atomic.NewInt64(0),
+		count:  atomic.NewInt64(0),
+	}
+}
+
+func (l *writeAuditLimiter) allow() bool {
+	now := l.nowFn().Unix()
+	if prev := l.second.Load(); prev != now && l.second.CAS(prev, now) {
+		l.count.Store(0)
+	}
+	return l.count.Inc() <= l.limit
+}
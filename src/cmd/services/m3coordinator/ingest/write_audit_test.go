@@ -0,0 +1,148 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestWriteAuditor(
+	t *testing.T,
+	cfg WriteAuditConfiguration,
+	store kv.Store,
+	now *time.Time,
+) (WriteAuditor, *observer.ObservedLogs) {
+	core, recorded := observer.New(zapcore.InfoLevel)
+	opts := WriteAuditorOptions{
+		InstrumentOptions: instrument.NewOptions().SetLogger(zap.New(core)),
+		NowFn:             func() time.Time { return *now },
+	}
+	if store != nil {
+		opts.KVStore = func() (kv.Store, error) { return store, nil }
+	}
+
+	auditor, err := cfg.NewWriteAuditor(opts)
+	require.NoError(t, err)
+	t.Cleanup(auditor.Close)
+	return auditor, recorded
+}
+
+func TestWriteAuditConfigurationInvalidMatcher(t *testing.T) {
+	for _, matcher := range []string{"", "env"} {
+		_, err := WriteAuditConfiguration{
+			Matchers: []string{matcher},
+		}.NewWriteAuditor(WriteAuditorOptions{})
+		require.Error(t, err, matcher)
+	}
+}
+
+func TestWriteAuditorLogsMatchingSeries(t *testing.T) {
+	now := time.Now()
+	auditor, recorded := newTestWriteAuditor(t, WriteAuditConfiguration{
+		Enabled:  true,
+		Matchers: []string{"__name__:http_requests_* env:prod"},
+	}, nil, &now)
+	require.True(t, auditor.Enabled())
+
+	source := WriteAuditSource{
+		RemoteAddr: "10.0.0.1:1234",
+		Identity:   "prometheus",
+		Options: WriteOptions{
+			WriteOverride:        true,
+			WriteStoragePolicies: []policy.StoragePolicy{testSampledStoragePolicy},
+		},
+	}
+	auditor.Audit(newTestSamplingTags("http_requests_total", "prod", 0), source)
+	auditor.Audit(newTestSamplingTags("http_requests_total", "dev", 1), source)
+	auditor.Audit(newTestSamplingTags("cpu_seconds", "prod", 2), source)
+
+	logs := recorded.FilterMessage("audited write").All()
+	require.Len(t, logs, 1)
+	fields := logs[0].ContextMap()
+	assert.Equal(t, "10.0.0.1:1234", fields["remoteAddr"])
+	assert.Equal(t, "prometheus", fields["identity"])
+	assert.Equal(t, []interface{}{testSampledStoragePolicy.String()}, fields["storagePolicies"])
+}
+
+func TestWriteAuditorRateLimited(t *testing.T) {
+	now := time.Now()
+	auditor, recorded := newTestWriteAuditor(t, WriteAuditConfiguration{
+		Enabled:          true,
+		Matchers:         []string{"env:prod"},
+		MaxLogsPerSecond: 2,
+	}, nil, &now)
+
+	for i := 0; i < 5; i++ {
+		auditor.Audit(newTestSamplingTags("foo", "prod", i), WriteAuditSource{})
+	}
+	require.Equal(t, 2, recorded.FilterMessage("audited write").Len())
+
+	now = now.Add(time.Second)
+	for i := 0; i < 5; i++ {
+		auditor.Audit(newTestSamplingTags("foo", "prod", i), WriteAuditSource{})
+	}
+	require.Equal(t, 4, recorded.FilterMessage("audited write").Len())
+}
+
+func TestWriteAuditorKVUpdates(t *testing.T) {
+	store := mem.NewStore()
+	now := time.Now()
+	auditor, recorded := newTestWriteAuditor(t, WriteAuditConfiguration{}, store, &now)
+	require.False(t, auditor.Enabled())
+
+	_, err := store.Set(WriteAuditEnabledKey, &commonpb.BoolProto{Value: true})
+	require.NoError(t, err)
+	_, err = store.Set(WriteAuditMatchersKey, &commonpb.StringArrayProto{
+		Values: []string{"env:prod"},
+	})
+	require.NoError(t, err)
+
+	require.True(t, clock.WaitUntil(func() bool {
+		auditor.Audit(newTestSamplingTags("foo", "prod", 0), WriteAuditSource{})
+		return auditor.Enabled() && recorded.FilterMessage("audited write").Len() > 0
+	}, 5*time.Second))
+
+	// Invalid matchers are logged and not applied.
+	_, err = store.Set(WriteAuditMatchersKey, &commonpb.StringArrayProto{
+		Values: []string{"env"},
+	})
+	require.NoError(t, err)
+
+	_, err = store.Set(WriteAuditEnabledKey, &commonpb.BoolProto{Value: false})
+	require.NoError(t, err)
+	require.True(t, clock.WaitUntil(func() bool {
+		return !auditor.Enabled()
+	}, 5*time.Second))
+}
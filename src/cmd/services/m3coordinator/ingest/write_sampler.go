@@ -0,0 +1,221 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m3db/m3/src/metrics/filters"
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/uber-go/tally"
+)
+
+// samplingBuckets is the number of buckets series are hashed into when
+// deciding whether they are sampled. Sample rates are rounded down to a
+// multiple of 1/samplingBuckets, i.e. a resolution of 0.0001%, so a rate
+// below 0.000001 samples no series.
+const samplingBuckets = 1000000
+
+var errNoWriteSamplingStoragePolicy = errors.New("write sampling rule requires a storage policy")
+
+// WriteSamplingConfiguration is the configuration for forking a sample of
+// incoming writes into additional namespaces, e.g. to mirror a subset of
+// production writes into a test namespace.
+type WriteSamplingConfiguration struct {
+	// Rules are the write sampling rules, a series is written to the storage
+	// policy of every rule that samples it.
+	Rules []WriteSamplingRuleConfiguration `yaml:"rules"`
+}
+
+// WriteSamplingRuleConfiguration is the configuration for a single write
+// sampling rule.
+type WriteSamplingRuleConfiguration struct {
+	// Filter is a space separated filter of label name to label value glob
+	// patterns to select series eligible for sampling, e.g.
+	// "__name__:http_requests_* env:prod". An empty filter matches all series.
+	Filter string `yaml:"filter"`
+
+	// SampleRate is the fraction of matching series, between 0 and 1, that
+	// are written to the target namespace. It is rounded down to a multiple
+	// of 0.000001.
+	SampleRate float64 `yaml:"sampleRate" validate:"min=0.0,max=1.0"`
+
+	// StoragePolicy is the storage policy of the target namespace, e.g. "1m:48h".
+	StoragePolicy policy.StoragePolicy `yaml:"storagePolicy"`
+}
+
+// NewWriteSampler returns a new write sampler for the configuration, or nil
+// if there are no rules configured.
+func (c WriteSamplingConfiguration) NewWriteSampler(scope tally.Scope) (WriteSampler, error) {
+	if len(c.Rules) == 0 {
+		return nil, nil
+	}
+
+	scope = scope.SubScope("write-sampler")
+	rules := make([]writeSamplingRule, 0, len(c.Rules))
+	for _, r := range c.Rules {
+		rule, err := newWriteSamplingRule(r, scope)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return &writeSampler{rules: rules}, nil
+}
+
+// WriteSampler decides which additional storage policies a series should be
+// written to. Sampling is consistent, a given series is always either sampled
+// or not for a rule.
+type WriteSampler interface {
+	// SampledStoragePolicies appends the storage policies the series should
+	// additionally be written to and returns the resulting slice.
+	SampledStoragePolicies(
+		dst []policy.StoragePolicy,
+		tags models.Tags,
+	) []policy.StoragePolicy
+}
+
+type writeSampler struct {
+	rules []writeSamplingRule
+}
+
+type writeSamplingRule struct {
+	filters       []tagValueFilter
+	threshold     uint64
+	storagePolicy policy.StoragePolicy
+	metrics       writeSamplingRuleMetrics
+}
+
+type writeSamplingRuleMetrics struct {
+	sampled tally.Counter
+	dropped tally.Counter
+}
+
+func newWriteSamplingRuleMetrics(
+	scope tally.Scope,
+	storagePolicy policy.StoragePolicy,
+) writeSamplingRuleMetrics {
+	scope = scope.Tagged(map[string]string{
+		"storage-policy": storagePolicy.String(),
+	})
+	return writeSamplingRuleMetrics{
+		sampled: scope.Counter("sampled"),
+		dropped: scope.Counter("dropped"),
+	}
+}
+
+type tagValueFilter struct {
+	name   []byte
+	filter filters.Filter
+}
+
+func newWriteSamplingRule(
+	cfg WriteSamplingRuleConfiguration,
+	scope tally.Scope,
+) (writeSamplingRule, error) {
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return writeSamplingRule{}, fmt.Errorf(
+			"write sampling rate must be between 0 and 1: actual=%v", cfg.SampleRate)
+	}
+	if cfg.StoragePolicy.Equivalent(policy.EmptyStoragePolicy) {
+		return writeSamplingRule{}, errNoWriteSamplingStoragePolicy
+	}
+
+	valueFilters, err := newTagValueFilters(cfg.Filter)
+	if err != nil {
+		return writeSamplingRule{}, fmt.Errorf(
+			"invalid write sampling filter %s: %w", cfg.Filter, err)
+	}
+
+	return writeSamplingRule{
+		filters:       valueFilters,
+		threshold:     uint64(cfg.SampleRate * samplingBuckets),
+		storagePolicy: cfg.StoragePolicy,
+		metrics:       newWriteSamplingRuleMetrics(scope, cfg.StoragePolicy),
+	}, nil
+}
+
+func (s *writeSampler) SampledStoragePolicies(
+	dst []policy.StoragePolicy,
+	tags models.Tags,
+) []policy.StoragePolicy {
+	var (
+		hash     uint64
+		computed bool
+	)
+	for _, rule := range s.rules {
+		if rule.threshold == 0 || !rule.matches(tags) {
+			continue
+		}
+		if !computed {
+			// NB: only hash the series once it matches at least one rule.
+			hash = tags.HashedID()
+			computed = true
+		}
+		if hash%samplingBuckets < rule.threshold {
+			dst = append(dst, rule.storagePolicy)
+			rule.metrics.sampled.Inc(1)
+		} else {
+			rule.metrics.dropped.Inc(1)
+		}
+	}
+	return dst
+}
+
+func (r writeSamplingRule) matches(tags models.Tags) bool {
+	return matchesTagValueFilters(r.filters, tags)
+}
+
+// newTagValueFilters parses a space separated filter of label name to label
+// value glob patterns.
+func newTagValueFilters(filter string) ([]tagValueFilter, error) {
+	filterValues, err := filters.ParseTagFilterValueMap(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	valueFilters := make([]tagValueFilter, 0, len(filterValues))
+	for name, value := range filterValues {
+		f, err := filters.NewFilterFromFilterValue(value)
+		if err != nil {
+			return nil, err
+		}
+		valueFilters = append(valueFilters, tagValueFilter{
+			name:   []byte(name),
+			filter: f,
+		})
+	}
+	return valueFilters, nil
+}
+
+func matchesTagValueFilters(valueFilters []tagValueFilter, tags models.Tags) bool {
+	for _, f := range valueFilters {
+		value, _ := tags.Get(f.name)
+		if !f.filter.Matches(value) {
+			return false
+		}
+	}
+	return true
+}
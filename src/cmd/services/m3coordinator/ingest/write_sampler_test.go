@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/models"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+var testSampledStoragePolicy = policy.NewStoragePolicy(time.Minute, xtime.Second, 48*time.Hour)
+
+func newTestSamplingTags(name, env string, i int) models.Tags {
+	return models.NewTags(3, nil).AddTags([]models.Tag{
+		{Name: []byte("__name__"), Value: []byte(name)},
+		{Name: []byte("env"), Value: []byte(env)},
+		{Name: []byte("instance"), Value: []byte(fmt.Sprintf("host-%d", i))},
+	})
+}
+
+func TestWriteSamplingConfigurationNoRules(t *testing.T) {
+	sampler, err := WriteSamplingConfiguration{}.NewWriteSampler(tally.NoopScope)
+	require.NoError(t, err)
+	require.Nil(t, sampler)
+}
+
+func TestWriteSamplingConfigurationInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule WriteSamplingRuleConfiguration
+	}{
+		{
+			name: "sample rate too large",
+			rule: WriteSamplingRuleConfiguration{
+				SampleRate:    1.5,
+				StoragePolicy: testSampledStoragePolicy,
+			},
+		},
+		{
+			name: "no storage policy",
+			rule: WriteSamplingRuleConfiguration{
+				SampleRate: 0.5,
+			},
+		},
+		{
+			name: "invalid filter",
+			rule: WriteSamplingRuleConfiguration{
+				Filter:        "env",
+				SampleRate:    0.5,
+				StoragePolicy: testSampledStoragePolicy,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := WriteSamplingConfiguration{
+				Rules: []WriteSamplingRuleConfiguration{tt.rule},
+			}.NewWriteSampler(tally.NoopScope)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWriteSamplerSampledStoragePolicies(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	sampler, err := WriteSamplingConfiguration{
+		Rules: []WriteSamplingRuleConfiguration{
+			{
+				Filter:        "__name__:http_* env:prod",
+				SampleRate:    0.1,
+				StoragePolicy: testSampledStoragePolicy,
+			},
+		},
+	}.NewWriteSampler(scope)
+	require.NoError(t, err)
+
+	const numSeries = 10000
+	sampled := 0
+	for i := 0; i < numSeries; i++ {
+		tags := newTestSamplingTags("http_requests", "prod", i)
+		policies := sampler.SampledStoragePolicies(nil, tags)
+		if len(policies) > 0 {
+			require.Equal(t, []policy.StoragePolicy{testSampledStoragePolicy}, policies)
+			sampled++
+		}
+
+		// Sampling must be consistent for the same series.
+		assert.Equal(t, policies, sampler.SampledStoragePolicies(nil, tags))
+
+		// Series not matching the filter are never sampled.
+		assert.Empty(t, sampler.SampledStoragePolicies(nil,
+			newTestSamplingTags("http_requests", "staging", i)))
+		assert.Empty(t, sampler.SampledStoragePolicies(nil,
+			newTestSamplingTags("rpc_requests", "prod", i)))
+	}
+
+	assert.InDelta(t, 0.1, float64(sampled)/numSeries, 0.02)
+
+	counters := scope.Snapshot().Counters()
+	tags := "+storage-policy=" + testSampledStoragePolicy.String()
+	require.Contains(t, counters, "write-sampler.sampled"+tags)
+	require.Contains(t, counters, "write-sampler.dropped"+tags)
+	// Every series is sampled twice to check consistency.
+	assert.Equal(t, int64(2*sampled), counters["write-sampler.sampled"+tags].Value())
+	assert.Equal(t, int64(2*(numSeries-sampled)), counters["write-sampler.dropped"+tags].Value())
+}
+
+func TestWriteSamplerSampleRateBounds(t *testing.T) {
+	sampler, err := WriteSamplingConfiguration{
+		Rules: []WriteSamplingRuleConfiguration{
+			{
+				SampleRate:    0,
+				StoragePolicy: policy.NewStoragePolicy(time.Minute, xtime.Second, time.Hour),
+			},
+			{
+				SampleRate:    1,
+				StoragePolicy: testSampledStoragePolicy,
+			},
+		},
+	}.NewWriteSampler(tally.NoopScope)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		policies := sampler.SampledStoragePolicies(nil, newTestSamplingTags("foo", "prod", i))
+		require.Equal(t, []policy.StoragePolicy{testSampledStoragePolicy}, policies)
+	}
+}
@@ -893,7 +893,7 @@ func newTestDownsamplerAndWriterWithEnabled(
 	}
 	downsampler := downsample.NewMockDownsampler(ctrl)
 	downsampler.EXPECT().Enabled().Return(enabled)
-	return NewDownsamplerAndWriter(storage, downsampler, testWorkerPool,
+	return NewDownsamplerAndWriter(storage, downsampler, testWorkerPool, nil,
 		instrument.NewOptions()).(*downsamplerAndWriter), downsampler, session
 }
 
@@ -906,7 +906,7 @@ func newTestDownsamplerAndWriterWithAggregatedNamespace(
 		t, ctrl, aggregatedNamespaces)
 	downsampler := downsample.NewMockDownsampler(ctrl)
 	downsampler.EXPECT().Enabled().Return(true)
-	return NewDownsamplerAndWriter(storage, downsampler, testWorkerPool,
+	return NewDownsamplerAndWriter(storage, downsampler, testWorkerPool, nil,
 		instrument.NewOptions()).(*downsamplerAndWriter), downsampler, session
 }
 
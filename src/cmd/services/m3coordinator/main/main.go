@@ -23,15 +23,25 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/query/server"
 	xconfig "github.com/m3db/m3/src/x/config"
 	"github.com/m3db/m3/src/x/config/configflag"
+	"github.com/m3db/m3/src/x/config/configvalidate"
 )
 
 func main() {
+	if configvalidate.IsCommand(os.Args) {
+		var cfg config.Configuration
+		configvalidate.Main("m3coordinator", os.Args, &cfg,
+			func(r *configvalidate.Report, opts configvalidate.Options) {
+				cfg.Check("", r, opts)
+			})
+	}
+
 	var cfgOpts configflag.Options
 	cfgOpts.Register()
 
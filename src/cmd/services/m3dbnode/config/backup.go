@@ -0,0 +1,56 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+var errBackupDirectoryRequired = errors.New("backup directory is required when backups are enabled")
+
+// BackupConfiguration is the configuration for cluster wide backups of
+// namespaces and point-in-time restores of them into new namespaces.
+type BackupConfiguration struct {
+	// Enabled enables the backup coordinator and agent on the node.
+	Enabled bool `yaml:"enabled"`
+
+	// Directory is the directory backups are uploaded to and restored from,
+	// typically a mount of a remote file system or object storage bucket
+	// shared by all nodes.
+	Directory string `yaml:"directory"`
+
+	// CheckInterval is how often pending backups and restores are checked.
+	// Default is 10s.
+	CheckInterval time.Duration `yaml:"checkInterval" validate:"min=0"`
+
+	// Timeout is how long backups and restores may remain pending before
+	// they are failed. Default is 1h.
+	Timeout time.Duration `yaml:"timeout" validate:"min=0"`
+}
+
+// Validate validates the backup configuration.
+func (c BackupConfiguration) Validate() error {
+	if c.Enabled && c.Directory == "" {
+		return errBackupDirectoryRequired
+	}
+	return nil
+}
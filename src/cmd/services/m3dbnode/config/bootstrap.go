@@ -75,10 +75,20 @@ var (
 		uninitialized.UninitializedTopologyBootstrapperName,
 	}
 
+	// bootstrapper order where only filesets on disk are loaded, used by
+	// read-only nodes serving historical data.
+	filesystemOnlyOrderedBootstrappers = []string{
+		bfs.FileSystemBootstrapperName,
+		// Anything not on disk is considered fulfilled, there are no peers or
+		// commitlogs to recover from.
+		bootstrapper.NoOpAllBootstrapperName,
+	}
+
 	validBootstrapModes = []BootstrapMode{
 		DefaultBootstrapMode,
 		PreferPeersBootstrapMode,
 		ExcludeCommitLogBootstrapMode,
+		FilesystemOnlyBootstrapMode,
 	}
 
 	errReadBootstrapModeInvalid = errors.New("bootstrap mode invalid")
@@ -94,6 +104,8 @@ const (
 	PreferPeersBootstrapMode
 	// ExcludeCommitLogBootstrapMode executes all default bootstrappers except commitlog.
 	ExcludeCommitLogBootstrapMode
+	// FilesystemOnlyBootstrapMode executes only the filesystem bootstrapper.
+	FilesystemOnlyBootstrapMode
 )
 
 // MarshalYAML marshals a BootstrapMode.
@@ -133,6 +145,8 @@ func (m BootstrapMode) String() string {
 		return "prefer_peers"
 	case ExcludeCommitLogBootstrapMode:
 		return "exclude_commitlog"
+	case FilesystemOnlyBootstrapMode:
+		return "filesystem_only"
 	}
 	return "unknown"
 }
@@ -431,6 +445,8 @@ func (bsc BootstrapConfiguration) orderedBootstrappers() []string {
 			return preferPeersOrderedBootstrappers
 		case ExcludeCommitLogBootstrapMode:
 			return excludeCommitLogOrderedBootstrappers
+		case FilesystemOnlyBootstrapMode:
+			return filesystemOnlyOrderedBootstrappers
 		}
 	}
 	return defaultOrderedBootstrappers
@@ -33,13 +33,18 @@ import (
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/discovery"
 	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/health"
+	"github.com/m3db/m3/src/dbnode/network/server/probe"
+	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/x/config/hostid"
 	"github.com/m3db/m3/src/x/debug/config"
+	"github.com/m3db/m3/src/x/fault"
 	"github.com/m3db/m3/src/x/instrument"
 	xlog "github.com/m3db/m3/src/x/log"
 	"github.com/m3db/m3/src/x/opentracing"
+	"github.com/m3db/m3/src/x/sampler"
 
 	"github.com/m3dbx/vellum/regexp"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
@@ -149,6 +154,31 @@ type DBConfiguration struct {
 	// Transforms configuration.
 	Transforms TransformConfiguration `yaml:"transforms"`
 
+	// Preflight configuration.
+	Preflight *PreflightConfiguration `yaml:"preflight"`
+
+	// ReadOnly configuration.
+	ReadOnly *ReadOnlyConfiguration `yaml:"readOnly"`
+
+	// Backup configuration.
+	Backup *BackupConfiguration `yaml:"backup"`
+
+	// Health configuration for reporting the node as degraded when commit
+	// log or flush thresholds are exceeded.
+	Health *health.Configuration `yaml:"health"`
+
+	// Probes configuration for the liveness and readiness probes.
+	Probes *probe.Configuration `yaml:"probes"`
+
+	// CodecStats configuration.
+	CodecStats *CodecStatsConfiguration `yaml:"codecStats"`
+
+	// ReadAgeStats configuration.
+	ReadAgeStats *ReadAgeStatsConfiguration `yaml:"readAgeStats"`
+
+	// FaultInjection configuration, only for testing recovery paths.
+	FaultInjection *fault.Configuration `yaml:"faultInjection"`
+
 	// Logging configuration.
 	Logging *xlog.Configuration `yaml:"logging"`
 
@@ -170,6 +200,10 @@ type DBConfiguration struct {
 	// The host and port on which to listen for debug endpoints.
 	DebugListenAddress *string `yaml:"debugListenAddress"`
 
+	// The HTTP host and port on which to listen for the node admin REST
+	// endpoints, the admin endpoints are disabled if not set.
+	HTTPAdminListenAddress *string `yaml:"httpAdminListenAddress"`
+
 	// HostID is the local host ID configuration.
 	HostID *hostid.Configuration `yaml:"hostID"`
 
@@ -393,6 +427,36 @@ func (c *DBConfiguration) Validate() error {
 		return err
 	}
 
+	if c.Preflight != nil {
+		if err := c.Preflight.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.ReadOnly != nil {
+		if err := c.ReadOnly.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Backup != nil {
+		if err := c.Backup.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.CodecStats != nil {
+		if err := c.CodecStats.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.ReadAgeStats != nil {
+		if err := c.ReadAgeStats.Validate(); err != nil {
+			return err
+		}
+	}
+
 	if c.Replication != nil {
 		if err := c.Replication.Validate(); err != nil {
 			return err
@@ -440,6 +504,43 @@ type IndexConfiguration struct {
 	// block boundaries by eagerly writing the series to the next block
 	// preemptively.
 	ForwardIndexThreshold float64 `yaml:"forwardIndexThreshold" validate:"min=0.0,max=1.0"`
+
+	// TimeWindowCompaction merges the flushed segments of sealed index blocks
+	// into coarser time windows in the background, reducing the number of
+	// segments searched by queries over wide time ranges.
+	TimeWindowCompaction *IndexTimeWindowCompactionConfiguration `yaml:"timeWindowCompaction"`
+}
+
+// IndexTimeWindowCompactionConfiguration is the configuration for merging
+// the flushed segments of sealed index blocks into coarser time windows.
+type IndexTimeWindowCompactionConfiguration struct {
+	// Enabled determines whether sealed index blocks are merged into windows.
+	Enabled bool `yaml:"enabled"`
+
+	// Window is the size of each time window, it must be a multiple of the
+	// index block size of a namespace for the namespace to be merged.
+	// Default is one week.
+	Window time.Duration `yaml:"window" validate:"min=0"`
+
+	// Interval is how often to check for time windows to merge.
+	// Default is 10m.
+	Interval time.Duration `yaml:"interval" validate:"min=0"`
+}
+
+// Options returns the index time window compaction options.
+func (c *IndexTimeWindowCompactionConfiguration) Options() index.TimeWindowCompactionOptions {
+	opts := index.NewTimeWindowCompactionOptions()
+	if c == nil {
+		return opts
+	}
+	opts.Enabled = c.Enabled
+	if c.Window > 0 {
+		opts.Window = c.Window
+	}
+	if c.Interval > 0 {
+		opts.Interval = c.Interval
+	}
+	return opts
 }
 
 // RegexpDFALimitOrDefault returns the deterministic finite automaton states
@@ -468,6 +569,9 @@ type TransformConfiguration struct {
 	TruncateBy series.TruncateType `yaml:"truncateBy"`
 	// ForcedValue determines what to set all incoming write values to.
 	ForcedValue *float64 `yaml:"forceValue"`
+	// SampleIntervals sets per namespace minimum intervals between samples
+	// of a series and how writes arriving sooner are handled.
+	SampleIntervals []SampleIntervalConfiguration `yaml:"sampleIntervals"`
 }
 
 // Validate validates the transform configuration.
@@ -476,7 +580,124 @@ func (c *TransformConfiguration) Validate() error {
 		return nil
 	}
 
-	return c.TruncateBy.Validate()
+	if err := c.TruncateBy.Validate(); err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(c.SampleIntervals))
+	for _, interval := range c.SampleIntervals {
+		if err := interval.Validate(); err != nil {
+			return err
+		}
+		if _, ok := seen[interval.Namespace]; ok {
+			return fmt.Errorf("duplicate sample interval for namespace: %s",
+				interval.Namespace)
+		}
+		seen[interval.Namespace] = struct{}{}
+	}
+
+	return nil
+}
+
+// CodecStatsConfiguration is the configuration for gathering per namespace
+// codec stats by benchmarking a sample of the blocks flushed by each series.
+type CodecStatsConfiguration struct {
+	// SampleRate is the fraction of flushed blocks that are decoded and
+	// re-encoded to gather codec stats, zero disables it.
+	SampleRate *float64 `yaml:"sampleRate"`
+}
+
+// Validate validates the codec stats configuration.
+func (c *CodecStatsConfiguration) Validate() error {
+	if v := c.SampleRateOrDefault(); v < 0 || v > 1 {
+		return fmt.Errorf("codec stats sample rate must be between 0 and 1: actual=%v", v)
+	}
+	return nil
+}
+
+// SampleRateOrDefault returns the codec stats sample rate or the default.
+func (c *CodecStatsConfiguration) SampleRateOrDefault() float64 {
+	if c == nil || c.SampleRate == nil {
+		return series.DefaultCodecStatsSampleRate
+	}
+	return *c.SampleRate
+}
+
+// ReadAgeStatsConfiguration is the configuration for tracking the age of the
+// data read from each namespace, to tell how far back reads actually go.
+type ReadAgeStatsConfiguration struct {
+	// Enabled enables read age stats, defaults to true.
+	Enabled *bool `yaml:"enabled"`
+
+	// Buckets are the upper bounds of the age buckets reads are tracked by,
+	// reads of data older than the largest are tracked in an unbounded one.
+	Buckets []time.Duration `yaml:"buckets"`
+}
+
+// Validate validates the read age stats configuration.
+func (c *ReadAgeStatsConfiguration) Validate() error {
+	return series.ValidateReadAgeBuckets(c.Buckets)
+}
+
+// BucketsOrDefault returns the read age buckets or the default, nil if read
+// age stats are disabled.
+func (c *ReadAgeStatsConfiguration) BucketsOrDefault() []time.Duration {
+	if c == nil {
+		return series.DefaultReadAgeBuckets
+	}
+	if c.Enabled != nil && !*c.Enabled {
+		return nil
+	}
+	if len(c.Buckets) == 0 {
+		return series.DefaultReadAgeBuckets
+	}
+	return c.Buckets
+}
+
+// SampleIntervalPolicies returns the sample interval policies keyed by
+// namespace ID.
+func (c *TransformConfiguration) SampleIntervalPolicies() map[string]series.SampleIntervalPolicy {
+	if c == nil || len(c.SampleIntervals) == 0 {
+		return nil
+	}
+
+	policies := make(map[string]series.SampleIntervalPolicy, len(c.SampleIntervals))
+	for _, interval := range c.SampleIntervals {
+		policies[interval.Namespace] = interval.SampleIntervalPolicy()
+	}
+	return policies
+}
+
+// SampleIntervalConfiguration is the minimum sample interval configuration
+// for a single namespace.
+type SampleIntervalConfiguration struct {
+	// Namespace is the ID of the namespace the interval applies to.
+	Namespace string `yaml:"namespace"`
+	// MinInterval is the minimum interval between samples of a series.
+	MinInterval time.Duration `yaml:"minInterval"`
+	// Mode determines how samples written sooner than the minimum
+	// interval are handled.
+	Mode series.SampleIntervalMode `yaml:"mode"`
+}
+
+// SampleIntervalPolicy returns the series sample interval policy.
+func (c SampleIntervalConfiguration) SampleIntervalPolicy() series.SampleIntervalPolicy {
+	return series.SampleIntervalPolicy{
+		MinInterval: c.MinInterval,
+		Mode:        c.Mode,
+	}
+}
+
+// Validate validates the sample interval configuration.
+func (c SampleIntervalConfiguration) Validate() error {
+	if c.Namespace == "" {
+		return errors.New("sample interval namespace must be set")
+	}
+	if c.MinInterval <= 0 {
+		return fmt.Errorf("sample interval for namespace %s must be positive: %v",
+			c.Namespace, c.MinInterval)
+	}
+	return c.SampleIntervalPolicy().Validate()
 }
 
 // TickConfiguration is the tick configuration for background processing of
@@ -516,6 +737,11 @@ type BlockRetrievePolicy struct {
 	// CacheBlocksOnRetrieve globally enables/disables callbacks used to cache blocks fetched
 	// from disk.
 	CacheBlocksOnRetrieve *bool `yaml:"cacheBlocksOnRetrieve"`
+
+	// DeadlinePriorityThreshold is how close to its deadline a queued request
+	// must be to be fetched from disk ahead of other queued requests, zero
+	// disables prioritizing requests by deadline.
+	DeadlinePriorityThreshold *time.Duration `yaml:"deadlinePriorityThreshold"`
 }
 
 // CommitLogPolicy is the commit log policy.
@@ -590,6 +816,14 @@ type RepairPolicy struct {
 	// Concurrency sets the repair shard concurrency if set.
 	Concurrency int `yaml:"concurrency"`
 
+	// PriorityRepairsPerRun sets the maximum number of namespace, shard and
+	// block start tuples repaired per run when using the priority strategy.
+	PriorityRepairsPerRun int `yaml:"priorityRepairsPerRun"`
+
+	// PriorityRepairsPerSecond sets the maximum rate at which namespace, shard
+	// and block start tuples are repaired when using the priority strategy.
+	PriorityRepairsPerSecond float64 `yaml:"priorityRepairsPerSecond"`
+
 	// Whether debug shadow comparisons are enabled.
 	DebugShadowComparisonsEnabled bool `yaml:"debugShadowComparisonsEnabled"`
 
@@ -647,6 +881,10 @@ func (r *ReplicatedCluster) Validate() error {
 type HashingConfiguration struct {
 	// Murmur32 seed value.
 	Seed uint32 `yaml:"seed"`
+
+	// ShardIDVerifySampleRate is the rate at which the shard IDs precomputed
+	// by clients on tagged writes are verified against the hash of the ID.
+	ShardIDVerifySampleRate *sampler.Rate `yaml:"shardIDVerifySampleRate"`
 }
 
 // ProtoConfiguration is the configuration for running with ProtoDataMode enabled.
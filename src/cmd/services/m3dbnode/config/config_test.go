@@ -23,18 +23,22 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/environment"
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/bootstrapper/commitlog"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/topology"
 	xconfig "github.com/m3db/m3/src/x/config"
 	"github.com/m3db/m3/src/x/instrument"
 	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -345,9 +349,19 @@ func TestConfiguration(t *testing.T) {
     regexpFSALimit: null
     forwardIndexProbability: 0
     forwardIndexThreshold: 0
+    timeWindowCompaction: null
   transforms:
     truncateBy: none
     forceValue: null
+    sampleIntervals: []
+  preflight: null
+  readOnly: null
+  backup: null
+  health: null
+  probes: null
+  codecStats: null
+  readAgeStats: null
+  faultInjection: null
   logging:
     file: /var/log/m3dbnode.log
     level: info
@@ -370,6 +384,7 @@ func TestConfiguration(t *testing.T) {
   httpNodeListenAddress: 0.0.0.0:9002
   httpClusterListenAddress: 0.0.0.0:9003
   debugListenAddress: 0.0.0.0:9004
+  httpAdminListenAddress: null
   hostID:
     resolver: config
     value: host1
@@ -391,6 +406,8 @@ func TestConfiguration(t *testing.T) {
       maxRetries: 2
       forever: null
       jitter: true
+      budget: null
+      deadlineAware: null
     fetchRetry:
       initialBackoff: 500ms
       backoffFactor: 2
@@ -398,6 +415,9 @@ func TestConfiguration(t *testing.T) {
       maxRetries: 3
       forever: null
       jitter: true
+      budget: null
+      deadlineAware: null
+    retryBudget: null
     logErrorSampleRate: 0
     logHostWriteErrorSampleRate: 0
     logHostFetchErrorSampleRate: 0
@@ -409,12 +429,19 @@ func TestConfiguration(t *testing.T) {
     asyncWriteWorkerPoolSize: null
     asyncWriteMaxConcurrency: null
     useV2BatchAPIs: null
+    writeShardIDEnabled: null
+    writeWaitForIndex: null
+    fetchChecksumVerification: null
     writeTimestampOffset: null
     fetchSeriesBlocksBatchConcurrency: null
     fetchSeriesBlocksBatchSize: null
     writeShardsInitializing: null
     shardsLeavingCountTowardsConsistency: null
     iterateEqualTimestampStrategy: null
+    readRepair: null
+    readZone: ""
+    writeFanout: null
+    peerStream: null
   gcPercentage: 100
   tick: null
   bootstrap:
@@ -453,6 +480,12 @@ func TestConfiguration(t *testing.T) {
     force_index_summaries_mmap_memory: true
     force_bloom_filter_mmap_memory: true
     bloomFilterFalsePositivePercent: null
+    encryption: null
+    compression: null
+    ioHealth: null
+    dataDirectories: null
+    flushConcurrency: null
+    diskRateLimit: null
   commitlog:
     flushMaxBytes: 524288
     flushEvery: 1s
@@ -468,6 +501,8 @@ func TestConfiguration(t *testing.T) {
     throttle: 2m0s
     checkInterval: 1m0s
     concurrency: 0
+    priorityRepairsPerRun: 0
+    priorityRepairsPerSecond: 0
     debugShadowComparisonsEnabled: false
     debugShadowComparisonsPercentage: 0
   replication: null
@@ -643,10 +678,12 @@ func TestConfiguration(t *testing.T) {
             - 1.1.1.3:2379
             keepAlive: null
             tls: null
+            auth: null
             autoSyncInterval: 0s
             dialTimeout: 0s
           m3sd:
             initTimeout: null
+            advertisementRetry: null
           watchWithRevision: 0
           newDirectoryMode: null
           retry:
@@ -656,11 +693,28 @@ func TestConfiguration(t *testing.T) {
             maxRetries: 0
             forever: null
             jitter: null
+            budget: null
+            deadlineAware: null
           requestTimeout: 0s
           watchChanInitTimeout: 0s
           watchChanCheckInterval: 0s
           watchChanResetInterval: 0s
           enableFastGets: false
+          rateLimit:
+            rate: 0
+            burst: 0
+            children: {}
+          circuitBreaker:
+            window: 0s
+            probeInterval: 0s
+          valueCompression:
+            type: ""
+            threshold: 0
+          valueChunkSize: 0
+          kvAudit: null
+          readOnly: false
+          zookeeper: null
+          consul: null
       statics: []
       seedNodes:
         rootDir: /var/lib/etcd
@@ -698,6 +752,7 @@ func TestConfiguration(t *testing.T) {
           autoTls: false
   hashing:
     seed: 42
+    shardIDVerifySampleRate: null
   writeNewSeriesAsync: true
   writeNewSeriesBackoffDuration: 2ms
   proto: null
@@ -759,6 +814,7 @@ func TestConfiguration(t *testing.T) {
     maxOutstandingRepairedBytes: 0
     maxEncodersPerBlock: 0
     writeNewSeriesPerSecond: 0
+    readLanes: null
   tchannel: null
   debug:
     mutexProfileFraction: 0
@@ -1078,6 +1134,105 @@ db:
 	require.NoError(t, err)
 }
 
+func TestTransformConfigurationSampleIntervals(t *testing.T) {
+	var cfg TransformConfiguration
+	require.NoError(t, yaml.Unmarshal([]byte(`
+sampleIntervals:
+  - namespace: metrics_10s
+    minInterval: 10s
+    mode: average
+  - namespace: metrics_1m
+    minInterval: 1m
+`), &cfg))
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, map[string]series.SampleIntervalPolicy{
+		"metrics_10s": {
+			MinInterval: 10 * time.Second,
+			Mode:        series.SampleIntervalAverage,
+		},
+		"metrics_1m": {
+			MinInterval: time.Minute,
+			Mode:        series.SampleIntervalReject,
+		},
+	}, cfg.SampleIntervalPolicies())
+
+	cfg.SampleIntervals = append(cfg.SampleIntervals, SampleIntervalConfiguration{
+		Namespace:   "metrics_1m",
+		MinInterval: time.Minute,
+	})
+	require.Error(t, cfg.Validate())
+
+	cfg.SampleIntervals = []SampleIntervalConfiguration{{Namespace: "metrics_1m"}}
+	require.Error(t, cfg.Validate())
+
+	require.Error(t, yaml.Unmarshal([]byte(`
+sampleIntervals:
+  - namespace: metrics_1m
+    minInterval: 1m
+    mode: unknown
+`), &cfg))
+}
+
+func TestReadOnlyConfiguration(t *testing.T) {
+	var cfg ReadOnlyConfiguration
+	require.NoError(t, yaml.Unmarshal([]byte(`
+enabled: true
+namespaces:
+  - namespace: metrics_archive
+    start: 2021-01-01T00:00:00Z
+    end: 2021-02-01T00:00:00Z
+  - namespace: metrics_1h
+`), &cfg))
+	require.NoError(t, cfg.Validate())
+	require.True(t, cfg.Enabled)
+	require.Equal(t, []string{"metrics_archive", "metrics_1h"}, cfg.NamespaceIDs())
+
+	start := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, map[string]xtime.Range{
+		"metrics_archive": {
+			Start: xtime.ToUnixNano(start),
+			End:   xtime.ToUnixNano(end),
+		},
+		"metrics_1h": {
+			Start: 0,
+			End:   xtime.UnixNano(math.MaxInt64),
+		},
+	}, cfg.TimeRanges())
+
+	cfg.Namespaces = append(cfg.Namespaces, ReadOnlyNamespaceConfiguration{
+		Namespace: "metrics_1h",
+	})
+	require.Error(t, cfg.Validate())
+
+	cfg.Namespaces = []ReadOnlyNamespaceConfiguration{
+		{Namespace: "metrics_archive", Start: &end, End: &start},
+	}
+	require.Error(t, cfg.Validate())
+
+	require.Nil(t, ReadOnlyConfiguration{Enabled: true}.NamespaceIDs())
+}
+
+func TestReadAgeStatsConfiguration(t *testing.T) {
+	var nilCfg *ReadAgeStatsConfiguration
+	require.Equal(t, series.DefaultReadAgeBuckets, nilCfg.BucketsOrDefault())
+
+	var cfg ReadAgeStatsConfiguration
+	require.NoError(t, yaml.Unmarshal([]byte(`
+buckets: [1h, 24h, 720h]
+`), &cfg))
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, []time.Duration{time.Hour, 24 * time.Hour, 720 * time.Hour},
+		cfg.BucketsOrDefault())
+
+	disabled := false
+	cfg.Enabled = &disabled
+	require.Nil(t, cfg.BucketsOrDefault())
+
+	cfg.Buckets = []time.Duration{24 * time.Hour, time.Hour}
+	require.Error(t, cfg.Validate())
+}
+
 func TestConfigurationComponents(t *testing.T) {
 	testConfDB := `
 db: {}
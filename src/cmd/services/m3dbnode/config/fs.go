@@ -23,6 +23,15 @@ package config
 import (
 	"fmt"
 	"os"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs/compression"
+	"github.com/m3db/m3/src/dbnode/persist/fs/datadirs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/encryption"
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
+	"github.com/m3db/m3/src/dbnode/ratelimit"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/x/instrument"
+	xratelimit "github.com/m3db/m3/src/x/ratelimit"
 )
 
 const (
@@ -98,6 +107,33 @@ type FilesystemConfiguration struct {
 	// BloomFilterFalsePositivePercent controls the target false positive percentage
 	// for the bloom filters for the fileset files.
 	BloomFilterFalsePositivePercent *float64 `yaml:"bloomFilterFalsePositivePercent"`
+
+	// Encryption is the configuration for encrypting the data and index
+	// files of data filesets at rest, index segment filesets are not
+	// encrypted.
+	Encryption *encryption.Configuration `yaml:"encryption"`
+
+	// Compression is the configuration for compressing the data files of
+	// filesets written at flush.
+	Compression *compression.Configuration `yaml:"compression"`
+
+	// IOHealth is the configuration for tracking the IO latency and health
+	// of the data directories.
+	IOHealth *iohealth.Configuration `yaml:"ioHealth"`
+
+	// DataDirectories is the configuration for placing shard directories
+	// across multiple data directories, typically one per disk.
+	DataDirectories *datadirs.Configuration `yaml:"dataDirectories"`
+
+	// FlushConcurrency is the number of shards of a namespace that are
+	// flushed or snapshotted concurrently.
+	FlushConcurrency *FlushConcurrencyConfiguration `yaml:"flushConcurrency"`
+
+	// DiskRateLimit is the configuration for limiting the bytes per second
+	// written to disk or streamed from peers, the flush, repair and
+	// peer-streaming children share the budget of the limit. Rates can be
+	// adjusted at runtime in kv.
+	DiskRateLimit *xratelimit.Configuration `yaml:"diskRateLimit"`
 }
 
 // Validate validates the Filesystem configuration. We use this method to validate
@@ -145,6 +181,12 @@ func (f FilesystemConfiguration) Validate() error {
 			*f.BloomFilterFalsePositivePercent)
 	}
 
+	if f.FlushConcurrency != nil {
+		if err := f.FlushConcurrency.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -320,3 +362,96 @@ func (f FilesystemConfiguration) ParseNewDirectoryMode() (os.FileMode, error) {
 	}
 	return os.ModeDir | os.FileMode(v), nil
 }
+
+// EncryptionOptions returns the fileset file encryption options.
+func (f FilesystemConfiguration) EncryptionOptions() (encryption.Options, error) {
+	if f.Encryption == nil {
+		return encryption.NewOptions(), nil
+	}
+	return f.Encryption.NewOptions()
+}
+
+// CompressionOptions returns the fileset data file compression options.
+func (f FilesystemConfiguration) CompressionOptions() (compression.Options, error) {
+	if f.Compression == nil {
+		return compression.NewOptions(), nil
+	}
+	return f.Compression.NewOptions()
+}
+
+// IOHealthTracker returns the tracker of the IO health of the data directories.
+func (f FilesystemConfiguration) IOHealthTracker(
+	iOpts instrument.Options,
+) (iohealth.Tracker, error) {
+	var cfg iohealth.Configuration
+	if f.IOHealth != nil {
+		cfg = *f.IOHealth
+	}
+	return cfg.NewTracker(iOpts)
+}
+
+// DataDirectoryLayout returns the layout of shard directories across the data
+// directories.
+func (f FilesystemConfiguration) DataDirectoryLayout(
+	newDirectoryMode os.FileMode,
+	ioHealthTracker iohealth.Tracker,
+	iOpts instrument.Options,
+) (datadirs.Layout, error) {
+	var cfg datadirs.Configuration
+	if f.DataDirectories != nil {
+		cfg = *f.DataDirectories
+	}
+	return cfg.NewLayout(f.FilePathPrefixOrDefault(), newDirectoryMode,
+		ioHealthTracker, iOpts)
+}
+
+// DiskRateLimiter returns the limiter of the bytes per second written to disk
+// or streamed from peers, unlimited unless configured.
+func (f FilesystemConfiguration) DiskRateLimiter(opts xratelimit.Options) xratelimit.Limiter {
+	var cfg xratelimit.Configuration
+	if f.DiskRateLimit != nil {
+		cfg = *f.DiskRateLimit
+	}
+	return cfg.NewLimiter(ratelimit.DiskLimiterName, opts)
+}
+
+// NamespaceFlushConcurrency returns the number of shards of each namespace
+// to flush or snapshot concurrently.
+func (f FilesystemConfiguration) NamespaceFlushConcurrency() storage.NamespaceFlushConcurrency {
+	if f.FlushConcurrency == nil {
+		return storage.NamespaceFlushConcurrency{}
+	}
+	return storage.NamespaceFlushConcurrency{
+		Default:    f.FlushConcurrency.Default,
+		Namespaces: f.FlushConcurrency.Namespaces,
+	}
+}
+
+// FlushConcurrencyConfiguration is the configuration for the number of shards
+// of a namespace that are flushed or snapshotted concurrently. All concurrent
+// flushes share the throughputLimitMbps disk flush budget.
+type FlushConcurrencyConfiguration struct {
+	// Default is the concurrency for namespaces not listed in Namespaces.
+	Default int `yaml:"default"`
+
+	// Namespaces is the concurrency for specific namespaces, keyed by
+	// namespace ID.
+	Namespaces map[string]int `yaml:"namespaces"`
+}
+
+// Validate validates the flush concurrency configuration.
+func (c FlushConcurrencyConfiguration) Validate() error {
+	if c.Default < 0 {
+		return fmt.Errorf(
+			"fs flushConcurrency default is set to: %d, but must be at least 1",
+			c.Default)
+	}
+	for ns, concurrency := range c.Namespaces {
+		if concurrency < 1 {
+			return fmt.Errorf(
+				"fs flushConcurrency for namespace %s is set to: %d, but must be at least 1",
+				ns, concurrency)
+		}
+	}
+	return nil
+}
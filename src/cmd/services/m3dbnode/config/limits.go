@@ -72,6 +72,33 @@ type LimitsConfiguration struct {
 
 	// Write new series limit per second to limit overwhelming during new ID bursts.
 	WriteNewSeriesPerSecond int `yaml:"writeNewSeriesPerSecond" validate:"min=0"`
+
+	// ReadLanes separates reads of recent data held in memory from reads of
+	// historical data on disk into warm and cold lanes with their own
+	// concurrency and queue limits, so that recent data reads do not queue
+	// behind historical scans.
+	ReadLanes *ReadLanesConfiguration `yaml:"readLanes"`
+}
+
+// ReadLanesConfiguration is the configuration of the warm and cold read lanes.
+// Reads are cold if they start before the previous block of the namespace.
+type ReadLanesConfiguration struct {
+	// Warm is the lane of reads of recent data held in memory.
+	Warm ReadLaneConfiguration `yaml:"warm"`
+
+	// Cold is the lane of reads of historical data on disk.
+	Cold ReadLaneConfiguration `yaml:"cold"`
+}
+
+// ReadLaneConfiguration is the configuration of a read lane.
+type ReadLaneConfiguration struct {
+	// MaxConcurrency is the maximum number of reads executing concurrently in
+	// the lane. A value of 0 means reads in the lane are not limited.
+	MaxConcurrency int `yaml:"maxConcurrency" validate:"min=0"`
+
+	// MaxQueued is the maximum number of reads waiting to execute in the lane
+	// before further reads are rejected. A value of 0 means no maximum.
+	MaxQueued int `yaml:"maxQueued" validate:"min=0"`
 }
 
 // MaxRecentQueryResourceLimitConfiguration sets an upper limit on resources consumed by all queries
@@ -0,0 +1,110 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	defaultPreflightMinFreeDiskBytes = 1 << 30 // 1GiB
+	defaultPreflightMinNoFile        = 3000000
+	defaultPreflightMinVMMaxMapCount = 3000000
+	defaultPreflightMaxClockSkew     = 5 * time.Second
+)
+
+// PreflightConfiguration is the configuration for the checks run at startup
+// to fail fast with actionable errors rather than partway through bootstrap.
+type PreflightConfiguration struct {
+	// Enabled enables the preflight checks, a failed check stops the node
+	// from starting.
+	Enabled bool `yaml:"enabled"`
+
+	// MinFreeDiskBytes is the minimum free space required on the filesystem
+	// holding the commit logs and filesets.
+	MinFreeDiskBytes *uint64 `yaml:"minFreeDiskBytes"`
+
+	// MinNoFile is the minimum required value of RLIMIT_NOFILE.
+	MinNoFile *uint64 `yaml:"minNoFile"`
+
+	// MinMemlockBytes is the minimum required value of RLIMIT_MEMLOCK, zero
+	// skips the check.
+	MinMemlockBytes uint64 `yaml:"minMemlockBytes"`
+
+	// MinVMMaxMapCount is the minimum required value of vm.max_map_count.
+	MinVMMaxMapCount *int64 `yaml:"minVMMaxMapCount"`
+
+	// MaxClockSkew is the maximum allowed difference between the local clock
+	// and the clocks of the etcd peers.
+	MaxClockSkew *time.Duration `yaml:"maxClockSkew"`
+
+	// RequirePlacementMembership requires the node to be part of the
+	// placement before it starts.
+	RequirePlacementMembership bool `yaml:"requirePlacementMembership"`
+}
+
+// MinFreeDiskBytesOrDefault returns the configured minimum free disk space
+// or the default value otherwise.
+func (c PreflightConfiguration) MinFreeDiskBytesOrDefault() uint64 {
+	if c.MinFreeDiskBytes != nil {
+		return *c.MinFreeDiskBytes
+	}
+	return defaultPreflightMinFreeDiskBytes
+}
+
+// MinNoFileOrDefault returns the configured minimum RLIMIT_NOFILE or the
+// default value otherwise.
+func (c PreflightConfiguration) MinNoFileOrDefault() uint64 {
+	if c.MinNoFile != nil {
+		return *c.MinNoFile
+	}
+	return defaultPreflightMinNoFile
+}
+
+// MinVMMaxMapCountOrDefault returns the configured minimum vm.max_map_count
+// or the default value otherwise.
+func (c PreflightConfiguration) MinVMMaxMapCountOrDefault() int64 {
+	if c.MinVMMaxMapCount != nil {
+		return *c.MinVMMaxMapCount
+	}
+	return defaultPreflightMinVMMaxMapCount
+}
+
+// MaxClockSkewOrDefault returns the configured maximum clock skew or the
+// default value otherwise.
+func (c PreflightConfiguration) MaxClockSkewOrDefault() time.Duration {
+	if c.MaxClockSkew != nil {
+		return *c.MaxClockSkew
+	}
+	return defaultPreflightMaxClockSkew
+}
+
+// Validate validates the preflight configuration.
+func (c PreflightConfiguration) Validate() error {
+	if c.MaxClockSkew != nil && *c.MaxClockSkew <= 0 {
+		return errors.New("preflight max clock skew must be positive")
+	}
+	if c.MinVMMaxMapCount != nil && *c.MinVMMaxMapCount < 0 {
+		return errors.New("preflight min vm.max_map_count must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+// ReadOnlyConfiguration is the configuration for running the node as a
+// read-only side-car that serves historical filesets already on disk. A
+// read-only node has no commit log, rejects all writes, only bootstraps from
+// the filesystem and is expected to be registered in its own placement that
+// the coordinator reads from for cold data.
+type ReadOnlyConfiguration struct {
+	// Enabled enables read-only mode.
+	Enabled bool `yaml:"enabled"`
+
+	// Namespaces restricts the namespaces served and the time range served
+	// for each, all namespaces are served for all time if empty.
+	Namespaces []ReadOnlyNamespaceConfiguration `yaml:"namespaces"`
+}
+
+// ReadOnlyNamespaceConfiguration is the configuration for a namespace served
+// by a read-only node.
+type ReadOnlyNamespaceConfiguration struct {
+	// Namespace is the ID of the namespace.
+	Namespace string `yaml:"namespace" validate:"nonzero"`
+
+	// Start is the inclusive start of the time range served, unbounded if
+	// not set.
+	Start *time.Time `yaml:"start"`
+
+	// End is the exclusive end of the time range served, unbounded if not set.
+	End *time.Time `yaml:"end"`
+}
+
+// Validate validates the read-only configuration.
+func (c ReadOnlyConfiguration) Validate() error {
+	seen := make(map[string]struct{}, len(c.Namespaces))
+	for _, ns := range c.Namespaces {
+		if err := ns.Validate(); err != nil {
+			return err
+		}
+		if _, ok := seen[ns.Namespace]; ok {
+			return fmt.Errorf("read-only namespace %s specified more than once",
+				ns.Namespace)
+		}
+		seen[ns.Namespace] = struct{}{}
+	}
+	return nil
+}
+
+// NamespaceIDs returns the IDs of the namespaces served, nil if all
+// namespaces are served.
+func (c ReadOnlyConfiguration) NamespaceIDs() []string {
+	if len(c.Namespaces) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(c.Namespaces))
+	for _, ns := range c.Namespaces {
+		ids = append(ids, ns.Namespace)
+	}
+	return ids
+}
+
+// TimeRanges returns the time ranges served keyed by namespace ID.
+func (c ReadOnlyConfiguration) TimeRanges() map[string]xtime.Range {
+	ranges := make(map[string]xtime.Range, len(c.Namespaces))
+	for _, ns := range c.Namespaces {
+		ranges[ns.Namespace] = ns.TimeRange()
+	}
+	return ranges
+}
+
+// Validate validates the read-only namespace configuration.
+func (c ReadOnlyNamespaceConfiguration) Validate() error {
+	if c.Namespace == "" {
+		return errors.New("read-only namespace must be specified")
+	}
+	if c.Start != nil && c.End != nil && !c.Start.Before(*c.End) {
+		return fmt.Errorf("read-only namespace %s start must be before end",
+			c.Namespace)
+	}
+	return nil
+}
+
+// TimeRange returns the time range served for the namespace.
+func (c ReadOnlyNamespaceConfiguration) TimeRange() xtime.Range {
+	r := xtime.Range{
+		Start: 0,
+		End:   xtime.UnixNano(math.MaxInt64),
+	}
+	if c.Start != nil {
+		r.Start = xtime.ToUnixNano(*c.Start)
+	}
+	if c.End != nil {
+		r.End = xtime.ToUnixNano(*c.End)
+	}
+	return r
+}
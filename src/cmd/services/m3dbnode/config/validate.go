@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	etcdclient "github.com/m3db/m3/src/cluster/client/etcd"
+	"github.com/m3db/m3/src/cluster/kv"
+	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/x/config/configvalidate"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Check checks the configuration, including the retention, buffer and index
+// block sizes of the static namespaces, and adds the problems found to the
+// report. If kv references are resolved the dynamic namespaces are read from
+// kv and checked as well.
+func (c *Configuration) Check(report *configvalidate.Report, opts configvalidate.Options) {
+	if c.DB != nil {
+		c.DB.check(report, opts)
+	}
+	if c.Coordinator != nil {
+		c.Coordinator.Check("coordinator", report, opts)
+	}
+}
+
+func (c *DBConfiguration) check(report *configvalidate.Report, opts configvalidate.Options) {
+	report.Add("db", c.Validate())
+
+	hostID, err := c.HostIDOrDefault().Resolve()
+	if err != nil {
+		report.Add("db.hostID", err)
+		return
+	}
+	discoveryCfg := c.DiscoveryOrDefault()
+	envCfg, err := discoveryCfg.EnvironmentConfig(hostID)
+	if err != nil {
+		report.Add("db.discovery", err)
+		return
+	}
+	report.Add("db.discovery", envCfg.Validate())
+
+	for i, cluster := range envCfg.Statics {
+		for j, nsCfg := range cluster.Namespaces {
+			_, err := nsCfg.Metadata()
+			report.Add(fmt.Sprintf("db.discovery.statics[%d].namespaces[%d]", i, j), err)
+		}
+	}
+
+	if !opts.ResolveKV {
+		return
+	}
+	for i, cluster := range envCfg.Services {
+		if cluster.Service == nil {
+			continue
+		}
+		checkKVNamespaces(fmt.Sprintf("db.discovery.services[%d]", i),
+			*cluster.Service, report)
+	}
+}
+
+// checkKVNamespaces reads the namespaces of the cluster from kv and checks
+// each of them, nothing is written to kv.
+func checkKVNamespaces(
+	path string,
+	cfg etcdclient.Configuration,
+	report *configvalidate.Report,
+) {
+	// NB: do not write the kv cache file while validating.
+	cfg.CacheDir = ""
+	csClient, err := cfg.NewClient(instrument.NewOptions())
+	if err != nil {
+		report.Add(path, err)
+		return
+	}
+	kvStore, err := csClient.KV()
+	if err != nil {
+		report.Add(path, err)
+		return
+	}
+
+	value, err := kvStore.Get(kvconfig.NamespacesKey)
+	if err == kv.ErrNotFound {
+		return
+	}
+	if err != nil {
+		report.Add(path, fmt.Errorf("could not read namespaces from kv: %w", err))
+		return
+	}
+
+	var registry nsproto.Registry
+	if err := value.Unmarshal(&registry); err != nil {
+		report.Add(path, fmt.Errorf("could not unmarshal namespaces from kv: %w", err))
+		return
+	}
+
+	ids := make([]string, 0, len(registry.Namespaces))
+	for id := range registry.Namespaces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		_, err := namespace.ToMetadata(id, registry.Namespaces[id])
+		report.Add(fmt.Sprintf("%s.kv.%s.%s", path, kvconfig.NamespacesKey, id), err)
+	}
+}
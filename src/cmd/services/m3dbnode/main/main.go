@@ -23,15 +23,22 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 
 	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
 	"github.com/m3db/m3/src/cmd/services/m3dbnode/server"
 	xconfig "github.com/m3db/m3/src/x/config"
 	"github.com/m3db/m3/src/x/config/configflag"
+	"github.com/m3db/m3/src/x/config/configvalidate"
 	xos "github.com/m3db/m3/src/x/os"
 )
 
 func main() {
+	if configvalidate.IsCommand(os.Args) {
+		var cfg config.Configuration
+		configvalidate.Main("m3dbnode", os.Args, &cfg, cfg.Check)
+	}
+
 	var cfgOpts configflag.Options
 	cfgOpts.Register()
 
@@ -22,27 +22,36 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"time"
 
 	etcdclient "github.com/m3db/m3/src/cluster/client/etcd"
 	"github.com/m3db/m3/src/cluster/placement"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
 	ingestm3msg "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/m3msg"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/server/m3msg"
 	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/query/accesscontrol"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/audit"
+	"github.com/m3db/m3/src/query/cardinality"
 	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/querycost"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/storage/m3/consolidators"
 	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	"github.com/m3db/m3/src/query/storage/resultcache"
+	"github.com/m3db/m3/src/query/storage/shadow"
 	xconfig "github.com/m3db/m3/src/x/config"
 	"github.com/m3db/m3/src/x/debug/config"
 	"github.com/m3db/m3/src/x/instrument"
 	xlog "github.com/m3db/m3/src/x/log"
 	"github.com/m3db/m3/src/x/opentracing"
+	"github.com/m3db/m3/src/x/retry"
 	xtime "github.com/m3db/m3/src/x/time"
 )
 
@@ -131,6 +140,11 @@ type Configuration struct {
 	// query endpoints.
 	Clusters m3.ClustersStaticConfiguration `yaml:"clusters"`
 
+	// NamespaceAliases maps the previous names of renamed namespaces to the
+	// current names of the namespaces, queries restricted to a namespace
+	// by a previous name are routed to the renamed namespace.
+	NamespaceAliases map[string]string `yaml:"namespaceAliases"`
+
 	// LocalConfiguration is the local embedded configuration if running
 	// coordinator embedded in the DB.
 	Local *LocalConfiguration `yaml:"local"`
@@ -170,6 +184,34 @@ type Configuration struct {
 	// WriteForwarding is the write forwarding options.
 	WriteForwarding WriteForwardingConfiguration `yaml:"writeForwarding"`
 
+	// PrometheusRemoteWrite configures the Prometheus remote write endpoint.
+	PrometheusRemoteWrite PrometheusRemoteWriteConfiguration `yaml:"prometheusRemoteWrite"`
+
+	// WriteSampling configures forking a consistent sample of unaggregated
+	// writes into additional namespaces.
+	WriteSampling ingest.WriteSamplingConfiguration `yaml:"writeSampling"`
+
+	// WriteAudit configures logging a rate limited sample of written series
+	// that match a set of audit matchers, writes are not audited and the
+	// runtime overrides in KV are not watched if not configured.
+	WriteAudit *ingest.WriteAuditConfiguration `yaml:"writeAudit"`
+
+	// QueryAudit configures recording who queried what, for a sample of
+	// queries.
+	QueryAudit audit.Configuration `yaml:"queryAudit"`
+
+	// NamespaceProvisioning configures provisioning a namespace from a
+	// template for each new tenant seen in write traffic.
+	NamespaceProvisioning ingest.NamespaceProvisioningConfiguration `yaml:"namespaceProvisioning"`
+
+	// ShadowRead configures mirroring a sample of reads to a shadow namespace
+	// or cluster to verify that it returns the same results.
+	ShadowRead shadow.Configuration `yaml:"shadowRead"`
+
+	// ResultCache configures caching the results of reads of windows of time
+	// that are no longer receiving writes.
+	ResultCache resultcache.Configuration `yaml:"resultCache"`
+
 	// Downsample configures how the metrics should be downsampled.
 	Downsample downsample.Configuration `yaml:"downsample"`
 
@@ -179,6 +221,9 @@ type Configuration struct {
 	// Carbon is the carbon configuration.
 	Carbon *CarbonConfiguration `yaml:"carbon"`
 
+	// KafkaIngester if set consumes metrics from Kafka topics.
+	KafkaIngester *KafkaIngesterConfiguration `yaml:"kafkaIngester"`
+
 	// Middleware is middleware-specific configuration.
 	Middleware MiddlewareConfiguration `yaml:"middleware"`
 
@@ -290,12 +335,24 @@ type QueryConfiguration struct {
 	// RestrictTags is an optional configuration that can be set to restrict
 	// all queries with certain tags by.
 	RestrictTags *RestrictTagsConfiguration `yaml:"restrictTags"`
+	// AccessControl is an optional configuration that can be set to enforce
+	// tag matchers on queries based on the identity of the requester.
+	AccessControl *AccessControlConfiguration `yaml:"accessControl"`
+	// CostEstimation is an optional configuration that can be set to reject
+	// queries estimated to exceed cost thresholds before they are executed.
+	CostEstimation *QueryCostConfiguration `yaml:"costEstimation"`
+	// CardinalityAnalytics is an optional configuration for the cardinality
+	// and churn reports of namespaces, computed from the index.
+	CardinalityAnalytics *CardinalityAnalyticsConfiguration `yaml:"cardinalityAnalytics"`
 	// RequireLabelsEndpointStartEndTime requires requests to /label(s) endpoints
 	// to specify a start and end time to prevent unbounded queries.
 	RequireLabelsEndpointStartEndTime bool `yaml:"requireLabelsEndpointStartEndTime"`
 	// RequireSeriesEndpointStartEndTime requires requests to /series endpoint
 	// to specify a start and end time to prevent unbounded queries.
 	RequireSeriesEndpointStartEndTime bool `yaml:"requireSeriesEndpointStartEndTime"`
+	// Stitching is the configuration for stitching the results of the
+	// unaggregated and aggregated namespaces when a query spans both.
+	Stitching StitchingConfiguration `yaml:"stitching"`
 }
 
 // TimeoutOrDefault returns the configured timeout or default value.
@@ -339,6 +396,199 @@ type RestrictTagsConfiguration struct {
 	Strip    []string      `yaml:"strip"`
 }
 
+// StitchingConfiguration is the configuration for stitching the results of
+// the unaggregated and aggregated namespaces when a query spans both.
+type StitchingConfiguration struct {
+	// Policy is the default stitching policy, queries can override it with
+	// the M3-Stitching-Policy header.
+	Policy storage.StitchingPolicy `yaml:"policy"`
+	// Cutovers are the cut-over timestamps between namespace pairs used by
+	// the cutover stitching policy.
+	Cutovers []StitchingCutoverConfiguration `yaml:"cutovers"`
+}
+
+// StitchingCutoverConfiguration is the cut-over timestamp between the
+// unaggregated namespace and an aggregated namespace.
+type StitchingCutoverConfiguration struct {
+	// UnaggregatedNamespace is the unaggregated namespace of the pair.
+	UnaggregatedNamespace string `yaml:"unaggregatedNamespace" validate:"nonzero"`
+	// AggregatedNamespace is the aggregated namespace of the pair.
+	AggregatedNamespace string `yaml:"aggregatedNamespace" validate:"nonzero"`
+	// Cutover is the timestamp before which the aggregated namespace is read
+	// and from which the unaggregated namespace is read.
+	Cutover time.Time `yaml:"cutover" validate:"nonzero"`
+}
+
+// StitchingCutovers returns the configured cut-overs between namespace pairs.
+func (c StitchingConfiguration) StitchingCutovers() []m3.StitchingCutover {
+	if len(c.Cutovers) == 0 {
+		return nil
+	}
+	cutovers := make([]m3.StitchingCutover, 0, len(c.Cutovers))
+	for _, cutover := range c.Cutovers {
+		cutovers = append(cutovers, m3.StitchingCutover{
+			UnaggregatedNamespace: cutover.UnaggregatedNamespace,
+			AggregatedNamespace:   cutover.AggregatedNamespace,
+			Cutover:               xtime.ToUnixNano(cutover.Cutover),
+		})
+	}
+	return cutovers
+}
+
+// AccessControlConfiguration enforces tag matchers on queries based on the
+// identity of the requester.
+type AccessControlConfiguration struct {
+	// IdentityHeader is the header containing the identity of the requester,
+	// it must be set by a trusted proxy that authenticates requests.
+	IdentityHeader string `yaml:"identityHeader"`
+	// Identities are the matchers enforced on queries keyed by identity.
+	Identities map[string][]StringMatch `yaml:"identities"`
+	// Default are the matchers enforced on queries from identities that are
+	// not listed, including requests without an identity.
+	Default []StringMatch `yaml:"default"`
+	// DenyUnlisted rejects queries from identities that are not listed,
+	// including requests without an identity.
+	DenyUnlisted bool `yaml:"denyUnlisted"`
+}
+
+// NewPolicy returns the access control policy.
+func (c AccessControlConfiguration) NewPolicy() (*accesscontrol.Policy, error) {
+	policy := &accesscontrol.Policy{
+		Identities:   make(map[string]models.Matchers, len(c.Identities)),
+		DenyUnlisted: c.DenyUnlisted,
+	}
+	for identity, matches := range c.Identities {
+		matchers, err := stringMatchesToMatchers(matches)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid access control matchers for identity %s: %w", identity, err)
+		}
+		if len(matchers) == 0 {
+			return nil, fmt.Errorf(
+				"no access control matchers for identity %s", identity)
+		}
+		policy.Identities[identity] = matchers
+	}
+
+	matchers, err := stringMatchesToMatchers(c.Default)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"invalid default access control matchers: %w", err)
+	}
+	policy.Default = matchers
+
+	return policy, nil
+}
+
+// QueryCostConfiguration estimates the cost of queries from the index before
+// they are executed and rejects queries estimated to exceed the thresholds
+// of the requester, unless forced.
+type QueryCostConfiguration struct {
+	// DefaultResolution is the interval assumed between datapoints of series
+	// in namespaces without a resolution, i.e. unaggregated namespaces.
+	DefaultResolution time.Duration `yaml:"defaultResolution"`
+	// BytesPerDatapoint is the number of compressed bytes assumed per
+	// datapoint.
+	BytesPerDatapoint float64 `yaml:"bytesPerDatapoint"`
+	// Identities are the thresholds keyed by the identity of the requester,
+	// as set by the access control identity header.
+	Identities map[string]QueryCostThresholdsConfiguration `yaml:"identities"`
+	// Default are the thresholds of identities that are not listed, including
+	// requests without an identity.
+	Default QueryCostThresholdsConfiguration `yaml:"default"`
+}
+
+// QueryCostThresholdsConfiguration are the maximum estimated costs of
+// queries that are executed, zero values are not enforced.
+type QueryCostThresholdsConfiguration struct {
+	MaxSeries     int64 `yaml:"maxSeries"`
+	MaxDatapoints int64 `yaml:"maxDatapoints"`
+	MaxBytes      int64 `yaml:"maxBytes"`
+}
+
+func (c QueryCostThresholdsConfiguration) thresholds() (querycost.Thresholds, error) {
+	if c.MaxSeries < 0 || c.MaxDatapoints < 0 || c.MaxBytes < 0 {
+		return querycost.Thresholds{}, errors.New("query cost thresholds must not be negative")
+	}
+	return querycost.Thresholds{
+		MaxSeries:     c.MaxSeries,
+		MaxDatapoints: c.MaxDatapoints,
+		MaxBytes:      c.MaxBytes,
+	}, nil
+}
+
+// NewPolicy returns the query cost policy.
+func (c QueryCostConfiguration) NewPolicy() (*querycost.Policy, error) {
+	policy := &querycost.Policy{
+		Identities: make(map[string]querycost.Thresholds, len(c.Identities)),
+	}
+	for identity, cfg := range c.Identities {
+		thresholds, err := cfg.thresholds()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid query cost thresholds for identity %s: %w", identity, err)
+		}
+		policy.Identities[identity] = thresholds
+	}
+
+	thresholds, err := c.Default.thresholds()
+	if err != nil {
+		return nil, fmt.Errorf("invalid default query cost thresholds: %w", err)
+	}
+	policy.Default = thresholds
+
+	return policy, nil
+}
+
+// CardinalityAnalyticsConfiguration is the configuration for the cardinality
+// and churn reports of namespaces, zero values use the defaults.
+type CardinalityAnalyticsConfiguration struct {
+	// TopK is the number of label and metric names reported.
+	TopK int `yaml:"topK"`
+	// MaxMetricNames is the maximum number of metric names whose series are
+	// counted to find the metric names with most series.
+	MaxMetricNames int `yaml:"maxMetricNames"`
+	// Window is the time range that label and metric names are reported over.
+	Window time.Duration `yaml:"window"`
+	// ChurnHours is the number of hours that churn is reported for.
+	ChurnHours int `yaml:"churnHours"`
+	// SeriesLimit is the maximum number of series matched by each index query.
+	SeriesLimit int `yaml:"seriesLimit"`
+	// CacheTTL is the time reports are cached for.
+	CacheTTL time.Duration `yaml:"cacheTTL"`
+}
+
+// AnalyzerOptions returns the cardinality analyzer options, the options are
+// all defaults if the configuration is nil.
+func (c *CardinalityAnalyticsConfiguration) AnalyzerOptions() cardinality.AnalyzerOptions {
+	if c == nil {
+		return cardinality.AnalyzerOptions{}
+	}
+	return cardinality.AnalyzerOptions{
+		TopK:           c.TopK,
+		MaxMetricNames: c.MaxMetricNames,
+		Window:         c.Window,
+		ChurnHours:     c.ChurnHours,
+		SeriesLimit:    c.SeriesLimit,
+		CacheTTL:       c.CacheTTL,
+	}
+}
+
+func stringMatchesToMatchers(matches []StringMatch) (models.Matchers, error) {
+	opts := handleroptions.StringTagOptions{
+		Restrict: make([]handleroptions.StringMatch, 0, len(matches)),
+	}
+	for _, elem := range matches {
+		opts.Restrict = append(opts.Restrict, handleroptions.StringMatch(elem))
+	}
+
+	restrict, err := opts.StorageOptions()
+	if err != nil {
+		return nil, err
+	}
+	return restrict.GetMatchers(), nil
+}
+
 // StringMatch is an easy to use representation of models.Matcher.
 type StringMatch struct {
 	Name  string `yaml:"name"`
@@ -359,6 +609,14 @@ type PrometheusQueryConfiguration struct {
 
 	// Convert configures Prometheus time series conversions.
 	Convert *PrometheusConvertConfiguration `yaml:"convert"`
+
+	// IndexAggregationPushdown answers selectors that are only counted or
+	// grouped by labels, such as count by (label) (metric), from the index
+	// without fetching series data. Series are considered present for the
+	// whole range their index blocks overlap, so results can include series
+	// that stopped or started within the range. Falls back to fetching data
+	// when a namespace does not return all of its index matches.
+	IndexAggregationPushdown bool `yaml:"indexAggregationPushdown"`
 }
 
 // ConvertOptionsOrDefault creates storage.PromConvertOptions based on the given configuration.
@@ -403,6 +661,73 @@ type PrometheusConvertConfiguration struct {
 	ValueDecreaseToleranceUntil *time.Time `yaml:"valueDecreaseToleranceUntil"`
 }
 
+// NativeHistogramRepresentation is how native histograms received with
+// Prometheus remote write 2.0 are stored.
+type NativeHistogramRepresentation string
+
+const (
+	// BucketSeriesNativeHistogramRepresentation stores native histograms as
+	// classic histogram series, a cumulative bucket series per bucket boundary
+	// labeled le along with count and sum series.
+	BucketSeriesNativeHistogramRepresentation NativeHistogramRepresentation = "bucketSeries"
+
+	// NativeBucketsNativeHistogramRepresentation stores native histograms in
+	// their native bucket layout, a series per exponential bucket labeled with
+	// the schema and bucket index along with count, sum and zero count series
+	// so that the histogram can be reconstructed without loss. The zero count
+	// series is labeled le with the zero threshold. Histograms with custom
+	// bucket boundaries are stored as bucket series.
+	NativeBucketsNativeHistogramRepresentation NativeHistogramRepresentation = "nativeBuckets"
+)
+
+var validNativeHistogramRepresentations = []NativeHistogramRepresentation{
+	BucketSeriesNativeHistogramRepresentation,
+	NativeBucketsNativeHistogramRepresentation,
+}
+
+// UnmarshalYAML unmarshals a native histogram representation.
+func (r *NativeHistogramRepresentation) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	if str == "" {
+		*r = BucketSeriesNativeHistogramRepresentation
+		return nil
+	}
+	for _, valid := range validNativeHistogramRepresentations {
+		if str == string(valid) {
+			*r = valid
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid native histogram representation '%s' valid types are: %v",
+		str, validNativeHistogramRepresentations)
+}
+
+// PrometheusRemoteWriteConfiguration configures the Prometheus remote write
+// endpoint.
+type PrometheusRemoteWriteConfiguration struct {
+	// NativeHistograms is how native histograms received with remote write
+	// 2.0 are stored, defaults to bucket series.
+	NativeHistograms NativeHistogramRepresentation `yaml:"nativeHistograms"`
+
+	// IngestCreatedTimestamps writes a zero sample at the created timestamp
+	// of counter and histogram series received with remote write 2.0 that
+	// precedes their first sample, so that increases since creation are not
+	// lost to extrapolation.
+	IngestCreatedTimestamps bool `yaml:"ingestCreatedTimestamps"`
+}
+
+// NativeHistogramsOrDefault returns the native histogram representation or
+// default.
+func (c PrometheusRemoteWriteConfiguration) NativeHistogramsOrDefault() NativeHistogramRepresentation {
+	if c.NativeHistograms == "" {
+		return BucketSeriesNativeHistogramRepresentation
+	}
+	return c.NativeHistograms
+}
+
 // MaxSamplesPerQueryOrDefault returns the max samples per query or default.
 func (c PrometheusQueryConfiguration) MaxSamplesPerQueryOrDefault() int {
 	if v := c.MaxSamplesPerQuery; v != nil {
@@ -626,10 +951,91 @@ type PrometheusMiddlewareConfiguration struct {
 
 // CarbonIngesterConfiguration is the configuration struct for carbon ingestion.
 type CarbonIngesterConfiguration struct {
-	ListenAddress  string                             `yaml:"listenAddress"`
-	MaxConcurrency int                                `yaml:"maxConcurrency"`
-	Rewrite        CarbonIngesterRewriteConfiguration `yaml:"rewrite"`
-	Rules          []CarbonIngesterRuleConfiguration  `yaml:"rules"`
+	ListenAddress  string                                  `yaml:"listenAddress"`
+	MaxConcurrency int                                     `yaml:"maxConcurrency"`
+	Rewrite        CarbonIngesterRewriteConfiguration      `yaml:"rewrite"`
+	Rules          []CarbonIngesterRuleConfiguration       `yaml:"rules"`
+	TagMappings    []CarbonIngesterTagMappingConfiguration `yaml:"tagMappings"`
+}
+
+// KafkaIngesterConfiguration is the configuration for consuming metrics from
+// Kafka topics and writing them through the same downsampling and write path
+// as metrics received over HTTP.
+type KafkaIngesterConfiguration struct {
+	// Brokers are the addresses of the brokers used to discover the cluster.
+	Brokers []string `yaml:"brokers" validate:"nonzero"`
+
+	// ClientID identifies the coordinator to the brokers.
+	ClientID string `yaml:"clientID"`
+
+	// Group is the consumer group coordinators join to balance the
+	// partitions of the topics across them and commit offsets to.
+	Group string `yaml:"group" validate:"nonzero"`
+
+	// Topics are the topics to consume.
+	Topics []KafkaIngesterTopicConfiguration `yaml:"topics" validate:"nonzero"`
+
+	// InitialOffset is where partitions without a committed offset are
+	// consumed from, either "earliest" or "latest", defaults to "latest".
+	InitialOffset string `yaml:"initialOffset"`
+
+	// CommitInterval is how often consumed offsets are committed.
+	CommitInterval time.Duration `yaml:"commitInterval"`
+
+	// DialTimeout is the timeout for connecting to a broker.
+	DialTimeout time.Duration `yaml:"dialTimeout"`
+
+	// RequestTimeout is the timeout for requests to brokers.
+	RequestTimeout time.Duration `yaml:"requestTimeout"`
+
+	// FetchMaxWait is how long a fetch waits for records to be available.
+	FetchMaxWait time.Duration `yaml:"fetchMaxWait"`
+
+	// FetchMaxBytes is the maximum bytes returned by a fetch.
+	FetchMaxBytes int32 `yaml:"fetchMaxBytes"`
+
+	// SessionTimeout is how long the consumer group waits for a heartbeat
+	// before reassigning the partitions of a coordinator.
+	SessionTimeout time.Duration `yaml:"sessionTimeout"`
+
+	// RebalanceTimeout is how long the consumer group waits for coordinators
+	// to rejoin when it rebalances.
+	RebalanceTimeout time.Duration `yaml:"rebalanceTimeout"`
+
+	// HeartbeatInterval is how often heartbeats are sent to the consumer
+	// group, it must be lower than the session timeout.
+	HeartbeatInterval time.Duration `yaml:"heartbeatInterval"`
+
+	// Retry configures retrying writes that fail.
+	Retry retry.Configuration `yaml:"retry"`
+}
+
+// KafkaIngesterTopicConfiguration is the configuration for consuming a topic.
+type KafkaIngesterTopicConfiguration struct {
+	// Name is the name of the topic.
+	Name string `yaml:"name" validate:"nonzero"`
+
+	// Format is the format of the record values, either
+	// "prometheus_remote_write" or "influx_line_protocol".
+	Format string `yaml:"format" validate:"nonzero"`
+}
+
+// CarbonIngesterTagMappingConfiguration is the configuration for mapping the
+// path components of carbon metrics to tags, so that the metrics are stored
+// with tags queryable by PromQL in addition to their graphite path tags.
+// The first mapping with a prefix matching a metric is applied to it.
+type CarbonIngesterTagMappingConfiguration struct {
+	// Prefix is the dot separated path prefix metrics must match for the
+	// mapping to apply, each component may be a "*" wildcard, for example
+	// "servers.*.*" matches "servers.dc1.host1.cpu.user".
+	Prefix string `yaml:"prefix"`
+	// Tags maps zero based path component positions to the tag names the
+	// component values are stored as, for example {1: dc, 2: host}.
+	Tags map[int]string `yaml:"tags"`
+	// Name is the metric name set on matching metrics, if not set it is
+	// generated by joining the path components that are not mapped to tags
+	// with underscores, for example "servers_cpu_user".
+	Name string `yaml:"name"`
 }
 
 // CarbonIngesterRewriteConfiguration is the configuration for rewriting
@@ -27,6 +27,7 @@ import (
 
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/querycost"
 	"github.com/m3db/m3/src/query/storage"
 	xconfig "github.com/m3db/m3/src/x/config"
 	xtime "github.com/m3db/m3/src/x/time"
@@ -221,3 +222,65 @@ func TestKeepNaNsDefault(t *testing.T) {
 	r = ResultOptions{}
 	assert.Equal(t, false, r.KeepNaNs)
 }
+
+func TestAccessControlConfigNewPolicy(t *testing.T) {
+	var cfg AccessControlConfiguration
+	config := `
+identityHeader: X-Auth-User
+identities:
+  alice:
+    - name: team
+      type: EQUAL
+      value: X
+default:
+  - name: team
+    type: NOTEXISTS
+denyUnlisted: true
+`
+	require.NoError(t, yaml.Unmarshal([]byte(config), &cfg))
+	assert.Equal(t, "X-Auth-User", cfg.IdentityHeader)
+
+	policy, err := cfg.NewPolicy()
+	require.NoError(t, err)
+	require.Len(t, policy.Identities["alice"], 1)
+	assert.Equal(t, "team=\"X\"", policy.Identities["alice"][0].String())
+	require.Len(t, policy.Default, 1)
+	assert.Equal(t, models.MatchNotField, policy.Default[0].Type)
+	assert.True(t, policy.DenyUnlisted)
+
+	cfg.Identities["bob"] = nil
+	_, err = cfg.NewPolicy()
+	require.Error(t, err)
+
+	cfg.Identities["bob"] = []StringMatch{{Name: "team", Type: "REGEXP", Value: "("}}
+	_, err = cfg.NewPolicy()
+	require.Error(t, err)
+}
+
+func TestQueryCostConfigNewPolicy(t *testing.T) {
+	var cfg QueryCostConfiguration
+	config := `
+defaultResolution: 15s
+bytesPerDatapoint: 1.5
+identities:
+  dashboards:
+    maxSeries: 100000
+default:
+  maxSeries: 10000
+  maxBytes: 1000000
+`
+	require.NoError(t, yaml.Unmarshal([]byte(config), &cfg))
+	assert.Equal(t, 15*time.Second, cfg.DefaultResolution)
+	assert.Equal(t, 1.5, cfg.BytesPerDatapoint)
+
+	policy, err := cfg.NewPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, querycost.Thresholds{MaxSeries: 100000},
+		policy.Identities["dashboards"])
+	assert.Equal(t, querycost.Thresholds{MaxSeries: 10000, MaxBytes: 1000000},
+		policy.Default)
+
+	cfg.Default.MaxDatapoints = -1
+	_, err = cfg.NewPolicy()
+	require.Error(t, err)
+}
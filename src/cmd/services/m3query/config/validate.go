@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	"github.com/m3db/m3/src/x/config/configvalidate"
+)
+
+// Check checks the cross field constraints of the configuration that can not
+// be expressed with validation tags and adds the problems found to the
+// report, the paths of the problems are prefixed with the prefix.
+func (c *Configuration) Check(
+	prefix string,
+	report *configvalidate.Report,
+	opts configvalidate.Options,
+) {
+	type resolutionRetention struct {
+		resolution time.Duration
+		retention  time.Duration
+	}
+	var (
+		numUnaggregated int
+		aggregated      = make(map[resolutionRetention]struct{})
+	)
+	for i, cluster := range c.Clusters {
+		for j, ns := range cluster.Namespaces {
+			path := checkPath(prefix, fmt.Sprintf("clusters[%d].namespaces[%d]", i, j))
+			nsType := ns.Type
+			if nsType == storagemetadata.UnknownMetricsType {
+				nsType = storagemetadata.DefaultMetricsType
+			}
+
+			switch nsType {
+			case storagemetadata.UnaggregatedMetricsType:
+				numUnaggregated++
+			case storagemetadata.AggregatedMetricsType:
+				if ns.Resolution <= 0 {
+					report.Addf(path, "aggregated namespace %s requires a positive resolution",
+						ns.Namespace)
+					continue
+				}
+				if ns.Retention < ns.Resolution {
+					report.Addf(path, "retention %s is less than resolution %s",
+						ns.Retention, ns.Resolution)
+				}
+				aggregated[resolutionRetention{ns.Resolution, ns.Retention}] = struct{}{}
+			default:
+				report.Addf(path, "unknown storage metrics type: %v", ns.Type)
+			}
+		}
+	}
+	if len(c.Clusters) > 0 && numUnaggregated != 1 {
+		report.Addf(checkPath(prefix, "clusters"),
+			"one unaggregated cluster namespace must be specified: specified %d",
+			numUnaggregated)
+	}
+
+	rules := c.Downsample.Rules
+	if rules == nil {
+		return
+	}
+	report.Add(checkPath(prefix, "downsample.rules"), downsample.ValidateAggregationRules(*rules))
+
+	// Every storage policy of the rules needs an aggregated namespace to be
+	// written to, otherwise the aggregated metrics are dropped.
+	checkStoragePolicies := func(path string, policies []downsample.StoragePolicyConfiguration) {
+		for k, sp := range policies {
+			policyPath := checkPath(prefix, fmt.Sprintf("%s.storagePolicies[%d]", path, k))
+			if sp.Resolution <= 0 || sp.Retention < sp.Resolution {
+				report.Addf(policyPath, "invalid storage policy %s", sp.String())
+				continue
+			}
+			if len(c.Clusters) == 0 {
+				continue
+			}
+			if _, ok := aggregated[resolutionRetention{sp.Resolution, sp.Retention}]; !ok {
+				report.Addf(policyPath, "no aggregated namespace for storage policy %s",
+					sp.String())
+			}
+		}
+	}
+	for i, rule := range rules.MappingRules {
+		checkStoragePolicies(fmt.Sprintf("downsample.rules.mappingRules[%d]", i),
+			rule.StoragePolicies)
+	}
+	for i, rule := range rules.RollupRules {
+		checkStoragePolicies(fmt.Sprintf("downsample.rules.rollupRules[%d]", i),
+			rule.StoragePolicies)
+	}
+}
+
+func checkPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	return prefix + "." + path
+}
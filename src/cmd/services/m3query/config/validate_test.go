@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
+	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/query/storage/m3"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	"github.com/m3db/m3/src/x/config/configvalidate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationCheck(t *testing.T) {
+	cfg := Configuration{
+		Clusters: m3.ClustersStaticConfiguration{
+			{
+				Namespaces: []m3.ClusterStaticNamespaceConfiguration{
+					{
+						Namespace: "default",
+						Retention: 48 * time.Hour,
+					},
+					{
+						Namespace:  "agg",
+						Type:       storagemetadata.AggregatedMetricsType,
+						Retention:  30 * 24 * time.Hour,
+						Resolution: time.Minute,
+					},
+					{
+						Namespace: "agg_no_resolution",
+						Type:      storagemetadata.AggregatedMetricsType,
+						Retention: 30 * 24 * time.Hour,
+					},
+				},
+			},
+		},
+		Downsample: downsample.Configuration{
+			Rules: &downsample.RulesConfiguration{
+				MappingRules: []downsample.MappingRuleConfiguration{
+					{
+						Filter:       "app:nginx*",
+						Aggregations: []aggregation.Type{aggregation.Max},
+						StoragePolicies: []downsample.StoragePolicyConfiguration{
+							{Resolution: time.Minute, Retention: 30 * 24 * time.Hour},
+							{Resolution: 10 * time.Second, Retention: 48 * time.Hour},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var report configvalidate.Report
+	cfg.Check("coordinator", &report, configvalidate.Options{})
+	require.Equal(t, []configvalidate.Problem{
+		{
+			Path:    "coordinator.clusters[0].namespaces[2]",
+			Message: "aggregated namespace agg_no_resolution requires a positive resolution",
+		},
+		{
+			Path:    "coordinator.downsample.rules.mappingRules[0].storagePolicies[1]",
+			Message: "no aggregated namespace for storage policy 10s:48h0m0s",
+		},
+	}, report.Problems)
+}
+
+func TestConfigurationCheckUnaggregatedNamespaces(t *testing.T) {
+	cfg := Configuration{
+		Clusters: m3.ClustersStaticConfiguration{
+			{
+				Namespaces: []m3.ClusterStaticNamespaceConfiguration{
+					{
+						Namespace:  "agg",
+						Type:       storagemetadata.AggregatedMetricsType,
+						Retention:  time.Minute,
+						Resolution: time.Hour,
+					},
+				},
+			},
+		},
+	}
+
+	var report configvalidate.Report
+	cfg.Check("", &report, configvalidate.Options{})
+	require.Equal(t, []configvalidate.Problem{
+		{
+			Path:    "clusters[0].namespaces[0]",
+			Message: "retention 1m0s is less than resolution 1h0m0s",
+		},
+		{
+			Path:    "clusters",
+			Message: "one unaggregated cluster namespace must be specified: specified 0",
+		},
+	}, report.Problems)
+}
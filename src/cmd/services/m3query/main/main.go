@@ -23,14 +23,24 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/query/server"
 	xconfig "github.com/m3db/m3/src/x/config"
 	"github.com/m3db/m3/src/x/config/configflag"
+	"github.com/m3db/m3/src/x/config/configvalidate"
 )
 
 func main() {
+	if configvalidate.IsCommand(os.Args) {
+		var cfg config.Configuration
+		configvalidate.Main("m3query", os.Args, &cfg,
+			func(r *configvalidate.Report, opts configvalidate.Options) {
+				cfg.Check("", r, opts)
+			})
+	}
+
 	var configOpts configflag.Options
 	configOpts.Register()
 
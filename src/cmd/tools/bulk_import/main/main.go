@@ -0,0 +1,574 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/x/context"
+	"github.com/m3db/m3/src/x/ident"
+	"github.com/m3db/m3/src/x/pool"
+	"github.com/m3db/m3/src/x/serialize"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/pborman/getopt"
+	"go.uber.org/zap"
+)
+
+const (
+	// registerPath is the dbnode admin API path volumes are registered at.
+	registerPath = "/api/v1/import/register"
+
+	csvFormat  = "csv"
+	jsonFormat = "json"
+)
+
+func main() {
+	var (
+		optPathPrefix     = getopt.StringLong("path-prefix", 'p', "", "Path prefix [e.g. /var/lib/m3db]")
+		optNamespace      = getopt.StringLong("namespace", 'n', "", "Namespace [e.g. metrics]")
+		optBlockSize      = getopt.StringLong("block-size", 'b', "", "Namespace block size [e.g. 2h]")
+		optShards         = getopt.Uint32Long("shards", 's', 0, "Number of shards in the placement")
+		optInput          = getopt.StringLong("input", 'i', "-", "Input file, '-' for stdin")
+		optFormat         = getopt.StringLong("format", 'f', csvFormat, "Input format [csv, json]")
+		optPlannedRecords = getopt.IntLong("planned-records", 'r', 1<<16,
+			"Estimate of the number of series per shard and block")
+		optRegister = getopt.StringLong("register", 'e', "",
+			"HTTP admin listen address of the dbnode to register volumes with [e.g. http://localhost:9006]")
+		optRegisterHeader = getopt.StringLong("register-header", 'H', "",
+			"Header sent with register requests to authenticate with the dbnode [e.g. 'Authorization: Bearer <token>']")
+	)
+	getopt.Parse()
+
+	rawLogger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatalf("unable to create logger: %+v", err)
+	}
+	logger := rawLogger.Sugar()
+
+	blockSize, err := time.ParseDuration(*optBlockSize)
+	if err != nil ||
+		blockSize <= 0 ||
+		*optPathPrefix == "" ||
+		*optNamespace == "" ||
+		*optShards == 0 ||
+		*optPlannedRecords <= 0 ||
+		(*optFormat != csvFormat && *optFormat != jsonFormat) {
+		getopt.Usage()
+		os.Exit(1)
+	}
+
+	input := os.Stdin
+	if *optInput != "-" {
+		input, err = os.Open(*optInput) // nolint: gosec
+		if err != nil {
+			logger.Fatalf("unable to open input: %+v", err)
+		}
+		defer input.Close() // nolint: errcheck
+	}
+
+	var registerHeader http.Header
+	if *optRegisterHeader != "" {
+		idx := strings.Index(*optRegisterHeader, ":")
+		if idx <= 0 {
+			logger.Fatalf("invalid register header %q, expected name: value", *optRegisterHeader)
+		}
+		registerHeader = http.Header{}
+		registerHeader.Set(strings.TrimSpace((*optRegisterHeader)[:idx]),
+			strings.TrimSpace((*optRegisterHeader)[idx+1:]))
+	}
+
+	parseFn := parseCSVLine
+	if *optFormat == jsonFormat {
+		parseFn = parseJSONLine
+	}
+
+	imp, err := newImporter(importerOptions{
+		fsOpts:         fs.NewOptions().SetFilePathPrefix(*optPathPrefix),
+		namespace:      ident.StringID(*optNamespace),
+		blockSize:      blockSize,
+		numShards:      *optShards,
+		plannedRecords: uint(*optPlannedRecords),
+		parseFn:        parseFn,
+		registerAddr:   *optRegister,
+		registerHeader: registerHeader,
+	})
+	if err != nil {
+		logger.Fatalf("unable to create importer: %+v", err)
+	}
+
+	start := time.Now()
+	if err := imp.importAll(input); err != nil {
+		if abortErr := imp.abort(); abortErr != nil {
+			logger.Errorf("unable to abort partially written volumes: %+v", abortErr)
+		}
+		logger.Fatalf("import failed: %+v", err)
+	}
+
+	for _, v := range imp.imported {
+		fmt.Printf("shard=%d blockStart=%d volume=%d series=%d registered=%v\n", // nolint: forbidigo
+			v.Shard, v.BlockStart.UnixNano(), v.Volume, v.series, v.registered)
+	}
+	fmt.Printf("Running time: %s\n", time.Since(start)) // nolint: forbidigo
+}
+
+type importerOptions struct {
+	fsOpts         fs.Options
+	namespace      ident.ID
+	blockSize      time.Duration
+	numShards      uint32
+	plannedRecords uint
+	parseFn        parseFn
+	registerAddr   string
+	registerHeader http.Header
+}
+
+// importedVolume is a fileset volume written by the importer, it is also the
+// body of the dbnode register request.
+type importedVolume struct {
+	Namespace  string    `json:"namespace"`
+	Shard      uint32    `json:"shard"`
+	BlockStart time.Time `json:"blockStart"`
+	Volume     int       `json:"volume"`
+
+	series     int
+	registered bool
+}
+
+// importer builds fileset volumes from input that is sorted by block start,
+// then series ID, then timestamp. Each block is written to a new volume per
+// shard that is placed after the latest existing volume of the block.
+type importer struct {
+	opts       importerOptions
+	hashFn     sharding.HashFn
+	encoder    encoding.Encoder
+	tagEncoder serialize.TagEncoder
+	ctx        context.Context
+	data       [][]byte
+
+	// latestVolumes is the latest existing volume by block start for each
+	// shard, lazily read from the info files.
+	latestVolumes map[uint32]map[xtime.UnixNano]latestVolume
+
+	blockStart xtime.UnixNano
+	writers    map[uint32]*volumeWriter
+	imported   []importedVolume
+
+	seriesID     []byte
+	seriesTags   ident.Tags
+	lastWritten  xtime.UnixNano
+	hasSeries    bool
+	hasDatapoint bool
+}
+
+type latestVolume struct {
+	volume  int
+	entries int64
+}
+
+type volumeWriter struct {
+	writer fs.StreamingWriter
+	volume importedVolume
+}
+
+func newImporter(opts importerOptions) (*importer, error) {
+	poolOpts := pool.NewObjectPoolOptions().SetSize(1)
+	tagEncoderPool := serialize.NewTagEncoderPool(serialize.NewTagEncoderOptions(), poolOpts)
+	tagEncoderPool.Init()
+
+	return &importer{
+		opts:          opts,
+		hashFn:        sharding.DefaultHashFn(int(opts.numShards)),
+		encoder:       m3tsz.NewEncoder(0, nil, m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions()),
+		tagEncoder:    tagEncoderPool.Get(),
+		ctx:           context.NewBackground(),
+		data:          make([][]byte, 0, 2),
+		latestVolumes: make(map[uint32]map[xtime.UnixNano]latestVolume),
+		writers:       make(map[uint32]*volumeWriter),
+	}, nil
+}
+
+func (i *importer) importAll(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		dp, err := i.opts.parseFn(line)
+		if err == nil {
+			err = i.importDatapoint(dp)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return i.finishBlock()
+}
+
+// datapoint is a datapoint of a series parsed from a line of input.
+type datapoint struct {
+	id        []byte
+	timestamp xtime.UnixNano
+	value     float64
+	tags      ident.Tags
+}
+
+// parseFn parses a line of input into a datapoint.
+type parseFn func(line string) (datapoint, error)
+
+// parseCSVLine parses a line formatted as id,timestampNanos,value[,tag=value...].
+func parseCSVLine(line string) (datapoint, error) {
+	fields := strings.Split(line, ",")
+	if len(fields) < 3 {
+		return datapoint{}, fmt.Errorf("expected at least 3 fields, got %d", len(fields))
+	}
+	timestampNanos, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return datapoint{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	value, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return datapoint{}, fmt.Errorf("invalid value: %w", err)
+	}
+	tags := make(map[string]string, len(fields)-3)
+	for _, field := range fields[3:] {
+		idx := strings.Index(field, "=")
+		if idx <= 0 {
+			return datapoint{}, fmt.Errorf("invalid tag %q, expected name=value", field)
+		}
+		tags[field[:idx]] = field[idx+1:]
+	}
+	return datapoint{
+		id:        []byte(fields[0]),
+		timestamp: xtime.UnixNano(timestampNanos),
+		value:     value,
+		tags:      newTags(tags),
+	}, nil
+}
+
+// jsonDatapoint is a line of JSON input.
+type jsonDatapoint struct {
+	ID        string            `json:"id"`
+	Timestamp *int64            `json:"timestamp"`
+	Value     *float64          `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// parseJSONLine parses a line formatted as a JSON object with the series ID,
+// the timestamp in nsec, the value and optionally the tags of the series.
+func parseJSONLine(line string) (datapoint, error) {
+	var dp jsonDatapoint
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&dp); err != nil {
+		return datapoint{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dp.Timestamp == nil {
+		return datapoint{}, errors.New("missing timestamp")
+	}
+	if dp.Value == nil {
+		return datapoint{}, errors.New("missing value")
+	}
+	return datapoint{
+		id:        []byte(dp.ID),
+		timestamp: xtime.UnixNano(*dp.Timestamp),
+		value:     *dp.Value,
+		tags:      newTags(dp.Tags),
+	}, nil
+}
+
+// newTags returns the tags sorted by name.
+func newTags(tags map[string]string) ident.Tags {
+	result := make([]ident.Tag, 0, len(tags))
+	for name, value := range tags {
+		result = append(result, ident.StringTag(name, value))
+	}
+	sort.Slice(result, func(a, b int) bool {
+		return bytes.Compare(result[a].Name.Bytes(), result[b].Name.Bytes()) < 0
+	})
+	return ident.NewTags(result...)
+}
+
+func (i *importer) importDatapoint(dp datapoint) error {
+	if len(dp.id) == 0 {
+		return errors.New("empty series ID")
+	}
+
+	blockStart := dp.timestamp.Truncate(i.opts.blockSize)
+	if !i.hasSeries || blockStart != i.blockStart {
+		if i.hasSeries && blockStart.Before(i.blockStart) {
+			return fmt.Errorf("input is not sorted by block start: %v before %v",
+				blockStart, i.blockStart)
+		}
+		if err := i.finishBlock(); err != nil {
+			return err
+		}
+		i.blockStart = blockStart
+	}
+
+	if !i.hasSeries || !bytes.Equal(dp.id, i.seriesID) {
+		if i.hasSeries && bytes.Compare(dp.id, i.seriesID) < 0 {
+			return fmt.Errorf("input is not sorted by series ID: %s before %s", dp.id, i.seriesID)
+		}
+		if err := i.finishSeries(); err != nil {
+			return err
+		}
+		i.seriesID = dp.id
+		i.seriesTags = dp.tags
+		i.hasSeries = true
+		i.encoder.Reset(blockStart, 0, nil)
+	} else if !dp.timestamp.After(i.lastWritten) {
+		return fmt.Errorf("input is not sorted by timestamp for series %s", dp.id)
+	}
+
+	if err := i.encoder.Encode(ts.Datapoint{
+		TimestampNanos: dp.timestamp,
+		Value:          dp.value,
+	}, xtime.Nanosecond, nil); err != nil {
+		return err
+	}
+	i.lastWritten = dp.timestamp
+	i.hasDatapoint = true
+	return nil
+}
+
+// finishSeries writes the current series to the volume of its shard.
+func (i *importer) finishSeries() error {
+	if !i.hasDatapoint {
+		return nil
+	}
+	i.hasDatapoint = false
+
+	id := ident.BytesID(i.seriesID)
+	w, err := i.writerFor(i.hashFn(id))
+	if err != nil {
+		return err
+	}
+
+	i.tagEncoder.Reset()
+	if err := i.tagEncoder.Encode(ident.NewTagsIterator(i.seriesTags)); err != nil {
+		return err
+	}
+	encodedTags, ok := i.tagEncoder.Data()
+	if !ok {
+		return fmt.Errorf("unable to encode tags for series %s", id)
+	}
+
+	i.ctx.Reset()
+	defer i.ctx.BlockingClose()
+	stream, ok := i.encoder.Stream(i.ctx)
+	if !ok {
+		return nil
+	}
+	segment, err := stream.Segment()
+	if err != nil {
+		return err
+	}
+	i.data = i.data[:0]
+	if segment.Head != nil {
+		i.data = append(i.data, segment.Head.Bytes())
+	}
+	if segment.Tail != nil {
+		i.data = append(i.data, segment.Tail.Bytes())
+	}
+	checksum := segment.CalculateChecksum()
+
+	if err := w.writer.WriteAll(id, encodedTags.Bytes(), i.data, checksum); err != nil {
+		return err
+	}
+	w.volume.series++
+	return nil
+}
+
+// finishBlock closes the volumes written for the current block and registers
+// them with the dbnode if configured.
+func (i *importer) finishBlock() error {
+	if err := i.finishSeries(); err != nil {
+		return err
+	}
+	i.hasSeries = false
+	i.seriesID = nil
+
+	shards := make([]uint32, 0, len(i.writers))
+	for shard := range i.writers {
+		shards = append(shards, shard)
+	}
+	sort.Slice(shards, func(a, b int) bool { return shards[a] < shards[b] })
+
+	for _, shard := range shards {
+		w := i.writers[shard]
+		if err := w.writer.Close(); err != nil {
+			return fmt.Errorf("unable to close volume for shard %d: %w", shard, err)
+		}
+		delete(i.writers, shard)
+
+		if i.opts.registerAddr != "" {
+			if err := register(i.opts.registerAddr, i.opts.registerHeader, w.volume); err != nil {
+				return fmt.Errorf("unable to register volume for shard %d: %w", shard, err)
+			}
+			w.volume.registered = true
+		}
+		i.imported = append(i.imported, w.volume)
+	}
+	return nil
+}
+
+// abort aborts the volumes still being written, they are left without a
+// checkpoint file so dbnodes never read them.
+func (i *importer) abort() error {
+	var firstErr error
+	for shard, w := range i.writers {
+		if err := w.writer.Abort(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(i.writers, shard)
+	}
+	return firstErr
+}
+
+func (i *importer) writerFor(shard uint32) (*volumeWriter, error) {
+	if w, ok := i.writers[shard]; ok {
+		return w, nil
+	}
+
+	latest, err := i.latestVolume(shard, i.blockStart)
+	if err != nil {
+		return nil, err
+	}
+	// NB: the imported volume of a flushed block replaces the latest volume
+	// for reads rather than being merged with it, so only flushed blocks
+	// without data can be imported.
+	if latest.entries > 0 {
+		return nil, fmt.Errorf("shard %d block %v already has %d series in volume %d",
+			shard, i.blockStart, latest.entries, latest.volume)
+	}
+
+	writer, err := fs.NewStreamingWriter(i.opts.fsOpts)
+	if err != nil {
+		return nil, err
+	}
+	volume := latest.volume + 1
+	if err := writer.Open(fs.StreamingWriterOpenOptions{
+		NamespaceID:         i.opts.namespace,
+		ShardID:             shard,
+		BlockStart:          i.blockStart,
+		BlockSize:           i.opts.blockSize,
+		VolumeIndex:         volume,
+		PlannedRecordsCount: i.opts.plannedRecords,
+	}); err != nil {
+		return nil, err
+	}
+
+	w := &volumeWriter{
+		writer: writer,
+		volume: importedVolume{
+			Namespace:  i.opts.namespace.String(),
+			Shard:      shard,
+			BlockStart: i.blockStart.ToTime(),
+			Volume:     volume,
+		},
+	}
+	i.writers[shard] = w
+	return w, nil
+}
+
+func (i *importer) latestVolume(shard uint32, blockStart xtime.UnixNano) (latestVolume, error) {
+	volumes, ok := i.latestVolumes[shard]
+	if !ok {
+		volumes = make(map[xtime.UnixNano]latestVolume)
+		fsOpts := i.opts.fsOpts
+		results := fs.ReadInfoFiles(fsOpts.FilePathPrefix(), i.opts.namespace, shard,
+			fsOpts.InfoReaderBufferSize(), fsOpts.DecodingOptions(), persist.FileSetFlushType)
+		for _, result := range results {
+			if err := result.Err.Error(); err != nil {
+				return latestVolume{}, fmt.Errorf("unable to read info file %s: %w",
+					result.Err.Filepath(), err)
+			}
+			at := xtime.UnixNano(result.Info.BlockStart)
+			if curr, ok := volumes[at]; !ok || curr.volume < result.Info.VolumeIndex {
+				volumes[at] = latestVolume{
+					volume:  result.Info.VolumeIndex,
+					entries: result.Info.Entries,
+				}
+			}
+		}
+		i.latestVolumes[shard] = volumes
+	}
+
+	latest, ok := volumes[blockStart]
+	if !ok {
+		// Blocks that have not been flushed yet are imported as their first
+		// volume, see dbShard.RegisterImportedVolume.
+		return latestVolume{volume: -1}, nil
+	}
+	return latest, nil
+}
+
+func register(addr string, header http.Header, volume importedVolume) error {
+	body, err := json.Marshal(volume)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, // nolint: noctx
+		strings.TrimSuffix(addr, "/")+registerPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name := range header {
+		req.Header.Set(name, header.Get(name))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3/src/cmd/tools/m3ctl/apply"
 	"github.com/m3db/m3/src/cmd/tools/m3ctl/namespaces"
 	"github.com/m3db/m3/src/cmd/tools/m3ctl/placements"
+	"github.com/m3db/m3/src/cmd/tools/m3ctl/rules"
 	"github.com/m3db/m3/src/cmd/tools/m3ctl/topics"
 	"github.com/m3db/m3/src/query/generated/proto/admin"
 
@@ -79,6 +80,11 @@ func main() {
 		showAll   bool
 		deleteAll bool
 		nodeName  string
+
+		simulateArgs placements.SimulateArgs
+
+		rulesPath       string
+		storagePolicies []string
 	)
 
 	logger := mustNewLogger(defaultLoggerOptions)
@@ -125,6 +131,16 @@ database creation, database init, adding a node, and replacing a node, are suppo
 		},
 	}
 
+	simulateCmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulate changes to resources without applying them",
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Translate resources from other systems using the remote endpoint",
+	}
+
 	getNamespaceCmd := &cobra.Command{
 		Use:     "namespace []",
 		Short:   "Get the namespaces from the remote endpoint",
@@ -195,6 +211,55 @@ database creation, database init, adding a node, and replacing a node, are suppo
 		},
 	}
 
+	simulatePlacementCmd := &cobra.Command{
+		Use:   "placement <m3db/m3coordinator/m3aggregator>",
+		Short: "Simulate adding or removing instances from a service placement",
+		Long: `This will apply the placement change to a copy of the service placement
+and output the projected per instance load, the bytes moved and the rebalance
+duration under the given pacing. The placement is read from the file given with
+--file (as output by "get placement") or fetched from the remote endpoint.
+`,
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: []string{"m3db", "m3coordinator", "m3aggregator"},
+		Aliases:   []string{"pl"},
+		Run: func(cmd *cobra.Command, args []string) {
+			logger.Debug("running command", zap.String("command", cmd.Name()))
+
+			resp, err := placements.DoSimulate(endPoint, args[0], headers, simulateArgs, logger)
+			if err != nil {
+				logger.Fatal("simulate placement failed", zap.Error(err))
+			}
+
+			os.Stdout.Write(resp) //nolint:errcheck
+		},
+	}
+
+	importPromRulesCmd := &cobra.Command{
+		Use:   "prom-rules",
+		Short: "Translate a Prometheus recording rule file into rollup rules",
+		Long: `This will send the Prometheus rule file given with --file to the remote
+endpoint and output the translated rollup rules as YAML along with a report of
+which rules were translated, with any caveats, and why the others were skipped.
+Only sum, min, max, count and avg aggregations of a selector, or of rate or
+increase over a selector, can be translated.
+`,
+		Aliases: []string{"pr"},
+		Run: func(cmd *cobra.Command, args []string) {
+			logger.Debug("running command", zap.String("command", cmd.Name()))
+
+			if len(rulesPath) == 0 {
+				logger.Fatal("need to specify a path to a Prometheus rule file")
+			}
+
+			resp, err := rules.DoImportPrometheus(endPoint, headers, rulesPath, storagePolicies, logger)
+			if err != nil {
+				logger.Fatal("import prometheus rules failed", zap.Error(err))
+			}
+
+			os.Stdout.Write(resp) //nolint:errcheck
+		},
+	}
+
 	deleteNamespaceCmd := &cobra.Command{
 		Use:     "namespace",
 		Short:   "Delete the namespace from the remote endpoint",
@@ -243,13 +308,15 @@ database creation, database init, adding a node, and replacing a node, are suppo
 		},
 	}
 
-	rootCmd.AddCommand(getCmd, applyCmd, deleteCmd)
+	rootCmd.AddCommand(getCmd, applyCmd, deleteCmd, simulateCmd, importCmd)
 	getCmd.AddCommand(getNamespaceCmd)
 	getCmd.AddCommand(getPlacementCmd)
 	getCmd.AddCommand(getTopicCmd)
 	deleteCmd.AddCommand(deletePlacementCmd)
 	deleteCmd.AddCommand(deleteNamespaceCmd)
 	deleteCmd.AddCommand(deleteTopicCmd)
+	simulateCmd.AddCommand(simulatePlacementCmd)
+	importCmd.AddCommand(importPromRulesCmd)
 
 	var headersSlice []string
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "debug log output level (cannot use JSON output)")
@@ -260,6 +327,28 @@ database creation, database init, adding a node, and replacing a node, are suppo
 	deletePlacementCmd.Flags().BoolVarP(&deleteAll, "delete-all", "a", false, "delete the entire placement")
 	deleteCmd.PersistentFlags().StringVarP(&nodeName, "name", "n", "", "which namespace or node to delete")
 
+	simulateFlags := simulatePlacementCmd.Flags()
+	simulateFlags.StringVarP(&simulateArgs.PlacementPath, "file", "f", "",
+		"placement JSON file, fetched from the endpoint if not set")
+	simulateFlags.StringVar(&simulateArgs.LoadsPath, "loads", "",
+		"JSON file of shard loads keyed by shard ID, e.g. {\"0\": {\"bytes\": 1024, \"writesPerSecond\": 10}}")
+	simulateFlags.Int64Var(&simulateArgs.DefaultShardBytes, "shard-bytes", 0,
+		"bytes of shards without a load in the loads file")
+	simulateFlags.IntVar(&simulateArgs.AddInstances, "add", 0, "number of instances to add")
+	simulateFlags.StringSliceVar(&simulateArgs.AddIsolationGroups, "add-isolation-groups", nil,
+		"isolation groups to spread added instances over, defaults to those of the placement")
+	simulateFlags.Uint32Var(&simulateArgs.AddWeight, "add-weight", 0,
+		"weight of added instances, defaults to the weight of existing instances")
+	simulateFlags.StringSliceVar(&simulateArgs.RemoveInstances, "remove", nil, "IDs of instances to remove")
+	simulateFlags.Int64Var(&simulateArgs.BytesPerSecond, "bytes-per-second", 100<<20,
+		"rate each instance receives shard data at")
+	simulateFlags.IntVar(&simulateArgs.MaxConcurrentInstances, "max-concurrent", 0,
+		"number of instances receiving shard data at once, unlimited if zero")
+
+	importPromRulesCmd.Flags().StringVarP(&rulesPath, "file", "f", "", "Prometheus rule file to translate")
+	importPromRulesCmd.Flags().StringSliceVar(&storagePolicies, "storage-policy", nil,
+		"storage policies to keep rolled up metrics at, e.g. 1m:40d")
+
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		// Override logger if debug flag set.
 		if debug {
@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placements
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/simulation"
+	"github.com/m3db/m3/src/query/generated/proto/admin"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"go.uber.org/zap"
+)
+
+// SimulateArgs are the arguments of a placement change simulation.
+type SimulateArgs struct {
+	// PlacementPath is a file with the placement as returned by get placement,
+	// the placement is fetched from the remote endpoint if empty.
+	PlacementPath string
+	// LoadsPath is a JSON file with shard loads keyed by shard ID.
+	LoadsPath string
+	// DefaultShardBytes is the size of shards without a load in the loads file.
+	DefaultShardBytes int64
+	// AddInstances is the number of instances to add.
+	AddInstances int
+	// AddIsolationGroups are the isolation groups added instances are spread
+	// over, defaults to the isolation groups of the placement.
+	AddIsolationGroups []string
+	// AddWeight is the weight of added instances, defaults to the weight of
+	// the first instance in the placement.
+	AddWeight uint32
+	// RemoveInstances are the IDs of instances to remove.
+	RemoveInstances []string
+	// BytesPerSecond is the rate each instance receives shard data at.
+	BytesPerSecond int64
+	// MaxConcurrentInstances is how many instances receive shard data at once.
+	MaxConcurrentInstances int
+}
+
+// DoSimulate simulates a placement change and returns the projected load.
+func DoSimulate(
+	endpoint string,
+	service string,
+	headers map[string]string,
+	args SimulateArgs,
+	logger *zap.Logger,
+) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if args.PlacementPath != "" {
+		data, err = ioutil.ReadFile(args.PlacementPath)
+	} else {
+		data, err = DoGet(endpoint, service, headers, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp admin.PlacementGetResponse
+	unmarshaller := &jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err := unmarshaller.Unmarshal(bytes.NewReader(data), &resp); err != nil {
+		return nil, fmt.Errorf("could not unmarshal placement: %v", err)
+	}
+	if resp.Placement == nil {
+		return nil, errors.New("no placement to simulate against")
+	}
+	p, err := placement.NewPlacementFromProto(resp.Placement)
+	if err != nil {
+		return nil, err
+	}
+
+	loads := make(simulation.ShardLoads, p.NumShards())
+	if args.LoadsPath != "" {
+		data, err := ioutil.ReadFile(args.LoadsPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &loads); err != nil {
+			return nil, fmt.Errorf("could not unmarshal shard loads: %v", err)
+		}
+	}
+	for _, id := range p.Shards() {
+		if _, ok := loads[id]; !ok {
+			loads[id] = simulation.ShardLoad{Bytes: args.DefaultShardBytes}
+		}
+	}
+
+	res, err := simulation.Simulate(p, loads, simulation.Change{
+		Add:    simulatedInstances(p, args),
+		Remove: args.RemoveInstances,
+	}, simulation.Pacing{
+		BytesPerSecond:         args.BytesPerSecond,
+		MaxConcurrentInstances: args.MaxConcurrentInstances,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		simulation.Result
+		RebalanceDuration string `json:"rebalanceDuration"`
+	}{
+		Result:            res,
+		RebalanceDuration: res.RebalanceDuration.String(),
+	})
+}
+
+// simulatedInstances returns the instances to add, spread over the isolation
+// groups in the zone of the placement.
+func simulatedInstances(p placement.Placement, args SimulateArgs) []placement.Instance {
+	if args.AddInstances <= 0 {
+		return nil
+	}
+	var (
+		existing        = p.Instances()
+		zone            string
+		weight          = args.AddWeight
+		isolationGroups = args.AddIsolationGroups
+	)
+	if len(existing) > 0 {
+		zone = existing[0].Zone()
+		if weight == 0 {
+			weight = existing[0].Weight()
+		}
+	}
+	if weight == 0 {
+		weight = 1
+	}
+	if len(isolationGroups) == 0 {
+		seen := make(map[string]struct{}, len(existing))
+		for _, instance := range existing {
+			if _, ok := seen[instance.IsolationGroup()]; ok {
+				continue
+			}
+			seen[instance.IsolationGroup()] = struct{}{}
+			isolationGroups = append(isolationGroups, instance.IsolationGroup())
+		}
+		sort.Strings(isolationGroups)
+	}
+	if len(isolationGroups) == 0 {
+		isolationGroups = []string{""}
+	}
+
+	instances := make([]placement.Instance, 0, args.AddInstances)
+	for i := 0; i < args.AddInstances; i++ {
+		id := fmt.Sprintf("simulated-%d", i)
+		instances = append(instances, placement.NewEmptyInstance(
+			id, isolationGroups[i%len(isolationGroups)], zone, id, weight))
+	}
+	return instances
+}
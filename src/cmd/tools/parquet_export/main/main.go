@@ -0,0 +1,247 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/export"
+	"github.com/m3db/m3/src/x/headers"
+
+	"github.com/pborman/getopt"
+	"go.uber.org/zap"
+)
+
+const partFileName = "part.parquet"
+
+var errTruncatedFile = errors.New("exported file is truncated")
+
+func main() {
+	var (
+		optCoordinator = getopt.StringLong("coordinator", 'c', "http://localhost:7201",
+			"Coordinator address")
+		optMatch     = getopt.StringLong("match", 'm', "", "Series selector [e.g. up{job=\"api\"}]")
+		optStart     = getopt.Int64Long("start", 's', 0, "Start time, inclusive [in sec]")
+		optEnd       = getopt.Int64Long("end", 'e', 0, "End time, exclusive [in sec]")
+		optPartition = getopt.StringLong("partition", 'p', "1h", "Duration of each exported partition")
+		optOutput    = getopt.StringLong("output", 'o', "", "Output directory")
+		optColumns   = getopt.StringLong("columns", 'l', "",
+			"Tags to export as columns, formatted as tag or tag:column and comma separated, all tags if empty")
+		optMetricsType = getopt.StringLong("metrics-type", 't', "",
+			"Metrics type of the namespace to export [unaggregated or aggregated]")
+		optStoragePolicy = getopt.StringLong("storage-policy", 'r', "",
+			"Storage policy of the aggregated namespace to export [e.g. 1m:40d]")
+		optParallelism = getopt.IntLong("parallelism", 'j', 4, "Number of partitions exported concurrently")
+	)
+	getopt.Parse()
+
+	rawLogger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatalf("unable to create logger: %+v", err)
+	}
+	logger := rawLogger.Sugar()
+
+	partition, err := time.ParseDuration(*optPartition)
+	if err != nil ||
+		partition < time.Second ||
+		*optMatch == "" ||
+		*optOutput == "" ||
+		*optStart <= 0 ||
+		*optEnd <= *optStart ||
+		*optParallelism <= 0 {
+		getopt.Usage()
+		os.Exit(1)
+	}
+
+	exporter := &exporter{
+		client:        &http.Client{},
+		coordinator:   strings.TrimSuffix(*optCoordinator, "/"),
+		match:         *optMatch,
+		columns:       *optColumns,
+		metricsType:   *optMetricsType,
+		storagePolicy: *optStoragePolicy,
+		output:        *optOutput,
+	}
+
+	var (
+		start      = time.Unix(*optStart, 0)
+		end        = time.Unix(*optEnd, 0)
+		partitions = make(chan timeRange)
+		wg         sync.WaitGroup
+		failed     int64
+		mu         sync.Mutex
+	)
+	for i := 0; i < *optParallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range partitions {
+				skipped, err := exporter.exportPartition(r.start, r.end)
+				if err != nil {
+					logger.Errorf("unable to export partition %s: %+v", r.start.UTC(), err)
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					continue
+				}
+				if skipped {
+					fmt.Printf("%s - skip (already exported)\n", r.start.UTC()) // nolint: forbidigo
+					continue
+				}
+				fmt.Printf("%s - exported\n", r.start.UTC()) // nolint: forbidigo
+			}
+		}()
+	}
+
+	runStart := time.Now()
+	// Partitions are aligned to the partition duration, the first and last
+	// partitions are clamped to the export time range.
+	for t := start.Truncate(partition); t.Before(end); t = t.Add(partition) {
+		r := timeRange{start: t, end: t.Add(partition)}
+		if r.start.Before(start) {
+			r.start = start
+		}
+		if r.end.After(end) {
+			r.end = end
+		}
+		partitions <- r
+	}
+	close(partitions)
+	wg.Wait()
+
+	fmt.Printf("Running time: %s\n", time.Since(runStart)) // nolint: forbidigo
+	if failed > 0 {
+		// Partitions that were exported are skipped when rerun.
+		logger.Fatalf("%d partitions failed to export, rerun to resume", failed)
+	}
+}
+
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+type exporter struct {
+	client        *http.Client
+	coordinator   string
+	match         string
+	columns       string
+	metricsType   string
+	storagePolicy string
+	output        string
+}
+
+// exportPartition exports the partition to a file named after the partition
+// start, partitions that were already exported are skipped so that exports
+// can be resumed.
+func (e *exporter) exportPartition(start, end time.Time) (bool, error) {
+	dir := filepath.Join(e.output, "start="+strconv.FormatInt(start.Unix(), 10))
+	path := filepath.Join(dir, partFileName)
+	if _, err := os.Stat(path); err == nil {
+		return true, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil { // nolint: gosec
+		return false, err
+	}
+
+	params := url.Values{}
+	params.Set("match[]", e.match)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	// NB: the end of a query is inclusive, exclude the first sample of the
+	// next partition.
+	params.Set("end", strconv.FormatFloat(float64(end.UnixNano()-int64(time.Millisecond))/1e9, 'f', 3, 64))
+	if e.columns != "" {
+		params.Set(export.ColumnParam, e.columns)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.coordinator+export.ParquetURL,
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if e.metricsType != "" {
+		req.Header.Set(headers.MetricsTypeHeader, e.metricsType)
+	}
+	if e.storagePolicy != "" {
+		req.Header.Set(headers.MetricsStoragePolicyHeader, e.storagePolicy)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return false, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	// Write to a temporary file first so that only complete partitions are
+	// ever visible under the final path.
+	tmp, err := ioutil.TempFile(dir, partFileName+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+
+	tail := &tailWriter{}
+	if _, err := io.Copy(io.MultiWriter(tmp, tail), resp.Body); err != nil {
+		tmp.Close() // nolint: errcheck
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	// The coordinator streams the file, a response cut short is only
+	// detectable by the missing footer magic.
+	if !bytes.Equal(tail.buf, []byte("PAR1")) {
+		return false, errTruncatedFile
+	}
+	return false, os.Rename(tmp.Name(), path)
+}
+
+// tailWriter retains the last four bytes written.
+type tailWriter struct {
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > 4 {
+		w.buf = w.buf[len(w.buf)-4:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	"github.com/m3db/m3/src/x/headers"
+)
+
+const nameLabel = "__name__"
+
+var errInvalidRule = errors.New("rule requires a name, a raw query and a rollup query")
+
+// rule is a rollup rule to verify, the raw query recomputes the rollup from
+// the unaggregated namespace and the rollup query reads the series produced
+// by the rule from the aggregated namespace.
+type rule struct {
+	Name      string  `yaml:"name"`
+	Raw       string  `yaml:"raw"`
+	Rollup    string  `yaml:"rollup"`
+	Tolerance float64 `yaml:"tolerance"`
+}
+
+type rulesConfiguration struct {
+	Rules []rule `yaml:"rules"`
+}
+
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+type series struct {
+	labels map[string]string
+	// values by timestamp in milliseconds.
+	values map[int64]float64
+}
+
+// ruleReport summarizes the verification of a rule.
+type ruleReport struct {
+	rule             string
+	samples          int
+	series           int
+	points           int
+	mismatches       int
+	missingSeries    int
+	missingPoints    int
+	maxRelativeError float64
+}
+
+func (r ruleReport) failed() bool {
+	return r.mismatches > 0 || r.missingSeries > 0 || r.missingPoints > 0
+}
+
+type checker struct {
+	client          *http.Client
+	coordinator     string
+	storagePolicy   string
+	step            time.Duration
+	seriesPerSample int
+	tolerance       float64
+	rnd             *rand.Rand
+}
+
+// sampleRanges returns n ranges of the given length picked at random within
+// [start, end) and aligned to the step.
+func (c *checker) sampleRanges(start, end time.Time, n int, length time.Duration) []timeRange {
+	if end.Sub(start) <= length {
+		return []timeRange{{start: start, end: end}}
+	}
+
+	ranges := make([]timeRange, 0, n)
+	for i := 0; i < n; i++ {
+		offset := time.Duration(c.rnd.Int63n(int64(end.Sub(start) - length)))
+		rangeStart := start.Add(offset).Truncate(c.step)
+		if rangeStart.Before(start) {
+			rangeStart = rangeStart.Add(c.step)
+		}
+		ranges = append(ranges, timeRange{start: rangeStart, end: rangeStart.Add(length)})
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Before(ranges[j].start)
+	})
+	return ranges
+}
+
+// checkRule recomputes the rollup of the rule from raw data for a sample of
+// the series in each range and compares it against the aggregated data.
+func (c *checker) checkRule(r rule, ranges []timeRange) (ruleReport, error) {
+	report := ruleReport{rule: r.Name}
+	if r.Name == "" || r.Raw == "" || r.Rollup == "" {
+		return report, errInvalidRule
+	}
+
+	tolerance := c.tolerance
+	if r.Tolerance > 0 {
+		tolerance = r.Tolerance
+	}
+
+	for _, tr := range ranges {
+		raw, err := c.query(r.Raw, tr, storagemetadata.UnaggregatedMetricsType)
+		if err != nil {
+			return report, fmt.Errorf("raw query failed: %w", err)
+		}
+		rollup, err := c.query(r.Rollup, tr, storagemetadata.AggregatedMetricsType)
+		if err != nil {
+			return report, fmt.Errorf("rollup query failed: %w", err)
+		}
+
+		rollupByKey := make(map[string]series, len(rollup))
+		for _, s := range rollup {
+			rollupByKey[seriesKey(s.labels)] = s
+		}
+
+		report.samples++
+		for _, s := range c.sampleSeries(raw) {
+			report.series++
+			aggregated, ok := rollupByKey[seriesKey(s.labels)]
+			if !ok {
+				report.missingSeries++
+				continue
+			}
+
+			for ts, expected := range s.values {
+				report.points++
+				actual, ok := aggregated.values[ts]
+				if !ok {
+					report.missingPoints++
+					continue
+				}
+
+				relErr := relativeError(expected, actual)
+				if relErr > report.maxRelativeError {
+					report.maxRelativeError = relErr
+				}
+				if relErr > tolerance {
+					report.mismatches++
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// sampleSeries returns at most seriesPerSample series picked at random.
+func (c *checker) sampleSeries(all []series) []series {
+	if len(all) <= c.seriesPerSample {
+		return all
+	}
+	sampled := make([]series, 0, c.seriesPerSample)
+	for _, idx := range c.rnd.Perm(len(all))[:c.seriesPerSample] {
+		sampled = append(sampled, all[idx])
+	}
+	return sampled
+}
+
+type queryRangeResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][2]interface{}  `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func (c *checker) query(
+	query string,
+	r timeRange,
+	metricsType storagemetadata.MetricsType,
+) ([]series, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(r.start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(r.end.Unix(), 10))
+	params.Set("step", strconv.FormatFloat(c.step.Seconds(), 'f', -1, 64))
+
+	req, err := http.NewRequest(http.MethodPost, c.coordinator+route.QueryRangeURL,
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(headers.MetricsTypeHeader, metricsType.String())
+	if metricsType == storagemetadata.AggregatedMetricsType && c.storagePolicy != "" {
+		req.Header.Set(headers.MetricsStoragePolicyHeader, c.storagePolicy)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result queryRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("query failed: %s", result.Error)
+	}
+
+	out := make([]series, 0, len(result.Data.Result))
+	for _, res := range result.Data.Result {
+		s := series{
+			labels: res.Metric,
+			values: make(map[int64]float64, len(res.Values)),
+		}
+		for _, v := range res.Values {
+			ts, ok := v[0].(float64)
+			if !ok {
+				return nil, fmt.Errorf("invalid timestamp: %v", v[0])
+			}
+			str, ok := v[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid value: %v", v[1])
+			}
+			value, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				return nil, err
+			}
+			s.values[int64(math.Round(ts*1000))] = value
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// seriesKey identifies a series by its labels, excluding the metric name
+// since the rollup series are renamed by the rule.
+func seriesKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if name != nameLabel {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(labels[name])
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// relativeError returns the difference of the values relative to the
+// largest of them.
+func relativeError(expected, actual float64) float64 {
+	switch {
+	case math.IsNaN(expected) && math.IsNaN(actual):
+		return 0
+	case math.IsNaN(expected) || math.IsNaN(actual):
+		return math.Inf(1)
+	case expected == actual:
+		return 0
+	}
+	return math.Abs(expected-actual) / math.Max(math.Abs(expected), math.Abs(actual))
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	"github.com/m3db/m3/src/x/headers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRule(t *testing.T) {
+	responses := map[string]string{
+		"raw": `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"service":"a"},"values":[[60,"10"],[120,"20"]]},
+			{"metric":{"service":"b"},"values":[[60,"5"],[120,"5"]]},
+			{"metric":{"service":"c"},"values":[[60,"1"]]}]}}`,
+		"rollup": `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"__name__":"requests_by_service","service":"a"},"values":[[60,"10"],[120,"20.1"]]},
+			{"metric":{"__name__":"requests_by_service","service":"b"},"values":[[60,"5"],[120,"7"]]}]}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		query := r.Form.Get("query")
+		expectedType := storagemetadata.UnaggregatedMetricsType
+		if query == "rollup" {
+			expectedType = storagemetadata.AggregatedMetricsType
+			assert.Equal(t, "1m:40d", r.Header.Get(headers.MetricsStoragePolicyHeader))
+		}
+		assert.Equal(t, expectedType.String(), r.Header.Get(headers.MetricsTypeHeader))
+		assert.Equal(t, "60", r.Form.Get("step"))
+		fmt.Fprint(w, responses[query])
+	}))
+	defer server.Close()
+
+	c := &checker{
+		client:          &http.Client{},
+		coordinator:     server.URL,
+		storagePolicy:   "1m:40d",
+		step:            time.Minute,
+		seriesPerSample: 10,
+		tolerance:       0.01,
+		rnd:             rand.New(rand.NewSource(0)),
+	}
+	ranges := []timeRange{{start: time.Unix(60, 0), end: time.Unix(180, 0)}}
+
+	report, err := c.checkRule(rule{Name: "requests", Raw: "raw", Rollup: "rollup"}, ranges)
+	require.NoError(t, err)
+	assert.True(t, report.failed())
+	assert.Equal(t, ruleReport{
+		rule:             "requests",
+		samples:          1,
+		series:           3,
+		points:           4,
+		mismatches:       1,
+		missingSeries:    1,
+		maxRelativeError: 2.0 / 7,
+	}, report)
+
+	// A rule level tolerance overrides the default one.
+	report, err = c.checkRule(rule{Name: "requests", Raw: "raw", Rollup: "rollup", Tolerance: 0.5}, ranges)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.mismatches)
+
+	_, err = c.checkRule(rule{Name: "requests", Raw: "raw"}, ranges)
+	require.Equal(t, errInvalidRule, err)
+}
+
+func TestCheckRuleQueryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad query", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	c := &checker{
+		client:          &http.Client{},
+		coordinator:     server.URL,
+		step:            time.Minute,
+		seriesPerSample: 10,
+		rnd:             rand.New(rand.NewSource(0)),
+	}
+	ranges := []timeRange{{start: time.Unix(60, 0), end: time.Unix(180, 0)}}
+
+	_, err := c.checkRule(rule{Name: "requests", Raw: "raw", Rollup: "rollup"}, ranges)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "bad query"))
+}
+
+func TestSampleRanges(t *testing.T) {
+	c := &checker{
+		step: time.Minute,
+		rnd:  rand.New(rand.NewSource(0)),
+	}
+	var (
+		start = time.Unix(0, 0)
+		end   = start.Add(24 * time.Hour)
+	)
+
+	ranges := c.sampleRanges(start, end, 5, 10*time.Minute)
+	require.Len(t, ranges, 5)
+	for _, r := range ranges {
+		assert.False(t, r.start.Before(start))
+		assert.Equal(t, 10*time.Minute, r.end.Sub(r.start))
+		assert.Equal(t, r.start, r.start.Truncate(time.Minute))
+	}
+
+	ranges = c.sampleRanges(start, start.Add(5*time.Minute), 5, 10*time.Minute)
+	require.Equal(t, []timeRange{{start: start, end: start.Add(5 * time.Minute)}}, ranges)
+}
+
+func TestRelativeError(t *testing.T) {
+	assert.Equal(t, 0.0, relativeError(1, 1))
+	assert.Equal(t, 0.0, relativeError(math.NaN(), math.NaN()))
+	assert.True(t, math.IsInf(relativeError(1, math.NaN()), 1))
+	assert.InDelta(t, 0.5, relativeError(1, 2), 1e-9)
+	assert.InDelta(t, 0.5, relativeError(-2, -1), 1e-9)
+}
+
+func TestSeriesKey(t *testing.T) {
+	assert.Equal(t,
+		seriesKey(map[string]string{"__name__": "raw", "a": "1", "b": "2"}),
+		seriesKey(map[string]string{"b": "2", "__name__": "rollup", "a": "1"}))
+	assert.NotEqual(t,
+		seriesKey(map[string]string{"a": "1"}),
+		seriesKey(map[string]string{"a": "2"}))
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pborman/getopt"
+	"go.uber.org/zap"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func main() {
+	var (
+		optCoordinator = getopt.StringLong("coordinator", 'c', "http://localhost:7201",
+			"Coordinator address")
+		optRules = getopt.StringLong("rules", 'f', "", "Rules file, a YAML list of rules "+
+			"with a name, a raw query, a rollup query and an optional tolerance")
+		optStart         = getopt.Int64Long("start", 's', 0, "Start time, inclusive [in sec]")
+		optEnd           = getopt.Int64Long("end", 'e', 0, "End time, exclusive [in sec]")
+		optSamples       = getopt.IntLong("samples", 'n', 10, "Number of sampled time ranges per rule")
+		optRange         = getopt.StringLong("range", 'r', "10m", "Duration of each sampled time range")
+		optStep          = getopt.StringLong("step", 't', "1m", "Query step, the resolution of the aggregated namespace")
+		optSeries        = getopt.IntLong("series", 'm', 20, "Maximum number of series compared per sampled range")
+		optTolerance     = getopt.StringLong("tolerance", 'o', "0.01", "Default relative tolerance of rollup values")
+		optStoragePolicy = getopt.StringLong("storage-policy", 'p', "",
+			"Storage policy of the aggregated namespace [e.g. 1m:40d]")
+		optSeed = getopt.Int64Long("seed", 'd', 0, "Seed of the sampling, random if zero")
+	)
+	getopt.Parse()
+
+	rawLogger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatalf("unable to create logger: %+v", err)
+	}
+	logger := rawLogger.Sugar()
+
+	sampleRange, rangeErr := time.ParseDuration(*optRange)
+	step, stepErr := time.ParseDuration(*optStep)
+	tolerance, toleranceErr := strconv.ParseFloat(*optTolerance, 64)
+	if rangeErr != nil ||
+		stepErr != nil ||
+		toleranceErr != nil ||
+		sampleRange < step ||
+		step < time.Second ||
+		tolerance < 0 ||
+		*optRules == "" ||
+		*optStart <= 0 ||
+		*optEnd <= *optStart ||
+		*optSamples <= 0 ||
+		*optSeries <= 0 {
+		getopt.Usage()
+		os.Exit(1)
+	}
+
+	data, err := ioutil.ReadFile(*optRules)
+	if err != nil {
+		logger.Fatalf("unable to read rules file: %+v", err)
+	}
+	var cfg rulesConfiguration
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		logger.Fatalf("unable to parse rules file: %+v", err)
+	}
+
+	seed := *optSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	c := &checker{
+		client:          &http.Client{},
+		coordinator:     strings.TrimSuffix(*optCoordinator, "/"),
+		storagePolicy:   *optStoragePolicy,
+		step:            step,
+		seriesPerSample: *optSeries,
+		tolerance:       tolerance,
+		rnd:             rand.New(rand.NewSource(seed)), // nolint: gosec
+	}
+
+	var (
+		start  = time.Unix(*optStart, 0)
+		end    = time.Unix(*optEnd, 0)
+		failed int
+	)
+	fmt.Printf("Sampling seed: %d\n", seed) // nolint: forbidigo
+	for _, r := range cfg.Rules {
+		ranges := c.sampleRanges(start, end, *optSamples, sampleRange)
+		report, err := c.checkRule(r, ranges)
+		if err != nil {
+			logger.Errorf("unable to verify rule %s: %+v", r.Name, err)
+			failed++
+			continue
+		}
+
+		status := "OK"
+		if report.failed() {
+			status = "MISMATCH"
+			failed++
+		}
+		// nolint: forbidigo
+		fmt.Printf("%s - %s: samples=%d series=%d points=%d mismatches=%d "+
+			"missing_series=%d missing_points=%d max_relative_error=%g\n",
+			report.rule, status, report.samples, report.series, report.points,
+			report.mismatches, report.missingSeries, report.missingPoints,
+			report.maxRelativeError)
+	}
+
+	if failed > 0 {
+		logger.Fatalf("%d of %d rules failed verification", failed, len(cfg.Rules))
+	}
+}
@@ -0,0 +1,447 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const restoreDirMode = 0755
+
+var errAgentAlreadyOpen = errors.New("backup agent already open")
+
+type agentMetrics struct {
+	shardsUploaded     tally.Counter
+	shardUploadErrors  tally.Counter
+	filesUploaded      tally.Counter
+	shardsRestored     tally.Counter
+	shardRestoreErrors tally.Counter
+	filesRestored      tally.Counter
+}
+
+func newAgentMetrics(scope tally.Scope) agentMetrics {
+	uploadScope := scope.SubScope("upload")
+	restoreScope := scope.SubScope("restore")
+	return agentMetrics{
+		shardsUploaded: uploadScope.Tagged(map[string]string{
+			"result": "success",
+		}).Counter("shards"),
+		shardUploadErrors: uploadScope.Tagged(map[string]string{
+			"result": "error",
+		}).Counter("shards"),
+		filesUploaded: uploadScope.Counter("files"),
+		shardsRestored: restoreScope.Tagged(map[string]string{
+			"result": "success",
+		}).Counter("shards"),
+		shardRestoreErrors: restoreScope.Tagged(map[string]string{
+			"result": "error",
+		}).Counter("shards"),
+		filesRestored: restoreScope.Counter("files"),
+	}
+}
+
+type agent struct {
+	sync.Mutex
+
+	opts    Options
+	store   Store
+	objects ObjectStore
+	hostID  string
+	logger  *zap.Logger
+	metrics agentMetrics
+	nowFn   func() time.Time
+
+	open    bool
+	closeCh chan struct{}
+	doneCh  chan struct{}
+}
+
+// NewAgent returns a new backup agent for the local node.
+func NewAgent(opts Options) (Agent, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	iOpts := opts.InstrumentOptions()
+	return &agent{
+		opts:    opts,
+		store:   opts.Store(),
+		objects: opts.ObjectStore(),
+		hostID:  opts.HostID(),
+		logger:  iOpts.Logger(),
+		metrics: newAgentMetrics(iOpts.MetricsScope().SubScope("backup-agent")),
+		nowFn:   opts.ClockOptions().NowFn(),
+	}, nil
+}
+
+func (a *agent) Open() error {
+	a.Lock()
+	defer a.Unlock()
+	if a.open {
+		return errAgentAlreadyOpen
+	}
+	a.open = true
+	a.closeCh = make(chan struct{})
+	a.doneCh = make(chan struct{})
+	go a.processUntilClosed(a.closeCh, a.doneCh)
+	return nil
+}
+
+func (a *agent) Close() error {
+	a.Lock()
+	if !a.open {
+		a.Unlock()
+		return nil
+	}
+	a.open = false
+	close(a.closeCh)
+	doneCh := a.doneCh
+	a.Unlock()
+
+	<-doneCh
+	return nil
+}
+
+func (a *agent) processUntilClosed(closeCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(a.opts.CheckInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closeCh:
+			return
+		case <-ticker.C:
+			a.process()
+		}
+	}
+}
+
+// process uploads and restores the pending shards assigned to the host.
+func (a *agent) process() {
+	manifests, err := a.store.Manifests()
+	if err != nil {
+		a.logger.Error("unable to list backup manifests", zap.Error(err))
+		return
+	}
+	for _, m := range manifests {
+		if m.State != StatePending {
+			continue
+		}
+		for _, s := range m.Shards {
+			if s.Host == a.hostID && s.State == StatePending {
+				a.backupShard(m, s.Shard)
+			}
+		}
+	}
+
+	restores, err := a.store.Restores()
+	if err != nil {
+		a.logger.Error("unable to list backup restores", zap.Error(err))
+		return
+	}
+	for _, r := range restores {
+		if r.State != StatePending {
+			continue
+		}
+		for _, s := range r.Shards {
+			if containsHost(s.Hosts, a.hostID) && !containsHost(s.Restored, a.hostID) {
+				a.restoreShard(r, s.Shard)
+			}
+		}
+	}
+}
+
+func (a *agent) backupShard(m Manifest, shard uint32) {
+	files, ready, err := a.uploadShard(m, shard)
+	if err == nil && !ready {
+		// Wait for a snapshot of the shard taken after the target time.
+		return
+	}
+
+	_, updateErr := a.store.UpdateManifest(m.ID, func(m *Manifest) error {
+		for i := range m.Shards {
+			s := &m.Shards[i]
+			if s.Shard != shard || s.Host != a.hostID || s.State != StatePending {
+				continue
+			}
+			if err != nil {
+				s.State = StateFailed
+				s.Error = err.Error()
+			} else {
+				s.State = StateComplete
+				s.Files = files
+			}
+		}
+		m.UpdatedAt = a.nowFn().UnixNano()
+		return nil
+	})
+	if updateErr != nil {
+		a.logger.Error("unable to update backup manifest",
+			zap.String("manifest", m.ID), zap.Uint32("shard", shard), zap.Error(updateErr))
+		return
+	}
+
+	if err != nil {
+		a.metrics.shardUploadErrors.Inc(1)
+		a.logger.Error("unable to back up shard",
+			zap.String("manifest", m.ID), zap.String("namespace", m.Namespace),
+			zap.Uint32("shard", shard), zap.Error(err))
+		return
+	}
+	a.metrics.shardsUploaded.Inc(1)
+	a.logger.Info("backed up shard",
+		zap.String("manifest", m.ID), zap.String("namespace", m.Namespace),
+		zap.Uint32("shard", shard), zap.Int("files", len(files)))
+}
+
+// uploadShard uploads the filesets of the shard as of the target time of the
+// manifest and returns the keys of the uploaded files. Blocks flushed to disk
+// are uploaded from their latest volume, the remaining blocks from their
+// latest snapshot, which is not ready until a snapshot has been taken at or
+// after the target time.
+func (a *agent) uploadShard(m Manifest, shard uint32) ([]string, bool, error) {
+	filesets, ready, err := shardFileSetsAsOf(a.opts.FilePathPrefix(),
+		ident.StringID(m.Namespace), shard, m.Target())
+	if err != nil || !ready {
+		return nil, ready, err
+	}
+
+	var keys []string
+	for _, fileset := range filesets {
+		for _, filePath := range fileset.AbsoluteFilePaths {
+			key := path.Join(m.Namespace, m.ID, strconv.Itoa(int(shard)), filepath.Base(filePath))
+			if err := a.uploadFile(key, filePath); err != nil {
+				return nil, false, err
+			}
+			a.metrics.filesUploaded.Inc(1)
+			keys = append(keys, key)
+		}
+	}
+	return keys, true, nil
+}
+
+func (a *agent) uploadFile(key, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+
+	return a.objects.Put(key, f)
+}
+
+// shardFileSetsAsOf returns the filesets holding the data of the shard as of
+// the target time and whether a snapshot has been taken since the target time.
+func shardFileSetsAsOf(
+	filePathPrefix string,
+	namespace ident.ID,
+	shard uint32,
+	target xtime.UnixNano,
+) ([]fs.FileSetFile, bool, error) {
+	dataFiles, err := fs.DataFiles(filePathPrefix, namespace, shard)
+	if err != nil {
+		return nil, false, err
+	}
+	snapshotFiles, err := fs.SnapshotFiles(filePathPrefix, namespace, shard)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var (
+		results []fs.FileSetFile
+		flushed = make(map[xtime.UnixNano]struct{})
+	)
+	blockStarts := make(map[xtime.UnixNano]struct{}, len(dataFiles))
+	for _, f := range dataFiles {
+		if !f.ID.BlockStart.After(target) {
+			blockStarts[f.ID.BlockStart] = struct{}{}
+		}
+	}
+	for blockStart := range blockStarts {
+		latest, ok := dataFiles.LatestVolumeForBlock(blockStart)
+		if !ok {
+			continue
+		}
+		flushed[blockStart] = struct{}{}
+		results = append(results, latest)
+	}
+
+	var (
+		ready     bool
+		snapshots = make(map[xtime.UnixNano]fs.FileSetFile)
+	)
+	for _, f := range snapshotFiles {
+		if !f.HasCompleteCheckpointFile() {
+			continue
+		}
+		snapshotTime, _, err := f.SnapshotTimeAndID()
+		if err != nil {
+			return nil, false, err
+		}
+		if !snapshotTime.Before(target) {
+			ready = true
+		}
+
+		blockStart := f.ID.BlockStart
+		if _, ok := flushed[blockStart]; ok || blockStart.After(target) {
+			continue
+		}
+		if curr, ok := snapshots[blockStart]; !ok || f.ID.VolumeIndex > curr.ID.VolumeIndex {
+			snapshots[blockStart] = f
+		}
+	}
+	if !ready {
+		return nil, false, nil
+	}
+
+	for _, f := range snapshots {
+		results = append(results, f)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID.BlockStart.Before(results[j].ID.BlockStart)
+	})
+	return results, true, nil
+}
+
+func (a *agent) restoreShard(r Restore, shard uint32) {
+	files, err := a.downloadShard(r, shard)
+
+	_, updateErr := a.store.UpdateRestore(r.Namespace, func(r *Restore) error {
+		for i := range r.Shards {
+			s := &r.Shards[i]
+			if s.Shard != shard || containsHost(s.Restored, a.hostID) {
+				continue
+			}
+			if err != nil {
+				s.Error = fmt.Sprintf("%s: %v", a.hostID, err)
+			} else {
+				s.Restored = append(s.Restored, a.hostID)
+			}
+		}
+		r.UpdatedAt = a.nowFn().UnixNano()
+		return nil
+	})
+	if updateErr != nil {
+		a.logger.Error("unable to update backup restore",
+			zap.String("namespace", r.Namespace), zap.Uint32("shard", shard), zap.Error(updateErr))
+		return
+	}
+
+	if err != nil {
+		a.metrics.shardRestoreErrors.Inc(1)
+		a.logger.Error("unable to restore shard",
+			zap.String("manifest", r.ManifestID), zap.String("namespace", r.Namespace),
+			zap.Uint32("shard", shard), zap.Error(err))
+		return
+	}
+	a.metrics.shardsRestored.Inc(1)
+	a.logger.Info("restored shard",
+		zap.String("manifest", r.ManifestID), zap.String("namespace", r.Namespace),
+		zap.Uint32("shard", shard), zap.Int("files", files))
+}
+
+// downloadShard downloads the files of the shard into its data directory in
+// the restored namespace. Snapshots are restored as flushed volumes with the
+// same index, which the filesystem bootstrapper then loads like any other.
+func (a *agent) downloadShard(r Restore, shard uint32) (int, error) {
+	m, err := a.store.Manifest(r.ManifestID)
+	if err != nil {
+		return 0, err
+	}
+
+	var keys []string
+	for _, s := range m.Shards {
+		if s.Shard == shard {
+			keys = append(keys, s.Files...)
+		}
+	}
+	// Write checkpoint files last since they mark filesets as complete.
+	sort.SliceStable(keys, func(i, j int) bool {
+		return !isCheckpointFile(keys[i]) && isCheckpointFile(keys[j])
+	})
+
+	dir := fs.ShardDataDirPath(a.opts.FilePathPrefix(), ident.StringID(r.Namespace), shard)
+	if err := os.MkdirAll(dir, restoreDirMode); err != nil {
+		return 0, err
+	}
+	for _, key := range keys {
+		if err := a.downloadFile(key, filepath.Join(dir, path.Base(key))); err != nil {
+			return 0, err
+		}
+		a.metrics.filesRestored.Inc(1)
+	}
+	return len(keys), nil
+}
+
+func (a *agent) downloadFile(key, filePath string) error {
+	src, err := a.objects.Get(key)
+	if err != nil {
+		return fmt.Errorf("unable to get backup object %s: %w", key, err)
+	}
+	defer src.Close() // nolint: errcheck
+
+	dst, err := ioutil.TempFile(filepath.Dir(filePath), filepath.Base(filePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(dst.Name())
+		return err
+	}
+	return os.Rename(dst.Name(), filePath)
+}
+
+func isCheckpointFile(key string) bool {
+	return strings.HasSuffix(key, fs.CheckpointFileSuffix+".db")
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
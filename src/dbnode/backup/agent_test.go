@@ -0,0 +1,333 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/dbnode/namespace/kvadmin"
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/x/checked"
+	"github.com/m3db/m3/src/x/ident"
+	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testAgentBlockSize = 2 * time.Hour
+
+var errTestObjectUnavailable = errors.New("backup object unavailable")
+
+// failingObjectStore fails gets of the keys with the given suffix.
+type failingObjectStore struct {
+	ObjectStore
+
+	failSuffix string
+}
+
+func (s *failingObjectStore) Get(key string) (io.ReadCloser, error) {
+	if s.failSuffix != "" && strings.HasSuffix(key, s.failSuffix) {
+		return nil, errTestObjectUnavailable
+	}
+	return s.ObjectStore.Get(key)
+}
+
+func newTestAgent(
+	t *testing.T,
+	hostID string,
+	filePathPrefix string,
+	store Store,
+	objects ObjectStore,
+) *agent {
+	ctrl := xtest.NewController(t)
+	opts := NewOptions().
+		SetHostID(hostID).
+		SetFilePathPrefix(filePathPrefix).
+		SetStore(store).
+		SetObjectStore(objects).
+		SetTopology(topology.NewMockTopology(ctrl)).
+		SetNamespaceAdminService(kvadmin.NewAdminService(mem.NewStore(), "", nil)).
+		SetLeaderService(services.NewMockLeaderService(ctrl))
+	a, err := NewAgent(opts)
+	require.NoError(t, err)
+	return a.(*agent)
+}
+
+func writeTestFileSet(
+	t *testing.T,
+	filePathPrefix string,
+	namespace string,
+	blockStart xtime.UnixNano,
+	volume int,
+	snapshotTime xtime.UnixNano,
+	ids ...string,
+) {
+	writer, err := fs.NewWriter(fs.NewOptions().SetFilePathPrefix(filePathPrefix))
+	require.NoError(t, err)
+
+	opts := fs.DataWriterOpenOptions{
+		FileSetType: persist.FileSetFlushType,
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:   ident.StringID(namespace),
+			Shard:       0,
+			BlockStart:  blockStart,
+			VolumeIndex: volume,
+		},
+		BlockSize: testAgentBlockSize,
+	}
+	if snapshotTime != 0 {
+		opts.FileSetType = persist.FileSetSnapshotType
+		opts.Snapshot.SnapshotTime = snapshotTime
+	}
+	require.NoError(t, writer.Open(opts))
+	for _, id := range ids {
+		data := checked.NewBytes([]byte(id), nil)
+		data.IncRef()
+		metadata := persist.NewMetadataFromIDAndTags(ident.StringID(id),
+			ident.NewTags(ident.StringTag("name", id)), persist.MetadataOptions{})
+		require.NoError(t, writer.Write(metadata, data, 0))
+	}
+	require.NoError(t, writer.Close())
+}
+
+func requireSameFiles(t *testing.T, expected, actual []string) {
+	require.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		require.Equal(t, filepath.Base(expected[i]), filepath.Base(actual[i]))
+		expectedData, err := ioutil.ReadFile(expected[i])
+		require.NoError(t, err)
+		actualData, err := ioutil.ReadFile(actual[i])
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(expectedData, actualData), actual[i])
+	}
+}
+
+func TestAgentBackupAndRestoreShard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		sourcePrefix  = path.Join(dir, "source")
+		restorePrefix = path.Join(dir, "restore")
+		store         = NewKVStore(mem.NewStore(), "backup.")
+		objects       = NewDirObjectStore(path.Join(dir, "backups"))
+		target        = xtime.Now().Truncate(testAgentBlockSize)
+		flushedBlock  = target.Add(-testAgentBlockSize)
+	)
+
+	// A flushed block with two volumes, only the latest is backed up, the
+	// block containing the target time is backed up from its snapshot and the
+	// block after the target time is not backed up.
+	writeTestFileSet(t, sourcePrefix, "metrics", flushedBlock, 0, 0, "foo")
+	writeTestFileSet(t, sourcePrefix, "metrics", flushedBlock, 1, 0, "foo", "bar")
+	writeTestFileSet(t, sourcePrefix, "metrics", target, 0, target.Add(time.Minute), "baz")
+	writeTestFileSet(t, sourcePrefix, "metrics", target.Add(testAgentBlockSize), 0, 0, "qux")
+
+	filesets, ready, err := shardFileSetsAsOf(sourcePrefix, ident.StringID("metrics"), 0, target)
+	require.NoError(t, err)
+	require.True(t, ready)
+	require.Equal(t, 2, len(filesets))
+	require.Equal(t, flushedBlock, filesets[0].ID.BlockStart)
+	require.Equal(t, 1, filesets[0].ID.VolumeIndex)
+	require.Equal(t, target, filesets[1].ID.BlockStart)
+
+	require.NoError(t, store.CreateManifest(Manifest{
+		ID:         "m",
+		Namespace:  "metrics",
+		TargetTime: int64(target),
+		State:      StatePending,
+		Shards:     []ShardBackup{{Shard: 0, Host: "h1", State: StatePending}},
+	}))
+	newTestAgent(t, "h1", sourcePrefix, store, objects).process()
+
+	m, err := store.Manifest("m")
+	require.NoError(t, err)
+	require.Equal(t, StateComplete, m.Shards[0].State, m.Shards[0].Error)
+	var uploaded []string
+	for _, fileset := range filesets {
+		uploaded = append(uploaded, fileset.AbsoluteFilePaths...)
+	}
+	require.Equal(t, len(uploaded), len(m.Shards[0].Files))
+	for _, key := range m.Shards[0].Files {
+		require.True(t, strings.HasPrefix(key, "metrics/m/0/"), key)
+	}
+
+	// Restore into an empty data directory of another host.
+	require.NoError(t, store.CreateRestore(Restore{
+		ManifestID: "m",
+		Namespace:  "restored",
+		State:      StatePending,
+		Shards:     []ShardRestore{{Shard: 0, Hosts: []string{"h2"}}},
+	}))
+	newTestAgent(t, "h2", restorePrefix, store, objects).process()
+
+	r, err := store.Restore("restored")
+	require.NoError(t, err)
+	require.Empty(t, r.Shards[0].Error)
+	require.Equal(t, []string{"h2"}, r.Shards[0].Restored)
+	require.True(t, r.Complete())
+
+	// The snapshot is restored as a flushed volume with the same index.
+	restored, err := fs.DataFiles(restorePrefix, ident.StringID("restored"), 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(restored))
+	for i, fileset := range restored {
+		require.True(t, fileset.HasCompleteCheckpointFile())
+		require.Equal(t, filesets[i].ID.BlockStart, fileset.ID.BlockStart)
+		require.Equal(t, filesets[i].ID.VolumeIndex, fileset.ID.VolumeIndex)
+		requireSameFiles(t, filesets[i].AbsoluteFilePaths, fileset.AbsoluteFilePaths)
+	}
+}
+
+func TestAgentBackupWaitsForSnapshotAfterTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		store  = NewKVStore(mem.NewStore(), "backup.")
+		target = xtime.Now().Truncate(testAgentBlockSize)
+	)
+	writeTestFileSet(t, dir, "metrics", target, 0, target.Add(-time.Minute), "foo")
+
+	_, ready, err := shardFileSetsAsOf(dir, ident.StringID("metrics"), 0, target)
+	require.NoError(t, err)
+	require.False(t, ready)
+
+	require.NoError(t, store.CreateManifest(Manifest{
+		ID:         "m",
+		Namespace:  "metrics",
+		TargetTime: int64(target),
+		State:      StatePending,
+		Shards:     []ShardBackup{{Shard: 0, Host: "h1", State: StatePending}},
+	}))
+	newTestAgent(t, "h1", dir, store, NewDirObjectStore(path.Join(dir, "backups"))).process()
+
+	m, err := store.Manifest("m")
+	require.NoError(t, err)
+	require.Equal(t, StatePending, m.Shards[0].State)
+	require.Empty(t, m.Shards[0].Files)
+}
+
+func TestAgentRestoreShardPartialFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		sourcePrefix  = path.Join(dir, "source")
+		restorePrefix = path.Join(dir, "restore")
+		store         = NewKVStore(mem.NewStore(), "backup.")
+		objects       = NewDirObjectStore(path.Join(dir, "backups"))
+		target        = xtime.Now().Truncate(testAgentBlockSize)
+	)
+	writeTestFileSet(t, sourcePrefix, "metrics", target.Add(-testAgentBlockSize), 0, 0, "foo")
+	writeTestFileSet(t, sourcePrefix, "metrics", target, 0, target.Add(time.Minute), "bar")
+
+	require.NoError(t, store.CreateManifest(Manifest{
+		ID:         "m",
+		Namespace:  "metrics",
+		TargetTime: int64(target),
+		State:      StatePending,
+		Shards:     []ShardBackup{{Shard: 0, Host: "h1", State: StatePending}},
+	}))
+	newTestAgent(t, "h1", sourcePrefix, store, objects).process()
+
+	require.NoError(t, store.CreateRestore(Restore{
+		ManifestID: "m",
+		Namespace:  "restored",
+		State:      StatePending,
+		Shards:     []ShardRestore{{Shard: 0, Hosts: []string{"h2"}}},
+	}))
+
+	// Fail the download of the data files midway through the restore.
+	failing := &failingObjectStore{ObjectStore: objects, failSuffix: "-data.db"}
+	a := newTestAgent(t, "h2", restorePrefix, store, failing)
+	a.process()
+
+	r, err := store.Restore("restored")
+	require.NoError(t, err)
+	require.Empty(t, r.Shards[0].Restored)
+	require.Contains(t, r.Shards[0].Error, "h2")
+	require.Contains(t, r.Shards[0].Error, errTestObjectUnavailable.Error())
+	require.False(t, r.Complete())
+
+	// Checkpoint files are downloaded last so no partially restored fileset
+	// is complete, and no temporary files are left behind.
+	restored, err := fs.DataFiles(restorePrefix, ident.StringID("restored"), 0)
+	require.NoError(t, err)
+	for _, fileset := range restored {
+		require.False(t, fileset.HasCompleteCheckpointFile())
+	}
+	entries, err := ioutil.ReadDir(fs.ShardDataDirPath(restorePrefix, ident.StringID("restored"), 0))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.False(t, strings.Contains(entry.Name(), ".tmp"), entry.Name())
+	}
+
+	// The restore of the shard is retried once the files are available.
+	failing.failSuffix = ""
+	a.process()
+
+	r, err = store.Restore("restored")
+	require.NoError(t, err)
+	require.Equal(t, []string{"h2"}, r.Shards[0].Restored)
+	restored, err = fs.DataFiles(restorePrefix, ident.StringID("restored"), 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(restored))
+	for _, fileset := range restored {
+		require.True(t, fileset.HasCompleteCheckpointFile())
+	}
+}
+
+func TestAgentRestoreShardMissingManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := NewKVStore(mem.NewStore(), "backup.")
+	require.NoError(t, store.CreateRestore(Restore{
+		ManifestID: "missing",
+		Namespace:  "restored",
+		State:      StatePending,
+		Shards:     []ShardRestore{{Shard: 0, Hosts: []string{"h2"}}},
+	}))
+	newTestAgent(t, "h2", dir, store, NewDirObjectStore(path.Join(dir, "backups"))).process()
+
+	r, err := store.Restore("restored")
+	require.NoError(t, err)
+	require.Empty(t, r.Shards[0].Restored)
+	require.Equal(t, "h2: "+ErrNotFound.Error(), r.Shards[0].Error)
+}
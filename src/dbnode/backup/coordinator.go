@@ -0,0 +1,586 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/cluster/services/leader/campaign"
+	"github.com/m3db/m3/src/cluster/shard"
+	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
+	"github.com/m3db/m3/src/dbnode/namespace/kvadmin"
+	"github.com/m3db/m3/src/dbnode/topology"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/pborman/uuid"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const manifestObjectName = "manifest.json"
+
+var errCoordinatorAlreadyOpen = errors.New("backup coordinator already open")
+
+type coordinatorMetrics struct {
+	leader           tally.Gauge
+	backupsStarted   tally.Counter
+	backupsComplete  tally.Counter
+	backupsFailed    tally.Counter
+	restoresStarted  tally.Counter
+	restoresComplete tally.Counter
+	restoresFailed   tally.Counter
+}
+
+func newCoordinatorMetrics(scope tally.Scope) coordinatorMetrics {
+	backupScope := scope.SubScope("backups")
+	restoreScope := scope.SubScope("restores")
+	return coordinatorMetrics{
+		leader:         scope.Gauge("leader"),
+		backupsStarted: backupScope.Counter("started"),
+		backupsComplete: backupScope.Tagged(map[string]string{
+			"result": "complete",
+		}).Counter("finished"),
+		backupsFailed: backupScope.Tagged(map[string]string{
+			"result": "failed",
+		}).Counter("finished"),
+		restoresStarted: restoreScope.Counter("started"),
+		restoresComplete: restoreScope.Tagged(map[string]string{
+			"result": "complete",
+		}).Counter("finished"),
+		restoresFailed: restoreScope.Tagged(map[string]string{
+			"result": "failed",
+		}).Counter("finished"),
+	}
+}
+
+type coordinator struct {
+	sync.RWMutex
+
+	opts          Options
+	store         Store
+	objects       ObjectStore
+	topo          topology.Topology
+	nsAdmin       kvadmin.NamespaceMetadataAdminService
+	leaderService services.LeaderService
+	electionID    string
+	hostID        string
+	logger        *zap.Logger
+	metrics       coordinatorMetrics
+	nowFn         func() time.Time
+
+	leader  bool
+	open    bool
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewCoordinator returns a new backup coordinator.
+func NewCoordinator(opts Options) (Coordinator, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	iOpts := opts.InstrumentOptions()
+	return &coordinator{
+		opts:          opts,
+		store:         opts.Store(),
+		objects:       opts.ObjectStore(),
+		topo:          opts.Topology(),
+		nsAdmin:       opts.NamespaceAdminService(),
+		leaderService: opts.LeaderService(),
+		electionID:    opts.ElectionID(),
+		hostID:        opts.HostID(),
+		logger:        iOpts.Logger(),
+		metrics:       newCoordinatorMetrics(iOpts.MetricsScope().SubScope("backup-coordinator")),
+		nowFn:         opts.ClockOptions().NowFn(),
+	}, nil
+}
+
+func (c *coordinator) Open() error {
+	c.Lock()
+	defer c.Unlock()
+	if c.open {
+		return errCoordinatorAlreadyOpen
+	}
+	c.open = true
+	c.closeCh = make(chan struct{})
+	c.wg.Add(2)
+	go c.campaignUntilClosed(c.closeCh)
+	go c.checkUntilClosed(c.closeCh)
+	return nil
+}
+
+func (c *coordinator) Close() error {
+	c.Lock()
+	if !c.open {
+		c.Unlock()
+		return nil
+	}
+	c.open = false
+	close(c.closeCh)
+	c.Unlock()
+
+	c.wg.Wait()
+	return nil
+}
+
+func (c *coordinator) Leader() (string, error) {
+	return c.leaderService.Leader(c.electionID)
+}
+
+func (c *coordinator) isLeader() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.leader
+}
+
+func (c *coordinator) setLeader(leader bool) {
+	c.Lock()
+	c.leader = leader
+	c.Unlock()
+
+	if leader {
+		c.metrics.leader.Update(1)
+	} else {
+		c.metrics.leader.Update(0)
+	}
+}
+
+// campaignUntilClosed campaigns for leadership, campaigning again whenever
+// the campaign is invalidated, until the coordinator is closed.
+func (c *coordinator) campaignUntilClosed(closeCh chan struct{}) {
+	defer c.wg.Done()
+
+	for {
+		if err := c.campaign(closeCh); err != nil {
+			c.logger.Error("backup coordinator campaign failed", zap.Error(err))
+		}
+		c.setLeader(false)
+
+		select {
+		case <-closeCh:
+			return
+		case <-time.After(c.opts.CheckInterval()):
+		}
+	}
+}
+
+func (c *coordinator) campaign(closeCh chan struct{}) error {
+	campaignOpts, err := services.NewCampaignOptions()
+	if err != nil {
+		return err
+	}
+	statusCh, err := c.leaderService.Campaign(c.electionID,
+		campaignOpts.SetLeaderValue(c.hostID))
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-closeCh:
+			err := c.leaderService.Resign(c.electionID)
+			// NB: The status channel must be consumed until it is closed.
+			for range statusCh {
+			}
+			return err
+		case status, ok := <-statusCh:
+			if !ok {
+				return nil
+			}
+			if status.State == campaign.Error {
+				c.logger.Error("backup coordinator campaign error", zap.Error(status.Err))
+			}
+			c.setLeader(status.State == campaign.Leader)
+		}
+	}
+}
+
+func (c *coordinator) checkUntilClosed(closeCh chan struct{}) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.opts.CheckInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closeCh:
+			return
+		case <-ticker.C:
+			if c.isLeader() {
+				c.check()
+			}
+		}
+	}
+}
+
+// check completes or fails pending backups and restores.
+func (c *coordinator) check() {
+	manifests, err := c.store.Manifests()
+	if err != nil {
+		c.logger.Error("unable to list backup manifests", zap.Error(err))
+	}
+	for _, m := range manifests {
+		if m.State == StatePending {
+			c.checkBackup(m)
+		}
+	}
+
+	restores, err := c.store.Restores()
+	if err != nil {
+		c.logger.Error("unable to list backup restores", zap.Error(err))
+	}
+	for _, r := range restores {
+		if r.State == StatePending {
+			c.checkRestore(r)
+		}
+	}
+}
+
+func (c *coordinator) checkBackup(m Manifest) {
+	now := c.nowFn()
+
+	var failure error
+	for _, s := range m.Shards {
+		if s.State == StateFailed {
+			failure = fmt.Errorf("shard %d failed on host %s: %s", s.Shard, s.Host, s.Error)
+			break
+		}
+	}
+	complete := failure == nil && m.Complete()
+	if !complete && failure == nil && now.Sub(time.Unix(0, m.CreatedAt)) > c.opts.Timeout() {
+		failure = fmt.Errorf("backup timed out after %v", c.opts.Timeout())
+	}
+	if !complete && failure == nil {
+		return
+	}
+
+	if complete {
+		// Keep the manifest alongside the uploaded files so that backups can
+		// be located without access to the kv store.
+		m.State = StateComplete
+		m.UpdatedAt = now.UnixNano()
+		if err := c.putManifestObject(m); err != nil {
+			c.logger.Error("unable to upload backup manifest",
+				zap.String("manifest", m.ID), zap.Error(err))
+			return
+		}
+	}
+
+	_, err := c.store.UpdateManifest(m.ID, func(m *Manifest) error {
+		m.UpdatedAt = now.UnixNano()
+		if failure != nil {
+			m.State = StateFailed
+			m.Error = failure.Error()
+		} else {
+			m.State = StateComplete
+		}
+		return nil
+	})
+	if err != nil {
+		c.logger.Error("unable to update backup manifest",
+			zap.String("manifest", m.ID), zap.Error(err))
+		return
+	}
+
+	if failure != nil {
+		c.metrics.backupsFailed.Inc(1)
+		c.logger.Error("backup failed", zap.String("manifest", m.ID),
+			zap.String("namespace", m.Namespace), zap.Error(failure))
+		return
+	}
+	c.metrics.backupsComplete.Inc(1)
+	c.logger.Info("backup complete", zap.String("manifest", m.ID),
+		zap.String("namespace", m.Namespace))
+}
+
+func (c *coordinator) putManifestObject(m Manifest) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return c.objects.Put(path.Join(m.Namespace, m.ID, manifestObjectName), bytes.NewReader(b))
+}
+
+func (c *coordinator) checkRestore(r Restore) {
+	now := c.nowFn()
+
+	var failure error
+	complete := r.Complete()
+	if complete {
+		// Only register the namespace once every replica holds its data so
+		// that it is bootstrapped from the restored filesets.
+		failure = c.registerRestoredNamespace(r)
+	} else if now.Sub(time.Unix(0, r.CreatedAt)) > c.opts.Timeout() {
+		failure = fmt.Errorf("restore timed out after %v", c.opts.Timeout())
+	} else {
+		return
+	}
+
+	_, err := c.store.UpdateRestore(r.Namespace, func(r *Restore) error {
+		r.UpdatedAt = now.UnixNano()
+		if failure != nil {
+			r.State = StateFailed
+			r.Error = failure.Error()
+		} else {
+			r.State = StateComplete
+		}
+		return nil
+	})
+	if err != nil {
+		c.logger.Error("unable to update backup restore",
+			zap.String("namespace", r.Namespace), zap.Error(err))
+		return
+	}
+
+	if failure != nil {
+		c.metrics.restoresFailed.Inc(1)
+		c.logger.Error("restore failed", zap.String("manifest", r.ManifestID),
+			zap.String("namespace", r.Namespace), zap.Error(failure))
+		return
+	}
+	c.metrics.restoresComplete.Inc(1)
+	c.logger.Info("restore complete", zap.String("manifest", r.ManifestID),
+		zap.String("namespace", r.Namespace))
+}
+
+func (c *coordinator) registerRestoredNamespace(r Restore) error {
+	m, err := c.store.Manifest(r.ManifestID)
+	if err != nil {
+		return err
+	}
+
+	var nsOpts nsproto.NamespaceOptions
+	if err := nsOpts.Unmarshal(m.NamespaceOptions); err != nil {
+		return fmt.Errorf("unable to decode namespace options of backup: %w", err)
+	}
+	nsOpts.StagingState = &nsproto.StagingState{Status: nsproto.StagingStatus_READY}
+	return c.nsAdmin.Add(r.Namespace, &nsOpts)
+}
+
+func (c *coordinator) Backup(namespace string, target xtime.UnixNano) (Manifest, error) {
+	if !c.isLeader() {
+		return Manifest{}, ErrNotLeader
+	}
+
+	nsOpts, err := c.nsAdmin.Get(namespace)
+	if err == kvadmin.ErrNamespaceNotFound {
+		return Manifest{}, xerrors.NewInvalidParamsError(
+			fmt.Errorf("namespace %s not found", namespace))
+	}
+	if err != nil {
+		return Manifest{}, err
+	}
+	encodedOpts, err := nsOpts.Marshal()
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	shards, err := assignShards(c.topo.Get())
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	now := c.nowFn()
+	if target == 0 {
+		target = xtime.ToUnixNano(now)
+	}
+	m := Manifest{
+		ID:               uuid.New(),
+		Namespace:        namespace,
+		NamespaceOptions: encodedOpts,
+		TargetTime:       int64(target),
+		CreatedAt:        now.UnixNano(),
+		UpdatedAt:        now.UnixNano(),
+		State:            StatePending,
+		Shards:           shards,
+	}
+	if err := c.store.CreateManifest(m); err != nil {
+		return Manifest{}, err
+	}
+
+	c.metrics.backupsStarted.Inc(1)
+	c.logger.Info("backup started", zap.String("manifest", m.ID),
+		zap.String("namespace", namespace), zap.Time("target", target.ToTime()),
+		zap.Int("shards", len(shards)))
+	return m, nil
+}
+
+// assignShards assigns every shard to one of its available replicas,
+// spreading the shards evenly across the replicas.
+func assignShards(topoMap topology.Map) ([]ShardBackup, error) {
+	var (
+		ids      = topoMap.ShardSet().AllIDs()
+		assigned = make(map[string]int, topoMap.HostsLen())
+		results  = make([]ShardBackup, 0, len(ids))
+	)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		var host string
+		err := topoMap.RouteShardForEach(id, func(_ int, s shard.Shard, h topology.Host) {
+			if s.State() != shard.Available {
+				return
+			}
+			var (
+				hostID    = h.ID()
+				fewer     = assigned[hostID] < assigned[host]
+				tieBroken = assigned[hostID] == assigned[host] && hostID < host
+			)
+			if host == "" || fewer || tieBroken {
+				host = hostID
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		if host == "" {
+			return nil, fmt.Errorf("shard %d has no available replica to back up", id)
+		}
+		assigned[host]++
+		results = append(results, ShardBackup{
+			Shard: id,
+			Host:  host,
+			State: StatePending,
+		})
+	}
+	return results, nil
+}
+
+func (c *coordinator) Restore(manifestID, namespace string) (Restore, error) {
+	if !c.isLeader() {
+		return Restore{}, ErrNotLeader
+	}
+
+	m, err := c.store.Manifest(manifestID)
+	if err == ErrNotFound {
+		return Restore{}, xerrors.NewInvalidParamsError(
+			fmt.Errorf("backup manifest %s not found", manifestID))
+	}
+	if err != nil {
+		return Restore{}, err
+	}
+	if m.State != StateComplete {
+		return Restore{}, xerrors.NewInvalidParamsError(
+			fmt.Errorf("backup manifest %s is %s", manifestID, m.State))
+	}
+
+	_, err = c.nsAdmin.Get(namespace)
+	if err == nil {
+		return Restore{}, xerrors.NewInvalidParamsError(
+			fmt.Errorf("namespace %s already exists", namespace))
+	}
+	if err != kvadmin.ErrNamespaceNotFound {
+		return Restore{}, err
+	}
+
+	topoMap := c.topo.Get()
+	if err := ValidateCoverage(m, topoMap.ShardSet().AllIDs()); err != nil {
+		return Restore{}, xerrors.NewInvalidParamsError(err)
+	}
+
+	shards, err := restoreShards(topoMap)
+	if err != nil {
+		return Restore{}, err
+	}
+
+	now := c.nowFn()
+	r := Restore{
+		ManifestID: manifestID,
+		Namespace:  namespace,
+		CreatedAt:  now.UnixNano(),
+		UpdatedAt:  now.UnixNano(),
+		State:      StatePending,
+		Shards:     shards,
+	}
+	if err := c.store.CreateRestore(r); err != nil {
+		return Restore{}, err
+	}
+
+	c.metrics.restoresStarted.Inc(1)
+	c.logger.Info("restore started", zap.String("manifest", manifestID),
+		zap.String("namespace", namespace), zap.Int("shards", len(shards)))
+	return r, nil
+}
+
+// ValidateCoverage returns an error unless the manifest holds a complete
+// backup of exactly the given shards.
+func ValidateCoverage(m Manifest, shards []uint32) error {
+	backedUp := make(map[uint32]struct{}, len(m.Shards))
+	for _, s := range m.Shards {
+		if s.State == StateComplete {
+			backedUp[s.Shard] = struct{}{}
+		}
+	}
+
+	var missing []uint32
+	for _, id := range shards {
+		if _, ok := backedUp[id]; !ok {
+			missing = append(missing, id)
+		}
+		delete(backedUp, id)
+	}
+	if len(missing) > 0 {
+		sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+		return fmt.Errorf("backup manifest %s is missing shards %v", m.ID, missing)
+	}
+	if len(backedUp) > 0 {
+		return fmt.Errorf("backup manifest %s has %d shards not in the placement",
+			m.ID, len(backedUp))
+	}
+	return nil
+}
+
+// restoreShards returns the shards to restore to each of their replicas
+// that are not leaving.
+func restoreShards(topoMap topology.Map) ([]ShardRestore, error) {
+	ids := topoMap.ShardSet().AllIDs()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	results := make([]ShardRestore, 0, len(ids))
+	for _, id := range ids {
+		var hosts []string
+		err := topoMap.RouteShardForEach(id, func(_ int, s shard.Shard, h topology.Host) {
+			if s.State() != shard.Leaving {
+				hosts = append(hosts, h.ID())
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(hosts) == 0 {
+			return nil, fmt.Errorf("shard %d has no replica to restore to", id)
+		}
+		sort.Strings(hosts)
+		results = append(results, ShardRestore{
+			Shard: id,
+			Hosts: hosts,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/cluster/shard"
+	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/namespace/kvadmin"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/topology/testutil"
+	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCoordinator(t *testing.T) (*coordinator, Store, string, func()) {
+	ctrl := xtest.NewController(t)
+
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+
+	topoMap := testutil.MustNewTopologyMap(2, map[string][]shard.Shard{
+		"h1": testutil.ShardsRange(0, 2, shard.Available),
+		"h2": append(testutil.ShardsRange(0, 1, shard.Available),
+			testutil.Shards([]uint32{2}, shard.Initializing)...),
+	})
+	topo := topology.NewMockTopology(ctrl)
+	topo.EXPECT().Get().Return(topoMap).AnyTimes()
+
+	kvStore := mem.NewStore()
+	nsAdmin := kvadmin.NewAdminService(kvStore, "", nil)
+	nsOpts, err := namespace.OptionsToProto(namespace.NewOptions())
+	require.NoError(t, err)
+	require.NoError(t, nsAdmin.Add("metrics", nsOpts))
+
+	store := NewKVStore(kvStore, "backup.")
+	opts := NewOptions().
+		SetHostID("h1").
+		SetFilePathPrefix(dir).
+		SetStore(store).
+		SetObjectStore(NewDirObjectStore(path.Join(dir, "backups"))).
+		SetTopology(topo).
+		SetNamespaceAdminService(nsAdmin).
+		SetLeaderService(services.NewMockLeaderService(ctrl))
+	c, err := NewCoordinator(opts)
+	require.NoError(t, err)
+
+	return c.(*coordinator), store, dir, func() {
+		ctrl.Finish()
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func TestCoordinatorRequiresLeadership(t *testing.T) {
+	c, _, _, cleanup := newTestCoordinator(t)
+	defer cleanup()
+
+	_, err := c.Backup("metrics", 0)
+	require.Equal(t, ErrNotLeader, err)
+	_, err = c.Restore("id", "restored")
+	require.Equal(t, ErrNotLeader, err)
+}
+
+func TestCoordinatorBackupAndRestore(t *testing.T) {
+	c, store, dir, cleanup := newTestCoordinator(t)
+	defer cleanup()
+	c.setLeader(true)
+
+	target := xtime.UnixNano(1000)
+	m, err := c.Backup("metrics", target)
+	require.NoError(t, err)
+	require.Equal(t, target, m.Target())
+	require.Equal(t, []ShardBackup{
+		{Shard: 0, Host: "h1", State: StatePending},
+		{Shard: 1, Host: "h2", State: StatePending},
+		{Shard: 2, Host: "h1", State: StatePending},
+	}, m.Shards)
+
+	// Restoring is not allowed until the backup is complete.
+	_, err = c.Restore(m.ID, "restored")
+	require.Error(t, err)
+
+	_, err = store.UpdateManifest(m.ID, func(m *Manifest) error {
+		for i := range m.Shards {
+			m.Shards[i].State = StateComplete
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	c.check()
+
+	m, err = store.Manifest(m.ID)
+	require.NoError(t, err)
+	require.Equal(t, StateComplete, m.State)
+	_, err = os.Stat(path.Join(dir, "backups", "metrics", m.ID, manifestObjectName))
+	require.NoError(t, err)
+
+	// Restoring into an existing namespace is not allowed.
+	_, err = c.Restore(m.ID, "metrics")
+	require.Error(t, err)
+
+	r, err := c.Restore(m.ID, "restored")
+	require.NoError(t, err)
+	require.Equal(t, []ShardRestore{
+		{Shard: 0, Hosts: []string{"h1", "h2"}},
+		{Shard: 1, Hosts: []string{"h1", "h2"}},
+		{Shard: 2, Hosts: []string{"h1", "h2"}},
+	}, r.Shards)
+
+	_, err = c.nsAdmin.Get("restored")
+	require.Equal(t, kvadmin.ErrNamespaceNotFound, err)
+
+	_, err = store.UpdateRestore("restored", func(r *Restore) error {
+		for i := range r.Shards {
+			r.Shards[i].Restored = r.Shards[i].Hosts
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	c.check()
+
+	r, err = store.Restore("restored")
+	require.NoError(t, err)
+	require.Equal(t, StateComplete, r.State)
+
+	nsOpts, err := c.nsAdmin.Get("restored")
+	require.NoError(t, err)
+	require.Equal(t, nsproto.StagingStatus_READY, nsOpts.GetStagingState().GetStatus())
+}
+
+func TestCoordinatorBackupFailsOnShardFailure(t *testing.T) {
+	c, store, _, cleanup := newTestCoordinator(t)
+	defer cleanup()
+	c.setLeader(true)
+
+	m, err := c.Backup("metrics", 0)
+	require.NoError(t, err)
+
+	_, err = store.UpdateManifest(m.ID, func(m *Manifest) error {
+		m.Shards[1].State = StateFailed
+		m.Shards[1].Error = "disk error"
+		return nil
+	})
+	require.NoError(t, err)
+	c.check()
+
+	m, err = store.Manifest(m.ID)
+	require.NoError(t, err)
+	require.Equal(t, StateFailed, m.State)
+	require.Contains(t, m.Error, "disk error")
+}
+
+func TestValidateCoverage(t *testing.T) {
+	m := Manifest{
+		ID: "a",
+		Shards: []ShardBackup{
+			{Shard: 0, State: StateComplete},
+			{Shard: 1, State: StateFailed},
+			{Shard: 2, State: StateComplete},
+		},
+	}
+	require.Error(t, ValidateCoverage(m, []uint32{0, 1, 2}))
+	require.Error(t, ValidateCoverage(m, []uint32{0}))
+
+	m.Shards[1].State = StateComplete
+	require.NoError(t, ValidateCoverage(m, []uint32{2, 1, 0}))
+}
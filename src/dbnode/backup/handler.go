@@ -0,0 +1,161 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	xerrors "github.com/m3db/m3/src/x/errors"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// BackupURL is the URL to start a backup at and to list manifests from.
+	BackupURL = "/backup"
+	// RestoreURL is the URL to start a restore at and to list restores from.
+	RestoreURL = "/backup/restore"
+)
+
+type backupRequest struct {
+	Namespace string `json:"namespace"`
+	// TargetTime is the time to back up the namespace as of, defaults to now.
+	TargetTime *time.Time `json:"targetTime"`
+}
+
+type restoreRequest struct {
+	ManifestID string `json:"manifestId"`
+	Namespace  string `json:"namespace"`
+}
+
+type handler struct {
+	coordinator Coordinator
+	store       Store
+	logger      *zap.Logger
+}
+
+// RegisterHandlers registers the handlers to start and list backups and
+// restores with the mux.
+func RegisterHandlers(
+	mux *http.ServeMux,
+	coordinator Coordinator,
+	store Store,
+	logger *zap.Logger,
+) {
+	h := &handler{
+		coordinator: coordinator,
+		store:       store,
+		logger:      logger,
+	}
+	mux.HandleFunc(BackupURL, h.serveBackup)
+	mux.HandleFunc(RestoreURL, h.serveRestore)
+}
+
+func (h *handler) serveBackup(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if id := r.URL.Query().Get("id"); id != "" {
+			m, err := h.store.Manifest(id)
+			h.writeResponse(w, m, err)
+			return
+		}
+		manifests, err := h.store.Manifests()
+		h.writeResponse(w, manifests, err)
+	case http.MethodPost:
+		var req backupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("unable to parse request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Namespace == "" {
+			http.Error(w, "namespace is required", http.StatusBadRequest)
+			return
+		}
+		var target xtime.UnixNano
+		if req.TargetTime != nil {
+			target = xtime.ToUnixNano(*req.TargetTime)
+		}
+		m, err := h.coordinator.Backup(req.Namespace, target)
+		h.writeResponse(w, m, err)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *handler) serveRestore(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if namespace := r.URL.Query().Get("namespace"); namespace != "" {
+			restore, err := h.store.Restore(namespace)
+			h.writeResponse(w, restore, err)
+			return
+		}
+		restores, err := h.store.Restores()
+		h.writeResponse(w, restores, err)
+	case http.MethodPost:
+		var req restoreRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("unable to parse request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.ManifestID == "" || req.Namespace == "" {
+			http.Error(w, "manifestId and namespace are required", http.StatusBadRequest)
+			return
+		}
+		restore, err := h.coordinator.Restore(req.ManifestID, req.Namespace)
+		h.writeResponse(w, restore, err)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *handler) writeResponse(w http.ResponseWriter, value interface{}, err error) {
+	switch {
+	case err == nil:
+	case err == ErrNotLeader:
+		// Point callers at the leader to retry the request against.
+		leader, leaderErr := h.coordinator.Leader()
+		if leaderErr != nil {
+			leader = "unknown"
+		}
+		http.Error(w, fmt.Sprintf("%v, leader is %s", err, leader), http.StatusServiceUnavailable)
+		return
+	case err == ErrNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err == ErrAlreadyExists || xerrors.IsInvalidParams(err):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		h.logger.Error("backup request failed", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		h.logger.Error("unable to encode backup response", zap.Error(err))
+	}
+}
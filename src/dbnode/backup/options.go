@@ -0,0 +1,309 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package backup
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/dbnode/namespace/kvadmin"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultElectionID    = "m3db-backup-coordinator"
+	defaultCheckInterval = 10 * time.Second
+	defaultTimeout       = time.Hour
+)
+
+var (
+	errHostIDNotSet             = errors.New("backup host id not set")
+	errFilePathPrefixNotSet     = errors.New("backup file path prefix not set")
+	errStoreNotSet              = errors.New("backup store not set")
+	errObjectStoreNotSet        = errors.New("backup object store not set")
+	errTopologyNotSet           = errors.New("backup topology not set")
+	errNamespaceAdminNotSet     = errors.New("backup namespace admin service not set")
+	errLeaderServiceNotSet      = errors.New("backup leader service not set")
+	errCheckIntervalNotPositive = errors.New("backup check interval must be positive")
+	errTimeoutNotPositive       = errors.New("backup timeout must be positive")
+)
+
+// Options are the options for backup coordinators and agents.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetHostID sets the ID of the local host.
+	SetHostID(value string) Options
+
+	// HostID returns the ID of the local host.
+	HostID() string
+
+	// SetFilePathPrefix sets the file path prefix of the local filesets.
+	SetFilePathPrefix(value string) Options
+
+	// FilePathPrefix returns the file path prefix of the local filesets.
+	FilePathPrefix() string
+
+	// SetStore sets the store of manifests and restores.
+	SetStore(value Store) Options
+
+	// Store returns the store of manifests and restores.
+	Store() Store
+
+	// SetObjectStore sets the object store of the uploaded files.
+	SetObjectStore(value ObjectStore) Options
+
+	// ObjectStore returns the object store of the uploaded files.
+	ObjectStore() ObjectStore
+
+	// SetTopology sets the topology used to assign shards to hosts.
+	SetTopology(value topology.Topology) Options
+
+	// Topology returns the topology used to assign shards to hosts.
+	Topology() topology.Topology
+
+	// SetNamespaceAdminService sets the service used to read the options of
+	// backed up namespaces and to register restored namespaces.
+	SetNamespaceAdminService(value kvadmin.NamespaceMetadataAdminService) Options
+
+	// NamespaceAdminService returns the service used to read the options of
+	// backed up namespaces and to register restored namespaces.
+	NamespaceAdminService() kvadmin.NamespaceMetadataAdminService
+
+	// SetLeaderService sets the leader service of the coordinator election.
+	SetLeaderService(value services.LeaderService) Options
+
+	// LeaderService returns the leader service of the coordinator election.
+	LeaderService() services.LeaderService
+
+	// SetElectionID sets the ID of the coordinator election.
+	SetElectionID(value string) Options
+
+	// ElectionID returns the ID of the coordinator election.
+	ElectionID() string
+
+	// SetCheckInterval sets how often pending backups and restores are checked.
+	SetCheckInterval(value time.Duration) Options
+
+	// CheckInterval returns how often pending backups and restores are checked.
+	CheckInterval() time.Duration
+
+	// SetTimeout sets how long backups and restores may remain pending
+	// before they are failed.
+	SetTimeout(value time.Duration) Options
+
+	// Timeout returns how long backups and restores may remain pending
+	// before they are failed.
+	Timeout() time.Duration
+
+	// SetClockOptions sets the clock options.
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+}
+
+type options struct {
+	hostID         string
+	filePathPrefix string
+	store          Store
+	objectStore    ObjectStore
+	topology       topology.Topology
+	nsAdmin        kvadmin.NamespaceMetadataAdminService
+	leaderService  services.LeaderService
+	electionID     string
+	checkInterval  time.Duration
+	timeout        time.Duration
+	clockOpts      clock.Options
+	instrumentOpts instrument.Options
+}
+
+// NewOptions returns new backup options.
+func NewOptions() Options {
+	return &options{
+		electionID:     defaultElectionID,
+		checkInterval:  defaultCheckInterval,
+		timeout:        defaultTimeout,
+		clockOpts:      clock.NewOptions(),
+		instrumentOpts: instrument.NewOptions(),
+	}
+}
+
+func (o *options) Validate() error {
+	if o.hostID == "" {
+		return errHostIDNotSet
+	}
+	if o.filePathPrefix == "" {
+		return errFilePathPrefixNotSet
+	}
+	if o.store == nil {
+		return errStoreNotSet
+	}
+	if o.objectStore == nil {
+		return errObjectStoreNotSet
+	}
+	if o.topology == nil {
+		return errTopologyNotSet
+	}
+	if o.nsAdmin == nil {
+		return errNamespaceAdminNotSet
+	}
+	if o.leaderService == nil {
+		return errLeaderServiceNotSet
+	}
+	if o.checkInterval <= 0 {
+		return errCheckIntervalNotPositive
+	}
+	if o.timeout <= 0 {
+		return errTimeoutNotPositive
+	}
+	return nil
+}
+
+func (o *options) SetHostID(value string) Options {
+	opts := *o
+	opts.hostID = value
+	return &opts
+}
+
+func (o *options) HostID() string {
+	return o.hostID
+}
+
+func (o *options) SetFilePathPrefix(value string) Options {
+	opts := *o
+	opts.filePathPrefix = value
+	return &opts
+}
+
+func (o *options) FilePathPrefix() string {
+	return o.filePathPrefix
+}
+
+func (o *options) SetStore(value Store) Options {
+	opts := *o
+	opts.store = value
+	return &opts
+}
+
+func (o *options) Store() Store {
+	return o.store
+}
+
+func (o *options) SetObjectStore(value ObjectStore) Options {
+	opts := *o
+	opts.objectStore = value
+	return &opts
+}
+
+func (o *options) ObjectStore() ObjectStore {
+	return o.objectStore
+}
+
+func (o *options) SetTopology(value topology.Topology) Options {
+	opts := *o
+	opts.topology = value
+	return &opts
+}
+
+func (o *options) Topology() topology.Topology {
+	return o.topology
+}
+
+func (o *options) SetNamespaceAdminService(value kvadmin.NamespaceMetadataAdminService) Options {
+	opts := *o
+	opts.nsAdmin = value
+	return &opts
+}
+
+func (o *options) NamespaceAdminService() kvadmin.NamespaceMetadataAdminService {
+	return o.nsAdmin
+}
+
+func (o *options) SetLeaderService(value services.LeaderService) Options {
+	opts := *o
+	opts.leaderService = value
+	return &opts
+}
+
+func (o *options) LeaderService() services.LeaderService {
+	return o.leaderService
+}
+
+func (o *options) SetElectionID(value string) Options {
+	opts := *o
+	opts.electionID = value
+	return &opts
+}
+
+func (o *options) ElectionID() string {
+	return o.electionID
+}
+
+func (o *options) SetCheckInterval(value time.Duration) Options {
+	opts := *o
+	opts.checkInterval = value
+	return &opts
+}
+
+func (o *options) CheckInterval() time.Duration {
+	return o.checkInterval
+}
+
+func (o *options) SetTimeout(value time.Duration) Options {
+	opts := *o
+	opts.timeout = value
+	return &opts
+}
+
+func (o *options) Timeout() time.Duration {
+	return o.timeout
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
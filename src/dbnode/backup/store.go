@@ -0,0 +1,247 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+)
+
+// maxUpdateAttempts is the number of times updates are attempted when keys
+// are concurrently updated, e.g. by several nodes completing their shards.
+const maxUpdateAttempts = 20
+
+var errTooManyConflicts = errors.New("too many conflicting backup updates")
+
+type kvStore struct {
+	store     kv.Store
+	keyPrefix string
+}
+
+// NewKVStore returns a Store keeping each manifest and restore as a JSON
+// encoded key in a kv store, along with a key listing their IDs.
+func NewKVStore(store kv.Store, keyPrefix string) Store {
+	return &kvStore{
+		store:     store,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (s *kvStore) manifestsKey() string {
+	return s.keyPrefix + "manifests"
+}
+
+func (s *kvStore) restoresKey() string {
+	return s.keyPrefix + "restores"
+}
+
+func (s *kvStore) CreateManifest(m Manifest) error {
+	return s.create(s.manifestsKey(), m.ID, m)
+}
+
+func (s *kvStore) Manifest(id string) (Manifest, error) {
+	var m Manifest
+	_, err := s.get(s.manifestsKey()+"."+id, &m)
+	return m, err
+}
+
+func (s *kvStore) Manifests() ([]Manifest, error) {
+	ids, _, err := s.ids(s.manifestsKey())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Manifest, 0, len(ids))
+	for _, id := range ids {
+		m, err := s.Manifest(id)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, m)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt < results[j].CreatedAt
+	})
+	return results, nil
+}
+
+func (s *kvStore) UpdateManifest(id string, update func(m *Manifest) error) (Manifest, error) {
+	var m Manifest
+	err := s.update(s.manifestsKey()+"."+id, func() interface{} {
+		m = Manifest{}
+		return &m
+	}, func() error {
+		return update(&m)
+	})
+	return m, err
+}
+
+func (s *kvStore) CreateRestore(r Restore) error {
+	return s.create(s.restoresKey(), r.Namespace, r)
+}
+
+func (s *kvStore) Restore(namespace string) (Restore, error) {
+	var r Restore
+	_, err := s.get(s.restoresKey()+"."+namespace, &r)
+	return r, err
+}
+
+func (s *kvStore) Restores() ([]Restore, error) {
+	namespaces, _, err := s.ids(s.restoresKey())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Restore, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		r, err := s.Restore(namespace)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt < results[j].CreatedAt
+	})
+	return results, nil
+}
+
+func (s *kvStore) UpdateRestore(namespace string, update func(r *Restore) error) (Restore, error) {
+	var r Restore
+	err := s.update(s.restoresKey()+"."+namespace, func() interface{} {
+		r = Restore{}
+		return &r
+	}, func() error {
+		return update(&r)
+	})
+	return r, err
+}
+
+// create stores the value at the key of the ID and adds the ID to the list
+// at the index key.
+func (s *kvStore) create(indexKey, id string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.SetIfNotExists(indexKey+"."+id, &commonpb.StringProto{Value: string(b)})
+	if err == kv.ErrAlreadyExists {
+		return ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		ids, version, err := s.ids(indexKey)
+		if err != nil {
+			return err
+		}
+
+		value := &commonpb.StringArrayProto{Values: append(ids, id)}
+		if version == 0 {
+			_, err = s.store.SetIfNotExists(indexKey, value)
+		} else {
+			_, err = s.store.CheckAndSet(indexKey, version, value)
+		}
+		if err == kv.ErrAlreadyExists || err == kv.ErrVersionMismatch {
+			continue
+		}
+		return err
+	}
+	return errTooManyConflicts
+}
+
+// get decodes the value at the key, returning the version of the key.
+func (s *kvStore) get(key string, value interface{}) (int, error) {
+	v, err := s.store.Get(key)
+	if err == kv.ErrNotFound {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var proto commonpb.StringProto
+	if err := v.Unmarshal(&proto); err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal([]byte(proto.Value), value); err != nil {
+		return 0, fmt.Errorf("unable to decode backup key %s: %w", key, err)
+	}
+	return v.Version(), nil
+}
+
+// update decodes the value at the key into the value returned by reset,
+// applies the update and stores the result if the key was not modified in
+// the meantime.
+func (s *kvStore) update(key string, reset func() interface{}, update func() error) error {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		value := reset()
+		version, err := s.get(key, value)
+		if err != nil {
+			return err
+		}
+		if err := update(); err != nil {
+			return err
+		}
+
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = s.store.CheckAndSet(key, version, &commonpb.StringProto{Value: string(b)})
+		if err == kv.ErrVersionMismatch {
+			continue
+		}
+		return err
+	}
+	return errTooManyConflicts
+}
+
+// ids returns the IDs listed at the index key and the version of the key,
+// which is zero if the key does not exist yet.
+func (s *kvStore) ids(indexKey string) ([]string, int, error) {
+	value, err := s.store.Get(indexKey)
+	if err == kv.ErrNotFound {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var proto commonpb.StringArrayProto
+	if err := value.Unmarshal(&proto); err != nil {
+		return nil, 0, err
+	}
+	return proto.Values, value.Version(), nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/kv/mem"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVStoreManifests(t *testing.T) {
+	store := NewKVStore(mem.NewStore(), "backup.")
+
+	_, err := store.Manifest("a")
+	require.Equal(t, ErrNotFound, err)
+
+	require.NoError(t, store.CreateManifest(Manifest{ID: "b", CreatedAt: 2, State: StatePending}))
+	require.NoError(t, store.CreateManifest(Manifest{ID: "a", CreatedAt: 1, State: StatePending}))
+	require.Equal(t, ErrAlreadyExists, store.CreateManifest(Manifest{ID: "a"}))
+
+	manifests, err := store.Manifests()
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	require.Equal(t, "a", manifests[0].ID)
+	require.Equal(t, "b", manifests[1].ID)
+
+	updated, err := store.UpdateManifest("a", func(m *Manifest) error {
+		m.State = StateComplete
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, StateComplete, updated.State)
+
+	m, err := store.Manifest("a")
+	require.NoError(t, err)
+	require.Equal(t, StateComplete, m.State)
+
+	_, err = store.UpdateManifest("c", func(m *Manifest) error { return nil })
+	require.Equal(t, ErrNotFound, err)
+}
+
+func TestKVStoreRestores(t *testing.T) {
+	store := NewKVStore(mem.NewStore(), "backup.")
+
+	require.NoError(t, store.CreateRestore(Restore{
+		ManifestID: "a",
+		Namespace:  "restored",
+		State:      StatePending,
+		Shards:     []ShardRestore{{Shard: 0, Hosts: []string{"h1", "h2"}}},
+	}))
+	require.Equal(t, ErrAlreadyExists, store.CreateRestore(Restore{Namespace: "restored"}))
+
+	r, err := store.UpdateRestore("restored", func(r *Restore) error {
+		r.Shards[0].Restored = append(r.Shards[0].Restored, "h1")
+		return nil
+	})
+	require.NoError(t, err)
+	require.False(t, r.Complete())
+
+	r, err = store.UpdateRestore("restored", func(r *Restore) error {
+		r.Shards[0].Restored = append(r.Shards[0].Restored, "h2")
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, r.Complete())
+
+	restores, err := store.Restores()
+	require.NoError(t, err)
+	require.Len(t, restores, 1)
+	require.Equal(t, r, restores[0])
+}
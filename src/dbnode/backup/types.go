@@ -0,0 +1,201 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package backup coordinates cluster wide backups of namespaces to object
+// storage and point-in-time restores of them into new namespaces.
+//
+// A leader elected coordinator assigns every shard of a namespace to one of
+// its available replicas in a manifest stored in kv, the agent running on
+// each node uploads the filesets of its assigned shards as of the target
+// time of the manifest and marks them complete. A restore validates that
+// a complete manifest covers every shard of the placement, the agents then
+// download the filesets into the data directories of every replica of each
+// shard and once all are restored the namespace is registered as ready.
+package backup
+
+import (
+	"errors"
+	"io"
+
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+var (
+	// ErrNotLeader is returned when a backup or restore is requested from a
+	// node that is not the leader of the backup coordinator election.
+	ErrNotLeader = errors.New("backup coordinator is not the leader")
+
+	// ErrNotFound is returned when a manifest or restore does not exist.
+	ErrNotFound = errors.New("backup manifest or restore not found")
+
+	// ErrAlreadyExists is returned when a manifest or restore already exists.
+	ErrAlreadyExists = errors.New("backup manifest or restore already exists")
+)
+
+// State is the state of a backup, a restore or of one of their shards.
+type State string
+
+const (
+	// StatePending is the state of backups, restores and shards in progress.
+	StatePending State = "pending"
+	// StateComplete is the state of completed backups, restores and shards.
+	StateComplete State = "complete"
+	// StateFailed is the state of failed backups, restores and shards.
+	StateFailed State = "failed"
+)
+
+// Manifest describes a backup of a namespace as of a target time.
+type Manifest struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	// NamespaceOptions are the protobuf encoded options of the namespace at
+	// the time of the backup, used to register the namespace on restore.
+	NamespaceOptions []byte        `json:"namespaceOptions"`
+	TargetTime       int64         `json:"targetTime"`
+	CreatedAt        int64         `json:"createdAt"`
+	UpdatedAt        int64         `json:"updatedAt"`
+	State            State         `json:"state"`
+	Error            string        `json:"error,omitempty"`
+	Shards           []ShardBackup `json:"shards"`
+}
+
+// ShardBackup describes the backup of a single shard.
+type ShardBackup struct {
+	Shard uint32 `json:"shard"`
+	Host  string `json:"host"`
+	State State  `json:"state"`
+	Error string `json:"error,omitempty"`
+	// Files are the object store keys of the uploaded fileset files.
+	Files []string `json:"files,omitempty"`
+}
+
+// Complete returns whether every shard of the manifest has been uploaded.
+func (m Manifest) Complete() bool {
+	for _, s := range m.Shards {
+		if s.State != StateComplete {
+			return false
+		}
+	}
+	return len(m.Shards) > 0
+}
+
+// Target returns the target time of the manifest.
+func (m Manifest) Target() xtime.UnixNano {
+	return xtime.UnixNano(m.TargetTime)
+}
+
+// Restore describes the restore of a manifest into a namespace.
+type Restore struct {
+	ManifestID string         `json:"manifestId"`
+	Namespace  string         `json:"namespace"`
+	CreatedAt  int64          `json:"createdAt"`
+	UpdatedAt  int64          `json:"updatedAt"`
+	State      State          `json:"state"`
+	Error      string         `json:"error,omitempty"`
+	Shards     []ShardRestore `json:"shards"`
+}
+
+// ShardRestore describes the restore of a single shard to its replicas.
+type ShardRestore struct {
+	Shard    uint32   `json:"shard"`
+	Hosts    []string `json:"hosts"`
+	Restored []string `json:"restored,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Complete returns whether every replica of every shard has been restored.
+func (r Restore) Complete() bool {
+	for _, s := range r.Shards {
+		if len(s.Restored) < len(s.Hosts) {
+			return false
+		}
+	}
+	return len(r.Shards) > 0
+}
+
+// Store stores backup manifests and restores.
+type Store interface {
+	// CreateManifest creates a manifest, returning ErrAlreadyExists if a
+	// manifest with the same ID exists.
+	CreateManifest(m Manifest) error
+
+	// Manifest returns the manifest with the given ID.
+	Manifest(id string) (Manifest, error)
+
+	// Manifests returns all manifests.
+	Manifests() ([]Manifest, error)
+
+	// UpdateManifest applies the update to the manifest with the given ID,
+	// retrying the update on concurrent modifications.
+	UpdateManifest(id string, update func(m *Manifest) error) (Manifest, error)
+
+	// CreateRestore creates a restore, returning ErrAlreadyExists if a restore
+	// into the same namespace exists.
+	CreateRestore(r Restore) error
+
+	// Restore returns the restore into the given namespace.
+	Restore(namespace string) (Restore, error)
+
+	// Restores returns all restores.
+	Restores() ([]Restore, error)
+
+	// UpdateRestore applies the update to the restore into the given
+	// namespace, retrying the update on concurrent modifications.
+	UpdateRestore(namespace string, update func(r *Restore) error) (Restore, error)
+}
+
+// ObjectStore stores uploaded backup files.
+type ObjectStore interface {
+	// Put stores the contents of the reader at the given key.
+	Put(key string, r io.Reader) error
+
+	// Get returns the contents stored at the given key.
+	Get(key string) (io.ReadCloser, error)
+}
+
+// Coordinator triggers cluster wide backups and restores while it is the
+// leader of its election.
+type Coordinator interface {
+	// Open starts campaigning for leadership and tracking the progress of
+	// pending backups and restores while leader.
+	Open() error
+
+	// Leader returns the leader of the coordinator election.
+	Leader() (string, error)
+
+	// Backup starts a backup of the namespace as of the target time.
+	Backup(namespace string, target xtime.UnixNano) (Manifest, error)
+
+	// Restore starts a restore of the manifest into a new namespace.
+	Restore(manifestID, namespace string) (Restore, error)
+
+	// Close resigns leadership and stops the coordinator.
+	Close() error
+}
+
+// Agent uploads and downloads the filesets of the shards assigned to the
+// local node by pending backups and restores.
+type Agent interface {
+	// Open starts processing pending backups and restores.
+	Open() error
+
+	// Close stops processing pending backups and restores.
+	Close() error
+}
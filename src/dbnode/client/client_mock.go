@@ -32,11 +32,13 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/x/clock"
 	context0 "github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
@@ -508,6 +510,22 @@ func (mr *MockTaggedIDsIteratorMockRecorder) Finalize() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finalize", reflect.TypeOf((*MockTaggedIDsIterator)(nil).Finalize))
 }
 
+// LatestDatapoint mocks base method.
+func (m *MockTaggedIDsIterator) LatestDatapoint() (ts.Datapoint, ts.Annotation, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestDatapoint")
+	ret0, _ := ret[0].(ts.Datapoint)
+	ret1, _ := ret[1].(ts.Annotation)
+	ret2, _ := ret[2].(bool)
+	return ret0, ret1, ret2
+}
+
+// LatestDatapoint indicates an expected call of LatestDatapoint.
+func (mr *MockTaggedIDsIteratorMockRecorder) LatestDatapoint() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestDatapoint", reflect.TypeOf((*MockTaggedIDsIterator)(nil).LatestDatapoint))
+}
+
 // Next mocks base method.
 func (m *MockTaggedIDsIterator) Next() bool {
 	m.ctrl.T.Helper()
@@ -1423,6 +1441,20 @@ func (mr *MockOptionsMockRecorder) FetchBatchSize() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchBatchSize", reflect.TypeOf((*MockOptions)(nil).FetchBatchSize))
 }
 
+// FetchChecksumVerificationEnabled mocks base method.
+func (m *MockOptions) FetchChecksumVerificationEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchChecksumVerificationEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// FetchChecksumVerificationEnabled indicates an expected call of FetchChecksumVerificationEnabled.
+func (mr *MockOptionsMockRecorder) FetchChecksumVerificationEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchChecksumVerificationEnabled", reflect.TypeOf((*MockOptions)(nil).FetchChecksumVerificationEnabled))
+}
+
 // FetchRequestTimeout mocks base method.
 func (m *MockOptions) FetchRequestTimeout() time.Duration {
 	m.ctrl.T.Helper()
@@ -1703,6 +1735,34 @@ func (mr *MockOptionsMockRecorder) ReadConsistencyLevel() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadConsistencyLevel", reflect.TypeOf((*MockOptions)(nil).ReadConsistencyLevel))
 }
 
+// ReadRepairReporter mocks base method.
+func (m *MockOptions) ReadRepairReporter() ReadRepairReporter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRepairReporter")
+	ret0, _ := ret[0].(ReadRepairReporter)
+	return ret0
+}
+
+// ReadRepairReporter indicates an expected call of ReadRepairReporter.
+func (mr *MockOptionsMockRecorder) ReadRepairReporter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRepairReporter", reflect.TypeOf((*MockOptions)(nil).ReadRepairReporter))
+}
+
+// ReadZone mocks base method.
+func (m *MockOptions) ReadZone() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadZone")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ReadZone indicates an expected call of ReadZone.
+func (mr *MockOptionsMockRecorder) ReadZone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadZone", reflect.TypeOf((*MockOptions)(nil).ReadZone))
+}
+
 // ReaderIteratorAllocate mocks base method.
 func (m *MockOptions) ReaderIteratorAllocate() encoding.ReaderIteratorAllocate {
 	m.ctrl.T.Helper()
@@ -2039,6 +2099,20 @@ func (mr *MockOptionsMockRecorder) SetFetchBatchSize(value interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFetchBatchSize", reflect.TypeOf((*MockOptions)(nil).SetFetchBatchSize), value)
 }
 
+// SetFetchChecksumVerificationEnabled mocks base method.
+func (m *MockOptions) SetFetchChecksumVerificationEnabled(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFetchChecksumVerificationEnabled", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetFetchChecksumVerificationEnabled indicates an expected call of SetFetchChecksumVerificationEnabled.
+func (mr *MockOptionsMockRecorder) SetFetchChecksumVerificationEnabled(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFetchChecksumVerificationEnabled", reflect.TypeOf((*MockOptions)(nil).SetFetchChecksumVerificationEnabled), value)
+}
+
 // SetFetchRequestTimeout mocks base method.
 func (m *MockOptions) SetFetchRequestTimeout(value time.Duration) Options {
 	m.ctrl.T.Helper()
@@ -2305,6 +2379,34 @@ func (mr *MockOptionsMockRecorder) SetReadConsistencyLevel(value interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadConsistencyLevel", reflect.TypeOf((*MockOptions)(nil).SetReadConsistencyLevel), value)
 }
 
+// SetReadRepairReporter mocks base method.
+func (m *MockOptions) SetReadRepairReporter(value ReadRepairReporter) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadRepairReporter", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetReadRepairReporter indicates an expected call of SetReadRepairReporter.
+func (mr *MockOptionsMockRecorder) SetReadRepairReporter(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadRepairReporter", reflect.TypeOf((*MockOptions)(nil).SetReadRepairReporter), value)
+}
+
+// SetReadZone mocks base method.
+func (m *MockOptions) SetReadZone(value string) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadZone", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetReadZone indicates an expected call of SetReadZone.
+func (mr *MockOptionsMockRecorder) SetReadZone(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadZone", reflect.TypeOf((*MockOptions)(nil).SetReadZone), value)
+}
+
 // SetReaderIteratorAllocate mocks base method.
 func (m *MockOptions) SetReaderIteratorAllocate(value encoding.ReaderIteratorAllocate) Options {
 	m.ctrl.T.Helper()
@@ -2515,6 +2617,34 @@ func (mr *MockOptionsMockRecorder) SetWriteConsistencyLevel(value interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteConsistencyLevel", reflect.TypeOf((*MockOptions)(nil).SetWriteConsistencyLevel), value)
 }
 
+// SetWriteFanoutOrdered mocks base method.
+func (m *MockOptions) SetWriteFanoutOrdered(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWriteFanoutOrdered", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWriteFanoutOrdered indicates an expected call of SetWriteFanoutOrdered.
+func (mr *MockOptionsMockRecorder) SetWriteFanoutOrdered(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteFanoutOrdered", reflect.TypeOf((*MockOptions)(nil).SetWriteFanoutOrdered), value)
+}
+
+// SetWriteShardIDEnabled mocks base method.
+func (m *MockOptions) SetWriteShardIDEnabled(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWriteShardIDEnabled", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWriteShardIDEnabled indicates an expected call of SetWriteShardIDEnabled.
+func (mr *MockOptionsMockRecorder) SetWriteShardIDEnabled(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteShardIDEnabled", reflect.TypeOf((*MockOptions)(nil).SetWriteShardIDEnabled), value)
+}
+
 // SetWriteOpPoolSize mocks base method.
 func (m *MockOptions) SetWriteOpPoolSize(value pool.Size) Options {
 	m.ctrl.T.Helper()
@@ -2599,6 +2729,34 @@ func (mr *MockOptionsMockRecorder) SetWriteTimestampOffset(value interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteTimestampOffset", reflect.TypeOf((*MockOptions)(nil).SetWriteTimestampOffset), value)
 }
 
+// SetWriteWaitForIndex mocks base method.
+func (m *MockOptions) SetWriteWaitForIndex(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWriteWaitForIndex", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWriteWaitForIndex indicates an expected call of SetWriteWaitForIndex.
+func (mr *MockOptionsMockRecorder) SetWriteWaitForIndex(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteWaitForIndex", reflect.TypeOf((*MockOptions)(nil).SetWriteWaitForIndex), value)
+}
+
+// SetWriteZoneLocalAckRequired mocks base method.
+func (m *MockOptions) SetWriteZoneLocalAckRequired(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWriteZoneLocalAckRequired", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWriteZoneLocalAckRequired indicates an expected call of SetWriteZoneLocalAckRequired.
+func (mr *MockOptionsMockRecorder) SetWriteZoneLocalAckRequired(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteZoneLocalAckRequired", reflect.TypeOf((*MockOptions)(nil).SetWriteZoneLocalAckRequired), value)
+}
+
 // ShardsLeavingCountTowardsConsistency mocks base method.
 func (m *MockOptions) ShardsLeavingCountTowardsConsistency() bool {
 	m.ctrl.T.Helper()
@@ -2767,6 +2925,34 @@ func (mr *MockOptionsMockRecorder) WriteConsistencyLevel() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteConsistencyLevel", reflect.TypeOf((*MockOptions)(nil).WriteConsistencyLevel))
 }
 
+// WriteFanoutOrdered mocks base method.
+func (m *MockOptions) WriteFanoutOrdered() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteFanoutOrdered")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// WriteFanoutOrdered indicates an expected call of WriteFanoutOrdered.
+func (mr *MockOptionsMockRecorder) WriteFanoutOrdered() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteFanoutOrdered", reflect.TypeOf((*MockOptions)(nil).WriteFanoutOrdered))
+}
+
+// WriteShardIDEnabled mocks base method.
+func (m *MockOptions) WriteShardIDEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteShardIDEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// WriteShardIDEnabled indicates an expected call of WriteShardIDEnabled.
+func (mr *MockOptionsMockRecorder) WriteShardIDEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteShardIDEnabled", reflect.TypeOf((*MockOptions)(nil).WriteShardIDEnabled))
+}
+
 // WriteOpPoolSize mocks base method.
 func (m *MockOptions) WriteOpPoolSize() pool.Size {
 	m.ctrl.T.Helper()
@@ -2851,6 +3037,34 @@ func (mr *MockOptionsMockRecorder) WriteTimestampOffset() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTimestampOffset", reflect.TypeOf((*MockOptions)(nil).WriteTimestampOffset))
 }
 
+// WriteWaitForIndex mocks base method.
+func (m *MockOptions) WriteWaitForIndex() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteWaitForIndex")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// WriteWaitForIndex indicates an expected call of WriteWaitForIndex.
+func (mr *MockOptionsMockRecorder) WriteWaitForIndex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteWaitForIndex", reflect.TypeOf((*MockOptions)(nil).WriteWaitForIndex))
+}
+
+// WriteZoneLocalAckRequired mocks base method.
+func (m *MockOptions) WriteZoneLocalAckRequired() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteZoneLocalAckRequired")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// WriteZoneLocalAckRequired indicates an expected call of WriteZoneLocalAckRequired.
+func (mr *MockOptionsMockRecorder) WriteZoneLocalAckRequired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteZoneLocalAckRequired", reflect.TypeOf((*MockOptions)(nil).WriteZoneLocalAckRequired))
+}
+
 // MockAdminOptions is a mock of AdminOptions interface.
 type MockAdminOptions struct {
 	ctrl     *gomock.Controller
@@ -3140,6 +3354,20 @@ func (mr *MockAdminOptionsMockRecorder) FetchBatchSize() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchBatchSize", reflect.TypeOf((*MockAdminOptions)(nil).FetchBatchSize))
 }
 
+// FetchChecksumVerificationEnabled mocks base method.
+func (m *MockAdminOptions) FetchChecksumVerificationEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchChecksumVerificationEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// FetchChecksumVerificationEnabled indicates an expected call of FetchChecksumVerificationEnabled.
+func (mr *MockAdminOptionsMockRecorder) FetchChecksumVerificationEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchChecksumVerificationEnabled", reflect.TypeOf((*MockAdminOptions)(nil).FetchChecksumVerificationEnabled))
+}
+
 // FetchRequestTimeout mocks base method.
 func (m *MockAdminOptions) FetchRequestTimeout() time.Duration {
 	m.ctrl.T.Helper()
@@ -3490,6 +3718,20 @@ func (mr *MockAdminOptionsMockRecorder) Origin() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Origin", reflect.TypeOf((*MockAdminOptions)(nil).Origin))
 }
 
+// PeerStreamCodecs mocks base method.
+func (m *MockAdminOptions) PeerStreamCodecs() []peerstream.Codec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerStreamCodecs")
+	ret0, _ := ret[0].([]peerstream.Codec)
+	return ret0
+}
+
+// PeerStreamCodecs indicates an expected call of PeerStreamCodecs.
+func (mr *MockAdminOptionsMockRecorder) PeerStreamCodecs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerStreamCodecs", reflect.TypeOf((*MockAdminOptions)(nil).PeerStreamCodecs))
+}
+
 // ReadConsistencyLevel mocks base method.
 func (m *MockAdminOptions) ReadConsistencyLevel() topology.ReadConsistencyLevel {
 	m.ctrl.T.Helper()
@@ -3504,6 +3746,34 @@ func (mr *MockAdminOptionsMockRecorder) ReadConsistencyLevel() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadConsistencyLevel", reflect.TypeOf((*MockAdminOptions)(nil).ReadConsistencyLevel))
 }
 
+// ReadRepairReporter mocks base method.
+func (m *MockAdminOptions) ReadRepairReporter() ReadRepairReporter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRepairReporter")
+	ret0, _ := ret[0].(ReadRepairReporter)
+	return ret0
+}
+
+// ReadRepairReporter indicates an expected call of ReadRepairReporter.
+func (mr *MockAdminOptionsMockRecorder) ReadRepairReporter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRepairReporter", reflect.TypeOf((*MockAdminOptions)(nil).ReadRepairReporter))
+}
+
+// ReadZone mocks base method.
+func (m *MockAdminOptions) ReadZone() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadZone")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ReadZone indicates an expected call of ReadZone.
+func (mr *MockAdminOptionsMockRecorder) ReadZone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadZone", reflect.TypeOf((*MockAdminOptions)(nil).ReadZone))
+}
+
 // ReaderIteratorAllocate mocks base method.
 func (m *MockAdminOptions) ReaderIteratorAllocate() encoding.ReaderIteratorAllocate {
 	m.ctrl.T.Helper()
@@ -3854,6 +4124,20 @@ func (mr *MockAdminOptionsMockRecorder) SetFetchBatchSize(value interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFetchBatchSize", reflect.TypeOf((*MockAdminOptions)(nil).SetFetchBatchSize), value)
 }
 
+// SetFetchChecksumVerificationEnabled mocks base method.
+func (m *MockAdminOptions) SetFetchChecksumVerificationEnabled(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFetchChecksumVerificationEnabled", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetFetchChecksumVerificationEnabled indicates an expected call of SetFetchChecksumVerificationEnabled.
+func (mr *MockAdminOptionsMockRecorder) SetFetchChecksumVerificationEnabled(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFetchChecksumVerificationEnabled", reflect.TypeOf((*MockAdminOptions)(nil).SetFetchChecksumVerificationEnabled), value)
+}
+
 // SetFetchRequestTimeout mocks base method.
 func (m *MockAdminOptions) SetFetchRequestTimeout(value time.Duration) Options {
 	m.ctrl.T.Helper()
@@ -4190,6 +4474,20 @@ func (mr *MockAdminOptionsMockRecorder) SetOrigin(value interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrigin", reflect.TypeOf((*MockAdminOptions)(nil).SetOrigin), value)
 }
 
+// SetPeerStreamCodecs mocks base method.
+func (m *MockAdminOptions) SetPeerStreamCodecs(value []peerstream.Codec) AdminOptions {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPeerStreamCodecs", value)
+	ret0, _ := ret[0].(AdminOptions)
+	return ret0
+}
+
+// SetPeerStreamCodecs indicates an expected call of SetPeerStreamCodecs.
+func (mr *MockAdminOptionsMockRecorder) SetPeerStreamCodecs(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPeerStreamCodecs", reflect.TypeOf((*MockAdminOptions)(nil).SetPeerStreamCodecs), value)
+}
+
 // SetReadConsistencyLevel mocks base method.
 func (m *MockAdminOptions) SetReadConsistencyLevel(value topology.ReadConsistencyLevel) Options {
 	m.ctrl.T.Helper()
@@ -4204,6 +4502,34 @@ func (mr *MockAdminOptionsMockRecorder) SetReadConsistencyLevel(value interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadConsistencyLevel", reflect.TypeOf((*MockAdminOptions)(nil).SetReadConsistencyLevel), value)
 }
 
+// SetReadRepairReporter mocks base method.
+func (m *MockAdminOptions) SetReadRepairReporter(value ReadRepairReporter) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadRepairReporter", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetReadRepairReporter indicates an expected call of SetReadRepairReporter.
+func (mr *MockAdminOptionsMockRecorder) SetReadRepairReporter(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadRepairReporter", reflect.TypeOf((*MockAdminOptions)(nil).SetReadRepairReporter), value)
+}
+
+// SetReadZone mocks base method.
+func (m *MockAdminOptions) SetReadZone(value string) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadZone", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetReadZone indicates an expected call of SetReadZone.
+func (mr *MockAdminOptionsMockRecorder) SetReadZone(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadZone", reflect.TypeOf((*MockAdminOptions)(nil).SetReadZone), value)
+}
+
 // SetReaderIteratorAllocate mocks base method.
 func (m *MockAdminOptions) SetReaderIteratorAllocate(value encoding.ReaderIteratorAllocate) Options {
 	m.ctrl.T.Helper()
@@ -4428,6 +4754,34 @@ func (mr *MockAdminOptionsMockRecorder) SetWriteConsistencyLevel(value interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteConsistencyLevel", reflect.TypeOf((*MockAdminOptions)(nil).SetWriteConsistencyLevel), value)
 }
 
+// SetWriteFanoutOrdered mocks base method.
+func (m *MockAdminOptions) SetWriteFanoutOrdered(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWriteFanoutOrdered", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWriteFanoutOrdered indicates an expected call of SetWriteFanoutOrdered.
+func (mr *MockAdminOptionsMockRecorder) SetWriteFanoutOrdered(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteFanoutOrdered", reflect.TypeOf((*MockAdminOptions)(nil).SetWriteFanoutOrdered), value)
+}
+
+// SetWriteShardIDEnabled mocks base method.
+func (m *MockAdminOptions) SetWriteShardIDEnabled(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWriteShardIDEnabled", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWriteShardIDEnabled indicates an expected call of SetWriteShardIDEnabled.
+func (mr *MockAdminOptionsMockRecorder) SetWriteShardIDEnabled(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteShardIDEnabled", reflect.TypeOf((*MockAdminOptions)(nil).SetWriteShardIDEnabled), value)
+}
+
 // SetWriteOpPoolSize mocks base method.
 func (m *MockAdminOptions) SetWriteOpPoolSize(value pool.Size) Options {
 	m.ctrl.T.Helper()
@@ -4512,6 +4866,34 @@ func (mr *MockAdminOptionsMockRecorder) SetWriteTimestampOffset(value interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteTimestampOffset", reflect.TypeOf((*MockAdminOptions)(nil).SetWriteTimestampOffset), value)
 }
 
+// SetWriteWaitForIndex mocks base method.
+func (m *MockAdminOptions) SetWriteWaitForIndex(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWriteWaitForIndex", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWriteWaitForIndex indicates an expected call of SetWriteWaitForIndex.
+func (mr *MockAdminOptionsMockRecorder) SetWriteWaitForIndex(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteWaitForIndex", reflect.TypeOf((*MockAdminOptions)(nil).SetWriteWaitForIndex), value)
+}
+
+// SetWriteZoneLocalAckRequired mocks base method.
+func (m *MockAdminOptions) SetWriteZoneLocalAckRequired(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWriteZoneLocalAckRequired", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWriteZoneLocalAckRequired indicates an expected call of SetWriteZoneLocalAckRequired.
+func (mr *MockAdminOptionsMockRecorder) SetWriteZoneLocalAckRequired(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteZoneLocalAckRequired", reflect.TypeOf((*MockAdminOptions)(nil).SetWriteZoneLocalAckRequired), value)
+}
+
 // ShardsLeavingCountTowardsConsistency mocks base method.
 func (m *MockAdminOptions) ShardsLeavingCountTowardsConsistency() bool {
 	m.ctrl.T.Helper()
@@ -4694,6 +5076,34 @@ func (mr *MockAdminOptionsMockRecorder) WriteConsistencyLevel() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteConsistencyLevel", reflect.TypeOf((*MockAdminOptions)(nil).WriteConsistencyLevel))
 }
 
+// WriteFanoutOrdered mocks base method.
+func (m *MockAdminOptions) WriteFanoutOrdered() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteFanoutOrdered")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// WriteFanoutOrdered indicates an expected call of WriteFanoutOrdered.
+func (mr *MockAdminOptionsMockRecorder) WriteFanoutOrdered() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteFanoutOrdered", reflect.TypeOf((*MockAdminOptions)(nil).WriteFanoutOrdered))
+}
+
+// WriteShardIDEnabled mocks base method.
+func (m *MockAdminOptions) WriteShardIDEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteShardIDEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// WriteShardIDEnabled indicates an expected call of WriteShardIDEnabled.
+func (mr *MockAdminOptionsMockRecorder) WriteShardIDEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteShardIDEnabled", reflect.TypeOf((*MockAdminOptions)(nil).WriteShardIDEnabled))
+}
+
 // WriteOpPoolSize mocks base method.
 func (m *MockAdminOptions) WriteOpPoolSize() pool.Size {
 	m.ctrl.T.Helper()
@@ -4778,6 +5188,34 @@ func (mr *MockAdminOptionsMockRecorder) WriteTimestampOffset() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTimestampOffset", reflect.TypeOf((*MockAdminOptions)(nil).WriteTimestampOffset))
 }
 
+// WriteWaitForIndex mocks base method.
+func (m *MockAdminOptions) WriteWaitForIndex() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteWaitForIndex")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// WriteWaitForIndex indicates an expected call of WriteWaitForIndex.
+func (mr *MockAdminOptionsMockRecorder) WriteWaitForIndex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteWaitForIndex", reflect.TypeOf((*MockAdminOptions)(nil).WriteWaitForIndex))
+}
+
+// WriteZoneLocalAckRequired mocks base method.
+func (m *MockAdminOptions) WriteZoneLocalAckRequired() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteZoneLocalAckRequired")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// WriteZoneLocalAckRequired indicates an expected call of WriteZoneLocalAckRequired.
+func (mr *MockAdminOptionsMockRecorder) WriteZoneLocalAckRequired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteZoneLocalAckRequired", reflect.TypeOf((*MockAdminOptions)(nil).WriteZoneLocalAckRequired))
+}
+
 // MockclientSession is a mock of clientSession interface.
 type MockclientSession struct {
 	ctrl     *gomock.Controller
@@ -25,10 +25,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/environment"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/x/clock"
 	xerrors "github.com/m3db/m3/src/x/errors"
@@ -37,6 +39,8 @@ import (
 	"github.com/m3db/m3/src/x/retry"
 	"github.com/m3db/m3/src/x/sampler"
 	xsync "github.com/m3db/m3/src/x/sync"
+
+	"github.com/uber/tchannel-go"
 )
 
 const (
@@ -72,6 +76,10 @@ type Configuration struct {
 	// FetchRetry is the fetch retry config.
 	FetchRetry *retry.Configuration `yaml:"fetchRetry"`
 
+	// RetryBudget is the retry budget shared by the write and fetch retries,
+	// it takes precedence over the budgets of the write and fetch retry config.
+	RetryBudget *retry.BudgetConfiguration `yaml:"retryBudget"`
+
 	// LogErrorSampleRate is the log error sample rate.
 	LogErrorSampleRate sampler.Rate `yaml:"logErrorSampleRate"`
 
@@ -105,6 +113,21 @@ type Configuration struct {
 	// have support for the V2 APIs in order for this feature to be used.
 	UseV2BatchAPIs *bool `yaml:"useV2BatchAPIs"`
 
+	// WriteShardIDEnabled determines whether tagged writes send the shard of the series
+	// ID. Note that the M3DB nodes must have support for shard IDs on writes in order for
+	// this feature to be used.
+	WriteShardIDEnabled *bool `yaml:"writeShardIDEnabled"`
+
+	// WriteWaitForIndex determines whether tagged writes are only acknowledged by the
+	// M3DB nodes once they are queryable from the index. Note that this increases
+	// write latency by up to the index insert batching interval.
+	WriteWaitForIndex *bool `yaml:"writeWaitForIndex"`
+
+	// FetchChecksumVerification determines whether the checksums of the segments
+	// returned by fetches are verified, replicas that return corrupt segments are
+	// treated as having failed the fetch.
+	FetchChecksumVerification *bool `yaml:"fetchChecksumVerification"`
+
 	// WriteTimestampOffset offsets all writes by specified duration into the past.
 	WriteTimestampOffset *time.Duration `yaml:"writeTimestampOffset"`
 
@@ -126,6 +149,47 @@ type Configuration struct {
 
 	// IterateEqualTimestampStrategy specifies the iterate equal timestamp strategy.
 	IterateEqualTimestampStrategy *encoding.IterateEqualTimestampStrategy `yaml:"iterateEqualTimestampStrategy"`
+
+	// ReadRepair specifies the read repair configuration.
+	ReadRepair *ReadRepairConfiguration `yaml:"readRepair"`
+
+	// ReadZone is the placement zone the client resides in, if set fetches
+	// prefer replicas in the same zone and only read from other zones when
+	// the zone local replicas fail to satisfy the read consistency level.
+	ReadZone string `yaml:"readZone"`
+
+	// WriteFanout specifies how writes are fanned out to replicas.
+	WriteFanout *WriteFanoutConfiguration `yaml:"writeFanout"`
+
+	// PeerStream specifies the compression and TLS of the blocks dbnodes
+	// stream from their peers, dbnodes serve peer streams with the same
+	// settings. Clients connecting to dbnodes serving with TLS must also
+	// specify the TLS settings.
+	PeerStream *peerstream.Configuration `yaml:"peerStream"`
+}
+
+// WriteFanoutConfiguration is the configuration for how writes are fanned out
+// to the replicas of a shard, relative to the zone set with readZone.
+type WriteFanoutConfiguration struct {
+	// Ordered specifies whether writes are enqueued to replicas in the client
+	// zone first, then to the replicas with the lowest recent error rate.
+	Ordered bool `yaml:"ordered"`
+
+	// RequireZoneLocalAck specifies whether a write must also be acknowledged
+	// by at least one replica in the client zone when one owns the shard, so
+	// that a successful write survives the loss of the remote zones.
+	RequireZoneLocalAck bool `yaml:"requireZoneLocalAck"`
+}
+
+// ReadRepairConfiguration is the configuration for reporting the blocks that
+// replicas returned differing data for when reading at consistency level all,
+// so that dbnodes repair actively queried data first.
+type ReadRepairConfiguration struct {
+	// Enabled specifies whether read repair reporting is enabled.
+	Enabled bool `yaml:"enabled"`
+
+	// FlushInterval is how often reported divergences are published to KV.
+	FlushInterval time.Duration `yaml:"flushInterval"`
 }
 
 // ProtoConfiguration is the configuration for running with ProtoDataMode enabled.
@@ -189,6 +253,10 @@ func (c *Configuration) Validate() error {
 		return fmt.Errorf("m3db client error validating log error sample rate: %v", err)
 	}
 
+	if c.WriteFanout != nil && c.WriteFanout.RequireZoneLocalAck && c.ReadZone == "" {
+		return errors.New("m3db client writeFanout requireZoneLocalAck requires readZone to be set")
+	}
+
 	if c.BackgroundHealthCheckFailLimit != nil &&
 		(*c.BackgroundHealthCheckFailLimit < 0 || *c.BackgroundHealthCheckFailLimit > 10) {
 		return fmt.Errorf(
@@ -306,6 +374,7 @@ func (c Configuration) NewAdminClient(
 		syncTopoInit         = params.TopologyInitializer
 		syncClientOverrides  environment.ClientOverrides
 		syncNsInit           namespace.Initializer
+		syncKVStore          kv.Store
 		asyncTopoInits       = []topology.Initializer{}
 		asyncClientOverrides = []environment.ClientOverrides{}
 	)
@@ -327,6 +396,7 @@ func (c Configuration) NewAdminClient(
 				syncTopoInit = envCfg.TopologyInitializer
 				syncClientOverrides = envCfg.ClientOverrides
 				syncNsInit = envCfg.NamespaceInitializer
+				syncKVStore = envCfg.KVStore
 			}
 		}
 	}
@@ -348,6 +418,18 @@ func (c Configuration) NewAdminClient(
 		v = v.SetUseV2BatchAPIs(*c.UseV2BatchAPIs)
 	}
 
+	if c.WriteShardIDEnabled != nil {
+		v = v.SetWriteShardIDEnabled(*c.WriteShardIDEnabled)
+	}
+
+	if c.WriteWaitForIndex != nil {
+		v = v.SetWriteWaitForIndex(*c.WriteWaitForIndex)
+	}
+
+	if c.FetchChecksumVerification != nil {
+		v = v.SetFetchChecksumVerificationEnabled(*c.FetchChecksumVerification)
+	}
+
 	if buildAsyncPool {
 		var size int
 		if c.AsyncWriteWorkerPoolSize == nil {
@@ -414,6 +496,11 @@ func (c Configuration) NewAdminClient(
 			SetMetricsScope(fetchRequestScope)
 		v = v.SetFetchRetrier(retry.NewRetrier(retrierOpts))
 	}
+	if c.RetryBudget != nil {
+		budget := c.RetryBudget.NewBudget()
+		v = v.SetWriteRetrier(retry.NewRetrier(v.WriteRetrier().Options().SetBudget(budget)))
+		v = v.SetFetchRetrier(retry.NewRetrier(v.FetchRetrier().Options().SetBudget(budget)))
+	}
 	if syncClientOverrides.TargetHostQueueFlushSize != nil {
 		v = v.SetHostQueueOpsFlushSize(*syncClientOverrides.TargetHostQueueFlushSize)
 	}
@@ -454,6 +541,32 @@ func (c Configuration) NewAdminClient(
 	if c.ShardsLeavingCountTowardsConsistency != nil {
 		v = v.SetShardsLeavingCountTowardsConsistency(*c.ShardsLeavingCountTowardsConsistency)
 	}
+	if c.ReadRepair != nil && c.ReadRepair.Enabled {
+		if syncKVStore == nil {
+			return nil, errors.New("m3db client read repair requires a dynamic cluster config")
+		}
+		v = v.SetReadRepairReporter(NewKVReadRepairReporter(syncKVStore,
+			c.ReadRepair.FlushInterval, iopts))
+	}
+	if c.ReadZone != "" {
+		v = v.SetReadZone(c.ReadZone)
+	}
+	if c.WriteFanout != nil {
+		v = v.SetWriteFanoutOrdered(c.WriteFanout.Ordered).
+			SetWriteZoneLocalAckRequired(c.WriteFanout.RequireZoneLocalAck)
+	}
+	tlsConfig, err := c.PeerStream.ClientTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		var chanOpts tchannel.ChannelOptions
+		if existing := v.ChannelOptions(); existing != nil {
+			chanOpts = *existing
+		}
+		chanOpts.Dialer = peerstream.NewDialFn(tlsConfig)
+		v = v.SetChannelOptions(&chanOpts)
+	}
 
 	// Cast to admin options to apply admin config options.
 	opts := v.(AdminOptions)
@@ -468,6 +581,11 @@ func (c Configuration) NewAdminClient(
 	if c.FetchSeriesBlocksBatchSize != nil {
 		opts = opts.SetFetchSeriesBlocksBatchSize(*c.FetchSeriesBlocksBatchSize)
 	}
+	peerStreamCodecs, err := c.PeerStream.NewCodecs()
+	if err != nil {
+		return nil, err
+	}
+	opts = opts.SetPeerStreamCodecs(peerStreamCodecs)
 
 	// Apply programmatic custom options last.
 	for _, opt := range custom {
@@ -54,6 +54,9 @@ fetchRetry:
     backoffFactor: 2
     maxRetries: 3
     jitter: true
+retryBudget:
+    ratio: 0.2
+    minRetriesPerSecond: 5
 backgroundHealthCheckFailLimit: 4
 backgroundHealthCheckFailThrottleFactor: 0.5
 hashing:
@@ -116,6 +119,10 @@ proto:
 			MaxRetries:     3,
 			Jitter:         &boolTrue,
 		},
+		RetryBudget: &retry.BudgetConfiguration{
+			Ratio:               0.2,
+			MinRetriesPerSecond: 5,
+		},
 		BackgroundHealthCheckFailLimit:          &num4,
 		BackgroundHealthCheckFailThrottleFactor: &numHalf,
 		HashingConfiguration: &HashingConfiguration{
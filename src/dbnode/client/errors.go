@@ -70,6 +70,18 @@ func IsResourceExhaustedError(err error) bool {
 	return false
 }
 
+// IsShardNotOwnedError determines if the error is due to a host not owning
+// the shard it was sent a request for, e.g. after a topology change.
+func IsShardNotOwnedError(err error) bool {
+	for err != nil {
+		if e, ok := err.(*rpc.Error); ok && tterrors.IsShardNotOwnedErrorFlag(e) { //nolint:errorlint
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
 // IsTimeoutError determines if the error is a timeout.
 func IsTimeoutError(err error) bool {
 	for err != nil {
@@ -79,11 +91,12 @@ func IsTimeoutError(err error) bool {
 				return true
 			}
 		}
-		// Need to also check if the message is directly the tchannel ErrTimeout error.
+		// Need to also check if the error is a tchannel timeout error.
 		// This is because those errors can come through at the tchannel layer,
 		// rather than in our application layer, meaning we don't have any
 		// means to intercept / set the SERVER_TIMEOUT flag.
-		if err.Error() == tchannel.ErrTimeout.Error() {
+		// nolint:errorlint
+		if e, ok := err.(tchannel.SystemError); ok && e.Code() == tchannel.ErrCodeTimeout {
 			return true
 		}
 		err = xerrors.InnerError(err)
@@ -91,6 +104,43 @@ func IsTimeoutError(err error) bool {
 	return false
 }
 
+// ErrorDetails returns the structured details of the error, as set by the
+// server that returned the error if it is an RPC error.
+func ErrorDetails(err error) xerrors.ErrorDetails {
+	if details, ok := StructuredErrorDetails(err); ok {
+		return details
+	}
+	if IsTimeoutError(err) {
+		return xerrors.NewErrorDetails(xerrors.CodeTimeout)
+	}
+	return xerrors.ErrorDetailsOf(err)
+}
+
+// StructuredErrorDetails returns the structured details of the error if
+// the error or any error it contains carries them explicitly.
+func StructuredErrorDetails(err error) (xerrors.ErrorDetails, bool) {
+	for curr := err; curr != nil; curr = xerrors.InnerError(curr) {
+		// nolint:errorlint
+		if e, ok := curr.(*rpc.Error); ok {
+			details := tterrors.ErrorDetails(e)
+			if xerrors.IsNonRetryableError(err) {
+				details.Retryable = false
+			}
+			return details, true
+		}
+		if details, ok := xerrors.GetErrorDetails(curr); ok {
+			return details, true
+		}
+	}
+	return xerrors.ErrorDetails{}, false
+}
+
+// IsRetryableError determines if the request that failed with the error
+// may succeed if retried.
+func IsRetryableError(err error) bool {
+	return ErrorDetails(err).Retryable
+}
+
 // IsConsistencyResultError determines if the error is a consistency result error.
 func IsConsistencyResultError(err error) bool {
 	for err != nil {
@@ -207,6 +257,17 @@ func newConsistencyResultError(
 	}
 }
 
+// NewConsistencyResultError returns the error of an operation that failed to
+// meet its consistency level, it allows fakes of the client to return errors
+// that are classified the same way as those returned by sessions.
+func NewConsistencyResultError(
+	level fmt.Stringer,
+	enqueued, responded int,
+	errs []error,
+) error {
+	return newConsistencyResultError(level, enqueued, responded, errs)
+}
+
 func (e consistencyResultErr) InnerError() error {
 	return e.topLevelErr
 }
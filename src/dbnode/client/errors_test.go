@@ -93,3 +93,64 @@ func TestConsistencyResultTchannelTimeoutError(t *testing.T) {
 	assert.Equal(t, 1, NumSuccess(err))
 	assert.Equal(t, 2, NumError(err))
 }
+
+func TestErrorDetails(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      xerrors.Code
+		retryable bool
+	}{
+		{
+			name:      "unclassified error",
+			err:       fmt.Errorf("some error"),
+			code:      xerrors.CodeUnknown,
+			retryable: true,
+		},
+		{
+			name: "legacy bad request error",
+			err: xerrors.NewRenamedError(&rpc.Error{
+				Type: rpc.ErrorType_BAD_REQUEST,
+			}, fmt.Errorf("renamed error")),
+			code: xerrors.CodeInvalidParams,
+		},
+		{
+			name: "resource exhausted error",
+			err: newConsistencyResultError(topology.ReadConsistencyLevelMajority, 2, 2,
+				[]error{errors.NewResourceExhaustedError(fmt.Errorf("limit"))}),
+			code: xerrors.CodeResourceExhausted,
+		},
+		{
+			name:      "server timeout error",
+			err:       errors.NewTimeoutError(fmt.Errorf("timeout")),
+			code:      xerrors.CodeTimeout,
+			retryable: true,
+		},
+		{
+			name:      "tchannel timeout error",
+			err:       xerrors.NewRenamedError(tchannel.ErrTimeout, fmt.Errorf("error")),
+			code:      xerrors.CodeTimeout,
+			retryable: true,
+		},
+		{
+			name: "non-retryable internal error",
+			err:  xerrors.NewNonRetryableError(errors.NewInternalError(fmt.Errorf("internal"))),
+			code: xerrors.CodeInternal,
+		},
+		{
+			name: "structured unavailable error",
+			err: errors.NewStructuredError(fmt.Errorf("bootstrapping"),
+				xerrors.NewErrorDetails(xerrors.CodeUnavailable)),
+			code:      xerrors.CodeUnavailable,
+			retryable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := ErrorDetails(tt.err)
+			assert.Equal(t, tt.code, details.Code)
+			assert.Equal(t, tt.retryable, details.Retryable)
+			assert.Equal(t, tt.retryable, IsRetryableError(tt.err))
+		})
+	}
+}
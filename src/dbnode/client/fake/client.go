@@ -0,0 +1,76 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fake
+
+import (
+	"sync"
+
+	"github.com/m3db/m3/src/dbnode/client"
+)
+
+type fakeClient struct {
+	sync.Mutex
+
+	cluster        *cluster
+	opts           client.Options
+	defaultSession client.Session
+}
+
+func newClient(c *cluster) *fakeClient {
+	return &fakeClient{cluster: c}
+}
+
+// Options returns the default client options, the fake does not use them
+// but callers may read them to configure themselves.
+func (c *fakeClient) Options() client.Options {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.opts == nil {
+		c.opts = client.NewOptions()
+	}
+	return c.opts
+}
+
+func (c *fakeClient) NewSession() (client.Session, error) {
+	return newSession(c.cluster), nil
+}
+
+func (c *fakeClient) NewSessionWithOptions(_ client.Options) (client.Session, error) {
+	return newSession(c.cluster), nil
+}
+
+func (c *fakeClient) DefaultSession() (client.Session, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.defaultSession == nil {
+		c.defaultSession = newSession(c.cluster)
+	}
+	return c.defaultSession, nil
+}
+
+func (c *fakeClient) DefaultSessionActive() bool {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.defaultSession != nil
+}
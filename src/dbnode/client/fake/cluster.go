@@ -0,0 +1,386 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fake
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+var (
+	errUnknownHost        = errors.New("fake: unknown host")
+	errUnknownShard       = errors.New("fake: unknown shard")
+	errRequestTimeout     = errors.New("fake: request timed out")
+	errNotEnoughOwners    = errors.New("fake: shard needs at least one owner")
+	errFixtureNoNamespace = errors.New("fake: series fixture has no namespace")
+	errFixtureNoID        = errors.New("fake: series fixture has no ID")
+)
+
+type cluster struct {
+	sync.RWMutex
+
+	opts    Options
+	hashFn  sharding.HashFn
+	hostIDs []string
+	hosts   map[string]*host
+	owners  [][]string
+}
+
+type host struct {
+	behavior   HostBehavior
+	requests   int
+	namespaces map[string]map[string]*series
+}
+
+type series struct {
+	shard  uint32
+	tags   []tag
+	points []point
+}
+
+type tag struct {
+	name  string
+	value string
+}
+
+type point struct {
+	dp         ts.Datapoint
+	unit       xtime.Unit
+	annotation []byte
+}
+
+// response is the simulated response of a single host to a request.
+type response struct {
+	hostID  string
+	latency time.Duration
+	err     error
+}
+
+// NewCluster returns a new fake cluster, by default shard replicas are
+// assigned to consecutive hosts and every host responds immediately and
+// successfully.
+func NewCluster(opts Options) (Cluster, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		hostIDs  = opts.HostIDs()
+		replicas = opts.Replicas()
+		hosts    = make(map[string]*host, len(hostIDs))
+		owners   = make([][]string, opts.NumShards())
+	)
+	for _, id := range hostIDs {
+		hosts[id] = &host{namespaces: make(map[string]map[string]*series)}
+	}
+	for shard := range owners {
+		owners[shard] = make([]string, 0, replicas)
+		for r := 0; r < replicas; r++ {
+			owners[shard] = append(owners[shard], hostIDs[(shard+r)%len(hostIDs)])
+		}
+	}
+
+	return &cluster{
+		opts:    opts,
+		hashFn:  sharding.DefaultHashFn(opts.NumShards()),
+		hostIDs: append([]string(nil), hostIDs...),
+		hosts:   hosts,
+		owners:  owners,
+	}, nil
+}
+
+func (c *cluster) NewClient() client.Client {
+	return newClient(c)
+}
+
+func (c *cluster) NewSession() client.Session {
+	return newSession(c)
+}
+
+func (c *cluster) SetHostBehavior(hostID string, behavior HostBehavior) error {
+	c.Lock()
+	defer c.Unlock()
+
+	h, ok := c.hosts[hostID]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownHost, hostID)
+	}
+	h.behavior = behavior
+	return nil
+}
+
+func (c *cluster) ResetHostBehavior(hostID string) error {
+	return c.SetHostBehavior(hostID, HostBehavior{})
+}
+
+func (c *cluster) SetShardOwners(shard uint32, hostIDs ...string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if int(shard) >= len(c.owners) {
+		return fmt.Errorf("%w: %d", errUnknownShard, shard)
+	}
+	if len(hostIDs) == 0 {
+		return errNotEnoughOwners
+	}
+	for _, id := range hostIDs {
+		if _, ok := c.hosts[id]; !ok {
+			return fmt.Errorf("%w: %s", errUnknownHost, id)
+		}
+	}
+	c.owners[shard] = append([]string(nil), hostIDs...)
+	return nil
+}
+
+func (c *cluster) ShardOwners(shard uint32) []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	if int(shard) >= len(c.owners) {
+		return nil
+	}
+	return append([]string(nil), c.owners[shard]...)
+}
+
+func (c *cluster) ShardID(id ident.ID) uint32 {
+	return c.hashFn(id)
+}
+
+func (c *cluster) Load(fixtures ...Series) error {
+	for _, f := range fixtures {
+		if err := validateFixture(f); err != nil {
+			return err
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, f := range fixtures {
+		shard := c.hashFn(ident.StringID(f.ID))
+		for _, hostID := range c.owners[shard] {
+			c.hosts[hostID].load(shard, f)
+		}
+	}
+	return nil
+}
+
+func (c *cluster) LoadHost(hostID string, fixtures ...Series) error {
+	for _, f := range fixtures {
+		if err := validateFixture(f); err != nil {
+			return err
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	h, ok := c.hosts[hostID]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownHost, hostID)
+	}
+	for _, f := range fixtures {
+		h.load(c.hashFn(ident.StringID(f.ID)), f)
+	}
+	return nil
+}
+
+func (c *cluster) Requests(hostID string) int {
+	c.RLock()
+	defer c.RUnlock()
+
+	h, ok := c.hosts[hostID]
+	if !ok {
+		return 0
+	}
+	return h.requests
+}
+
+// send simulates sending a request to each of the hosts and returns their
+// responses ordered by latency, hosts are only asked once even if they
+// appear more than once.
+func (c *cluster) send(op Op, namespace ident.ID, hostIDs []string) map[string]response {
+	c.Lock()
+	behaviors := make(map[string]HostBehavior, len(hostIDs))
+	for _, id := range hostIDs {
+		if _, ok := behaviors[id]; ok {
+			continue
+		}
+		h := c.hosts[id]
+		h.requests++
+		behaviors[id] = h.behavior
+	}
+	c.Unlock()
+
+	// NB: ErrFn is called without holding the lock so that scripts are free
+	// to call back into the cluster, e.g. to reset their own behavior.
+	timeout := c.opts.RequestTimeout()
+	responses := make(map[string]response, len(behaviors))
+	for id, b := range behaviors {
+		r := response{hostID: id, latency: b.Latency, err: b.Err}
+		if r.err == nil && b.ErrFn != nil {
+			r.err = b.ErrFn(op, namespace)
+		}
+		if r.latency >= timeout {
+			r.latency = timeout
+			if r.err == nil {
+				r.err = tterrors.NewTimeoutError(fmt.Errorf("%w: %s", errRequestTimeout, id))
+			}
+		}
+		responses[id] = r
+	}
+	return responses
+}
+
+// available returns whether a host would currently succeed a request
+// without calling back into user scripts, it must be called holding the lock.
+func (c *cluster) available(hostID string) bool {
+	b := c.hosts[hostID].behavior
+	return b.Err == nil && b.Latency < c.opts.RequestTimeout()
+}
+
+// ordered returns the responses of the owners ordered by latency, ties are
+// broken by the order of the owners.
+func ordered(owners []string, responses map[string]response) []response {
+	result := make([]response, 0, len(owners))
+	for _, id := range owners {
+		result = append(result, responses[id])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].latency < result[j].latency
+	})
+	return result
+}
+
+func validateFixture(f Series) error {
+	if f.Namespace == "" {
+		return errFixtureNoNamespace
+	}
+	if f.ID == "" {
+		return errFixtureNoID
+	}
+	return nil
+}
+
+func (h *host) load(shard uint32, f Series) {
+	unit := f.Unit
+	if unit == xtime.None {
+		unit = xtime.Second
+	}
+	s := h.series(f.Namespace, f.ID, shard)
+	if len(f.Tags) > 0 {
+		s.tags = tagsFromMap(f.Tags)
+	}
+	for _, dp := range f.Datapoints {
+		s.write(point{dp: dp, unit: unit})
+	}
+}
+
+func (h *host) series(namespace, id string, shard uint32) *series {
+	ns, ok := h.namespaces[namespace]
+	if !ok {
+		ns = make(map[string]*series)
+		h.namespaces[namespace] = ns
+	}
+	s, ok := ns[id]
+	if !ok {
+		s = &series{shard: shard}
+		ns[id] = s
+	}
+	return s
+}
+
+// write inserts the point keeping points ordered by time, a point at the
+// same time as an existing one replaces it.
+func (s *series) write(p point) {
+	t := p.dp.TimestampNanos
+	i := sort.Search(len(s.points), func(i int) bool {
+		return s.points[i].dp.TimestampNanos >= t
+	})
+	if i < len(s.points) && s.points[i].dp.TimestampNanos == t {
+		s.points[i] = p
+		return
+	}
+	s.points = append(s.points, point{})
+	copy(s.points[i+1:], s.points[i:])
+	s.points[i] = p
+}
+
+// inRange returns the points in [start, end).
+func (s *series) inRange(start, end xtime.UnixNano) []point {
+	lo := sort.Search(len(s.points), func(i int) bool {
+		return !s.points[i].dp.TimestampNanos.Before(start)
+	})
+	hi := sort.Search(len(s.points), func(i int) bool {
+		return !s.points[i].dp.TimestampNanos.Before(end)
+	})
+	return s.points[lo:hi]
+}
+
+func tagsFromMap(m map[string]string) []tag {
+	tags := make([]tag, 0, len(m))
+	for name, value := range m {
+		tags = append(tags, tag{name: name, value: value})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].name < tags[j].name
+	})
+	return tags
+}
+
+func tagsFromIter(iter ident.TagIterator) ([]tag, error) {
+	if iter == nil {
+		return nil, nil
+	}
+	iter = iter.Duplicate()
+	defer iter.Close()
+
+	tags := make([]tag, 0, iter.Remaining())
+	for iter.Next() {
+		t := iter.Current()
+		tags = append(tags, tag{name: t.Name.String(), value: t.Value.String()})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].name < tags[j].name
+	})
+	return tags, nil
+}
+
+func identTags(tags []tag) ident.Tags {
+	result := make([]ident.Tag, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, ident.StringTag(t.name, t.value))
+	}
+	return ident.NewTags(result...)
+}
@@ -0,0 +1,341 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/m3ninx/idx"
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+var (
+	testNamespace = ident.StringID("metrics")
+	testStart     = xtime.FromSeconds(1600000000)
+	testEnd       = testStart.Add(time.Hour)
+)
+
+type sleeps struct {
+	durations []time.Duration
+}
+
+func (s *sleeps) sleep(d time.Duration) {
+	s.durations = append(s.durations, d)
+}
+
+func (s *sleeps) last() time.Duration {
+	if len(s.durations) == 0 {
+		return 0
+	}
+	return s.durations[len(s.durations)-1]
+}
+
+func newTestCluster(t *testing.T, opts Options) (*cluster, *sleeps) {
+	s := &sleeps{}
+	c, err := NewCluster(opts.SetSleepFn(s.sleep))
+	require.NoError(t, err)
+	return c.(*cluster), s
+}
+
+func readValues(t *testing.T, iter encoding.SeriesIterator) []float64 {
+	var values []float64
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		values = append(values, dp.Value)
+	}
+	require.NoError(t, iter.Err())
+	return values
+}
+
+func testDatapoint(offset time.Duration, value float64) ts.Datapoint {
+	return ts.Datapoint{TimestampNanos: testStart.Add(offset), Value: value}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	require.NoError(t, NewOptions().Validate())
+	require.Error(t, NewOptions().SetHostIDs(nil).Validate())
+	require.Error(t, NewOptions().SetHostIDs([]string{"a", "a", "b"}).Validate())
+	require.Error(t, NewOptions().SetReplicas(4).Validate())
+	require.Error(t, NewOptions().SetNumShards(0).Validate())
+	require.Error(t, NewOptions().SetRequestTimeout(0).Validate())
+}
+
+func TestWriteConsistency(t *testing.T) {
+	c, sleeps := newTestCluster(t, NewOptions())
+	session := c.NewSession()
+	id := ident.StringID("foo")
+	owners := c.ShardOwners(c.ShardID(id))
+	require.Len(t, owners, 3)
+
+	hostErr := errors.New("host down")
+	require.NoError(t, c.SetHostBehavior(owners[0], HostBehavior{Err: hostErr}))
+	require.NoError(t, c.SetHostBehavior(owners[1], HostBehavior{Latency: 20 * time.Millisecond}))
+	require.NoError(t, c.SetHostBehavior(owners[2], HostBehavior{Latency: 10 * time.Millisecond}))
+
+	// The write completes once the second host acks.
+	require.NoError(t, session.Write(testNamespace, id, testStart, 1, xtime.Second, nil))
+	assert.Equal(t, 20*time.Millisecond, sleeps.last())
+
+	require.NoError(t, c.SetHostBehavior(owners[1], HostBehavior{Err: hostErr}))
+	err := session.Write(testNamespace, id, testStart.Add(time.Second), 2, xtime.Second, nil)
+	require.Error(t, err)
+	assert.True(t, client.IsConsistencyResultError(err))
+	assert.Equal(t, 1, client.NumSuccess(err))
+	assert.Equal(t, 2, client.NumError(err))
+
+	available, err := session.WriteClusterAvailability()
+	require.NoError(t, err)
+	assert.False(t, available)
+
+	// The host that acked kept both writes.
+	for _, hostID := range owners {
+		require.NoError(t, c.ResetHostBehavior(hostID))
+	}
+	one := topology.ReadConsistencyLevelOne
+	require.NoError(t, c.SetShardOwners(c.ShardID(id), owners[2]))
+	iters, _, err := session.FetchTagged(context.Background(), testNamespace,
+		index.Query{Query: idx.NewAllQuery()},
+		index.QueryOptions{StartInclusive: testStart, EndExclusive: testEnd, ReadConsistencyLevel: &one})
+	require.NoError(t, err)
+	// Series written without tags are not indexed.
+	assert.Equal(t, 0, iters.Len())
+
+	iter, err := session.Fetch(testNamespace, id, testStart, testEnd)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2}, readValues(t, iter))
+}
+
+func TestReadConsistencyWithDivergedReplicas(t *testing.T) {
+	c, _ := newTestCluster(t, NewOptions().
+		SetReadConsistencyLevel(topology.ReadConsistencyLevelOne))
+	id := ident.StringID("foo")
+	owners := c.ShardOwners(c.ShardID(id))
+
+	require.NoError(t, c.Load(Series{
+		Namespace:  testNamespace.String(),
+		ID:         id.String(),
+		Datapoints: []ts.Datapoint{testDatapoint(0, 1)},
+	}))
+	require.NoError(t, c.LoadHost(owners[0], Series{
+		Namespace:  testNamespace.String(),
+		ID:         id.String(),
+		Datapoints: []ts.Datapoint{testDatapoint(time.Minute, 2)},
+	}))
+	require.NoError(t, c.SetHostBehavior(owners[0], HostBehavior{Latency: 10 * time.Millisecond}))
+
+	// Only the fastest replica is read, which is missing the diverged point.
+	session := c.NewSession()
+	iter, err := session.Fetch(testNamespace, id, testStart, testEnd)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1}, readValues(t, iter))
+
+	// Reading all replicas merges them.
+	c.opts = c.opts.SetReadConsistencyLevel(topology.ReadConsistencyLevelAll)
+	iter, err = session.Fetch(testNamespace, id, testStart, testEnd)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2}, readValues(t, iter))
+}
+
+func TestReadTimeout(t *testing.T) {
+	c, sleeps := newTestCluster(t, NewOptions().
+		SetReadConsistencyLevel(topology.ReadConsistencyLevelMajority).
+		SetRequestTimeout(time.Second))
+	id := ident.StringID("foo")
+	owners := c.ShardOwners(c.ShardID(id))
+
+	require.NoError(t, c.SetHostBehavior(owners[0], HostBehavior{Latency: time.Minute}))
+	require.NoError(t, c.SetHostBehavior(owners[1], HostBehavior{Latency: time.Minute}))
+
+	available, err := c.NewSession().ReadClusterAvailability()
+	require.NoError(t, err)
+	assert.False(t, available)
+
+	_, err = c.NewSession().Fetch(testNamespace, id, testStart, testEnd)
+	require.Error(t, err)
+	assert.True(t, client.IsConsistencyResultError(err))
+	assert.True(t, client.IsTimeoutError(err))
+	assert.Equal(t, time.Second, sleeps.last())
+}
+
+func TestHostErrFn(t *testing.T) {
+	c, _ := newTestCluster(t, NewOptions().
+		SetWriteConsistencyLevel(topology.ConsistencyLevelAll))
+	id := ident.StringID("foo")
+	owners := c.ShardOwners(c.ShardID(id))
+
+	failures := 1
+	require.NoError(t, c.SetHostBehavior(owners[0], HostBehavior{
+		ErrFn: func(op Op, _ ident.ID) error {
+			if op != OpWrite || failures == 0 {
+				return nil
+			}
+			failures--
+			return errors.New("transient")
+		},
+	}))
+
+	session := c.NewSession()
+	require.Error(t, session.Write(testNamespace, id, testStart, 1, xtime.Second, nil))
+	require.NoError(t, session.Write(testNamespace, id, testStart, 1, xtime.Second, nil))
+	assert.Equal(t, 2, c.Requests(owners[0]))
+}
+
+func TestShardOwnership(t *testing.T) {
+	c, _ := newTestCluster(t, NewOptions().
+		SetHostIDs([]string{"a", "b", "c", "d"}).
+		SetNumShards(4).
+		SetReplicas(2).
+		SetWriteConsistencyLevel(topology.ConsistencyLevelAll))
+	id := ident.StringID("foo")
+	shard := c.ShardID(id)
+
+	session := c.NewSession()
+	sessionShard, err := session.ShardID(id)
+	require.NoError(t, err)
+	assert.Equal(t, shard, sessionShard)
+	assert.Len(t, c.ShardOwners(shard), 2)
+
+	require.NoError(t, c.SetShardOwners(shard, "d"))
+	require.NoError(t, session.Write(testNamespace, id, testStart, 1, xtime.Second, nil))
+	for _, hostID := range []string{"a", "b", "c"} {
+		assert.Equal(t, 0, c.Requests(hostID))
+	}
+	assert.Equal(t, 1, c.Requests("d"))
+
+	require.Error(t, c.SetShardOwners(4, "a"))
+	require.Error(t, c.SetShardOwners(shard, "e"))
+	require.Error(t, c.SetHostBehavior("e", HostBehavior{}))
+}
+
+func TestFetchTaggedAndAggregate(t *testing.T) {
+	c, _ := newTestCluster(t, NewOptions())
+	require.NoError(t, c.Load(
+		Series{
+			Namespace:  testNamespace.String(),
+			ID:         "cpu{city=nyc}",
+			Tags:       map[string]string{"__name__": "cpu", "city": "nyc"},
+			Datapoints: []ts.Datapoint{testDatapoint(0, 1)},
+		},
+		Series{
+			Namespace:  testNamespace.String(),
+			ID:         "cpu{city=sf}",
+			Tags:       map[string]string{"__name__": "cpu", "city": "sf"},
+			Datapoints: []ts.Datapoint{testDatapoint(0, 2)},
+		},
+		Series{
+			Namespace:  testNamespace.String(),
+			ID:         "mem{city=nyc}",
+			Tags:       map[string]string{"__name__": "mem", "city": "nyc"},
+			Datapoints: []ts.Datapoint{testDatapoint(2*time.Hour, 3)},
+		},
+	))
+
+	regexp, err := idx.NewRegexpQuery([]byte("city"), []byte("n.*"))
+	require.NoError(t, err)
+	q := index.Query{Query: idx.NewConjunctionQuery(
+		idx.NewTermQuery([]byte("__name__"), []byte("cpu")), regexp)}
+	opts := index.QueryOptions{StartInclusive: testStart, EndExclusive: testEnd}
+
+	session := c.NewSession()
+	iters, meta, err := session.FetchTagged(context.Background(), testNamespace, q, opts)
+	require.NoError(t, err)
+	assert.True(t, meta.Exhaustive)
+	require.Equal(t, 1, iters.Len())
+	assert.Equal(t, "cpu{city=nyc}", iters.Iters()[0].ID().String())
+	assert.Equal(t, []float64{1}, readValues(t, iters.Iters()[0]))
+
+	all := index.Query{Query: idx.NewFieldQuery([]byte("city"))}
+	ids, meta, err := session.FetchTaggedIDs(context.Background(), testNamespace, all,
+		index.QueryOptions{StartInclusive: testStart, EndExclusive: testEnd, SeriesLimit: 1})
+	require.NoError(t, err)
+	assert.False(t, meta.Exhaustive)
+	require.True(t, ids.Next())
+	_, id, tags := ids.Current()
+	assert.Equal(t, "cpu{city=nyc}", id.String())
+	assert.Equal(t, 2, tags.Remaining())
+	_, _, ok := ids.LatestDatapoint()
+	assert.False(t, ok)
+	assert.False(t, ids.Next())
+
+	ids, _, err = session.FetchTaggedIDs(context.Background(), testNamespace, all,
+		index.QueryOptions{StartInclusive: testStart, EndExclusive: testEnd, FetchLatestDatapoint: true})
+	require.NoError(t, err)
+	var latest []float64
+	for ids.Next() {
+		dp, _, ok := ids.LatestDatapoint()
+		require.True(t, ok)
+		latest = append(latest, dp.Value)
+	}
+	assert.Equal(t, []float64{1, 2}, latest)
+
+	_, _, err = session.FetchTaggedIDs(context.Background(), testNamespace, all,
+		index.QueryOptions{StartInclusive: testStart, EndExclusive: testEnd,
+			SeriesLimit: 1, RequireExhaustive: true})
+	require.Error(t, err)
+
+	aggregated, _, err := session.Aggregate(context.Background(), testNamespace, all,
+		index.AggregationOptions{
+			QueryOptions: index.QueryOptions{StartInclusive: testStart, EndExclusive: testEnd},
+			FieldFilter:  index.AggregateFieldFilter{[]byte("city")},
+			Type:         index.AggregateTagNamesAndValues,
+		})
+	require.NoError(t, err)
+	require.True(t, aggregated.Next())
+	name, values := aggregated.Current()
+	assert.Equal(t, "city", name.String())
+	var cities []string
+	for values.Next() {
+		cities = append(cities, values.Current().String())
+	}
+	assert.Equal(t, []string{"nyc", "sf"}, cities)
+	assert.False(t, aggregated.Next())
+}
+
+func TestClientSessions(t *testing.T) {
+	c, _ := newTestCluster(t, NewOptions())
+	cli := c.NewClient()
+
+	assert.False(t, cli.DefaultSessionActive())
+	session, err := cli.DefaultSession()
+	require.NoError(t, err)
+	assert.True(t, cli.DefaultSessionActive())
+
+	other, err := cli.DefaultSession()
+	require.NoError(t, err)
+	assert.Equal(t, session, other)
+
+	require.NoError(t, session.Close())
+	require.Error(t, session.Close())
+	require.Error(t, session.Write(testNamespace, ident.StringID("foo"),
+		testStart, 1, xtime.Second, nil))
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fake
+
+import (
+	"sort"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/x/ident"
+)
+
+type taggedIDsIterator struct {
+	namespace string
+	matches   []indexMatch
+	idx       int
+}
+
+func newTaggedIDsIterator(namespace string, matches []indexMatch) *taggedIDsIterator {
+	return &taggedIDsIterator{namespace: namespace, matches: matches, idx: -1}
+}
+
+func (it *taggedIDsIterator) Next() bool {
+	if it.idx >= len(it.matches) {
+		return false
+	}
+	it.idx++
+	return it.idx < len(it.matches)
+}
+
+func (it *taggedIDsIterator) Remaining() int {
+	if it.idx >= len(it.matches) {
+		return 0
+	}
+	return len(it.matches) - it.idx - 1
+}
+
+func (it *taggedIDsIterator) Current() (ident.ID, ident.ID, ident.TagIterator) {
+	m := it.matches[it.idx]
+	return ident.StringID(it.namespace), ident.StringID(m.id),
+		ident.NewTagsIterator(identTags(m.tags))
+}
+
+func (it *taggedIDsIterator) LatestDatapoint() (ts.Datapoint, ts.Annotation, bool) {
+	latest := it.matches[it.idx].latest
+	if latest == nil {
+		return ts.Datapoint{}, nil, false
+	}
+	return latest.dp, latest.annotation, true
+}
+
+func (it *taggedIDsIterator) Err() error {
+	return nil
+}
+
+func (it *taggedIDsIterator) Finalize() {
+	it.matches = nil
+	it.idx = 0
+}
+
+type aggregatedTagsIterator struct {
+	names  []string
+	values map[string]map[string]struct{}
+	idx    int
+}
+
+func newAggregatedTagsIterator(
+	names []string,
+	values map[string]map[string]struct{},
+) *aggregatedTagsIterator {
+	return &aggregatedTagsIterator{names: names, values: values, idx: -1}
+}
+
+func (it *aggregatedTagsIterator) Next() bool {
+	if it.idx >= len(it.names) {
+		return false
+	}
+	it.idx++
+	return it.idx < len(it.names)
+}
+
+func (it *aggregatedTagsIterator) Remaining() int {
+	if it.idx >= len(it.names) {
+		return 0
+	}
+	return len(it.names) - it.idx - 1
+}
+
+func (it *aggregatedTagsIterator) Current() (ident.ID, ident.Iterator) {
+	name := it.names[it.idx]
+	values := make([]string, 0, len(it.values[name]))
+	for value := range it.values[name] {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return ident.StringID(name), ident.NewStringIDsSliceIterator(values)
+}
+
+func (it *aggregatedTagsIterator) Err() error {
+	return nil
+}
+
+func (it *aggregatedTagsIterator) Finalize() {
+	it.names = nil
+	it.values = nil
+	it.idx = 0
+}
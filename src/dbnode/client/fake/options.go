@@ -0,0 +1,178 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/topology"
+)
+
+const (
+	defaultNumShards             = 16
+	defaultReplicas              = 3
+	defaultWriteConsistencyLevel = topology.ConsistencyLevelMajority
+	defaultReadConsistencyLevel  = topology.ReadConsistencyLevelUnstrictMajority
+	defaultRequestTimeout        = 2 * time.Second
+)
+
+var (
+	defaultHostIDs = []string{"host0", "host1", "host2"}
+
+	errNoHosts          = errors.New("fake: no hosts specified")
+	errInvalidNumShards = errors.New("fake: number of shards must be positive")
+	errInvalidReplicas  = errors.New("fake: replicas must be positive and at most the number of hosts")
+	errInvalidTimeout   = errors.New("fake: request timeout must be positive")
+	errNoSleepFn        = errors.New("fake: no sleep function specified")
+	errDuplicateHostID  = errors.New("fake: duplicate host ID")
+	errEmptyHostID      = errors.New("fake: empty host ID")
+)
+
+type options struct {
+	hostIDs               []string
+	numShards             int
+	replicas              int
+	writeConsistencyLevel topology.ConsistencyLevel
+	readConsistencyLevel  topology.ReadConsistencyLevel
+	requestTimeout        time.Duration
+	sleepFn               SleepFn
+}
+
+// NewOptions returns a new set of fake cluster options, the defaults describe
+// three hosts each owning a replica of every one of sixteen shards.
+func NewOptions() Options {
+	return &options{
+		hostIDs:               defaultHostIDs,
+		numShards:             defaultNumShards,
+		replicas:              defaultReplicas,
+		writeConsistencyLevel: defaultWriteConsistencyLevel,
+		readConsistencyLevel:  defaultReadConsistencyLevel,
+		requestTimeout:        defaultRequestTimeout,
+		sleepFn:               time.Sleep,
+	}
+}
+
+func (o *options) Validate() error {
+	if len(o.hostIDs) == 0 {
+		return errNoHosts
+	}
+	seen := make(map[string]struct{}, len(o.hostIDs))
+	for _, id := range o.hostIDs {
+		if id == "" {
+			return errEmptyHostID
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("%w: %s", errDuplicateHostID, id)
+		}
+		seen[id] = struct{}{}
+	}
+	if o.numShards <= 0 {
+		return errInvalidNumShards
+	}
+	if o.replicas <= 0 || o.replicas > len(o.hostIDs) {
+		return errInvalidReplicas
+	}
+	if err := topology.ValidateConsistencyLevel(o.writeConsistencyLevel); err != nil {
+		return err
+	}
+	if err := topology.ValidateReadConsistencyLevel(o.readConsistencyLevel); err != nil {
+		return err
+	}
+	if o.requestTimeout <= 0 {
+		return errInvalidTimeout
+	}
+	if o.sleepFn == nil {
+		return errNoSleepFn
+	}
+	return nil
+}
+
+func (o *options) SetHostIDs(value []string) Options {
+	opts := *o
+	opts.hostIDs = value
+	return &opts
+}
+
+func (o *options) HostIDs() []string {
+	return o.hostIDs
+}
+
+func (o *options) SetNumShards(value int) Options {
+	opts := *o
+	opts.numShards = value
+	return &opts
+}
+
+func (o *options) NumShards() int {
+	return o.numShards
+}
+
+func (o *options) SetReplicas(value int) Options {
+	opts := *o
+	opts.replicas = value
+	return &opts
+}
+
+func (o *options) Replicas() int {
+	return o.replicas
+}
+
+func (o *options) SetWriteConsistencyLevel(value topology.ConsistencyLevel) Options {
+	opts := *o
+	opts.writeConsistencyLevel = value
+	return &opts
+}
+
+func (o *options) WriteConsistencyLevel() topology.ConsistencyLevel {
+	return o.writeConsistencyLevel
+}
+
+func (o *options) SetReadConsistencyLevel(value topology.ReadConsistencyLevel) Options {
+	opts := *o
+	opts.readConsistencyLevel = value
+	return &opts
+}
+
+func (o *options) ReadConsistencyLevel() topology.ReadConsistencyLevel {
+	return o.readConsistencyLevel
+}
+
+func (o *options) SetRequestTimeout(value time.Duration) Options {
+	opts := *o
+	opts.requestTimeout = value
+	return &opts
+}
+
+func (o *options) RequestTimeout() time.Duration {
+	return o.requestTimeout
+}
+
+func (o *options) SetSleepFn(value SleepFn) Options {
+	opts := *o
+	opts.sleepFn = value
+	return &opts
+}
+
+func (o *options) SleepFn() SleepFn {
+	return o.sleepFn
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fake
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
+)
+
+var errQueryNotSpecified = errors.New("fake: index query not specified")
+
+// queryMatcher evaluates an index query against the tags of a single series,
+// regexps are compiled the same way the index compiles them so they are
+// anchored to the whole value.
+type queryMatcher struct {
+	query   *querypb.Query
+	regexps map[*querypb.RegexpQuery]*regexp.Regexp
+}
+
+func newQueryMatcher(q index.Query) (queryMatcher, error) {
+	if q.Query.SearchQuery() == nil {
+		return queryMatcher{}, errQueryNotSpecified
+	}
+
+	m := queryMatcher{
+		query:   q.Query.SearchQuery().ToProto(),
+		regexps: make(map[*querypb.RegexpQuery]*regexp.Regexp),
+	}
+	if err := m.compile(m.query); err != nil {
+		return queryMatcher{}, err
+	}
+	return m, nil
+}
+
+func (m queryMatcher) compile(q *querypb.Query) error {
+	switch v := q.GetQuery().(type) {
+	case *querypb.Query_Regexp:
+		re, err := m3ninxindex.CompileRegex(v.Regexp.Regexp)
+		if err != nil {
+			return err
+		}
+		m.regexps[v.Regexp] = re.Simple
+	case *querypb.Query_Negation:
+		return m.compile(v.Negation.Query)
+	case *querypb.Query_Conjunction:
+		for _, inner := range v.Conjunction.Queries {
+			if err := m.compile(inner); err != nil {
+				return err
+			}
+		}
+	case *querypb.Query_Disjunction:
+		for _, inner := range v.Disjunction.Queries {
+			if err := m.compile(inner); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m queryMatcher) matches(tags []tag) bool {
+	return m.match(m.query, tags)
+}
+
+func (m queryMatcher) match(q *querypb.Query, tags []tag) bool {
+	switch v := q.GetQuery().(type) {
+	case *querypb.Query_All:
+		return true
+	case *querypb.Query_Field:
+		for _, t := range tags {
+			if t.name == string(v.Field.Field) {
+				return true
+			}
+		}
+		return false
+	case *querypb.Query_Term:
+		for _, t := range tags {
+			if t.name == string(v.Term.Field) && t.value == string(v.Term.Term) {
+				return true
+			}
+		}
+		return false
+	case *querypb.Query_Regexp:
+		re := m.regexps[v.Regexp]
+		for _, t := range tags {
+			if t.name == string(v.Regexp.Field) && re.MatchString(t.value) {
+				return true
+			}
+		}
+		return false
+	case *querypb.Query_Negation:
+		return !m.match(v.Negation.Query, tags)
+	case *querypb.Query_Conjunction:
+		for _, inner := range v.Conjunction.Queries {
+			if !m.match(inner, tags) {
+				return false
+			}
+		}
+		return true
+	case *querypb.Query_Disjunction:
+		for _, inner := range v.Disjunction.Queries {
+			if m.match(inner, tags) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
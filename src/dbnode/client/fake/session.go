@@ -0,0 +1,648 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fake
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3/src/x/checked"
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+var (
+	errSessionClosed             = errors.New("fake: session is closed")
+	errIteratorPoolsNotSupported = errors.New("fake: iterator pools are not supported")
+	errIDsIteratorNotSpecified   = errors.New("fake: ids iterator not specified")
+
+	encodingOpts = encoding.NewOptions()
+	iterAllocFn  = m3tsz.DefaultReaderIteratorAllocFn(encodingOpts)
+)
+
+type session struct {
+	sync.RWMutex
+
+	cluster *cluster
+	closed  bool
+}
+
+// indexMatch is a series matched by an index query along with the hosts
+// whose responses are used to read it and, if requested, the latest point
+// returned by any of them.
+type indexMatch struct {
+	id      string
+	tags    []tag
+	hostIDs []string
+	latest  *point
+}
+
+func newSession(c *cluster) *session {
+	return &session{cluster: c}
+}
+
+func (s *session) WriteClusterAvailability() (bool, error) {
+	if err := s.checkOpen(); err != nil {
+		return false, err
+	}
+
+	c := s.cluster
+	c.RLock()
+	defer c.RUnlock()
+
+	level := c.opts.WriteConsistencyLevel()
+	for _, owners := range c.owners {
+		available := 0
+		for _, id := range owners {
+			if c.available(id) {
+				available++
+			}
+		}
+		majority := topology.Majority(len(owners))
+		if !topology.WriteConsistencyAchieved(level, majority, len(owners), available) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *session) ReadClusterAvailability() (bool, error) {
+	if err := s.checkOpen(); err != nil {
+		return false, err
+	}
+
+	c := s.cluster
+	c.RLock()
+	defer c.RUnlock()
+
+	level := c.opts.ReadConsistencyLevel()
+	for _, owners := range c.owners {
+		available := 0
+		for _, id := range owners {
+			if c.available(id) {
+				available++
+			}
+		}
+		majority := topology.Majority(len(owners))
+		if !topology.ReadConsistencyAchieved(level, majority, len(owners), available) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *session) Write(
+	namespace,
+	id ident.ID,
+	t xtime.UnixNano,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	return s.WriteTagged(namespace, id, nil, t, value, unit, annotation)
+}
+
+func (s *session) WriteTagged(
+	namespace,
+	id ident.ID,
+	tagsIter ident.TagIterator,
+	t xtime.UnixNano,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	tags, err := tagsFromIter(tagsIter)
+	if err != nil {
+		return err
+	}
+
+	var (
+		c         = s.cluster
+		shard     = c.hashFn(id)
+		owners    = c.ShardOwners(shard)
+		responses = c.send(OpWrite, namespace, owners)
+		p         = point{
+			dp:         ts.Datapoint{TimestampNanos: t, Value: value},
+			unit:       unit,
+			annotation: append([]byte(nil), annotation...),
+		}
+	)
+
+	// Hosts that succeed keep the write even when the write fails to meet
+	// its consistency level, as they would in a real cluster.
+	c.Lock()
+	for _, r := range responses {
+		if r.err != nil {
+			continue
+		}
+		series := c.hosts[r.hostID].series(namespace.String(), id.String(), shard)
+		if len(tags) > 0 {
+			series.tags = tags
+		}
+		series.write(p)
+	}
+	c.Unlock()
+
+	var (
+		level    = c.opts.WriteConsistencyLevel()
+		majority = topology.Majority(len(owners))
+		success  int
+		latency  time.Duration
+		errs     []error
+		achieved bool
+	)
+	for _, r := range ordered(owners, responses) {
+		latency = r.latency
+		if r.err != nil {
+			errs = append(errs, r.err)
+		} else {
+			success++
+		}
+		if topology.WriteConsistencyAchieved(level, majority, len(owners), success) {
+			achieved = true
+			break
+		}
+	}
+
+	c.opts.SleepFn()(latency)
+	if !achieved {
+		return client.NewConsistencyResultError(level, len(owners), success+len(errs), errs)
+	}
+	return nil
+}
+
+func (s *session) Fetch(
+	namespace,
+	id ident.ID,
+	startInclusive,
+	endExclusive xtime.UnixNano,
+) (encoding.SeriesIterator, error) {
+	iters, err := s.fetchIDs(namespace, []ident.ID{id}, startInclusive, endExclusive)
+	if err != nil {
+		return nil, err
+	}
+	return iters[0], nil
+}
+
+func (s *session) FetchIDs(
+	namespace ident.ID,
+	ids ident.Iterator,
+	startInclusive,
+	endExclusive xtime.UnixNano,
+) (encoding.SeriesIterators, error) {
+	if ids == nil {
+		return nil, errIDsIteratorNotSpecified
+	}
+
+	var fetchIDs []ident.ID
+	for ids.Next() {
+		fetchIDs = append(fetchIDs, ident.StringID(ids.Current().String()))
+	}
+	if err := ids.Err(); err != nil {
+		return nil, err
+	}
+
+	iters, err := s.fetchIDs(namespace, fetchIDs, startInclusive, endExclusive)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.NewSeriesIterators(iters), nil
+}
+
+func (s *session) fetchIDs(
+	namespace ident.ID,
+	ids []ident.ID,
+	start, end xtime.UnixNano,
+) ([]encoding.SeriesIterator, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	c := s.cluster
+	shards := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		shards = append(shards, c.hashFn(id))
+	}
+
+	used, err := s.read(OpFetch, namespace, shards, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	iters := make([]encoding.SeriesIterator, 0, len(ids))
+	for i, id := range ids {
+		iter, err := c.seriesIterator(namespace.String(), id.String(),
+			used[shards[i]], start, end, nil)
+		if err != nil {
+			for _, iter := range iters {
+				iter.Close()
+			}
+			return nil, err
+		}
+		iters = append(iters, iter)
+	}
+	return iters, nil
+}
+
+func (s *session) FetchTagged(
+	ctx gocontext.Context,
+	namespace ident.ID,
+	q index.Query,
+	opts index.QueryOptions,
+) (encoding.SeriesIterators, client.FetchResponseMetadata, error) {
+	matches, meta, err := s.query(ctx, OpFetchTagged, namespace, q, opts)
+	if err != nil {
+		return nil, client.FetchResponseMetadata{}, err
+	}
+
+	c := s.cluster
+	c.RLock()
+	defer c.RUnlock()
+
+	iters := make([]encoding.SeriesIterator, 0, len(matches))
+	for _, m := range matches {
+		iter, err := c.seriesIterator(namespace.String(), m.id, m.hostIDs,
+			opts.StartInclusive, opts.EndExclusive, opts.IterateEqualTimestampStrategy)
+		if err != nil {
+			for _, iter := range iters {
+				iter.Close()
+			}
+			return nil, client.FetchResponseMetadata{}, err
+		}
+		iters = append(iters, iter)
+	}
+	return encoding.NewSeriesIterators(iters), meta, nil
+}
+
+func (s *session) FetchTaggedIDs(
+	ctx gocontext.Context,
+	namespace ident.ID,
+	q index.Query,
+	opts index.QueryOptions,
+) (client.TaggedIDsIterator, client.FetchResponseMetadata, error) {
+	matches, meta, err := s.query(ctx, OpFetchTagged, namespace, q, opts)
+	if err != nil {
+		return nil, client.FetchResponseMetadata{}, err
+	}
+	return newTaggedIDsIterator(namespace.String(), matches), meta, nil
+}
+
+func (s *session) Aggregate(
+	ctx gocontext.Context,
+	namespace ident.ID,
+	q index.Query,
+	opts index.AggregationOptions,
+) (client.AggregatedTagsIterator, client.FetchResponseMetadata, error) {
+	// NB: limits apply to the number of aggregated tags rather than the
+	// number of matched series, so the series themselves are not limited.
+	queryOpts := opts.QueryOptions
+	queryOpts.SeriesLimit = 0
+	queryOpts.DocsLimit = 0
+	matches, meta, err := s.query(ctx, OpAggregate, namespace, q, queryOpts)
+	if err != nil {
+		return nil, client.FetchResponseMetadata{}, err
+	}
+
+	filter := make(map[string]struct{}, len(opts.FieldFilter))
+	for _, field := range opts.FieldFilter {
+		filter[string(field)] = struct{}{}
+	}
+
+	values := make(map[string]map[string]struct{})
+	for _, m := range matches {
+		for _, t := range m.tags {
+			if _, ok := filter[t.name]; len(filter) > 0 && !ok {
+				continue
+			}
+			if _, ok := values[t.name]; !ok {
+				values[t.name] = make(map[string]struct{})
+			}
+			if opts.Type == index.AggregateTagNamesAndValues {
+				values[t.name][t.value] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if limit := opts.SeriesLimit; limit > 0 && len(names) > limit {
+		if opts.RequireExhaustive {
+			return nil, client.FetchResponseMetadata{}, limits.NewQueryLimitExceededError(
+				fmt.Sprintf("fake: aggregate matched %d tags, limit is %d", len(names), limit))
+		}
+		names = names[:limit]
+		meta.Exhaustive = false
+	}
+
+	return newAggregatedTagsIterator(names, values), meta, nil
+}
+
+// query resolves an index query against the hosts whose responses are used
+// for each shard, with matches ordered by ID.
+func (s *session) query(
+	ctx gocontext.Context,
+	op Op,
+	namespace ident.ID,
+	q index.Query,
+	opts index.QueryOptions,
+) ([]indexMatch, client.FetchResponseMetadata, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, client.FetchResponseMetadata{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, client.FetchResponseMetadata{}, err
+	}
+
+	matcher, err := newQueryMatcher(q)
+	if err != nil {
+		return nil, client.FetchResponseMetadata{}, err
+	}
+
+	c := s.cluster
+	shards := make([]uint32, 0, c.opts.NumShards())
+	for shard := 0; shard < c.opts.NumShards(); shard++ {
+		shards = append(shards, uint32(shard))
+	}
+
+	used, err := s.read(op, namespace, shards, opts.ReadConsistencyLevel)
+	if err != nil {
+		return nil, client.FetchResponseMetadata{}, err
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	var (
+		byID      = make(map[string]*indexMatch)
+		responded = make(map[string]struct{})
+	)
+	for _, shard := range shards {
+		for _, hostID := range used[shard] {
+			responded[hostID] = struct{}{}
+			for id, series := range c.hosts[hostID].namespaces[namespace.String()] {
+				if series.shard != shard || len(series.tags) == 0 {
+					continue
+				}
+				points := series.inRange(opts.StartInclusive, opts.EndExclusive)
+				if len(points) == 0 {
+					continue
+				}
+				if !matcher.matches(series.tags) {
+					continue
+				}
+				m, ok := byID[id]
+				if !ok {
+					m = &indexMatch{id: id, tags: series.tags}
+					byID[id] = m
+				}
+				m.hostIDs = append(m.hostIDs, hostID)
+				if opts.FetchLatestDatapoint {
+					latest := points[len(points)-1]
+					if m.latest == nil || latest.dp.TimestampNanos.After(m.latest.dp.TimestampNanos) {
+						m.latest = &latest
+					}
+				}
+			}
+		}
+	}
+
+	matches := make([]indexMatch, 0, len(byID))
+	for _, m := range byID {
+		matches = append(matches, *m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].id < matches[j].id
+	})
+
+	meta := client.FetchResponseMetadata{
+		Exhaustive: true,
+		Responses:  len(responded),
+	}
+	limit := opts.SeriesLimit
+	if opts.DocsLimit > 0 && (limit <= 0 || opts.DocsLimit < limit) {
+		limit = opts.DocsLimit
+	}
+	if limit > 0 && len(matches) > limit {
+		if opts.RequireExhaustive {
+			return nil, client.FetchResponseMetadata{}, limits.NewQueryLimitExceededError(
+				fmt.Sprintf("fake: query matched %d series, limit is %d", len(matches), limit))
+		}
+		matches = matches[:limit]
+		meta.Exhaustive = false
+	}
+	return matches, meta, nil
+}
+
+// read sends a read to the owners of the shards and returns, for each shard,
+// the hosts whose responses are used once the read consistency level allows
+// the read to terminate, in the order they responded.
+func (s *session) read(
+	op Op,
+	namespace ident.ID,
+	shards []uint32,
+	levelOverride *topology.ReadConsistencyLevel,
+) (map[uint32][]string, error) {
+	var (
+		c      = s.cluster
+		level  = c.opts.ReadConsistencyLevel()
+		owners = make(map[uint32][]string, len(shards))
+		hosts  []string
+	)
+	if levelOverride != nil {
+		level = *levelOverride
+	}
+	for _, shard := range shards {
+		if _, ok := owners[shard]; ok {
+			continue
+		}
+		owners[shard] = c.ShardOwners(shard)
+		hosts = append(hosts, owners[shard]...)
+	}
+
+	var (
+		responses = c.send(op, namespace, hosts)
+		used      = make(map[uint32][]string, len(owners))
+		latency   time.Duration
+	)
+	for _, shard := range shards {
+		if _, ok := used[shard]; ok {
+			continue
+		}
+
+		var (
+			shardOwners = owners[shard]
+			majority    = topology.Majority(len(shardOwners))
+			remaining   = len(shardOwners)
+			success     int
+			hostIDs     []string
+			errs        []error
+		)
+		for _, r := range ordered(shardOwners, responses) {
+			remaining--
+			if r.latency > latency {
+				latency = r.latency
+			}
+			if r.err != nil {
+				errs = append(errs, r.err)
+			} else {
+				success++
+				hostIDs = append(hostIDs, r.hostID)
+			}
+			if topology.ReadConsistencyTermination(level, int32(majority),
+				int32(remaining), int32(success)) {
+				break
+			}
+		}
+
+		if !topology.ReadConsistencyAchieved(level, majority, len(shardOwners), success) {
+			c.opts.SleepFn()(latency)
+			return nil, client.NewConsistencyResultError(level, len(shardOwners),
+				success+len(errs), errs)
+		}
+		if hostIDs == nil {
+			hostIDs = []string{}
+		}
+		used[shard] = hostIDs
+	}
+
+	c.opts.SleepFn()(latency)
+	return used, nil
+}
+
+func (s *session) ShardID(id ident.ID) (uint32, error) {
+	if err := s.checkOpen(); err != nil {
+		return 0, err
+	}
+	return s.cluster.hashFn(id), nil
+}
+
+func (s *session) IteratorPools() (encoding.IteratorPools, error) {
+	return nil, errIteratorPoolsNotSupported
+}
+
+func (s *session) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return errSessionClosed
+	}
+	s.closed = true
+	return nil
+}
+
+func (s *session) checkOpen() error {
+	s.RLock()
+	defer s.RUnlock()
+
+	if s.closed {
+		return errSessionClosed
+	}
+	return nil
+}
+
+// seriesIterator returns an iterator over the series with a replica for
+// each of the hosts that has data for it in the range, it must be called
+// holding the cluster lock.
+func (c *cluster) seriesIterator(
+	namespace, id string,
+	hostIDs []string,
+	start, end xtime.UnixNano,
+	strategy *encoding.IterateEqualTimestampStrategy,
+) (encoding.SeriesIterator, error) {
+	var (
+		replicas []encoding.MultiReaderIterator
+		tags     []tag
+	)
+	for _, hostID := range hostIDs {
+		series, ok := c.hosts[hostID].namespaces[namespace][id]
+		if !ok {
+			continue
+		}
+		if tags == nil {
+			tags = series.tags
+		}
+		points := series.inRange(start, end)
+		if len(points) == 0 {
+			continue
+		}
+		replica, err := newReplicaIterator(points, start, end)
+		if err != nil {
+			for _, r := range replicas {
+				r.Close()
+			}
+			return nil, err
+		}
+		replicas = append(replicas, replica)
+	}
+
+	opts := encoding.SeriesIteratorOptions{
+		ID:             ident.StringID(id),
+		Namespace:      ident.StringID(namespace),
+		Tags:           ident.NewTagsIterator(identTags(tags)),
+		Replicas:       replicas,
+		StartInclusive: start,
+		EndExclusive:   end,
+	}
+	if strategy != nil {
+		opts.IterateEqualTimestampStrategy = *strategy
+	}
+	return encoding.NewSeriesIterator(opts, nil), nil
+}
+
+func newReplicaIterator(
+	points []point,
+	start, end xtime.UnixNano,
+) (encoding.MultiReaderIterator, error) {
+	encoder := m3tsz.NewEncoder(start, checked.NewBytes(nil, nil), true, encodingOpts)
+	for _, p := range points {
+		if err := encoder.Encode(p.dp, p.unit, p.annotation); err != nil {
+			return nil, err
+		}
+	}
+
+	iter := encoding.NewMultiReaderIterator(iterAllocFn, nil)
+	iter.Reset([]xio.SegmentReader{xio.NewSegmentReader(encoder.Discard())},
+		start, end.Sub(start), nil)
+	return iter, nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package fake provides an in-memory fake of an M3DB cluster that implements
+// the client interfaces, with scriptable per host latencies and errors, shard
+// ownership and data fixtures, so that applications can test consistency
+// level behaviors and partial failures without running an integration cluster.
+package fake
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+// Op is an operation performed against a fake host.
+type Op int
+
+const (
+	// OpWrite is a write of a single datapoint.
+	OpWrite Op = iota
+	// OpFetch is a fetch of series data by ID.
+	OpFetch
+	// OpFetchTagged is an index query, with or without series data.
+	OpFetchTagged
+	// OpAggregate is an index aggregate query.
+	OpAggregate
+)
+
+// String returns the operation as a string.
+func (o Op) String() string {
+	switch o {
+	case OpWrite:
+		return "write"
+	case OpFetch:
+		return "fetch"
+	case OpFetchTagged:
+		return "fetch_tagged"
+	case OpAggregate:
+		return "aggregate"
+	}
+	return "unknown"
+}
+
+// HostBehavior scripts how a fake host responds to requests.
+type HostBehavior struct {
+	// Latency is the simulated latency of every request to the host, requests
+	// with a latency at or above the request timeout fail with a timeout.
+	Latency time.Duration
+	// Err if set is returned for every request to the host.
+	Err error
+	// ErrFn if set is called for every request to the host and any error it
+	// returns fails the request, it allows failing only some operations or
+	// namespaces, or failing the next N requests.
+	ErrFn func(op Op, namespace ident.ID) error
+}
+
+// Series is a data fixture for a single series.
+type Series struct {
+	// Namespace is the namespace the series belongs to.
+	Namespace string
+	// ID is the series ID.
+	ID string
+	// Tags are the series tags, series without tags are not indexed.
+	Tags map[string]string
+	// Datapoints are the series datapoints.
+	Datapoints []ts.Datapoint
+	// Unit is the time unit the datapoints are encoded with, it defaults
+	// to seconds.
+	Unit xtime.Unit
+}
+
+// Cluster is an in-memory fake of an M3DB cluster.
+type Cluster interface {
+	// NewClient returns a client whose sessions are served by the cluster.
+	NewClient() client.Client
+
+	// NewSession returns a session served by the cluster.
+	NewSession() client.Session
+
+	// SetHostBehavior scripts the behavior of a host.
+	SetHostBehavior(hostID string, behavior HostBehavior) error
+
+	// ResetHostBehavior resets a host to respond immediately and successfully.
+	ResetHostBehavior(hostID string) error
+
+	// SetShardOwners sets the hosts owning a shard, overriding the default
+	// assignment of shards to hosts.
+	SetShardOwners(shard uint32, hostIDs ...string) error
+
+	// ShardOwners returns the hosts owning a shard.
+	ShardOwners(shard uint32) []string
+
+	// ShardID returns the shard an ID belongs to.
+	ShardID(id ident.ID) uint32
+
+	// Load writes series fixtures to every host owning their shards.
+	Load(series ...Series) error
+
+	// LoadHost writes series fixtures to a single host only, regardless of
+	// shard ownership, which is useful to simulate diverged replicas.
+	LoadHost(hostID string, series ...Series) error
+
+	// Requests returns the number of requests a host has received.
+	Requests(hostID string) int
+}
+
+// Options is a set of options for a fake cluster.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetHostIDs sets the IDs of the hosts in the cluster.
+	SetHostIDs(value []string) Options
+
+	// HostIDs returns the IDs of the hosts in the cluster.
+	HostIDs() []string
+
+	// SetNumShards sets the number of shards.
+	SetNumShards(value int) Options
+
+	// NumShards returns the number of shards.
+	NumShards() int
+
+	// SetReplicas sets the number of replicas of each shard.
+	SetReplicas(value int) Options
+
+	// Replicas returns the number of replicas of each shard.
+	Replicas() int
+
+	// SetWriteConsistencyLevel sets the write consistency level.
+	SetWriteConsistencyLevel(value topology.ConsistencyLevel) Options
+
+	// WriteConsistencyLevel returns the write consistency level.
+	WriteConsistencyLevel() topology.ConsistencyLevel
+
+	// SetReadConsistencyLevel sets the read consistency level.
+	SetReadConsistencyLevel(value topology.ReadConsistencyLevel) Options
+
+	// ReadConsistencyLevel returns the read consistency level.
+	ReadConsistencyLevel() topology.ReadConsistencyLevel
+
+	// SetRequestTimeout sets the timeout after which a request to a host fails.
+	SetRequestTimeout(value time.Duration) Options
+
+	// RequestTimeout returns the timeout after which a request to a host fails.
+	RequestTimeout() time.Duration
+
+	// SetSleepFn sets the function used to wait out the simulated latency of
+	// an operation, tests that should not block can set a no-op or record the
+	// requested durations.
+	SetSleepFn(value SleepFn) Options
+
+	// SleepFn returns the function used to wait out the simulated latency
+	// of an operation.
+	SleepFn() SleepFn
+}
+
+// SleepFn waits for a duration.
+type SleepFn func(d time.Duration)
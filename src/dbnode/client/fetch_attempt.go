@@ -36,6 +36,10 @@ type fetchAttempt struct {
 
 	result encoding.SeriesIterators
 
+	// attempts is the number of fetch attempts performed so far, only the
+	// first attempt is restricted to zone local replicas.
+	attempts int
+
 	session *session
 
 	attemptFn xretry.Fn
@@ -51,15 +55,20 @@ type fetchAttemptArgs struct {
 func (f *fetchAttempt) reset() {
 	f.args = fetchAttemptArgsZeroed
 	f.result = nil
+	f.attempts = 0
 }
 
 func (f *fetchAttempt) perform() error {
+	zoneLocal := f.attempts == 0
+	f.attempts++
+
 	result, err := f.session.fetchIDsAttempt(f.args.namespace,
-		f.args.ids, f.args.start, f.args.end)
+		f.args.ids, f.args.start, f.args.end, zoneLocal)
 	f.result = result
 
-	if IsBadRequestError(err) {
-		// Do not retry bad request errors
+	if err != nil && !IsRetryableError(err) {
+		// Do not retry errors the server classified as not retryable,
+		// e.g. bad request or resource exhausted errors.
 		err = xerrors.NewNonRetryableError(err)
 	}
 
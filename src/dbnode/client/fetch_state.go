@@ -72,6 +72,7 @@ type fetchState struct {
 
 	done          bool
 	lastResetTime time.Time
+	successHosts  []string
 }
 
 func newFetchState(pool fetchStatePool) *fetchState {
@@ -100,6 +101,10 @@ func (f *fetchState) close() {
 	f.err = nil
 	f.done = false
 	f.lastResetTime = time.Time{}
+	for i := range f.successHosts {
+		f.successHosts[i] = ""
+	}
+	f.successHosts = f.successHosts[:0]
 	f.tagResultAccumulator.Clear()
 
 	if f.pool == nil {
@@ -170,9 +175,11 @@ func (f *fetchState) completionFn(
 	switch r := result.(type) {
 	case fetchTaggedResultAccumulatorOpts:
 		f.pool.MaybeLogHostError(maybeHostFetchError{err: resultErr, host: r.host, reqRespTime: took})
+		f.maybeAddSuccessHostWithLock(r.host, resultErr)
 		done, err = f.tagResultAccumulator.AddFetchTaggedResponse(r, resultErr)
 	case aggregateResultAccumulatorOpts:
 		f.pool.MaybeLogHostError(maybeHostFetchError{err: resultErr, host: r.host, reqRespTime: took})
+		f.maybeAddSuccessHostWithLock(r.host, resultErr)
 		done, err = f.tagResultAccumulator.AddAggregateResponse(r, resultErr)
 	default:
 		// should never happen
@@ -184,10 +191,20 @@ func (f *fetchState) completionFn(
 	}
 
 	if done {
+		if err == nil {
+			f.pool.LogConsistencyAchieved(f.tagResultAccumulator.consistencyLevel, f.successHosts)
+		}
 		f.markDoneWithLock(err)
 	}
 }
 
+func (f *fetchState) maybeAddSuccessHostWithLock(host topology.Host, err error) {
+	if err != nil || host == nil {
+		return
+	}
+	f.successHosts = append(f.successHosts, host.ID())
+}
+
 func (f *fetchState) markDoneWithLock(err error) {
 	f.done = true
 	f.err = err
@@ -250,6 +267,20 @@ func (f *fetchState) asEncodingSeriesIterators(
 	return f.tagResultAccumulator.AsEncodingSeriesIterators(limit, pools, descr, opts)
 }
 
+func (f *fetchState) reportReadRepairDivergences(
+	pools fetchTaggedPools,
+	descr namespace.SchemaDescr,
+	reporter ReadRepairReporter,
+) int {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.stateType != fetchTaggedFetchState || !f.done || f.err != nil {
+		return 0
+	}
+	return f.tagResultAccumulator.reportReadRepairDivergences(pools, descr, reporter)
+}
+
 func (f *fetchState) asAggregatedTagsIterator(pools fetchTaggedPools, limit int) (
 	AggregatedTagsIterator, FetchResponseMetadata, error,
 ) {
@@ -36,6 +36,7 @@ type fetchStatePool interface {
 	Get() *fetchState
 	Put(*fetchState)
 	MaybeLogHostError(hostErr maybeHostFetchError)
+	LogConsistencyAchieved(level topology.ReadConsistencyLevel, hostIDs []string)
 }
 
 type fetchStatePoolImpl struct {
@@ -89,6 +90,15 @@ func (p *fetchStatePoolImpl) MaybeLogHostError(hostErr maybeHostFetchError) {
 		zap.Error(hostErr.err))
 }
 
+func (p *fetchStatePoolImpl) LogConsistencyAchieved(
+	level topology.ReadConsistencyLevel,
+	hostIDs []string,
+) {
+	if ce := p.logger.Check(zap.DebugLevel, "read consistency level achieved"); ce != nil {
+		ce.Write(zap.Stringer("consistencyLevel", level), zap.Strings("hosts", hostIDs))
+	}
+}
+
 type maybeHostFetchError struct {
 	// Note: both these fields should be set always.
 	host        topology.Host
@@ -23,6 +23,7 @@ package client
 import (
 	"testing"
 
+	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/x/pool"
 	"github.com/m3db/m3/src/x/sampler"
 
@@ -88,3 +89,10 @@ func (p *testFetchStatePool) Get() *fetchState {
 func (p *testFetchStatePool) MaybeLogHostError(hostErr maybeHostFetchError) {
 	panic("not implemented")
 }
+
+func (p *testFetchStatePool) LogConsistencyAchieved(
+	level topology.ReadConsistencyLevel,
+	hostIDs []string,
+) {
+	panic("not implemented")
+}
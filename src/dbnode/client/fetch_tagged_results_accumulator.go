@@ -365,6 +365,61 @@ func (accum *fetchTaggedResultAccumulator) AsEncodingSeriesIterators(
 	}, nil
 }
 
+// reportReadRepairDivergences reports the blocks that replicas returned
+// differing data for to the reporter and returns the number reported. Only
+// exhaustive reads at consistency level all that every replica responded to
+// are inspected since otherwise replicas are expected to return differing
+// sets of series.
+func (accum *fetchTaggedResultAccumulator) reportReadRepairDivergences(
+	pools fetchTaggedPools,
+	descr namespace.SchemaDescr,
+	reporter ReadRepairReporter,
+) int {
+	if accum.consistencyLevel != topology.ReadConsistencyLevelAll ||
+		len(accum.errors) > 0 || !accum.exhaustive || accum.topoMap == nil {
+		return 0
+	}
+
+	results := fetchTaggedIDResultsSortedByID(accum.fetchResponses)
+	sort.Sort(results)
+	accum.fetchResponses = fetchTaggedIDResults(results)
+
+	var (
+		replicas = accum.topoMap.Replicas()
+		shardSet = accum.topoMap.ShardSet()
+		digests  = make([][]readRepairBlockDigest, 0, replicas)
+		reported = 0
+	)
+	accum.fetchResponses.forEachID(func(elems fetchTaggedIDResults, _ bool) bool {
+		digests = digests[:0]
+		for _, elem := range elems {
+			digests = append(digests, newReadRepairBlockDigests(elem.Segments, pools, descr))
+		}
+		// A replica that did not return the series diverges on every block.
+		for len(digests) < replicas {
+			digests = append(digests, nil)
+		}
+
+		blockStarts := divergentReadRepairBlockStarts(digests)
+		if len(blockStarts) == 0 {
+			return true
+		}
+
+		elem := elems[0]
+		shard := shardSet.Lookup(ident.BytesID(elem.ID))
+		for _, blockStart := range blockStarts {
+			reporter.ReportDivergence(ReadRepairDivergence{
+				Namespace:  string(elem.NameSpace),
+				Shard:      shard,
+				BlockStart: blockStart,
+			})
+			reported++
+		}
+		return true
+	})
+	return reported
+}
+
 func (accum *fetchTaggedResultAccumulator) AsTaggedIDsIterator(
 	limit int,
 	pools fetchTaggedPools,
@@ -378,7 +433,7 @@ func (accum *fetchTaggedResultAccumulator) AsTaggedIDsIterator(
 	sort.Sort(results)
 	accum.fetchResponses = fetchTaggedIDResults(results)
 	accum.fetchResponses.forEachID(func(elems fetchTaggedIDResults, hasMore bool) bool {
-		iter.addBacking(elems[0].NameSpace, elems[0].ID, elems[0].EncodedTags)
+		iter.addBacking(elems[0].NameSpace, elems[0].ID, elems[0].EncodedTags, latestDatapoint(elems))
 		count++
 		moreElems = hasMore
 		return count < limit
@@ -394,6 +449,23 @@ func (accum *fetchTaggedResultAccumulator) AsTaggedIDsIterator(
 	}, nil
 }
 
+// latestDatapoint returns the most recent of the latest datapoints returned by each
+// replica for a series, or nil if no replica returned one. Nodes always return the
+// latest datapoint with a nanosecond timestamp so the timestamps compare directly.
+func latestDatapoint(elems fetchTaggedIDResults) *rpc.Datapoint {
+	var latest *rpc.Datapoint
+	for _, elem := range elems {
+		dp := elem.LatestDatapoint
+		if dp == nil {
+			continue
+		}
+		if latest == nil || dp.Timestamp > latest.Timestamp {
+			latest = dp
+		}
+	}
+	return latest
+}
+
 func (accum *fetchTaggedResultAccumulator) AsAggregatedTagsIterator(
 	limit int,
 	pools fetchTaggedPools,
@@ -142,6 +142,66 @@ func TestFetchTaggedResultsAccumulatorIdsMergeUnstrictMajority(t *testing.T) {
 	require.True(t, matcher.Matches(resultsIter))
 }
 
+func TestFetchTaggedResultsAccumulatorIdsMergeLatestDatapoint(t *testing.T) {
+	// rf=3, 3 identical hosts, with same shards
+	topoMap := testutil.MustNewTopologyMap(3, map[string][]shard.Shard{
+		"testhost0": testutil.ShardsRange(0, 29, shard.Available),
+		"testhost1": testutil.ShardsRange(0, 29, shard.Available),
+		"testhost2": testutil.ShardsRange(0, 29, shard.Available),
+	})
+
+	th := newTestFetchTaggedHelper(t)
+	ts1 := newTestSeries(1)
+	withLatest := func(result *rpc.FetchTaggedResult_, timestamp xtime.UnixNano, v float64) *rpc.FetchTaggedResult_ {
+		result.Elements[0].LatestDatapoint = &rpc.Datapoint{
+			Timestamp:         int64(timestamp),
+			TimestampTimeType: rpc.TimeType_UNIX_NANOSECONDS,
+			Value:             v,
+		}
+		return result
+	}
+	workflow := testFetchStateWorkflow{
+		t:         t,
+		topoMap:   topoMap,
+		level:     topology.ReadConsistencyLevelAll,
+		startTime: testStartTime,
+		endTime:   testEndTime,
+		steps: []testFetchStateWorklowStep{
+			{
+				hostname: "testhost0",
+				fetchTaggedResult: withLatest(testSerieses{ts1}.toRPCResult(th, testStartTime, true),
+					testStartTime.Add(time.Second), 1),
+			},
+			{
+				hostname: "testhost1",
+				fetchTaggedResult: withLatest(testSerieses{ts1}.toRPCResult(th, testStartTime, true),
+					testStartTime.Add(2*time.Second), 2),
+			},
+			{
+				// a replica that has not received the series datapoints yet.
+				hostname:          "testhost2",
+				fetchTaggedResult: testSerieses{ts1}.toRPCResult(th, testStartTime, true),
+				expectedDone:      true,
+			},
+		},
+	}
+	accum := workflow.run()
+
+	resultsIter, _, err := accum.AsTaggedIDsIterator(10, th.pools)
+	require.NoError(t, err)
+	require.True(t, resultsIter.Next())
+	_, id, _ := resultsIter.Current()
+	require.Equal(t, ts1.id.String(), id.String())
+
+	// the most recent datapoint across replicas is returned.
+	dp, _, ok := resultsIter.LatestDatapoint()
+	require.True(t, ok)
+	require.Equal(t, testStartTime.Add(2*time.Second), dp.TimestampNanos)
+	require.Equal(t, 2.0, dp.Value)
+	require.False(t, resultsIter.Next())
+	require.NoError(t, resultsIter.Err())
+}
+
 func TestFetchTaggedResultsAccumulatorIdsMergeReportsExhaustiveCorrectly(t *testing.T) {
 	// rf=3, 3 identical hosts, with same shards
 	topoMap := testutil.MustNewTopologyMap(3, map[string][]shard.Shard{
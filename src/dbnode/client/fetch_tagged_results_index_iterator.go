@@ -21,6 +21,9 @@
 package client
 
 import (
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/serialize"
 )
@@ -35,12 +38,17 @@ type taggedIDsIterator struct {
 		nsID ident.ID
 		tsID ident.ID
 		tags serialize.TagDecoder
+
+		latest     ts.Datapoint
+		annotation ts.Annotation
+		hasLatest  bool
 	}
 
 	backing struct {
-		nses [][]byte
-		ids  [][]byte
-		tags [][]byte
+		nses   [][]byte
+		ids    [][]byte
+		tags   [][]byte
+		latest []*rpc.Datapoint
 	}
 }
 
@@ -72,6 +80,17 @@ func (i *taggedIDsIterator) Next() bool {
 	i.current.tsID = ident.BytesID(i.backing.ids[i.currentIdx])
 	i.current.nsID = ident.BytesID(i.backing.nses[i.currentIdx])
 	i.current.tags = dec
+
+	if dp := i.backing.latest[i.currentIdx]; dp != nil {
+		timestamp, err := convert.ToTime(dp.Timestamp, dp.TimestampTimeType)
+		if err != nil {
+			i.err = err
+			return false
+		}
+		i.current.latest = ts.Datapoint{TimestampNanos: timestamp, Value: dp.Value}
+		i.current.annotation = dp.Annotation
+		i.current.hasLatest = true
+	}
 	return true
 }
 
@@ -83,10 +102,11 @@ func (i *taggedIDsIterator) Remaining() int {
 	return len(i.backing.ids) - at
 }
 
-func (i *taggedIDsIterator) addBacking(nsID, tsID, tags []byte) {
+func (i *taggedIDsIterator) addBacking(nsID, tsID, tags []byte, latest *rpc.Datapoint) {
 	i.backing.nses = append(i.backing.nses, nsID)
 	i.backing.ids = append(i.backing.ids, tsID)
 	i.backing.tags = append(i.backing.tags, tags)
+	i.backing.latest = append(i.backing.latest, latest)
 }
 
 func (i *taggedIDsIterator) Finalize() {
@@ -94,6 +114,7 @@ func (i *taggedIDsIterator) Finalize() {
 	i.backing.nses = nil
 	i.backing.ids = nil
 	i.backing.tags = nil
+	i.backing.latest = nil
 }
 
 func (i *taggedIDsIterator) release() {
@@ -109,12 +130,19 @@ func (i *taggedIDsIterator) release() {
 		decoder.Close()
 		i.current.tags = nil
 	}
+	i.current.latest = ts.Datapoint{}
+	i.current.annotation = nil
+	i.current.hasLatest = false
 }
 
 func (i *taggedIDsIterator) Current() (ident.ID, ident.ID, ident.TagIterator) {
 	return i.current.nsID, i.current.tsID, i.current.tags
 }
 
+func (i *taggedIDsIterator) LatestDatapoint() (ts.Datapoint, ts.Annotation, bool) {
+	return i.current.latest, i.current.annotation, i.current.hasLatest
+}
+
 func (i *taggedIDsIterator) Err() error {
 	return i.err
 }
@@ -22,10 +22,13 @@ package client
 
 import (
 	"testing"
+	"time"
 
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/x/serialize"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/pool"
+	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -38,11 +41,14 @@ func TestFetchTaggedResultsIndexIterator(t *testing.T) {
 	encPool := serialize.NewTagEncoderPool(opts, popts)
 	encPool.Init()
 
+	now := xtime.Now().Truncate(time.Second)
+
 	for _, tc := range []struct {
-		name string
-		nses []ident.ID
-		ids  []ident.ID
-		tags []ident.TagIterator
+		name   string
+		nses   []ident.ID
+		ids    []ident.ID
+		tags   []ident.TagIterator
+		latest []*rpc.Datapoint
 	}{
 		{
 			"testcase0",
@@ -56,6 +62,20 @@ func TestFetchTaggedResultsIndexIterator(t *testing.T) {
 				ident.NewTagsIterator(ident.NewTags(
 					ident.StringTag("tn0", "tv0"), ident.StringTag("tn1", "tv1"), ident.StringTag("tn2", "tv2"))),
 			},
+			[]*rpc.Datapoint{
+				nil,
+				{
+					Timestamp:         int64(now),
+					TimestampTimeType: rpc.TimeType_UNIX_NANOSECONDS,
+					Value:             1.5,
+					Annotation:        []byte("foo"),
+				},
+				{
+					Timestamp:         int64(now / xtime.UnixNano(time.Second)),
+					TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+					Value:             2,
+				},
+			},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -70,7 +90,7 @@ func TestFetchTaggedResultsIndexIterator(t *testing.T) {
 				require.NoError(t, err)
 				data, ok := enc.Data()
 				require.True(t, ok)
-				iter.addBacking(ns.Bytes(), id.Bytes(), data.Bytes())
+				iter.addBacking(ns.Bytes(), id.Bytes(), data.Bytes(), tc.latest[i])
 			}
 
 			// validate iter
@@ -81,6 +101,16 @@ func TestFetchTaggedResultsIndexIterator(t *testing.T) {
 				require.Equal(t, expNs.String(), obsNs.String())
 				require.Equal(t, expID.String(), obsID.String())
 				require.True(t, ident.NewTagIterMatcher(expTags).Matches(obsTags))
+
+				dp, annotation, ok := iter.LatestDatapoint()
+				expLatest := tc.latest[i]
+				require.Equal(t, expLatest != nil, ok)
+				if expLatest == nil {
+					continue
+				}
+				require.Equal(t, now, dp.TimestampNanos)
+				require.Equal(t, expLatest.Value, dp.Value)
+				require.Equal(t, expLatest.Annotation, []byte(annotation))
 			}
 		})
 	}
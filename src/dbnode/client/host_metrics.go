@@ -0,0 +1,221 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	// hostErrorRateWindow is the window the rolling per host error rate is
+	// calculated over.
+	hostErrorRateWindow = time.Minute
+	// hostErrorRateBuckets is the number of buckets the error rate window is
+	// divided into, outcomes age out of the window one bucket at a time.
+	hostErrorRateBuckets = 6
+)
+
+// sessionHostMetrics tracks the outcomes of the requests the session fans
+// out to each host, so an unhealthy replica is not hidden by the aggregation
+// of errors across hosts.
+type sessionHostMetrics struct {
+	sync.RWMutex
+
+	scope tally.Scope
+	nowFn clock.NowFn
+	hosts map[string]*hostMetrics
+}
+
+func newSessionHostMetrics(scope tally.Scope, nowFn clock.NowFn) *sessionHostMetrics {
+	return &sessionHostMetrics{
+		scope: scope.SubScope("host"),
+		nowFn: nowFn,
+		hosts: make(map[string]*hostMetrics),
+	}
+}
+
+// forHost returns the metrics of a host, creating them if required.
+func (m *sessionHostMetrics) forHost(hostID string) *hostMetrics {
+	if m == nil {
+		return nil
+	}
+
+	m.RLock()
+	h, ok := m.hosts[hostID]
+	m.RUnlock()
+	if ok {
+		return h
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if h, ok := m.hosts[hostID]; ok {
+		return h
+	}
+	h = newHostMetrics(m.scope.Tagged(map[string]string{
+		"host": hostID,
+	}), m.nowFn)
+	m.hosts[hostID] = h
+	return h
+}
+
+// errorRate returns the rolling error rate of a host.
+func (m *sessionHostMetrics) errorRate(hostID string) float64 {
+	if m == nil {
+		return 0
+	}
+
+	m.RLock()
+	h, ok := m.hosts[hostID]
+	m.RUnlock()
+	if !ok {
+		return 0
+	}
+	return h.errorRate()
+}
+
+type hostOutcomeBucket struct {
+	start   time.Time
+	success int64
+	errors  int64
+}
+
+type hostMetrics struct {
+	sync.Mutex
+
+	nowFn          clock.NowFn
+	buckets        [hostErrorRateBuckets]hostOutcomeBucket
+	writeSuccess   tally.Counter
+	writeErrors    tally.Counter
+	writeLatency   tally.Histogram
+	fetchSuccess   tally.Counter
+	fetchErrors    tally.Counter
+	fetchLatency   tally.Histogram
+	fetchCorrupt   tally.Counter
+	errorRateGauge tally.Gauge
+}
+
+func newHostMetrics(scope tally.Scope, nowFn clock.NowFn) *hostMetrics {
+	return &hostMetrics{
+		nowFn:          nowFn,
+		writeSuccess:   scope.Counter("write.success"),
+		writeErrors:    scope.Counter("write.errors"),
+		writeLatency:   histogramWithDurationBuckets(scope, "write.latency"),
+		fetchSuccess:   scope.Counter("fetch.success"),
+		fetchErrors:    scope.Counter("fetch.errors"),
+		fetchLatency:   histogramWithDurationBuckets(scope, "fetch.latency"),
+		fetchCorrupt:   scope.Counter("fetch.checksum-mismatch"),
+		errorRateGauge: scope.Gauge("error-rate"),
+	}
+}
+
+func (m *hostMetrics) recordWrite(took time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.writeLatency.RecordDuration(took)
+	if err != nil {
+		m.writeErrors.Inc(1)
+	} else {
+		m.writeSuccess.Inc(1)
+	}
+	m.recordOutcome(err)
+}
+
+func (m *hostMetrics) recordFetch(took time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.fetchLatency.RecordDuration(took)
+	if err != nil {
+		m.fetchErrors.Inc(1)
+	} else {
+		m.fetchSuccess.Inc(1)
+	}
+	m.recordOutcome(err)
+}
+
+// recordChecksumMismatches records fetched segments that did not match
+// their checksum.
+func (m *hostMetrics) recordChecksumMismatches(n int) {
+	if m == nil {
+		return
+	}
+	m.fetchCorrupt.Inc(int64(n))
+}
+
+func (m *hostMetrics) recordOutcome(err error) {
+	m.Lock()
+	bucket := m.bucketWithLock(m.nowFn())
+	if err != nil {
+		bucket.errors++
+	} else {
+		bucket.success++
+	}
+	rate := m.errorRateWithLock()
+	m.Unlock()
+
+	m.errorRateGauge.Update(rate)
+}
+
+func (m *hostMetrics) errorRate() float64 {
+	m.Lock()
+	m.bucketWithLock(m.nowFn())
+	rate := m.errorRateWithLock()
+	m.Unlock()
+	return rate
+}
+
+// bucketWithLock returns the bucket for the given time, resetting buckets
+// that have aged out of the error rate window.
+func (m *hostMetrics) bucketWithLock(now time.Time) *hostOutcomeBucket {
+	var (
+		bucketSize = hostErrorRateWindow / hostErrorRateBuckets
+		start      = now.Truncate(bucketSize)
+		bucket     = &m.buckets[(start.UnixNano()/int64(bucketSize))%hostErrorRateBuckets]
+	)
+	for i := range m.buckets {
+		if now.Sub(m.buckets[i].start) >= hostErrorRateWindow {
+			m.buckets[i] = hostOutcomeBucket{}
+		}
+	}
+	if !bucket.start.Equal(start) {
+		*bucket = hostOutcomeBucket{start: start}
+	}
+	return bucket
+}
+
+func (m *hostMetrics) errorRateWithLock() float64 {
+	var success, errors int64
+	for _, bucket := range m.buckets {
+		success += bucket.success
+		errors += bucket.errors
+	}
+	if total := success + errors; total > 0 {
+		return float64(errors) / float64(total)
+	}
+	return 0
+}
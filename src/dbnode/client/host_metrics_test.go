@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestSessionHostMetrics(t *testing.T) {
+	var (
+		scope = tally.NewTestScope("", nil)
+		now   = time.Unix(0, 0).Add(time.Hour)
+		nowFn = func() time.Time { return now }
+		m     = newSessionHostMetrics(scope, nowFn)
+		errFn = errors.New("host error")
+	)
+
+	host1 := m.forHost("host1")
+	require.True(t, host1 == m.forHost("host1"))
+
+	host1.recordWrite(time.Millisecond, nil)
+	host1.recordWrite(time.Millisecond, errFn)
+	host1.recordFetch(time.Millisecond, nil)
+	host1.recordFetch(time.Millisecond, nil)
+	m.forHost("host2").recordFetch(time.Millisecond, errFn)
+
+	require.Equal(t, 0.25, m.errorRate("host1"))
+	require.Equal(t, 1.0, m.errorRate("host2"))
+	require.Equal(t, 0.0, m.errorRate("host3"))
+
+	snapshot := scope.Snapshot()
+	counters := snapshot.Counters()
+	require.Equal(t, int64(1), counters["host.write.success+host=host1"].Value())
+	require.Equal(t, int64(1), counters["host.write.errors+host=host1"].Value())
+	require.Equal(t, int64(2), counters["host.fetch.success+host=host1"].Value())
+	require.Equal(t, int64(1), counters["host.fetch.errors+host=host2"].Value())
+	gauges := snapshot.Gauges()
+	require.Equal(t, 0.25, gauges["host.error-rate+host=host1"].Value())
+	require.Equal(t, 1.0, gauges["host.error-rate+host=host2"].Value())
+
+	// Outcomes age out of the rolling window one bucket at a time.
+	now = now.Add(hostErrorRateWindow / 2)
+	host1.recordWrite(time.Millisecond, nil)
+	require.Equal(t, 0.2, m.errorRate("host1"))
+
+	now = now.Add(hostErrorRateWindow / 2)
+	require.Equal(t, 0.0, m.errorRate("host1"))
+	require.Equal(t, 0.0, m.errorRate("host2"))
+}
+
+func TestHostMetricsNilSafe(t *testing.T) {
+	var (
+		m *sessionHostMetrics
+		h = m.forHost("host1")
+	)
+	require.Nil(t, h)
+	h.recordWrite(time.Millisecond, nil)
+	h.recordFetch(time.Millisecond, errors.New("host error"))
+}
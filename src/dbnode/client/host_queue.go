@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/ident"
@@ -47,6 +48,10 @@ var (
 	ErrCallMissingContext = errors.New("call missing context")
 	// ErrCallWithoutDeadline returned when call context has no deadline.
 	ErrCallWithoutDeadline = errors.New("call context without deadline")
+
+	waitForIndexHeaders = map[string]string{
+		tchannelthrift.WaitForIndexHeader: "true",
+	}
 )
 
 type queue struct {
@@ -76,6 +81,7 @@ type queue struct {
 	drainIn                                      chan []op
 	writeOpBatchSize                             tally.Histogram
 	fetchOpBatchSize                             tally.Histogram
+	hostMetrics                                  *hostMetrics
 	status                                       status
 	serverSupportsV2APIs                         bool
 }
@@ -154,6 +160,7 @@ func newHostQueue(
 		opsArrayPool:                                 opArrayPool,
 		writeOpBatchSize:                             scope.Histogram("write-op-batch-size", writeOpBatchSizeBuckets),
 		fetchOpBatchSize:                             scope.Histogram("fetch-op-batch-size", fetchOpBatchSizeBuckets),
+		hostMetrics:                                  hostQueueOpts.hostMetrics.forHost(host.ID()),
 		drainIn:                                      make(chan []op, opsArrayLen),
 		serverSupportsV2APIs:                         opts.UseV2BatchAPIs(),
 	}, nil
@@ -540,16 +547,22 @@ func (q *queue) asyncTaggedWrite(
 		// NB(bl): host is passed to writeState to determine the state of the
 		// shard on the node we're writing to
 
+		start := q.nowFn()
 		client, _, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available
+			q.recordWrite(start, err)
 			callAllCompletionFns(ops, q.host, err)
 			cleanup()
 			return
 		}
 
 		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
+		if q.opts.WriteWaitForIndex() {
+			ctx = thrift.WithHeaders(ctx, waitForIndexHeaders)
+		}
 		err = client.WriteTaggedBatchRaw(ctx, req)
+		q.recordWrite(start, err)
 		if err == nil {
 			// All succeeded
 			callAllCompletionFns(ops, q.host, nil)
@@ -600,16 +613,22 @@ func (q *queue) asyncTaggedWriteV2(
 
 		// NB(bl): host is passed to writeState to determine the state of the
 		// shard on the node we're writing to.
+		start := q.nowFn()
 		client, _, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available
+			q.recordWrite(start, err)
 			callAllCompletionFns(ops, q.host, err)
 			cleanup()
 			return
 		}
 
 		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
+		if q.opts.WriteWaitForIndex() {
+			ctx = thrift.WithHeaders(ctx, waitForIndexHeaders)
+		}
 		err = client.WriteTaggedBatchRawV2(ctx, req)
+		q.recordWrite(start, err)
 		if err == nil {
 			// All succeeded
 			callAllCompletionFns(ops, q.host, nil)
@@ -665,9 +684,11 @@ func (q *queue) asyncWrite(
 		// NB(bl): host is passed to writeState to determine the state of the
 		// shard on the node we're writing to
 
+		start := q.nowFn()
 		client, _, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available
+			q.recordWrite(start, err)
 			callAllCompletionFns(ops, q.host, err)
 			cleanup()
 			return
@@ -675,6 +696,7 @@ func (q *queue) asyncWrite(
 
 		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
 		err = client.WriteBatchRaw(ctx, req)
+		q.recordWrite(start, err)
 		if err == nil {
 			// All succeeded
 			callAllCompletionFns(ops, q.host, nil)
@@ -724,9 +746,11 @@ func (q *queue) asyncWriteV2(
 
 		// NB(bl): host is passed to writeState to determine the state of the
 		// shard on the node we're writing to.
+		start := q.nowFn()
 		client, _, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available.
+			q.recordWrite(start, err)
 			callAllCompletionFns(ops, q.host, err)
 			cleanup()
 			return
@@ -734,6 +758,7 @@ func (q *queue) asyncWriteV2(
 
 		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
 		err = client.WriteBatchRawV2(ctx, req)
+		q.recordWrite(start, err)
 		if err == nil {
 			// All succeeded.
 			callAllCompletionFns(ops, q.host, nil)
@@ -777,9 +802,11 @@ func (q *queue) asyncFetch(op *fetchBatchOp) {
 			q.Done()
 		}
 
+		start := q.nowFn()
 		client, _, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available
+			q.hostMetrics.recordFetch(q.nowFn().Sub(start), err)
 			op.completeAll(nil, err)
 			cleanup()
 			return
@@ -787,6 +814,7 @@ func (q *queue) asyncFetch(op *fetchBatchOp) {
 
 		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
 		result, err := client.FetchBatchRaw(ctx, &op.request)
+		q.hostMetrics.recordFetch(q.nowFn().Sub(start), err)
 		if err != nil {
 			op.completeAll(nil, err)
 			cleanup()
@@ -805,6 +833,10 @@ func (q *queue) asyncFetch(op *fetchBatchOp) {
 				op.complete(i, nil, result.Elements[i].Err)
 				continue
 			}
+			if err := q.verifySegments(result.Elements[i].Segments...); err != nil {
+				op.complete(i, nil, err)
+				continue
+			}
 			op.complete(i, result.Elements[i].Segments, nil)
 		}
 		cleanup()
@@ -830,9 +862,11 @@ func (q *queue) asyncFetchV2(
 			q.Done()
 		}
 
+		start := q.nowFn()
 		client, _, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available.
+			q.hostMetrics.recordFetch(q.nowFn().Sub(start), err)
 			callAllCompletionFns(ops, nil, err)
 			cleanup()
 			return
@@ -840,6 +874,7 @@ func (q *queue) asyncFetchV2(
 
 		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
 		result, err := client.FetchBatchRawV2(ctx, currV2FetchBatchRawReq)
+		q.hostMetrics.recordFetch(q.nowFn().Sub(start), err)
 		if err != nil {
 			callAllCompletionFns(ops, nil, err)
 			cleanup()
@@ -861,6 +896,10 @@ func (q *queue) asyncFetchV2(
 					fetchOp.complete(j, nil, result.Elements[resultIdx].Err)
 					continue
 				}
+				if err := q.verifySegments(result.Elements[resultIdx].Segments...); err != nil {
+					fetchOp.complete(j, nil, err)
+					continue
+				}
 				fetchOp.complete(j, result.Elements[resultIdx].Segments, nil)
 			}
 		}
@@ -893,19 +932,33 @@ func (q *queue) asyncFetchTagged(op *fetchTaggedOp) {
 			return
 		}
 
+		start := q.nowFn()
 		client, _, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available
+			q.hostMetrics.recordFetch(q.nowFn().Sub(start), err)
 			op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host}, err)
 			return
 		}
 
 		result, err := client.FetchTagged(ctx, &op.request)
+		q.hostMetrics.recordFetch(q.nowFn().Sub(start), err)
 		if err != nil {
 			op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host}, err)
 			return
 		}
 
+		if q.opts.FetchChecksumVerificationEnabled() {
+			// Drop the whole response of a replica returning any corrupt segment
+			// so that the results are served by the other replicas.
+			for _, elem := range result.Elements {
+				if err := q.verifySegments(elem.Segments...); err != nil {
+					op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host}, err)
+					return
+				}
+			}
+		}
+
 		op.CompletionFn()(fetchTaggedResultAccumulatorOpts{
 			host:     q.host,
 			response: result,
@@ -938,14 +991,17 @@ func (q *queue) asyncAggregate(op *aggregateOp) {
 			return
 		}
 
+		start := q.nowFn()
 		client, _, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available
+			q.hostMetrics.recordFetch(q.nowFn().Sub(start), err)
 			op.CompletionFn()(aggregateResultAccumulatorOpts{host: q.host}, err)
 			return
 		}
 
 		result, err := client.AggregateRaw(ctx, &op.request)
+		q.hostMetrics.recordFetch(q.nowFn().Sub(start), err)
 		if err != nil {
 			op.CompletionFn()(aggregateResultAccumulatorOpts{host: q.host}, err)
 			return
@@ -983,6 +1039,15 @@ func (q *queue) asyncTruncate(op *truncateOp) {
 	})
 }
 
+func (q *queue) recordWrite(start time.Time, err error) {
+	if _, ok := err.(*rpc.WriteBatchRawErrors); ok {
+		// Errors of individual writes are returned by a host that otherwise
+		// served the request, do not count them against the host.
+		err = nil
+	}
+	q.hostMetrics.recordWrite(q.nowFn().Sub(start), err)
+}
+
 func (q *queue) mustWrapAndCheckContext(
 	callingContext context.Context,
 	method string,
@@ -1123,6 +1188,24 @@ func (q *queue) Close() {
 	q.Unlock()
 }
 
+// verifySegments returns an error if checksum verification is enabled and
+// any of the segments does not match its checksum.
+func (q *queue) verifySegments(segments ...*rpc.Segments) error {
+	if !q.opts.FetchChecksumVerificationEnabled() {
+		return nil
+	}
+
+	mismatches := 0
+	for _, segs := range segments {
+		mismatches += segmentsChecksumMismatches(segs)
+	}
+	if mismatches == 0 {
+		return nil
+	}
+	q.hostMetrics.recordChecksumMismatches(mismatches)
+	return errQueueFetchChecksumMismatch(q.host.ID(), mismatches)
+}
+
 // errors
 
 func errQueueNotOpen(hostID string) error {
@@ -1137,6 +1220,11 @@ func errQueueFetchNoResponse(hostID string) error {
 	return fmt.Errorf("host operation queue did not receive response for given fetch for host: %s", hostID)
 }
 
+func errQueueFetchChecksumMismatch(hostID string, mismatches int) error {
+	return fmt.Errorf("host operation queue received %d segments not matching their checksum for host: %s",
+		mismatches, hostID)
+}
+
 // ops container types
 
 type namespaceWriteBatchOps struct {
@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 
 	"github.com/golang/mock/gomock"
@@ -156,9 +157,53 @@ func TestHostQueueFetchTaggedErrorOnFetchTaggedError(t *testing.T) {
 	})
 }
 
+func TestHostQueueFetchTaggedChecksumMismatch(t *testing.T) {
+	var (
+		namespace = "testNs"
+		checksum  = int64(digest.Checksum([]byte("headtail")))
+		corrupt   = checksum + 1
+		newResult = func(checksum int64) *rpc.FetchTaggedResult_ {
+			return &rpc.FetchTaggedResult_{
+				Elements: []*rpc.FetchTaggedIDResult_{
+					{
+						NameSpace: []byte(namespace),
+						ID:        []byte("abc"),
+						Segments: []*rpc.Segments{{
+							Merged: &rpc.Segment{
+								Head:     []byte("head"),
+								Tail:     []byte("tail"),
+								Checksum: &checksum,
+							},
+						}},
+					},
+				},
+				Exhaustive: true,
+			}
+		}
+		opts = &testHostQueueFetchTaggedOptions{verifyChecksums: true}
+	)
+
+	valid := newResult(checksum)
+	testHostQueueFetchTagged(t, namespace, valid, nil, opts, func(results []hostQueueResult) {
+		assert.Equal(t, []hostQueueResult{
+			{result: fetchTaggedResultAccumulatorOpts{response: valid, host: h}},
+		}, results)
+	})
+
+	testHostQueueFetchTagged(t, namespace, newResult(corrupt), nil, opts, func(results []hostQueueResult) {
+		assert.Equal(t, []hostQueueResult{
+			{
+				result: fetchTaggedResultAccumulatorOpts{host: h},
+				err:    errQueueFetchChecksumMismatch(h.ID(), 1),
+			},
+		}, results)
+	})
+}
+
 type testHostQueueFetchTaggedOptions struct {
-	nextClientErr  error
-	fetchTaggedErr error
+	nextClientErr   error
+	fetchTaggedErr  error
+	verifyChecksums bool
 }
 
 func testHostQueueFetchTagged(
@@ -176,6 +221,9 @@ func testHostQueueFetchTagged(
 
 	opts := newHostQueueTestOptions().
 		SetHostQueueOpsFlushInterval(time.Millisecond)
+	if testOpts != nil {
+		opts = opts.SetFetchChecksumVerificationEnabled(testOpts.verifyChecksums)
+	}
 	queue := newTestHostQueue(opts)
 	queue.connPool = mockConnPool
 
@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/environment"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	nchannel "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/node/channel"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/index"
@@ -172,6 +173,18 @@ const (
 	// be used.
 	defaultUseV2BatchAPIs = false
 
+	// defaultWriteShardIDEnabled is the default setting for whether tagged writes
+	// should send the shard of the series ID to the M3DB nodes.
+	defaultWriteShardIDEnabled = false
+
+	// defaultWriteWaitForIndex is the default setting for whether tagged writes
+	// should ask the M3DB nodes to wait until the write is queryable.
+	defaultWriteWaitForIndex = false
+
+	// defaultFetchChecksumVerificationEnabled is the default setting for whether
+	// the checksums of fetched segments are verified.
+	defaultFetchChecksumVerificationEnabled = false
+
 	// defaultHostQueueWorkerPoolKillProbability is the default host queue worker pool
 	// kill probability.
 	defaultHostQueueWorkerPoolKillProbability = 0.01
@@ -261,8 +274,13 @@ type options struct {
 	writeRetrier                            xretry.Retrier
 	fetchRetrier                            xretry.Retrier
 	streamBlocksRetrier                     xretry.Retrier
+	peerStreamCodecs                        []peerstream.Codec
 	writeShardsInitializing                 bool
 	shardsLeavingCountTowardsConsistency    bool
+	readRepairReporter                      ReadRepairReporter
+	readZone                                string
+	writeFanoutOrdered                      bool
+	writeZoneLocalAckRequired               bool
 	newConnectionFn                         NewConnectionFn
 	readerIteratorAllocate                  encoding.ReaderIteratorAllocate
 	writeOperationPoolSize                  pool.Size
@@ -292,6 +310,9 @@ type options struct {
 	asyncWriteWorkerPool                    xsync.PooledWorkerPool
 	asyncWriteMaxConcurrency                int
 	useV2BatchAPIs                          bool
+	writeShardIDEnabled                     bool
+	writeWaitForIndex                       bool
+	fetchChecksumVerificationEnabled        bool
 	iterationOptions                        index.IterationOptions
 	writeTimestampOffset                    time.Duration
 	namespaceInitializer                    namespace.Initializer
@@ -444,6 +465,9 @@ func newOptions() *options {
 		asyncTopologyInitializers:               []topology.Initializer{},
 		asyncWriteMaxConcurrency:                defaultAsyncWriteMaxConcurrency,
 		useV2BatchAPIs:                          defaultUseV2BatchAPIs,
+		writeShardIDEnabled:                     defaultWriteShardIDEnabled,
+		writeWaitForIndex:                       defaultWriteWaitForIndex,
+		fetchChecksumVerificationEnabled:        defaultFetchChecksumVerificationEnabled,
 		thriftContextFn:                         defaultThriftContextFn,
 	}
 	return opts.SetEncodingM3TSZ().(*options)
@@ -802,6 +826,46 @@ func (o *options) ShardsLeavingCountTowardsConsistency() bool {
 	return o.shardsLeavingCountTowardsConsistency
 }
 
+func (o *options) SetReadRepairReporter(value ReadRepairReporter) Options {
+	opts := *o
+	opts.readRepairReporter = value
+	return &opts
+}
+
+func (o *options) ReadRepairReporter() ReadRepairReporter {
+	return o.readRepairReporter
+}
+
+func (o *options) SetReadZone(value string) Options {
+	opts := *o
+	opts.readZone = value
+	return &opts
+}
+
+func (o *options) ReadZone() string {
+	return o.readZone
+}
+
+func (o *options) SetWriteFanoutOrdered(value bool) Options {
+	opts := *o
+	opts.writeFanoutOrdered = value
+	return &opts
+}
+
+func (o *options) WriteFanoutOrdered() bool {
+	return o.writeFanoutOrdered
+}
+
+func (o *options) SetWriteZoneLocalAckRequired(value bool) Options {
+	opts := *o
+	opts.writeZoneLocalAckRequired = value
+	return &opts
+}
+
+func (o *options) WriteZoneLocalAckRequired() bool {
+	return o.writeZoneLocalAckRequired
+}
+
 func (o *options) SetTagEncoderOptions(value serialize.TagEncoderOptions) Options {
 	opts := *o
 	opts.tagEncoderOpts = value
@@ -852,6 +916,16 @@ func (o *options) StreamBlocksRetrier() xretry.Retrier {
 	return o.streamBlocksRetrier
 }
 
+func (o *options) SetPeerStreamCodecs(value []peerstream.Codec) AdminOptions {
+	opts := *o
+	opts.peerStreamCodecs = value
+	return &opts
+}
+
+func (o *options) PeerStreamCodecs() []peerstream.Codec {
+	return o.peerStreamCodecs
+}
+
 func (o *options) SetNewConnectionFn(value NewConnectionFn) AdminOptions {
 	opts := *o
 	opts.newConnectionFn = value
@@ -1132,6 +1206,36 @@ func (o *options) UseV2BatchAPIs() bool {
 	return o.useV2BatchAPIs
 }
 
+func (o *options) SetWriteShardIDEnabled(value bool) Options {
+	opts := *o
+	opts.writeShardIDEnabled = value
+	return &opts
+}
+
+func (o *options) WriteShardIDEnabled() bool {
+	return o.writeShardIDEnabled
+}
+
+func (o *options) SetWriteWaitForIndex(value bool) Options {
+	opts := *o
+	opts.writeWaitForIndex = value
+	return &opts
+}
+
+func (o *options) WriteWaitForIndex() bool {
+	return o.writeWaitForIndex
+}
+
+func (o *options) SetFetchChecksumVerificationEnabled(value bool) Options {
+	opts := *o
+	opts.fetchChecksumVerificationEnabled = value
+	return &opts
+}
+
+func (o *options) FetchChecksumVerificationEnabled() bool {
+	return o.fetchChecksumVerificationEnabled
+}
+
 func (o *options) SetIterationOptions(value index.IterationOptions) Options {
 	opts := *o
 	opts.iterationOptions = value
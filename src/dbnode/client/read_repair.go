@@ -0,0 +1,382 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/x/instrument"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReadRepairFlushInterval = 10 * time.Second
+	// defaultReadRepairMaxPending bounds the number of divergences buffered
+	// between flushes, further divergences are dropped until the next flush.
+	defaultReadRepairMaxPending = 4096
+	// defaultReadRepairMaxPublished bounds the number of divergences
+	// published to KV by all clients, the oldest are dropped first.
+	defaultReadRepairMaxPublished = 4096
+	// readRepairMaxFlushConflicts is the number of times a flush is retried
+	// when another client published divergences concurrently.
+	readRepairMaxFlushConflicts = 5
+)
+
+// ReadRepairDivergence is a namespace, shard and block start that replicas
+// returned differing data for when read at consistency level all.
+type ReadRepairDivergence struct {
+	Namespace  string
+	Shard      uint32
+	BlockStart xtime.UnixNano
+}
+
+// String returns the divergence encoded as namespace/shard/blockStart.
+func (d ReadRepairDivergence) String() string {
+	return fmt.Sprintf("%s/%d/%d", d.Namespace, d.Shard, int64(d.BlockStart))
+}
+
+// ParseReadRepairDivergence parses a divergence encoded by String.
+func ParseReadRepairDivergence(value string) (ReadRepairDivergence, error) {
+	blockStartIdx := strings.LastIndex(value, "/")
+	if blockStartIdx <= 0 {
+		return ReadRepairDivergence{}, fmt.Errorf("invalid read repair divergence: %s", value)
+	}
+	shardIdx := strings.LastIndex(value[:blockStartIdx], "/")
+	if shardIdx <= 0 {
+		return ReadRepairDivergence{}, fmt.Errorf("invalid read repair divergence: %s", value)
+	}
+
+	shard, err := strconv.ParseUint(value[shardIdx+1:blockStartIdx], 10, 32)
+	if err != nil {
+		return ReadRepairDivergence{}, fmt.Errorf("invalid read repair divergence shard: %w", err)
+	}
+	blockStart, err := strconv.ParseInt(value[blockStartIdx+1:], 10, 64)
+	if err != nil {
+		return ReadRepairDivergence{}, fmt.Errorf("invalid read repair divergence block start: %w", err)
+	}
+
+	return ReadRepairDivergence{
+		Namespace:  value[:shardIdx],
+		Shard:      uint32(shard),
+		BlockStart: xtime.UnixNano(blockStart),
+	}, nil
+}
+
+// ReadRepairReporter is notified of replica divergences detected by reads so
+// that the divergent blocks can be repaired ahead of the regular repair cycle.
+type ReadRepairReporter interface {
+	// ReportDivergence reports a divergence, it must not block.
+	ReportDivergence(divergence ReadRepairDivergence)
+}
+
+// KVReadRepairReporter is a read repair reporter that periodically publishes
+// the divergences reported since the last flush to KV for dbnodes to consume.
+type KVReadRepairReporter interface {
+	ReadRepairReporter
+
+	// Close flushes any pending divergences and stops the reporter.
+	Close()
+}
+
+type kvReadRepairReporter struct {
+	sync.Mutex
+
+	store        kv.Store
+	logger       *zap.Logger
+	maxPending   int
+	maxPublished int
+	pending      map[ReadRepairDivergence]struct{}
+	closeCh      chan struct{}
+	doneCh       chan struct{}
+	metrics      kvReadRepairReporterMetrics
+}
+
+type kvReadRepairReporterMetrics struct {
+	reported    tally.Counter
+	dropped     tally.Counter
+	flushed     tally.Counter
+	flushErrors tally.Counter
+}
+
+// NewKVReadRepairReporter returns a new read repair reporter that publishes
+// divergences to KV every flush interval, merging them with the divergences
+// already published by other clients.
+func NewKVReadRepairReporter(
+	store kv.Store,
+	flushInterval time.Duration,
+	iopts instrument.Options,
+) KVReadRepairReporter {
+	if flushInterval <= 0 {
+		flushInterval = defaultReadRepairFlushInterval
+	}
+	scope := iopts.MetricsScope().SubScope("read-repair")
+	r := &kvReadRepairReporter{
+		store:        store,
+		logger:       iopts.Logger(),
+		maxPending:   defaultReadRepairMaxPending,
+		maxPublished: defaultReadRepairMaxPublished,
+		pending:      make(map[ReadRepairDivergence]struct{}),
+		closeCh:      make(chan struct{}),
+		doneCh:       make(chan struct{}),
+		metrics: kvReadRepairReporterMetrics{
+			reported:    scope.Counter("reported"),
+			dropped:     scope.Counter("dropped"),
+			flushed:     scope.Counter("flushed"),
+			flushErrors: scope.Counter("flush-errors"),
+		},
+	}
+	go r.flushLoop(flushInterval)
+	return r
+}
+
+func (r *kvReadRepairReporter) ReportDivergence(divergence ReadRepairDivergence) {
+	r.Lock()
+	_, exists := r.pending[divergence]
+	full := !exists && len(r.pending) >= r.maxPending
+	if !full {
+		r.pending[divergence] = struct{}{}
+	}
+	r.Unlock()
+
+	if full {
+		r.metrics.dropped.Inc(1)
+		return
+	}
+	r.metrics.reported.Inc(1)
+}
+
+func (r *kvReadRepairReporter) Close() {
+	close(r.closeCh)
+	<-r.doneCh
+}
+
+func (r *kvReadRepairReporter) flushLoop(interval time.Duration) {
+	defer close(r.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.flush()
+		case <-r.closeCh:
+			r.flush()
+			return
+		}
+	}
+}
+
+func (r *kvReadRepairReporter) flush() {
+	r.Lock()
+	if len(r.pending) == 0 {
+		r.Unlock()
+		return
+	}
+	values := make([]string, 0, len(r.pending))
+	for divergence := range r.pending {
+		values = append(values, divergence.String())
+	}
+	r.pending = make(map[ReadRepairDivergence]struct{})
+	r.Unlock()
+
+	sort.Strings(values)
+	if err := r.publish(values); err != nil {
+		r.metrics.flushErrors.Inc(1)
+		r.logger.Warn("unable to publish read repair divergences", zap.Error(err))
+		return
+	}
+	r.metrics.flushed.Inc(int64(len(values)))
+}
+
+// publish merges the divergences into the ones published to KV, clients
+// share the key so the divergences are only set if no other client published
+// divergences since they were read.
+func (r *kvReadRepairReporter) publish(values []string) error {
+	var err error
+	for i := 0; i < readRepairMaxFlushConflicts; i++ {
+		var (
+			version   int
+			published commonpb.StringArrayProto
+		)
+		value, getErr := r.store.Get(kvconfig.ReadRepairDivergenceKey)
+		switch getErr {
+		case nil:
+			version = value.Version()
+			if err := value.Unmarshal(&published); err != nil {
+				return err
+			}
+		case kv.ErrNotFound:
+		default:
+			return getErr
+		}
+
+		merged := mergeReadRepairDivergences(values, published.Values, r.maxPublished)
+		_, err = r.store.CheckAndSet(kvconfig.ReadRepairDivergenceKey, version,
+			&commonpb.StringArrayProto{Values: merged})
+		if err != kv.ErrVersionMismatch {
+			return err
+		}
+	}
+	return err
+}
+
+// mergeReadRepairDivergences returns the divergences followed by the
+// published divergences that are not among them, at most max of them. The
+// most recent divergences come first so the oldest are dropped once full.
+func mergeReadRepairDivergences(values, published []string, max int) []string {
+	seen := make(map[string]struct{}, len(values)+len(published))
+	merged := make([]string, 0, len(values)+len(published))
+	add := func(value string) {
+		if _, ok := seen[value]; ok || len(merged) >= max {
+			return
+		}
+		seen[value] = struct{}{}
+		merged = append(merged, value)
+	}
+	for _, value := range values {
+		add(value)
+	}
+	for _, value := range published {
+		add(value)
+	}
+	return merged
+}
+
+// readRepairBlockDigest is the digest of the datapoints a replica returned
+// for a single block of a series.
+type readRepairBlockDigest struct {
+	blockStart xtime.UnixNano
+	digest     uint64
+}
+
+// newReadRepairBlockDigests returns the per block digests of the datapoints a
+// replica returned for a series. The segments are decoded and the unmerged
+// segments of a block merged since replicas holding the same datapoints may
+// encode them differently, e.g. one replica has flushed a block and another
+// has not. A block that fails to decode is left out so that it diverges.
+func newReadRepairBlockDigests(
+	segments []*rpc.Segments,
+	pools fetchTaggedPools,
+	descr namespace.SchemaDescr,
+) []readRepairBlockDigest {
+	var (
+		digests = make([]readRepairBlockDigest, 0, len(segments))
+		buf     [8]byte
+	)
+	for _, s := range segments {
+		var blockStart xtime.UnixNano
+		switch {
+		case s == nil:
+			continue
+		case s.Merged != nil:
+			blockStart = timeConvert(s.Merged.StartTime)
+		case len(s.Unmerged) > 0:
+			blockStart = timeConvert(s.Unmerged[0].StartTime)
+		default:
+			continue
+		}
+
+		// NB: the iterator closes the slices iterator once closed.
+		slicesIter := pools.ReaderSliceOfSlicesIterator().Get()
+		slicesIter.Reset([]*rpc.Segments{s})
+		iter := pools.MultiReaderIterator().Get()
+		iter.ResetSliceOfSlices(slicesIter, descr)
+
+		d := xxhash.New()
+		for iter.Next() {
+			dp, unit, annotation := iter.Current()
+			binary.LittleEndian.PutUint64(buf[:], uint64(dp.TimestampNanos))
+			_, _ = d.Write(buf[:])
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(dp.Value))
+			_, _ = d.Write(buf[:])
+			buf[0] = byte(unit)
+			_, _ = d.Write(buf[:1])
+			_, _ = d.Write(annotation)
+		}
+		err := iter.Err()
+		iter.Close()
+		if err != nil {
+			continue
+		}
+
+		digests = append(digests, readRepairBlockDigest{
+			blockStart: blockStart,
+			digest:     d.Sum64(),
+		})
+	}
+	return digests
+}
+
+// divergentReadRepairBlockStarts returns the block starts that were either
+// not returned by every replica or whose digests differ between replicas.
+func divergentReadRepairBlockStarts(replicas [][]readRepairBlockDigest) []xtime.UnixNano {
+	if len(replicas) < 2 {
+		return nil
+	}
+
+	type blockState struct {
+		digest    uint64
+		count     int
+		divergent bool
+	}
+	blocks := make(map[xtime.UnixNano]*blockState)
+	for _, replica := range replicas {
+		for _, b := range replica {
+			state, ok := blocks[b.blockStart]
+			if !ok {
+				blocks[b.blockStart] = &blockState{digest: b.digest, count: 1}
+				continue
+			}
+			state.count++
+			if state.digest != b.digest {
+				state.divergent = true
+			}
+		}
+	}
+
+	var divergent []xtime.UnixNano
+	for blockStart, state := range blocks {
+		if state.divergent || state.count != len(replicas) {
+			divergent = append(divergent, blockStart)
+		}
+	}
+	sort.Slice(divergent, func(i, j int) bool {
+		return divergent[i] < divergent[j]
+	})
+	return divergent
+}
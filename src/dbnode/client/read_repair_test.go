@@ -0,0 +1,193 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/x/instrument"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRepairDivergenceRoundTrip(t *testing.T) {
+	d := ReadRepairDivergence{
+		Namespace:  "ns/with/slashes",
+		Shard:      42,
+		BlockStart: xtime.UnixNano(7200000000000),
+	}
+	require.Equal(t, "ns/with/slashes/42/7200000000000", d.String())
+
+	parsed, err := ParseReadRepairDivergence(d.String())
+	require.NoError(t, err)
+	require.Equal(t, d, parsed)
+
+	for _, invalid := range []string{"", "ns", "ns/1", "ns/a/1", "ns/1/b", "/1/2"} {
+		_, err := ParseReadRepairDivergence(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func testReadRepairSegment(
+	t *testing.T,
+	start xtime.UnixNano,
+	values ...float64,
+) *rpc.Segment {
+	enc := m3tsz.NewEncoder(start, nil, m3tsz.DefaultIntOptimizationEnabled,
+		encoding.NewOptions())
+	for i, v := range values {
+		require.NoError(t, enc.Encode(ts.Datapoint{
+			TimestampNanos: start.Add(time.Duration(i) * time.Second),
+			Value:          v,
+		}, xtime.Second, nil))
+	}
+
+	var (
+		seg       = enc.Discard()
+		startTime = int64(start)
+		segment   = &rpc.Segment{StartTime: &startTime}
+	)
+	if seg.Head != nil {
+		segment.Head = append([]byte(nil), seg.Head.Bytes()...)
+	}
+	if seg.Tail != nil {
+		segment.Tail = append([]byte(nil), seg.Tail.Bytes()...)
+	}
+	return segment
+}
+
+func testReadRepairSegments(t *testing.T, starts []int64, values ...float64) []*rpc.Segments {
+	segments := make([]*rpc.Segments, 0, len(starts))
+	for i, start := range starts {
+		segments = append(segments, &rpc.Segments{
+			Merged: testReadRepairSegment(t, xtime.UnixNano(start), values[i]),
+		})
+	}
+	return segments
+}
+
+func TestDivergentReadRepairBlockStarts(t *testing.T) {
+	var (
+		pools   = newTestFetchTaggedPools()
+		digests = func(segments []*rpc.Segments) []readRepairBlockDigest {
+			return newReadRepairBlockDigests(segments, pools, nil)
+		}
+		same      = digests(testReadRepairSegments(t, []int64{1, 2}, 1, 2))
+		differs   = digests(testReadRepairSegments(t, []int64{1, 2}, 1, 3))
+		missing   = digests(testReadRepairSegments(t, []int64{1}, 1))
+		unmerged  = digests([]*rpc.Segments{{}})
+		noReplica []readRepairBlockDigest
+	)
+	require.Empty(t, unmerged)
+
+	require.Empty(t, divergentReadRepairBlockStarts([][]readRepairBlockDigest{same}))
+	require.Empty(t, divergentReadRepairBlockStarts([][]readRepairBlockDigest{same, same, same}))
+	require.Equal(t, []xtime.UnixNano{2},
+		divergentReadRepairBlockStarts([][]readRepairBlockDigest{same, differs, same}))
+	require.Equal(t, []xtime.UnixNano{2},
+		divergentReadRepairBlockStarts([][]readRepairBlockDigest{same, missing}))
+	require.Equal(t, []xtime.UnixNano{1, 2},
+		divergentReadRepairBlockStarts([][]readRepairBlockDigest{same, same, noReplica}))
+}
+
+func TestReadRepairBlockDigestsCompareDatapoints(t *testing.T) {
+	var (
+		pools  = newTestFetchTaggedPools()
+		start  = xtime.UnixNano(time.Hour)
+		merged = []*rpc.Segments{{
+			Merged: testReadRepairSegment(t, start, 1, 2, 3),
+		}}
+		// The same datapoints split across unmerged segments encode to
+		// different bytes but must not diverge.
+		unmerged = []*rpc.Segments{{
+			Unmerged: []*rpc.Segment{
+				testReadRepairSegment(t, start, 1, 2),
+				testReadRepairSegment(t, start, 1, 2, 3),
+			},
+		}}
+		corrupt = []*rpc.Segments{{
+			Merged: &rpc.Segment{Head: []byte("corrupt"), StartTime: merged[0].Merged.StartTime},
+		}}
+	)
+
+	mergedDigests := newReadRepairBlockDigests(merged, pools, nil)
+	unmergedDigests := newReadRepairBlockDigests(unmerged, pools, nil)
+	require.Len(t, mergedDigests, 1)
+	require.Equal(t, mergedDigests, unmergedDigests)
+	require.Empty(t, divergentReadRepairBlockStarts(
+		[][]readRepairBlockDigest{mergedDigests, unmergedDigests}))
+
+	// A block that fails to decode diverges from the replicas returning it.
+	require.Empty(t, newReadRepairBlockDigests(corrupt, pools, nil))
+}
+
+func TestKVReadRepairReporterFlushesOnClose(t *testing.T) {
+	store := mem.NewStore()
+	reporter := NewKVReadRepairReporter(store, time.Hour, instrument.NewOptions())
+
+	reporter.ReportDivergence(ReadRepairDivergence{Namespace: "ns", Shard: 2, BlockStart: 10})
+	reporter.ReportDivergence(ReadRepairDivergence{Namespace: "ns", Shard: 1, BlockStart: 10})
+	reporter.ReportDivergence(ReadRepairDivergence{Namespace: "ns", Shard: 1, BlockStart: 10})
+	reporter.Close()
+
+	value, err := store.Get(kvconfig.ReadRepairDivergenceKey)
+	require.NoError(t, err)
+
+	var proto commonpb.StringArrayProto
+	require.NoError(t, value.Unmarshal(&proto))
+	require.Equal(t, []string{"ns/1/10", "ns/2/10"}, proto.Values)
+}
+
+func TestKVReadRepairReporterMergesPublishedDivergences(t *testing.T) {
+	store := mem.NewStore()
+	_, err := store.Set(kvconfig.ReadRepairDivergenceKey,
+		&commonpb.StringArrayProto{Values: []string{"ns/3/10", "ns/1/10"}})
+	require.NoError(t, err)
+
+	reporter := NewKVReadRepairReporter(store, time.Hour, instrument.NewOptions())
+	reporter.ReportDivergence(ReadRepairDivergence{Namespace: "ns", Shard: 2, BlockStart: 10})
+	reporter.ReportDivergence(ReadRepairDivergence{Namespace: "ns", Shard: 1, BlockStart: 10})
+	reporter.Close()
+
+	value, err := store.Get(kvconfig.ReadRepairDivergenceKey)
+	require.NoError(t, err)
+	require.Equal(t, 2, value.Version())
+
+	var proto commonpb.StringArrayProto
+	require.NoError(t, value.Unmarshal(&proto))
+	require.Equal(t, []string{"ns/1/10", "ns/2/10", "ns/3/10"}, proto.Values)
+}
+
+func TestMergeReadRepairDivergences(t *testing.T) {
+	require.Equal(t, []string{"a", "b", "c"},
+		mergeReadRepairDivergences([]string{"a", "b"}, []string{"b", "c", "d"}, 3))
+	require.Equal(t, []string{"a"},
+		mergeReadRepairDivergences([]string{"a"}, nil, 3))
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package client
+
+import (
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/x/ident"
+)
+
+// zoneLocalReadSatisfiedWithRLock returns whether the replicas of the series
+// that reside in the read zone alone are enough to satisfy the read
+// consistency level, must be called with the session state read lock held.
+func (s *session) zoneLocalReadSatisfiedWithRLock(
+	id ident.ID,
+	level topology.ReadConsistencyLevel,
+	replicas, majority int,
+) bool {
+	var (
+		numDesired = topology.NumDesiredForReadConsistency(level, replicas, majority)
+		numLocal   int
+	)
+	if err := s.state.topoMap.RouteForEach(id, func(
+		_ int,
+		_ shard.Shard,
+		host topology.Host,
+	) {
+		if host.Zone() == s.readZone {
+			numLocal++
+		}
+	}); err != nil {
+		return false
+	}
+	return numLocal > 0 && numLocal >= numDesired
+}
+
+// zoneReadBytesCompletionFn wraps the completion function of a fetch from the
+// given host to track the bytes read from within and across zones.
+func (s *session) zoneReadBytesCompletionFn(
+	host topology.Host,
+	fn completionFn,
+) completionFn {
+	if s.readZone == "" {
+		return fn
+	}
+	counter := s.metrics.fetchCrossZoneReadBytes
+	if host.Zone() == s.readZone {
+		counter = s.metrics.fetchZoneLocalReadBytes
+	}
+	return func(result interface{}, err error) {
+		if err == nil {
+			if segments, ok := result.([]*rpc.Segments); ok {
+				counter.Inc(segmentsSize(segments))
+			}
+		}
+		fn(result, err)
+	}
+}
+
+// segmentsSize returns the number of encoded bytes held by the segments.
+func segmentsSize(segments []*rpc.Segments) int64 {
+	var size int
+	for _, s := range segments {
+		if s == nil {
+			continue
+		}
+		if s.Merged != nil {
+			size += len(s.Merged.Head) + len(s.Merged.Tail)
+		}
+		for _, u := range s.Unmerged {
+			size += len(u.Head) + len(u.Tail)
+		}
+	}
+	return int64(size)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+)
+
+// segmentsChecksumMismatches returns the number of segments that do not
+// match the checksum they were returned with, segments returned without a
+// checksum are not verified.
+func segmentsChecksumMismatches(segments *rpc.Segments) int {
+	if segments == nil {
+		return 0
+	}
+
+	mismatches := 0
+	if !segmentChecksumMatches(segments.Merged) {
+		mismatches++
+	}
+	for _, seg := range segments.Unmerged {
+		if !segmentChecksumMatches(seg) {
+			mismatches++
+		}
+	}
+	return mismatches
+}
+
+func segmentChecksumMatches(seg *rpc.Segment) bool {
+	if seg == nil || seg.Checksum == nil {
+		return true
+	}
+	// NB: The checksum is the digest of the head followed by the tail, as
+	// calculated by ts.Segment.
+	checksum := digest.NewDigest().Update(seg.Head).Update(seg.Tail).Sum32()
+	return checksum == uint32(*seg.Checksum)
+}
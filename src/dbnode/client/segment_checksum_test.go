@@ -0,0 +1,59 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentsChecksumMismatches(t *testing.T) {
+	newSegment := func(head, tail string, checksum *int64) *rpc.Segment {
+		return &rpc.Segment{Head: []byte(head), Tail: []byte(tail), Checksum: checksum}
+	}
+	var (
+		valid   = int64(digest.Checksum([]byte("headtail")))
+		corrupt = valid + 1
+	)
+
+	require.Equal(t, 0, segmentsChecksumMismatches(nil))
+	require.Equal(t, 0, segmentsChecksumMismatches(&rpc.Segments{
+		Merged: newSegment("head", "tail", &valid),
+	}))
+	require.Equal(t, 0, segmentsChecksumMismatches(&rpc.Segments{
+		Merged: newSegment("head", "tail", nil),
+	}))
+	require.Equal(t, 1, segmentsChecksumMismatches(&rpc.Segments{
+		Merged: newSegment("head", "tail", &corrupt),
+	}))
+	require.Equal(t, 2, segmentsChecksumMismatches(&rpc.Segments{
+		Unmerged: []*rpc.Segment{
+			newSegment("head", "tail", &valid),
+			newSegment("head", "tall", &valid),
+			newSegment("head", "tail", &corrupt),
+			newSegment("head", "tail", nil),
+		},
+	}))
+}
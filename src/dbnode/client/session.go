@@ -37,6 +37,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
@@ -51,6 +52,7 @@ import (
 	"github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/context"
 	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/fault"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/pool"
@@ -132,6 +134,9 @@ type sessionState struct {
 	topoWatch      topology.MapWatch
 	replicas       int
 	majority       int
+
+	// topoUpdatedCh is closed when the topology map is next updated.
+	topoUpdatedCh chan struct{}
 }
 
 func (s *sessionState) readConsistencyLevelWithRLock(
@@ -170,9 +175,15 @@ type session struct {
 	streamBlocksBatchSize                int
 	streamBlocksMetadataBatchTimeout     time.Duration
 	streamBlocksBatchTimeout             time.Duration
+	streamBlocksCodecs                   []peerstream.Codec
 	writeShardsInitializing              bool
 	shardsLeavingCountTowardsConsistency bool
+	readRepairReporter                   ReadRepairReporter
+	readZone                             string
+	writeFanoutOrdered                   bool
+	writeZoneLocalAckRequired            bool
 	metrics                              sessionMetrics
+	hostMetrics                          *sessionHostMetrics
 }
 
 type shardMetricsKey struct {
@@ -188,15 +199,22 @@ type sessionMetrics struct {
 	writeLatencyHistogram                tally.Histogram
 	writeNodesRespondingErrors           []tally.Counter
 	writeNodesRespondingBadRequestErrors []tally.Counter
+	writeZoneLocalAckMissing             tally.Counter
+	writeRerouted                        tally.Counter
 	fetchSuccess                         tally.Counter
 	fetchErrorsBadRequest                tally.Counter
 	fetchErrorsInternalError             tally.Counter
 	fetchLatencyHistogram                tally.Histogram
 	fetchNodesRespondingErrors           []tally.Counter
 	fetchNodesRespondingBadRequestErrors []tally.Counter
+	fetchReadRepairDivergences           tally.Counter
+	fetchZoneLocal                       tally.Counter
+	fetchZoneLocalReadBytes              tally.Counter
+	fetchCrossZoneReadBytes              tally.Counter
 	topologyUpdatedSuccess               tally.Counter
 	topologyUpdatedError                 tally.Counter
 	streamFromPeersMetrics               map[shardMetricsKey]streamFromPeersMetrics
+	peerStream                           *peerstream.Metrics
 }
 
 func newSessionMetrics(scope tally.Scope) sessionMetrics {
@@ -208,18 +226,25 @@ func newSessionMetrics(scope tally.Scope) sessionMetrics {
 		writeErrorsInternalError: scope.Tagged(map[string]string{
 			"error_type": "internal_error",
 		}).Counter("write.errors"),
-		writeLatencyHistogram: histogramWithDurationBuckets(scope, "write.latency"),
-		fetchSuccess:          scope.Counter("fetch.success"),
+		writeLatencyHistogram:    histogramWithDurationBuckets(scope, "write.latency"),
+		writeZoneLocalAckMissing: scope.Counter("write.zone-local-ack-missing"),
+		writeRerouted:            scope.Counter("write.rerouted"),
+		fetchSuccess:             scope.Counter("fetch.success"),
 		fetchErrorsBadRequest: scope.Tagged(map[string]string{
 			"error_type": "bad_request",
 		}).Counter("fetch.errors"),
 		fetchErrorsInternalError: scope.Tagged(map[string]string{
 			"error_type": "internal_error",
 		}).Counter("fetch.errors"),
-		fetchLatencyHistogram:  histogramWithDurationBuckets(scope, "fetch.latency"),
-		topologyUpdatedSuccess: scope.Counter("topology.updated-success"),
-		topologyUpdatedError:   scope.Counter("topology.updated-error"),
-		streamFromPeersMetrics: make(map[shardMetricsKey]streamFromPeersMetrics),
+		fetchLatencyHistogram:      histogramWithDurationBuckets(scope, "fetch.latency"),
+		fetchReadRepairDivergences: scope.Counter("fetch.read-repair-divergences"),
+		fetchZoneLocal:             scope.Counter("fetch.zone-local"),
+		fetchZoneLocalReadBytes:    scope.Counter("fetch.zone-local-read-bytes"),
+		fetchCrossZoneReadBytes:    scope.Counter("fetch.cross-zone-read-bytes"),
+		topologyUpdatedSuccess:     scope.Counter("topology.updated-success"),
+		topologyUpdatedError:       scope.Counter("topology.updated-error"),
+		streamFromPeersMetrics:     make(map[shardMetricsKey]streamFromPeersMetrics),
+		peerStream:                 peerstream.NewMetrics(scope.SubScope("peer-stream")),
 	}
 }
 
@@ -253,6 +278,7 @@ type hostQueueOpts struct {
 	writeTaggedBatchRawV2RequestElementArrayPool writeTaggedBatchRawV2RequestElementArrayPool
 	fetchBatchRawV2RequestPool                   fetchBatchRawV2RequestPool
 	fetchBatchRawV2RequestElementArrayPool       fetchBatchRawV2RequestElementArrayPool
+	hostMetrics                                  *sessionHostMetrics
 	opts                                         Options
 }
 
@@ -317,7 +343,12 @@ func newSession(opts Options) (clientSession, error) {
 		},
 		writeShardsInitializing:              opts.WriteShardsInitializing(),
 		shardsLeavingCountTowardsConsistency: opts.ShardsLeavingCountTowardsConsistency(),
+		readRepairReporter:                   opts.ReadRepairReporter(),
+		readZone:                             opts.ReadZone(),
+		writeFanoutOrdered:                   opts.WriteFanoutOrdered(),
+		writeZoneLocalAckRequired:            opts.WriteZoneLocalAckRequired(),
 		metrics:                              newSessionMetrics(scope),
+		hostMetrics:                          newSessionHostMetrics(scope, opts.ClockOptions().NowFn()),
 	}
 	s.reattemptStreamBlocksFromPeersFn = s.streamBlocksReattemptFromPeers
 	s.pickBestPeerFn = s.streamBlocksPickBestPeer
@@ -393,6 +424,7 @@ func newSession(opts Options) (clientSession, error) {
 		s.streamBlocksMetadataBatchTimeout = opts.FetchSeriesBlocksMetadataBatchTimeout()
 		s.streamBlocksBatchTimeout = opts.FetchSeriesBlocksBatchTimeout()
 		s.streamBlocksRetrier = opts.StreamBlocksRetrier()
+		s.streamBlocksCodecs = opts.PeerStreamCodecs()
 	}
 
 	if runtimeOptsMgr := opts.RuntimeOptionsManager(); runtimeOptsMgr != nil {
@@ -1024,6 +1056,10 @@ func (s *session) setTopologyWithLock(topoMap topology.Map, queues []hostQueue,
 	s.state.queuesByHostID = newQueuesByHostID
 
 	s.state.topoMap = topoMap
+	if s.state.topoUpdatedCh != nil {
+		close(s.state.topoUpdatedCh)
+	}
+	s.state.topoUpdatedCh = make(chan struct{})
 
 	s.state.replicas = replicas
 	s.state.majority = majority
@@ -1223,6 +1259,7 @@ func (s *session) newHostQueue(host topology.Host, topoMap topology.Map) (hostQu
 		writeTaggedBatchRawV2RequestElementArrayPool: writeTaggedBatchRawV2RequestElementArrayPool,
 		fetchBatchRawV2RequestPool:                   fetchBatchRawV2RequestPool,
 		fetchBatchRawV2RequestElementArrayPool:       fetchBatchRawV2RequestElementArrPool,
+		hostMetrics:                                  s.hostMetrics,
 		opts:                                         s.opts,
 	})
 	if err != nil {
@@ -1289,35 +1326,88 @@ func (s *session) writeAttempt(
 		return timestampErr
 	}
 
-	s.state.RLock()
-	if s.state.status != statusOpen {
+	// Writes that fail because a shard moved while they were in flight are
+	// re-routed with the updated topology as long as it is received within
+	// the write request timeout of the original attempt.
+	rerouteDeadline := startWriteAttempt.Add(s.opts.WriteRequestTimeout())
+	for {
+		s.state.RLock()
+		if s.state.status != statusOpen {
+			s.state.RUnlock()
+			return ErrSessionStatusNotOpen
+		}
+
+		state, majority, enqueued, err := s.writeAttemptWithRLock(
+			wType, nsID, id, inputTags, timestamp, value, timeType, annotation)
 		s.state.RUnlock()
-		return ErrSessionStatusNotOpen
-	}
 
-	state, majority, enqueued, err := s.writeAttemptWithRLock(
-		wType, nsID, id, inputTags, timestamp, value, timeType, annotation)
-	s.state.RUnlock()
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
+		// it's safe to Wait() here, as we still hold the lock on state, after it's
+		// returned from writeAttemptWithRLock.
+		state.Wait()
+
+		err = s.writeConsistencyResult(state.consistencyLevel, majority, enqueued,
+			enqueued-state.pending, int32(len(state.errors)), state.errors)
+		if err == nil && !state.zoneLocalAckSatisfied() {
+			s.metrics.writeZoneLocalAckMissing.Inc(1)
+			err = newConsistencyResultError(zoneLocalAckConsistencyLevel{
+				level: state.consistencyLevel,
+				zone:  state.zone,
+			}, int(enqueued), int(enqueued-state.pending), state.errors)
+		}
+
+		var (
+			numErrors     = int32(len(state.errors))
+			routedTopoMap = state.topoMap
+			reroute       = err != nil && state.shardNotOwned > 0
+		)
+
+		// must Unlock before decRef'ing, as the latter releases the writeState back into a
+		// pool if ref count == 0.
+		state.Unlock()
+		state.decRef()
+
+		if reroute && s.waitForTopologyUpdate(routedTopoMap, rerouteDeadline) {
+			s.metrics.writeRerouted.Inc(1)
+			continue
+		}
+
+		s.recordWriteMetrics(err, numErrors, startWriteAttempt)
 		return err
 	}
+}
 
-	// it's safe to Wait() here, as we still hold the lock on state, after it's
-	// returned from writeAttemptWithRLock.
-	state.Wait()
+// waitForTopologyUpdate waits until the topology map is updated from the given
+// map, it returns false if it is not updated before the deadline.
+func (s *session) waitForTopologyUpdate(
+	topoMap topology.Map,
+	deadline time.Time,
+) bool {
+	s.state.RLock()
+	updated := s.state.topoMap != topoMap
+	topoUpdatedCh := s.state.topoUpdatedCh
+	s.state.RUnlock()
+	if updated {
+		return true
+	}
 
-	err = s.writeConsistencyResult(state.consistencyLevel, majority, enqueued,
-		enqueued-state.pending, int32(len(state.errors)), state.errors)
+	timeout := deadline.Sub(s.nowFn())
+	if timeout <= 0 {
+		return false
+	}
 
-	s.recordWriteMetrics(err, int32(len(state.errors)), startWriteAttempt)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 
-	// must Unlock before decRef'ing, as the latter releases the writeState back into a
-	// pool if ref count == 0.
-	state.Unlock()
-	state.decRef()
-
-	return err
+	select {
+	case <-topoUpdatedCh:
+		return true
+	case <-timer.C:
+		return false
+	}
 }
 
 // NB(prateek): the returned writeState, if valid, still holds the lock. Its ownership
@@ -1383,6 +1473,13 @@ func (s *session) writeAttemptWithRLock(
 		wop.namespace = nsID
 		wop.shardID = s.state.topoMap.ShardSet().Lookup(tsID)
 		wop.request.ID = tsID.Bytes()
+		if s.opts.WriteShardIDEnabled() {
+			// NB: send the shard the ID maps to which the nodes would
+			// otherwise compute again to find the owning shard.
+			wop.requestShardID = int64(wop.shardID)
+			wop.request.ShardID = &wop.requestShardID
+			wop.requestV2.ShardID = &wop.requestShardID
+		}
 		encodedTagBytes, ok := tagEncoder.Data()
 		if !ok {
 			return nil, 0, 0, errUnableToEncodeTags
@@ -1406,6 +1503,9 @@ func (s *session) writeAttemptWithRLock(
 	state.shardsLeavingCountTowardsConsistency = s.shardsLeavingCountTowardsConsistency
 	state.topoMap = s.state.topoMap
 	state.lastResetTime = time.Now()
+	if s.writeZoneLocalAckRequired {
+		state.zone, state.zoneLocalAckRequired = s.readZone, true
+	}
 	state.incRef()
 
 	// todo@bl: Can we combine the writeOpPool and the writeStatePool?
@@ -1430,11 +1530,18 @@ func (s *session) writeAttemptWithRLock(
 		// which rely on the count when executing
 		state.pending++
 		state.queues = append(state.queues, s.state.queues[idx])
+		if state.zoneLocalAckRequired && host.Zone() == state.zone {
+			state.zoneLocalEnqueued++
+		}
 	}); err != nil {
 		state.decRef()
 		return nil, 0, 0, err
 	}
 
+	if s.writeFanoutOrdered {
+		s.orderWriteFanout(state.queues)
+	}
+
 	state.Lock()
 	for i := range state.queues {
 		state.incRef()
@@ -1500,7 +1607,7 @@ func (s *session) Aggregate(
 	f.args.ns = ns
 	f.args.query = q
 	f.args.opts = opts
-	err := s.fetchRetrier.Attempt(f.attemptFn)
+	err := s.fetchAttemptContext(ctx, f.attemptFn)
 	iter, metadata := f.resultIter, f.resultMetadata
 	s.pools.aggregateAttempt.Put(f)
 	return iter, metadata, err
@@ -1577,7 +1684,7 @@ func (s *session) FetchTagged(
 	f.args.ns = ns
 	f.args.query = q
 	f.args.opts = opts
-	err := s.fetchRetrier.Attempt(f.dataAttemptFn)
+	err := s.fetchAttemptContext(ctx, f.dataAttemptFn)
 	iters, metadata := f.dataResultIters, f.dataResultMetadata
 	s.pools.fetchTaggedAttempt.Put(f)
 	return iters, metadata, err
@@ -1594,12 +1701,23 @@ func (s *session) FetchTaggedIDs(
 	f.args.ns = ns
 	f.args.query = q
 	f.args.opts = opts
-	err := s.fetchRetrier.Attempt(f.idsAttemptFn)
+	err := s.fetchAttemptContext(ctx, f.idsAttemptFn)
 	iter, metadata := f.idsResultIter, f.idsResultMetadata
 	s.pools.fetchTaggedAttempt.Put(f)
 	return iter, metadata, err
 }
 
+// fetchAttemptContext attempts fn with the fetch retrier, only using the
+// context to cut retries short if the retrier has a budget or is deadline
+// aware so that the retries of fetches are otherwise unchanged.
+func (s *session) fetchAttemptContext(ctx gocontext.Context, fn xretry.Fn) error {
+	retryOpts := s.fetchRetrier.Options()
+	if retryOpts.Budget() == nil && !retryOpts.DeadlineAware() {
+		return s.fetchRetrier.Attempt(fn)
+	}
+	return s.fetchRetrier.AttemptContext(ctx, fn)
+}
+
 func (s *session) fetchTaggedAttempt(
 	ctx gocontext.Context,
 	ns ident.ID,
@@ -1668,6 +1786,11 @@ func (s *session) fetchTaggedAttempt(
 
 	iters, metadata, err := fetchState.asEncodingSeriesIterators(
 		s.pools, nsCtx.Schema, iterOpts, opts.SeriesLimit)
+	if err == nil && s.readRepairReporter != nil {
+		reported := fetchState.reportReadRepairDivergences(s.pools, nsCtx.Schema,
+			s.readRepairReporter)
+		s.metrics.fetchReadRepairDivergences.Inc(int64(reported))
+	}
 
 	// must Unlock() before decRef'ing, as the latter releases the fetchState back into a
 	// pool if ref count == 0.
@@ -1834,6 +1957,7 @@ func (s *session) fetchIDsAttempt(
 	inputNamespace ident.ID,
 	inputIDs ident.Iterator,
 	startInclusive, endExclusive xtime.UnixNano,
+	zoneLocal bool,
 ) (encoding.SeriesIterators, error) {
 	nsCtx, err := s.nsCtxFor(inputNamespace)
 	if err != nil {
@@ -1902,6 +2026,11 @@ func (s *session) fetchIDsAttempt(
 	majority = int32(s.state.majority)
 	numReplicas = int32(s.state.replicas)
 
+	// NB: replicas are only compared when every replica is read since
+	// otherwise the replicas not read may hold the data that is missing.
+	readRepair := s.readRepairReporter != nil &&
+		readLevel == topology.ReadConsistencyLevelAll
+
 	// NB(prateek): namespaceAccessors tracks the number of pending accessors for nsID.
 	// It is set to incremented by `replica` for each requested ID during fetch enqueuing,
 	// and once by initial request, and is decremented for each replica retrieved, inside
@@ -1929,6 +2058,9 @@ func (s *session) fetchIDsAttempt(
 			success          int32
 			errors           []error
 			errs             int32
+			shardID          uint32
+			replicaDigests   [][]readRepairBlockDigest
+			localOnly        bool
 		)
 
 		// increment namespaceAccesors by 1 to indicate it still needs to be handled by the
@@ -1984,6 +2116,16 @@ func (s *session) fetchIDsAttempt(
 					SeriesIteratorConsolidator: consolidator,
 				})
 				iters.SetAt(idx, iter)
+
+				if readRepair {
+					resultsLock.RLock()
+					allSucceeded := success == enqueued
+					digests := replicaDigests
+					resultsLock.RUnlock()
+					if allSucceeded {
+						s.reportReadRepairDivergences(namespace, shardID, digests)
+					}
+				}
 			}
 			if atomic.AddInt32(&resultsAccessors, -1) == 0 {
 				s.pools.multiReaderIteratorArray.Put(results)
@@ -2015,13 +2157,21 @@ func (s *session) fetchIDsAttempt(
 				errors = append(errors, err)
 				resultErrLock.Unlock()
 			} else {
+				segments := result.([]*rpc.Segments)
+				var digests []readRepairBlockDigest
+				if readRepair {
+					digests = newReadRepairBlockDigests(segments, s.pools, nsCtx.Schema)
+				}
 				slicesIter := s.pools.readerSliceOfSlicesIterator.Get()
-				slicesIter.Reset(result.([]*rpc.Segments))
+				slicesIter.Reset(segments)
 				multiIter := s.pools.multiReaderIterator.Get()
 				multiIter.ResetSliceOfSlices(slicesIter, nsCtx.Schema)
 				// Results is pre-allocated after creating fetch ops for this ID below
 				resultsLock.Lock()
 				results[success] = multiIter
+				if readRepair {
+					replicaDigests = append(replicaDigests, digests)
+				}
 				success++
 				snapshotSuccess = success
 				resultsLock.Unlock()
@@ -2049,12 +2199,28 @@ func (s *session) fetchIDsAttempt(
 			}
 		}
 
+		if s.readZone != "" {
+			// NB: only read from the replicas in the same zone when they alone
+			// can satisfy the read consistency level, the replicas in other
+			// zones are read from when a retry is required.
+			localOnly = zoneLocal && s.zoneLocalReadSatisfiedWithRLock(tsID,
+				readLevel, int(numReplicas), int(majority))
+			if localOnly {
+				s.metrics.fetchZoneLocal.Inc(1)
+			}
+		}
+
 		if err := s.state.topoMap.RouteForEach(tsID, func(
 			hostIdx int,
 			hostShard shard.Shard,
 			host topology.Host,
 		) {
+			if localOnly && host.Zone() != s.readZone {
+				return
+			}
+
 			// Inc safely as this for each is sequential
+			shardID = hostShard.ID()
 			enqueued++
 			pending++
 			allPending++
@@ -2083,7 +2249,8 @@ func (s *session) fetchIDsAttempt(
 			}
 
 			// Append IDWithNamespace to this request
-			f.append(namespace.Bytes(), tsID.Bytes(), completionFn)
+			f.append(namespace.Bytes(), tsID.Bytes(),
+				s.zoneReadBytesCompletionFn(host, completionFn))
 		}); err != nil {
 			routeErr = err
 			break
@@ -2133,6 +2300,22 @@ func (s *session) fetchIDsAttempt(
 	return iters, nil
 }
 
+func (s *session) reportReadRepairDivergences(
+	namespace ident.ID,
+	shard uint32,
+	replicas [][]readRepairBlockDigest,
+) {
+	blockStarts := divergentReadRepairBlockStarts(replicas)
+	for _, blockStart := range blockStarts {
+		s.readRepairReporter.ReportDivergence(ReadRepairDivergence{
+			Namespace:  namespace.String(),
+			Shard:      shard,
+			BlockStart: blockStart,
+		})
+	}
+	s.metrics.fetchReadRepairDivergences.Inc(int64(len(blockStarts)))
+}
+
 func (s *session) writeConsistencyResult(
 	level topology.ConsistencyLevel,
 	majority, enqueued, responded, resultErrs int32,
@@ -2770,6 +2953,10 @@ func (s *session) streamBlocksMetadataFromPeer(
 		req.IncludeLastRead = &optionIncludeLastRead
 
 		progress.metadataFetchBatchCall.Inc(1)
+		if err := fault.Inject(fault.PeerStreamMetadata); err != nil {
+			progress.metadataFetchBatchError.Inc(1)
+			return err
+		}
 		result, err := client.FetchBlocksMetadataRawV2(tctx, req)
 		if err != nil {
 			progress.metadataFetchBatchError.Inc(1)
@@ -3364,6 +3551,7 @@ func (s *session) streamBlocksBatchFromPeer(
 	)
 	req.NameSpace = namespaceMetadata.ID().Bytes()
 	req.Shard = int32(shard)
+	req.AcceptCompression = peerstream.Names(s.streamBlocksCodecs)
 	req.Elements = make([]*rpc.FetchBlocksRawRequestElement, 0, len(batch))
 	for i := range batch {
 		blockStart := batch[i].block.start
@@ -3383,6 +3571,9 @@ func (s *session) streamBlocksBatchFromPeer(
 
 	// Attempt request
 	if err := retrier.Attempt(func() error {
+		if err := fault.Inject(fault.PeerStreamBlocks); err != nil {
+			return err
+		}
 		var attemptErr error
 		borrowErr := peer.BorrowConnection(func(client rpc.TChanNode, _ Channel) {
 			tctx, _ := thrift.NewContext(s.streamBlocksBatchTimeout)
@@ -3402,8 +3593,28 @@ func (s *session) streamBlocksBatchFromPeer(
 		return
 	}
 
+	// Peers only compress with a codec from those accepted.
+	var codec peerstream.Codec
+	if result.IsSetCompression() {
+		codec = peerstream.Find(result.GetCompression(), s.streamBlocksCodecs)
+		if codec == nil {
+			blocksErr := fmt.Errorf(
+				"stream blocks unaccepted compression: compression=%s, peer=%s",
+				result.GetCompression(), peer.Host().String(),
+			)
+			s.reattemptStreamBlocksFromPeersFn(batch, enqueueCh, blocksErr,
+				respErrReason, nextRetryReattemptType, m)
+			m.fetchBlockError.Inc(int64(reqBlocksLen))
+			s.log.Error(blocksErr.Error())
+			return
+		}
+	}
+
 	// Parse and act on result
-	tooManyIDsLogged := false
+	var (
+		tooManyIDsLogged       = false
+		rawBytes, encodedBytes int
+	)
 	for i := range result.Elements {
 		if i >= len(batch) {
 			m.fetchBlockError.Inc(int64(len(req.Elements[i].Starts)))
@@ -3473,8 +3684,13 @@ func (s *session) streamBlocksBatchFromPeer(
 				continue
 			}
 
-			// Verify and if verify succeeds add the block from the peer
-			err := s.verifyFetchedBlock(block)
+			// Decompress, verify and if verify succeeds add the block from the peer
+			encoded, raw, err := decodeFetchedBlock(codec, block)
+			encodedBytes += encoded
+			rawBytes += raw
+			if err == nil {
+				err = s.verifyFetchedBlock(block)
+			}
 			if err == nil {
 				err = blocksResult.addBlockFromPeer(id, batch[i].encodedTags,
 					peer.Host(), block)
@@ -3500,6 +3716,18 @@ func (s *session) streamBlocksBatchFromPeer(
 			m.fetchBlockSuccess.Inc(1)
 		}
 	}
+
+	s.metrics.peerStream.Record(codec, rawBytes, encodedBytes)
+}
+
+// decodeFetchedBlock decompresses the segments of a block streamed from a
+// peer in place, returning the size of the segments before and after.
+func decodeFetchedBlock(codec peerstream.Codec, block *rpc.Block) (int, int, error) {
+	if codec == nil || block.Err != nil {
+		size := peerstream.SegmentsSize(block.Segments)
+		return size, size, nil
+	}
+	return peerstream.DecodeSegments(codec, block.Segments)
 }
 
 func (s *session) verifyFetchedBlock(block *rpc.Block) error {
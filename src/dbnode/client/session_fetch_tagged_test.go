@@ -21,6 +21,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -404,6 +405,64 @@ func TestSessionFetchTaggedMergeTest(t *testing.T) {
 	require.Equal(t, 1, numOpAllocs)
 }
 
+func TestSessionFetchAttemptContext(t *testing.T) {
+	retryOpts := xretry.NewOptions().
+		SetInitialBackoff(time.Millisecond).
+		SetMaxRetries(1)
+	tests := []struct {
+		name         string
+		retryOpts    xretry.Options
+		expectErr    bool
+		expectCalled int
+	}{
+		{
+			// NB: by default fetches are attempted and retried regardless of
+			// the context, otherwise not at all once it is canceled.
+			name:         "default",
+			retryOpts:    retryOpts,
+			expectCalled: 2,
+		},
+		{
+			name:         "budget",
+			retryOpts:    retryOpts.SetBudget(xretry.NewBudget(1, 1)),
+			expectErr:    true,
+			expectCalled: 0,
+		},
+		{
+			name:         "deadline aware",
+			retryOpts:    retryOpts.SetDeadlineAware(true),
+			expectErr:    true,
+			expectCalled: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := newSessionTestOptions().
+				SetFetchRetrier(xretry.NewRetrier(tt.retryOpts))
+			session := newTestSession(t, opts).(*session)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			called := 0
+			err := session.fetchAttemptContext(ctx, func() error {
+				called++
+				if called == 1 {
+					return fmt.Errorf("random-err")
+				}
+				return nil
+			})
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expectCalled, called)
+		})
+	}
+}
+
 func TestSessionFetchTaggedMergeWithRetriesTest(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -23,6 +23,7 @@ package client
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -455,6 +456,124 @@ func testFetchConsistencyLevel(
 	}
 }
 
+func TestSessionFetchIDsPrefersZoneLocalReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	shardSet := sessionTestShardSet()
+	var hostShardSets []topology.HostShardSet
+	for i := 0; i < sessionTestReplicas; i++ {
+		id := testHostName(i)
+		zone := "zone-a"
+		if i == 0 {
+			zone = "zone-b"
+		}
+		host := topology.NewHostWithZone(id, fmt.Sprintf("%s:9000", id), zone)
+		hostShardSets = append(hostShardSets, topology.NewHostShardSet(host, shardSet))
+	}
+
+	scope := tally.NewTestScope("", nil)
+	opts := newSessionTestOptions().
+		SetReadZone("zone-a").
+		SetReadConsistencyLevel(topology.ReadConsistencyLevelMajority).
+		SetFetchRetrier(xretry.NewRetrier(xretry.NewOptions().
+			SetInitialBackoff(time.Millisecond).
+			SetMaxRetries(1))).
+		SetTopologyInitializer(topology.NewStaticInitializer(
+			topology.NewStaticOptions().
+				SetReplicas(sessionTestReplicas).
+				SetShardSet(shardSet).
+				SetHostShardSets(hostShardSets)))
+	opts = opts.SetInstrumentOptions(opts.InstrumentOptions().
+		SetMetricsScope(scope))
+	testOpts := testOptions{nsID: ident.StringID(testNamespaceName), opts: opts}
+
+	s, err := newSession(opts)
+	require.NoError(t, err)
+	session := s.(*session)
+
+	type enqueuedFetch struct {
+		host string
+		op   *fetchBatchOp
+	}
+	enqueued := make(chan enqueuedFetch, 2*sessionTestReplicas)
+	session.newHostQueueFn = func(
+		host topology.Host,
+		opts hostQueueOpts,
+	) (hostQueue, error) {
+		hostQueue := NewMockhostQueue(ctrl)
+		hostQueue.EXPECT().Open()
+		hostQueue.EXPECT().Host().Return(host).AnyTimes()
+		hostQueue.EXPECT().ConnectionCount().
+			Return(opts.opts.MinConnectionCount()).AnyTimes()
+		hostQueue.EXPECT().Enqueue(gomock.Any()).Do(func(op op) error {
+			enqueued <- enqueuedFetch{host: host.ID(), op: op.(*fetchBatchOp)}
+			return nil
+		}).Return(nil).AnyTimes()
+		hostQueue.EXPECT().Close()
+		return hostQueue, nil
+	}
+
+	start := xtime.Now().Truncate(time.Hour)
+	end := start.Add(2 * time.Hour)
+
+	fetches := testFetches([]testFetch{
+		{"foo", []testValue{
+			{1.0, start.Add(1 * time.Second), xtime.Second, []byte{1, 2, 3}},
+			{2.0, start.Add(2 * time.Second), xtime.Second, nil},
+		}},
+	})
+
+	var (
+		firstAttemptHosts  []string
+		secondAttemptHosts []string
+		doneWg             sync.WaitGroup
+	)
+	doneWg.Add(1)
+	go func() {
+		defer doneWg.Done()
+
+		// The first attempt only reads from the zone local replicas, fail
+		// them to force a retry that reads across zones.
+		for i := 0; i < 2; i++ {
+			fetch := <-enqueued
+			firstAttemptHosts = append(firstAttemptHosts, fetch.host)
+			fetch.op.completionFns[0](nil, fmt.Errorf("random failure"))
+		}
+
+		var ops []*fetchBatchOp
+		for i := 0; i < sessionTestReplicas; i++ {
+			fetch := <-enqueued
+			secondAttemptHosts = append(secondAttemptHosts, fetch.host)
+			ops = append(ops, fetch.op)
+		}
+		fulfillFetchBatchOps(t, testOpts, fetches, ops, 0)
+	}()
+
+	require.NoError(t, session.Open())
+
+	results, err := session.FetchIDs(testOpts.nsID, fetches.IDsIter(), start, end)
+	require.NoError(t, err)
+	doneWg.Wait()
+	assertFetchResults(t, start, end, fetches, results, nil)
+
+	sort.Strings(firstAttemptHosts)
+	sort.Strings(secondAttemptHosts)
+	assert.Equal(t, []string{testHostName(1), testHostName(2)}, firstAttemptHosts)
+	assert.Equal(t, []string{testHostName(0), testHostName(1), testHostName(2)},
+		secondAttemptHosts)
+
+	counters := scope.Snapshot().Counters()
+	require.NotNil(t, counters["fetch.zone-local+"])
+	assert.Equal(t, int64(1), counters["fetch.zone-local+"].Value())
+	require.NotNil(t, counters["fetch.zone-local-read-bytes+"])
+	assert.True(t, counters["fetch.zone-local-read-bytes+"].Value() > 0)
+	require.NotNil(t, counters["fetch.cross-zone-read-bytes+"])
+	assert.True(t, counters["fetch.cross-zone-read-bytes+"].Value() > 0)
+
+	require.NoError(t, session.Close())
+}
+
 func prepareTestFetchEnqueuesWithErrors(
 	t *testing.T,
 	ctrl *gomock.Controller,
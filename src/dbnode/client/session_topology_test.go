@@ -21,6 +21,7 @@
 package client
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/m3db/m3/src/cluster/services"
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/dbnode/integration/fake"
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
 	"github.com/m3db/m3/src/dbnode/topology"
 
 	"github.com/golang/mock/gomock"
@@ -167,3 +169,90 @@ func TestSessionTopologyChangeCreatesNewClosesOldHostQueues(t *testing.T) {
 	require.Equal(t, 1, len(createdQueues.get("testhost2")))
 	require.Equal(t, 1, len(closedQueues.get("testhost0")))
 }
+
+func TestSessionWriteReroutedOnShardNotOwned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	avail := shard.Available
+
+	node := func(id string, shards []uint32) services.ServiceInstance {
+		result := services.NewServiceInstance().SetInstanceID(id)
+		resultShards := make([]shard.Shard, len(shards))
+		for i, id := range shards {
+			resultShards[i] = shard.NewShard(id).SetState(avail)
+		}
+		return result.SetShards(shard.NewShards(resultShards))
+	}
+
+	svc := fake.NewM3ClusterService().
+		SetInstances([]services.ServiceInstance{
+			node("testhost0", []uint32{0, 1, 2, 3}),
+		}).
+		SetReplication(services.NewServiceReplication().SetReplicas(1)).
+		SetSharding(services.NewServiceSharding().SetNumShards(4))
+
+	svcs := fake.NewM3ClusterServices()
+	svcs.RegisterService("m3db", svc)
+
+	topoOpts := topology.NewDynamicOptions().
+		SetConfigServiceClient(fake.NewM3ClusterClient(svcs, nil))
+	topoInit := topology.NewDynamicInitializer(topoOpts)
+
+	var testScopeTags map[string]string
+	scope := tally.NewTestScope("", testScopeTags)
+
+	opts := newSessionTestOptions().
+		SetTopologyInitializer(topoInit)
+	opts = opts.SetInstrumentOptions(opts.InstrumentOptions().
+		SetMetricsScope(scope))
+
+	s, err := newSession(opts)
+	require.NoError(t, err)
+
+	session := s.(*session)
+	session.newHostQueueFn = func(
+		host topology.Host,
+		opts hostQueueOpts,
+	) (hostQueue, error) {
+		queue := NewMockhostQueue(ctrl)
+		queue.EXPECT().Open()
+		queue.EXPECT().Host().Return(host).AnyTimes()
+		queue.EXPECT().ConnectionCount().Return(opts.opts.MinConnectionCount()).AnyTimes()
+		queue.EXPECT().Close().AnyTimes()
+
+		switch host.ID() {
+		case "testhost0":
+			// Move the shards to another instance while the write is in flight.
+			queue.EXPECT().Enqueue(gomock.Any()).DoAndReturn(func(op op) error {
+				svc.SetInstances([]services.ServiceInstance{
+					node("testhost1", []uint32{0, 1, 2, 3}),
+				})
+				svcs.NotifyServiceUpdate("m3db")
+
+				notOwnedErr := tterrors.NewShardNotOwnedError(errors.New("not responsible for shard"))
+				go op.CompletionFn()(host, notOwnedErr)
+				return nil
+			})
+		case "testhost1":
+			queue.EXPECT().Enqueue(gomock.Any()).DoAndReturn(func(op op) error {
+				go op.CompletionFn()(host, nil)
+				return nil
+			})
+		}
+		return queue, nil
+	}
+
+	require.NoError(t, session.Open())
+	defer func() {
+		assert.NoError(t, session.Close())
+	}()
+
+	// The write is re-routed to the new owner without being retried.
+	w := newWriteStub()
+	require.NoError(t, session.Write(w.ns, w.id, w.t, w.value, w.unit, w.annotation))
+
+	rerouted, ok := scope.Snapshot().Counters()[tally.KeyForPrefixedStringMap("write.rerouted", testScopeTags)]
+	require.True(t, ok)
+	require.Equal(t, int64(1), rerouted.Value())
+}
@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/topology"
 	xmetrics "github.com/m3db/m3/src/dbnode/x/metrics"
 	"github.com/m3db/m3/src/x/checked"
@@ -45,6 +46,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
+	"github.com/uber/tchannel-go/thrift"
 )
 
 func TestSessionWriteTaggedNotOpenError(t *testing.T) {
@@ -161,6 +163,142 @@ func TestSessionWriteTagged(t *testing.T) {
 	assert.NoError(t, session.Close())
 }
 
+func TestSessionWriteTaggedShardID(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	w := newWriteTaggedStub()
+	opts := newSessionTestOptions().SetWriteShardIDEnabled(true)
+	session := newTestSession(t, opts).(*session)
+	mockEncoder := serialize.NewMockTagEncoder(ctrl)
+	mockEncoderPool := serialize.NewMockTagEncoderPool(ctrl)
+	session.pools.tagEncoder = mockEncoderPool
+
+	gomock.InOrder(
+		mockEncoderPool.EXPECT().Get().Return(mockEncoder),
+		mockEncoder.EXPECT().Encode(gomock.Any()).Return(nil),
+		mockEncoder.EXPECT().Data().Return(testEncodeTags(w.tags), true),
+		mockEncoder.EXPECT().Finalize(),
+	)
+
+	var completionFn completionFn
+	enqueueWg := mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{func(idx int, op op) {
+		completionFn = op.CompletionFn()
+		write, ok := op.(*writeTaggedOperation)
+		require.True(t, ok)
+		require.True(t, write.request.IsSetShardID())
+		require.True(t, write.requestV2.IsSetShardID())
+		assert.Equal(t, int64(write.shardID), write.request.GetShardID())
+		assert.Equal(t, int64(write.shardID), write.requestV2.GetShardID())
+	}})
+
+	require.NoError(t, session.Open())
+
+	var resultErr error
+	var writeWg sync.WaitGroup
+	writeWg.Add(1)
+	go func() {
+		resultErr = session.WriteTagged(w.ns, w.id, ident.NewTagsIterator(w.tags),
+			w.t, w.value, w.unit, w.annotation)
+		writeWg.Done()
+	}()
+
+	enqueueWg.Wait()
+	for i := 0; i < session.state.topoMap.Replicas(); i++ {
+		completionFn(session.state.topoMap.Hosts()[0], nil)
+	}
+
+	writeWg.Wait()
+	require.NoError(t, resultErr)
+	require.NoError(t, session.Close())
+}
+
+func TestSessionWriteTaggedWaitForIndex(t *testing.T) {
+	tests := []struct {
+		name         string
+		waitForIndex bool
+		useV2        bool
+	}{
+		{name: "default"},
+		{name: "default v2", useV2: true},
+		{name: "wait for index", waitForIndex: true},
+		{name: "wait for index v2", waitForIndex: true, useV2: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := xtest.NewController(t)
+			defer ctrl.Finish()
+
+			var (
+				headersLock sync.Mutex
+				headers     []map[string]string
+			)
+			recordHeaders := func(ctx thrift.Context) {
+				headersLock.Lock()
+				headers = append(headers, ctx.Headers())
+				headersLock.Unlock()
+			}
+
+			// Override the new connection function for connection pools
+			// to be able to mock the entire end to end pipeline.
+			healthCheckResult := &rpc.NodeHealthResult_{Ok: true, Status: "ok", Bootstrapped: true}
+			newConnFn := func(
+				_ string, addr string, _ Options,
+			) (Channel, rpc.TChanNode, error) {
+				mockClient := rpc.NewMockTChanNode(ctrl)
+				mockClient.EXPECT().Health(gomock.Any()).
+					Return(healthCheckResult, nil).
+					AnyTimes()
+				mockClient.EXPECT().WriteTaggedBatchRaw(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx thrift.Context, _ *rpc.WriteTaggedBatchRawRequest) error {
+						recordHeaders(ctx)
+						return nil
+					}).
+					AnyTimes()
+				mockClient.EXPECT().WriteTaggedBatchRawV2(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx thrift.Context, _ *rpc.WriteTaggedBatchRawV2Request) error {
+						recordHeaders(ctx)
+						return nil
+					}).
+					AnyTimes()
+				return &noopPooledChannel{}, mockClient, nil
+			}
+
+			opts := newSessionTestOptions().
+				SetNewConnectionFn(newConnFn).
+				SetUseV2BatchAPIs(tt.useV2).
+				SetWriteConsistencyLevel(topology.ConsistencyLevelAll)
+			if tt.waitForIndex {
+				opts = opts.SetWriteWaitForIndex(true)
+			}
+
+			s, err := newSession(opts)
+			require.NoError(t, err)
+			session := s.(*session)
+			require.NoError(t, session.Open())
+
+			w := newWriteTaggedStub()
+			require.NoError(t, session.WriteTagged(w.ns, w.id, ident.NewTagsIterator(w.tags),
+				w.t, w.value, w.unit, w.annotation))
+			require.NoError(t, session.Close())
+
+			headersLock.Lock()
+			defer headersLock.Unlock()
+			require.Equal(t, sessionTestReplicas, len(headers))
+			for _, h := range headers {
+				value, ok := h[tchannelthrift.WaitForIndexHeader]
+				if !tt.waitForIndex {
+					assert.False(t, ok)
+					continue
+				}
+				assert.True(t, ok)
+				assert.Equal(t, "true", value)
+			}
+		})
+	}
+}
+
 func TestSessionWriteTaggedDoesNotCloneNoFinalize(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
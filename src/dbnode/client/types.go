@@ -28,11 +28,13 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
@@ -194,6 +196,11 @@ type TaggedIDsIterator interface {
 	// These remain valid until Next() is called again.
 	Current() (namespaceID ident.ID, seriesID ident.ID, tags ident.TagIterator)
 
+	// LatestDatapoint returns the most recent datapoint of the current series within the
+	// query range, ok is false unless the query set FetchLatestDatapoint and the series
+	// has data in the range. These remain valid until Next() is called again.
+	LatestDatapoint() (dp ts.Datapoint, annotation ts.Annotation, ok bool)
+
 	// Err returns any error encountered.
 	Err() error
 
@@ -545,6 +552,40 @@ type Options interface {
 	// that are leaving or not towards consistency level calculations.
 	ShardsLeavingCountTowardsConsistency() bool
 
+	// SetReadRepairReporter sets the reporter notified of blocks that replicas
+	// returned differing data for when reading at consistency level all.
+	SetReadRepairReporter(value ReadRepairReporter) Options
+
+	// ReadRepairReporter returns the reporter notified of blocks that replicas
+	// returned differing data for when reading at consistency level all.
+	ReadRepairReporter() ReadRepairReporter
+
+	// SetReadZone sets the zone the client resides in, when set fetches prefer
+	// replicas in this zone and only read from other zones on failure.
+	SetReadZone(value string) Options
+
+	// ReadZone returns the zone the client resides in, when set fetches prefer
+	// replicas in this zone and only read from other zones on failure.
+	ReadZone() string
+
+	// SetWriteFanoutOrdered sets whether writes are enqueued to replicas in the
+	// zone set with SetReadZone first, then to the healthiest replicas.
+	SetWriteFanoutOrdered(value bool) Options
+
+	// WriteFanoutOrdered returns whether writes are enqueued to replicas in the
+	// zone set with SetReadZone first, then to the healthiest replicas.
+	WriteFanoutOrdered() bool
+
+	// SetWriteZoneLocalAckRequired sets whether a write must be acknowledged by
+	// at least one replica in the zone set with SetReadZone, in addition to
+	// meeting the write consistency level, when such a replica owns the shard.
+	SetWriteZoneLocalAckRequired(value bool) Options
+
+	// WriteZoneLocalAckRequired returns whether a write must be acknowledged by
+	// at least one replica in the zone set with SetReadZone, in addition to
+	// meeting the write consistency level, when such a replica owns the shard.
+	WriteZoneLocalAckRequired() bool
+
 	// SetTagEncoderOptions sets the TagEncoderOptions.
 	SetTagEncoderOptions(value serialize.TagEncoderOptions) Options
 
@@ -701,6 +742,32 @@ type Options interface {
 	// UseV2BatchAPIs returns whether the V2 batch APIs should be used.
 	UseV2BatchAPIs() bool
 
+	// SetWriteShardIDEnabled sets whether tagged writes send the shard of the
+	// series ID so that M3DB nodes can skip hashing the series ID.
+	SetWriteShardIDEnabled(value bool) Options
+
+	// WriteShardIDEnabled returns whether tagged writes send the shard of the
+	// series ID so that M3DB nodes can skip hashing the series ID.
+	WriteShardIDEnabled() bool
+
+	// SetWriteWaitForIndex sets whether tagged writes ask M3DB nodes to only
+	// acknowledge the write once it is queryable from the index.
+	SetWriteWaitForIndex(value bool) Options
+
+	// WriteWaitForIndex returns whether tagged writes ask M3DB nodes to only
+	// acknowledge the write once it is queryable from the index.
+	WriteWaitForIndex() bool
+
+	// SetFetchChecksumVerificationEnabled sets whether the checksums of the
+	// segments returned by fetches are verified, a replica that returns a
+	// segment that does not match its checksum is treated as having failed
+	// the fetch so that the results are served by the other replicas.
+	SetFetchChecksumVerificationEnabled(value bool) Options
+
+	// FetchChecksumVerificationEnabled returns whether the checksums of the
+	// segments returned by fetches are verified.
+	FetchChecksumVerificationEnabled() bool
+
 	// SetIterationOptions sets experimental iteration options.
 	SetIterationOptions(index.IterationOptions) Options
 
@@ -787,6 +854,14 @@ type AdminOptions interface {
 
 	// StreamBlocksRetrier returns the retrier for streaming blocks.
 	StreamBlocksRetrier() xretry.Retrier
+
+	// SetPeerStreamCodecs sets the codecs accepted for blocks streamed from
+	// peers in order of preference, if empty streams are not compressed.
+	SetPeerStreamCodecs(value []peerstream.Codec) AdminOptions
+
+	// PeerStreamCodecs returns the codecs accepted for blocks streamed from
+	// peers in order of preference.
+	PeerStreamCodecs() []peerstream.Codec
 }
 
 // The rest of these types are internal types that mocks are generated for
@@ -67,8 +67,9 @@ func (w *writeAttempt) perform() error {
 		w.args.namespace, w.args.id, w.args.tags, w.args.t,
 		w.args.value, w.args.unit, w.args.annotation)
 
-	if IsBadRequestError(err) {
-		// Do not retry bad request errors
+	if err != nil && !IsRetryableError(err) {
+		// Do not retry errors the server classified as not retryable,
+		// e.g. bad request or resource exhausted errors.
 		err = xerrors.NewNonRetryableError(err)
 	}
 
@@ -63,9 +63,18 @@ type writeState struct {
 	annotation                           checked.Bytes
 	majority, pending                    int32
 	success                              int32
+	shardNotOwned                        int32
 	errors                               []error
+	successHosts                         []string
+	consistencyAchieved                  bool
 	lastResetTime                        time.Time
 
+	// zoneLocalAckRequired is set when a write must succeed on at least one
+	// replica in the zone, if the shard has any replicas in the zone.
+	zone                                string
+	zoneLocalAckRequired                bool
+	zoneLocalEnqueued, zoneLocalSuccess int32
+
 	queues         []hostQueue
 	tagEncoderPool serialize.TagEncoderPool
 	pool           *writeStatePool
@@ -100,6 +109,7 @@ func (w *writeState) close() {
 	}
 
 	w.op, w.majority, w.pending, w.success = nil, 0, 0, 0
+	w.shardNotOwned = 0
 	w.nsID, w.tsID, w.tagEncoder, w.annotation = nil, nil, nil, nil
 
 	for i := range w.errors {
@@ -107,8 +117,17 @@ func (w *writeState) close() {
 	}
 	w.errors = w.errors[:0]
 
+	for i := range w.successHosts {
+		w.successHosts[i] = ""
+	}
+	w.successHosts = w.successHosts[:0]
+	w.consistencyAchieved = false
+
 	w.lastResetTime = time.Time{}
 
+	w.zone, w.zoneLocalAckRequired = "", false
+	w.zoneLocalEnqueued, w.zoneLocalSuccess = 0, 0
+
 	for i := range w.queues {
 		w.queues[i] = nil
 	}
@@ -141,6 +160,10 @@ func (w *writeState) completionFn(result interface{}, err error) {
 			// not retried.
 			err = xerrors.NewInvalidParamsError(err)
 			err = xerrors.NewNonRetryableError(err)
+		} else if IsShardNotOwnedError(err) {
+			// The shard moved while the write was in flight, the session
+			// re-routes the write once it receives the updated topology.
+			w.shardNotOwned++
 		}
 
 		w.pool.MaybeLogHostError(maybeHostWriteError{err: err, host: host, reqRespTime: took})
@@ -173,6 +196,10 @@ func (w *writeState) completionFn(result interface{}, err error) {
 			wErr = xerrors.NewRetryableError(fmt.Errorf(errStr, w.op.ShardID(), hostID))
 		} else {
 			w.success++
+			w.successHosts = append(w.successHosts, hostID)
+			if w.zoneLocalAckRequired && host.Zone() == w.zone {
+				w.zoneLocalSuccess++
+			}
 		}
 	}
 
@@ -180,13 +207,22 @@ func (w *writeState) completionFn(result interface{}, err error) {
 		w.errors = append(w.errors, wErr)
 	}
 
+	if !w.consistencyAchieved && wErr == nil {
+		numPeers := int(w.success+w.pending) + len(w.errors)
+		if topology.WriteConsistencyAchieved(w.consistencyLevel, int(w.majority),
+			numPeers, int(w.success)) && w.zoneLocalAckSatisfied() {
+			w.consistencyAchieved = true
+			w.pool.LogConsistencyAchieved(w.consistencyLevel, w.successHosts)
+		}
+	}
+
 	switch w.consistencyLevel {
 	case topology.ConsistencyLevelOne:
-		if w.success > 0 || w.pending == 0 {
+		if (w.success > 0 && w.zoneLocalAckSatisfied()) || w.pending == 0 {
 			w.Signal()
 		}
 	case topology.ConsistencyLevelMajority:
-		if w.success >= w.majority || w.pending == 0 {
+		if (w.success >= w.majority && w.zoneLocalAckSatisfied()) || w.pending == 0 {
 			w.Signal()
 		}
 	case topology.ConsistencyLevelAll:
@@ -199,6 +235,12 @@ func (w *writeState) completionFn(result interface{}, err error) {
 	w.decRef()
 }
 
+// zoneLocalAckSatisfied returns whether the write has the zone local ack it
+// requires, a write to a shard with no replicas in the zone never requires one.
+func (w *writeState) zoneLocalAckSatisfied() bool {
+	return !w.zoneLocalAckRequired || w.zoneLocalEnqueued == 0 || w.zoneLocalSuccess > 0
+}
+
 type writeStatePool struct {
 	pool                pool.ObjectPool
 	tagEncoderPool      serialize.TagEncoderPool
@@ -252,6 +294,20 @@ func (p *writeStatePool) MaybeLogHostError(hostErr maybeHostWriteError) {
 		zap.Error(hostErr.err))
 }
 
+// LogConsistencyAchieved logs the set of hosts whose successful writes
+// achieved the write consistency level.
+func (p *writeStatePool) LogConsistencyAchieved(
+	level topology.ConsistencyLevel,
+	hostIDs []string,
+) {
+	if p == nil || p.logger == nil {
+		return
+	}
+	if ce := p.logger.Check(zap.DebugLevel, "write consistency level achieved"); ce != nil {
+		ce.Write(zap.Stringer("consistencyLevel", level), zap.Strings("hosts", hostIDs))
+	}
+}
+
 type maybeHostWriteError struct {
 	// Note: both these fields should be set always.
 	host        topology.Host
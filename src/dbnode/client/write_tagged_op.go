@@ -34,13 +34,16 @@ var (
 )
 
 type writeTaggedOperation struct {
-	namespace    ident.ID
-	shardID      uint32
-	request      rpc.WriteTaggedBatchRawRequestElement
-	requestV2    rpc.WriteTaggedBatchRawV2RequestElement
-	datapoint    rpc.Datapoint
-	completionFn completionFn
-	pool         *writeTaggedOperationPool
+	namespace ident.ID
+	shardID   uint32
+	// requestShardID backs the shard ID sent with the request since the
+	// generated request types take a pointer to the value.
+	requestShardID int64
+	request        rpc.WriteTaggedBatchRawRequestElement
+	requestV2      rpc.WriteTaggedBatchRawV2RequestElement
+	datapoint      rpc.Datapoint
+	completionFn   completionFn
+	pool           *writeTaggedOperationPool
 }
 
 func (w *writeTaggedOperation) reset() {
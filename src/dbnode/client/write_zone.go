@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/m3db/m3/src/dbnode/topology"
+)
+
+// zoneLocalAckConsistencyLevel is a write consistency level that in addition
+// requires at least one successful write to a replica in the zone.
+type zoneLocalAckConsistencyLevel struct {
+	level topology.ConsistencyLevel
+	zone  string
+}
+
+func (l zoneLocalAckConsistencyLevel) String() string {
+	return fmt.Sprintf("%s with zone local ack in %s", l.level.String(), l.zone)
+}
+
+// orderWriteFanout orders the host queues a write is enqueued to so that the
+// replicas in the zone of the client come first, followed by the replicas
+// with the lowest recent error rates. Replicas that are likely to succeed are
+// written to first so that consistency is achieved sooner when other replicas
+// are partitioned away.
+func (s *session) orderWriteFanout(queues []hostQueue) {
+	if len(queues) < 2 {
+		return
+	}
+
+	type rankedQueue struct {
+		queue     hostQueue
+		remote    bool
+		errorRate float64
+	}
+	ranked := make([]rankedQueue, 0, len(queues))
+	for _, q := range queues {
+		host := q.Host()
+		ranked = append(ranked, rankedQueue{
+			queue:     q,
+			remote:    s.readZone != "" && host.Zone() != s.readZone,
+			errorRate: s.hostMetrics.errorRate(host.ID()),
+		})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].remote != ranked[j].remote {
+			return !ranked[i].remote
+		}
+		return ranked[i].errorRate < ranked[j].errorRate
+	})
+	for i := range ranked {
+		queues[i] = ranked[i].queue
+	}
+}
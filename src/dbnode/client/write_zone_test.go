@@ -0,0 +1,208 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/topology"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestSessionWriteZoneLocalAck(t *testing.T) {
+	for _, required := range []bool{false, true} {
+		t.Run(fmt.Sprintf("required=%v", required), func(t *testing.T) {
+			testSessionWriteZoneLocalAck(t, required)
+		})
+	}
+}
+
+func testSessionWriteZoneLocalAck(t *testing.T, required bool) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Only the first replica is in the zone of the client, simulate a
+	// partition between the client and it while the remote replicas succeed.
+	shardSet := sessionTestShardSet()
+	var hostShardSets []topology.HostShardSet
+	for i := 0; i < sessionTestReplicas; i++ {
+		id := testHostName(i)
+		zone := "zone-b"
+		if i == 0 {
+			zone = "zone-a"
+		}
+		host := topology.NewHostWithZone(id, fmt.Sprintf("%s:9000", id), zone)
+		hostShardSets = append(hostShardSets, topology.NewHostShardSet(host, shardSet))
+	}
+
+	scope := tally.NewTestScope("", nil)
+	opts := newSessionTestOptions().
+		SetReadZone("zone-a").
+		SetWriteFanoutOrdered(true).
+		SetWriteZoneLocalAckRequired(required).
+		SetWriteConsistencyLevel(topology.ConsistencyLevelMajority).
+		SetTopologyInitializer(topology.NewStaticInitializer(
+			topology.NewStaticOptions().
+				SetReplicas(sessionTestReplicas).
+				SetShardSet(shardSet).
+				SetHostShardSets(hostShardSets)))
+	opts = opts.SetInstrumentOptions(opts.InstrumentOptions().
+		SetMetricsScope(scope))
+
+	s, err := newSession(opts)
+	require.NoError(t, err)
+	session := s.(*session)
+
+	type enqueuedWrite struct {
+		host topology.Host
+		op   op
+	}
+	enqueued := make(chan enqueuedWrite, sessionTestReplicas)
+	session.newHostQueueFn = func(
+		host topology.Host,
+		opts hostQueueOpts,
+	) (hostQueue, error) {
+		hostQueue := NewMockhostQueue(ctrl)
+		hostQueue.EXPECT().Open()
+		hostQueue.EXPECT().Host().Return(host).AnyTimes()
+		hostQueue.EXPECT().ConnectionCount().
+			Return(opts.opts.MinConnectionCount()).AnyTimes()
+		hostQueue.EXPECT().Enqueue(gomock.Any()).Do(func(op op) error {
+			enqueued <- enqueuedWrite{host: host, op: op}
+			return nil
+		}).Return(nil)
+		hostQueue.EXPECT().Close()
+		return hostQueue, nil
+	}
+
+	require.NoError(t, session.Open())
+
+	var (
+		w        = newWriteStub()
+		writeErr error
+		wg       sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		writeErr = session.Write(w.ns, w.id, w.t, w.value, w.unit, w.annotation)
+	}()
+
+	var hosts []string
+	for i := 0; i < sessionTestReplicas; i++ {
+		write := <-enqueued
+		hosts = append(hosts, write.host.ID())
+		if write.host.Zone() == "zone-a" {
+			write.op.CompletionFn()(write.host, errors.New("partitioned"))
+		} else {
+			write.op.CompletionFn()(write.host, nil)
+		}
+	}
+	wg.Wait()
+
+	// The zone local replica is always enqueued to first.
+	assert.Equal(t, testHostName(0), hosts[0])
+
+	missing := scope.Snapshot().Counters()["write.zone-local-ack-missing+"]
+	if required {
+		require.Error(t, writeErr)
+		assert.True(t, strings.Contains(writeErr.Error(),
+			"majority with zone local ack in zone-a"), writeErr.Error())
+		require.NotNil(t, missing)
+		assert.Equal(t, int64(1), missing.Value())
+	} else {
+		assert.NoError(t, writeErr)
+		if missing != nil {
+			assert.Equal(t, int64(0), missing.Value())
+		}
+	}
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionOrderWriteFanout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := xtime.Now().ToTime()
+	session := &session{
+		readZone:    "zone-a",
+		hostMetrics: newSessionHostMetrics(tally.NoopScope, func() time.Time { return now }),
+	}
+
+	newQueue := func(id, zone string, errorRate float64) hostQueue {
+		host := topology.NewHostWithZone(id, fmt.Sprintf("%s:9000", id), zone)
+		for i := 0; i < 10; i++ {
+			var err error
+			if float64(i) < errorRate*10 {
+				err = errors.New("write error")
+			}
+			session.hostMetrics.forHost(id).recordWrite(time.Millisecond, err)
+		}
+		queue := NewMockhostQueue(ctrl)
+		queue.EXPECT().Host().Return(host).AnyTimes()
+		return queue
+	}
+
+	queues := []hostQueue{
+		newQueue("remote-unhealthy", "zone-b", 0.5),
+		newQueue("local-unhealthy", "zone-a", 0.8),
+		newQueue("remote-healthy", "zone-b", 0),
+		newQueue("local-healthy", "zone-a", 0.1),
+	}
+	session.orderWriteFanout(queues)
+
+	var ids []string
+	for _, q := range queues {
+		ids = append(ids, q.Host().ID())
+	}
+	assert.Equal(t, []string{
+		"local-healthy",
+		"local-unhealthy",
+		"remote-healthy",
+		"remote-unhealthy",
+	}, ids)
+
+	// Without a zone replicas are ordered by error rate only.
+	session.readZone = ""
+	session.orderWriteFanout(queues)
+
+	ids = ids[:0]
+	for _, q := range queues {
+		ids = append(ids, q.Host().ID())
+	}
+	assert.Equal(t, []string{
+		"remote-healthy",
+		"local-healthy",
+		"remote-unhealthy",
+		"local-unhealthy",
+	}, ids)
+}
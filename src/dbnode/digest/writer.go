@@ -31,12 +31,19 @@ type FdWithDigestWriter interface {
 	FdWithDigest
 	io.Writer
 
+	// ResetWithWriter resets the file descriptor and the digest, with
+	// buffered writes going to the given writer rather than the file
+	// descriptor, e.g. to transform the contents before they reach the file.
+	// The writer is closed before the file descriptor upon close.
+	ResetWithWriter(fd *os.File, w io.WriteCloser)
+
 	Flush() error
 }
 
 type fdWithDigestWriter struct {
 	FdWithDigest
-	writer *bufio.Writer
+	writer   *bufio.Writer
+	wrapping io.WriteCloser
 }
 
 // NewFdWithDigestWriter creates a new FdWithDigestWriter.
@@ -50,6 +57,13 @@ func NewFdWithDigestWriter(bufferSize int) FdWithDigestWriter {
 func (w *fdWithDigestWriter) Reset(fd *os.File) {
 	w.FdWithDigest.Reset(fd)
 	w.writer.Reset(fd)
+	w.wrapping = nil
+}
+
+func (w *fdWithDigestWriter) ResetWithWriter(fd *os.File, wrapping io.WriteCloser) {
+	w.FdWithDigest.Reset(fd)
+	w.writer.Reset(wrapping)
+	w.wrapping = wrapping
 }
 
 // Write bytes to the underlying file.
@@ -70,6 +84,13 @@ func (w *fdWithDigestWriter) Close() error {
 	if err := w.writer.Flush(); err != nil {
 		return err
 	}
+	if w.wrapping != nil {
+		wrapping := w.wrapping
+		w.wrapping = nil
+		if err := wrapping.Close(); err != nil {
+			return err
+		}
+	}
 	return w.FdWithDigest.Close()
 }
 
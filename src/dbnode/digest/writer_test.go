@@ -22,6 +22,7 @@ package digest
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"io/ioutil"
 	"os"
@@ -108,6 +109,37 @@ func TestFdWithDigestWriterCloseSuccess(t *testing.T) {
 	require.Nil(t, writer.Fd())
 }
 
+type testWriteCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *testWriteCloser) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestFdWithDigestWriterResetWithWriter(t *testing.T) {
+	writer, fd, md := createTestFdWithDigestWriter(t)
+	defer func() {
+		fd.Close()
+		os.Remove(fd.Name())
+	}()
+
+	var wrapping testWriteCloser
+	writer.ResetWithWriter(fd, &wrapping)
+
+	data := []byte{0x1, 0x2, 0x3}
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.Equal(t, data, md.b)
+
+	require.NoError(t, writer.Close())
+	require.Equal(t, data, wrapping.Bytes())
+	require.True(t, wrapping.closed)
+	require.Nil(t, writer.Fd())
+}
+
 func TestFdWithDigestWriteDigestsError(t *testing.T) {
 	writer, fd, _ := createTestFdWithDigestContentsWriter(t)
 	defer func() {
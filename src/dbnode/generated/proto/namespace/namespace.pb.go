@@ -159,7 +159,8 @@ func (m *RetentionOptions) GetFutureRetentionPeriodNanos() int64 {
 
 type IndexOptions struct {
 	Enabled        bool  `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
-	BlockSizeNanos int64 `protobuf:"varint,2,opt,name=blockSizeNanos,proto3" json:"blockSizeNanos,omitempty"`
+	BlockSizeNanos int64    `protobuf:"varint,2,opt,name=blockSizeNanos,proto3" json:"blockSizeNanos,omitempty"`
+	ExcludedTags   []string `protobuf:"bytes,3,rep,name=excludedTags" json:"excludedTags,omitempty"`
 }
 
 func (m *IndexOptions) Reset()                    { *m = IndexOptions{} }
@@ -181,6 +182,13 @@ func (m *IndexOptions) GetBlockSizeNanos() int64 {
 	return 0
 }
 
+func (m *IndexOptions) GetExcludedTags() []string {
+	if m != nil {
+		return m.ExcludedTags
+	}
+	return nil
+}
+
 type NamespaceOptions struct {
 	BootstrapEnabled      bool                        `protobuf:"varint,1,opt,name=bootstrapEnabled,proto3" json:"bootstrapEnabled,omitempty"`
 	FlushEnabled          bool                        `protobuf:"varint,2,opt,name=flushEnabled,proto3" json:"flushEnabled,omitempty"`
@@ -442,6 +450,7 @@ func (m *Registry) GetNamespaces() map[string]*NamespaceOptions {
 type NamespaceRuntimeOptions struct {
 	WriteIndexingPerCPUConcurrency *google_protobuf1.DoubleValue `protobuf:"bytes,1,opt,name=writeIndexingPerCPUConcurrency" json:"writeIndexingPerCPUConcurrency,omitempty"`
 	FlushIndexingPerCPUConcurrency *google_protobuf1.DoubleValue `protobuf:"bytes,2,opt,name=flushIndexingPerCPUConcurrency" json:"flushIndexingPerCPUConcurrency,omitempty"`
+	IndexingPaused                 *google_protobuf1.BoolValue   `protobuf:"bytes,3,opt,name=indexingPaused" json:"indexingPaused,omitempty"`
 }
 
 func (m *NamespaceRuntimeOptions) Reset()                    { *m = NamespaceRuntimeOptions{} }
@@ -463,6 +472,13 @@ func (m *NamespaceRuntimeOptions) GetFlushIndexingPerCPUConcurrency() *google_pr
 	return nil
 }
 
+func (m *NamespaceRuntimeOptions) GetIndexingPaused() *google_protobuf1.BoolValue {
+	if m != nil {
+		return m.IndexingPaused
+	}
+	return nil
+}
+
 type ExtendedOptions struct {
 	Type    string                  `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
 	Options *google_protobuf.Struct `protobuf:"bytes,2,opt,name=options" json:"options,omitempty"`
@@ -589,6 +605,21 @@ func (m *IndexOptions) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintNamespace(dAtA, i, uint64(m.BlockSizeNanos))
 	}
+	if len(m.ExcludedTags) > 0 {
+		for _, s := range m.ExcludedTags {
+			dAtA[i] = 0x1a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
 	return i, nil
 }
 
@@ -995,6 +1026,16 @@ func (m *NamespaceRuntimeOptions) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n13
 	}
+	if m.IndexingPaused != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNamespace(dAtA, i, uint64(m.IndexingPaused.Size()))
+		n14, err := m.IndexingPaused.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n14
+	}
 	return i, nil
 }
 
@@ -1023,11 +1064,11 @@ func (m *ExtendedOptions) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintNamespace(dAtA, i, uint64(m.Options.Size()))
-		n14, err := m.Options.MarshalTo(dAtA[i:])
+		n15, err := m.Options.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n14
+		i += n15
 	}
 	return i, nil
 }
@@ -1077,6 +1118,12 @@ func (m *IndexOptions) Size() (n int) {
 	if m.BlockSizeNanos != 0 {
 		n += 1 + sovNamespace(uint64(m.BlockSizeNanos))
 	}
+	if len(m.ExcludedTags) > 0 {
+		for _, s := range m.ExcludedTags {
+			l = len(s)
+			n += 1 + l + sovNamespace(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1225,6 +1272,10 @@ func (m *NamespaceRuntimeOptions) Size() (n int) {
 		l = m.FlushIndexingPerCPUConcurrency.Size()
 		n += 1 + l + sovNamespace(uint64(l))
 	}
+	if m.IndexingPaused != nil {
+		l = m.IndexingPaused.Size()
+		n += 1 + l + sovNamespace(uint64(l))
+	}
 	return n
 }
 
@@ -1507,6 +1558,35 @@ func (m *IndexOptions) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExcludedTags", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNamespace
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthNamespace
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExcludedTags = append(m.ExcludedTags, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipNamespace(dAtA[iNdEx:])
@@ -2675,6 +2755,39 @@ func (m *NamespaceRuntimeOptions) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IndexingPaused", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNamespace
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNamespace
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.IndexingPaused == nil {
+				m.IndexingPaused = &google_protobuf1.BoolValue{}
+			}
+			if err := m.IndexingPaused.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipNamespace(dAtA[iNdEx:])
@@ -2918,68 +3031,70 @@ func init() {
 }
 
 var fileDescriptorNamespace = []byte{
-	// 1001 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x96, 0xdd, 0x6e, 0x1b, 0x45,
-	0x14, 0x80, 0xbb, 0xf9, 0x73, 0x72, 0xec, 0x24, 0xce, 0xa8, 0x10, 0x2b, 0x2d, 0xa6, 0x5a, 0x7e,
-	0x14, 0x55, 0xc8, 0xa6, 0xc9, 0x0d, 0x14, 0xa9, 0xe0, 0x24, 0x26, 0x72, 0x29, 0x8e, 0x35, 0x69,
-	0x29, 0xe4, 0x6e, 0x76, 0xf7, 0x78, 0xb3, 0xea, 0x7a, 0x66, 0x35, 0x33, 0xdb, 0x24, 0x3c, 0x43,
-	0x2f, 0x78, 0x0f, 0x5e, 0x84, 0x4b, 0x1e, 0x01, 0x05, 0x21, 0x71, 0xc3, 0x3b, 0xa0, 0x9d, 0xf5,
-	0x3a, 0xfb, 0xe3, 0x96, 0x88, 0x9b, 0x68, 0x72, 0xce, 0x77, 0x7e, 0xe6, 0xfc, 0xcc, 0x1a, 0x8e,
-	0xfd, 0x40, 0x9f, 0xc7, 0x4e, 0xc7, 0x15, 0x93, 0xee, 0x64, 0xdf, 0x73, 0xba, 0x93, 0xfd, 0xae,
-	0x92, 0x6e, 0xd7, 0x73, 0xb8, 0xf0, 0xb0, 0xeb, 0x23, 0x47, 0xc9, 0x34, 0x7a, 0xdd, 0x48, 0x0a,
-	0x2d, 0xba, 0x9c, 0x4d, 0x50, 0x45, 0xcc, 0xc5, 0x9b, 0x53, 0xc7, 0x68, 0xc8, 0xda, 0x4c, 0xb0,
-	0x73, 0xdf, 0x17, 0xc2, 0x0f, 0x31, 0x35, 0x71, 0xe2, 0x71, 0x57, 0x69, 0x19, 0xbb, 0x3a, 0x05,
-	0x77, 0xda, 0x65, 0xed, 0x85, 0x64, 0x51, 0x84, 0x52, 0x4d, 0xf5, 0x47, 0xff, 0x37, 0x23, 0xe5,
-	0x9e, 0xe3, 0x84, 0xa5, 0x5e, 0xec, 0x37, 0x8b, 0xd0, 0xa4, 0xa8, 0x91, 0xeb, 0x40, 0xf0, 0x93,
-	0x28, 0xf9, 0xab, 0xc8, 0x1e, 0xdc, 0x95, 0x99, 0x6c, 0x84, 0x32, 0x10, 0xde, 0x90, 0x71, 0xa1,
-	0x5a, 0xd6, 0x03, 0x6b, 0x77, 0x91, 0xce, 0xd5, 0x91, 0x4f, 0x61, 0xc3, 0x09, 0x85, 0xfb, 0xea,
-	0x34, 0xf8, 0x19, 0x53, 0x7a, 0xc1, 0xd0, 0x25, 0x29, 0xf9, 0x0c, 0xb6, 0x9c, 0x78, 0x3c, 0x46,
-	0xf9, 0x6d, 0xac, 0x63, 0x39, 0x45, 0x17, 0x0d, 0x5a, 0x55, 0x90, 0x5d, 0xd8, 0x4c, 0x85, 0x23,
-	0xa6, 0x74, 0xca, 0x2e, 0x19, 0xb6, 0x2c, 0x36, 0x64, 0x12, 0xe9, 0x88, 0x69, 0xd6, 0xbf, 0x8c,
-	0x02, 0x79, 0xd5, 0x5a, 0x7e, 0x60, 0xed, 0xae, 0xd2, 0xb2, 0x98, 0x9c, 0xc1, 0x6e, 0x49, 0xd4,
-	0x1b, 0x6b, 0x94, 0x43, 0xa1, 0x7b, 0xae, 0x8b, 0x4a, 0xe5, 0x6f, 0xbc, 0x62, 0x82, 0xdd, 0x9a,
-	0x27, 0x4f, 0x60, 0x67, 0x6c, 0xd2, 0xa7, 0xf3, 0xea, 0x57, 0x33, 0xde, 0xde, 0x41, 0xd8, 0x23,
-	0x68, 0x0c, 0xb8, 0x87, 0x97, 0x59, 0x27, 0x5a, 0x50, 0x43, 0xce, 0x9c, 0x10, 0x3d, 0x53, 0xfc,
-	0x55, 0x9a, 0xfd, 0x7b, 0xdb, 0x7a, 0xdb, 0xff, 0xac, 0x40, 0x73, 0x98, 0xf5, 0x3e, 0x73, 0xfb,
-	0x10, 0x9a, 0x8e, 0x10, 0x5a, 0x69, 0xc9, 0xa2, 0x7e, 0xc1, 0x7f, 0x45, 0x4e, 0x6c, 0x68, 0x8c,
-	0xc3, 0x58, 0x9d, 0x67, 0xdc, 0x82, 0xe1, 0x0a, 0xb2, 0xa4, 0xa9, 0x17, 0x32, 0xd0, 0xa8, 0x9e,
-	0x8b, 0x43, 0x31, 0x99, 0x04, 0xfa, 0x99, 0xf0, 0x4d, 0x53, 0x57, 0x69, 0x55, 0x91, 0xa4, 0xee,
-	0x86, 0xc8, 0x78, 0x3c, 0x8b, 0xbd, 0x64, 0xd0, 0x92, 0x94, 0x7c, 0x0c, 0xeb, 0x12, 0x23, 0x16,
-	0xc8, 0x0c, 0x4b, 0x1b, 0x5a, 0x14, 0x92, 0x63, 0x68, 0xca, 0xd2, 0x00, 0x9b, 0xb6, 0xd5, 0xf7,
-	0xee, 0x75, 0x6e, 0x96, 0xaf, 0x3c, 0xe3, 0xb4, 0x62, 0x94, 0x4c, 0x90, 0xe2, 0x2c, 0x52, 0xe7,
-	0x42, 0x67, 0x01, 0x6b, 0xe9, 0x04, 0x95, 0xc4, 0xe4, 0x2b, 0x68, 0x04, 0xb9, 0x2e, 0xb5, 0x56,
-	0x4d, 0xb8, 0xed, 0x5c, 0xb8, 0x7c, 0x13, 0x69, 0x01, 0x26, 0x4f, 0x60, 0x3d, 0xdd, 0xc0, 0xcc,
-	0x7a, 0xcd, 0x58, 0xb7, 0x72, 0xd6, 0xa7, 0x79, 0x3d, 0x2d, 0xe2, 0x49, 0xad, 0x5d, 0x11, 0x7a,
-	0x2f, 0x4d, 0x59, 0xb3, 0x44, 0x21, 0xad, 0x75, 0x45, 0x41, 0x9e, 0xc2, 0x86, 0x8c, 0xb9, 0x0e,
-	0x26, 0x59, 0xef, 0x5b, 0x75, 0x13, 0xce, 0xce, 0x85, 0x9b, 0x8d, 0x07, 0x2d, 0x90, 0xb4, 0x64,
-	0x49, 0x46, 0xf0, 0x9e, 0xcb, 0xdc, 0x73, 0x3c, 0x48, 0x26, 0x4c, 0x9d, 0x70, 0x8a, 0x5a, 0x06,
-	0xf8, 0x1a, 0x5b, 0x0d, 0xe3, 0x72, 0xa7, 0x93, 0xbe, 0x58, 0x9d, 0xec, 0xc5, 0xea, 0x1c, 0x08,
-	0x11, 0xfe, 0xc0, 0xc2, 0x18, 0xe9, 0x7c, 0x43, 0xf2, 0x3d, 0x10, 0xe6, 0xfb, 0x12, 0x7d, 0x96,
-	0xef, 0xde, 0xba, 0x71, 0xf7, 0x41, 0x2e, 0xc3, 0x5e, 0x05, 0xa2, 0x73, 0x0c, 0x93, 0xbe, 0x28,
-	0xcd, 0xfc, 0x80, 0xfb, 0xa7, 0x9a, 0x69, 0x6c, 0x6d, 0x54, 0xfa, 0x72, 0x9a, 0x53, 0xd3, 0x02,
-	0x4c, 0xfa, 0xb0, 0x89, 0x97, 0x1a, 0xb9, 0x87, 0x5e, 0x96, 0xc8, 0xdf, 0xb5, 0xe9, 0xc5, 0x6e,
-	0x1c, 0xf4, 0x8b, 0x08, 0x2d, 0xdb, 0xd8, 0x23, 0x20, 0xd5, 0x6c, 0xc9, 0x63, 0x68, 0xe4, 0xf2,
-	0x4d, 0x5e, 0xd2, 0xc5, 0xdd, 0xfa, 0xde, 0xfb, 0xf3, 0xaf, 0x48, 0x0b, 0xac, 0xcd, 0xa1, 0x9e,
-	0x53, 0x92, 0x36, 0x40, 0xa6, 0x9e, 0x6d, 0x6d, 0x4e, 0x42, 0xbe, 0x06, 0x60, 0x5a, 0xcb, 0xc0,
-	0x89, 0x35, 0xa6, 0x8f, 0x42, 0x7d, 0xef, 0xc3, 0x39, 0x81, 0xd0, 0xeb, 0xcd, 0x30, 0x9a, 0x33,
-	0xb1, 0xdf, 0x58, 0x70, 0x77, 0x1e, 0x94, 0x2c, 0x88, 0x44, 0x25, 0xc2, 0x38, 0xc9, 0x23, 0xff,
-	0x45, 0x28, 0x8b, 0xc9, 0x53, 0xd8, 0xf2, 0xc4, 0x05, 0x57, 0x6c, 0x12, 0x85, 0xb3, 0xc1, 0x4b,
-	0x53, 0xb9, 0x9f, 0x4b, 0xe5, 0xa8, 0xcc, 0xd0, 0xaa, 0x99, 0xfd, 0x09, 0x6c, 0x55, 0x38, 0xd2,
-	0x84, 0x45, 0x16, 0x86, 0xd3, 0xdb, 0x27, 0x47, 0xfb, 0x1b, 0x68, 0xe4, 0x9b, 0x4b, 0x3e, 0x87,
-	0x15, 0xa5, 0x99, 0x8e, 0xd3, 0x1c, 0x37, 0x8a, 0xfb, 0x75, 0x03, 0xc6, 0x8a, 0x4e, 0x39, 0xfb,
-	0x57, 0x0b, 0x56, 0x29, 0xfa, 0x81, 0xd2, 0xf2, 0x8a, 0x1c, 0x02, 0xcc, 0xf8, 0xac, 0x5d, 0x1f,
-	0x15, 0xde, 0x93, 0x14, 0xbc, 0x59, 0x1e, 0xd5, 0xe7, 0x5a, 0x5e, 0xd1, 0x9c, 0xd9, 0xce, 0x19,
-	0x6c, 0x96, 0xd4, 0x49, 0xe2, 0xaf, 0xf0, 0xca, 0xe4, 0xb4, 0x46, 0x93, 0x23, 0x79, 0x04, 0xcb,
-	0xaf, 0x93, 0x1d, 0x99, 0xd6, 0xe7, 0xde, 0xbc, 0xc5, 0xcc, 0xca, 0x93, 0x92, 0x8f, 0x17, 0xbe,
-	0xb0, 0xec, 0xbf, 0x2c, 0xd8, 0x7e, 0xcb, 0xe2, 0x12, 0x0f, 0xda, 0xe6, 0xd5, 0x35, 0xaf, 0x50,
-	0xc0, 0xfd, 0x11, 0xca, 0xc3, 0xd1, 0x8b, 0x43, 0xc1, 0xdd, 0x58, 0x4a, 0xe4, 0x6e, 0x1a, 0x3f,
-	0xe9, 0x45, 0x79, 0x63, 0x8f, 0x44, 0xec, 0x84, 0x98, 0xee, 0xec, 0x7f, 0xf8, 0x48, 0xa2, 0x98,
-	0x8f, 0xc0, 0xdb, 0xa3, 0x2c, 0xdc, 0x26, 0xca, 0xbb, 0x7d, 0xd8, 0x3f, 0xc2, 0x66, 0x69, 0xe7,
-	0x08, 0x81, 0x25, 0x7d, 0x15, 0xe1, 0xb4, 0x88, 0xe6, 0x4c, 0x1e, 0x41, 0x4d, 0x14, 0xe6, 0x6c,
-	0xbb, 0x12, 0xf5, 0xd4, 0xfc, 0xba, 0xa2, 0x19, 0xf7, 0xf0, 0x4b, 0x58, 0x2f, 0x0c, 0x02, 0xa9,
-	0x43, 0xed, 0xc5, 0xf0, 0xbb, 0xe1, 0xc9, 0xcb, 0x61, 0xf3, 0x0e, 0x69, 0x42, 0x63, 0x30, 0x1c,
-	0x3c, 0x1f, 0xf4, 0x9e, 0x0d, 0xce, 0x06, 0xc3, 0xe3, 0xa6, 0x45, 0xd6, 0x60, 0x99, 0xf6, 0x7b,
-	0x47, 0x3f, 0x35, 0x17, 0x0e, 0x9a, 0xbf, 0x5d, 0xb7, 0xad, 0xdf, 0xaf, 0xdb, 0xd6, 0x1f, 0xd7,
-	0x6d, 0xeb, 0x97, 0x3f, 0xdb, 0x77, 0x9c, 0x15, 0x13, 0x66, 0xff, 0xdf, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0x48, 0x8b, 0xb8, 0x2d, 0x28, 0x0a, 0x00, 0x00,
+	// 1029 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x9d, 0x56, 0xdd, 0x6e, 0x1b, 0x45,
+	0x14, 0xae, 0xed, 0x24, 0xb6, 0x8f, 0x1d, 0xc7, 0x19, 0x15, 0x62, 0xb9, 0xc5, 0xa0, 0x05, 0xaa,
+	0xa8, 0x42, 0x36, 0xa4, 0x37, 0x50, 0x24, 0xc0, 0x89, 0x4d, 0x64, 0x28, 0x8e, 0x35, 0x4e, 0x69,
+	0xc9, 0xdd, 0x78, 0x77, 0xbc, 0x59, 0x75, 0xbd, 0xb3, 0x9a, 0x99, 0x6d, 0x12, 0x9e, 0xa1, 0x17,
+	0xbc, 0x06, 0xe2, 0x45, 0xb8, 0xe4, 0x11, 0x10, 0xdc, 0x70, 0xc3, 0x3b, 0x30, 0x3b, 0xeb, 0xb5,
+	0xf7, 0xc7, 0x6d, 0x23, 0x2e, 0x6c, 0xad, 0xcf, 0xf9, 0xce, 0x7c, 0x67, 0xce, 0x77, 0xce, 0x59,
+	0xc3, 0xa9, 0xed, 0xc8, 0xcb, 0x60, 0xd6, 0x35, 0xd9, 0xa2, 0xb7, 0x78, 0x64, 0xcd, 0xd4, 0x57,
+	0x4f, 0x70, 0xb3, 0x67, 0xcd, 0x3c, 0x66, 0xd1, 0x9e, 0x4d, 0x3d, 0xca, 0x89, 0xa4, 0x56, 0xcf,
+	0xe7, 0x4c, 0xb2, 0x9e, 0x47, 0x16, 0x54, 0xf8, 0xc4, 0xa4, 0xeb, 0xa7, 0xae, 0xf6, 0xa0, 0xea,
+	0xca, 0xd0, 0xbe, 0x6f, 0x33, 0x66, 0xbb, 0x34, 0x0a, 0x99, 0x05, 0xf3, 0x9e, 0x90, 0x3c, 0x30,
+	0x65, 0x04, 0x6c, 0x77, 0xb2, 0xde, 0x2b, 0x4e, 0x7c, 0x9f, 0x72, 0xb1, 0xf4, 0x0f, 0xfe, 0x6f,
+	0x46, 0xc2, 0xbc, 0xa4, 0x0b, 0x12, 0x9d, 0x62, 0xbc, 0x2a, 0x41, 0x13, 0x53, 0x49, 0x3d, 0xe9,
+	0x30, 0xef, 0xcc, 0x0f, 0xbf, 0x05, 0x3a, 0x82, 0xbb, 0x3c, 0xb6, 0x4d, 0x28, 0x77, 0x98, 0x35,
+	0x26, 0x1e, 0x13, 0xad, 0xc2, 0x07, 0x85, 0xc3, 0x12, 0xde, 0xe8, 0x43, 0x0f, 0xa0, 0x31, 0x73,
+	0x99, 0xf9, 0x62, 0xea, 0xfc, 0x4c, 0x23, 0x74, 0x51, 0xa3, 0x33, 0x56, 0xf4, 0x09, 0xec, 0xab,
+	0xcb, 0xcc, 0x29, 0xff, 0x36, 0x90, 0x01, 0x5f, 0x42, 0x4b, 0x1a, 0x9a, 0x77, 0xa0, 0x43, 0xd8,
+	0x8b, 0x8c, 0x13, 0x22, 0x64, 0x84, 0xdd, 0xd2, 0xd8, 0xac, 0x59, 0x23, 0x43, 0xa6, 0x01, 0x91,
+	0x64, 0x78, 0xed, 0x3b, 0xfc, 0xa6, 0xb5, 0xad, 0x90, 0x15, 0x9c, 0x35, 0xa3, 0x0b, 0x38, 0xcc,
+	0x98, 0xfa, 0x73, 0x49, 0xf9, 0x98, 0xc9, 0xbe, 0x69, 0x52, 0x21, 0x92, 0x37, 0xde, 0xd1, 0x64,
+	0xb7, 0xc6, 0xa3, 0xaf, 0xa0, 0x3d, 0xd7, 0xe9, 0xe3, 0x4d, 0xf5, 0x2b, 0xeb, 0xd3, 0xde, 0x80,
+	0x30, 0x24, 0xd4, 0x47, 0x9e, 0x45, 0xaf, 0x63, 0x25, 0x5a, 0x50, 0xa6, 0x1e, 0x99, 0xb9, 0xd4,
+	0xd2, 0xc5, 0xaf, 0xe0, 0xf8, 0xe7, 0xad, 0xeb, 0x6d, 0x40, 0x9d, 0x5e, 0x9b, 0x6e, 0x60, 0x51,
+	0xeb, 0x9c, 0xd8, 0x61, 0xa9, 0x4b, 0x87, 0x55, 0x9c, 0xb2, 0x19, 0xff, 0xee, 0x40, 0x73, 0x1c,
+	0xf7, 0x47, 0x4c, 0xfd, 0x10, 0x9a, 0x33, 0xc6, 0xa4, 0xea, 0x49, 0xe2, 0x0f, 0x53, 0x39, 0xe4,
+	0xec, 0x21, 0xc9, 0xdc, 0x0d, 0xc4, 0x65, 0x8c, 0x2b, 0x6a, 0x5c, 0xca, 0x16, 0x0a, 0x7f, 0xc5,
+	0x1d, 0x49, 0xc5, 0x39, 0x3b, 0x61, 0x8b, 0x85, 0x23, 0x9f, 0x30, 0x5b, 0x0b, 0x5f, 0xc1, 0x79,
+	0x47, 0x78, 0x3d, 0xd3, 0xa5, 0xc4, 0x0b, 0x56, 0xdc, 0x5b, 0x1a, 0x9a, 0xb1, 0xa2, 0x8f, 0x60,
+	0x97, 0x53, 0x9f, 0x38, 0x3c, 0x86, 0x45, 0xa2, 0xa7, 0x8d, 0xe8, 0x14, 0x9a, 0x3c, 0xd3, 0xe4,
+	0x5a, 0xda, 0xda, 0xd1, 0xbd, 0xee, 0x7a, 0x40, 0xb3, 0x73, 0x80, 0x73, 0x41, 0x61, 0x97, 0x09,
+	0x8f, 0xf8, 0xe2, 0x92, 0xc9, 0x98, 0xb0, 0x1c, 0x75, 0x59, 0xc6, 0x8c, 0xbe, 0x84, 0xba, 0x93,
+	0x50, 0xb2, 0x55, 0xd1, 0x74, 0x07, 0x09, 0xba, 0xa4, 0xd0, 0x38, 0x05, 0x56, 0x6d, 0xb4, 0x1b,
+	0x4d, 0x69, 0x1c, 0x5d, 0xd5, 0xd1, 0xad, 0x44, 0xf4, 0x34, 0xe9, 0xc7, 0x69, 0x78, 0x58, 0x6b,
+	0x93, 0xb9, 0xd6, 0x33, 0x5d, 0xd6, 0x38, 0x51, 0x88, 0x6a, 0x9d, 0x73, 0xa0, 0xef, 0xa0, 0xc1,
+	0x03, 0x75, 0xcd, 0x45, 0xac, 0x7d, 0xab, 0xa6, 0xe9, 0x8c, 0x04, 0xdd, 0xaa, 0x3d, 0x70, 0x0a,
+	0x89, 0x33, 0x91, 0x68, 0x02, 0xef, 0x98, 0x44, 0xe5, 0x72, 0x1c, 0x76, 0xa1, 0x38, 0xf3, 0x54,
+	0x4d, 0xb9, 0x43, 0x5f, 0xd2, 0x56, 0x5d, 0x1f, 0xd9, 0xee, 0x46, 0x5b, 0xad, 0x1b, 0x6f, 0xb5,
+	0xee, 0x31, 0x63, 0xee, 0x8f, 0xc4, 0x0d, 0x28, 0xde, 0x1c, 0x88, 0x7e, 0x00, 0x44, 0x6c, 0x9b,
+	0x53, 0x9b, 0x24, 0xd5, 0xdb, 0xd5, 0xc7, 0xbd, 0x97, 0xc8, 0xb0, 0x9f, 0x03, 0xe1, 0x0d, 0x81,
+	0xa1, 0x2e, 0x42, 0x12, 0xdb, 0xf1, 0xec, 0xa9, 0x54, 0xeb, 0xb1, 0xd5, 0xc8, 0xe9, 0x32, 0x4d,
+	0xb8, 0x71, 0x0a, 0x8c, 0x86, 0xb0, 0x47, 0xaf, 0x55, 0x4b, 0xa8, 0xc1, 0x89, 0x13, 0xf9, 0xa7,
+	0xbc, 0xbc, 0xd8, 0xfa, 0x80, 0x61, 0x1a, 0x82, 0xb3, 0x31, 0xc6, 0x04, 0x50, 0x3e, 0x5b, 0xf4,
+	0x18, 0xea, 0x89, 0x7c, 0xc3, 0x6d, 0x5b, 0x52, 0x07, 0xbf, 0xbb, 0xf9, 0x8a, 0x38, 0x85, 0x35,
+	0x3c, 0xa8, 0x25, 0x9c, 0xa8, 0x03, 0x10, 0xbb, 0x57, 0x53, 0x9b, 0xb0, 0xa0, 0xaf, 0x95, 0x5f,
+	0xaa, 0xfa, 0xce, 0x02, 0xd5, 0x06, 0x7a, 0x5a, 0x6b, 0x47, 0xef, 0x6f, 0x20, 0xa2, 0x56, 0x7f,
+	0x05, 0xc3, 0x89, 0x10, 0xe3, 0x55, 0x01, 0xee, 0x6e, 0x02, 0x85, 0x03, 0xc2, 0xa9, 0x60, 0x6e,
+	0x10, 0xe6, 0x91, 0x7c, 0x6b, 0x64, 0xcd, 0xaa, 0xeb, 0xf6, 0x2d, 0x76, 0xe5, 0x09, 0xb2, 0xf0,
+	0xdd, 0x55, 0xe3, 0x45, 0xa9, 0xdc, 0x4f, 0xa4, 0x32, 0xc8, 0x62, 0x70, 0x3e, 0xcc, 0xf8, 0x18,
+	0xf6, 0x73, 0x38, 0xd4, 0x84, 0x12, 0x71, 0xdd, 0xe5, 0xed, 0xc3, 0x47, 0xe3, 0x1b, 0xa8, 0x27,
+	0xc5, 0x45, 0x9f, 0xc2, 0x8e, 0x92, 0x57, 0x06, 0x51, 0x8e, 0x8d, 0xf4, 0x7c, 0xad, 0x81, 0x81,
+	0xc0, 0x4b, 0x9c, 0xf1, 0x5b, 0x01, 0x2a, 0x98, 0xda, 0x8e, 0xda, 0x7e, 0x37, 0xe8, 0x04, 0x60,
+	0x85, 0x8f, 0xe5, 0xfa, 0x30, 0xb5, 0x4f, 0x22, 0xe0, 0x7a, 0x78, 0xd4, 0xc8, 0xa9, 0xdf, 0x38,
+	0x11, 0xd6, 0xbe, 0x80, 0xbd, 0x8c, 0x3b, 0x4c, 0xfc, 0x05, 0xbd, 0xd1, 0x39, 0x55, 0x71, 0xf8,
+	0x88, 0x3e, 0x83, 0xed, 0x97, 0xe1, 0x8c, 0x2c, 0xeb, 0x73, 0x6f, 0xd3, 0x60, 0xc6, 0xe5, 0x89,
+	0x90, 0x8f, 0x8b, 0x9f, 0x17, 0x8c, 0x5f, 0x8b, 0x70, 0xf0, 0x9a, 0xc1, 0x45, 0x16, 0x74, 0xf4,
+	0xd6, 0xd5, 0x5b, 0x48, 0x5d, 0x54, 0xbd, 0x85, 0x4e, 0x26, 0x4f, 0x4f, 0x98, 0x67, 0x06, 0x9c,
+	0x53, 0xcf, 0x8c, 0xf8, 0x43, 0x2d, 0xb2, 0x13, 0x3b, 0x60, 0x81, 0x5a, 0x1b, 0xd1, 0xcc, 0xbe,
+	0xe5, 0x8c, 0x90, 0x45, 0xbf, 0x04, 0x5e, 0xcf, 0x52, 0xbc, 0x0d, 0xcb, 0x9b, 0xcf, 0x40, 0xc7,
+	0xd0, 0x70, 0x62, 0x27, 0x09, 0x84, 0x6a, 0xf9, 0xd2, 0x5b, 0xb7, 0x4d, 0x26, 0xc2, 0x78, 0x0e,
+	0x7b, 0x99, 0xb9, 0x45, 0x08, 0xb6, 0xe4, 0x8d, 0x4f, 0x97, 0x42, 0xe8, 0x67, 0xa5, 0x44, 0x99,
+	0xa5, 0x7a, 0xf5, 0x20, 0xc7, 0x31, 0xd5, 0xff, 0xe2, 0x70, 0x8c, 0x7b, 0xf8, 0x05, 0xec, 0xa6,
+	0x9a, 0x09, 0xd5, 0xa0, 0xfc, 0x74, 0xfc, 0xfd, 0xf8, 0xec, 0xd9, 0xb8, 0x79, 0x47, 0x89, 0x5d,
+	0x1f, 0x8d, 0x47, 0xe7, 0xa3, 0xfe, 0x93, 0xd1, 0xc5, 0x68, 0x7c, 0xda, 0x2c, 0xa0, 0x2a, 0x6c,
+	0xe3, 0x61, 0x7f, 0xf0, 0x53, 0xb3, 0x78, 0xdc, 0xfc, 0xfd, 0xaf, 0x4e, 0xe1, 0x0f, 0xf5, 0xf9,
+	0x53, 0x7d, 0x7e, 0xf9, 0xbb, 0x73, 0x67, 0xb6, 0xa3, 0x69, 0x1e, 0xfd, 0x07, 0x62, 0xf5, 0xdd,
+	0xe0, 0x90, 0x0a, 0x00, 0x00,
 }
@@ -35,13 +35,28 @@ enum ErrorType {
 enum ErrorFlags {
     NONE               = 0x00,
     RESOURCE_EXHAUSTED = 0x01,
-    SERVER_TIMEOUT     = 0x02
+    SERVER_TIMEOUT     = 0x02,
+    SHARD_NOT_OWNED    = 0x04
+}
+
+enum ErrorCode {
+	UNKNOWN,
+	INVALID_PARAMS,
+	NOT_FOUND,
+	RESOURCE_EXHAUSTED,
+	TIMEOUT,
+	UNAVAILABLE,
+	INTERNAL
 }
 
 exception Error {
 	1: required ErrorType type = ErrorType.INTERNAL_ERROR
 	2: required string message
 	3: optional i64 flags = 0
+	4: optional ErrorCode code
+	5: optional bool retryable
+	6: optional string resource
+	7: optional i64 limit
 }
 
 exception WriteBatchRawErrors {
@@ -185,6 +200,7 @@ struct FetchTaggedRequest {
 	9: optional i64 docsLimit
 	10: optional binary source
 	11: optional bool requireNoWait = false
+	12: optional bool fetchLatestDatapoint = false
 }
 
 struct FetchTaggedResult {
@@ -202,6 +218,7 @@ struct FetchTaggedIDResult {
 
 	// Deprecated -- do not use.
 	5: optional Error err
+	6: optional Datapoint latestDatapoint
 }
 
 struct FetchBlocksRawRequest {
@@ -209,6 +226,7 @@ struct FetchBlocksRawRequest {
 	2: required i32 shard
 	3: required list<FetchBlocksRawRequestElement> elements
 	4: optional binary source
+	5: optional list<string> acceptCompression
 }
 
 struct FetchBlocksRawRequestElement {
@@ -218,6 +236,7 @@ struct FetchBlocksRawRequestElement {
 
 struct FetchBlocksRawResult {
 	1: required list<Blocks> elements
+	2: optional string compression
 }
 
 struct Blocks {
@@ -300,6 +319,9 @@ struct WriteTaggedBatchRawRequestElement {
 	1: required binary id
 	2: required binary encodedTags
 	3: required Datapoint datapoint
+	// shardID is the shard the id maps to, precomputed by the client, which
+	// lets the server skip hashing the id.
+	4: optional i64 shardID
 }
 
 struct WriteTaggedBatchRawV2RequestElement {
@@ -307,6 +329,7 @@ struct WriteTaggedBatchRawV2RequestElement {
 	2: required binary encodedTags
 	3: required Datapoint datapoint
 	4: required i64 nameSpace
+	5: optional i64 shardID
 }
 
 struct WriteBatchRawError {
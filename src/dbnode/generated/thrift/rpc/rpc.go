@@ -168,6 +168,7 @@ const (
 	ErrorFlags_NONE               ErrorFlags = 0
 	ErrorFlags_RESOURCE_EXHAUSTED ErrorFlags = 1
 	ErrorFlags_SERVER_TIMEOUT     ErrorFlags = 2
+	ErrorFlags_SHARD_NOT_OWNED    ErrorFlags = 4
 )
 
 func (p ErrorFlags) String() string {
@@ -178,6 +179,8 @@ func (p ErrorFlags) String() string {
 		return "RESOURCE_EXHAUSTED"
 	case ErrorFlags_SERVER_TIMEOUT:
 		return "SERVER_TIMEOUT"
+	case ErrorFlags_SHARD_NOT_OWNED:
+		return "SHARD_NOT_OWNED"
 	}
 	return "<UNSET>"
 }
@@ -190,6 +193,8 @@ func ErrorFlagsFromString(s string) (ErrorFlags, error) {
 		return ErrorFlags_RESOURCE_EXHAUSTED, nil
 	case "SERVER_TIMEOUT":
 		return ErrorFlags_SERVER_TIMEOUT, nil
+	case "SHARD_NOT_OWNED":
+		return ErrorFlags_SHARD_NOT_OWNED, nil
 	}
 	return ErrorFlags(0), fmt.Errorf("not a valid ErrorFlags string")
 }
@@ -225,6 +230,89 @@ func (p *ErrorFlags) Value() (driver.Value, error) {
 	return int64(*p), nil
 }
 
+type ErrorCode int64
+
+const (
+	ErrorCode_UNKNOWN            ErrorCode = 0
+	ErrorCode_INVALID_PARAMS     ErrorCode = 1
+	ErrorCode_NOT_FOUND          ErrorCode = 2
+	ErrorCode_RESOURCE_EXHAUSTED ErrorCode = 3
+	ErrorCode_TIMEOUT            ErrorCode = 4
+	ErrorCode_UNAVAILABLE        ErrorCode = 5
+	ErrorCode_INTERNAL           ErrorCode = 6
+)
+
+func (p ErrorCode) String() string {
+	switch p {
+	case ErrorCode_UNKNOWN:
+		return "UNKNOWN"
+	case ErrorCode_INVALID_PARAMS:
+		return "INVALID_PARAMS"
+	case ErrorCode_NOT_FOUND:
+		return "NOT_FOUND"
+	case ErrorCode_RESOURCE_EXHAUSTED:
+		return "RESOURCE_EXHAUSTED"
+	case ErrorCode_TIMEOUT:
+		return "TIMEOUT"
+	case ErrorCode_UNAVAILABLE:
+		return "UNAVAILABLE"
+	case ErrorCode_INTERNAL:
+		return "INTERNAL"
+	}
+	return "<UNSET>"
+}
+
+func ErrorCodeFromString(s string) (ErrorCode, error) {
+	switch s {
+	case "UNKNOWN":
+		return ErrorCode_UNKNOWN, nil
+	case "INVALID_PARAMS":
+		return ErrorCode_INVALID_PARAMS, nil
+	case "NOT_FOUND":
+		return ErrorCode_NOT_FOUND, nil
+	case "RESOURCE_EXHAUSTED":
+		return ErrorCode_RESOURCE_EXHAUSTED, nil
+	case "TIMEOUT":
+		return ErrorCode_TIMEOUT, nil
+	case "UNAVAILABLE":
+		return ErrorCode_UNAVAILABLE, nil
+	case "INTERNAL":
+		return ErrorCode_INTERNAL, nil
+	}
+	return ErrorCode(0), fmt.Errorf("not a valid ErrorCode string")
+}
+
+func ErrorCodePtr(v ErrorCode) *ErrorCode { return &v }
+
+func (p ErrorCode) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *ErrorCode) UnmarshalText(text []byte) error {
+	q, err := ErrorCodeFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*p = q
+	return nil
+}
+
+func (p *ErrorCode) Scan(value interface{}) error {
+	v, ok := value.(int64)
+	if !ok {
+		return errors.New("Scan value is not int64")
+	}
+	*p = ErrorCode(v)
+	return nil
+}
+
+func (p *ErrorCode) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return int64(*p), nil
+}
+
 type AggregateQueryType int64
 
 const (
@@ -428,10 +516,18 @@ func (p *EqualTimestampStrategy) Value() (driver.Value, error) {
 //  - Type
 //  - Message
 //  - Flags
+//  - Code
+//  - Retryable
+//  - Resource
+//  - Limit
 type Error struct {
-	Type    ErrorType `thrift:"type,1,required" db:"type" json:"type"`
-	Message string    `thrift:"message,2,required" db:"message" json:"message"`
-	Flags   int64     `thrift:"flags,3" db:"flags" json:"flags,omitempty"`
+	Type      ErrorType  `thrift:"type,1,required" db:"type" json:"type"`
+	Message   string     `thrift:"message,2,required" db:"message" json:"message"`
+	Flags     int64      `thrift:"flags,3" db:"flags" json:"flags,omitempty"`
+	Code      *ErrorCode `thrift:"code,4" db:"code" json:"code,omitempty"`
+	Retryable *bool      `thrift:"retryable,5" db:"retryable" json:"retryable,omitempty"`
+	Resource  *string    `thrift:"resource,6" db:"resource" json:"resource,omitempty"`
+	Limit     *int64     `thrift:"limit,7" db:"limit" json:"limit,omitempty"`
 }
 
 func NewError() *Error {
@@ -453,10 +549,62 @@ var Error_Flags_DEFAULT int64 = 0
 func (p *Error) GetFlags() int64 {
 	return p.Flags
 }
+
+var Error_Code_DEFAULT ErrorCode
+
+func (p *Error) GetCode() ErrorCode {
+	if !p.IsSetCode() {
+		return Error_Code_DEFAULT
+	}
+	return *p.Code
+}
+
+var Error_Retryable_DEFAULT bool
+
+func (p *Error) GetRetryable() bool {
+	if !p.IsSetRetryable() {
+		return Error_Retryable_DEFAULT
+	}
+	return *p.Retryable
+}
+
+var Error_Resource_DEFAULT string
+
+func (p *Error) GetResource() string {
+	if !p.IsSetResource() {
+		return Error_Resource_DEFAULT
+	}
+	return *p.Resource
+}
+
+var Error_Limit_DEFAULT int64
+
+func (p *Error) GetLimit() int64 {
+	if !p.IsSetLimit() {
+		return Error_Limit_DEFAULT
+	}
+	return *p.Limit
+}
 func (p *Error) IsSetFlags() bool {
 	return p.Flags != Error_Flags_DEFAULT
 }
 
+func (p *Error) IsSetCode() bool {
+	return p.Code != nil
+}
+
+func (p *Error) IsSetRetryable() bool {
+	return p.Retryable != nil
+}
+
+func (p *Error) IsSetResource() bool {
+	return p.Resource != nil
+}
+
+func (p *Error) IsSetLimit() bool {
+	return p.Limit != nil
+}
+
 func (p *Error) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -488,6 +636,22 @@ func (p *Error) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField3(iprot); err != nil {
 				return err
 			}
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -537,6 +701,43 @@ func (p *Error) ReadField3(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *Error) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		temp := ErrorCode(v)
+		p.Code = &temp
+	}
+	return nil
+}
+
+func (p *Error) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.Retryable = &v
+	}
+	return nil
+}
+
+func (p *Error) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.Resource = &v
+	}
+	return nil
+}
+
+func (p *Error) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		p.Limit = &v
+	}
+	return nil
+}
+
 func (p *Error) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("Error"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -551,6 +752,18 @@ func (p *Error) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField3(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -602,6 +815,66 @@ func (p *Error) writeField3(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *Error) writeField4(oprot thrift.TProtocol) (err error) {
+	if p.IsSetCode() {
+		if err := oprot.WriteFieldBegin("code", thrift.I32, 4); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:code: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(*p.Code)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.code (4) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:code: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *Error) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRetryable() {
+		if err := oprot.WriteFieldBegin("retryable", thrift.BOOL, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:retryable: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.Retryable)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.retryable (5) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:retryable: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *Error) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetResource() {
+		if err := oprot.WriteFieldBegin("resource", thrift.STRING, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:resource: ", p), err)
+		}
+		if err := oprot.WriteString(string(*p.Resource)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.resource (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:resource: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *Error) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimit() {
+		if err := oprot.WriteFieldBegin("limit", thrift.I64, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:limit: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.Limit)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limit (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:limit: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *Error) String() string {
 	if p == nil {
 		return "<nil>"
@@ -614,7 +887,7 @@ func (p *Error) Error() string {
 }
 
 // Attributes:
-//  - Errors
+//   - Errors
 type WriteBatchRawErrors struct {
 	Errors []*WriteBatchRawError `thrift:"errors,1,required" db:"errors" json:"errors"`
 }
@@ -736,13 +1009,13 @@ func (p *WriteBatchRawErrors) Error() string {
 }
 
 // Attributes:
-//  - RangeStart
-//  - RangeEnd
-//  - NameSpace
-//  - ID
-//  - RangeType
-//  - ResultTimeType
-//  - Source
+//   - RangeStart
+//   - RangeEnd
+//   - NameSpace
+//   - ID
+//   - RangeType
+//   - ResultTimeType
+//   - Source
 type FetchRequest struct {
 	RangeStart     int64    `thrift:"rangeStart,1,required" db:"rangeStart" json:"rangeStart"`
 	RangeEnd       int64    `thrift:"rangeEnd,2,required" db:"rangeEnd" json:"rangeEnd"`
@@ -1090,7 +1363,7 @@ func (p *FetchRequest) String() string {
 }
 
 // Attributes:
-//  - Datapoints
+//   - Datapoints
 type FetchResult_ struct {
 	Datapoints []*Datapoint `thrift:"datapoints,1,required" db:"datapoints" json:"datapoints"`
 }
@@ -1210,10 +1483,10 @@ func (p *FetchResult_) String() string {
 }
 
 // Attributes:
-//  - Timestamp
-//  - Value
-//  - Annotation
-//  - TimestampTimeType
+//   - Timestamp
+//   - Value
+//   - Annotation
+//   - TimestampTimeType
 type Datapoint struct {
 	Timestamp         int64    `thrift:"timestamp,1,required" db:"timestamp" json:"timestamp"`
 	Value             float64  `thrift:"value,2,required" db:"value" json:"value"`
@@ -1438,9 +1711,9 @@ func (p *Datapoint) String() string {
 }
 
 // Attributes:
-//  - NameSpace
-//  - ID
-//  - Datapoint
+//   - NameSpace
+//   - ID
+//   - Datapoint
 type WriteRequest struct {
 	NameSpace string     `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 	ID        string     `thrift:"id,2,required" db:"id" json:"id"`
@@ -1627,10 +1900,10 @@ func (p *WriteRequest) String() string {
 }
 
 // Attributes:
-//  - NameSpace
-//  - ID
-//  - Tags
-//  - Datapoint
+//   - NameSpace
+//   - ID
+//   - Tags
+//   - Datapoint
 type WriteTaggedRequest struct {
 	NameSpace string     `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 	ID        string     `thrift:"id,2,required" db:"id" json:"id"`
@@ -1875,12 +2148,12 @@ func (p *WriteTaggedRequest) String() string {
 }
 
 // Attributes:
-//  - RangeStart
-//  - RangeEnd
-//  - NameSpace
-//  - Ids
-//  - RangeTimeType
-//  - Source
+//   - RangeStart
+//   - RangeEnd
+//   - NameSpace
+//   - Ids
+//   - RangeTimeType
+//   - Source
 type FetchBatchRawRequest struct {
 	RangeStart    int64    `thrift:"rangeStart,1,required" db:"rangeStart" json:"rangeStart"`
 	RangeEnd      int64    `thrift:"rangeEnd,2,required" db:"rangeEnd" json:"rangeEnd"`
@@ -2204,9 +2477,9 @@ func (p *FetchBatchRawRequest) String() string {
 }
 
 // Attributes:
-//  - NameSpaces
-//  - Elements
-//  - Source
+//   - NameSpaces
+//   - Elements
+//   - Source
 type FetchBatchRawV2Request struct {
 	NameSpaces [][]byte                         `thrift:"nameSpaces,1,required" db:"nameSpaces" json:"nameSpaces"`
 	Elements   []*FetchBatchRawV2RequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
@@ -2428,11 +2701,11 @@ func (p *FetchBatchRawV2Request) String() string {
 }
 
 // Attributes:
-//  - NameSpace
-//  - RangeStart
-//  - RangeEnd
-//  - ID
-//  - RangeTimeType
+//   - NameSpace
+//   - RangeStart
+//   - RangeEnd
+//   - ID
+//   - RangeTimeType
 type FetchBatchRawV2RequestElement struct {
 	NameSpace     int64    `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 	RangeStart    int64    `thrift:"rangeStart,2,required" db:"rangeStart" json:"rangeStart"`
@@ -2693,7 +2966,7 @@ func (p *FetchBatchRawV2RequestElement) String() string {
 }
 
 // Attributes:
-//  - Elements
+//   - Elements
 type FetchBatchRawResult_ struct {
 	Elements []*FetchRawResult_ `thrift:"elements,1,required" db:"elements" json:"elements"`
 }
@@ -2811,8 +3084,8 @@ func (p *FetchBatchRawResult_) String() string {
 }
 
 // Attributes:
-//  - Segments
-//  - Err
+//   - Segments
+//   - Err
 type FetchRawResult_ struct {
 	Segments []*Segments `thrift:"segments,1,required" db:"segments" json:"segments"`
 	Err      *Error      `thrift:"err,2" db:"err" json:"err,omitempty"`
@@ -2976,8 +3249,8 @@ func (p *FetchRawResult_) String() string {
 }
 
 // Attributes:
-//  - Merged
-//  - Unmerged
+//   - Merged
+//   - Unmerged
 type Segments struct {
 	Merged   *Segment   `thrift:"merged,1" db:"merged" json:"merged,omitempty"`
 	Unmerged []*Segment `thrift:"unmerged,2" db:"unmerged" json:"unmerged,omitempty"`
@@ -3141,11 +3414,11 @@ func (p *Segments) String() string {
 }
 
 // Attributes:
-//  - Head
-//  - Tail
-//  - StartTime
-//  - BlockSize
-//  - Checksum
+//   - Head
+//   - Tail
+//   - StartTime
+//   - BlockSize
+//   - Checksum
 type Segment struct {
 	Head      []byte `thrift:"head,1,required" db:"head" json:"head"`
 	Tail      []byte `thrift:"tail,2,required" db:"tail" json:"tail"`
@@ -3418,29 +3691,31 @@ func (p *Segment) String() string {
 }
 
 // Attributes:
-//  - NameSpace
-//  - Query
-//  - RangeStart
-//  - RangeEnd
-//  - FetchData
-//  - SeriesLimit
-//  - RangeTimeType
-//  - RequireExhaustive
-//  - DocsLimit
-//  - Source
-//  - RequireNoWait
+//   - NameSpace
+//   - Query
+//   - RangeStart
+//   - RangeEnd
+//   - FetchData
+//   - SeriesLimit
+//   - RangeTimeType
+//   - RequireExhaustive
+//   - DocsLimit
+//   - Source
+//   - RequireNoWait
+//   - FetchLatestDatapoint
 type FetchTaggedRequest struct {
-	NameSpace         []byte   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
-	Query             []byte   `thrift:"query,2,required" db:"query" json:"query"`
-	RangeStart        int64    `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
-	RangeEnd          int64    `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
-	FetchData         bool     `thrift:"fetchData,5,required" db:"fetchData" json:"fetchData"`
-	SeriesLimit       *int64   `thrift:"seriesLimit,6" db:"seriesLimit" json:"seriesLimit,omitempty"`
-	RangeTimeType     TimeType `thrift:"rangeTimeType,7" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
-	RequireExhaustive bool     `thrift:"requireExhaustive,8" db:"requireExhaustive" json:"requireExhaustive,omitempty"`
-	DocsLimit         *int64   `thrift:"docsLimit,9" db:"docsLimit" json:"docsLimit,omitempty"`
-	Source            []byte   `thrift:"source,10" db:"source" json:"source,omitempty"`
-	RequireNoWait     bool     `thrift:"requireNoWait,11" db:"requireNoWait" json:"requireNoWait,omitempty"`
+	NameSpace            []byte   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Query                []byte   `thrift:"query,2,required" db:"query" json:"query"`
+	RangeStart           int64    `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd             int64    `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
+	FetchData            bool     `thrift:"fetchData,5,required" db:"fetchData" json:"fetchData"`
+	SeriesLimit          *int64   `thrift:"seriesLimit,6" db:"seriesLimit" json:"seriesLimit,omitempty"`
+	RangeTimeType        TimeType `thrift:"rangeTimeType,7" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+	RequireExhaustive    bool     `thrift:"requireExhaustive,8" db:"requireExhaustive" json:"requireExhaustive,omitempty"`
+	DocsLimit            *int64   `thrift:"docsLimit,9" db:"docsLimit" json:"docsLimit,omitempty"`
+	Source               []byte   `thrift:"source,10" db:"source" json:"source,omitempty"`
+	RequireNoWait        bool     `thrift:"requireNoWait,11" db:"requireNoWait" json:"requireNoWait,omitempty"`
+	FetchLatestDatapoint bool     `thrift:"fetchLatestDatapoint,12" db:"fetchLatestDatapoint" json:"fetchLatestDatapoint,omitempty"`
 }
 
 func NewFetchTaggedRequest() *FetchTaggedRequest {
@@ -3512,6 +3787,12 @@ var FetchTaggedRequest_RequireNoWait_DEFAULT bool = false
 func (p *FetchTaggedRequest) GetRequireNoWait() bool {
 	return p.RequireNoWait
 }
+
+var FetchTaggedRequest_FetchLatestDatapoint_DEFAULT bool = false
+
+func (p *FetchTaggedRequest) GetFetchLatestDatapoint() bool {
+	return p.FetchLatestDatapoint
+}
 func (p *FetchTaggedRequest) IsSetSeriesLimit() bool {
 	return p.SeriesLimit != nil
 }
@@ -3536,6 +3817,10 @@ func (p *FetchTaggedRequest) IsSetRequireNoWait() bool {
 	return p.RequireNoWait != FetchTaggedRequest_RequireNoWait_DEFAULT
 }
 
+func (p *FetchTaggedRequest) IsSetFetchLatestDatapoint() bool {
+	return p.FetchLatestDatapoint != FetchTaggedRequest_FetchLatestDatapoint_DEFAULT
+}
+
 func (p *FetchTaggedRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -3605,6 +3890,10 @@ func (p *FetchTaggedRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField11(iprot); err != nil {
 				return err
 			}
+		case 12:
+			if err := p.ReadField12(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -3735,6 +4024,15 @@ func (p *FetchTaggedRequest) ReadField11(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchTaggedRequest) ReadField12(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 12: ", err)
+	} else {
+		p.FetchLatestDatapoint = v
+	}
+	return nil
+}
+
 func (p *FetchTaggedRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchTaggedRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -3773,6 +4071,9 @@ func (p *FetchTaggedRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField11(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField12(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -3938,6 +4239,21 @@ func (p *FetchTaggedRequest) writeField11(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchTaggedRequest) writeField12(oprot thrift.TProtocol) (err error) {
+	if p.IsSetFetchLatestDatapoint() {
+		if err := oprot.WriteFieldBegin("fetchLatestDatapoint", thrift.BOOL, 12); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 12:fetchLatestDatapoint: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(p.FetchLatestDatapoint)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.fetchLatestDatapoint (12) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 12:fetchLatestDatapoint: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchTaggedRequest) String() string {
 	if p == nil {
 		return "<nil>"
@@ -3946,10 +4262,10 @@ func (p *FetchTaggedRequest) String() string {
 }
 
 // Attributes:
-//  - Elements
-//  - Exhaustive
-//  - WaitedIndex
-//  - WaitedSeriesRead
+//   - Elements
+//   - Exhaustive
+//   - WaitedIndex
+//   - WaitedSeriesRead
 type FetchTaggedResult_ struct {
 	Elements         []*FetchTaggedIDResult_ `thrift:"elements,1,required" db:"elements" json:"elements"`
 	Exhaustive       bool                    `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
@@ -4196,17 +4512,19 @@ func (p *FetchTaggedResult_) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - NameSpace
-//  - EncodedTags
-//  - Segments
-//  - Err
+//   - ID
+//   - NameSpace
+//   - EncodedTags
+//   - Segments
+//   - Err
+//   - LatestDatapoint
 type FetchTaggedIDResult_ struct {
-	ID          []byte      `thrift:"id,1,required" db:"id" json:"id"`
-	NameSpace   []byte      `thrift:"nameSpace,2,required" db:"nameSpace" json:"nameSpace"`
-	EncodedTags []byte      `thrift:"encodedTags,3,required" db:"encodedTags" json:"encodedTags"`
-	Segments    []*Segments `thrift:"segments,4" db:"segments" json:"segments,omitempty"`
-	Err         *Error      `thrift:"err,5" db:"err" json:"err,omitempty"`
+	ID              []byte      `thrift:"id,1,required" db:"id" json:"id"`
+	NameSpace       []byte      `thrift:"nameSpace,2,required" db:"nameSpace" json:"nameSpace"`
+	EncodedTags     []byte      `thrift:"encodedTags,3,required" db:"encodedTags" json:"encodedTags"`
+	Segments        []*Segments `thrift:"segments,4" db:"segments" json:"segments,omitempty"`
+	Err             *Error      `thrift:"err,5" db:"err" json:"err,omitempty"`
+	LatestDatapoint *Datapoint  `thrift:"latestDatapoint,6" db:"latestDatapoint" json:"latestDatapoint,omitempty"`
 }
 
 func NewFetchTaggedIDResult_() *FetchTaggedIDResult_ {
@@ -4239,6 +4557,15 @@ func (p *FetchTaggedIDResult_) GetErr() *Error {
 	}
 	return p.Err
 }
+
+var FetchTaggedIDResult__LatestDatapoint_DEFAULT *Datapoint
+
+func (p *FetchTaggedIDResult_) GetLatestDatapoint() *Datapoint {
+	if !p.IsSetLatestDatapoint() {
+		return FetchTaggedIDResult__LatestDatapoint_DEFAULT
+	}
+	return p.LatestDatapoint
+}
 func (p *FetchTaggedIDResult_) IsSetSegments() bool {
 	return p.Segments != nil
 }
@@ -4247,6 +4574,10 @@ func (p *FetchTaggedIDResult_) IsSetErr() bool {
 	return p.Err != nil
 }
 
+func (p *FetchTaggedIDResult_) IsSetLatestDatapoint() bool {
+	return p.LatestDatapoint != nil
+}
+
 func (p *FetchTaggedIDResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -4288,6 +4619,10 @@ func (p *FetchTaggedIDResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField5(iprot); err != nil {
 				return err
 			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4369,6 +4704,16 @@ func (p *FetchTaggedIDResult_) ReadField5(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchTaggedIDResult_) ReadField6(iprot thrift.TProtocol) error {
+	p.LatestDatapoint = &Datapoint{
+		TimestampTimeType: 0,
+	}
+	if err := p.LatestDatapoint.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.LatestDatapoint), err)
+	}
+	return nil
+}
+
 func (p *FetchTaggedIDResult_) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchTaggedIDResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -4389,6 +4734,9 @@ func (p *FetchTaggedIDResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField5(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -4476,6 +4824,21 @@ func (p *FetchTaggedIDResult_) writeField5(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchTaggedIDResult_) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLatestDatapoint() {
+		if err := oprot.WriteFieldBegin("latestDatapoint", thrift.STRUCT, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:latestDatapoint: ", p), err)
+		}
+		if err := p.LatestDatapoint.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.LatestDatapoint), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:latestDatapoint: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchTaggedIDResult_) String() string {
 	if p == nil {
 		return "<nil>"
@@ -4488,11 +4851,13 @@ func (p *FetchTaggedIDResult_) String() string {
 //  - Shard
 //  - Elements
 //  - Source
+//  - AcceptCompression
 type FetchBlocksRawRequest struct {
-	NameSpace []byte                          `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
-	Shard     int32                           `thrift:"shard,2,required" db:"shard" json:"shard"`
-	Elements  []*FetchBlocksRawRequestElement `thrift:"elements,3,required" db:"elements" json:"elements"`
-	Source    []byte                          `thrift:"source,4" db:"source" json:"source,omitempty"`
+	NameSpace         []byte                          `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Shard             int32                           `thrift:"shard,2,required" db:"shard" json:"shard"`
+	Elements          []*FetchBlocksRawRequestElement `thrift:"elements,3,required" db:"elements" json:"elements"`
+	Source            []byte                          `thrift:"source,4" db:"source" json:"source,omitempty"`
+	AcceptCompression []string                        `thrift:"acceptCompression,5" db:"acceptCompression" json:"acceptCompression,omitempty"`
 }
 
 func NewFetchBlocksRawRequest() *FetchBlocksRawRequest {
@@ -4516,10 +4881,20 @@ var FetchBlocksRawRequest_Source_DEFAULT []byte
 func (p *FetchBlocksRawRequest) GetSource() []byte {
 	return p.Source
 }
+
+var FetchBlocksRawRequest_AcceptCompression_DEFAULT []string
+
+func (p *FetchBlocksRawRequest) GetAcceptCompression() []string {
+	return p.AcceptCompression
+}
 func (p *FetchBlocksRawRequest) IsSetSource() bool {
 	return p.Source != nil
 }
 
+func (p *FetchBlocksRawRequest) IsSetAcceptCompression() bool {
+	return p.AcceptCompression != nil
+}
+
 func (p *FetchBlocksRawRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -4557,6 +4932,10 @@ func (p *FetchBlocksRawRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField4(iprot); err != nil {
 				return err
 			}
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4628,6 +5007,28 @@ func (p *FetchBlocksRawRequest) ReadField4(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchBlocksRawRequest) ReadField5(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]string, 0, size)
+	p.AcceptCompression = tSlice
+	for i := 0; i < size; i++ {
+		var _elem12 string
+		if v, err := iprot.ReadString(); err != nil {
+			return thrift.PrependError("error reading field 0: ", err)
+		} else {
+			_elem12 = v
+		}
+		p.AcceptCompression = append(p.AcceptCompression, _elem12)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
 func (p *FetchBlocksRawRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchBlocksRawRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -4645,6 +5046,9 @@ func (p *FetchBlocksRawRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField4(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -4717,6 +5121,29 @@ func (p *FetchBlocksRawRequest) writeField4(oprot thrift.TProtocol) (err error)
 	return err
 }
 
+func (p *FetchBlocksRawRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetAcceptCompression() {
+		if err := oprot.WriteFieldBegin("acceptCompression", thrift.LIST, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:acceptCompression: ", p), err)
+		}
+		if err := oprot.WriteListBegin(thrift.STRING, len(p.AcceptCompression)); err != nil {
+			return thrift.PrependError("error writing list begin: ", err)
+		}
+		for _, v := range p.AcceptCompression {
+			if err := oprot.WriteString(string(v)); err != nil {
+				return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", p), err)
+			}
+		}
+		if err := oprot.WriteListEnd(); err != nil {
+			return thrift.PrependError("error writing list end: ", err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:acceptCompression: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchBlocksRawRequest) String() string {
 	if p == nil {
 		return "<nil>"
@@ -4725,8 +5152,8 @@ func (p *FetchBlocksRawRequest) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - Starts
+//   - ID
+//   - Starts
 type FetchBlocksRawRequestElement struct {
 	ID     []byte  `thrift:"id,1,required" db:"id" json:"id"`
 	Starts []int64 `thrift:"starts,2,required" db:"starts" json:"starts"`
@@ -4886,8 +5313,10 @@ func (p *FetchBlocksRawRequestElement) String() string {
 
 // Attributes:
 //  - Elements
+//  - Compression
 type FetchBlocksRawResult_ struct {
-	Elements []*Blocks `thrift:"elements,1,required" db:"elements" json:"elements"`
+	Elements    []*Blocks `thrift:"elements,1,required" db:"elements" json:"elements"`
+	Compression *string   `thrift:"compression,2" db:"compression" json:"compression,omitempty"`
 }
 
 func NewFetchBlocksRawResult_() *FetchBlocksRawResult_ {
@@ -4897,6 +5326,19 @@ func NewFetchBlocksRawResult_() *FetchBlocksRawResult_ {
 func (p *FetchBlocksRawResult_) GetElements() []*Blocks {
 	return p.Elements
 }
+
+var FetchBlocksRawResult__Compression_DEFAULT string
+
+func (p *FetchBlocksRawResult_) GetCompression() string {
+	if !p.IsSetCompression() {
+		return FetchBlocksRawResult__Compression_DEFAULT
+	}
+	return *p.Compression
+}
+func (p *FetchBlocksRawResult_) IsSetCompression() bool {
+	return p.Compression != nil
+}
+
 func (p *FetchBlocksRawResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -4918,6 +5360,10 @@ func (p *FetchBlocksRawResult_) Read(iprot thrift.TProtocol) error {
 				return err
 			}
 			issetElements = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4956,6 +5402,15 @@ func (p *FetchBlocksRawResult_) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchBlocksRawResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Compression = &v
+	}
+	return nil
+}
+
 func (p *FetchBlocksRawResult_) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchBlocksRawResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -4964,6 +5419,9 @@ func (p *FetchBlocksRawResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -4995,6 +5453,21 @@ func (p *FetchBlocksRawResult_) writeField1(oprot thrift.TProtocol) (err error)
 	return err
 }
 
+func (p *FetchBlocksRawResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetCompression() {
+		if err := oprot.WriteFieldBegin("compression", thrift.STRING, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:compression: ", p), err)
+		}
+		if err := oprot.WriteString(string(*p.Compression)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.compression (2) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:compression: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchBlocksRawResult_) String() string {
 	if p == nil {
 		return "<nil>"
@@ -5003,8 +5476,8 @@ func (p *FetchBlocksRawResult_) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - Blocks
+//   - ID
+//   - Blocks
 type Blocks struct {
 	ID     []byte   `thrift:"id,1,required" db:"id" json:"id"`
 	Blocks []*Block `thrift:"blocks,2,required" db:"blocks" json:"blocks"`
@@ -5161,10 +5634,10 @@ func (p *Blocks) String() string {
 }
 
 // Attributes:
-//  - Start
-//  - Segments
-//  - Err
-//  - Checksum
+//   - Start
+//   - Segments
+//   - Err
+//   - Checksum
 type Block struct {
 	Start    int64     `thrift:"start,1,required" db:"start" json:"start"`
 	Segments *Segments `thrift:"segments,2" db:"segments" json:"segments,omitempty"`
@@ -5398,8 +5871,8 @@ func (p *Block) String() string {
 }
 
 // Attributes:
-//  - Name
-//  - Value
+//   - Name
+//   - Value
 type Tag struct {
 	Name  string `thrift:"name,1,required" db:"name" json:"name"`
 	Value string `thrift:"value,2,required" db:"value" json:"value"`
@@ -5537,15 +6010,15 @@ func (p *Tag) String() string {
 }
 
 // Attributes:
-//  - NameSpace
-//  - Shard
-//  - RangeStart
-//  - RangeEnd
-//  - Limit
-//  - PageToken
-//  - IncludeSizes
-//  - IncludeChecksums
-//  - IncludeLastRead
+//   - NameSpace
+//   - Shard
+//   - RangeStart
+//   - RangeEnd
+//   - Limit
+//   - PageToken
+//   - IncludeSizes
+//   - IncludeChecksums
+//   - IncludeLastRead
 type FetchBlocksMetadataRawV2Request struct {
 	NameSpace        []byte `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 	Shard            int32  `thrift:"shard,2,required" db:"shard" json:"shard"`
@@ -5977,8 +6450,8 @@ func (p *FetchBlocksMetadataRawV2Request) String() string {
 }
 
 // Attributes:
-//  - Elements
-//  - NextPageToken
+//   - Elements
+//   - NextPageToken
 type FetchBlocksMetadataRawV2Result_ struct {
 	Elements      []*BlockMetadataV2 `thrift:"elements,1,required" db:"elements" json:"elements"`
 	NextPageToken []byte             `thrift:"nextPageToken,2" db:"nextPageToken" json:"nextPageToken,omitempty"`
@@ -6140,14 +6613,14 @@ func (p *FetchBlocksMetadataRawV2Result_) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - Start
-//  - Err
-//  - Size
-//  - Checksum
-//  - LastRead
-//  - LastReadTimeType
-//  - EncodedTags
+//   - ID
+//   - Start
+//   - Err
+//   - Size
+//   - Checksum
+//   - LastRead
+//   - LastReadTimeType
+//   - EncodedTags
 type BlockMetadataV2 struct {
 	ID               []byte   `thrift:"id,1,required" db:"id" json:"id"`
 	Start            int64    `thrift:"start,2,required" db:"start" json:"start"`
@@ -6553,8 +7026,8 @@ func (p *BlockMetadataV2) String() string {
 }
 
 // Attributes:
-//  - NameSpace
-//  - Elements
+//   - NameSpace
+//   - Elements
 type WriteBatchRawRequest struct {
 	NameSpace []byte                         `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 	Elements  []*WriteBatchRawRequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
@@ -6711,8 +7184,8 @@ func (p *WriteBatchRawRequest) String() string {
 }
 
 // Attributes:
-//  - NameSpaces
-//  - Elements
+//   - NameSpaces
+//   - Elements
 type WriteBatchRawV2Request struct {
 	NameSpaces [][]byte                         `thrift:"nameSpaces,1,required" db:"nameSpaces" json:"nameSpaces"`
 	Elements   []*WriteBatchRawV2RequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
@@ -6890,8 +7363,8 @@ func (p *WriteBatchRawV2Request) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - Datapoint
+//   - ID
+//   - Datapoint
 type WriteBatchRawRequestElement struct {
 	ID        []byte     `thrift:"id,1,required" db:"id" json:"id"`
 	Datapoint *Datapoint `thrift:"datapoint,2,required" db:"datapoint" json:"datapoint"`
@@ -7039,9 +7512,9 @@ func (p *WriteBatchRawRequestElement) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - Datapoint
-//  - NameSpace
+//   - ID
+//   - Datapoint
+//   - NameSpace
 type WriteBatchRawV2RequestElement struct {
 	ID        []byte     `thrift:"id,1,required" db:"id" json:"id"`
 	Datapoint *Datapoint `thrift:"datapoint,2,required" db:"datapoint" json:"datapoint"`
@@ -7228,8 +7701,8 @@ func (p *WriteBatchRawV2RequestElement) String() string {
 }
 
 // Attributes:
-//  - NameSpace
-//  - Elements
+//   - NameSpace
+//   - Elements
 type WriteTaggedBatchRawRequest struct {
 	NameSpace []byte                               `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 	Elements  []*WriteTaggedBatchRawRequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
@@ -7386,8 +7859,8 @@ func (p *WriteTaggedBatchRawRequest) String() string {
 }
 
 // Attributes:
-//  - NameSpaces
-//  - Elements
+//   - NameSpaces
+//   - Elements
 type WriteTaggedBatchRawV2Request struct {
 	NameSpaces [][]byte                               `thrift:"nameSpaces,1,required" db:"nameSpaces" json:"nameSpaces"`
 	Elements   []*WriteTaggedBatchRawV2RequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
@@ -7565,13 +8038,15 @@ func (p *WriteTaggedBatchRawV2Request) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - EncodedTags
-//  - Datapoint
+//   - ID
+//   - EncodedTags
+//   - Datapoint
+//   - ShardID
 type WriteTaggedBatchRawRequestElement struct {
 	ID          []byte     `thrift:"id,1,required" db:"id" json:"id"`
 	EncodedTags []byte     `thrift:"encodedTags,2,required" db:"encodedTags" json:"encodedTags"`
 	Datapoint   *Datapoint `thrift:"datapoint,3,required" db:"datapoint" json:"datapoint"`
+	ShardID     *int64     `thrift:"shardID,4" db:"shardID" json:"shardID,omitempty"`
 }
 
 func NewWriteTaggedBatchRawRequestElement() *WriteTaggedBatchRawRequestElement {
@@ -7598,6 +8073,19 @@ func (p *WriteTaggedBatchRawRequestElement) IsSetDatapoint() bool {
 	return p.Datapoint != nil
 }
 
+var WriteTaggedBatchRawRequestElement_ShardID_DEFAULT int64
+
+func (p *WriteTaggedBatchRawRequestElement) GetShardID() int64 {
+	if !p.IsSetShardID() {
+		return WriteTaggedBatchRawRequestElement_ShardID_DEFAULT
+	}
+	return *p.ShardID
+}
+
+func (p *WriteTaggedBatchRawRequestElement) IsSetShardID() bool {
+	return p.ShardID != nil
+}
+
 func (p *WriteTaggedBatchRawRequestElement) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -7631,6 +8119,10 @@ func (p *WriteTaggedBatchRawRequestElement) Read(iprot thrift.TProtocol) error {
 				return err
 			}
 			issetDatapoint = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -7683,6 +8175,15 @@ func (p *WriteTaggedBatchRawRequestElement) ReadField3(iprot thrift.TProtocol) e
 	return nil
 }
 
+func (p *WriteTaggedBatchRawRequestElement) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.ShardID = &v
+	}
+	return nil
+}
+
 func (p *WriteTaggedBatchRawRequestElement) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("WriteTaggedBatchRawRequestElement"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -7697,6 +8198,9 @@ func (p *WriteTaggedBatchRawRequestElement) Write(oprot thrift.TProtocol) error
 		if err := p.writeField3(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -7746,6 +8250,21 @@ func (p *WriteTaggedBatchRawRequestElement) writeField3(oprot thrift.TProtocol)
 	return err
 }
 
+func (p *WriteTaggedBatchRawRequestElement) writeField4(oprot thrift.TProtocol) (err error) {
+	if p.IsSetShardID() {
+		if err := oprot.WriteFieldBegin("shardID", thrift.I64, 4); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:shardID: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.ShardID)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.shardID (4) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:shardID: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *WriteTaggedBatchRawRequestElement) String() string {
 	if p == nil {
 		return "<nil>"
@@ -7754,15 +8273,17 @@ func (p *WriteTaggedBatchRawRequestElement) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - EncodedTags
-//  - Datapoint
-//  - NameSpace
+//   - ID
+//   - EncodedTags
+//   - Datapoint
+//   - NameSpace
+//   - ShardID
 type WriteTaggedBatchRawV2RequestElement struct {
 	ID          []byte     `thrift:"id,1,required" db:"id" json:"id"`
 	EncodedTags []byte     `thrift:"encodedTags,2,required" db:"encodedTags" json:"encodedTags"`
 	Datapoint   *Datapoint `thrift:"datapoint,3,required" db:"datapoint" json:"datapoint"`
 	NameSpace   int64      `thrift:"nameSpace,4,required" db:"nameSpace" json:"nameSpace"`
+	ShardID     *int64     `thrift:"shardID,5" db:"shardID" json:"shardID,omitempty"`
 }
 
 func NewWriteTaggedBatchRawV2RequestElement() *WriteTaggedBatchRawV2RequestElement {
@@ -7793,6 +8314,19 @@ func (p *WriteTaggedBatchRawV2RequestElement) IsSetDatapoint() bool {
 	return p.Datapoint != nil
 }
 
+var WriteTaggedBatchRawV2RequestElement_ShardID_DEFAULT int64
+
+func (p *WriteTaggedBatchRawV2RequestElement) GetShardID() int64 {
+	if !p.IsSetShardID() {
+		return WriteTaggedBatchRawV2RequestElement_ShardID_DEFAULT
+	}
+	return *p.ShardID
+}
+
+func (p *WriteTaggedBatchRawV2RequestElement) IsSetShardID() bool {
+	return p.ShardID != nil
+}
+
 func (p *WriteTaggedBatchRawV2RequestElement) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -7832,6 +8366,10 @@ func (p *WriteTaggedBatchRawV2RequestElement) Read(iprot thrift.TProtocol) error
 				return err
 			}
 			issetNameSpace = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -7896,6 +8434,15 @@ func (p *WriteTaggedBatchRawV2RequestElement) ReadField4(iprot thrift.TProtocol)
 	return nil
 }
 
+func (p *WriteTaggedBatchRawV2RequestElement) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.ShardID = &v
+	}
+	return nil
+}
+
 func (p *WriteTaggedBatchRawV2RequestElement) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("WriteTaggedBatchRawV2RequestElement"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -7913,6 +8460,9 @@ func (p *WriteTaggedBatchRawV2RequestElement) Write(oprot thrift.TProtocol) erro
 		if err := p.writeField4(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -7975,6 +8525,21 @@ func (p *WriteTaggedBatchRawV2RequestElement) writeField4(oprot thrift.TProtocol
 	return err
 }
 
+func (p *WriteTaggedBatchRawV2RequestElement) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetShardID() {
+		if err := oprot.WriteFieldBegin("shardID", thrift.I64, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:shardID: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.ShardID)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.shardID (5) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:shardID: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *WriteTaggedBatchRawV2RequestElement) String() string {
 	if p == nil {
 		return "<nil>"
@@ -7983,8 +8548,8 @@ func (p *WriteTaggedBatchRawV2RequestElement) String() string {
 }
 
 // Attributes:
-//  - Index
-//  - Err
+//   - Index
+//   - Err
 type WriteBatchRawError struct {
 	Index int64  `thrift:"index,1,required" db:"index" json:"index"`
 	Err   *Error `thrift:"err,2,required" db:"err" json:"err"`
@@ -8132,7 +8697,7 @@ func (p *WriteBatchRawError) String() string {
 }
 
 // Attributes:
-//  - NameSpace
+//   - NameSpace
 type TruncateRequest struct {
 	NameSpace []byte `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 }
@@ -8231,7 +8796,7 @@ func (p *TruncateRequest) String() string {
 }
 
 // Attributes:
-//  - NumSeries
+//   - NumSeries
 type TruncateResult_ struct {
 	NumSeries int64 `thrift:"numSeries,1,required" db:"numSeries" json:"numSeries"`
 }
@@ -8330,10 +8895,10 @@ func (p *TruncateResult_) String() string {
 }
 
 // Attributes:
-//  - Ok
-//  - Status
-//  - Bootstrapped
-//  - Metadata
+//   - Ok
+//   - Status
+//   - Bootstrapped
+//   - Metadata
 type NodeHealthResult_ struct {
 	Ok           bool              `thrift:"ok,1,required" db:"ok" json:"ok"`
 	Status       string            `thrift:"status,2,required" db:"status" json:"status"`
@@ -8692,9 +9257,9 @@ func (p *NodeBootstrappedInPlacementOrNoPlacementResult_) String() string {
 }
 
 // Attributes:
-//  - LimitEnabled
-//  - LimitMbps
-//  - LimitCheckEvery
+//   - LimitEnabled
+//   - LimitMbps
+//   - LimitCheckEvery
 type NodePersistRateLimitResult_ struct {
 	LimitEnabled    bool    `thrift:"limitEnabled,1,required" db:"limitEnabled" json:"limitEnabled"`
 	LimitMbps       float64 `thrift:"limitMbps,2,required" db:"limitMbps" json:"limitMbps"`
@@ -8871,9 +9436,9 @@ func (p *NodePersistRateLimitResult_) String() string {
 }
 
 // Attributes:
-//  - LimitEnabled
-//  - LimitMbps
-//  - LimitCheckEvery
+//   - LimitEnabled
+//   - LimitMbps
+//   - LimitCheckEvery
 type NodeSetPersistRateLimitRequest struct {
 	LimitEnabled    *bool    `thrift:"limitEnabled,1" db:"limitEnabled" json:"limitEnabled,omitempty"`
 	LimitMbps       *float64 `thrift:"limitMbps,2" db:"limitMbps" json:"limitMbps,omitempty"`
@@ -9067,7 +9632,7 @@ func (p *NodeSetPersistRateLimitRequest) String() string {
 }
 
 // Attributes:
-//  - WriteNewSeriesAsync
+//   - WriteNewSeriesAsync
 type NodeWriteNewSeriesAsyncResult_ struct {
 	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
 }
@@ -9166,7 +9731,7 @@ func (p *NodeWriteNewSeriesAsyncResult_) String() string {
 }
 
 // Attributes:
-//  - WriteNewSeriesAsync
+//   - WriteNewSeriesAsync
 type NodeSetWriteNewSeriesAsyncRequest struct {
 	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
 }
@@ -9265,8 +9830,8 @@ func (p *NodeSetWriteNewSeriesAsyncRequest) String() string {
 }
 
 // Attributes:
-//  - WriteNewSeriesBackoffDuration
-//  - DurationType
+//   - WriteNewSeriesBackoffDuration
+//   - DurationType
 type NodeWriteNewSeriesBackoffDurationResult_ struct {
 	WriteNewSeriesBackoffDuration int64    `thrift:"writeNewSeriesBackoffDuration,1,required" db:"writeNewSeriesBackoffDuration" json:"writeNewSeriesBackoffDuration"`
 	DurationType                  TimeType `thrift:"durationType,2,required" db:"durationType" json:"durationType"`
@@ -9405,8 +9970,8 @@ func (p *NodeWriteNewSeriesBackoffDurationResult_) String() string {
 }
 
 // Attributes:
-//  - WriteNewSeriesBackoffDuration
-//  - DurationType
+//   - WriteNewSeriesBackoffDuration
+//   - DurationType
 type NodeSetWriteNewSeriesBackoffDurationRequest struct {
 	WriteNewSeriesBackoffDuration int64    `thrift:"writeNewSeriesBackoffDuration,1,required" db:"writeNewSeriesBackoffDuration" json:"writeNewSeriesBackoffDuration"`
 	DurationType                  TimeType `thrift:"durationType,2" db:"durationType" json:"durationType,omitempty"`
@@ -9550,7 +10115,7 @@ func (p *NodeSetWriteNewSeriesBackoffDurationRequest) String() string {
 }
 
 // Attributes:
-//  - WriteNewSeriesLimitPerShardPerSecond
+//   - WriteNewSeriesLimitPerShardPerSecond
 type NodeWriteNewSeriesLimitPerShardPerSecondResult_ struct {
 	WriteNewSeriesLimitPerShardPerSecond int64 `thrift:"writeNewSeriesLimitPerShardPerSecond,1,required" db:"writeNewSeriesLimitPerShardPerSecond" json:"writeNewSeriesLimitPerShardPerSecond"`
 }
@@ -9649,7 +10214,7 @@ func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) String() string {
 }
 
 // Attributes:
-//  - WriteNewSeriesLimitPerShardPerSecond
+//   - WriteNewSeriesLimitPerShardPerSecond
 type NodeSetWriteNewSeriesLimitPerShardPerSecondRequest struct {
 	WriteNewSeriesLimitPerShardPerSecond int64 `thrift:"writeNewSeriesLimitPerShardPerSecond,1,required" db:"writeNewSeriesLimitPerShardPerSecond" json:"writeNewSeriesLimitPerShardPerSecond"`
 }
@@ -9748,8 +10313,8 @@ func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) String() string {
 }
 
 // Attributes:
-//  - Ok
-//  - Status
+//   - Ok
+//   - Status
 type HealthResult_ struct {
 	Ok     bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
 	Status string `thrift:"status,2,required" db:"status" json:"status"`
@@ -9887,18 +10452,18 @@ func (p *HealthResult_) String() string {
 }
 
 // Attributes:
-//  - Query
-//  - RangeStart
-//  - RangeEnd
-//  - NameSpace
-//  - SeriesLimit
-//  - TagNameFilter
-//  - AggregateQueryType
-//  - RangeType
-//  - Source
-//  - DocsLimit
-//  - RequireExhaustive
-//  - RequireNoWait
+//   - Query
+//   - RangeStart
+//   - RangeEnd
+//   - NameSpace
+//   - SeriesLimit
+//   - TagNameFilter
+//   - AggregateQueryType
+//   - RangeType
+//   - Source
+//   - DocsLimit
+//   - RequireExhaustive
+//   - RequireNoWait
 type AggregateQueryRawRequest struct {
 	Query              []byte             `thrift:"query,1,required" db:"query" json:"query"`
 	RangeStart         int64              `thrift:"rangeStart,2,required" db:"rangeStart" json:"rangeStart"`
@@ -10489,9 +11054,9 @@ func (p *AggregateQueryRawRequest) String() string {
 }
 
 // Attributes:
-//  - Results
-//  - Exhaustive
-//  - WaitedIndex
+//   - Results
+//   - Exhaustive
+//   - WaitedIndex
 type AggregateQueryRawResult_ struct {
 	Results     []*AggregateQueryRawResultTagNameElement `thrift:"results,1,required" db:"results" json:"results"`
 	Exhaustive  bool                                     `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
@@ -10693,8 +11258,8 @@ func (p *AggregateQueryRawResult_) String() string {
 }
 
 // Attributes:
-//  - TagName
-//  - TagValues
+//   - TagName
+//   - TagValues
 type AggregateQueryRawResultTagNameElement struct {
 	TagName   []byte                                    `thrift:"tagName,1,required" db:"tagName" json:"tagName"`
 	TagValues []*AggregateQueryRawResultTagValueElement `thrift:"tagValues,2" db:"tagValues" json:"tagValues,omitempty"`
@@ -10854,7 +11419,7 @@ func (p *AggregateQueryRawResultTagNameElement) String() string {
 }
 
 // Attributes:
-//  - TagValue
+//   - TagValue
 type AggregateQueryRawResultTagValueElement struct {
 	TagValue []byte `thrift:"tagValue,1,required" db:"tagValue" json:"tagValue"`
 }
@@ -10953,18 +11518,18 @@ func (p *AggregateQueryRawResultTagValueElement) String() string {
 }
 
 // Attributes:
-//  - Query
-//  - RangeStart
-//  - RangeEnd
-//  - NameSpace
-//  - SeriesLimit
-//  - TagNameFilter
-//  - AggregateQueryType
-//  - RangeType
-//  - Source
-//  - DocsLimit
-//  - RequireExhaustive
-//  - RequireNoWait
+//   - Query
+//   - RangeStart
+//   - RangeEnd
+//   - NameSpace
+//   - SeriesLimit
+//   - TagNameFilter
+//   - AggregateQueryType
+//   - RangeType
+//   - Source
+//   - DocsLimit
+//   - RequireExhaustive
+//   - RequireNoWait
 type AggregateQueryRequest struct {
 	Query              *Query             `thrift:"query,1" db:"query" json:"query,omitempty"`
 	RangeStart         int64              `thrift:"rangeStart,2,required" db:"rangeStart" json:"rangeStart"`
@@ -11560,8 +12125,8 @@ func (p *AggregateQueryRequest) String() string {
 }
 
 // Attributes:
-//  - Results
-//  - Exhaustive
+//   - Results
+//   - Exhaustive
 type AggregateQueryResult_ struct {
 	Results    []*AggregateQueryResultTagNameElement `thrift:"results,1,required" db:"results" json:"results"`
 	Exhaustive bool                                  `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
@@ -11718,8 +12283,8 @@ func (p *AggregateQueryResult_) String() string {
 }
 
 // Attributes:
-//  - TagName
-//  - TagValues
+//   - TagName
+//   - TagValues
 type AggregateQueryResultTagNameElement struct {
 	TagName   string                                 `thrift:"tagName,1,required" db:"tagName" json:"tagName"`
 	TagValues []*AggregateQueryResultTagValueElement `thrift:"tagValues,2" db:"tagValues" json:"tagValues,omitempty"`
@@ -11879,7 +12444,7 @@ func (p *AggregateQueryResultTagNameElement) String() string {
 }
 
 // Attributes:
-//  - TagValue
+//   - TagValue
 type AggregateQueryResultTagValueElement struct {
 	TagValue string `thrift:"tagValue,1,required" db:"tagValue" json:"tagValue"`
 }
@@ -11978,16 +12543,16 @@ func (p *AggregateQueryResultTagValueElement) String() string {
 }
 
 // Attributes:
-//  - Query
-//  - RangeStart
-//  - RangeEnd
-//  - NameSpace
-//  - Limit
-//  - NoData
-//  - RangeType
-//  - ResultTimeType
-//  - Source
-//  - ClusterOptions
+//   - Query
+//   - RangeStart
+//   - RangeEnd
+//   - NameSpace
+//   - Limit
+//   - NoData
+//   - RangeType
+//   - ResultTimeType
+//   - Source
+//   - ClusterOptions
 type QueryRequest struct {
 	Query          *Query               `thrift:"query,1,required" db:"query" json:"query"`
 	RangeStart     int64                `thrift:"rangeStart,2,required" db:"rangeStart" json:"rangeStart"`
@@ -12477,8 +13042,8 @@ func (p *QueryRequest) String() string {
 }
 
 // Attributes:
-//  - ReadConsistency
-//  - ConflictResolutionStrategy
+//   - ReadConsistency
+//   - ConflictResolutionStrategy
 type ClusterQueryOptions struct {
 	ReadConsistency            *ReadConsistency        `thrift:"readConsistency,1" db:"readConsistency" json:"readConsistency,omitempty"`
 	ConflictResolutionStrategy *EqualTimestampStrategy `thrift:"conflictResolutionStrategy,2" db:"conflictResolutionStrategy" json:"conflictResolutionStrategy,omitempty"`
@@ -12629,8 +13194,8 @@ func (p *ClusterQueryOptions) String() string {
 }
 
 // Attributes:
-//  - Results
-//  - Exhaustive
+//   - Results
+//   - Exhaustive
 type QueryResult_ struct {
 	Results    []*QueryResultElement `thrift:"results,1,required" db:"results" json:"results"`
 	Exhaustive bool                  `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
@@ -12787,9 +13352,9 @@ func (p *QueryResult_) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - Tags
-//  - Datapoints
+//   - ID
+//   - Tags
+//   - Datapoints
 type QueryResultElement struct {
 	ID         string       `thrift:"id,1,required" db:"id" json:"id"`
 	Tags       []*Tag       `thrift:"tags,2,required" db:"tags" json:"tags"`
@@ -13006,8 +13571,8 @@ func (p *QueryResultElement) String() string {
 }
 
 // Attributes:
-//  - Field
-//  - Term
+//   - Field
+//   - Term
 type TermQuery struct {
 	Field string `thrift:"field,1,required" db:"field" json:"field"`
 	Term  string `thrift:"term,2,required" db:"term" json:"term"`
@@ -13145,8 +13710,8 @@ func (p *TermQuery) String() string {
 }
 
 // Attributes:
-//  - Field
-//  - Regexp
+//   - Field
+//   - Regexp
 type RegexpQuery struct {
 	Field  string `thrift:"field,1,required" db:"field" json:"field"`
 	Regexp string `thrift:"regexp,2,required" db:"regexp" json:"regexp"`
@@ -13284,7 +13849,7 @@ func (p *RegexpQuery) String() string {
 }
 
 // Attributes:
-//  - Query
+//   - Query
 type NegationQuery struct {
 	Query *Query `thrift:"query,1,required" db:"query" json:"query"`
 }
@@ -13391,7 +13956,7 @@ func (p *NegationQuery) String() string {
 }
 
 // Attributes:
-//  - Queries
+//   - Queries
 type ConjunctionQuery struct {
 	Queries []*Query `thrift:"queries,1,required" db:"queries" json:"queries"`
 }
@@ -13509,7 +14074,7 @@ func (p *ConjunctionQuery) String() string {
 }
 
 // Attributes:
-//  - Queries
+//   - Queries
 type DisjunctionQuery struct {
 	Queries []*Query `thrift:"queries,1,required" db:"queries" json:"queries"`
 }
@@ -13682,7 +14247,7 @@ func (p *AllQuery) String() string {
 }
 
 // Attributes:
-//  - Field
+//   - Field
 type FieldQuery struct {
 	Field string `thrift:"field,1,required" db:"field" json:"field"`
 }
@@ -13781,13 +14346,13 @@ func (p *FieldQuery) String() string {
 }
 
 // Attributes:
-//  - Term
-//  - Regexp
-//  - Negation
-//  - Conjunction
-//  - Disjunction
-//  - All
-//  - Field
+//   - Term
+//   - Regexp
+//   - Negation
+//   - Conjunction
+//   - Disjunction
+//   - All
+//   - Field
 type Query struct {
 	Term        *TermQuery        `thrift:"term,1" db:"term" json:"term,omitempty"`
 	Regexp      *RegexpQuery      `thrift:"regexp,2" db:"regexp" json:"regexp,omitempty"`
@@ -14154,12 +14719,12 @@ func (p *Query) String() string {
 }
 
 // Attributes:
-//  - SourceNamespace
-//  - TargetNamespace
-//  - RangeStart
-//  - RangeEnd
-//  - Step
-//  - RangeType
+//   - SourceNamespace
+//   - TargetNamespace
+//   - RangeStart
+//   - RangeEnd
+//   - Step
+//   - RangeType
 type AggregateTilesRequest struct {
 	SourceNamespace string   `thrift:"sourceNamespace,1,required" db:"sourceNamespace" json:"sourceNamespace"`
 	TargetNamespace string   `thrift:"targetNamespace,2,required" db:"targetNamespace" json:"targetNamespace"`
@@ -14459,7 +15024,7 @@ func (p *AggregateTilesRequest) String() string {
 }
 
 // Attributes:
-//  - ProcessedTileCount
+//   - ProcessedTileCount
 type AggregateTilesResult_ struct {
 	ProcessedTileCount int64 `thrift:"processedTileCount,1,required" db:"processedTileCount" json:"processedTileCount"`
 }
@@ -14558,14 +15123,14 @@ func (p *AggregateTilesResult_) String() string {
 }
 
 // Attributes:
-//  - Name
-//  - FilePathTemplate
-//  - Interval
-//  - Duration
-//  - Debug
-//  - ConditionalNumGoroutinesGreaterThan
-//  - ConditionalNumGoroutinesLessThan
-//  - ConditionalIsOverloaded
+//   - Name
+//   - FilePathTemplate
+//   - Interval
+//   - Duration
+//   - Debug
+//   - ConditionalNumGoroutinesGreaterThan
+//   - ConditionalNumGoroutinesLessThan
+//   - ConditionalIsOverloaded
 type DebugProfileStartRequest struct {
 	Name                                string  `thrift:"name,1,required" db:"name" json:"name"`
 	FilePathTemplate                    string  `thrift:"filePathTemplate,2,required" db:"filePathTemplate" json:"filePathTemplate"`
@@ -15028,7 +15593,7 @@ func (p *DebugProfileStartResult_) String() string {
 }
 
 // Attributes:
-//  - Name
+//   - Name
 type DebugProfileStopRequest struct {
 	Name string `thrift:"name,1,required" db:"name" json:"name"`
 }
@@ -15182,7 +15747,7 @@ func (p *DebugProfileStopResult_) String() string {
 }
 
 // Attributes:
-//  - Directory
+//   - Directory
 type DebugIndexMemorySegmentsRequest struct {
 	Directory string `thrift:"directory,1,required" db:"directory" json:"directory"`
 }
@@ -15445,7 +16010,7 @@ func NewNodeClientProtocol(t thrift.TTransport, iprot thrift.TProtocol, oprot th
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) Query(req *QueryRequest) (r *QueryResult_, err error) {
 	if err = p.sendQuery(req); err != nil {
 		return
@@ -15526,7 +16091,7 @@ func (p *NodeClient) recvQuery() (value *QueryResult_, err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) Aggregate(req *AggregateQueryRequest) (r *AggregateQueryResult_, err error) {
 	if err = p.sendAggregate(req); err != nil {
 		return
@@ -15607,7 +16172,7 @@ func (p *NodeClient) recvAggregate() (value *AggregateQueryResult_, err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) Fetch(req *FetchRequest) (r *FetchResult_, err error) {
 	if err = p.sendFetch(req); err != nil {
 		return
@@ -15688,7 +16253,7 @@ func (p *NodeClient) recvFetch() (value *FetchResult_, err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) Write(req *WriteRequest) (err error) {
 	if err = p.sendWrite(req); err != nil {
 		return
@@ -15768,7 +16333,7 @@ func (p *NodeClient) recvWrite() (err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) WriteTagged(req *WriteTaggedRequest) (err error) {
 	if err = p.sendWriteTagged(req); err != nil {
 		return
@@ -15848,7 +16413,7 @@ func (p *NodeClient) recvWriteTagged() (err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) AggregateRaw(req *AggregateQueryRawRequest) (r *AggregateQueryRawResult_, err error) {
 	if err = p.sendAggregateRaw(req); err != nil {
 		return
@@ -15929,7 +16494,7 @@ func (p *NodeClient) recvAggregateRaw() (value *AggregateQueryRawResult_, err er
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error) {
 	if err = p.sendFetchBatchRaw(req); err != nil {
 		return
@@ -16010,7 +16575,7 @@ func (p *NodeClient) recvFetchBatchRaw() (value *FetchBatchRawResult_, err error
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) FetchBatchRawV2(req *FetchBatchRawV2Request) (r *FetchBatchRawResult_, err error) {
 	if err = p.sendFetchBatchRawV2(req); err != nil {
 		return
@@ -16091,7 +16656,7 @@ func (p *NodeClient) recvFetchBatchRawV2() (value *FetchBatchRawResult_, err err
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error) {
 	if err = p.sendFetchBlocksRaw(req); err != nil {
 		return
@@ -16172,7 +16737,7 @@ func (p *NodeClient) recvFetchBlocksRaw() (value *FetchBlocksRawResult_, err err
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error) {
 	if err = p.sendFetchTagged(req); err != nil {
 		return
@@ -16253,7 +16818,7 @@ func (p *NodeClient) recvFetchTagged() (value *FetchTaggedResult_, err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) FetchBlocksMetadataRawV2(req *FetchBlocksMetadataRawV2Request) (r *FetchBlocksMetadataRawV2Result_, err error) {
 	if err = p.sendFetchBlocksMetadataRawV2(req); err != nil {
 		return
@@ -16334,7 +16899,7 @@ func (p *NodeClient) recvFetchBlocksMetadataRawV2() (value *FetchBlocksMetadataR
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) WriteBatchRaw(req *WriteBatchRawRequest) (err error) {
 	if err = p.sendWriteBatchRaw(req); err != nil {
 		return
@@ -16414,7 +16979,7 @@ func (p *NodeClient) recvWriteBatchRaw() (err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) WriteBatchRawV2(req *WriteBatchRawV2Request) (err error) {
 	if err = p.sendWriteBatchRawV2(req); err != nil {
 		return
@@ -16494,7 +17059,7 @@ func (p *NodeClient) recvWriteBatchRawV2() (err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) WriteTaggedBatchRaw(req *WriteTaggedBatchRawRequest) (err error) {
 	if err = p.sendWriteTaggedBatchRaw(req); err != nil {
 		return
@@ -16574,7 +17139,7 @@ func (p *NodeClient) recvWriteTaggedBatchRaw() (err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) WriteTaggedBatchRawV2(req *WriteTaggedBatchRawV2Request) (err error) {
 	if err = p.sendWriteTaggedBatchRawV2(req); err != nil {
 		return
@@ -16730,7 +17295,7 @@ func (p *NodeClient) recvRepair() (err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) Truncate(req *TruncateRequest) (r *TruncateResult_, err error) {
 	if err = p.sendTruncate(req); err != nil {
 		return
@@ -16811,7 +17376,7 @@ func (p *NodeClient) recvTruncate() (value *TruncateResult_, err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) AggregateTiles(req *AggregateTilesRequest) (r *AggregateTilesResult_, err error) {
 	if err = p.sendAggregateTiles(req); err != nil {
 		return
@@ -17200,7 +17765,7 @@ func (p *NodeClient) recvGetPersistRateLimit() (value *NodePersistRateLimitResul
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error) {
 	if err = p.sendSetPersistRateLimit(req); err != nil {
 		return
@@ -17358,7 +17923,7 @@ func (p *NodeClient) recvGetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyn
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error) {
 	if err = p.sendSetWriteNewSeriesAsync(req); err != nil {
 		return
@@ -17516,7 +18081,7 @@ func (p *NodeClient) recvGetWriteNewSeriesBackoffDuration() (value *NodeWriteNew
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) SetWriteNewSeriesBackoffDuration(req *NodeSetWriteNewSeriesBackoffDurationRequest) (r *NodeWriteNewSeriesBackoffDurationResult_, err error) {
 	if err = p.sendSetWriteNewSeriesBackoffDuration(req); err != nil {
 		return
@@ -17674,7 +18239,7 @@ func (p *NodeClient) recvGetWriteNewSeriesLimitPerShardPerSecond() (value *NodeW
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) SetWriteNewSeriesLimitPerShardPerSecond(req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
 	if err = p.sendSetWriteNewSeriesLimitPerShardPerSecond(req); err != nil {
 		return
@@ -17755,7 +18320,7 @@ func (p *NodeClient) recvSetWriteNewSeriesLimitPerShardPerSecond() (value *NodeW
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) DebugProfileStart(req *DebugProfileStartRequest) (r *DebugProfileStartResult_, err error) {
 	if err = p.sendDebugProfileStart(req); err != nil {
 		return
@@ -17836,7 +18401,7 @@ func (p *NodeClient) recvDebugProfileStart() (value *DebugProfileStartResult_, e
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) DebugProfileStop(req *DebugProfileStopRequest) (r *DebugProfileStopResult_, err error) {
 	if err = p.sendDebugProfileStop(req); err != nil {
 		return
@@ -17917,7 +18482,7 @@ func (p *NodeClient) recvDebugProfileStop() (value *DebugProfileStopResult_, err
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *NodeClient) DebugIndexMemorySegments(req *DebugIndexMemorySegmentsRequest) (r *DebugIndexMemorySegmentsResult_, err error) {
 	if err = p.sendDebugIndexMemorySegments(req); err != nil {
 		return
@@ -19750,7 +20315,7 @@ func (p *nodeProcessorDebugIndexMemorySegments) Process(seqId int32, iprot, opro
 // HELPER FUNCTIONS AND STRUCTURES
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeQueryArgs struct {
 	Req *QueryRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -19855,8 +20420,8 @@ func (p *NodeQueryArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeQueryResult struct {
 	Success *QueryResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -20005,7 +20570,7 @@ func (p *NodeQueryResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeAggregateArgs struct {
 	Req *AggregateQueryRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -20110,8 +20675,8 @@ func (p *NodeAggregateArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeAggregateResult struct {
 	Success *AggregateQueryResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                 `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -20260,7 +20825,7 @@ func (p *NodeAggregateResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeFetchArgs struct {
 	Req *FetchRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -20365,8 +20930,8 @@ func (p *NodeFetchArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeFetchResult struct {
 	Success *FetchResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -20515,7 +21080,7 @@ func (p *NodeFetchResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeWriteArgs struct {
 	Req *WriteRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -20616,7 +21181,7 @@ func (p *NodeWriteArgs) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type NodeWriteResult struct {
 	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -20721,7 +21286,7 @@ func (p *NodeWriteResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeWriteTaggedArgs struct {
 	Req *WriteTaggedRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -20822,7 +21387,7 @@ func (p *NodeWriteTaggedArgs) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type NodeWriteTaggedResult struct {
 	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -20927,7 +21492,7 @@ func (p *NodeWriteTaggedResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeAggregateRawArgs struct {
 	Req *AggregateQueryRawRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -21032,8 +21597,8 @@ func (p *NodeAggregateRawArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeAggregateRawResult struct {
 	Success *AggregateQueryRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                    `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -21182,7 +21747,7 @@ func (p *NodeAggregateRawResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeFetchBatchRawArgs struct {
 	Req *FetchBatchRawRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -21285,8 +21850,8 @@ func (p *NodeFetchBatchRawArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeFetchBatchRawResult struct {
 	Success *FetchBatchRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -21435,7 +22000,7 @@ func (p *NodeFetchBatchRawResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeFetchBatchRawV2Args struct {
 	Req *FetchBatchRawV2Request `thrift:"req,1" db:"req" json:"req"`
 }
@@ -21536,8 +22101,8 @@ func (p *NodeFetchBatchRawV2Args) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeFetchBatchRawV2Result struct {
 	Success *FetchBatchRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -21686,7 +22251,7 @@ func (p *NodeFetchBatchRawV2Result) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeFetchBlocksRawArgs struct {
 	Req *FetchBlocksRawRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -21787,8 +22352,8 @@ func (p *NodeFetchBlocksRawArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeFetchBlocksRawResult struct {
 	Success *FetchBlocksRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                 `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -21937,7 +22502,7 @@ func (p *NodeFetchBlocksRawResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeFetchTaggedArgs struct {
 	Req *FetchTaggedRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -22042,8 +22607,8 @@ func (p *NodeFetchTaggedArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeFetchTaggedResult struct {
 	Success *FetchTaggedResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error              `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -22192,7 +22757,7 @@ func (p *NodeFetchTaggedResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeFetchBlocksMetadataRawV2Args struct {
 	Req *FetchBlocksMetadataRawV2Request `thrift:"req,1" db:"req" json:"req"`
 }
@@ -22293,8 +22858,8 @@ func (p *NodeFetchBlocksMetadataRawV2Args) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeFetchBlocksMetadataRawV2Result struct {
 	Success *FetchBlocksMetadataRawV2Result_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                           `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -22443,7 +23008,7 @@ func (p *NodeFetchBlocksMetadataRawV2Result) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeWriteBatchRawArgs struct {
 	Req *WriteBatchRawRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -22544,7 +23109,7 @@ func (p *NodeWriteBatchRawArgs) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type NodeWriteBatchRawResult struct {
 	Err *WriteBatchRawErrors `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -22647,7 +23212,7 @@ func (p *NodeWriteBatchRawResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeWriteBatchRawV2Args struct {
 	Req *WriteBatchRawV2Request `thrift:"req,1" db:"req" json:"req"`
 }
@@ -22748,7 +23313,7 @@ func (p *NodeWriteBatchRawV2Args) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type NodeWriteBatchRawV2Result struct {
 	Err *WriteBatchRawErrors `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -22851,7 +23416,7 @@ func (p *NodeWriteBatchRawV2Result) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeWriteTaggedBatchRawArgs struct {
 	Req *WriteTaggedBatchRawRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -22952,7 +23517,7 @@ func (p *NodeWriteTaggedBatchRawArgs) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type NodeWriteTaggedBatchRawResult struct {
 	Err *WriteBatchRawErrors `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -23055,7 +23620,7 @@ func (p *NodeWriteTaggedBatchRawResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeWriteTaggedBatchRawV2Args struct {
 	Req *WriteTaggedBatchRawV2Request `thrift:"req,1" db:"req" json:"req"`
 }
@@ -23156,7 +23721,7 @@ func (p *NodeWriteTaggedBatchRawV2Args) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type NodeWriteTaggedBatchRawV2Result struct {
 	Err *WriteBatchRawErrors `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -23314,7 +23879,7 @@ func (p *NodeRepairArgs) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type NodeRepairResult struct {
 	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -23419,7 +23984,7 @@ func (p *NodeRepairResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeTruncateArgs struct {
 	Req *TruncateRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -23520,8 +24085,8 @@ func (p *NodeTruncateArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeTruncateResult struct {
 	Success *TruncateResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error           `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -23670,7 +24235,7 @@ func (p *NodeTruncateResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeAggregateTilesArgs struct {
 	Req *AggregateTilesRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -23773,8 +24338,8 @@ func (p *NodeAggregateTilesArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeAggregateTilesResult struct {
 	Success *AggregateTilesResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                 `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -23978,8 +24543,8 @@ func (p *NodeHealthArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeHealthResult struct {
 	Success *NodeHealthResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error             `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -24183,8 +24748,8 @@ func (p *NodeBootstrappedArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeBootstrappedResult struct {
 	Success *NodeBootstrappedResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                   `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -24388,8 +24953,8 @@ func (p *NodeBootstrappedInPlacementOrNoPlacementArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeBootstrappedInPlacementOrNoPlacementResult struct {
 	Success *NodeBootstrappedInPlacementOrNoPlacementResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                                           `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -24593,8 +25158,8 @@ func (p *NodeGetPersistRateLimitArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeGetPersistRateLimitResult struct {
 	Success *NodePersistRateLimitResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                       `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -24743,7 +25308,7 @@ func (p *NodeGetPersistRateLimitResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeSetPersistRateLimitArgs struct {
 	Req *NodeSetPersistRateLimitRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -24844,8 +25409,8 @@ func (p *NodeSetPersistRateLimitArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeSetPersistRateLimitResult struct {
 	Success *NodePersistRateLimitResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                       `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -25049,8 +25614,8 @@ func (p *NodeGetWriteNewSeriesAsyncArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeGetWriteNewSeriesAsyncResult struct {
 	Success *NodeWriteNewSeriesAsyncResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                          `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -25199,7 +25764,7 @@ func (p *NodeGetWriteNewSeriesAsyncResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeSetWriteNewSeriesAsyncArgs struct {
 	Req *NodeSetWriteNewSeriesAsyncRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -25300,8 +25865,8 @@ func (p *NodeSetWriteNewSeriesAsyncArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeSetWriteNewSeriesAsyncResult struct {
 	Success *NodeWriteNewSeriesAsyncResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                          `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -25505,8 +26070,8 @@ func (p *NodeGetWriteNewSeriesBackoffDurationArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeGetWriteNewSeriesBackoffDurationResult struct {
 	Success *NodeWriteNewSeriesBackoffDurationResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                                    `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -25655,7 +26220,7 @@ func (p *NodeGetWriteNewSeriesBackoffDurationResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeSetWriteNewSeriesBackoffDurationArgs struct {
 	Req *NodeSetWriteNewSeriesBackoffDurationRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -25758,8 +26323,8 @@ func (p *NodeSetWriteNewSeriesBackoffDurationArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeSetWriteNewSeriesBackoffDurationResult struct {
 	Success *NodeWriteNewSeriesBackoffDurationResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                                    `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -25963,8 +26528,8 @@ func (p *NodeGetWriteNewSeriesLimitPerShardPerSecondArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeGetWriteNewSeriesLimitPerShardPerSecondResult struct {
 	Success *NodeWriteNewSeriesLimitPerShardPerSecondResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                                           `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -26113,7 +26678,7 @@ func (p *NodeGetWriteNewSeriesLimitPerShardPerSecondResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeSetWriteNewSeriesLimitPerShardPerSecondArgs struct {
 	Req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -26214,8 +26779,8 @@ func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeSetWriteNewSeriesLimitPerShardPerSecondResult struct {
 	Success *NodeWriteNewSeriesLimitPerShardPerSecondResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                                           `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -26364,7 +26929,7 @@ func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeDebugProfileStartArgs struct {
 	Req *DebugProfileStartRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -26465,8 +27030,8 @@ func (p *NodeDebugProfileStartArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeDebugProfileStartResult struct {
 	Success *DebugProfileStartResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                    `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -26615,7 +27180,7 @@ func (p *NodeDebugProfileStartResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeDebugProfileStopArgs struct {
 	Req *DebugProfileStopRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -26716,8 +27281,8 @@ func (p *NodeDebugProfileStopArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeDebugProfileStopResult struct {
 	Success *DebugProfileStopResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                   `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -26866,7 +27431,7 @@ func (p *NodeDebugProfileStopResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type NodeDebugIndexMemorySegmentsArgs struct {
 	Req *DebugIndexMemorySegmentsRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -26967,8 +27532,8 @@ func (p *NodeDebugIndexMemorySegmentsArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type NodeDebugIndexMemorySegmentsResult struct {
 	Success *DebugIndexMemorySegmentsResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                           `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -27242,7 +27807,7 @@ func (p *ClusterClient) recvHealth() (value *HealthResult_, err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *ClusterClient) Write(req *WriteRequest) (err error) {
 	if err = p.sendWrite(req); err != nil {
 		return
@@ -27322,7 +27887,7 @@ func (p *ClusterClient) recvWrite() (err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *ClusterClient) WriteTagged(req *WriteTaggedRequest) (err error) {
 	if err = p.sendWriteTagged(req); err != nil {
 		return
@@ -27402,7 +27967,7 @@ func (p *ClusterClient) recvWriteTagged() (err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *ClusterClient) Query(req *QueryRequest) (r *QueryResult_, err error) {
 	if err = p.sendQuery(req); err != nil {
 		return
@@ -27483,7 +28048,7 @@ func (p *ClusterClient) recvQuery() (value *QueryResult_, err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *ClusterClient) Aggregate(req *AggregateQueryRequest) (r *AggregateQueryResult_, err error) {
 	if err = p.sendAggregate(req); err != nil {
 		return
@@ -27564,7 +28129,7 @@ func (p *ClusterClient) recvAggregate() (value *AggregateQueryResult_, err error
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *ClusterClient) Fetch(req *FetchRequest) (r *FetchResult_, err error) {
 	if err = p.sendFetch(req); err != nil {
 		return
@@ -27645,7 +28210,7 @@ func (p *ClusterClient) recvFetch() (value *FetchResult_, err error) {
 }
 
 // Parameters:
-//  - Req
+//   - Req
 func (p *ClusterClient) Truncate(req *TruncateRequest) (r *TruncateResult_, err error) {
 	if err = p.sendTruncate(req); err != nil {
 		return
@@ -28198,8 +28763,8 @@ func (p *ClusterHealthArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type ClusterHealthResult struct {
 	Success *HealthResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error         `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -28348,7 +28913,7 @@ func (p *ClusterHealthResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type ClusterWriteArgs struct {
 	Req *WriteRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -28449,7 +29014,7 @@ func (p *ClusterWriteArgs) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type ClusterWriteResult struct {
 	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -28554,7 +29119,7 @@ func (p *ClusterWriteResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type ClusterWriteTaggedArgs struct {
 	Req *WriteTaggedRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -28655,7 +29220,7 @@ func (p *ClusterWriteTaggedArgs) String() string {
 }
 
 // Attributes:
-//  - Err
+//   - Err
 type ClusterWriteTaggedResult struct {
 	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
@@ -28760,7 +29325,7 @@ func (p *ClusterWriteTaggedResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type ClusterQueryArgs struct {
 	Req *QueryRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -28865,8 +29430,8 @@ func (p *ClusterQueryArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type ClusterQueryResult struct {
 	Success *QueryResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -29015,7 +29580,7 @@ func (p *ClusterQueryResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type ClusterAggregateArgs struct {
 	Req *AggregateQueryRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -29120,8 +29685,8 @@ func (p *ClusterAggregateArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type ClusterAggregateResult struct {
 	Success *AggregateQueryResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error                 `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -29270,7 +29835,7 @@ func (p *ClusterAggregateResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type ClusterFetchArgs struct {
 	Req *FetchRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -29375,8 +29940,8 @@ func (p *ClusterFetchArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type ClusterFetchResult struct {
 	Success *FetchResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
@@ -29525,7 +30090,7 @@ func (p *ClusterFetchResult) String() string {
 }
 
 // Attributes:
-//  - Req
+//   - Req
 type ClusterTruncateArgs struct {
 	Req *TruncateRequest `thrift:"req,1" db:"req" json:"req"`
 }
@@ -29626,8 +30191,8 @@ func (p *ClusterTruncateArgs) String() string {
 }
 
 // Attributes:
-//  - Success
-//  - Err
+//   - Success
+//   - Err
 type ClusterTruncateResult struct {
 	Success *TruncateResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
 	Err     *Error           `thrift:"err,1" db:"err" json:"err,omitempty"`
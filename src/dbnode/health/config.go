@@ -0,0 +1,95 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package health
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Configuration is the configuration for evaluating node health.
+type Configuration struct {
+	// Enabled enables flipping the node health endpoint to degraded when
+	// a threshold is exceeded.
+	Enabled bool `yaml:"enabled"`
+
+	// EvaluationInterval is how often the thresholds are evaluated.
+	EvaluationInterval *time.Duration `yaml:"evaluationInterval"`
+
+	// MaxCommitLogQueueRatio is the fraction of the commit log queue
+	// capacity above which the node is degraded.
+	MaxCommitLogQueueRatio *float64 `yaml:"maxCommitLogQueueRatio"`
+
+	// MaxCommitLogFlushLatency is the latency from write to commit log flush
+	// above which the node is degraded.
+	MaxCommitLogFlushLatency *time.Duration `yaml:"maxCommitLogFlushLatency"`
+
+	// MaxFlushLag is the lag of flushed blocks behind the wall clock above
+	// which the node is degraded.
+	MaxFlushLag *time.Duration `yaml:"maxFlushLag"`
+
+	// CommitLogWriteSLOTarget is the target fraction of writes accepted by
+	// the commit log queue, for example 0.999.
+	CommitLogWriteSLOTarget *float64 `yaml:"commitLogWriteSLOTarget"`
+
+	// SLOWindow is the window the error budget burn rate is measured over.
+	SLOWindow *time.Duration `yaml:"sloWindow"`
+
+	// MaxSLOBurnRate is the error budget burn rate above which the node
+	// is degraded.
+	MaxSLOBurnRate *float64 `yaml:"maxSLOBurnRate"`
+}
+
+const defaultEvaluationInterval = 10 * time.Second
+
+// EvaluationIntervalOrDefault returns the evaluation interval or the default.
+func (c Configuration) EvaluationIntervalOrDefault() time.Duration {
+	if c.EvaluationInterval != nil && *c.EvaluationInterval > 0 {
+		return *c.EvaluationInterval
+	}
+	return defaultEvaluationInterval
+}
+
+// NewTracker returns a new health tracker for the configuration.
+func (c Configuration) NewTracker(iOpts instrument.Options) (Tracker, error) {
+	opts := NewOptions().
+		SetInstrumentOptions(iOpts)
+	if c.MaxCommitLogQueueRatio != nil {
+		opts = opts.SetMaxCommitLogQueueRatio(*c.MaxCommitLogQueueRatio)
+	}
+	if c.MaxCommitLogFlushLatency != nil {
+		opts = opts.SetMaxCommitLogFlushLatency(*c.MaxCommitLogFlushLatency)
+	}
+	if c.MaxFlushLag != nil {
+		opts = opts.SetMaxFlushLag(*c.MaxFlushLag)
+	}
+	if c.CommitLogWriteSLOTarget != nil {
+		opts = opts.SetCommitLogWriteSLOTarget(*c.CommitLogWriteSLOTarget)
+	}
+	if c.SLOWindow != nil {
+		opts = opts.SetSLOWindow(*c.SLOWindow)
+	}
+	if c.MaxSLOBurnRate != nil {
+		opts = opts.SetMaxSLOBurnRate(*c.MaxSLOBurnRate)
+	}
+	return NewTracker(opts)
+}
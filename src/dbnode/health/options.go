@@ -0,0 +1,162 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package health
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultSLOWindow      = 5 * time.Minute
+	defaultMaxSLOBurnRate = 14.4
+)
+
+var (
+	errMaxCommitLogQueueRatioInvalid = errors.New("max commit log queue ratio must be between 0 and 1")
+	errSLOTargetInvalid              = errors.New("commit log write SLO target must be at least 0 and less than 1")
+	errSLOWindowNotPositive          = errors.New("SLO window must be positive")
+	errMaxSLOBurnRateNotPositive     = errors.New("max SLO burn rate must be positive")
+	errNegativeThreshold             = errors.New("health thresholds must not be negative")
+)
+
+type options struct {
+	clockOpts                clock.Options
+	instrumentOpts           instrument.Options
+	maxCommitLogQueueRatio   float64
+	maxCommitLogFlushLatency time.Duration
+	maxFlushLag              time.Duration
+	commitLogWriteSLOTarget  float64
+	sloWindow                time.Duration
+	maxSLOBurnRate           float64
+}
+
+// NewOptions returns new health options with all thresholds disabled.
+func NewOptions() Options {
+	return &options{
+		clockOpts:      clock.NewOptions(),
+		instrumentOpts: instrument.NewOptions(),
+		sloWindow:      defaultSLOWindow,
+		maxSLOBurnRate: defaultMaxSLOBurnRate,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.maxCommitLogQueueRatio < 0 || o.maxCommitLogQueueRatio > 1 {
+		return errMaxCommitLogQueueRatioInvalid
+	}
+	if o.commitLogWriteSLOTarget < 0 || o.commitLogWriteSLOTarget >= 1 {
+		return errSLOTargetInvalid
+	}
+	if o.maxCommitLogFlushLatency < 0 || o.maxFlushLag < 0 {
+		return errNegativeThreshold
+	}
+	if o.sloWindow <= 0 {
+		return errSLOWindowNotPositive
+	}
+	if o.maxSLOBurnRate <= 0 {
+		return errMaxSLOBurnRateNotPositive
+	}
+	return nil
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetMaxCommitLogQueueRatio(value float64) Options {
+	opts := *o
+	opts.maxCommitLogQueueRatio = value
+	return &opts
+}
+
+func (o *options) MaxCommitLogQueueRatio() float64 {
+	return o.maxCommitLogQueueRatio
+}
+
+func (o *options) SetMaxCommitLogFlushLatency(value time.Duration) Options {
+	opts := *o
+	opts.maxCommitLogFlushLatency = value
+	return &opts
+}
+
+func (o *options) MaxCommitLogFlushLatency() time.Duration {
+	return o.maxCommitLogFlushLatency
+}
+
+func (o *options) SetMaxFlushLag(value time.Duration) Options {
+	opts := *o
+	opts.maxFlushLag = value
+	return &opts
+}
+
+func (o *options) MaxFlushLag() time.Duration {
+	return o.maxFlushLag
+}
+
+func (o *options) SetCommitLogWriteSLOTarget(value float64) Options {
+	opts := *o
+	opts.commitLogWriteSLOTarget = value
+	return &opts
+}
+
+func (o *options) CommitLogWriteSLOTarget() float64 {
+	return o.commitLogWriteSLOTarget
+}
+
+func (o *options) SetSLOWindow(value time.Duration) Options {
+	opts := *o
+	opts.sloWindow = value
+	return &opts
+}
+
+func (o *options) SLOWindow() time.Duration {
+	return o.sloWindow
+}
+
+func (o *options) SetMaxSLOBurnRate(value float64) Options {
+	opts := *o
+	opts.maxSLOBurnRate = value
+	return &opts
+}
+
+func (o *options) MaxSLOBurnRate() float64 {
+	return o.maxSLOBurnRate
+}
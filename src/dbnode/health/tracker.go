@@ -0,0 +1,224 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/uber-go/tally"
+)
+
+type sloSample struct {
+	at       time.Time
+	accepted int64
+	dropped  int64
+}
+
+type trackerMetrics struct {
+	degraded          tally.Gauge
+	sloBurnRate       tally.Gauge
+	commitLogQueue    tally.Gauge
+	maxFlushLag       tally.Gauge
+	thresholdBreaches tally.Counter
+}
+
+type tracker struct {
+	sync.Mutex
+
+	opts    Options
+	nowFn   clock.NowFn
+	metrics trackerMetrics
+
+	accepted        int64
+	dropped         int64
+	queueDepth      int64
+	queueCapacity   int64
+	maxFlushLatency time.Duration
+	flushLags       map[string]time.Duration
+	samples         []sloSample
+	status          Status
+}
+
+// NewTracker returns a new health tracker.
+func NewTracker(opts Options) (Tracker, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("health")
+	return &tracker{
+		opts:  opts,
+		nowFn: opts.ClockOptions().NowFn(),
+		metrics: trackerMetrics{
+			degraded:          scope.Gauge("degraded"),
+			sloBurnRate:       scope.Gauge("commitlog-slo-burn-rate"),
+			commitLogQueue:    scope.Gauge("commitlog-queue-ratio"),
+			maxFlushLag:       scope.Gauge("max-flush-lag"),
+			thresholdBreaches: scope.Counter("threshold-breaches"),
+		},
+		flushLags: make(map[string]time.Duration),
+	}, nil
+}
+
+func (t *tracker) RecordCommitLogWrites(accepted, dropped int64) {
+	t.Lock()
+	t.accepted += accepted
+	t.dropped += dropped
+	t.Unlock()
+}
+
+func (t *tracker) UpdateCommitLogQueue(depth, capacity int64) {
+	t.Lock()
+	t.queueDepth = depth
+	t.queueCapacity = capacity
+	t.Unlock()
+}
+
+func (t *tracker) RecordCommitLogFlushLatency(latency time.Duration) {
+	t.Lock()
+	if latency > t.maxFlushLatency {
+		t.maxFlushLatency = latency
+	}
+	t.Unlock()
+}
+
+func (t *tracker) UpdateFlushLag(namespace string, lag time.Duration) {
+	t.Lock()
+	t.flushLags[namespace] = lag
+	t.Unlock()
+}
+
+func (t *tracker) Evaluate() Status {
+	t.Lock()
+	defer t.Unlock()
+
+	var (
+		now    = t.nowFn()
+		status Status
+	)
+
+	// Close out the writes since the last evaluation as a sample and expire
+	// the samples that fell out of the SLO window.
+	t.samples = append(t.samples, sloSample{
+		at:       now,
+		accepted: t.accepted,
+		dropped:  t.dropped,
+	})
+	t.accepted, t.dropped = 0, 0
+	cutoff := now.Add(-t.opts.SLOWindow())
+	expired := 0
+	for expired < len(t.samples) && t.samples[expired].at.Before(cutoff) {
+		expired++
+	}
+	t.samples = t.samples[expired:]
+
+	var accepted, dropped int64
+	for _, s := range t.samples {
+		accepted += s.accepted
+		dropped += s.dropped
+	}
+
+	if target := t.opts.CommitLogWriteSLOTarget(); target > 0 {
+		var burnRate float64
+		if total := accepted + dropped; total > 0 {
+			burnRate = (float64(dropped) / float64(total)) / (1 - target)
+		}
+		t.metrics.sloBurnRate.Update(burnRate)
+		if max := t.opts.MaxSLOBurnRate(); burnRate > max {
+			status.Reasons = append(status.Reasons, fmt.Sprintf(
+				"commit log write SLO burn rate %.2f exceeds %.2f", burnRate, max))
+		}
+	}
+
+	if t.queueCapacity > 0 {
+		ratio := float64(t.queueDepth) / float64(t.queueCapacity)
+		t.metrics.commitLogQueue.Update(ratio)
+		if max := t.opts.MaxCommitLogQueueRatio(); max > 0 && ratio > max {
+			status.Reasons = append(status.Reasons, fmt.Sprintf(
+				"commit log queue %d/%d exceeds ratio %.2f",
+				t.queueDepth, t.queueCapacity, max))
+		}
+	}
+
+	// The flush latency is the max since the last evaluation so that a
+	// single slow flush is reported for exactly one evaluation.
+	if max := t.opts.MaxCommitLogFlushLatency(); max > 0 && t.maxFlushLatency > max {
+		status.Reasons = append(status.Reasons, fmt.Sprintf(
+			"commit log flush latency %s exceeds %s", t.maxFlushLatency, max))
+	}
+	t.maxFlushLatency = 0
+
+	var (
+		maxLag     time.Duration
+		namespaces = make([]string, 0, len(t.flushLags))
+	)
+	for ns, lag := range t.flushLags {
+		namespaces = append(namespaces, ns)
+		if lag > maxLag {
+			maxLag = lag
+		}
+	}
+	t.metrics.maxFlushLag.Update(maxLag.Seconds())
+	if max := t.opts.MaxFlushLag(); max > 0 {
+		sort.Strings(namespaces)
+		for _, ns := range namespaces {
+			if lag := t.flushLags[ns]; lag > max {
+				status.Reasons = append(status.Reasons, fmt.Sprintf(
+					"namespace %s flush lag %s exceeds %s", ns, lag, max))
+			}
+		}
+	}
+
+	status.Degraded = len(status.Reasons) > 0
+	if status.Degraded {
+		t.metrics.degraded.Update(1)
+		t.metrics.thresholdBreaches.Inc(int64(len(status.Reasons)))
+	} else {
+		t.metrics.degraded.Update(0)
+	}
+	t.status = status
+	return status
+}
+
+func (t *tracker) Status() Status {
+	t.Lock()
+	status := t.status
+	t.Unlock()
+	return status
+}
+
+type noopTracker struct{}
+
+// NewNoopTracker returns a tracker that records nothing and is never degraded.
+func NewNoopTracker() Tracker {
+	return noopTracker{}
+}
+
+func (noopTracker) RecordCommitLogWrites(accepted, dropped int64)      {}
+func (noopTracker) UpdateCommitLogQueue(depth, capacity int64)         {}
+func (noopTracker) RecordCommitLogFlushLatency(latency time.Duration)  {}
+func (noopTracker) UpdateFlushLag(namespace string, lag time.Duration) {}
+func (noopTracker) Evaluate() Status                                   { return Status{} }
+func (noopTracker) Status() Status                                     { return Status{} }
@@ -0,0 +1,143 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) nowFn() time.Time {
+	return c.now
+}
+
+func newTestTracker(t *testing.T, opts Options) (Tracker, *testClock, tally.TestScope) {
+	clk := &testClock{now: time.Unix(1000, 0)}
+	scope := tally.NewTestScope("", nil)
+	tracker, err := NewTracker(opts.
+		SetClockOptions(clock.NewOptions().SetNowFn(clk.nowFn)).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope)))
+	require.NoError(t, err)
+	return tracker, clk, scope
+}
+
+func TestTrackerHealthyWithThresholdsDisabled(t *testing.T) {
+	tracker, _, _ := newTestTracker(t, NewOptions())
+
+	tracker.RecordCommitLogWrites(0, 100)
+	tracker.UpdateCommitLogQueue(100, 100)
+	tracker.RecordCommitLogFlushLatency(time.Hour)
+	tracker.UpdateFlushLag("foo", 24*time.Hour)
+
+	require.Equal(t, Status{}, tracker.Evaluate())
+	require.Equal(t, Status{}, tracker.Status())
+}
+
+func TestTrackerCommitLogThresholds(t *testing.T) {
+	tracker, _, scope := newTestTracker(t, NewOptions().
+		SetMaxCommitLogQueueRatio(0.5).
+		SetMaxCommitLogFlushLatency(time.Second))
+
+	tracker.UpdateCommitLogQueue(40, 100)
+	tracker.RecordCommitLogFlushLatency(500 * time.Millisecond)
+	require.False(t, tracker.Evaluate().Degraded)
+
+	tracker.UpdateCommitLogQueue(60, 100)
+	tracker.RecordCommitLogFlushLatency(2 * time.Second)
+	status := tracker.Evaluate()
+	require.True(t, status.Degraded)
+	require.Equal(t, []string{
+		"commit log queue 60/100 exceeds ratio 0.50",
+		"commit log flush latency 2s exceeds 1s",
+	}, status.Reasons)
+	require.Equal(t, status, tracker.Status())
+	require.Equal(t, float64(1), scope.Snapshot().Gauges()["health.degraded+"].Value())
+
+	// The flush latency is only reported for the evaluation after it.
+	tracker.UpdateCommitLogQueue(0, 100)
+	require.False(t, tracker.Evaluate().Degraded)
+	require.Equal(t, float64(0), scope.Snapshot().Gauges()["health.degraded+"].Value())
+}
+
+func TestTrackerFlushLag(t *testing.T) {
+	tracker, _, _ := newTestTracker(t, NewOptions().SetMaxFlushLag(time.Hour))
+
+	tracker.UpdateFlushLag("foo", 3*time.Hour)
+	tracker.UpdateFlushLag("bar", 30*time.Minute)
+	require.Equal(t, Status{
+		Degraded: true,
+		Reasons:  []string{"namespace foo flush lag 3h0m0s exceeds 1h0m0s"},
+	}, tracker.Evaluate())
+
+	tracker.UpdateFlushLag("foo", 0)
+	require.False(t, tracker.Evaluate().Degraded)
+}
+
+func TestTrackerSLOBurnRateWindow(t *testing.T) {
+	tracker, clk, scope := newTestTracker(t, NewOptions().
+		SetCommitLogWriteSLOTarget(0.99).
+		SetSLOWindow(time.Minute).
+		SetMaxSLOBurnRate(10))
+
+	// 5% dropped burns the 1% budget at 5x.
+	tracker.RecordCommitLogWrites(95, 5)
+	require.False(t, tracker.Evaluate().Degraded)
+	require.InDelta(t, 5, scope.Snapshot().Gauges()["health.commitlog-slo-burn-rate+"].Value(), 0.001)
+
+	// 20% dropped over the window burns the budget at 20x.
+	clk.now = clk.now.Add(30 * time.Second)
+	tracker.RecordCommitLogWrites(65, 35)
+	status := tracker.Evaluate()
+	require.True(t, status.Degraded)
+	require.Equal(t, []string{"commit log write SLO burn rate 20.00 exceeds 10.00"}, status.Reasons)
+
+	// Once the drops fall out of the window the node recovers.
+	clk.now = clk.now.Add(2 * time.Minute)
+	tracker.RecordCommitLogWrites(100, 0)
+	require.False(t, tracker.Evaluate().Degraded)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	require.NoError(t, NewOptions().Validate())
+	require.Error(t, NewOptions().SetMaxCommitLogQueueRatio(1.5).Validate())
+	require.Error(t, NewOptions().SetCommitLogWriteSLOTarget(1).Validate())
+	require.Error(t, NewOptions().SetMaxFlushLag(-time.Second).Validate())
+	require.Error(t, NewOptions().SetSLOWindow(0).Validate())
+	require.Error(t, NewOptions().SetMaxSLOBurnRate(0).Validate())
+}
+
+func TestNoopTracker(t *testing.T) {
+	tracker := NewNoopTracker()
+	tracker.RecordCommitLogWrites(0, 1)
+	tracker.UpdateFlushLag("foo", time.Hour)
+	require.Equal(t, Status{}, tracker.Evaluate())
+	require.Equal(t, Status{}, tracker.Status())
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package health evaluates built-in thresholds on the commit log and flush
+// pipelines of a node so that the node can report itself as degraded before
+// writes start failing.
+package health
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Status is the result of evaluating the health thresholds.
+type Status struct {
+	// Degraded is whether any threshold is exceeded.
+	Degraded bool `json:"degraded"`
+	// Reasons describes each exceeded threshold.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Tracker tracks the commit log and flush pipelines of a node and evaluates
+// them against thresholds, it is safe for concurrent use.
+type Tracker interface {
+	// RecordCommitLogWrites records the number of writes accepted by the
+	// commit log queue and the number dropped because it was full.
+	RecordCommitLogWrites(accepted, dropped int64)
+
+	// UpdateCommitLogQueue updates the number of writes in the commit log
+	// queue and the capacity of the queue.
+	UpdateCommitLogQueue(depth, capacity int64)
+
+	// RecordCommitLogFlushLatency records the time from a write to the
+	// commit log until the commit log was flushed.
+	RecordCommitLogFlushLatency(latency time.Duration)
+
+	// UpdateFlushLag updates how far the flushed blocks of a namespace lag
+	// behind the wall clock.
+	UpdateFlushLag(namespace string, lag time.Duration)
+
+	// Evaluate evaluates the thresholds and returns the resulting status.
+	Evaluate() Status
+
+	// Status returns the status of the last evaluation.
+	Status() Status
+}
+
+// Options is a set of health options, thresholds set to zero are disabled.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetClockOptions sets the clock options.
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+
+	// SetMaxCommitLogQueueRatio sets the fraction of the commit log queue
+	// capacity above which the node is degraded.
+	SetMaxCommitLogQueueRatio(value float64) Options
+
+	// MaxCommitLogQueueRatio returns the fraction of the commit log queue
+	// capacity above which the node is degraded.
+	MaxCommitLogQueueRatio() float64
+
+	// SetMaxCommitLogFlushLatency sets the latency from write to commit log
+	// flush above which the node is degraded.
+	SetMaxCommitLogFlushLatency(value time.Duration) Options
+
+	// MaxCommitLogFlushLatency returns the latency from write to commit log
+	// flush above which the node is degraded.
+	MaxCommitLogFlushLatency() time.Duration
+
+	// SetMaxFlushLag sets the lag of flushed blocks behind the wall clock
+	// above which the node is degraded.
+	SetMaxFlushLag(value time.Duration) Options
+
+	// MaxFlushLag returns the lag of flushed blocks behind the wall clock
+	// above which the node is degraded.
+	MaxFlushLag() time.Duration
+
+	// SetCommitLogWriteSLOTarget sets the target fraction of writes accepted
+	// by the commit log queue.
+	SetCommitLogWriteSLOTarget(value float64) Options
+
+	// CommitLogWriteSLOTarget returns the target fraction of writes accepted
+	// by the commit log queue.
+	CommitLogWriteSLOTarget() float64
+
+	// SetSLOWindow sets the window the error budget burn rate is measured over.
+	SetSLOWindow(value time.Duration) Options
+
+	// SLOWindow returns the window the error budget burn rate is measured over.
+	SLOWindow() time.Duration
+
+	// SetMaxSLOBurnRate sets the error budget burn rate above which the
+	// node is degraded.
+	SetMaxSLOBurnRate(value float64) Options
+
+	// MaxSLOBurnRate returns the error budget burn rate above which the
+	// node is degraded.
+	MaxSLOBurnRate() float64
+}
@@ -27,6 +27,8 @@ import (
 	"github.com/m3db/m3/src/cluster/client"
 	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/placement/history"
+	"github.com/m3db/m3/src/cluster/placement/queue"
 	"github.com/m3db/m3/src/cluster/services"
 	xwatch "github.com/m3db/m3/src/x/watch"
 )
@@ -178,6 +180,13 @@ func (s *m3ClusterServices) Unadvertise(
 	return fmt.Errorf("not implemented")
 }
 
+func (s *m3ClusterServices) AdvertisementHealth(
+	service services.ServiceID,
+	id string,
+) error {
+	return fmt.Errorf("not implemented")
+}
+
 func (s *m3ClusterServices) Query(
 	service services.ServiceID,
 	opts services.QueryOptions,
@@ -231,6 +240,20 @@ func (s *m3ClusterServices) PlacementService(
 	return s.placementService, nil
 }
 
+func (s *m3ClusterServices) ShardStateHistory(
+	service services.ServiceID,
+	popts placement.Options,
+) (history.Store, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (s *m3ClusterServices) PlacementOperationQueue(
+	service services.ServiceID,
+	opts queue.Options,
+) (queue.Queue, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (s *m3ClusterServices) HeartbeatService(
 	service services.ServiceID,
 ) (services.HeartbeatService, error) {
@@ -277,6 +300,7 @@ type m3ClusterService struct {
 	instances   []services.ServiceInstance
 	replication services.ServiceReplication
 	sharding    services.ServiceSharding
+	version     int
 }
 
 func (s *m3ClusterService) Instance(
@@ -336,3 +360,16 @@ func (s *m3ClusterService) SetSharding(
 	s.sharding = ss
 	return s
 }
+
+func (s *m3ClusterService) Version() int {
+	s.RLock()
+	defer s.RUnlock()
+	return s.version
+}
+
+func (s *m3ClusterService) SetVersion(v int) services.Service {
+	s.Lock()
+	defer s.Unlock()
+	s.version = v
+	return s
+}
@@ -52,4 +52,22 @@ const (
 
 	// QueryLimits is the KV config key for query limits enforced on each dbnode.
 	QueryLimits = "m3db.query.limits"
+
+	// ReadRepairDivergenceKey is the KV config key clients report the
+	// namespace, shard and block starts that replicas diverged on during
+	// reads to, so that dbnodes can prioritize repairing them.
+	ReadRepairDivergenceKey = "m3db.node.read-repair-divergence"
+
+	// FeatureFlagKeyPrefix is the prefix of the KV config keys of the feature
+	// flags read by dbnodes, the flag name follows the prefix.
+	FeatureFlagKeyPrefix = "m3db.node.feature-flags."
+
+	// RateLimitKeyPrefix is the prefix of the KV config keys of the rates of
+	// the rate limiters of dbnodes, the dotted limiter name follows the
+	// prefix, for instance "disk.flush".
+	RateLimitKeyPrefix = "m3db.node.rate-limits."
+
+	// BackupKeyPrefix is the prefix of the KV config keys of the backup
+	// manifests and restores coordinated across dbnodes.
+	BackupKeyPrefix = "m3db.node.backup."
 )
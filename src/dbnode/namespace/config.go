@@ -93,13 +93,15 @@ func (mc *MetadataConfiguration) Metadata() (Metadata, error) {
 
 // IndexConfiguration controls the knobs to tweak indexing configuration.
 type IndexConfiguration struct {
-	Enabled   bool          `yaml:"enabled" validate:"nonzero"`
-	BlockSize time.Duration `yaml:"blockSize" validate:"nonzero"`
+	Enabled      bool          `yaml:"enabled" validate:"nonzero"`
+	BlockSize    time.Duration `yaml:"blockSize" validate:"nonzero"`
+	ExcludedTags []string      `yaml:"excludedTags"`
 }
 
 // Options returns the IndexOptions corresponding to the receiver struct.
 func (ic *IndexConfiguration) Options() IndexOptions {
 	return NewIndexOptions().
 		SetEnabled(ic.Enabled).
-		SetBlockSize(ic.BlockSize)
+		SetBlockSize(ic.BlockSize).
+		SetExcludedTags(ic.ExcludedTags)
 }
@@ -87,7 +87,8 @@ func ToIndexOptions(
 	}
 
 	iopts = iopts.SetEnabled(io.Enabled).
-		SetBlockSize(FromNanos(io.BlockSizeNanos))
+		SetBlockSize(FromNanos(io.BlockSizeNanos)).
+		SetExcludedTags(io.ExcludedTags)
 
 	return iopts, nil
 }
@@ -108,6 +109,10 @@ func ToRuntimeOptions(
 		newValue := v.Value
 		runtimeOpts = runtimeOpts.SetFlushIndexingPerCPUConcurrency(&newValue)
 	}
+	if v := opts.IndexingPaused; v != nil {
+		newValue := v.Value
+		runtimeOpts = runtimeOpts.SetIndexingPaused(&newValue)
+	}
 	return runtimeOpts, nil
 }
 
@@ -333,6 +338,7 @@ func OptionsToProto(opts Options) (*nsproto.NamespaceOptions, error) {
 		IndexOptions: &nsproto.IndexOptions{
 			Enabled:        iopts.Enabled(),
 			BlockSizeNanos: iopts.BlockSize().Nanoseconds(),
+			ExcludedTags:   iopts.ExcludedTags(),
 		},
 		ColdWritesEnabled:     opts.ColdWritesEnabled(),
 		RuntimeOptions:        toRuntimeOptions(opts.RuntimeOptions()),
@@ -387,6 +393,7 @@ func toRuntimeOptions(opts RuntimeOptions) *nsproto.NamespaceRuntimeOptions {
 	var (
 		writeIndexingPerCPUConcurrency *protobuftypes.DoubleValue
 		flushIndexingPerCPUConcurrency *protobuftypes.DoubleValue
+		indexingPaused                 *protobuftypes.BoolValue
 	)
 	if v := opts.WriteIndexingPerCPUConcurrency(); v != nil {
 		writeIndexingPerCPUConcurrency = &protobuftypes.DoubleValue{
@@ -398,9 +405,15 @@ func toRuntimeOptions(opts RuntimeOptions) *nsproto.NamespaceRuntimeOptions {
 			Value: *v,
 		}
 	}
+	if v := opts.IndexingPaused(); v != nil {
+		indexingPaused = &protobuftypes.BoolValue{
+			Value: *v,
+		}
+	}
 	return &nsproto.NamespaceRuntimeOptions{
 		WriteIndexingPerCPUConcurrency: writeIndexingPerCPUConcurrency,
 		FlushIndexingPerCPUConcurrency: flushIndexingPerCPUConcurrency,
+		IndexingPaused:                 indexingPaused,
 	}
 }
 
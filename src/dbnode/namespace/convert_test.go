@@ -324,6 +324,52 @@ func TestFromProtoSnapshotEnabled(t *testing.T) {
 	require.Equal(t, !namespace.NewOptions().SnapshotEnabled(), md.Options().SnapshotEnabled())
 }
 
+func TestRuntimeOptionsIndexingPausedRoundTrip(t *testing.T) {
+	paused := true
+	md, err := namespace.NewMetadata(
+		ident.StringID("ns1"),
+		namespace.NewOptions().
+			SetRuntimeOptions(namespace.NewRuntimeOptions().SetIndexingPaused(&paused)),
+	)
+	require.NoError(t, err)
+	nsMap, err := namespace.NewMap([]namespace.Metadata{md})
+	require.NoError(t, err)
+
+	reg, err := namespace.ToProto(nsMap)
+	require.NoError(t, err)
+	require.Len(t, reg.Namespaces, 1)
+	require.NotNil(t, reg.Namespaces["ns1"].RuntimeOptions)
+	require.True(t, reg.Namespaces["ns1"].RuntimeOptions.IndexingPaused.Value)
+
+	runtimeOpts, err := namespace.ToRuntimeOptions(reg.Namespaces["ns1"].RuntimeOptions)
+	require.NoError(t, err)
+	require.True(t, runtimeOpts.IndexingPausedOrDefault())
+	require.False(t, namespace.NewRuntimeOptions().IndexingPausedOrDefault())
+}
+
+func TestIndexOptionsExcludedTagsRoundTrip(t *testing.T) {
+	excluded := []string{"trace_id", "span_*"}
+	md, err := namespace.NewMetadata(
+		ident.StringID("ns1"),
+		namespace.NewOptions().
+			SetIndexOptions(namespace.NewIndexOptions().
+				SetEnabled(true).
+				SetExcludedTags(excluded)),
+	)
+	require.NoError(t, err)
+	nsMap, err := namespace.NewMap([]namespace.Metadata{md})
+	require.NoError(t, err)
+
+	reg, err := namespace.ToProto(nsMap)
+	require.NoError(t, err)
+	require.Len(t, reg.Namespaces, 1)
+	require.Equal(t, excluded, reg.Namespaces["ns1"].IndexOptions.ExcludedTags)
+
+	iopts, err := namespace.ToIndexOptions(reg.Namespaces["ns1"].IndexOptions, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, excluded, iopts.ExcludedTags())
+}
+
 func TestInvalidExtendedOptions(t *testing.T) {
 	invalidExtendedOptsNoConverterForType := &nsproto.ExtendedOptions{Type: "unknown"}
 	_, err := namespace.ToExtendedOptions(invalidExtendedOptsNoConverterForType)
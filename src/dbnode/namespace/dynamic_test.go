@@ -439,6 +439,10 @@ func (v *testValue) IsNewer(other kv.Value) bool {
 	return v.Version() > other.Version()
 }
 
+func (v *testValue) IsStale() bool {
+	return false
+}
+
 func newTestWatchable(t *testing.T, initValue *testValue) kv.ValueWatchable {
 	w := kv.NewValueWatchable()
 	if initValue != nil {
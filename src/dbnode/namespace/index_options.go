@@ -33,8 +33,9 @@ var (
 )
 
 type indexOpts struct {
-	enabled   bool
-	blockSize time.Duration
+	enabled      bool
+	blockSize    time.Duration
+	excludedTags []string
 }
 
 // NewIndexOptions returns a new IndexOptions.
@@ -47,7 +48,8 @@ func NewIndexOptions() IndexOptions {
 
 func (i *indexOpts) Equal(value IndexOptions) bool {
 	return i.Enabled() == value.Enabled() &&
-		i.BlockSize() == value.BlockSize()
+		i.BlockSize() == value.BlockSize() &&
+		stringsEqual(i.ExcludedTags(), value.ExcludedTags())
 }
 
 func (i *indexOpts) SetEnabled(value bool) IndexOptions {
@@ -69,3 +71,25 @@ func (i *indexOpts) SetBlockSize(value time.Duration) IndexOptions {
 func (i *indexOpts) BlockSize() time.Duration {
 	return i.blockSize
 }
+
+func (i *indexOpts) SetExcludedTags(value []string) IndexOptions {
+	io := *i
+	io.excludedTags = value
+	return &io
+}
+
+func (i *indexOpts) ExcludedTags() []string {
+	return i.excludedTags
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
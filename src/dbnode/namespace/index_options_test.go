@@ -33,6 +33,11 @@ func TestIndexOptionsEqual(t *testing.T) {
 	require.False(t, opts.SetEnabled(true).Equal(opts.SetEnabled(false)))
 	require.False(t, opts.SetBlockSize(time.Hour).Equal(
 		opts.SetBlockSize(time.Hour*2)))
+	require.True(t, opts.SetExcludedTags([]string{"trace_id"}).Equal(
+		opts.SetExcludedTags([]string{"trace_id"})))
+	require.False(t, opts.SetExcludedTags([]string{"trace_id"}).Equal(
+		opts.SetExcludedTags([]string{"span_id"})))
+	require.False(t, opts.SetExcludedTags([]string{"trace_id"}).Equal(opts))
 }
 
 func TestIndexOptionsEnabled(t *testing.T) {
@@ -45,3 +50,10 @@ func TestIndexOptionsBlockSize(t *testing.T) {
 	opts := NewIndexOptions()
 	require.Equal(t, time.Hour, opts.SetBlockSize(time.Hour).BlockSize())
 }
+
+func TestIndexOptionsExcludedTags(t *testing.T) {
+	opts := NewIndexOptions()
+	require.Nil(t, opts.ExcludedTags())
+	require.Equal(t, []string{"trace_id", "span_*"},
+		opts.SetExcludedTags([]string{"trace_id", "span_*"}).ExcludedTags())
+}
@@ -574,6 +574,20 @@ func (mr *MockIndexOptionsMockRecorder) Equal(value interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Equal", reflect.TypeOf((*MockIndexOptions)(nil).Equal), value)
 }
 
+// ExcludedTags mocks base method.
+func (m *MockIndexOptions) ExcludedTags() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExcludedTags")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// ExcludedTags indicates an expected call of ExcludedTags.
+func (mr *MockIndexOptionsMockRecorder) ExcludedTags() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExcludedTags", reflect.TypeOf((*MockIndexOptions)(nil).ExcludedTags))
+}
+
 // SetBlockSize mocks base method.
 func (m *MockIndexOptions) SetBlockSize(value time.Duration) IndexOptions {
 	m.ctrl.T.Helper()
@@ -602,6 +616,20 @@ func (mr *MockIndexOptionsMockRecorder) SetEnabled(value interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEnabled", reflect.TypeOf((*MockIndexOptions)(nil).SetEnabled), value)
 }
 
+// SetExcludedTags mocks base method.
+func (m *MockIndexOptions) SetExcludedTags(value []string) IndexOptions {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetExcludedTags", value)
+	ret0, _ := ret[0].(IndexOptions)
+	return ret0
+}
+
+// SetExcludedTags indicates an expected call of SetExcludedTags.
+func (mr *MockIndexOptionsMockRecorder) SetExcludedTags(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExcludedTags", reflect.TypeOf((*MockIndexOptions)(nil).SetExcludedTags), value)
+}
+
 // MockSchemaDescr is a mock of SchemaDescr interface.
 type MockSchemaDescr struct {
 	ctrl     *gomock.Controller
@@ -30,6 +30,7 @@ import (
 const (
 	defaultWriteIndexingPerCPUConcurrency = 0.75
 	defaultFlushIndexingPerCPUConcurrency = 0.25
+	defaultIndexingPaused                 = false
 )
 
 // RuntimeOptions is a set of runtime options that can
@@ -65,6 +66,17 @@ type RuntimeOptions interface {
 	// FlushIndexingPerCPUConcurrencyOrDefault returns the flush
 	// indexing per CPU concurrency.
 	FlushIndexingPerCPUConcurrencyOrDefault() float64
+
+	// SetIndexingPaused sets whether indexing of incoming writes is paused,
+	// writes are still stored and are backfilled into the index on resume.
+	SetIndexingPaused(value *bool) RuntimeOptions
+
+	// IndexingPaused returns whether indexing of incoming writes is paused.
+	IndexingPaused() *bool
+
+	// IndexingPausedOrDefault returns whether indexing of incoming writes
+	// is paused or default.
+	IndexingPausedOrDefault() bool
 }
 
 // RuntimeOptionsManagerRegistry is a registry of runtime options managers.
@@ -107,6 +119,7 @@ type RuntimeOptionsListener interface {
 type runtimeOptions struct {
 	writeIndexingPerCPUConcurrency *float64
 	flushIndexingPerCPUConcurrency *float64
+	indexingPaused                 *bool
 }
 
 // NewRuntimeOptions returns a new namespace runtime options.
@@ -125,7 +138,8 @@ func (o *runtimeOptions) IsDefault() bool {
 
 func (o *runtimeOptions) Equal(other RuntimeOptions) bool {
 	return o.writeIndexingPerCPUConcurrency == other.WriteIndexingPerCPUConcurrency() &&
-		o.flushIndexingPerCPUConcurrency == other.FlushIndexingPerCPUConcurrency()
+		o.flushIndexingPerCPUConcurrency == other.FlushIndexingPerCPUConcurrency() &&
+		o.indexingPaused == other.IndexingPaused()
 }
 
 func (o *runtimeOptions) SetWriteIndexingPerCPUConcurrency(value *float64) RuntimeOptions {
@@ -164,6 +178,24 @@ func (o *runtimeOptions) FlushIndexingPerCPUConcurrencyOrDefault() float64 {
 	return *value
 }
 
+func (o *runtimeOptions) SetIndexingPaused(value *bool) RuntimeOptions {
+	opts := *o
+	opts.indexingPaused = value
+	return &opts
+}
+
+func (o *runtimeOptions) IndexingPaused() *bool {
+	return o.indexingPaused
+}
+
+func (o *runtimeOptions) IndexingPausedOrDefault() bool {
+	value := o.indexingPaused
+	if value == nil {
+		return defaultIndexingPaused
+	}
+	return *value
+}
+
 type runtimeOptionsManagerRegistry struct {
 	sync.RWMutex
 	managers map[string]RuntimeOptionsManager
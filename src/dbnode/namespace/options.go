@@ -22,8 +22,10 @@ package namespace
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/m3db/m3/src/dbnode/retention"
+	"github.com/m3db/m3/src/metrics/filters"
 )
 
 const (
@@ -136,6 +138,11 @@ func (o *options) Validate() error {
 	if indexBlockSize%dataBlockSize != 0 {
 		return errIndexBlockSizeMustBeAMultipleOfDataBlockSize
 	}
+	for _, pattern := range o.indexOpts.ExcludedTags() {
+		if _, err := filters.NewFilter([]byte(pattern)); err != nil {
+			return fmt.Errorf("invalid index excluded tag pattern %q: %w", pattern, err)
+		}
+	}
 	if o.runtimeOpts == nil {
 		return errNamespaceRuntimeOptionsNotSet
 	}
@@ -106,6 +106,7 @@ func TestOptionsValidate(t *testing.T) {
 	rOpts.EXPECT().FutureRetentionPeriod().Return(time.Duration(0))
 	rOpts.EXPECT().BlockSize().Return(time.Hour)
 	iOpts.EXPECT().BlockSize().Return(time.Hour)
+	iOpts.EXPECT().ExcludedTags().Return(nil)
 	require.NoError(t, o1.Validate())
 
 	rOpts.EXPECT().Validate().Return(nil)
@@ -179,6 +180,18 @@ func TestOptionsValidateBlockSizePositive(t *testing.T) {
 	require.Error(t, o1.Validate())
 }
 
+func TestOptionsValidateExcludedTags(t *testing.T) {
+	opts := NewOptions().
+		SetIndexOptions(NewIndexOptions().
+			SetEnabled(true).
+			SetExcludedTags([]string{"trace_id", "span_*"}))
+	require.NoError(t, opts.Validate())
+
+	opts = opts.SetIndexOptions(opts.IndexOptions().
+		SetExcludedTags([]string{"abc[z-a]"}))
+	require.Error(t, opts.Validate())
+}
+
 func TestOptionsValidateNoIndexing(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -212,6 +225,7 @@ func TestOptionsValidateStagingStatus(t *testing.T) {
 	rOpts.EXPECT().FutureRetentionPeriod().Return(time.Duration(0)).AnyTimes()
 	rOpts.EXPECT().BlockSize().Return(time.Hour).AnyTimes()
 	iOpts.EXPECT().BlockSize().Return(time.Hour).AnyTimes()
+	iOpts.EXPECT().ExcludedTags().Return(nil).AnyTimes()
 	require.NoError(t, o1.Validate())
 
 	o1 = o1.SetStagingState(StagingState{status: StagingStatus(12)})
@@ -149,6 +149,14 @@ type IndexOptions interface {
 
 	// BlockSize returns the block size.
 	BlockSize() time.Duration
+
+	// SetExcludedTags sets the tag names or glob patterns of tags that are
+	// stored with the series but excluded from the index.
+	SetExcludedTags(value []string) IndexOptions
+
+	// ExcludedTags returns the tag names or glob patterns of tags that are
+	// stored with the series but excluded from the index.
+	ExcludedTags() []string
 }
 
 // SchemaDescr describes the schema for a complex type value.
@@ -0,0 +1,218 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerstream
+
+import (
+	"fmt"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// NewCodec returns a new codec for the given compression.
+func NewCodec(c Compression) (Codec, error) {
+	switch c {
+	case CompressionZstd:
+		return newZstdCodec()
+	case CompressionSnappy:
+		return snappyCodec{}, nil
+	}
+	return nil, fmt.Errorf("no codec for peer stream compression %q", string(c))
+}
+
+// NewCodecs returns new codecs for the given compressions, skipping
+// CompressionNone.
+func NewCodecs(compressions []Compression) ([]Codec, error) {
+	codecs := make([]Codec, 0, len(compressions))
+	for _, c := range compressions {
+		if c == CompressionNone {
+			continue
+		}
+		codec, err := NewCodec(c)
+		if err != nil {
+			return nil, err
+		}
+		codecs = append(codecs, codec)
+	}
+	return codecs, nil
+}
+
+// Names returns the names of the codecs, as sent by a client to list the
+// codecs it accepts.
+func Names(codecs []Codec) []string {
+	if len(codecs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(codecs))
+	for _, codec := range codecs {
+		names = append(names, string(codec.Compression()))
+	}
+	return names
+}
+
+// Negotiate returns the codec for the first accepted compression that is
+// supported, or nil if none are supported and the stream is not compressed.
+func Negotiate(accepted []string, supported []Codec) Codec {
+	for _, name := range accepted {
+		if codec := Find(name, supported); codec != nil {
+			return codec
+		}
+	}
+	return nil
+}
+
+// Find returns the codec with the given compression name, or nil if there
+// is none.
+func Find(name string, codecs []Codec) Codec {
+	for _, codec := range codecs {
+		if string(codec.Compression()) == name {
+			return codec
+		}
+	}
+	return nil
+}
+
+// SegmentsSize returns the size of the head and tail of each segment.
+func SegmentsSize(segments *rpc.Segments) int {
+	if segments == nil {
+		return 0
+	}
+	var size int
+	if seg := segments.Merged; seg != nil {
+		size += len(seg.Head) + len(seg.Tail)
+	}
+	for _, seg := range segments.Unmerged {
+		size += len(seg.Head) + len(seg.Tail)
+	}
+	return size
+}
+
+// EncodeSegments compresses the head and tail of each segment in place,
+// returning the sizes of the segments before and after compression.
+func EncodeSegments(codec Codec, segments *rpc.Segments) (int, int) {
+	var raw, encoded int
+	encode := func(seg *rpc.Segment) {
+		if seg == nil {
+			return
+		}
+		raw += len(seg.Head) + len(seg.Tail)
+		seg.Head = codec.Encode(nil, seg.Head)
+		seg.Tail = codec.Encode(nil, seg.Tail)
+		encoded += len(seg.Head) + len(seg.Tail)
+	}
+
+	if segments == nil {
+		return 0, 0
+	}
+	encode(segments.Merged)
+	for _, seg := range segments.Unmerged {
+		encode(seg)
+	}
+	return raw, encoded
+}
+
+// DecodeSegments decompresses the head and tail of each segment in place,
+// returning the sizes of the segments before and after decompression.
+func DecodeSegments(codec Codec, segments *rpc.Segments) (int, int, error) {
+	var encoded, raw int
+	decode := func(seg *rpc.Segment) error {
+		if seg == nil {
+			return nil
+		}
+		encoded += len(seg.Head) + len(seg.Tail)
+		head, err := codec.Decode(nil, seg.Head)
+		if err != nil {
+			return err
+		}
+		tail, err := codec.Decode(nil, seg.Tail)
+		if err != nil {
+			return err
+		}
+		seg.Head, seg.Tail = head, tail
+		raw += len(seg.Head) + len(seg.Tail)
+		return nil
+	}
+
+	if segments == nil {
+		return 0, 0, nil
+	}
+	if err := decode(segments.Merged); err != nil {
+		return 0, 0, err
+	}
+	for _, seg := range segments.Unmerged {
+		if err := decode(seg); err != nil {
+			return 0, 0, err
+		}
+	}
+	return encoded, raw, nil
+}
+
+type zstdCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func newZstdCodec() (Codec, error) {
+	// Segments are already checksummed by the block checksum.
+	enc, err := zstd.NewWriter(nil,
+		zstd.WithEncoderCRC(false),
+		zstd.WithEncoderLevel(zstd.SpeedFastest))
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	return zstdCodec{encoder: enc, decoder: dec}, nil
+}
+
+func (c zstdCodec) Compression() Compression {
+	return CompressionZstd
+}
+
+func (c zstdCodec) Encode(dst, src []byte) []byte {
+	return c.encoder.EncodeAll(src, dst)
+}
+
+func (c zstdCodec) Decode(dst, src []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(src, dst)
+}
+
+type snappyCodec struct{}
+
+func (c snappyCodec) Compression() Compression {
+	return CompressionSnappy
+}
+
+func (c snappyCodec) Encode(dst, src []byte) []byte {
+	return append(dst, snappy.Encode(nil, src)...)
+}
+
+func (c snappyCodec) Decode(dst, src []byte) ([]byte, error) {
+	decoded, err := snappy.Decode(nil, src)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, decoded...), nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerstream
+
+import (
+	"crypto/tls"
+)
+
+// Configuration is the configuration for peer streams of a cluster, it must
+// be the same across the dbnodes of the cluster for TLS to be negotiated.
+type Configuration struct {
+	// Compression lists the codecs streams may be compressed with in order of
+	// preference, streams are compressed with the first codec also supported
+	// by the peer.
+	Compression []Compression `yaml:"compression"`
+
+	// TLS configures TLS for the node endpoint serving peer streams and for
+	// connections to peers, clients of the cluster must then also connect
+	// with TLS.
+	TLS *TLSConfiguration `yaml:"tls"`
+}
+
+// NewCodecs returns the codecs to compress streams with.
+func (c *Configuration) NewCodecs() ([]Codec, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return NewCodecs(c.Compression)
+}
+
+// ServerTLSConfig returns the TLS config to serve peer streams with, or nil
+// if TLS is not enabled.
+func (c *Configuration) ServerTLSConfig() (*tls.Config, error) {
+	if c == nil || c.TLS == nil {
+		return nil, nil
+	}
+	return c.TLS.ServerConfig()
+}
+
+// ClientTLSConfig returns the TLS config to connect to peers with, or nil if
+// TLS is not enabled.
+func (c *Configuration) ClientTLSConfig() (*tls.Config, error) {
+	if c == nil || c.TLS == nil {
+		return nil, nil
+	}
+	return c.TLS.ClientConfig()
+}
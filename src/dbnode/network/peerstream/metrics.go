@@ -0,0 +1,73 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerstream
+
+import (
+	"github.com/uber-go/tally"
+)
+
+var ratioBuckets = tally.MustMakeLinearValueBuckets(1, 0.5, 16)
+
+// Metrics tracks the throughput and compression ratio of peer streams.
+type Metrics struct {
+	byCompression map[Compression]compressionMetrics
+}
+
+type compressionMetrics struct {
+	streams      tally.Counter
+	rawBytes     tally.Counter
+	encodedBytes tally.Counter
+	ratio        tally.Histogram
+}
+
+// NewMetrics returns new peer stream metrics, tagged by compression.
+func NewMetrics(scope tally.Scope) *Metrics {
+	m := &Metrics{byCompression: make(map[Compression]compressionMetrics)}
+	for _, c := range validCompressions {
+		s := scope.Tagged(map[string]string{"compression": string(c)})
+		m.byCompression[c] = compressionMetrics{
+			streams:      s.Counter("streams"),
+			rawBytes:     s.Counter("raw-bytes"),
+			encodedBytes: s.Counter("encoded-bytes"),
+			ratio:        s.Histogram("ratio", ratioBuckets),
+		}
+	}
+	return m
+}
+
+// Record records a stream of segments with the given size before and after
+// compression, a nil codec records an uncompressed stream.
+func (m *Metrics) Record(codec Codec, rawBytes, encodedBytes int) {
+	c := CompressionNone
+	if codec != nil {
+		c = codec.Compression()
+	}
+	cm, ok := m.byCompression[c]
+	if !ok {
+		return
+	}
+	cm.streams.Inc(1)
+	cm.rawBytes.Inc(int64(rawBytes))
+	cm.encodedBytes.Inc(int64(encodedBytes))
+	if encodedBytes > 0 {
+		cm.ratio.RecordValue(float64(rawBytes) / float64(encodedBytes))
+	}
+}
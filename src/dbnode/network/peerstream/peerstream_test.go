@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerstream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func testCodecs(t *testing.T) []Codec {
+	codecs, err := NewCodecs([]Compression{CompressionZstd, CompressionNone, CompressionSnappy})
+	require.NoError(t, err)
+	require.Equal(t, []string{"zstd", "snappy"}, Names(codecs))
+	return codecs
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("m3db peer stream segment "), 100)
+	for _, codec := range testCodecs(t) {
+		encoded := codec.Encode(nil, data)
+		require.True(t, len(encoded) < len(data), string(codec.Compression()))
+
+		decoded, err := codec.Decode([]byte("prefix"), encoded)
+		require.NoError(t, err)
+		require.Equal(t, append([]byte("prefix"), data...), decoded)
+
+		_, err = codec.Decode(nil, []byte("not compressed"))
+		require.Error(t, err, string(codec.Compression()))
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	var (
+		codecs = testCodecs(t)
+		zstd   = codecs[0]
+		snappy = codecs[1]
+	)
+
+	require.Equal(t, snappy, Negotiate([]string{"lz4", "snappy", "zstd"}, codecs))
+	require.Equal(t, zstd, Negotiate([]string{"zstd"}, codecs))
+	require.Nil(t, Negotiate([]string{"lz4"}, codecs))
+	require.Nil(t, Negotiate(nil, codecs))
+	require.Nil(t, Negotiate([]string{"zstd"}, nil))
+}
+
+func TestEncodeDecodeSegments(t *testing.T) {
+	var (
+		head = bytes.Repeat([]byte{1, 2, 3, 4}, 64)
+		tail = bytes.Repeat([]byte{5, 6}, 32)
+		size = len(head) + 2*len(tail)
+	)
+	for _, codec := range testCodecs(t) {
+		segments := &rpc.Segments{
+			Unmerged: []*rpc.Segment{
+				{Head: head, Tail: tail},
+				{Tail: tail},
+			},
+		}
+		require.Equal(t, size, SegmentsSize(segments))
+
+		raw, encoded := EncodeSegments(codec, segments)
+		require.Equal(t, size, raw)
+		require.Equal(t, encoded, SegmentsSize(segments))
+		require.True(t, encoded < raw)
+
+		encoded2, raw2, err := DecodeSegments(codec, segments)
+		require.NoError(t, err)
+		require.Equal(t, encoded, encoded2)
+		require.Equal(t, raw, raw2)
+		require.Equal(t, head, segments.Unmerged[0].Head)
+		require.Equal(t, tail, segments.Unmerged[0].Tail)
+		require.Equal(t, 0, len(segments.Unmerged[1].Head))
+		require.Equal(t, tail, segments.Unmerged[1].Tail)
+	}
+}
+
+func TestMetricsRecord(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	m := NewMetrics(scope)
+	m.Record(testCodecs(t)[0], 400, 100)
+	m.Record(nil, 50, 50)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(400), counters["raw-bytes+compression=zstd"].Value())
+	require.Equal(t, int64(100), counters["encoded-bytes+compression=zstd"].Value())
+	require.Equal(t, int64(1), counters["streams+compression=none"].Value())
+}
+
+func TestConfigurationUnmarshal(t *testing.T) {
+	var cfg Configuration
+	require.NoError(t, yaml.Unmarshal([]byte("compression: [zstd, snappy]"), &cfg))
+	require.Equal(t, []Compression{CompressionZstd, CompressionSnappy}, cfg.Compression)
+
+	codecs, err := cfg.NewCodecs()
+	require.NoError(t, err)
+	require.Equal(t, []string{"zstd", "snappy"}, Names(codecs))
+
+	tlsConfig, err := cfg.ServerTLSConfig()
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig)
+
+	require.Error(t, yaml.Unmarshal([]byte("compression: [lz4]"), &cfg))
+
+	var nilCfg *Configuration
+	codecs, err = nilCfg.NewCodecs()
+	require.NoError(t, err)
+	require.Nil(t, codecs)
+}
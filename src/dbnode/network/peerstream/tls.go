@@ -0,0 +1,134 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package peerstream
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+)
+
+// TLSConfiguration is the configuration for TLS of peer streams, the same
+// certificate is used to serve and to connect to peers.
+type TLSConfiguration struct {
+	// CrtPath is the path to the PEM encoded certificate.
+	CrtPath string `yaml:"crtPath" validate:"nonzero"`
+
+	// KeyPath is the path to the PEM encoded key of the certificate.
+	KeyPath string `yaml:"keyPath" validate:"nonzero"`
+
+	// CACrtPath is the path to the PEM encoded CA certificate used to verify
+	// peers, if set peers must also present a certificate signed by it.
+	CACrtPath string `yaml:"caCrtPath"`
+
+	// ServerName overrides the name used to verify the certificates of the
+	// peers connected to, by default the host of the peer is used.
+	ServerName string `yaml:"serverName"`
+
+	// InsecureSkipVerify skips verifying the certificates of the peers
+	// connected to.
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+}
+
+// ServerConfig returns the TLS config to serve peer streams with.
+func (c TLSConfiguration) ServerConfig() (*tls.Config, error) {
+	cert, err := c.certificate()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.CACrtPath != "" {
+		pool, err := c.caPool()
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// ClientConfig returns the TLS config to connect to peers with.
+func (c TLSConfiguration) ClientConfig() (*tls.Config, error) {
+	cert, err := c.certificate()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if c.CACrtPath != "" {
+		pool, err := c.caPool()
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+func (c TLSConfiguration) certificate() (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(c.CrtPath, c.KeyPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unable to load peer stream certificate: %v", err)
+	}
+	return cert, nil
+}
+
+func (c TLSConfiguration) caPool() (*x509.CertPool, error) {
+	caCrt, err := ioutil.ReadFile(c.CACrtPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read peer stream CA certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCrt) {
+		return nil, errors.New("no certificates found in peer stream CA certificate")
+	}
+	return pool, nil
+}
+
+// DialFn dials a connection to a peer.
+type DialFn func(ctx context.Context, network, hostPort string) (net.Conn, error)
+
+// NewDialFn returns a DialFn that connects to peers over TLS.
+func NewDialFn(cfg *tls.Config) DialFn {
+	dialer := &tls.Dialer{Config: cfg}
+	return dialer.DialContext
+}
+
+// Listen listens for connections from peers over TLS.
+func Listen(address string, cfg *tls.Config) (net.Listener, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewListener(listener, cfg), nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package peerstream provides the compression and TLS used to secure and
+// shrink the block streams that dbnodes fetch from their peers when
+// bootstrapping and repairing.
+//
+// Compression is negotiated per request: the client lists the codecs it
+// accepts in order of preference and the server compresses the segments it
+// returns with the first of those it also supports, peers unaware of
+// compression simply stream uncompressed segments.
+package peerstream
+
+import (
+	"fmt"
+)
+
+// Compression is the name of a codec used to compress peer streams.
+type Compression string
+
+const (
+	// CompressionNone does not compress peer streams.
+	CompressionNone Compression = "none"
+	// CompressionZstd compresses peer streams with zstd, favouring ratio.
+	CompressionZstd Compression = "zstd"
+	// CompressionSnappy compresses peer streams with snappy, favouring speed.
+	CompressionSnappy Compression = "snappy"
+)
+
+var validCompressions = []Compression{
+	CompressionNone,
+	CompressionZstd,
+	CompressionSnappy,
+}
+
+// Validate validates the compression.
+func (c Compression) Validate() error {
+	for _, valid := range validCompressions {
+		if c == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid peer stream compression %q, valid values are: %v",
+		string(c), validCompressions)
+}
+
+// UnmarshalYAML unmarshals a Compression from a string.
+func (c *Compression) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	value := Compression(str)
+	if err := value.Validate(); err != nil {
+		return err
+	}
+	*c = value
+	return nil
+}
+
+// Codec compresses and decompresses segments of peer streams, it is safe for
+// concurrent use.
+type Codec interface {
+	// Compression returns the compression of the codec.
+	Compression() Compression
+
+	// Encode appends the compressed src to dst.
+	Encode(dst, src []byte) []byte
+
+	// Decode appends the decompressed src to dst.
+	Decode(dst, src []byte) ([]byte, error)
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/server/httpjson"
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
+	"github.com/m3db/m3/src/dbnode/storage"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/uber/tchannel-go/thrift"
+)
+
+const (
+	// HealthURL is the url for the node health.
+	HealthURL = "/api/v1/health"
+
+	// BootstrappedURL is the url that succeeds only once the node is
+	// bootstrapped and durable.
+	BootstrappedURL = "/api/v1/bootstrapped"
+
+	// NamespacesURL is the url for the namespaces the node owns, the optional
+	// name query parameter restricts the result to a single namespace.
+	NamespacesURL = "/api/v1/namespaces"
+
+	// RepairURL is the url to trigger a repair of the node.
+	RepairURL = "/api/v1/repair"
+
+	// ProfileStartURL is the url to start a continuous profile.
+	ProfileStartURL = "/api/v1/profile/start"
+
+	// ProfileStopURL is the url to stop a continuous profile.
+	ProfileStopURL = "/api/v1/profile/stop"
+
+	// IndexCompactURL is the url to compact the mutable index segments of a
+	// namespace in the foreground.
+	IndexCompactURL = "/api/v1/index/compact"
+
+	// CodecStatsURL is the url for the codec stats of the namespaces the node
+	// owns, the optional name query parameter restricts the result to a
+	// single namespace.
+	CodecStatsURL = "/api/v1/codec/stats"
+
+	// ImportRegisterURL is the url to register a fileset volume built offline
+	// by the bulk import tool with the node.
+	ImportRegisterURL = "/api/v1/import/register"
+
+	namespaceNameQueryParam = "name"
+)
+
+type handlerFn func(ctx thrift.Context, r *http.Request) (interface{}, error)
+
+type handlers struct {
+	service rpc.TChanNode
+	db      storage.Database
+	opts    httpjson.ServerOptions
+}
+
+// RegisterHandlers registers the admin handlers for the node service and
+// database on the HTTP serve mux.
+func RegisterHandlers(
+	mux *http.ServeMux,
+	service rpc.TChanNode,
+	db storage.Database,
+	opts httpjson.ServerOptions,
+) {
+	h := &handlers{service: service, db: db, opts: opts}
+	mux.HandleFunc(HealthURL, h.handle(http.MethodGet, "Health", h.health))
+	mux.HandleFunc(BootstrappedURL, h.handle(http.MethodGet, "Bootstrapped", h.bootstrapped))
+	mux.HandleFunc(NamespacesURL, h.handle(http.MethodGet, "Namespaces", h.namespaces))
+	mux.HandleFunc(RepairURL, h.handle(http.MethodPost, "Repair", h.repair))
+	mux.HandleFunc(ProfileStartURL, h.handle(http.MethodPost, "DebugProfileStart", h.profileStart))
+	mux.HandleFunc(ProfileStopURL, h.handle(http.MethodPost, "DebugProfileStop", h.profileStop))
+	mux.HandleFunc(IndexCompactURL, h.handle(http.MethodPost, "IndexCompact", h.indexCompact))
+	mux.HandleFunc(CodecStatsURL, h.handle(http.MethodGet, "CodecStats", h.codecStats))
+	mux.HandleFunc(ImportRegisterURL, h.handle(http.MethodPost, "ImportRegister", h.importRegister))
+}
+
+func (h *handlers) handle(
+	httpMethod string,
+	method string,
+	fn handlerFn,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Always close the request body
+		defer r.Body.Close()
+
+		if !httpjson.Authenticate(w, r, h.opts.AuthenticateFn()) {
+			return
+		}
+
+		if r.Method != httpMethod {
+			err := fmt.Errorf("request must be %s", httpMethod)
+			httpjson.WriteError(w, httpjson.NewError(err, http.StatusMethodNotAllowed))
+			return
+		}
+
+		httpHeaders := make(map[string]string)
+		for key, values := range r.Header {
+			if len(values) > 0 {
+				httpHeaders[key] = values[0]
+			}
+		}
+
+		callContext, cancel := thrift.NewContext(h.opts.RequestTimeout())
+		defer cancel()
+		if contextFn := h.opts.ContextFn(); contextFn != nil {
+			callContext = contextFn(callContext, method, httpHeaders)
+		}
+		callContext = thrift.WithHeaders(callContext, httpHeaders)
+		if postResponseFn := h.opts.PostResponseFn(); postResponseFn != nil {
+			defer postResponseFn(callContext, method, nil)
+		}
+
+		result, err := fn(callContext, r)
+		if err != nil {
+			httpjson.WriteError(w, toHTTPError(err))
+			return
+		}
+
+		buff := bytes.NewBuffer(nil)
+		if err := encodeResult(buff, result); err != nil {
+			httpjson.WriteError(w, fmt.Errorf("failed to encode response body: %v", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(buff.Bytes())
+	}
+}
+
+// healthResponse is the node health along with the IO health of its volumes.
+type healthResponse struct {
+	*rpc.NodeHealthResult_
+	Volumes []iohealth.VolumeHealth `json:"volumes,omitempty"`
+}
+
+func (h *handlers) health(ctx thrift.Context, _ *http.Request) (interface{}, error) {
+	result, err := h.service.Health(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if h.db == nil {
+		return result, nil
+	}
+	return healthResponse{
+		NodeHealthResult_: result,
+		Volumes: h.db.Options().CommitLogOptions().FilesystemOptions().
+			IOHealthTracker().Volumes(),
+	}, nil
+}
+
+func (h *handlers) bootstrapped(ctx thrift.Context, _ *http.Request) (interface{}, error) {
+	result, err := h.service.Bootstrapped(ctx)
+	if err != nil {
+		// Allow load balancers and orchestrators to treat the node as not
+		// ready rather than as failing.
+		return nil, httpjson.NewError(err, http.StatusServiceUnavailable)
+	}
+	return result, nil
+}
+
+func (h *handlers) namespaces(_ thrift.Context, r *http.Request) (interface{}, error) {
+	var (
+		name     = r.URL.Query().Get(namespaceNameQueryParam)
+		registry = &nsproto.Registry{
+			Namespaces: make(map[string]*nsproto.NamespaceOptions),
+		}
+	)
+	for _, n := range h.db.Namespaces() {
+		id := n.ID().String()
+		if name != "" && id != name {
+			continue
+		}
+		opts, err := namespace.OptionsToProto(n.Options())
+		if err != nil {
+			return nil, err
+		}
+		registry.Namespaces[id] = opts
+	}
+
+	if name != "" && len(registry.Namespaces) == 0 {
+		err := fmt.Errorf("namespace not found: %s", name)
+		return nil, httpjson.NewError(err, http.StatusNotFound)
+	}
+
+	return registry, nil
+}
+
+func (h *handlers) repair(ctx thrift.Context, _ *http.Request) (interface{}, error) {
+	return nil, h.service.Repair(ctx)
+}
+
+func (h *handlers) profileStart(ctx thrift.Context, r *http.Request) (interface{}, error) {
+	var req rpc.DebugProfileStartRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	return h.service.DebugProfileStart(ctx, &req)
+}
+
+func (h *handlers) profileStop(ctx thrift.Context, r *http.Request) (interface{}, error) {
+	var req rpc.DebugProfileStopRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	return h.service.DebugProfileStop(ctx, &req)
+}
+
+// indexCompactRequest is a request to compact the mutable index segments of
+// a namespace, or of a single block if the block start is set.
+type indexCompactRequest struct {
+	Namespace   string     `json:"namespace"`
+	BlockStart  *time.Time `json:"blockStart,omitempty"`
+	Concurrency int        `json:"concurrency,omitempty"`
+}
+
+type indexCompactResponse struct {
+	Blocks []indexCompactBlock `json:"blocks"`
+}
+
+type indexCompactBlock struct {
+	BlockStart           time.Time `json:"blockStart"`
+	Active               bool      `json:"active"`
+	NumSegmentsCompacted int       `json:"numSegmentsCompacted"`
+	NumDocs              int64     `json:"numDocs"`
+}
+
+func (h *handlers) indexCompact(_ thrift.Context, r *http.Request) (interface{}, error) {
+	var req indexCompactRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Namespace == "" {
+		return nil, xerrors.NewInvalidParamsError(errors.New("namespace is required"))
+	}
+	if req.Concurrency < 0 {
+		return nil, xerrors.NewInvalidParamsError(errors.New("concurrency must not be negative"))
+	}
+
+	n, ok := h.db.Namespace(ident.StringID(req.Namespace))
+	if !ok {
+		err := fmt.Errorf("namespace not found: %s", req.Namespace)
+		return nil, httpjson.NewError(err, http.StatusNotFound)
+	}
+	idx, err := n.Index()
+	if err != nil {
+		return nil, xerrors.NewInvalidParamsError(err)
+	}
+
+	var blockStart xtime.UnixNano
+	if req.BlockStart != nil {
+		blockStart = xtime.ToUnixNano(*req.BlockStart)
+	}
+	results, err := idx.Compact(blockStart, req.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := indexCompactResponse{Blocks: make([]indexCompactBlock, 0, len(results))}
+	for _, result := range results {
+		resp.Blocks = append(resp.Blocks, indexCompactBlock{
+			BlockStart:           result.BlockStart.ToTime(),
+			Active:               result.BlockStart.IsZero(),
+			NumSegmentsCompacted: result.NumSegmentsCompacted,
+			NumDocs:              result.NumDocs,
+		})
+	}
+	return resp, nil
+}
+
+type codecStatsResponse struct {
+	Namespaces map[string]namespaceCodecStats `json:"namespaces"`
+}
+
+// namespaceCodecStats are the codec stats of a namespace, gathered by
+// benchmarking a sample of the blocks it flushed.
+type namespaceCodecStats struct {
+	Codec                   string  `json:"codec"`
+	Blocks                  int64   `json:"blocks"`
+	Datapoints              int64   `json:"datapoints"`
+	EncodedBytes            int64   `json:"encodedBytes"`
+	Errors                  int64   `json:"errors"`
+	CompressionRatio        float64 `json:"compressionRatio"`
+	BytesPerDatapoint       float64 `json:"bytesPerDatapoint"`
+	EncodeNanosPerDatapoint float64 `json:"encodeNanosPerDatapoint"`
+	DecodeNanosPerDatapoint float64 `json:"decodeNanosPerDatapoint"`
+}
+
+func (h *handlers) codecStats(_ thrift.Context, r *http.Request) (interface{}, error) {
+	var (
+		name = r.URL.Query().Get(namespaceNameQueryParam)
+		resp = codecStatsResponse{
+			Namespaces: make(map[string]namespaceCodecStats),
+		}
+	)
+	for _, n := range h.db.Namespaces() {
+		id := n.ID().String()
+		if name != "" && id != name {
+			continue
+		}
+
+		codec := "m3tsz"
+		if n.Schema() != nil {
+			codec = "proto"
+		}
+		stats := n.CodecStats()
+		resp.Namespaces[id] = namespaceCodecStats{
+			Codec:                   codec,
+			Blocks:                  stats.Blocks,
+			Datapoints:              stats.Datapoints,
+			EncodedBytes:            stats.EncodedBytes,
+			Errors:                  stats.Errors,
+			CompressionRatio:        stats.CompressionRatio(),
+			BytesPerDatapoint:       stats.BytesPerDatapoint(),
+			EncodeNanosPerDatapoint: stats.EncodeNanosPerDatapoint(),
+			DecodeNanosPerDatapoint: stats.DecodeNanosPerDatapoint(),
+		}
+	}
+
+	if name != "" && len(resp.Namespaces) == 0 {
+		err := fmt.Errorf("namespace not found: %s", name)
+		return nil, httpjson.NewError(err, http.StatusNotFound)
+	}
+
+	return resp, nil
+}
+
+// importRegisterRequest is a request to register a fileset volume built
+// offline by the bulk import tool and placed alongside the node's filesets.
+type importRegisterRequest struct {
+	Namespace  string    `json:"namespace"`
+	Shard      uint32    `json:"shard"`
+	BlockStart time.Time `json:"blockStart"`
+	Volume     int       `json:"volume"`
+}
+
+func (h *handlers) importRegister(_ thrift.Context, r *http.Request) (interface{}, error) {
+	var req importRegisterRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Namespace == "" {
+		return nil, xerrors.NewInvalidParamsError(errors.New("namespace is required"))
+	}
+	if req.BlockStart.IsZero() {
+		return nil, xerrors.NewInvalidParamsError(errors.New("block start is required"))
+	}
+	if req.Volume < 0 {
+		return nil, xerrors.NewInvalidParamsError(errors.New("volume must not be negative"))
+	}
+
+	id := ident.StringID(req.Namespace)
+	if _, ok := h.db.Namespace(id); !ok {
+		err := fmt.Errorf("namespace not found: %s", req.Namespace)
+		return nil, httpjson.NewError(err, http.StatusNotFound)
+	}
+	return nil, h.db.RegisterImportedVolume(id, req.Shard,
+		xtime.ToUnixNano(req.BlockStart), req.Volume)
+}
+
+func decodeRequest(r *http.Request, req interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil {
+		err = fmt.Errorf("invalid request body: %v", err)
+		return xerrors.NewInvalidParamsError(err)
+	}
+	return nil
+}
+
+func encodeResult(buff *bytes.Buffer, result interface{}) error {
+	switch v := result.(type) {
+	case nil:
+		_, err := buff.WriteString("{}\n")
+		return err
+	case proto.Message:
+		marshaler := jsonpb.Marshaler{EmitDefaults: true}
+		return marshaler.Marshal(buff, v)
+	default:
+		return json.NewEncoder(buff).Encode(v)
+	}
+}
+
+// toHTTPError maps the node service errors onto the matching status codes.
+func toHTTPError(err error) error {
+	if rpcErr, ok := err.(*rpc.Error); ok && tterrors.IsBadRequestError(rpcErr) {
+		return httpjson.NewError(err, http.StatusBadRequest)
+	}
+	return err
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/server/httpjson"
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/series"
+	"github.com/m3db/m3/src/x/clock"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/ident"
+	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMux(
+	service rpc.TChanNode,
+	db storage.Database,
+	opts httpjson.ServerOptions,
+) *http.ServeMux {
+	if opts == nil {
+		opts = httpjson.NewServerOptions()
+	}
+	mux := http.NewServeMux()
+	RegisterHandlers(mux, service, db, opts)
+	return mux
+}
+
+func serve(mux *http.ServeMux, method, url, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	mux.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestHealth(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	service := rpc.NewMockTChanNode(ctrl)
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{
+		Ok:           true,
+		Status:       "up",
+		Bootstrapped: true,
+	}, nil)
+
+	mux := newTestMux(service, nil, nil)
+
+	recorder := serve(mux, http.MethodGet, HealthURL, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"ok":true,"status":"up","bootstrapped":true}`,
+		recorder.Body.String())
+
+	recorder = serve(mux, http.MethodPost, HealthURL, "")
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestHealthWithVolumes(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	// Pin the clock so the recorded operation does not decay below the
+	// minimum number of operations before the volumes are read.
+	now := time.Now()
+	tracker, err := iohealth.NewTracker(iohealth.NewOptions().
+		SetMinOperations(1).
+		SetClockOptions(clock.NewOptions().SetNowFn(func() time.Time {
+			return now
+		})))
+	require.NoError(t, err)
+	tracker.Record("/var/lib/m3db", iohealth.OpFsync, time.Minute, nil)
+
+	storageOpts := storage.DefaultTestOptions()
+	storageOpts = storageOpts.SetCommitLogOptions(storageOpts.CommitLogOptions().
+		SetFilesystemOptions(storageOpts.CommitLogOptions().FilesystemOptions().
+			SetIOHealthTracker(tracker)))
+
+	service := rpc.NewMockTChanNode(ctrl)
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{
+		Ok:           true,
+		Status:       "up",
+		Bootstrapped: true,
+	}, nil)
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().Options().Return(storageOpts)
+
+	mux := newTestMux(service, db, nil)
+
+	recorder := serve(mux, http.MethodGet, HealthURL, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{
+		"ok": true,
+		"status": "up",
+		"bootstrapped": true,
+		"volumes": [
+			{"path": "/var/lib/m3db", "score": 0, "healthy": false, "excluded": false}
+		]
+	}`, recorder.Body.String())
+}
+
+func TestBootstrapped(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	service := rpc.NewMockTChanNode(ctrl)
+	gomock.InOrder(
+		service.EXPECT().Bootstrapped(gomock.Any()).
+			Return(nil, tterrors.NewInternalError(errors.New("not bootstrapped"))),
+		service.EXPECT().Bootstrapped(gomock.Any()).
+			Return(&rpc.NodeBootstrappedResult_{}, nil),
+	)
+
+	mux := newTestMux(service, nil, nil)
+
+	recorder := serve(mux, http.MethodGet, BootstrappedURL, "")
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	require.True(t, strings.Contains(recorder.Body.String(), "not bootstrapped"))
+
+	recorder = serve(mux, http.MethodGet, BootstrappedURL, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestNamespaces(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var namespaces []storage.Namespace
+	for _, name := range []string{"metrics", "metrics_agg"} {
+		ns := storage.NewMockNamespace(ctrl)
+		ns.EXPECT().ID().Return(ident.StringID(name)).AnyTimes()
+		ns.EXPECT().Options().Return(namespace.NewOptions().
+			SetRetentionOptions(namespace.NewOptions().RetentionOptions().
+				SetRetentionPeriod(48 * time.Hour))).AnyTimes()
+		namespaces = append(namespaces, ns)
+	}
+
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().Namespaces().Return(namespaces).AnyTimes()
+
+	mux := newTestMux(nil, db, nil)
+
+	recorder := serve(mux, http.MethodGet, NamespacesURL, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	require.True(t, strings.Contains(body, `"metrics"`))
+	require.True(t, strings.Contains(body, `"metrics_agg"`))
+	require.True(t, strings.Contains(body, `"retentionPeriodNanos":"172800000000000"`))
+
+	recorder = serve(mux, http.MethodGet, NamespacesURL+"?name=metrics_agg", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body = recorder.Body.String()
+	require.False(t, strings.Contains(body, `"metrics"`))
+	require.True(t, strings.Contains(body, `"metrics_agg"`))
+
+	recorder = serve(mux, http.MethodGet, NamespacesURL+"?name=unknown", "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestRepair(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	service := rpc.NewMockTChanNode(ctrl)
+	service.EXPECT().Repair(gomock.Any()).Return(nil)
+
+	mux := newTestMux(service, nil, nil)
+
+	recorder := serve(mux, http.MethodGet, RepairURL, "")
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder = serve(mux, http.MethodPost, RepairURL, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{}`, recorder.Body.String())
+}
+
+func TestProfileStartStop(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	service := rpc.NewMockTChanNode(ctrl)
+	service.EXPECT().
+		DebugProfileStart(gomock.Any(), &rpc.DebugProfileStartRequest{
+			Name:             "cpu",
+			FilePathTemplate: "/tmp/{{.ProfileName}}",
+		}).
+		Return(&rpc.DebugProfileStartResult_{}, nil)
+	service.EXPECT().
+		DebugProfileStop(gomock.Any(), &rpc.DebugProfileStopRequest{Name: "cpu"}).
+		Return(nil, tterrors.NewBadRequestError(errors.New("profile does not exist")))
+
+	mux := newTestMux(service, nil, nil)
+
+	recorder := serve(mux, http.MethodPost, ProfileStartURL, `{"unknown":true}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = serve(mux, http.MethodPost, ProfileStartURL,
+		`{"name":"cpu","filePathTemplate":"/tmp/{{.ProfileName}}"}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = serve(mux, http.MethodPost, ProfileStopURL, `{"name":"cpu"}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.True(t, strings.Contains(recorder.Body.String(), "profile does not exist"))
+}
+
+func TestIndexCompact(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	blockStart := xtime.ToUnixNano(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	idx := storage.NewMockNamespaceIndex(ctrl)
+	idx.EXPECT().Compact(xtime.UnixNano(0), 2).Return([]index.BlockCompactResult{
+		{NumSegmentsCompacted: 3, NumDocs: 100},
+		{BlockStart: blockStart, NumSegmentsCompacted: 2, NumDocs: 10},
+	}, nil)
+	idx.EXPECT().Compact(blockStart, 0).Return([]index.BlockCompactResult{
+		{BlockStart: blockStart},
+	}, nil)
+
+	ns := storage.NewMockNamespace(ctrl)
+	ns.EXPECT().Index().Return(idx, nil).AnyTimes()
+
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().Namespace(ident.NewIDMatcher("metrics")).Return(ns, true).AnyTimes()
+	db.EXPECT().Namespace(ident.NewIDMatcher("unknown")).Return(nil, false)
+
+	mux := newTestMux(nil, db, nil)
+
+	recorder := serve(mux, http.MethodPost, IndexCompactURL, `{}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = serve(mux, http.MethodPost, IndexCompactURL, `{"namespace":"unknown"}`)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = serve(mux, http.MethodPost, IndexCompactURL,
+		`{"namespace":"metrics","concurrency":-1}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = serve(mux, http.MethodPost, IndexCompactURL,
+		`{"namespace":"metrics","concurrency":2}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var resp indexCompactResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, 2, len(resp.Blocks))
+	require.True(t, resp.Blocks[0].Active)
+	require.Equal(t, 3, resp.Blocks[0].NumSegmentsCompacted)
+	require.Equal(t, int64(100), resp.Blocks[0].NumDocs)
+	require.False(t, resp.Blocks[1].Active)
+	require.True(t, blockStart.ToTime().Equal(resp.Blocks[1].BlockStart))
+	require.Equal(t, 2, resp.Blocks[1].NumSegmentsCompacted)
+	require.Equal(t, int64(10), resp.Blocks[1].NumDocs)
+
+	recorder = serve(mux, http.MethodPost, IndexCompactURL,
+		`{"namespace":"metrics","blockStart":"2021-01-01T00:00:00Z"}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestImportRegister(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	blockStart := xtime.ToUnixNano(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().Namespace(ident.NewIDMatcher("metrics")).
+		Return(storage.NewMockNamespace(ctrl), true).AnyTimes()
+	db.EXPECT().Namespace(ident.NewIDMatcher("unknown")).Return(nil, false)
+	gomock.InOrder(
+		db.EXPECT().RegisterImportedVolume(ident.NewIDMatcher("metrics"),
+			uint32(3), blockStart, 1).Return(nil),
+		db.EXPECT().RegisterImportedVolume(ident.NewIDMatcher("metrics"),
+			uint32(3), blockStart, 2).
+			Return(xerrors.NewInvalidParamsError(errors.New("invalid volume"))),
+	)
+
+	mux := newTestMux(nil, db, nil)
+
+	recorder := serve(mux, http.MethodGet, ImportRegisterURL, "")
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	for _, body := range []string{
+		`{"shard":3,"blockStart":"2021-01-01T00:00:00Z","volume":1}`,
+		`{"namespace":"metrics","shard":3,"volume":1}`,
+		`{"namespace":"metrics","shard":3,"blockStart":"2021-01-01T00:00:00Z","volume":-1}`,
+	} {
+		recorder = serve(mux, http.MethodPost, ImportRegisterURL, body)
+		require.Equal(t, http.StatusBadRequest, recorder.Code, body)
+	}
+
+	recorder = serve(mux, http.MethodPost, ImportRegisterURL,
+		`{"namespace":"unknown","shard":3,"blockStart":"2021-01-01T00:00:00Z","volume":1}`)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = serve(mux, http.MethodPost, ImportRegisterURL,
+		`{"namespace":"metrics","shard":3,"blockStart":"2021-01-01T00:00:00Z","volume":1}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = serve(mux, http.MethodPost, ImportRegisterURL,
+		`{"namespace":"metrics","shard":3,"blockStart":"2021-01-01T00:00:00Z","volume":2}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestCodecStats(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	ns := storage.NewMockNamespace(ctrl)
+	ns.EXPECT().ID().Return(ident.StringID("metrics")).AnyTimes()
+	ns.EXPECT().Schema().Return(nil).AnyTimes()
+	ns.EXPECT().CodecStats().Return(series.CodecStatsSnapshot{
+		Blocks:       2,
+		Datapoints:   100,
+		EncodedBytes: 200,
+		EncodeNanos:  5000,
+		DecodeNanos:  3000,
+	}).AnyTimes()
+
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().Namespaces().Return([]storage.Namespace{ns}).AnyTimes()
+
+	mux := newTestMux(nil, db, nil)
+
+	recorder := serve(mux, http.MethodGet, CodecStatsURL, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var resp codecStatsResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	stats, ok := resp.Namespaces["metrics"]
+	require.True(t, ok)
+	require.Equal(t, "m3tsz", stats.Codec)
+	require.Equal(t, int64(100), stats.Datapoints)
+	require.Equal(t, 8.0, stats.CompressionRatio)
+	require.Equal(t, 2.0, stats.BytesPerDatapoint)
+	require.Equal(t, 50.0, stats.EncodeNanosPerDatapoint)
+	require.Equal(t, 30.0, stats.DecodeNanosPerDatapoint)
+
+	recorder = serve(mux, http.MethodGet, CodecStatsURL+"?name=unknown", "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestAuthenticateFn(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	service := rpc.NewMockTChanNode(ctrl)
+	service.EXPECT().Repair(gomock.Any()).Return(nil)
+
+	opts := httpjson.NewServerOptions().
+		SetAuthenticateFn(func(r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				return errors.New("invalid token")
+			}
+			return nil
+		})
+	mux := newTestMux(service, nil, opts)
+
+	recorder := serve(mux, http.MethodPost, RepairURL, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, RepairURL, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	mux.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
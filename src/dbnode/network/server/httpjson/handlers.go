@@ -84,6 +84,7 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 	t := v.Type()
 	contextFn := opts.ContextFn()
 	postResponseFn := opts.PostResponseFn()
+	authenticateFn := opts.AuthenticateFn()
 	for i := 0; i < t.NumMethod(); i++ {
 		method := t.Method(i)
 
@@ -145,13 +146,17 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 			// Always close the request body
 			defer r.Body.Close()
 
+			if !Authenticate(w, r, authenticateFn) {
+				return
+			}
+
 			httpMethod := strings.ToUpper(r.Method)
 			if reqIn == nil && httpMethod != "GET" {
-				writeError(w, errRequestMustBeGet)
+				WriteError(w, errRequestMustBeGet)
 				return
 			}
 			if reqIn != nil && httpMethod != "POST" {
-				writeError(w, errRequestMustBePost)
+				WriteError(w, errRequestMustBePost)
 				return
 			}
 
@@ -173,7 +178,7 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 				}
 				if err := decoder.Decode(in); err != nil {
 					err := fmt.Errorf("invalid request body: %v", err)
-					writeError(w, xerrors.NewInvalidParamsError(err))
+					WriteError(w, xerrors.NewInvalidParamsError(err))
 					return
 				}
 			}
@@ -206,7 +211,7 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 
 				// Deal with error case
 				if !ret[0].IsNil() {
-					writeError(w, ret[0].Interface())
+					WriteError(w, ret[0].Interface())
 					return
 				}
 				json.NewEncoder(w).Encode(&respSuccess{})
@@ -226,13 +231,13 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 
 			// Deal with error case
 			if !ret[1].IsNil() {
-				writeError(w, ret[1].Interface())
+				WriteError(w, ret[1].Interface())
 				return
 			}
 
 			buff := bytes.NewBuffer(nil)
 			if err := json.NewEncoder(buff).Encode(ret[0].Interface()); err != nil {
-				writeError(w, fmt.Errorf("failed to encode response body: %v", err))
+				WriteError(w, fmt.Errorf("failed to encode response body: %v", err))
 				return
 			}
 
@@ -243,7 +248,22 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 	return nil
 }
 
-func writeError(w http.ResponseWriter, errValue interface{}) {
+// Authenticate authenticates the request with the authenticate fn if set,
+// writing an unauthorized error and returning false if it is rejected.
+func Authenticate(w http.ResponseWriter, r *http.Request, fn AuthenticateFn) bool {
+	if fn == nil {
+		return true
+	}
+	if err := fn(r); err != nil {
+		WriteError(w, NewError(err, http.StatusUnauthorized))
+		return false
+	}
+	return true
+}
+
+// WriteError writes an error as a JSON error result with the status code
+// matching the error.
+func WriteError(w http.ResponseWriter, errValue interface{}) {
 	result := respErrorResult{respError{}}
 	if value, ok := errValue.(error); ok {
 		result.Error.Message = value.Error()
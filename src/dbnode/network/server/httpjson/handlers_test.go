@@ -108,6 +108,41 @@ func TestRegisterHandlersRequestPostRequestFn(t *testing.T) {
 	require.Equal(t, 1, calledPostRequestFn)
 }
 
+func TestRegisterHandlersRequestAuthenticateFn(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	mux := http.NewServeMux()
+
+	client := newTestClient(ctrl)
+	service := cluster.NewService(client)
+
+	opts := NewServerOptions().
+		SetAuthenticateFn(func(r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				return errors.New("invalid token")
+			}
+			return nil
+		})
+
+	err := RegisterHandlers(mux, service, opts)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	mux.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.True(t, strings.Contains(recorder.Body.String(), "invalid token"))
+
+	recorder = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	mux.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
 func TestRegisterHandlersRequestUnknownFieldsBadRequestError(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
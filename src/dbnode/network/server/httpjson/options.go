@@ -21,6 +21,7 @@
 package httpjson
 
 import (
+	"net/http"
 	"time"
 
 	apachethrift "github.com/uber/tchannel-go/thirdparty/github.com/apache/thrift/lib/go/thrift"
@@ -41,6 +42,10 @@ type ContextFn func(ctx context.Context, method string, headers map[string]strin
 // PostResponseFn is a function that is called at the end of a request
 type PostResponseFn func(ctx context.Context, method string, response apachethrift.TStruct)
 
+// AuthenticateFn is a function that authenticates an incoming request before
+// it is served, returning an error rejects the request as unauthorized
+type AuthenticateFn func(r *http.Request) error
+
 // ServerOptions is a set of server options
 type ServerOptions interface {
 	// SetReadTimeout sets the read timeout and returns a new ServerOptions
@@ -72,6 +77,18 @@ type ServerOptions interface {
 
 	// PostResponseFn returns the post response fn
 	PostResponseFn() PostResponseFn
+
+	// SetAuthenticateFn sets the authenticate fn and returns a new ServerOptions
+	SetAuthenticateFn(value AuthenticateFn) ServerOptions
+
+	// AuthenticateFn returns the authenticate fn
+	AuthenticateFn() AuthenticateFn
+
+	// SetHandlers sets additional handlers to serve by path and returns a new ServerOptions
+	SetHandlers(value map[string]http.Handler) ServerOptions
+
+	// Handlers returns the additional handlers to serve by path
+	Handlers() map[string]http.Handler
 }
 
 type serverOptions struct {
@@ -80,6 +97,8 @@ type serverOptions struct {
 	requestTimeout time.Duration
 	contextFn      ContextFn
 	postResponseFn PostResponseFn
+	authenticateFn AuthenticateFn
+	handlers       map[string]http.Handler
 }
 
 // NewServerOptions creates a new set of server options with defaults
@@ -140,3 +159,23 @@ func (o *serverOptions) SetPostResponseFn(value PostResponseFn) ServerOptions {
 func (o *serverOptions) PostResponseFn() PostResponseFn {
 	return o.postResponseFn
 }
+
+func (o *serverOptions) SetAuthenticateFn(value AuthenticateFn) ServerOptions {
+	opts := *o
+	opts.authenticateFn = value
+	return &opts
+}
+
+func (o *serverOptions) AuthenticateFn() AuthenticateFn {
+	return o.authenticateFn
+}
+
+func (o *serverOptions) SetHandlers(value map[string]http.Handler) ServerOptions {
+	opts := *o
+	opts.handlers = value
+	return &opts
+}
+
+func (o *serverOptions) Handlers() map[string]http.Handler {
+	return o.handlers
+}
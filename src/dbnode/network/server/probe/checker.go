@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+
+	"github.com/uber/tchannel-go/thrift"
+	"go.uber.org/atomic"
+)
+
+var errNodeNotOk = errors.New("node is not ok")
+
+type checker struct {
+	service rpc.TChanNode
+	opts    Options
+	nowFn   func() time.Time
+	start   time.Time
+	// ready is set once the node was ready for the first time, after which
+	// the bootstrap grace period no longer applies.
+	ready atomic.Bool
+}
+
+// NewChecker returns a checker of the liveness and readiness of the node
+// served by the service, the bootstrap grace period starts when the checker
+// is created.
+func NewChecker(service rpc.TChanNode, opts Options) (Checker, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	nowFn := opts.ClockOptions().NowFn()
+	return &checker{
+		service: service,
+		opts:    opts,
+		nowFn:   nowFn,
+		start:   nowFn(),
+	}, nil
+}
+
+// Live reports the node as live if it is ok and it either became ready once
+// or is still within the bootstrap grace period.
+func (c *checker) Live() error {
+	ctx, cancel := thrift.NewContext(c.opts.CheckTimeout())
+	defer cancel()
+
+	if err := c.checkOk(ctx); err != nil {
+		return err
+	}
+
+	grace := c.opts.BootstrapGracePeriod()
+	if grace == 0 || c.ready.Load() || c.nowFn().Sub(c.start) <= grace {
+		return nil
+	}
+	if err := c.checkBootstrapped(ctx); err != nil {
+		return fmt.Errorf("node not ready within bootstrap grace period of %s: %w", grace, err)
+	}
+	return nil
+}
+
+// Ready reports the node as ready if it is ok and bootstrapped, or if no
+// placement is set yet and the options allow it.
+func (c *checker) Ready() error {
+	ctx, cancel := thrift.NewContext(c.opts.CheckTimeout())
+	defer cancel()
+
+	if err := c.checkOk(ctx); err != nil {
+		return err
+	}
+	return c.checkBootstrapped(ctx)
+}
+
+func (c *checker) checkOk(ctx thrift.Context) error {
+	result, err := c.service.Health(ctx)
+	if err != nil {
+		return err
+	}
+	if !result.Ok {
+		return errNodeNotOk
+	}
+	return nil
+}
+
+func (c *checker) checkBootstrapped(ctx thrift.Context) error {
+	var err error
+	if c.opts.ReadyWithoutPlacement() {
+		_, err = c.service.BootstrappedInPlacementOrNoPlacement(ctx)
+	} else {
+		_, err = c.service.Bootstrapped(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.ready.Store(true)
+	return nil
+}
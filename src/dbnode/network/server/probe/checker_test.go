@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package probe
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotBootstrapped = errors.New("node is not bootstrapped")
+
+func TestCheckerReady(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := rpc.NewMockTChanNode(ctrl)
+	checker, err := NewChecker(service, NewOptions())
+	require.NoError(t, err)
+
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: false}, nil)
+	require.Equal(t, errNodeNotOk, checker.Ready())
+
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	service.EXPECT().BootstrappedInPlacementOrNoPlacement(gomock.Any()).
+		Return(nil, errNotBootstrapped)
+	require.Equal(t, errNotBootstrapped, checker.Ready())
+
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	service.EXPECT().BootstrappedInPlacementOrNoPlacement(gomock.Any()).
+		Return(&rpc.NodeBootstrappedInPlacementOrNoPlacementResult_{}, nil)
+	require.NoError(t, checker.Ready())
+}
+
+func TestCheckerReadyRequiresPlacement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := rpc.NewMockTChanNode(ctrl)
+	checker, err := NewChecker(service, NewOptions().SetReadyWithoutPlacement(false))
+	require.NoError(t, err)
+
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	service.EXPECT().Bootstrapped(gomock.Any()).Return(nil, errNotBootstrapped)
+	require.Equal(t, errNotBootstrapped, checker.Ready())
+}
+
+func TestCheckerLiveBootstrapGracePeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	opts := NewOptions().SetBootstrapGracePeriod(time.Minute)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	service := rpc.NewMockTChanNode(ctrl)
+	checker, err := NewChecker(service, opts)
+	require.NoError(t, err)
+
+	// Live while bootstrapping within the grace period.
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	require.NoError(t, checker.Live())
+
+	// Not live once the grace period elapsed without becoming ready.
+	now = now.Add(2 * time.Minute)
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	service.EXPECT().BootstrappedInPlacementOrNoPlacement(gomock.Any()).
+		Return(nil, errNotBootstrapped)
+	err = checker.Live()
+	require.Error(t, err)
+	require.True(t, errors.Is(err, errNotBootstrapped))
+
+	// Live again once ready, the grace period no longer applies after.
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	service.EXPECT().BootstrappedInPlacementOrNoPlacement(gomock.Any()).
+		Return(&rpc.NodeBootstrappedInPlacementOrNoPlacementResult_{}, nil)
+	require.NoError(t, checker.Live())
+
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	require.NoError(t, checker.Live())
+
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: false}, nil)
+	require.Equal(t, errNodeNotOk, checker.Live())
+}
+
+func TestCheckerLiveWithoutGracePeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := rpc.NewMockTChanNode(ctrl)
+	checker, err := NewChecker(service, NewOptions())
+	require.NoError(t, err)
+
+	service.EXPECT().Health(gomock.Any()).Return(&rpc.NodeHealthResult_{Ok: true}, nil)
+	require.NoError(t, checker.Live())
+}
+
+func TestOptionsValidate(t *testing.T) {
+	require.NoError(t, NewOptions().Validate())
+	require.Equal(t, errNegativeBootstrapGracePeriod,
+		NewOptions().SetBootstrapGracePeriod(-time.Second).Validate())
+	require.Equal(t, errNonPositiveCheckTimeout,
+		NewOptions().SetCheckTimeout(0).Validate())
+	require.Equal(t, errNonPositiveCheckInterval,
+		NewOptions().SetCheckInterval(0).Validate())
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package probe
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Configuration is the configuration of the liveness and readiness probes.
+type Configuration struct {
+	// GRPCListenAddress is the host and port on which to serve the gRPC
+	// health service, the gRPC health service is disabled if not set.
+	GRPCListenAddress string `yaml:"grpcListenAddress"`
+
+	// ReadyWithoutPlacement reports the node as ready when no placement is
+	// set yet so that the cluster can be initialized, defaults to true.
+	ReadyWithoutPlacement *bool `yaml:"readyWithoutPlacement"`
+
+	// BootstrapGracePeriod is how long the node may take to become ready
+	// before it is reported as not live, bootstrapping nodes are always
+	// reported as live if not set.
+	BootstrapGracePeriod time.Duration `yaml:"bootstrapGracePeriod" validate:"min=0"`
+
+	// CheckTimeout is the timeout of a single check.
+	CheckTimeout *time.Duration `yaml:"checkTimeout"`
+
+	// CheckInterval is how often the gRPC serving statuses are updated.
+	CheckInterval *time.Duration `yaml:"checkInterval"`
+}
+
+// NewOptions returns the probe options for the configuration.
+func (c Configuration) NewOptions(iOpts instrument.Options) (Options, error) {
+	opts := NewOptions().
+		SetInstrumentOptions(iOpts).
+		SetBootstrapGracePeriod(c.BootstrapGracePeriod)
+	if c.ReadyWithoutPlacement != nil {
+		opts = opts.SetReadyWithoutPlacement(*c.ReadyWithoutPlacement)
+	}
+	if c.CheckTimeout != nil {
+		opts = opts.SetCheckTimeout(*c.CheckTimeout)
+	}
+	if c.CheckInterval != nil {
+		opts = opts.SetCheckInterval(*c.CheckInterval)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package probe
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultReadyWithoutPlacement = true
+	defaultCheckTimeout          = 5 * time.Second
+	defaultCheckInterval         = time.Second
+)
+
+var (
+	errNegativeBootstrapGracePeriod = errors.New("bootstrap grace period must not be negative")
+	errNonPositiveCheckTimeout      = errors.New("check timeout must be positive")
+	errNonPositiveCheckInterval     = errors.New("check interval must be positive")
+)
+
+type options struct {
+	clockOpts             clock.Options
+	instrumentOpts        instrument.Options
+	readyWithoutPlacement bool
+	bootstrapGracePeriod  time.Duration
+	checkTimeout          time.Duration
+	checkInterval         time.Duration
+}
+
+// NewOptions creates a new set of probe options.
+func NewOptions() Options {
+	return &options{
+		clockOpts:             clock.NewOptions(),
+		instrumentOpts:        instrument.NewOptions(),
+		readyWithoutPlacement: defaultReadyWithoutPlacement,
+		checkTimeout:          defaultCheckTimeout,
+		checkInterval:         defaultCheckInterval,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.bootstrapGracePeriod < 0 {
+		return errNegativeBootstrapGracePeriod
+	}
+	if o.checkTimeout <= 0 {
+		return errNonPositiveCheckTimeout
+	}
+	if o.checkInterval <= 0 {
+		return errNonPositiveCheckInterval
+	}
+	return nil
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetReadyWithoutPlacement(value bool) Options {
+	opts := *o
+	opts.readyWithoutPlacement = value
+	return &opts
+}
+
+func (o *options) ReadyWithoutPlacement() bool {
+	return o.readyWithoutPlacement
+}
+
+func (o *options) SetBootstrapGracePeriod(value time.Duration) Options {
+	opts := *o
+	opts.bootstrapGracePeriod = value
+	return &opts
+}
+
+func (o *options) BootstrapGracePeriod() time.Duration {
+	return o.bootstrapGracePeriod
+}
+
+func (o *options) SetCheckTimeout(value time.Duration) Options {
+	opts := *o
+	opts.checkTimeout = value
+	return &opts
+}
+
+func (o *options) CheckTimeout() time.Duration {
+	return o.checkTimeout
+}
+
+func (o *options) SetCheckInterval(value time.Duration) Options {
+	opts := *o
+	opts.checkInterval = value
+	return &opts
+}
+
+func (o *options) CheckInterval() time.Duration {
+	return o.checkInterval
+}
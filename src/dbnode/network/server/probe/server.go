@@ -0,0 +1,134 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package probe
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	ns "github.com/m3db/m3/src/dbnode/network/server"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type probeResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewHandlers returns the HTTP handlers of the liveness and readiness probes
+// by path, a probe responds with 200 if it passes and 503 otherwise.
+func NewHandlers(checker Checker) map[string]http.Handler {
+	return map[string]http.Handler{
+		LivePath:  newHandler(checker.Live),
+		ReadyPath: newHandler(checker.Ready),
+	}
+}
+
+func newHandler(check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, resp := http.StatusOK, probeResponse{OK: true}
+		if err := check(); err != nil {
+			status, resp = http.StatusServiceUnavailable, probeResponse{Error: err.Error()}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp) // nolint: errcheck
+	})
+}
+
+type grpcServer struct {
+	checker Checker
+	address string
+	opts    Options
+}
+
+// NewGRPCServer creates a server of the standard gRPC health service which
+// reports liveness for the LivenessService and the overall health of the
+// server, and readiness for the ReadinessService.
+func NewGRPCServer(checker Checker, address string, opts Options) ns.NetworkService {
+	return &grpcServer{
+		checker: checker,
+		address: address,
+		opts:    opts,
+	}
+}
+
+func (s *grpcServer) ListenAndServe() (ns.Close, error) {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	updateStatuses(healthServer, s.checker)
+
+	closeCh := make(chan struct{})
+	go s.updateStatusesEvery(healthServer, closeCh)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			s.opts.InstrumentOptions().Logger().Error("grpc health server stopped",
+				zap.Error(err))
+		}
+	}()
+
+	return func() {
+		close(closeCh)
+		healthServer.Shutdown()
+		server.Stop()
+	}, nil
+}
+
+func (s *grpcServer) updateStatusesEvery(healthServer *health.Server, closeCh <-chan struct{}) {
+	ticker := time.NewTicker(s.opts.CheckInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			updateStatuses(healthServer, s.checker)
+		case <-closeCh:
+			return
+		}
+	}
+}
+
+func updateStatuses(healthServer *health.Server, checker Checker) {
+	live := servingStatus(checker.Live())
+	healthServer.SetServingStatus("", live)
+	healthServer.SetServingStatus(LivenessService, live)
+	healthServer.SetServingStatus(ReadinessService, servingStatus(checker.Ready()))
+}
+
+func servingStatus(err error) healthpb.HealthCheckResponse_ServingStatus {
+	if err != nil {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package probe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type testChecker struct {
+	liveErr  error
+	readyErr error
+}
+
+func (c testChecker) Live() error  { return c.liveErr }
+func (c testChecker) Ready() error { return c.readyErr }
+
+func TestHandlers(t *testing.T) {
+	handlers := NewHandlers(testChecker{readyErr: errors.New("bootstrapping")})
+
+	recorder := httptest.NewRecorder()
+	handlers[LivePath].ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LivePath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"ok":true}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handlers[ReadyPath].ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadyPath, nil))
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	require.JSONEq(t, `{"ok":false,"error":"bootstrapping"}`, recorder.Body.String())
+}
+
+func TestUpdateStatuses(t *testing.T) {
+	healthServer := health.NewServer()
+	updateStatuses(healthServer, testChecker{readyErr: errors.New("bootstrapping")})
+
+	for service, expected := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":               healthpb.HealthCheckResponse_SERVING,
+		LivenessService:  healthpb.HealthCheckResponse_SERVING,
+		ReadinessService: healthpb.HealthCheckResponse_NOT_SERVING,
+	} {
+		resp, err := healthServer.Check(context.Background(),
+			&healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		require.Equal(t, expected, resp.Status, service)
+	}
+}
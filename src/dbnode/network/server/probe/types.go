@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package probe reports whether a node is live, meaning the process is
+// healthy and should not be restarted, separately from whether it is ready,
+// meaning it is bootstrapped and serving, so that orchestration tools do not
+// restart nodes during long bootstraps. The probes are served over HTTP and
+// the standard gRPC health checking protocol.
+package probe
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	// LivePath is the HTTP path of the liveness probe.
+	LivePath = "/live"
+
+	// ReadyPath is the HTTP path of the readiness probe.
+	ReadyPath = "/ready"
+
+	// LivenessService is the gRPC health service name of the liveness probe,
+	// the overall health of the server with an empty service name reports
+	// liveness as well.
+	LivenessService = "liveness"
+
+	// ReadinessService is the gRPC health service name of the readiness probe.
+	ReadinessService = "readiness"
+)
+
+// Checker checks the liveness and readiness of a node.
+type Checker interface {
+	// Live returns an error if the node is not live.
+	Live() error
+
+	// Ready returns an error if the node is not ready.
+	Ready() error
+}
+
+// Options is a set of probe options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetClockOptions sets the clock options.
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+
+	// SetReadyWithoutPlacement sets whether the node is ready when no
+	// placement is set yet.
+	SetReadyWithoutPlacement(value bool) Options
+
+	// ReadyWithoutPlacement returns whether the node is ready when no
+	// placement is set yet.
+	ReadyWithoutPlacement() bool
+
+	// SetBootstrapGracePeriod sets how long the node may take to become ready
+	// before it is reported as not live, zero never reports a bootstrapping
+	// node as not live.
+	SetBootstrapGracePeriod(value time.Duration) Options
+
+	// BootstrapGracePeriod returns how long the node may take to become ready
+	// before it is reported as not live, zero never reports a bootstrapping
+	// node as not live.
+	BootstrapGracePeriod() time.Duration
+
+	// SetCheckTimeout sets the timeout of a single check.
+	SetCheckTimeout(value time.Duration) Options
+
+	// CheckTimeout returns the timeout of a single check.
+	CheckTimeout() time.Duration
+
+	// SetCheckInterval sets how often the gRPC serving statuses are updated.
+	SetCheckInterval(value time.Duration) Options
+
+	// CheckInterval returns how often the gRPC serving statuses are updated.
+	CheckInterval() time.Duration
+}
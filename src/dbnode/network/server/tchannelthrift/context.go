@@ -33,6 +33,11 @@ import (
 
 const (
 	contextKey = "m3dbcontext"
+
+	// WaitForIndexHeader is the header a caller sets to true for tagged
+	// writes to only return once the written series are queryable, for
+	// callers that immediately read after writing.
+	WaitForIndexHeader = "M3-Wait-For-Index"
 )
 
 // RegisterServer will register a tchannel thrift server and create and close M3DB contexts per request
@@ -28,6 +28,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/x/xio"
@@ -212,9 +213,15 @@ func ToRPCError(err error) *rpc.Error {
 		return rpcErr
 	}
 
+	if details, ok := xerrors.GetErrorDetails(err); ok {
+		return tterrors.NewStructuredError(err, details)
+	}
 	if limits.IsQueryLimitExceededError(err) {
 		return tterrors.NewResourceExhaustedError(err)
 	}
+	if dberrors.IsShardNotOwnedError(err) {
+		return tterrors.NewShardNotOwnedError(err)
+	}
 	if xerrors.IsInvalidParams(err) {
 		return tterrors.NewBadRequestError(err)
 	}
@@ -249,10 +256,11 @@ func FromRPCFetchTaggedRequest(
 	}
 
 	opts := index.QueryOptions{
-		StartInclusive:    start,
-		EndExclusive:      end,
-		RequireExhaustive: req.RequireExhaustive,
-		RequireNoWait:     req.RequireNoWait,
+		StartInclusive:       start,
+		EndExclusive:         end,
+		RequireExhaustive:    req.RequireExhaustive,
+		RequireNoWait:        req.RequireNoWait,
+		FetchLatestDatapoint: req.FetchLatestDatapoint,
 	}
 	if l := req.SeriesLimit; l != nil {
 		opts.SeriesLimit = int(*l)
@@ -312,6 +320,11 @@ func ToRPCFetchTaggedRequest(
 		RequireNoWait:     opts.RequireNoWait,
 	}
 
+	if !fetchData {
+		// the latest datapoint is only returned alongside series IDs, data fetches already return every datapoint.
+		request.FetchLatestDatapoint = opts.FetchLatestDatapoint
+	}
+
 	if opts.SeriesLimit > 0 {
 		l := int64(opts.SeriesLimit)
 		request.SeriesLimit = &l
@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
@@ -180,6 +181,30 @@ func TestConvertFetchTaggedRequest(t *testing.T) {
 	}
 }
 
+func TestConvertFetchTaggedRequestLatestDatapoint(t *testing.T) {
+	ns := ident.StringID("abc")
+	q, _ := termQueryTestCase(t)
+	opts := index.QueryOptions{
+		StartInclusive:       xtime.Now().Add(-900 * time.Hour),
+		EndExclusive:         xtime.Now(),
+		FetchLatestDatapoint: true,
+	}
+
+	// the latest datapoint is only requested alongside series IDs.
+	req, err := convert.ToRPCFetchTaggedRequest(ns, index.Query{Query: q}, opts, true)
+	require.NoError(t, err)
+	require.False(t, req.FetchLatestDatapoint)
+
+	req, err = convert.ToRPCFetchTaggedRequest(ns, index.Query{Query: q}, opts, false)
+	require.NoError(t, err)
+	require.True(t, req.FetchLatestDatapoint)
+
+	_, _, observedOpts, fetchData, err := convert.FromRPCFetchTaggedRequest(&req, nil)
+	require.NoError(t, err)
+	require.False(t, fetchData)
+	require.True(t, observedOpts.FetchLatestDatapoint)
+}
+
 func TestConvertAggregateRawQueryRequest(t *testing.T) {
 	var (
 		seriesLimit       int64 = 10
@@ -296,6 +321,19 @@ func TestToRPCError(t *testing.T) {
 		tterrors.NewTimeoutError(xerrors.Wrap(stdctx.DeadlineExceeded, "wrap")),
 		convert.ToRPCError(xerrors.Wrap(stdctx.DeadlineExceeded, "wrap")),
 	)
+
+	shardNotOwnedErr := dberrors.NewShardNotOwnedError(1)
+	require.Equal(t, tterrors.NewShardNotOwnedError(shardNotOwnedErr), convert.ToRPCError(shardNotOwnedErr))
+	require.True(t, tterrors.IsShardNotOwnedErrorFlag(convert.ToRPCError(shardNotOwnedErr)))
+
+	limitWithDetailsErr := xerrors.NewInvalidParamsError(
+		limits.NewQueryLimitExceededErrorWithLimit("limit", "docs-matched", 10))
+	rpcErr := convert.ToRPCError(limitWithDetailsErr)
+	require.True(t, tterrors.IsResourceExhaustedErrorFlag(rpcErr))
+	require.Equal(t, rpc.ErrorCode_RESOURCE_EXHAUSTED, rpcErr.GetCode())
+	require.False(t, rpcErr.GetRetryable())
+	require.Equal(t, "docs-matched", rpcErr.GetResource())
+	require.Equal(t, int64(10), rpcErr.GetLimit())
 }
 
 type testPools struct {
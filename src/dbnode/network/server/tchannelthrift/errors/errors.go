@@ -24,13 +24,38 @@ import (
 	"fmt"
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	xerrors "github.com/m3db/m3/src/x/errors"
 )
 
-func newError(errType rpc.ErrorType, err error, flags int64) *rpc.Error {
+var (
+	codesToRPC = map[xerrors.Code]rpc.ErrorCode{
+		xerrors.CodeUnknown:           rpc.ErrorCode_UNKNOWN,
+		xerrors.CodeInvalidParams:     rpc.ErrorCode_INVALID_PARAMS,
+		xerrors.CodeNotFound:          rpc.ErrorCode_NOT_FOUND,
+		xerrors.CodeResourceExhausted: rpc.ErrorCode_RESOURCE_EXHAUSTED,
+		xerrors.CodeTimeout:           rpc.ErrorCode_TIMEOUT,
+		xerrors.CodeUnavailable:       rpc.ErrorCode_UNAVAILABLE,
+		xerrors.CodeInternal:          rpc.ErrorCode_INTERNAL,
+	}
+	codesFromRPC = map[rpc.ErrorCode]xerrors.Code{
+		rpc.ErrorCode_UNKNOWN:            xerrors.CodeUnknown,
+		rpc.ErrorCode_INVALID_PARAMS:     xerrors.CodeInvalidParams,
+		rpc.ErrorCode_NOT_FOUND:          xerrors.CodeNotFound,
+		rpc.ErrorCode_RESOURCE_EXHAUSTED: xerrors.CodeResourceExhausted,
+		rpc.ErrorCode_TIMEOUT:            xerrors.CodeTimeout,
+		rpc.ErrorCode_UNAVAILABLE:        xerrors.CodeUnavailable,
+		rpc.ErrorCode_INTERNAL:           xerrors.CodeInternal,
+	}
+)
+
+func newError(errType rpc.ErrorType, err error, flags int64, code xerrors.Code) *rpc.Error {
 	rpcErr := rpc.NewError()
 	rpcErr.Type = errType
 	rpcErr.Message = fmt.Sprintf("%v", err)
 	rpcErr.Flags = flags
+	rpcErr.Code = rpc.ErrorCodePtr(codesToRPC[code])
+	retryable := code.Retryable()
+	rpcErr.Retryable = &retryable
 	return rpcErr
 }
 
@@ -54,24 +79,101 @@ func IsTimeoutError(err *rpc.Error) bool {
 	return err != nil && err.Flags&int64(rpc.ErrorFlags_SERVER_TIMEOUT) != 0
 }
 
+// IsShardNotOwnedErrorFlag returns whether error has shard not owned flag.
+func IsShardNotOwnedErrorFlag(err *rpc.Error) bool {
+	return err != nil && err.Flags&int64(rpc.ErrorFlags_SHARD_NOT_OWNED) != 0
+}
+
 // NewInternalError creates a new internal error
 func NewInternalError(err error) *rpc.Error {
-	return newError(rpc.ErrorType_INTERNAL_ERROR, err, int64(rpc.ErrorFlags_NONE))
+	return newError(rpc.ErrorType_INTERNAL_ERROR, err, int64(rpc.ErrorFlags_NONE),
+		xerrors.CodeInternal)
 }
 
 // NewBadRequestError creates a new bad request error
 func NewBadRequestError(err error) *rpc.Error {
-	return newError(rpc.ErrorType_BAD_REQUEST, err, int64(rpc.ErrorFlags_NONE))
+	return newError(rpc.ErrorType_BAD_REQUEST, err, int64(rpc.ErrorFlags_NONE),
+		xerrors.CodeInvalidParams)
 }
 
 // NewResourceExhaustedError creates a new resource exhausted error.
 func NewResourceExhaustedError(err error) *rpc.Error {
-	return newError(rpc.ErrorType_BAD_REQUEST, err, int64(rpc.ErrorFlags_RESOURCE_EXHAUSTED))
+	return newError(rpc.ErrorType_BAD_REQUEST, err, int64(rpc.ErrorFlags_RESOURCE_EXHAUSTED),
+		xerrors.CodeResourceExhausted)
 }
 
 // NewTimeoutError creates a new timeout error.
 func NewTimeoutError(err error) *rpc.Error {
-	return newError(rpc.ErrorType_INTERNAL_ERROR, err, int64(rpc.ErrorFlags_SERVER_TIMEOUT))
+	return newError(rpc.ErrorType_INTERNAL_ERROR, err, int64(rpc.ErrorFlags_SERVER_TIMEOUT),
+		xerrors.CodeTimeout)
+}
+
+// NewShardNotOwnedError creates a new shard not owned error.
+func NewShardNotOwnedError(err error) *rpc.Error {
+	return newError(rpc.ErrorType_INTERNAL_ERROR, err, int64(rpc.ErrorFlags_SHARD_NOT_OWNED),
+		xerrors.CodeUnavailable)
+}
+
+// NewStructuredError creates a new error carrying the structured details,
+// the error type and flags are derived from the details so that clients
+// that do not understand the structured details can still classify it.
+func NewStructuredError(err error, details xerrors.ErrorDetails) *rpc.Error {
+	var (
+		errType = rpc.ErrorType_INTERNAL_ERROR
+		flags   = rpc.ErrorFlags_NONE
+	)
+	switch details.Code {
+	case xerrors.CodeInvalidParams, xerrors.CodeNotFound:
+		errType = rpc.ErrorType_BAD_REQUEST
+	case xerrors.CodeResourceExhausted:
+		errType = rpc.ErrorType_BAD_REQUEST
+		flags = rpc.ErrorFlags_RESOURCE_EXHAUSTED
+	case xerrors.CodeTimeout:
+		flags = rpc.ErrorFlags_SERVER_TIMEOUT
+	}
+
+	rpcErr := newError(errType, err, int64(flags), details.Code)
+	rpcErr.Retryable = &details.Retryable
+	if details.Resource != "" {
+		rpcErr.Resource = &details.Resource
+	}
+	if details.Limit != 0 {
+		rpcErr.Limit = &details.Limit
+	}
+	return rpcErr
+}
+
+// ErrorDetails returns the structured details of the error, derived from
+// the error type and flags if the error was returned by a server that
+// does not set the structured details.
+func ErrorDetails(err *rpc.Error) xerrors.ErrorDetails {
+	if err == nil {
+		return xerrors.NewErrorDetails(xerrors.CodeUnknown)
+	}
+	if !err.IsSetCode() {
+		switch {
+		case IsResourceExhaustedErrorFlag(err):
+			return xerrors.NewErrorDetails(xerrors.CodeResourceExhausted)
+		case IsTimeoutError(err):
+			return xerrors.NewErrorDetails(xerrors.CodeTimeout)
+		case IsBadRequestError(err):
+			return xerrors.NewErrorDetails(xerrors.CodeInvalidParams)
+		default:
+			return xerrors.NewErrorDetails(xerrors.CodeInternal)
+		}
+	}
+
+	code, ok := codesFromRPC[err.GetCode()]
+	if !ok {
+		code = xerrors.CodeUnknown
+	}
+	details := xerrors.NewErrorDetails(code)
+	if err.IsSetRetryable() {
+		details.Retryable = err.GetRetryable()
+	}
+	details.Resource = err.GetResource()
+	details.Limit = err.GetLimit()
+	return details
 }
 
 // NewWriteBatchRawError creates a new write batch error
@@ -82,6 +184,14 @@ func NewWriteBatchRawError(index int, err error) *rpc.WriteBatchRawError {
 	return batchErr
 }
 
+// NewShardNotOwnedWriteBatchRawError creates a new shard not owned write batch error
+func NewShardNotOwnedWriteBatchRawError(index int, err error) *rpc.WriteBatchRawError {
+	batchErr := rpc.NewWriteBatchRawError()
+	batchErr.Index = int64(index)
+	batchErr.Err = NewShardNotOwnedError(err)
+	return batchErr
+}
+
 // NewBadRequestWriteBatchRawError creates a new bad request write batch error
 func NewBadRequestWriteBatchRawError(index int, err error) *rpc.WriteBatchRawError {
 	batchErr := rpc.NewWriteBatchRawError()
@@ -24,7 +24,11 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	xerrors "github.com/m3db/m3/src/x/errors"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestErrorsAreRecognized(t *testing.T) {
@@ -50,6 +54,14 @@ func TestErrorsAreRecognized(t *testing.T) {
 			name:  "resource exhausted flag",
 			value: IsResourceExhaustedErrorFlag(NewResourceExhaustedError(someError)),
 		},
+		{
+			name:  "shard not owned flag",
+			value: IsShardNotOwnedErrorFlag(NewShardNotOwnedError(someError)),
+		},
+		{
+			name:  "shard not owned batch error flag",
+			value: IsShardNotOwnedErrorFlag(NewShardNotOwnedWriteBatchRawError(0, someError).Err),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -57,3 +69,96 @@ func TestErrorsAreRecognized(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorDetails(t *testing.T) {
+	someError := errors.New("some inner error")
+
+	tests := []struct {
+		name     string
+		err      *rpc.Error
+		expected xerrors.ErrorDetails
+	}{
+		{
+			name:     "internal error",
+			err:      NewInternalError(someError),
+			expected: xerrors.ErrorDetails{Code: xerrors.CodeInternal, Retryable: true},
+		},
+		{
+			name:     "bad request error",
+			err:      NewBadRequestError(someError),
+			expected: xerrors.ErrorDetails{Code: xerrors.CodeInvalidParams},
+		},
+		{
+			name:     "resource exhausted error",
+			err:      NewResourceExhaustedError(someError),
+			expected: xerrors.ErrorDetails{Code: xerrors.CodeResourceExhausted},
+		},
+		{
+			name:     "timeout error",
+			err:      NewTimeoutError(someError),
+			expected: xerrors.ErrorDetails{Code: xerrors.CodeTimeout, Retryable: true},
+		},
+		{
+			name: "structured error",
+			err: NewStructuredError(someError, xerrors.ErrorDetails{
+				Code:     xerrors.CodeResourceExhausted,
+				Resource: "docs-matched",
+				Limit:    100,
+			}),
+			expected: xerrors.ErrorDetails{
+				Code:     xerrors.CodeResourceExhausted,
+				Resource: "docs-matched",
+				Limit:    100,
+			},
+		},
+		{
+			name: "legacy bad request error",
+			err: &rpc.Error{
+				Type:    rpc.ErrorType_BAD_REQUEST,
+				Message: "legacy",
+			},
+			expected: xerrors.ErrorDetails{Code: xerrors.CodeInvalidParams},
+		},
+		{
+			name: "legacy resource exhausted error",
+			err: &rpc.Error{
+				Type:    rpc.ErrorType_BAD_REQUEST,
+				Message: "legacy",
+				Flags:   int64(rpc.ErrorFlags_RESOURCE_EXHAUSTED),
+			},
+			expected: xerrors.ErrorDetails{Code: xerrors.CodeResourceExhausted},
+		},
+		{
+			name: "legacy internal error",
+			err: &rpc.Error{
+				Type:    rpc.ErrorType_INTERNAL_ERROR,
+				Message: "legacy",
+			},
+			expected: xerrors.ErrorDetails{Code: xerrors.CodeInternal, Retryable: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ErrorDetails(tt.err))
+		})
+	}
+}
+
+func TestNewStructuredErrorSetsLegacyFields(t *testing.T) {
+	err := NewStructuredError(errors.New("too many"), xerrors.ErrorDetails{
+		Code:     xerrors.CodeResourceExhausted,
+		Resource: "series",
+		Limit:    10,
+	})
+	require.True(t, IsBadRequestError(err))
+	require.True(t, IsResourceExhaustedErrorFlag(err))
+	require.Equal(t, rpc.ErrorCode_RESOURCE_EXHAUSTED, err.GetCode())
+	require.False(t, err.GetRetryable())
+
+	err = NewStructuredError(errors.New("slow"), xerrors.NewErrorDetails(xerrors.CodeTimeout))
+	require.True(t, IsInternalError(err))
+	require.True(t, IsTimeoutError(err))
+	require.True(t, err.GetRetryable())
+	require.False(t, err.IsSetResource())
+	require.False(t, err.IsSetLimit())
+}
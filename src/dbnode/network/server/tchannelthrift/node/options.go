@@ -21,6 +21,8 @@
 package node
 
 import (
+	"crypto/tls"
+
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/x/instrument"
 
@@ -81,6 +83,13 @@ type Options interface {
 
 	// InstrumentOptions returns the instrumentation options.
 	InstrumentOptions() instrument.Options
+
+	// SetTLSConfig sets the TLS config to serve with, if nil the server does
+	// not use TLS.
+	SetTLSConfig(value *tls.Config) Options
+
+	// TLSConfig returns the TLS config to serve with.
+	TLSConfig() *tls.Config
 }
 
 type options struct {
@@ -88,6 +97,7 @@ type options struct {
 	instrumentOpts    instrument.Options
 	tchanChannelFn    NewTChanChannelFn
 	tchanNodeServerFn NewTChanNodeServerFn
+	tlsConfig         *tls.Config
 }
 
 // NewOptions creates a new options.
@@ -137,3 +147,13 @@ func (o *options) SetInstrumentOptions(value instrument.Options) Options {
 func (o *options) InstrumentOptions() instrument.Options {
 	return o.instrumentOpts
 }
+
+func (o *options) SetTLSConfig(value *tls.Config) Options {
+	opts := *o
+	opts.tlsConfig = value
+	return &opts
+}
+
+func (o *options) TLSConfig() *tls.Config {
+	return o.tlsConfig
+}
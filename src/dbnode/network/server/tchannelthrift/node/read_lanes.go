@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package node
+
+import (
+	"errors"
+	"time"
+
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/storage/limits/permits"
+	"github.com/m3db/m3/src/x/context"
+	"github.com/m3db/m3/src/x/ident"
+	xresource "github.com/m3db/m3/src/x/resource"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/uber-go/tally"
+)
+
+// readLane is the execution lane of a read. Reads of recent data held in
+// memory run in the warm lane so that they do not queue behind reads of
+// historical data from disk which run in the cold lane.
+type readLane int
+
+const (
+	warmReadLane readLane = iota
+	coldReadLane
+)
+
+func (l readLane) String() string {
+	if l == coldReadLane {
+		return "cold"
+	}
+	return "warm"
+}
+
+// readLaneFor returns the lane of a read starting at start for a namespace
+// with the given block size. Blocks before the previous block are flushed to
+// disk in steady state so reads that include them are cold.
+func readLaneFor(start, now xtime.UnixNano, blockSize time.Duration) readLane {
+	if blockSize > 0 && start.Before(now.Truncate(blockSize).Add(-blockSize)) {
+		return coldReadLane
+	}
+	return warmReadLane
+}
+
+type readLaneMetrics struct {
+	acquired tally.Counter
+	rejected tally.Counter
+}
+
+func newReadLaneMetrics(scope tally.Scope, lane readLane) readLaneMetrics {
+	scope = scope.Tagged(map[string]string{"lane": lane.String()})
+	return readLaneMetrics{
+		acquired: scope.Counter("read-lane-acquired"),
+		rejected: scope.Counter("read-lane-rejected"),
+	}
+}
+
+// acquireReadLane acquires a permit of the execution lane of a read of the
+// namespace starting at start. The permit is released when the context is
+// closed. Reads are not limited if the lane has no permits manager.
+func (s *service) acquireReadLane(
+	ctx context.Context,
+	db storage.Database,
+	nsID ident.ID,
+	start xtime.UnixNano,
+) error {
+	if s.warmReadPermits == nil && s.coldReadPermits == nil {
+		return nil
+	}
+
+	lane := warmReadLane
+	if ns, ok := db.Namespace(nsID); ok {
+		lane = readLaneFor(start, xtime.ToUnixNano(s.nowFn()),
+			ns.Options().RetentionOptions().BlockSize())
+	}
+
+	manager, metrics := s.warmReadPermits, s.metrics.warmReadLane
+	if lane == coldReadLane {
+		manager, metrics = s.coldReadPermits, s.metrics.coldReadLane
+	}
+	if manager == nil {
+		return nil
+	}
+
+	lanePermits, err := manager.NewPermits(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := lanePermits.Acquire(ctx)
+	if err != nil {
+		if errors.Is(err, permits.ErrPermitsQueueFull) {
+			metrics.rejected.Inc(1)
+			return tterrors.NewResourceExhaustedError(err)
+		}
+		return err
+	}
+
+	metrics.acquired.Inc(1)
+	ctx.RegisterCloser(xresource.SimpleCloserFn(func() {
+		lanePermits.Release(res.Permit)
+	}))
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/storage/limits/permits"
+	"github.com/m3db/m3/src/x/context"
+	"github.com/m3db/m3/src/x/ident"
+	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLaneFor(t *testing.T) {
+	var (
+		blockSize = 2 * time.Hour
+		now       = xtime.ToUnixNano(time.Date(2021, 1, 1, 5, 0, 0, 0, time.UTC))
+	)
+
+	tests := []struct {
+		start    xtime.UnixNano
+		expected readLane
+	}{
+		{start: now, expected: warmReadLane},
+		{start: now.Add(-time.Hour), expected: warmReadLane},
+		// The previous block [02:00, 04:00) is still warm.
+		{start: now.Add(-3 * time.Hour), expected: warmReadLane},
+		{start: now.Add(-3*time.Hour - time.Second), expected: coldReadLane},
+		{start: now.Add(-24 * time.Hour), expected: coldReadLane},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, readLaneFor(tt.start, now, blockSize),
+			tt.start.String())
+	}
+}
+
+func TestServiceAcquireReadLane(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		nsID      = ident.StringID("metrics")
+		nsOpts    = namespace.NewOptions()
+		blockSize = nsOpts.RetentionOptions().BlockSize()
+		now       = xtime.Now()
+		permit    = permits.NewMockPermit(ctrl)
+	)
+
+	mockNs := storage.NewMockNamespace(ctrl)
+	mockNs.EXPECT().Options().Return(nsOpts).AnyTimes()
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().Namespace(nsID).Return(mockNs, true).AnyTimes()
+
+	coldPermits := permits.NewMockPermits(ctrl)
+	coldManager := permits.NewMockManager(ctrl)
+	coldManager.EXPECT().NewPermits(gomock.Any()).Return(coldPermits, nil).AnyTimes()
+
+	opts := testTChannelThriftOptions.SetPermitsOptions(
+		permits.NewOptions().SetColdReadPermitsManager(coldManager))
+	service := NewService(mockDB, opts).(*service)
+	service.nowFn = now.ToTime
+
+	// Warm reads are not limited when the warm lane has no permits.
+	ctx := context.NewBackground()
+	require.NoError(t, service.acquireReadLane(ctx, mockDB, nsID, now))
+	ctx.Close()
+
+	// Cold reads hold a permit until the context is closed.
+	coldPermits.EXPECT().Acquire(gomock.Any()).Return(permits.AcquireResult{Permit: permit}, nil)
+	ctx = context.NewBackground()
+	require.NoError(t, service.acquireReadLane(ctx, mockDB, nsID, now.Add(-3*blockSize)))
+	coldPermits.EXPECT().Release(permit)
+	ctx.BlockingClose()
+
+	// Cold reads are rejected when the lane queue is full.
+	coldPermits.EXPECT().Acquire(gomock.Any()).Return(permits.AcquireResult{}, permits.ErrPermitsQueueFull)
+	ctx = context.NewBackground()
+	defer ctx.Close()
+	err := service.acquireReadLane(ctx, mockDB, nsID, now.Add(-3*blockSize))
+	require.Error(t, err)
+	rpcErr, ok := err.(*rpc.Error)
+	require.True(t, ok)
+	require.True(t, tterrors.IsResourceExhaustedErrorFlag(rpcErr))
+}
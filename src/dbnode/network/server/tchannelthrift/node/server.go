@@ -21,6 +21,7 @@
 package node
 
 import (
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	ns "github.com/m3db/m3/src/dbnode/network/server"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/node/channel"
@@ -67,7 +68,17 @@ func (s *server) ListenAndServe() (ns.Close, error) {
 	iOpts := s.opts.InstrumentOptions()
 	server := s.opts.TChanNodeServerFn()(s.service, iOpts)
 	tchannelthrift.RegisterServer(channel, server, s.contextPool)
-	channel.ListenAndServe(s.address)
+
+	if tlsConfig := s.opts.TLSConfig(); tlsConfig != nil {
+		listener, err := peerstream.Listen(s.address, tlsConfig)
+		if err != nil {
+			channel.Close()
+			return nil, err
+		}
+		channel.Serve(listener)
+	} else {
+		channel.ListenAndServe(s.address)
+	}
 
 	return channel.Close, nil
 }
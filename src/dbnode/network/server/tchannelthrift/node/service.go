@@ -26,23 +26,29 @@ import (
 	"fmt"
 	"runtime"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	idxconvert "github.com/m3db/m3/src/dbnode/storage/index/convert"
 	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/limits/permits"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/tracepoint"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/ts/writes"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
@@ -78,6 +84,13 @@ const (
 	maxSegmentArrayPooledLength = 32
 	// Any pooled error slices that grow beyond this capcity will be thrown away.
 	writeBatchPooledReqPoolMaxErrorsSliceSize = 4096
+
+	// healthStatusDegraded is the health status reported when a health
+	// threshold is exceeded.
+	healthStatusDegraded = "degraded"
+	// healthDegradedReasonsKey is the health metadata key listing the
+	// exceeded health thresholds.
+	healthDegradedReasonsKey = "degradedReasons"
 )
 
 var (
@@ -125,6 +138,9 @@ type serviceMetrics struct {
 	rpcStatusCanceledRead   tally.Counter
 	// the series blocks read during a call to fetchTagged
 	fetchTaggedSeriesBlocks tally.Histogram
+	warmReadLane            readLaneMetrics
+	coldReadLane            readLaneMetrics
+	peerStream              *peerstream.Metrics
 }
 
 func newServiceMetrics(scope tally.Scope, opts instrument.TimerOptions) serviceMetrics {
@@ -152,6 +168,9 @@ func newServiceMetrics(scope tally.Scope, opts instrument.TimerOptions) serviceM
 			"rpc_status": "canceled",
 			"rpc_type":   "read",
 		}).Counter("rpc_status"),
+		warmReadLane: newReadLaneMetrics(scope, warmReadLane),
+		coldReadLane: newReadLaneMetrics(scope, coldReadLane),
+		peerStream:   peerstream.NewMetrics(scope.SubScope("peer-stream")),
 	}
 }
 
@@ -167,6 +186,8 @@ type service struct {
 	metrics           serviceMetrics
 	queryLimits       limits.QueryLimits
 	seriesReadPermits permits.Manager
+	warmReadPermits   permits.Manager
+	coldReadPermits   permits.Manager
 }
 
 type serviceState struct {
@@ -346,6 +367,8 @@ func NewService(db storage.Database, opts tchannelthrift.Options) Service {
 		},
 		queryLimits:       opts.QueryLimits(),
 		seriesReadPermits: opts.PermitsOptions().SeriesReadPermitsManager(),
+		warmReadPermits:   opts.PermitsOptions().WarmReadPermitsManager(),
+		coldReadPermits:   opts.PermitsOptions().ColdReadPermitsManager(),
 	}
 }
 
@@ -387,7 +410,7 @@ func (s *service) Health(ctx thrift.Context) (*rpc.NodeHealthResult_, error) {
 	db, ok := s.state.DB()
 	if !ok {
 		// DB not yet set, just return existing health status
-		return health, nil
+		return s.withHealthStatus(health), nil
 	}
 
 	// Update bootstrapped field if not up to date. Note that we use
@@ -410,7 +433,30 @@ func (s *service) Health(ctx thrift.Context) (*rpc.NodeHealthResult_, error) {
 		health = newHealth
 	}
 
-	return health, nil
+	return s.withHealthStatus(health), nil
+}
+
+// withHealthStatus returns the health result marked as degraded when the
+// health tracker has found exceeded thresholds, the node otherwise remains
+// ok so that it keeps serving reads and writes.
+func (s *service) withHealthStatus(
+	result *rpc.NodeHealthResult_,
+) *rpc.NodeHealthResult_ {
+	status := s.opts.HealthTracker().Status()
+	if !status.Degraded {
+		return result
+	}
+
+	degraded := &rpc.NodeHealthResult_{}
+	*degraded = *result
+	degraded.Status = healthStatusDegraded
+	meta := make(map[string]string, len(result.Metadata)+1)
+	for k, v := range result.Metadata {
+		meta[k] = v
+	}
+	meta[healthDegradedReasonsKey] = strings.Join(status.Reasons, "; ")
+	degraded.Metadata = meta
+	return degraded
 }
 
 // Bootstrapped is designed to be used with cluster management tools like k8s
@@ -768,10 +814,11 @@ func (s *service) fetchTaggedResult(ctx context.Context,
 			return nil, err
 		}
 		response.Elements = append(response.Elements, &rpc.FetchTaggedIDResult_{
-			ID:          cur.ID(),
-			NameSpace:   iter.Namespace().Bytes(),
-			EncodedTags: tagBytes,
-			Segments:    segments,
+			ID:              cur.ID(),
+			NameSpace:       iter.Namespace().Bytes(),
+			EncodedTags:     tagBytes,
+			Segments:        segments,
+			LatestDatapoint: cur.LatestDatapoint(),
 		})
 	}
 	if iter.Err() != nil {
@@ -834,6 +881,10 @@ func (s *service) fetchTaggedIter(
 		return nil, tterrors.NewBadRequestError(err)
 	}
 
+	if err := s.acquireReadLane(ctx, db, ns, opts.StartInclusive); err != nil {
+		return nil, convert.ToRPCError(err)
+	}
+
 	queryResult, err := db.QueryIDs(ctx, ns, query, opts)
 	if err != nil {
 		return nil, convert.ToRPCError(err)
@@ -844,6 +895,29 @@ func (s *service) fetchTaggedIter(
 		return nil, convert.ToRPCError(err)
 	}
 
+	var (
+		fetchLatest = !fetchData && opts.FetchLatestDatapoint
+		blockSize   time.Duration
+		retainStart xtime.UnixNano
+		retainEnd   xtime.UnixNano
+	)
+	if fetchLatest {
+		nsMetadata, ok := db.Namespace(ns)
+		if !ok {
+			return nil, tterrors.NewBadRequestError(
+				fmt.Errorf("unable to find specified namespace: %v", ns.String()))
+		}
+		var (
+			ropts = nsMetadata.Options().RetentionOptions()
+			now   = xtime.ToUnixNano(s.nowFn())
+		)
+		blockSize = ropts.BlockSize()
+		// NB: only blocks within retention can have data, so the latest
+		// datapoint is never searched for past them however wide the range is.
+		retainStart = retention.FlushTimeStart(ropts, now)
+		retainEnd = now.Add(ropts.BufferFuture()).Truncate(blockSize).Add(blockSize)
+	}
+
 	tagEncoder := s.pools.tagEncoder.Get()
 	ctx.RegisterFinalizer(tagEncoder)
 
@@ -851,6 +925,10 @@ func (s *service) fetchTaggedIter(
 		queryResult:     queryResult,
 		queryOpts:       opts,
 		fetchData:       fetchData,
+		fetchLatest:     fetchLatest,
+		blockSize:       blockSize,
+		retainStart:     retainStart,
+		retainEnd:       retainEnd,
 		db:              db,
 		docReader:       docs.NewEncodedDocumentReader(),
 		nsID:            ns,
@@ -915,6 +993,10 @@ type fetchTaggedResultsIterOpts struct {
 	queryResult     index.QueryResult
 	queryOpts       index.QueryOptions
 	fetchData       bool
+	fetchLatest     bool
+	blockSize       time.Duration
+	retainStart     xtime.UnixNano
+	retainEnd       xtime.UnixNano
 	db              storage.Database
 	docReader       *docs.EncodedDocumentReader
 	nsID            ident.ID
@@ -1019,11 +1101,104 @@ func (i *fetchTaggedResultsIter) Next(ctx context.Context) bool {
 		}
 	}
 
+	if i.fetchLatest {
+		// only the latest datapoint is returned in metadata only mode so read it lazily for the
+		// current series ID rather than prefetching any block readers.
+		currResult := &i.idResults[i.idx]
+		currResult.latestDatapoint, i.err = i.readLatestDatapoint(ctx, i.idx)
+		if i.err != nil {
+			return false
+		}
+	}
+
 	i.cur = &i.idResults[i.idx]
 	i.idx++
 	return true
 }
 
+// readLatestDatapoint returns the most recent datapoint of a series within the query range, or nil if the series
+// has no data in the range. Blocks are read newest first and reading stops at the first block with data in the
+// range, so the read is charged a single block permit however many blocks the range spans.
+func (i *fetchTaggedResultsIter) readLatestDatapoint(ctx context.Context, idx int) (*rpc.Datapoint, error) {
+	// the current series ID is always being read so acquire waits until a permit is available.
+	if _, err := i.acquire(ctx, idx); err != nil {
+		return nil, err
+	}
+
+	var (
+		id        = ident.BytesID(i.idResults[idx].queryResult.Key())
+		start     = i.queryOpts.StartInclusive
+		end       = i.queryOpts.EndExclusive
+		blockSize = i.blockSize
+		nsCtx     = namespace.NewContextFor(i.nsID, i.db.Options().SchemaRegistry())
+		multiIt   = i.db.Options().MultiReaderIteratorPool().Get()
+	)
+	defer multiIt.Close()
+
+	if start.Before(i.retainStart) {
+		start = i.retainStart
+	}
+	if end.After(i.retainEnd) {
+		end = i.retainEnd
+	}
+
+	for blockStart := end.Add(-1).Truncate(blockSize); blockStart.Add(blockSize).After(start); blockStart = blockStart.Add(-blockSize) {
+		readStart, readEnd := blockStart, blockStart.Add(blockSize)
+		if readStart.Before(start) {
+			readStart = start
+		}
+		if readEnd.After(end) {
+			readEnd = end
+		}
+
+		blockIter, err := i.db.ReadEncoded(ctx, i.nsID, id, readStart, readEnd)
+		if err != nil {
+			return nil, err
+		}
+		blocks, err := blockIter.ToSlices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		multiIt.ResetSliceOfSlices(
+			xio.NewReaderSliceOfSlicesFromBlockReadersIterator(blocks), nsCtx.Schema)
+
+		var (
+			latest     ts.Datapoint
+			annotation ts.Annotation
+			found      bool
+		)
+		for multiIt.Next() {
+			dp, _, a := multiIt.Current()
+			if dp.TimestampNanos.Before(start) || !dp.TimestampNanos.Before(end) {
+				continue
+			}
+			// NB: the annotation is only valid until the iterator is advanced.
+			latest, annotation, found = dp, append(annotation[:0], a...), true
+		}
+		if err := multiIt.Err(); err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		datapoint := rpc.NewDatapoint()
+		datapoint.Timestamp = int64(latest.TimestampNanos)
+		datapoint.TimestampTimeType = rpc.TimeType_UNIX_NANOSECONDS
+		datapoint.Value = latest.Value
+		if len(annotation) > 0 {
+			datapoint.Annotation = annotation
+		}
+		return datapoint, nil
+	}
+
+	return nil, nil
+}
+
 // acquire a block permit for a series ID. returns true if a permit is available.
 func (i *fetchTaggedResultsIter) acquire(ctx context.Context, idx int) (bool, error) {
 	var curPermit permits.Permit
@@ -1113,6 +1288,10 @@ type IDResult interface {
 	// needs to grow, just like append().
 	// This method blocks until segment data is available or the context deadline expires.
 	WriteSegments(ctx context.Context, dst []*rpc.Segments) ([]*rpc.Segments, error)
+
+	// LatestDatapoint returns the most recent datapoint of the series if it was requested and the series has data
+	// in the query range, otherwise nil.
+	LatestDatapoint() *rpc.Datapoint
 }
 
 type idResult struct {
@@ -1121,6 +1300,7 @@ type idResult struct {
 	tagEncoder       serialize.TagEncoder
 	blockReadersIter series.BlockReaderIter
 	blockReaders     [][]xio.BlockReader
+	latestDatapoint  *rpc.Datapoint
 	quotaUsed        int64
 	iOpts            instrument.Options
 }
@@ -1158,6 +1338,10 @@ func (i *idResult) WriteSegments(ctx context.Context, dst []*rpc.Segments) ([]*r
 	return dst, nil
 }
 
+func (i *idResult) LatestDatapoint() *rpc.Datapoint {
+	return i.latestDatapoint
+}
+
 func (s *service) Aggregate(tctx thrift.Context, req *rpc.AggregateQueryRequest) (*rpc.AggregateQueryResult_, error) {
 	db, err := s.startReadRPCWithDB()
 	if err != nil {
@@ -1471,6 +1655,13 @@ func (s *service) FetchBlocksRaw(tctx thrift.Context, req *rpc.FetchBlocksRawReq
 	res := rpc.NewFetchBlocksRawResult_()
 	res.Elements = make([]*rpc.Blocks, len(req.Elements))
 
+	// Compress with the codec most preferred by the peer, peers that do not
+	// list any codecs receive uncompressed segments.
+	var (
+		codec                  = peerstream.Negotiate(req.AcceptCompression, s.opts.PeerStreamCodecs())
+		rawBytes, encodedBytes int
+	)
+
 	// Preallocate starts to maximum size since at least one element will likely
 	// be fetching most blocks for peer bootstrapping
 	ropts := nsMetadata.Options().RetentionOptions()
@@ -1513,6 +1704,15 @@ func (s *service) FetchBlocksRaw(tctx thrift.Context, req *rpc.FetchBlocksRawReq
 				}
 				block.Segments = converted.Segments
 				block.Checksum = converted.Checksum
+				if codec != nil {
+					raw, encoded := peerstream.EncodeSegments(codec, block.Segments)
+					rawBytes += raw
+					encodedBytes += encoded
+				} else {
+					size := peerstream.SegmentsSize(block.Segments)
+					rawBytes += size
+					encodedBytes += size
+				}
 			}
 
 			blocks.Blocks = append(blocks.Blocks, block)
@@ -1521,6 +1721,11 @@ func (s *service) FetchBlocksRaw(tctx thrift.Context, req *rpc.FetchBlocksRawReq
 		res.Elements[i] = blocks
 	}
 
+	if codec != nil {
+		compression := string(codec.Compression())
+		res.Compression = &compression
+	}
+	s.metrics.peerStream.Record(codec, rawBytes, encodedBytes)
 	s.metrics.fetchBlocks.ReportSuccess(s.nowFn().Sub(callStart))
 
 	return res, nil
@@ -1758,6 +1963,11 @@ func (s *service) WriteTagged(tctx thrift.Context, req *rpc.WriteTaggedRequest)
 		return convert.ToRPCError(err)
 	}
 
+	if err := s.maybeWaitForIndex(tctx, db, ident.StringID(req.NameSpace)); err != nil {
+		s.metrics.writeTagged.ReportError(s.nowFn().Sub(callStart))
+		return err
+	}
+
 	s.metrics.writeTagged.ReportSuccess(s.nowFn().Sub(callStart))
 
 	return nil
@@ -2018,14 +2228,31 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 
 		seriesID := s.newPooledID(ctx, elem.ID, pooledReq)
 
-		batchWriter.AddTagged(
-			i,
-			seriesID,
-			elem.EncodedTags,
-			xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
-			elem.Datapoint.Value,
-			unit,
-			elem.Datapoint.Annotation)
+		if elem.IsSetShardID() {
+			err = batchWriter.AddTaggedWithShardID(
+				i,
+				seriesID,
+				elem.EncodedTags,
+				xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
+				elem.Datapoint.Value,
+				unit,
+				elem.Datapoint.Annotation,
+				uint32(elem.GetShardID()))
+		} else {
+			err = batchWriter.AddTagged(
+				i,
+				seriesID,
+				elem.EncodedTags,
+				xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
+				elem.Datapoint.Value,
+				unit,
+				elem.Datapoint.Annotation)
+		}
+		if err != nil {
+			nonRetryableErrors++
+			pooledReq.addError(tterrors.NewBadRequestWriteBatchRawError(i, err))
+			continue
+		}
 	}
 
 	err = db.WriteTaggedBatch(ctx, nsID, batchWriter, pooledReq)
@@ -2033,6 +2260,10 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 		return convert.ToRPCError(err)
 	}
 
+	if err := s.maybeWaitForIndex(tctx, db, nsID); err != nil {
+		return err
+	}
+
 	nonRetryableErrors += pooledReq.numNonRetryableErrors()
 	retryableErrors += pooledReq.numRetryableErrors()
 	totalErrors := nonRetryableErrors + retryableErrors
@@ -2099,6 +2330,9 @@ func (s *service) WriteTaggedBatchRawV2(tctx thrift.Context, req *rpc.WriteTagge
 				if err != nil {
 					return convert.ToRPCError(err)
 				}
+				if err := s.maybeWaitForIndex(tctx, db, nsID); err != nil {
+					return err
+				}
 				batchWriter = nil
 			}
 
@@ -2132,15 +2366,33 @@ func (s *service) WriteTaggedBatchRawV2(tctx thrift.Context, req *rpc.WriteTagge
 
 		seriesID := s.newPooledID(ctx, elem.ID, pooledReq)
 
-		batchWriter.AddTagged(
-			i,
-			seriesID,
-			elem.EncodedTags,
-			xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
-			elem.Datapoint.Value,
-			unit,
-			elem.Datapoint.Annotation,
-		)
+		if elem.IsSetShardID() {
+			err = batchWriter.AddTaggedWithShardID(
+				i,
+				seriesID,
+				elem.EncodedTags,
+				xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
+				elem.Datapoint.Value,
+				unit,
+				elem.Datapoint.Annotation,
+				uint32(elem.GetShardID()),
+			)
+		} else {
+			err = batchWriter.AddTagged(
+				i,
+				seriesID,
+				elem.EncodedTags,
+				xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
+				elem.Datapoint.Value,
+				unit,
+				elem.Datapoint.Annotation,
+			)
+		}
+		if err != nil {
+			nonRetryableErrors++
+			pooledReq.addError(tterrors.NewBadRequestWriteBatchRawError(i, err))
+			continue
+		}
 	}
 
 	if batchWriter != nil {
@@ -2149,6 +2401,9 @@ func (s *service) WriteTaggedBatchRawV2(tctx thrift.Context, req *rpc.WriteTagge
 		if err != nil {
 			return convert.ToRPCError(err)
 		}
+		if err := s.maybeWaitForIndex(tctx, db, nsID); err != nil {
+			return err
+		}
 	}
 
 	nonRetryableErrors += pooledReq.numNonRetryableErrors()
@@ -2170,6 +2425,25 @@ func (s *service) WriteTaggedBatchRawV2(tctx thrift.Context, req *rpc.WriteTagge
 	return nil
 }
 
+// maybeWaitForIndex blocks until the series written to the namespace are
+// queryable if the caller requested so with the wait for index header.
+func (s *service) maybeWaitForIndex(
+	tctx thrift.Context,
+	db storage.Database,
+	nsID ident.ID,
+) error {
+	wait, err := strconv.ParseBool(tctx.Headers()[tchannelthrift.WaitForIndexHeader])
+	if err != nil || !wait {
+		return nil
+	}
+
+	ctx := tchannelthrift.Context(tctx)
+	if err := db.WaitForIndexInserts(ctx, nsID); err != nil {
+		return convert.ToRPCError(err)
+	}
+	return nil
+}
+
 func (s *service) Repair(tctx thrift.Context) error {
 	db, err := s.startRPCWithDB()
 	if err != nil {
@@ -2802,6 +3076,14 @@ func (r *writeBatchPooledReq) HandleError(index int, err error) {
 	}
 
 	r.retryableErrors++
+	if dberrors.IsShardNotOwnedError(err) {
+		// NB: flag the error so the client re-routes the write with its
+		// updated topology.
+		r.errs = append(
+			r.errs,
+			tterrors.NewShardNotOwnedWriteBatchRawError(index, err))
+		return
+	}
 	r.errs = append(
 		r.errs,
 		tterrors.NewWriteBatchRawError(index, err))
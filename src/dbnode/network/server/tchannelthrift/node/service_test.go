@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
@@ -110,6 +111,43 @@ func TestServiceHealth(t *testing.T) {
 	assert.Equal(t, true, result.Bootstrapped)
 }
 
+func TestServiceHealthDegraded(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsBootstrappedAndDurable().Return(true).AnyTimes()
+
+	tracker, err := health.NewTracker(health.NewOptions().SetMaxCommitLogQueueRatio(0.5))
+	require.NoError(t, err)
+	opts := testTChannelThriftOptions.SetHealthTracker(tracker)
+	service := NewService(mockDB, opts).(*service)
+	service.SetMetadata("foo", "bar")
+
+	tracker.UpdateCommitLogQueue(90, 100)
+	require.True(t, tracker.Evaluate().Degraded)
+
+	tctx, _ := thrift.NewContext(time.Minute)
+	result, err := service.Health(tctx)
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Ok)
+	assert.Equal(t, "degraded", result.Status)
+	assert.Equal(t, map[string]string{
+		"foo":             "bar",
+		"degradedReasons": "commit log queue 90/100 exceeds ratio 0.50",
+	}, result.Metadata)
+
+	// Recovering flips the node back without the reasons.
+	tracker.UpdateCommitLogQueue(0, 100)
+	require.False(t, tracker.Evaluate().Degraded)
+
+	result, err = service.Health(tctx)
+	require.NoError(t, err)
+	assert.Equal(t, "up", result.Status)
+	assert.Equal(t, map[string]string{"foo": "bar"}, result.Metadata)
+}
+
 func TestServiceBootstrapped(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
@@ -2017,6 +2055,189 @@ func TestServiceFetchTaggedNoData(t *testing.T) {
 	}
 }
 
+func TestServiceFetchTaggedLatestDatapoint(t *testing.T) {
+	testCases := []struct {
+		name            string
+		blocksReadLimit int64
+		fetchErrMsg     string
+	}{
+		{
+			name: "happy path",
+		},
+		{
+			// reading the latest datapoint of a series is charged a single permit however many blocks are read
+			// so reading "foo" and "bar" stays within the limit.
+			name:            "block read limit per series",
+			blocksReadLimit: 3,
+		},
+		{
+			name:            "block read limit",
+			blocksReadLimit: 2,
+			fetchErrMsg:     "query aborted due to limit",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := xtest.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := storage.NewMockDatabase(ctrl)
+			mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+			mockDB.EXPECT().IsOverloaded().Return(false)
+
+			limitsOpts := limits.NewOptions().
+				SetInstrumentOptions(testTChannelThriftOptions.InstrumentOptions()).
+				SetBytesReadLimitOpts(limits.DefaultLookbackLimitOptions()).
+				SetDiskSeriesReadLimitOpts(limits.LookbackLimitOptions{
+					Limit:    tc.blocksReadLimit,
+					Lookback: time.Second * 1,
+				}).
+				SetDocsLimitOpts(limits.DefaultLookbackLimitOptions())
+			queryLimits, err := limits.NewQueryLimits(limitsOpts)
+			require.NoError(t, err)
+			permitOpts := permits.NewOptions().
+				SetSeriesReadPermitsManager(permits.NewLookbackLimitPermitsManager(
+					"disk-series-read",
+					limitsOpts.DiskSeriesReadLimitOpts(),
+					testTChannelThriftOptions.InstrumentOptions(),
+					limitsOpts.SourceLoggerBuilder(),
+				))
+
+			service := NewService(mockDB, testTChannelThriftOptions.
+				SetQueryLimits(queryLimits).
+				SetPermitsOptions(permitOpts)).(*service)
+
+			tctx, _ := tchannelthrift.NewContext(time.Minute)
+			ctx := tchannelthrift.Context(tctx)
+			defer ctx.Close()
+
+			start := xtime.Now().Truncate(time.Hour).Add(-2 * time.Hour)
+			end := start.Add(2 * time.Hour)
+			nsID := "metrics"
+
+			mockNs := storage.NewMockNamespace(ctrl)
+			mockNs.EXPECT().Options().Return(testNamespaceOptions.SetRetentionOptions(
+				testNamespaceOptions.RetentionOptions().SetBlockSize(time.Hour))).AnyTimes()
+			mockDB.EXPECT().Namespace(ident.NewIDMatcher(nsID)).Return(mockNs, true)
+
+			encodeStream := func(blockStart xtime.UnixNano, values ...float64) xio.SegmentReader {
+				enc := testStorageOpts.EncoderPool().Get()
+				enc.Reset(blockStart, 0, nil)
+				for i, v := range values {
+					dp := ts.Datapoint{
+						TimestampNanos: blockStart.Add(time.Duration(i+1) * time.Second),
+						Value:          v,
+					}
+					require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+				}
+				stream, _ := enc.Stream(ctx)
+				return stream
+			}
+
+			// series are read in no particular order so when the limit is exceeded either may be left unread.
+			minReads := 1
+			if tc.fetchErrMsg != "" {
+				minReads = 0
+			}
+
+			// "foo" has data in both blocks, the latest datapoint lives in the second block so the first block
+			// is never read.
+			mockDB.EXPECT().
+				ReadEncoded(gomock.Any(), ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"),
+					start.Add(time.Hour), end).
+				Return(&series.FakeBlockReaderIter{
+					Readers: [][]xio.BlockReader{
+						{xio.BlockReader{SegmentReader: encodeStream(start.Add(time.Hour), 3.0, 4.0)}},
+					},
+				}, nil).
+				MinTimes(minReads).MaxTimes(1)
+			// "bar" has no data in the query range so both blocks are read, newest first.
+			gomock.InOrder(
+				mockDB.EXPECT().
+					ReadEncoded(gomock.Any(), ident.NewIDMatcher(nsID), ident.NewIDMatcher("bar"),
+						start.Add(time.Hour), end).
+					Return(&series.FakeBlockReaderIter{}, nil).
+					MinTimes(minReads).MaxTimes(1),
+				mockDB.EXPECT().
+					ReadEncoded(gomock.Any(), ident.NewIDMatcher(nsID), ident.NewIDMatcher("bar"),
+						start, start.Add(time.Hour)).
+					Return(&series.FakeBlockReaderIter{}, nil).
+					MinTimes(minReads).MaxTimes(1),
+			)
+
+			req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+			require.NoError(t, err)
+			qry := index.Query{Query: req}
+
+			md1 := doc.Metadata{
+				ID:     ident.BytesID("foo"),
+				Fields: []doc.Field{},
+			}
+			md2 := doc.Metadata{
+				ID:     ident.BytesID("bar"),
+				Fields: []doc.Field{},
+			}
+
+			resMap := index.NewQueryResults(ident.StringID(nsID),
+				index.QueryResultsOptions{}, testIndexOptions)
+			resMap.Map().Set(md1.ID, doc.NewDocumentFromMetadata(md1))
+			resMap.Map().Set(md2.ID, doc.NewDocumentFromMetadata(md2))
+			mockDB.EXPECT().QueryIDs(
+				gomock.Any(),
+				ident.NewIDMatcher(nsID),
+				index.NewQueryMatcher(qry),
+				index.QueryOptions{
+					StartInclusive:       start,
+					EndExclusive:         end,
+					FetchLatestDatapoint: true,
+				}).Return(index.QueryResult{Results: resMap, Exhaustive: true}, nil)
+
+			startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+			require.NoError(t, err)
+			endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+			require.NoError(t, err)
+
+			data, err := idx.Marshal(req)
+			require.NoError(t, err)
+			r, err := service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+				NameSpace:            []byte(nsID),
+				Query:                data,
+				RangeStart:           startNanos,
+				RangeEnd:             endNanos,
+				FetchData:            false,
+				FetchLatestDatapoint: true,
+			})
+			if tc.fetchErrMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.fetchErrMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			// sort to order results to make test deterministic.
+			sort.Slice(r.Elements, func(i, j int) bool {
+				return bytes.Compare(r.Elements[i].ID, r.Elements[j].ID) < 0
+			})
+			require.Equal(t, 2, len(r.Elements))
+
+			bar := r.Elements[0]
+			require.Equal(t, []byte("bar"), bar.ID)
+			require.Nil(t, bar.LatestDatapoint)
+			require.Empty(t, bar.Segments)
+
+			foo := r.Elements[1]
+			require.Equal(t, []byte("foo"), foo.ID)
+			require.Empty(t, foo.Segments)
+			require.NotNil(t, foo.LatestDatapoint)
+			assert.Equal(t, int64(start.Add(time.Hour+2*time.Second)), foo.LatestDatapoint.Timestamp)
+			assert.Equal(t, rpc.TimeType_UNIX_NANOSECONDS, foo.LatestDatapoint.TimestampTimeType)
+			assert.Equal(t, 4.0, foo.LatestDatapoint.Value)
+		})
+	}
+}
+
 func TestServiceFetchTaggedErrs(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
@@ -2905,6 +3126,62 @@ func TestServiceWriteTaggedBatchRaw(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceWriteTaggedBatchRawWaitForIndex(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	opts := tchannelthrift.NewOptions()
+
+	service := NewService(mockDB, opts).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	tctx = thrift.WithHeaders(tctx, map[string]string{
+		tchannelthrift.WaitForIndexHeader: "true",
+	})
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+	elements := []*rpc.WriteTaggedBatchRawRequestElement{
+		{
+			ID:          []byte("foo"),
+			EncodedTags: []byte("a|b"),
+			Datapoint: &rpc.Datapoint{
+				Timestamp:         time.Now().Unix(),
+				TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+				Value:             12.34,
+			},
+		},
+	}
+
+	for _, waitErr := range []error{nil, errors.New("index wait timed out")} {
+		writeBatch := writes.NewWriteBatch(len(elements), ident.StringID(nsID), nil)
+		mockDB.EXPECT().
+			BatchWriter(ident.NewIDMatcher(nsID), len(elements)).
+			Return(writeBatch, nil)
+		mockDB.EXPECT().
+			WriteTaggedBatch(ctx, ident.NewIDMatcher(nsID), writeBatch, gomock.Any()).
+			Return(nil)
+		mockDB.EXPECT().
+			WaitForIndexInserts(ctx, ident.NewIDMatcher(nsID)).
+			Return(waitErr)
+
+		mockDB.EXPECT().IsOverloaded().Return(false)
+		err := service.WriteTaggedBatchRaw(tctx, &rpc.WriteTaggedBatchRawRequest{
+			NameSpace: []byte(nsID),
+			Elements:  elements,
+		})
+		if waitErr == nil {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+		}
+	}
+}
+
 func TestServiceWriteTaggedBatchRawV2(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
@@ -21,6 +21,8 @@
 package tchannelthrift
 
 import (
+	"github.com/m3db/m3/src/dbnode/health"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/limits/permits"
 	"github.com/m3db/m3/src/dbnode/topology"
@@ -46,7 +48,9 @@ type options struct {
 	maxOutstandingReadRequests  int
 	queryLimits                 limits.QueryLimits
 	permitsOptions              permits.Options
+	healthTracker               health.Tracker
 	seriesBlocksPerBatch        int
+	peerStreamCodecs            []peerstream.Codec
 }
 
 // NewOptions creates new options.
@@ -84,6 +88,7 @@ func NewOptions() Options {
 		checkedBytesWrapperPool:  bytesWrapperPool,
 		queryLimits:              limits.NoOpQueryLimits(),
 		permitsOptions:           permits.NewOptions(),
+		healthTracker:            health.NewNoopTracker(),
 	}
 }
 
@@ -207,6 +212,16 @@ func (o *options) PermitsOptions() permits.Options {
 	return o.permitsOptions
 }
 
+func (o *options) HealthTracker() health.Tracker {
+	return o.healthTracker
+}
+
+func (o *options) SetHealthTracker(value health.Tracker) Options {
+	opts := *o
+	opts.healthTracker = value
+	return &opts
+}
+
 func (o *options) SetFetchTaggedSeriesBlocksPerBatch(value int) Options {
 	opts := *o
 	opts.seriesBlocksPerBatch = value
@@ -216,3 +231,13 @@ func (o *options) SetFetchTaggedSeriesBlocksPerBatch(value int) Options {
 func (o *options) FetchTaggedSeriesBlocksPerBatch() int {
 	return o.seriesBlocksPerBatch
 }
+
+func (o *options) PeerStreamCodecs() []peerstream.Codec {
+	return o.peerStreamCodecs
+}
+
+func (o *options) SetPeerStreamCodecs(value []peerstream.Codec) Options {
+	opts := *o
+	opts.peerStreamCodecs = value
+	return &opts
+}
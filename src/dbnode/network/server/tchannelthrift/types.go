@@ -23,6 +23,8 @@
 package tchannelthrift
 
 import (
+	"github.com/m3db/m3/src/dbnode/health"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
 	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/limits/permits"
 	"github.com/m3db/m3/src/dbnode/topology"
@@ -137,7 +139,23 @@ type Options interface {
 	// SetPermitsOptions sets the permits options.
 	SetPermitsOptions(value permits.Options) Options
 
+	// HealthTracker returns the tracker that determines whether the node
+	// reports itself as degraded.
+	HealthTracker() health.Tracker
+
+	// SetHealthTracker sets the tracker that determines whether the node
+	// reports itself as degraded.
+	SetHealthTracker(value health.Tracker) Options
+
 	// SetFetchTaggedSeriesBlocksPerBatch sets the series blocks allowed to be read
 	// per permit acquired.
 	SetFetchTaggedSeriesBlocksPerBatch(value int) Options
+
+	// PeerStreamCodecs returns the codecs that blocks streamed to peers may be
+	// compressed with.
+	PeerStreamCodecs() []peerstream.Codec
+
+	// SetPeerStreamCodecs sets the codecs that blocks streamed to peers may be
+	// compressed with.
+	SetPeerStreamCodecs(value []peerstream.Codec) Options
 }
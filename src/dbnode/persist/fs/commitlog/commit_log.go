@@ -27,6 +27,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -103,9 +104,12 @@ type commitLog struct {
 	commitLogFailFn      commitLogFailFn
 	beforeAsyncWriteFn   func()
 
-	metrics commitLogMetrics
+	metrics       commitLogMetrics
+	healthTracker health.Tracker
 
-	numWritesInQueue int64
+	numWritesInQueue  int64
+	numWritesAccepted int64
+	numWritesDropped  int64
 }
 
 // Use the helper methods when interacting with this struct, the mutex
@@ -162,6 +166,9 @@ type asyncResettableWriter struct {
 	// with commitlog 1 should be called as the writer associated with commitlog 2 may not have been
 	// flushed at all yet.
 	pendingFlushFns []callbackFn
+	// firstUnflushedWriteAt is when the oldest write not yet flushed by the
+	// writer was written, it is zero when there are no unflushed writes.
+	firstUnflushedWriteAt time.Time
 }
 
 func (w *asyncResettableWriter) onFlush(err error) {
@@ -173,16 +180,25 @@ type closedState struct {
 	closed bool
 }
 
+var (
+	latencyBuckets    = tally.MustMakeExponentialDurationBuckets(50*time.Microsecond, 2, 20)
+	queueDepthBuckets = tally.MustMakeExponentialValueBuckets(1, 2, 24)
+)
+
 type commitLogMetrics struct {
-	numWritesInQueue tally.Gauge
-	queueLength      tally.Gauge
-	queueCapacity    tally.Gauge
-	success          tally.Counter
-	errors           tally.Counter
-	openErrors       tally.Counter
-	closeErrors      tally.Counter
-	flushErrors      tally.Counter
-	flushDone        tally.Counter
+	numWritesInQueue      tally.Gauge
+	queueLength           tally.Gauge
+	queueCapacity         tally.Gauge
+	queueDepth            tally.Histogram
+	queueFull             tally.Counter
+	enqueueToWriteLatency tally.Histogram
+	writeToFlushLatency   tally.Histogram
+	success               tally.Counter
+	errors                tally.Counter
+	openErrors            tally.Counter
+	closeErrors           tally.Counter
+	flushErrors           tally.Counter
+	flushDone             tally.Counter
 }
 
 type eventType int
@@ -244,6 +260,7 @@ type commitLogWrite struct {
 	eventType  eventType
 	write      writeOrWriteBatch
 	callbackFn callbackFn
+	enqueuedAt time.Time
 }
 
 type testOnlyOpts struct {
@@ -280,16 +297,21 @@ func newCommitLog(opts Options, testOpts testOnlyOpts) (CommitLog, error) {
 		maxQueueSize: int64(opts.BacklogQueueSize()),
 		closeErr:     make(chan error),
 		metrics: commitLogMetrics{
-			numWritesInQueue: scope.Gauge("writes.queued"),
-			queueLength:      scope.Gauge("writes.queue-length"),
-			queueCapacity:    scope.Gauge("writes.queue-capacity"),
-			success:          scope.Counter("writes.success"),
-			errors:           scope.Counter("writes.errors"),
-			openErrors:       scope.Counter("writes.open-errors"),
-			closeErrors:      scope.Counter("writes.close-errors"),
-			flushErrors:      scope.Counter("writes.flush-errors"),
-			flushDone:        scope.Counter("writes.flush-done"),
+			numWritesInQueue:      scope.Gauge("writes.queued"),
+			queueLength:           scope.Gauge("writes.queue-length"),
+			queueCapacity:         scope.Gauge("writes.queue-capacity"),
+			queueDepth:            scope.Histogram("writes.queue-depth", queueDepthBuckets),
+			queueFull:             scope.Counter("writes.queue-full"),
+			enqueueToWriteLatency: scope.Histogram("writes.enqueue-to-write-latency", latencyBuckets),
+			writeToFlushLatency:   scope.Histogram("writes.write-to-flush-latency", latencyBuckets),
+			success:               scope.Counter("writes.success"),
+			errors:                scope.Counter("writes.errors"),
+			openErrors:            scope.Counter("writes.open-errors"),
+			closeErrors:           scope.Counter("writes.close-errors"),
+			flushErrors:           scope.Counter("writes.flush-errors"),
+			flushDone:             scope.Counter("writes.flush-done"),
 		},
+		healthTracker:      opts.HealthTracker(),
 		beforeAsyncWriteFn: testOpts.beforeAsyncWriteFn,
 	}
 	// Setup backreferences for onFlush().
@@ -437,12 +459,19 @@ func (l *commitLog) flushEvery(interval time.Duration) {
 
 	for {
 		// The number of actual metrics / writes in the queue.
-		l.metrics.numWritesInQueue.Update(float64(atomic.LoadInt64(&l.numWritesInQueue)))
+		numWritesInQueue := atomic.LoadInt64(&l.numWritesInQueue)
+		l.metrics.numWritesInQueue.Update(float64(numWritesInQueue))
+		l.metrics.queueDepth.RecordValue(float64(numWritesInQueue))
 		// The current length of the queue, different from number of writes due to each
 		// item in the queue could (potentially) be a batch of many writes.
 		l.metrics.queueLength.Update(float64(len(l.writes)))
 		l.metrics.queueCapacity.Update(float64(cap(l.writes)))
 
+		l.healthTracker.UpdateCommitLogQueue(numWritesInQueue, l.maxQueueSize)
+		l.healthTracker.RecordCommitLogWrites(
+			atomic.SwapInt64(&l.numWritesAccepted, 0),
+			atomic.SwapInt64(&l.numWritesDropped, 0))
+
 		sleepFor := interval
 
 		if sleepForOverride > 0 {
@@ -525,6 +554,10 @@ func (l *commitLog) write() {
 			continue
 		}
 
+		if !write.enqueuedAt.IsZero() {
+			l.metrics.enqueueToWriteLatency.RecordDuration(l.nowFn().Sub(write.enqueuedAt))
+		}
+
 		var (
 			numWritesSuccess int64
 			numDequeued      int
@@ -572,6 +605,10 @@ func (l *commitLog) write() {
 
 		atomic.AddInt64(&l.numWritesInQueue, int64(-numDequeued))
 		l.metrics.success.Inc(numWritesSuccess)
+
+		if numWritesSuccess > 0 && l.writerState.primary.firstUnflushedWriteAt.IsZero() {
+			l.writerState.primary.firstUnflushedWriteAt = l.nowFn()
+		}
 	}
 
 	// Ensure that there is no active background goroutine in the middle of reseting
@@ -596,7 +633,17 @@ func (l *commitLog) write() {
 }
 
 func (l *commitLog) onFlush(writer *asyncResettableWriter, err error) {
-	l.flushState.setLastFlushAt(l.nowFn())
+	now := l.nowFn()
+	l.flushState.setLastFlushAt(now)
+
+	if !writer.firstUnflushedWriteAt.IsZero() {
+		// The latency of the oldest write in the flush bounds the time any
+		// write in it waited to become durable.
+		latency := now.Sub(writer.firstUnflushedWriteAt)
+		l.metrics.writeToFlushLatency.RecordDuration(latency)
+		l.healthTracker.RecordCommitLogFlushLatency(latency)
+		writer.firstUnflushedWriteAt = time.Time{}
+	}
 
 	if err != nil {
 		l.metrics.errors.Inc(1)
@@ -804,6 +851,7 @@ func (l *commitLog) writeWait(
 	writeToEnqueue := commitLogWrite{
 		write:      write,
 		callbackFn: completion,
+		enqueuedAt: l.nowFn(),
 	}
 
 	numToEnqueue := int64(1)
@@ -817,6 +865,8 @@ func (l *commitLog) writeWait(
 	// If we exceeded the limit, decrement the number of enqueued writes and bail.
 	if numEnqueued > l.maxQueueSize {
 		atomic.AddInt64(&l.numWritesInQueue, -numToEnqueue)
+		atomic.AddInt64(&l.numWritesDropped, numToEnqueue)
+		l.metrics.queueFull.Inc(numToEnqueue)
 		l.closedState.RUnlock()
 
 		if write.writeBatch != nil {
@@ -829,10 +879,8 @@ func (l *commitLog) writeWait(
 	}
 
 	// Otherwise submit the write.
-	l.writes <- commitLogWrite{
-		write:      write,
-		callbackFn: completion,
-	}
+	atomic.AddInt64(&l.numWritesAccepted, numToEnqueue)
+	l.writes <- writeToEnqueue
 
 	l.closedState.RUnlock()
 
@@ -862,6 +910,8 @@ func (l *commitLog) writeBehind(
 	// If we exceeded the limit, decrement the number of enqueued writes and bail.
 	if numEnqueued > l.maxQueueSize {
 		atomic.AddInt64(&l.numWritesInQueue, -numToEnqueue)
+		atomic.AddInt64(&l.numWritesDropped, numToEnqueue)
+		l.metrics.queueFull.Inc(numToEnqueue)
 		l.closedState.RUnlock()
 
 		if write.writeBatch != nil {
@@ -874,8 +924,10 @@ func (l *commitLog) writeBehind(
 	}
 
 	// Otherwise submit the write.
+	atomic.AddInt64(&l.numWritesAccepted, numToEnqueue)
 	l.writes <- commitLogWrite{
-		write: write,
+		write:      write,
+		enqueuedAt: l.nowFn(),
 	}
 
 	l.closedState.RUnlock()
@@ -28,6 +28,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -349,6 +350,20 @@ func (mr *MockOptionsMockRecorder) FailureCallback() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureCallback", reflect.TypeOf((*MockOptions)(nil).FailureCallback))
 }
 
+// HealthTracker mocks base method.
+func (m *MockOptions) HealthTracker() health.Tracker {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HealthTracker")
+	ret0, _ := ret[0].(health.Tracker)
+	return ret0
+}
+
+// HealthTracker indicates an expected call of HealthTracker.
+func (mr *MockOptionsMockRecorder) HealthTracker() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthTracker", reflect.TypeOf((*MockOptions)(nil).HealthTracker))
+}
+
 // FailureStrategy mocks base method.
 func (m *MockOptions) FailureStrategy() FailureStrategy {
 	m.ctrl.T.Helper()
@@ -531,6 +546,20 @@ func (mr *MockOptionsMockRecorder) SetFailureCallback(value interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFailureCallback", reflect.TypeOf((*MockOptions)(nil).SetFailureCallback), value)
 }
 
+// SetHealthTracker mocks base method.
+func (m *MockOptions) SetHealthTracker(value health.Tracker) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHealthTracker", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetHealthTracker indicates an expected call of SetHealthTracker.
+func (mr *MockOptionsMockRecorder) SetHealthTracker(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHealthTracker", reflect.TypeOf((*MockOptions)(nil).SetHealthTracker), value)
+}
+
 // SetFailureStrategy mocks base method.
 func (m *MockOptions) SetFailureStrategy(value FailureStrategy) Options {
 	m.ctrl.T.Helper()
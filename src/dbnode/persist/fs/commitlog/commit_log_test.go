@@ -805,7 +805,7 @@ func TestCommitLogWriteErrorOnFull(t *testing.T) {
 	// Set backlog of size one and don't automatically flush.
 	backlogQueueSize := 1
 	flushInterval := time.Duration(0)
-	opts, _ := newTestOptions(t, overrides{
+	opts, scope := newTestOptions(t, overrides{
 		backlogQueueSize: &backlogQueueSize,
 		flushInterval:    &flushInterval,
 		strategy:         StrategyWriteBehind,
@@ -849,6 +849,25 @@ func TestCommitLogWriteErrorOnFull(t *testing.T) {
 	// Close and consequently flush.
 	require.NoError(t, commitLog.Close())
 
+	// Assert the dropped write and the latency of the accepted write were recorded.
+	require.Len(t, writes, backlogQueueSize)
+	queueFull, ok := snapshotCounterValue(scope, "commitlog.writes.queue-full")
+	require.True(t, ok)
+	require.Equal(t, int64(1), queueFull.Value())
+	histograms := scope.Snapshot().Histograms()
+	for _, name := range []string{
+		"commitlog.writes.enqueue-to-write-latency",
+		"commitlog.writes.write-to-flush-latency",
+	} {
+		histogram, ok := histograms[tally.KeyForPrefixedStringMap(name, nil)]
+		require.True(t, ok, name)
+		var count int64
+		for _, v := range histogram.Durations() {
+			count += v
+		}
+		require.Equal(t, int64(1), count, name)
+	}
+
 	// Assert write flushed by reading the commit log.
 	assertCommitLogWritesByIterating(t, commitLog, writes)
 }
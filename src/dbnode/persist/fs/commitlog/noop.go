@@ -0,0 +1,78 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"errors"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/ts/writes"
+	"github.com/m3db/m3/src/x/context"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+var errNoopCommitLogWrite = errors.New("commit log disabled, writes are not accepted")
+
+type noopCommitLog struct{}
+
+// NewNoopCommitLog returns a commit log that writes nothing and rejects
+// writes, used by databases that only serve data already on disk.
+func NewNoopCommitLog() CommitLog {
+	return noopCommitLog{}
+}
+
+func (noopCommitLog) Open() error {
+	return nil
+}
+
+func (noopCommitLog) Write(
+	ctx context.Context,
+	series ts.Series,
+	datapoint ts.Datapoint,
+	unit xtime.Unit,
+	annotation ts.Annotation,
+) error {
+	return errNoopCommitLogWrite
+}
+
+func (noopCommitLog) WriteBatch(
+	ctx context.Context,
+	writes writes.WriteBatch,
+) error {
+	return errNoopCommitLogWrite
+}
+
+func (noopCommitLog) Close() error {
+	return nil
+}
+
+func (noopCommitLog) ActiveLogs() (persist.CommitLogFiles, error) {
+	return nil, nil
+}
+
+func (noopCommitLog) RotateLogs() (persist.CommitLogFile, error) {
+	return persist.CommitLogFile{}, nil
+}
+
+func (noopCommitLog) QueueLength() int64 {
+	return 0
+}
@@ -26,6 +26,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/ident"
@@ -92,6 +93,7 @@ type options struct {
 	readConcurrency         int
 	failureMode             FailureStrategy
 	failureCallback         FailureCallback
+	healthTracker           health.Tracker
 }
 
 type optionsInput struct {
@@ -156,6 +158,7 @@ func NewOptions(setters ...OptionSetter) Options {
 		}),
 		readConcurrency: defaultReadConcurrency,
 		failureCallback: nil,
+		healthTracker:   health.NewNoopTracker(),
 	}
 
 	o.bytesPool.Init()
@@ -328,3 +331,13 @@ func (o *options) SetFailureCallback(value FailureCallback) Options {
 func (o *options) FailureCallback() FailureCallback {
 	return o.failureCallback
 }
+
+func (o *options) SetHealthTracker(value health.Tracker) Options {
+	opts := *o
+	opts.healthTracker = value
+	return &opts
+}
+
+func (o *options) HealthTracker() health.Tracker {
+	return o.healthTracker
+}
@@ -23,6 +23,7 @@ package commitlog
 import (
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -247,6 +248,14 @@ type Options interface {
 
 	// FailureCallback returns the strategy.
 	FailureCallback() FailureCallback
+
+	// SetHealthTracker sets the tracker the queue depth, dropped writes and
+	// flush latency of the commit log are reported to.
+	SetHealthTracker(value health.Tracker) Options
+
+	// HealthTracker returns the tracker the queue depth, dropped writes and
+	// flush latency of the commit log are reported to.
+	HealthTracker() health.Tracker
 }
 
 // FileFilterInfo contains information about a commitog file that can be used to
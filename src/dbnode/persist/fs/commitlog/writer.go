@@ -31,12 +31,16 @@ import (
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/fault"
 	xos "github.com/m3db/m3/src/x/os"
 	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/uber-go/tally"
 )
 
 const (
@@ -120,14 +124,18 @@ func newCommitLogWriter(
 	flushFn flushFn,
 	opts Options,
 ) commitLogWriter {
-	shouldFsync := opts.Strategy() == StrategyWriteWait
+	var (
+		shouldFsync  = opts.Strategy() == StrategyWriteWait
+		scope        = opts.InstrumentOptions().MetricsScope().SubScope("commitlog")
+		fsyncLatency = scope.Histogram("writes.fsync-latency", latencyBuckets)
+	)
 
 	return &writer{
 		filePathPrefix:      opts.FilesystemOptions().FilePathPrefix(),
 		newFileMode:         opts.FilesystemOptions().NewFileMode(),
 		newDirectoryMode:    opts.FilesystemOptions().NewDirectoryMode(),
 		nowFn:               opts.ClockOptions().NowFn(),
-		chunkWriter:         newChunkWriter(flushFn, shouldFsync),
+		chunkWriter:         newChunkWriter(flushFn, shouldFsync, fsyncLatency, opts.FilesystemOptions()),
 		chunkReserveHeader:  make([]byte, chunkHeaderLen),
 		buffer:              bufio.NewWriterSize(nil, opts.FlushSize()),
 		sizeBuffer:          make([]byte, binary.MaxVarintLen64),
@@ -300,17 +308,30 @@ func (w *writer) write(data []byte) error {
 }
 
 type fsChunkWriter struct {
-	fd      xos.File
-	flushFn flushFn
-	buff    []byte
-	fsync   bool
+	fd       xos.File
+	flushFn  flushFn
+	buff     []byte
+	fsync    bool
+	nowFn    clock.NowFn
+	volume   string
+	ioHealth iohealth.Tracker
+	latency  tally.Histogram
 }
 
-func newChunkWriter(flushFn flushFn, fsync bool) chunkWriter {
+func newChunkWriter(
+	flushFn flushFn,
+	fsync bool,
+	fsyncLatency tally.Histogram,
+	fsOpts fs.Options,
+) chunkWriter {
 	return &fsChunkWriter{
-		flushFn: flushFn,
-		buff:    make([]byte, chunkHeaderLen),
-		fsync:   fsync,
+		flushFn:  flushFn,
+		buff:     make([]byte, chunkHeaderLen),
+		fsync:    fsync,
+		nowFn:    fsOpts.ClockOptions().NowFn(),
+		volume:   fsOpts.FilePathPrefix(),
+		ioHealth: fsOpts.IOHealthTracker(),
+		latency:  fsyncLatency,
 	}
 }
 
@@ -333,7 +354,15 @@ func (w *fsChunkWriter) isOpen() bool {
 }
 
 func (w *fsChunkWriter) sync() error {
-	return w.fd.Sync()
+	start := w.nowFn()
+	err := fault.Inject(fault.CommitLogFsync)
+	if err == nil {
+		err = w.fd.Sync()
+	}
+	took := w.nowFn().Sub(start)
+	w.ioHealth.Record(w.volume, iohealth.OpFsync, took, err)
+	w.latency.RecordDuration(took)
+	return err
 }
 
 // Writes a custom header in front of p to a file and returns number of bytes of p successfully written to the file.
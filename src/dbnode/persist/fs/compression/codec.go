@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compression
+
+import (
+	"github.com/klauspost/compress/zstd"
+)
+
+type encoder struct {
+	header  []byte
+	encoder *zstd.Encoder
+}
+
+// NewEncoder returns a new encoder that compresses with the given dictionary,
+// or without a dictionary if it is empty.
+func NewEncoder(dict []byte) (Encoder, error) {
+	opts := []zstd.EOption{
+		// Series data is already checksummed before compression.
+		zstd.WithEncoderCRC(false),
+		zstd.WithEncoderConcurrency(1),
+		zstd.WithEncoderLevel(zstd.SpeedDefault),
+		// Single segment frames always store their content size so that the
+		// decoded size of a series can be read without decompressing it.
+		zstd.WithSingleSegment(true),
+	}
+	if len(dict) > 0 {
+		opts = append(opts, zstd.WithEncoderDict(dict))
+	}
+	enc, err := zstd.NewWriter(nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &encoder{
+		header:  Header(dict),
+		encoder: enc,
+	}, nil
+}
+
+func (e *encoder) Header() []byte {
+	return e.header
+}
+
+func (e *encoder) Encode(dst, src []byte) []byte {
+	return e.encoder.EncodeAll(src, dst)
+}
+
+func (e *encoder) Close() error {
+	return e.encoder.Close()
+}
+
+type decoder struct {
+	decoder *zstd.Decoder
+}
+
+// NewDecoder returns a new decoder that decompresses with the given
+// dictionary, or without a dictionary if it is empty.
+func NewDecoder(dict []byte) (Decoder, error) {
+	opts := []zstd.DOption{
+		// A decoder is held by every open reader of a compressed data file
+		// so keep them as small as possible.
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderLowmem(true),
+	}
+	if len(dict) > 0 {
+		opts = append(opts, zstd.WithDecoderDicts(dict))
+	}
+	dec, err := zstd.NewReader(nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &decoder{decoder: dec}, nil
+}
+
+func (d *decoder) Decode(dst, src []byte) ([]byte, error) {
+	return d.decoder.DecodeAll(src, dst)
+}
+
+func (d *decoder) Close() error {
+	d.decoder.Close()
+	return nil
+}
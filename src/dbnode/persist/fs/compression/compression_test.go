@@ -0,0 +1,216 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compression
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testSamples returns samples that share some of their contents with each
+// other like the data of series with similar values does.
+func testSamples(n int) [][]byte {
+	rng := rand.New(rand.NewSource(1))
+	shared := make([]byte, 256)
+	rng.Read(shared)
+
+	samples := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		unique := make([]byte, 32)
+		rng.Read(unique)
+
+		var sample []byte
+		sample = append(sample, shared[:48]...)
+		sample = append(sample, unique...)
+		sample = append(sample, shared[128:128+rng.Intn(128)]...)
+		sample = append(sample, fmt.Sprintf("series-%d", i)...)
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+// trainDictionary trains a dictionary from the samples and fails the test if
+// training fails.
+func trainDictionary(t *testing.T, samples [][]byte, maxSize int) []byte {
+	dict, err := TrainDictionary(samples, maxSize)
+	require.NoError(t, err)
+	return dict
+}
+
+func TestTrainDictionary(t *testing.T) {
+	samples := testSamples(1000)
+	dict := trainDictionary(t, samples, 4096)
+	require.NotNil(t, dict)
+
+	// Dictionaries start with the zstd dictionary magic number and their ID.
+	require.Equal(t, uint32(0xEC30A437), binary.LittleEndian.Uint32(dict))
+	id := binary.LittleEndian.Uint32(dict[4:])
+	require.Equal(t, dictionaryID(samples), id)
+	require.True(t, id >= minDictionaryID && id < maxDictionaryID)
+}
+
+func TestTrainDictionaryNoSharedContent(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	samples := make([][]byte, 10)
+	for i := range samples {
+		samples[i] = make([]byte, 64)
+		rng.Read(samples[i])
+	}
+	require.Nil(t, trainDictionary(t, samples, 4096))
+	require.Nil(t, trainDictionary(t, [][]byte{samples[0], samples[0]}, 4096))
+	require.Nil(t, trainDictionary(t, [][]byte{[]byte("short")}, 4096))
+	require.Nil(t, trainDictionary(t, testSamples(100), minDictionaryContentSize-1))
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	samples := testSamples(1000)
+	for _, test := range []struct {
+		name string
+		dict []byte
+	}{
+		{name: "with dictionary", dict: trainDictionary(t, samples, 4096)},
+		{name: "without dictionary"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc, err := NewEncoder(test.dict)
+			require.NoError(t, err)
+			defer enc.Close()
+
+			dict, headerSize, err := ParseHeader(enc.Header())
+			require.NoError(t, err)
+			require.Equal(t, len(enc.Header()), headerSize)
+			require.Equal(t, len(test.dict), len(dict))
+
+			dec, err := NewDecoder(dict)
+			require.NoError(t, err)
+			defer dec.Close()
+
+			var compressed, decompressed []byte
+			for _, sample := range samples {
+				compressed = enc.Encode(compressed[:0], sample)
+				decompressed, err = dec.Decode(decompressed[:0], compressed)
+				require.NoError(t, err)
+				require.Equal(t, sample, decompressed)
+
+				size, err := DecodedSize(compressed)
+				require.NoError(t, err)
+				require.Equal(t, len(sample), size)
+			}
+		})
+	}
+}
+
+func TestDictionaryImprovesCompression(t *testing.T) {
+	samples := testSamples(1000)
+	compressedSize := func(dict []byte) int {
+		enc, err := NewEncoder(dict)
+		require.NoError(t, err)
+		defer enc.Close()
+
+		size := 0
+		for _, sample := range samples {
+			size += len(enc.Encode(nil, sample))
+		}
+		return size
+	}
+
+	withDict := compressedSize(trainDictionary(t, samples, 4096))
+	withoutDict := compressedSize(nil)
+	require.True(t, withDict < withoutDict,
+		"expected %d to be less than %d", withDict, withoutDict)
+}
+
+func TestDecodedSize(t *testing.T) {
+	enc, err := NewEncoder(nil)
+	require.NoError(t, err)
+	defer enc.Close()
+
+	// Sizes that are encoded with each of the content size field sizes.
+	for _, n := range []int{1, 255, 256, 65791, 65792, 1 << 20} {
+		size, err := DecodedSize(enc.Encode(nil, make([]byte, n)))
+		require.NoError(t, err)
+		require.Equal(t, n, size)
+	}
+
+	_, err = DecodedSize([]byte{1, 2, 3, 4, 5, 6})
+	require.Equal(t, errInvalidFrame, err)
+}
+
+func TestHeader(t *testing.T) {
+	dict := trainDictionary(t, testSamples(100), 1024)
+	header := Header(dict)
+	require.True(t, IsCompressed(header))
+
+	parsed, size, err := ParseHeader(append(header, 1, 2, 3))
+	require.NoError(t, err)
+	require.Equal(t, dict, parsed)
+	require.Equal(t, len(header), size)
+
+	read, size, err := ReadHeader(bytes.NewReader(append(header, 1, 2, 3)))
+	require.NoError(t, err)
+	require.Equal(t, dict, read)
+	require.Equal(t, len(header), size)
+
+	_, _, err = ParseHeader(header[:len(header)-1])
+	require.Equal(t, errHeaderTruncated, err)
+	_, _, err = ReadHeader(bytes.NewReader(header[:len(header)-1]))
+	require.Equal(t, errHeaderTruncated, err)
+	_, _, err = ParseHeader(header[:fixedHeaderSize-1])
+	require.Equal(t, errHeaderTruncated, err)
+
+	invalid := append([]byte(nil), header...)
+	invalid[0] = 'X'
+	require.False(t, IsCompressed(invalid))
+	_, _, err = ParseHeader(invalid)
+	require.Equal(t, errInvalidMagic, err)
+
+	invalid = append([]byte(nil), header...)
+	invalid[len(magic)] = formatVersion + 1
+	_, _, err = ParseHeader(invalid)
+	require.Error(t, err)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	require.NoError(t, NewOptions().Validate())
+	require.Error(t, NewOptions().SetDictionarySize(minDictionarySize-1).Validate())
+	require.Error(t, NewOptions().SetDictionarySize(maxDictionarySize+1).Validate())
+	require.Error(t, NewOptions().SetTrainingSize(defaultDictionarySize-1).Validate())
+	require.Error(t, NewOptions().SetTrainingSize(maxTrainingSize+1).Validate())
+}
+
+func TestOptionsNamespaceCompressed(t *testing.T) {
+	opts := NewOptions()
+	require.False(t, opts.NamespaceCompressed(ident.StringID("foo")))
+
+	opts = opts.SetEnabled(true)
+	require.True(t, opts.NamespaceCompressed(ident.StringID("foo")))
+
+	opts = opts.SetNamespaces([]string{"bar"})
+	require.False(t, opts.NamespaceCompressed(ident.StringID("foo")))
+	require.True(t, opts.NamespaceCompressed(ident.StringID("bar")))
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compression
+
+// Configuration is the configuration for compressing the data files of
+// filesets on top of the compression of the series data itself.
+type Configuration struct {
+	// Enabled enables compression of new data files, data files that were
+	// written compressed are readable regardless.
+	Enabled bool `yaml:"enabled"`
+
+	// Namespaces are the namespaces whose data files are compressed, if
+	// empty then the data files of all namespaces are compressed.
+	Namespaces []string `yaml:"namespaces"`
+
+	// DictionarySize is the maximum size of the dictionary trained for each
+	// data file.
+	DictionarySize *int `yaml:"dictionarySize"`
+
+	// TrainingSize is the amount of series data buffered at the start of
+	// each data file to train its dictionary from.
+	TrainingSize *int `yaml:"trainingSize"`
+}
+
+// NewOptions returns new compression options from the configuration.
+func (c Configuration) NewOptions() (Options, error) {
+	opts := NewOptions().
+		SetEnabled(c.Enabled).
+		SetNamespaces(c.Namespaces)
+	if c.DictionarySize != nil {
+		opts = opts.SetDictionarySize(*c.DictionarySize)
+	}
+	if c.TrainingSize != nil {
+		opts = opts.SetTrainingSize(*c.TrainingSize)
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
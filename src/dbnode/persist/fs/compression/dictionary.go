@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compression
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// minDictionaryContentSize is the minimum size of the content of a
+	// dictionary since the repeat offsets must be within the content.
+	minDictionaryContentSize = 8
+
+	// dmerSize is the size of the substrings whose frequency is counted.
+	dmerSize = 8
+
+	// Dictionary IDs below 32768 and above 2^31 are reserved.
+	minDictionaryID = 1 << 15
+	maxDictionaryID = 1 << 31
+)
+
+// TrainDictionary returns a zstd dictionary whose content is at most maxSize
+// bytes built from the given samples, or nil if the samples share too little
+// to be worth a dictionary. The dictionary ID is derived from the samples.
+func TrainDictionary(samples [][]byte, maxSize int) ([]byte, error) {
+	if maxSize < minDictionaryContentSize {
+		return nil, nil
+	}
+	// The builder selects the substrings that appear in more samples than
+	// average so it has nothing to select when they all appear in as many.
+	if shared, uniform := sharedContent(samples); !shared || uniform {
+		return nil, nil
+	}
+	return dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: maxSize,
+		HashBytes:   dmerSize,
+		ZstdDictID:  dictionaryID(samples),
+		ZstdLevel:   zstd.SpeedDefault,
+	})
+}
+
+// sharedContent returns whether any substring appears in more than one of
+// the samples and whether every substring appears in as many samples.
+func sharedContent(samples [][]byte) (shared bool, uniform bool) {
+	counts := make(map[uint64]int)
+	seen := make(map[uint64]struct{})
+	for _, sample := range samples {
+		for k := range seen {
+			delete(seen, k)
+		}
+		for i := 0; i+dmerSize <= len(sample); i++ {
+			dmer := binary.LittleEndian.Uint64(sample[i:])
+			if _, ok := seen[dmer]; ok {
+				continue
+			}
+			seen[dmer] = struct{}{}
+			counts[dmer]++
+		}
+	}
+
+	uniform = true
+	first := -1
+	for _, count := range counts {
+		if count > 1 {
+			shared = true
+		}
+		if first < 0 {
+			first = count
+		} else if count != first {
+			uniform = false
+		}
+	}
+	return shared, uniform
+}
+
+func dictionaryID(samples [][]byte) uint32 {
+	hash := fnv.New32a()
+	for _, sample := range samples {
+		_, _ = hash.Write(sample)
+	}
+	return minDictionaryID + hash.Sum32()%(maxDictionaryID-minDictionaryID)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compression
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The compressed data file format is a header followed by the data of each
+// series compressed as its own zstd frame so that series remain randomly
+// accessible by their offset and size:
+//
+//	magic (4 bytes) | version (1 byte) | dictionary length (4 bytes) |
+//	dictionary
+//
+// The dictionary is empty when too little data was written to train one in
+// which case frames are compressed without a dictionary.
+const (
+	magic = "M3FZ"
+
+	formatVersion uint8 = 1
+
+	fixedHeaderSize = len(magic) + 1 + 4
+
+	frameMagic = 0xFD2FB528
+)
+
+var (
+	errInvalidMagic    = errors.New("compressed file has invalid magic")
+	errHeaderTruncated = errors.New("compressed file header is truncated")
+
+	errInvalidFrame        = errors.New("compressed data is not a zstd frame")
+	errFrameSizeNotPresent = errors.New("compressed data frame has no content size")
+)
+
+// MagicSize is the number of bytes required to detect whether a file is compressed.
+const MagicSize = len(magic)
+
+// IsCompressed returns whether the contents beginning with the given prefix
+// are compressed, the prefix must be at least MagicSize bytes long.
+func IsCompressed(prefix []byte) bool {
+	return len(prefix) >= len(magic) && string(prefix[:len(magic)]) == magic
+}
+
+// Header returns the header of a compressed file whose frames are compressed
+// with the given dictionary.
+func Header(dict []byte) []byte {
+	b := make([]byte, fixedHeaderSize, fixedHeaderSize+len(dict))
+	copy(b, magic)
+	b[len(magic)] = formatVersion
+	binary.LittleEndian.PutUint32(b[len(magic)+1:], uint32(len(dict)))
+	return append(b, dict...)
+}
+
+// ParseHeader returns the dictionary and the size of the header at the start
+// of the contents of a compressed file.
+func ParseHeader(contents []byte) ([]byte, int, error) {
+	dictSize, err := parseFixedHeader(contents)
+	if err != nil {
+		return nil, 0, err
+	}
+	size := fixedHeaderSize + dictSize
+	if len(contents) < size {
+		return nil, 0, errHeaderTruncated
+	}
+	return contents[fixedHeaderSize:size], size, nil
+}
+
+// ReadHeader returns the dictionary and the size of the header at the start
+// of a compressed file.
+func ReadHeader(r io.ReaderAt) ([]byte, int, error) {
+	var fixed [fixedHeaderSize]byte
+	if err := readFullAt(r, fixed[:], 0); err != nil {
+		return nil, 0, err
+	}
+	dictSize, err := parseFixedHeader(fixed[:])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dict := make([]byte, dictSize)
+	if err := readFullAt(r, dict, int64(fixedHeaderSize)); err != nil {
+		return nil, 0, err
+	}
+	return dict, fixedHeaderSize + dictSize, nil
+}
+
+func readFullAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		return errHeaderTruncated
+	}
+	return err
+}
+
+func parseFixedHeader(b []byte) (int, error) {
+	if len(b) < fixedHeaderSize {
+		return 0, errHeaderTruncated
+	}
+	if !IsCompressed(b) {
+		return 0, errInvalidMagic
+	}
+	if version := b[len(magic)]; version != formatVersion {
+		return 0, fmt.Errorf("unsupported compressed file version: %d", version)
+	}
+	dictSize := binary.LittleEndian.Uint32(b[len(magic)+1:])
+	if dictSize > maxDictionarySize {
+		return 0, fmt.Errorf("compressed file dictionary too large: %d", dictSize)
+	}
+	return int(dictSize), nil
+}
+
+// DecodedSize returns the size of the decompressed data of a series from the
+// header of its compressed frame without decompressing it.
+func DecodedSize(frame []byte) (int, error) {
+	// See RFC 8878 section 3.1.1.1 for the layout of frame headers.
+	if len(frame) < 5 || binary.LittleEndian.Uint32(frame) != frameMagic {
+		return 0, errInvalidFrame
+	}
+	var (
+		descriptor    = frame[4]
+		sizeFlag      = descriptor >> 6
+		singleSegment = descriptor&0x20 != 0
+		dictIDFlag    = descriptor & 0x3
+		offset        = 5
+	)
+	if !singleSegment {
+		// Skip the window descriptor.
+		offset++
+	}
+	offset += [4]int{0, 1, 2, 4}[dictIDFlag]
+
+	sizeBytes := [4]int{0, 2, 4, 8}[sizeFlag]
+	if sizeFlag == 0 && singleSegment {
+		sizeBytes = 1
+	}
+	if sizeBytes == 0 {
+		return 0, errFrameSizeNotPresent
+	}
+	if len(frame) < offset+sizeBytes {
+		return 0, errInvalidFrame
+	}
+
+	b := frame[offset:]
+	switch sizeBytes {
+	case 1:
+		return int(b[0]), nil
+	case 2:
+		return int(binary.LittleEndian.Uint16(b)) + 256, nil
+	case 4:
+		return int(binary.LittleEndian.Uint32(b)), nil
+	default:
+		return int(binary.LittleEndian.Uint64(b)), nil
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compression
+
+import (
+	"fmt"
+
+	"github.com/m3db/m3/src/x/ident"
+)
+
+const (
+	// defaultDictionarySize is the default maximum size of dictionaries.
+	defaultDictionarySize = 1 << 14
+	// minDictionarySize is the minimum size of dictionaries, smaller
+	// dictionaries have too little content to be worth their header.
+	minDictionarySize = 1 << 8
+	// maxDictionarySize is the maximum size of dictionaries, every reader
+	// of a data file holds its dictionary in memory.
+	maxDictionarySize = 1 << 20
+
+	// defaultTrainingSize is the default amount of series data to train
+	// dictionaries from.
+	defaultTrainingSize = 1 << 20
+	// maxTrainingSize is the maximum amount of series data to train
+	// dictionaries from, it is held in memory until the dictionary is trained.
+	maxTrainingSize = 1 << 26
+)
+
+type options struct {
+	enabled        bool
+	namespaces     []string
+	dictionarySize int
+	trainingSize   int
+}
+
+// NewOptions returns new compression options.
+func NewOptions() Options {
+	return &options{
+		dictionarySize: defaultDictionarySize,
+		trainingSize:   defaultTrainingSize,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.dictionarySize < minDictionarySize || o.dictionarySize > maxDictionarySize {
+		return fmt.Errorf(
+			"invalid compression dictionary size, must be >= %d and <= %d: instead %d",
+			minDictionarySize, maxDictionarySize, o.dictionarySize)
+	}
+	if o.trainingSize < o.dictionarySize || o.trainingSize > maxTrainingSize {
+		return fmt.Errorf(
+			"invalid compression training size, must be >= dictionary size %d and <= %d: instead %d",
+			o.dictionarySize, maxTrainingSize, o.trainingSize)
+	}
+	return nil
+}
+
+func (o *options) SetEnabled(value bool) Options {
+	opts := *o
+	opts.enabled = value
+	return &opts
+}
+
+func (o *options) Enabled() bool {
+	return o.enabled
+}
+
+func (o *options) SetNamespaces(value []string) Options {
+	opts := *o
+	opts.namespaces = value
+	return &opts
+}
+
+func (o *options) Namespaces() []string {
+	return o.namespaces
+}
+
+func (o *options) SetDictionarySize(value int) Options {
+	opts := *o
+	opts.dictionarySize = value
+	return &opts
+}
+
+func (o *options) DictionarySize() int {
+	return o.dictionarySize
+}
+
+func (o *options) SetTrainingSize(value int) Options {
+	opts := *o
+	opts.trainingSize = value
+	return &opts
+}
+
+func (o *options) TrainingSize() int {
+	return o.trainingSize
+}
+
+func (o *options) NamespaceCompressed(namespace ident.ID) bool {
+	if !o.enabled {
+		return false
+	}
+	if len(o.namespaces) == 0 {
+		return true
+	}
+	for _, ns := range o.namespaces {
+		if namespace.String() == ns {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package compression provides zstd compression of fileset data files on top
+// of the compression of the series data itself.
+//
+// A dictionary is trained from the data of the first series written to each
+// data file and stored in a header at the start of the file, each series is
+// then compressed as its own frame with the dictionary so that series remain
+// readable individually by their offset and size in the index.
+package compression
+
+import (
+	"github.com/m3db/m3/src/x/ident"
+)
+
+// Options represents the options for fileset data compression.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetEnabled sets whether new data files are compressed, files that were
+	// written compressed remain readable regardless.
+	SetEnabled(value bool) Options
+
+	// Enabled returns whether new data files are compressed.
+	Enabled() bool
+
+	// SetNamespaces sets the namespaces whose data files are compressed, if
+	// empty then all namespaces are compressed when enabled.
+	SetNamespaces(value []string) Options
+
+	// Namespaces returns the namespaces whose data files are compressed.
+	Namespaces() []string
+
+	// SetDictionarySize sets the maximum size of the content of the
+	// dictionary trained for each data file.
+	SetDictionarySize(value int) Options
+
+	// DictionarySize returns the maximum size of the content of the
+	// dictionary trained for each data file.
+	DictionarySize() int
+
+	// SetTrainingSize sets the amount of series data buffered at the start of
+	// each data file to train its dictionary from.
+	SetTrainingSize(value int) Options
+
+	// TrainingSize returns the amount of series data buffered at the start of
+	// each data file to train its dictionary from.
+	TrainingSize() int
+
+	// NamespaceCompressed returns whether data files written for the namespace
+	// should be compressed.
+	NamespaceCompressed(namespace ident.ID) bool
+}
+
+// Encoder compresses the data of individual series.
+type Encoder interface {
+	// Header returns the header to write at the start of the data file.
+	Header() []byte
+
+	// Encode appends the compressed data of a series to dst.
+	Encode(dst, src []byte) []byte
+
+	// Close closes the encoder.
+	Close() error
+}
+
+// Decoder decompresses the data of individual series, it is safe for
+// concurrent use.
+type Decoder interface {
+	// Decode appends the decompressed data of a series to dst.
+	Decode(dst, src []byte) ([]byte, error)
+
+	// Close closes the decoder.
+	Close() error
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package datadirs
+
+import (
+	"os"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Configuration is the configuration for placing shard directories across
+// multiple data directories.
+type Configuration struct {
+	// Directories are the data directories, typically one per disk, that
+	// shard directories are placed on. When empty shard directories are
+	// stored directly under the file path prefix.
+	Directories []string `yaml:"directories"`
+
+	// HighWatermark is the used fraction of a data directory's volume at or
+	// above which it is not assigned new shards.
+	HighWatermark *float64 `yaml:"highWatermark"`
+}
+
+// NewLayout returns a new layout of shard directories for the configuration.
+func (c Configuration) NewLayout(
+	filePathPrefix string,
+	newDirectoryMode os.FileMode,
+	ioHealthTracker iohealth.Tracker,
+	iOpts instrument.Options,
+) (Layout, error) {
+	opts := NewOptions().
+		SetInstrumentOptions(iOpts).
+		SetIOHealthTracker(ioHealthTracker).
+		SetFilePathPrefix(filePathPrefix).
+		SetNewDirectoryMode(newDirectoryMode).
+		SetDirectories(c.Directories)
+	if c.HighWatermark != nil {
+		opts = opts.SetHighWatermark(*c.HighWatermark)
+	}
+	return NewLayout(opts)
+}
@@ -0,0 +1,481 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package datadirs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+
+	"github.com/m3db/m3/src/x/ident"
+)
+
+const rebalanceSuffix = ".rebalance"
+
+type layoutMetrics struct {
+	rebalanced     tally.Counter
+	rebalanceError tally.Counter
+	capacityError  tally.Counter
+}
+
+func newLayoutMetrics(scope tally.Scope) layoutMetrics {
+	return layoutMetrics{
+		rebalanced:     scope.Counter("rebalanced"),
+		rebalanceError: scope.Counter("rebalance-error"),
+		capacityError:  scope.Counter("capacity-error"),
+	}
+}
+
+type directoryMetrics struct {
+	usedFraction   tally.Gauge
+	availableBytes tally.Gauge
+	full           tally.Gauge
+}
+
+type layout struct {
+	sync.RWMutex
+
+	opts        Options
+	prefix      string
+	directories []string
+	full        map[string]bool
+	scope       tally.Scope
+	logger      *zap.Logger
+	metrics     layoutMetrics
+	dirMetrics  map[string]directoryMetrics
+}
+
+// NewLayout returns a new layout of shard directories across the configured
+// data directories, when there are none shard directories are stored directly
+// under the file path prefix.
+func NewLayout(opts Options) (Layout, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	directories := make([]string, 0, len(opts.Directories()))
+	for _, dir := range opts.Directories() {
+		directories = append(directories, filepath.Clean(dir))
+	}
+	sort.Strings(directories)
+
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("data-directories")
+	l := &layout{
+		opts:        opts,
+		prefix:      filepath.Clean(opts.FilePathPrefix()),
+		directories: directories,
+		full:        make(map[string]bool, len(directories)),
+		scope:       scope,
+		logger:      opts.InstrumentOptions().Logger(),
+		metrics:     newLayoutMetrics(scope),
+		dirMetrics:  make(map[string]directoryMetrics, len(directories)),
+	}
+	for _, dir := range directories {
+		if err := os.MkdirAll(dir, opts.NewDirectoryMode()); err != nil {
+			return nil, fmt.Errorf("could not create data directory %s: %w", dir, err)
+		}
+		dirScope := scope.Tagged(map[string]string{"directory": dir})
+		l.dirMetrics[dir] = directoryMetrics{
+			usedFraction:   dirScope.Gauge("used-fraction"),
+			availableBytes: dirScope.Gauge("available-bytes"),
+			full:           dirScope.Gauge("full"),
+		}
+	}
+
+	if _, err := l.UpdateCapacity(); err != nil {
+		// Capacity is best effort, placement treats directories with unknown
+		// capacity as having room.
+		l.logger.Warn("could not determine data directory capacity", zap.Error(err))
+	}
+	return l, nil
+}
+
+// NewNoopLayout returns a layout that stores shard directories directly under
+// the file path prefix.
+func NewNoopLayout() Layout {
+	return &layout{
+		opts:   NewOptions(),
+		full:   make(map[string]bool),
+		logger: zap.NewNop(),
+	}
+}
+
+func (l *layout) Directories() []string {
+	return l.directories
+}
+
+func (l *layout) Directory(namespace ident.ID, shard uint32) string {
+	if len(l.directories) == 0 {
+		return l.prefix
+	}
+
+	var (
+		preferences = l.preferences(namespace, shard)
+		ioHealth    = l.opts.IOHealthTracker()
+	)
+	l.RLock()
+	defer l.RUnlock()
+	for _, dir := range preferences {
+		if !l.full[dir] && !ioHealth.Excluded(dir) {
+			return dir
+		}
+	}
+	// All data directories are full, fall back to the most preferred.
+	return preferences[0]
+}
+
+// preferences returns the data directories in the order a shard prefers
+// them, ranking each by a hash of the directory and the shard so that the
+// relative order of any two directories does not depend on the others.
+func (l *layout) preferences(namespace ident.ID, shard uint32) []string {
+	type ranked struct {
+		dir    string
+		weight uint64
+	}
+	var (
+		key    = namespace.String() + "/" + strconv.Itoa(int(shard))
+		ranks  = make([]ranked, 0, len(l.directories))
+		result = make([]string, 0, len(l.directories))
+	)
+	for _, dir := range l.directories {
+		ranks = append(ranks, ranked{dir: dir, weight: xxhash.Sum64String(dir + "\x00" + key)})
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].weight != ranks[j].weight {
+			return ranks[i].weight > ranks[j].weight
+		}
+		return ranks[i].dir < ranks[j].dir
+	})
+	for _, r := range ranks {
+		result = append(result, r.dir)
+	}
+	return result
+}
+
+func (l *layout) EnsureShardDirectory(
+	shardDirPath string,
+	namespace ident.ID,
+	shard uint32,
+) error {
+	if len(l.directories) == 0 {
+		return nil
+	}
+
+	mode := l.opts.NewDirectoryMode()
+	info, err := os.Lstat(shardDirPath)
+	switch {
+	case err == nil && info.Mode()&os.ModeSymlink != 0:
+		// Recreate the target in case the data directory was replaced.
+		target, err := l.Resolve(shardDirPath)
+		if err != nil {
+			return err
+		}
+		return os.MkdirAll(target, mode)
+	case err == nil && info.IsDir():
+		// Shard directories that predate the data directories are moved
+		// when rebalancing.
+		return nil
+	case err == nil:
+		return fmt.Errorf("shard directory is not a directory: %s", shardDirPath)
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	target, err := l.targetPath(l.Directory(namespace, shard), shardDirPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(target, mode); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(shardDirPath), mode); err != nil {
+		return err
+	}
+	if err := os.Symlink(target, shardDirPath); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *layout) Rebalance(
+	shardDirPath string,
+	namespace ident.ID,
+	shard uint32,
+) (bool, error) {
+	if len(l.directories) == 0 {
+		return false, nil
+	}
+
+	info, err := os.Lstat(shardDirPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	isLink := info.Mode()&os.ModeSymlink != 0
+
+	current, err := l.Resolve(shardDirPath)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		preferences = l.preferences(namespace, shard)
+		currentDir  = l.containingDirectory(current)
+		targetDir   string
+	)
+	l.RLock()
+	preferredFull := l.full[preferences[0]]
+	l.RUnlock()
+	switch {
+	case currentDir == preferences[0]:
+		return false, nil
+	case currentDir != "" && preferredFull:
+		// Stay on a configured data directory until the preferred one has room.
+		return false, nil
+	case currentDir != "":
+		targetDir = preferences[0]
+	default:
+		// Either not yet on a data directory or on one that was removed.
+		targetDir = l.Directory(namespace, shard)
+	}
+
+	target, err := l.targetPath(targetDir, shardDirPath)
+	if err != nil {
+		return false, err
+	}
+	if err := l.move(shardDirPath, current, target, isLink); err != nil {
+		l.metrics.rebalanceError.Inc(1)
+		return false, fmt.Errorf("could not move shard directory %s to %s: %w",
+			shardDirPath, target, err)
+	}
+
+	l.metrics.rebalanced.Inc(1)
+	l.logger.Info("moved shard directory to data directory",
+		zap.String("shardDirectory", shardDirPath),
+		zap.String("from", current),
+		zap.String("to", target))
+	return true, nil
+}
+
+func (l *layout) move(shardDirPath, current, target string, isLink bool) error {
+	if err := os.MkdirAll(target, l.opts.NewDirectoryMode()); err != nil {
+		return err
+	}
+
+	// A dangling symlink means the data directory is gone, there is nothing
+	// to copy and the shard is recovered by peers or repair.
+	if _, err := os.Stat(current); err == nil {
+		if err := copyDirectory(current, target); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if isLink {
+		// Swap the symlink atomically so the shard directory always resolves.
+		tmp := shardDirPath + rebalanceSuffix
+		if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Symlink(target, tmp); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, shardDirPath); err != nil {
+			return err
+		}
+	} else {
+		// A directory cannot be atomically replaced by a symlink.
+		if err := os.RemoveAll(shardDirPath); err != nil {
+			return err
+		}
+		if err := os.Symlink(target, shardDirPath); err != nil {
+			return err
+		}
+	}
+
+	if current == shardDirPath {
+		return nil
+	}
+	if err := os.RemoveAll(current); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *layout) Volume(shardDirPath string) string {
+	if len(l.directories) == 0 {
+		return ""
+	}
+	target, err := l.Resolve(shardDirPath)
+	if err != nil {
+		return ""
+	}
+	return l.containingDirectory(target)
+}
+
+func (l *layout) Resolve(shardDirPath string) (string, error) {
+	target, err := os.Readlink(shardDirPath)
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) && !os.IsNotExist(err) {
+			// Not a symlink.
+			return shardDirPath, nil
+		}
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(shardDirPath), target)
+	}
+	return filepath.Clean(target), nil
+}
+
+func (l *layout) UpdateCapacity() ([]DirectoryCapacity, error) {
+	var (
+		result    = make([]DirectoryCapacity, 0, len(l.directories))
+		full      = make(map[string]bool, len(l.directories))
+		firstErr  error
+		watermark = l.opts.HighWatermark()
+	)
+	for _, dir := range l.directories {
+		total, available, err := l.opts.CapacityFn()(dir)
+		if err != nil {
+			l.metrics.capacityError.Inc(1)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("could not determine capacity of %s: %w", dir, err)
+			}
+			continue
+		}
+
+		capacity := DirectoryCapacity{
+			Path:           dir,
+			TotalBytes:     total,
+			AvailableBytes: available,
+		}
+		if total > 0 {
+			capacity.UsedFraction = float64(total-available) / float64(total)
+		}
+		capacity.Full = capacity.UsedFraction >= watermark
+		full[dir] = capacity.Full
+		result = append(result, capacity)
+
+		metrics := l.dirMetrics[dir]
+		metrics.usedFraction.Update(capacity.UsedFraction)
+		metrics.availableBytes.Update(float64(capacity.AvailableBytes))
+		metrics.full.Update(boolToFloat(capacity.Full))
+	}
+
+	l.Lock()
+	l.full = full
+	l.Unlock()
+	return result, firstErr
+}
+
+func (l *layout) targetPath(dataDir, shardDirPath string) (string, error) {
+	rel, err := filepath.Rel(l.prefix, filepath.Clean(shardDirPath))
+	if err != nil {
+		return "", err
+	}
+	if rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("shard directory %s is not beneath file path prefix %s",
+			shardDirPath, l.prefix)
+	}
+	return filepath.Join(dataDir, rel), nil
+}
+
+func (l *layout) containingDirectory(path string) string {
+	for _, dir := range l.directories {
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return dir
+		}
+	}
+	return ""
+}
+
+func copyDirectory(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			return fmt.Errorf("unexpected non-regular file in shard directory: %s",
+				filepath.Join(src, entry.Name()))
+		}
+		if err := copyFile(
+			filepath.Join(src, entry.Name()),
+			filepath.Join(dst, entry.Name()),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	// Hard link when on the same volume to avoid copying data.
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint: errcheck
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close() // nolint: errcheck
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close() // nolint: errcheck
+		return err
+	}
+	return out.Close()
+}
+
+func boolToFloat(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package datadirs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCapacity map[string]float64
+
+func (c testCapacity) capacityFn(path string) (uint64, uint64, error) {
+	used := c[path]
+	return 1000, uint64((1 - used) * 1000), nil
+}
+
+func newTestLayout(
+	t *testing.T,
+	prefix string,
+	dirs []string,
+	capacity testCapacity,
+) Layout {
+	layout, err := NewLayout(NewOptions().
+		SetFilePathPrefix(prefix).
+		SetDirectories(dirs).
+		SetCapacityFn(capacity.capacityFn))
+	require.NoError(t, err)
+	return layout
+}
+
+func newTestDirs(t *testing.T) (string, []string) {
+	root, err := ioutil.TempDir("", "datadirs")
+	require.NoError(t, err)
+	return root, []string{
+		filepath.Join(root, "disk0"),
+		filepath.Join(root, "disk1"),
+		filepath.Join(root, "disk2"),
+	}
+}
+
+func TestLayoutPlacementIsDeterministicAndStable(t *testing.T) {
+	root, dirs := newTestDirs(t)
+	defer os.RemoveAll(root)
+
+	var (
+		prefix = filepath.Join(root, "prefix")
+		ns     = ident.StringID("metrics")
+		all    = newTestLayout(t, prefix, dirs, testCapacity{})
+		fewer  = newTestLayout(t, prefix, dirs[:2], testCapacity{})
+		counts = make(map[string]int)
+	)
+	for shard := uint32(0); shard < 256; shard++ {
+		dir := all.Directory(ns, shard)
+		require.Equal(t, dir, all.Directory(ns, shard))
+		counts[dir]++
+
+		// Removing a data directory only moves the shards placed on it.
+		if dir != dirs[2] {
+			require.Equal(t, dir, fewer.Directory(ns, shard))
+		}
+	}
+	require.Len(t, counts, 3)
+	for _, count := range counts {
+		require.True(t, count > 50, "unbalanced placement: %v", counts)
+	}
+}
+
+func TestLayoutPlacementSkipsFullDirectories(t *testing.T) {
+	root, dirs := newTestDirs(t)
+	defer os.RemoveAll(root)
+
+	layout := newTestLayout(t, filepath.Join(root, "prefix"), dirs,
+		testCapacity{dirs[0]: 0.95, dirs[1]: 0.95})
+	for shard := uint32(0); shard < 16; shard++ {
+		require.Equal(t, dirs[2], layout.Directory(ident.StringID("metrics"), shard))
+	}
+
+	capacity, err := layout.UpdateCapacity()
+	require.NoError(t, err)
+	require.Len(t, capacity, 3)
+	require.True(t, capacity[0].Full)
+	require.True(t, capacity[1].Full)
+	require.False(t, capacity[2].Full)
+}
+
+func TestLayoutEnsureAndRebalanceShardDirectory(t *testing.T) {
+	root, dirs := newTestDirs(t)
+	defer os.RemoveAll(root)
+
+	var (
+		prefix       = filepath.Join(root, "prefix")
+		ns           = ident.StringID("metrics")
+		shard        = uint32(7)
+		shardDirPath = filepath.Join(prefix, "data", "metrics", "7")
+	)
+
+	// A shard directory that predates the data directories.
+	require.NoError(t, os.MkdirAll(shardDirPath, 0755))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(shardDirPath, "fileset-0-0-data.db"), []byte("data"), 0644))
+
+	layout := newTestLayout(t, prefix, dirs, testCapacity{})
+	require.NoError(t, layout.EnsureShardDirectory(shardDirPath, ns, shard))
+	require.Equal(t, "", layout.Volume(shardDirPath))
+
+	moved, err := layout.Rebalance(shardDirPath, ns, shard)
+	require.NoError(t, err)
+	require.True(t, moved)
+
+	dir := layout.Directory(ns, shard)
+	require.Equal(t, dir, layout.Volume(shardDirPath))
+	resolved, err := layout.Resolve(shardDirPath)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "data", "metrics", "7"), resolved)
+
+	contents, err := ioutil.ReadFile(filepath.Join(shardDirPath, "fileset-0-0-data.db"))
+	require.NoError(t, err)
+	require.Equal(t, "data", string(contents))
+
+	moved, err = layout.Rebalance(shardDirPath, ns, shard)
+	require.NoError(t, err)
+	require.False(t, moved)
+
+	// Removing the data directory the shard is on moves it to another.
+	var remaining []string
+	for _, d := range dirs {
+		if d != dir {
+			remaining = append(remaining, d)
+		}
+	}
+	layout = newTestLayout(t, prefix, remaining, testCapacity{})
+	moved, err = layout.Rebalance(shardDirPath, ns, shard)
+	require.NoError(t, err)
+	require.True(t, moved)
+	require.Equal(t, layout.Directory(ns, shard), layout.Volume(shardDirPath))
+
+	contents, err = ioutil.ReadFile(filepath.Join(shardDirPath, "fileset-0-0-data.db"))
+	require.NoError(t, err)
+	require.Equal(t, "data", string(contents))
+	_, err = os.Stat(resolved)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestLayoutEnsureNewShardDirectory(t *testing.T) {
+	root, dirs := newTestDirs(t)
+	defer os.RemoveAll(root)
+
+	var (
+		prefix       = filepath.Join(root, "prefix")
+		ns           = ident.StringID("metrics")
+		shardDirPath = filepath.Join(prefix, "snapshots", "metrics", "3")
+		layout       = newTestLayout(t, prefix, dirs, testCapacity{})
+	)
+	require.NoError(t, layout.EnsureShardDirectory(shardDirPath, ns, 3))
+
+	info, err := os.Lstat(shardDirPath)
+	require.NoError(t, err)
+	require.True(t, info.Mode()&os.ModeSymlink != 0)
+	require.Equal(t, layout.Directory(ns, 3), layout.Volume(shardDirPath))
+
+	info, err = os.Stat(shardDirPath)
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package datadirs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultHighWatermark    = 0.9
+	defaultNewDirectoryMode = os.FileMode(0755)
+)
+
+var (
+	errInstrumentOptionsNotSet = errors.New("instrument options not set")
+	errFilePathPrefixNotSet    = errors.New("file path prefix not set")
+	errCapacityFnNotSet        = errors.New("capacity fn not set")
+	errIOHealthTrackerNotSet   = errors.New("io health tracker not set")
+)
+
+type options struct {
+	instrumentOpts   instrument.Options
+	filePathPrefix   string
+	directories      []string
+	highWatermark    float64
+	newDirectoryMode os.FileMode
+	ioHealthTracker  iohealth.Tracker
+	capacityFn       CapacityFn
+}
+
+// NewOptions returns a new set of data directory options.
+func NewOptions() Options {
+	return &options{
+		instrumentOpts:   instrument.NewOptions(),
+		highWatermark:    defaultHighWatermark,
+		newDirectoryMode: defaultNewDirectoryMode,
+		ioHealthTracker:  iohealth.NewNoopTracker(),
+		capacityFn:       volumeCapacity,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.instrumentOpts == nil {
+		return errInstrumentOptionsNotSet
+	}
+	if o.filePathPrefix == "" {
+		return errFilePathPrefixNotSet
+	}
+	if o.highWatermark <= 0 || o.highWatermark > 1 {
+		return fmt.Errorf(
+			"high watermark must be > 0 and <= 1: instead %f", o.highWatermark)
+	}
+	if o.ioHealthTracker == nil {
+		return errIOHealthTrackerNotSet
+	}
+	if o.capacityFn == nil {
+		return errCapacityFnNotSet
+	}
+	seen := make(map[string]struct{}, len(o.directories))
+	for _, dir := range o.directories {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("data directory must be an absolute path: %s", dir)
+		}
+		clean := filepath.Clean(dir)
+		if _, ok := seen[clean]; ok {
+			return fmt.Errorf("data directory specified more than once: %s", dir)
+		}
+		seen[clean] = struct{}{}
+	}
+	return nil
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetFilePathPrefix(value string) Options {
+	opts := *o
+	opts.filePathPrefix = value
+	return &opts
+}
+
+func (o *options) FilePathPrefix() string {
+	return o.filePathPrefix
+}
+
+func (o *options) SetDirectories(value []string) Options {
+	opts := *o
+	opts.directories = append([]string(nil), value...)
+	return &opts
+}
+
+func (o *options) Directories() []string {
+	return o.directories
+}
+
+func (o *options) SetHighWatermark(value float64) Options {
+	opts := *o
+	opts.highWatermark = value
+	return &opts
+}
+
+func (o *options) HighWatermark() float64 {
+	return o.highWatermark
+}
+
+func (o *options) SetNewDirectoryMode(value os.FileMode) Options {
+	opts := *o
+	opts.newDirectoryMode = value
+	return &opts
+}
+
+func (o *options) NewDirectoryMode() os.FileMode {
+	return o.newDirectoryMode
+}
+
+func (o *options) SetIOHealthTracker(value iohealth.Tracker) Options {
+	opts := *o
+	opts.ioHealthTracker = value
+	return &opts
+}
+
+func (o *options) IOHealthTracker() iohealth.Tracker {
+	return o.ioHealthTracker
+}
+
+func (o *options) SetCapacityFn(value CapacityFn) Options {
+	opts := *o
+	opts.capacityFn = value
+	return &opts
+}
+
+func (o *options) CapacityFn() CapacityFn {
+	return o.capacityFn
+}
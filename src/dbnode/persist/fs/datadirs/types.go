@@ -0,0 +1,156 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package datadirs places the shard directories of a node across multiple
+// data directories, typically one per disk, so that hosts with many disks can
+// use all of them without striping them into a single volume.
+//
+// Shard directories remain addressable under the file path prefix, when
+// multiple data directories are configured each one is a symlink into the data
+// directory the shard is placed on.
+package datadirs
+
+import (
+	"os"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
+	"github.com/m3db/m3/src/x/ident"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// DirectoryCapacity is the capacity of a single data directory.
+type DirectoryCapacity struct {
+	// Path is the path of the data directory.
+	Path string `json:"path"`
+	// TotalBytes is the size of the volume the data directory is on.
+	TotalBytes uint64 `json:"totalBytes"`
+	// AvailableBytes is the space available on the volume the data directory
+	// is on.
+	AvailableBytes uint64 `json:"availableBytes"`
+	// UsedFraction is the fraction of the volume that is in use.
+	UsedFraction float64 `json:"usedFraction"`
+	// Full is whether the used fraction is at or above the high watermark,
+	// full data directories are not assigned new shards.
+	Full bool `json:"full"`
+}
+
+// CapacityFn returns the total and available bytes of the volume a path is on.
+type CapacityFn func(path string) (totalBytes uint64, availableBytes uint64, err error)
+
+// Layout places shard directories across data directories. Placement is
+// deterministic, each shard has a preference order over the data directories
+// derived by rendezvous hashing so that adding or removing a data directory
+// only moves the shards that prefer it.
+type Layout interface {
+	// Directories returns the data directories, empty when shard directories
+	// are stored directly under the file path prefix.
+	Directories() []string
+
+	// Directory returns the data directory a shard should be placed on, the
+	// most preferred that is neither full nor excluded for poor IO health.
+	Directory(namespace ident.ID, shard uint32) string
+
+	// EnsureShardDirectory ensures the shard directory at the given path,
+	// which must be beneath the file path prefix, resolves to a directory on
+	// the data directory the shard is placed on. It is a no-op when there are
+	// no data directories, callers remain responsible for creating it.
+	EnsureShardDirectory(
+		shardDirPath string,
+		namespace ident.ID,
+		shard uint32,
+	) error
+
+	// Rebalance moves the contents of the shard directory at the given path
+	// to the data directory the shard should now be placed on, returning
+	// whether the shard directory was moved. Data directories only change
+	// with configuration so this is intended to be called on startup, it
+	// must not be called concurrently with reads or writes of the shard.
+	Rebalance(
+		shardDirPath string,
+		namespace ident.ID,
+		shard uint32,
+	) (bool, error)
+
+	// Volume returns the data directory the shard directory at the given path
+	// resolves to, empty if it does not resolve to one.
+	Volume(shardDirPath string) string
+
+	// Resolve returns the directory the shard directory at the given path
+	// resolves to, which is the path itself when it is not a symlink.
+	Resolve(shardDirPath string) (string, error)
+
+	// UpdateCapacity refreshes and returns the capacity of each data
+	// directory, placement of new shards uses the last refreshed capacity.
+	UpdateCapacity() ([]DirectoryCapacity, error)
+}
+
+// Options is a set of data directory options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+
+	// SetFilePathPrefix sets the file path prefix shard directories are
+	// addressed beneath.
+	SetFilePathPrefix(value string) Options
+
+	// FilePathPrefix returns the file path prefix shard directories are
+	// addressed beneath.
+	FilePathPrefix() string
+
+	// SetDirectories sets the data directories.
+	SetDirectories(value []string) Options
+
+	// Directories returns the data directories.
+	Directories() []string
+
+	// SetHighWatermark sets the used fraction of a data directory's volume
+	// at or above which it is not assigned new shards.
+	SetHighWatermark(value float64) Options
+
+	// HighWatermark returns the used fraction of a data directory's volume
+	// at or above which it is not assigned new shards.
+	HighWatermark() float64
+
+	// SetNewDirectoryMode sets the mode of new directories.
+	SetNewDirectoryMode(value os.FileMode) Options
+
+	// NewDirectoryMode returns the mode of new directories.
+	NewDirectoryMode() os.FileMode
+
+	// SetIOHealthTracker sets the tracker of the IO health of the data
+	// directories, excluded data directories are not assigned new shards.
+	SetIOHealthTracker(value iohealth.Tracker) Options
+
+	// IOHealthTracker returns the tracker of the IO health of the data
+	// directories.
+	IOHealthTracker() iohealth.Tracker
+
+	// SetCapacityFn sets the function used to determine volume capacity.
+	SetCapacityFn(value CapacityFn) Options
+
+	// CapacityFn returns the function used to determine volume capacity.
+	CapacityFn() CapacityFn
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package encryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var errNoKeyProviderConfigured = errors.New("no encryption key provider configured")
+
+// Configuration is the configuration for encrypting the data and index files
+// of data filesets at rest, the files of index segment filesets are not
+// encrypted.
+type Configuration struct {
+	// Enabled enables encryption of new fileset files, fileset files that
+	// were written encrypted are readable regardless as long as a key
+	// provider is configured.
+	Enabled bool `yaml:"enabled"`
+
+	// Namespaces are the namespaces whose fileset files are encrypted, if
+	// empty then the fileset files of all namespaces are encrypted.
+	Namespaces []string `yaml:"namespaces"`
+
+	// ChunkSize is the size of the plaintext chunks that are encrypted and
+	// authenticated individually, reads decrypt whole chunks.
+	ChunkSize *int `yaml:"chunkSize"`
+
+	// KeyProvider is the key provider used to wrap and unwrap data keys.
+	KeyProvider KeyProviderConfiguration `yaml:"keyProvider"`
+}
+
+// KeyProviderConfiguration is the configuration for the key provider, exactly
+// one key provider must be configured.
+type KeyProviderConfiguration struct {
+	// Static wraps data keys with a static set of key encryption keys.
+	Static *StaticKeyProviderConfiguration `yaml:"static"`
+}
+
+// StaticKeyProviderConfiguration is the configuration for a static key provider.
+type StaticKeyProviderConfiguration struct {
+	// ActiveKeyID is the ID of the key used to wrap new data keys.
+	ActiveKeyID string `yaml:"activeKeyID" validate:"nonzero"`
+
+	// Keys are the base64 encoded key encryption keys by key ID, keys that are
+	// no longer active should be kept until all files using them have expired.
+	// Keys of 16, 24 or 32 bytes select AES-128, AES-192 or AES-256 respectively.
+	Keys map[string]string `yaml:"keys" validate:"nonzero"`
+}
+
+// NewOptions returns the encryption options for the configuration.
+func (c Configuration) NewOptions() (Options, error) {
+	opts := NewOptions().
+		SetEnabled(c.Enabled).
+		SetNamespaces(c.Namespaces)
+	if c.ChunkSize != nil {
+		opts = opts.SetChunkSize(*c.ChunkSize)
+	}
+
+	keyProvider, err := c.KeyProvider.NewKeyProvider()
+	if err != nil && (c.Enabled || err != errNoKeyProviderConfigured) {
+		return nil, err
+	}
+	opts = opts.SetKeyProvider(keyProvider)
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// NewKeyProvider returns the configured key provider.
+func (c KeyProviderConfiguration) NewKeyProvider() (KeyProvider, error) {
+	if c.Static == nil {
+		return nil, errNoKeyProviderConfigured
+	}
+	return c.Static.NewKeyProvider()
+}
+
+// NewKeyProvider returns a new static key provider.
+func (c StaticKeyProviderConfiguration) NewKeyProvider() (KeyProvider, error) {
+	keys := make(map[string][]byte, len(c.Keys))
+	for id, encoded := range c.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return NewStaticKeyProvider(keys, c.ActiveKeyID)
+}
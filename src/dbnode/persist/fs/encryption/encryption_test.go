@@ -0,0 +1,291 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testChunkSize = minChunkSize
+
+func newTestKeyProvider(t testing.TB, activeKeyID string) KeyProvider {
+	keyProvider, err := NewStaticKeyProvider(map[string][]byte{
+		"a": bytes.Repeat([]byte{1}, 32),
+		"b": bytes.Repeat([]byte{2}, 16),
+	}, activeKeyID)
+	require.NoError(t, err)
+	return keyProvider
+}
+
+func newTestOptions(t testing.TB) Options {
+	return NewOptions().
+		SetEnabled(true).
+		SetKeyProvider(newTestKeyProvider(t, "a")).
+		SetChunkSize(testChunkSize)
+}
+
+func encrypt(t testing.TB, opts Options, plaintext []byte) []byte {
+	w, err := NewWriter(opts)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Reset(&buf))
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func randomBytes(t testing.TB, n int) []byte {
+	b := make([]byte, n)
+	_, err := io.ReadFull(rand.Reader, b)
+	require.NoError(t, err)
+	return b
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	opts := newTestOptions(t)
+	for _, size := range []int{
+		0, 1, testChunkSize - 1, testChunkSize, testChunkSize + 1,
+		3 * testChunkSize, 3*testChunkSize + 7,
+	} {
+		t.Run(fmt.Sprintf("size=%d", size), func(t *testing.T) {
+			plaintext := randomBytes(t, size)
+			encrypted := encrypt(t, opts, plaintext)
+			require.True(t, IsEncrypted(encrypted))
+
+			decrypted, err := Decrypt(encrypted, opts.KeyProvider())
+			require.NoError(t, err)
+			require.Equal(t, len(plaintext), len(decrypted))
+			require.True(t, bytes.Equal(plaintext, decrypted))
+		})
+	}
+}
+
+func TestWriterSmallWrites(t *testing.T) {
+	opts := newTestOptions(t)
+	plaintext := randomBytes(t, 5*testChunkSize+3)
+
+	w, err := NewWriter(opts)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, w.Reset(&buf))
+	for i := 0; i < len(plaintext); i += 7 {
+		end := i + 7
+		if end > len(plaintext) {
+			end = len(plaintext)
+		}
+		_, err := w.Write(plaintext[i:end])
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	decrypted, err := Decrypt(buf.Bytes(), opts.KeyProvider())
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(plaintext, decrypted))
+}
+
+func TestWriterReset(t *testing.T) {
+	opts := newTestOptions(t)
+	w, err := NewWriter(opts)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte{1})
+	require.Equal(t, errWriterNotReset, err)
+
+	var first, second bytes.Buffer
+	require.NoError(t, w.Reset(&first))
+	_, err = w.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	require.NoError(t, w.Reset(&second))
+	_, err = w.Write([]byte{4, 5})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	decrypted, err := Decrypt(first.Bytes(), opts.KeyProvider())
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, decrypted)
+	decrypted, err = Decrypt(second.Bytes(), opts.KeyProvider())
+	require.NoError(t, err)
+	require.Equal(t, []byte{4, 5}, decrypted)
+}
+
+func TestReaderAtRandomAccess(t *testing.T) {
+	opts := newTestOptions(t)
+	plaintext := randomBytes(t, 4*testChunkSize+100)
+	encrypted := encrypt(t, opts, plaintext)
+
+	r, err := NewReaderAt(bytes.NewReader(encrypted), int64(len(encrypted)), opts.KeyProvider())
+	require.NoError(t, err)
+	require.Equal(t, int64(len(plaintext)), r.Size())
+
+	for _, tc := range []struct {
+		off, n int
+	}{
+		{0, 1},
+		{testChunkSize - 1, 2},
+		{testChunkSize, testChunkSize},
+		{10, 3 * testChunkSize},
+		{len(plaintext) - 5, 5},
+	} {
+		p := make([]byte, tc.n)
+		n, err := r.ReadAt(p, int64(tc.off))
+		require.NoError(t, err)
+		require.Equal(t, tc.n, n)
+		require.Equal(t, plaintext[tc.off:tc.off+tc.n], p)
+	}
+
+	// Reading past the end returns what is available along with io.EOF.
+	p := make([]byte, 10)
+	n, err := r.ReadAt(p, int64(len(plaintext)-5))
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 5, n)
+	require.Equal(t, plaintext[len(plaintext)-5:], p[:n])
+
+	_, err = r.ReadAt(p, int64(len(plaintext)))
+	require.Equal(t, io.EOF, err)
+}
+
+func TestDecryptKeyRotation(t *testing.T) {
+	opts := newTestOptions(t)
+	plaintext := randomBytes(t, 100)
+	encrypted := encrypt(t, opts, plaintext)
+
+	// Files wrapped with a previously active key remain readable.
+	decrypted, err := Decrypt(encrypted, newTestKeyProvider(t, "b"))
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// But not once the key is removed.
+	keyProvider, err := NewStaticKeyProvider(map[string][]byte{
+		"b": bytes.Repeat([]byte{2}, 16),
+	}, "b")
+	require.NoError(t, err)
+	_, err = Decrypt(encrypted, keyProvider)
+	require.Error(t, err)
+}
+
+func TestDecryptTamperedOrTruncated(t *testing.T) {
+	opts := newTestOptions(t)
+	plaintext := randomBytes(t, 3*testChunkSize)
+	encrypted := encrypt(t, opts, plaintext)
+
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, err := Decrypt(tampered, opts.KeyProvider())
+	require.Error(t, err)
+
+	// Dropping the last chunk on a chunk boundary is detected since the new
+	// last chunk was not sealed as the last chunk.
+	truncated := encrypted[:len(encrypted)-(testChunkSize+tagSize)]
+	_, err = Decrypt(truncated, opts.KeyProvider())
+	require.Error(t, err)
+
+	_, err = Decrypt(encrypted[:fixedHeaderSize], opts.KeyProvider())
+	require.Error(t, err)
+
+	unsupported := append([]byte(nil), encrypted...)
+	unsupported[len(magic)] = formatVersion + 1
+	_, err = Decrypt(unsupported, opts.KeyProvider())
+	require.Error(t, err)
+}
+
+func TestOptionsNamespaceEncrypted(t *testing.T) {
+	opts := newTestOptions(t)
+	require.True(t, opts.NamespaceEncrypted(ident.StringID("foo")))
+
+	opts = opts.SetNamespaces([]string{"bar"})
+	require.False(t, opts.NamespaceEncrypted(ident.StringID("foo")))
+	require.True(t, opts.NamespaceEncrypted(ident.StringID("bar")))
+
+	require.False(t, opts.SetEnabled(false).NamespaceEncrypted(ident.StringID("bar")))
+
+	require.Error(t, opts.SetKeyProvider(nil).Validate())
+	require.Error(t, opts.SetChunkSize(1).Validate())
+}
+
+func BenchmarkWrite(b *testing.B) {
+	data := randomBytes(b, 1<<20)
+	w, err := NewWriter(newTestOptions(b).SetChunkSize(defaultChunkSize))
+	require.NoError(b, err)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		require.NoError(b, w.Reset(io.Discard))
+		_, err := w.Write(data)
+		require.NoError(b, err)
+		require.NoError(b, w.Close())
+	}
+}
+
+func BenchmarkReadAt(b *testing.B) {
+	for _, readSize := range []int{64, 4096} {
+		b.Run(fmt.Sprintf("read_size=%d", readSize), func(b *testing.B) {
+			opts := newTestOptions(b).SetChunkSize(defaultChunkSize)
+			data := randomBytes(b, 1<<20)
+			encrypted := encrypt(b, opts, data)
+			r, err := NewReaderAt(bytes.NewReader(encrypted), int64(len(encrypted)), opts.KeyProvider())
+			require.NoError(b, err)
+
+			p := make([]byte, readSize)
+			b.SetBytes(int64(readSize))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Stride across chunks so that every read decrypts a chunk.
+				off := int64(i*defaultChunkSize) % (int64(len(data)) - int64(readSize))
+				if _, err := r.ReadAt(p, off); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadAtSequential(b *testing.B) {
+	opts := newTestOptions(b).SetChunkSize(defaultChunkSize)
+	data := randomBytes(b, 1<<20)
+	encrypted := encrypt(b, opts, data)
+	r, err := NewReaderAt(bytes.NewReader(encrypted), int64(len(encrypted)), opts.KeyProvider())
+	require.NoError(b, err)
+
+	p := make([]byte, 4096)
+	b.SetBytes(int64(len(p)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		off := int64(i*len(p)) % int64(len(data))
+		if _, err := r.ReadAt(p, off); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package encryption
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The encrypted file format is a header followed by a sequence of chunks:
+//
+//	magic (4 bytes) | version (1 byte) | chunk size (4 bytes) |
+//	key ID length (2 bytes) | key ID | wrapped key length (2 bytes) |
+//	wrapped key | nonce prefix (4 bytes)
+//
+// Each chunk holds exactly chunk size bytes of plaintext except for the last
+// chunk which holds between zero and chunk size bytes, it is sealed with
+// AES-GCM using the nonce prefix followed by the chunk index as the nonce and
+// whether it is the last chunk as additional data so that truncating a file
+// on a chunk boundary fails authentication.
+const (
+	magic = "M3FE"
+
+	formatVersion uint8 = 1
+
+	noncePrefixSize = 4
+	nonceSize       = noncePrefixSize + 8
+	tagSize         = 16
+
+	fixedHeaderSize = len(magic) + 1 + 4 + 2 + 2 + noncePrefixSize
+)
+
+var (
+	errInvalidMagic  = errors.New("encrypted file has invalid magic")
+	errFileTruncated = errors.New("encrypted file is truncated")
+
+	lastChunkAdditionalData  = []byte{1}
+	otherChunkAdditionalData = []byte{0}
+)
+
+// IsEncrypted returns whether the contents beginning with the given prefix
+// are encrypted, the prefix must be at least MagicSize bytes long.
+func IsEncrypted(prefix []byte) bool {
+	return len(prefix) >= len(magic) && string(prefix[:len(magic)]) == magic
+}
+
+// MagicSize is the number of bytes required to detect whether a file is encrypted.
+const MagicSize = len(magic)
+
+type header struct {
+	chunkSize   int
+	keyID       string
+	wrappedKey  []byte
+	noncePrefix [noncePrefixSize]byte
+}
+
+func (h header) size() int {
+	return fixedHeaderSize + len(h.keyID) + len(h.wrappedKey)
+}
+
+func (h header) encode() ([]byte, error) {
+	if len(h.keyID) > math.MaxUint16 || len(h.wrappedKey) > math.MaxUint16 {
+		return nil, fmt.Errorf(
+			"encryption key ID or wrapped key too long: key_id=%d, wrapped_key=%d",
+			len(h.keyID), len(h.wrappedKey))
+	}
+	var (
+		b   = make([]byte, h.size())
+		pos = copy(b, magic)
+	)
+	b[pos] = formatVersion
+	pos++
+	binary.BigEndian.PutUint32(b[pos:], uint32(h.chunkSize))
+	pos += 4
+	binary.BigEndian.PutUint16(b[pos:], uint16(len(h.keyID)))
+	pos += 2
+	pos += copy(b[pos:], h.keyID)
+	binary.BigEndian.PutUint16(b[pos:], uint16(len(h.wrappedKey)))
+	pos += 2
+	pos += copy(b[pos:], h.wrappedKey)
+	copy(b[pos:], h.noncePrefix[:])
+	return b, nil
+}
+
+func decodeHeader(r io.Reader) (header, error) {
+	var (
+		h   header
+		buf [len(magic) + 1 + 4]byte
+	)
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return h, headerReadError(err)
+	}
+	if !IsEncrypted(buf[:]) {
+		return h, errInvalidMagic
+	}
+	if v := buf[len(magic)]; v != formatVersion {
+		return h, fmt.Errorf("unsupported encrypted file version: %d", v)
+	}
+	h.chunkSize = int(binary.BigEndian.Uint32(buf[len(magic)+1:]))
+	if h.chunkSize < minChunkSize || h.chunkSize > maxChunkSize {
+		return h, fmt.Errorf("invalid encrypted file chunk size: %d", h.chunkSize)
+	}
+
+	keyID, err := readLengthPrefixed(r)
+	if err != nil {
+		return h, err
+	}
+	h.keyID = string(keyID)
+	if h.wrappedKey, err = readLengthPrefixed(r); err != nil {
+		return h, err
+	}
+	if _, err := io.ReadFull(r, h.noncePrefix[:]); err != nil {
+		return h, headerReadError(err)
+	}
+	return h, nil
+}
+
+func readLengthPrefixed(r io.Reader) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, headerReadError(err)
+	}
+	b := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, headerReadError(err)
+	}
+	return b, nil
+}
+
+func headerReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errFileTruncated
+	}
+	return err
+}
+
+// chunkCipher seals and opens the chunks of a single file, it is safe for
+// concurrent use.
+type chunkCipher struct {
+	aead        cipher.AEAD
+	chunkSize   int
+	noncePrefix [noncePrefixSize]byte
+}
+
+func (c chunkCipher) nonce(idx uint64) [nonceSize]byte {
+	var nonce [nonceSize]byte
+	copy(nonce[:], c.noncePrefix[:])
+	binary.BigEndian.PutUint64(nonce[noncePrefixSize:], idx)
+	return nonce
+}
+
+func (c chunkCipher) seal(dst, plaintext []byte, idx uint64, last bool) []byte {
+	nonce := c.nonce(idx)
+	return c.aead.Seal(dst, nonce[:], plaintext, chunkAdditionalData(last))
+}
+
+func (c chunkCipher) open(dst, ciphertext []byte, idx uint64, last bool) ([]byte, error) {
+	nonce := c.nonce(idx)
+	plaintext, err := c.aead.Open(dst, nonce[:], ciphertext, chunkAdditionalData(last))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt chunk %d: %w", idx, err)
+	}
+	return plaintext, nil
+}
+
+func chunkAdditionalData(last bool) []byte {
+	if last {
+		return lastChunkAdditionalData
+	}
+	return otherChunkAdditionalData
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// dataKeySize is the size of generated data keys, selecting AES-256.
+const dataKeySize = 32
+
+var errWrappedKeyTooShort = errors.New("wrapped data key is too short")
+
+type staticKeyProvider struct {
+	activeKeyID string
+	keys        map[string]cipher.AEAD
+}
+
+// NewStaticKeyProvider returns a key provider that wraps data keys with
+// AES-GCM using a static set of key encryption keys, keyed by key ID.
+// New data keys are wrapped with the active key, while files written with
+// any of the other keys can still be read which allows keys to be rotated.
+func NewStaticKeyProvider(
+	keys map[string][]byte,
+	activeKeyID string,
+) (KeyProvider, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q not found", activeKeyID)
+	}
+	p := &staticKeyProvider{
+		activeKeyID: activeKeyID,
+		keys:        make(map[string]cipher.AEAD, len(keys)),
+	}
+	for id, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		p.keys[id] = aead
+	}
+	return p, nil
+}
+
+func (p *staticKeyProvider) GenerateDataKey() (DataKey, error) {
+	plaintext := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, plaintext); err != nil {
+		return DataKey{}, err
+	}
+
+	aead := p.keys[p.activeKeyID]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+dataKeySize+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return DataKey{}, err
+	}
+
+	// Authenticate the key ID so a wrapped key cannot be swapped between keys.
+	wrapped := aead.Seal(nonce, nonce, plaintext, []byte(p.activeKeyID))
+	return DataKey{
+		KeyID:     p.activeKeyID,
+		Plaintext: plaintext,
+		Wrapped:   wrapped,
+	}, nil
+}
+
+func (p *staticKeyProvider) UnwrapDataKey(keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found", keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errWrappedKeyTooShort
+	}
+	nonce, ciphertext := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to unwrap data key with key %q: %w", keyID, err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package encryption
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m3db/m3/src/x/ident"
+)
+
+const (
+	// defaultChunkSize is the default size of plaintext chunks.
+	defaultChunkSize = 1 << 14
+	// minChunkSize is the minimum size of plaintext chunks, smaller chunks
+	// spend a disproportionate amount of space on authentication tags.
+	minChunkSize = 1 << 10
+	// maxChunkSize is the maximum size of plaintext chunks, larger chunks
+	// make small reads expensive since whole chunks need to be decrypted.
+	maxChunkSize = 1 << 24
+)
+
+var errKeyProviderNotSet = errors.New("encryption key provider is not set")
+
+type options struct {
+	enabled     bool
+	keyProvider KeyProvider
+	namespaces  []string
+	chunkSize   int
+}
+
+// NewOptions returns a new set of encryption options.
+func NewOptions() Options {
+	return &options{
+		chunkSize: defaultChunkSize,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.chunkSize < minChunkSize || o.chunkSize > maxChunkSize {
+		return fmt.Errorf(
+			"invalid encryption chunk size, must be >= %d and <= %d: instead %d",
+			minChunkSize, maxChunkSize, o.chunkSize)
+	}
+	if o.enabled && o.keyProvider == nil {
+		return errKeyProviderNotSet
+	}
+	return nil
+}
+
+func (o *options) SetEnabled(value bool) Options {
+	opts := *o
+	opts.enabled = value
+	return &opts
+}
+
+func (o *options) Enabled() bool {
+	return o.enabled
+}
+
+func (o *options) SetKeyProvider(value KeyProvider) Options {
+	opts := *o
+	opts.keyProvider = value
+	return &opts
+}
+
+func (o *options) KeyProvider() KeyProvider {
+	return o.keyProvider
+}
+
+func (o *options) SetNamespaces(value []string) Options {
+	opts := *o
+	opts.namespaces = value
+	return &opts
+}
+
+func (o *options) Namespaces() []string {
+	return o.namespaces
+}
+
+func (o *options) SetChunkSize(value int) Options {
+	opts := *o
+	opts.chunkSize = value
+	return &opts
+}
+
+func (o *options) ChunkSize() int {
+	return o.chunkSize
+}
+
+func (o *options) NamespaceEncrypted(namespace ident.ID) bool {
+	if !o.enabled || o.keyProvider == nil {
+		return false
+	}
+	if len(o.namespaces) == 0 {
+		return true
+	}
+	for _, ns := range o.namespaces {
+		if namespace.String() == ns {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+)
+
+var (
+	errKeyProviderRequired = errors.New("encrypted file requires an encryption key provider")
+	errNegativeOffset      = errors.New("encrypted file read at negative offset")
+)
+
+// ReaderAt provides random access to the plaintext of an encrypted file, it
+// is safe for concurrent use.
+type ReaderAt interface {
+	io.ReaderAt
+
+	// Size returns the size of the plaintext.
+	Size() int64
+}
+
+type readerAt struct {
+	r          io.ReaderAt
+	cipher     chunkCipher
+	dataOffset int64
+	dataSize   int64
+	numChunks  int64
+	size       int64
+	buffers    sync.Pool
+}
+
+// chunkBuffers are the buffers used to read and decrypt a single chunk, they
+// also retain the last decrypted chunk since sequential reads smaller than
+// the chunk size are common.
+type chunkBuffers struct {
+	sealed []byte
+	plain  []byte
+	idx    int64
+}
+
+// NewReaderAt returns a reader for the plaintext of the encrypted file of
+// the given size read from r.
+func NewReaderAt(r io.ReaderAt, size int64, keys KeyProvider) (ReaderAt, error) {
+	if keys == nil {
+		return nil, errKeyProviderRequired
+	}
+	h, err := decodeHeader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	key, err := keys.UnwrapDataKey(h.keyID, h.wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		dataOffset = int64(h.size())
+		dataSize   = size - dataOffset
+		sealedSize = int64(h.chunkSize + tagSize)
+		numChunks  = (dataSize + sealedSize - 1) / sealedSize
+	)
+	// Every file has at least one chunk, and the last chunk is never empty
+	// since it at least holds its tag.
+	if numChunks == 0 || dataSize-(numChunks-1)*sealedSize < tagSize {
+		return nil, errFileTruncated
+	}
+
+	reader := &readerAt{
+		r: r,
+		cipher: chunkCipher{
+			aead:        aead,
+			chunkSize:   h.chunkSize,
+			noncePrefix: h.noncePrefix,
+		},
+		dataOffset: dataOffset,
+		dataSize:   dataSize,
+		numChunks:  numChunks,
+		size:       dataSize - numChunks*tagSize,
+	}
+	reader.buffers.New = func() interface{} {
+		return &chunkBuffers{
+			sealed: make([]byte, sealedSize),
+			plain:  make([]byte, 0, h.chunkSize),
+			idx:    -1,
+		}
+	}
+	return reader, nil
+}
+
+func (r *readerAt) Size() int64 {
+	return r.size
+}
+
+func (r *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	bufs := r.buffers.Get().(*chunkBuffers)
+	defer r.buffers.Put(bufs)
+
+	n := 0
+	for n < len(p) && off < r.size {
+		idx := off / int64(r.cipher.chunkSize)
+		plain, err := r.readChunk(idx, bufs)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], plain[off-idx*int64(r.cipher.chunkSize):])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *readerAt) readChunk(idx int64, bufs *chunkBuffers) ([]byte, error) {
+	if bufs.idx == idx {
+		return bufs.plain, nil
+	}
+
+	var (
+		sealedSize = int64(len(bufs.sealed))
+		start      = idx * sealedSize
+		end        = start + sealedSize
+	)
+	if end > r.dataSize {
+		end = r.dataSize
+	}
+	sealed := bufs.sealed[:end-start]
+	if n, err := r.r.ReadAt(sealed, r.dataOffset+start); n < len(sealed) {
+		if err == nil || err == io.EOF {
+			err = errFileTruncated
+		}
+		return nil, err
+	}
+
+	// Invalidate the retained chunk before decrypting into its buffer.
+	bufs.idx = -1
+	plain, err := r.cipher.open(bufs.plain[:0], sealed, uint64(idx), idx == r.numChunks-1)
+	if err != nil {
+		return nil, err
+	}
+	bufs.plain = plain
+	bufs.idx = idx
+	return plain, nil
+}
+
+// Decrypt returns the plaintext of the given encrypted file contents.
+func Decrypt(data []byte, keys KeyProvider) ([]byte, error) {
+	r, err := NewReaderAt(bytes.NewReader(data), int64(len(data)), keys)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, r.Size())
+	if _, err := r.ReadAt(plaintext, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+// Package encryption provides envelope encryption of fileset files at rest.
+//
+// Each file is encrypted with its own randomly generated data key which is
+// itself encrypted (wrapped) by a key encryption key held by a KeyProvider,
+// typically backed by a key management service. The wrapped data key is
+// stored in a versioned header at the start of the file, followed by the file
+// contents encrypted with AES-GCM in fixed size chunks so that arbitrary
+// ranges can be read back without decrypting the whole file.
+package encryption
+
+import (
+	"github.com/m3db/m3/src/x/ident"
+)
+
+// DataKey is a data encryption key used to encrypt the contents of a single file.
+type DataKey struct {
+	// KeyID identifies the key encryption key that wrapped the data key.
+	KeyID string
+	// Plaintext is the data key used to encrypt the file contents, it
+	// must never be persisted.
+	Plaintext []byte
+	// Wrapped is the data key encrypted by the key encryption key, this
+	// is stored in the file header.
+	Wrapped []byte
+}
+
+// KeyProvider generates and unwraps data keys, implementations are expected
+// to delegate to a key management service that holds the key encryption keys.
+type KeyProvider interface {
+	// GenerateDataKey returns a new data key wrapped by the active key
+	// encryption key.
+	GenerateDataKey() (DataKey, error)
+
+	// UnwrapDataKey returns the plaintext of a data key wrapped by the
+	// key encryption key with the given ID.
+	UnwrapDataKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Options is a set of encryption options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetEnabled sets whether new fileset files are encrypted, files that were
+	// written encrypted remain readable as long as a key provider is set.
+	SetEnabled(value bool) Options
+
+	// Enabled returns whether new fileset files are encrypted.
+	Enabled() bool
+
+	// SetKeyProvider sets the key provider.
+	SetKeyProvider(value KeyProvider) Options
+
+	// KeyProvider returns the key provider.
+	KeyProvider() KeyProvider
+
+	// SetNamespaces sets the namespaces whose filesets are encrypted, if empty
+	// then all namespaces are encrypted when enabled.
+	SetNamespaces(value []string) Options
+
+	// Namespaces returns the namespaces whose filesets are encrypted.
+	Namespaces() []string
+
+	// SetChunkSize sets the size of the plaintext chunks that are encrypted
+	// and authenticated individually.
+	SetChunkSize(value int) Options
+
+	// ChunkSize returns the size of the plaintext chunks that are encrypted
+	// and authenticated individually.
+	ChunkSize() int
+
+	// NamespaceEncrypted returns whether filesets written for the namespace
+	// should be encrypted.
+	NamespaceEncrypted(namespace ident.ID) bool
+}
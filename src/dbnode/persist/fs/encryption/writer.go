@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package encryption
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+var errWriterNotReset = errors.New("encryption writer has not been reset")
+
+// Writer encrypts everything written to it and writes the resulting
+// ciphertext to an underlying writer.
+type Writer interface {
+	io.Writer
+
+	// Reset generates a new data key and writes the file header to the
+	// underlying writer, subsequent writes are encrypted with the data key.
+	Reset(w io.Writer) error
+
+	// Close writes out the last chunk, it does not close the underlying writer.
+	Close() error
+}
+
+type writer struct {
+	opts   Options
+	w      io.Writer
+	cipher chunkCipher
+	chunk  []byte
+	sealed []byte
+	idx    uint64
+}
+
+// NewWriter returns a new encryption writer, it must be reset before use.
+func NewWriter(opts Options) (Writer, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.KeyProvider() == nil {
+		return nil, errKeyProviderNotSet
+	}
+	return &writer{
+		opts:   opts,
+		chunk:  make([]byte, 0, opts.ChunkSize()),
+		sealed: make([]byte, 0, opts.ChunkSize()+tagSize),
+	}, nil
+}
+
+func (w *writer) Reset(dst io.Writer) error {
+	w.w = nil
+	w.chunk = w.chunk[:0]
+	w.idx = 0
+
+	key, err := w.opts.KeyProvider().GenerateDataKey()
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key.Plaintext)
+	if err != nil {
+		return err
+	}
+
+	h := header{
+		chunkSize:  w.opts.ChunkSize(),
+		keyID:      key.KeyID,
+		wrappedKey: key.Wrapped,
+	}
+	if _, err := io.ReadFull(rand.Reader, h.noncePrefix[:]); err != nil {
+		return err
+	}
+	b, err := h.encode()
+	if err != nil {
+		return err
+	}
+	if _, err := dst.Write(b); err != nil {
+		return err
+	}
+
+	w.w = dst
+	w.cipher = chunkCipher{
+		aead:        aead,
+		chunkSize:   h.chunkSize,
+		noncePrefix: h.noncePrefix,
+	}
+	return nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.w == nil {
+		return 0, errWriterNotReset
+	}
+	written := 0
+	for len(p) > 0 {
+		// Only seal a full chunk once more data arrives so that the last
+		// chunk is never sealed as anything but the last chunk.
+		if len(w.chunk) == cap(w.chunk) {
+			if err := w.writeChunk(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.chunk[len(w.chunk):cap(w.chunk)], p)
+		w.chunk = w.chunk[:len(w.chunk)+n]
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (w *writer) Close() error {
+	if w.w == nil {
+		return errWriterNotReset
+	}
+	err := w.writeChunk(true)
+	w.w = nil
+	return err
+}
+
+func (w *writer) writeChunk(last bool) error {
+	w.sealed = w.cipher.seal(w.sealed[:0], w.chunk, w.idx, last)
+	if _, err := w.w.Write(w.sealed); err != nil {
+		return err
+	}
+	w.chunk = w.chunk[:0]
+	w.idx++
+	return nil
+}
@@ -376,6 +376,18 @@ func DeleteFiles(filePaths []string) error {
 func DeleteDirectories(dirPaths []string) error {
 	multiErr := xerrors.NewMultiError()
 	for _, dir := range dirPaths {
+		// Shard directories placed on a data directory are symlinks to it, the
+		// directory they link to must be removed along with the symlink.
+		if target, err := os.Readlink(dir); err == nil {
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(dir), target)
+			}
+			if err := os.RemoveAll(target); err != nil {
+				detailedErr := fmt.Errorf("failed to remove dir %s: %v", target, err)
+				multiErr = multiErr.Add(detailedErr)
+				continue
+			}
+		}
 		if err := os.RemoveAll(dir); err != nil {
 			detailedErr := fmt.Errorf("failed to remove dir %s: %v", dir, err)
 			multiErr = multiErr.Add(detailedErr)
@@ -1194,6 +1206,60 @@ func DeleteInactiveDirectories(parentDirectoryPath string, activeDirectories []s
 	return DeleteDirectories(toDelete)
 }
 
+// RebalanceShardDirectories moves the data and snapshot shard directories
+// under the file path prefix to the data directory each shard should be placed
+// on, returning the number of shard directories moved. It must be called
+// before the database is opened since shard directories are moved in place.
+func RebalanceShardDirectories(opts Options) (int, error) {
+	var (
+		layout         = opts.DataDirectoryLayout()
+		filePathPrefix = opts.FilePathPrefix()
+		multiErr       = xerrors.NewMultiError()
+		moved          int
+	)
+	if len(layout.Directories()) == 0 {
+		return 0, nil
+	}
+
+	for _, dirPath := range []string{
+		DataDirPath(filePathPrefix),
+		SnapshotsDirPath(filePathPrefix),
+	} {
+		namespaceDirs, err := findSubDirectoriesAndPaths(dirPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+		for namespace, namespaceDirPath := range namespaceDirs {
+			shardDirs, err := findSubDirectoriesAndPaths(namespaceDirPath)
+			if err != nil {
+				multiErr = multiErr.Add(err)
+				continue
+			}
+			nsID := ident.StringID(namespace)
+			for shardName, shardDirPath := range shardDirs {
+				shard, err := strconv.ParseUint(shardName, 10, 32)
+				if err != nil {
+					// Not a shard directory.
+					continue
+				}
+				ok, err := layout.Rebalance(shardDirPath, nsID, uint32(shard))
+				if err != nil {
+					multiErr = multiErr.Add(err)
+					continue
+				}
+				if ok {
+					moved++
+				}
+			}
+		}
+	}
+	return moved, multiErr.FinalError()
+}
+
 // SortedCommitLogFiles returns all the commit log files in the commit logs directory.
 func SortedCommitLogFiles(commitLogsDir string) ([]string, error) {
 	return sortedCommitLogFiles(commitLogsDir, commitLogFilePattern)
@@ -0,0 +1,233 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"io"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs/compression"
+	"github.com/m3db/m3/src/x/checked"
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/uber-go/tally"
+)
+
+type compressionMetrics struct {
+	uncompressedBytes tally.Counter
+	compressedBytes   tally.Counter
+	compressionRatio  tally.Histogram
+	decodeLatency     tally.Timer
+	decodedBytes      tally.Counter
+}
+
+func newCompressionMetrics(iopts instrument.Options) compressionMetrics {
+	scope := iopts.MetricsScope().SubScope("fileset-compression")
+	return compressionMetrics{
+		uncompressedBytes: scope.Counter("uncompressed-bytes"),
+		compressedBytes:   scope.Counter("compressed-bytes"),
+		compressionRatio: scope.Histogram("compression-ratio",
+			tally.MustMakeLinearValueBuckets(0, 0.5, 20)),
+		decodeLatency: scope.Timer("decode-latency"),
+		decodedBytes:  scope.Counter("decoded-bytes"),
+	}
+}
+
+// dataFileCompressor compresses the data of each series written to a data
+// file as its own frame, the data of the first series is buffered until
+// enough of it has been written to train the dictionary of the data file.
+type dataFileCompressor struct {
+	opts    compression.Options
+	metrics compressionMetrics
+	enabled bool
+	encoder compression.Encoder
+
+	// pendingEntries are the positions in the index entries of the series
+	// whose data is buffered in pendingData.
+	pendingEntries []int
+	pendingData    [][]byte
+	pendingSize    int
+
+	buf              []byte
+	frame            []byte
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+func newDataFileCompressor(opts Options) *dataFileCompressor {
+	return &dataFileCompressor{
+		opts:    opts.CompressionOptions(),
+		metrics: newCompressionMetrics(opts.InstrumentOptions()),
+	}
+}
+
+func (c *dataFileCompressor) reset(enabled bool) error {
+	err := c.closeEncoder()
+	c.enabled = enabled
+	c.pendingEntries = c.pendingEntries[:0]
+	for i := range c.pendingData {
+		c.pendingData[i] = nil
+	}
+	c.pendingData = c.pendingData[:0]
+	c.pendingSize = 0
+	c.uncompressedSize = 0
+	c.compressedSize = 0
+	return err
+}
+
+func (c *dataFileCompressor) trained() bool {
+	return c.encoder != nil
+}
+
+// buffer buffers the data of the series at the given position in the index
+// entries and returns whether enough data is buffered to train the dictionary.
+func (c *dataFileCompressor) buffer(entry int, data []checked.Bytes) bool {
+	c.pendingEntries = append(c.pendingEntries, entry)
+	c.pendingData = append(c.pendingData, append([]byte(nil), c.concat(data)...))
+	c.pendingSize += len(c.pendingData[len(c.pendingData)-1])
+	return c.pendingSize >= c.opts.TrainingSize()
+}
+
+// train trains the dictionary from the buffered data and returns the header
+// to write at the start of the data file.
+func (c *dataFileCompressor) train() ([]byte, error) {
+	dict, err := compression.TrainDictionary(c.pendingData, c.opts.DictionarySize())
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := compression.NewEncoder(dict)
+	if err != nil {
+		return nil, err
+	}
+	c.encoder = encoder
+	header := encoder.Header()
+	c.compressedSize += int64(len(header))
+	return header, nil
+}
+
+// encode returns the compressed frame of the data of a series, it is only
+// valid until the next call to encode.
+func (c *dataFileCompressor) encode(data []byte) []byte {
+	c.frame = c.encoder.Encode(c.frame[:0], data)
+	c.uncompressedSize += int64(len(data))
+	c.compressedSize += int64(len(c.frame))
+	return c.frame
+}
+
+func (c *dataFileCompressor) concat(data []checked.Bytes) []byte {
+	c.buf = c.buf[:0]
+	for _, d := range data {
+		if d == nil {
+			continue
+		}
+		c.buf = append(c.buf, d.Bytes()...)
+	}
+	return c.buf
+}
+
+func (c *dataFileCompressor) close() error {
+	if c.trained() {
+		c.metrics.uncompressedBytes.Inc(c.uncompressedSize)
+		c.metrics.compressedBytes.Inc(c.compressedSize)
+		if c.compressedSize > 0 {
+			c.metrics.compressionRatio.RecordValue(
+				float64(c.uncompressedSize) / float64(c.compressedSize))
+		}
+	}
+	return c.reset(false)
+}
+
+func (c *dataFileCompressor) closeEncoder() error {
+	if c.encoder == nil {
+		return nil
+	}
+	err := c.encoder.Close()
+	c.encoder = nil
+	return err
+}
+
+// dataFileDecompressor decompresses the data of the series of a compressed
+// data file, it is safe for concurrent use.
+type dataFileDecompressor struct {
+	decoder compression.Decoder
+	metrics compressionMetrics
+	nowFn   clock.NowFn
+}
+
+// newDataFileDecompressorAt returns a decompressor for a data file read via
+// a reader of its plaintext along with the size of its compression header,
+// or nil if the data file was not written compressed.
+func newDataFileDecompressorAt(
+	r io.ReaderAt,
+	size int64,
+	opts Options,
+) (*dataFileDecompressor, int, error) {
+	if size < int64(compression.MagicSize) {
+		return nil, 0, nil
+	}
+	var magic [compression.MagicSize]byte
+	if n, err := r.ReadAt(magic[:], 0); n < len(magic) {
+		return nil, 0, err
+	}
+	if !compression.IsCompressed(magic[:]) {
+		return nil, 0, nil
+	}
+	dict, headerSize, err := compression.ReadHeader(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	d, err := newDataFileDecompressorWithDictionary(dict, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return d, headerSize, nil
+}
+
+func newDataFileDecompressorWithDictionary(
+	dict []byte,
+	opts Options,
+) (*dataFileDecompressor, error) {
+	decoder, err := compression.NewDecoder(dict)
+	if err != nil {
+		return nil, err
+	}
+	return &dataFileDecompressor{
+		decoder: decoder,
+		metrics: newCompressionMetrics(opts.InstrumentOptions()),
+		nowFn:   opts.ClockOptions().NowFn(),
+	}, nil
+}
+
+// decode appends the decompressed data of a series to dst.
+func (d *dataFileDecompressor) decode(dst, src []byte) ([]byte, error) {
+	start := d.nowFn()
+	result, err := d.decoder.Decode(dst, src)
+	if err != nil {
+		return nil, err
+	}
+	d.metrics.decodeLatency.Record(d.nowFn().Sub(start))
+	d.metrics.decodedBytes.Inc(int64(len(result) - len(dst)))
+	return result, nil
+}
+
+func (d *dataFileDecompressor) close() error {
+	return d.decoder.Close()
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs/compression"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCompressionOptions() compression.Options {
+	return compression.NewOptions().
+		SetEnabled(true).
+		SetDictionarySize(1024).
+		SetTrainingSize(4096)
+}
+
+// newTestCompressionEntries returns entries whose data shares some of its
+// contents like the data of series with similar values does.
+func newTestCompressionEntries(n int) []testEntry {
+	rng := rand.New(rand.NewSource(1))
+	shared := make([]byte, 128)
+	rng.Read(shared)
+
+	entries := make([]testEntry, 0, n)
+	for i := 0; i < n; i++ {
+		data := append([]byte(nil), shared[:rng.Intn(len(shared))+1]...)
+		unique := make([]byte, 16)
+		rng.Read(unique)
+		entries = append(entries, testEntry{
+			id:   fmt.Sprintf("foo-%d", i),
+			data: append(data, unique...),
+		})
+	}
+	return entries
+}
+
+func readDataFile(t *testing.T, filePathPrefix string) []byte {
+	shardDir := ShardDataDirPath(filePathPrefix, testNs1ID, 0)
+	contents, err := ioutil.ReadFile(dataFilesetPathFromTimeAndIndex(
+		shardDir, testWriterStart, 0, dataFileSuffix, false))
+	require.NoError(t, err)
+	return contents
+}
+
+func TestCompressedReadWriteSeek(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		entries []testEntry
+	}{
+		{
+			// Enough data to train a dictionary before the end of the data file.
+			name:    "trained while writing",
+			entries: newTestCompressionEntries(500),
+		},
+		{
+			name:    "trained on close",
+			entries: newTestCompressionEntries(10),
+		},
+		{
+			name: "too little data to train",
+			entries: []testEntry{
+				{"foo", nil, []byte{1, 2, 3}},
+				{"bar", map[string]string{"qux": "qaz"}, []byte{4, 5, 6}},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := createTempDir(t)
+			filePathPrefix := filepath.Join(dir, "")
+			defer os.RemoveAll(dir)
+
+			opts := testDefaultOpts.
+				SetFilePathPrefix(filePathPrefix).
+				SetWriterBufferSize(testWriterBufferSize).
+				SetInfoReaderBufferSize(testReaderBufferSize).
+				SetDataReaderBufferSize(testReaderBufferSize).
+				SetCompressionOptions(newTestCompressionOptions())
+
+			w, err := NewWriter(opts)
+			require.NoError(t, err)
+			writeTestData(t, w, 0, testWriterStart, test.entries, persist.FileSetFlushType)
+			require.True(t, compression.IsCompressed(readDataFile(t, filePathPrefix)))
+
+			// Compressed data files are readable regardless of whether
+			// compression is enabled.
+			readOpts := opts.SetCompressionOptions(compression.NewOptions())
+			r, err := NewReader(testBytesPool, readOpts)
+			require.NoError(t, err)
+			readTestData(t, r, 0, testWriterStart, test.entries)
+
+			// The digest of the data file includes the compression header.
+			require.NoError(t, r.Open(DataReaderOpenOptions{
+				Identifier: FileSetFileIdentifier{
+					Namespace:  testNs1ID,
+					Shard:      0,
+					BlockStart: testWriterStart,
+				},
+			}))
+			for {
+				_, _, _, _, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+			}
+			require.NoError(t, r.Validate())
+			require.NoError(t, r.Close())
+
+			resources := newTestReusableSeekerResources()
+			s := NewSeeker(filePathPrefix, testReaderBufferSize, testReaderBufferSize,
+				testBytesPool, false, readOpts)
+			require.NoError(t, s.Open(testNs1ID, 0, testWriterStart, 0, resources))
+			defer s.Close()
+
+			clone, err := s.ConcurrentClone()
+			require.NoError(t, err)
+			defer clone.Close()
+
+			for _, seeker := range []ConcurrentDataFileSetSeeker{s, clone} {
+				for _, entry := range test.entries {
+					data, err := seeker.SeekByID(ident.StringID(entry.id), resources)
+					require.NoError(t, err)
+					data.IncRef()
+					require.Equal(t, entry.data, data.Bytes())
+					data.DecRef()
+				}
+			}
+		})
+	}
+}
+
+func TestCompressionReducesDataFileSize(t *testing.T) {
+	entries := newTestCompressionEntries(500)
+	dataFileSize := func(compressionOpts compression.Options) int {
+		dir := createTempDir(t)
+		filePathPrefix := filepath.Join(dir, "")
+		defer os.RemoveAll(dir)
+
+		w, err := NewWriter(testDefaultOpts.
+			SetFilePathPrefix(filePathPrefix).
+			SetWriterBufferSize(testWriterBufferSize).
+			SetCompressionOptions(compressionOpts))
+		require.NoError(t, err)
+		writeTestData(t, w, 0, testWriterStart, entries, persist.FileSetFlushType)
+		return len(readDataFile(t, filePathPrefix))
+	}
+
+	compressed := dataFileSize(newTestCompressionOptions())
+	uncompressed := dataFileSize(compression.NewOptions())
+	require.True(t, compressed < uncompressed,
+		"expected %d to be less than %d", compressed, uncompressed)
+}
+
+func TestCompressedEncryptedReadWriteSeek(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := newTestCompressionEntries(100)
+	opts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetWriterBufferSize(testWriterBufferSize).
+		SetCompressionOptions(newTestCompressionOptions()).
+		SetEncryptionOptions(newTestEncryptionOptions(t))
+
+	w, err := NewWriter(opts)
+	require.NoError(t, err)
+	writeTestData(t, w, 0, testWriterStart, entries, persist.FileSetFlushType)
+
+	r, err := NewReader(testBytesPool, opts)
+	require.NoError(t, err)
+	readTestData(t, r, 0, testWriterStart, entries)
+
+	resources := newTestReusableSeekerResources()
+	s := NewSeeker(filePathPrefix, testReaderBufferSize, testReaderBufferSize,
+		testBytesPool, false, opts)
+	require.NoError(t, s.Open(testNs1ID, 0, testWriterStart, 0, resources))
+	defer s.Close()
+	for _, entry := range entries {
+		data, err := s.SeekByID(ident.StringID(entry.id), resources)
+		require.NoError(t, err)
+		data.IncRef()
+		require.Equal(t, entry.data, data.Bytes())
+		data.DecRef()
+	}
+}
+
+func TestCompressionOnlyAppliesToEnabledNamespacesAndFlushes(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := newTestCompressionEntries(100)
+	opts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetWriterBufferSize(testWriterBufferSize).
+		SetCompressionOptions(newTestCompressionOptions())
+
+	w, err := NewWriter(opts)
+	require.NoError(t, err)
+	writeTestData(t, w, 0, testWriterStart, entries, persist.FileSetSnapshotType)
+	shardDir := ShardSnapshotsDirPath(filePathPrefix, testNs1ID, 0)
+	contents, err := ioutil.ReadFile(FilesetPathFromTimeAndIndex(
+		shardDir, testWriterStart, 0, dataFileSuffix))
+	require.NoError(t, err)
+	require.False(t, compression.IsCompressed(contents))
+
+	w, err = NewWriter(opts.SetCompressionOptions(newTestCompressionOptions().
+		SetNamespaces([]string{"other"})))
+	require.NoError(t, err)
+	writeTestData(t, w, 0, testWriterStart, entries, persist.FileSetFlushType)
+	require.False(t, compression.IsCompressed(readDataFile(t, filePathPrefix)))
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package fs
+
+import (
+	"io"
+	"os"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs/encryption"
+)
+
+// newFileSetFileReaderAt returns a reader for the plaintext of a fileset file
+// along with the plaintext size, decrypting on read if the file was written
+// encrypted.
+func newFileSetFileReaderAt(fd *os.File, opts Options) (io.ReaderAt, int64, error) {
+	stat, err := fd.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := stat.Size()
+
+	var magic [encryption.MagicSize]byte
+	if n, err := fd.ReadAt(magic[:], 0); n < len(magic) {
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		return fd, size, nil
+	}
+	if !encryption.IsEncrypted(magic[:]) {
+		return fd, size, nil
+	}
+
+	r, err := encryption.NewReaderAt(fd, size, opts.EncryptionOptions().KeyProvider())
+	if err != nil {
+		return nil, 0, err
+	}
+	return r, r.Size(), nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs/encryption"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEncryptionOptions(t *testing.T) encryption.Options {
+	keyProvider, err := encryption.NewStaticKeyProvider(map[string][]byte{
+		"test": make([]byte, 32),
+	}, "test")
+	require.NoError(t, err)
+	return encryption.NewOptions().
+		SetEnabled(true).
+		SetKeyProvider(keyProvider).
+		SetChunkSize(1024)
+}
+
+func TestEncryptedReadWriteSeek(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", nil, []byte{1, 2, 3}},
+		{"bar", nil, []byte{4, 5, 6}},
+		{"baz", nil, make([]byte, 65536)},
+		{"cat", nil, make([]byte, 100000)},
+		{"foo+bar=baz,qux=qaz", map[string]string{
+			"bar": "baz",
+			"qux": "qaz",
+		}, []byte{7, 8, 9}},
+	}
+
+	opts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetWriterBufferSize(testWriterBufferSize).
+		SetInfoReaderBufferSize(testReaderBufferSize).
+		SetDataReaderBufferSize(testReaderBufferSize).
+		SetEncryptionOptions(newTestEncryptionOptions(t))
+
+	w, err := NewWriter(opts)
+	require.NoError(t, err)
+	writeTestData(t, w, 0, testWriterStart, entries, persist.FileSetFlushType)
+
+	shardDir := ShardDataDirPath(filePathPrefix, testNs1ID, 0)
+	for _, suffix := range []string{indexFileSuffix, dataFileSuffix} {
+		contents, err := ioutil.ReadFile(dataFilesetPathFromTimeAndIndex(
+			shardDir, testWriterStart, 0, suffix, false))
+		require.NoError(t, err)
+		require.True(t, encryption.IsEncrypted(contents), suffix)
+	}
+
+	r, err := NewReader(testBytesPool, opts)
+	require.NoError(t, err)
+	readTestData(t, r, 0, testWriterStart, entries)
+
+	resources := newTestReusableSeekerResources()
+	s := NewSeeker(filePathPrefix, testReaderBufferSize, testReaderBufferSize,
+		testBytesPool, false, opts)
+	require.NoError(t, s.Open(testNs1ID, 0, testWriterStart, 0, resources))
+	defer s.Close()
+
+	clone, err := s.ConcurrentClone()
+	require.NoError(t, err)
+	defer clone.Close()
+
+	for _, seeker := range []ConcurrentDataFileSetSeeker{s, clone} {
+		for _, entry := range entries {
+			data, err := seeker.SeekByID(ident.StringID(entry.id), resources)
+			require.NoError(t, err)
+			data.IncRef()
+			require.Equal(t, entry.data, data.Bytes())
+			data.DecRef()
+		}
+	}
+}
+
+func TestEncryptedReadRequiresKeyProvider(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", nil, []byte{1, 2, 3}},
+	}
+
+	opts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetWriterBufferSize(testWriterBufferSize).
+		SetEncryptionOptions(newTestEncryptionOptions(t))
+	w, err := NewWriter(opts)
+	require.NoError(t, err)
+	writeTestData(t, w, 0, testWriterStart, entries, persist.FileSetFlushType)
+
+	r := newTestReader(t, filePathPrefix)
+	err = r.Open(DataReaderOpenOptions{
+		Identifier: FileSetFileIdentifier{
+			Namespace:  testNs1ID,
+			Shard:      0,
+			BlockStart: testWriterStart,
+		},
+	})
+	require.Error(t, err)
+
+	s := newTestSeeker(filePathPrefix)
+	err = s.Open(testNs1ID, 0, testWriterStart, 0, newTestReusableSeekerResources())
+	require.Error(t, err)
+}
+
+func TestEncryptionDisabledNamespaceWritesPlaintext(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", nil, []byte{1, 2, 3}},
+	}
+
+	opts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetWriterBufferSize(testWriterBufferSize).
+		SetEncryptionOptions(newTestEncryptionOptions(t).
+			SetNamespaces([]string{"other"}))
+	w, err := NewWriter(opts)
+	require.NoError(t, err)
+	writeTestData(t, w, 0, testWriterStart, entries, persist.FileSetFlushType)
+
+	// Files written in plaintext remain readable without a key provider.
+	r := newTestReader(t, filePathPrefix)
+	readTestData(t, r, 0, testWriterStart, entries)
+}
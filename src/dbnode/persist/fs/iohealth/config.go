@@ -0,0 +1,78 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package iohealth
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Configuration is the configuration for tracking volume IO health.
+type Configuration struct {
+	// ReadSlowThreshold is the latency above which a read is slow.
+	ReadSlowThreshold *time.Duration `yaml:"readSlowThreshold"`
+
+	// SeekSlowThreshold is the latency above which an index lookup is slow.
+	SeekSlowThreshold *time.Duration `yaml:"seekSlowThreshold"`
+
+	// FsyncSlowThreshold is the latency above which an fsync is slow.
+	FsyncSlowThreshold *time.Duration `yaml:"fsyncSlowThreshold"`
+
+	// HalfLife is the half life of recorded operations when scoring health.
+	HalfLife *time.Duration `yaml:"halfLife"`
+
+	// MinOperations is the minimum number of recent operations required
+	// before a volume can be scored unhealthy.
+	MinOperations *float64 `yaml:"minOperations"`
+
+	// UnhealthyScore is the score below which a volume is unhealthy.
+	UnhealthyScore *float64 `yaml:"unhealthyScore"`
+
+	// ExcludeUnhealthy excludes unhealthy volumes from new flushes when
+	// there are other data directories to flush to.
+	ExcludeUnhealthy bool `yaml:"excludeUnhealthy"`
+}
+
+// NewTracker returns a new IO health tracker for the configuration.
+func (c Configuration) NewTracker(iOpts instrument.Options) (Tracker, error) {
+	opts := NewOptions().
+		SetInstrumentOptions(iOpts).
+		SetExcludeUnhealthy(c.ExcludeUnhealthy)
+	if c.ReadSlowThreshold != nil {
+		opts = opts.SetSlowThreshold(OpRead, *c.ReadSlowThreshold)
+	}
+	if c.SeekSlowThreshold != nil {
+		opts = opts.SetSlowThreshold(OpSeek, *c.SeekSlowThreshold)
+	}
+	if c.FsyncSlowThreshold != nil {
+		opts = opts.SetSlowThreshold(OpFsync, *c.FsyncSlowThreshold)
+	}
+	if c.HalfLife != nil {
+		opts = opts.SetHalfLife(*c.HalfLife)
+	}
+	if c.MinOperations != nil {
+		opts = opts.SetMinOperations(*c.MinOperations)
+	}
+	if c.UnhealthyScore != nil {
+		opts = opts.SetUnhealthyScore(*c.UnhealthyScore)
+	}
+	return NewTracker(opts)
+}
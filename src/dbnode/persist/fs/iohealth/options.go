@@ -0,0 +1,171 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package iohealth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultReadSlowThreshold  = 100 * time.Millisecond
+	defaultSeekSlowThreshold  = 100 * time.Millisecond
+	defaultFsyncSlowThreshold = time.Second
+	defaultHalfLife           = time.Minute
+	defaultMinOperations      = 100
+	defaultUnhealthyScore     = 0.5
+)
+
+var (
+	errClockOptionsNotSet      = errors.New("clock options not set")
+	errInstrumentOptionsNotSet = errors.New("instrument options not set")
+	errInvalidHalfLife         = errors.New("half life must be positive")
+)
+
+type options struct {
+	clockOpts        clock.Options
+	instrumentOpts   instrument.Options
+	slowThresholds   [numOps]time.Duration
+	halfLife         time.Duration
+	minOperations    float64
+	unhealthyScore   float64
+	excludeUnhealthy bool
+}
+
+// NewOptions returns a new set of IO health options.
+func NewOptions() Options {
+	return &options{
+		clockOpts:      clock.NewOptions(),
+		instrumentOpts: instrument.NewOptions(),
+		slowThresholds: [numOps]time.Duration{
+			OpRead:  defaultReadSlowThreshold,
+			OpSeek:  defaultSeekSlowThreshold,
+			OpFsync: defaultFsyncSlowThreshold,
+		},
+		halfLife:       defaultHalfLife,
+		minOperations:  defaultMinOperations,
+		unhealthyScore: defaultUnhealthyScore,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.clockOpts == nil {
+		return errClockOptionsNotSet
+	}
+	if o.instrumentOpts == nil {
+		return errInstrumentOptionsNotSet
+	}
+	for op, threshold := range o.slowThresholds {
+		if threshold <= 0 {
+			return fmt.Errorf("slow threshold for %s must be positive: instead %v",
+				Op(op), threshold)
+		}
+	}
+	if o.halfLife <= 0 {
+		return errInvalidHalfLife
+	}
+	if o.minOperations < 0 {
+		return fmt.Errorf("min operations must be non-negative: instead %f", o.minOperations)
+	}
+	if o.unhealthyScore < 0 || o.unhealthyScore > 1 {
+		return fmt.Errorf(
+			"unhealthy score must be >= 0 and <= 1: instead %f", o.unhealthyScore)
+	}
+	return nil
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetSlowThreshold(op Op, value time.Duration) Options {
+	opts := *o
+	if op >= 0 && int(op) < numOps {
+		opts.slowThresholds[op] = value
+	}
+	return &opts
+}
+
+func (o *options) SlowThreshold(op Op) time.Duration {
+	if op < 0 || int(op) >= numOps {
+		return 0
+	}
+	return o.slowThresholds[op]
+}
+
+func (o *options) SetHalfLife(value time.Duration) Options {
+	opts := *o
+	opts.halfLife = value
+	return &opts
+}
+
+func (o *options) HalfLife() time.Duration {
+	return o.halfLife
+}
+
+func (o *options) SetMinOperations(value float64) Options {
+	opts := *o
+	opts.minOperations = value
+	return &opts
+}
+
+func (o *options) MinOperations() float64 {
+	return o.minOperations
+}
+
+func (o *options) SetUnhealthyScore(value float64) Options {
+	opts := *o
+	opts.unhealthyScore = value
+	return &opts
+}
+
+func (o *options) UnhealthyScore() float64 {
+	return o.unhealthyScore
+}
+
+func (o *options) SetExcludeUnhealthy(value bool) Options {
+	opts := *o
+	opts.excludeUnhealthy = value
+	return &opts
+}
+
+func (o *options) ExcludeUnhealthy() bool {
+	return o.excludeUnhealthy
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package iohealth
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/uber-go/tally"
+)
+
+var latencyBuckets = tally.MustMakeExponentialDurationBuckets(50*time.Microsecond, 2, 18)
+
+type tracker struct {
+	sync.RWMutex
+
+	opts    Options
+	nowFn   clock.NowFn
+	scope   tally.Scope
+	volumes map[string]*volume
+}
+
+// NewTracker returns a new IO health tracker.
+func NewTracker(opts Options) (Tracker, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &tracker{
+		opts:    opts,
+		nowFn:   opts.ClockOptions().NowFn(),
+		scope:   opts.InstrumentOptions().MetricsScope().SubScope("io-health"),
+		volumes: make(map[string]*volume),
+	}, nil
+}
+
+func (t *tracker) Record(path string, op Op, latency time.Duration, err error) {
+	if op < 0 || int(op) >= numOps {
+		return
+	}
+	t.volume(path).record(t.nowFn(), op, latency, err)
+}
+
+func (t *tracker) Health(path string) VolumeHealth {
+	t.RLock()
+	v, ok := t.volumes[path]
+	t.RUnlock()
+	if !ok {
+		return VolumeHealth{Path: path, Score: 1, Healthy: true}
+	}
+	return v.health(t.nowFn())
+}
+
+func (t *tracker) Volumes() []VolumeHealth {
+	now := t.nowFn()
+	t.RLock()
+	result := make([]VolumeHealth, 0, len(t.volumes))
+	for _, v := range t.volumes {
+		result = append(result, v.health(now))
+	}
+	t.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+func (t *tracker) Excluded(path string) bool {
+	return t.Health(path).Excluded
+}
+
+func (t *tracker) volume(path string) *volume {
+	t.RLock()
+	v, ok := t.volumes[path]
+	t.RUnlock()
+	if ok {
+		return v
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	if v, ok := t.volumes[path]; ok {
+		return v
+	}
+	v = newVolume(path, t.opts, t.scope.Tagged(map[string]string{"volume": path}))
+	t.volumes[path] = v
+	return v
+}
+
+type volumeMetrics struct {
+	latency [numOps]tally.Histogram
+	slow    [numOps]tally.Counter
+	errors  [numOps]tally.Counter
+	score   tally.Gauge
+	healthy tally.Gauge
+}
+
+func newVolumeMetrics(scope tally.Scope) volumeMetrics {
+	var m volumeMetrics
+	for op := 0; op < numOps; op++ {
+		opScope := scope.Tagged(map[string]string{"op": Op(op).String()})
+		m.latency[op] = opScope.Histogram("latency", latencyBuckets)
+		m.slow[op] = opScope.Counter("slow")
+		m.errors[op] = opScope.Counter("errors")
+	}
+	m.score = scope.Gauge("score")
+	m.healthy = scope.Gauge("healthy")
+	return m
+}
+
+// volume tracks the recent operations against a volume as exponentially
+// decaying counts, so that a volume that is no longer used (for instance
+// since it was excluded from flushes) recovers once it has been quiet.
+type volume struct {
+	sync.Mutex
+
+	path    string
+	opts    Options
+	metrics volumeMetrics
+
+	lastDecay time.Time
+	total     float64
+	bad       float64
+}
+
+func newVolume(path string, opts Options, scope tally.Scope) *volume {
+	return &volume{
+		path:    path,
+		opts:    opts,
+		metrics: newVolumeMetrics(scope),
+	}
+}
+
+func (v *volume) record(now time.Time, op Op, latency time.Duration, err error) {
+	v.metrics.latency[op].RecordDuration(latency)
+
+	bad := false
+	if err != nil {
+		v.metrics.errors[op].Inc(1)
+		bad = true
+	} else if latency > v.opts.SlowThreshold(op) {
+		v.metrics.slow[op].Inc(1)
+		bad = true
+	}
+
+	v.Lock()
+	v.decayWithLock(now)
+	v.total++
+	if bad {
+		v.bad++
+	}
+	health := v.healthWithLock()
+	v.Unlock()
+
+	v.metrics.score.Update(health.Score)
+	v.metrics.healthy.Update(boolToFloat(health.Healthy))
+}
+
+func (v *volume) health(now time.Time) VolumeHealth {
+	v.Lock()
+	v.decayWithLock(now)
+	health := v.healthWithLock()
+	v.Unlock()
+	return health
+}
+
+func (v *volume) decayWithLock(now time.Time) {
+	if v.lastDecay.IsZero() {
+		v.lastDecay = now
+		return
+	}
+	elapsed := now.Sub(v.lastDecay)
+	if elapsed <= 0 {
+		return
+	}
+	factor := math.Exp2(-float64(elapsed) / float64(v.opts.HalfLife()))
+	v.total *= factor
+	v.bad *= factor
+	v.lastDecay = now
+}
+
+func (v *volume) healthWithLock() VolumeHealth {
+	score := 1.0
+	if v.total > 0 {
+		score = 1 - v.bad/v.total
+	}
+	healthy := v.total < v.opts.MinOperations() || score >= v.opts.UnhealthyScore()
+	return VolumeHealth{
+		Path:     v.path,
+		Score:    score,
+		Healthy:  healthy,
+		Excluded: !healthy && v.opts.ExcludeUnhealthy(),
+	}
+}
+
+func boolToFloat(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+type noopTracker struct{}
+
+// NewNoopTracker returns a tracker that records nothing and reports every
+// volume as healthy.
+func NewNoopTracker() Tracker {
+	return noopTracker{}
+}
+
+func (noopTracker) Record(string, Op, time.Duration, error) {}
+
+func (noopTracker) Health(path string) VolumeHealth {
+	return VolumeHealth{Path: path, Score: 1, Healthy: true}
+}
+
+func (noopTracker) Volumes() []VolumeHealth {
+	return nil
+}
+
+func (noopTracker) Excluded(string) bool {
+	return false
+}
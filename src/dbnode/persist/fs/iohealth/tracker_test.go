@@ -0,0 +1,148 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package iohealth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) nowFn() time.Time {
+	return c.now
+}
+
+func newTestTracker(t *testing.T, opts Options) (Tracker, *testClock, tally.TestScope) {
+	clk := &testClock{now: time.Unix(1000, 0)}
+	scope := tally.NewTestScope("", nil)
+	tracker, err := NewTracker(opts.
+		SetClockOptions(clock.NewOptions().SetNowFn(clk.nowFn)).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope)))
+	require.NoError(t, err)
+	return tracker, clk, scope
+}
+
+func TestTrackerHealthyVolume(t *testing.T) {
+	tracker, _, _ := newTestTracker(t, NewOptions().SetMinOperations(10))
+
+	require.Equal(t, VolumeHealth{Path: "/a", Score: 1, Healthy: true}, tracker.Health("/a"))
+	require.Empty(t, tracker.Volumes())
+
+	for i := 0; i < 100; i++ {
+		tracker.Record("/a", OpRead, time.Millisecond, nil)
+	}
+	require.Equal(t, []VolumeHealth{{Path: "/a", Score: 1, Healthy: true}}, tracker.Volumes())
+}
+
+func TestTrackerSlowAndFailingVolume(t *testing.T) {
+	tracker, _, scope := newTestTracker(t, NewOptions().
+		SetMinOperations(10).
+		SetExcludeUnhealthy(true))
+
+	for i := 0; i < 10; i++ {
+		tracker.Record("/a", OpRead, time.Millisecond, nil)
+		tracker.Record("/b", OpRead, time.Millisecond, nil)
+		tracker.Record("/b", OpSeek, time.Second, nil)
+		tracker.Record("/b", OpFsync, time.Millisecond, errors.New("eio"))
+	}
+
+	volumes := tracker.Volumes()
+	require.Len(t, volumes, 2)
+	require.Equal(t, VolumeHealth{Path: "/a", Score: 1, Healthy: true}, volumes[0])
+	require.Equal(t, "/b", volumes[1].Path)
+	require.True(t, volumes[1].Score > 0.33 && volumes[1].Score < 0.34)
+	require.False(t, volumes[1].Healthy)
+	require.True(t, volumes[1].Excluded)
+	require.False(t, tracker.Excluded("/a"))
+	require.True(t, tracker.Excluded("/b"))
+
+	snapshot := scope.Snapshot()
+	counters := snapshot.Counters()
+	require.Equal(t, int64(10),
+		counters["io-health.slow+op=seek,volume=/b"].Value())
+	require.Equal(t, int64(10),
+		counters["io-health.errors+op=fsync,volume=/b"].Value())
+	gauges := snapshot.Gauges()
+	require.Equal(t, float64(0), gauges["io-health.healthy+volume=/b"].Value())
+	require.Equal(t, float64(1), gauges["io-health.healthy+volume=/a"].Value())
+}
+
+func TestTrackerRequiresMinOperations(t *testing.T) {
+	tracker, _, _ := newTestTracker(t, NewOptions().SetMinOperations(10))
+
+	for i := 0; i < 5; i++ {
+		tracker.Record("/a", OpFsync, time.Minute, nil)
+	}
+	health := tracker.Health("/a")
+	require.Equal(t, float64(0), health.Score)
+	require.True(t, health.Healthy)
+}
+
+func TestTrackerRecoversWhenQuiet(t *testing.T) {
+	tracker, clk, _ := newTestTracker(t, NewOptions().
+		SetMinOperations(10).
+		SetHalfLife(time.Minute).
+		SetExcludeUnhealthy(true))
+
+	for i := 0; i < 20; i++ {
+		tracker.Record("/a", OpFsync, time.Minute, nil)
+	}
+	require.True(t, tracker.Excluded("/a"))
+
+	// Once excluded the volume receives no flushes, so it must recover as the
+	// recorded operations decay rather than wait for new fast operations.
+	clk.now = clk.now.Add(30 * time.Second)
+	require.True(t, tracker.Excluded("/a"))
+
+	clk.now = clk.now.Add(time.Minute)
+	require.False(t, tracker.Excluded("/a"))
+
+	// Fast operations restore the score.
+	for i := 0; i < 100; i++ {
+		tracker.Record("/a", OpFsync, time.Millisecond, nil)
+	}
+	require.True(t, tracker.Health("/a").Score > 0.9)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	require.NoError(t, NewOptions().Validate())
+	require.Error(t, NewOptions().SetSlowThreshold(OpSeek, 0).Validate())
+	require.Error(t, NewOptions().SetHalfLife(0).Validate())
+	require.Error(t, NewOptions().SetUnhealthyScore(2).Validate())
+	require.Error(t, NewOptions().SetMinOperations(-1).Validate())
+}
+
+func TestNoopTracker(t *testing.T) {
+	tracker := NewNoopTracker()
+	tracker.Record("/a", OpFsync, time.Minute, errors.New("eio"))
+	require.True(t, tracker.Health("/a").Healthy)
+	require.False(t, tracker.Excluded("/a"))
+	require.Empty(t, tracker.Volumes())
+}
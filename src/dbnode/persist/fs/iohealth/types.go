@@ -0,0 +1,147 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+// Package iohealth tracks the IO latency of the volumes that fileset files are
+// stored on and scores their health so that a degraded disk can be attributed
+// and, when there are alternatives, avoided.
+package iohealth
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// Op is an IO operation against a volume.
+type Op int
+
+const (
+	// OpRead is a read of data from a fileset file.
+	OpRead Op = iota
+	// OpSeek is a lookup of an entry in a fileset index file.
+	OpSeek
+	// OpFsync is an fsync of a file.
+	OpFsync
+
+	numOps = int(OpFsync) + 1
+)
+
+// String returns the name of the operation.
+func (o Op) String() string {
+	switch o {
+	case OpRead:
+		return "read"
+	case OpSeek:
+		return "seek"
+	case OpFsync:
+		return "fsync"
+	default:
+		return "unknown"
+	}
+}
+
+// VolumeHealth is the health of a single volume.
+type VolumeHealth struct {
+	// Path is the path of the volume.
+	Path string `json:"path"`
+	// Score is the health score of the volume, between zero and one, being
+	// the recent fraction of operations that were neither slow nor failed.
+	Score float64 `json:"score"`
+	// Healthy is whether the score is at or above the unhealthy threshold.
+	Healthy bool `json:"healthy"`
+	// Excluded is whether the volume should be excluded from new flushes.
+	Excluded bool `json:"excluded"`
+}
+
+// Tracker tracks the latency of IO operations against volumes and derives a
+// health score per volume, it is safe for concurrent use.
+type Tracker interface {
+	// Record records the latency and result of an operation against the
+	// volume at the given path.
+	Record(volume string, op Op, latency time.Duration, err error)
+
+	// Health returns the health of the volume at the given path.
+	Health(volume string) VolumeHealth
+
+	// Volumes returns the health of all volumes with recorded operations,
+	// sorted by path.
+	Volumes() []VolumeHealth
+
+	// Excluded returns whether the volume at the given path should be
+	// excluded from new flushes, callers with a single volume to choose from
+	// should use it regardless.
+	Excluded(volume string) bool
+}
+
+// Options is a set of IO health options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetClockOptions sets the clock options.
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+
+	// SetSlowThreshold sets the latency above which an operation is
+	// considered slow.
+	SetSlowThreshold(op Op, value time.Duration) Options
+
+	// SlowThreshold returns the latency above which an operation is
+	// considered slow.
+	SlowThreshold(op Op) time.Duration
+
+	// SetHalfLife sets the half life of recorded operations when scoring
+	// volume health.
+	SetHalfLife(value time.Duration) Options
+
+	// HalfLife returns the half life of recorded operations when scoring
+	// volume health.
+	HalfLife() time.Duration
+
+	// SetMinOperations sets the minimum number of recent operations required
+	// before a volume can be scored unhealthy.
+	SetMinOperations(value float64) Options
+
+	// MinOperations returns the minimum number of recent operations required
+	// before a volume can be scored unhealthy.
+	MinOperations() float64
+
+	// SetUnhealthyScore sets the score below which a volume is unhealthy.
+	SetUnhealthyScore(value float64) Options
+
+	// UnhealthyScore returns the score below which a volume is unhealthy.
+	UnhealthyScore() float64
+
+	// SetExcludeUnhealthy sets whether unhealthy volumes are excluded from
+	// new flushes.
+	SetExcludeUnhealthy(value bool) Options
+
+	// ExcludeUnhealthy returns whether unhealthy volumes are excluded from
+	// new flushes.
+	ExcludeUnhealthy() bool
+}
@@ -25,13 +25,19 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/m3db/m3/src/dbnode/persist/fs/compression"
+	"github.com/m3db/m3/src/dbnode/persist/fs/datadirs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/encryption"
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
+	"github.com/m3db/m3/src/dbnode/ratelimit"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/m3ninx/index/segment/fst"
 	"github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/mmap"
 	"github.com/m3db/m3/src/x/pool"
+	xratelimit "github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/serialize"
 )
 
@@ -82,6 +88,12 @@ var (
 
 	errTagEncoderPoolNotSet = errors.New("tag encoder pool is not set")
 	errTagDecoderPoolNotSet = errors.New("tag decoder pool is not set")
+
+	errEncryptionOptionsNotSet   = errors.New("encryption options are not set")
+	errCompressionOptionsNotSet  = errors.New("compression options are not set")
+	errDiskRateLimiterNotSet     = errors.New("disk rate limiter is not set")
+	errIOHealthTrackerNotSet     = errors.New("io health tracker is not set")
+	errDataDirectoryLayoutNotSet = errors.New("data directory layout is not set")
 )
 
 type options struct {
@@ -109,6 +121,11 @@ type options struct {
 	mmapReporter                         mmap.Reporter
 	indexReaderAutovalidateIndexSegments bool
 	encodingOptions                      msgpack.LegacyEncodingOptions
+	encryptionOpts                       encryption.Options
+	compressionOpts                      compression.Options
+	diskRateLimiter                      xratelimit.Limiter
+	ioHealthTracker                      iohealth.Tracker
+	dataDirectoryLayout                  datadirs.Layout
 }
 
 type optionsInput struct {
@@ -207,9 +224,21 @@ func NewOptions(setters ...OptionSetter) Options {
 		fstWriterOptions:                     defaultFSTWriterOptions,
 		indexReaderAutovalidateIndexSegments: defaultIndexReaderAutovalidateIndexSegments,
 		encodingOptions:                      msgpack.DefaultLegacyEncodingOptions,
+		encryptionOpts:                       encryption.NewOptions(),
+		compressionOpts:                      compression.NewOptions(),
+		diskRateLimiter:                      newDefaultDiskRateLimiter(),
+		ioHealthTracker:                      iohealth.NewNoopTracker(),
+		dataDirectoryLayout:                  datadirs.NewNoopLayout(),
 	}
 }
 
+// newDefaultDiskRateLimiter returns an unlimited disk limiter so that only
+// the persist rate limit of the runtime options applies unless configured.
+func newDefaultDiskRateLimiter() xratelimit.Limiter {
+	return xratelimit.NewLimiter(ratelimit.DiskLimiterName,
+		xratelimit.Limit{}, xratelimit.NewOptions())
+}
+
 func (o *options) Validate() error {
 	if o.indexSummariesPercent < 0 || o.indexSummariesPercent > 1.0 {
 		return fmt.Errorf(
@@ -227,6 +256,27 @@ func (o *options) Validate() error {
 	if o.tagDecoderPool == nil {
 		return errTagDecoderPoolNotSet
 	}
+	if o.encryptionOpts == nil {
+		return errEncryptionOptionsNotSet
+	}
+	if err := o.encryptionOpts.Validate(); err != nil {
+		return fmt.Errorf("invalid encryption options: %w", err)
+	}
+	if o.compressionOpts == nil {
+		return errCompressionOptionsNotSet
+	}
+	if err := o.compressionOpts.Validate(); err != nil {
+		return fmt.Errorf("invalid compression options: %w", err)
+	}
+	if o.diskRateLimiter == nil {
+		return errDiskRateLimiterNotSet
+	}
+	if o.ioHealthTracker == nil {
+		return errIOHealthTrackerNotSet
+	}
+	if o.dataDirectoryLayout == nil {
+		return errDataDirectoryLayoutNotSet
+	}
 	return nil
 }
 
@@ -469,3 +519,53 @@ func (o *options) SetEncodingOptions(value msgpack.LegacyEncodingOptions) Option
 func (o *options) EncodingOptions() msgpack.LegacyEncodingOptions {
 	return o.encodingOptions
 }
+
+func (o *options) SetEncryptionOptions(value encryption.Options) Options {
+	opts := *o
+	opts.encryptionOpts = value
+	return &opts
+}
+
+func (o *options) EncryptionOptions() encryption.Options {
+	return o.encryptionOpts
+}
+
+func (o *options) SetCompressionOptions(value compression.Options) Options {
+	opts := *o
+	opts.compressionOpts = value
+	return &opts
+}
+
+func (o *options) CompressionOptions() compression.Options {
+	return o.compressionOpts
+}
+
+func (o *options) SetDiskRateLimiter(value xratelimit.Limiter) Options {
+	opts := *o
+	opts.diskRateLimiter = value
+	return &opts
+}
+
+func (o *options) DiskRateLimiter() xratelimit.Limiter {
+	return o.diskRateLimiter
+}
+
+func (o *options) SetIOHealthTracker(value iohealth.Tracker) Options {
+	opts := *o
+	opts.ioHealthTracker = value
+	return &opts
+}
+
+func (o *options) IOHealthTracker() iohealth.Tracker {
+	return o.ioHealthTracker
+}
+
+func (o *options) SetDataDirectoryLayout(value datadirs.Layout) Options {
+	opts := *o
+	opts.dataDirectoryLayout = value
+	return &opts
+}
+
+func (o *options) DataDirectoryLayout() datadirs.Layout {
+	return o.dataDirectoryLayout
+}
@@ -21,6 +21,7 @@
 package fs
 
 import (
+	stdctx "context"
 	"errors"
 	"fmt"
 	"sync"
@@ -36,6 +37,7 @@ import (
 	"github.com/m3db/m3/src/x/checked"
 	"github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/instrument"
+	xratelimit "github.com/m3db/m3/src/x/ratelimit"
 	xresource "github.com/m3db/m3/src/x/resource"
 
 	"github.com/pborman/uuid"
@@ -70,7 +72,9 @@ type sleepFn func(time.Duration)
 type nextSnapshotMetadataFileIndexFn func(opts Options) (index int64, err error)
 
 // persistManager is responsible for persisting series segments onto local filesystem.
-// It is not thread-safe.
+// Starting and finishing persistence is not thread-safe, data for different shards
+// may however be prepared and persisted concurrently, in which case the writes
+// share the persist rate limit.
 type persistManager struct {
 	sync.RWMutex
 
@@ -84,6 +88,7 @@ type persistManager struct {
 
 	status            persistManagerStatus
 	currRateLimitOpts ratelimit.Options
+	flushLimiter      xratelimit.Limiter
 
 	start        time.Time
 	count        int
@@ -113,6 +118,25 @@ type dataPersistManager struct {
 
 	// The ID of the snapshot being prepared. Only used when writing out snapshots.
 	snapshotID uuid.UUID
+
+	// writersLock guards the writers used by prepared data persists. The
+	// writer above is used unless it is already in use by another prepared
+	// data persist, in which case an additional writer is created and kept
+	// in idleWriters for reuse once closed.
+	writersLock sync.Mutex
+	writerInUse bool
+	idleWriters []*dataWriter
+	newWriterFn newDataWriterFn
+}
+
+type newDataWriterFn func(Options) (DataFileSetWriter, error)
+
+// dataWriter is a data writer used by a prepared data persist along with
+// the resources used to write segments with it.
+type dataWriter struct {
+	pm            *persistManager
+	writer        DataFileSetWriter
+	segmentHolder []checked.Bytes
 }
 
 type singleUseIndexWriterState struct {
@@ -265,14 +289,16 @@ func NewPersistManager(opts Options) (persist.Manager, error) {
 			segmentHolder:                 make([]checked.Bytes, 2),
 			nextSnapshotMetadataFileIndex: NextSnapshotMetadataFileIndex,
 			snapshotMetadataWriter:        NewSnapshotMetadataWriter(opts),
+			newWriterFn:                   NewWriter,
 		},
 		indexPM: indexPersistManager{
 			segmentWriter: segmentWriter,
 			// fs opts are used by underlying index writers
 			opts: opts,
 		},
-		status:  persistManagerIdle,
-		metrics: newPersistManagerMetrics(scope),
+		status:       persistManagerIdle,
+		flushLimiter: opts.DiskRateLimiter().Child(ratelimit.FlushLimiterName, xratelimit.Limit{}),
+		metrics:      newPersistManagerMetrics(scope),
 	}
 	pm.indexPM.newReaderFn = NewIndexReader
 	pm.indexPM.newPersistentSegmentFn = m3ninxpersist.NewSegment
@@ -509,31 +535,102 @@ func (pm *persistManager) PrepareData(opts persist.DataPrepareOptions) (persist.
 			VolumeIndex: volumeIndex,
 		},
 	}
-	if err := pm.dataPM.writer.Open(dataWriterOpts); err != nil {
+	w, err := pm.acquireDataWriter()
+	if err != nil {
+		return prepared, err
+	}
+	if err := w.writer.Open(dataWriterOpts); err != nil {
+		pm.releaseDataWriter(w)
 		return prepared, err
 	}
 
-	prepared.Persist = pm.persist
-	prepared.Close = pm.closeData
-	prepared.DeferClose = pm.deferCloseData
+	prepared.Persist = w.persist
+	prepared.Close = w.close
+	prepared.DeferClose = w.deferClose
 
 	return prepared, nil
 }
 
-func (pm *persistManager) persist(
+func (pm *persistManager) acquireDataWriter() (*dataWriter, error) {
+	pm.dataPM.writersLock.Lock()
+	defer pm.dataPM.writersLock.Unlock()
+
+	if !pm.dataPM.writerInUse {
+		pm.dataPM.writerInUse = true
+		return &dataWriter{
+			pm:            pm,
+			writer:        pm.dataPM.writer,
+			segmentHolder: pm.dataPM.segmentHolder,
+		}, nil
+	}
+
+	if n := len(pm.dataPM.idleWriters); n > 0 {
+		w := pm.dataPM.idleWriters[n-1]
+		pm.dataPM.idleWriters[n-1] = nil
+		pm.dataPM.idleWriters = pm.dataPM.idleWriters[:n-1]
+		return w, nil
+	}
+
+	writer, err := pm.dataPM.newWriterFn(pm.opts)
+	if err != nil {
+		return nil, err
+	}
+	return &dataWriter{
+		pm:            pm,
+		writer:        writer,
+		segmentHolder: make([]checked.Bytes, 2),
+	}, nil
+}
+
+func (pm *persistManager) releaseDataWriter(w *dataWriter) {
+	pm.dataPM.writersLock.Lock()
+	defer pm.dataPM.writersLock.Unlock()
+
+	if w.writer == pm.dataPM.writer {
+		pm.dataPM.writerInUse = false
+		return
+	}
+	pm.dataPM.idleWriters = append(pm.dataPM.idleWriters, w)
+}
+
+func (w *dataWriter) persist(
 	metadata persist.Metadata,
 	segment ts.Segment,
 	checksum uint32,
 ) error {
-	pm.RLock()
-	// Rate limit options can change dynamically
-	opts := pm.currRateLimitOpts
-	pm.RUnlock()
+	w.segmentHolder[0] = segment.Head
+	w.segmentHolder[1] = segment.Tail
+	return w.pm.persist(w.writer, w.segmentHolder, segment.Len(), metadata, checksum)
+}
 
+func (w *dataWriter) close() error {
+	defer w.pm.releaseDataWriter(w)
+	return w.writer.Close()
+}
+
+func (w *dataWriter) deferClose() (persist.DataCloser, error) {
+	defer w.pm.releaseDataWriter(w)
+	return w.writer.DeferClose()
+}
+
+func (pm *persistManager) persist(
+	writer DataFileSetWriter,
+	segmentHolder []checked.Bytes,
+	segmentLen int,
+	metadata persist.Metadata,
+	checksum uint32,
+) error {
 	var (
 		start = pm.nowFn()
+		wait  time.Duration
 		slept time.Duration
 	)
+
+	// NB: the rate limit is shared by all data being persisted concurrently
+	// so the accounting is done while holding the lock, sleeping is not.
+	pm.Lock()
+	// Rate limit options can change dynamically
+	opts := pm.currRateLimitOpts
 	rateLimitMbps := opts.LimitMbps()
 	if opts.LimitEnabled() && rateLimitMbps > 0.0 {
 		if pm.start.IsZero() {
@@ -541,38 +638,46 @@ func (pm *persistManager) persist(
 		} else if pm.count >= opts.LimitCheckEvery() {
 			target := time.Duration(float64(time.Second) * float64(pm.bytesWritten) / (rateLimitMbps * bytesPerMegabit))
 			if elapsed := start.Sub(pm.start); elapsed < target {
-				pm.sleepFn(target - elapsed)
-				// Recapture start for precise timing, might take some time to "wakeup"
-				now := pm.nowFn()
-				slept = now.Sub(start)
-				start = now
+				wait = target - elapsed
 			}
 			pm.count = 0
 		}
 	}
+	pm.Unlock()
 
-	pm.dataPM.segmentHolder[0] = segment.Head
-	pm.dataPM.segmentHolder[1] = segment.Tail
-	err := pm.dataPM.writer.WriteAll(metadata, pm.dataPM.segmentHolder, checksum)
-	pm.count++
-	pm.bytesWritten += int64(segment.Len())
+	if wait > 0 {
+		pm.sleepFn(wait)
+		// Recapture start for precise timing, might take some time to "wakeup"
+		now := pm.nowFn()
+		slept = now.Sub(start)
+		start = now
+	}
 
-	pm.worked += pm.nowFn().Sub(start)
+	// The flush budget is drawn from the disk rate limit shared with repairs
+	// and peer streaming, time spent waiting on it counts as throttled.
+	if err := pm.flushLimiter.WaitN(stdctx.Background(), int64(segmentLen)); err != nil {
+		return err
+	}
+	if now := pm.nowFn(); now.After(start) {
+		slept += now.Sub(start)
+		start = now
+	}
+
+	err := writer.WriteAll(metadata, segmentHolder, checksum)
+	worked := pm.nowFn().Sub(start)
+
+	pm.Lock()
+	pm.count++
+	pm.bytesWritten += int64(segmentLen)
+	pm.worked += worked
 	if slept > 0 {
 		pm.slept += slept
 	}
+	pm.Unlock()
 
 	return err
 }
 
-func (pm *persistManager) closeData() error {
-	return pm.dataPM.writer.Close()
-}
-
-func (pm *persistManager) deferCloseData() (persist.DataCloser, error) {
-	return pm.dataPM.writer.DeferClose()
-}
-
 // DoneFlush is called by the databaseFlushManager to finish the data persist process.
 func (pm *persistManager) DoneFlush() error {
 	pm.Lock()
@@ -37,6 +37,7 @@ import (
 	"github.com/m3db/m3/src/x/checked"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
+	xratelimit "github.com/m3db/m3/src/x/ratelimit"
 	m3test "github.com/m3db/m3/src/x/test"
 	xtest "github.com/m3db/m3/src/x/test"
 	xtime "github.com/m3db/m3/src/x/time"
@@ -311,7 +312,69 @@ func TestPersistenceManagerCloseData(t *testing.T) {
 	defer os.RemoveAll(pm.filePathPrefix)
 
 	writer.EXPECT().Close()
-	pm.closeData()
+	w, err := pm.acquireDataWriter()
+	require.NoError(t, err)
+	require.NoError(t, w.close())
+	require.False(t, pm.dataPM.writerInUse)
+}
+
+func TestPersistenceManagerPrepareDataConcurrently(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pm, writer, _, _ := testDataPersistManager(t, ctrl)
+	defer os.RemoveAll(pm.filePathPrefix)
+
+	var (
+		blockStart = xtime.FromSeconds(1000)
+		head       = checked.NewBytes([]byte{0x1, 0x2}, nil)
+		tail       = checked.NewBytes([]byte{0x3}, nil)
+		segment    = ts.NewSegment(head, tail, 0, ts.FinalizeNone)
+		checksum   = segment.CalculateChecksum()
+		metadata   = persist.NewMetadataFromIDAndTags(ident.StringID("foo"),
+			ident.Tags{}, persist.MetadataOptions{})
+		otherWriter = NewMockDataFileSetWriter(ctrl)
+		newWriters  = 0
+	)
+	pm.dataPM.newWriterFn = func(Options) (DataFileSetWriter, error) {
+		newWriters++
+		return otherWriter, nil
+	}
+
+	for _, w := range []*MockDataFileSetWriter{writer, otherWriter} {
+		w.EXPECT().Open(gomock.Any()).Return(nil)
+		w.EXPECT().WriteAll(metadata, gomock.Any(), checksum).Return(nil)
+		w.EXPECT().Close().Return(nil)
+	}
+
+	flush, err := pm.StartFlushPersist()
+	require.NoError(t, err)
+
+	var prepared []persist.PreparedDataPersist
+	for shard := uint32(0); shard < 2; shard++ {
+		p, err := flush.PrepareData(persist.DataPrepareOptions{
+			NamespaceMetadata: testNs1Metadata(t),
+			Shard:             shard,
+			BlockStart:        blockStart,
+		})
+		require.NoError(t, err)
+		prepared = append(prepared, p)
+	}
+	require.Equal(t, 1, newWriters)
+
+	for _, p := range prepared {
+		require.NoError(t, p.Persist(metadata, segment, checksum))
+	}
+	// Both writers count towards the shared rate limit.
+	require.Equal(t, 2, pm.count)
+	require.Equal(t, int64(6), pm.bytesWritten)
+
+	for _, p := range prepared {
+		require.NoError(t, p.Close())
+	}
+	require.False(t, pm.dataPM.writerInUse)
+	require.Len(t, pm.dataPM.idleWriters, 1)
+	require.NoError(t, flush.DoneFlush())
 }
 
 func TestPersistenceManagerPrepareIndexFileExists(t *testing.T) {
@@ -548,6 +611,72 @@ func TestPersistenceManagerNoRateLimit(t *testing.T) {
 	require.Equal(t, int64(6), pm.bytesWritten)
 }
 
+func TestPersistenceManagerWithDiskRateLimiter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pm, writer, _, _ := testDataPersistManager(t, ctrl)
+	defer os.RemoveAll(pm.filePathPrefix)
+
+	disk := xratelimit.NewLimiter("disk", xratelimit.Limit{Rate: 1000}, xratelimit.NewOptions())
+	defer disk.Close()
+	pm.flushLimiter = disk.Child("flush", xratelimit.Limit{Rate: 1000, Burst: 3})
+
+	shard := uint32(0)
+	blockStart := xtime.FromSeconds(1000)
+	writerOpts := xtest.CmpMatcher(DataWriterOpenOptions{
+		Identifier: FileSetFileIdentifier{
+			Namespace:  testNs1ID,
+			Shard:      shard,
+			BlockStart: blockStart,
+		},
+		BlockSize: testBlockSize,
+	}, m3test.IdentTransformer)
+	writer.EXPECT().Open(writerOpts).Return(nil)
+
+	var (
+		id       = ident.StringID("foo")
+		tags     = ident.NewTags(ident.StringTag("bar", "baz"))
+		head     = checked.NewBytes([]byte{0x1, 0x2}, nil)
+		tail     = checked.NewBytes([]byte{0x3}, nil)
+		segment  = ts.NewSegment(head, tail, 0, ts.FinalizeNone)
+		checksum = segment.CalculateChecksum()
+	)
+
+	metadata := persist.NewMetadataFromIDAndTags(id, tags,
+		persist.MetadataOptions{})
+	writer.EXPECT().
+		WriteAll(metadata, pm.dataPM.segmentHolder, checksum).
+		Return(nil).
+		Times(2)
+
+	flush, err := pm.StartFlushPersist()
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, flush.DoneFlush())
+	}()
+
+	prepared, err := flush.PrepareData(persist.DataPrepareOptions{
+		NamespaceMetadata: testNs1Metadata(t),
+		Shard:             shard,
+		BlockStart:        blockStart,
+	})
+	require.NoError(t, err)
+
+	// The second write waits for the flush budget to refill.
+	require.NoError(t, prepared.Persist(metadata, segment, checksum))
+	require.NoError(t, prepared.Persist(metadata, segment, checksum))
+
+	// The bytes persisted are drawn from the disk budget as well.
+	states := disk.States()
+	require.Len(t, states, 2)
+	require.Equal(t, "disk", states[0].Name)
+	require.Equal(t, int64(6), states[0].Consumed)
+	require.Equal(t, "disk.flush", states[1].Name)
+	require.Equal(t, int64(6), states[1].Consumed)
+}
+
 func TestPersistenceManagerWithRateLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -686,6 +815,10 @@ func TestPersistenceManagerNamespaceSwitch(t *testing.T) {
 	require.NotNil(t, prepared.Persist)
 	require.NotNil(t, prepared.Close)
 
+	// Close the data of the first namespace so that its writer is reused.
+	writer.EXPECT().Close()
+	require.NoError(t, prepared.Close())
+
 	writerOpts = xtest.CmpMatcher(DataWriterOpenOptions{
 		Identifier: FileSetFileIdentifier{
 			Namespace:  testNs2ID,
@@ -21,6 +21,7 @@
 package fs
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
@@ -31,6 +32,8 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs/compression"
+	"github.com/m3db/m3/src/dbnode/persist/fs/encryption"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
 	"github.com/m3db/m3/src/x/checked"
@@ -80,10 +83,29 @@ type reader struct {
 	indexMmap               mmap.Descriptor
 	indexDecoderStream      dataFileSetReaderDecoderStream
 	indexEntriesByOffsetAsc []schema.IndexEntry
-
-	dataFd     *os.File
-	dataMmap   mmap.Descriptor
-	dataReader digest.ReaderWithDigest
+	// indexDecoder is the decoder for the index file, which is the allocating
+	// decoder when the index file is encrypted since its entries are then
+	// decoded from a reader rather than from the mmap'd bytes.
+	indexDecoder      *msgpack.Decoder
+	allocIndexDecoder *msgpack.Decoder
+	indexBufReader    *bufio.Reader
+
+	dataFd   *os.File
+	dataMmap mmap.Descriptor
+	// dataBytes is only set when the data file is not encrypted, otherwise
+	// the data file is decrypted chunk by chunk as it is read via the
+	// dataReaderAt.
+	dataBytes     []byte
+	dataReaderAt  io.ReaderAt
+	dataSize      int64
+	dataBufReader *bufio.Reader
+	dataReader    digest.ReaderWithDigest
+	entryData     []byte
+
+	// dataDecompressor is set when the data file was written compressed.
+	dataDecompressor *dataFileDecompressor
+	compressedData   []byte
+	decompressedData []byte
 
 	bloomFilterFd *os.File
 
@@ -136,6 +158,9 @@ func NewReader(
 		indexDecoderStream:         newReaderDecoderStream(),
 		dataReader:                 digest.NewReaderWithDigest(nil),
 		decoder:                    msgpack.NewDecoder(opts.DecodingOptions()),
+		allocIndexDecoder:          msgpack.NewDecoder(opts.DecodingOptions().SetAllocDecodedBytes(true)),
+		indexBufReader:             bufio.NewReaderSize(nil, opts.DataReaderBufferSize()),
+		dataBufReader:              bufio.NewReaderSize(nil, opts.DataReaderBufferSize()),
 		digestBuf:                  digest.NewBuffer(),
 		bytesPool:                  bytesPool,
 		tagDecoderPool:             opts.TagDecoderPool(),
@@ -265,8 +290,56 @@ func (r *reader) Open(opts DataReaderOpenOptions) error {
 		logger.Warn("warning while mmapping files in reader", zap.Error(warning))
 	}
 
-	r.indexDecoderStream.Reset(r.indexMmap.Bytes)
-	r.dataReader.Reset(bytes.NewReader(r.dataMmap.Bytes))
+	// NB: Encrypted files are decrypted chunk by chunk as they are read
+	// rather than into memory in full, unencrypted files are read directly
+	// from the mmap'd bytes.
+	if encryption.IsEncrypted(r.indexMmap.Bytes) {
+		indexReaderAt, indexSize, err := newFileSetFileReaderAt(r.indexFd, r.opts)
+		if err != nil {
+			r.Close()
+			return err
+		}
+		r.indexBufReader.Reset(io.NewSectionReader(indexReaderAt, 0, indexSize))
+		r.indexDecoderStream.resetReader(r.indexBufReader)
+		r.indexDecoder = r.allocIndexDecoder
+	} else {
+		r.indexDecoderStream.Reset(r.indexMmap.Bytes)
+		r.indexDecoder = r.decoder
+	}
+
+	var dataReader io.Reader
+	if encryption.IsEncrypted(r.dataMmap.Bytes) {
+		r.dataReaderAt, r.dataSize, err = newFileSetFileReaderAt(r.dataFd, r.opts)
+		if err != nil {
+			r.Close()
+			return err
+		}
+		r.dataBufReader.Reset(io.NewSectionReader(r.dataReaderAt, 0, r.dataSize))
+		dataReader = r.dataBufReader
+	} else {
+		r.dataBytes = r.dataMmap.Bytes
+		r.dataReaderAt = bytes.NewReader(r.dataBytes)
+		r.dataSize = int64(len(r.dataBytes))
+		dataReader = bytes.NewReader(r.dataBytes)
+	}
+
+	var compressionHeaderSize int
+	r.dataDecompressor, compressionHeaderSize, err = newDataFileDecompressorAt(
+		r.dataReaderAt, r.dataSize, r.opts)
+	if err != nil {
+		r.Close()
+		return err
+	}
+
+	r.dataReader.Reset(dataReader)
+	if compressionHeaderSize > 0 {
+		// Skip over the compression header while still including it in the
+		// digest of the data file.
+		if _, err := io.CopyN(io.Discard, r.dataReader, int64(compressionHeaderSize)); err != nil {
+			r.Close()
+			return err
+		}
+	}
 
 	if err := r.readDigest(); err != nil {
 		// Try to close if failed to read
@@ -283,7 +356,7 @@ func (r *reader) Open(opts DataReaderOpenOptions) error {
 		return err
 	}
 	if opts.StreamingEnabled {
-		r.decoder.Reset(r.indexDecoderStream)
+		r.indexDecoder.Reset(r.indexDecoderStream)
 	} else if err := r.readIndexAndSortByOffsetAsc(); err != nil {
 		r.Close()
 		return err
@@ -354,9 +427,9 @@ func (r *reader) readIndexAndSortByOffsetAsc() error {
 		return errUnexpectedSortByOffset
 	}
 
-	r.decoder.Reset(r.indexDecoderStream)
+	r.indexDecoder.Reset(r.indexDecoderStream)
 	for i := 0; i < r.entries; i++ {
-		entry, err := r.decoder.DecodeIndexEntry(nil)
+		entry, err := r.indexDecoder.DecodeIndexEntry(nil)
 		if err != nil {
 			return err
 		}
@@ -378,25 +451,30 @@ func (r *reader) StreamingRead() (StreamedDataEntry, error) {
 		return StreamedDataEntry{}, io.EOF
 	}
 
-	entry, err := r.decoder.DecodeIndexEntry(nil)
+	entry, err := r.indexDecoder.DecodeIndexEntry(nil)
 	if err != nil {
 		return StreamedDataEntry{}, err
 	}
 
-	if entry.Offset+entry.Size > int64(len(r.dataMmap.Bytes)) {
-		return StreamedDataEntry{}, fmt.Errorf(
-			"attempt to read beyond data file size (offset=%d, size=%d, file size=%d)",
-			entry.Offset, entry.Size, len(r.dataMmap.Bytes))
+	data, err := r.readEntryData(entry)
+	if err != nil {
+		return StreamedDataEntry{}, err
+	}
+	if r.dataDecompressor != nil {
+		r.streamingData, err = r.dataDecompressor.decode(r.streamingData[:0], data)
+		if err != nil {
+			return StreamedDataEntry{}, err
+		}
+	} else {
+		r.streamingData = append(r.streamingData[:0], data...)
 	}
-	data := r.dataMmap.Bytes[entry.Offset : entry.Offset+entry.Size]
 
 	// NB(r): _must_ check the checksum against known checksum as the data
 	// file might not have been verified if we haven't read through the file yet.
-	if entry.DataChecksum != int64(digest.Checksum(data)) {
+	if entry.DataChecksum != int64(digest.Checksum(r.streamingData)) {
 		return StreamedDataEntry{}, errSeekChecksumMismatch
 	}
 
-	r.streamingData = append(r.streamingData[:0], data...)
 	r.streamingID = append(r.streamingID[:0], entry.ID...)
 	r.streamingTags = append(r.streamingTags[:0], entry.EncodedTags...)
 
@@ -429,24 +507,36 @@ func (r *reader) Read() (ident.ID, ident.TagIterator, checked.Bytes, uint32, err
 
 	entry := r.indexEntriesByOffsetAsc[r.entriesRead]
 
+	size := int(entry.Size)
+	if r.dataDecompressor != nil {
+		if err := r.readCompressedData(entry); err != nil {
+			return nil, nil, nil, 0, err
+		}
+		size = len(r.decompressedData)
+	}
+
 	var data checked.Bytes
 	if r.bytesPool != nil {
-		data = r.bytesPool.Get(int(entry.Size))
+		data = r.bytesPool.Get(size)
 		data.IncRef()
 		defer data.DecRef()
-		data.Resize(int(entry.Size))
+		data.Resize(size)
 	} else {
-		data = checked.NewBytes(make([]byte, entry.Size), nil)
+		data = checked.NewBytes(make([]byte, size), nil)
 		data.IncRef()
 		defer data.DecRef()
 	}
 
-	n, err := r.dataReader.Read(data.Bytes())
-	if err != nil {
-		return nil, nil, nil, 0, err
-	}
-	if n != int(entry.Size) {
-		return nil, nil, nil, 0, errReadNotExpectedSize
+	if r.dataDecompressor != nil {
+		copy(data.Bytes(), r.decompressedData)
+	} else {
+		n, err := io.ReadFull(r.dataReader, data.Bytes())
+		if n != int(entry.Size) {
+			return nil, nil, nil, 0, errReadNotExpectedSize
+		}
+		if err != nil {
+			return nil, nil, nil, 0, err
+		}
 	}
 
 	id := r.entryClonedID(entry.ID)
@@ -456,6 +546,26 @@ func (r *reader) Read() (ident.ID, ident.TagIterator, checked.Bytes, uint32, err
 	return id, tags, data, uint32(entry.DataChecksum), nil
 }
 
+// readCompressedData reads the compressed data of the entry and decompresses
+// it into the decompressed data buffer.
+func (r *reader) readCompressedData(entry schema.IndexEntry) error {
+	if cap(r.compressedData) < int(entry.Size) {
+		r.compressedData = make([]byte, entry.Size)
+	}
+	r.compressedData = r.compressedData[:entry.Size]
+
+	n, err := io.ReadFull(r.dataReader, r.compressedData)
+	if n != int(entry.Size) {
+		return errReadNotExpectedSize
+	}
+	if err != nil {
+		return err
+	}
+
+	r.decompressedData, err = r.dataDecompressor.decode(r.decompressedData[:0], r.compressedData)
+	return err
+}
+
 func (r *reader) StreamingReadMetadata() (StreamedMetadataEntry, error) {
 	if !r.streamingEnabled {
 		return StreamedMetadataEntry{}, errStreamingRequired
@@ -465,7 +575,11 @@ func (r *reader) StreamingReadMetadata() (StreamedMetadataEntry, error) {
 		return StreamedMetadataEntry{}, io.EOF
 	}
 
-	entry, err := r.decoder.DecodeIndexEntry(nil)
+	entry, err := r.indexDecoder.DecodeIndexEntry(nil)
+	if err != nil {
+		return StreamedMetadataEntry{}, err
+	}
+	length, err := r.entryLength(entry)
 	if err != nil {
 		return StreamedMetadataEntry{}, err
 	}
@@ -478,7 +592,7 @@ func (r *reader) StreamingReadMetadata() (StreamedMetadataEntry, error) {
 	return StreamedMetadataEntry{
 		ID:           r.streamingID,
 		EncodedTags:  r.streamingTags,
-		Length:       int(entry.Size),
+		Length:       length,
 		DataChecksum: uint32(entry.DataChecksum),
 	}, nil
 }
@@ -493,15 +607,54 @@ func (r *reader) ReadMetadata() (ident.ID, ident.TagIterator, int, uint32, error
 	}
 
 	entry := r.indexEntriesByOffsetAsc[r.metadataRead]
+	length, err := r.entryLength(entry)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
 	id := r.entryClonedID(entry.ID)
 	tags := r.entryClonedEncodedTagsIter(entry.EncodedTags)
-	length := int(entry.Size)
 	checksum := uint32(entry.DataChecksum)
 
 	r.metadataRead++
 	return id, tags, length, checksum, nil
 }
 
+// entryLength returns the length of the data of the entry, for compressed data
+// files this is the length of the decompressed data so that it is the same
+// regardless of whether the data file was written compressed.
+func (r *reader) entryLength(entry schema.IndexEntry) (int, error) {
+	if r.dataDecompressor == nil {
+		return int(entry.Size), nil
+	}
+	data, err := r.readEntryData(entry)
+	if err != nil {
+		return 0, err
+	}
+	return compression.DecodedSize(data)
+}
+
+// readEntryData returns the data of the entry as stored in the data file,
+// the returned bytes are only valid until the next call.
+func (r *reader) readEntryData(entry schema.IndexEntry) ([]byte, error) {
+	if entry.Offset < 0 || entry.Size < 0 || entry.Offset+entry.Size > r.dataSize {
+		return nil, fmt.Errorf(
+			"attempt to read beyond data file size (offset=%d, size=%d, file size=%d)",
+			entry.Offset, entry.Size, r.dataSize)
+	}
+	if r.dataBytes != nil {
+		return r.dataBytes[entry.Offset : entry.Offset+entry.Size], nil
+	}
+
+	if cap(r.entryData) < int(entry.Size) {
+		r.entryData = make([]byte, entry.Size)
+	}
+	r.entryData = r.entryData[:entry.Size]
+	if n, err := r.dataReaderAt.ReadAt(r.entryData, entry.Offset); n < len(r.entryData) {
+		return nil, err
+	}
+	return r.entryData, nil
+}
+
 func (r *reader) ReadBloomFilter() (*ManagedConcurrentBloomFilter, error) {
 	return newManagedConcurrentBloomFilterFromFile(
 		r.bloomFilterFd,
@@ -600,7 +753,12 @@ func (r *reader) Close() error {
 	multiErr = multiErr.Add(r.indexFd.Close())
 	multiErr = multiErr.Add(r.dataFd.Close())
 	multiErr = multiErr.Add(r.bloomFilterFd.Close())
+	if r.dataDecompressor != nil {
+		multiErr = multiErr.Add(r.dataDecompressor.close())
+	}
 	r.indexDecoderStream.Reset(nil)
+	r.indexBufReader.Reset(nil)
+	r.dataBufReader.Reset(nil)
 	r.dataReader.Reset(nil)
 	for i := 0; i < len(r.indexEntriesByOffsetAsc); i++ {
 		r.indexEntriesByOffsetAsc[i].ID = nil
@@ -617,6 +775,10 @@ func (r *reader) Close() error {
 	indexDecoderStream := r.indexDecoderStream
 	dataReader := r.dataReader
 	decoder := r.decoder
+	allocIndexDecoder := r.allocIndexDecoder
+	indexBufReader := r.indexBufReader
+	dataBufReader := r.dataBufReader
+	entryData := r.entryData
 	digestBuf := r.digestBuf
 	bytesPool := r.bytesPool
 	tagDecoderPool := r.tagDecoderPool
@@ -635,6 +797,10 @@ func (r *reader) Close() error {
 	r.indexDecoderStream = indexDecoderStream
 	r.dataReader = dataReader
 	r.decoder = decoder
+	r.allocIndexDecoder = allocIndexDecoder
+	r.indexBufReader = indexBufReader
+	r.dataBufReader = dataBufReader
+	r.entryData = entryData[:0]
 	r.digestBuf = digestBuf
 	r.bytesPool = bytesPool
 	r.tagDecoderPool = tagDecoderPool
@@ -35,6 +35,11 @@ type dataFileSetReaderDecoderStream interface {
 	// reader returns the underlying reader with access to the
 	// incremental computed digest
 	reader() digest.ReaderWithDigest
+
+	// resetReader resets the decoder stream for decoding from a reader
+	// rather than a byte slice, in which case there are no backing bytes
+	// and decoded bytes must be allocated by the decoder.
+	resetReader(r io.Reader)
 }
 
 type readerDecoderStream struct {
@@ -65,6 +70,14 @@ func (s *readerDecoderStream) Reset(d []byte) {
 	s.unreadByte = -1
 }
 
+func (s *readerDecoderStream) resetReader(r io.Reader) {
+	s.bytesReader.Reset(nil)
+	s.readerWithDigest.Reset(r)
+	s.backingBytes = nil
+	s.lastReadByte = -1
+	s.unreadByte = -1
+}
+
 func (s *readerDecoderStream) Read(p []byte) (int, error) {
 	if len(p) == 0 {
 		return 0, nil
@@ -88,6 +88,7 @@ type blockRetriever struct {
 	queryLimits             limits.QueryLimits
 	bytesReadLimit          limits.LookbackLimit
 	seriesBloomFilterMisses tally.Counter
+	metrics                 blockRetrieverMetrics
 
 	newSeekerMgrFn newSeekerMgrFn
 
@@ -125,6 +126,7 @@ func NewBlockRetriever(
 		queryLimits:             opts.QueryLimits(),
 		bytesReadLimit:          opts.QueryLimits().BytesReadLimit(),
 		seriesBloomFilterMisses: scope.Counter("series-bloom-filter-misses"),
+		metrics:                 newBlockRetrieverMetrics(scope),
 		newSeekerMgrFn:          NewSeekerManager,
 		reqPool:                 opts.RetrieveRequestPool(),
 		bytesPool:               opts.BytesPool(),
@@ -223,9 +225,13 @@ func (r *blockRetriever) fetchLoop(seekerMgr DataFileSetSeekerManager) {
 		}
 
 		status := r.status
-		n := len(inFlight)
 		r.RUnlock()
 
+		// Fail any requests whose context finished while they were queued so
+		// that no disk IO is spent on them.
+		inFlight = r.filterDone(inFlight)
+		n := len(inFlight)
+
 		// Exit if not open and fulfilled all open requests
 		if n == 0 && status != blockRetrieverOpen {
 			break
@@ -254,6 +260,14 @@ func (r *blockRetriever) fetchLoop(seekerMgr DataFileSetSeekerManager) {
 		// so we re-arrange the order of the requests to achieve that
 		sort.Sort(retrieveRequestByStartAscShardAsc(inFlight))
 
+		// Requests close to their deadline are fetched first so that they
+		// don't time out waiting behind requests that have time to spare, the
+		// sort is stable so that each group of requests keeps its locality.
+		if threshold := r.opts.DeadlinePriorityThreshold(); threshold > 0 {
+			r.markNearDeadline(inFlight, threshold)
+			sort.Stable(retrieveRequestByNearDeadlineFirst(inFlight))
+		}
+
 		// Iterate through all in flight requests and send them to the seeker in
 		// batches of block time + shard.
 		currBatchShard := uint32(0)
@@ -295,6 +309,41 @@ func (r *blockRetriever) fetchLoop(seekerMgr DataFileSetSeekerManager) {
 	r.fetchLoopsHaveShutdownCh <- struct{}{}
 }
 
+// filterDone completes the requests whose context is done with the context
+// error and returns the remaining requests.
+func (r *blockRetriever) filterDone(reqs []*retrieveRequest) []*retrieveRequest {
+	filtered := reqs[:0]
+	for _, req := range reqs {
+		if err := req.stdCtx.Err(); err != nil {
+			r.metrics.cancelledWhileQueued.Inc(1)
+			req.err = err
+			req.onDone()
+			continue
+		}
+		filtered = append(filtered, req)
+	}
+
+	// Free references to the completed requests.
+	for i := len(filtered); i < len(reqs); i++ {
+		reqs[i] = nil
+	}
+	return filtered
+}
+
+func (r *blockRetriever) markNearDeadline(
+	reqs []*retrieveRequest,
+	threshold time.Duration,
+) {
+	cutoff := time.Now().Add(threshold)
+	for _, req := range reqs {
+		deadline, ok := req.stdCtx.Deadline()
+		req.nearDeadline = ok && deadline.Before(cutoff)
+		if req.nearDeadline {
+			r.metrics.nearDeadlinePrioritized.Inc(1)
+		}
+	}
+}
+
 func (r *blockRetriever) fetchBatch(
 	seekerMgr DataFileSetSeekerManager,
 	shard uint32,
@@ -377,6 +426,7 @@ func (r *blockRetriever) fetchBatch(
 		select {
 		case <-req.stdCtx.Done():
 			req.err = req.stdCtx.Err()
+			r.metrics.cancelledBeforeIndexSeek.Inc(1)
 			continue
 		default:
 		}
@@ -424,6 +474,8 @@ func (r *blockRetriever) fetchBatch(
 		select {
 		case <-req.stdCtx.Done():
 			req.err = req.stdCtx.Err()
+			r.metrics.cancelledBeforeDataSeek.Inc(1)
+			r.metrics.cancelledBytesNotRead.Inc(int64(req.indexEntry.Size))
 			continue
 		default:
 		}
@@ -722,8 +774,9 @@ type retrieveRequest struct {
 	finalizes uint32
 	shard     uint32
 
-	notFound bool
-	success  bool
+	notFound     bool
+	success      bool
+	nearDeadline bool
 }
 
 func (req *retrieveRequest) toBlock() xio.BlockReader {
@@ -875,6 +928,7 @@ func (req *retrieveRequest) resetForReuse() {
 	req.err = nil
 	req.notFound = false
 	req.success = false
+	req.nearDeadline = false
 	req.stdCtx = nil
 }
 
@@ -889,6 +943,14 @@ func (r retrieveRequestByStartAscShardAsc) Less(i, j int) bool {
 	return r[i].shard < r[j].shard
 }
 
+type retrieveRequestByNearDeadlineFirst []*retrieveRequest
+
+func (r retrieveRequestByNearDeadlineFirst) Len() int      { return len(r) }
+func (r retrieveRequestByNearDeadlineFirst) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r retrieveRequestByNearDeadlineFirst) Less(i, j int) bool {
+	return r[i].nearDeadline && !r[j].nearDeadline
+}
+
 type retrieveRequestByIndexEntryOffsetAsc []*retrieveRequest
 
 func (r retrieveRequestByIndexEntryOffsetAsc) Len() int      { return len(r) }
@@ -962,3 +1024,25 @@ func (r *reusableRetrieverResources) resetDataReqs() {
 	}
 	r.dataReqs = r.dataReqs[:0]
 }
+
+type blockRetrieverMetrics struct {
+	cancelledWhileQueued     tally.Counter
+	cancelledBeforeIndexSeek tally.Counter
+	cancelledBeforeDataSeek  tally.Counter
+	cancelledBytesNotRead    tally.Counter
+	nearDeadlinePrioritized  tally.Counter
+}
+
+func newBlockRetrieverMetrics(scope tally.Scope) blockRetrieverMetrics {
+	cancelled := func(stage string) tally.Counter {
+		return scope.Tagged(map[string]string{"stage": stage}).
+			Counter("cancelled-before-read")
+	}
+	return blockRetrieverMetrics{
+		cancelledWhileQueued:     cancelled("queued"),
+		cancelledBeforeIndexSeek: cancelled("index-seek"),
+		cancelledBeforeDataSeek:  cancelled("data-seek"),
+		cancelledBytesNotRead:    scope.Counter("cancelled-bytes-not-read"),
+		nearDeadlinePrioritized:  scope.Counter("near-deadline-prioritized"),
+	}
+}
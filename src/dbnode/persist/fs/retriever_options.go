@@ -23,6 +23,7 @@ package fs
 import (
 	"errors"
 	"runtime"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/limits"
@@ -36,6 +37,8 @@ var (
 	defaultFetchConcurrency = runtime.GOMAXPROCS(0)
 	defaultCacheOnRetrieve  = false
 
+	defaultDeadlinePriorityThreshold = time.Second
+
 	errBlockLeaseManagerNotSet = errors.New("block lease manager is not set")
 )
 
@@ -47,6 +50,8 @@ type blockRetrieverOptions struct {
 	identifierPool    ident.Pool
 	blockLeaseManager block.LeaseManager
 	queryLimits       limits.QueryLimits
+
+	deadlinePriorityThreshold time.Duration
 }
 
 // NewBlockRetrieverOptions creates a new set of block retriever options
@@ -71,6 +76,8 @@ func NewBlockRetrieverOptions() BlockRetrieverOptions {
 		cacheOnRetrieve:  defaultCacheOnRetrieve,
 		identifierPool:   ident.NewPool(bytesPool, ident.PoolOptions{}),
 		queryLimits:      limits.NoOpQueryLimits(),
+
+		deadlinePriorityThreshold: defaultDeadlinePriorityThreshold,
 	}
 
 	return o
@@ -152,3 +159,13 @@ func (o *blockRetrieverOptions) SetQueryLimits(value limits.QueryLimits) BlockRe
 func (o *blockRetrieverOptions) QueryLimits() limits.QueryLimits {
 	return o.queryLimits
 }
+
+func (o *blockRetrieverOptions) SetDeadlinePriorityThreshold(value time.Duration) BlockRetrieverOptions {
+	opts := *o
+	opts.deadlinePriorityThreshold = value
+	return &opts
+}
+
+func (o *blockRetrieverOptions) DeadlinePriorityThreshold() time.Duration {
+	return o.deadlinePriorityThreshold
+}
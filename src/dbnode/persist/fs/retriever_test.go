@@ -28,6 +28,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"sync"
 	"testing"
@@ -884,6 +885,90 @@ func testBlockRetrieverHandlesSeekErrors(t *testing.T, ctrl *gomock.Controller,
 	assert.Equal(t, nil, segment.Tail)
 }
 
+// TestBlockRetrieverSkipsCancelledRequests verifies that requests whose context
+// is done before they are fetched never borrow a seeker.
+func TestBlockRetrieverSkipsCancelledRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("test", nil)
+
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePathPrefix := filepath.Join(dir, "")
+
+	var (
+		fsOpts     = testDefaultOpts.SetFilePathPrefix(filePathPrefix)
+		rOpts      = testNs1Metadata(t).Options().RetentionOptions()
+		nsCtx      = namespace.NewContextFrom(testNs1Metadata(t))
+		shard      = uint32(0)
+		blockStart = xtime.Now().Truncate(rOpts.BlockSize())
+	)
+
+	// NB: no Borrow expectation since the request should never be fetched.
+	mockSeekerManager := NewMockDataFileSetSeekerManager(ctrl)
+	mockSeekerManager.EXPECT().Open(gomock.Any(), gomock.Any()).Return(nil)
+	mockSeekerManager.EXPECT().Test(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+	mockSeekerManager.EXPECT().Close().Return(nil)
+
+	newSeekerMgr := func(
+		bytesPool pool.CheckedBytesPool,
+		opts Options,
+		blockRetrieverOpts BlockRetrieverOptions,
+	) DataFileSetSeekerManager {
+		return mockSeekerManager
+	}
+
+	opts := testBlockRetrieverOptions{
+		retrieverOpts:  defaultTestBlockRetrieverOptions,
+		fsOpts:         fsOpts.SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope)),
+		newSeekerMgrFn: newSeekerMgr,
+		shards:         []uint32{shard},
+	}
+	retriever, cleanup := newOpenTestBlockRetriever(t, testNs1Metadata(t), opts)
+	defer cleanup()
+
+	goCtx, cancel := stdctx.WithCancel(stdctx.Background())
+	cancel()
+
+	ctx := context.NewWithGoContext(goCtx)
+	defer ctx.Close()
+	segmentReader, err := retriever.Stream(ctx, shard,
+		ident.StringID("cancelled"), blockStart, nil, nsCtx)
+	require.NoError(t, err)
+
+	_, err = segmentReader.Segment()
+	require.Equal(t, stdctx.Canceled, err)
+
+	snapshot := scope.Snapshot()
+	cancelled := snapshot.Counters()["test.retriever.cancelled-before-read+stage=queued"]
+	require.NotNil(t, cancelled)
+	require.Equal(t, int64(1), cancelled.Value())
+}
+
+func TestRetrieveRequestsNearDeadlineFirst(t *testing.T) {
+	r := &blockRetriever{metrics: newBlockRetrieverMetrics(tally.NoopScope)}
+
+	far, cancelFar := stdctx.WithTimeout(stdctx.Background(), time.Hour)
+	defer cancelFar()
+	near, cancelNear := stdctx.WithTimeout(stdctx.Background(), time.Millisecond)
+	defer cancelNear()
+
+	reqs := []*retrieveRequest{
+		{stdCtx: stdctx.Background(), start: 1, shard: 0},
+		{stdCtx: far, start: 1, shard: 1},
+		{stdCtx: near, start: 2, shard: 0},
+		{stdCtx: stdctx.Background(), start: 2, shard: 1},
+		{stdCtx: near, start: 3, shard: 0},
+	}
+	expected := []*retrieveRequest{reqs[2], reqs[4], reqs[0], reqs[1], reqs[3]}
+
+	r.markNearDeadline(reqs, time.Second)
+	sort.Stable(retrieveRequestByNearDeadlineFirst(reqs))
+	require.Equal(t, expected, reqs)
+}
+
 func testTagsFromIDAndVolume(seriesID string, volume int) ident.Tags {
 	tags := []ident.Tag{}
 	for j := 0; j < 5; j++ {
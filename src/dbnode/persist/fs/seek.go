@@ -30,10 +30,12 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
 	xmsgpack "github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
 	"github.com/m3db/m3/src/x/checked"
 	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/fault"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/mmap"
@@ -76,6 +78,15 @@ type seeker struct {
 	indexFd       *os.File
 	indexFileSize int64
 
+	// Readers of the plaintext of the index and data files, these are the
+	// fds themselves unless the files were written encrypted.
+	dataReader  io.ReaderAt
+	indexReader io.ReaderAt
+
+	// dataDecompressor is set when the data file was written compressed, it
+	// is shared with clones.
+	dataDecompressor *dataFileDecompressor
+
 	unreadBuf []byte
 
 	// Bloom filter associated with the shard / block the seeker is responsible
@@ -83,6 +94,10 @@ type seeker struct {
 	bloomFilter *ManagedConcurrentBloomFilter
 	indexLookup *nearestIndexOffsetLookup
 
+	// volume is the data directory the seeker's files are on, used to
+	// attribute IO latency.
+	volume string
+
 	isClone bool
 }
 
@@ -158,6 +173,10 @@ func (s *seeker) Open(
 	}
 
 	shardDir := ShardDataDirPath(s.opts.filePathPrefix, namespace, shard)
+	s.volume = s.opts.opts.DataDirectoryLayout().Volume(shardDir)
+	if s.volume == "" {
+		s.volume = s.opts.filePathPrefix
+	}
 	var (
 		infoFd, digestFd, bloomFilterFd, summariesFd *os.File
 		err                                          error
@@ -230,8 +249,31 @@ func (s *seeker) Open(
 	s.blockSize = time.Duration(info.BlockSize)
 	s.versionChecker = schema.NewVersionChecker(int(info.MajorVersion), int(info.MinorVersion))
 
+	s.indexReader, s.indexFileSize, err = newFileSetFileReaderAt(s.indexFd, s.opts.opts)
+	if err != nil {
+		s.Close()
+		return err
+	}
+	var dataSize int64
+	s.dataReader, dataSize, err = newFileSetFileReaderAt(s.dataFd, s.opts.opts)
+	if err != nil {
+		s.Close()
+		return err
+	}
+	s.dataDecompressor, _, err = newDataFileDecompressorAt(s.dataReader, dataSize, s.opts.opts)
+	if err != nil {
+		s.Close()
+		return err
+	}
+
+	var indexReaderWithDigest digestValidatingReader = indexFdWithDigest
+	if s.indexReader != s.indexFd {
+		// The digest is of the plaintext so validate via the decrypting reader.
+		indexReaderWithDigest = digest.NewReaderWithDigest(bufio.NewReaderSize(
+			io.NewSectionReader(s.indexReader, 0, s.indexFileSize), s.opts.dataBufferSize))
+	}
 	err = s.validateIndexFileDigest(
-		indexFdWithDigest, expectedDigests.indexDigest)
+		indexReaderWithDigest, expectedDigests.indexDigest)
 	if err != nil {
 		s.Close()
 		return fmt.Errorf(
@@ -240,13 +282,6 @@ func (s *seeker) Open(
 		)
 	}
 
-	indexFdStat, err := s.indexFd.Stat()
-	if err != nil {
-		s.Close()
-		return err
-	}
-	s.indexFileSize = indexFdStat.Size()
-
 	s.bloomFilter, err = newManagedConcurrentBloomFilterFromFile(
 		bloomFilterFd,
 		bloomFilterFdWithDigest,
@@ -338,7 +373,15 @@ func (s *seeker) SeekByIndexEntry(
 	entry IndexEntry,
 	resources ReusableSeekerResources,
 ) (checked.Bytes, error) {
-	resources.offsetFileReader.reset(s.dataFd, entry.Offset)
+	if err := fault.Inject(fault.BlockRead); err != nil {
+		return nil, err
+	}
+
+	if s.dataDecompressor != nil {
+		return s.seekCompressedByIndexEntry(entry, resources)
+	}
+
+	resources.offsetFileReader.reset(s.dataReader, entry.Offset)
 
 	// Obtain an appropriately sized buffer.
 	var buffer checked.Bytes
@@ -355,7 +398,11 @@ func (s *seeker) SeekByIndexEntry(
 
 	// Copy the actual data into the underlying buffer.
 	underlyingBuf := buffer.Bytes()
+	nowFn := s.opts.opts.ClockOptions().NowFn()
+	start := nowFn()
 	n, err := io.ReadFull(resources.offsetFileReader, underlyingBuf)
+	s.opts.opts.IOHealthTracker().Record(
+		s.volume, iohealth.OpRead, nowFn().Sub(start), err)
 	if err != nil {
 		return nil, err
 	}
@@ -375,6 +422,59 @@ func (s *seeker) SeekByIndexEntry(
 	return buffer, nil
 }
 
+// seekCompressedByIndexEntry reads the compressed data of the entry and returns
+// it decompressed, the checksum of the entry is that of the decompressed data.
+func (s *seeker) seekCompressedByIndexEntry(
+	entry IndexEntry,
+	resources ReusableSeekerResources,
+) (checked.Bytes, error) {
+	bufs := resources.compressionBuffers
+	if bufs == nil {
+		bufs = &seekerCompressionBuffers{}
+	}
+	if cap(bufs.compressed) < int(entry.Size) {
+		bufs.compressed = make([]byte, entry.Size)
+	}
+	bufs.compressed = bufs.compressed[:entry.Size]
+
+	resources.offsetFileReader.reset(s.dataReader, entry.Offset)
+	nowFn := s.opts.opts.ClockOptions().NowFn()
+	start := nowFn()
+	_, err := io.ReadFull(resources.offsetFileReader, bufs.compressed)
+	s.opts.opts.IOHealthTracker().Record(
+		s.volume, iohealth.OpRead, nowFn().Sub(start), err)
+	if err != nil {
+		return nil, err
+	}
+
+	bufs.decompressed, err = s.dataDecompressor.decode(bufs.decompressed[:0], bufs.compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	// NB: _must_ check the checksum against known checksum as the data
+	// file might not have been verified if we haven't read through the file yet.
+	if entry.DataChecksum != digest.Checksum(bufs.decompressed) {
+		return nil, errSeekChecksumMismatch
+	}
+
+	size := len(bufs.decompressed)
+	var buffer checked.Bytes
+	if s.opts.bytesPool != nil {
+		buffer = s.opts.bytesPool.Get(size)
+		buffer.IncRef()
+		defer buffer.DecRef()
+		buffer.Resize(size)
+	} else {
+		buffer = checked.NewBytes(make([]byte, size), nil)
+		buffer.IncRef()
+		defer buffer.DecRef()
+	}
+	copy(buffer.Bytes(), bufs.decompressed)
+
+	return buffer, nil
+}
+
 // SeekIndexEntry performs the following steps:
 //
 //     1. Go to the indexLookup and it will give us an offset that is a good starting
@@ -388,6 +488,23 @@ func (s *seeker) SeekByIndexEntry(
 func (s *seeker) SeekIndexEntry(
 	id ident.ID,
 	resources ReusableSeekerResources,
+) (IndexEntry, error) {
+	nowFn := s.opts.opts.ClockOptions().NowFn()
+	start := nowFn()
+	entry, err := s.seekIndexEntry(id, resources)
+	ioErr := err
+	if err == errSeekIDNotFound {
+		// Not finding an ID is not a failure of the volume.
+		ioErr = nil
+	}
+	s.opts.opts.IOHealthTracker().Record(
+		s.volume, iohealth.OpSeek, nowFn().Sub(start), ioErr)
+	return entry, err
+}
+
+func (s *seeker) seekIndexEntry(
+	id ident.ID,
+	resources ReusableSeekerResources,
 ) (IndexEntry, error) {
 	offset, err := s.indexLookup.getNearestIndexFileOffset(id, resources)
 	// Should never happen, either something is really wrong with the code or
@@ -396,7 +513,7 @@ func (s *seeker) SeekIndexEntry(
 		return IndexEntry{}, err
 	}
 
-	resources.offsetFileReader.reset(s.indexFd, offset)
+	resources.offsetFileReader.reset(s.indexReader, offset)
 	resources.fileDecoderStream.Reset(resources.offsetFileReader)
 	resources.xmsgpackDecoder.Reset(resources.fileDecoderStream)
 
@@ -491,6 +608,12 @@ func (s *seeker) Close() error {
 		multiErr = multiErr.Add(s.dataFd.Close())
 		s.dataFd = nil
 	}
+	if s.dataDecompressor != nil {
+		multiErr = multiErr.Add(s.dataDecompressor.close())
+		s.dataDecompressor = nil
+	}
+	s.indexReader = nil
+	s.dataReader = nil
 	return multiErr.FinalError()
 }
 
@@ -509,11 +632,17 @@ func (s *seeker) ConcurrentClone() (ConcurrentDataFileSetSeeker, error) {
 		bloomFilter: s.bloomFilter,
 		indexLookup: indexLookupClone,
 		isClone:     true,
+		volume:      s.volume,
 
 		// Index and data fd's are always accessed via the ReadAt() / pread APIs so
 		// they are concurrency safe and can be shared among clones.
 		indexFd: s.indexFd,
 		dataFd:  s.dataFd,
+		// Decrypting readers are concurrency safe as well.
+		indexReader: s.indexReader,
+		dataReader:  s.dataReader,
+		// As are decompressors.
+		dataDecompressor: s.dataDecompressor,
 
 		versionChecker: s.versionChecker,
 	}
@@ -521,8 +650,17 @@ func (s *seeker) ConcurrentClone() (ConcurrentDataFileSetSeeker, error) {
 	return seeker, nil
 }
 
+// digestValidatingReader is a reader that computes the digest of what is read.
+type digestValidatingReader interface {
+	io.Reader
+
+	// Validate compares the current digest against the expected digest and returns
+	// an error if they don't match.
+	Validate(expectedDigest uint32) error
+}
+
 func (s *seeker) validateIndexFileDigest(
-	indexFdWithDigest digest.FdWithDigestReader,
+	indexFdWithDigest digestValidatingReader,
 	expectedDigest uint32,
 ) error {
 	// If piecemeal checksumming validation enabled for index entries, do not attempt to validate the
@@ -561,10 +699,18 @@ type ReusableSeekerResources struct {
 	// since the ReusableSeekerResources is only ever used by a single seeker at
 	// a time, we can size this pool such that it almost never has to allocate.
 	decodeIndexEntryBytesPool pool.BytesPool
+	// compressionBuffers hold the data read from compressed data files, it is
+	// a pointer since resources are passed by value.
+	compressionBuffers *seekerCompressionBuffers
 
 	seekerOpenResources reusableSeekerOpenResources
 }
 
+type seekerCompressionBuffers struct {
+	compressed   []byte
+	decompressed []byte
+}
+
 // reusableSeekerOpenResources contains resources used for the Open() method of the seeker.
 type reusableSeekerOpenResources struct {
 	infoFDDigestReader           digest.FdWithDigestReader
@@ -594,6 +740,7 @@ func NewReusableSeekerResources(opts Options) ReusableSeekerResources {
 		byteDecoderStream:         xmsgpack.NewByteDecoderStream(nil),
 		offsetFileReader:          newOffsetFileReader(),
 		decodeIndexEntryBytesPool: newSimpleBytesPool(),
+		compressionBuffers:        &seekerCompressionBuffers{},
 		seekerOpenResources:       newReusableSeekerOpenResources(opts),
 	}
 }
@@ -649,14 +796,14 @@ func (s *simpleBytesPool) Put(b []byte) {
 
 var _ io.Reader = &offsetFileReader{}
 
-// offsetFileReader implements io.Reader() and allows an *os.File to be wrapped
-// such that any calls to Read() are issued at the provided offset. This is used
-// to issue reads to specific portions of the index and data files without having
-// to first call Seek(). This reduces the number of syscalls that need to be made
-// and also allows the fds to be shared among concurrent goroutines since the
-// internal F.D offset managed by the kernel is not being used.
+// offsetFileReader implements io.Reader() and allows an io.ReaderAt such as an
+// *os.File to be wrapped such that any calls to Read() are issued at the provided
+// offset. This is used to issue reads to specific portions of the index and data
+// files without having to first call Seek(). This reduces the number of syscalls
+// that need to be made and also allows the fds to be shared among concurrent
+// goroutines since the internal F.D offset managed by the kernel is not being used.
 type offsetFileReader struct {
-	fd     *os.File
+	fd     io.ReaderAt
 	offset int64
 }
 
@@ -670,7 +817,7 @@ func (p *offsetFileReader) Read(b []byte) (n int, err error) {
 	return n, err
 }
 
-func (p *offsetFileReader) reset(fd *os.File, offset int64) {
+func (p *offsetFileReader) reset(fd io.ReaderAt, offset int64) {
 	p.fd = fd
 	p.offset = offset
 }
@@ -30,6 +30,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/stretchr/testify/assert"
@@ -237,6 +238,41 @@ func TestSeek(t *testing.T) {
 	assert.NoError(t, s.Close())
 }
 
+func TestSeekRecordsIOHealth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(t, err)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	w := newTestWriter(t, filePathPrefix)
+	writeTestData(t, w, 0, testWriterStart, []testEntry{
+		{"foo", nil, []byte{1, 2, 3}},
+	}, persist.FileSetFlushType)
+
+	tracker, err := iohealth.NewTracker(iohealth.NewOptions().SetMinOperations(1))
+	require.NoError(t, err)
+
+	resources := newTestReusableSeekerResources()
+	s := NewSeeker(filePathPrefix, testReaderBufferSize, testReaderBufferSize,
+		testBytesPool, false, testDefaultOpts.SetIOHealthTracker(tracker))
+	require.NoError(t, s.Open(testNs1ID, 0, testWriterStart, 0, resources))
+	defer s.Close()
+
+	data, err := s.SeekByID(ident.StringID("foo"), resources)
+	require.NoError(t, err)
+	data.IncRef()
+	require.Equal(t, []byte{1, 2, 3}, data.Bytes())
+	data.DecRef()
+
+	// Not finding an ID does not count against the volume.
+	_, err = s.SeekByID(ident.StringID("bar"), resources)
+	require.Equal(t, errSeekIDNotFound, err)
+
+	require.Equal(t, []iohealth.VolumeHealth{
+		{Path: filePathPrefix, Score: 1, Healthy: true},
+	}, tracker.Volumes())
+}
+
 // TestSeekIDNotExists is similar to TestSeek, but it covers more edge cases
 // around IDs not existing.
 func TestSeekIDNotExists(t *testing.T) {
@@ -123,6 +123,11 @@ func (w *streamingWriter) Open(opts StreamingWriterOpenOptions) error {
 	if err := w.writer.Open(writerOpts); err != nil {
 		return err
 	}
+	// NB: Series are written straight through to the data file so data files
+	// written by the streaming writer are never compressed.
+	if err := w.writer.dataCompressor.reset(false); err != nil {
+		return err
+	}
 
 	w.currIdx = 0
 	w.indexOffset = 0
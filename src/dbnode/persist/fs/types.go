@@ -29,6 +29,10 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs/compression"
+	"github.com/m3db/m3/src/dbnode/persist/fs/datadirs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/encryption"
+	"github.com/m3db/m3/src/dbnode/persist/fs/iohealth"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/sharding"
@@ -47,6 +51,7 @@ import (
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/mmap"
 	"github.com/m3db/m3/src/x/pool"
+	xratelimit "github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/serialize"
 	xtime "github.com/m3db/m3/src/x/time"
 )
@@ -540,6 +545,42 @@ type Options interface {
 
 	// EncodingOptions returns the encoder options used by the encoder.
 	EncodingOptions() msgpack.LegacyEncodingOptions
+
+	// SetEncryptionOptions sets the options for encrypting fileset files at rest.
+	SetEncryptionOptions(value encryption.Options) Options
+
+	// EncryptionOptions returns the options for encrypting fileset files at rest.
+	EncryptionOptions() encryption.Options
+
+	// SetCompressionOptions sets the options for compressing data files.
+	SetCompressionOptions(value compression.Options) Options
+
+	// CompressionOptions returns the options for compressing data files.
+	CompressionOptions() compression.Options
+
+	// SetDiskRateLimiter sets the limiter of the bytes per second written to
+	// disk or streamed from peers, unlimited by default.
+	SetDiskRateLimiter(value xratelimit.Limiter) Options
+
+	// DiskRateLimiter returns the limiter of the bytes per second written to
+	// disk or streamed from peers.
+	DiskRateLimiter() xratelimit.Limiter
+
+	// SetIOHealthTracker sets the tracker of the IO health of the volumes
+	// fileset files are stored on.
+	SetIOHealthTracker(value iohealth.Tracker) Options
+
+	// IOHealthTracker returns the tracker of the IO health of the volumes
+	// fileset files are stored on.
+	IOHealthTracker() iohealth.Tracker
+
+	// SetDataDirectoryLayout sets the layout of shard directories across
+	// data directories.
+	SetDataDirectoryLayout(value datadirs.Layout) Options
+
+	// DataDirectoryLayout returns the layout of shard directories across
+	// data directories.
+	DataDirectoryLayout() datadirs.Layout
 }
 
 // BlockRetrieverOptions represents the options for block retrieval.
@@ -588,6 +629,15 @@ type BlockRetrieverOptions interface {
 
 	// QueryLimits returns the query limits.
 	QueryLimits() limits.QueryLimits
+
+	// SetDeadlinePriorityThreshold sets how close to its deadline a queued
+	// request must be to be fetched ahead of other queued requests, zero
+	// disables prioritizing requests by deadline.
+	SetDeadlinePriorityThreshold(value time.Duration) BlockRetrieverOptions
+
+	// DeadlinePriorityThreshold returns how close to its deadline a queued
+	// request must be to be fetched ahead of other queued requests.
+	DeadlinePriorityThreshold() time.Duration
 }
 
 // ForEachRemainingFn is the function that is run on each of the remaining
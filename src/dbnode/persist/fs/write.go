@@ -31,6 +31,9 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs/compression"
+	"github.com/m3db/m3/src/dbnode/persist/fs/datadirs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/encryption"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -58,6 +61,8 @@ type writer struct {
 	newFileMode      os.FileMode
 	newDirectoryMode os.FileMode
 
+	dataDirectoryLayout datadirs.Layout
+
 	summariesPercent                float64
 	bloomFilterFalsePositivePercent float64
 	bufferSize                      int
@@ -68,6 +73,11 @@ type writer struct {
 	bloomFilterFdWithDigest    digest.FdWithDigestWriter
 	dataFdWithDigest           digest.FdWithDigestWriter
 	digestFdWithDigestContents digest.FdWithDigestContentsWriter
+	encryptionOpts             encryption.Options
+	indexEncryptionWriter      encryption.Writer
+	dataEncryptionWriter       encryption.Writer
+	compressionOpts            compression.Options
+	dataCompressor             *dataFileCompressor
 	checkpointFilePath         string
 	indexEntries               indexEntries
 
@@ -131,6 +141,17 @@ func NewWriter(opts Options) (DataFileSetWriter, error) {
 		return nil, err
 	}
 	bufferSize := opts.WriterBufferSize()
+	encryptionOpts := opts.EncryptionOptions()
+	var indexEncryptionWriter, dataEncryptionWriter encryption.Writer
+	if encryptionOpts.Enabled() {
+		var err error
+		if indexEncryptionWriter, err = encryption.NewWriter(encryptionOpts); err != nil {
+			return nil, err
+		}
+		if dataEncryptionWriter, err = encryption.NewWriter(encryptionOpts); err != nil {
+			return nil, err
+		}
+	}
 	return &writer{
 		filePathPrefix:                  opts.FilePathPrefix(),
 		newFileMode:                     opts.NewFileMode(),
@@ -144,11 +165,17 @@ func NewWriter(opts Options) (DataFileSetWriter, error) {
 		bloomFilterFdWithDigest:         digest.NewFdWithDigestWriter(bufferSize),
 		dataFdWithDigest:                digest.NewFdWithDigestWriter(bufferSize),
 		digestFdWithDigestContents:      digest.NewFdWithDigestContentsWriter(bufferSize),
+		encryptionOpts:                  encryptionOpts,
+		indexEncryptionWriter:           indexEncryptionWriter,
+		dataEncryptionWriter:            dataEncryptionWriter,
+		compressionOpts:                 opts.CompressionOptions(),
+		dataCompressor:                  newDataFileCompressor(opts),
 		encoder:                         msgpack.NewEncoderWithOptions(opts.EncodingOptions()),
 		digestBuf:                       digest.NewBuffer(),
 		singleCheckedBytes:              make([]checked.Bytes, 1),
 		tagsIterator:                    ident.NewTagsIterator(ident.Tags{}),
 		tagEncoderPool:                  opts.TagEncoderPool(),
+		dataDirectoryLayout:             opts.DataDirectoryLayout(),
 	}, nil
 }
 
@@ -165,6 +192,14 @@ func (w *writer) Open(opts DataWriterOpenOptions) error {
 	)
 	w.reset(opts)
 
+	// NB: Only data files of filesets written at flush are compressed since
+	// snapshots are short lived.
+	compressed := opts.FileSetType == persist.FileSetFlushType &&
+		w.compressionOpts.NamespaceCompressed(namespace)
+	if err := w.dataCompressor.reset(compressed); err != nil {
+		return err
+	}
+
 	var (
 		shardDir            string
 		infoFilepath        string
@@ -179,7 +214,7 @@ func (w *writer) Open(opts DataWriterOpenOptions) error {
 		shardDir = ShardSnapshotsDirPath(w.filePathPrefix, namespace, shard)
 		// Can't do this outside of the switch statement because we need to make sure
 		// the directory exists before calling NextSnapshotFileSetIndex
-		if err := os.MkdirAll(shardDir, w.newDirectoryMode); err != nil {
+		if err := w.ensureShardDir(shardDir, namespace, shard); err != nil {
 			return err
 		}
 
@@ -192,7 +227,7 @@ func (w *writer) Open(opts DataWriterOpenOptions) error {
 		digestFilepath = FilesetPathFromTimeAndIndex(shardDir, blockStart, volumeIndex, DigestFileSuffix)
 	case persist.FileSetFlushType:
 		shardDir = ShardDataDirPath(w.filePathPrefix, namespace, shard)
-		if err := os.MkdirAll(shardDir, w.newDirectoryMode); err != nil {
+		if err := w.ensureShardDir(shardDir, namespace, shard); err != nil {
 			return err
 		}
 
@@ -229,9 +264,46 @@ func (w *writer) Open(opts DataWriterOpenOptions) error {
 	w.dataFdWithDigest.Reset(dataFd)
 	w.digestFdWithDigestContents.Reset(digestFd)
 
+	if w.encryptionOpts.NamespaceEncrypted(namespace) {
+		if err := w.resetEncryption(indexFd, dataFd); err != nil {
+			// NB: Close the files as callers do not close writers that fail to open.
+			_ = xresource.CloseAll(
+				w.infoFdWithDigest,
+				w.indexFdWithDigest,
+				w.summariesFdWithDigest,
+				w.bloomFilterFdWithDigest,
+				w.dataFdWithDigest,
+				w.digestFdWithDigestContents,
+			)
+			return err
+		}
+	}
+
 	return nil
 }
 
+// resetEncryption writes the encryption headers to the index and data files
+// and encrypts all subsequent writes to them, the digests remain those of
+// the plaintext so that they can be validated the same way once decrypted.
+func (w *writer) resetEncryption(indexFd, dataFd *os.File) error {
+	if err := w.indexEncryptionWriter.Reset(indexFd); err != nil {
+		return err
+	}
+	if err := w.dataEncryptionWriter.Reset(dataFd); err != nil {
+		return err
+	}
+	w.indexFdWithDigest.ResetWithWriter(indexFd, w.indexEncryptionWriter)
+	w.dataFdWithDigest.ResetWithWriter(dataFd, w.dataEncryptionWriter)
+	return nil
+}
+
+func (w *writer) ensureShardDir(shardDir string, namespace ident.ID, shard uint32) error {
+	if err := w.dataDirectoryLayout.EnsureShardDirectory(shardDir, namespace, shard); err != nil {
+		return err
+	}
+	return os.MkdirAll(shardDir, w.newDirectoryMode)
+}
+
 func (w *writer) reset(opts DataWriterOpenOptions) {
 	w.blockSize = opts.BlockSize
 	w.start = opts.Identifier.BlockStart
@@ -301,6 +373,10 @@ func (w *writer) writeAll(
 		return nil
 	}
 
+	if w.dataCompressor.enabled {
+		return w.writeAllCompressed(metadata, data, dataChecksum)
+	}
+
 	entry := indexEntryWithMetadata{
 		entry: indexEntry{
 			index:          w.currIdx,
@@ -325,6 +401,65 @@ func (w *writer) writeAll(
 	return nil
 }
 
+// writeAllCompressed writes the data of a series compressed as its own frame,
+// the index entry refers to the frame while its checksum remains that of the
+// uncompressed data so that it can still be compared with those of replicas.
+// The data of the first series is buffered until there is enough of it to
+// train the dictionary that is written at the start of the data file.
+func (w *writer) writeAllCompressed(
+	metadata persist.Metadata,
+	data []checked.Bytes,
+	dataChecksum uint32,
+) error {
+	w.indexEntries = append(w.indexEntries, indexEntryWithMetadata{
+		entry: indexEntry{
+			index:        w.currIdx,
+			dataChecksum: dataChecksum,
+		},
+		metadata: metadata,
+	})
+	w.currIdx++
+
+	entry := len(w.indexEntries) - 1
+	if !w.dataCompressor.trained() {
+		if !w.dataCompressor.buffer(entry, data) {
+			return nil
+		}
+		return w.trainDataCompression()
+	}
+	frame := w.dataCompressor.encode(w.dataCompressor.concat(data))
+	return w.writeCompressedFrame(entry, frame)
+}
+
+// trainDataCompression trains the dictionary of the data file from the
+// buffered data and writes the header followed by the buffered data.
+func (w *writer) trainDataCompression() error {
+	header, err := w.dataCompressor.train()
+	if err != nil {
+		return err
+	}
+	if err := w.writeData(header); err != nil {
+		return err
+	}
+	for i, entry := range w.dataCompressor.pendingEntries {
+		frame := w.dataCompressor.encode(w.dataCompressor.pendingData[i])
+		if err := w.writeCompressedFrame(entry, frame); err != nil {
+			return err
+		}
+		w.dataCompressor.pendingData[i] = nil
+	}
+	w.dataCompressor.pendingEntries = w.dataCompressor.pendingEntries[:0]
+	w.dataCompressor.pendingData = w.dataCompressor.pendingData[:0]
+	w.dataCompressor.pendingSize = 0
+	return nil
+}
+
+func (w *writer) writeCompressedFrame(entry int, frame []byte) error {
+	w.indexEntries[entry].entry.dataFileOffset = w.currOffset
+	w.indexEntries[entry].entry.size = uint32(len(frame))
+	return w.writeData(frame)
+}
+
 func (w *writer) Close() error {
 	err := w.close()
 	if w.err != nil {
@@ -371,6 +506,10 @@ func (w *writer) DeferClose() (persist.DataCloser, error) {
 }
 
 func (w *writer) close() error {
+	if err := w.closeDataCompression(); err != nil {
+		return err
+	}
+
 	if err := w.writeIndexRelatedFiles(); err != nil {
 		return err
 	}
@@ -378,6 +517,18 @@ func (w *writer) close() error {
 	return w.closeWOIndex()
 }
 
+// closeDataCompression writes out the data of series still buffered when
+// fewer than required to train a dictionary were written.
+func (w *writer) closeDataCompression() error {
+	if w.dataCompressor.enabled && !w.dataCompressor.trained() &&
+		len(w.dataCompressor.pendingEntries) > 0 {
+		if err := w.trainDataCompression(); err != nil {
+			return err
+		}
+	}
+	return w.dataCompressor.close()
+}
+
 func (w *writer) closeWOIndex() error {
 	if err := w.digestFdWithDigestContents.WriteDigests(
 		w.infoFdWithDigest.Digest().Sum32(),
@@ -40,3 +40,23 @@ type Options interface {
 	// LimitCheckEvery returns the limit check frequency
 	LimitCheckEvery() int
 }
+
+const (
+	// DiskLimiterName is the name of the limiter of the bytes per second
+	// written to disk or streamed from peers, its budget is shared by the
+	// flush, repair and peer streaming limiters.
+	DiskLimiterName = "disk"
+
+	// FlushLimiterName is the name of the child of the disk limiter limiting
+	// the bytes per second of data persisted by flushes and snapshots.
+	FlushLimiterName = "flush"
+
+	// RepairLimiterName is the name of the child of the disk limiter limiting
+	// the bytes per second of blocks fetched from peers by repairs.
+	RepairLimiterName = "repair"
+
+	// PeerStreamingLimiterName is the name of the child of the disk limiter
+	// limiting the bytes per second of blocks streamed from peers while
+	// bootstrapping.
+	PeerStreamingLimiterName = "peer-streaming"
+)
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	"github.com/m3db/m3/src/dbnode/backup"
+	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	"github.com/m3db/m3/src/dbnode/namespace/kvadmin"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+var errBackupRequiresDynamicCluster = errors.New(
+	"backups require a dynamic cluster configuration")
+
+// startBackup starts the backup coordinator and agent of the node and
+// registers the backup handlers with the mux, returning a function to stop
+// them.
+func startBackup(
+	cfg config.BackupConfiguration,
+	envConfig environment.Configuration,
+	clusterClient clusterclient.Client,
+	kvStore kv.Store,
+	topo topology.Topology,
+	hostID string,
+	filePathPrefix string,
+	mux *http.ServeMux,
+	iOpts instrument.Options,
+) (func(), error) {
+	if clusterClient == nil || kvStore == nil || len(envConfig.Services) == 0 {
+		return nil, errBackupRequiresDynamicCluster
+	}
+	cluster, err := envConfig.Services.SyncCluster()
+	if err != nil {
+		return nil, err
+	}
+	if cluster.Service == nil {
+		return nil, errBackupRequiresDynamicCluster
+	}
+
+	svcs, err := clusterClient.Services(nil)
+	if err != nil {
+		return nil, err
+	}
+	serviceID := services.NewServiceID().
+		SetName(cluster.Service.Service).
+		SetEnvironment(cluster.Service.Env).
+		SetZone(cluster.Service.Zone)
+	leaderService, err := svcs.LeaderService(serviceID, services.NewElectionOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	store := backup.NewKVStore(kvStore, kvconfig.BackupKeyPrefix)
+	opts := backup.NewOptions().
+		SetHostID(hostID).
+		SetFilePathPrefix(filePathPrefix).
+		SetStore(store).
+		SetObjectStore(backup.NewDirObjectStore(cfg.Directory)).
+		SetTopology(topo).
+		SetNamespaceAdminService(kvadmin.NewAdminService(kvStore, kvconfig.NamespacesKey, nil)).
+		SetLeaderService(leaderService).
+		SetInstrumentOptions(iOpts)
+	if cfg.CheckInterval > 0 {
+		opts = opts.SetCheckInterval(cfg.CheckInterval)
+	}
+	if cfg.Timeout > 0 {
+		opts = opts.SetTimeout(cfg.Timeout)
+	}
+
+	coordinator, err := backup.NewCoordinator(opts)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := backup.NewAgent(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := coordinator.Open(); err != nil {
+		return nil, err
+	}
+	if err := agent.Open(); err != nil {
+		_ = coordinator.Close()
+		return nil, err
+	}
+
+	backup.RegisterHandlers(mux, coordinator, store, iOpts.Logger())
+	return func() {
+		_ = agent.Close()
+		_ = coordinator.Close()
+		_ = leaderService.Close()
+	}, nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/m3db/m3/src/dbnode/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRepairQueueDebugLimit = 100
+	maxRepairQueueDebugLimit     = 1000
+)
+
+// repairQueueDebugHandler serves the repair queue so operators can see what
+// will be repaired next and the outcome of recent repairs, the queue is
+// paginated with the offset and limit query parameters since it holds every
+// owned namespace, shard and block start tuple.
+type repairQueueDebugHandler struct {
+	db     storage.Database
+	logger *zap.Logger
+}
+
+func newRepairQueueDebugHandler(
+	db storage.Database,
+	logger *zap.Logger,
+) http.Handler {
+	return repairQueueDebugHandler{db: db, logger: logger}
+}
+
+func (h repairQueueDebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	offset, err := parseRepairQueueDebugParam(r, "offset", 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := parseRepairQueueDebugParam(r, "limit", defaultRepairQueueDebugLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit > maxRepairQueueDebugLimit {
+		limit = maxRepairQueueDebugLimit
+	}
+
+	state := h.db.RepairQueueState(offset, limit)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		h.logger.Error("unable to encode repair queue state", zap.Error(err))
+	}
+}
+
+func parseRepairQueueDebugParam(r *http.Request, name string, defaultValue int) (int, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(str)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, str)
+	}
+	return value, nil
+}
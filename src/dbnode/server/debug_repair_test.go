@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/storage"
+	xtest "github.com/m3db/m3/src/x/test"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRepairQueueDebugHandlerPaginates(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	queue := make([]storage.RepairQueueEntry, 5)
+	for i := range queue {
+		queue[i].Shard = uint32(i)
+	}
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().RepairQueueState(0, defaultRepairQueueDebugLimit).Return(storage.RepairQueueState{
+		Queue:     queue,
+		QueueSize: len(queue),
+	})
+	db.EXPECT().RepairQueueState(1, 2).Return(storage.RepairQueueState{
+		Queue:     queue[1:3],
+		QueueSize: len(queue),
+	})
+	db.EXPECT().RepairQueueState(0, maxRepairQueueDebugLimit).Return(storage.RepairQueueState{
+		Queue:     queue,
+		QueueSize: len(queue),
+	})
+
+	h := newRepairQueueDebugHandler(db, zap.NewNop())
+
+	get := func(url string) (int, storage.RepairQueueState) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		var state storage.RepairQueueState
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &state))
+		}
+		return w.Code, state
+	}
+
+	code, state := get("/debug/repair")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, state.Queue, 5)
+	require.Equal(t, 5, state.QueueSize)
+
+	code, state = get("/debug/repair?offset=1&limit=2")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, state.Queue, 2)
+	require.Equal(t, uint32(1), state.Queue[0].Shard)
+	require.Equal(t, uint32(2), state.Queue[1].Shard)
+	require.Equal(t, 5, state.QueueSize)
+
+	code, state = get("/debug/repair?limit=5000")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, state.Queue, 5)
+
+	code, _ = get("/debug/repair?limit=-1")
+	require.Equal(t, http.StatusBadRequest, code)
+}
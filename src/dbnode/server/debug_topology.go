@@ -0,0 +1,207 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/topology"
+
+	"go.uber.org/zap"
+)
+
+// topologyDebugHandler serves the topology as this node sees it to help
+// troubleshoot shard ownership, indicating whether the placement version the
+// node acts on lags the latest version stored in kv.
+type topologyDebugHandler struct {
+	sync.RWMutex
+
+	hostID       string
+	db           storage.Database
+	placementSvc placement.Service
+	logger       *zap.Logger
+	nowFn        func() time.Time
+
+	topoMap    topology.Map
+	lastUpdate time.Time
+}
+
+type topologyDebugResponse struct {
+	HostID string `json:"hostID"`
+	// PlacementVersion is the version of the placement the node acts on.
+	PlacementVersion int `json:"placementVersion"`
+	// LatestPlacementVersion is the version of the placement in kv, not set
+	// for static topologies or when the placement could not be read.
+	LatestPlacementVersion *int                 `json:"latestPlacementVersion,omitempty"`
+	LatestPlacementError   string               `json:"latestPlacementError,omitempty"`
+	PlacementVersionStale  bool                 `json:"placementVersionStale"`
+	LastUpdate             string               `json:"lastUpdate"`
+	SinceLastUpdate        string               `json:"sinceLastUpdate"`
+	Shards                 []topologyDebugShard `json:"shards"`
+}
+
+type topologyDebugShard struct {
+	ID uint32 `json:"id"`
+	// State is the state of the shard in the placement, not set for shards
+	// no longer placed on the node that the database still holds.
+	State string `json:"state,omitempty"`
+	// Assigned is whether the database holds the shard, the database applies
+	// placement updates asynchronously.
+	Assigned bool `json:"assigned"`
+}
+
+func newTopologyDebugHandler(
+	hostID string,
+	topo topology.Topology,
+	db storage.Database,
+	placementSvc placement.Service,
+	logger *zap.Logger,
+) (*topologyDebugHandler, error) {
+	watch, err := topo.Watch()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &topologyDebugHandler{
+		hostID:       hostID,
+		db:           db,
+		placementSvc: placementSvc,
+		logger:       logger,
+		nowFn:        time.Now,
+	}
+	<-watch.C()
+	h.update(watch.Get())
+
+	go func() {
+		for range watch.C() {
+			h.update(watch.Get())
+		}
+	}()
+	return h, nil
+}
+
+func (h *topologyDebugHandler) update(topoMap topology.Map) {
+	h.Lock()
+	h.topoMap = topoMap
+	h.lastUpdate = h.nowFn()
+	h.Unlock()
+}
+
+func (h *topologyDebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.RLock()
+	topoMap, lastUpdate := h.topoMap, h.lastUpdate
+	h.RUnlock()
+
+	resp := topologyDebugResponse{
+		HostID:           h.hostID,
+		PlacementVersion: topoMap.Version(),
+		LastUpdate:       lastUpdate.UTC().Format(time.RFC3339Nano),
+		SinceLastUpdate:  h.nowFn().Sub(lastUpdate).String(),
+		Shards:           h.shards(topoMap),
+	}
+
+	if h.placementSvc != nil {
+		p, err := h.placementSvc.Placement()
+		if err != nil {
+			resp.LatestPlacementError = err.Error()
+		} else {
+			latest := p.Version()
+			resp.LatestPlacementVersion = &latest
+			resp.PlacementVersionStale = latest != resp.PlacementVersion
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("unable to encode topology debug response", zap.Error(err))
+	}
+}
+
+func (h *topologyDebugHandler) shards(topoMap topology.Map) []topologyDebugShard {
+	assigned := make(map[uint32]struct{})
+	for _, id := range h.db.ShardSet().AllIDs() {
+		assigned[id] = struct{}{}
+	}
+
+	var shards []topologyDebugShard
+	if hostShardSet, ok := topoMap.LookupHostShardSet(h.hostID); ok {
+		for _, s := range hostShardSet.ShardSet().All() {
+			_, isAssigned := assigned[s.ID()]
+			delete(assigned, s.ID())
+			shards = append(shards, topologyDebugShard{
+				ID:       s.ID(),
+				State:    s.State().String(),
+				Assigned: isAssigned,
+			})
+		}
+	}
+	for id := range assigned {
+		shards = append(shards, topologyDebugShard{ID: id, Assigned: true})
+	}
+
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].ID < shards[j].ID
+	})
+	return shards
+}
+
+// topologyPlacementService returns the placement service backing a dynamic
+// topology, nil for static topologies.
+func topologyPlacementService(
+	envConfig environment.Configuration,
+	clusterClient clusterclient.Client,
+	logger *zap.Logger,
+) placement.Service {
+	if clusterClient == nil || len(envConfig.Services) == 0 {
+		return nil
+	}
+
+	cluster, err := envConfig.Services.SyncCluster()
+	if err != nil || cluster.Service == nil {
+		return nil
+	}
+
+	svcs, err := clusterClient.Services(nil)
+	if err != nil {
+		logger.Warn("unable to create services client for topology debug", zap.Error(err))
+		return nil
+	}
+
+	serviceID := services.NewServiceID().
+		SetName(cluster.Service.Service).
+		SetEnvironment(cluster.Service.Env).
+		SetZone(cluster.Service.Zone)
+	placementSvc, err := svcs.PlacementService(serviceID, placement.NewOptions())
+	if err != nil {
+		logger.Warn("unable to create placement service for topology debug", zap.Error(err))
+		return nil
+	}
+	return placementSvc
+}
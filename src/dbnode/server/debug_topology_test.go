@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/placement"
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/topology"
+	xtest "github.com/m3db/m3/src/x/test"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestShardSet(t *testing.T, state shard.State, ids ...uint32) sharding.ShardSet {
+	shardSet, err := sharding.NewShardSet(sharding.NewShards(ids, state),
+		sharding.DefaultHashFn(4))
+	require.NoError(t, err)
+	return shardSet
+}
+
+func TestTopologyDebugHandler(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	topo := topology.NewStaticTopology(topology.NewStaticOptions().
+		SetReplicas(1).
+		SetShardSet(newTestShardSet(t, shard.Available, 0, 1, 2, 3)).
+		SetHostShardSets([]topology.HostShardSet{
+			topology.NewHostShardSet(topology.NewHost("a", "a:9000"),
+				newTestShardSet(t, shard.Available, 0, 1)),
+			topology.NewHostShardSet(topology.NewHost("b", "b:9000"),
+				newTestShardSet(t, shard.Initializing, 2, 3)),
+		}).
+		SetVersion(3))
+
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().ShardSet().Return(newTestShardSet(t, shard.Available, 1, 2)).AnyTimes()
+
+	placementSvc := placement.NewMockService(ctrl)
+	placementSvc.EXPECT().Placement().Return(placement.NewPlacement().SetVersion(4), nil)
+
+	h, err := newTopologyDebugHandler("a", topo, db, placementSvc, zap.NewNop())
+	require.NoError(t, err)
+	lastUpdate := h.lastUpdate
+	h.nowFn = func() time.Time {
+		return lastUpdate.Add(time.Minute)
+	}
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/topology", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp topologyDebugResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, "a", resp.HostID)
+	require.Equal(t, 3, resp.PlacementVersion)
+	require.NotNil(t, resp.LatestPlacementVersion)
+	require.Equal(t, 4, *resp.LatestPlacementVersion)
+	require.True(t, resp.PlacementVersionStale)
+	require.Equal(t, "1m0s", resp.SinceLastUpdate)
+	require.Equal(t, []topologyDebugShard{
+		{ID: 0, State: "Available", Assigned: false},
+		{ID: 1, State: "Available", Assigned: true},
+		{ID: 2, Assigned: true},
+	}, resp.Shards)
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/topology"
+	xdocs "github.com/m3db/m3/src/x/docs"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	xos "github.com/m3db/m3/src/x/os"
+
+	"go.uber.org/zap"
+)
+
+const preflightClockSkewRequestTimeout = 5 * time.Second
+
+// errPreflightCheckSkipped is returned by checks that cannot run in the
+// current environment.
+var errPreflightCheckSkipped = errors.New("preflight check skipped")
+
+// preflightCheck is a check run at startup before the database is created.
+type preflightCheck struct {
+	name string
+	fn   func() error
+}
+
+// runPreflightChecks runs all the checks and returns the combined error of
+// the checks that failed.
+func runPreflightChecks(checks []preflightCheck, logger *zap.Logger) error {
+	var multiErr xerrors.MultiError
+	for _, check := range checks {
+		err := check.fn()
+		switch {
+		case err == nil:
+			logger.Info("preflight check passed", zap.String("check", check.name))
+		case errors.Is(err, errPreflightCheckSkipped):
+			logger.Warn("preflight check skipped",
+				zap.String("check", check.name), zap.Error(err))
+		default:
+			logger.Error("preflight check failed",
+				zap.String("check", check.name), zap.Error(err))
+			multiErr = multiErr.Add(fmt.Errorf("preflight check %s failed: %w",
+				check.name, err))
+		}
+	}
+	return multiErr.FinalError()
+}
+
+// newLocalPreflightChecks returns the checks of the local host that can run
+// before connecting to the cluster.
+func newLocalPreflightChecks(
+	cfg config.PreflightConfiguration,
+	filePathPrefix string,
+	dirs []string,
+	newDirectoryMode os.FileMode,
+) []preflightCheck {
+	return []preflightCheck{
+		{
+			name: "filesystem-permissions",
+			fn: func() error {
+				return checkDirectoriesWritable(dirs, newDirectoryMode)
+			},
+		},
+		{
+			name: "disk-space",
+			fn: func() error {
+				return checkFreeDiskSpace(filePathPrefix, cfg.MinFreeDiskBytesOrDefault())
+			},
+		},
+		{
+			name: "process-limits",
+			fn: func() error {
+				return checkPreflightProcessLimits(cfg)
+			},
+		},
+	}
+}
+
+// newClusterPreflightChecks returns the checks that require the cluster.
+func newClusterPreflightChecks(
+	cfg config.PreflightConfiguration,
+	etcdEndpoints []string,
+	hostID string,
+	topoMap topology.Map,
+) []preflightCheck {
+	checks := []preflightCheck{
+		{
+			name: "clock-skew",
+			fn: func() error {
+				client := &http.Client{Timeout: preflightClockSkewRequestTimeout}
+				return checkClockSkew(client, etcdEndpoints, cfg.MaxClockSkewOrDefault(), time.Now)
+			},
+		},
+	}
+	if cfg.RequirePlacementMembership {
+		checks = append(checks, preflightCheck{
+			name: "placement-membership",
+			fn: func() error {
+				return checkPlacementMembership(topoMap, hostID)
+			},
+		})
+	}
+	return checks
+}
+
+func checkDirectoriesWritable(dirs []string, newDirectoryMode os.FileMode) error {
+	var multiErr xerrors.MultiError
+	for _, dir := range dirs {
+		if err := checkDirectoryWritable(dir, newDirectoryMode); err != nil {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"directory %s is not writable, ensure it exists on a read-write "+
+					"mount and is owned by the user running dbnode: %w", dir, err))
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func checkDirectoryWritable(dir string, newDirectoryMode os.FileMode) error {
+	if err := os.MkdirAll(dir, newDirectoryMode); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, ".preflight-")
+	if err != nil {
+		return err
+	}
+	_, writeErr := f.Write([]byte("preflight"))
+	closeErr := f.Close()
+	removeErr := os.Remove(f.Name())
+	if writeErr != nil {
+		return writeErr
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	return removeErr
+}
+
+func checkFreeDiskSpace(path string, minFreeBytes uint64) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("%w: unable to determine disk usage on %s",
+			errPreflightCheckSkipped, runtime.GOOS)
+	}
+
+	usage, err := xos.GetDiskUsage(path)
+	if err != nil {
+		return fmt.Errorf("unable to determine disk usage of %s: %w", path, err)
+	}
+	if usage.AvailableBytes < minFreeBytes {
+		return fmt.Errorf(
+			"available disk space of %s (%d bytes) is below the required %d bytes "+
+				"for commit logs and filesets, free up space or increase the volume size",
+			path, usage.AvailableBytes, minFreeBytes)
+	}
+	return nil
+}
+
+func checkPreflightProcessLimits(cfg config.PreflightConfiguration) error {
+	if ok, msg := canValidateProcessLimits(); !ok {
+		return fmt.Errorf("%w: %s", errPreflightCheckSkipped, msg)
+	}
+
+	limits, err := xos.GetProcessLimits()
+	if err != nil {
+		return fmt.Errorf("unable to determine process limits: %w", err)
+	}
+
+	var (
+		multiErr  xerrors.MultiError
+		minNoFile = cfg.MinNoFileOrDefault()
+		minMaps   = cfg.MinVMMaxMapCountOrDefault()
+		docsURL   = xdocs.Path("operational_guide/kernel_configuration")
+	)
+	if limits.NoFileCurr < minNoFile {
+		multiErr = multiErr.Add(fmt.Errorf(
+			"RLIMIT_NOFILE(%d) is below the required %d, raise it with ulimit -n "+
+				"or LimitNOFILE in the service unit, see %s",
+			limits.NoFileCurr, minNoFile, docsURL))
+	}
+	if minMemlock := cfg.MinMemlockBytes; minMemlock > 0 && limits.MemlockCurr < minMemlock {
+		multiErr = multiErr.Add(fmt.Errorf(
+			"RLIMIT_MEMLOCK(%d) is below the required %d, raise it with ulimit -l "+
+				"or LimitMEMLOCK in the service unit",
+			limits.MemlockCurr, minMemlock))
+	}
+	if limits.VMMaxMapCount < minMaps {
+		multiErr = multiErr.Add(fmt.Errorf(
+			"vm.max_map_count(%d) is below the required %d, raise it with "+
+				"sysctl -w vm.max_map_count=%d, see %s",
+			limits.VMMaxMapCount, minMaps, minMaps, docsURL))
+	}
+	return multiErr.FinalError()
+}
+
+// checkClockSkew compares the local clock with the Date header returned by
+// the etcd peers, failing if any reachable peer is skewed beyond the max.
+func checkClockSkew(
+	client *http.Client,
+	endpoints []string,
+	maxSkew time.Duration,
+	nowFn func() time.Time,
+) error {
+	if len(endpoints) == 0 {
+		return fmt.Errorf("%w: no etcd endpoints without TLS to compare clocks with",
+			errPreflightCheckSkipped)
+	}
+
+	var (
+		multiErr  xerrors.MultiError
+		reachable int
+	)
+	for _, endpoint := range endpoints {
+		url := endpoint
+		if !strings.Contains(url, "://") {
+			url = "http://" + url
+		}
+
+		start := nowFn()
+		resp, err := client.Get(strings.TrimSuffix(url, "/") + "/version")
+		if err != nil {
+			continue
+		}
+		end := nowFn()
+		resp.Body.Close()
+		reachable++
+
+		remote, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"etcd endpoint %s returned no valid date: %w", endpoint, err))
+			continue
+		}
+
+		// NB: Date headers have second resolution so allow for truncation.
+		local := start.Add(end.Sub(start) / 2)
+		skew := local.Sub(remote)
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > maxSkew+time.Second {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"local clock is skewed by %v from etcd endpoint %s which exceeds the "+
+					"max allowed %v, ensure NTP is running and synchronized",
+				skew, endpoint, maxSkew))
+		}
+	}
+
+	if reachable == 0 {
+		return fmt.Errorf("none of the etcd endpoints %v are reachable, "+
+			"ensure etcd is running and reachable from this host", endpoints)
+	}
+	return multiErr.FinalError()
+}
+
+func checkPlacementMembership(topoMap topology.Map, hostID string) error {
+	if _, ok := topoMap.LookupHostShardSet(hostID); !ok {
+		return fmt.Errorf(
+			"host %s is not part of the placement, add it to the placement "+
+				"or check that the configured host ID matches the placement instance ID",
+			hostID)
+	}
+	return nil
+}
+
+// preflightEtcdEndpoints returns the endpoints of the etcd clusters the node
+// connects to that do not use TLS.
+func preflightEtcdEndpoints(envConfig environment.Configuration) []string {
+	var endpoints []string
+	for _, cluster := range envConfig.Services {
+		if cluster == nil || cluster.Service == nil {
+			continue
+		}
+		for _, etcdCluster := range cluster.Service.ETCDClusters {
+			if etcdCluster.TLS != nil {
+				continue
+			}
+			endpoints = append(endpoints, etcdCluster.Endpoints...)
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/topology"
+	xtest "github.com/m3db/m3/src/x/test"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRunPreflightChecks(t *testing.T) {
+	var ran []string
+	check := func(name string, err error) preflightCheck {
+		return preflightCheck{
+			name: name,
+			fn: func() error {
+				ran = append(ran, name)
+				return err
+			},
+		}
+	}
+
+	err := runPreflightChecks([]preflightCheck{
+		check("passes", nil),
+		check("skipped", fmt.Errorf("%w: not supported", errPreflightCheckSkipped)),
+		check("fails-a", errors.New("a")),
+		check("fails-b", errors.New("b")),
+	}, zap.NewNop())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "preflight check fails-a failed: a")
+	require.Contains(t, err.Error(), "preflight check fails-b failed: b")
+	require.NotContains(t, err.Error(), "skipped")
+	require.Equal(t, []string{"passes", "skipped", "fails-a", "fails-b"}, ran)
+
+	require.NoError(t, runPreflightChecks([]preflightCheck{
+		check("passes", nil),
+	}, zap.NewNop()))
+}
+
+func TestCheckDirectoriesWritable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preflight")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	commitLogsDir := filepath.Join(dir, "commitlogs")
+	require.NoError(t, checkDirectoriesWritable([]string{dir, commitLogsDir}, 0755))
+
+	// Directories are created if missing and left empty.
+	files, err := ioutil.ReadDir(commitLogsDir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	// A path under a regular file can never be created.
+	file := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(file, nil, 0644))
+	err = checkDirectoriesWritable([]string{filepath.Join(file, "dir")}, 0755)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not writable")
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	var serverNowUnix int64
+	setServerNow := func(v time.Time) { atomic.StoreInt64(&serverNowUnix, v.Unix()) }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverNow := time.Unix(atomic.LoadInt64(&serverNowUnix), 0)
+		w.Header().Set("Date", serverNow.UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	var (
+		now   = time.Now().Truncate(time.Second)
+		nowFn = func() time.Time { return now }
+	)
+
+	setServerNow(now.Add(2 * time.Second))
+	require.NoError(t, checkClockSkew(server.Client(),
+		[]string{server.URL}, 5*time.Second, nowFn))
+
+	setServerNow(now.Add(-time.Minute))
+	err := checkClockSkew(server.Client(), []string{server.URL}, 5*time.Second, nowFn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "local clock is skewed by 1m0s")
+
+	// Endpoints without a scheme default to http.
+	setServerNow(now)
+	require.NoError(t, checkClockSkew(server.Client(),
+		[]string{server.Listener.Addr().String()}, 5*time.Second, nowFn))
+
+	// Unreachable endpoints are ignored as long as one endpoint is reachable.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	require.NoError(t, checkClockSkew(server.Client(),
+		[]string{closed.URL, server.URL}, 5*time.Second, nowFn))
+	require.Error(t, checkClockSkew(server.Client(),
+		[]string{closed.URL}, 5*time.Second, nowFn))
+
+	err = checkClockSkew(server.Client(), nil, 5*time.Second, nowFn)
+	require.True(t, errors.Is(err, errPreflightCheckSkipped))
+}
+
+func TestCheckPlacementMembership(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	topoMap := topology.NewMockMap(ctrl)
+	topoMap.EXPECT().LookupHostShardSet("host1").Return(nil, true)
+	topoMap.EXPECT().LookupHostShardSet("host2").Return(nil, false)
+
+	require.NoError(t, checkPlacementMembership(topoMap, "host1"))
+	err := checkPlacementMembership(topoMap, "host2")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "host host2 is not part of the placement")
+}
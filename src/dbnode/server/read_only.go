@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"github.com/m3db/m3/src/dbnode/namespace"
+)
+
+// readOnlyNamespaceInitializer restricts the namespaces served by a read-only
+// node to the configured set, namespaces not in the set are never created
+// and so are neither bootstrapped nor served.
+type readOnlyNamespaceInitializer struct {
+	initializer namespace.Initializer
+	ids         map[string]struct{}
+}
+
+func newReadOnlyNamespaceInitializer(
+	initializer namespace.Initializer,
+	ids []string,
+) namespace.Initializer {
+	if len(ids) == 0 {
+		return initializer
+	}
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return readOnlyNamespaceInitializer{
+		initializer: initializer,
+		ids:         set,
+	}
+}
+
+func (i readOnlyNamespaceInitializer) Init() (namespace.Registry, error) {
+	registry, err := i.initializer.Init()
+	if err != nil {
+		return nil, err
+	}
+	return readOnlyNamespaceRegistry{Registry: registry, ids: i.ids}, nil
+}
+
+type readOnlyNamespaceRegistry struct {
+	namespace.Registry
+	ids map[string]struct{}
+}
+
+func (r readOnlyNamespaceRegistry) Watch() (namespace.Watch, error) {
+	watch, err := r.Registry.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return readOnlyNamespaceWatch{Watch: watch, ids: r.ids}, nil
+}
+
+type readOnlyNamespaceWatch struct {
+	namespace.Watch
+	ids map[string]struct{}
+}
+
+func (w readOnlyNamespaceWatch) Get() namespace.Map {
+	nsMap := w.Watch.Get()
+	if nsMap == nil {
+		return nil
+	}
+
+	var metadatas []namespace.Metadata
+	for _, md := range nsMap.Metadatas() {
+		if _, ok := w.ids[md.ID().String()]; ok {
+			metadatas = append(metadatas, md)
+		}
+	}
+	if len(metadatas) == 0 {
+		// None of the namespaces served are registered yet.
+		return nil
+	}
+
+	// NB: the namespaces are a subset of a valid map so cannot be invalid.
+	filtered, err := namespace.NewMap(metadatas)
+	if err != nil {
+		return nil
+	}
+	return filtered
+}
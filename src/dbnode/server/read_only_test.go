@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyNamespaceInitializer(t *testing.T) {
+	var metadatas []namespace.Metadata
+	for _, id := range []string{"metrics", "metrics_archive", "metrics_1h"} {
+		md, err := namespace.NewMetadata(ident.StringID(id), namespace.NewOptions())
+		require.NoError(t, err)
+		metadatas = append(metadatas, md)
+	}
+	initializer := namespace.NewStaticInitializer(metadatas)
+
+	readNamespaces := func(initializer namespace.Initializer) []string {
+		registry, err := initializer.Init()
+		require.NoError(t, err)
+		watch, err := registry.Watch()
+		require.NoError(t, err)
+
+		nsMap := watch.Get()
+		if nsMap == nil {
+			return nil
+		}
+		var ids []string
+		for _, md := range nsMap.Metadatas() {
+			ids = append(ids, md.ID().String())
+		}
+		return ids
+	}
+
+	require.Equal(t, []string{"metrics", "metrics_archive", "metrics_1h"},
+		readNamespaces(newReadOnlyNamespaceInitializer(initializer, nil)))
+	require.Equal(t, []string{"metrics_archive", "metrics_1h"},
+		readNamespaces(newReadOnlyNamespaceInitializer(initializer,
+			[]string{"metrics_1h", "metrics_archive"})))
+	require.Nil(t, readNamespaces(newReadOnlyNamespaceInitializer(initializer,
+		[]string{"unknown"})))
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/x/ident"
+
+	"go.uber.org/zap"
+)
+
+// readRepairMaxClearConflicts is the number of times clearing applied
+// divergences is retried when clients published divergences concurrently.
+const readRepairMaxClearConflicts = 5
+
+// kvWatchReadRepairDivergence applies the read repair divergences published to
+// KV by clients to the repair queue so that divergent blocks being actively
+// queried are prioritized for repair.
+func kvWatchReadRepairDivergence(
+	store kv.Store,
+	logger *zap.Logger,
+	db storage.Database,
+) {
+	watch, err := store.Watch(kvconfig.ReadRepairDivergenceKey)
+	if err != nil {
+		logger.Error("could not watch read repair divergences", zap.Error(err))
+		return
+	}
+
+	go func() {
+		var (
+			protoValue = &commonpb.StringArrayProto{}
+			applier    = newReadRepairDivergenceApplier(store, logger, db)
+		)
+		for range watch.C() {
+			newValue := watch.Get()
+			if newValue == nil {
+				continue
+			}
+			if err := newValue.Unmarshal(protoValue); err != nil {
+				logger.Warn("unable to parse read repair divergences", zap.Error(err))
+				continue
+			}
+			applier.apply(protoValue.Values)
+		}
+	}()
+}
+
+// readRepairDivergenceApplier applies published read repair divergences to
+// the repair queue at most once and clears them from KV once applied.
+type readRepairDivergenceApplier struct {
+	store  kv.Store
+	logger *zap.Logger
+	db     storage.Database
+	// applied are the divergences applied but not yet cleared from KV.
+	applied map[string]struct{}
+}
+
+func newReadRepairDivergenceApplier(
+	store kv.Store,
+	logger *zap.Logger,
+	db storage.Database,
+) *readRepairDivergenceApplier {
+	return &readRepairDivergenceApplier{
+		store:   store,
+		logger:  logger,
+		db:      db,
+		applied: make(map[string]struct{}),
+	}
+}
+
+// apply applies the published divergences that have not been applied yet and
+// belong to shards this node owns, then clears the applied divergences.
+// NB: the other replicas of a shard may not see a divergence once cleared,
+// however a divergence that remains after this node repairs the block is
+// detected by reads again and published anew.
+func (a *readRepairDivergenceApplier) apply(published []string) {
+	publishedSet := make(map[string]struct{}, len(published))
+	for _, value := range published {
+		publishedSet[value] = struct{}{}
+	}
+	// Forget divergences cleared by other nodes so that they are applied
+	// again if they are published again.
+	for value := range a.applied {
+		if _, ok := publishedSet[value]; !ok {
+			delete(a.applied, value)
+		}
+	}
+
+	shardSet := a.db.ShardSet()
+	for _, value := range published {
+		if _, ok := a.applied[value]; ok {
+			continue
+		}
+		divergence, err := client.ParseReadRepairDivergence(value)
+		if err != nil {
+			a.logger.Warn("unable to parse read repair divergence", zap.Error(err))
+			continue
+		}
+		if _, err := shardSet.LookupStateByID(divergence.Shard); err != nil {
+			// Left for the nodes that own the shard to apply and clear.
+			continue
+		}
+		a.db.ReportReadRepairDivergence(ident.StringID(divergence.Namespace),
+			divergence.Shard, divergence.BlockStart)
+		a.applied[value] = struct{}{}
+	}
+
+	if len(a.applied) == 0 {
+		return
+	}
+	if err := a.clear(); err != nil {
+		// NB: the divergences remain applied and are cleared by a later update.
+		a.logger.Warn("unable to clear applied read repair divergences", zap.Error(err))
+		return
+	}
+	a.applied = make(map[string]struct{})
+}
+
+// clear removes the applied divergences from the ones published to KV, the
+// divergences are only set if no client published divergences since they
+// were read.
+func (a *readRepairDivergenceApplier) clear() error {
+	var err error
+	for i := 0; i < readRepairMaxClearConflicts; i++ {
+		value, getErr := a.store.Get(kvconfig.ReadRepairDivergenceKey)
+		if getErr == kv.ErrNotFound {
+			return nil
+		}
+		if getErr != nil {
+			return getErr
+		}
+		var published commonpb.StringArrayProto
+		if err := value.Unmarshal(&published); err != nil {
+			return err
+		}
+
+		remaining := make([]string, 0, len(published.Values))
+		for _, v := range published.Values {
+			if _, ok := a.applied[v]; !ok {
+				remaining = append(remaining, v)
+			}
+		}
+		if len(remaining) == len(published.Values) {
+			return nil
+		}
+
+		_, err = a.store.CheckAndSet(kvconfig.ReadRepairDivergenceKey, value.Version(),
+			&commonpb.StringArrayProto{Values: remaining})
+		if err != kv.ErrVersionMismatch {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/x/ident"
+	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReadRepairDivergenceApplierAppliesOnceAndClears(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	shardSet, err := sharding.NewShardSet(sharding.NewShards([]uint32{0}, shard.Available),
+		sharding.DefaultHashFn(1))
+	require.NoError(t, err)
+
+	var (
+		store  = mem.NewStore()
+		owned  = client.ReadRepairDivergence{Namespace: "ns", Shard: 0, BlockStart: xtime.UnixNano(10)}
+		other  = client.ReadRepairDivergence{Namespace: "ns", Shard: 1, BlockStart: xtime.UnixNano(10)}
+		values = []string{owned.String(), other.String()}
+	)
+	publish := func(values []string) {
+		_, err := store.Set(kvconfig.ReadRepairDivergenceKey,
+			&commonpb.StringArrayProto{Values: values})
+		require.NoError(t, err)
+	}
+	published := func() []string {
+		value, err := store.Get(kvconfig.ReadRepairDivergenceKey)
+		require.NoError(t, err)
+		var proto commonpb.StringArrayProto
+		require.NoError(t, value.Unmarshal(&proto))
+		return proto.Values
+	}
+
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().ShardSet().Return(shardSet).AnyTimes()
+	db.EXPECT().ReportReadRepairDivergence(ident.NewIDMatcher("ns"),
+		uint32(0), xtime.UnixNano(10)).Times(2)
+
+	a := newReadRepairDivergenceApplier(store, zap.NewNop(), db)
+
+	// Only the divergence of the owned shard is applied and cleared.
+	publish(values)
+	a.apply(values)
+	require.Equal(t, []string{other.String()}, published())
+	require.Empty(t, a.applied)
+
+	// Updates that do not publish the divergence again do not apply it.
+	a.apply(published())
+
+	// A divergence published again after being cleared is applied again.
+	publish(values)
+	a.apply(values)
+	require.Equal(t, []string{other.String()}, published())
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
 	"github.com/m3db/m3/src/cluster/generated/proto/kvpb"
 	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/cluster/placement"
 	"github.com/m3db/m3/src/cluster/placementhandler"
 	"github.com/m3db/m3/src/cluster/placementhandler/handleroptions"
@@ -50,10 +51,15 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/encoding/proto"
 	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/kvconfig"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/network/peerstream"
+	"github.com/m3db/m3/src/dbnode/network/server/httpjson"
+	hjadmin "github.com/m3db/m3/src/dbnode/network/server/httpjson/admin"
 	hjcluster "github.com/m3db/m3/src/dbnode/network/server/httpjson/cluster"
 	hjnode "github.com/m3db/m3/src/dbnode/network/server/httpjson/node"
+	"github.com/m3db/m3/src/dbnode/network/server/probe"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	ttcluster "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/cluster"
 	ttnode "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/node"
@@ -86,11 +92,13 @@ import (
 	xdebug "github.com/m3db/m3/src/x/debug"
 	extdebug "github.com/m3db/m3/src/x/debug/ext"
 	xdocs "github.com/m3db/m3/src/x/docs"
+	"github.com/m3db/m3/src/x/fault"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/mmap"
 	xos "github.com/m3db/m3/src/x/os"
 	"github.com/m3db/m3/src/x/pool"
+	xratelimit "github.com/m3db/m3/src/x/ratelimit"
 	"github.com/m3db/m3/src/x/serialize"
 	tbinarypool "github.com/m3db/m3/src/x/thrift"
 
@@ -164,6 +172,10 @@ type RunOptions struct {
 	// CustomBuildTags are additional tags to be added to the instrument build
 	// reporter.
 	CustomBuildTags map[string]string
+
+	// HTTPAdminAuthenticateFn is an optional hook to authenticate requests
+	// to the node admin REST endpoints.
+	HTTPAdminAuthenticateFn httpjson.AuthenticateFn
 }
 
 // Run runs the server programmatically given a filename for the
@@ -268,10 +280,36 @@ func Run(runOpts RunOptions) {
 	// nolint: errcheck
 	defer fslock.releaseLockfile()
 
+	if preflight := cfg.Preflight; preflight != nil && preflight.Enabled {
+		filePathPrefix := cfg.Filesystem.FilePathPrefixOrDefault()
+		checks := newLocalPreflightChecks(*preflight, filePathPrefix, []string{
+			filePathPrefix,
+			fs.CommitLogsDirPath(filePathPrefix),
+		}, newDirectoryMode)
+		if err := runPreflightChecks(checks, logger); err != nil {
+			logger.Fatal("local preflight checks failed", zap.Error(err))
+		}
+	}
+
 	go bgValidateProcessLimits(logger)
 	debug.SetGCPercent(cfg.GCPercentageOrDefault())
 
 	defaultServeMux := http.NewServeMux()
+
+	// Enable fault injection before any component that injects faults starts
+	// so that integration tests can exercise recovery paths from startup.
+	if faultCfg := cfg.FaultInjection; faultCfg != nil && faultCfg.Enabled {
+		injector, err := faultCfg.NewInjector()
+		if err != nil {
+			logger.Fatal("could not create fault injector", zap.Error(err))
+		}
+		fault.Enable(injector)
+		defer fault.Disable()
+		defaultServeMux.Handle(fault.HandlerURL, fault.NewHandler(injector))
+		logger.Warn("fault injection enabled, faults can be set at runtime",
+			zap.String("url", fault.HandlerURL),
+			zap.Int("numFaults", len(faultCfg.Faults)))
+	}
 	scope, _, _, err := cfg.MetricsOrDefault().NewRootScopeAndReporters(
 		instrument.NewRootScopeAndReportersOptions{
 			PrometheusDefaultServeMux: defaultServeMux,
@@ -438,6 +476,7 @@ func Run(runOpts RunOptions) {
 	defer cancel()
 	go mmapReporter.Run(mmapReporterCtx)
 	opts = opts.SetMmapReporter(mmapReporter)
+	opts = opts.SetNamespaceFlushConcurrency(cfg.Filesystem.NamespaceFlushConcurrency())
 
 	runtimeOpts := m3dbruntime.NewOptions().
 		SetPersistRateLimitOptions(ratelimit.NewOptions().
@@ -512,8 +551,19 @@ func Run(runOpts RunOptions) {
 		logger.Info("max index worker time was not set, falling back to default value",
 			zap.Duration("maxWorkerTime", maxWorkerTime))
 	}
-	opts = opts.SetPermitsOptions(permitOptions.SetIndexQueryPermitsManager(
-		permits.NewFixedPermitsManager(maxIdxConcurrency, int64(maxWorkerTime), iOpts)))
+	permitOptions = permitOptions.SetIndexQueryPermitsManager(
+		permits.NewFixedPermitsManager(maxIdxConcurrency, int64(maxWorkerTime), iOpts))
+	if lanes := cfg.Limits.ReadLanes; lanes != nil {
+		logger.Info("read lanes enabled",
+			zap.Int("warmMaxConcurrency", lanes.Warm.MaxConcurrency),
+			zap.Int("warmMaxQueued", lanes.Warm.MaxQueued),
+			zap.Int("coldMaxConcurrency", lanes.Cold.MaxConcurrency),
+			zap.Int("coldMaxQueued", lanes.Cold.MaxQueued))
+		permitOptions = permitOptions.
+			SetWarmReadPermitsManager(newReadLanePermitsManager(lanes.Warm, iOpts)).
+			SetColdReadPermitsManager(newReadLanePermitsManager(lanes.Cold, iOpts))
+	}
+	opts = opts.SetPermitsOptions(permitOptions)
 
 	// Setup postings list cache.
 	var (
@@ -637,10 +687,41 @@ func Run(runOpts RunOptions) {
 	// to both the DB and the blockRetriever.
 	blockLeaseManager := block.NewLeaseManager(nil)
 	opts = opts.SetBlockLeaseManager(blockLeaseManager)
+
+	encryptionOpts, err := cfg.Filesystem.EncryptionOptions()
+	if err != nil {
+		logger.Fatal("could not create fileset encryption options", zap.Error(err))
+	}
+
+	compressionOpts, err := cfg.Filesystem.CompressionOptions()
+	if err != nil {
+		logger.Fatal("could not create fileset compression options", zap.Error(err))
+	}
+
+	fsInstrumentOpts := opts.InstrumentOptions().
+		SetMetricsScope(scope.SubScope("database.fs"))
+	ioHealthTracker, err := cfg.Filesystem.IOHealthTracker(fsInstrumentOpts)
+	if err != nil {
+		logger.Fatal("could not create io health tracker", zap.Error(err))
+	}
+
+	dataDirectoryLayout, err := cfg.Filesystem.DataDirectoryLayout(newDirectoryMode,
+		ioHealthTracker, fsInstrumentOpts)
+	if err != nil {
+		logger.Fatal("could not create data directory layout", zap.Error(err))
+	}
+
+	// The disk rate limiter starts watching kv for rate adjustments once the
+	// kv store is available.
+	diskRateLimiter := cfg.Filesystem.DiskRateLimiter(xratelimit.NewOptions().
+		SetKeyPrefix(kvconfig.RateLimitKeyPrefix).
+		SetClockOptions(opts.ClockOptions()).
+		SetInstrumentOptions(fsInstrumentOpts))
+	defer diskRateLimiter.Close()
+
 	fsopts := fs.NewOptions().
 		SetClockOptions(opts.ClockOptions()).
-		SetInstrumentOptions(opts.InstrumentOptions().
-			SetMetricsScope(scope.SubScope("database.fs"))).
+		SetInstrumentOptions(fsInstrumentOpts).
 		SetFilePathPrefix(cfg.Filesystem.FilePathPrefixOrDefault()).
 		SetNewFileMode(newFileMode).
 		SetNewDirectoryMode(newDirectoryMode).
@@ -656,7 +737,24 @@ func Run(runOpts RunOptions) {
 		SetForceIndexSummariesMmapMemory(cfg.Filesystem.ForceIndexSummariesMmapMemoryOrDefault()).
 		SetForceBloomFilterMmapMemory(cfg.Filesystem.ForceBloomFilterMmapMemoryOrDefault()).
 		SetIndexBloomFilterFalsePositivePercent(cfg.Filesystem.BloomFilterFalsePositivePercentOrDefault()).
-		SetMmapReporter(mmapReporter)
+		SetMmapReporter(mmapReporter).
+		SetEncryptionOptions(encryptionOpts).
+		SetCompressionOptions(compressionOpts).
+		SetDiskRateLimiter(diskRateLimiter).
+		SetIOHealthTracker(ioHealthTracker).
+		SetDataDirectoryLayout(dataDirectoryLayout)
+
+	// Shard directories are moved between data directories before anything
+	// reads or writes them.
+	moved, err := fs.RebalanceShardDirectories(fsopts)
+	if err != nil {
+		logger.Fatal("could not rebalance shard directories across data directories",
+			zap.Error(err))
+	}
+	if moved > 0 {
+		logger.Info("rebalanced shard directories across data directories",
+			zap.Int("moved", moved))
+	}
 
 	var commitLogQueueSize int
 	cfgCommitLog := cfg.CommitLogOrDefault()
@@ -697,9 +795,22 @@ func Run(runOpts RunOptions) {
 		logger.Fatal("could not get pooling policy", zap.Error(err))
 	}
 
+	healthTracker := health.NewNoopTracker()
+	if cfg.Health != nil && cfg.Health.Enabled {
+		healthTracker, err = cfg.Health.NewTracker(opts.InstrumentOptions())
+		if err != nil {
+			logger.Fatal("could not create health tracker", zap.Error(err))
+		}
+		healthCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go evaluateHealth(healthCtx, healthTracker, cfg.Health.EvaluationIntervalOrDefault())
+	}
+
 	opts = withEncodingAndPoolingOptions(cfg, logger, opts, poolingPolicy)
+	opts = opts.SetHealthTracker(healthTracker)
 	opts = opts.SetCommitLogOptions(opts.CommitLogOptions().
 		SetInstrumentOptions(opts.InstrumentOptions()).
+		SetHealthTracker(healthTracker).
 		SetFilesystemOptions(fsopts).
 		SetStrategy(commitlog.StrategyWriteBehind).
 		SetFlushSize(cfgCommitLog.FlushMaxBytes).
@@ -727,6 +838,9 @@ func Run(runOpts RunOptions) {
 			if v := blockRetrieveCfg.CacheBlocksOnRetrieve; v != nil {
 				retrieverOpts = retrieverOpts.SetCacheBlocksOnRetrieve(*v)
 			}
+			if v := blockRetrieveCfg.DeadlinePriorityThreshold; v != nil {
+				retrieverOpts = retrieverOpts.SetDeadlinePriorityThreshold(*v)
+			}
 		}
 		blockRetrieverMgr := block.NewDatabaseBlockRetrieverManager(
 			func(md namespace.Metadata, shardSet sharding.ShardSet) (block.DatabaseBlockRetriever, error) {
@@ -765,6 +879,10 @@ func Run(runOpts RunOptions) {
 		opts = opts.SetForceColdWritesEnabled(*value)
 	}
 
+	if value := cfg.Hashing.ShardIDVerifySampleRate; value != nil {
+		opts = opts.SetShardIDVerifySampleRate(*value)
+	}
+
 	forceColdWrites := opts.ForceColdWritesEnabled()
 	var envCfgResults environment.ConfigureResults
 	if len(envConfig.Statics) == 0 {
@@ -805,7 +923,36 @@ func Run(runOpts RunOptions) {
 		runOpts.KVStoreCh <- syncCfg.KVStore
 	}
 
-	opts = opts.SetNamespaceInitializer(syncCfg.NamespaceInitializer)
+	nsInitializer := syncCfg.NamespaceInitializer
+	if readOnly := cfg.ReadOnly; readOnly != nil && readOnly.Enabled {
+		// Read-only nodes serve the filesets on disk for the configured
+		// namespaces and time ranges, they should be registered in a
+		// placement separate to the nodes taking writes.
+		logger.Info("running in read-only mode",
+			zap.Strings("namespaces", readOnly.NamespaceIDs()))
+		nsInitializer = newReadOnlyNamespaceInitializer(nsInitializer,
+			readOnly.NamespaceIDs())
+		opts = opts.
+			SetReadOnly(true).
+			SetReadOnlyTimeRanges(readOnly.TimeRanges())
+	}
+	opts = opts.SetNamespaceInitializer(nsInitializer)
+
+	// Feature flags toggle behavior at runtime from kv, their effective values
+	// are listed by the debug endpoint.
+	featureFlags := featureflag.NewRegistry(featureflag.NewOptions().
+		SetKVStore(syncCfg.KVStore).
+		SetKeyPrefix(kvconfig.FeatureFlagKeyPrefix).
+		SetInstanceID(hostID).
+		SetInstrumentOptions(iOpts))
+	defer featureFlags.Close()
+	opts = opts.SetFeatureFlags(featureFlags)
+	defaultServeMux.Handle("/debug/feature-flags", featureFlags.Handler())
+
+	if err := diskRateLimiter.Watch(syncCfg.KVStore); err != nil {
+		logger.Fatal("could not watch disk rate limits", zap.Error(err))
+	}
+	defaultServeMux.Handle("/debug/rate-limits", diskRateLimiter.Handler())
 
 	// Set tchannelthrift options.
 	ttopts := tchannelthrift.NewOptions().
@@ -818,7 +965,14 @@ func Run(runOpts RunOptions) {
 		SetMaxOutstandingWriteRequests(cfg.Limits.MaxOutstandingWriteRequests).
 		SetMaxOutstandingReadRequests(cfg.Limits.MaxOutstandingReadRequests).
 		SetQueryLimits(queryLimits).
-		SetPermitsOptions(opts.PermitsOptions())
+		SetPermitsOptions(opts.PermitsOptions()).
+		SetHealthTracker(healthTracker)
+
+	peerStreamCodecs, err := cfg.Client.PeerStream.NewCodecs()
+	if err != nil {
+		logger.Fatal("could not create peer stream codecs", zap.Error(err))
+	}
+	ttopts = ttopts.SetPeerStreamCodecs(peerStreamCodecs)
 
 	// Start servers before constructing the DB so orchestration tools can check health endpoints
 	// before topology is set.
@@ -833,8 +987,24 @@ func Run(runOpts RunOptions) {
 		tchannelOpts.MaxIdleTime = cfg.TChannel.MaxIdleTime
 		tchannelOpts.IdleCheckInterval = cfg.TChannel.IdleCheckInterval
 	}
+
+	// Peer streams are served and fetched with the settings of the client,
+	// the dialer is set on the channel options shared with the admin client.
+	peerStreamServerTLS, err := cfg.Client.PeerStream.ServerTLSConfig()
+	if err != nil {
+		logger.Fatal("could not load peer stream server tls config", zap.Error(err))
+	}
+	peerStreamClientTLS, err := cfg.Client.PeerStream.ClientTLSConfig()
+	if err != nil {
+		logger.Fatal("could not load peer stream client tls config", zap.Error(err))
+	}
+	if peerStreamClientTLS != nil {
+		tchannelOpts.Dialer = peerstream.NewDialFn(peerStreamClientTLS)
+	}
+
 	tchanOpts := ttnode.NewOptions(tchannelOpts).
-		SetInstrumentOptions(opts.InstrumentOptions())
+		SetInstrumentOptions(opts.InstrumentOptions()).
+		SetTLSConfig(peerStreamServerTLS)
 	if fn := runOpts.StorageOptions.TChanChannelFn; fn != nil {
 		tchanOpts = tchanOpts.SetTChanChannelFn(fn)
 	}
@@ -852,9 +1022,34 @@ func Run(runOpts RunOptions) {
 	defer tchannelthriftNodeClose()
 	logger.Info("node tchannelthrift: listening", zap.String("address", listenAddress))
 
+	var probeCfg probe.Configuration
+	if cfg.Probes != nil {
+		probeCfg = *cfg.Probes
+	}
+	probeOpts, err := probeCfg.NewOptions(iOpts)
+	if err != nil {
+		logger.Fatal("could not create probe options", zap.Error(err))
+	}
+	probeChecker, err := probe.NewChecker(service, probeOpts)
+	if err != nil {
+		logger.Fatal("could not create probe checker", zap.Error(err))
+	}
+	if probeCfg.GRPCListenAddress != "" {
+		probeClose, err := probe.NewGRPCServer(probeChecker,
+			probeCfg.GRPCListenAddress, probeOpts).ListenAndServe()
+		if err != nil {
+			logger.Fatal("could not open grpc health interface",
+				zap.String("address", probeCfg.GRPCListenAddress), zap.Error(err))
+		}
+		defer probeClose()
+		logger.Info("node grpc health: listening",
+			zap.String("address", probeCfg.GRPCListenAddress))
+	}
+
 	httpListenAddress := cfg.HTTPNodeListenAddressOrDefault()
 	httpjsonNodeClose, err := hjnode.NewServer(service,
-		httpListenAddress, contextPool, nil).ListenAndServe()
+		httpListenAddress, contextPool, httpjson.NewServerOptions().
+			SetHandlers(probe.NewHandlers(probeChecker))).ListenAndServe()
 	if err != nil {
 		logger.Fatal("could not open httpjson interface",
 			zap.String("address", httpListenAddress), zap.Error(err))
@@ -913,6 +1108,14 @@ func Run(runOpts RunOptions) {
 		logger.Fatal("could not initialize m3db topology", zap.Error(err))
 	}
 
+	if preflight := cfg.Preflight; preflight != nil && preflight.Enabled {
+		checks := newClusterPreflightChecks(*preflight,
+			preflightEtcdEndpoints(envConfig), hostID, topo.Get())
+		if err := runPreflightChecks(checks, logger); err != nil {
+			logger.Fatal("cluster preflight checks failed", zap.Error(err))
+		}
+	}
+
 	var protoEnabled bool
 	if cfg.Proto != nil && cfg.Proto.Enabled {
 		protoEnabled = true
@@ -983,7 +1186,7 @@ func Run(runOpts RunOptions) {
 			repairClients = append(repairClients, clusterClient)
 		}
 	}
-	repairEnabled := len(repairClients) > 0
+	repairEnabled := len(repairClients) > 0 && !opts.ReadOnly()
 	if repairEnabled {
 		repairOpts := opts.RepairOptions().
 			SetAdminClients(repairClients)
@@ -1004,6 +1207,12 @@ func Run(runOpts RunOptions) {
 			if cfg.Repair.Concurrency > 0 {
 				repairOpts = repairOpts.SetRepairShardConcurrency(cfg.Repair.Concurrency)
 			}
+			if cfg.Repair.PriorityRepairsPerRun > 0 {
+				repairOpts = repairOpts.SetPriorityRepairsPerRun(cfg.Repair.PriorityRepairsPerRun)
+			}
+			if cfg.Repair.PriorityRepairsPerSecond > 0 {
+				repairOpts = repairOpts.SetPriorityRepairsPerSecond(cfg.Repair.PriorityRepairsPerSecond)
+			}
 
 			if cfg.Repair.DebugShadowComparisonsPercentage > 0 {
 				// Set conditionally to avoid stomping on the default value of 1.0.
@@ -1027,7 +1236,13 @@ func Run(runOpts RunOptions) {
 	// recent as the one that triggered the bootstrap, if not newer.
 	// See GitHub issue #1013 for more details.
 	topoMapProvider := newTopoMapProvider(topo)
-	bs, err := cfg.Bootstrap.New(
+	bootstrapCfg := cfg.Bootstrap
+	if opts.ReadOnly() {
+		// Read-only nodes have no commit log and never stream from peers.
+		mode := config.FilesystemOnlyBootstrapMode
+		bootstrapCfg.BootstrapMode = &mode
+	}
+	bs, err := bootstrapCfg.New(
 		rsOpts, opts, topoMapProvider, origin, m3dbClient,
 	)
 	if err != nil {
@@ -1082,6 +1297,46 @@ func Run(runOpts RunOptions) {
 	// Now that we've initialized the database we can set it on the service.
 	service.SetDatabase(db)
 
+	if cfg.HTTPAdminListenAddress != nil && *cfg.HTTPAdminListenAddress != "" {
+		httpAdminListenAddress := *cfg.HTTPAdminListenAddress
+		httpAdminOpts := httpjson.NewServerOptions().
+			SetAuthenticateFn(runOpts.HTTPAdminAuthenticateFn)
+		httpjsonAdminClose, err := hjadmin.NewServer(service, db,
+			httpAdminListenAddress, contextPool, httpAdminOpts).ListenAndServe()
+		if err != nil {
+			logger.Fatal("could not open httpjson admin interface",
+				zap.String("address", httpAdminListenAddress), zap.Error(err))
+		}
+		defer httpjsonAdminClose()
+		logger.Info("node httpjson admin: listening",
+			zap.String("address", httpAdminListenAddress))
+	}
+
+	// Expose the repair queue so operators can see what will be repaired next
+	// and the outcome of recent repairs.
+	defaultServeMux.Handle("/debug/repair", newRepairQueueDebugHandler(db, logger))
+
+	// Expose the topology this node acts on to troubleshoot shard ownership.
+	topologyHandler, err := newTopologyDebugHandler(hostID, topo, db,
+		topologyPlacementService(envConfig, syncCfg.ClusterClient, logger), logger)
+	if err != nil {
+		logger.Error("unable to create topology debug handler", zap.Error(err))
+	} else {
+		defaultServeMux.Handle("/debug/topology", topologyHandler)
+	}
+
+	if cfg.Backup != nil && cfg.Backup.Enabled {
+		backupClose, err := startBackup(*cfg.Backup, envConfig, syncCfg.ClusterClient,
+			syncCfg.KVStore, topo, hostID, cfg.Filesystem.FilePathPrefixOrDefault(),
+			defaultServeMux, iOpts)
+		if err != nil {
+			logger.Error("unable to start backups", zap.Error(err))
+		} else {
+			defer backupClose()
+			logger.Info("backups enabled", zap.String("directory", cfg.Backup.Directory))
+		}
+	}
+
 	go func() {
 		if runOpts.BootstrapCh != nil {
 			// Notify on bootstrap chan if specified.
@@ -1111,6 +1366,9 @@ func Run(runOpts RunOptions) {
 			queryLimits.AggregateDocsLimit(),
 			limitOpts,
 		)
+		if opts.RepairEnabled() {
+			kvWatchReadRepairDivergence(syncCfg.KVStore, logger, db)
+		}
 	}()
 
 	// Stop our async watch and now block waiting for the interrupt.
@@ -1207,6 +1465,20 @@ func bgValidateProcessLimits(logger *zap.Logger) {
 	}
 }
 
+func evaluateHealth(ctx context.Context, tracker health.Tracker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tracker.Evaluate()
+		}
+	}
+}
+
 func kvWatchNewSeriesLimitPerShard(
 	store kv.Store,
 	logger *zap.Logger,
@@ -1908,6 +2180,9 @@ func withEncodingAndPoolingOptions(
 			ForceValue:        *forcedValue,
 		})
 	}
+	opts = opts.SetSampleIntervalPolicies(cfg.Transforms.SampleIntervalPolicies())
+	opts = opts.SetCodecStatsSampleRate(cfg.CodecStats.SampleRateOrDefault())
+	opts = opts.SetReadAgeBuckets(cfg.ReadAgeStats.BucketsOrDefault())
 
 	// Set index options.
 	indexOpts := opts.IndexOptions().
@@ -1930,7 +2205,8 @@ func withEncodingAndPoolingOptions(
 		SetAggregateResultsPool(aggregateQueryResultsPool).
 		SetAggregateValuesPool(aggregateQueryValuesPool).
 		SetForwardIndexProbability(cfg.Index.ForwardIndexProbability).
-		SetForwardIndexThreshold(cfg.Index.ForwardIndexThreshold)
+		SetForwardIndexThreshold(cfg.Index.ForwardIndexThreshold).
+		SetTimeWindowCompactionOptions(cfg.Index.TimeWindowCompaction.Options())
 
 	queryResultsPool.Init(func() index.QueryResults {
 		// NB(r): Need to initialize after setting the index opts so
@@ -2267,3 +2543,16 @@ func (r *mmapReporter) ReportUnmap(ctx mmap.Context) error {
 
 	return nil
 }
+
+// newReadLanePermitsManager returns the permits manager of a read lane, or nil
+// if reads in the lane are not limited. Each read holds a single permit.
+func newReadLanePermitsManager(
+	cfg config.ReadLaneConfiguration,
+	iOpts instrument.Options,
+) permits.Manager {
+	if cfg.MaxConcurrency <= 0 {
+		return nil
+	}
+	return permits.NewFixedPermitsManagerWithMaxWaiting(cfg.MaxConcurrency, 1,
+		cfg.MaxQueued, iOpts)
+}
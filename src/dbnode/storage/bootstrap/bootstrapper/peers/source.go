@@ -21,6 +21,7 @@
 package peers
 
 import (
+	stdctx "context"
 	"errors"
 	"fmt"
 	"io"
@@ -36,6 +37,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/ratelimit"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/bootstrapper"
@@ -51,6 +53,7 @@ import (
 	"github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
+	xratelimit "github.com/m3db/m3/src/x/ratelimit"
 	xresource "github.com/m3db/m3/src/x/resource"
 	xsync "github.com/m3db/m3/src/x/sync"
 	xtime "github.com/m3db/m3/src/x/time"
@@ -63,6 +66,7 @@ const readSeriesBlocksWorkerChannelSize = 512
 type peersSource struct {
 	opts              Options
 	newPersistManager func() (persist.Manager, error)
+	limiter           xratelimit.Limiter
 	log               *zap.Logger
 	instrumentation   *instrumentation
 }
@@ -85,6 +89,8 @@ func newPeersSource(opts Options) (bootstrap.Source, error) {
 		newPersistManager: func() (persist.Manager, error) {
 			return fs.NewPersistManager(opts.FilesystemOptions())
 		},
+		limiter: opts.FilesystemOptions().DiskRateLimiter().
+			Child(ratelimit.PeerStreamingLimiterName, xratelimit.Limit{}),
 		log:             instrumentation.log,
 		instrumentation: instrumentation,
 	}, nil
@@ -431,6 +437,15 @@ func (s *peersSource) fetchBootstrapBlocksFromPeers(
 				continue
 			}
 
+			// Peer streaming shares the disk rate limit with flushes and
+			// repairs, throttle before persisting or loading the blocks.
+			if err := s.limiter.WaitN(stdctx.Background(), shardResultSize(shardResult)); err != nil {
+				s.log.Error("could not wait for peer streaming rate limit",
+					zap.Uint32("shard", shard), zap.Error(err))
+				unfulfill(currRange)
+				continue
+			}
+
 			if shouldPersist {
 				persistenceQueue <- persistenceFlush{
 					nsMetadata:  nsMetadata,
@@ -489,6 +504,17 @@ func (s *peersSource) fetchBootstrapBlocksFromPeers(
 	}
 }
 
+// shardResultSize returns the number of bytes of all blocks of a shard result.
+func shardResultSize(shardResult result.ShardResult) int64 {
+	var size int64
+	for _, entry := range shardResult.AllSeries().Iter() { // nolint
+		for _, b := range entry.Value().Blocks.AllBlocks() {
+			size += int64(b.Len())
+		}
+	}
+	return size
+}
+
 func (s *peersSource) logFetchBootstrapBlocksFromPeersOutcome(
 	shard uint32,
 	shardResult result.ShardResult,
@@ -182,6 +182,11 @@ func (m *cleanupManager) WarmFlushCleanup(t xtime.UnixNano) error {
 			"encountered errors when cleaning up snapshot and commitlog files: %w", err))
 	}
 
+	if err := m.updateDataDirectoryCapacity(); err != nil {
+		multiErr = multiErr.Add(fmt.Errorf(
+			"encountered errors when updating data directory capacity: %w", err))
+	}
+
 	return multiErr.FinalError()
 }
 
@@ -234,6 +239,23 @@ func (m *cleanupManager) Report() {
 	}
 }
 
+// updateDataDirectoryCapacity refreshes the capacity of the data directories
+// after files have been cleaned up so that new shards are not placed on data
+// directories that remain full.
+func (m *cleanupManager) updateDataDirectoryCapacity() error {
+	layout := m.opts.CommitLogOptions().FilesystemOptions().DataDirectoryLayout()
+	capacities, err := layout.UpdateCapacity()
+	for _, capacity := range capacities {
+		if capacity.Full {
+			m.logger.Warn("data directory above high watermark after cleanup",
+				zap.String("directory", capacity.Path),
+				zap.Float64("usedFraction", capacity.UsedFraction),
+				zap.Uint64("availableBytes", capacity.AvailableBytes))
+		}
+	}
+	return err
+}
+
 func (m *cleanupManager) deleteInactiveNamespaceFiles(namespaces []databaseNamespace) error {
 	var namespaceDirNames []string
 	filePathPrefix := m.database.Options().CommitLogOptions().FilesystemOptions().FilePathPrefix()
@@ -170,9 +170,18 @@ func NewDatabase(
 		return nil, fmt.Errorf("invalid options: %v", err)
 	}
 
-	commitLog, err := commitlog.NewCommitLog(opts.CommitLogOptions())
-	if err != nil {
-		return nil, err
+	var (
+		commitLog commitlog.CommitLog
+		err       error
+	)
+	if opts.ReadOnly() {
+		// Read-only databases only serve the filesets on disk.
+		commitLog = commitlog.NewNoopCommitLog()
+	} else {
+		commitLog, err = commitlog.NewCommitLog(opts.CommitLogOptions())
+		if err != nil {
+			return nil, err
+		}
 	}
 	if err := commitLog.Open(); err != nil {
 		return nil, err
@@ -857,6 +866,20 @@ func (d *db) WriteTagged(
 	return d.commitLog.Write(ctx, seriesWrite.Series, dp, unit, annotation)
 }
 
+func (d *db) WaitForIndexInserts(ctx context.Context, namespace ident.ID) error {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return err
+	}
+
+	idx, err := n.Index()
+	if err != nil {
+		return err
+	}
+
+	return idx.WaitForInserts(ctx)
+}
+
 func (d *db) BatchWriter(namespace ident.ID, batchSize int) (writes.BatchWriter, error) {
 	n, err := d.namespaceFor(namespace)
 	if err != nil {
@@ -928,7 +951,18 @@ func (d *db) writeBatch(
 			err         error
 		)
 
-		if tagged {
+		if tagged && write.ShardIDSet {
+			seriesWrite, err = n.WriteTaggedWithShardID(
+				ctx,
+				write.Write.Series.ID,
+				write.ShardID,
+				convert.NewEncodedTagsMetadataResolver(write.EncodedTags),
+				write.Write.Datapoint.TimestampNanos,
+				write.Write.Datapoint.Value,
+				write.Write.Unit,
+				write.Write.Annotation,
+			)
+		} else if tagged {
 			seriesWrite, err = n.WriteTagged(
 				ctx,
 				write.Write.Series.ID,
@@ -1034,6 +1068,8 @@ func (d *db) QueryIDs(
 		return index.QueryResult{}, err
 	}
 
+	opts.StartInclusive, opts.EndExclusive = d.readOnlyRange(namespace,
+		opts.StartInclusive, opts.EndExclusive)
 	return n.QueryIDs(ctx, query, opts)
 }
 
@@ -1048,6 +1084,9 @@ func (d *db) AggregateQuery(
 		d.metrics.unknownNamespaceQueryIDs.Inc(1)
 		return index.AggregateQueryResult{}, err
 	}
+	aggResultOpts.QueryOptions.StartInclusive, aggResultOpts.QueryOptions.EndExclusive =
+		d.readOnlyRange(namespace, aggResultOpts.QueryOptions.StartInclusive,
+			aggResultOpts.QueryOptions.EndExclusive)
 
 	ctx, sp, sampled := ctx.StartSampledTraceSpan(tracepoint.DBAggregateQuery)
 	if sampled {
@@ -1077,9 +1116,36 @@ func (d *db) ReadEncoded(
 		return nil, err
 	}
 
+	start, end = d.readOnlyRange(namespace, start, end)
 	return n.ReadEncoded(ctx, id, start, end)
 }
 
+// readOnlyRange clamps a read to the time range served for the namespace
+// when running read-only, reads outside of the range are made empty.
+func (d *db) readOnlyRange(
+	namespace ident.ID,
+	start, end xtime.UnixNano,
+) (xtime.UnixNano, xtime.UnixNano) {
+	ranges := d.opts.ReadOnlyTimeRanges()
+	if len(ranges) == 0 {
+		return start, end
+	}
+	r, ok := ranges[namespace.String()]
+	if !ok {
+		return start, end
+	}
+	if start.Before(r.Start) {
+		start = r.Start
+	}
+	if end.After(r.End) {
+		end = r.End
+	}
+	if !start.Before(end) {
+		end = start
+	}
+	return start, end
+}
+
 func (d *db) FetchBlocks(
 	ctx context.Context,
 	namespace ident.ID,
@@ -1212,6 +1278,31 @@ func (d *db) Repair() error {
 	return d.repairer.Repair()
 }
 
+func (d *db) RepairQueueState(offset, limit int) RepairQueueState {
+	return d.repairer.QueueState(offset, limit)
+}
+
+func (d *db) ReportReadRepairDivergence(
+	namespace ident.ID,
+	shard uint32,
+	blockStart xtime.UnixNano,
+) {
+	d.repairer.ReportReadDivergence(namespace, shard, blockStart)
+}
+
+func (d *db) RegisterImportedVolume(
+	namespace ident.ID,
+	shard uint32,
+	blockStart xtime.UnixNano,
+	volume int,
+) error {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return err
+	}
+	return n.RegisterImportedVolume(shard, blockStart, volume)
+}
+
 func (d *db) Truncate(namespace ident.ID) (int64, error) {
 	n, err := d.namespaceFor(namespace)
 	if err != nil {
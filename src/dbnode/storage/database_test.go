@@ -296,6 +296,45 @@ func TestDatabaseReadEncoded(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestDatabaseReadEncodedReadOnlyTimeRange(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.NewBackground()
+	defer ctx.Close()
+
+	ns := ident.StringID("testns1")
+	rangeEnd := xtime.Now().Truncate(time.Hour)
+	rangeStart := rangeEnd.Add(-2 * time.Hour)
+	d, mapCh, _ := newTestDatabase(t, ctrl, newTestDatabaseOpt{
+		bs:    Bootstrapped,
+		nsMap: testNamespaceMap(t),
+		dbOpt: DefaultTestOptions().
+			SetReadOnly(true).
+			SetReadOnlyTimeRanges(map[string]xtime.Range{
+				ns.String(): {Start: rangeStart, End: rangeEnd},
+			}),
+	})
+	defer func() {
+		close(mapCh)
+	}()
+
+	id := ident.StringID("bar")
+	mockNamespace := NewMockdatabaseNamespace(ctrl)
+	d.namespaces.Set(ns, mockNamespace)
+
+	// Reads are clamped to the range served.
+	mockNamespace.EXPECT().ReadEncoded(ctx, id, rangeStart, rangeEnd).Return(nil, nil)
+	_, err := d.ReadEncoded(ctx, ns, id, rangeStart.Add(-time.Hour), rangeEnd.Add(time.Hour))
+	require.NoError(t, err)
+
+	// Reads outside of the range served are empty.
+	later := rangeEnd.Add(time.Hour)
+	mockNamespace.EXPECT().ReadEncoded(ctx, id, later, later).Return(nil, nil)
+	_, err = d.ReadEncoded(ctx, ns, id, later, later.Add(time.Hour))
+	require.NoError(t, err)
+}
+
 func TestDatabaseFetchBlocksNamespaceNonExistent(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
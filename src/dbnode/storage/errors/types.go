@@ -35,6 +35,32 @@ var (
 	ErrTooPast = xerrors.NewInvalidParamsError(errors.New("datapoint is too far in the past"))
 )
 
+// NewShardNotOwnedError returns a new error indicating a shard is not owned,
+// it is retryable as it occurs during a topology change and the client must
+// retry the request with the updated topology.
+func NewShardNotOwnedError(shardID uint32) error {
+	return xerrors.NewRetryableError(shardNotOwned{shardID})
+}
+
+type shardNotOwned struct {
+	shardID uint32
+}
+
+func (e shardNotOwned) Error() string {
+	return fmt.Sprintf("not responsible for shard %d", e.shardID)
+}
+
+// IsShardNotOwnedError returns true if this is a shard not owned error.
+func IsShardNotOwnedError(err error) bool {
+	for err != nil {
+		if _, ok := err.(shardNotOwned); ok { //nolint:errorlint
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
 // NewUnknownNamespaceError returns a new error indicating an unknown namespace parameter.
 func NewUnknownNamespaceError(namespace string) error {
 	return xerrors.NewInvalidParamsError(unknownNamespace{namespace})
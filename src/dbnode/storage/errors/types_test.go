@@ -21,11 +21,22 @@
 package errors
 
 import (
+	"errors"
 	"testing"
 
+	xerrors "github.com/m3db/m3/src/x/errors"
+
 	"github.com/stretchr/testify/require"
 )
 
+func TestShardNotOwnedError(t *testing.T) {
+	err := NewShardNotOwnedError(3)
+	require.Equal(t, "not responsible for shard 3", err.Error())
+	require.True(t, IsShardNotOwnedError(err))
+	require.True(t, xerrors.IsRetryableError(err))
+	require.False(t, IsShardNotOwnedError(errors.New("not responsible for shard 3")))
+}
+
 func TestUnknownNamespaceError(t *testing.T) {
 	err := NewUnknownNamespaceError("ns")
 	require.Equal(t, "unknown namespace: ns", err.Error())
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
@@ -63,6 +64,7 @@ type flushManagerMetrics struct {
 	dataSnapshotDuration            tally.Timer
 	indexFlushDuration              tally.Timer
 	commitLogRotationDuration       tally.Timer
+	scope                           tally.Scope
 }
 
 func newFlushManagerMetrics(scope tally.Scope) flushManagerMetrics {
@@ -75,6 +77,7 @@ func newFlushManagerMetrics(scope tally.Scope) flushManagerMetrics {
 		dataSnapshotDuration:            scope.Timer("data-snapshot-duration"),
 		indexFlushDuration:              scope.Timer("index-flush-duration"),
 		commitLogRotationDuration:       scope.Timer("commit-log-rotation-duration"),
+		scope:                           scope,
 	}
 }
 
@@ -115,6 +118,11 @@ func newFlushManager(
 }
 
 func (m *flushManager) Flush(startTime xtime.UnixNano) error {
+	// read-only databases serve the filesets on disk and never persist
+	if m.opts.ReadOnly() {
+		return nil
+	}
+
 	// ensure only a single flush is happening at a time
 	m.Lock()
 	if m.state != flushManagerIdle {
@@ -188,6 +196,7 @@ func (m *flushManager) dataWarmFlush(
 		if err := m.flushNamespaceWithTimes(ns, flushTimes, flushPersist); err != nil {
 			multiErr = multiErr.Add(err)
 		}
+		m.updateFlushLag(ns, flushTimes, startTime)
 	}
 
 	err = flushPersist.DoneFlush()
@@ -352,6 +361,37 @@ func (m *flushManager) namespaceSnapshotTimes(ns databaseNamespace, curr xtime.U
 	})
 }
 
+// updateFlushLag reports how long the oldest block of the namespace that is
+// still unflushed after a warm flush has been flushable for.
+func (m *flushManager) updateFlushLag(
+	ns databaseNamespace,
+	flushTimes []xtime.UnixNano,
+	curr xtime.UnixNano,
+) {
+	var (
+		rOpts = ns.Options().RetentionOptions()
+		lag   time.Duration
+	)
+	// Flush times are in descending order so the last block still needing
+	// a flush is the oldest.
+	for i := len(flushTimes) - 1; i >= 0; i-- {
+		t := flushTimes[i]
+		if needsFlush, err := ns.NeedsFlush(t, t); err != nil || !needsFlush {
+			continue
+		}
+		flushableAt := t.Add(rOpts.BlockSize()).Add(rOpts.BufferPast())
+		if lag = curr.Sub(flushableAt); lag < 0 {
+			lag = 0
+		}
+		break
+	}
+
+	nsID := ns.ID().String()
+	m.metrics.scope.Tagged(map[string]string{"namespace": nsID}).
+		Gauge("flush-lag").Update(lag.Seconds())
+	m.opts.HealthTracker().UpdateFlushLag(nsID, lag)
+}
+
 // flushWithTime flushes in-memory data for a given namespace, at a given
 // time, returning any error encountered during flushing
 func (m *flushManager) flushNamespaceWithTimes(
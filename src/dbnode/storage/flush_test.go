@@ -28,6 +28,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
@@ -552,6 +553,48 @@ func TestFlushManagerNamespaceFlushTimesSomeNeedFlush(t *testing.T) {
 	require.Equal(t, expectedTimes, times)
 }
 
+func TestFlushManagerUpdateFlushLag(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	tracker, err := health.NewTracker(health.NewOptions().SetMaxFlushLag(time.Hour))
+	require.NoError(t, err)
+	scope := tally.NewTestScope("", nil)
+
+	fm, ns1, _, _ := newMultipleFlushManagerNeedsFlush(t, ctrl)
+	fm.opts = fm.opts.SetHealthTracker(tracker)
+	fm.metrics = newFlushManagerMetrics(scope)
+
+	var (
+		rOpts     = ns1.Options().RetentionOptions()
+		blockSize = rOpts.BlockSize()
+		oldest    = xtime.FromSeconds(0)
+		times     = []xtime.UnixNano{oldest.Add(2 * blockSize), oldest.Add(blockSize), oldest}
+	)
+	// The second oldest block has been flushable for two hours.
+	now := times[1].Add(blockSize).Add(rOpts.BufferPast()).Add(2 * time.Hour)
+	// Only the second oldest block failed to flush.
+	ns1.EXPECT().NeedsFlush(oldest, oldest).Return(false, nil)
+	ns1.EXPECT().NeedsFlush(times[1], times[1]).Return(true, nil)
+
+	fm.updateFlushLag(ns1, times, now)
+	status := tracker.Evaluate()
+	require.True(t, status.Degraded)
+	require.Equal(t, []string{"namespace testns1 flush lag 2h0m0s exceeds 1h0m0s"}, status.Reasons)
+
+	gauges := scope.Snapshot().Gauges()
+	lag, ok := gauges[tally.KeyForPrefixedStringMap("flush-lag", map[string]string{
+		"namespace": defaultTestNs1ID.String(),
+	})]
+	require.True(t, ok)
+	require.Equal(t, (2 * time.Hour).Seconds(), lag.Value())
+
+	// Once every block is flushed there is no lag.
+	ns1.EXPECT().NeedsFlush(gomock.Any(), gomock.Any()).Return(false, nil).Times(len(times))
+	fm.updateFlushLag(ns1, times, now)
+	require.False(t, tracker.Evaluate().Degraded)
+}
+
 func TestFlushManagerFlushSnapshot(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
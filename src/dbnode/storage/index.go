@@ -56,13 +56,16 @@ import (
 	"github.com/m3db/m3/src/m3ninx/index/segment/builder"
 	idxpersist "github.com/m3db/m3/src/m3ninx/persist"
 	"github.com/m3db/m3/src/m3ninx/x"
+	"github.com/m3db/m3/src/metrics/filters"
 	"github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/context"
 	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/mmap"
 	xopentracing "github.com/m3db/m3/src/x/opentracing"
 	xresource "github.com/m3db/m3/src/x/resource"
+	xsync "github.com/m3db/m3/src/x/sync"
 	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/m3db/bitset"
@@ -75,6 +78,7 @@ import (
 
 var (
 	errDbIndexAlreadyClosed               = errors.New("database index has already been closed")
+	errDbIndexBlockNotFound               = errors.New("database index block not found")
 	errDbIndexUnableToWriteClosed         = errors.New("unable to write to database index, already closed")
 	errDbIndexUnableToQueryClosed         = errors.New("unable to query database index, already closed")
 	errDbIndexUnableToFlushClosed         = errors.New("unable to flush database index, already closed")
@@ -82,6 +86,7 @@ var (
 	errDbIndexTerminatingTickCancellation = errors.New("terminating tick early due to cancellation")
 	errDbIndexIsBootstrapping             = errors.New("index is already bootstrapping")
 	errDbIndexDoNotIndexSeries            = errors.New("series matched do not index fields")
+	errDbIndexIndexingPaused              = errors.New("namespace indexing is paused")
 )
 
 const (
@@ -89,6 +94,8 @@ const (
 	nsIndexReportStatsInterval          = 10 * time.Second
 
 	defaultFlushDocsBatchSize = 8192
+
+	mmapIndexFlushedSeriesName = "mmap.index.flushed-series"
 )
 
 var allQuery = idx.NewAllQuery()
@@ -107,6 +114,8 @@ type nsIndex struct {
 	bufferFuture          time.Duration
 	coldWritesEnabled     bool
 
+	timeWindowCompactionOpts index.TimeWindowCompactionOptions
+
 	namespaceRuntimeOptsMgr namespace.RuntimeOptionsManager
 	indexFilesetsBeforeFn   indexFilesetsBeforeFn
 	deleteFilesFn           deleteFilesFn
@@ -137,6 +146,10 @@ type nsIndex struct {
 
 	doNotIndexWithFields []doc.Field
 
+	// excludedTagFilters match the names of tags that are stored with the
+	// series but are not added to the index.
+	excludedTagFilters []filters.Filter
+
 	activeBlock index.Block
 }
 
@@ -165,6 +178,12 @@ type nsIndexState struct {
 	// for Query(..) since it is rebuilt each time and immutable once built.
 	blocksDescOrderImmutable []blockAndBlockStart
 
+	// NB: `windowsImmutable` contains the time windows merged from sealed
+	// blocks keyed by window start. A reference can be safely taken for
+	// query purposes since the map is replaced rather than modified each
+	// time the set of windows changes.
+	windowsImmutable map[xtime.UnixNano]index.Window
+
 	// shardsFilterID is set every time the shards change to correctly
 	// only return IDs that this node owns.
 	shardsFilterID func(ident.ID) bool
@@ -338,6 +357,17 @@ func newNamespaceIndexWithOptions(
 	nowFn := indexOpts.ClockOptions().NowFn()
 	logger := indexOpts.InstrumentOptions().Logger()
 
+	blockSize := nsMD.Options().IndexOptions().BlockSize()
+	timeWindowCompactionOpts := indexOpts.TimeWindowCompactionOptions()
+	if timeWindowCompactionOpts.Enabled {
+		if err := timeWindowCompactionOpts.ValidateForBlockSize(blockSize); err != nil {
+			logger.Warn("namespace index time window compaction disabled",
+				zap.Stringer("namespace", nsMD.ID()),
+				zap.Error(err))
+			timeWindowCompactionOpts.Enabled = false
+		}
+	}
+
 	var doNotIndexWithFields []doc.Field
 	if m := newIndexOpts.opts.DoNotIndexWithFieldsMap(); m != nil && len(m) != 0 {
 		for k, v := range m {
@@ -348,6 +378,16 @@ func newNamespaceIndexWithOptions(
 		}
 	}
 
+	excludedTags := nsMD.Options().IndexOptions().ExcludedTags()
+	excludedTagFilters := make([]filters.Filter, 0, len(excludedTags))
+	for _, pattern := range excludedTags {
+		f, err := filters.NewFilter([]byte(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid index excluded tag pattern %q: %w", pattern, err)
+		}
+		excludedTagFilters = append(excludedTagFilters, f)
+	}
+
 	idx := &nsIndex{
 		state: nsIndexState{
 			closeCh: make(chan struct{}),
@@ -359,13 +399,15 @@ func newNamespaceIndexWithOptions(
 		},
 
 		nowFn:                 nowFn,
-		blockSize:             nsMD.Options().IndexOptions().BlockSize(),
+		blockSize:             blockSize,
 		retentionPeriod:       nsMD.Options().RetentionOptions().RetentionPeriod(),
 		futureRetentionPeriod: nsMD.Options().RetentionOptions().FutureRetentionPeriod(),
 		bufferPast:            nsMD.Options().RetentionOptions().BufferPast(),
 		bufferFuture:          nsMD.Options().RetentionOptions().BufferFuture(),
 		coldWritesEnabled:     nsMD.Options().ColdWritesEnabled(),
 
+		timeWindowCompactionOpts: timeWindowCompactionOpts,
+
 		namespaceRuntimeOptsMgr: newIndexOpts.namespaceRuntimeOptsMgr,
 		indexFilesetsBeforeFn:   fs.IndexFileSetsBefore,
 		readIndexInfoFilesFn:    fs.ReadIndexInfoFiles,
@@ -383,6 +425,7 @@ func newNamespaceIndexWithOptions(
 		metrics:        newNamespaceIndexMetrics(indexOpts, instrumentOpts),
 
 		doNotIndexWithFields: doNotIndexWithFields,
+		excludedTagFilters:   excludedTagFilters,
 	}
 
 	activeBlock, err := idx.newBlockFn(xtime.UnixNano(0), idx.nsMetadata,
@@ -442,6 +485,10 @@ func newNamespaceIndexWithOptions(
 	// Report stats
 	go idx.reportStatsUntilClosed()
 
+	if idx.timeWindowCompactionOpts.Enabled {
+		go idx.compactTimeWindowsUntilClosed()
+	}
+
 	return idx, nil
 }
 
@@ -454,7 +501,14 @@ func (i *nsIndex) SetNamespaceRuntimeOptions(opts namespace.RuntimeOptions) {
 	i.logger.Info("set namespace runtime index options",
 		zap.Stringer("namespace", i.nsMetadata.ID()),
 		zap.Any("writeIndexingPerCPUConcurrency", opts.WriteIndexingPerCPUConcurrency()),
-		zap.Any("flushIndexingPerCPUConcurrency", opts.FlushIndexingPerCPUConcurrency()))
+		zap.Any("flushIndexingPerCPUConcurrency", opts.FlushIndexingPerCPUConcurrency()),
+		zap.Any("indexingPaused", opts.IndexingPaused()))
+}
+
+// indexingPaused returns whether indexing of incoming writes is currently
+// paused for the namespace by its runtime options.
+func (i *nsIndex) indexingPaused() bool {
+	return i.namespaceRuntimeOptsMgr.Get().IndexingPausedOrDefault()
 }
 
 func (i *nsIndex) reportStatsUntilClosed() {
@@ -496,8 +550,25 @@ func (i *nsIndex) reportStats() error {
 	maxIndexConcurrency := 0
 	sumIndexConcurrency := 0
 	numIndexingStats := 0
+
+	var (
+		numMutableSegments   int64
+		numFSTSegments       int64
+		oldestMutableSegment time.Duration
+	)
 	reporter := index.NewBlockStatsReporter(
 		func(s index.BlockSegmentStats) {
+			// Segments of the active and unflushed blocks are mutable, only
+			// flushed segments are immutable FST segments.
+			if s.Type == index.FlushedSegment {
+				numFSTSegments++
+			} else {
+				numMutableSegments++
+				if s.Age > oldestMutableSegment {
+					oldestMutableSegment = s.Age
+				}
+			}
+
 			var (
 				levels     []nsIndexBlocksSegmentsLevelMetrics
 				levelStats []nsIndexCompactionLevelStats
@@ -578,6 +649,19 @@ func (i *nsIndex) reportStats() error {
 		}
 	}
 
+	// Update the mutable vs FST segment stats.
+	i.metrics.numMutableSegments.Update(float64(numMutableSegments))
+	i.metrics.numFSTSegments.Update(float64(numFSTSegments))
+	i.metrics.oldestMutableSegmentAge.Update(oldestMutableSegment.Seconds())
+
+	// Update the time window stats.
+	var numTimeWindowDocs int64
+	for _, w := range i.state.windowsImmutable {
+		numTimeWindowDocs += w.NumDocs()
+	}
+	i.metrics.numTimeWindows.Update(float64(len(i.state.windowsImmutable)))
+	i.metrics.numTimeWindowDocs.Update(float64(numTimeWindowDocs))
+
 	// Update the indexing stats.
 	i.metrics.indexingConcurrencyMin.Update(float64(minIndexConcurrency))
 	i.metrics.indexingConcurrencyMax.Update(float64(maxIndexConcurrency))
@@ -629,6 +713,17 @@ func (i *nsIndex) WriteBatch(
 		return nil
 	}
 
+	if i.indexingPaused() {
+		// The series data is still written by the shard, so skip indexing
+		// and rely on the backfill once indexing is resumed.
+		entries := batch.PendingEntries()
+		for idx := range entries {
+			batch.MarkUnmarkedEntryError(errDbIndexIndexingPaused, idx)
+		}
+		i.metrics.insertPaused.Inc(int64(len(entries)))
+		return nil
+	}
+
 	i.state.RLock()
 	if !i.isOpenWithRLock() {
 		i.state.RUnlock()
@@ -686,6 +781,17 @@ func (i *nsIndex) WritePending(
 		return nil
 	}
 
+	if i.indexingPaused() {
+		// Release the references taken for indexing, the pending series
+		// are indexed by the backfill once indexing is resumed.
+		for j := range pending {
+			t := i.BlockStartForWriteTime(pending[j].Entry.Timestamp)
+			pending[j].Entry.OnIndexSeries.OnIndexFinalize(t)
+		}
+		i.metrics.insertPaused.Inc(int64(len(pending)))
+		return nil
+	}
+
 	i.state.RLock()
 	if !i.isOpenWithRLock() {
 		i.state.RUnlock()
@@ -699,6 +805,105 @@ func (i *nsIndex) WritePending(
 	return err
 }
 
+func (i *nsIndex) AddFlushedSeries(
+	shard uint32,
+	blockStart xtime.UnixNano,
+	docs []doc.Metadata,
+) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	indexOpts := i.opts.IndexOptions()
+	builder, err := builder.NewBuilderFromDocuments(indexOpts.SegmentBuilderOptions())
+	if err != nil {
+		return err
+	}
+	defer builder.Close()
+
+	err = i.sanitizeAllowDuplicatesWriteError(builder.InsertBatch(m3ninxindex.Batch{
+		Docs:                docs,
+		AllowPartialUpdates: true,
+	}))
+	if err != nil {
+		return err
+	}
+
+	compactor, err := compaction.NewCompactor(indexOpts.MetadataArrayPool(),
+		index.MetadataArrayPoolCapacity,
+		indexOpts.SegmentBuilderOptions(),
+		indexOpts.FSTSegmentOptions(),
+		compaction.CompactorOptions{})
+	if err != nil {
+		return err
+	}
+	defer compactor.Close()
+
+	seg, err := compactor.CompactUsingBuilder(builder, nil, mmap.ReporterOptions{
+		Context: mmap.Context{
+			Name: mmapIndexFlushedSeriesName,
+		},
+		Reporter: indexOpts.MmapReporter(),
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		dataBlockSize   = i.nsMetadata.Options().RetentionOptions().BlockSize()
+		indexBlockStart = i.BlockStartForWriteTime(blockStart)
+		// NB: a flushed data block holds every series of the shard written
+		// to it so the segment fulfills the data block for the shard.
+		fulfilled = result.NewShardTimeRangesFromRange(blockStart,
+			blockStart.Add(dataBlockSize), shard)
+		results = result.NewIndexBlockByVolumeType(indexBlockStart)
+	)
+	results.SetBlock(idxpersist.DefaultIndexVolumeType, result.NewIndexBlock(
+		[]result.Segment{result.NewSegment(seg, false)}, fulfilled))
+
+	i.state.RLock()
+	defer i.state.RUnlock()
+
+	blockResult, err := i.ensureBlockPresentWithRLock(indexBlockStart)
+	if err != nil {
+		return xerrors.FirstError(i.unableToAllocBlockInvariantError(err), seg.Close())
+	}
+	if err := blockResult.block.AddResults(results); err != nil {
+		return xerrors.FirstError(err, seg.Close())
+	}
+	return nil
+}
+
+func (i *nsIndex) WaitForInserts(ctx context.Context) error {
+	i.state.RLock()
+	if !i.isOpenWithRLock() {
+		i.state.RUnlock()
+		return errDbIndexUnableToWriteClosed
+	}
+	// NB: Enqueueing nothing returns the wait group of the next batch the
+	// insert queue indexes, which completes only after every insert enqueued
+	// before it since batches are indexed in order.
+	wg, err := i.state.insertQueue.InsertPending(nil)
+	i.state.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	indexed := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(indexed)
+	}()
+
+	select {
+	case <-indexed:
+		return nil
+	case <-ctx.GoContext().Done():
+		i.metrics.insertWaitTimeout.Inc(1)
+		return fmt.Errorf("timed out waiting for index inserts: %w", ctx.GoContext().Err())
+	}
+}
+
 // WriteBatches is called by the indexInsertQueue.
 func (i *nsIndex) writeBatches(
 	batch *index.WriteBatch,
@@ -770,6 +975,13 @@ func (i *nsIndex) writeBatches(
 				}
 			}
 
+			if len(i.excludedTagFilters) != 0 {
+				if filtered, ok := i.withoutExcludedTags(d); ok {
+					d = filtered
+					batch.UpdateDoc(idx, d)
+				}
+			}
+
 			ts := entry.Timestamp
 			// NB(bodu): Always check first to see if the write is within retention.
 			if !ts.After(earliestBlockStartToRetain) {
@@ -826,6 +1038,36 @@ func (i *nsIndex) writeBatches(
 	i.metrics.forwardIndexMisses.Inc(int64(forwardIndexMiss))
 }
 
+// withoutExcludedTags returns a copy of the document without the fields
+// matching the excluded tags of the namespace and true if any field was
+// removed, the series metadata the document references is left untouched
+// so excluded tags are still persisted with the series.
+func (i *nsIndex) withoutExcludedTags(d doc.Metadata) (doc.Metadata, bool) {
+	var fields []doc.Field
+	for idx, field := range d.Fields {
+		excluded := false
+		for _, f := range i.excludedTagFilters {
+			if f.Matches(field.Name) {
+				excluded = true
+				break
+			}
+		}
+		if excluded && fields == nil {
+			fields = make([]doc.Field, idx, len(d.Fields)-1)
+			copy(fields, d.Fields[:idx])
+		} else if !excluded && fields != nil {
+			fields = append(fields, field)
+		}
+	}
+	if fields == nil {
+		return d, false
+	}
+
+	i.metrics.insertExcludedTags.Inc(int64(len(d.Fields) - len(fields)))
+	d.Fields = fields
+	return d, true
+}
+
 func (i *nsIndex) writeBatchForBlockStart(
 	blockStart xtime.UnixNano, batch *index.WriteBatch,
 ) {
@@ -840,11 +1082,21 @@ func (i *nsIndex) writeBatchForBlockStart(
 	// i.e. we have the block and the inserts, perform the writes.
 	result, err := i.activeBlock.WriteBatch(batch)
 
-	// Record the end to end indexing latency.
-	now := i.nowFn()
+	// Record the end to end indexing latency and the lag between a write
+	// being accepted and the series being queryable.
+	var (
+		now = i.nowFn()
+		lag time.Duration
+	)
 	for idx := range pending {
 		took := now.Sub(pending[idx].EnqueuedAt)
 		i.metrics.insertEndToEndLatency.Record(took)
+		if took > lag {
+			lag = took
+		}
+	}
+	if numPending > 0 {
+		i.metrics.insertLag.Update(lag.Seconds())
 	}
 
 	// NB: we don't need to do anything to the OnIndexSeries refs in `inserts` at this point,
@@ -996,6 +1248,9 @@ func (i *nsIndex) tickingBlocks(
 		}
 	}
 
+	// Drop any time windows holding blocks past the retention period.
+	multiErr = multiErr.Add(i.removeExpiredTimeWindowsWithLock(earliestBlockStartToRetain))
+
 	return tickingBlocksResult{
 		totalBlocks:   len(i.state.blocksByTime),
 		activeBlock:   activeBlock,
@@ -1134,6 +1389,81 @@ func (i *nsIndex) BackgroundCompact() {
 	}
 }
 
+func (i *nsIndex) Compact(
+	blockStart xtime.UnixNano,
+	concurrency int,
+) ([]index.BlockCompactResult, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	i.state.RLock()
+	if i.state.closed {
+		i.state.RUnlock()
+		return nil, errDbIndexAlreadyClosed
+	}
+	var blocks []index.Block
+	if blockStart.IsZero() {
+		blocks = make([]index.Block, 0, 1+len(i.state.blocksDescOrderImmutable))
+		blocks = append(blocks, i.activeBlock)
+		for _, b := range i.state.blocksDescOrderImmutable {
+			blocks = append(blocks, b.block)
+		}
+	} else {
+		b, ok := i.state.blocksByTime[blockStart]
+		if !ok {
+			i.state.RUnlock()
+			return nil, xerrors.NewInvalidParamsError(fmt.Errorf("%w: %s",
+				errDbIndexBlockNotFound, blockStart.ToTime().String()))
+		}
+		blocks = append(blocks, b)
+	}
+	i.state.RUnlock()
+
+	var (
+		start   = i.nowFn()
+		results = make([]index.BlockCompactResult, len(blocks))
+		errs    = make([]error, len(blocks))
+		workers = xsync.NewWorkerPool(concurrency)
+		wg      sync.WaitGroup
+	)
+	workers.Init()
+	for idx, b := range blocks {
+		idx, b := idx, b
+		wg.Add(1)
+		workers.Go(func() {
+			defer wg.Done()
+			results[idx], errs[idx] = b.Compact()
+		})
+	}
+	wg.Wait()
+
+	var (
+		compacted = make([]index.BlockCompactResult, 0, len(results))
+		multiErr  xerrors.MultiError
+	)
+	for idx, err := range errs {
+		if err == index.ErrUnableToCompactBlockClosed {
+			// Closed blocks are temporarily in the list still.
+			continue
+		}
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+		compacted = append(compacted, results[idx])
+	}
+
+	if err := multiErr.FinalError(); err != nil {
+		i.metrics.forcedCompactionErrors.Inc(1)
+		return nil, err
+	}
+
+	i.metrics.forcedCompactionSuccess.Inc(1)
+	i.metrics.forcedCompactionLatency.Record(i.nowFn().Sub(start))
+	return compacted, nil
+}
+
 func (i *nsIndex) readInfoFilesAsMap() map[xtime.UnixNano][]fs.ReadIndexInfoFileResult {
 	fsOpts := i.opts.CommitLogOptions().FilesystemOptions()
 	infoFiles := i.readIndexInfoFilesFn(fs.ReadIndexInfoFilesOptions{
@@ -1622,25 +1952,31 @@ func (i *nsIndex) query(
 
 	// If require exhaustive but not, return error.
 	if opts.RequireExhaustive {
-		seriesCount := results.Size()
-		docsCount := results.TotalDocsCount()
+		var (
+			seriesCount = results.Size()
+			docsCount   = results.TotalDocsCount()
+			resource    string
+			limit       int64
+		)
 		if opts.SeriesLimitExceeded(seriesCount) {
 			i.metrics.queryNonExhaustiveSeriesLimitError.Inc(1)
+			resource, limit = "series", int64(opts.SeriesLimit)
 		} else if opts.DocsLimitExceeded(docsCount) {
 			i.metrics.queryNonExhaustiveDocsLimitError.Inc(1)
+			resource, limit = "docs", int64(opts.DocsLimit)
 		} else {
 			i.metrics.queryNonExhaustiveLimitError.Inc(1)
 		}
 
 		// NB(r): Make sure error is not retried and returns as bad request.
-		return queryRes, xerrors.NewInvalidParamsError(limits.NewQueryLimitExceededError(fmt.Sprintf(
+		return queryRes, xerrors.NewInvalidParamsError(limits.NewQueryLimitExceededErrorWithLimit(fmt.Sprintf(
 			"query exceeded limit: require_exhaustive=%v, series_limit=%d, series_matched=%d, docs_limit=%d, docs_matched=%d",
 			opts.RequireExhaustive,
 			opts.SeriesLimit,
 			seriesCount,
 			opts.DocsLimit,
 			docsCount,
-		)))
+		), resource, limit))
 	}
 
 	// Otherwise non-exhaustive but not required to be.
@@ -1691,6 +2027,8 @@ func (i *nsIndex) queryWithSpan(
 	// NB(r): Safe to take ref to i.state.blocksDescOrderImmutable since it's
 	// immutable and we only create an iterator over it.
 	blocks := newBlocksIterStackAlloc(i.activeBlock, i.state.blocksDescOrderImmutable, qryRange)
+	windows := newQueryTimeWindows(i.state.windowsImmutable, i.timeWindowCompactionOpts,
+		xtime.Range{Start: opts.StartInclusive, End: opts.EndExclusive})
 
 	// Can now release the lock and execute the query without holding the lock.
 	i.state.RUnlock()
@@ -1707,17 +2045,35 @@ func (i *nsIndex) queryWithSpan(
 	defer perms.Close()
 
 	var blockIters []*blockIter
-	for b, ok := blocks.Next(); ok; b, ok = b.Next() {
-		block := b.Current()
+	appendBlockIter := func(block index.Block) error {
 		iter, err := newBlockIterFn(ctx, block, query, results)
 		if err != nil {
-			return queryResult{}, err
+			return err
 		}
 		blockIters = append(blockIters, &blockIter{
 			iter:       iter,
 			iterCloser: x.NewSafeCloser(iter),
 			block:      block,
 		})
+		return nil
+	}
+	for b, ok := blocks.Next(); ok; b, ok = b.Next() {
+		block := b.Current()
+
+		// Query the time window holding the block instead of the block
+		// itself if the window holds all of the block's documents.
+		windowBlock, covered := windows.blockFor(ctx, block)
+		if windowBlock != nil {
+			if err := appendBlockIter(windowBlock); err != nil {
+				return queryResult{}, err
+			}
+		}
+		if covered {
+			continue
+		}
+		if err := appendBlockIter(block); err != nil {
+			return queryResult{}, err
+		}
 	}
 
 	defer func() {
@@ -2397,10 +2753,16 @@ func (i *nsIndex) Close() error {
 	}
 	blocks = append(blocks, i.activeBlock)
 
+	windows := make([]index.Window, 0, len(i.state.windowsImmutable))
+	for _, w := range i.state.windowsImmutable {
+		windows = append(windows, w)
+	}
+
 	i.activeBlock = nil
 	i.state.latestBlock = nil
 	i.state.blocksByTime = nil
 	i.state.blocksDescOrderImmutable = nil
+	i.state.windowsImmutable = nil
 
 	if i.runtimeOptsListener != nil {
 		i.runtimeOptsListener.Close()
@@ -2424,6 +2786,9 @@ func (i *nsIndex) Close() error {
 	for _, block := range blocks {
 		multiErr = multiErr.Add(block.Close())
 	}
+	for _, w := range windows {
+		multiErr = multiErr.Add(w.Close())
+	}
 
 	return multiErr.FinalError()
 }
@@ -2446,11 +2811,15 @@ type nsIndexMetrics struct {
 	asyncInsertSuccess               tally.Counter
 	asyncInsertErrors                tally.Counter
 	insertAfterClose                 tally.Counter
+	insertPaused                     tally.Counter
+	insertExcludedTags               tally.Counter
 	queryAfterClose                  tally.Counter
 	forwardIndexHits                 tally.Counter
 	forwardIndexMisses               tally.Counter
 	forwardIndexCounter              tally.Counter
 	insertEndToEndLatency            tally.Timer
+	insertLag                        tally.Gauge
+	insertWaitTimeout                tally.Counter
 	blocksEvictedMutableSegments     tally.Counter
 	blockMetrics                     nsIndexBlocksMetrics
 	indexingConcurrencyMin           tally.Gauge
@@ -2463,6 +2832,17 @@ type nsIndexMetrics struct {
 	latestBlockNumDocsForeground     tally.Gauge
 	latestBlockNumSegmentsBackground tally.Gauge
 	latestBlockNumDocsBackground     tally.Gauge
+	numMutableSegments               tally.Gauge
+	numFSTSegments                   tally.Gauge
+	oldestMutableSegmentAge          tally.Gauge
+	forcedCompactionSuccess          tally.Counter
+	forcedCompactionErrors           tally.Counter
+	forcedCompactionLatency          tally.Timer
+	timeWindowCompactionSuccess      tally.Counter
+	timeWindowCompactionErrors       tally.Counter
+	timeWindowCompactionLatency      tally.Timer
+	numTimeWindows                   tally.Gauge
+	numTimeWindowDocs                tally.Gauge
 
 	loadedDocsPerQuery                 tally.Histogram
 	queryExhaustiveSuccess             tally.Counter
@@ -2504,6 +2884,8 @@ func newNamespaceIndexMetrics(
 		insertAfterClose: scope.Tagged(map[string]string{
 			"error_type": "insert-closed",
 		}).Counter("insert-after-close"),
+		insertPaused:       scope.Counter("insert-indexing-paused"),
+		insertExcludedTags: scope.Counter("insert-excluded-tags"),
 		queryAfterClose: scope.Tagged(map[string]string{
 			"error_type": "query-closed",
 		}).Counter("query-after-error"),
@@ -2518,6 +2900,8 @@ func newNamespaceIndexMetrics(
 		}).Counter(forwardIndexName),
 		insertEndToEndLatency: instrument.NewTimer(scope,
 			"insert-end-to-end-latency", iopts.TimerOptions()),
+		insertLag:                    scope.Gauge("index-lag-seconds"),
+		insertWaitTimeout:            scope.Counter("insert-wait-timeout"),
 		blocksEvictedMutableSegments: scope.Counter("blocks-evicted-mutable-segments"),
 		blockMetrics:                 newNamespaceIndexBlocksMetrics(opts, blocksScope),
 		indexingConcurrencyMin: scope.Tagged(map[string]string{
@@ -2548,6 +2932,33 @@ func newNamespaceIndexMetrics(
 		latestBlockNumDocsBackground: scope.Tagged(map[string]string{
 			"segment_type": "background",
 		}).Gauge("latest-block-num-docs"),
+		numMutableSegments: scope.Tagged(map[string]string{
+			"segment_format": "mutable",
+		}).Gauge("num-segments"),
+		numFSTSegments: scope.Tagged(map[string]string{
+			"segment_format": "fst",
+		}).Gauge("num-segments"),
+		oldestMutableSegmentAge: scope.Tagged(map[string]string{
+			"segment_format": "mutable",
+		}).Gauge("oldest-segment-age-seconds"),
+		forcedCompactionSuccess: scope.Tagged(map[string]string{
+			"result": "success",
+		}).Counter("forced-compaction"),
+		forcedCompactionErrors: scope.Tagged(map[string]string{
+			"result": "error",
+		}).Counter("forced-compaction"),
+		forcedCompactionLatency: instrument.NewTimer(scope,
+			"forced-compaction-latency", iopts.TimerOptions()),
+		timeWindowCompactionSuccess: scope.Tagged(map[string]string{
+			"result": "success",
+		}).Counter("time-window-compaction"),
+		timeWindowCompactionErrors: scope.Tagged(map[string]string{
+			"result": "error",
+		}).Counter("time-window-compaction"),
+		timeWindowCompactionLatency: instrument.NewTimer(scope,
+			"time-window-compaction-latency", iopts.TimerOptions()),
+		numTimeWindows:    scope.Gauge("num-time-windows"),
+		numTimeWindowDocs: scope.Gauge("num-time-window-docs"),
 		loadedDocsPerQuery: scope.Histogram(
 			"loaded-docs-per-query",
 			tally.MustMakeExponentialValueBuckets(10, 2, 16),
@@ -51,6 +51,7 @@ import (
 
 	opentracinglog "github.com/opentracing/opentracing-go/log"
 	"github.com/uber-go/tally"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
 
@@ -59,6 +60,8 @@ var (
 	ErrUnableToQueryBlockClosed = errors.New("unable to query, index block is closed")
 	// ErrUnableReportStatsBlockClosed is returned from Stats when the block is closed.
 	ErrUnableReportStatsBlockClosed = errors.New("unable to report stats, block is closed")
+	// ErrUnableToCompactBlockClosed is returned from Compact when the block is closed.
+	ErrUnableToCompactBlockClosed = errors.New("unable to compact, block is closed")
 
 	errUnableToWriteBlockClosed     = errors.New("unable to write, index block is closed")
 	errUnableToWriteBlockSealed     = errors.New("unable to write, index block is sealed")
@@ -99,6 +102,8 @@ func (s blockState) String() string {
 
 type newExecutorFn func() (search.Executor, error)
 
+type newSegmentReadersFn func() ([]segment.Reader, error)
+
 type shardRangesSegmentsByVolumeType map[persist.IndexVolumeType][]blockShardRangesSegments
 
 func (s shardRangesSegmentsByVolumeType) forEachSegment(cb func(segment segment.Segment) error) error {
@@ -136,6 +141,9 @@ type block struct {
 
 	state blockState
 
+	// version is incremented every time documents or segments are added.
+	version atomic.Uint64
+
 	cachedSearchesWorkers xsync.WorkerPool
 
 	mutableSegments                 *mutableSegments
@@ -143,6 +151,7 @@ type block struct {
 	shardRangesSegmentsByVolumeType shardRangesSegmentsByVolumeType
 	newFieldsAndTermsIteratorFn     newFieldsAndTermsIteratorFn
 	newExecutorWithRLockFn          newExecutorFn
+	newSegmentReadersWithRLockFn    newSegmentReadersFn
 	addAggregateResultsFn           addAggregateResultsFn
 	blockStart                      xtime.UnixNano
 	blockEnd                        xtime.UnixNano
@@ -291,6 +300,7 @@ func NewBlock(
 	}
 	b.newFieldsAndTermsIteratorFn = newFieldsAndTermsIterator
 	b.newExecutorWithRLockFn = b.executorWithRLock
+	b.newSegmentReadersWithRLockFn = b.segmentReadersWithRLock
 	b.addAggregateResultsFn = b.addAggregateResults
 
 	return b, nil
@@ -309,6 +319,35 @@ func (b *block) BackgroundCompact() {
 	b.mutableSegments.BackgroundCompact()
 }
 
+// Compact synchronously compacts the warm and cold mutable segments.
+func (b *block) Compact() (BlockCompactResult, error) {
+	b.RLock()
+	if b.state == blockStateClosed {
+		b.RUnlock()
+		return BlockCompactResult{}, ErrUnableToCompactBlockClosed
+	}
+	mutableSegs := make([]*mutableSegments, 0, 1+len(b.coldMutableSegments))
+	mutableSegs = append(mutableSegs, b.mutableSegments)
+	mutableSegs = append(mutableSegs, b.coldMutableSegments...)
+	b.RUnlock()
+
+	result := BlockCompactResult{BlockStart: b.blockStart}
+	for _, segs := range mutableSegs {
+		segsResult, err := segs.Compact()
+		if err == errMutableSegmentsAlreadyClosed {
+			// Evicted concurrently, e.g. after a flush.
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.NumSegmentsCompacted += segsResult.NumSegmentsCompacted
+		result.NumDocs += segsResult.NumDocs
+	}
+
+	return result, nil
+}
+
 func (b *block) WriteBatch(inserts *WriteBatch) (WriteBatchResult, error) {
 	b.RLock()
 	if !b.writesAcceptedWithRLock() {
@@ -320,12 +359,14 @@ func (b *block) WriteBatch(inserts *WriteBatch) (WriteBatchResult, error) {
 		coldBlock := b.coldMutableSegments[len(b.coldMutableSegments)-1]
 		b.RUnlock()
 		_, err := coldBlock.WriteBatch(inserts)
+		b.version.Inc()
 		// Don't pass stats back from insertion into a cold block,
 		// we only care about warm mutable segments stats.
 		return b.writeBatchResult(inserts, MutableSegmentsStats{}, err)
 	}
 	b.RUnlock()
 	stats, err := b.mutableSegments.WriteBatch(inserts)
+	b.version.Inc()
 	return b.writeBatchResult(inserts, stats, err)
 }
 
@@ -376,7 +417,7 @@ func (b *block) writesAcceptedWithRLock() bool {
 }
 
 func (b *block) executorWithRLock() (search.Executor, error) {
-	readers, err := b.segmentReadersWithRLock()
+	readers, err := b.newSegmentReadersWithRLockFn()
 	if err != nil {
 		return nil, err
 	}
@@ -701,7 +742,7 @@ func (b *block) AggregateIter(ctx context.Context, aggOpts AggregateResultsOptio
 			return newFilterFieldsIterator(r, aggOpts.FieldFilter)
 		},
 	}
-	readers, err := b.segmentReadersWithRLock()
+	readers, err := b.newSegmentReadersWithRLockFn()
 	if err != nil {
 		return nil, err
 	}
@@ -1029,6 +1070,9 @@ func (b *block) addResults(
 		b.shardRangesSegmentsByVolumeType[volumeType] = shardRangesSegments
 	}
 
+	// Any segments added change the set of documents the block holds.
+	b.version.Inc()
+
 	var (
 		plCaches = ReadThroughSegmentCaches{
 			SegmentPostingsListCache: b.opts.PostingsListCache(),
@@ -1304,6 +1348,36 @@ func (b *block) MemorySegmentsData(ctx context.Context) ([]fst.SegmentData, erro
 	return data, nil
 }
 
+func (b *block) FlushedSegments() (BlockFlushedSegments, error) {
+	b.RLock()
+	defer b.RUnlock()
+	if b.state == blockStateClosed {
+		return BlockFlushedSegments{}, errBlockAlreadyClosed
+	}
+
+	result := BlockFlushedSegments{
+		Version:   b.version.Load(),
+		Unflushed: b.mutableSegments.NeedsEviction(),
+	}
+	for _, coldSeg := range b.coldMutableSegments {
+		result.Unflushed = result.Unflushed || coldSeg.NeedsEviction()
+	}
+	b.shardRangesSegmentsByVolumeType.forEachSegment(func(seg segment.Segment) error {
+		if _, ok := seg.(segment.MutableSegment); ok {
+			// Bootstrapped mutable segments are not backed by disk.
+			result.Unflushed = result.Unflushed || seg.Size() > 0
+			return nil
+		}
+		result.Segments = append(result.Segments, seg)
+		return nil
+	})
+	return result, nil
+}
+
+func (b *block) Version() uint64 {
+	return b.version.Load()
+}
+
 func (b *block) Close() error {
 	b.Lock()
 	defer b.Unlock()
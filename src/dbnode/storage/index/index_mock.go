@@ -882,6 +882,21 @@ func (mr *MockBlockMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBlock)(nil).Close))
 }
 
+// Compact mocks base method.
+func (m *MockBlock) Compact() (BlockCompactResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compact")
+	ret0, _ := ret[0].(BlockCompactResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Compact indicates an expected call of Compact.
+func (mr *MockBlockMockRecorder) Compact() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compact", reflect.TypeOf((*MockBlock)(nil).Compact))
+}
+
 // EndTime mocks base method.
 func (m *MockBlock) EndTime() time0.UnixNano {
 	m.ctrl.T.Helper()
@@ -924,6 +939,21 @@ func (mr *MockBlockMockRecorder) EvictMutableSegments() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictMutableSegments", reflect.TypeOf((*MockBlock)(nil).EvictMutableSegments))
 }
 
+// FlushedSegments mocks base method.
+func (m *MockBlock) FlushedSegments() (BlockFlushedSegments, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlushedSegments")
+	ret0, _ := ret[0].(BlockFlushedSegments)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlushedSegments indicates an expected call of FlushedSegments.
+func (mr *MockBlockMockRecorder) FlushedSegments() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushedSegments", reflect.TypeOf((*MockBlock)(nil).FlushedSegments))
+}
+
 // IsOpen mocks base method.
 func (m *MockBlock) IsOpen() bool {
 	m.ctrl.T.Helper()
@@ -1095,6 +1125,20 @@ func (mr *MockBlockMockRecorder) Tick(c interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tick", reflect.TypeOf((*MockBlock)(nil).Tick), c)
 }
 
+// Version mocks base method.
+func (m *MockBlock) Version() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockBlockMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockBlock)(nil).Version))
+}
+
 // WriteBatch mocks base method.
 func (m *MockBlock) WriteBatch(inserts *WriteBatch) (WriteBatchResult, error) {
 	m.ctrl.T.Helper()
@@ -2301,6 +2345,34 @@ func (mr *MockOptionsMockRecorder) SetSegmentBuilderOptions(value interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentBuilderOptions", reflect.TypeOf((*MockOptions)(nil).SetSegmentBuilderOptions), value)
 }
 
+// SetTimeWindowCompactionOptions mocks base method.
+func (m *MockOptions) SetTimeWindowCompactionOptions(value TimeWindowCompactionOptions) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTimeWindowCompactionOptions", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetTimeWindowCompactionOptions indicates an expected call of SetTimeWindowCompactionOptions.
+func (mr *MockOptionsMockRecorder) SetTimeWindowCompactionOptions(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTimeWindowCompactionOptions", reflect.TypeOf((*MockOptions)(nil).SetTimeWindowCompactionOptions), value)
+}
+
+// TimeWindowCompactionOptions mocks base method.
+func (m *MockOptions) TimeWindowCompactionOptions() TimeWindowCompactionOptions {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeWindowCompactionOptions")
+	ret0, _ := ret[0].(TimeWindowCompactionOptions)
+	return ret0
+}
+
+// TimeWindowCompactionOptions indicates an expected call of TimeWindowCompactionOptions.
+func (mr *MockOptionsMockRecorder) TimeWindowCompactionOptions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeWindowCompactionOptions", reflect.TypeOf((*MockOptions)(nil).TimeWindowCompactionOptions))
+}
+
 // Validate mocks base method.
 func (m *MockOptions) Validate() error {
 	m.ctrl.T.Helper()
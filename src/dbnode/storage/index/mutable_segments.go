@@ -101,6 +101,10 @@ type mutableSegments struct {
 	writeIndexingConcurrency int
 	cachedSearchesWorkers    xsync.WorkerPool
 
+	// backgroundStandardDone is signaled when a standard background
+	// compaction completes.
+	backgroundStandardDone *sync.Cond
+
 	metrics mutableSegmentsMetrics
 	logger  *zap.Logger
 
@@ -110,6 +114,7 @@ type mutableSegments struct {
 
 type mutableSegmentsMetrics struct {
 	foregroundCompactionPlanRunLatency                          tally.Timer
+	forcedCompactionPlanRunLatency                              tally.Timer
 	foregroundCompactionTaskRunLatency                          tally.Timer
 	backgroundCompactionPlanRunLatency                          tally.Timer
 	backgroundCompactionTaskRunLatency                          tally.Timer
@@ -137,6 +142,9 @@ func newMutableSegmentsMetrics(s tally.Scope) mutableSegmentsMetrics {
 	activeBlockScope := s.SubScope("active-block")
 	return mutableSegmentsMetrics{
 		foregroundCompactionPlanRunLatency: foregroundScope.Timer("compaction-plan-run-latency"),
+		forcedCompactionPlanRunLatency: s.Tagged(map[string]string{
+			"compaction-type": "forced",
+		}).Timer("compaction-plan-run-latency"),
 		foregroundCompactionTaskRunLatency: foregroundScope.Timer("compaction-task-run-latency"),
 		backgroundCompactionPlanRunLatency: backgroundScope.Timer("compaction-plan-run-latency"),
 		backgroundCompactionTaskRunLatency: backgroundScope.Timer("compaction-task-run-latency"),
@@ -201,6 +209,7 @@ func newMutableSegments(
 		metrics:               newMutableSegmentsMetrics(iopts.MetricsScope()),
 		logger:                iopts.Logger(),
 	}
+	m.backgroundStandardDone = sync.NewCond(m)
 	m.optsListener = namespaceRuntimeOptsMgr.RegisterListener(m)
 	return m
 }
@@ -470,6 +479,10 @@ func (m *mutableSegments) maybeBackgroundCompactWithLock() {
 	if m.compact.compactingBackgroundStandard || m.backgroundCompactDisable {
 		return
 	}
+	if m.compact.numForceCompactsPending > 0 {
+		// Let the pending forced compactions run first.
+		return
+	}
 
 	m.backgroundCompactWithLock(false)
 }
@@ -587,6 +600,7 @@ func (m *mutableSegments) backgroundCompactWithLock(force bool) {
 
 			m.Lock()
 			m.compact.compactingBackgroundStandard = false
+			m.backgroundStandardDone.Broadcast()
 			m.cleanupBackgroundCompactWithLock()
 			m.Unlock()
 		}()
@@ -614,6 +628,78 @@ func (m *mutableSegments) backgroundCompactWithLock(force bool) {
 	}
 }
 
+// Compact synchronously compacts all the background segments that are not
+// being garbage collected into a single segment, waiting for any standard
+// background compaction in progress to complete first.
+func (m *mutableSegments) Compact() (BlockCompactResult, error) {
+	m.Lock()
+	m.compact.numForceCompactsPending++
+	for m.compact.compactingBackgroundStandard && m.state == mutableSegmentsStateOpen {
+		m.backgroundStandardDone.Wait()
+	}
+	m.compact.numForceCompactsPending--
+
+	if m.state == mutableSegmentsStateClosed {
+		m.Unlock()
+		return BlockCompactResult{}, errMutableSegmentsAlreadyClosed
+	}
+
+	task := compaction.Task{
+		Segments: make([]compaction.Segment, 0, len(m.backgroundSegments)),
+	}
+	for _, seg := range m.backgroundSegments {
+		if seg.garbageCollecting {
+			continue
+		}
+		task.Segments = append(task.Segments, compaction.Segment{
+			Age:     seg.Age(),
+			Size:    seg.Segment().Size(),
+			Type:    segments.FSTType,
+			Segment: seg.Segment(),
+		})
+	}
+
+	compactors := m.compact.backgroundCompactors
+	if len(task.Segments) < 2 || compactors == nil {
+		// Nothing to compact, resume standard background compactions.
+		m.maybeBackgroundCompactWithLock()
+		m.Unlock()
+		return BlockCompactResult{}, nil
+	}
+
+	// Run as the standard background compaction so that no other standard
+	// background compaction plans the same segments concurrently.
+	m.compact.compactingBackgroundStandard = true
+	m.Unlock()
+
+	summary := task.Summary()
+	logger := m.logger.With(zap.Time("blockStart", m.blockStart.ToTime()))
+	logger.Info("start forced compaction",
+		zap.Int("numSegments", len(task.Segments)),
+		zap.Int64("cumulativeSize", summary.CumulativeSize))
+
+	sw := m.metrics.forcedCompactionPlanRunLatency.Start()
+	compactor := <-compactors
+	err := m.backgroundCompactWithTask(task, compactor, false, true, logger)
+	compactors <- compactor
+	sw.Stop()
+
+	m.Lock()
+	m.compact.compactingBackgroundStandard = false
+	m.backgroundStandardDone.Broadcast()
+	m.cleanupBackgroundCompactWithLock()
+	m.Unlock()
+
+	if err != nil {
+		return BlockCompactResult{}, err
+	}
+
+	return BlockCompactResult{
+		NumSegmentsCompacted: len(task.Segments),
+		NumDocs:              summary.CumulativeSize,
+	}, nil
+}
+
 func (m *mutableSegments) segmentAnyInactiveSeries(seg segment.Segment) (bool, error) {
 	reader, err := seg.Reader()
 	if err != nil {
@@ -1441,6 +1527,7 @@ type mutableSegmentsCompact struct {
 	compactingBackgroundGarbageCollect bool
 	numForeground                      int
 	numBackground                      int
+	numForceCompactsPending            int
 
 	foregroundCompactorCreatedAt time.Time
 }
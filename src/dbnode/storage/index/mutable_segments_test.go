@@ -273,3 +273,70 @@ func testDocN(n int) doc.Metadata {
 func moduloByteStr(strs []string, n int) []byte {
 	return []byte(strs[n%len(strs)])
 }
+
+func TestMutableSegmentsCompact(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	blockSize := time.Hour
+	testMD := newTestNSMetadata(t)
+	blockStart := xtime.Now().Truncate(blockSize)
+
+	segs, _ := newTestMutableSegments(t, testMD, blockStart)
+	segs.backgroundCompactDisable = true // Disable to explicitly test.
+
+	// Nothing to compact before any writes.
+	result, err := segs.Compact()
+	require.NoError(t, err)
+	require.Equal(t, BlockCompactResult{}, result)
+
+	// Insert until there are multiple background segments.
+	inserted := 0
+	for {
+		segs.Lock()
+		numBackground := len(segs.backgroundSegments)
+		segs.Unlock()
+		if numBackground > 1 {
+			break
+		}
+
+		batch := NewWriteBatch(WriteBatchOptions{
+			IndexBlockSize: blockSize,
+		})
+		for i := 0; i < 128; i++ {
+			onIndexSeries := doc.NewMockOnIndexSeries(ctrl)
+			onIndexSeries.EXPECT().NeedsIndexGarbageCollected().Return(false).AnyTimes()
+			onIndexSeries.EXPECT().TryMarkIndexGarbageCollected().Return(false).AnyTimes()
+			batch.Append(WriteBatchEntry{
+				Timestamp:     blockStart.Add(time.Minute),
+				OnIndexSeries: onIndexSeries,
+			}, testDocN(inserted))
+			inserted++
+		}
+
+		_, err := segs.WriteBatch(batch)
+		require.NoError(t, err)
+	}
+
+	segs.Lock()
+	numBackground, numBackgroundDocs := numSegmentsAndDocs(segs.backgroundSegments)
+	segs.Unlock()
+
+	result, err = segs.Compact()
+	require.NoError(t, err)
+	require.Equal(t, int(numBackground), result.NumSegmentsCompacted)
+	require.Equal(t, numBackgroundDocs, result.NumDocs)
+
+	segs.Lock()
+	require.Equal(t, 1, len(segs.backgroundSegments))
+	require.Equal(t, numBackgroundDocs, segs.backgroundSegments[0].Segment().Size())
+	require.False(t, segs.compact.compactingBackgroundStandard)
+	segs.Unlock()
+
+	// Compacted segments are still searchable.
+	testDocSearches(t, segs)
+
+	segs.Close()
+	_, err = segs.Compact()
+	require.Equal(t, errMutableSegmentsAlreadyClosed, err)
+}
@@ -135,6 +135,7 @@ type options struct {
 	readThroughSegmentOptions       ReadThroughSegmentOptions
 	mmapReporter                    mmap.Reporter
 	queryLimits                     limits.QueryLimits
+	timeWindowCompactionOpts        TimeWindowCompactionOptions
 }
 
 var undefinedUUIDFn = func() ([]byte, error) { return nil, errIDGenerationDisabled }
@@ -195,6 +196,7 @@ func NewOptions() Options {
 		foregroundCompactionPlannerOpts: defaultForegroundCompactionOpts,
 		backgroundCompactionPlannerOpts: defaultBackgroundCompactionOpts,
 		queryLimits:                     limits.NoOpQueryLimits(),
+		timeWindowCompactionOpts:        NewTimeWindowCompactionOptions(),
 	}
 	resultsPool.Init(func() QueryResults {
 		return NewQueryResults(nil, QueryResultsOptions{}, opts)
@@ -234,6 +236,9 @@ func (o *options) Validate() error {
 	if o.postingsListCache == nil {
 		return errPostingsListCacheUnspecified
 	}
+	if err := o.timeWindowCompactionOpts.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -470,3 +475,13 @@ func (o *options) SetQueryLimits(value limits.QueryLimits) Options {
 func (o *options) QueryLimits() limits.QueryLimits {
 	return o.queryLimits
 }
+
+func (o *options) SetTimeWindowCompactionOptions(value TimeWindowCompactionOptions) Options {
+	opts := *o
+	opts.timeWindowCompactionOpts = value
+	return &opts
+}
+
+func (o *options) TimeWindowCompactionOptions() TimeWindowCompactionOptions {
+	return o.timeWindowCompactionOpts
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/idx"
+	"github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3/src/m3ninx/index/segment/builder"
 	"github.com/m3db/m3/src/m3ninx/index/segment/fst"
 	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
@@ -87,6 +88,9 @@ type QueryOptions struct {
 	RequireExhaustive bool
 	// RequireNoWait requires queries to abort if execution must wait for permits.
 	RequireNoWait bool
+	// FetchLatestDatapoint requests the latest datapoint of each series within the
+	// query range when only fetching series IDs.
+	FetchLatestDatapoint bool
 	// ReadConsistencyLevel defines the read consistency at the query level.
 	// Overrides the level defined by the database.
 	ReadConsistencyLevel *topology.ReadConsistencyLevel
@@ -432,13 +436,35 @@ type Block interface {
 	// MemorySegmentsData returns all in memory segments data.
 	MemorySegmentsData(ctx context.Context) ([]fst.SegmentData, error)
 
+	// FlushedSegments returns the immutable segments of the block that have
+	// been flushed to or bootstrapped from disk.
+	FlushedSegments() (BlockFlushedSegments, error)
+
+	// Version returns the version of the block, it changes every time
+	// documents or segments are added to the block.
+	Version() uint64
+
 	// BackgroundCompact background compacts eligible segments.
 	BackgroundCompact()
 
+	// Compact synchronously compacts the eligible mutable segments of the
+	// block, waiting for any background compaction in progress to complete.
+	Compact() (BlockCompactResult, error)
+
 	// Close will release any held resources and close the Block.
 	Close() error
 }
 
+// BlockFlushedSegments is the set of immutable segments of a block
+// along with the version of the block they were collected at.
+type BlockFlushedSegments struct {
+	Segments []segment.Segment
+	Version  uint64
+	// Unflushed is true when the block also holds documents in mutable
+	// segments that are not contained by the flushed segments.
+	Unflushed bool
+}
+
 // EvictMutableSegmentResults returns statistics about the EvictMutableSegments execution.
 type EvictMutableSegmentResults struct {
 	NumMutableSegments int64
@@ -544,6 +570,13 @@ type BlockTickResult struct {
 	FreeMmap                int64
 }
 
+// BlockCompactResult returns statistics about a forced compaction of a block.
+type BlockCompactResult struct {
+	BlockStart           xtime.UnixNano
+	NumSegmentsCompacted int
+	NumDocs              int64
+}
+
 // WriteBatch is a batch type that allows for building of a slice of documents
 // with metadata in a separate slice, this allows the documents slice to be
 // passed to the segment to batch insert without having to copy into a buffer
@@ -615,6 +648,11 @@ func (b *WriteBatch) appendWithResult(
 	b.docs = append(b.docs, doc)
 }
 
+// UpdateDoc replaces the document of the entry at the given index.
+func (b *WriteBatch) UpdateDoc(idx int, doc doc.Metadata) {
+	b.docs[idx] = doc
+}
+
 // ForEachWriteBatchEntryFn allows a caller to perform an operation for each
 // batch entry.
 type ForEachWriteBatchEntryFn func(
@@ -1107,4 +1145,10 @@ type Options interface {
 
 	// QueryLimits returns the current query limits.
 	QueryLimits() limits.QueryLimits
+
+	// SetTimeWindowCompactionOptions sets the time window compaction options.
+	SetTimeWindowCompactionOptions(value TimeWindowCompactionOptions) Options
+
+	// TimeWindowCompactionOptions returns the time window compaction options.
+	TimeWindowCompactionOptions() TimeWindowCompactionOptions
 }
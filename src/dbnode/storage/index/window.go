@@ -0,0 +1,565 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/storage/index/compaction"
+	"github.com/m3db/m3/src/m3ninx/doc"
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/index/segment"
+	"github.com/m3db/m3/src/m3ninx/postings"
+	"github.com/m3db/m3/src/m3ninx/postings/roaring"
+	"github.com/m3db/m3/src/x/context"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/mmap"
+	xresource "github.com/m3db/m3/src/x/resource"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+const (
+	defaultTimeWindowCompactionWindow   = 7 * 24 * time.Hour
+	defaultTimeWindowCompactionInterval = 10 * time.Minute
+
+	mmapIndexWindowName = "mmap.index.window"
+)
+
+var (
+	// ErrWindowClosed is returned when querying a closed window.
+	ErrWindowClosed = errors.New("unable to query, index window is closed")
+
+	errWindowNoSegments              = errors.New("no flushed segments to merge into index window")
+	errWindowAlreadyClosed           = errors.New("unable to close, index window already closed")
+	errTimeWindowNotPositive         = errors.New("time window compaction window must be positive")
+	errTimeWindowIntervalNotPositive = errors.New("time window compaction interval must be positive")
+)
+
+// TimeWindowCompactionOptions is the options struct for merging the flushed
+// segments of sealed index blocks into coarser time windows.
+type TimeWindowCompactionOptions struct {
+	// Enabled determines whether sealed index blocks are merged into windows.
+	Enabled bool
+	// Window is the size of each time window, windows are aligned to the
+	// window size which must be a multiple of the namespace index block size.
+	Window time.Duration
+	// Interval is how often to check for time windows that need merging.
+	Interval time.Duration
+}
+
+// NewTimeWindowCompactionOptions returns the default time window compaction
+// options, which have time window compaction disabled.
+func NewTimeWindowCompactionOptions() TimeWindowCompactionOptions {
+	return TimeWindowCompactionOptions{
+		Window:   defaultTimeWindowCompactionWindow,
+		Interval: defaultTimeWindowCompactionInterval,
+	}
+}
+
+// Validate validates the time window compaction options.
+func (o TimeWindowCompactionOptions) Validate() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Window <= 0 {
+		return errTimeWindowNotPositive
+	}
+	if o.Interval <= 0 {
+		return errTimeWindowIntervalNotPositive
+	}
+	return nil
+}
+
+// ValidateForBlockSize validates that the windows can be composed of whole
+// index blocks of the given size.
+func (o TimeWindowCompactionOptions) ValidateForBlockSize(blockSize time.Duration) error {
+	if o.Window <= blockSize || o.Window%blockSize != 0 {
+		return fmt.Errorf("time window compaction window %v must be a multiple "+
+			"of and larger than the index block size %v", o.Window, blockSize)
+	}
+	return nil
+}
+
+// WindowBlockSegments are the flushed segments of a single index block to
+// merge into a window.
+type WindowBlockSegments struct {
+	BlockStart xtime.UnixNano
+	Version    uint64
+	Segments   []segment.Segment
+}
+
+// Window is a read only view of the sealed index blocks within a time window,
+// backed by a single segment merged from the flushed segments of each block.
+// The documents indexed for each block start are tracked so that queries
+// against the window can be narrowed to the blocks overlapping the query.
+type Window interface {
+	// StartTime returns the start of the time window.
+	StartTime() xtime.UnixNano
+
+	// EndTime returns the end of the time window.
+	EndTime() xtime.UnixNano
+
+	// Covers returns whether the window holds every document of the block
+	// with the given start as of the given block version.
+	Covers(blockStart xtime.UnixNano, version uint64) bool
+
+	// NumBlocks returns the number of blocks merged into the window.
+	NumBlocks() int
+
+	// NumDocs returns the number of documents in the window.
+	NumDocs() int64
+
+	// QueryBlock returns a block that queries the window narrowed to the
+	// documents indexed for block starts overlapping the query range, the
+	// window is kept open until the context is finalized.
+	QueryBlock(ctx context.Context, queryRange xtime.Range) (Block, error)
+
+	// Close closes the window, the merged segment is released once all
+	// outstanding queries have finished.
+	Close() error
+}
+
+type window struct {
+	sync.Mutex
+
+	start     xtime.UnixNano
+	end       xtime.UnixNano
+	blockSize time.Duration
+	segment   segment.Segment
+	versions  map[xtime.UnixNano]uint64
+	postings  map[xtime.UnixNano]postings.List
+	closed    bool
+	refs      int
+
+	opts    Options
+	nsMD    namespace.Metadata
+	metrics blockMetrics
+}
+
+// NewWindow merges the flushed segments of the given blocks into a window
+// starting at the given time.
+func NewWindow(
+	windowStart xtime.UnixNano,
+	blocks []WindowBlockSegments,
+	md namespace.Metadata,
+	opts Options,
+) (Window, error) {
+	var (
+		segs        []segment.Segment
+		blockStarts = make(map[segment.Segment]xtime.UnixNano)
+		versions    = make(map[xtime.UnixNano]uint64, len(blocks))
+	)
+	for _, b := range blocks {
+		versions[b.BlockStart] = b.Version
+		for _, seg := range b.Segments {
+			segs = append(segs, seg)
+			blockStarts[seg] = b.BlockStart
+		}
+	}
+	if len(segs) == 0 {
+		return nil, errWindowNoSegments
+	}
+
+	compactor, err := compaction.NewCompactor(opts.MetadataArrayPool(),
+		MetadataArrayPoolCapacity,
+		opts.SegmentBuilderOptions(),
+		opts.FSTSegmentOptions(),
+		compaction.CompactorOptions{
+			// NB: The documents must be copied out of the block segments since
+			// the blocks are free to close their segments independently.
+			MmapDocsData: true,
+		})
+	if err != nil {
+		return nil, err
+	}
+	defer compactor.Close()
+
+	result, err := compactor.Compact(segs, nil, mmap.ReporterOptions{
+		Context: mmap.Context{
+			Name: mmapIndexWindowName,
+		},
+		Reporter: opts.MmapReporter(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	postingsByBlockStart, err := windowPostingsByBlockStart(result, blockStarts)
+	if err != nil {
+		return nil, xerrors.FirstError(err, result.Compacted.Close())
+	}
+
+	var (
+		scope = opts.InstrumentOptions().MetricsScope().
+			SubScope("index").SubScope("window")
+		plCaches = ReadThroughSegmentCaches{
+			SegmentPostingsListCache: opts.PostingsListCache(),
+			SearchPostingsListCache:  opts.SearchPostingsListCache(),
+		}
+		blockSize = md.Options().IndexOptions().BlockSize()
+	)
+	return &window{
+		start:     windowStart,
+		end:       windowStart.Add(opts.TimeWindowCompactionOptions().Window),
+		blockSize: blockSize,
+		segment: NewReadThroughSegment(result.Compacted, plCaches,
+			opts.ReadThroughSegmentOptions()),
+		versions: versions,
+		postings: postingsByBlockStart,
+		refs:     1,
+		opts:     opts,
+		nsMD:     md,
+		metrics:  newBlockMetrics(scope),
+	}, nil
+}
+
+// windowPostingsByBlockStart returns the postings of the merged segment
+// documents indexed for each block start.
+func windowPostingsByBlockStart(
+	result compaction.CompactResult,
+	blockStarts map[segment.Segment]xtime.UnixNano,
+) (map[xtime.UnixNano]postings.List, error) {
+	merged, err := result.Compacted.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer merged.Close()
+
+	byBlockStart := make(map[xtime.UnixNano]postings.MutableList)
+	for _, meta := range result.SegmentMetadatas {
+		blockStart := blockStarts[meta.Segment]
+		pl, ok := byBlockStart[blockStart]
+		if !ok {
+			pl = roaring.NewPostingsList()
+			byBlockStart[blockStart] = pl
+		}
+		if err := addWindowPostings(pl, meta, merged); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make(map[xtime.UnixNano]postings.List, len(byBlockStart))
+	for blockStart, pl := range byBlockStart {
+		results[blockStart] = pl
+	}
+	return results, nil
+}
+
+// addWindowPostings adds the merged segment postings of every document in
+// a source segment to the postings list.
+func addWindowPostings(
+	pl postings.MutableList,
+	meta segment.SegmentsBuilderSegmentMetadata,
+	merged segment.Reader,
+) error {
+	reader, err := meta.Segment.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	iter, err := reader.AllDocs()
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for iter.Next() {
+		id := iter.PostingsID()
+		if idx := int(id); idx < len(meta.NegativeOffsets) && meta.NegativeOffsets[idx] != -1 {
+			// Document was merged from this segment, translate the postings ID.
+			if err := pl.Insert(id + meta.Offset - postings.ID(meta.NegativeOffsets[idx])); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Document is a duplicate that was merged from another segment,
+		// look up its postings ID in the merged segment.
+		matched, err := merged.MatchTerm(doc.IDReservedFieldName, iter.Current().ID)
+		if err != nil {
+			return err
+		}
+		if err := pl.AddIterator(matched.Iterator()); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func (w *window) StartTime() xtime.UnixNano {
+	return w.start
+}
+
+func (w *window) EndTime() xtime.UnixNano {
+	return w.end
+}
+
+func (w *window) Covers(blockStart xtime.UnixNano, version uint64) bool {
+	v, ok := w.versions[blockStart]
+	return ok && v == version
+}
+
+func (w *window) NumBlocks() int {
+	return len(w.versions)
+}
+
+func (w *window) NumDocs() int64 {
+	return w.segment.Size()
+}
+
+func (w *window) QueryBlock(ctx context.Context, queryRange xtime.Range) (Block, error) {
+	w.Lock()
+	if w.closed {
+		w.Unlock()
+		return nil, ErrWindowClosed
+	}
+	w.refs++
+	w.Unlock()
+
+	ctx.RegisterFinalizer(xresource.FinalizerFn(func() {
+		w.decRef()
+	}))
+
+	allowed, err := w.allowedPostings(queryRange)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &block{
+		state:          blockStateSealed,
+		blockStart:     w.start,
+		blockEnd:       w.end,
+		blockSize:      w.blockSize,
+		opts:           w.opts,
+		iopts:          w.opts.InstrumentOptions(),
+		nsMD:           w.nsMD,
+		metrics:        w.metrics,
+		logger:         w.opts.InstrumentOptions().Logger(),
+		fetchDocsLimit: w.opts.QueryLimits().FetchDocsLimit(),
+		aggDocsLimit:   w.opts.QueryLimits().AggregateDocsLimit(),
+	}
+	b.newFieldsAndTermsIteratorFn = newFieldsAndTermsIterator
+	b.newSegmentReadersWithRLockFn = func() ([]segment.Reader, error) {
+		reader, err := w.segment.Reader()
+		if err != nil {
+			return nil, err
+		}
+		if allowed == nil {
+			return []segment.Reader{reader}, nil
+		}
+		return []segment.Reader{newWindowReader(reader, allowed)}, nil
+	}
+	b.newExecutorWithRLockFn = b.executorWithRLock
+	b.addAggregateResultsFn = b.addAggregateResults
+	return b, nil
+}
+
+// allowedPostings returns the postings of documents indexed for block starts
+// overlapping the query range, or nil if every block start overlaps it.
+func (w *window) allowedPostings(queryRange xtime.Range) (postings.List, error) {
+	overlapping := make([]postings.List, 0, len(w.postings))
+	for blockStart, pl := range w.postings {
+		blockRange := xtime.Range{Start: blockStart, End: blockStart.Add(w.blockSize)}
+		if queryRange.Overlaps(blockRange) {
+			overlapping = append(overlapping, pl)
+		}
+	}
+	if len(overlapping) == len(w.postings) {
+		// No narrowing required.
+		return nil, nil
+	}
+	return roaring.Union(overlapping)
+}
+
+func (w *window) decRef() {
+	w.Lock()
+	w.refs--
+	release := w.refs == 0
+	w.Unlock()
+	if release {
+		_ = w.segment.Close()
+	}
+}
+
+func (w *window) Close() error {
+	w.Lock()
+	if w.closed {
+		w.Unlock()
+		return errWindowAlreadyClosed
+	}
+	w.closed = true
+	w.Unlock()
+	w.decRef()
+	return nil
+}
+
+// windowReader narrows the postings returned by a segment reader to an
+// allowed set of documents.
+type windowReader struct {
+	segment.Reader
+
+	allowed postings.List
+}
+
+func newWindowReader(reader segment.Reader, allowed postings.List) segment.Reader {
+	return &windowReader{Reader: reader, allowed: allowed}
+}
+
+func (r *windowReader) MatchField(field []byte) (postings.List, error) {
+	pl, err := r.Reader.MatchField(field)
+	if err != nil {
+		return nil, err
+	}
+	return r.allowed.Intersect(pl)
+}
+
+func (r *windowReader) MatchTerm(field, term []byte) (postings.List, error) {
+	pl, err := r.Reader.MatchTerm(field, term)
+	if err != nil {
+		return nil, err
+	}
+	return r.allowed.Intersect(pl)
+}
+
+func (r *windowReader) MatchRegexp(field []byte, c m3ninxindex.CompiledRegex) (postings.List, error) {
+	pl, err := r.Reader.MatchRegexp(field, c)
+	if err != nil {
+		return nil, err
+	}
+	return r.allowed.Intersect(pl)
+}
+
+func (r *windowReader) MatchAll() (postings.List, error) {
+	return r.allowed.CloneAsMutable(), nil
+}
+
+func (r *windowReader) AllDocs() (m3ninxindex.IDDocIterator, error) {
+	return m3ninxindex.NewIDDocIterator(r.Reader, r.allowed.Iterator()), nil
+}
+
+func (r *windowReader) FieldsPostingsList() (segment.FieldsPostingsListIterator, error) {
+	iter, err := r.Reader.FieldsPostingsList()
+	if err != nil {
+		return nil, err
+	}
+	return &windowFieldsPostingsListIter{
+		FieldsPostingsListIterator: iter,
+		allowed:                    r.allowed,
+	}, nil
+}
+
+func (r *windowReader) Terms(field []byte) (segment.TermsIterator, error) {
+	iter, err := r.Reader.Terms(field)
+	if err != nil {
+		return nil, err
+	}
+	return &windowTermsIter{
+		TermsIterator: iter,
+		allowed:       r.allowed,
+	}, nil
+}
+
+// windowFieldsPostingsListIter skips fields without any allowed documents.
+type windowFieldsPostingsListIter struct {
+	segment.FieldsPostingsListIterator
+
+	allowed  postings.List
+	field    []byte
+	postings postings.List
+	err      error
+}
+
+func (i *windowFieldsPostingsListIter) Next() bool {
+	if i.err != nil {
+		return false
+	}
+	for i.FieldsPostingsListIterator.Next() {
+		field, pl := i.FieldsPostingsListIterator.Current()
+		narrowed, err := i.allowed.Intersect(pl)
+		if err != nil {
+			i.err = err
+			return false
+		}
+		if narrowed.IsEmpty() {
+			continue
+		}
+		i.field, i.postings = field, narrowed
+		return true
+	}
+	return false
+}
+
+func (i *windowFieldsPostingsListIter) Current() ([]byte, postings.List) {
+	return i.field, i.postings
+}
+
+func (i *windowFieldsPostingsListIter) Err() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.FieldsPostingsListIterator.Err()
+}
+
+// windowTermsIter skips terms without any allowed documents.
+type windowTermsIter struct {
+	segment.TermsIterator
+
+	allowed  postings.List
+	term     []byte
+	postings postings.List
+	err      error
+}
+
+func (i *windowTermsIter) Next() bool {
+	if i.err != nil {
+		return false
+	}
+	for i.TermsIterator.Next() {
+		term, pl := i.TermsIterator.Current()
+		narrowed, err := i.allowed.Intersect(pl)
+		if err != nil {
+			i.err = err
+			return false
+		}
+		if narrowed.IsEmpty() {
+			continue
+		}
+		i.term, i.postings = term, narrowed
+		return true
+	}
+	return false
+}
+
+func (i *windowTermsIter) Current() ([]byte, postings.List) {
+	return i.term, i.postings
+}
+
+func (i *windowTermsIter) Err() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.TermsIterator.Err()
+}
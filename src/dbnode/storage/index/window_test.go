@@ -0,0 +1,155 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index/segment"
+	"github.com/m3db/m3/src/m3ninx/index/segment/fst"
+	"github.com/m3db/m3/src/x/context"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWindowSegment(t *testing.T, ids ...string) segment.Segment {
+	docs := make([]doc.Metadata, 0, len(ids))
+	for _, id := range ids {
+		docs = append(docs, doc.Metadata{
+			ID: []byte(id),
+			Fields: []doc.Field{
+				{Name: []byte("name"), Value: []byte(id)},
+			},
+		})
+	}
+	memSeg := testSegment(t, docs...).(segment.MutableSegment)
+	return fst.ToTestSegment(t, memSeg, testFstOptions)
+}
+
+func requireWindowQueryIDs(
+	t *testing.T,
+	w Window,
+	queryRange xtime.Range,
+	expected ...string,
+) {
+	ctx := context.NewBackground()
+	defer ctx.Close()
+
+	b, err := w.QueryBlock(ctx, queryRange)
+	require.NoError(t, err)
+
+	readers, err := b.(*block).newSegmentReadersWithRLockFn()
+	require.NoError(t, err)
+	require.Len(t, readers, 1)
+	defer func() {
+		require.NoError(t, readers[0].Close())
+	}()
+
+	iter, err := readers[0].AllDocs()
+	require.NoError(t, err)
+
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, string(iter.Current().ID))
+	}
+	require.NoError(t, iter.Err())
+	require.NoError(t, iter.Close())
+
+	sort.Strings(ids)
+	require.Equal(t, expected, ids)
+}
+
+func TestTimeWindowCompactionOptionsValidateForBlockSize(t *testing.T) {
+	opts := NewTimeWindowCompactionOptions()
+	require.False(t, opts.Enabled)
+	require.NoError(t, opts.Validate())
+	require.NoError(t, opts.ValidateForBlockSize(time.Hour))
+	require.Error(t, opts.ValidateForBlockSize(5*time.Hour))
+	require.Error(t, opts.ValidateForBlockSize(opts.Window))
+
+	opts.Interval = 0
+	require.NoError(t, opts.Validate())
+	opts.Enabled = true
+	require.Error(t, opts.Validate())
+}
+
+func TestWindowQueryBlockNarrowsToOverlappingBlocks(t *testing.T) {
+	var (
+		md   = newTestNSMetadata(t)
+		opts = testOpts.SetTimeWindowCompactionOptions(TimeWindowCompactionOptions{
+			Enabled:  true,
+			Window:   2 * time.Hour,
+			Interval: time.Minute,
+		})
+		start  = xtime.Now().Truncate(2 * time.Hour)
+		second = start.Add(time.Hour)
+	)
+
+	w, err := NewWindow(start, []WindowBlockSegments{
+		{
+			BlockStart: start,
+			Version:    3,
+			Segments: []segment.Segment{
+				newTestWindowSegment(t, "foo"),
+				newTestWindowSegment(t, "bar"),
+			},
+		},
+		{
+			BlockStart: second,
+			Version:    5,
+			Segments: []segment.Segment{
+				newTestWindowSegment(t, "bar", "baz"),
+			},
+		},
+	}, md, opts)
+	require.NoError(t, err)
+
+	require.Equal(t, start, w.StartTime())
+	require.Equal(t, start.Add(2*time.Hour), w.EndTime())
+	require.Equal(t, 2, w.NumBlocks())
+	require.Equal(t, int64(3), w.NumDocs())
+	require.True(t, w.Covers(start, 3))
+	require.False(t, w.Covers(start, 4))
+	require.True(t, w.Covers(second, 5))
+	require.False(t, w.Covers(start.Add(2*time.Hour), 0))
+
+	requireWindowQueryIDs(t, w, xtime.Range{Start: start, End: start.Add(2 * time.Hour)},
+		"bar", "baz", "foo")
+	requireWindowQueryIDs(t, w, xtime.Range{Start: start, End: second},
+		"bar", "foo")
+	requireWindowQueryIDs(t, w, xtime.Range{Start: second, End: second.Add(time.Minute)},
+		"bar", "baz")
+
+	// Queries in flight keep the window open after close.
+	ctx := context.NewBackground()
+	_, err = w.QueryBlock(ctx, xtime.Range{Start: start, End: second})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	ctx.Close()
+
+	_, err = w.QueryBlock(context.NewBackground(), xtime.Range{Start: start, End: second})
+	require.Equal(t, ErrWindowClosed, err)
+	require.Equal(t, errWindowAlreadyClosed, w.Close())
+}
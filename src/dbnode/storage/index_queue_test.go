@@ -21,6 +21,7 @@
 package storage
 
 import (
+	stdctx "context"
 	"fmt"
 	"sync"
 	"testing"
@@ -30,6 +31,7 @@ import (
 	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	idxconvert "github.com/m3db/m3/src/dbnode/storage/index/convert"
+	"github.com/m3db/m3/src/dbnode/ts/writes"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	m3ninxidx "github.com/m3db/m3/src/m3ninx/idx"
 	"github.com/m3db/m3/src/m3ninx/index/segment/fst/encoding/docs"
@@ -206,6 +208,137 @@ func TestNamespaceIndexWriteQueueError(t *testing.T) {
 		testWriteBatchBlockSizeOption(idx.blockSize))))
 }
 
+func TestNamespaceIndexWriteIndexingPaused(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	q := NewMocknamespaceIndexInsertQueue(ctrl)
+	newFn := func(
+		fn nsIndexInsertBatchFn,
+		md namespace.Metadata,
+		nowFn clock.NowFn,
+		coreFn xsync.CoreFn,
+		s tally.Scope,
+	) namespaceIndexInsertQueue {
+		return q
+	}
+	q.EXPECT().Start().Return(nil)
+
+	md, err := namespace.NewMetadata(defaultTestNs1ID, defaultTestNs1Opts)
+	require.NoError(t, err)
+	runtimeOptsMgr := namespace.NewRuntimeOptionsManager(md.ID().String())
+	paused := true
+	require.NoError(t, runtimeOptsMgr.Update(
+		namespace.NewRuntimeOptions().SetIndexingPaused(&paused)))
+
+	dbIdx, err := newNamespaceIndexWithInsertQueueFn(md, runtimeOptsMgr,
+		testShardSet, newFn, DefaultTestOptions())
+	require.NoError(t, err)
+	idx, ok := dbIdx.(*nsIndex)
+	require.True(t, ok)
+	defer func() {
+		q.EXPECT().Stop().Return(nil)
+		require.NoError(t, idx.Close())
+	}()
+
+	var (
+		id   = ident.StringID("foo")
+		tags = ident.NewTags(ident.StringTag("name", "value"))
+		now  = xtime.Now()
+	)
+
+	// Neither write should reach the insert queue while paused.
+	lifecycle := doc.NewMockOnIndexSeries(ctrl)
+	lifecycle.EXPECT().IfAlreadyIndexedMarkIndexSuccessAndFinalize(gomock.Any()).
+		Return(false).
+		Times(2)
+	lifecycle.EXPECT().OnIndexFinalize(now.Truncate(idx.blockSize)).Times(2)
+
+	entry, document := testWriteBatchEntry(id, tags, now, lifecycle)
+	require.NoError(t, idx.WriteBatch(testWriteBatch(entry, document,
+		testWriteBatchBlockSizeOption(idx.blockSize))))
+	require.NoError(t, idx.WritePending([]writes.PendingIndexInsert{
+		{Entry: entry, Document: document},
+	}))
+}
+
+func TestNamespaceIndexWithoutExcludedTags(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	q := NewMocknamespaceIndexInsertQueue(ctrl)
+	newFn := func(
+		fn nsIndexInsertBatchFn,
+		md namespace.Metadata,
+		nowFn clock.NowFn,
+		coreFn xsync.CoreFn,
+		s tally.Scope,
+	) namespaceIndexInsertQueue {
+		return q
+	}
+	q.EXPECT().Start().Return(nil)
+	nsOpts := defaultTestNs1Opts.SetIndexOptions(defaultTestNs1Opts.IndexOptions().
+		SetExcludedTags([]string{"trace_id", "span_*"}))
+	md, err := namespace.NewMetadata(defaultTestNs1ID, nsOpts)
+	require.NoError(t, err)
+	dbIdx, err := newNamespaceIndexWithInsertQueueFn(md,
+		namespace.NewRuntimeOptionsManager(md.ID().String()),
+		testShardSet, newFn, DefaultTestOptions())
+	require.NoError(t, err)
+	idx, ok := dbIdx.(*nsIndex)
+	require.True(t, ok)
+
+	var (
+		name    = doc.Field{Name: []byte("name"), Value: []byte("value")}
+		spanID  = doc.Field{Name: []byte("span_id"), Value: []byte("1")}
+		traceID = doc.Field{Name: []byte("trace_id"), Value: []byte("2")}
+		zone    = doc.Field{Name: []byte("zone"), Value: []byte("a")}
+		d       = doc.Metadata{
+			ID:     []byte("foo"),
+			Fields: []doc.Field{name, spanID, traceID, zone},
+		}
+	)
+	filtered, ok := idx.withoutExcludedTags(d)
+	require.True(t, ok)
+	require.Equal(t, []doc.Field{name, zone}, filtered.Fields)
+	// The series metadata still holds the excluded tags.
+	require.Equal(t, []doc.Field{name, spanID, traceID, zone}, d.Fields)
+
+	_, ok = idx.withoutExcludedTags(filtered)
+	require.False(t, ok)
+
+	q.EXPECT().Stop().Return(nil)
+	require.NoError(t, idx.Close())
+}
+
+func TestNamespaceIndexWaitForInserts(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	dbIdx, q := newTestNamespaceIndex(t, ctrl)
+	defer func() {
+		q.EXPECT().Stop().Return(nil)
+		require.NoError(t, dbIdx.Close())
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	q.EXPECT().InsertPending(gomock.Len(0)).Return(&wg, nil).Times(2)
+
+	// Times out while the insert queue has not indexed the batch.
+	goCtx, cancel := stdctx.WithTimeout(stdctx.Background(), 10*time.Millisecond)
+	defer cancel()
+	ctx := context.NewWithGoContext(goCtx)
+	require.Error(t, dbIdx.WaitForInserts(ctx))
+	ctx.Close()
+
+	// Returns once the insert queue has indexed the batch.
+	wg.Done()
+	ctx = context.NewBackground()
+	defer ctx.Close()
+	require.NoError(t, dbIdx.WaitForInserts(ctx))
+}
+
 func TestNamespaceIndexInsertOlderThanRetentionPeriod(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
@@ -403,6 +536,43 @@ func TestNamespaceIndexInsertQuery(t *testing.T) {
 		tags))
 }
 
+func TestNamespaceIndexAddFlushedSeries(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.NewBackground()
+	defer ctx.Close()
+
+	idx, q := newTestNamespaceIndex(t, ctrl)
+	defer func() {
+		q.EXPECT().Stop().Return(nil)
+		require.NoError(t, idx.Close())
+	}()
+
+	var (
+		blockSize  = defaultTestNs1Opts.RetentionOptions().BlockSize()
+		blockStart = xtime.Now().Truncate(blockSize).Add(-blockSize)
+		fields     = []doc.Field{{Name: []byte("name"), Value: []byte("value")}}
+	)
+	require.NoError(t, idx.AddFlushedSeries(0, blockStart, nil))
+	// Duplicate IDs are tolerated.
+	require.NoError(t, idx.AddFlushedSeries(0, blockStart, []doc.Metadata{
+		{ID: []byte("foo"), Fields: fields},
+		{ID: []byte("foo"), Fields: fields},
+	}))
+
+	res, err := idx.Query(ctx, index.Query{
+		Query: m3ninxidx.NewTermQuery([]byte("name"), []byte("value")),
+	}, index.QueryOptions{
+		StartInclusive: blockStart,
+		EndExclusive:   blockStart.Add(blockSize),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, res.Results.Size())
+	_, ok := res.Results.Map().Get(ident.BytesID("foo"))
+	require.True(t, ok)
+}
+
 func TestNamespaceIndexInsertAggregateQuery(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
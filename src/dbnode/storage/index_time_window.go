@@ -0,0 +1,273 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/retention"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/x/context"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"go.uber.org/zap"
+)
+
+// timeWindowCandidate is a time window whose blocks need to be merged.
+type timeWindowCandidate struct {
+	windowStart xtime.UnixNano
+	blocks      []index.Block
+}
+
+func (i *nsIndex) compactTimeWindowsUntilClosed() {
+	ticker := time.NewTicker(i.timeWindowCompactionOpts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := i.compactTimeWindows(); err != nil {
+				i.logger.Warn("could not compact index time windows", zap.Error(err))
+			}
+		case <-i.state.closeCh:
+			return
+		}
+	}
+}
+
+// compactTimeWindows merges the flushed segments of the sealed blocks within
+// each time window that has not been merged yet or whose blocks have changed
+// since the window was merged.
+func (i *nsIndex) compactTimeWindows() error {
+	var multiErr xerrors.MultiError
+	for _, candidate := range i.timeWindowCandidates(xtime.ToUnixNano(i.nowFn())) {
+		if err := i.compactTimeWindow(candidate); err != nil {
+			i.metrics.timeWindowCompactionErrors.Inc(1)
+			multiErr = multiErr.Add(err)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func (i *nsIndex) timeWindowCandidates(now xtime.UnixNano) []timeWindowCandidate {
+	var (
+		window             = i.timeWindowCompactionOpts.Window
+		earliestBlockStart = retention.FlushTimeStartForRetentionPeriod(
+			i.retentionPeriod, i.blockSize, now)
+		lastSealableBlockStart = i.lastSealableBlockStart(now)
+		blocksByWindowStart    = make(map[xtime.UnixNano][]index.Block)
+	)
+
+	i.state.RLock()
+	defer i.state.RUnlock()
+	if !i.isOpenWithRLock() {
+		return nil
+	}
+
+	for blockStart, block := range i.state.blocksByTime {
+		windowStart := blockStart.Truncate(window)
+		lastBlockStart := windowStart.Add(window - i.blockSize)
+		if windowStart.Before(earliestBlockStart) || lastBlockStart.After(lastSealableBlockStart) {
+			// Only merge windows that are entirely within retention and
+			// whose blocks can all be sealed.
+			continue
+		}
+		blocksByWindowStart[windowStart] = append(blocksByWindowStart[windowStart], block)
+	}
+
+	candidates := make([]timeWindowCandidate, 0, len(blocksByWindowStart))
+	for windowStart, blocks := range blocksByWindowStart {
+		if len(blocks) < 2 {
+			// Nothing to gain from merging a single block.
+			continue
+		}
+		if w, ok := i.state.windowsImmutable[windowStart]; ok && timeWindowCoversBlocks(w, blocks) {
+			continue
+		}
+		candidates = append(candidates, timeWindowCandidate{
+			windowStart: windowStart,
+			blocks:      blocks,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].windowStart < candidates[j].windowStart
+	})
+	return candidates
+}
+
+func timeWindowCoversBlocks(w index.Window, blocks []index.Block) bool {
+	if w.NumBlocks() != len(blocks) {
+		return false
+	}
+	for _, block := range blocks {
+		if !w.Covers(block.StartTime(), block.Version()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *nsIndex) compactTimeWindow(candidate timeWindowCandidate) error {
+	segments := make([]index.WindowBlockSegments, 0, len(candidate.blocks))
+	for _, block := range candidate.blocks {
+		if !block.IsSealed() {
+			// Wait for the block to be sealed on a subsequent tick.
+			return nil
+		}
+		flushed, err := block.FlushedSegments()
+		if err != nil {
+			return err
+		}
+		if flushed.Unflushed {
+			// Wait for the block to be flushed before merging the window,
+			// otherwise the block would still need to be queried.
+			return nil
+		}
+		segments = append(segments, index.WindowBlockSegments{
+			BlockStart: block.StartTime(),
+			Version:    flushed.Version,
+			Segments:   flushed.Segments,
+		})
+	}
+
+	start := i.nowFn()
+	w, err := index.NewWindow(candidate.windowStart, segments,
+		i.nsMetadata, i.opts.IndexOptions())
+	if err != nil {
+		return err
+	}
+	took := i.nowFn().Sub(start)
+
+	i.state.Lock()
+	if !i.isOpenWithRLock() {
+		i.state.Unlock()
+		return w.Close()
+	}
+	windows := make(map[xtime.UnixNano]index.Window, len(i.state.windowsImmutable)+1)
+	for windowStart, existing := range i.state.windowsImmutable {
+		windows[windowStart] = existing
+	}
+	replaced := windows[candidate.windowStart]
+	windows[candidate.windowStart] = w
+	i.state.windowsImmutable = windows
+	i.state.Unlock()
+
+	i.metrics.timeWindowCompactionSuccess.Inc(1)
+	i.metrics.timeWindowCompactionLatency.Record(took)
+	i.logger.Debug("compacted index time window",
+		zap.Stringer("namespace", i.nsMetadata.ID()),
+		zap.Time("windowStart", candidate.windowStart.ToTime()),
+		zap.Int("numBlocks", w.NumBlocks()),
+		zap.Int64("numDocs", w.NumDocs()),
+		zap.Duration("took", took))
+
+	if replaced != nil {
+		// Outstanding queries keep the replaced window open until done.
+		return replaced.Close()
+	}
+	return nil
+}
+
+// removeExpiredTimeWindowsWithLock closes and removes any time windows
+// holding blocks before the earliest block start to retain.
+func (i *nsIndex) removeExpiredTimeWindowsWithLock(
+	earliestBlockStartToRetain xtime.UnixNano,
+) error {
+	var expired []xtime.UnixNano
+	for windowStart := range i.state.windowsImmutable {
+		if windowStart.Before(earliestBlockStartToRetain) {
+			expired = append(expired, windowStart)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	windows := make(map[xtime.UnixNano]index.Window, len(i.state.windowsImmutable))
+	for windowStart, w := range i.state.windowsImmutable {
+		windows[windowStart] = w
+	}
+	var multiErr xerrors.MultiError
+	for _, windowStart := range expired {
+		multiErr = multiErr.Add(windows[windowStart].Close())
+		delete(windows, windowStart)
+	}
+	i.state.windowsImmutable = windows
+	return multiErr.FinalError()
+}
+
+// queryTimeWindows resolves the time windows to query in place of the
+// blocks they cover for a single query.
+type queryTimeWindows struct {
+	windows    map[xtime.UnixNano]index.Window
+	window     time.Duration
+	queryRange xtime.Range
+	queried    map[xtime.UnixNano]struct{}
+}
+
+func newQueryTimeWindows(
+	windows map[xtime.UnixNano]index.Window,
+	opts index.TimeWindowCompactionOptions,
+	queryRange xtime.Range,
+) queryTimeWindows {
+	return queryTimeWindows{
+		windows:    windows,
+		window:     opts.Window,
+		queryRange: queryRange,
+	}
+}
+
+// blockFor returns the window block to query for the given block if the
+// window holding it has not been queried yet, and whether the window holds
+// all of the block's documents so the block itself need not be queried.
+func (q *queryTimeWindows) blockFor(
+	ctx context.Context,
+	block index.Block,
+) (index.Block, bool) {
+	if len(q.windows) == 0 {
+		return nil, false
+	}
+
+	blockStart := block.StartTime()
+	w, ok := q.windows[blockStart.Truncate(q.window)]
+	if !ok {
+		return nil, false
+	}
+
+	covered := w.Covers(blockStart, block.Version())
+	if _, ok := q.queried[w.StartTime()]; ok {
+		return nil, covered
+	}
+
+	windowBlock, err := w.QueryBlock(ctx, q.queryRange)
+	if err != nil {
+		// The window was closed concurrently, query the block itself.
+		return nil, false
+	}
+	if q.queried == nil {
+		q.queried = make(map[xtime.UnixNano]struct{})
+	}
+	q.queried[w.StartTime()] = struct{}{}
+	return windowBlock, covered
+}
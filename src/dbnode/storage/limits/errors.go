@@ -33,6 +33,15 @@ func NewQueryLimitExceededError(msg string) error {
 	}
 }
 
+// NewQueryLimitExceededErrorWithLimit creates a query limit exceeded error
+// that carries the name and value of the limit that was exceeded.
+func NewQueryLimitExceededErrorWithLimit(msg, resource string, limit int64) error {
+	details := xerrors.NewErrorDetails(xerrors.CodeResourceExhausted)
+	details.Resource = resource
+	details.Limit = limit
+	return xerrors.NewStructuredError(NewQueryLimitExceededError(msg), details)
+}
+
 func (err *queryLimitExceededError) Error() string {
 	return err.msg
 }
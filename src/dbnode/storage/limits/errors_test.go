@@ -87,6 +87,21 @@ func TestIsQueryLimitExceededError(t *testing.T) {
 	}
 }
 
+func TestQueryLimitExceededErrorWithLimit(t *testing.T) {
+	err := xerrors.NewInvalidParamsError(
+		NewQueryLimitExceededErrorWithLimit("query limit exceeded", "docs-matched", 100))
+	assert.True(t, IsQueryLimitExceededError(err))
+	assert.Equal(t, "query limit exceeded", err.Error())
+
+	details, ok := xerrors.GetErrorDetails(err)
+	assert.True(t, ok)
+	assert.Equal(t, xerrors.ErrorDetails{
+		Code:     xerrors.CodeResourceExhausted,
+		Resource: "docs-matched",
+		Limit:    100,
+	}, details)
+}
+
 func multiError(errs ...error) error {
 	multiErr := xerrors.NewMultiError()
 	for _, e := range errs {
@@ -21,15 +21,26 @@
 package permits
 
 import (
+	"errors"
+
 	"github.com/m3db/m3/src/x/context"
+	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/instrument"
 
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
 
+// ErrPermitsQueueFull is returned when acquiring a permit would exceed the
+// maximum number of callers waiting for permits.
+var ErrPermitsQueueFull = xerrors.NewResourceExhaustedError(
+	errors.New("too many operations waiting for permits"))
+
 type fixedPermits struct {
-	permits chan Permit
-	iOpts   instrument.Options
+	permits    chan Permit
+	maxWaiting int64
+	waiting    atomic.Int64
+	iOpts      instrument.Options
 }
 
 type fixedPermitsManager struct {
@@ -43,7 +54,23 @@ var (
 
 // NewFixedPermitsManager returns a permits manager that uses a fixed size of permits.
 func NewFixedPermitsManager(size int, quotaPerPermit int64, iOpts instrument.Options) Manager {
-	fp := fixedPermits{permits: make(chan Permit, size), iOpts: iOpts}
+	return NewFixedPermitsManagerWithMaxWaiting(size, quotaPerPermit, 0, iOpts)
+}
+
+// NewFixedPermitsManagerWithMaxWaiting returns a permits manager that uses a fixed
+// size of permits and rejects acquires with ErrPermitsQueueFull once maxWaiting
+// callers are already waiting for a permit. A maxWaiting of zero is unlimited.
+func NewFixedPermitsManagerWithMaxWaiting(
+	size int,
+	quotaPerPermit int64,
+	maxWaiting int,
+	iOpts instrument.Options,
+) Manager {
+	fp := fixedPermits{
+		permits:    make(chan Permit, size),
+		maxWaiting: int64(maxWaiting),
+		iOpts:      iOpts,
+	}
 	for i := 0; i < size; i++ {
 		fp.permits <- NewPermit(quotaPerPermit, iOpts)
 	}
@@ -62,6 +89,23 @@ func (f *fixedPermits) Acquire(ctx context.Context) (AcquireResult, error) {
 	default:
 	}
 
+	if f.maxWaiting > 0 {
+		select {
+		case p := <-f.permits:
+			p.PreAcquire()
+			return AcquireResult{
+				Permit: p,
+			}, nil
+		default:
+		}
+
+		if f.waiting.Inc() > f.maxWaiting {
+			f.waiting.Dec()
+			return AcquireResult{}, ErrPermitsQueueFull
+		}
+		defer f.waiting.Dec()
+	}
+
 	select {
 	case <-ctx.GoContext().Done():
 		return AcquireResult{}, ctx.GoContext().Err()
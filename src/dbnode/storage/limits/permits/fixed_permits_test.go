@@ -23,10 +23,14 @@ package permits
 import (
 	stdctx "context"
 	"testing"
+	"time"
 
+	xclock "github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/context"
+	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/instrument"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -66,6 +70,37 @@ func TestFixedPermits(t *testing.T) {
 	require.Equal(t, expectedP, p)
 }
 
+func TestFixedPermitsMaxWaiting(t *testing.T) {
+	ctx := context.NewBackground()
+	iOpts := instrument.NewOptions()
+	fp, err := NewFixedPermitsManagerWithMaxWaiting(1, 1, 1, iOpts).NewPermits(ctx)
+	require.NoError(t, err)
+
+	r, err := fp.Acquire(ctx)
+	require.NoError(t, err)
+
+	acquired := make(chan AcquireResult)
+	go func() {
+		r, err := fp.Acquire(ctx)
+		assert.NoError(t, err)
+		acquired <- r
+	}()
+	require.True(t, xclock.WaitUntil(func() bool {
+		return fp.(*fixedPermits).waiting.Load() == 1
+	}, time.Minute))
+
+	// The queue is full so further acquires are rejected.
+	_, err = fp.Acquire(ctx)
+	require.Error(t, err)
+	require.True(t, xerrors.IsResourceExhausted(err))
+
+	fp.Release(r.Permit)
+	r = <-acquired
+	require.NotNil(t, r.Permit)
+	require.Equal(t, int64(0), fp.(*fixedPermits).waiting.Load())
+	fp.Release(r.Permit)
+}
+
 func TestPanics(t *testing.T) {
 	ctx := context.NewBackground()
 	iOpts := instrument.NewOptions()
@@ -29,6 +29,8 @@ import (
 type options struct {
 	seriesReadManager Manager
 	indexQueryManager Manager
+	warmReadManager   Manager
+	coldReadManager   Manager
 }
 
 // NewOptions return a new set of default permit managers.
@@ -67,3 +69,23 @@ func (o *options) SetIndexQueryPermitsManager(value Manager) Options {
 	opts.indexQueryManager = value
 	return &opts
 }
+
+func (o *options) WarmReadPermitsManager() Manager {
+	return o.warmReadManager
+}
+
+func (o *options) SetWarmReadPermitsManager(value Manager) Options {
+	opts := *o
+	opts.warmReadManager = value
+	return &opts
+}
+
+func (o *options) ColdReadPermitsManager() Manager {
+	return o.coldReadManager
+}
+
+func (o *options) SetColdReadPermitsManager(value Manager) Options {
+	opts := *o
+	opts.coldReadManager = value
+	return &opts
+}
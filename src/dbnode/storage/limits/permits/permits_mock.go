@@ -55,6 +55,20 @@ func (m *MockOptions) EXPECT() *MockOptionsMockRecorder {
 	return m.recorder
 }
 
+// ColdReadPermitsManager mocks base method.
+func (m *MockOptions) ColdReadPermitsManager() Manager {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ColdReadPermitsManager")
+	ret0, _ := ret[0].(Manager)
+	return ret0
+}
+
+// ColdReadPermitsManager indicates an expected call of ColdReadPermitsManager.
+func (mr *MockOptionsMockRecorder) ColdReadPermitsManager() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ColdReadPermitsManager", reflect.TypeOf((*MockOptions)(nil).ColdReadPermitsManager))
+}
+
 // IndexQueryPermitsManager mocks base method.
 func (m *MockOptions) IndexQueryPermitsManager() Manager {
 	m.ctrl.T.Helper()
@@ -83,6 +97,20 @@ func (mr *MockOptionsMockRecorder) SeriesReadPermitsManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SeriesReadPermitsManager", reflect.TypeOf((*MockOptions)(nil).SeriesReadPermitsManager))
 }
 
+// SetColdReadPermitsManager mocks base method.
+func (m *MockOptions) SetColdReadPermitsManager(manager Manager) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetColdReadPermitsManager", manager)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetColdReadPermitsManager indicates an expected call of SetColdReadPermitsManager.
+func (mr *MockOptionsMockRecorder) SetColdReadPermitsManager(manager interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetColdReadPermitsManager", reflect.TypeOf((*MockOptions)(nil).SetColdReadPermitsManager), manager)
+}
+
 // SetIndexQueryPermitsManager mocks base method.
 func (m *MockOptions) SetIndexQueryPermitsManager(manager Manager) Options {
 	m.ctrl.T.Helper()
@@ -111,6 +139,34 @@ func (mr *MockOptionsMockRecorder) SetSeriesReadPermitsManager(manager interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSeriesReadPermitsManager", reflect.TypeOf((*MockOptions)(nil).SetSeriesReadPermitsManager), manager)
 }
 
+// SetWarmReadPermitsManager mocks base method.
+func (m *MockOptions) SetWarmReadPermitsManager(manager Manager) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWarmReadPermitsManager", manager)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetWarmReadPermitsManager indicates an expected call of SetWarmReadPermitsManager.
+func (mr *MockOptionsMockRecorder) SetWarmReadPermitsManager(manager interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWarmReadPermitsManager", reflect.TypeOf((*MockOptions)(nil).SetWarmReadPermitsManager), manager)
+}
+
+// WarmReadPermitsManager mocks base method.
+func (m *MockOptions) WarmReadPermitsManager() Manager {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WarmReadPermitsManager")
+	ret0, _ := ret[0].(Manager)
+	return ret0
+}
+
+// WarmReadPermitsManager indicates an expected call of WarmReadPermitsManager.
+func (mr *MockOptionsMockRecorder) WarmReadPermitsManager() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarmReadPermitsManager", reflect.TypeOf((*MockOptions)(nil).WarmReadPermitsManager))
+}
+
 // MockManager is a mock of Manager interface.
 type MockManager struct {
 	ctrl     *gomock.Controller
@@ -46,6 +46,16 @@ type Options interface {
 	SeriesReadPermitsManager() Manager
 	// SetSeriesReadPermitsManager sets the series read permits manager.
 	SetSeriesReadPermitsManager(manager Manager) Options
+	// WarmReadPermitsManager returns the permits manager of the warm read lane,
+	// used by reads of recent data held in memory. Nil if the lane is unlimited.
+	WarmReadPermitsManager() Manager
+	// SetWarmReadPermitsManager sets the permits manager of the warm read lane.
+	SetWarmReadPermitsManager(manager Manager) Options
+	// ColdReadPermitsManager returns the permits manager of the cold read lane,
+	// used by reads of historical data on disk. Nil if the lane is unlimited.
+	ColdReadPermitsManager() Manager
+	// SetColdReadPermitsManager sets the permits manager of the cold read lane.
+	SetColdReadPermitsManager(manager Manager) Options
 }
 
 // Manager manages a set of permits.
@@ -302,8 +302,8 @@ func (q *lookbackLimit) checkLimit(recent int64) error {
 	if currentOpts.ForceExceeded {
 		q.metrics.exceeded.Inc(1)
 
-		return xerrors.NewInvalidParamsError(NewQueryLimitExceededError(fmt.Sprintf(
-			"query aborted due to forced limit: name=%s", q.name)))
+		return xerrors.NewInvalidParamsError(NewQueryLimitExceededErrorWithLimit(fmt.Sprintf(
+			"query aborted due to forced limit: name=%s", q.name), q.name, currentOpts.Limit))
 	}
 
 	if currentOpts.Limit == disabledLimitValue {
@@ -313,9 +313,9 @@ func (q *lookbackLimit) checkLimit(recent int64) error {
 	if recent >= currentOpts.Limit {
 		q.metrics.exceeded.Inc(1)
 
-		return xerrors.NewInvalidParamsError(NewQueryLimitExceededError(fmt.Sprintf(
+		return xerrors.NewInvalidParamsError(NewQueryLimitExceededErrorWithLimit(fmt.Sprintf(
 			"query aborted due to limit: name=%s, limit=%d, current=%d, within=%s",
-			q.name, q.options.Limit, recent, q.options.Lookback)))
+			q.name, q.options.Limit, recent, q.options.Lookback), q.name, currentOpts.Limit))
 	}
 
 	return nil
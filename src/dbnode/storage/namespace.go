@@ -36,6 +36,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/index/convert"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
@@ -52,6 +53,7 @@ import (
 	"github.com/m3db/m3/src/x/instrument"
 	xopentracing "github.com/m3db/m3/src/x/opentracing"
 	xresource "github.com/m3db/m3/src/x/resource"
+	"github.com/m3db/m3/src/x/sampler"
 	xsync "github.com/m3db/m3/src/x/sync"
 	xtime "github.com/m3db/m3/src/x/time"
 
@@ -64,6 +66,11 @@ var (
 	errNamespaceAlreadyClosed    = errors.New("namespace already closed")
 	errNamespaceIndexingDisabled = errors.New("namespace indexing is disabled")
 	errNamespaceReadOnly         = errors.New("cannot write to a read only namespace")
+	errShardIDMismatch           = errors.New("precomputed shard ID does not match the id")
+
+	// NB: cold flushes pick the next volume index without coordinating with
+	// imports, so imports are only safe when cold writes are disabled.
+	errNamespaceImportColdWritesEnabled = errors.New("cannot import volumes into a namespace with cold writes enabled")
 )
 
 type commitLogWriter interface {
@@ -120,6 +127,9 @@ type dbNamespace struct {
 	metadata           namespace.Metadata
 	nopts              namespace.Options
 	seriesOpts         series.Options
+	shardIDSampler     *sampler.Sampler
+	codecStats         *series.CodecStats
+	readAgeStats       *series.ReadAgeStats
 	nowFn              clock.NowFn
 	snapshotFilesFn    snapshotFilesFn
 	log                *zap.Logger
@@ -131,6 +141,17 @@ type dbNamespace struct {
 	schemaListener xresource.SimpleCloser
 	schemaDescr    namespace.SchemaDescr
 
+	// runtimeOptsListener listens for namespace runtime options updates to
+	// track when indexing is paused and resumed.
+	runtimeOptsListener xresource.SimpleCloser
+	// indexPausedAt is when indexing was paused, zero if indexing is active,
+	// it is guarded by indexPausedLock.
+	indexPausedLock sync.Mutex
+	indexPausedAt   xtime.UnixNano
+	// indexBackfillLock serializes backfills of the index after indexing
+	// is resumed.
+	indexBackfillLock sync.Mutex
+
 	// Contains an entry to all shards for fast shard lookup, an
 	// entry will be nil when this shard does not belong to current database
 	shards []databaseShard
@@ -183,10 +204,12 @@ type databaseNamespaceMetrics struct {
 	bootstrapEnd            tally.Counter
 	snapshotSeriesPersist   tally.Counter
 	writesWithoutAnnotation tally.Counter
+	shardIDMismatch         tally.Counter
 
-	shards databaseNamespaceShardMetrics
-	tick   databaseNamespaceTickMetrics
-	status databaseNamespaceStatusMetrics
+	shards        databaseNamespaceShardMetrics
+	tick          databaseNamespaceTickMetrics
+	status        databaseNamespaceStatusMetrics
+	indexBackfill databaseNamespaceIndexBackfillMetrics
 
 	repairDifferingPercent tally.Gauge
 	repairComparedBlocks   tally.Counter
@@ -196,6 +219,16 @@ type databaseNamespaceMetrics struct {
 	repairExtraBlocks      tally.Counter
 }
 
+type databaseNamespaceIndexBackfillMetrics struct {
+	start         tally.Counter
+	end           tally.Counter
+	errors        tally.Counter
+	seriesQueued  tally.Counter
+	seriesSkipped tally.Counter
+	blocksSkipped tally.Counter
+	shardsPending tally.Gauge
+}
+
 type databaseNamespaceShardMetrics struct {
 	add         tally.Counter
 	close       tally.Counter
@@ -254,6 +287,7 @@ func newDatabaseNamespaceMetrics(
 	bootstrapScope := scope.SubScope("bootstrap")
 	snapshotScope := scope.SubScope("snapshot")
 	repairScope := scope.SubScope("repair")
+	indexBackfillScope := scope.SubScope("index-backfill")
 	return databaseNamespaceMetrics{
 		bootstrap:           instrument.NewMethodMetrics(scope, "bootstrap", opts),
 		flushWarmData:       instrument.NewMethodMetrics(scope, "flushWarmData", opts),
@@ -273,6 +307,7 @@ func newDatabaseNamespaceMetrics(
 		bootstrapEnd:            bootstrapScope.Counter("end"),
 		snapshotSeriesPersist:   snapshotScope.Counter("series-persist"),
 		writesWithoutAnnotation: scope.Counter("writes-without-annotation"),
+		shardIDMismatch:         scope.Counter("shard-id-mismatch"),
 
 		shards: databaseNamespaceShardMetrics{
 			add:         shardsScope.Counter("add"),
@@ -309,6 +344,15 @@ func newDatabaseNamespaceMetrics(
 				numSegments: indexStatusScope.Gauge("num-segments"),
 			},
 		},
+		indexBackfill: databaseNamespaceIndexBackfillMetrics{
+			start:         indexBackfillScope.Counter("start"),
+			end:           indexBackfillScope.Counter("end"),
+			errors:        indexBackfillScope.Counter("errors"),
+			seriesQueued:  indexBackfillScope.Counter("series-queued"),
+			seriesSkipped: indexBackfillScope.Counter("series-skipped"),
+			blocksSkipped: indexBackfillScope.Counter("blocks-skipped"),
+			shardsPending: indexBackfillScope.Gauge("shards-pending"),
+		},
 		repairDifferingPercent: repairScope.Gauge("differing-percent"),
 		repairComparedBlocks:   repairScope.Counter("compared-blocks"),
 		repairDifferingBlocks:  repairScope.Counter("differing-blocks"),
@@ -353,16 +397,43 @@ func newDatabaseNamespace(
 	seriesOpts := NewSeriesOptionsFromOptions(opts, nopts.RetentionOptions()).
 		SetStats(series.NewStats(scope)).
 		SetColdWritesEnabled(nopts.ColdWritesEnabled())
+	if policy, ok := opts.SampleIntervalPolicies()[id.String()]; ok {
+		seriesOpts = seriesOpts.SetSampleIntervalPolicy(policy)
+	}
+	coldWritesFlag, err := opts.FeatureFlags().Bool(ColdWritesEnabledFeatureFlag, true)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to create namespace %v, invalid cold writes feature flag: %v",
+			metadata.ID().String(), err)
+	}
+	seriesOpts = seriesOpts.SetColdWritesFlag(coldWritesFlag)
+	var codecStats *series.CodecStats
+	if rate := opts.CodecStatsSampleRate(); rate > 0 {
+		stats, err := series.NewCodecStats(rate, scope, opts.ClockOptions().NowFn())
+		if err != nil {
+			return nil, fmt.Errorf(
+				"unable to create namespace %v, invalid codec stats: %v",
+				metadata.ID().String(), err)
+		}
+		codecStats = stats
+		seriesOpts = seriesOpts.SetCodecStats(codecStats)
+	}
+	var readAgeStats *series.ReadAgeStats
+	if buckets := opts.ReadAgeBuckets(); len(buckets) > 0 {
+		readAgeStats, err = series.NewReadAgeStats(buckets, scope, opts.ClockOptions().NowFn())
+		if err != nil {
+			return nil, fmt.Errorf(
+				"unable to create namespace %v, invalid read age stats: %v",
+				metadata.ID().String(), err)
+		}
+	}
 	if err := seriesOpts.Validate(); err != nil {
 		return nil, fmt.Errorf(
 			"unable to create namespace %v, invalid series options: %v",
 			metadata.ID().String(), err)
 	}
 
-	var (
-		index NamespaceIndex
-		err   error
-	)
+	var index NamespaceIndex
 	if metadata.Options().IndexOptions().Enabled() {
 		index, err = newNamespaceIndex(metadata, namespaceRuntimeOptsMgr,
 			shardSet, opts)
@@ -371,6 +442,13 @@ func newDatabaseNamespace(
 		}
 	}
 
+	shardIDSampler, err := sampler.NewSampler(opts.ShardIDVerifySampleRate())
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to create namespace %v, invalid shard ID verify sample rate: %v",
+			metadata.ID().String(), err)
+	}
+
 	n := &dbNamespace{
 		id:                     id,
 		shutdownCh:             make(chan struct{}),
@@ -381,12 +459,16 @@ func newDatabaseNamespace(
 		metadata:               metadata,
 		nopts:                  nopts,
 		seriesOpts:             seriesOpts,
+		shardIDSampler:         shardIDSampler,
+		codecStats:             codecStats,
+		readAgeStats:           readAgeStats,
 		nowFn:                  opts.ClockOptions().NowFn(),
 		snapshotFilesFn:        fs.SnapshotFiles,
 		log:                    logger,
 		increasingIndex:        increasingIndex,
 		commitLogWriter:        commitLogWriter,
 		reverseIndex:           index,
+		readOnly:               opts.ReadOnly(),
 		tickWorkers:            tickWorkers,
 		tickWorkersConcurrency: tickWorkersConcurrency,
 		metrics:                newDatabaseNamespaceMetrics(scope, iops.TimerOptions()),
@@ -406,6 +488,9 @@ func newDatabaseNamespace(
 			metadata.ID().String(), err)
 	}
 	n.schemaListener = sl
+	if namespaceRuntimeOptsMgr != nil {
+		n.runtimeOptsListener = namespaceRuntimeOptsMgr.RegisterListener(n)
+	}
 	n.assignShardSet(shardSet, assignShardSetOptions{
 		needsBootstrap:    nopts.BootstrapEnabled(),
 		initialAssignment: true,
@@ -436,6 +521,99 @@ func (n *dbNamespace) SetSchemaHistory(value namespace.SchemaHistory) {
 	n.metadata = metadata
 }
 
+// SetNamespaceRuntimeOptions implements namespace.RuntimeOptionsListener.
+func (n *dbNamespace) SetNamespaceRuntimeOptions(value namespace.RuntimeOptions) {
+	paused := value.IndexingPausedOrDefault()
+
+	n.indexPausedLock.Lock()
+	defer n.indexPausedLock.Unlock()
+
+	switch {
+	case paused && n.indexPausedAt.IsZero():
+		n.indexPausedAt = xtime.ToUnixNano(n.nowFn())
+		n.log.Info("namespace indexing paused, writes will not be indexed until resumed")
+	case !paused && !n.indexPausedAt.IsZero():
+		var (
+			now   = xtime.ToUnixNano(n.nowFn())
+			ropts = n.nopts.RetentionOptions()
+			// Writes accepted while paused can be as old as buffer past and
+			// as new as buffer future relative to when they were written.
+			start = n.indexPausedAt.Add(-ropts.BufferPast()).Truncate(ropts.BlockSize())
+			end   = now.Add(ropts.BufferFuture()).Truncate(ropts.BlockSize()).Add(ropts.BlockSize())
+		)
+		n.log.Info("namespace indexing resumed, backfilling index",
+			zap.Time("pausedAt", n.indexPausedAt.ToTime()),
+			zap.Time("start", start.ToTime()),
+			zap.Time("end", end.ToTime()))
+		n.indexPausedAt = 0
+		go n.backfillIndex(start, end)
+	}
+}
+
+// backfillIndex indexes series written to the namespace between start and
+// end that were skipped by the index while indexing was paused.
+func (n *dbNamespace) backfillIndex(start, end xtime.UnixNano) {
+	n.indexBackfillLock.Lock()
+	defer n.indexBackfillLock.Unlock()
+
+	if n.reverseIndex == nil {
+		return
+	}
+
+	var (
+		shards       = n.OwnedShards()
+		backfillFrom = n.nowFn()
+		numQueued    int
+		numSkipped   int
+		numErrors    int
+	)
+	n.metrics.indexBackfill.start.Inc(1)
+	n.metrics.indexBackfill.shardsPending.Update(float64(len(shards)))
+	for i, shard := range shards {
+		select {
+		case <-n.shutdownCh:
+			n.log.Info("namespace closed, aborting index backfill")
+			return
+		default:
+		}
+
+		result, err := shard.BackfillIndex(start, end)
+		numQueued += result.NumSeriesQueued
+		numSkipped += result.NumSeriesSkipped
+		n.metrics.indexBackfill.seriesQueued.Inc(int64(result.NumSeriesQueued))
+		n.metrics.indexBackfill.seriesSkipped.Inc(int64(result.NumSeriesSkipped))
+		n.metrics.indexBackfill.blocksSkipped.Inc(int64(result.NumBlocksSkipped))
+		if err != nil {
+			numErrors++
+			n.metrics.indexBackfill.errors.Inc(1)
+			n.log.Error("index backfill failed for shard",
+				zap.Uint32("shard", shard.ID()),
+				zap.Int("seriesSkipped", result.NumSeriesSkipped),
+				zap.Int("blocksSkipped", result.NumBlocksSkipped),
+				zap.Error(err))
+		} else if result.NumSeriesSkipped > 0 {
+			n.log.Warn("index backfill skipped series of shard",
+				zap.Uint32("shard", shard.ID()),
+				zap.Int("seriesSkipped", result.NumSeriesSkipped))
+		}
+
+		n.metrics.indexBackfill.shardsPending.Update(float64(len(shards) - i - 1))
+		n.log.Debug("index backfill shard complete",
+			zap.Uint32("shard", shard.ID()),
+			zap.Int("seriesQueued", result.NumSeriesQueued),
+			zap.Int("shardsDone", i+1),
+			zap.Int("shardsTotal", len(shards)))
+	}
+	n.metrics.indexBackfill.end.Inc(1)
+
+	n.log.Info("index backfill complete",
+		zap.Int("shards", len(shards)),
+		zap.Int("seriesQueued", numQueued),
+		zap.Int("seriesSkipped", numSkipped),
+		zap.Int("shardErrors", numErrors),
+		zap.Duration("took", n.nowFn().Sub(backfillFrom)))
+}
+
 func (n *dbNamespace) reportStatusLoop(reportInterval time.Duration) {
 	ticker := time.NewTicker(reportInterval)
 	defer ticker.Stop()
@@ -451,6 +629,9 @@ func (n *dbNamespace) reportStatusLoop(reportInterval time.Duration) {
 			n.metrics.status.index.numBlocks.Update(float64(n.statsLastTick.index.numBlocks))
 			n.metrics.status.index.numSegments.Update(float64(n.statsLastTick.index.numSegments))
 			n.statsLastTick.RUnlock()
+			if n.readAgeStats != nil {
+				n.readAgeStats.Report()
+			}
 		}
 	}
 }
@@ -490,6 +671,13 @@ func (n *dbNamespace) NumSeries() int64 {
 	return count
 }
 
+func (n *dbNamespace) CodecStats() series.CodecStatsSnapshot {
+	if n.codecStats == nil {
+		return series.CodecStatsSnapshot{}
+	}
+	return n.codecStats.Snapshot()
+}
+
 func (n *dbNamespace) Shards() []Shard {
 	n.RLock()
 	shards := n.shardSet.AllIDs()
@@ -767,6 +955,33 @@ func (n *dbNamespace) WriteTagged(
 	value float64,
 	unit xtime.Unit,
 	annotation []byte,
+) (SeriesWrite, error) {
+	return n.writeTagged(ctx, id, 0, false, tagResolver, timestamp, value, unit, annotation)
+}
+
+func (n *dbNamespace) WriteTaggedWithShardID(
+	ctx context.Context,
+	id ident.ID,
+	shardID uint32,
+	tagResolver convert.TagMetadataResolver,
+	timestamp xtime.UnixNano,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) (SeriesWrite, error) {
+	return n.writeTagged(ctx, id, shardID, true, tagResolver, timestamp, value, unit, annotation)
+}
+
+func (n *dbNamespace) writeTagged(
+	ctx context.Context,
+	id ident.ID,
+	shardID uint32,
+	shardIDSet bool,
+	tagResolver convert.TagMetadataResolver,
+	timestamp xtime.UnixNano,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
 ) (SeriesWrite, error) {
 	callStart := n.nowFn()
 
@@ -780,7 +995,16 @@ func (n *dbNamespace) WriteTagged(
 		return SeriesWrite{}, errNamespaceIndexingDisabled
 	}
 
-	shard, nsCtx, err := n.shardFor(id)
+	var (
+		shard databaseShard
+		nsCtx namespace.Context
+		err   error
+	)
+	if shardIDSet {
+		shard, nsCtx, err = n.shardForShardID(id, shardID)
+	} else {
+		shard, nsCtx, err = n.shardFor(id)
+	}
 	if err != nil {
 		n.metrics.writeTagged.ReportError(n.nowFn().Sub(callStart))
 		return SeriesWrite{}, err
@@ -940,6 +1164,9 @@ func (n *dbNamespace) ReadEncoded(
 	}
 	res, err := shard.ReadEncoded(ctx, id, start, end, nsCtx)
 	n.metrics.read.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+	if err == nil && n.readAgeStats != nil {
+		n.readAgeStats.RecordRead(start, end)
+	}
 	return res, err
 }
 
@@ -1165,23 +1392,29 @@ func (n *dbNamespace) WarmFlush(
 		return fmt.Errorf("failed to flush at time %v, not aligned to blockSize", blockStart.String())
 	}
 
-	multiErr := xerrors.NewMultiError()
-	shards := n.OwnedShards()
-	for _, shard := range shards {
+	var (
+		multiErr      = xerrors.NewMultiError()
+		flushStateErr error
+		mutex         sync.Mutex
+	)
+	n.forEachShardConcurrently(n.OwnedShards(), func(shard databaseShard) {
 		if !shard.IsBootstrapped() {
 			n.log.
 				With(zap.Uint32("shard", shard.ID())).
 				Debug("skipping warm flush due to shard not bootstrapped yet")
-			continue
+			return
 		}
 
 		flushState, err := shard.FlushState(blockStart)
 		if err != nil {
-			return err
+			mutex.Lock()
+			flushStateErr = err
+			mutex.Unlock()
+			return
 		}
 		// skip flushing if the shard has already flushed data for the `blockStart`
 		if flushState.WarmStatus.DataFlushed == fileOpSuccess {
-			continue
+			return
 		}
 
 		// NB(xichen): we still want to proceed if a shard fails to flush its data.
@@ -1189,8 +1422,13 @@ func (n *dbNamespace) WarmFlush(
 		if err := shard.WarmFlush(blockStart, flushPersist, nsCtx); err != nil {
 			detailedErr := fmt.Errorf("shard %d failed to flush data: %v",
 				shard.ID(), err)
+			mutex.Lock()
 			multiErr = multiErr.Add(detailedErr)
+			mutex.Unlock()
 		}
+	})
+	if flushStateErr != nil {
+		return flushStateErr
 	}
 
 	res := multiErr.FinalError()
@@ -1398,29 +1636,32 @@ func (n *dbNamespace) Snapshot(
 	var (
 		seriesPersist int
 		multiErr      xerrors.MultiError
+		mutex         sync.Mutex
 	)
 
-	for _, shard := range n.OwnedShards() {
+	n.forEachShardConcurrently(n.OwnedShards(), func(shard databaseShard) {
 		log := n.log.With(zap.Uint32("shard", shard.ID()))
 		if !shard.IsBootstrapped() {
 			log.Debug("skipping snapshot due to shard not bootstrapped yet")
-			continue
+			return
 		}
 		snapshotBlockStarts := shard.FilterBlocksNeedSnapshot(blockStarts)
 		if len(snapshotBlockStarts) == 0 {
 			log.Debug("skipping shard snapshot since no blocks need it")
-			continue
+			return
 		}
 		for _, blockStart := range snapshotBlockStarts {
 			snapshotResult, err := shard.Snapshot(blockStart, snapshotTime, snapshotPersist, nsCtx)
+			mutex.Lock()
 			if err != nil {
 				detailedErr := fmt.Errorf("shard %d failed to snapshot %v block: %w", shard.ID(), blockStart, err)
 				multiErr = multiErr.Add(detailedErr)
-				continue
+			} else {
+				seriesPersist += snapshotResult.SeriesPersist
 			}
-			seriesPersist += snapshotResult.SeriesPersist
+			mutex.Unlock()
 		}
-	}
+	})
 
 	n.metrics.snapshotSeriesPersist.Inc(int64(seriesPersist))
 
@@ -1429,6 +1670,35 @@ func (n *dbNamespace) Snapshot(
 	return res
 }
 
+// forEachShardConcurrently calls fn for each of the shards, with up to the
+// namespace's flush concurrency calls in progress at once.
+func (n *dbNamespace) forEachShardConcurrently(
+	shards []databaseShard,
+	fn func(shard databaseShard),
+) {
+	concurrency := n.opts.NamespaceFlushConcurrency().ForNamespace(n.id)
+	if concurrency == 1 || len(shards) <= 1 {
+		for _, shard := range shards {
+			fn(shard)
+		}
+		return
+	}
+
+	workers := xsync.NewWorkerPool(concurrency)
+	workers.Init()
+
+	var wg sync.WaitGroup
+	for _, shard := range shards {
+		shard := shard
+		wg.Add(1)
+		workers.Go(func() {
+			defer wg.Done()
+			fn(shard)
+		})
+	}
+	wg.Wait()
+}
+
 func (n *dbNamespace) NeedsFlush(
 	alignedInclusiveStart xtime.UnixNano,
 	alignedInclusiveEnd xtime.UnixNano,
@@ -1533,6 +1803,18 @@ func (n *dbNamespace) Repair(
 
 	multiErr := xerrors.NewMultiError()
 	shards := n.OwnedShards()
+	if len(opts.Shards) > 0 {
+		filtered := make([]databaseShard, 0, len(opts.Shards))
+		for _, shard := range shards {
+			for _, id := range opts.Shards {
+				if shard.ID() == id {
+					filtered = append(filtered, shard)
+					break
+				}
+			}
+		}
+		shards = filtered
+	}
 	numShards := len(shards)
 	if numShards > 0 {
 		throttlePerShard = time.Duration(
@@ -1660,6 +1942,31 @@ func (n *dbNamespace) shardFor(id ident.ID) (databaseShard, namespace.Context, e
 	return shard, nsCtx, err
 }
 
+// shardForShardID returns the shard for an ID whose shard was precomputed by
+// the caller. The precomputed shard is trusted to avoid hashing the ID again
+// and only a sample of the writes is validated against the shard set.
+func (n *dbNamespace) shardForShardID(
+	id ident.ID,
+	shardID uint32,
+) (databaseShard, namespace.Context, error) {
+	n.RLock()
+	nsCtx := n.nsContextWithRLock()
+	if !n.shardIDSampler.Sample() {
+		shard, _, err := n.shardAtWithRLock(shardID)
+		n.RUnlock()
+		return shard, nsCtx, err
+	}
+	if expected := n.shardSet.Lookup(id); expected != shardID {
+		n.RUnlock()
+		n.metrics.shardIDMismatch.Inc(1)
+		return nil, nsCtx, xerrors.NewInvalidParamsError(fmt.Errorf(
+			"%w: id=%s, shardID=%d, expected=%d", errShardIDMismatch, id.String(), shardID, expected))
+	}
+	shard, _, err := n.shardAtWithRLock(shardID)
+	n.RUnlock()
+	return shard, nsCtx, err
+}
+
 func (n *dbNamespace) readableShardFor(id ident.ID) (databaseShard, namespace.Context, error) {
 	n.RLock()
 	nsCtx := n.nsContextWithRLock()
@@ -1681,13 +1988,11 @@ func (n *dbNamespace) shardAtWithRLock(shardID uint32) (databaseShard, bool, err
 	// NB(r): These errors are retryable as they will occur
 	// during a topology change and must be retried by the client.
 	if int(shardID) >= len(n.shards) {
-		return nil, false, xerrors.NewRetryableError(
-			fmt.Errorf("not responsible for shard %d", shardID))
+		return nil, false, dberrors.NewShardNotOwnedError(shardID)
 	}
 	shard := n.shards[shardID]
 	if shard == nil {
-		return nil, false, xerrors.NewRetryableError(
-			fmt.Errorf("not responsible for shard %d", shardID))
+		return nil, false, dberrors.NewShardNotOwnedError(shardID)
 	}
 	return shard, true, nil
 }
@@ -1723,6 +2028,9 @@ func (n *dbNamespace) Close() error {
 		}
 	}
 	close(n.shutdownCh)
+	if n.runtimeOptsListener != nil {
+		n.runtimeOptsListener.Close()
+	}
 	if n.reverseIndex != nil {
 		return n.reverseIndex.Close()
 	}
@@ -1765,6 +2073,30 @@ func (n *dbNamespace) FlushState(shardID uint32, blockStart xtime.UnixNano) (fil
 	return flushState, nil
 }
 
+func (n *dbNamespace) RegisterImportedVolume(
+	shardID uint32,
+	blockStart xtime.UnixNano,
+	volume int,
+) error {
+	if n.nopts.ColdWritesEnabled() {
+		return xerrors.NewInvalidParamsError(errNamespaceImportColdWritesEnabled)
+	}
+	blockSize := n.nopts.RetentionOptions().BlockSize()
+	if !blockStart.Equal(blockStart.Truncate(blockSize)) {
+		return xerrors.NewInvalidParamsError(fmt.Errorf(
+			"block start %v is not aligned to block size %v", blockStart, blockSize))
+	}
+
+	n.RLock()
+	shard, _, err := n.shardAtWithRLock(shardID)
+	nsCtx := n.nsContextWithRLock()
+	n.RUnlock()
+	if err != nil {
+		return err
+	}
+	return shard.RegisterImportedVolume(blockStart, volume, nsCtx)
+}
+
 func (n *dbNamespace) nsContextWithRLock() namespace.Context {
 	return namespace.Context{ID: n.id, Schema: n.schemaDescr}
 }
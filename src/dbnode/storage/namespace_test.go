@@ -49,6 +49,7 @@ import (
 	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/sampler"
 	xtest "github.com/m3db/m3/src/x/test"
 	xtime "github.com/m3db/m3/src/x/time"
 
@@ -320,6 +321,65 @@ func TestNamespaceReadEncodedShardOwned(t *testing.T) {
 	require.Equal(t, errShardNotBootstrappedToRead, xerrors.GetInnerRetryableError(err))
 }
 
+func TestNamespaceIndexingResumedBackfillsIndex(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	idx := NewMockNamespaceIndex(ctrl)
+	ns, closer := newTestNamespaceWithIndex(t, idx)
+	defer closer()
+
+	var (
+		wg        sync.WaitGroup
+		ropts     = ns.Options().RetentionOptions()
+		pausedAt  = xtime.Now()
+		start     = pausedAt.Add(-ropts.BufferPast()).Truncate(ropts.BlockSize())
+		backfills = make(map[uint32]int)
+		lock      sync.Mutex
+	)
+	ns.nowFn = pausedAt.ToTime
+	for _, shardID := range testShardIDs {
+		shardID := shardID.ID()
+		require.NoError(t, ns.shards[shardID].Close())
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().ID().Return(shardID).AnyTimes()
+		shard.EXPECT().Close().Return(nil)
+		shard.EXPECT().BackfillIndex(start, gomock.Any()).DoAndReturn(
+			func(_, _ xtime.UnixNano) (ShardIndexBackfillResult, error) {
+				lock.Lock()
+				backfills[shardID]++
+				lock.Unlock()
+				wg.Done()
+				return ShardIndexBackfillResult{NumSeriesQueued: 1}, nil
+			})
+		ns.shards[shardID] = shard
+		wg.Add(1)
+	}
+
+	paused := true
+	ns.SetNamespaceRuntimeOptions(namespace.NewRuntimeOptions().SetIndexingPaused(&paused))
+	require.Equal(t, pausedAt, ns.indexPausedAt)
+
+	// Pausing again is a no-op and does not reset when it was paused.
+	ns.nowFn = pausedAt.Add(time.Minute).ToTime
+	ns.SetNamespaceRuntimeOptions(namespace.NewRuntimeOptions().SetIndexingPaused(&paused))
+	require.Equal(t, pausedAt, ns.indexPausedAt)
+
+	ns.SetNamespaceRuntimeOptions(namespace.NewRuntimeOptions())
+	require.True(t, ns.indexPausedAt.IsZero())
+	wg.Wait()
+
+	// Wait for the backfill to release its lock before checking results.
+	ns.indexBackfillLock.Lock()
+	lock.Lock()
+	require.Equal(t, map[uint32]int{0: 1, 1: 1}, backfills)
+	lock.Unlock()
+	ns.indexBackfillLock.Unlock()
+
+	idx.EXPECT().Close().Return(nil)
+	require.NoError(t, ns.Close())
+}
+
 func TestNamespaceFetchBlocksShardNotOwned(t *testing.T) {
 	ctx := context.NewBackground()
 	defer ctx.Close()
@@ -742,6 +802,22 @@ func TestNamespaceSnapshotShardError(t *testing.T) {
 	require.Error(t, testSnapshotWithShardSnapshotErrs(t, shardMethodResults))
 }
 
+func TestNamespaceSnapshotConcurrently(t *testing.T) {
+	shardMethodResults := make([]snapshotTestCase, len(testShardIDs))
+	for i := range shardMethodResults {
+		shardMethodResults[i] = snapshotTestCase{
+			expectSnapshot:                true,
+			shardBootstrapStateBeforeTick: Bootstrapped,
+			isBootstrapped:                true,
+		}
+	}
+	shardMethodResults[1].shardSnapshotErr = errors.New("err")
+
+	concurrency := NamespaceFlushConcurrency{Default: len(testShardIDs)}
+	require.Error(t, testSnapshotWithShardSnapshotErrsAndConcurrency(t,
+		shardMethodResults, concurrency))
+}
+
 func TestNamespaceSnapshotShardSkipNotBootstrapped(t *testing.T) {
 	shardMethodResults := []snapshotTestCase{
 		{
@@ -834,6 +910,15 @@ func TestNamespaceSnapshotShardBlockAllShardsFiltered(t *testing.T) {
 func testSnapshotWithShardSnapshotErrs(
 	t *testing.T,
 	shardMethodResults []snapshotTestCase,
+) error {
+	return testSnapshotWithShardSnapshotErrsAndConcurrency(t,
+		shardMethodResults, NamespaceFlushConcurrency{})
+}
+
+func testSnapshotWithShardSnapshotErrsAndConcurrency(
+	t *testing.T,
+	shardMethodResults []snapshotTestCase,
+	concurrency NamespaceFlushConcurrency,
 ) error {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
@@ -845,6 +930,7 @@ func testSnapshotWithShardSnapshotErrs(
 		namespace.NewOptions().SetSnapshotEnabled(true))
 	defer closer()
 	ns.bootstrapState = Bootstrapped
+	ns.opts = ns.opts.SetNamespaceFlushConcurrency(concurrency)
 	now := xtime.Now()
 	ns.nowFn = func() time.Time {
 		return now.ToTime()
@@ -1437,6 +1523,55 @@ func TestNamespaceIndexInsert(t *testing.T) {
 	}
 }
 
+func TestNamespaceIndexWriteTaggedWithShardID(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	idx := NewMockNamespaceIndex(ctrl)
+	ns, closer := newTestNamespaceWithTruncateType(t, idx, series.TypeNone)
+	defer closer()
+
+	ctx := context.NewBackground()
+	now := xtime.Now()
+	opts := series.WriteOptions{TruncateType: series.TypeNone}
+
+	// The test shard set hashes every ID to the first shard, so every verified
+	// write with a precomputed shard ID of the second shard is rejected.
+	shard0 := NewMockdatabaseShard(ctrl)
+	shard1 := NewMockdatabaseShard(ctrl)
+	ns.shards[testShardIDs[0].ID()] = shard0
+	ns.shards[testShardIDs[1].ID()] = shard1
+
+	ns.shardIDSampler = sampler.MustNewSampler(1)
+	for i := 0; i < 3; i++ {
+		_, err := ns.WriteTaggedWithShardID(ctx, ident.StringID("a"),
+			testShardIDs[1].ID(), convert.EmptyTagMetadataResolver, now, 1.0, xtime.Second, nil)
+		require.Error(t, err)
+		require.True(t, xerrors.IsInvalidParams(err))
+		require.True(t, errors.Is(xerrors.GetInnerInvalidParamsError(err), errShardIDMismatch))
+	}
+
+	shard0.EXPECT().
+		WriteTagged(ctx, ident.NewIDMatcher("a"), convert.EmptyTagMetadataResolver,
+			now, 1.0, xtime.Second, nil, opts).
+		Return(SeriesWrite{WasWritten: true}, nil)
+	seriesWrite, err := ns.WriteTaggedWithShardID(ctx, ident.StringID("a"),
+		testShardIDs[0].ID(), convert.EmptyTagMetadataResolver, now, 1.0, xtime.Second, nil)
+	require.NoError(t, err)
+	require.True(t, seriesWrite.WasWritten)
+
+	// Writes that are not sampled trust the precomputed shard ID.
+	ns.shardIDSampler = sampler.MustNewSampler(0)
+	shard1.EXPECT().
+		WriteTagged(ctx, ident.NewIDMatcher("a"), convert.EmptyTagMetadataResolver,
+			now, 1.0, xtime.Second, nil, opts).
+		Return(SeriesWrite{WasWritten: true}, nil)
+	seriesWrite, err = ns.WriteTaggedWithShardID(ctx, ident.StringID("a"),
+		testShardIDs[1].ID(), convert.EmptyTagMetadataResolver, now, 1.0, xtime.Second, nil)
+	require.NoError(t, err)
+	require.True(t, seriesWrite.WasWritten)
+}
+
 func TestNamespaceIndexQuery(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
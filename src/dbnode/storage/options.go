@@ -25,9 +25,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
@@ -50,7 +52,9 @@ import (
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/mmap"
 	"github.com/m3db/m3/src/x/pool"
+	"github.com/m3db/m3/src/x/sampler"
 	xsync "github.com/m3db/m3/src/x/sync"
+	xtime "github.com/m3db/m3/src/x/time"
 )
 
 const (
@@ -77,6 +81,10 @@ const (
 	defaultNumLoadedBytesLimit = 2 << 30
 
 	defaultMediatorTickInterval = 5 * time.Second
+
+	// defaultShardIDVerifySampleRate is the default rate at which the shard IDs
+	// precomputed by clients on writes are verified.
+	defaultShardIDVerifySampleRate = sampler.Rate(0.01)
 )
 
 var (
@@ -132,8 +140,10 @@ type options struct {
 	repairEnabled                   bool
 	truncateType                    series.TruncateType
 	transformOptions                series.WriteTransformOptions
+	shardIDVerifySampleRate         sampler.Rate
 	indexOpts                       index.Options
 	repairOpts                      repair.Options
+	featureFlags                    featureflag.Registry
 	newEncoderFn                    encoding.NewEncoderFn
 	newDecoderFn                    encoding.NewDecoderFn
 	bootstrapProcessProvider        bootstrap.ProcessProvider
@@ -143,6 +153,11 @@ type options struct {
 	poolOpts                        pool.ObjectPoolOptions
 	contextPool                     context.Pool
 	seriesCachePolicy               series.CachePolicy
+	sampleIntervalPolicies          map[string]series.SampleIntervalPolicy
+	codecStatsSampleRate            float64
+	readAgeBuckets                  []time.Duration
+	readOnly                        bool
+	readOnlyTimeRanges              map[string]xtime.Range
 	seriesOpts                      series.Options
 	seriesPool                      series.DatabaseSeriesPool
 	bytesPool                       pool.CheckedBytesPool
@@ -162,6 +177,7 @@ type options struct {
 	blockLeaseManager               block.LeaseManager
 	onColdFlush                     OnColdFlush
 	forceColdWritesEnabled          bool
+	namespaceFlushConcurrency       NamespaceFlushConcurrency
 	sourceLoggerBuilder             limits.SourceLoggerBuilder
 	iterationOptions                index.IterationOptions
 	memoryTracker                   MemoryTracker
@@ -175,6 +191,7 @@ type options struct {
 	tileAggregator                  TileAggregator
 	permitsOptions                  permits.Options
 	limitsOptions                   limits.Options
+	healthTracker                   health.Tracker
 	coreFn                          xsync.CoreFn
 	tickOptions                     TickOptions
 }
@@ -205,6 +222,8 @@ func newOptions(poolOpts pool.ObjectPoolOptions) Options {
 	bytesWrapperPool.Init()
 
 	iOpts := instrument.NewOptions()
+	featureFlags := featureflag.NewRegistry(featureflag.NewOptions().
+		SetInstrumentOptions(iOpts))
 	o := &options{
 		clockOpts:                clock.NewOptions(),
 		instrumentOpts:           iOpts,
@@ -217,12 +236,16 @@ func newOptions(poolOpts pool.ObjectPoolOptions) Options {
 		indexOpts:                index.NewOptions(),
 		repairEnabled:            defaultRepairEnabled,
 		repairOpts:               repair.NewOptions(),
+		shardIDVerifySampleRate:  defaultShardIDVerifySampleRate,
+		featureFlags:             featureFlags,
 		bootstrapProcessProvider: defaultBootstrapProcessProvider,
 		poolOpts:                 poolOpts,
 		contextPool: context.NewPool(context.NewOptions().
 			SetContextPoolOptions(poolOpts).
 			SetFinalizerPoolOptions(poolOpts)),
 		seriesCachePolicy:       series.DefaultCachePolicy,
+		codecStatsSampleRate:    series.DefaultCodecStatsSampleRate,
+		readAgeBuckets:          series.DefaultReadAgeBuckets,
 		seriesOpts:              seriesOpts,
 		seriesPool:              series.NewDatabaseSeriesPool(poolOpts),
 		bytesPool:               bytesPool,
@@ -251,6 +274,7 @@ func newOptions(poolOpts pool.ObjectPoolOptions) Options {
 		tileAggregator:                  &noopTileAggregator{},
 		permitsOptions:                  permits.NewOptions(),
 		limitsOptions:                   limits.DefaultLimitsOptions(iOpts),
+		healthTracker:                   health.NewNoopTracker(),
 		coreFn:                          xsync.CPUCore,
 	}
 	return o.SetEncodingM3TSZPooled()
@@ -306,6 +330,11 @@ func (o *options) Validate() error {
 		return err
 	}
 
+	// validate shard ID verify sample rate
+	if err := o.shardIDVerifySampleRate.Validate(); err != nil {
+		return fmt.Errorf("unable to validate shard ID verify sample rate, err: %v", err)
+	}
+
 	if o.blockLeaseManager == nil {
 		return errBlockLeaserNotSet
 	}
@@ -451,6 +480,16 @@ func (o *options) WriteTransformOptions() series.WriteTransformOptions {
 	return o.transformOptions
 }
 
+func (o *options) SetShardIDVerifySampleRate(value sampler.Rate) Options {
+	opts := *o
+	opts.shardIDVerifySampleRate = value
+	return &opts
+}
+
+func (o *options) ShardIDVerifySampleRate() sampler.Rate {
+	return o.shardIDVerifySampleRate
+}
+
 func (o *options) SetRepairOptions(value repair.Options) Options {
 	opts := *o
 	opts.repairOpts = value
@@ -461,6 +500,16 @@ func (o *options) RepairOptions() repair.Options {
 	return o.repairOpts
 }
 
+func (o *options) SetFeatureFlags(value featureflag.Registry) Options {
+	opts := *o
+	opts.featureFlags = value
+	return &opts
+}
+
+func (o *options) FeatureFlags() featureflag.Registry {
+	return o.featureFlags
+}
+
 func (o *options) SetEncodingM3TSZPooled() Options {
 	opts := *o
 
@@ -600,6 +649,58 @@ func (o *options) SeriesCachePolicy() series.CachePolicy {
 	return o.seriesCachePolicy
 }
 
+func (o *options) SetSampleIntervalPolicies(
+	value map[string]series.SampleIntervalPolicy,
+) Options {
+	opts := *o
+	opts.sampleIntervalPolicies = value
+	return &opts
+}
+
+func (o *options) SampleIntervalPolicies() map[string]series.SampleIntervalPolicy {
+	return o.sampleIntervalPolicies
+}
+
+func (o *options) SetCodecStatsSampleRate(value float64) Options {
+	opts := *o
+	opts.codecStatsSampleRate = value
+	return &opts
+}
+
+func (o *options) CodecStatsSampleRate() float64 {
+	return o.codecStatsSampleRate
+}
+
+func (o *options) SetReadAgeBuckets(value []time.Duration) Options {
+	opts := *o
+	opts.readAgeBuckets = value
+	return &opts
+}
+
+func (o *options) ReadAgeBuckets() []time.Duration {
+	return o.readAgeBuckets
+}
+
+func (o *options) SetReadOnly(value bool) Options {
+	opts := *o
+	opts.readOnly = value
+	return &opts
+}
+
+func (o *options) ReadOnly() bool {
+	return o.readOnly
+}
+
+func (o *options) SetReadOnlyTimeRanges(value map[string]xtime.Range) Options {
+	opts := *o
+	opts.readOnlyTimeRanges = value
+	return &opts
+}
+
+func (o *options) ReadOnlyTimeRanges() map[string]xtime.Range {
+	return o.readOnlyTimeRanges
+}
+
 func (o *options) SetSeriesOptions(value series.Options) Options {
 	opts := *o
 	opts.seriesOpts = value
@@ -781,6 +882,16 @@ func (o *options) OnColdFlush() OnColdFlush {
 	return o.onColdFlush
 }
 
+func (o *options) SetNamespaceFlushConcurrency(value NamespaceFlushConcurrency) Options {
+	opts := *o
+	opts.namespaceFlushConcurrency = value
+	return &opts
+}
+
+func (o *options) NamespaceFlushConcurrency() NamespaceFlushConcurrency {
+	return o.namespaceFlushConcurrency
+}
+
 func (o *options) SetForceColdWritesEnabled(value bool) Options {
 	opts := *o
 	opts.forceColdWritesEnabled = value
@@ -945,6 +1056,16 @@ func (o *options) TickOptions() TickOptions {
 	return o.tickOptions
 }
 
+func (o *options) SetHealthTracker(value health.Tracker) Options {
+	opts := *o
+	opts.healthTracker = value
+	return &opts
+}
+
+func (o *options) HealthTracker() health.Tracker {
+	return o.healthTracker
+}
+
 type noOpColdFlush struct{}
 
 func (n *noOpColdFlush) ColdFlushNamespace(Namespace, ColdFlushNsOpts) (OnColdFlushNamespace, error) {
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/x/ident"
 	xtest "github.com/m3db/m3/src/x/test"
 )
 
@@ -49,3 +50,14 @@ func TestOptionsValidateIndexOptions(t *testing.T) {
 	opts := DefaultTestOptions().SetIndexOptions(nil)
 	require.Error(t, opts.Validate())
 }
+
+func TestNamespaceFlushConcurrencyForNamespace(t *testing.T) {
+	c := NamespaceFlushConcurrency{
+		Default:    4,
+		Namespaces: map[string]int{"foo": 2, "bar": 0},
+	}
+	require.Equal(t, 2, c.ForNamespace(ident.StringID("foo")))
+	require.Equal(t, 1, c.ForNamespace(ident.StringID("bar")))
+	require.Equal(t, 4, c.ForNamespace(ident.StringID("baz")))
+	require.Equal(t, 1, NamespaceFlushConcurrency{}.ForNamespace(ident.StringID("baz")))
+}
@@ -30,8 +30,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/ratelimit"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
@@ -43,6 +45,7 @@ import (
 	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
+	xratelimit "github.com/m3db/m3/src/x/ratelimit"
 	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/jhump/protoreflect/dynamic"
@@ -69,6 +72,7 @@ type shardRepairer struct {
 	clients []client.AdminClient
 	record  recordFn
 	nowFn   clock.NowFn
+	limiter xratelimit.Limiter
 	logger  *zap.Logger
 	scope   tally.Scope
 	metrics shardRepairerMetrics
@@ -93,12 +97,15 @@ func newShardRepairerMetrics(scope tally.Scope) shardRepairerMetrics {
 func newShardRepairer(opts Options, rpopts repair.Options) databaseShardRepairer {
 	iopts := opts.InstrumentOptions()
 	scope := iopts.MetricsScope().SubScope("repair")
+	limiter := opts.CommitLogOptions().FilesystemOptions().DiskRateLimiter().
+		Child(ratelimit.RepairLimiterName, xratelimit.Limit{})
 
 	r := shardRepairer{
 		opts:    opts,
 		rpopts:  rpopts,
 		clients: rpopts.AdminClients(),
 		nowFn:   opts.ClockOptions().NowFn(),
+		limiter: limiter,
 		logger:  iopts.Logger(),
 		scope:   scope,
 		metrics: newShardRepairerMetrics(scope),
@@ -112,6 +119,29 @@ func (r shardRepairer) Options() repair.Options {
 	return r.rpopts
 }
 
+// queueRecordingShardRepairer records the divergence detected by each shard
+// repair into the repair queue so that future repairs can be prioritized.
+type queueRecordingShardRepairer struct {
+	databaseShardRepairer
+
+	queue *repairQueue
+	nowFn clock.NowFn
+}
+
+func (r queueRecordingShardRepairer) Repair(
+	ctx context.Context,
+	nsCtx namespace.Context,
+	nsMeta namespace.Metadata,
+	tr xtime.Range,
+	shard databaseShard,
+) (repair.MetadataComparisonResult, error) {
+	attemptTime := xtime.ToUnixNano(r.nowFn())
+	res, err := r.databaseShardRepairer.Repair(ctx, nsCtx, nsMeta, tr, shard)
+	divergence := res.ChecksumDifferences.NumBlocks() + res.SizeDifferences.NumBlocks()
+	r.queue.recordResult(nsMeta.ID(), shard.ID(), tr.Start, attemptTime, divergence, err)
+	return res, err
+}
+
 func (r shardRepairer) Repair(
 	ctx context.Context,
 	nsCtx namespace.Context,
@@ -320,6 +350,11 @@ func (r shardRepairer) Repair(
 
 		for perSeriesReplicaIter.Next() {
 			_, id, tags, block := perSeriesReplicaIter.Current()
+			// Repairs share the disk rate limit with flushes and peer
+			// streaming, throttle as blocks are received from peers.
+			if err := r.limiter.WaitN(ctx.GoContext(), int64(block.Len())); err != nil {
+				return repair.MetadataComparisonResult{}, err
+			}
 			if existing, ok := results.BlockAt(id, block.StartTime()); ok {
 				// Merge contents with existing block.
 				if err := existing.Merge(block); err != nil {
@@ -548,8 +583,10 @@ type dbRepairer struct {
 	database         database
 	opts             Options
 	ropts            repair.Options
+	enabled          featureflag.BoolFlag
 	shardRepairer    databaseShardRepairer
 	repairStatesByNs repairStatesByNs
+	queue            *repairQueue
 
 	repairFn            repairFn
 	sleepFn             sleepFn
@@ -576,15 +613,26 @@ func newDatabaseRepairer(database database, opts Options) (databaseRepairer, err
 	if err := ropts.Validate(); err != nil {
 		return nil, err
 	}
+	enabled, err := opts.FeatureFlags().Bool(RepairEnabledFeatureFlag, true)
+	if err != nil {
+		return nil, err
+	}
 
-	shardRepairer := newShardRepairer(opts, ropts)
+	queue := newRepairQueue()
+	shardRepairer := queueRecordingShardRepairer{
+		databaseShardRepairer: newShardRepairer(opts, ropts),
+		queue:                 queue,
+		nowFn:                 nowFn,
+	}
 
 	r := &dbRepairer{
 		database:            database,
 		opts:                opts,
 		ropts:               ropts,
+		enabled:             enabled,
 		shardRepairer:       shardRepairer,
 		repairStatesByNs:    newRepairStates(),
+		queue:               queue,
 		sleepFn:             time.Sleep,
 		nowFn:               nowFn,
 		logger:              opts.InstrumentOptions().Logger(),
@@ -609,6 +657,12 @@ func (r *dbRepairer) run() {
 
 		r.sleepFn(r.repairCheckInterval)
 
+		if !r.enabled.Value() {
+			r.logger.Debug("skipping repair, disabled by feature flag",
+				zap.String("flag", RepairEnabledFeatureFlag))
+			continue
+		}
+
 		if err := r.repairFn(); err != nil {
 			r.logger.Error("error repairing database", zap.Error(err))
 		}
@@ -668,6 +722,10 @@ func (r *dbRepairer) Repair() error {
 		return err
 	}
 
+	// Track tuples that became eligible for repair and stop tracking tuples
+	// whose block left retention or whose shard or namespace is no longer owned.
+	r.queue.update(r.repairQueueRanges(namespaces))
+
 	var (
 		strategy                           = r.ropts.Strategy()
 		repairBlockStartShortCircuitRepair bool
@@ -677,6 +735,8 @@ func (r *dbRepairer) Repair() error {
 		repairBlockStartShortCircuitRepair = true
 	case repair.FullSweepStrategy:
 		repairBlockStartShortCircuitRepair = false
+	case repair.PriorityStrategy:
+		return r.repairByPriority(namespaces)
 	default:
 		// Unrecognized strategy.
 		return fmt.Errorf("unknown repair strategy: %v", strategy)
@@ -761,6 +821,93 @@ func (r *dbRepairer) Repair() error {
 	return multiErr.FinalError()
 }
 
+// repairByPriority repairs the namespace, shard and block start tuples with the
+// highest priority in the repair queue, up to the configured number of priority
+// repairs per run and at no more than the configured priority repairs per second.
+func (r *dbRepairer) repairByPriority(namespaces []databaseNamespace) error {
+	var (
+		multiErr = xerrors.NewMultiError()
+		byName   = make(map[string]databaseNamespace, len(namespaces))
+		now      = xtime.ToUnixNano(r.nowFn())
+		entries  = r.queue.entries(0, r.ropts.PriorityRepairsPerRun(), now)
+	)
+	for _, n := range namespaces {
+		byName[n.ID().String()] = n
+	}
+
+	var (
+		interval = time.Duration(float64(time.Second) / r.ropts.PriorityRepairsPerSecond())
+		start    = r.nowFn()
+	)
+	for i, entry := range entries {
+		// Pace repairs by when they start so that the rate holds regardless of
+		// how long each repair takes.
+		if wait := start.Add(time.Duration(i) * interval).Sub(r.nowFn()); wait > 0 {
+			r.sleepFn(wait)
+		}
+
+		n, ok := byName[entry.Namespace]
+		if !ok {
+			continue
+		}
+		var (
+			blockSize   = n.Options().RetentionOptions().BlockSize()
+			blockStart  = xtime.ToUnixNano(entry.BlockStart)
+			repairRange = xtime.Range{Start: blockStart, End: blockStart.Add(blockSize)}
+		)
+		if err := n.Repair(r.shardRepairer, repairRange, NamespaceRepairOptions{
+			Force:  r.ropts.Force(),
+			Shards: []uint32{entry.Shard},
+		}); err != nil {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"namespace %s shard %d failed to repair time range %v: %v",
+				entry.Namespace, entry.Shard, repairRange, err))
+		}
+	}
+
+	return multiErr.FinalError()
+}
+
+// repairQueueRanges returns the owned shards and block starts eligible for
+// repair in each owned namespace.
+func (r *dbRepairer) repairQueueRanges(namespaces []databaseNamespace) []repairQueueRange {
+	ranges := make([]repairQueueRange, 0, len(namespaces))
+	for _, n := range namespaces {
+		var (
+			owned       = n.OwnedShards()
+			shards      = make([]uint32, 0, len(owned))
+			repairRange = r.namespaceRepairTimeRange(n)
+		)
+		for _, shard := range owned {
+			shards = append(shards, shard.ID())
+		}
+		ranges = append(ranges, repairQueueRange{
+			namespace: n.ID().String(),
+			blockSize: n.Options().RetentionOptions().BlockSize(),
+			first:     repairRange.Start,
+			last:      repairRange.End,
+			shards:    shards,
+		})
+	}
+	return ranges
+}
+
+func (r *dbRepairer) QueueState(offset, limit int) RepairQueueState {
+	return RepairQueueState{
+		Queue:         r.queue.entries(offset, limit, xtime.ToUnixNano(r.nowFn())),
+		QueueSize:     r.queue.size(),
+		RecentResults: r.queue.recentResults(),
+	}
+}
+
+func (r *dbRepairer) ReportReadDivergence(
+	namespace ident.ID,
+	shard uint32,
+	blockStart xtime.UnixNano,
+) {
+	r.queue.recordReadDivergence(namespace, shard, blockStart)
+}
+
 func (r *dbRepairer) Report() {
 	if atomic.LoadInt32(&r.running) == 1 {
 		r.status.Update(1)
@@ -815,6 +962,9 @@ func (r repairerNoOp) Stop()         {}
 func (r repairerNoOp) Repair() error { return nil }
 func (r repairerNoOp) Report()       {}
 
+func (r repairerNoOp) QueueState(int, int) RepairQueueState                  { return RepairQueueState{} }
+func (r repairerNoOp) ReportReadDivergence(ident.ID, uint32, xtime.UnixNano) {}
+
 func (r shardRepairer) shadowCompare(
 	start xtime.UnixNano,
 	end xtime.UnixNano,
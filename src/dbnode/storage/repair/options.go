@@ -39,6 +39,8 @@ const (
 	defaultRepairCheckInterval              = time.Minute
 	defaultRepairThrottle                   = 90 * time.Second
 	defaultRepairShardConcurrency           = 1
+	defaultPriorityRepairsPerRun            = 8
+	defaultPriorityRepairsPerSecond         = 0.1
 	defaultDebugShadowComparisonsEnabled    = false
 	defaultDebugShadowComparisonsPercentage = 1.0
 )
@@ -47,6 +49,8 @@ var (
 	errNoAdminClient                           = errors.New("no admin client in repair options")
 	errInvalidRepairCheckInterval              = errors.New("invalid repair check interval in repair options")
 	errInvalidRepairThrottle                   = errors.New("invalid repair throttle in repair options")
+	errInvalidPriorityRepairsPerRun            = errors.New("invalid priority repairs per run in repair options")
+	errInvalidPriorityRepairsPerSecond         = errors.New("invalid priority repairs per second in repair options")
 	errNoReplicaMetadataSlicePool              = errors.New("no replica metadata pool in repair options")
 	errNoResultOptions                         = errors.New("no result options in repair options")
 	errInvalidDebugShadowComparisonsPercentage = errors.New("debug shadow comparisons percentage must be between 0 and 1")
@@ -61,6 +65,8 @@ type options struct {
 	repairShardConcurrency           int
 	repairCheckInterval              time.Duration
 	repairThrottle                   time.Duration
+	priorityRepairsPerRun            int
+	priorityRepairsPerSecond         float64
 	replicaMetadataSlicePool         ReplicaMetadataSlicePool
 	resultOptions                    result.Options
 	debugShadowComparisonsEnabled    bool
@@ -76,6 +82,8 @@ func NewOptions() Options {
 		repairShardConcurrency:           defaultRepairShardConcurrency,
 		repairCheckInterval:              defaultRepairCheckInterval,
 		repairThrottle:                   defaultRepairThrottle,
+		priorityRepairsPerRun:            defaultPriorityRepairsPerRun,
+		priorityRepairsPerSecond:         defaultPriorityRepairsPerSecond,
 		replicaMetadataSlicePool:         NewReplicaMetadataSlicePool(nil, 0),
 		resultOptions:                    result.NewOptions(),
 		debugShadowComparisonsEnabled:    defaultDebugShadowComparisonsEnabled,
@@ -163,6 +171,26 @@ func (o *options) RepairThrottle() time.Duration {
 	return o.repairThrottle
 }
 
+func (o *options) SetPriorityRepairsPerRun(value int) Options {
+	opts := *o
+	opts.priorityRepairsPerRun = value
+	return &opts
+}
+
+func (o *options) PriorityRepairsPerRun() int {
+	return o.priorityRepairsPerRun
+}
+
+func (o *options) SetPriorityRepairsPerSecond(value float64) Options {
+	opts := *o
+	opts.priorityRepairsPerSecond = value
+	return &opts
+}
+
+func (o *options) PriorityRepairsPerSecond() float64 {
+	return o.priorityRepairsPerSecond
+}
+
 func (o *options) SetReplicaMetadataSlicePool(value ReplicaMetadataSlicePool) Options {
 	opts := *o
 	opts.replicaMetadataSlicePool = value
@@ -229,6 +257,12 @@ func (o *options) Validate() error {
 	if o.repairThrottle < 0 {
 		return errInvalidRepairThrottle
 	}
+	if o.priorityRepairsPerRun <= 0 {
+		return errInvalidPriorityRepairsPerRun
+	}
+	if o.priorityRepairsPerSecond <= 0 {
+		return errInvalidPriorityRepairsPerSecond
+	}
 	if o.replicaMetadataSlicePool == nil {
 		return errNoReplicaMetadataSlicePool
 	}
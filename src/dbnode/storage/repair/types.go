@@ -107,11 +107,19 @@ const (
 	// enabled to ensure that historical data gets repaired at least once on
 	// a full sweep before switching back to the default strategy.
 	FullSweepStrategy
+	// PriorityStrategy will repair the namespace, shard and block start
+	// tuples with the highest priority first, where priority is derived from
+	// the divergence detected between replicas during the last repair and the
+	// time since the tuple was last repaired. The number of tuples repaired
+	// per repair run is bounded by the priority repairs per run option and
+	// tuples are repaired at no more than the priority repairs per second rate.
+	PriorityStrategy
 )
 
 var validStrategies = []Strategy{
 	DefaultStrategy,
 	FullSweepStrategy,
+	PriorityStrategy,
 }
 
 // MarshalYAML returns the YAML representation of the repair strategy.
@@ -149,6 +157,8 @@ func (t Strategy) String() string {
 		return "default"
 	case FullSweepStrategy:
 		return "full_sweep"
+	case PriorityStrategy:
+		return "priority"
 	default:
 		return "unknown"
 	}
@@ -386,6 +396,22 @@ type Options interface {
 	// RepairThrottle returns the repair throttle.
 	RepairThrottle() time.Duration
 
+	// SetPriorityRepairsPerRun sets the maximum number of namespace, shard and
+	// block start tuples repaired per repair run by the priority strategy.
+	SetPriorityRepairsPerRun(value int) Options
+
+	// PriorityRepairsPerRun returns the maximum number of namespace, shard and
+	// block start tuples repaired per repair run by the priority strategy.
+	PriorityRepairsPerRun() int
+
+	// SetPriorityRepairsPerSecond sets the maximum rate at which namespace,
+	// shard and block start tuples are repaired by the priority strategy.
+	SetPriorityRepairsPerSecond(value float64) Options
+
+	// PriorityRepairsPerSecond returns the maximum rate at which namespace,
+	// shard and block start tuples are repaired by the priority strategy.
+	PriorityRepairsPerSecond() float64
+
 	// SetReplicaMetadataSlicePool sets the replicaMetadataSlice pool.
 	SetReplicaMetadataSlicePool(value ReplicaMetadataSlicePool) Options
 
@@ -0,0 +1,502 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+// defaultRepairQueueRecentResultsCapacity is the number of most recent shard
+// repair results retained for reporting.
+const defaultRepairQueueRecentResultsCapacity = 128
+
+// RepairQueueState is a point in time snapshot of the repair queue.
+type RepairQueueState struct {
+	// Queue is the repair queue ordered by descending priority.
+	Queue []RepairQueueEntry `json:"queue"`
+	// QueueSize is the size of the whole repair queue, the queue may only
+	// hold a page of it.
+	QueueSize int `json:"queueSize"`
+	// RecentResults are the most recent shard repair results, most recent first.
+	RecentResults []RepairQueueResult `json:"recentResults"`
+}
+
+// RepairQueueEntry is a namespace, shard and block start tuple tracked by the
+// repair queue.
+type RepairQueueEntry struct {
+	Namespace  string    `json:"namespace"`
+	Shard      uint32    `json:"shard"`
+	BlockStart time.Time `json:"blockStart"`
+	Divergence int64     `json:"divergence"`
+	// ReadDivergence is the number of times reads reported replicas diverged
+	// since the tuple was last repaired.
+	ReadDivergence int64     `json:"readDivergence"`
+	LastAttempt    time.Time `json:"lastAttempt,omitempty"`
+	LastFailed     bool      `json:"lastFailed"`
+	// Priority is the number of seconds the tuple is overdue for repair,
+	// negative if it is not due yet.
+	Priority float64 `json:"priority"`
+}
+
+// RepairQueueResult is the result of repairing a single namespace, shard and
+// block start tuple.
+type RepairQueueResult struct {
+	Namespace  string    `json:"namespace"`
+	Shard      uint32    `json:"shard"`
+	BlockStart time.Time `json:"blockStart"`
+	Time       time.Time `json:"time"`
+	Divergence int64     `json:"divergence"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type repairQueueKey struct {
+	namespace  string
+	shard      uint32
+	blockStart xtime.UnixNano
+}
+
+type repairQueueEntry struct {
+	divergence     int64
+	readDivergence int64
+	lastAttempt    xtime.UnixNano
+	lastFailed     bool
+}
+
+// due returns when the tuple is next due for repair, a block size after it
+// was last repaired, or after its block start if never repaired, shortened by
+// the divergence last detected and the divergences reported by reads since.
+// Unlike a priority that grows with time it does not change until the tuple
+// is repaired or diverges, so the queue can be kept ordered incrementally.
+func (e repairQueueEntry) due(
+	blockStart xtime.UnixNano,
+	blockSize time.Duration,
+) xtime.UnixNano {
+	since := blockStart
+	if !e.lastAttempt.IsZero() {
+		since = e.lastAttempt
+	}
+	return since.Add(blockSize / time.Duration(1+e.divergence+e.readDivergence))
+}
+
+type repairQueueItem struct {
+	key       repairQueueKey
+	entry     repairQueueEntry
+	blockSize time.Duration
+	due       xtime.UnixNano
+	index     int
+}
+
+func (i *repairQueueItem) queueEntry(now xtime.UnixNano) RepairQueueEntry {
+	result := RepairQueueEntry{
+		Namespace:      i.key.namespace,
+		Shard:          i.key.shard,
+		BlockStart:     i.key.blockStart.ToTime(),
+		Divergence:     i.entry.divergence,
+		ReadDivergence: i.entry.readDivergence,
+		LastFailed:     i.entry.lastFailed,
+		Priority:       now.Sub(i.due).Seconds(),
+	}
+	if !i.entry.lastAttempt.IsZero() {
+		result.LastAttempt = i.entry.lastAttempt.ToTime()
+	}
+	return result
+}
+
+// repairQueueItems is a min heap of tuples ordered by when they are due.
+type repairQueueItems []*repairQueueItem
+
+func (h repairQueueItems) Len() int { return len(h) }
+
+func (h repairQueueItems) Less(i, j int) bool {
+	a, b := h[i], h[j]
+	if a.due != b.due {
+		return a.due < b.due
+	}
+	// Prefer more recent blocks when equally due.
+	if a.key.blockStart != b.key.blockStart {
+		return a.key.blockStart > b.key.blockStart
+	}
+	if a.key.namespace != b.key.namespace {
+		return a.key.namespace < b.key.namespace
+	}
+	return a.key.shard < b.key.shard
+}
+
+func (h repairQueueItems) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *repairQueueItems) Push(x interface{}) {
+	item := x.(*repairQueueItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *repairQueueItems) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// repairQueueFrontier is a min heap of indexes into a repair queue heap used
+// to read the queue in order without modifying it.
+type repairQueueFrontier struct {
+	items   repairQueueItems
+	indexes []int
+}
+
+func (f *repairQueueFrontier) Len() int { return len(f.indexes) }
+
+func (f *repairQueueFrontier) Less(i, j int) bool {
+	return f.items.Less(f.indexes[i], f.indexes[j])
+}
+
+func (f *repairQueueFrontier) Swap(i, j int) {
+	f.indexes[i], f.indexes[j] = f.indexes[j], f.indexes[i]
+}
+
+func (f *repairQueueFrontier) Push(x interface{}) {
+	f.indexes = append(f.indexes, x.(int))
+}
+
+func (f *repairQueueFrontier) Pop() interface{} {
+	n := len(f.indexes)
+	index := f.indexes[n-1]
+	f.indexes = f.indexes[:n-1]
+	return index
+}
+
+// repairQueueRange is the owned shards and the range of block starts, both
+// inclusive, eligible for repair in a namespace.
+type repairQueueRange struct {
+	namespace string
+	blockSize time.Duration
+	first     xtime.UnixNano
+	last      xtime.UnixNano
+	shards    []uint32
+}
+
+type repairQueueNamespace struct {
+	blockSize time.Duration
+	first     xtime.UnixNano
+	last      xtime.UnixNano
+	shards    map[uint32]struct{}
+}
+
+// repairQueue tracks the divergence detected between replicas for each
+// namespace, shard and block start tuple eligible for repair and the time it
+// was last repaired so that repairs can be prioritized by divergence magnitude
+// and age. Tuples are kept in a heap ordered by when they are due for repair
+// which is updated as tuples enter or leave the repair range and as results
+// are recorded, rather than rebuilt from every owned tuple on each read.
+type repairQueue struct {
+	sync.RWMutex
+
+	items          map[repairQueueKey]*repairQueueItem
+	heap           repairQueueItems
+	namespaces     map[string]repairQueueNamespace
+	recent         []RepairQueueResult
+	recentCapacity int
+}
+
+func newRepairQueue() *repairQueue {
+	return &repairQueue{
+		items:          make(map[repairQueueKey]*repairQueueItem),
+		namespaces:     make(map[string]repairQueueNamespace),
+		recentCapacity: defaultRepairQueueRecentResultsCapacity,
+	}
+}
+
+// update tracks the tuples eligible for repair given the owned namespaces,
+// only the tuples that entered or left the range since the last update are
+// added or removed, such as blocks that left retention or shards that are no
+// longer owned.
+func (q *repairQueue) update(ranges []repairQueueRange) {
+	q.Lock()
+	defer q.Unlock()
+
+	owned := make(map[string]struct{}, len(ranges))
+	for _, r := range ranges {
+		owned[r.namespace] = struct{}{}
+		q.updateNamespace(r)
+	}
+	for namespace, state := range q.namespaces {
+		if _, ok := owned[namespace]; ok {
+			continue
+		}
+		for shard := range state.shards {
+			q.untrackBlocks(namespace, shard, state, state.first, state.last)
+		}
+		delete(q.namespaces, namespace)
+	}
+}
+
+func (q *repairQueue) updateNamespace(r repairQueueRange) {
+	next := repairQueueNamespace{
+		blockSize: r.blockSize,
+		first:     r.first,
+		last:      r.last,
+		shards:    make(map[uint32]struct{}, len(r.shards)),
+	}
+	for _, shard := range r.shards {
+		next.shards[shard] = struct{}{}
+	}
+	prev, ok := q.namespaces[r.namespace]
+	q.namespaces[r.namespace] = next
+	if ok && (prev.blockSize != next.blockSize ||
+		prev.first.Sub(next.first)%next.blockSize != 0) {
+		// Block starts are no longer aligned with the tracked ones.
+		for shard := range prev.shards {
+			q.untrackBlocks(r.namespace, shard, prev, prev.first, prev.last)
+		}
+		ok = false
+	}
+	if !ok {
+		for shard := range next.shards {
+			q.trackBlocks(r.namespace, shard, next, next.first, next.last)
+		}
+		return
+	}
+
+	for shard := range prev.shards {
+		if _, owned := next.shards[shard]; !owned {
+			q.untrackBlocks(r.namespace, shard, prev, prev.first, prev.last)
+			continue
+		}
+		// Untrack the blocks that left the range and track the blocks that
+		// entered it.
+		q.untrackBlocks(r.namespace, shard, prev, prev.first, next.first.Add(-next.blockSize))
+		q.untrackBlocks(r.namespace, shard, prev, next.last.Add(next.blockSize), prev.last)
+		q.trackBlocks(r.namespace, shard, next, next.first, prev.first.Add(-next.blockSize))
+		q.trackBlocks(r.namespace, shard, next, prev.last.Add(next.blockSize), next.last)
+	}
+	for shard := range next.shards {
+		if _, tracked := prev.shards[shard]; !tracked {
+			q.trackBlocks(r.namespace, shard, next, next.first, next.last)
+		}
+	}
+}
+
+// trackBlocks tracks the tuples for the block starts of the namespace state
+// between from and to inclusive.
+func (q *repairQueue) trackBlocks(
+	namespace string,
+	shard uint32,
+	state repairQueueNamespace,
+	from xtime.UnixNano,
+	to xtime.UnixNano,
+) {
+	forEachRepairQueueBlock(state, from, to, func(blockStart xtime.UnixNano) {
+		key := repairQueueKey{namespace: namespace, shard: shard, blockStart: blockStart}
+		if _, ok := q.items[key]; ok {
+			return
+		}
+		item := &repairQueueItem{
+			key:       key,
+			blockSize: state.blockSize,
+			due:       repairQueueEntry{}.due(blockStart, state.blockSize),
+		}
+		q.items[key] = item
+		heap.Push(&q.heap, item)
+	})
+}
+
+// untrackBlocks stops tracking the tuples for the block starts of the
+// namespace state between from and to inclusive.
+func (q *repairQueue) untrackBlocks(
+	namespace string,
+	shard uint32,
+	state repairQueueNamespace,
+	from xtime.UnixNano,
+	to xtime.UnixNano,
+) {
+	forEachRepairQueueBlock(state, from, to, func(blockStart xtime.UnixNano) {
+		key := repairQueueKey{namespace: namespace, shard: shard, blockStart: blockStart}
+		item, ok := q.items[key]
+		if !ok {
+			return
+		}
+		heap.Remove(&q.heap, item.index)
+		delete(q.items, key)
+	})
+}
+
+// forEachRepairQueueBlock calls fn for each block start of the namespace state
+// between from and to inclusive.
+func forEachRepairQueueBlock(
+	state repairQueueNamespace,
+	from xtime.UnixNano,
+	to xtime.UnixNano,
+	fn func(blockStart xtime.UnixNano),
+) {
+	if from.Before(state.first) {
+		from = state.first
+	}
+	if to.After(state.last) {
+		to = state.last
+	}
+	for blockStart := from; !blockStart.After(to); blockStart = blockStart.Add(state.blockSize) {
+		fn(blockStart)
+	}
+}
+
+// recordResult records the result of a shard repair, divergence is the number
+// of blocks that differed between replicas when compared.
+func (q *repairQueue) recordResult(
+	namespace ident.ID,
+	shard uint32,
+	blockStart xtime.UnixNano,
+	attemptTime xtime.UnixNano,
+	divergence int64,
+	err error,
+) {
+	key := repairQueueKey{
+		namespace:  namespace.String(),
+		shard:      shard,
+		blockStart: blockStart,
+	}
+	result := RepairQueueResult{
+		Namespace:  key.namespace,
+		Shard:      shard,
+		BlockStart: blockStart.ToTime(),
+		Time:       attemptTime.ToTime(),
+		Divergence: divergence,
+	}
+
+	q.Lock()
+	defer q.Unlock()
+
+	if item, ok := q.items[key]; ok {
+		item.entry.lastAttempt = attemptTime
+		item.entry.lastFailed = err != nil
+		if err != nil {
+			// Keep the last known divergence since the comparison may not have completed.
+			result.Divergence = item.entry.divergence
+		} else {
+			item.entry.divergence = divergence
+			item.entry.readDivergence = 0
+		}
+		q.fix(item)
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if len(q.recent) >= q.recentCapacity {
+		copy(q.recent, q.recent[1:])
+		q.recent = q.recent[:len(q.recent)-1]
+	}
+	q.recent = append(q.recent, result)
+}
+
+// recordReadDivergence records that a read detected replicas diverged for the
+// tuple, raising its priority until it is next repaired successfully. Tuples
+// not eligible for repair are ignored.
+func (q *repairQueue) recordReadDivergence(
+	namespace ident.ID,
+	shard uint32,
+	blockStart xtime.UnixNano,
+) {
+	key := repairQueueKey{
+		namespace:  namespace.String(),
+		shard:      shard,
+		blockStart: blockStart,
+	}
+
+	q.Lock()
+	if item, ok := q.items[key]; ok {
+		item.entry.readDivergence++
+		q.fix(item)
+	}
+	q.Unlock()
+}
+
+func (q *repairQueue) fix(item *repairQueueItem) {
+	item.due = item.entry.due(item.key.blockStart, item.blockSize)
+	heap.Fix(&q.heap, item.index)
+}
+
+// entries returns up to limit tuples in descending priority, skipping the
+// first offset tuples, reading only as much of the heap as is returned.
+func (q *repairQueue) entries(
+	offset int,
+	limit int,
+	now xtime.UnixNano,
+) []RepairQueueEntry {
+	q.RLock()
+	defer q.RUnlock()
+
+	size := len(q.heap) - offset
+	if size > limit {
+		size = limit
+	}
+	if size <= 0 {
+		return []RepairQueueEntry{}
+	}
+
+	var (
+		results  = make([]RepairQueueEntry, 0, size)
+		frontier = &repairQueueFrontier{items: q.heap}
+	)
+	heap.Push(frontier, 0)
+	for i := 0; len(results) < size; i++ {
+		index := heap.Pop(frontier).(int)
+		for _, child := range []int{2*index + 1, 2*index + 2} {
+			if child < len(q.heap) {
+				heap.Push(frontier, child)
+			}
+		}
+		if i >= offset {
+			results = append(results, q.heap[index].queueEntry(now))
+		}
+	}
+	return results
+}
+
+// size returns the number of tuples tracked.
+func (q *repairQueue) size() int {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.heap)
+}
+
+// recentResults returns the most recent shard repair results, most recent first.
+func (q *repairQueue) recentResults() []RepairQueueResult {
+	q.RLock()
+	defer q.RUnlock()
+
+	recent := make([]RepairQueueResult, 0, len(q.recent))
+	for i := len(q.recent) - 1; i >= 0; i-- {
+		recent = append(recent, q.recent[i])
+	}
+	return recent
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairQueuePrioritize(t *testing.T) {
+	var (
+		q          = newRepairQueue()
+		ns         = ident.StringID("ns")
+		blockSize  = 2 * time.Hour
+		now        = xtime.ToUnixNano(time.Unix(1600000000, 0))
+		olderBlock = now.Add(-2 * blockSize).Truncate(blockSize)
+		newerBlock = olderBlock.Add(blockSize)
+	)
+	q.update([]repairQueueRange{{
+		namespace: "ns",
+		blockSize: blockSize,
+		first:     olderBlock,
+		last:      newerBlock,
+		shards:    []uint32{0, 1},
+	}})
+
+	// Never repaired tuples are prioritized by age, older blocks first.
+	entries := q.entries(0, 10, now)
+	require.Len(t, entries, 4)
+	require.Equal(t, olderBlock.ToTime(), entries[0].BlockStart)
+	require.Equal(t, olderBlock.ToTime(), entries[1].BlockStart)
+	require.Equal(t, newerBlock.ToTime(), entries[2].BlockStart)
+
+	// Repair everything at the same time, shard 1 of the newer block diverged.
+	attempt := now.Add(-time.Minute)
+	q.recordResult(ns, 0, olderBlock, attempt, 0, nil)
+	q.recordResult(ns, 1, olderBlock, attempt, 0, nil)
+	q.recordResult(ns, 0, newerBlock, attempt, 0, nil)
+	q.recordResult(ns, 1, newerBlock, attempt, 10, nil)
+
+	entries = q.entries(0, 10, now)
+	require.Equal(t, uint32(1), entries[0].Shard)
+	require.Equal(t, newerBlock.ToTime(), entries[0].BlockStart)
+	require.Equal(t, int64(10), entries[0].Divergence)
+	require.Equal(t, attempt.ToTime(), entries[0].LastAttempt)
+
+	// Ties prefer more recent blocks.
+	require.Equal(t, uint32(0), entries[1].Shard)
+	require.Equal(t, newerBlock.ToTime(), entries[1].BlockStart)
+}
+
+func TestRepairQueueRecordFailureRetainsDivergence(t *testing.T) {
+	var (
+		q          = newRepairQueue()
+		ns         = ident.StringID("ns")
+		now        = xtime.ToUnixNano(time.Unix(1600000000, 0))
+		blockStart = now.Add(-4 * time.Hour)
+	)
+	q.update([]repairQueueRange{{
+		namespace: "ns",
+		blockSize: 2 * time.Hour,
+		first:     blockStart,
+		last:      blockStart,
+		shards:    []uint32{3},
+	}})
+
+	q.recordResult(ns, 3, blockStart, now.Add(-2*time.Minute), 5, nil)
+	q.recordResult(ns, 3, blockStart, now.Add(-time.Minute), 0, errors.New("boom"))
+
+	entries := q.entries(0, 10, now)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(5), entries[0].Divergence)
+	require.True(t, entries[0].LastFailed)
+
+	recent := q.recentResults()
+	require.Len(t, recent, 2)
+	require.Equal(t, "boom", recent[0].Error)
+	require.Equal(t, int64(5), recent[0].Divergence)
+	require.Equal(t, "", recent[1].Error)
+}
+
+func TestRepairQueueRecentResultsCapacity(t *testing.T) {
+	var (
+		q          = newRepairQueue()
+		ns         = ident.StringID("ns")
+		blockStart = xtime.ToUnixNano(time.Unix(1600000000, 0))
+	)
+	q.recentCapacity = 2
+
+	for i := 0; i < 5; i++ {
+		q.recordResult(ns, uint32(i), blockStart, blockStart.Add(time.Duration(i)*time.Second), 0, nil)
+	}
+
+	recent := q.recentResults()
+	require.Len(t, recent, 2)
+	require.Equal(t, uint32(4), recent[0].Shard)
+	require.Equal(t, uint32(3), recent[1].Shard)
+}
+
+func TestRepairQueueReadDivergenceRaisesPriority(t *testing.T) {
+	var (
+		q          = newRepairQueue()
+		ns         = ident.StringID("ns")
+		now        = xtime.ToUnixNano(time.Unix(1600000000, 0))
+		blockStart = now.Add(-4 * time.Hour)
+		attempt    = now.Add(-time.Minute)
+	)
+	q.update([]repairQueueRange{{
+		namespace: "ns",
+		blockSize: 2 * time.Hour,
+		first:     blockStart,
+		last:      blockStart,
+		shards:    []uint32{0, 1},
+	}})
+
+	q.recordResult(ns, 0, blockStart, attempt, 0, nil)
+	q.recordResult(ns, 1, blockStart, attempt, 0, nil)
+	q.recordReadDivergence(ns, 0, blockStart)
+	q.recordReadDivergence(ns, 0, blockStart)
+
+	entries := q.entries(0, 10, now)
+	require.Equal(t, uint32(0), entries[0].Shard)
+	require.Equal(t, int64(2), entries[0].ReadDivergence)
+	require.True(t, entries[0].Priority > entries[1].Priority)
+
+	// A successful repair clears the read divergence.
+	q.recordResult(ns, 0, blockStart, now, 0, nil)
+	entries = q.entries(0, 10, now)
+	require.Equal(t, uint32(1), entries[0].Shard)
+	require.Equal(t, int64(0), entries[1].ReadDivergence)
+}
+
+func TestRepairQueueUpdate(t *testing.T) {
+	var (
+		q         = newRepairQueue()
+		ns        = ident.StringID("ns")
+		blockSize = 2 * time.Hour
+		now       = xtime.ToUnixNano(time.Unix(1600000000, 0)).Truncate(blockSize)
+		b0        = now.Add(-4 * blockSize)
+		b1        = b0.Add(blockSize)
+		b2        = b1.Add(blockSize)
+		b3        = b2.Add(blockSize)
+	)
+
+	q.update([]repairQueueRange{{
+		namespace: "ns",
+		blockSize: blockSize,
+		first:     b0,
+		last:      b2,
+		shards:    []uint32{0, 1},
+	}})
+	require.Equal(t, 6, q.size())
+
+	q.recordResult(ns, 0, b2, now, 1, nil)
+	q.recordResult(ns, 1, b2, now, 2, nil)
+
+	// Tuples not eligible for repair are not tracked.
+	q.recordReadDivergence(ns, 0, b0.Add(-blockSize))
+	require.Equal(t, 6, q.size())
+
+	// The range moved forward a block, shard 1 is no longer owned and shard 2
+	// was assigned.
+	q.update([]repairQueueRange{{
+		namespace: "ns",
+		blockSize: blockSize,
+		first:     b1,
+		last:      b3,
+		shards:    []uint32{0, 2},
+	}})
+	require.Equal(t, 6, q.size())
+	for _, shard := range []uint32{0, 2} {
+		for _, blockStart := range []xtime.UnixNano{b1, b2, b3} {
+			key := repairQueueKey{namespace: "ns", shard: shard, blockStart: blockStart}
+			require.Contains(t, q.items, key)
+		}
+	}
+	item := q.items[repairQueueKey{namespace: "ns", shard: 0, blockStart: b2}]
+	require.Equal(t, int64(1), item.entry.divergence)
+	requireRepairQueueHeap(t, q)
+
+	// A block size change tracks the realigned block starts.
+	q.update([]repairQueueRange{{
+		namespace: "ns",
+		blockSize: 2 * blockSize,
+		first:     b0,
+		last:      b2,
+		shards:    []uint32{0},
+	}})
+	require.Equal(t, 2, q.size())
+	requireRepairQueueHeap(t, q)
+
+	// The namespace is no longer owned.
+	q.update(nil)
+	require.Equal(t, 0, q.size())
+	require.Len(t, q.items, 0)
+	require.Len(t, q.namespaces, 0)
+
+	// Recent results are retained for reporting.
+	require.Len(t, q.recentResults(), 2)
+}
+
+func TestRepairQueueEntriesPaginates(t *testing.T) {
+	var (
+		q         = newRepairQueue()
+		ns        = ident.StringID("ns")
+		blockSize = 2 * time.Hour
+		now       = xtime.ToUnixNano(time.Unix(1600000000, 0)).Truncate(blockSize)
+		first     = now.Add(-10 * blockSize)
+		shards    = []uint32{0, 1, 2, 3, 4}
+	)
+	q.update([]repairQueueRange{{
+		namespace: "ns",
+		blockSize: blockSize,
+		first:     first,
+		last:      now,
+		shards:    shards,
+	}})
+	for i, shard := range shards {
+		blockStart := first.Add(time.Duration(i) * blockSize)
+		q.recordResult(ns, shard, blockStart, now.Add(-time.Duration(i)*time.Minute), int64(i), nil)
+	}
+
+	all := q.entries(0, q.size(), now)
+	require.Len(t, all, 55)
+	for i := 1; i < len(all); i++ {
+		require.True(t, all[i-1].Priority >= all[i].Priority)
+	}
+
+	for _, test := range []struct {
+		offset, limit int
+	}{
+		{offset: 0, limit: 5},
+		{offset: 7, limit: 13},
+		{offset: 50, limit: 10},
+		{offset: 60, limit: 10},
+	} {
+		expected := []RepairQueueEntry{}
+		if test.offset < len(all) {
+			end := test.offset + test.limit
+			if end > len(all) {
+				end = len(all)
+			}
+			expected = all[test.offset:end]
+		}
+		require.Equal(t, expected, q.entries(test.offset, test.limit, now))
+	}
+}
+
+func requireRepairQueueHeap(t *testing.T, q *repairQueue) {
+	require.Len(t, q.heap, len(q.items))
+	for i, item := range q.heap {
+		require.Equal(t, i, item.index)
+		require.Equal(t, item, q.items[item.key])
+		if i > 0 {
+			require.False(t, q.heap.Less(i, (i-1)/2))
+		}
+	}
+}
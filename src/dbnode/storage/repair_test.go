@@ -23,9 +23,13 @@ package storage
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/retention"
@@ -93,6 +97,53 @@ func TestDatabaseRepairerStartStop(t *testing.T) {
 	}
 }
 
+func TestDatabaseRepairerDisabledByFeatureFlag(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	store := mem.NewStore()
+	_, err := store.Set(RepairEnabledFeatureFlag, &commonpb.BoolProto{Value: false})
+	require.NoError(t, err)
+	featureFlags := featureflag.NewRegistry(featureflag.NewOptions().SetKVStore(store))
+	defer featureFlags.Close()
+
+	opts := DefaultTestOptions().
+		SetRepairOptions(testRepairOptions(ctrl)).
+		SetFeatureFlags(featureFlags)
+	db := NewMockdatabase(ctrl)
+
+	databaseRepairer, err := newDatabaseRepairer(db, opts)
+	require.NoError(t, err)
+	repairer := databaseRepairer.(*dbRepairer)
+
+	var (
+		sleeps   int32
+		repaired int32
+	)
+	repairer.sleepFn = func(time.Duration) {
+		atomic.AddInt32(&sleeps, 1)
+		time.Sleep(time.Millisecond)
+	}
+	repairer.repairFn = func() error {
+		atomic.StoreInt32(&repaired, 1)
+		return nil
+	}
+
+	repairer.Start()
+	defer repairer.Stop()
+
+	for atomic.LoadInt32(&sleeps) < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	require.Equal(t, int32(0), atomic.LoadInt32(&repaired))
+
+	_, err = store.Set(RepairEnabledFeatureFlag, &commonpb.BoolProto{Value: true})
+	require.NoError(t, err)
+	for atomic.LoadInt32(&repaired) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func TestDatabaseRepairerRepairNotBootstrapped(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
@@ -246,8 +297,10 @@ func testDatabaseShardRepairerRepair(t *testing.T, withLimit bool) {
 		peerBlocksIter := client.NewMockPeerBlocksIter(ctrl)
 		dbBlock1 := block.NewMockDatabaseBlock(ctrl)
 		dbBlock1.EXPECT().StartTime().Return(inBlocks[2].Metadata.Start).AnyTimes()
+		dbBlock1.EXPECT().Len().Return(0).AnyTimes()
 		dbBlock2 := block.NewMockDatabaseBlock(ctrl)
 		dbBlock2.EXPECT().StartTime().Return(inBlocks[2].Metadata.Start).AnyTimes()
+		dbBlock2.EXPECT().Len().Return(0).AnyTimes()
 		// Ensure merging logic works.
 		dbBlock1.EXPECT().Merge(dbBlock2)
 		gomock.InOrder(
@@ -487,8 +540,10 @@ func TestDatabaseShardRepairerRepairMultiSession(t *testing.T) {
 		peerBlocksIter := client.NewMockPeerBlocksIter(ctrl)
 		dbBlock1 := block.NewMockDatabaseBlock(ctrl)
 		dbBlock1.EXPECT().StartTime().Return(inBlocksForSession[2].Metadata.Start).AnyTimes()
+		dbBlock1.EXPECT().Len().Return(0).AnyTimes()
 		dbBlock2 := block.NewMockDatabaseBlock(ctrl)
 		dbBlock2.EXPECT().StartTime().Return(inBlocksForSession[2].Metadata.Start).AnyTimes()
+		dbBlock2.EXPECT().Len().Return(0).AnyTimes()
 		// Ensure merging logic works. Nede AnyTimes() because the Merge() will only be called on dbBlock1
 		// for the first session (all subsequent blocks from other sessions will get merged into dbBlock1
 		// from the first session.)
@@ -727,6 +782,8 @@ func TestDatabaseRepairPrioritizationLogic(t *testing.T) {
 
 			ns1.EXPECT().ID().Return(ident.StringID("ns1")).AnyTimes()
 			ns2.EXPECT().ID().Return(ident.StringID("ns2")).AnyTimes()
+			ns1.EXPECT().OwnedShards().Return(nil).AnyTimes()
+			ns2.EXPECT().OwnedShards().Return(nil).AnyTimes()
 
 			for _, expected := range tc.expectedNS1Repairs {
 				ns1.EXPECT().Repair(gomock.Any(), expected.expectedRepairRange, NamespaceRepairOptions{})
@@ -836,6 +893,8 @@ func TestDatabaseRepairSkipsPoisonShard(t *testing.T) {
 
 			ns1.EXPECT().ID().Return(ident.StringID("ns1")).AnyTimes()
 			ns2.EXPECT().ID().Return(ident.StringID("ns2")).AnyTimes()
+			ns1.EXPECT().OwnedShards().Return(nil).AnyTimes()
+			ns2.EXPECT().OwnedShards().Return(nil).AnyTimes()
 
 			//Setup expected ns1 repair invocations for each repaired time range
 			var ns1RepairExpectations = make([]*gomock.Call, len(tc.expectedNS1Repairs))
@@ -234,6 +234,8 @@ type dbBuffer struct {
 	bucketVersionsPool *BufferBucketVersionsPool
 	bucketPool         *BufferBucketPool
 	blockRetriever     QueryableBlockRetriever
+	// sampleInterval is the state of the sample interval policy.
+	sampleInterval sampleIntervalState
 }
 
 // NB(prateek): databaseBuffer.Reset(...) must be called upon the returned
@@ -252,6 +254,7 @@ func (b *dbBuffer) Reset(opts databaseBufferResetOptions) {
 	b.bucketPool = opts.Options.BufferBucketPool()
 	b.bucketVersionsPool = opts.Options.BufferBucketVersionsPool()
 	b.blockRetriever = opts.BlockRetriever
+	b.sampleInterval = sampleIntervalState{}
 }
 
 func (b *dbBuffer) MoveTo(
@@ -325,7 +328,7 @@ func (b *dbBuffer) Write(
 
 	case timestamp.Before(pastLimit):
 		writeType = ColdWrite
-		if !b.opts.ColdWritesEnabled() {
+		if !b.coldWritesEnabled() {
 			return false, writeType, xerrors.NewInvalidParamsError(
 				fmt.Errorf("datapoint too far in past: "+
 					"id=%s, off_by=%s, timestamp=%s, past_limit=%s, "+
@@ -338,7 +341,7 @@ func (b *dbBuffer) Write(
 
 	case !futureLimit.After(timestamp):
 		writeType = ColdWrite
-		if !b.opts.ColdWritesEnabled() {
+		if !b.coldWritesEnabled() {
 			return false, writeType, xerrors.NewInvalidParamsError(
 				fmt.Errorf("datapoint too far in future: "+
 					"id=%s, off_by=%s, timestamp=%s, future_limit=%s, "+
@@ -397,9 +400,6 @@ func (b *dbBuffer) Write(
 		b.opts.Stats().IncColdWrites()
 	}
 
-	buckets := b.bucketVersionsAtCreate(blockStart)
-	b.putBucketVersionsInCache(buckets)
-
 	if wOpts.TruncateType == TypeBlock {
 		timestamp = blockStart
 	}
@@ -408,10 +408,82 @@ func (b *dbBuffer) Write(
 		value = wOpts.TransformOptions.ForceValue
 	}
 
+	if policy := b.opts.SampleIntervalPolicy(); policy.Enabled() &&
+		writeType == WarmWrite && !wOpts.BootstrapWrite {
+		var err error
+		timestamp, value, err = b.applySampleIntervalPolicy(id, policy,
+			blockSize, timestamp, value)
+		if err != nil {
+			return false, writeType, err
+		}
+	}
+
+	buckets := b.bucketVersionsAtCreate(blockStart)
+	b.putBucketVersionsInCache(buckets)
+
 	ok, err := buckets.write(timestamp, value, unit, annotation, writeType, wOpts.SchemaDesc)
 	return ok, writeType, err
 }
 
+// coldWritesEnabled returns whether cold writes are accepted, they can be
+// rejected at runtime by a feature flag even when enabled for the namespace.
+func (b *dbBuffer) coldWritesEnabled() bool {
+	if !b.opts.ColdWritesEnabled() {
+		return false
+	}
+	flag := b.opts.ColdWritesFlag()
+	return flag == nil || flag.Value()
+}
+
+// applySampleIntervalPolicy returns the timestamp and value to write for a
+// datapoint according to the sample interval policy, datapoints written
+// sooner than the minimum interval after the datapoint that started the
+// current interval are either rejected or coalesced into that datapoint.
+func (b *dbBuffer) applySampleIntervalPolicy(
+	id ident.ID,
+	policy SampleIntervalPolicy,
+	blockSize time.Duration,
+	timestamp xtime.UnixNano,
+	value float64,
+) (xtime.UnixNano, float64, error) {
+	state := &b.sampleInterval
+	if state.count > 0 && timestamp.Before(state.timestamp) {
+		// Out of order datapoints are written as is.
+		return timestamp, value, nil
+	}
+
+	if state.count == 0 ||
+		timestamp.Equal(state.timestamp) ||
+		timestamp.Sub(state.timestamp) >= policy.MinInterval ||
+		// NB: only coalesce into a datapoint of the same block so that the
+		// write type determined for the datapoint remains correct.
+		!timestamp.Truncate(blockSize).Equal(state.timestamp.Truncate(blockSize)) {
+		// Start a new interval, datapoints at the same timestamp are
+		// upserts and replace the datapoint that started the interval.
+		*state = sampleIntervalState{timestamp: timestamp, sum: value, count: 1}
+		return timestamp, value, nil
+	}
+
+	b.opts.Stats().IncSampleIntervalViolations(policy.Mode)
+	switch policy.Mode {
+	case SampleIntervalCoalesceLastWins:
+		state.sum = value
+		state.count = 1
+		return state.timestamp, value, nil
+	case SampleIntervalAverage:
+		state.sum += value
+		state.count++
+		return state.timestamp, state.sum / state.count, nil
+	default:
+		return 0, 0, xerrors.NewInvalidParamsError(
+			fmt.Errorf("datapoint written sooner than min sample interval: "+
+				"id=%s, timestamp=%s, interval_start=%s, min_interval=%s",
+				id.Bytes(), timestamp.Format(errTimestampFormat),
+				state.timestamp.Format(errTimestampFormat),
+				policy.MinInterval.String()))
+	}
+}
+
 func (b *dbBuffer) IsEmpty() bool {
 	// A buffer can only be empty if there are no buckets in its map, since
 	// buckets are only created when a write for a new block start is done, and
@@ -695,6 +767,10 @@ func (b *dbBuffer) WarmFlush(
 		return FlushOutcomeErr, err
 	}
 
+	if codecStats := b.opts.CodecStats(); codecStats != nil {
+		codecStats.maybeBenchmark(segment, blockStart, b.opts, nsCtx)
+	}
+
 	if bucket, exists := buckets.writableBucket(WarmWrite); exists {
 		// WarmFlushes only happen once per block, so it makes sense to always
 		// set this to 1.
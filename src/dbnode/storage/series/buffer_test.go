@@ -21,11 +21,13 @@
 package series
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/namespace"
@@ -166,6 +168,44 @@ func TestBufferWriteTooPast(t *testing.T) {
 	assert.True(t, strings.Contains(err.Error(), "past_limit="))
 }
 
+func TestBufferWriteColdDisabledByFeatureFlag(t *testing.T) {
+	featureFlags := featureflag.NewRegistry(featureflag.NewOptions())
+	defer featureFlags.Close()
+
+	for _, enabled := range []bool{false, true} {
+		flag, err := featureFlags.Bool(fmt.Sprintf("cold-writes-%v", enabled), enabled)
+		require.NoError(t, err)
+
+		opts := newBufferTestOptions().
+			SetColdWritesEnabled(true).
+			SetColdWritesFlag(flag)
+		rops := opts.RetentionOptions()
+		curr := xtime.Now().Truncate(rops.BlockSize())
+		opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+			return curr.ToTime()
+		}))
+		buffer := newDatabaseBuffer().(*dbBuffer)
+		buffer.Reset(databaseBufferResetOptions{
+			Options: opts,
+		})
+		ctx := context.NewBackground()
+
+		wasWritten, writeType, err := buffer.Write(ctx, testID,
+			curr.Add(-1*rops.BufferPast()-time.Second), 1, xtime.Second,
+			nil, WriteOptions{})
+		ctx.Close()
+		assert.Equal(t, ColdWrite, writeType)
+		if enabled {
+			require.NoError(t, err)
+			assert.True(t, wasWritten)
+		} else {
+			require.Error(t, err)
+			assert.False(t, wasWritten)
+			assert.True(t, xerrors.IsInvalidParams(err))
+		}
+	}
+}
+
 func maxDuration(a, b time.Duration) time.Duration {
 	if a > b {
 		return a
@@ -292,6 +332,67 @@ func testBufferWriteRead(t *testing.T, opts Options, setAnn setAnnotation) {
 	requireReaderValuesEqual(t, data, results, opts, nsCtx)
 }
 
+func TestBufferWriteSampleIntervalPolicy(t *testing.T) {
+	tests := []struct {
+		mode     SampleIntervalMode
+		expected []float64
+	}{
+		{mode: SampleIntervalReject, expected: []float64{1, 6}},
+		{mode: SampleIntervalCoalesceLastWins, expected: []float64{3, 7}},
+		{mode: SampleIntervalAverage, expected: []float64{2, 6.5}},
+	}
+	for _, test := range tests {
+		t.Run(test.mode.String(), func(t *testing.T) {
+			opts := newBufferTestOptions().
+				SetSampleIntervalPolicy(SampleIntervalPolicy{
+					MinInterval: 5 * time.Second,
+					Mode:        test.mode,
+				})
+			rops := opts.RetentionOptions()
+			curr := xtime.Now().Truncate(rops.BlockSize())
+			opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+				return curr.ToTime()
+			}))
+			buffer := newDatabaseBuffer().(*dbBuffer)
+			buffer.Reset(databaseBufferResetOptions{
+				Options: opts,
+			})
+
+			ctx := context.NewBackground()
+			defer ctx.Close()
+
+			for _, v := range []DecodedTestValue{
+				{curr.Add(secs(1)), 1, xtime.Second, nil},
+				{curr.Add(secs(2)), 2, xtime.Second, nil},
+				{curr.Add(secs(3)), 3, xtime.Second, nil},
+				{curr.Add(secs(6)), 6, xtime.Second, nil},
+				{curr.Add(secs(7)), 7, xtime.Second, nil},
+			} {
+				// Only the datapoints starting an interval are accepted when
+				// violations are rejected.
+				startsInterval := v.Value == 1 || v.Value == 6
+				wasWritten, _, err := buffer.Write(ctx, testID, v.Timestamp, v.Value,
+					v.Unit, v.Annotation, WriteOptions{})
+				if test.mode == SampleIntervalReject && !startsInterval {
+					require.Error(t, err)
+					require.True(t, xerrors.IsInvalidParams(err))
+					require.False(t, wasWritten)
+					continue
+				}
+				require.NoError(t, err)
+				require.True(t, wasWritten)
+			}
+
+			results, err := buffer.ReadEncoded(ctx, 0, timeDistantFuture, namespace.Context{})
+			require.NoError(t, err)
+			requireReaderValuesEqual(t, []DecodedTestValue{
+				{curr.Add(secs(1)), test.expected[0], xtime.Second, nil},
+				{curr.Add(secs(6)), test.expected[1], xtime.Second, nil},
+			}, results, opts, namespace.Context{})
+		})
+	}
+}
+
 func TestBufferReadOnlyMatchingBuckets(t *testing.T) {
 	opts := newBufferTestOptions()
 	rops := opts.RetentionOptions()
@@ -0,0 +1,291 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3/src/x/clock"
+	"github.com/m3db/m3/src/x/sampler"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	// DefaultCodecStatsSampleRate is the default fraction of flushed blocks
+	// that are benchmarked to gather codec stats, codec stats are disabled
+	// unless explicitly configured.
+	DefaultCodecStatsSampleRate = 0
+
+	// uncompressedDatapointBytes is the size of a datapoint before it is
+	// encoded, a nanosecond timestamp and a float64 value.
+	uncompressedDatapointBytes = 16
+)
+
+// CodecStats aggregates the codec stats of the series of a namespace. Stats
+// are gathered by benchmarking a sample of the blocks as they are flushed,
+// each sampled block is decoded and re-encoded with the configured encoding
+// so that codec choices can be evaluated on real traffic. Benchmarks run in
+// the background, one at a time, so that they never slow down a flush.
+type CodecStats struct {
+	sync.Mutex
+
+	wg      sync.WaitGroup
+	running chan struct{}
+	sampler *sampler.Sampler
+	nowFn   clock.NowFn
+	totals  CodecStatsSnapshot
+	metrics codecStatsMetrics
+}
+
+// CodecStatsSnapshot is a point in time snapshot of codec stats.
+type CodecStatsSnapshot struct {
+	// Blocks is the number of blocks benchmarked.
+	Blocks int64 `json:"blocks"`
+	// Datapoints is the number of datapoints in the benchmarked blocks.
+	Datapoints int64 `json:"datapoints"`
+	// EncodedBytes is the size of the benchmarked blocks once encoded.
+	EncodedBytes int64 `json:"encodedBytes"`
+	// EncodeNanos is the time spent encoding the benchmarked blocks.
+	EncodeNanos int64 `json:"encodeNanos"`
+	// DecodeNanos is the time spent decoding the benchmarked blocks.
+	DecodeNanos int64 `json:"decodeNanos"`
+	// Errors is the number of blocks that failed to be benchmarked.
+	Errors int64 `json:"errors"`
+}
+
+// CompressionRatio returns the ratio of uncompressed to encoded bytes.
+func (s CodecStatsSnapshot) CompressionRatio() float64 {
+	if s.EncodedBytes == 0 {
+		return 0
+	}
+	return float64(s.Datapoints*uncompressedDatapointBytes) / float64(s.EncodedBytes)
+}
+
+// BytesPerDatapoint returns the mean encoded bytes per datapoint.
+func (s CodecStatsSnapshot) BytesPerDatapoint() float64 {
+	return s.perDatapoint(s.EncodedBytes)
+}
+
+// EncodeNanosPerDatapoint returns the mean time to encode a datapoint.
+func (s CodecStatsSnapshot) EncodeNanosPerDatapoint() float64 {
+	return s.perDatapoint(s.EncodeNanos)
+}
+
+// DecodeNanosPerDatapoint returns the mean time to decode a datapoint.
+func (s CodecStatsSnapshot) DecodeNanosPerDatapoint() float64 {
+	return s.perDatapoint(s.DecodeNanos)
+}
+
+func (s CodecStatsSnapshot) perDatapoint(v int64) float64 {
+	if s.Datapoints == 0 {
+		return 0
+	}
+	return float64(v) / float64(s.Datapoints)
+}
+
+type codecStatsMetrics struct {
+	blocks                  tally.Counter
+	datapoints              tally.Counter
+	encodedBytes            tally.Counter
+	errors                  tally.Counter
+	skipped                 tally.Counter
+	compressionRatio        tally.Gauge
+	bytesPerDatapoint       tally.Gauge
+	encodeNanosPerDatapoint tally.Gauge
+	decodeNanosPerDatapoint tally.Gauge
+}
+
+// NewCodecStats returns new codec stats that benchmark the given fraction of
+// flushed blocks.
+func NewCodecStats(
+	sampleRate float64,
+	scope tally.Scope,
+	nowFn clock.NowFn,
+) (*CodecStats, error) {
+	s, err := sampler.NewSampler(sampler.Rate(sampleRate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid codec stats sample rate: %w", err)
+	}
+	if nowFn == nil {
+		nowFn = time.Now
+	}
+
+	subScope := scope.SubScope("codec")
+	return &CodecStats{
+		running: make(chan struct{}, 1),
+		sampler: s,
+		nowFn:   nowFn,
+		metrics: codecStatsMetrics{
+			blocks:                  subScope.Counter("benchmarked-blocks"),
+			datapoints:              subScope.Counter("benchmarked-datapoints"),
+			encodedBytes:            subScope.Counter("benchmarked-bytes"),
+			errors:                  subScope.Counter("benchmark-errors"),
+			skipped:                 subScope.Counter("benchmark-skipped"),
+			compressionRatio:        subScope.Gauge("compression-ratio"),
+			bytesPerDatapoint:       subScope.Gauge("bytes-per-datapoint"),
+			encodeNanosPerDatapoint: subScope.Gauge("encode-ns-per-datapoint"),
+			decodeNanosPerDatapoint: subScope.Gauge("decode-ns-per-datapoint"),
+		},
+	}, nil
+}
+
+// Snapshot returns a snapshot of the codec stats.
+func (s *CodecStats) Snapshot() CodecStatsSnapshot {
+	s.Lock()
+	defer s.Unlock()
+	return s.totals
+}
+
+// maybeBenchmark benchmarks the encoded block of a series in the background
+// if it is sampled and no other benchmark is running.
+func (s *CodecStats) maybeBenchmark(
+	segment ts.Segment,
+	blockStart xtime.UnixNano,
+	opts Options,
+	nsCtx namespace.Context,
+) {
+	if !s.sampler.Sample() {
+		return
+	}
+
+	select {
+	case s.running <- struct{}{}:
+	default:
+		s.metrics.skipped.Inc(1)
+		return
+	}
+
+	// NB: the segment is owned by the flush and is finalized once the flush
+	// returns, so benchmark a copy of it.
+	clone := segment.Clone(nil)
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			<-s.running
+			s.wg.Done()
+		}()
+		s.record(s.benchmark(clone, blockStart, opts, nsCtx))
+	}()
+}
+
+// wait waits for the running benchmark, if any, to complete.
+func (s *CodecStats) wait() {
+	s.wg.Wait()
+}
+
+func (s *CodecStats) record(result CodecStatsSnapshot, err error) {
+	if err != nil {
+		s.metrics.errors.Inc(1)
+		s.Lock()
+		s.totals.Errors++
+		s.Unlock()
+		return
+	}
+
+	s.metrics.blocks.Inc(1)
+	s.metrics.datapoints.Inc(result.Datapoints)
+	s.metrics.encodedBytes.Inc(result.EncodedBytes)
+
+	s.Lock()
+	s.totals.Blocks++
+	s.totals.Datapoints += result.Datapoints
+	s.totals.EncodedBytes += result.EncodedBytes
+	s.totals.EncodeNanos += result.EncodeNanos
+	s.totals.DecodeNanos += result.DecodeNanos
+	totals := s.totals
+	s.Unlock()
+
+	s.metrics.compressionRatio.Update(totals.CompressionRatio())
+	s.metrics.bytesPerDatapoint.Update(totals.BytesPerDatapoint())
+	s.metrics.encodeNanosPerDatapoint.Update(totals.EncodeNanosPerDatapoint())
+	s.metrics.decodeNanosPerDatapoint.Update(totals.DecodeNanosPerDatapoint())
+}
+
+type codecBenchmarkDatapoint struct {
+	dp         ts.Datapoint
+	unit       xtime.Unit
+	annotation ts.Annotation
+}
+
+// benchmark decodes the encoded block and re-encodes its datapoints, timing
+// each separately, the segment is finalized once done.
+func (s *CodecStats) benchmark(
+	segment ts.Segment,
+	blockStart xtime.UnixNano,
+	opts Options,
+	nsCtx namespace.Context,
+) (CodecStatsSnapshot, error) {
+	reader := xio.NewSegmentReader(segment)
+	defer reader.Finalize()
+
+	iter := opts.MultiReaderIteratorPool().Get()
+	defer iter.Close()
+
+	var (
+		datapoints []codecBenchmarkDatapoint
+		start      = s.nowFn()
+	)
+	iter.Reset([]xio.SegmentReader{reader}, blockStart,
+		opts.RetentionOptions().BlockSize(), nsCtx.Schema)
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if len(annotation) > 0 {
+			// The annotation is only valid until the next call to Next.
+			annotation = append(ts.Annotation(nil), annotation...)
+		}
+		datapoints = append(datapoints, codecBenchmarkDatapoint{
+			dp:         dp,
+			unit:       unit,
+			annotation: annotation,
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return CodecStatsSnapshot{}, err
+	}
+	decodeNanos := s.nowFn().Sub(start).Nanoseconds()
+
+	encoder := opts.EncoderPool().Get()
+	defer encoder.Close()
+
+	start = s.nowFn()
+	encoder.Reset(blockStart, opts.DatabaseBlockOptions().DatabaseBlockAllocSize(), nsCtx.Schema)
+	for _, v := range datapoints {
+		if err := encoder.Encode(v.dp, v.unit, v.annotation); err != nil {
+			return CodecStatsSnapshot{}, err
+		}
+	}
+	encodeNanos := s.nowFn().Sub(start).Nanoseconds()
+
+	return CodecStatsSnapshot{
+		Blocks:       1,
+		Datapoints:   int64(len(datapoints)),
+		EncodedBytes: int64(segment.Len()),
+		EncodeNanos:  encodeNanos,
+		DecodeNanos:  decodeNanos,
+	}, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/x/context"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestCodecStatsSnapshotDerivedStats(t *testing.T) {
+	var empty CodecStatsSnapshot
+	require.Equal(t, 0.0, empty.CompressionRatio())
+	require.Equal(t, 0.0, empty.BytesPerDatapoint())
+
+	stats := CodecStatsSnapshot{
+		Datapoints:   10,
+		EncodedBytes: 40,
+		EncodeNanos:  100,
+		DecodeNanos:  50,
+	}
+	require.Equal(t, 4.0, stats.CompressionRatio())
+	require.Equal(t, 4.0, stats.BytesPerDatapoint())
+	require.Equal(t, 10.0, stats.EncodeNanosPerDatapoint())
+	require.Equal(t, 5.0, stats.DecodeNanosPerDatapoint())
+}
+
+func TestNewCodecStatsInvalidSampleRate(t *testing.T) {
+	_, err := NewCodecStats(1.5, tally.NoopScope, nil)
+	require.Error(t, err)
+}
+
+func TestBufferWarmFlushBenchmarksCodecStats(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	codecStats, err := NewCodecStats(1, scope, nil)
+	require.NoError(t, err)
+
+	opts := newBufferTestOptions().SetCodecStats(codecStats)
+	var (
+		rops  = opts.RetentionOptions()
+		curr  = xtime.Now().Truncate(rops.BlockSize())
+		start = curr
+	)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr.ToTime()
+	}))
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(databaseBufferResetOptions{
+		Options: opts,
+	})
+
+	data := []DecodedTestValue{
+		{curr, 1, xtime.Second, nil},
+		{curr.Add(secs(10)), 2, xtime.Second, nil},
+		{curr.Add(secs(20)), 3, xtime.Second, nil},
+		{curr.Add(secs(30)), 4, xtime.Second, nil},
+	}
+	for _, v := range data {
+		curr = v.Timestamp
+		verifyWriteToBufferSuccess(t, testID, buffer, v, nil)
+	}
+
+	var persisted int
+	persistFn := func(_ persist.Metadata, segment ts.Segment, _ uint32) error {
+		persisted = segment.Len()
+		return nil
+	}
+
+	ctx := context.NewBackground()
+	defer ctx.Close()
+
+	metadata := persist.NewMetadata(doc.Metadata{ID: []byte("some-id")})
+	outcome, err := buffer.WarmFlush(ctx, start, metadata, persistFn, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, FlushOutcomeFlushedToDisk, outcome)
+
+	codecStats.wait()
+	snapshot := codecStats.Snapshot()
+	require.Equal(t, int64(1), snapshot.Blocks)
+	require.Equal(t, int64(len(data)), snapshot.Datapoints)
+	require.Equal(t, int64(persisted), snapshot.EncodedBytes)
+	require.Equal(t, int64(0), snapshot.Errors)
+	require.True(t, snapshot.CompressionRatio() > 1)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(len(data)), counters["codec.benchmarked-datapoints+"].Value())
+}
@@ -21,6 +21,7 @@
 package series
 
 import (
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/retention"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
@@ -45,6 +46,9 @@ type options struct {
 	identifierPool                ident.Pool
 	stats                         Stats
 	coldWritesEnabled             bool
+	coldWritesFlag                featureflag.BoolFlag
+	sampleIntervalPolicy          SampleIntervalPolicy
+	codecStats                    *CodecStats
 	bufferBucketPool              *BufferBucketPool
 	bufferBucketVersionsPool      *BufferBucketVersionsPool
 	runtimeOptsMgr                m3dbruntime.OptionsManager
@@ -78,6 +82,9 @@ func (o *options) Validate() error {
 	if err := o.retentionOpts.Validate(); err != nil {
 		return err
 	}
+	if err := o.sampleIntervalPolicy.Validate(); err != nil {
+		return err
+	}
 	return ValidateCachePolicy(o.cachePolicy)
 }
 
@@ -201,6 +208,36 @@ func (o *options) ColdWritesEnabled() bool {
 	return o.coldWritesEnabled
 }
 
+func (o *options) SetColdWritesFlag(value featureflag.BoolFlag) Options {
+	opts := *o
+	opts.coldWritesFlag = value
+	return &opts
+}
+
+func (o *options) ColdWritesFlag() featureflag.BoolFlag {
+	return o.coldWritesFlag
+}
+
+func (o *options) SetSampleIntervalPolicy(value SampleIntervalPolicy) Options {
+	opts := *o
+	opts.sampleIntervalPolicy = value
+	return &opts
+}
+
+func (o *options) SampleIntervalPolicy() SampleIntervalPolicy {
+	return o.sampleIntervalPolicy
+}
+
+func (o *options) SetCodecStats(value *CodecStats) Options {
+	opts := *o
+	opts.codecStats = value
+	return &opts
+}
+
+func (o *options) CodecStats() *CodecStats {
+	return o.codecStats
+}
+
 func (o *options) SetBufferBucketVersionsPool(value *BufferBucketVersionsPool) Options {
 	opts := *o
 	opts.bufferBucketVersionsPool = value
@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/x/clock"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/atomic"
+)
+
+const (
+	day = 24 * time.Hour
+
+	readAgeTagName = "max_age"
+	// readAgeUnboundedTagValue tags the reads of data older than the largest
+	// read age bucket.
+	readAgeUnboundedTagValue = "inf"
+)
+
+// DefaultReadAgeBuckets are the default upper bounds of the age buckets
+// reads are tracked by.
+var DefaultReadAgeBuckets = []time.Duration{
+	6 * time.Hour,
+	day,
+	2 * day,
+	7 * day,
+	14 * day,
+	30 * day,
+	90 * day,
+	180 * day,
+	365 * day,
+}
+
+var errInvalidReadAgeBuckets = errors.New(
+	"read age buckets must be positive and in increasing order")
+
+// ValidateReadAgeBuckets validates the upper bounds of read age buckets.
+func ValidateReadAgeBuckets(buckets []time.Duration) error {
+	for i, b := range buckets {
+		if b <= 0 || (i > 0 && b <= buckets[i-1]) {
+			return errInvalidReadAgeBuckets
+		}
+	}
+	return nil
+}
+
+// ReadAgeStats tracks how recent the data read from a namespace is, so that
+// retention decisions can be based on how far back reads actually go. Each
+// read counts once towards every age bucket its time range overlaps, where
+// the age of data is how long ago it was written for.
+type ReadAgeStats struct {
+	nowFn   clock.NowFn
+	buckets []readAgeBucket
+	total   *atomic.Int64
+}
+
+type readAgeBucket struct {
+	// lower and upper are the bounds of the ages of the bucket, an upper of
+	// zero is unbounded.
+	lower time.Duration
+	upper time.Duration
+
+	// reads are the reads since the stats were last reported.
+	reads    *atomic.Int64
+	lastRead *atomic.Int64
+
+	readsCounter  tally.Counter
+	readsPercent  tally.Gauge
+	sinceLastRead tally.Gauge
+}
+
+// NewReadAgeStats returns new read age stats with the given upper bounds of
+// the age buckets, reads of data older than the largest are tracked in an
+// unbounded bucket.
+func NewReadAgeStats(
+	buckets []time.Duration,
+	scope tally.Scope,
+	nowFn clock.NowFn,
+) (*ReadAgeStats, error) {
+	if err := ValidateReadAgeBuckets(buckets); err != nil {
+		return nil, err
+	}
+	if nowFn == nil {
+		nowFn = time.Now
+	}
+
+	var (
+		subScope = scope.SubScope("read-age")
+		s        = &ReadAgeStats{
+			nowFn:   nowFn,
+			buckets: make([]readAgeBucket, 0, len(buckets)+1),
+			total:   atomic.NewInt64(0),
+		}
+		lower time.Duration
+	)
+	newBucket := func(upper time.Duration, tagValue string) readAgeBucket {
+		bucketScope := subScope.Tagged(map[string]string{readAgeTagName: tagValue})
+		return readAgeBucket{
+			lower:         lower,
+			upper:         upper,
+			reads:         atomic.NewInt64(0),
+			lastRead:      atomic.NewInt64(0),
+			readsCounter:  bucketScope.Counter("reads"),
+			readsPercent:  bucketScope.Gauge("reads-percent"),
+			sinceLastRead: bucketScope.Gauge("seconds-since-last-read"),
+		}
+	}
+	for _, upper := range buckets {
+		s.buckets = append(s.buckets, newBucket(upper, xtime.ToExtendedString(upper)))
+		lower = upper
+	}
+	s.buckets = append(s.buckets, newBucket(0, readAgeUnboundedTagValue))
+	return s, nil
+}
+
+// RecordRead records a read of the time range.
+func (s *ReadAgeStats) RecordRead(start, end xtime.UnixNano) {
+	now := s.nowFn()
+	s.total.Inc()
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		// The bucket covers the data written in [now-upper, now-lower).
+		if end <= xtime.ToUnixNano(now.Add(-b.upper)) && b.upper != 0 {
+			continue
+		}
+		if start >= xtime.ToUnixNano(now.Add(-b.lower)) {
+			continue
+		}
+		b.reads.Inc()
+		b.lastRead.Store(now.UnixNano())
+		b.readsCounter.Inc(1)
+	}
+}
+
+// Report reports the percentage of the reads since the last report that
+// read data of each age bucket, and how long ago each was last read from.
+func (s *ReadAgeStats) Report() {
+	var (
+		now   = s.nowFn()
+		total = s.total.Swap(0)
+	)
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		reads := b.reads.Swap(0)
+		if total > 0 {
+			b.readsPercent.Update(100 * float64(reads) / float64(total))
+		} else {
+			b.readsPercent.Update(0)
+		}
+		if lastRead := b.lastRead.Load(); lastRead > 0 {
+			b.sinceLastRead.Update(now.Sub(time.Unix(0, lastRead)).Seconds())
+		}
+	}
+}
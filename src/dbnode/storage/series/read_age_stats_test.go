@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"testing"
+	"time"
+
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestNewReadAgeStatsInvalidBuckets(t *testing.T) {
+	for _, buckets := range [][]time.Duration{
+		{0},
+		{-time.Hour},
+		{time.Hour, time.Hour},
+		{day, time.Hour},
+	} {
+		_, err := NewReadAgeStats(buckets, tally.NoopScope, nil)
+		require.Error(t, err)
+	}
+}
+
+func TestReadAgeStats(t *testing.T) {
+	var (
+		scope = tally.NewTestScope("", nil)
+		now   = time.Now()
+		nowFn = func() time.Time { return now }
+	)
+	stats, err := NewReadAgeStats([]time.Duration{time.Hour, day}, scope, nowFn)
+	require.NoError(t, err)
+
+	// A read of recent data only.
+	stats.RecordRead(xtime.ToUnixNano(now.Add(-30*time.Minute)), xtime.ToUnixNano(now))
+	// A read of data spanning the day bucket and the unbounded one.
+	stats.RecordRead(xtime.ToUnixNano(now.Add(-2*day)), xtime.ToUnixNano(now.Add(-12*time.Hour)))
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["read-age.reads+max_age=1h"].Value())
+	require.Equal(t, int64(1), counters["read-age.reads+max_age=1d"].Value())
+	require.Equal(t, int64(1), counters["read-age.reads+max_age=inf"].Value())
+
+	now = now.Add(10 * time.Second)
+	stats.Report()
+
+	gauges := scope.Snapshot().Gauges()
+	require.Equal(t, 50.0, gauges["read-age.reads-percent+max_age=1h"].Value())
+	require.Equal(t, 50.0, gauges["read-age.reads-percent+max_age=1d"].Value())
+	require.Equal(t, 50.0, gauges["read-age.reads-percent+max_age=inf"].Value())
+	require.Equal(t, 10.0, gauges["read-age.seconds-since-last-read+max_age=1h"].Value())
+
+	// Percentages are of the reads since the last report.
+	stats.RecordRead(xtime.ToUnixNano(now.Add(-time.Minute)), xtime.ToUnixNano(now))
+	now = now.Add(10 * time.Second)
+	stats.Report()
+
+	gauges = scope.Snapshot().Gauges()
+	require.Equal(t, 100.0, gauges["read-age.reads-percent+max_age=1h"].Value())
+	require.Equal(t, 0.0, gauges["read-age.reads-percent+max_age=1d"].Value())
+	require.Equal(t, 10.0, gauges["read-age.seconds-since-last-read+max_age=1h"].Value())
+	require.Equal(t, 20.0, gauges["read-age.seconds-since-last-read+max_age=1d"].Value())
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+var (
+	errSampleIntervalModeUnspecified = errors.New("sample interval mode unspecified")
+)
+
+// SampleIntervalMode is how datapoints written to a series sooner than the
+// minimum sample interval after the previous datapoint are handled.
+type SampleIntervalMode uint
+
+const (
+	// SampleIntervalReject rejects datapoints written sooner than the minimum
+	// sample interval after the previous datapoint.
+	SampleIntervalReject SampleIntervalMode = iota
+	// SampleIntervalCoalesceLastWins coalesces datapoints written sooner than
+	// the minimum sample interval after the previous datapoint into the
+	// previous datapoint, the last value written wins.
+	SampleIntervalCoalesceLastWins
+	// SampleIntervalAverage coalesces datapoints written sooner than the
+	// minimum sample interval after the previous datapoint into the previous
+	// datapoint, the average of the values written is kept.
+	SampleIntervalAverage
+
+	// DefaultSampleIntervalMode is the default sample interval mode.
+	DefaultSampleIntervalMode = SampleIntervalReject
+)
+
+// ValidSampleIntervalModes returns the valid sample interval modes.
+func ValidSampleIntervalModes() []SampleIntervalMode {
+	return []SampleIntervalMode{
+		SampleIntervalReject,
+		SampleIntervalCoalesceLastWins,
+		SampleIntervalAverage,
+	}
+}
+
+func (m SampleIntervalMode) String() string {
+	switch m {
+	case SampleIntervalReject:
+		return "reject"
+	case SampleIntervalCoalesceLastWins:
+		return "coalesce_last_wins"
+	case SampleIntervalAverage:
+		return "average"
+	}
+	return "unknown"
+}
+
+// ParseSampleIntervalMode parses a SampleIntervalMode from a string.
+func ParseSampleIntervalMode(str string) (SampleIntervalMode, error) {
+	var r SampleIntervalMode
+	if str == "" {
+		return r, errSampleIntervalModeUnspecified
+	}
+	for _, valid := range ValidSampleIntervalModes() {
+		if str == valid.String() {
+			r = valid
+			return r, nil
+		}
+	}
+	return r, fmt.Errorf("invalid series SampleIntervalMode '%s' valid types are: %v",
+		str, ValidSampleIntervalModes())
+}
+
+// MarshalYAML marshals a SampleIntervalMode.
+func (m SampleIntervalMode) MarshalYAML() (interface{}, error) {
+	return m.String(), nil
+}
+
+// UnmarshalYAML unmarshals a SampleIntervalMode into a valid type from string.
+func (m *SampleIntervalMode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	r, err := ParseSampleIntervalMode(str)
+	if err != nil {
+		return err
+	}
+	*m = r
+	return nil
+}
+
+// SampleIntervalPolicy is the policy enforcing a minimum interval between
+// the datapoints written to a series, a zero minimum interval disables the
+// policy.
+type SampleIntervalPolicy struct {
+	// MinInterval is the minimum interval between datapoints of a series.
+	MinInterval time.Duration
+	// Mode is how datapoints written sooner than the minimum interval after
+	// the previous datapoint are handled.
+	Mode SampleIntervalMode
+}
+
+// Enabled returns whether the policy is enabled.
+func (p SampleIntervalPolicy) Enabled() bool {
+	return p.MinInterval > 0
+}
+
+// Validate validates the policy.
+func (p SampleIntervalPolicy) Validate() error {
+	if p.MinInterval < 0 {
+		return fmt.Errorf("sample interval min interval must not be negative: %v",
+			p.MinInterval)
+	}
+	for _, valid := range ValidSampleIntervalModes() {
+		if p.Mode == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid series SampleIntervalMode '%d' valid types are: %v",
+		uint(p.Mode), ValidSampleIntervalModes())
+}
+
+// sampleIntervalState is the state of the sample interval policy of a
+// series, it tracks the datapoint that later datapoints within the minimum
+// interval are coalesced into.
+type sampleIntervalState struct {
+	timestamp xtime.UnixNano
+	sum       float64
+	count     float64
+}
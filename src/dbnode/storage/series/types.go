@@ -23,6 +23,7 @@ package series
 import (
 	"time"
 
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/persist"
@@ -371,6 +372,30 @@ type Options interface {
 	// ColdWritesEnabled returns whether cold writes are enabled.
 	ColdWritesEnabled() bool
 
+	// SetColdWritesFlag sets the feature flag that rejects cold writes when
+	// false even if cold writes are enabled, a nil flag has no effect.
+	SetColdWritesFlag(value featureflag.BoolFlag) Options
+
+	// ColdWritesFlag returns the feature flag that rejects cold writes when
+	// false even if cold writes are enabled.
+	ColdWritesFlag() featureflag.BoolFlag
+
+	// SetSampleIntervalPolicy sets the minimum interval policy enforced on
+	// the datapoints written to a series.
+	SetSampleIntervalPolicy(value SampleIntervalPolicy) Options
+
+	// SampleIntervalPolicy returns the minimum interval policy enforced on
+	// the datapoints written to a series.
+	SampleIntervalPolicy() SampleIntervalPolicy
+
+	// SetCodecStats sets the codec stats the flushed blocks of a series are
+	// benchmarked into, nil disables benchmarking.
+	SetCodecStats(value *CodecStats) Options
+
+	// CodecStats returns the codec stats the flushed blocks of a series are
+	// benchmarked into, nil disables benchmarking.
+	CodecStats() *CodecStats
+
 	// SetBufferBucketVersionsPool sets the BufferBucketVersionsPool.
 	SetBufferBucketVersionsPool(value *BufferBucketVersionsPool) Options
 
@@ -397,6 +422,9 @@ type Stats struct {
 	encodersPerBlock          tally.Histogram
 	encoderLimitWriteRejected tally.Counter
 	snapshotMergesEachBucket  tally.Counter
+	sampleIntervalRejected    tally.Counter
+	sampleIntervalCoalesced   tally.Counter
+	sampleIntervalAveraged    tally.Counter
 }
 
 // NewStats returns a new Stats for the provided scope.
@@ -411,6 +439,15 @@ func NewStats(scope tally.Scope) Stats {
 		encodersPerBlock:          subScope.Histogram("encoders-per-block", buckets),
 		encoderLimitWriteRejected: subScope.Counter("encoder-limit-write-rejected"),
 		snapshotMergesEachBucket:  subScope.Counter("snapshot-merges-each-bucket"),
+		sampleIntervalRejected: subScope.Tagged(map[string]string{
+			"mode": SampleIntervalReject.String(),
+		}).Counter("sample-interval-violations"),
+		sampleIntervalCoalesced: subScope.Tagged(map[string]string{
+			"mode": SampleIntervalCoalesceLastWins.String(),
+		}).Counter("sample-interval-violations"),
+		sampleIntervalAveraged: subScope.Tagged(map[string]string{
+			"mode": SampleIntervalAverage.String(),
+		}).Counter("sample-interval-violations"),
 	}
 }
 
@@ -434,6 +471,19 @@ func (s Stats) IncEncoderLimitWriteRejected() {
 	s.encoderLimitWriteRejected.Inc(1)
 }
 
+// IncSampleIntervalViolations incs the sample interval violations stat of
+// the sample interval mode that handled the violation.
+func (s Stats) IncSampleIntervalViolations(mode SampleIntervalMode) {
+	switch mode {
+	case SampleIntervalReject:
+		s.sampleIntervalRejected.Inc(1)
+	case SampleIntervalCoalesceLastWins:
+		s.sampleIntervalCoalesced.Inc(1)
+	case SampleIntervalAverage:
+		s.sampleIntervalAveraged.Inc(1)
+	}
+}
+
 // WriteType is an enum for warm/cold write types.
 type WriteType int
 
@@ -65,6 +65,7 @@ import (
 const (
 	shardIterateBatchPercent = 0.01
 	shardIterateBatchMinSize = 16
+	shardIndexBackfillBatch  = 1024
 	metricLabelName          = "__name__"
 )
 
@@ -2141,15 +2142,55 @@ func (s *dbShard) loadBlock(
 	tags ident.Tags,
 	block block.DatabaseBlock,
 ) (loadBlockResult, error) {
-	var (
-		timestamp = block.StartTime()
-		result    loadBlockResult
-	)
+	// NB(rartoul): The data being loaded is not part of the bootstrap process then it needs to be
+	// loaded as a cold write because the load could be happening concurrently with
+	// other processes like the flush (as opposed to bootstrap which cannot happen
+	// concurrently with a flush) and there is no way to know if this series/block
+	// combination has been warm flushed or not yet since updating the shard block state
+	// doesn't happen until the entire flush completes.
+	//
+	// As a result the only safe operation is to load the block as a cold write which
+	// ensures that the data will eventually be flushed and merged with the existing data
+	// on disk in the two scenarios where the Load() API is used (cold writes and repairs).
+	return s.loadBlockWithWriteType(id, tags, block, series.ColdWrite)
+}
+
+func (s *dbShard) loadBlockWithWriteType(
+	id ident.ID,
+	tags ident.Tags,
+	block block.DatabaseBlock,
+	writeType series.WriteType,
+) (loadBlockResult, error) {
+	timestamp := block.StartTime()
+	entry, shardOpts, result, err := s.retrieveOrInsertSeriesForLoad(id, tags, timestamp)
+	if err != nil {
+		return result, err
+	}
+
+	// Always decrement the reader writer count.
+	defer entry.DecrementReaderWriterCount()
+
+	if err := entry.Series.LoadBlock(block, writeType); err != nil {
+		return result, err
+	}
+	// Cannot close blocks once done as series takes ref to them.
+
+	return result, s.maybeIndexLoadedSeries(entry, shardOpts, timestamp)
+}
+
+// retrieveOrInsertSeriesForLoad returns the entry of a series data is loaded
+// for with its reader writer count incremented, the caller must decrement it.
+func (s *dbShard) retrieveOrInsertSeriesForLoad(
+	id ident.ID,
+	tags ident.Tags,
+	timestamp xtime.UnixNano,
+) (*Entry, WritableSeriesOptions, loadBlockResult, error) {
+	var result loadBlockResult
 
 	// First lookup if series already exists.
 	entry, shardOpts, err := s.TryRetrieveSeriesAndIncrementReaderWriterCount(id)
 	if err != nil && err != errShardEntryNotFound {
-		return result, err
+		return nil, shardOpts, result, err
 	}
 	if entry == nil {
 		// Synchronously insert to avoid waiting for the insert queue which could potentially
@@ -2168,7 +2209,7 @@ func (s *dbShard) loadBlock(
 				},
 			})
 		if err != nil {
-			return result, err
+			return nil, shardOpts, result, err
 		}
 	} else {
 		// No longer needed as we found the series and we don't require
@@ -2180,36 +2221,22 @@ func (s *dbShard) loadBlock(
 		// be garbage collected)
 		result.canFinalizeTags = true
 	}
+	return entry, shardOpts, result, nil
+}
 
-	// Always decrement the reader writer count.
-	defer entry.DecrementReaderWriterCount()
-
-	// NB(rartoul): The data being loaded is not part of the bootstrap process then it needs to be
-	// loaded as a cold write because the load could be happening concurrently with
-	// other processes like the flush (as opposed to bootstrap which cannot happen
-	// concurrently with a flush) and there is no way to know if this series/block
-	// combination has been warm flushed or not yet since updating the shard block state
-	// doesn't happen until the entire flush completes.
-	//
-	// As a result the only safe operation is to load the block as a cold write which
-	// ensures that the data will eventually be flushed and merged with the existing data
-	// on disk in the two scenarios where the Load() API is used (cold writes and repairs).
-	if err := entry.Series.LoadBlock(block, series.ColdWrite); err != nil {
-		return result, err
-	}
-	// Cannot close blocks once done as series takes ref to them.
-
-	// Check if needs to be reverse indexed.
-	if s.reverseIndex != nil &&
-		entry.NeedsIndexUpdate(s.reverseIndex.BlockStartForWriteTime(timestamp)) {
-		err = s.insertSeriesForIndexingAsyncBatched(entry, timestamp,
-			shardOpts.WriteNewSeriesAsync)
-		if err != nil {
-			return result, err
-		}
+// maybeIndexLoadedSeries enqueues a series data was loaded for to be reverse
+// indexed if it is not yet indexed for the index block of the timestamp.
+func (s *dbShard) maybeIndexLoadedSeries(
+	entry *Entry,
+	shardOpts WritableSeriesOptions,
+	timestamp xtime.UnixNano,
+) error {
+	if s.reverseIndex == nil ||
+		!entry.NeedsIndexUpdate(s.reverseIndex.BlockStartForWriteTime(timestamp)) {
+		return nil
 	}
-
-	return result, nil
+	return s.insertSeriesForIndexingAsyncBatched(entry, timestamp,
+		shardOpts.WriteNewSeriesAsync)
 }
 
 func (s *dbShard) cacheShardIndices() error {
@@ -2422,6 +2449,194 @@ func (s *dbShard) ColdFlush(
 	return flush, multiErr.FinalError()
 }
 
+func (s *dbShard) BackfillIndex(
+	start, end xtime.UnixNano,
+) (ShardIndexBackfillResult, error) {
+	if s.reverseIndex == nil || !s.IsBootstrapped() {
+		return ShardIndexBackfillResult{}, nil
+	}
+
+	var (
+		now            = xtime.ToUnixNano(s.nowFn())
+		pastLimit      = now.Add(-1 * s.namespace.Options().RetentionOptions().BufferPast())
+		nowIndexBlock  = s.reverseIndex.BlockStartForWriteTime(now)
+		nonEmptyBlocks = make(map[xtime.UnixNano]struct{})
+		indexBlocks    = make(map[xtime.UnixNano]struct{})
+		pending        = make([]writes.PendingIndexInsert, 0, shardIndexBackfillBatch)
+		result         ShardIndexBackfillResult
+		multiErr       xerrors.MultiError
+	)
+	writePending := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := s.reverseIndex.WritePending(pending); err != nil {
+			result.NumSeriesSkipped += len(pending)
+			multiErr = multiErr.Add(err)
+		} else {
+			result.NumSeriesQueued += len(pending)
+		}
+		pending = pending[:0]
+	}
+
+	s.forEachShardEntry(func(entry *Entry) bool {
+		for k := range nonEmptyBlocks {
+			delete(nonEmptyBlocks, k)
+		}
+		for k := range indexBlocks {
+			delete(indexBlocks, k)
+		}
+
+		// NB: only data still held in the series buffer is considered here,
+		// the series of blocks flushed since are indexed from their filesets.
+		entry.Series.MarkNonEmptyBlocks(nonEmptyBlocks)
+		for blockStart := range nonEmptyBlocks {
+			if blockStart.Before(start) || !blockStart.Before(end) {
+				continue
+			}
+
+			// Pick a write time the index will accept for the block: the current
+			// time for the active index block, otherwise the earliest time still
+			// within buffer past (or the block start itself for cold writes).
+			timestamp := blockStart
+			indexBlockStart := s.reverseIndex.BlockStartForWriteTime(blockStart)
+			if indexBlockStart.Equal(nowIndexBlock) {
+				timestamp = now
+			} else if blockStart.Before(pastLimit) &&
+				s.reverseIndex.BlockStartForWriteTime(pastLimit).Equal(indexBlockStart) {
+				timestamp = pastLimit
+			}
+
+			if _, ok := indexBlocks[indexBlockStart]; ok {
+				continue
+			}
+			indexBlocks[indexBlockStart] = struct{}{}
+
+			if !entry.NeedsIndexUpdate(indexBlockStart) {
+				continue
+			}
+			pending = append(pending, s.pendingIndexInsert(entry, timestamp))
+		}
+
+		if len(pending) >= shardIndexBackfillBatch {
+			writePending()
+		}
+		return true
+	})
+	writePending()
+
+	err := s.backfillFlushedIndex(start, end, now, &result)
+	multiErr = multiErr.Add(err)
+
+	return result, multiErr.FinalError()
+}
+
+// backfillFlushedIndex indexes the series of the warm and cold flushed blocks
+// in the range [start, end) that are missing from the reverse index, the
+// series of these blocks may no longer be held by the shard.
+func (s *dbShard) backfillFlushedIndex(
+	start, end, now xtime.UnixNano,
+	result *ShardIndexBackfillResult,
+) error {
+	var (
+		ropts      = s.namespace.Options().RetentionOptions()
+		blockSize  = ropts.BlockSize()
+		flushStart = retention.FlushTimeStart(ropts, now)
+		multiErr   xerrors.MultiError
+	)
+	for blockStart := start.Truncate(blockSize); blockStart.Before(end); blockStart = blockStart.Add(blockSize) {
+		if blockStart.Before(flushStart) {
+			continue
+		}
+		// NB: blocks that were never flushed are still held by the series
+		// buffers and were queued from the shard entries.
+		flushState := s.flushStateNoBootstrapCheck(blockStart)
+		if flushState.WarmStatus.DataFlushed != fileOpSuccess &&
+			flushState.ColdVersionRetrievable == 0 {
+			continue
+		}
+
+		docs, numSkipped, err := s.flushedBlockDocsMissingFromIndex(blockStart,
+			flushState.ColdVersionRetrievable)
+		result.NumSeriesSkipped += numSkipped
+		if err != nil {
+			result.NumBlocksSkipped++
+			multiErr = multiErr.Add(fmt.Errorf(
+				"unable to read flushed block %v: %w", blockStart, err))
+			continue
+		}
+		if err := s.reverseIndex.AddFlushedSeries(s.ID(), blockStart, docs); err != nil {
+			result.NumSeriesSkipped += len(docs)
+			multiErr = multiErr.Add(fmt.Errorf(
+				"unable to index flushed block %v: %w", blockStart, err))
+			continue
+		}
+		result.NumSeriesQueued += len(docs)
+	}
+	return multiErr.FinalError()
+}
+
+// flushedBlockDocsMissingFromIndex returns the documents of the series in a
+// volume of a flushed block that are not known to be indexed, along with the
+// number of series of the volume that could not be read.
+func (s *dbShard) flushedBlockDocsMissingFromIndex(
+	blockStart xtime.UnixNano,
+	volume int,
+) ([]doc.Metadata, int, error) {
+	reader, err := s.newReaderFn(s.opts.BytesPool(), s.opts.CommitLogOptions().FilesystemOptions())
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := reader.Open(fs.DataReaderOpenOptions{
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:   s.namespace.ID(),
+			Shard:       s.ID(),
+			BlockStart:  blockStart,
+			VolumeIndex: volume,
+		},
+		FileSetType: persist.FileSetFlushType,
+	}); err != nil {
+		return nil, 0, err
+	}
+	defer reader.Close() // nolint: errcheck
+
+	var (
+		indexBlockStart = s.reverseIndex.BlockStartForWriteTime(blockStart)
+		docs            []doc.Metadata
+		numIndexed      int
+		numSkipped      int
+	)
+	for {
+		id, tagsIter, _, _, err := reader.ReadMetadata()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// None of the series of the volume that are not known to be
+			// indexed are backfilled.
+			return nil, reader.Entries() - numIndexed, err
+		}
+
+		s.RLock()
+		entry, lookupErr := s.lookupEntryWithLock(id)
+		s.RUnlock()
+		if lookupErr == nil && entry.IndexedForBlockStart(indexBlockStart) {
+			tagsIter.Close()
+			numIndexed++
+			continue
+		}
+
+		d, err := convert.FromSeriesIDAndTagIter(id, tagsIter)
+		tagsIter.Close()
+		if err != nil {
+			numSkipped++
+			continue
+		}
+		docs = append(docs, d)
+	}
+	return docs, numSkipped, nil
+}
+
 func (s *dbShard) FilterBlocksNeedSnapshot(blockStarts []xtime.UnixNano) []xtime.UnixNano {
 	if !s.IsBootstrapped() {
 		return nil
@@ -2554,6 +2769,149 @@ func (s *dbShard) FlushState(blockStart xtime.UnixNano) (fileOpState, error) {
 	return state, nil
 }
 
+func (s *dbShard) RegisterImportedVolume(
+	blockStart xtime.UnixNano,
+	volume int,
+	nsCtx namespace.Context,
+) error {
+	state, err := s.FlushState(blockStart)
+	if err != nil {
+		return err
+	}
+
+	// Imported volumes of warm flushed blocks directly follow the latest
+	// flushed volume and replace it for reads. Blocks that have not been warm
+	// flushed yet have no volume on disk, their imported volume is the first
+	// volume and is loaded into memory so that the warm flush merges it with
+	// the writes of the block.
+	warmFlushed := state.WarmStatus.DataFlushed == fileOpSuccess
+	expected := 0
+	if warmFlushed {
+		expected = state.ColdVersionFlushed + 1
+	}
+	if volume != expected {
+		return xerrors.NewInvalidParamsError(fmt.Errorf(
+			"imported volume %d of block %v must be volume %d", volume, blockStart, expected))
+	}
+	if warmFlushed && s.reverseIndex != nil && !s.coldWritesEnabled {
+		// The series of flushed blocks are indexed as cold writes, which the
+		// index rejects when cold writes are disabled.
+		return xerrors.NewInvalidParamsError(fmt.Errorf(
+			"cannot index imported volume of flushed block %v without cold writes enabled", blockStart))
+	}
+	if !warmFlushed {
+		// Only load blocks that still accept warm writes so that the block
+		// cannot be warm flushed concurrently.
+		retentionOpts := s.namespace.Options().RetentionOptions()
+		flushableAt := blockStart.Add(retentionOpts.BlockSize()).Add(retentionOpts.BufferPast())
+		if !xtime.ToUnixNano(s.nowFn()).Before(flushableAt) {
+			return xerrors.NewInvalidParamsError(fmt.Errorf(
+				"block %v is pending its warm flush, retry once it has been flushed", blockStart))
+		}
+	}
+
+	filePathPrefix := s.opts.CommitLogOptions().FilesystemOptions().FilePathPrefix()
+	exists, err := fs.DataFileSetExists(filePathPrefix, s.namespace.ID(), s.ID(), blockStart, volume)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return xerrors.NewInvalidParamsError(fmt.Errorf(
+			"imported volume %d does not exist for block %v", volume, blockStart))
+	}
+
+	if !warmFlushed {
+		if err := s.importVolumeSeries(blockStart, volume, true, nsCtx); err != nil {
+			return err
+		}
+		// The warm flush of the block writes the first volume.
+		return fs.DeleteFileSetAt(filePathPrefix, s.namespace.ID(), s.ID(), blockStart, volume)
+	}
+
+	if err := s.finishWriting(blockStart, volume, false); err != nil {
+		return err
+	}
+	return s.importVolumeSeries(blockStart, volume, false, nsCtx)
+}
+
+// importVolumeSeries adds the series of an imported volume to the reverse
+// index, loading their data into memory as warm writes if load is set.
+func (s *dbShard) importVolumeSeries(
+	blockStart xtime.UnixNano,
+	volume int,
+	load bool,
+	nsCtx namespace.Context,
+) error {
+	if !load && s.reverseIndex == nil {
+		return nil
+	}
+
+	reader, err := s.newReaderFn(s.opts.BytesPool(), s.opts.CommitLogOptions().FilesystemOptions())
+	if err != nil {
+		return err
+	}
+	if err := reader.Open(fs.DataReaderOpenOptions{
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:   s.namespace.ID(),
+			Shard:       s.ID(),
+			BlockStart:  blockStart,
+			VolumeIndex: volume,
+		},
+		FileSetType: persist.FileSetFlushType,
+	}); err != nil {
+		return err
+	}
+	defer reader.Close() // nolint: errcheck
+
+	var (
+		blockSize = s.namespace.Options().RetentionOptions().BlockSize()
+		multiErr  = xerrors.NewMultiError()
+	)
+	for {
+		var (
+			id       ident.ID
+			tagsIter ident.TagIterator
+			data     checked.Bytes
+		)
+		if load {
+			id, tagsIter, data, _, err = reader.Read()
+		} else {
+			id, tagsIter, _, _, err = reader.ReadMetadata()
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		tags, err := convert.TagsFromTagsIter(id, tagsIter, nil)
+		tagsIter.Close()
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+
+		if load {
+			dbBlock := block.NewDatabaseBlock(blockStart, blockSize,
+				ts.NewSegment(data, nil, 0, ts.FinalizeHead), s.opts.DatabaseBlockOptions(), nsCtx)
+			_, err = s.loadBlockWithWriteType(id, tags, dbBlock, series.WarmWrite)
+			multiErr = multiErr.Add(err)
+			continue
+		}
+
+		entry, shardOpts, _, err := s.retrieveOrInsertSeriesForLoad(id, tags, blockStart)
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+		err = s.maybeIndexLoadedSeries(entry, shardOpts, blockStart)
+		entry.DecrementReaderWriterCount()
+		multiErr = multiErr.Add(err)
+	}
+	return multiErr.FinalError()
+}
+
 func (s *dbShard) flushStateNoBootstrapCheck(blockStart xtime.UnixNano) fileOpState {
 	s.flushState.RLock()
 	check := s.flushStateWithRLock(blockStart)
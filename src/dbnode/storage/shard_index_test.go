@@ -21,15 +21,20 @@
 package storage
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/index/convert"
 	"github.com/m3db/m3/src/dbnode/storage/series"
+	"github.com/m3db/m3/src/dbnode/ts/writes"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
@@ -211,6 +216,79 @@ func TestShardAsyncIndexIfExpired(t *testing.T) {
 	assert.True(t, seriesWrite.NeedsIndex)
 }
 
+func TestShardBackfillIndex(t *testing.T) {
+	defer leaktest.CheckTimeout(t, 2*time.Second)()
+
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		opts           = DefaultTestOptions()
+		now            = xtime.Now()
+		indexBlockSize = namespace.NewIndexOptions().BlockSize()
+		indexBlock     = now.Truncate(indexBlockSize)
+	)
+	idx := NewMockNamespaceIndex(ctrl)
+	idx.EXPECT().BlockStartForWriteTime(gomock.Any()).
+		DoAndReturn(func(t xtime.UnixNano) xtime.UnixNano {
+			return t.Truncate(indexBlockSize)
+		}).
+		AnyTimes()
+	// Simulate indexing being paused by finalizing without success.
+	idx.EXPECT().WriteBatch(gomock.Any()).Do(
+		func(batch *index.WriteBatch) {
+			for _, e := range batch.PendingEntries() {
+				e.OnIndexSeries.OnIndexFinalize(indexBlock)
+			}
+		}).Return(nil).AnyTimes()
+
+	shard := testDatabaseShardWithIndexFn(t, opts, idx, false)
+	shard.SetRuntimeOptions(runtime.NewOptions().SetWriteNewSeriesAsync(false))
+	shard.bootstrapState = Bootstrapped
+	defer shard.Close()
+
+	ctx := context.NewBackground()
+	defer ctx.Close()
+
+	seriesWrite, err := shard.WriteTagged(ctx, ident.StringID("foo"),
+		convert.NewTagsIterMetadataResolver(
+			ident.NewTagsIterator(ident.NewTags(ident.StringTag("name", "value")))),
+		now, 1.0, xtime.Second, nil, series.WriteOptions{})
+	require.NoError(t, err)
+	require.True(t, seriesWrite.WasWritten)
+
+	var (
+		blockSize = shard.namespace.Options().RetentionOptions().BlockSize()
+		start     = now.Truncate(blockSize)
+		end       = start.Add(blockSize)
+		pending   []writes.PendingIndexInsert
+	)
+	idx.EXPECT().WritePending(gomock.Any()).DoAndReturn(
+		func(inserts []writes.PendingIndexInsert) error {
+			for _, p := range inserts {
+				p.Entry.OnIndexSeries.OnIndexSuccess(indexBlock)
+				p.Entry.OnIndexSeries.OnIndexFinalize(indexBlock)
+			}
+			pending = append(pending, inserts...)
+			return nil
+		})
+
+	result, err := shard.BackfillIndex(start, end)
+	require.NoError(t, err)
+	require.Equal(t, ShardIndexBackfillResult{NumSeriesQueued: 1}, result)
+	require.Len(t, pending, 1)
+	require.Equal(t, []byte("foo"), pending[0].Document.ID)
+
+	// Series already indexed and blocks outside the range are skipped.
+	result, err = shard.BackfillIndex(start, end)
+	require.NoError(t, err)
+	require.Equal(t, ShardIndexBackfillResult{}, result)
+
+	result, err = shard.BackfillIndex(end, end.Add(blockSize))
+	require.NoError(t, err)
+	require.Equal(t, ShardIndexBackfillResult{}, result)
+}
+
 // TODO(prateek): wire tests above to use the field `ts`
 // nolint
 type testIndexWrite struct {
@@ -218,3 +296,75 @@ type testIndexWrite struct {
 	tags ident.Tags
 	ts   time.Time
 }
+
+func TestShardBackfillIndexFlushedBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	leaseMgr := block.NewMockLeaseManager(ctrl)
+	leaseMgr.EXPECT().RegisterLeaser(gomock.Any()).Return(nil).AnyTimes()
+	leaseMgr.EXPECT().UnregisterLeaser(gomock.Any()).Return(nil).AnyTimes()
+	shard, _ := newImportTestShard(t, ctrl, dir, leaseMgr, false)
+	defer shard.Close()
+
+	var (
+		blockSize = shard.namespace.Options().RetentionOptions().BlockSize()
+		start     = xtime.Now().Truncate(blockSize).Add(-2 * blockSize)
+		end       = start.Add(2 * blockSize)
+	)
+	writeImportTestVolume(t, shard, start, 0, "bar", "foo")
+
+	ctx := context.NewBackground()
+	defer ctx.Close()
+	require.NoError(t, shard.Bootstrap(ctx, namespace.Context{ID: defaultTestNs1ID}))
+
+	// The series of the flushed block are no longer held by the shard and
+	// are indexed from the fileset.
+	var docs []doc.Metadata
+	idx := shard.reverseIndex.(*MockNamespaceIndex)
+	idx.EXPECT().AddFlushedSeries(shard.ID(), start, gomock.Any()).DoAndReturn(
+		func(_ uint32, _ xtime.UnixNano, flushed []doc.Metadata) error {
+			docs = append(docs, flushed...)
+			return nil
+		})
+
+	result, err := shard.BackfillIndex(start, end)
+	require.NoError(t, err)
+	require.Equal(t, ShardIndexBackfillResult{NumSeriesQueued: 2}, result)
+	require.Len(t, docs, 2)
+	require.Equal(t, []byte("bar"), docs[0].ID)
+	require.Equal(t, []doc.Field{{Name: []byte("name"), Value: []byte("bar")}}, docs[0].Fields)
+	require.Equal(t, []byte("foo"), docs[1].ID)
+
+	// Series the index rejects are counted as skipped.
+	idx.EXPECT().AddFlushedSeries(shard.ID(), start, gomock.Any()).
+		Return(errors.New("index closed"))
+	result, err = shard.BackfillIndex(start, end)
+	require.Error(t, err)
+	require.Equal(t, ShardIndexBackfillResult{NumSeriesSkipped: 2}, result)
+
+	// Blocks that were only cold flushed are read from their latest volume.
+	coldStart := start.Add(blockSize)
+	writeImportTestVolume(t, shard, coldStart, 1, "baz")
+	shard.setFlushStateColdVersionRetrievable(coldStart, 1)
+	docs = nil
+	idx.EXPECT().AddFlushedSeries(shard.ID(), coldStart, gomock.Any()).DoAndReturn(
+		func(_ uint32, _ xtime.UnixNano, flushed []doc.Metadata) error {
+			docs = append(docs, flushed...)
+			return nil
+		})
+	result, err = shard.BackfillIndex(coldStart, end)
+	require.NoError(t, err)
+	require.Equal(t, ShardIndexBackfillResult{NumSeriesQueued: 1}, result)
+	require.Len(t, docs, 1)
+	require.Equal(t, []byte("baz"), docs[0].ID)
+
+	// Blocks outside of the range are not read.
+	result, err = shard.BackfillIndex(end, end.Add(blockSize))
+	require.NoError(t, err)
+	require.Equal(t, ShardIndexBackfillResult{}, result)
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/index/convert"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -48,6 +49,7 @@ import (
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/x/checked"
 	"github.com/m3db/m3/src/x/context"
+	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/pool"
 	xtest "github.com/m3db/m3/src/x/test"
@@ -319,6 +321,241 @@ func TestShardBootstrapWithFlushVersionNoCleanUp(t *testing.T) {
 	require.Equal(t, numVolumes-1, flushState.ColdVersionFlushed)
 }
 
+// newImportTestShard returns a shard with a file path prefix in the directory
+// whose reverse index sends the IDs of the series it indexes on the channel.
+func newImportTestShard(
+	t *testing.T,
+	ctrl *gomock.Controller,
+	dir string,
+	leaseMgr block.LeaseManager,
+	coldWritesEnabled bool,
+) (*dbShard, chan string) {
+	var (
+		opts           = DefaultTestOptions()
+		fsOpts         = opts.CommitLogOptions().FilesystemOptions().SetFilePathPrefix(dir)
+		newClOpts      = opts.CommitLogOptions().SetFilesystemOptions(fsOpts)
+		indexBlockSize = namespace.NewIndexOptions().BlockSize()
+		indexed        = make(chan string, 16)
+	)
+	opts = opts.
+		SetCommitLogOptions(newClOpts).
+		SetBlockLeaseManager(leaseMgr)
+
+	idx := NewMockNamespaceIndex(ctrl)
+	idx.EXPECT().BlockStartForWriteTime(gomock.Any()).
+		DoAndReturn(func(t xtime.UnixNano) xtime.UnixNano {
+			return t.Truncate(indexBlockSize)
+		}).
+		AnyTimes()
+	idx.EXPECT().WriteBatch(gomock.Any()).Do(
+		func(batch *index.WriteBatch) {
+			docs := batch.PendingDocs()
+			for i, e := range batch.PendingEntries() {
+				indexed <- string(docs[i].ID)
+				e.OnIndexSeries.OnIndexSuccess(e.Timestamp.Truncate(indexBlockSize))
+				e.OnIndexSeries.OnIndexFinalize(e.Timestamp.Truncate(indexBlockSize))
+			}
+		}).
+		Return(nil).
+		AnyTimes()
+
+	s := testDatabaseShardWithIndexFn(t, opts, idx, coldWritesEnabled)
+	s.SetRuntimeOptions(runtime.NewOptions().SetWriteNewSeriesAsync(false))
+	// Warm loads check whether blocks are retrievable through the shard.
+	retriever := block.NewMockDatabaseBlockRetriever(ctrl)
+	retriever.EXPECT().CacheShardIndices(gomock.Any()).Return(nil).AnyTimes()
+	s.setBlockRetriever(retriever)
+	return s, indexed
+}
+
+func writeImportTestVolume(
+	t *testing.T,
+	s *dbShard,
+	blockStart xtime.UnixNano,
+	volume int,
+	ids ...string,
+) {
+	writer, err := fs.NewWriter(s.opts.CommitLogOptions().FilesystemOptions())
+	require.NoError(t, err)
+	require.NoError(t, writer.Open(fs.DataWriterOpenOptions{
+		FileSetType: persist.FileSetFlushType,
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:   defaultTestNs1ID,
+			Shard:       s.ID(),
+			BlockStart:  blockStart,
+			VolumeIndex: volume,
+		},
+	}))
+	for _, id := range ids {
+		data := checked.NewBytes([]byte(id), nil)
+		data.IncRef()
+		metadata := persist.NewMetadataFromIDAndTags(ident.StringID(id),
+			ident.NewTags(ident.StringTag("name", id)), persist.MetadataOptions{})
+		require.NoError(t, writer.Write(metadata, data, 0))
+	}
+	require.NoError(t, writer.Close())
+}
+
+func requireImportedSeriesIndexed(t *testing.T, indexed chan string, ids ...string) {
+	actual := make(map[string]struct{}, len(ids))
+	for len(actual) < len(ids) {
+		select {
+		case id := <-indexed:
+			actual[id] = struct{}{}
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out waiting for imported series to be indexed")
+		}
+	}
+	for _, id := range ids {
+		_, ok := actual[id]
+		require.True(t, ok, id)
+	}
+}
+
+func TestShardRegisterImportedVolume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	mockLeaseMgr := block.NewMockLeaseManager(ctrl)
+	mockLeaseMgr.EXPECT().RegisterLeaser(gomock.Any()).Return(nil).AnyTimes()
+	mockLeaseMgr.EXPECT().UnregisterLeaser(gomock.Any()).Return(nil).AnyTimes()
+	s, indexed := newImportTestShard(t, ctrl, dir, mockLeaseMgr, true)
+	defer s.Close()
+
+	var (
+		blockSize = s.namespace.Options().RetentionOptions().BlockSize()
+		start     = xtime.Now().Truncate(blockSize).Add(-2 * blockSize)
+	)
+
+	// Imports require the flush state to have been bootstrapped.
+	require.Error(t, s.RegisterImportedVolume(start, 1, namespace.Context{}))
+
+	writeImportTestVolume(t, s, start, 0)
+	ctx := context.NewBackground()
+	defer ctx.Close()
+	require.NoError(t, s.Bootstrap(ctx, namespace.Context{ID: defaultTestNs1ID}))
+
+	// The volume must follow the latest flushed volume and exist on disk.
+	err = s.RegisterImportedVolume(start, 2, namespace.Context{})
+	require.True(t, xerrors.IsInvalidParams(err))
+	err = s.RegisterImportedVolume(start, 1, namespace.Context{})
+	require.True(t, xerrors.IsInvalidParams(err))
+
+	writeImportTestVolume(t, s, start, 1, "bar", "foo")
+	mockLeaseMgr.EXPECT().UpdateOpenLeases(gomock.Any(), block.LeaseState{Volume: 1}).DoAndReturn(
+		func(descriptor block.LeaseDescriptor, _ block.LeaseState) (block.UpdateLeasesResult, error) {
+			require.True(t, defaultTestNs1ID.Equal(descriptor.Namespace))
+			require.Equal(t, s.ID(), descriptor.Shard)
+			require.Equal(t, start, descriptor.BlockStart)
+			return block.UpdateLeasesResult{}, nil
+		})
+	require.NoError(t, s.RegisterImportedVolume(start, 1, namespace.Context{}))
+
+	flushState, err := s.FlushState(start)
+	require.NoError(t, err)
+	require.Equal(t, 1, flushState.ColdVersionFlushed)
+	require.Equal(t, 1, flushState.ColdVersionRetrievable)
+
+	// The imported series are added to the reverse index.
+	requireImportedSeriesIndexed(t, indexed, "bar", "foo")
+}
+
+func TestShardRegisterImportedVolumeColdWritesDisabled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	mockLeaseMgr := block.NewMockLeaseManager(ctrl)
+	mockLeaseMgr.EXPECT().RegisterLeaser(gomock.Any()).Return(nil).AnyTimes()
+	mockLeaseMgr.EXPECT().UnregisterLeaser(gomock.Any()).Return(nil).AnyTimes()
+	s, _ := newImportTestShard(t, ctrl, dir, mockLeaseMgr, false)
+	defer s.Close()
+
+	var (
+		blockSize = s.namespace.Options().RetentionOptions().BlockSize()
+		start     = xtime.Now().Truncate(blockSize).Add(-2 * blockSize)
+	)
+
+	writeImportTestVolume(t, s, start, 0)
+	ctx := context.NewBackground()
+	defer ctx.Close()
+	require.NoError(t, s.Bootstrap(ctx, namespace.Context{ID: defaultTestNs1ID}))
+
+	// The series of flushed blocks cannot be indexed without cold writes, so
+	// the volume is not registered.
+	writeImportTestVolume(t, s, start, 1, "bar", "foo")
+	err = s.RegisterImportedVolume(start, 1, namespace.Context{})
+	require.True(t, xerrors.IsInvalidParams(err))
+
+	flushState, err := s.FlushState(start)
+	require.NoError(t, err)
+	require.Equal(t, 0, flushState.ColdVersionFlushed)
+}
+
+func TestShardRegisterImportedVolumeUnflushedBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	mockLeaseMgr := block.NewMockLeaseManager(ctrl)
+	mockLeaseMgr.EXPECT().RegisterLeaser(gomock.Any()).Return(nil).AnyTimes()
+	mockLeaseMgr.EXPECT().UnregisterLeaser(gomock.Any()).Return(nil).AnyTimes()
+	s, indexed := newImportTestShard(t, ctrl, dir, mockLeaseMgr, false)
+	defer s.Close()
+
+	ctx := context.NewBackground()
+	defer ctx.Close()
+	require.NoError(t, s.Bootstrap(ctx, namespace.Context{ID: defaultTestNs1ID}))
+
+	var (
+		blockSize = s.namespace.Options().RetentionOptions().BlockSize()
+		start     = xtime.Now().Truncate(blockSize)
+		nsCtx     = namespace.Context{ID: defaultTestNs1ID}
+	)
+
+	// Blocks that have not been flushed take the first volume.
+	err = s.RegisterImportedVolume(start, 1, nsCtx)
+	require.True(t, xerrors.IsInvalidParams(err))
+
+	// Blocks that no longer accept warm writes are pending their warm flush.
+	past := start.Add(-2 * blockSize)
+	writeImportTestVolume(t, s, past, 0, "foo")
+	err = s.RegisterImportedVolume(past, 0, nsCtx)
+	require.True(t, xerrors.IsInvalidParams(err))
+
+	// The series are loaded into memory and indexed, and the volume is
+	// removed for the warm flush to write the first volume.
+	writeImportTestVolume(t, s, start, 0, "bar", "foo")
+	require.NoError(t, s.RegisterImportedVolume(start, 0, nsCtx))
+	requireImportedSeriesIndexed(t, indexed, "bar", "foo")
+
+	for _, id := range []string{"bar", "foo"} {
+		entry, _, err := s.TryRetrieveSeriesAndIncrementReaderWriterCount(ident.StringID(id))
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		require.False(t, entry.Series.IsEmpty())
+		entry.DecrementReaderWriterCount()
+	}
+
+	exists, err := fs.DataFileSetExists(dir, defaultTestNs1ID, s.ID(), start, 0)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	flushState, err := s.FlushState(start)
+	require.NoError(t, err)
+	require.Equal(t, fileOpNotStarted, flushState.WarmStatus.DataFlushed)
+}
+
 // TestShardBootstrapWithCacheShardIndices ensures that the shard is able to bootstrap
 // and call CacheShardIndices if a BlockRetrieverManager is present.
 func TestShardBootstrapWithCacheShardIndices(t *testing.T) {
@@ -29,8 +29,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
@@ -56,6 +58,7 @@ import (
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/mmap"
 	"github.com/m3db/m3/src/x/pool"
+	"github.com/m3db/m3/src/x/sampler"
 	sync0 "github.com/m3db/m3/src/x/sync"
 	time0 "github.com/m3db/m3/src/x/time"
 
@@ -408,6 +411,46 @@ func (mr *MockDatabaseMockRecorder) Repair() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Repair", reflect.TypeOf((*MockDatabase)(nil).Repair))
 }
 
+// RepairQueueState mocks base method.
+func (m *MockDatabase) RepairQueueState(offset, limit int) RepairQueueState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairQueueState", offset, limit)
+	ret0, _ := ret[0].(RepairQueueState)
+	return ret0
+}
+
+// RepairQueueState indicates an expected call of RepairQueueState.
+func (mr *MockDatabaseMockRecorder) RepairQueueState(offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairQueueState", reflect.TypeOf((*MockDatabase)(nil).RepairQueueState), offset, limit)
+}
+
+// ReportReadRepairDivergence mocks base method.
+func (m *MockDatabase) ReportReadRepairDivergence(namespace ident.ID, shard uint32, blockStart time0.UnixNano) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportReadRepairDivergence", namespace, shard, blockStart)
+}
+
+// ReportReadRepairDivergence indicates an expected call of ReportReadRepairDivergence.
+func (mr *MockDatabaseMockRecorder) ReportReadRepairDivergence(namespace, shard, blockStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportReadRepairDivergence", reflect.TypeOf((*MockDatabase)(nil).ReportReadRepairDivergence), namespace, shard, blockStart)
+}
+
+// RegisterImportedVolume mocks base method.
+func (m *MockDatabase) RegisterImportedVolume(namespace ident.ID, shard uint32, blockStart time0.UnixNano, volume int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterImportedVolume", namespace, shard, blockStart, volume)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterImportedVolume indicates an expected call of RegisterImportedVolume.
+func (mr *MockDatabaseMockRecorder) RegisterImportedVolume(namespace, shard, blockStart, volume interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterImportedVolume", reflect.TypeOf((*MockDatabase)(nil).RegisterImportedVolume), namespace, shard, blockStart, volume)
+}
+
 // ShardSet mocks base method.
 func (m *MockDatabase) ShardSet() sharding.ShardSet {
 	m.ctrl.T.Helper()
@@ -451,6 +494,20 @@ func (mr *MockDatabaseMockRecorder) Truncate(namespace interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Truncate", reflect.TypeOf((*MockDatabase)(nil).Truncate), namespace)
 }
 
+// WaitForIndexInserts mocks base method.
+func (m *MockDatabase) WaitForIndexInserts(ctx context.Context, namespace ident.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForIndexInserts", ctx, namespace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForIndexInserts indicates an expected call of WaitForIndexInserts.
+func (mr *MockDatabaseMockRecorder) WaitForIndexInserts(ctx, namespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForIndexInserts", reflect.TypeOf((*MockDatabase)(nil).WaitForIndexInserts), ctx, namespace)
+}
+
 // Write mocks base method.
 func (m *MockDatabase) Write(ctx context.Context, namespace, id ident.ID, timestamp time0.UnixNano, value float64, unit time0.Unit, annotation []byte) error {
 	m.ctrl.T.Helper()
@@ -833,6 +890,46 @@ func (mr *MockdatabaseMockRecorder) Repair() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Repair", reflect.TypeOf((*Mockdatabase)(nil).Repair))
 }
 
+// RepairQueueState mocks base method.
+func (m *Mockdatabase) RepairQueueState(offset, limit int) RepairQueueState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairQueueState", offset, limit)
+	ret0, _ := ret[0].(RepairQueueState)
+	return ret0
+}
+
+// RepairQueueState indicates an expected call of RepairQueueState.
+func (mr *MockdatabaseMockRecorder) RepairQueueState(offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairQueueState", reflect.TypeOf((*Mockdatabase)(nil).RepairQueueState), offset, limit)
+}
+
+// ReportReadRepairDivergence mocks base method.
+func (m *Mockdatabase) ReportReadRepairDivergence(namespace ident.ID, shard uint32, blockStart time0.UnixNano) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportReadRepairDivergence", namespace, shard, blockStart)
+}
+
+// ReportReadRepairDivergence indicates an expected call of ReportReadRepairDivergence.
+func (mr *MockdatabaseMockRecorder) ReportReadRepairDivergence(namespace, shard, blockStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportReadRepairDivergence", reflect.TypeOf((*Mockdatabase)(nil).ReportReadRepairDivergence), namespace, shard, blockStart)
+}
+
+// RegisterImportedVolume mocks base method.
+func (m *Mockdatabase) RegisterImportedVolume(namespace ident.ID, shard uint32, blockStart time0.UnixNano, volume int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterImportedVolume", namespace, shard, blockStart, volume)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterImportedVolume indicates an expected call of RegisterImportedVolume.
+func (mr *MockdatabaseMockRecorder) RegisterImportedVolume(namespace, shard, blockStart, volume interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterImportedVolume", reflect.TypeOf((*Mockdatabase)(nil).RegisterImportedVolume), namespace, shard, blockStart, volume)
+}
+
 // ShardSet mocks base method.
 func (m *Mockdatabase) ShardSet() sharding.ShardSet {
 	m.ctrl.T.Helper()
@@ -890,6 +987,20 @@ func (mr *MockdatabaseMockRecorder) UpdateOwnedNamespaces(namespaces interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOwnedNamespaces", reflect.TypeOf((*Mockdatabase)(nil).UpdateOwnedNamespaces), namespaces)
 }
 
+// WaitForIndexInserts mocks base method.
+func (m *Mockdatabase) WaitForIndexInserts(ctx context.Context, namespace ident.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForIndexInserts", ctx, namespace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForIndexInserts indicates an expected call of WaitForIndexInserts.
+func (mr *MockdatabaseMockRecorder) WaitForIndexInserts(ctx, namespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForIndexInserts", reflect.TypeOf((*Mockdatabase)(nil).WaitForIndexInserts), ctx, namespace)
+}
+
 // Write mocks base method.
 func (m *Mockdatabase) Write(ctx context.Context, namespace, id ident.ID, timestamp time0.UnixNano, value float64, unit time0.Unit, annotation []byte) error {
 	m.ctrl.T.Helper()
@@ -969,6 +1080,20 @@ func (m *MockNamespace) EXPECT() *MockNamespaceMockRecorder {
 	return m.recorder
 }
 
+// CodecStats mocks base method.
+func (m *MockNamespace) CodecStats() series.CodecStatsSnapshot {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CodecStats")
+	ret0, _ := ret[0].(series.CodecStatsSnapshot)
+	return ret0
+}
+
+// CodecStats indicates an expected call of CodecStats.
+func (mr *MockNamespaceMockRecorder) CodecStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CodecStats", reflect.TypeOf((*MockNamespace)(nil).CodecStats))
+}
+
 // DocRef mocks base method.
 func (m *MockNamespace) DocRef(id ident.ID) (doc.Metadata, bool, error) {
 	m.ctrl.T.Helper()
@@ -1261,6 +1386,20 @@ func (mr *MockdatabaseNamespaceMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockdatabaseNamespace)(nil).Close))
 }
 
+// CodecStats mocks base method.
+func (m *MockdatabaseNamespace) CodecStats() series.CodecStatsSnapshot {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CodecStats")
+	ret0, _ := ret[0].(series.CodecStatsSnapshot)
+	return ret0
+}
+
+// CodecStats indicates an expected call of CodecStats.
+func (mr *MockdatabaseNamespaceMockRecorder) CodecStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CodecStats", reflect.TypeOf((*MockdatabaseNamespace)(nil).CodecStats))
+}
+
 // ColdFlush mocks base method.
 func (m *MockdatabaseNamespace) ColdFlush(flush persist.FlushPreparer) error {
 	m.ctrl.T.Helper()
@@ -1351,6 +1490,20 @@ func (mr *MockdatabaseNamespaceMockRecorder) FlushState(shardID, blockStart inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushState", reflect.TypeOf((*MockdatabaseNamespace)(nil).FlushState), shardID, blockStart)
 }
 
+// RegisterImportedVolume mocks base method.
+func (m *MockdatabaseNamespace) RegisterImportedVolume(shardID uint32, blockStart time0.UnixNano, volume int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterImportedVolume", shardID, blockStart, volume)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterImportedVolume indicates an expected call of RegisterImportedVolume.
+func (mr *MockdatabaseNamespaceMockRecorder) RegisterImportedVolume(shardID, blockStart, volume interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterImportedVolume", reflect.TypeOf((*MockdatabaseNamespace)(nil).RegisterImportedVolume), shardID, blockStart, volume)
+}
+
 // ID mocks base method.
 func (m *MockdatabaseNamespace) ID() ident.ID {
 	m.ctrl.T.Helper()
@@ -1739,6 +1892,21 @@ func (mr *MockdatabaseNamespaceMockRecorder) WriteTagged(ctx, id, tagResolver, t
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTagged", reflect.TypeOf((*MockdatabaseNamespace)(nil).WriteTagged), ctx, id, tagResolver, timestamp, value, unit, annotation)
 }
 
+// WriteTaggedWithShardID mocks base method.
+func (m *MockdatabaseNamespace) WriteTaggedWithShardID(ctx context.Context, id ident.ID, shardID uint32, tagResolver convert.TagMetadataResolver, timestamp time0.UnixNano, value float64, unit time0.Unit, annotation []byte) (SeriesWrite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteTaggedWithShardID", ctx, id, shardID, tagResolver, timestamp, value, unit, annotation)
+	ret0, _ := ret[0].(SeriesWrite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteTaggedWithShardID indicates an expected call of WriteTaggedWithShardID.
+func (mr *MockdatabaseNamespaceMockRecorder) WriteTaggedWithShardID(ctx, id, shardID, tagResolver, timestamp, value, unit, annotation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTaggedWithShardID", reflect.TypeOf((*MockdatabaseNamespace)(nil).WriteTaggedWithShardID), ctx, id, shardID, tagResolver, timestamp, value, unit, annotation)
+}
+
 // MockShard is a mock of Shard interface.
 type MockShard struct {
 	ctrl     *gomock.Controller
@@ -1915,6 +2083,21 @@ func (mr *MockdatabaseShardMockRecorder) AggregateTiles(ctx, sourceNs, targetNs,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AggregateTiles", reflect.TypeOf((*MockdatabaseShard)(nil).AggregateTiles), ctx, sourceNs, targetNs, shardID, onFlushSeries, opts)
 }
 
+// BackfillIndex mocks base method.
+func (m *MockdatabaseShard) BackfillIndex(start, end time0.UnixNano) (ShardIndexBackfillResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillIndex", start, end)
+	ret0, _ := ret[0].(ShardIndexBackfillResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillIndex indicates an expected call of BackfillIndex.
+func (mr *MockdatabaseShardMockRecorder) BackfillIndex(start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillIndex", reflect.TypeOf((*MockdatabaseShard)(nil).BackfillIndex), start, end)
+}
+
 // Bootstrap mocks base method.
 func (m *MockdatabaseShard) Bootstrap(ctx context.Context, nsCtx namespace.Context) error {
 	m.ctrl.T.Helper()
@@ -2105,6 +2288,20 @@ func (mr *MockdatabaseShardMockRecorder) FlushState(blockStart interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushState", reflect.TypeOf((*MockdatabaseShard)(nil).FlushState), blockStart)
 }
 
+// RegisterImportedVolume mocks base method.
+func (m *MockdatabaseShard) RegisterImportedVolume(blockStart time0.UnixNano, volume int, nsCtx namespace.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterImportedVolume", blockStart, volume, nsCtx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterImportedVolume indicates an expected call of RegisterImportedVolume.
+func (mr *MockdatabaseShardMockRecorder) RegisterImportedVolume(blockStart, volume, nsCtx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterImportedVolume", reflect.TypeOf((*MockdatabaseShard)(nil).RegisterImportedVolume), blockStart, volume, nsCtx)
+}
+
 // ID mocks base method.
 func (m *MockdatabaseShard) ID() uint32 {
 	m.ctrl.T.Helper()
@@ -2436,6 +2633,20 @@ func (m *MockNamespaceIndex) EXPECT() *MockNamespaceIndexMockRecorder {
 	return m.recorder
 }
 
+// AddFlushedSeries mocks base method.
+func (m *MockNamespaceIndex) AddFlushedSeries(shard uint32, blockStart time0.UnixNano, docs []doc.Metadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFlushedSeries", shard, blockStart, docs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFlushedSeries indicates an expected call of AddFlushedSeries.
+func (mr *MockNamespaceIndexMockRecorder) AddFlushedSeries(shard, blockStart, docs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFlushedSeries", reflect.TypeOf((*MockNamespaceIndex)(nil).AddFlushedSeries), shard, blockStart, docs)
+}
+
 // AggregateQuery mocks base method.
 func (m *MockNamespaceIndex) AggregateQuery(ctx context.Context, query index.Query, opts index.AggregationOptions) (index.AggregateQueryResult, error) {
 	m.ctrl.T.Helper()
@@ -2603,6 +2814,21 @@ func (mr *MockNamespaceIndexMockRecorder) ColdFlush(shards interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ColdFlush", reflect.TypeOf((*MockNamespaceIndex)(nil).ColdFlush), shards)
 }
 
+// Compact mocks base method.
+func (m *MockNamespaceIndex) Compact(blockStart time0.UnixNano, concurrency int) ([]index.BlockCompactResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compact", blockStart, concurrency)
+	ret0, _ := ret[0].([]index.BlockCompactResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Compact indicates an expected call of Compact.
+func (mr *MockNamespaceIndexMockRecorder) Compact(blockStart, concurrency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compact", reflect.TypeOf((*MockNamespaceIndex)(nil).Compact), blockStart, concurrency)
+}
+
 // DebugMemorySegments mocks base method.
 func (m *MockNamespaceIndex) DebugMemorySegments(opts DebugMemorySegmentsOptions) error {
 	m.ctrl.T.Helper()
@@ -2647,6 +2873,20 @@ func (mr *MockNamespaceIndexMockRecorder) Tick(c, startTime interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tick", reflect.TypeOf((*MockNamespaceIndex)(nil).Tick), c, startTime)
 }
 
+// WaitForInserts mocks base method.
+func (m *MockNamespaceIndex) WaitForInserts(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForInserts", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForInserts indicates an expected call of WaitForInserts.
+func (mr *MockNamespaceIndexMockRecorder) WaitForInserts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForInserts", reflect.TypeOf((*MockNamespaceIndex)(nil).WaitForInserts), ctx)
+}
+
 // WarmFlush mocks base method.
 func (m *MockNamespaceIndex) WarmFlush(flush persist.IndexFlush, shards []databaseShard) error {
 	m.ctrl.T.Helper()
@@ -3410,6 +3650,20 @@ func (m *MockdatabaseRepairer) EXPECT() *MockdatabaseRepairerMockRecorder {
 	return m.recorder
 }
 
+// QueueState mocks base method.
+func (m *MockdatabaseRepairer) QueueState(offset, limit int) RepairQueueState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueState", offset, limit)
+	ret0, _ := ret[0].(RepairQueueState)
+	return ret0
+}
+
+// QueueState indicates an expected call of QueueState.
+func (mr *MockdatabaseRepairerMockRecorder) QueueState(offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueState", reflect.TypeOf((*MockdatabaseRepairer)(nil).QueueState), offset, limit)
+}
+
 // Repair mocks base method.
 func (m *MockdatabaseRepairer) Repair() error {
 	m.ctrl.T.Helper()
@@ -3436,6 +3690,18 @@ func (mr *MockdatabaseRepairerMockRecorder) Report() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockdatabaseRepairer)(nil).Report))
 }
 
+// ReportReadDivergence mocks base method.
+func (m *MockdatabaseRepairer) ReportReadDivergence(namespace ident.ID, shard uint32, blockStart time0.UnixNano) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportReadDivergence", namespace, shard, blockStart)
+}
+
+// ReportReadDivergence indicates an expected call of ReportReadDivergence.
+func (mr *MockdatabaseRepairerMockRecorder) ReportReadDivergence(namespace, shard, blockStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportReadDivergence", reflect.TypeOf((*MockdatabaseRepairer)(nil).ReportReadDivergence), namespace, shard, blockStart)
+}
+
 // Start mocks base method.
 func (m *MockdatabaseRepairer) Start() {
 	m.ctrl.T.Helper()
@@ -4009,6 +4275,20 @@ func (mr *MockOptionsMockRecorder) ClockOptions() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClockOptions", reflect.TypeOf((*MockOptions)(nil).ClockOptions))
 }
 
+// CodecStatsSampleRate mocks base method.
+func (m *MockOptions) CodecStatsSampleRate() float64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CodecStatsSampleRate")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+// CodecStatsSampleRate indicates an expected call of CodecStatsSampleRate.
+func (mr *MockOptionsMockRecorder) CodecStatsSampleRate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CodecStatsSampleRate", reflect.TypeOf((*MockOptions)(nil).CodecStatsSampleRate))
+}
+
 // CommitLogOptions mocks base method.
 func (m *MockOptions) CommitLogOptions() commitlog.Options {
 	m.ctrl.T.Helper()
@@ -4149,6 +4429,20 @@ func (mr *MockOptionsMockRecorder) ErrorWindowForLoad() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ErrorWindowForLoad", reflect.TypeOf((*MockOptions)(nil).ErrorWindowForLoad))
 }
 
+// FeatureFlags mocks base method.
+func (m *MockOptions) FeatureFlags() featureflag.Registry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FeatureFlags")
+	ret0, _ := ret[0].(featureflag.Registry)
+	return ret0
+}
+
+// FeatureFlags indicates an expected call of FeatureFlags.
+func (mr *MockOptionsMockRecorder) FeatureFlags() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeatureFlags", reflect.TypeOf((*MockOptions)(nil).FeatureFlags))
+}
+
 // FetchBlockMetadataResultsPool mocks base method.
 func (m *MockOptions) FetchBlockMetadataResultsPool() block.FetchBlockMetadataResultsPool {
 	m.ctrl.T.Helper()
@@ -4191,6 +4485,20 @@ func (mr *MockOptionsMockRecorder) ForceColdWritesEnabled() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceColdWritesEnabled", reflect.TypeOf((*MockOptions)(nil).ForceColdWritesEnabled))
 }
 
+// HealthTracker mocks base method.
+func (m *MockOptions) HealthTracker() health.Tracker {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HealthTracker")
+	ret0, _ := ret[0].(health.Tracker)
+	return ret0
+}
+
+// HealthTracker indicates an expected call of HealthTracker.
+func (mr *MockOptionsMockRecorder) HealthTracker() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthTracker", reflect.TypeOf((*MockOptions)(nil).HealthTracker))
+}
+
 // IdentifierPool mocks base method.
 func (m *MockOptions) IdentifierPool() ident.Pool {
 	m.ctrl.T.Helper()
@@ -4331,6 +4639,20 @@ func (mr *MockOptionsMockRecorder) MultiReaderIteratorPool() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MultiReaderIteratorPool", reflect.TypeOf((*MockOptions)(nil).MultiReaderIteratorPool))
 }
 
+// NamespaceFlushConcurrency mocks base method.
+func (m *MockOptions) NamespaceFlushConcurrency() NamespaceFlushConcurrency {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NamespaceFlushConcurrency")
+	ret0, _ := ret[0].(NamespaceFlushConcurrency)
+	return ret0
+}
+
+// NamespaceFlushConcurrency indicates an expected call of NamespaceFlushConcurrency.
+func (mr *MockOptionsMockRecorder) NamespaceFlushConcurrency() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NamespaceFlushConcurrency", reflect.TypeOf((*MockOptions)(nil).NamespaceFlushConcurrency))
+}
+
 // NamespaceHooks mocks base method.
 func (m *MockOptions) NamespaceHooks() NamespaceHooks {
 	m.ctrl.T.Helper()
@@ -4415,6 +4737,48 @@ func (mr *MockOptionsMockRecorder) PersistManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PersistManager", reflect.TypeOf((*MockOptions)(nil).PersistManager))
 }
 
+// ReadAgeBuckets mocks base method.
+func (m *MockOptions) ReadAgeBuckets() []time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAgeBuckets")
+	ret0, _ := ret[0].([]time.Duration)
+	return ret0
+}
+
+// ReadAgeBuckets indicates an expected call of ReadAgeBuckets.
+func (mr *MockOptionsMockRecorder) ReadAgeBuckets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAgeBuckets", reflect.TypeOf((*MockOptions)(nil).ReadAgeBuckets))
+}
+
+// ReadOnly mocks base method.
+func (m *MockOptions) ReadOnly() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOnly")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ReadOnly indicates an expected call of ReadOnly.
+func (mr *MockOptionsMockRecorder) ReadOnly() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOnly", reflect.TypeOf((*MockOptions)(nil).ReadOnly))
+}
+
+// ReadOnlyTimeRanges mocks base method.
+func (m *MockOptions) ReadOnlyTimeRanges() map[string]time0.Range {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOnlyTimeRanges")
+	ret0, _ := ret[0].(map[string]time0.Range)
+	return ret0
+}
+
+// ReadOnlyTimeRanges indicates an expected call of ReadOnlyTimeRanges.
+func (mr *MockOptionsMockRecorder) ReadOnlyTimeRanges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOnlyTimeRanges", reflect.TypeOf((*MockOptions)(nil).ReadOnlyTimeRanges))
+}
+
 // ReaderIteratorPool mocks base method.
 func (m *MockOptions) ReaderIteratorPool() encoding.ReaderIteratorPool {
 	m.ctrl.T.Helper()
@@ -4485,6 +4849,20 @@ func (mr *MockOptionsMockRecorder) RuntimeOptionsManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RuntimeOptionsManager", reflect.TypeOf((*MockOptions)(nil).RuntimeOptionsManager))
 }
 
+// SampleIntervalPolicies mocks base method.
+func (m *MockOptions) SampleIntervalPolicies() map[string]series.SampleIntervalPolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SampleIntervalPolicies")
+	ret0, _ := ret[0].(map[string]series.SampleIntervalPolicy)
+	return ret0
+}
+
+// SampleIntervalPolicies indicates an expected call of SampleIntervalPolicies.
+func (mr *MockOptionsMockRecorder) SampleIntervalPolicies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SampleIntervalPolicies", reflect.TypeOf((*MockOptions)(nil).SampleIntervalPolicies))
+}
+
 // SchemaRegistry mocks base method.
 func (m *MockOptions) SchemaRegistry() namespace.SchemaRegistry {
 	m.ctrl.T.Helper()
@@ -4667,6 +5045,20 @@ func (mr *MockOptionsMockRecorder) SetClockOptions(value interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClockOptions", reflect.TypeOf((*MockOptions)(nil).SetClockOptions), value)
 }
 
+// SetCodecStatsSampleRate mocks base method.
+func (m *MockOptions) SetCodecStatsSampleRate(value float64) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCodecStatsSampleRate", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetCodecStatsSampleRate indicates an expected call of SetCodecStatsSampleRate.
+func (mr *MockOptionsMockRecorder) SetCodecStatsSampleRate(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCodecStatsSampleRate", reflect.TypeOf((*MockOptions)(nil).SetCodecStatsSampleRate), value)
+}
+
 // SetCommitLogOptions mocks base method.
 func (m *MockOptions) SetCommitLogOptions(value commitlog.Options) Options {
 	m.ctrl.T.Helper()
@@ -4821,6 +5213,20 @@ func (mr *MockOptionsMockRecorder) SetErrorWindowForLoad(value interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetErrorWindowForLoad", reflect.TypeOf((*MockOptions)(nil).SetErrorWindowForLoad), value)
 }
 
+// SetFeatureFlags mocks base method.
+func (m *MockOptions) SetFeatureFlags(value featureflag.Registry) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFeatureFlags", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetFeatureFlags indicates an expected call of SetFeatureFlags.
+func (mr *MockOptionsMockRecorder) SetFeatureFlags(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeatureFlags", reflect.TypeOf((*MockOptions)(nil).SetFeatureFlags), value)
+}
+
 // SetFetchBlockMetadataResultsPool mocks base method.
 func (m *MockOptions) SetFetchBlockMetadataResultsPool(value block.FetchBlockMetadataResultsPool) Options {
 	m.ctrl.T.Helper()
@@ -4863,6 +5269,20 @@ func (mr *MockOptionsMockRecorder) SetForceColdWritesEnabled(value interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetForceColdWritesEnabled", reflect.TypeOf((*MockOptions)(nil).SetForceColdWritesEnabled), value)
 }
 
+// SetHealthTracker mocks base method.
+func (m *MockOptions) SetHealthTracker(value health.Tracker) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHealthTracker", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetHealthTracker indicates an expected call of SetHealthTracker.
+func (mr *MockOptionsMockRecorder) SetHealthTracker(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHealthTracker", reflect.TypeOf((*MockOptions)(nil).SetHealthTracker), value)
+}
+
 // SetIdentifierPool mocks base method.
 func (m *MockOptions) SetIdentifierPool(value ident.Pool) Options {
 	m.ctrl.T.Helper()
@@ -5003,6 +5423,20 @@ func (mr *MockOptionsMockRecorder) SetMultiReaderIteratorPool(value interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMultiReaderIteratorPool", reflect.TypeOf((*MockOptions)(nil).SetMultiReaderIteratorPool), value)
 }
 
+// SetNamespaceFlushConcurrency mocks base method.
+func (m *MockOptions) SetNamespaceFlushConcurrency(value NamespaceFlushConcurrency) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNamespaceFlushConcurrency", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetNamespaceFlushConcurrency indicates an expected call of SetNamespaceFlushConcurrency.
+func (mr *MockOptionsMockRecorder) SetNamespaceFlushConcurrency(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNamespaceFlushConcurrency", reflect.TypeOf((*MockOptions)(nil).SetNamespaceFlushConcurrency), value)
+}
+
 // SetNamespaceHooks mocks base method.
 func (m *MockOptions) SetNamespaceHooks(hooks NamespaceHooks) Options {
 	m.ctrl.T.Helper()
@@ -5087,6 +5521,48 @@ func (mr *MockOptionsMockRecorder) SetPersistManager(value interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPersistManager", reflect.TypeOf((*MockOptions)(nil).SetPersistManager), value)
 }
 
+// SetReadAgeBuckets mocks base method.
+func (m *MockOptions) SetReadAgeBuckets(value []time.Duration) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadAgeBuckets", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetReadAgeBuckets indicates an expected call of SetReadAgeBuckets.
+func (mr *MockOptionsMockRecorder) SetReadAgeBuckets(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadAgeBuckets", reflect.TypeOf((*MockOptions)(nil).SetReadAgeBuckets), value)
+}
+
+// SetReadOnly mocks base method.
+func (m *MockOptions) SetReadOnly(value bool) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadOnly", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetReadOnly indicates an expected call of SetReadOnly.
+func (mr *MockOptionsMockRecorder) SetReadOnly(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadOnly", reflect.TypeOf((*MockOptions)(nil).SetReadOnly), value)
+}
+
+// SetReadOnlyTimeRanges mocks base method.
+func (m *MockOptions) SetReadOnlyTimeRanges(value map[string]time0.Range) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadOnlyTimeRanges", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetReadOnlyTimeRanges indicates an expected call of SetReadOnlyTimeRanges.
+func (mr *MockOptionsMockRecorder) SetReadOnlyTimeRanges(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadOnlyTimeRanges", reflect.TypeOf((*MockOptions)(nil).SetReadOnlyTimeRanges), value)
+}
+
 // SetReaderIteratorPool mocks base method.
 func (m *MockOptions) SetReaderIteratorPool(value encoding.ReaderIteratorPool) Options {
 	m.ctrl.T.Helper()
@@ -5157,6 +5633,20 @@ func (mr *MockOptionsMockRecorder) SetRuntimeOptionsManager(value interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRuntimeOptionsManager", reflect.TypeOf((*MockOptions)(nil).SetRuntimeOptionsManager), value)
 }
 
+// SetSampleIntervalPolicies mocks base method.
+func (m *MockOptions) SetSampleIntervalPolicies(value map[string]series.SampleIntervalPolicy) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSampleIntervalPolicies", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetSampleIntervalPolicies indicates an expected call of SetSampleIntervalPolicies.
+func (mr *MockOptionsMockRecorder) SetSampleIntervalPolicies(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSampleIntervalPolicies", reflect.TypeOf((*MockOptions)(nil).SetSampleIntervalPolicies), value)
+}
+
 // SetSchemaRegistry mocks base method.
 func (m *MockOptions) SetSchemaRegistry(registry namespace.SchemaRegistry) Options {
 	m.ctrl.T.Helper()
@@ -5213,6 +5703,20 @@ func (mr *MockOptionsMockRecorder) SetSeriesOptions(value interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSeriesOptions", reflect.TypeOf((*MockOptions)(nil).SetSeriesOptions), value)
 }
 
+// SetShardIDVerifySampleRate mocks base method.
+func (m *MockOptions) SetShardIDVerifySampleRate(value sampler.Rate) Options {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShardIDVerifySampleRate", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+// SetShardIDVerifySampleRate indicates an expected call of SetShardIDVerifySampleRate.
+func (mr *MockOptionsMockRecorder) SetShardIDVerifySampleRate(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShardIDVerifySampleRate", reflect.TypeOf((*MockOptions)(nil).SetShardIDVerifySampleRate), value)
+}
+
 // SetSourceLoggerBuilder mocks base method.
 func (m *MockOptions) SetSourceLoggerBuilder(value limits.SourceLoggerBuilder) Options {
 	m.ctrl.T.Helper()
@@ -5283,6 +5787,20 @@ func (mr *MockOptionsMockRecorder) SetWriteTransformOptions(value interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWriteTransformOptions", reflect.TypeOf((*MockOptions)(nil).SetWriteTransformOptions), value)
 }
 
+// ShardIDVerifySampleRate mocks base method.
+func (m *MockOptions) ShardIDVerifySampleRate() sampler.Rate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShardIDVerifySampleRate")
+	ret0, _ := ret[0].(sampler.Rate)
+	return ret0
+}
+
+// ShardIDVerifySampleRate indicates an expected call of ShardIDVerifySampleRate.
+func (mr *MockOptionsMockRecorder) ShardIDVerifySampleRate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShardIDVerifySampleRate", reflect.TypeOf((*MockOptions)(nil).ShardIDVerifySampleRate))
+}
+
 // SourceLoggerBuilder mocks base method.
 func (m *MockOptions) SourceLoggerBuilder() limits.SourceLoggerBuilder {
 	m.ctrl.T.Helper()
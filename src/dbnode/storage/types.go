@@ -26,9 +26,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/kv/util/featureflag"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/generated/proto/annotation"
+	"github.com/m3db/m3/src/dbnode/health"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
@@ -55,6 +57,7 @@ import (
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/mmap"
 	"github.com/m3db/m3/src/x/pool"
+	"github.com/m3db/m3/src/x/sampler"
 	xsync "github.com/m3db/m3/src/x/sync"
 	xtime "github.com/m3db/m3/src/x/time"
 )
@@ -122,6 +125,10 @@ type Database interface {
 		annotation []byte,
 	) error
 
+	// WaitForIndexInserts blocks until every series enqueued for indexing in
+	// the namespace before the call is queryable, or the context is done.
+	WaitForIndexInserts(ctx context.Context, namespace ident.ID) error
+
 	// BatchWriter returns a batch writer for the provided namespace that can
 	// be used to issue a batch of writes to either WriteBatch
 	// or WriteTaggedBatch.
@@ -215,6 +222,23 @@ type Database interface {
 	// Repair will issue a repair and return nil on success or error on error.
 	Repair() error
 
+	// RepairQueueState returns a page of the repair queue, ordered by repair
+	// priority, and the most recent shard repair results.
+	RepairQueueState(offset, limit int) RepairQueueState
+
+	// ReportReadRepairDivergence reports that a read detected replicas diverged
+	// for the namespace, shard and block start so it is prioritized for repair.
+	ReportReadRepairDivergence(namespace ident.ID, shard uint32, blockStart xtime.UnixNano)
+
+	// RegisterImportedVolume makes a fileset volume that was built offline by a
+	// bulk import, and placed alongside the existing filesets, available for reads.
+	RegisterImportedVolume(
+		namespace ident.ID,
+		shard uint32,
+		blockStart xtime.UnixNano,
+		volume int,
+	) error
+
 	// Truncate truncates data for the given namespace.
 	Truncate(namespace ident.ID) (int64, error)
 
@@ -257,6 +281,10 @@ type Namespace interface {
 	// NumSeries returns the number of series in the namespace.
 	NumSeries() int64
 
+	// CodecStats returns the codec stats gathered by benchmarking a sample
+	// of the blocks flushed by the namespace.
+	CodecStats() series.CodecStatsSnapshot
+
 	// Shards returns the shard description.
 	Shards() []Shard
 
@@ -335,6 +363,19 @@ type databaseNamespace interface {
 		annotation []byte,
 	) (SeriesWrite, error)
 
+	// WriteTaggedWithShardID values to the namespace for an ID whose shard has
+	// been precomputed by the caller, the shard is validated against the ID.
+	WriteTaggedWithShardID(
+		ctx context.Context,
+		id ident.ID,
+		shardID uint32,
+		tagResolver convert.TagMetadataResolver,
+		timestamp xtime.UnixNano,
+		value float64,
+		unit xtime.Unit,
+		annotation []byte,
+	) (SeriesWrite, error)
+
 	// QueryIDs resolves the given query into known IDs.
 	QueryIDs(
 		ctx context.Context,
@@ -415,6 +456,10 @@ type databaseNamespace interface {
 	// FlushState returns the flush state for the specified shard and block start.
 	FlushState(shardID uint32, blockStart xtime.UnixNano) (fileOpState, error)
 
+	// RegisterImportedVolume makes an imported fileset volume for the specified
+	// shard and block start available for reads.
+	RegisterImportedVolume(shardID uint32, blockStart xtime.UnixNano, volume int) error
+
 	// SeriesRefResolver returns a series ref resolver, callers
 	// must make sure to call the release callback once finished
 	// with the reference.
@@ -438,6 +483,9 @@ type databaseNamespace interface {
 // NamespaceRepairOptions is a set of repair options for repairing a namespace.
 type NamespaceRepairOptions struct {
 	Force bool
+	// Shards restricts the repair to the given owned shards, when empty all
+	// owned shards are repaired.
+	Shards []uint32
 }
 
 // Shard is a time series database shard.
@@ -576,6 +624,12 @@ type databaseShard interface {
 		onFlush persist.OnFlushSeries,
 	) (ShardColdFlush, error)
 
+	// BackfillIndex queues series with buffered data for blocks in the
+	// range [start, end) that are missing from the reverse index and indexes
+	// the series of the flushed blocks in the range, returning the number of
+	// series queued or indexed and of those that could not be backfilled.
+	BackfillIndex(start, end xtime.UnixNano) (ShardIndexBackfillResult, error)
+
 	// FilterBlocksNeedSnapshot computes which blocks require snapshots.
 	FilterBlocksNeedSnapshot(blockStarts []xtime.UnixNano) []xtime.UnixNano
 
@@ -590,6 +644,12 @@ type databaseShard interface {
 	// FlushState returns the flush state for this shard at block start.
 	FlushState(blockStart xtime.UnixNano) (fileOpState, error)
 
+	// RegisterImportedVolume makes an imported fileset volume for the block
+	// start available for reads and adds its series to the reverse index. The
+	// volume must directly follow the latest flushed volume of a warm flushed
+	// block, or be the first volume of a block that has not been flushed.
+	RegisterImportedVolume(blockStart xtime.UnixNano, volume int, nsCtx namespace.Context) error
+
 	// CleanupExpiredFileSets removes expired fileset files.
 	CleanupExpiredFileSets(earliestToRetain xtime.UnixNano) error
 
@@ -637,6 +697,17 @@ type ShardSnapshotResult struct {
 	SeriesPersist int
 }
 
+// ShardIndexBackfillResult is a result from a shard index backfill.
+type ShardIndexBackfillResult struct {
+	// NumSeriesQueued is the number of series queued or indexed.
+	NumSeriesQueued int
+	// NumSeriesSkipped is the number of series that could not be backfilled.
+	NumSeriesSkipped int
+	// NumBlocksSkipped is the number of flushed blocks that could not be
+	// read, their series are not counted as skipped.
+	NumBlocksSkipped int
+}
+
 // ShardColdFlush exposes a done method to finalize shard cold flush
 // by persisting data and updating shard state/block leases.
 type ShardColdFlush interface {
@@ -669,6 +740,19 @@ type NamespaceIndex interface {
 		pending []writes.PendingIndexInsert,
 	) error
 
+	// AddFlushedSeries indexes the series read from a flushed data block of
+	// a shard as a segment of the index block the data block belongs to since
+	// series that far in the past are rejected when indexed as writes.
+	AddFlushedSeries(
+		shard uint32,
+		blockStart xtime.UnixNano,
+		docs []doc.Metadata,
+	) error
+
+	// WaitForInserts blocks until every insert enqueued before the call has
+	// been indexed and is queryable, or the context is done.
+	WaitForInserts(ctx context.Context) error
+
 	// Query resolves the given query into known IDs.
 	Query(
 		ctx context.Context,
@@ -726,6 +810,11 @@ type NamespaceIndex interface {
 	// BackgroundCompact background compacts eligible segments.
 	BackgroundCompact()
 
+	// Compact synchronously compacts the mutable segments of all blocks,
+	// or only of the block with the given block start if non-zero, with at
+	// most the given number of blocks compacted concurrently.
+	Compact(blockStart xtime.UnixNano, concurrency int) ([]index.BlockCompactResult, error)
+
 	// Close will release the index resources and close the index.
 	Close() error
 }
@@ -935,6 +1024,13 @@ type databaseRepairer interface {
 
 	// Repair repairs in-memory data.
 	Repair() error
+
+	// QueueState returns a page of the repair queue.
+	QueueState(offset, limit int) RepairQueueState
+
+	// ReportReadDivergence reports that a read detected replicas diverged for
+	// the namespace, shard and block start.
+	ReportReadDivergence(namespace ident.ID, shard uint32, blockStart xtime.UnixNano)
 }
 
 // databaseTickManager performs periodic ticking.
@@ -1010,6 +1106,28 @@ type OnColdFlushNamespace interface {
 // OptionTransform transforms given Options.
 type OptionTransform func(Options) Options
 
+// NamespaceFlushConcurrency is the number of shards of a namespace that are
+// warm flushed and snapshotted concurrently. Concurrent flushes share the
+// persist rate limit so the rate data is written to disk is unchanged.
+type NamespaceFlushConcurrency struct {
+	// Default is the concurrency of namespaces without an override.
+	Default int
+	// Namespaces overrides the concurrency by namespace ID.
+	Namespaces map[string]int
+}
+
+// ForNamespace returns the concurrency of a namespace, at least one.
+func (c NamespaceFlushConcurrency) ForNamespace(id ident.ID) int {
+	concurrency := c.Default
+	if v, ok := c.Namespaces[id.String()]; ok {
+		concurrency = v
+	}
+	if concurrency < 1 {
+		return 1
+	}
+	return concurrency
+}
+
 type TickOptions struct {
 	TopMetricsToTrack       int
 	MinCardinalityToTrack   int
@@ -1093,6 +1211,14 @@ type Options interface {
 	// to the database.
 	WriteTransformOptions() series.WriteTransformOptions
 
+	// SetShardIDVerifySampleRate sets the rate at which the shard IDs precomputed
+	// by clients on tagged writes are verified against the hash of the series ID.
+	SetShardIDVerifySampleRate(value sampler.Rate) Options
+
+	// ShardIDVerifySampleRate returns the rate at which the shard IDs precomputed
+	// by clients on tagged writes are verified against the hash of the series ID.
+	ShardIDVerifySampleRate() sampler.Rate
+
 	// SetRepairEnabled sets whether or not to enable the repair.
 	SetRepairEnabled(b bool) Options
 
@@ -1105,6 +1231,12 @@ type Options interface {
 	// RepairOptions returns the repair options.
 	RepairOptions() repair.Options
 
+	// SetFeatureFlags sets the feature flag registry runtime toggles are read from.
+	SetFeatureFlags(value featureflag.Registry) Options
+
+	// FeatureFlags returns the feature flag registry runtime toggles are read from.
+	FeatureFlags() featureflag.Registry
+
 	// SetBootstrapProcessProvider sets the bootstrap process provider for the database.
 	SetBootstrapProcessProvider(value bootstrap.ProcessProvider) Options
 
@@ -1152,6 +1284,46 @@ type Options interface {
 	// SeriesCachePolicy returns the series cache policy.
 	SeriesCachePolicy() series.CachePolicy
 
+	// SetSampleIntervalPolicies sets the minimum interval policies enforced
+	// on the datapoints written to series keyed by namespace ID.
+	SetSampleIntervalPolicies(value map[string]series.SampleIntervalPolicy) Options
+
+	// SampleIntervalPolicies returns the minimum interval policies enforced
+	// on the datapoints written to series keyed by namespace ID.
+	SampleIntervalPolicies() map[string]series.SampleIntervalPolicy
+
+	// SetCodecStatsSampleRate sets the fraction of flushed blocks that are
+	// benchmarked to gather per namespace codec stats, zero disables it.
+	SetCodecStatsSampleRate(value float64) Options
+
+	// CodecStatsSampleRate returns the fraction of flushed blocks that are
+	// benchmarked to gather per namespace codec stats, zero disables it.
+	CodecStatsSampleRate() float64
+
+	// SetReadAgeBuckets sets the upper bounds of the age buckets the reads of
+	// each namespace are tracked by, empty disables read age tracking.
+	SetReadAgeBuckets(value []time.Duration) Options
+
+	// ReadAgeBuckets returns the upper bounds of the age buckets the reads of
+	// each namespace are tracked by, empty disables read age tracking.
+	ReadAgeBuckets() []time.Duration
+
+	// SetReadOnly sets whether the database only serves reads from the
+	// filesets on disk, with no commit log and rejecting all writes.
+	SetReadOnly(value bool) Options
+
+	// ReadOnly returns whether the database only serves reads from the
+	// filesets on disk, with no commit log and rejecting all writes.
+	ReadOnly() bool
+
+	// SetReadOnlyTimeRanges sets the time ranges served by a read-only
+	// database keyed by namespace ID, reads are clamped to the range.
+	SetReadOnlyTimeRanges(value map[string]xtime.Range) Options
+
+	// ReadOnlyTimeRanges returns the time ranges served by a read-only
+	// database keyed by namespace ID, reads are clamped to the range.
+	ReadOnlyTimeRanges() map[string]xtime.Range
+
 	// SetSeriesOptions sets the series options.
 	SetSeriesOptions(value series.Options) Options
 
@@ -1266,6 +1438,14 @@ type Options interface {
 	// IterationOptions returns iteration options.
 	IterationOptions() index.IterationOptions
 
+	// SetNamespaceFlushConcurrency sets the number of shards of each
+	// namespace that are warm flushed and snapshotted concurrently.
+	SetNamespaceFlushConcurrency(value NamespaceFlushConcurrency) Options
+
+	// NamespaceFlushConcurrency returns the number of shards of each
+	// namespace that are warm flushed and snapshotted concurrently.
+	NamespaceFlushConcurrency() NamespaceFlushConcurrency
+
 	// SetForceColdWritesEnabled sets options for forcing cold writes.
 	SetForceColdWritesEnabled(value bool) Options
 
@@ -1346,6 +1526,14 @@ type Options interface {
 	// SetLimitsOptions sets the limits options.
 	SetLimitsOptions(value limits.Options) Options
 
+	// SetHealthTracker sets the tracker the flush lag of each namespace is
+	// reported to.
+	SetHealthTracker(value health.Tracker) Options
+
+	// HealthTracker returns the tracker the flush lag of each namespace is
+	// reported to.
+	HealthTracker() health.Tracker
+
 	// CoreFn gets the function for determining the current core.
 	CoreFn() xsync.CoreFn
 
@@ -1394,6 +1582,17 @@ type NamespaceBootstrapStates map[string]ShardBootstrapStates
 // namespace.
 type ShardBootstrapStates map[uint32]BootstrapState
 
+const (
+	// RepairEnabledFeatureFlag is the feature flag that pauses background
+	// repairs when set to false, it has no effect if repair is not enabled.
+	RepairEnabledFeatureFlag = "repair-enabled"
+
+	// ColdWritesEnabledFeatureFlag is the feature flag that rejects cold
+	// writes to all namespaces when set to false, even those that enable cold
+	// writes. Cold writes already accepted are still flushed.
+	ColdWritesEnabledFeatureFlag = "cold-writes-enabled"
+)
+
 // BootstrapState is an enum representing the possible bootstrap states for a shard.
 type BootstrapState int
 
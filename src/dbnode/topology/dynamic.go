@@ -254,7 +254,8 @@ func getStaticOptions(service services.Service, hashGen sharding.HashGen) (Stati
 	return NewStaticOptions().
 		SetReplicas(replicas).
 		SetShardSet(allShardSet).
-		SetHostShardSets(hostShardSets), nil
+		SetHostShardSets(hostShardSets).
+		SetVersion(service.Version()), nil
 }
 
 func validateInstances(
@@ -226,6 +226,7 @@ func getMockService(ctrl *gomock.Controller) services.Service {
 	mockService.EXPECT().Sharding().Return(mockSharding).AnyTimes()
 
 	mockService.EXPECT().Instances().Return(goodInstances()).AnyTimes()
+	mockService.EXPECT().Version().Return(1).AnyTimes()
 
 	return mockService
 }
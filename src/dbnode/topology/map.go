@@ -36,6 +36,7 @@ type staticMap struct {
 	orderedShardHostsByShard [][]orderedShardHost
 	replicas                 int
 	majority                 int
+	version                  int
 }
 
 // NewStaticMap creates a new static topology map
@@ -51,6 +52,7 @@ func NewStaticMap(opts StaticOptions) Map {
 		orderedShardHostsByShard: make([][]orderedShardHost, totalShards),
 		replicas:                 opts.Replicas(),
 		majority:                 Majority(opts.Replicas()),
+		version:                  opts.Version(),
 	}
 
 	for idx, hostShardSet := range hostShardSets {
@@ -138,6 +140,10 @@ func (t *staticMap) MajorityReplicas() int {
 	return t.majority
 }
 
+func (t *staticMap) Version() int {
+	return t.version
+}
+
 type mapWatch struct {
 	xwatch.Watch
 }
@@ -101,6 +101,20 @@ func (mr *MockHostMockRecorder) String() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "String", reflect.TypeOf((*MockHost)(nil).String))
 }
 
+// Zone mocks base method.
+func (m *MockHost) Zone() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Zone")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Zone indicates an expected call of Zone.
+func (mr *MockHostMockRecorder) Zone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Zone", reflect.TypeOf((*MockHost)(nil).Zone))
+}
+
 // MockHostShardSet is a mock of HostShardSet interface.
 type MockHostShardSet struct {
 	ctrl     *gomock.Controller
@@ -596,6 +610,20 @@ func (mr *MockMapMockRecorder) ShardSet() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShardSet", reflect.TypeOf((*MockMap)(nil).ShardSet))
 }
 
+// Version mocks base method.
+func (m *MockMap) Version() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockMapMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockMap)(nil).Version))
+}
+
 // MockStaticOptions is a mock of StaticOptions interface.
 type MockStaticOptions struct {
 	ctrl     *gomock.Controller
@@ -689,6 +717,20 @@ func (mr *MockStaticOptionsMockRecorder) SetShardSet(value interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShardSet", reflect.TypeOf((*MockStaticOptions)(nil).SetShardSet), value)
 }
 
+// SetVersion mocks base method.
+func (m *MockStaticOptions) SetVersion(value int) StaticOptions {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVersion", value)
+	ret0, _ := ret[0].(StaticOptions)
+	return ret0
+}
+
+// SetVersion indicates an expected call of SetVersion.
+func (mr *MockStaticOptionsMockRecorder) SetVersion(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVersion", reflect.TypeOf((*MockStaticOptions)(nil).SetVersion), value)
+}
+
 // ShardSet mocks base method.
 func (m *MockStaticOptions) ShardSet() sharding.ShardSet {
 	m.ctrl.T.Helper()
@@ -717,6 +759,20 @@ func (mr *MockStaticOptionsMockRecorder) Validate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockStaticOptions)(nil).Validate))
 }
 
+// Version mocks base method.
+func (m *MockStaticOptions) Version() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockStaticOptionsMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockStaticOptions)(nil).Version))
+}
+
 // MockDynamicOptions is a mock of DynamicOptions interface.
 type MockDynamicOptions struct {
 	ctrl     *gomock.Controller
@@ -37,6 +37,9 @@ type Host interface {
 	// Address returns the address of the host
 	Address() string
 
+	// Zone returns the zone the host resides in, empty if unknown
+	Zone() string
+
 	// String returns a string representation of the host
 	String() string
 }
@@ -131,6 +134,10 @@ type Map interface {
 
 	// MajorityReplicas returns the number of replicas to establish majority in the topology
 	MajorityReplicas() int
+
+	// Version returns the version of the placement the topology was built
+	// from, zero if not built from a placement
+	Version() int
 }
 
 // RouteForEachFn is a function to execute for each routed to host
@@ -171,6 +178,12 @@ type StaticOptions interface {
 
 	// HostShardSets returns the hostShardSets
 	HostShardSets() []HostShardSet
+
+	// SetVersion sets the version of the placement the topology is built from
+	SetVersion(value int) StaticOptions
+
+	// Version returns the version of the placement the topology is built from
+	Version() int
 }
 
 // DynamicOptions is a set of options for dynamic topology
@@ -70,6 +70,11 @@ type BatchWrite struct {
 	// Used to help the caller tie errors back to an index in their
 	// own collection.
 	OriginalIndex int
+	// ShardID is the shard of the series ID, precomputed by the caller
+	// if ShardIDSet is true.
+	ShardID uint32
+	// ShardIDSet returns whether the ShardID was precomputed by the caller.
+	ShardIDSet bool
 	// Used by the commitlog.
 	Err error
 }
@@ -116,6 +121,19 @@ type BatchWriter interface {
 		annotation []byte,
 	) error
 
+	// AddTaggedWithShardID adds a tagged write whose shard was precomputed
+	// by the caller so that the ID does not need to be hashed again.
+	AddTaggedWithShardID(
+		originalIndex int,
+		id ident.ID,
+		encodedTags ts.EncodedTags,
+		timestamp xtime.UnixNano,
+		value float64,
+		unit xtime.Unit,
+		annotation []byte,
+		shardID uint32,
+	) error
+
 	SetFinalizeEncodedTagsFn(f FinalizeEncodedTagsFn)
 
 	SetFinalizeAnnotationFn(f FinalizeAnnotationFn)
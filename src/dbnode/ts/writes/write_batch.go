@@ -122,6 +122,27 @@ func (b *writeBatch) AddTagged(
 	return nil
 }
 
+func (b *writeBatch) AddTaggedWithShardID(
+	originalIndex int,
+	id ident.ID,
+	encodedTags ts.EncodedTags,
+	timestamp xtime.UnixNano,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+	shardID uint32,
+) error {
+	write, err := newBatchWriterWrite(
+		originalIndex, b.ns, id, encodedTags, timestamp, value, unit, annotation)
+	if err != nil {
+		return err
+	}
+	write.ShardID = shardID
+	write.ShardIDSet = true
+	b.writes = append(b.writes, write)
+	return nil
+}
+
 func (b *writeBatch) Reset(
 	batchSize int,
 	ns ident.ID,
@@ -85,6 +85,20 @@ func (mr *MockWriteBatchMockRecorder) AddTagged(originalIndex, id, encodedTags,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTagged", reflect.TypeOf((*MockWriteBatch)(nil).AddTagged), originalIndex, id, encodedTags, timestamp, value, unit, annotation)
 }
 
+// AddTaggedWithShardID mocks base method.
+func (m *MockWriteBatch) AddTaggedWithShardID(originalIndex int, id ident.ID, encodedTags ts.EncodedTags, timestamp time.UnixNano, value float64, unit time.Unit, annotation []byte, shardID uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTaggedWithShardID", originalIndex, id, encodedTags, timestamp, value, unit, annotation, shardID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTaggedWithShardID indicates an expected call of AddTaggedWithShardID.
+func (mr *MockWriteBatchMockRecorder) AddTaggedWithShardID(originalIndex, id, encodedTags, timestamp, value, unit, annotation, shardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTaggedWithShardID", reflect.TypeOf((*MockWriteBatch)(nil).AddTaggedWithShardID), originalIndex, id, encodedTags, timestamp, value, unit, annotation, shardID)
+}
+
 // Finalize mocks base method.
 func (m *MockWriteBatch) Finalize() {
 	m.ctrl.T.Helper()
@@ -274,6 +288,20 @@ func (mr *MockBatchWriterMockRecorder) AddTagged(originalIndex, id, encodedTags,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTagged", reflect.TypeOf((*MockBatchWriter)(nil).AddTagged), originalIndex, id, encodedTags, timestamp, value, unit, annotation)
 }
 
+// AddTaggedWithShardID mocks base method.
+func (m *MockBatchWriter) AddTaggedWithShardID(originalIndex int, id ident.ID, encodedTags ts.EncodedTags, timestamp time.UnixNano, value float64, unit time.Unit, annotation []byte, shardID uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTaggedWithShardID", originalIndex, id, encodedTags, timestamp, value, unit, annotation, shardID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTaggedWithShardID indicates an expected call of AddTaggedWithShardID.
+func (mr *MockBatchWriterMockRecorder) AddTaggedWithShardID(originalIndex, id, encodedTags, timestamp, value, unit, annotation, shardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTaggedWithShardID", reflect.TypeOf((*MockBatchWriter)(nil).AddTaggedWithShardID), originalIndex, id, encodedTags, timestamp, value, unit, annotation, shardID)
+}
+
 // SetFinalizeAnnotationFn mocks base method.
 func (m *MockBatchWriter) SetFinalizeAnnotationFn(f FinalizeAnnotationFn) {
 	m.ctrl.T.Helper()
@@ -151,6 +151,40 @@ func TestBatchWriterAddTaggedAndIter(t *testing.T) {
 	assertDataPresent(t, writes, writeBatch)
 }
 
+func TestBatchWriterAddTaggedWithShardID(t *testing.T) {
+	writeBatch := NewWriteBatch(batchSize, namespace, nil)
+
+	for i, write := range writes {
+		if i%2 == 0 {
+			require.NoError(t, writeBatch.AddTaggedWithShardID(
+				i,
+				write.id,
+				write.encodedTags(t).Bytes(),
+				write.timestamp,
+				write.value,
+				write.unit,
+				write.annotation,
+				uint32(i)))
+			continue
+		}
+		require.NoError(t, writeBatch.AddTagged(
+			i,
+			write.id,
+			write.encodedTags(t).Bytes(),
+			write.timestamp,
+			write.value,
+			write.unit,
+			write.annotation))
+	}
+
+	for i, write := range writeBatch.Iter() {
+		require.Equal(t, i%2 == 0, write.ShardIDSet)
+		if write.ShardIDSet {
+			require.Equal(t, uint32(i), write.ShardID)
+		}
+	}
+}
+
 func TestBatchWriterSetSeries(t *testing.T) {
 	writeBatch := NewWriteBatch(batchSize, namespace, nil)
 
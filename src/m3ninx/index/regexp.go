@@ -26,6 +26,8 @@ import (
 	re "regexp"
 	"regexp/syntax"
 	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	fstregexp "github.com/m3db/m3/src/m3ninx/index/segment/fst/regexp"
 	"github.com/m3db/m3/src/x/cache"
@@ -164,8 +166,9 @@ func CompileRegex(r []byte) (CompiledRegex, error) {
 		return CompiledRegex{}, err
 	}
 	compiledRegex := CompiledRegex{
-		Simple:    simpleRE,
-		FSTSyntax: vellumRe,
+		Simple:         simpleRE,
+		FSTSyntax:      vellumRe,
+		FoldedLiterals: caseFoldedLiterals(vellumRe),
 	}
 
 	fstRE, start, end, err := fstregexp.ParsedRegexp(vellumRe.String(), vellumRe)
@@ -191,6 +194,69 @@ func parseRegexp(re string) (*syntax.Regexp, error) {
 	return syntax.Parse(re, syntax.Perl)
 }
 
+// CaseFold returns the case folded form of a term, two terms match each other
+// case-insensitively if and only if their case folded forms are equal.
+func CaseFold(term []byte) []byte {
+	folded := make([]byte, 0, len(term))
+	for len(term) > 0 {
+		r, size := utf8.DecodeRune(term)
+		term = term[size:]
+		folded = utf8.AppendRune(folded, minFoldRune(r))
+	}
+	return folded
+}
+
+// minFoldRune returns the smallest rune which is equivalent to r under
+// simple case folding, this matches the folding used by case-insensitive
+// regular expression literals.
+func minFoldRune(r rune) rune {
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
+
+// caseFoldedLiterals returns the case folded form of each literal if the
+// parsed regexp only matches a set of literals case-insensitively, such as
+// "(?i)foo" or "(?i)(foo|bar)", otherwise it returns nil.
+func caseFoldedLiterals(ast *syntax.Regexp) [][]byte {
+	var literals [][]byte
+	if !appendCaseFoldedLiterals(ast, &literals) {
+		return nil
+	}
+	return literals
+}
+
+func appendCaseFoldedLiterals(ast *syntax.Regexp, literals *[][]byte) bool {
+	if ast == nil {
+		return false
+	}
+	switch ast.Op {
+	case syntax.OpLiteral:
+		if ast.Flags&syntax.FoldCase == 0 {
+			return false
+		}
+		*literals = append(*literals, CaseFold([]byte(string(ast.Rune))))
+		return true
+	case syntax.OpCapture:
+		if len(ast.Sub) != 1 {
+			return false
+		}
+		return appendCaseFoldedLiterals(ast.Sub[0], literals)
+	case syntax.OpAlternate:
+		for _, sub := range ast.Sub {
+			if !appendCaseFoldedLiterals(sub, literals) {
+				return false
+			}
+		}
+		return len(ast.Sub) > 0
+	}
+	return false
+}
+
 // ensureRegexpAnchored adds '^' and '$' characters to appropriate locations in the parsed syntax.Regexp,
 // to ensure every input regular expression is converted to it's equivalent anchored regular expression.
 // NB: assumes input regexp AST is un-anchored.
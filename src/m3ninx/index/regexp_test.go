@@ -431,3 +431,51 @@ func TestRegexpCache(t *testing.T) {
 
 	tallytest.AssertCounterValue(t, 1, scope.Snapshot(), "m3ninx.regexp.cache.hit", nil)
 }
+
+func TestCompileRegexFoldedLiterals(t *testing.T) {
+	tests := []struct {
+		re       string
+		expected []string
+	}{
+		{re: "(?i)foo", expected: []string{"FOO"}},
+		{re: "(?i)^foo$", expected: []string{"FOO"}},
+		{re: "(?i)(foo|bar)", expected: []string{"FOO", "BAR"}},
+		{re: "(?i:foo)|(?i:bar)", expected: []string{"FOO", "BAR"}},
+		{re: "foo"},
+		{re: "(?i)foo.*"},
+		{re: "(?i:foo)|bar"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.re, func(t *testing.T) {
+			compiled, err := CompileRegex([]byte(test.re))
+			require.NoError(t, err)
+
+			var actual []string
+			for _, literal := range compiled.FoldedLiterals {
+				actual = append(actual, string(literal))
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestCaseFold(t *testing.T) {
+	assert.Equal(t, []byte("FOO"), CaseFold([]byte("foo")))
+	assert.Equal(t, CaseFold([]byte("FoO")), CaseFold([]byte("fOo")))
+	// Kelvin sign and long s fold to their ASCII equivalents.
+	assert.Equal(t, CaseFold([]byte("k")), CaseFold([]byte("K")))
+	assert.Equal(t, CaseFold([]byte("s")), CaseFold([]byte("ſ")))
+	assert.NotEqual(t, CaseFold([]byte("foo")), CaseFold([]byte("fob")))
+
+	for _, term := range []string{"foo", "FOO", "Foo", "Kelvin"} {
+		compiled, err := CompileRegex([]byte("(?i)" + term))
+		require.NoError(t, err)
+		require.Len(t, compiled.FoldedLiterals, 1)
+		for _, other := range []string{"foo", "kelvin", "KELVIN", "fob"} {
+			assert.Equal(t, compiled.Simple.MatchString(other),
+				string(compiled.FoldedLiterals[0]) == string(CaseFold([]byte(other))),
+				"term=%s, other=%s", term, other)
+		}
+	}
+}
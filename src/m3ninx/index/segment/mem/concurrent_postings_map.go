@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"sync"
 
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/postings/roaring"
 )
@@ -33,15 +34,23 @@ type concurrentPostingsMap struct {
 	sync.RWMutex
 	*postingsMap
 
+	// folded maps the case folded form of each key to the keys which share it,
+	// it is only maintained when case folded terms are enabled.
+	folded map[string][][]byte
+
 	opts Options
 }
 
 // newConcurrentPostingsMap returns a new thread-safe map from []byte -> postings.List.
 func newConcurrentPostingsMap(opts Options) *concurrentPostingsMap {
-	return &concurrentPostingsMap{
+	m := &concurrentPostingsMap{
 		postingsMap: newPostingsMap(opts.InitialCapacity()),
 		opts:        opts,
 	}
+	if opts.CaseFoldedTermsEnabled() {
+		m.folded = make(map[string][][]byte)
+	}
+	return m
 }
 
 // Add adds the provided `id` to the postings.List backing `key`.
@@ -72,6 +81,10 @@ func (m *concurrentPostingsMap) Add(key []byte, id postings.ID) error {
 		NoCopyKey:     true,
 		NoFinalizeKey: true,
 	})
+	if m.folded != nil {
+		foldedKey := string(index.CaseFold(key))
+		m.folded[foldedKey] = append(m.folded[foldedKey], key)
+	}
 	m.Unlock()
 	return p.Insert(id)
 }
@@ -121,3 +134,26 @@ func (m *concurrentPostingsMap) GetRegex(re *regexp.Regexp) (postings.List, bool
 	result, _ := roaring.Union(lists)
 	return result, true
 }
+
+// GetCaseFolded returns the union of the postings lists whose keys are equal
+// to one of the provided case folded literals once case folded. It must only
+// be called when case folded terms are enabled.
+func (m *concurrentPostingsMap) GetCaseFolded(literals [][]byte) (postings.List, bool) {
+	var lists []postings.List
+	m.RLock()
+	for _, literal := range literals {
+		for _, key := range m.folded[string(literal)] {
+			if p, ok := m.postingsMap.Get(key); ok {
+				lists = append(lists, p)
+			}
+		}
+	}
+	m.RUnlock()
+
+	if len(lists) == 0 {
+		return nil, false
+	}
+
+	result, _ := roaring.Union(lists)
+	return result, true
+}
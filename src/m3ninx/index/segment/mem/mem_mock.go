@@ -133,6 +133,22 @@ func (mr *MockReadableSegmentMockRecorder) getDoc(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getDoc", reflect.TypeOf((*MockReadableSegment)(nil).getDoc), arg0)
 }
 
+// matchCaseFolded mocks base method.
+func (m *MockReadableSegment) matchCaseFolded(arg0 []byte, arg1 [][]byte) (postings.List, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "matchCaseFolded", arg0, arg1)
+	ret0, _ := ret[0].(postings.List)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// matchCaseFolded indicates an expected call of matchCaseFolded.
+func (mr *MockReadableSegmentMockRecorder) matchCaseFolded(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "matchCaseFolded", reflect.TypeOf((*MockReadableSegment)(nil).matchCaseFolded), arg0, arg1)
+}
+
 // matchRegexp mocks base method.
 func (m *MockReadableSegment) matchRegexp(arg0 []byte, arg1 *regexp.Regexp) (postings.List, error) {
 	m.ctrl.T.Helper()
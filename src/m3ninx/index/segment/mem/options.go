@@ -70,6 +70,14 @@ type Options interface {
 
 	// NewUUIDFn returns the function used to generate new UUIDs.
 	NewUUIDFn() util.NewUUIDFn
+
+	// SetCaseFoldedTermsEnabled sets whether a case folded terms dictionary is
+	// maintained to speed up case-insensitive literal regexp matches.
+	SetCaseFoldedTermsEnabled(value bool) Options
+
+	// CaseFoldedTermsEnabled returns whether a case folded terms dictionary is
+	// maintained to speed up case-insensitive literal regexp matches.
+	CaseFoldedTermsEnabled() bool
 }
 
 type opts struct {
@@ -78,6 +86,7 @@ type opts struct {
 	postingsPool      postings.Pool
 	initialCapacity   int
 	newUUIDFn         util.NewUUIDFn
+	caseFoldedTerms   bool
 }
 
 // NewOptions returns new options.
@@ -149,3 +158,13 @@ func (o *opts) SetNewUUIDFn(v util.NewUUIDFn) Options {
 func (o *opts) NewUUIDFn() util.NewUUIDFn {
 	return o.newUUIDFn
 }
+
+func (o *opts) SetCaseFoldedTermsEnabled(v bool) Options {
+	opts := *o
+	opts.caseFoldedTerms = v
+	return &opts
+}
+
+func (o *opts) CaseFoldedTermsEnabled() bool {
+	return o.caseFoldedTerms
+}
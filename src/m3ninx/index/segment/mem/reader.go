@@ -105,6 +105,16 @@ func (r *reader) MatchRegexp(field []byte, compiled index.CompiledRegex) (postin
 	// permitted ID. The reader only guarantees that when fetching the documents associated
 	// with a postings list through a call to Docs will IDs greater than the maximum be
 	// filtered out.
+	if len(compiled.FoldedLiterals) > 0 {
+		pl, ok, err := r.segment.matchCaseFolded(field, compiled.FoldedLiterals)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return pl, nil
+		}
+	}
+
 	compileRE := compiled.Simple
 	if compileRE == nil {
 		return nil, errReaderNilRegex
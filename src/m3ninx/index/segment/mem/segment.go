@@ -396,6 +396,20 @@ func (s *memSegment) matchRegexp(field []byte, compiled *re.Regexp) (postings.Li
 	return s.termsDict.MatchRegexp(field, compiled), nil
 }
 
+func (s *memSegment) matchCaseFolded(
+	field []byte,
+	literals [][]byte,
+) (postings.List, bool, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, false, segment.ErrClosed
+	}
+
+	pl, ok := s.termsDict.MatchCaseFolded(field, literals)
+	return pl, ok, nil
+}
+
 func (s *memSegment) getDoc(id postings.ID) (doc.Metadata, error) {
 	s.state.RLock()
 	defer s.state.RUnlock()
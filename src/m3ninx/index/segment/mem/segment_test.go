@@ -21,6 +21,7 @@
 package mem
 
 import (
+	"fmt"
 	re "regexp"
 	"testing"
 
@@ -866,6 +867,72 @@ func TestSegmentReaderMatchRegex(t *testing.T) {
 	require.NoError(t, segment.Close())
 }
 
+func TestSegmentReaderMatchRegexCaseFolded(t *testing.T) {
+	docs := []doc.Metadata{
+		{
+			Fields: []doc.Field{{Name: []byte("fruit"), Value: []byte("Apple")}},
+		},
+		{
+			Fields: []doc.Field{{Name: []byte("fruit"), Value: []byte("APPLE")}},
+		},
+		{
+			Fields: []doc.Field{{Name: []byte("fruit"), Value: []byte("pineapple")}},
+		},
+		{
+			Fields: []doc.Field{{Name: []byte("fruit"), Value: []byte("banana")}},
+		},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			segment, err := NewSegment(testOptions.SetCaseFoldedTermsEnabled(enabled))
+			require.NoError(t, err)
+
+			for _, doc := range docs {
+				_, err = segment.Insert(doc)
+				require.NoError(t, err)
+			}
+
+			r, err := segment.Reader()
+			require.NoError(t, err)
+
+			for _, test := range []struct {
+				regexp   string
+				expected []doc.Metadata
+			}{
+				{regexp: "(?i)apple", expected: docs[:2]},
+				{regexp: "(?i)(apple|BANANA)", expected: []doc.Metadata{docs[0], docs[1], docs[3]}},
+				{regexp: "(?i)cherry"},
+			} {
+				compiled, err := index.CompileRegex([]byte(test.regexp))
+				require.NoError(t, err)
+				require.NotEmpty(t, compiled.FoldedLiterals)
+
+				pl, err := r.MatchRegexp([]byte("fruit"), compiled)
+				require.NoError(t, err)
+
+				iter, err := r.MetadataIterator(pl)
+				require.NoError(t, err)
+
+				actualDocs := make([]doc.Metadata, 0)
+				for iter.Next() {
+					actualDocs = append(actualDocs, iter.Current())
+				}
+				require.NoError(t, iter.Err())
+				require.NoError(t, iter.Close())
+
+				require.Equal(t, len(test.expected), len(actualDocs), test.regexp)
+				for i := range actualDocs {
+					require.True(t, compareDocs(test.expected[i], actualDocs[i]), test.regexp)
+				}
+			}
+
+			require.NoError(t, r.Close())
+			require.NoError(t, segment.Close())
+		})
+	}
+}
+
 func testDocument(t *testing.T, d doc.Metadata, r index.Reader) {
 	for _, f := range d.Fields {
 		name, value := f.Name, f.Value
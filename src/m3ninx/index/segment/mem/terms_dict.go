@@ -151,6 +151,26 @@ func (d *termsDict) MatchRegexp(
 	return pl
 }
 
+func (d *termsDict) MatchCaseFolded(
+	field []byte,
+	literals [][]byte,
+) (postings.List, bool) {
+	if !d.opts.CaseFoldedTermsEnabled() {
+		return nil, false
+	}
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return d.opts.PostingsListPool().Get(), true
+	}
+	pl, ok := postingsMap.GetCaseFolded(literals)
+	if !ok {
+		return d.opts.PostingsListPool().Get(), true
+	}
+	return pl, true
+}
+
 func (d *termsDict) Reset() {
 	d.fields.Lock()
 	defer d.fields.Unlock()
@@ -49,6 +49,11 @@ type termsDictionary interface {
 	// given egular expression.
 	MatchRegexp(field []byte, compiled *re.Regexp) postings.List
 
+	// MatchCaseFolded returns the postings list corresponding to documents which
+	// match any of the given case folded literals case-insensitively, the second
+	// return value is false if case folded terms are not enabled.
+	MatchCaseFolded(field []byte, literals [][]byte) (postings.List, bool)
+
 	// Fields returns the known fields.
 	Fields() sgmt.FieldsIterator
 
@@ -75,5 +80,6 @@ type ReadableSegment interface {
 	FieldsPostingsList() (sgmt.FieldsPostingsListIterator, error)
 	matchTerm(field, term []byte) (postings.List, error)
 	matchRegexp(field []byte, compiled *re.Regexp) (postings.List, error)
+	matchCaseFolded(field []byte, literals [][]byte) (postings.List, bool, error)
 	getDoc(id postings.ID) (doc.Metadata, error)
 }
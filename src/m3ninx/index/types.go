@@ -96,6 +96,12 @@ type CompiledRegex struct {
 	FSTSyntax   *syntax.Regexp
 	PrefixBegin []byte
 	PrefixEnd   []byte
+
+	// FoldedLiterals is set when the regexp only matches a set of literals
+	// case-insensitively and holds the case folded form of each literal, this
+	// allows segments with case folded terms dictionaries to avoid evaluating
+	// the regexp against every term.
+	FoldedLiterals [][]byte
 }
 
 // MetadataRetriever returns the metadata associated with a postings ID. It returns
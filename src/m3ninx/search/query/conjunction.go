@@ -46,6 +46,7 @@ func NewConjunctionQuery(queries []search.Query) search.Query {
 		case *ConjuctionQuery:
 			// Merge conjunction queries into slice of top-level queries.
 			qs = append(qs, query.queries...)
+			ns = append(ns, query.negations...)
 			continue
 		case *NegationQuery:
 			ns = append(ns, query.query)
@@ -91,7 +92,19 @@ func (q *ConjuctionQuery) Searcher() (search.Searcher, error) {
 	}
 
 	qsrs := make(search.Searchers, 0, len(q.queries))
+	nsrs := make(search.Searchers, 0, len(q.queries)+len(q.negations))
 	for _, q := range q.queries {
+		if neg, ok := q.(*NegationQuery); ok {
+			// NB: A negation is only kept with the queries when there are no other
+			// queries, evaluate it with the other negations so that the conjunction
+			// can subtract all of them at once rather than taking its complement.
+			sr, err := neg.query.Searcher()
+			if err != nil {
+				return nil, err
+			}
+			nsrs = append(nsrs, sr)
+			continue
+		}
 		sr, err := q.Searcher()
 		if err != nil {
 			return nil, err
@@ -99,7 +112,6 @@ func (q *ConjuctionQuery) Searcher() (search.Searcher, error) {
 		qsrs = append(qsrs, sr)
 	}
 
-	nsrs := make(search.Searchers, 0, len(q.negations))
 	for _, q := range q.negations {
 		sr, err := q.Searcher()
 		if err != nil {
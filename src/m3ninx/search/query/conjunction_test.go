@@ -63,6 +63,13 @@ func TestConjunctionQuery(t *testing.T) {
 				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
 			},
 		},
+		{
+			name: "multiple negation queries",
+			queries: []search.Query{
+				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
+				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -74,6 +81,25 @@ func TestConjunctionQuery(t *testing.T) {
 	}
 }
 
+func TestConjunctionQueryMergesNestedNegations(t *testing.T) {
+	nested := NewConjunctionQuery([]search.Query{
+		NewTermQuery([]byte("fruit"), []byte("apple")),
+		NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
+	})
+	q := NewConjunctionQuery([]search.Query{
+		nested,
+		NewTermQuery([]byte("color"), []byte("red")),
+	})
+
+	expected := NewConjunctionQuery([]search.Query{
+		NewTermQuery([]byte("fruit"), []byte("apple")),
+		NewTermQuery([]byte("color"), []byte("red")),
+		NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
+	})
+	require.True(t, q.Equal(expected))
+	require.Equal(t, expected.String(), q.String())
+}
+
 func TestConjunctionQueryEqual(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -23,20 +23,35 @@ package searcher
 import (
 	"sort"
 
+	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/search"
 )
 
+const (
+	// filterNegationsMaxPostings is the maximum length of the intersected postings
+	// list for which negations are evaluated by filtering the matched documents
+	// rather than by materializing the postings lists of the negations.
+	filterNegationsMaxPostings = 256
+)
+
+// documentMatcher is implemented by searchers which can determine whether a single
+// document matches without searching the reader for a postings list.
+type documentMatcher interface {
+	matchesDocument(d doc.Metadata) (bool, error)
+}
+
 type conjunctionSearcher struct {
 	searchers search.Searchers
 	negations search.Searchers
 }
 
 // NewConjunctionSearcher returns a new Searcher which matches documents which match each
-// of the given searchers and none of the negations.
+// of the given searchers and none of the negations. If no searchers are given then the
+// Searcher matches all documents which match none of the negations.
 func NewConjunctionSearcher(searchers, negations search.Searchers) (search.Searcher, error) {
-	if len(searchers) == 0 {
+	if len(searchers) == 0 && len(negations) == 0 {
 		return nil, errEmptySearchers
 	}
 
@@ -88,8 +103,41 @@ func (s *conjunctionSearcher) Search(r index.Reader) (postings.List, error) {
 		}
 	}
 
+	negations := s.negations
+	if pl == nil {
+		// NB: There are only negations so rather than taking the complement of
+		// each of them we subtract all of them from every document once.
+		all, err := r.MatchAll()
+		if err != nil {
+			return nil, err
+		}
+		pl = all
+	} else if !pl.IsEmpty() && pl.Len() <= filterNegationsMaxPostings {
+		// NB: When few documents match the other searchers it is cheaper to
+		// check each of them against the negations than to search the reader
+		// for the documents matching the negations, which for a regexp could
+		// be the union of the postings lists of a large number of terms.
+		var (
+			filtered bool
+			err      error
+		)
+		pl, negations, filtered, err = filterNegations(r, pl, negations)
+		if err != nil {
+			return nil, err
+		}
+		if filtered {
+			plNeedsClone = false
+		}
+	}
+
 	lists = lists[:0]
-	for _, sr := range s.negations {
+	for _, sr := range negations {
+		// We can skip evaluating the remaining negations if the resulting
+		// postings list is already empty.
+		if pl.IsEmpty() {
+			break
+		}
+
 		curr, err := sr.Search(r)
 		if err != nil {
 			return nil, err
@@ -123,6 +171,71 @@ func (s *conjunctionSearcher) Search(r index.Reader) (postings.List, error) {
 	return pl, nil
 }
 
+// filterNegations removes the documents from the postings list which match any of the
+// negations that are able to match documents directly and returns the negations which
+// still need to be evaluated, along with whether a new postings list was created.
+func filterNegations(
+	r index.Reader,
+	pl postings.List,
+	negations search.Searchers,
+) (postings.List, search.Searchers, bool, error) {
+	var (
+		matchers  []documentMatcher
+		remaining search.Searchers
+	)
+	for _, sr := range negations {
+		if m, ok := sr.(documentMatcher); ok {
+			matchers = append(matchers, m)
+			continue
+		}
+		remaining = append(remaining, sr)
+	}
+
+	if len(matchers) == 0 {
+		return pl, negations, false, nil
+	}
+
+	filtered := pl.CloneAsMutable()
+	iter := pl.Iterator()
+	for iter.Next() {
+		id := iter.Current()
+		d, err := r.Metadata(id)
+		if err == index.ErrDocNotFound {
+			// NB: Readers can return postings IDs which are past their limit, these
+			// are filtered out when the documents are retrieved so leave them as is.
+			continue
+		}
+		if err != nil {
+			_ = iter.Close()
+			return nil, nil, false, err
+		}
+
+		for _, m := range matchers {
+			matched, err := m.matchesDocument(d)
+			if err != nil {
+				_ = iter.Close()
+				return nil, nil, false, err
+			}
+			if matched {
+				if err := filtered.RemoveRange(id, id+1); err != nil {
+					_ = iter.Close()
+					return nil, nil, false, err
+				}
+				break
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		_ = iter.Close()
+		return nil, nil, false, err
+	}
+	if err := iter.Close(); err != nil {
+		return nil, nil, false, err
+	}
+
+	return filtered, remaining, true, nil
+}
+
 type postingsListWithLength struct {
 	list   postings.List
 	length int
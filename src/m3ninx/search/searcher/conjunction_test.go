@@ -23,6 +23,7 @@ package searcher
 import (
 	"testing"
 
+	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/postings/roaring"
@@ -108,6 +109,112 @@ func TestConjunctionSearcher(t *testing.T) {
 	require.True(t, pl.Equal(expected))
 }
 
+func TestConjunctionSearcherOnlyNegations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	reader := index.NewMockReader(mockCtrl)
+
+	allPL := roaring.NewPostingsList()
+	require.NoError(t, allPL.AddRange(postings.ID(1), postings.ID(6)))
+	firstPL := roaring.NewPostingsList()
+	require.NoError(t, firstPL.Insert(postings.ID(2)))
+	secondPL := roaring.NewPostingsList()
+	require.NoError(t, secondPL.Insert(postings.ID(4)))
+
+	firstSearcher := search.NewMockSearcher(mockCtrl)
+	secondSearcher := search.NewMockSearcher(mockCtrl)
+
+	gomock.InOrder(
+		reader.EXPECT().MatchAll().Return(allPL, nil),
+		firstSearcher.EXPECT().Search(reader).Return(firstPL, nil),
+		secondSearcher.EXPECT().Search(reader).Return(secondPL, nil),
+	)
+
+	s, err := NewConjunctionSearcher(nil, search.Searchers{firstSearcher, secondSearcher})
+	require.NoError(t, err)
+
+	expected := roaring.NewPostingsList()
+	require.NoError(t, expected.Insert(postings.ID(1)))
+	require.NoError(t, expected.Insert(postings.ID(3)))
+	require.NoError(t, expected.Insert(postings.ID(5)))
+
+	pl, err := s.Search(reader)
+	require.NoError(t, err)
+	require.True(t, pl.Equal(expected))
+}
+
+func TestConjunctionSearcherSkipsNegationsWhenEmpty(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	reader := index.NewMockReader(mockCtrl)
+
+	firstSearcher := search.NewMockSearcher(mockCtrl)
+	firstSearcher.EXPECT().Search(reader).Return(roaring.NewPostingsList(), nil)
+
+	// No expectations are set on the negation as it should never be searched.
+	negationSearcher := search.NewMockSearcher(mockCtrl)
+
+	s, err := NewConjunctionSearcher(search.Searchers{firstSearcher}, search.Searchers{negationSearcher})
+	require.NoError(t, err)
+
+	pl, err := s.Search(reader)
+	require.NoError(t, err)
+	require.True(t, pl.IsEmpty())
+}
+
+func TestConjunctionSearcherFilterNegations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	reader := index.NewMockReader(mockCtrl)
+
+	docs := map[postings.ID]doc.Metadata{
+		1: {Fields: []doc.Field{{Name: []byte("fruit"), Value: []byte("apple")}}},
+		2: {Fields: []doc.Field{{Name: []byte("fruit"), Value: []byte("banana")}}},
+		3: {Fields: []doc.Field{{Name: []byte("fruit"), Value: []byte("pineapple")}}},
+		4: {Fields: []doc.Field{{Name: []byte("color"), Value: []byte("red")}}},
+	}
+
+	positivePL := roaring.NewPostingsList()
+	for id := range docs {
+		require.NoError(t, positivePL.Insert(id))
+	}
+	positiveSearcher := search.NewMockSearcher(mockCtrl)
+	positiveSearcher.EXPECT().Search(reader).Return(positivePL, nil)
+
+	// The term and regexp negations are evaluated against the documents, no
+	// MatchTerm or MatchRegexp calls are expected on the reader.
+	reader.EXPECT().Metadata(gomock.Any()).DoAndReturn(func(id postings.ID) (doc.Metadata, error) {
+		return docs[id], nil
+	}).Times(len(docs))
+
+	compiled, err := index.CompileRegex([]byte(".*ple"))
+	require.NoError(t, err)
+
+	var (
+		searchers = search.Searchers{positiveSearcher}
+		negations = search.Searchers{
+			NewTermSearcher([]byte("fruit"), []byte("banana")),
+			NewRegexpSearcher([]byte("fruit"), compiled),
+		}
+	)
+
+	s, err := NewConjunctionSearcher(searchers, negations)
+	require.NoError(t, err)
+
+	expected := roaring.NewPostingsList()
+	require.NoError(t, expected.Insert(postings.ID(4)))
+
+	pl, err := s.Search(reader)
+	require.NoError(t, err)
+	require.True(t, pl.Equal(expected))
+
+	// The postings list returned by the positive searcher must not be modified.
+	require.Equal(t, len(docs), positivePL.Len())
+}
+
 func TestConjunctionSearcherError(t *testing.T) {
 	tests := []struct {
 		name      string
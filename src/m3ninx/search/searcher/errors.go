@@ -26,4 +26,5 @@ import (
 
 var (
 	errEmptySearchers = errors.New("list of searchers cannot be empty in a composite searcher")
+	errNilRegex       = errors.New("nil regex received")
 )
@@ -21,6 +21,9 @@
 package searcher
 
 import (
+	"bytes"
+
+	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/search"
@@ -40,3 +43,12 @@ func NewFieldSearcher(field []byte) (search.Searcher, error) {
 func (s *fieldSearcher) Search(r index.Reader) (postings.List, error) {
 	return r.MatchField(s.field)
 }
+
+func (s *fieldSearcher) matchesDocument(d doc.Metadata) (bool, error) {
+	for _, f := range d.Fields {
+		if bytes.Equal(f.Name, s.field) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
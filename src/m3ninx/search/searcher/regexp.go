@@ -21,6 +21,9 @@
 package searcher
 
 import (
+	"bytes"
+
+	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/search"
@@ -43,3 +46,15 @@ func NewRegexpSearcher(field []byte, compiled index.CompiledRegex) search.Search
 func (s *regexpSearcher) Search(r index.Reader) (postings.List, error) {
 	return r.MatchRegexp(s.field, s.compiled)
 }
+
+func (s *regexpSearcher) matchesDocument(d doc.Metadata) (bool, error) {
+	if s.compiled.Simple == nil {
+		return false, errNilRegex
+	}
+	for _, f := range d.Fields {
+		if bytes.Equal(f.Name, s.field) && s.compiled.Simple.Match(f.Value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -21,6 +21,9 @@
 package searcher
 
 import (
+	"bytes"
+
+	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/search"
@@ -41,3 +44,12 @@ func NewTermSearcher(field, term []byte) search.Searcher {
 func (s *termSearcher) Search(r index.Reader) (postings.List, error) {
 	return r.MatchTerm(s.field, s.term)
 }
+
+func (s *termSearcher) matchesDocument(d doc.Metadata) (bool, error) {
+	for _, f := range d.Fields {
+		if bytes.Equal(f.Name, s.field) && bytes.Equal(f.Value, s.term) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
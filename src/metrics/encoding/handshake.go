@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"errors"
+
+	"github.com/m3db/m3/src/metrics/generated/proto/metricpb"
+)
+
+const (
+	// LegacyProtocolVersion is the protocol version of connections that do not
+	// start with a handshake, which predate protocol negotiation.
+	LegacyProtocolVersion uint32 = 1
+
+	// CurrentProtocolVersion is the latest protocol version.
+	CurrentProtocolVersion uint32 = 2
+)
+
+var errNilProtocolHandshakeProto = errors.New("nil protocol handshake proto message")
+
+// A list of capabilities, which are the message types a peer can encode or
+// decode.
+const (
+	CounterCapability                  = "counter"
+	BatchTimerCapability               = "batch-timer"
+	GaugeCapability                    = "gauge"
+	ForwardedMetricCapability          = "forwarded-metric"
+	TimedMetricCapability              = "timed-metric"
+	TimedMetricWithMetadatasCapability = "timed-metric-with-metadatas"
+	PassthroughMetricCapability        = "passthrough-metric"
+)
+
+// DefaultCapabilities returns the capabilities of the current protocol.
+func DefaultCapabilities() []string {
+	return []string{
+		CounterCapability,
+		BatchTimerCapability,
+		GaugeCapability,
+		ForwardedMetricCapability,
+		TimedMetricCapability,
+		TimedMetricWithMetadatasCapability,
+		PassthroughMetricCapability,
+	}
+}
+
+// ProtocolHandshake negotiates the protocol version and capabilities of a
+// connection.
+type ProtocolHandshake struct {
+	// MinVersion and MaxVersion are the range of versions supported by the sender.
+	MinVersion uint32
+	MaxVersion uint32
+	// Version is the version chosen by the server, zero if there is no version
+	// supported by both the client and the server.
+	Version uint32
+	// Capabilities are the capabilities of the sender, or the ones supported by
+	// both the client and the server in the reply of the server.
+	Capabilities []string
+}
+
+// NegotiateProtocol returns the reply of a server supporting the versions in
+// [minVersion, maxVersion] and the given capabilities to the handshake of a
+// client, the highest version supported by both is chosen.
+func NegotiateProtocol(
+	h ProtocolHandshake,
+	minVersion, maxVersion uint32,
+	capabilities []string,
+) ProtocolHandshake {
+	version := h.MaxVersion
+	if version > maxVersion {
+		version = maxVersion
+	}
+	if version < h.MinVersion || version < minVersion {
+		version = 0
+	}
+
+	var common []string
+	for _, c := range capabilities {
+		for _, other := range h.Capabilities {
+			if c == other {
+				common = append(common, c)
+				break
+			}
+		}
+	}
+
+	return ProtocolHandshake{
+		MinVersion:   minVersion,
+		MaxVersion:   maxVersion,
+		Version:      version,
+		Capabilities: common,
+	}
+}
+
+// ToProto converts the protocol handshake to a protobuf message in place.
+func (h ProtocolHandshake) ToProto(pb *metricpb.ProtocolHandshake) {
+	pb.MinVersion = h.MinVersion
+	pb.MaxVersion = h.MaxVersion
+	pb.Version = h.Version
+	pb.Capabilities = h.Capabilities
+}
+
+// FromProto converts the protobuf message to a protocol handshake in place.
+func (h *ProtocolHandshake) FromProto(pb *metricpb.ProtocolHandshake) error {
+	if pb == nil {
+		return errNilProtocolHandshakeProto
+	}
+	h.MinVersion = pb.MinVersion
+	h.MaxVersion = pb.MaxVersion
+	h.Version = pb.Version
+	h.Capabilities = append(h.Capabilities[:0], pb.Capabilities...)
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/metrics/generated/proto/metricpb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateProtocol(t *testing.T) {
+	inputs := []struct {
+		client               ProtocolHandshake
+		minVersion           uint32
+		maxVersion           uint32
+		capabilities         []string
+		expectedVersion      uint32
+		expectedCapabilities []string
+	}{
+		{
+			client: ProtocolHandshake{
+				MinVersion:   1,
+				MaxVersion:   2,
+				Capabilities: []string{CounterCapability, GaugeCapability},
+			},
+			minVersion:           1,
+			maxVersion:           2,
+			capabilities:         DefaultCapabilities(),
+			expectedVersion:      2,
+			expectedCapabilities: []string{CounterCapability, GaugeCapability},
+		},
+		{
+			client: ProtocolHandshake{
+				MinVersion:   1,
+				MaxVersion:   3,
+				Capabilities: DefaultCapabilities(),
+			},
+			minVersion:           1,
+			maxVersion:           2,
+			capabilities:         []string{TimedMetricCapability},
+			expectedVersion:      2,
+			expectedCapabilities: []string{TimedMetricCapability},
+		},
+		{
+			client: ProtocolHandshake{
+				MinVersion: 3,
+				MaxVersion: 4,
+			},
+			minVersion:      1,
+			maxVersion:      2,
+			capabilities:    DefaultCapabilities(),
+			expectedVersion: 0,
+		},
+		{
+			client: ProtocolHandshake{
+				MinVersion: 1,
+				MaxVersion: 1,
+			},
+			minVersion:      2,
+			maxVersion:      3,
+			capabilities:    DefaultCapabilities(),
+			expectedVersion: 0,
+		},
+	}
+
+	for _, input := range inputs {
+		reply := NegotiateProtocol(input.client, input.minVersion, input.maxVersion, input.capabilities)
+		require.Equal(t, input.minVersion, reply.MinVersion)
+		require.Equal(t, input.maxVersion, reply.MaxVersion)
+		require.Equal(t, input.expectedVersion, reply.Version)
+		require.Equal(t, input.expectedCapabilities, reply.Capabilities)
+	}
+}
+
+func TestProtocolHandshakeToFromProto(t *testing.T) {
+	h := ProtocolHandshake{
+		MinVersion:   LegacyProtocolVersion,
+		MaxVersion:   CurrentProtocolVersion,
+		Version:      CurrentProtocolVersion,
+		Capabilities: DefaultCapabilities(),
+	}
+	var pb metricpb.ProtocolHandshake
+	h.ToProto(&pb)
+
+	var res ProtocolHandshake
+	require.NoError(t, res.FromProto(&pb))
+	require.Equal(t, h, res)
+}
+
+func TestProtocolHandshakeFromProtoNilProto(t *testing.T) {
+	var h ProtocolHandshake
+	require.Equal(t, errNilProtocolHandshakeProto, h.FromProto(nil))
+}
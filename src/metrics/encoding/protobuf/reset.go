@@ -50,6 +50,7 @@ func ReuseMetricWithMetadatasProto(pb *metricpb.MetricWithMetadatas) {
 	resetTimedMetricWithMetadataProto(pb.TimedMetricWithMetadata)
 	resetTimedMetricWithMetadatasProto(pb.TimedMetricWithMetadatas)
 	resetTimedMetricWithStoragePolicyProto(pb.TimedMetricWithStoragePolicy)
+	resetProtocolHandshakeProto(pb.ProtocolHandshake)
 }
 
 // ReuseAggregatedMetricProto allows for zero-alloc reuse of
@@ -118,6 +119,16 @@ func resetTimedMetricWithStoragePolicyProto(pb *metricpb.TimedMetricWithStorageP
 	pb.StoragePolicy.Reset()
 }
 
+func resetProtocolHandshakeProto(pb *metricpb.ProtocolHandshake) {
+	if pb == nil {
+		return
+	}
+	pb.MinVersion = 0
+	pb.MaxVersion = 0
+	pb.Version = 0
+	pb.Capabilities = pb.Capabilities[:0]
+}
+
 func resetCounter(pb *metricpb.Counter) {
 	if pb == nil {
 		return
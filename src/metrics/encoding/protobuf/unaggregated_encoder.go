@@ -70,6 +70,7 @@ type unaggregatedEncoder struct {
 	fm                  metricpb.ForwardedMetricWithMetadata
 	pm                  metricpb.TimedMetricWithStoragePolicy
 	tm                  metricpb.TimedMetricWithMetadata
+	hs                  metricpb.ProtocolHandshake
 	used                int
 	initBufSize         int
 	maxMessageSize      int
@@ -131,6 +132,8 @@ func (enc *unaggregatedEncoder) EncodeMessage(msg encoding.UnaggregatedMessageUn
 		return enc.encodeTimedMetricWithMetadatas(msg.TimedMetricWithMetadatas)
 	case encoding.PassthroughMetricWithMetadataType:
 		return enc.encodePassthroughMetricWithMetadata(msg.PassthroughMetricWithMetadata)
+	case encoding.ProtocolHandshakeType:
+		return enc.encodeProtocolHandshake(msg.ProtocolHandshake)
 	default:
 		return fmt.Errorf("unknown message type: %v", msg.Type)
 	}
@@ -213,6 +216,15 @@ func (enc *unaggregatedEncoder) encodePassthroughMetricWithMetadata(pm aggregate
 	return enc.encodeMetricWithMetadatas(mm)
 }
 
+func (enc *unaggregatedEncoder) encodeProtocolHandshake(h encoding.ProtocolHandshake) error {
+	h.ToProto(&enc.hs)
+	mm := metricpb.MetricWithMetadatas{
+		Type:              metricpb.MetricWithMetadatas_PROTOCOL_HANDSHAKE,
+		ProtocolHandshake: &enc.hs,
+	}
+	return enc.encodeMetricWithMetadatas(mm)
+}
+
 func (enc *unaggregatedEncoder) encodeMetricWithMetadatas(pb metricpb.MetricWithMetadatas) error {
 	msgSize := pb.Size()
 	if msgSize > enc.maxMessageSize {
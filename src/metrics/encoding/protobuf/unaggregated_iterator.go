@@ -150,6 +150,9 @@ func (it *UnaggregatedIterator) decodeMessage(size int) error {
 	case metricpb.MetricWithMetadatas_TIMED_METRIC_WITH_STORAGE_POLICY:
 		it.msg.Type = encoding.PassthroughMetricWithMetadataType
 		it.err = it.msg.PassthroughMetricWithMetadata.FromProto(it.pb.TimedMetricWithStoragePolicy)
+	case metricpb.MetricWithMetadatas_PROTOCOL_HANDSHAKE:
+		it.msg.Type = encoding.ProtocolHandshakeType
+		it.err = it.msg.ProtocolHandshake.FromProto(it.pb.ProtocolHandshake)
 	default:
 		it.err = fmt.Errorf("unrecognized message type: %v", it.pb.Type)
 	}
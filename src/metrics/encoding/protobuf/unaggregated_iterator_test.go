@@ -310,6 +310,47 @@ func TestUnaggregatedIteratorDecodeTimedMetricWithMetadata(t *testing.T) {
 	require.Equal(t, len(inputs), i)
 }
 
+func TestUnaggregatedIteratorDecodeProtocolHandshake(t *testing.T) {
+	inputs := []encoding.ProtocolHandshake{
+		{
+			MinVersion:   encoding.LegacyProtocolVersion,
+			MaxVersion:   encoding.CurrentProtocolVersion,
+			Capabilities: encoding.DefaultCapabilities(),
+		},
+		{
+			MinVersion:   encoding.LegacyProtocolVersion,
+			MaxVersion:   encoding.CurrentProtocolVersion,
+			Version:      encoding.CurrentProtocolVersion,
+			Capabilities: []string{encoding.CounterCapability},
+		},
+	}
+
+	enc := NewUnaggregatedEncoder(NewUnaggregatedOptions())
+	for _, input := range inputs {
+		require.NoError(t, enc.EncodeMessage(encoding.UnaggregatedMessageUnion{
+			Type:              encoding.ProtocolHandshakeType,
+			ProtocolHandshake: input,
+		}))
+	}
+	dataBuf := enc.Relinquish()
+	defer dataBuf.Close()
+
+	var (
+		i      int
+		stream = bytes.NewReader(dataBuf.Bytes())
+	)
+	it := NewUnaggregatedIterator(stream, NewUnaggregatedOptions())
+	defer it.Close()
+	for it.Next() {
+		res := it.Current()
+		require.Equal(t, encoding.ProtocolHandshakeType, res.Type)
+		require.Equal(t, inputs[i], res.ProtocolHandshake)
+		i++
+	}
+	require.Equal(t, io.EOF, it.Err())
+	require.Equal(t, len(inputs), i)
+}
+
 func TestUnaggregatedIteratorDecodeStress(t *testing.T) {
 	inputs := []interface{}{
 		unaggregated.CounterWithMetadatas{
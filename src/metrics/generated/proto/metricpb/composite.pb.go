@@ -39,6 +39,7 @@
 		TimedMetricWithStoragePolicy
 		AggregatedMetric
 		MetricWithMetadatas
+		ProtocolHandshake
 		PipelineMetadata
 		Metadata
 		StagedMetadata
@@ -84,6 +85,7 @@ const (
 	MetricWithMetadatas_TIMED_METRIC_WITH_METADATA       MetricWithMetadatas_Type = 5
 	MetricWithMetadatas_TIMED_METRIC_WITH_METADATAS      MetricWithMetadatas_Type = 6
 	MetricWithMetadatas_TIMED_METRIC_WITH_STORAGE_POLICY MetricWithMetadatas_Type = 7
+	MetricWithMetadatas_PROTOCOL_HANDSHAKE               MetricWithMetadatas_Type = 8
 )
 
 var MetricWithMetadatas_Type_name = map[int32]string{
@@ -95,6 +97,7 @@ var MetricWithMetadatas_Type_name = map[int32]string{
 	5: "TIMED_METRIC_WITH_METADATA",
 	6: "TIMED_METRIC_WITH_METADATAS",
 	7: "TIMED_METRIC_WITH_STORAGE_POLICY",
+	8: "PROTOCOL_HANDSHAKE",
 }
 var MetricWithMetadatas_Type_value = map[string]int32{
 	"UNKNOWN":                          0,
@@ -105,6 +108,7 @@ var MetricWithMetadatas_Type_value = map[string]int32{
 	"TIMED_METRIC_WITH_METADATA":       5,
 	"TIMED_METRIC_WITH_METADATAS":      6,
 	"TIMED_METRIC_WITH_STORAGE_POLICY": 7,
+	"PROTOCOL_HANDSHAKE":               8,
 }
 
 func (x MetricWithMetadatas_Type) String() string {
@@ -327,6 +331,7 @@ type MetricWithMetadatas struct {
 	TimedMetricWithMetadata      *TimedMetricWithMetadata      `protobuf:"bytes,6,opt,name=timed_metric_with_metadata,json=timedMetricWithMetadata" json:"timed_metric_with_metadata,omitempty"`
 	TimedMetricWithMetadatas     *TimedMetricWithMetadatas     `protobuf:"bytes,7,opt,name=timed_metric_with_metadatas,json=timedMetricWithMetadatas" json:"timed_metric_with_metadatas,omitempty"`
 	TimedMetricWithStoragePolicy *TimedMetricWithStoragePolicy `protobuf:"bytes,8,opt,name=timed_metric_with_storage_policy,json=timedMetricWithStoragePolicy" json:"timed_metric_with_storage_policy,omitempty"`
+	ProtocolHandshake            *ProtocolHandshake            `protobuf:"bytes,9,opt,name=protocol_handshake,json=protocolHandshake" json:"protocol_handshake,omitempty"`
 }
 
 func (m *MetricWithMetadatas) Reset()                    { *m = MetricWithMetadatas{} }
@@ -390,6 +395,57 @@ func (m *MetricWithMetadatas) GetTimedMetricWithStoragePolicy() *TimedMetricWith
 	return nil
 }
 
+func (m *MetricWithMetadatas) GetProtocolHandshake() *ProtocolHandshake {
+	if m != nil {
+		return m.ProtocolHandshake
+	}
+	return nil
+}
+
+// ProtocolHandshake negotiates the protocol version and capabilities of a
+// connection. The client sends the range of versions it supports and its
+// capabilities, the server replies with the version chosen and the
+// capabilities supported by both sides.
+type ProtocolHandshake struct {
+	MinVersion   uint32   `protobuf:"varint,1,opt,name=min_version,json=minVersion,proto3" json:"min_version,omitempty"`
+	MaxVersion   uint32   `protobuf:"varint,2,opt,name=max_version,json=maxVersion,proto3" json:"max_version,omitempty"`
+	Version      uint32   `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Capabilities []string `protobuf:"bytes,4,rep,name=capabilities" json:"capabilities,omitempty"`
+}
+
+func (m *ProtocolHandshake) Reset()                    { *m = ProtocolHandshake{} }
+func (m *ProtocolHandshake) String() string            { return proto.CompactTextString(m) }
+func (*ProtocolHandshake) ProtoMessage()               {}
+func (*ProtocolHandshake) Descriptor() ([]byte, []int) { return fileDescriptorComposite, []int{9} }
+
+func (m *ProtocolHandshake) GetMinVersion() uint32 {
+	if m != nil {
+		return m.MinVersion
+	}
+	return 0
+}
+
+func (m *ProtocolHandshake) GetMaxVersion() uint32 {
+	if m != nil {
+		return m.MaxVersion
+	}
+	return 0
+}
+
+func (m *ProtocolHandshake) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ProtocolHandshake) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*CounterWithMetadatas)(nil), "metricpb.CounterWithMetadatas")
 	proto.RegisterType((*BatchTimerWithMetadatas)(nil), "metricpb.BatchTimerWithMetadatas")
@@ -400,6 +456,7 @@ func init() {
 	proto.RegisterType((*TimedMetricWithStoragePolicy)(nil), "metricpb.TimedMetricWithStoragePolicy")
 	proto.RegisterType((*AggregatedMetric)(nil), "metricpb.AggregatedMetric")
 	proto.RegisterType((*MetricWithMetadatas)(nil), "metricpb.MetricWithMetadatas")
+	proto.RegisterType((*ProtocolHandshake)(nil), "metricpb.ProtocolHandshake")
 	proto.RegisterEnum("metricpb.MetricWithMetadatas_Type", MetricWithMetadatas_Type_name, MetricWithMetadatas_Type_value)
 }
 func (m *CounterWithMetadatas) Marshal() (dAtA []byte, err error) {
@@ -761,6 +818,64 @@ func (m *MetricWithMetadatas) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n22
 	}
+	if m.ProtocolHandshake != nil {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintComposite(dAtA, i, uint64(m.ProtocolHandshake.Size()))
+		n23, err := m.ProtocolHandshake.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n23
+	}
+	return i, nil
+}
+
+func (m *ProtocolHandshake) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProtocolHandshake) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.MinVersion != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintComposite(dAtA, i, uint64(m.MinVersion))
+	}
+	if m.MaxVersion != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintComposite(dAtA, i, uint64(m.MaxVersion))
+	}
+	if m.Version != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintComposite(dAtA, i, uint64(m.Version))
+	}
+	if len(m.Capabilities) > 0 {
+		for _, s := range m.Capabilities {
+			dAtA[i] = 0x22
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
 	return i, nil
 }
 
@@ -888,6 +1003,31 @@ func (m *MetricWithMetadatas) Size() (n int) {
 		l = m.TimedMetricWithStoragePolicy.Size()
 		n += 1 + l + sovComposite(uint64(l))
 	}
+	if m.ProtocolHandshake != nil {
+		l = m.ProtocolHandshake.Size()
+		n += 1 + l + sovComposite(uint64(l))
+	}
+	return n
+}
+
+func (m *ProtocolHandshake) Size() (n int) {
+	var l int
+	_ = l
+	if m.MinVersion != 0 {
+		n += 1 + sovComposite(uint64(m.MinVersion))
+	}
+	if m.MaxVersion != 0 {
+		n += 1 + sovComposite(uint64(m.MaxVersion))
+	}
+	if m.Version != 0 {
+		n += 1 + sovComposite(uint64(m.Version))
+	}
+	if len(m.Capabilities) > 0 {
+		for _, s := range m.Capabilities {
+			l = len(s)
+			n += 1 + l + sovComposite(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -2052,6 +2192,175 @@ func (m *MetricWithMetadatas) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProtocolHandshake", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowComposite
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthComposite
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ProtocolHandshake == nil {
+				m.ProtocolHandshake = &ProtocolHandshake{}
+			}
+			if err := m.ProtocolHandshake.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipComposite(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthComposite
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProtocolHandshake) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowComposite
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProtocolHandshake: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProtocolHandshake: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinVersion", wireType)
+			}
+			m.MinVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowComposite
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxVersion", wireType)
+			}
+			m.MaxVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowComposite
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowComposite
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capabilities", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowComposite
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthComposite
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Capabilities = append(m.Capabilities, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipComposite(dAtA[iNdEx:])
@@ -2183,56 +2492,63 @@ func init() {
 }
 
 var fileDescriptorComposite = []byte{
-	// 808 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x96, 0xcd, 0x6e, 0xd3, 0x58,
-	0x14, 0xc7, 0xeb, 0x36, 0x4d, 0x3a, 0x27, 0x9d, 0x4e, 0xe6, 0x4e, 0xa6, 0xc9, 0x24, 0x95, 0xdb,
-	0x5a, 0x33, 0x23, 0x24, 0x44, 0x22, 0x1a, 0x89, 0x0a, 0x55, 0x20, 0x39, 0x1f, 0x4d, 0x23, 0x68,
-	0x52, 0x39, 0xae, 0x22, 0x58, 0xd4, 0xb2, 0x1d, 0xd7, 0x31, 0x22, 0x71, 0x64, 0xdf, 0xa8, 0xaa,
-	0xd8, 0xb0, 0x84, 0x0d, 0x42, 0x42, 0xbc, 0x01, 0x0f, 0xd3, 0x25, 0x4f, 0x80, 0x50, 0x59, 0xb1,
-	0xe7, 0x01, 0x90, 0xed, 0xeb, 0xf8, 0x2b, 0x2e, 0x90, 0xec, 0xec, 0xf3, 0xf1, 0x3b, 0xff, 0x5c,
-	0xdf, 0xf3, 0x57, 0xa0, 0xa9, 0x6a, 0x78, 0x30, 0x91, 0x4a, 0xb2, 0x3e, 0x2c, 0x0f, 0x2b, 0x7d,
-	0xa9, 0x3c, 0xac, 0x94, 0x4d, 0x43, 0x2e, 0x0f, 0x15, 0x6c, 0x68, 0xb2, 0x59, 0x56, 0x95, 0x91,
-	0x62, 0x88, 0x58, 0xe9, 0x97, 0xc7, 0x86, 0x8e, 0x75, 0x12, 0x1f, 0x4b, 0x65, 0x59, 0x1f, 0x8e,
-	0x75, 0x53, 0xc3, 0x4a, 0xc9, 0x4e, 0xa0, 0x35, 0x37, 0x53, 0xb8, 0xe3, 0x43, 0xaa, 0xba, 0xaa,
-	0x3b, 0x9d, 0xd2, 0xe4, 0xdc, 0x7e, 0x73, 0x30, 0xd6, 0x93, 0xd3, 0x58, 0xa8, 0xcf, 0xab, 0xc0,
-	0x79, 0x20, 0x94, 0xc3, 0x05, 0x28, 0x62, 0x5f, 0xc4, 0xe2, 0x9c, 0x6a, 0xc6, 0xfa, 0x73, 0x4d,
-	0xbe, 0x1c, 0x4b, 0xe4, 0xc1, 0xa1, 0x30, 0xaf, 0x28, 0xc8, 0xd6, 0xf4, 0xc9, 0x08, 0x2b, 0x46,
-	0x4f, 0xc3, 0x83, 0x63, 0x32, 0xc3, 0x44, 0x77, 0x21, 0x25, 0x3b, 0xf1, 0x3c, 0xb5, 0x43, 0xdd,
-	0x4a, 0xef, 0xfd, 0x59, 0x72, 0x95, 0x94, 0x48, 0x43, 0x35, 0x71, 0xf5, 0x69, 0x7b, 0x89, 0x73,
-	0xeb, 0xd0, 0x03, 0xf8, 0xcd, 0xd5, 0x68, 0xe6, 0x97, 0xed, 0xa6, 0x7f, 0xbc, 0xa6, 0x2e, 0x16,
-	0x55, 0xa5, 0x3f, 0x1d, 0x40, 0x9a, 0xbd, 0x0e, 0xe6, 0x3d, 0x05, 0xb9, 0xaa, 0x88, 0xe5, 0x01,
-	0xaf, 0x0d, 0xc3, 0x6a, 0x0e, 0x20, 0x2d, 0x59, 0x29, 0x01, 0x5b, 0x39, 0xa2, 0x28, 0xeb, 0xc1,
-	0xbd, 0x3e, 0xc2, 0x05, 0x69, 0x1a, 0x59, 0x54, 0xd7, 0x4b, 0x0a, 0x50, 0x53, 0x9c, 0xa8, 0x4a,
-	0x50, 0xd2, 0x6d, 0x58, 0x55, 0xad, 0x28, 0x11, 0xf3, 0x87, 0x47, 0xb4, 0x8b, 0x09, 0xc7, 0xa9,
-	0x59, 0x54, 0xc2, 0x3b, 0x0a, 0x8a, 0x87, 0xba, 0x71, 0x21, 0x1a, 0x7d, 0xbb, 0xce, 0xd0, 0x64,
-	0xbf, 0x18, 0xb4, 0x0f, 0x49, 0x07, 0x46, 0xc4, 0xf8, 0xd8, 0xa1, 0x36, 0xc2, 0x26, 0xe5, 0xe8,
-	0x00, 0xd6, 0xdc, 0x29, 0x51, 0x59, 0xa4, 0xd5, 0x9d, 0x42, 0x5a, 0xa7, 0x0d, 0xcc, 0x6b, 0x0a,
-	0x72, 0xd6, 0x09, 0xcf, 0x52, 0x54, 0x09, 0x29, 0xfa, 0xdb, 0xc3, 0xfa, 0x5a, 0x42, 0x6a, 0xee,
-	0x47, 0xd4, 0xe4, 0xa2, 0x6d, 0xb3, 0xb5, 0xbc, 0xa1, 0x20, 0x1f, 0xa3, 0xc5, 0x9c, 0x4f, 0xcc,
-	0x82, 0x9f, 0xec, 0x03, 0x05, 0x5b, 0x21, 0x41, 0x5d, 0xac, 0x1b, 0xa2, 0xaa, 0x9c, 0xd8, 0xfb,
-	0x87, 0x1e, 0xc2, 0xba, 0x75, 0x99, 0xfb, 0xc2, 0xcf, 0x4b, 0x4b, 0x63, 0x2f, 0x84, 0xea, 0xb0,
-	0x61, 0x3a, 0x40, 0xc1, 0xd9, 0xe8, 0xe9, 0x91, 0xb9, 0x9b, 0x5e, 0x0a, 0x0c, 0x24, 0x8c, 0xdf,
-	0x4d, 0x7f, 0x90, 0x79, 0x01, 0x19, 0x56, 0x55, 0x0d, 0x45, 0xb5, 0x9c, 0x62, 0x4a, 0x0e, 0x1e,
-	0xd7, 0xff, 0x33, 0x35, 0x45, 0x7e, 0x51, 0xe8, 0xfc, 0x76, 0x61, 0x5d, 0x19, 0xc9, 0x7a, 0x5f,
-	0x11, 0x46, 0xe2, 0x48, 0x77, 0x8e, 0x70, 0x85, 0x4b, 0x3b, 0xb1, 0xb6, 0x15, 0x62, 0xbe, 0xa6,
-	0xe0, 0xaf, 0x59, 0xdf, 0xeb, 0x1e, 0x24, 0xf0, 0xe5, 0xd8, 0xd9, 0xac, 0x8d, 0x3d, 0xc6, 0x1b,
-	0x3f, 0xa3, 0xb8, 0xc4, 0x5f, 0x8e, 0x15, 0xce, 0xae, 0x47, 0x3c, 0x6c, 0x12, 0x2f, 0x12, 0x2e,
-	0x34, 0x3c, 0x10, 0xc2, 0xdf, 0x8f, 0x8e, 0x58, 0x58, 0x00, 0xc5, 0x65, 0xe5, 0x59, 0x4e, 0x78,
-	0x06, 0x05, 0x9f, 0xf7, 0x84, 0xc9, 0x2b, 0x36, 0x79, 0x77, 0x96, 0x15, 0x05, 0xe1, 0x39, 0x29,
-	0xc6, 0xdb, 0xda, 0x90, 0xb5, 0x4d, 0x22, 0x4c, 0x4e, 0xd8, 0xe4, 0xad, 0x90, 0xaf, 0x04, 0xa1,
-	0x48, 0x8d, 0x1a, 0xd3, 0x33, 0xa0, 0xcf, 0xdd, 0xa5, 0x27, 0x97, 0x2b, 0x88, 0xce, 0xaf, 0xda,
-	0xe4, 0xff, 0x62, 0x4d, 0xc2, 0xcf, 0xe3, 0x8a, 0xe7, 0x37, 0x18, 0xcf, 0x19, 0x14, 0xfc, 0x97,
-	0x38, 0x34, 0x27, 0x19, 0x3e, 0x9b, 0x98, 0x0d, 0xe5, 0x72, 0x38, 0xc6, 0x46, 0x44, 0x28, 0xc6,
-	0xf3, 0xcd, 0x7c, 0xca, 0x1e, 0xc0, 0xfc, 0x70, 0x80, 0xc9, 0xe5, 0x71, 0x9c, 0x39, 0x8c, 0x60,
-	0x27, 0x3a, 0x22, 0xb4, 0x59, 0x6b, 0xbf, 0xb2, 0x07, 0xdc, 0x16, 0xbe, 0x21, 0xcb, 0x7c, 0xa3,
-	0x20, 0x61, 0xdd, 0x59, 0x94, 0x86, 0xd4, 0x69, 0xfb, 0x51, 0xbb, 0xd3, 0x6b, 0x67, 0x96, 0x50,
-	0x01, 0x36, 0x6b, 0x9d, 0xd3, 0x36, 0xdf, 0xe0, 0x84, 0x5e, 0x8b, 0x3f, 0x12, 0x8e, 0x1b, 0x3c,
-	0x5b, 0x67, 0x79, 0xb6, 0x9b, 0xa1, 0x10, 0x0d, 0x85, 0x2a, 0xcb, 0xd7, 0x8e, 0x04, 0xbe, 0x75,
-	0x1c, 0xcd, 0x2f, 0xa3, 0x3c, 0x64, 0x9b, 0xec, 0x69, 0xb3, 0x11, 0xce, 0xac, 0x20, 0x06, 0xe8,
-	0xc3, 0x0e, 0xd7, 0x63, 0xb9, 0x7a, 0xa3, 0x6e, 0x25, 0xb8, 0x56, 0x2d, 0x58, 0x94, 0x49, 0x58,
-	0x74, 0x8b, 0x1b, 0x93, 0x5f, 0x45, 0xdb, 0x50, 0x8c, 0xcf, 0x77, 0x33, 0x49, 0xf4, 0x2f, 0xec,
-	0x44, 0x0b, 0xba, 0x7c, 0x87, 0x63, 0x9b, 0x0d, 0xe1, 0xa4, 0xf3, 0xb8, 0x55, 0x7b, 0x92, 0x49,
-	0x55, 0x5b, 0x57, 0xd7, 0x34, 0xf5, 0xf1, 0x9a, 0xa6, 0x3e, 0x5f, 0xd3, 0xd4, 0xdb, 0x2f, 0xf4,
-	0xd2, 0xd3, 0xfd, 0x39, 0xff, 0x08, 0x49, 0x49, 0xfb, 0xbd, 0xf2, 0x3d, 0x00, 0x00, 0xff, 0xff,
-	0x34, 0x3f, 0x09, 0x4f, 0x12, 0x0a, 0x00, 0x00,
+	// 920 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xa5, 0x56, 0x51, 0x6f, 0xdb, 0x54,
+	0x14, 0xae, 0x9b, 0xb4, 0x49, 0x4f, 0xba, 0x91, 0xde, 0x85, 0xd6, 0x24, 0x55, 0xd6, 0x59, 0x30,
+	0x4d, 0x42, 0x24, 0x62, 0x95, 0x98, 0x10, 0x02, 0xc9, 0x4d, 0xd2, 0x26, 0x6c, 0x4d, 0xaa, 0x1b,
+	0x97, 0x0a, 0x1e, 0x66, 0xd9, 0x8e, 0xeb, 0x7a, 0x34, 0x76, 0x64, 0xdf, 0xb2, 0x4d, 0xbc, 0xf0,
+	0x08, 0x12, 0x42, 0x48, 0x88, 0x7f, 0xc0, 0x8f, 0xd9, 0x0b, 0x12, 0xbf, 0x00, 0x21, 0xf8, 0x23,
+	0x5c, 0x5f, 0x5f, 0xc7, 0xb1, 0x1d, 0x6f, 0xd0, 0x3e, 0x24, 0xb2, 0xcf, 0xf9, 0xbe, 0xef, 0x7c,
+	0x39, 0xd7, 0xe7, 0x38, 0x70, 0x64, 0xd9, 0xe4, 0xe2, 0x4a, 0x6f, 0x19, 0xee, 0xb4, 0x3d, 0xdd,
+	0x9f, 0xe8, 0xf4, 0xab, 0xed, 0x7b, 0x46, 0x7b, 0x6a, 0x12, 0xcf, 0x36, 0xfc, 0xb6, 0x65, 0x3a,
+	0xa6, 0xa7, 0x11, 0x73, 0xd2, 0x9e, 0x79, 0x2e, 0x71, 0x79, 0x7c, 0xa6, 0xb7, 0x29, 0x61, 0xe6,
+	0xfa, 0x36, 0x31, 0x5b, 0x2c, 0x81, 0xca, 0x51, 0xa6, 0xfe, 0xc1, 0x82, 0xa4, 0xe5, 0x5a, 0x6e,
+	0xc8, 0xd4, 0xaf, 0xce, 0xd9, 0x5d, 0x28, 0x13, 0x5c, 0x85, 0xc4, 0x7a, 0xf7, 0xba, 0x0e, 0xc2,
+	0x0b, 0xae, 0x72, 0x78, 0x03, 0x15, 0x6d, 0xa2, 0x11, 0xed, 0x9a, 0x6e, 0x66, 0xee, 0xa5, 0x6d,
+	0xbc, 0xa4, 0x3a, 0xe1, 0x45, 0xa8, 0x22, 0x7d, 0x2f, 0x40, 0xad, 0xe3, 0x5e, 0x39, 0xc4, 0xf4,
+	0xce, 0xa8, 0xde, 0x31, 0xaf, 0xe1, 0xa3, 0x0f, 0xa1, 0x64, 0x84, 0x71, 0x51, 0xd8, 0x13, 0x1e,
+	0x54, 0x1e, 0x6e, 0xb5, 0x22, 0x27, 0x2d, 0x4e, 0x38, 0x28, 0xbe, 0xfa, 0xf3, 0xee, 0x0a, 0x8e,
+	0x70, 0xe8, 0x53, 0xd8, 0x88, 0x3c, 0xfa, 0xe2, 0x2a, 0x23, 0xbd, 0x13, 0x93, 0xc6, 0x44, 0xb3,
+	0xcc, 0xc9, 0xbc, 0x00, 0x27, 0xc7, 0x0c, 0xe9, 0x57, 0x01, 0x76, 0x0e, 0x34, 0x62, 0x5c, 0x28,
+	0xf6, 0x34, 0xed, 0xe6, 0x13, 0xa8, 0xe8, 0x41, 0x4a, 0x25, 0x41, 0x8e, 0x3b, 0xaa, 0xc5, 0xe2,
+	0x31, 0x8f, 0xeb, 0x82, 0x3e, 0x8f, 0xdc, 0xd4, 0xd7, 0x77, 0x02, 0xa0, 0x23, 0xed, 0xca, 0x32,
+	0x93, 0x96, 0xde, 0x87, 0x35, 0x2b, 0x88, 0x72, 0x33, 0x6f, 0xc5, 0x8a, 0x0c, 0xcc, 0x75, 0x42,
+	0xcc, 0x4d, 0x2d, 0xfc, 0x22, 0x40, 0xe3, 0xd0, 0xf5, 0x9e, 0x6b, 0xde, 0x84, 0xe1, 0x28, 0x6d,
+	0xd1, 0x0c, 0x7a, 0x04, 0xeb, 0xa1, 0x18, 0x37, 0xb3, 0xa0, 0x9d, 0xa2, 0x71, 0x6d, 0x0e, 0xa7,
+	0x7d, 0x2d, 0x47, 0x55, 0xb2, 0xb6, 0x38, 0x35, 0xaa, 0xc2, 0xa9, 0x73, 0x82, 0xf4, 0x03, 0x3d,
+	0xb0, 0xa0, 0xc3, 0xcb, 0x1c, 0xed, 0xa7, 0x1c, 0xbd, 0x1d, 0xcb, 0x2e, 0x50, 0x52, 0x6e, 0x3e,
+	0xce, 0xb8, 0xd9, 0xc9, 0xd2, 0x96, 0x7b, 0xf9, 0x49, 0x00, 0x31, 0xc7, 0x8b, 0x7f, 0x3d, 0x33,
+	0x37, 0x3c, 0xb2, 0xdf, 0x04, 0xd8, 0x4d, 0x19, 0x1a, 0x13, 0xd7, 0xa3, 0xac, 0x13, 0x36, 0x7f,
+	0xe8, 0x33, 0xd8, 0x0c, 0x1e, 0xe6, 0x89, 0xfa, 0xdf, 0xad, 0x55, 0x48, 0x1c, 0x42, 0x5d, 0xb8,
+	0xed, 0x87, 0x82, 0x6a, 0x38, 0xd1, 0xf3, 0x96, 0x45, 0x93, 0xde, 0x4a, 0x14, 0xe4, 0x1a, 0xb7,
+	0xfc, 0xc5, 0xa0, 0xf4, 0x2d, 0x54, 0x65, 0xcb, 0xf2, 0x4c, 0x2b, 0xd8, 0x14, 0x73, 0xe5, 0x64,
+	0xbb, 0xee, 0x2f, 0xf5, 0x94, 0xf9, 0x45, 0xa9, 0xfe, 0xdd, 0x83, 0x4d, 0xd3, 0x31, 0xdc, 0x89,
+	0xa9, 0x3a, 0x9a, 0xe3, 0x86, 0x2d, 0x2c, 0xe0, 0x4a, 0x18, 0x1b, 0x06, 0x21, 0xe9, 0xf7, 0x32,
+	0xdc, 0x59, 0x76, 0x5e, 0x1f, 0x41, 0x91, 0xbc, 0x9c, 0x85, 0x93, 0x75, 0xfb, 0xa1, 0x14, 0x97,
+	0x5f, 0x02, 0x6e, 0x29, 0x14, 0x89, 0x19, 0x1e, 0x29, 0xb0, 0xcd, 0x77, 0x91, 0xfa, 0x9c, 0x62,
+	0xd4, 0xf4, 0xf9, 0x35, 0x33, 0x2b, 0x2c, 0x21, 0x85, 0x6b, 0xc6, 0xb2, 0x4d, 0xf8, 0x14, 0xea,
+	0x0b, 0xbb, 0x27, 0xad, 0x5c, 0x60, 0xca, 0xf7, 0x96, 0xad, 0xa2, 0xa4, 0xf8, 0x8e, 0x9e, 0xb3,
+	0xdb, 0x86, 0x50, 0x63, 0x4b, 0x22, 0xad, 0x5c, 0x64, 0xca, 0xbb, 0xa9, 0xbd, 0x92, 0x14, 0x45,
+	0x56, 0x76, 0x31, 0x3d, 0x83, 0xe6, 0x79, 0x34, 0xf4, 0xfc, 0xe1, 0x4a, 0x4a, 0x8b, 0x6b, 0x4c,
+	0xf9, 0xbd, 0xdc, 0x25, 0xb1, 0xa8, 0x87, 0x1b, 0xe7, 0xaf, 0x59, 0x3c, 0xb4, 0x37, 0x8b, 0x0f,
+	0x71, 0xaa, 0xce, 0x7a, 0xba, 0x37, 0x39, 0x13, 0x8a, 0x77, 0x48, 0xce, 0x1a, 0xd1, 0xa0, 0x91,
+	0xaf, 0xef, 0x8b, 0x25, 0x56, 0x40, 0x7a, 0x63, 0x01, 0x1f, 0x8b, 0x24, 0x6f, 0x39, 0x38, 0xb0,
+	0x97, 0x2d, 0x91, 0x9a, 0xac, 0xf2, 0xff, 0x99, 0x03, 0xbc, 0x4b, 0x5e, 0x37, 0xf7, 0x9f, 0x03,
+	0x62, 0xaf, 0x5e, 0xc3, 0xbd, 0x54, 0x2f, 0x34, 0x67, 0xe2, 0x5f, 0x68, 0x5f, 0x9b, 0xe2, 0x06,
+	0xab, 0xd0, 0x88, 0x2b, 0x9c, 0x70, 0x4c, 0x3f, 0x82, 0xe0, 0xad, 0x59, 0x3a, 0x24, 0xfd, 0xb8,
+	0x0a, 0xc5, 0xe0, 0xf9, 0x47, 0x15, 0x28, 0x9d, 0x0e, 0x1f, 0x0f, 0x47, 0x67, 0xc3, 0xea, 0x0a,
+	0xaa, 0xc3, 0x76, 0x67, 0x74, 0x3a, 0x54, 0x7a, 0x58, 0x3d, 0x1b, 0x28, 0x7d, 0xf5, 0xb8, 0xa7,
+	0xc8, 0x5d, 0x59, 0x91, 0xc7, 0x55, 0x01, 0x35, 0xa1, 0x7e, 0x20, 0x2b, 0x9d, 0xbe, 0xaa, 0x0c,
+	0x8e, 0xb3, 0xf9, 0x55, 0x24, 0x42, 0xed, 0x48, 0x3e, 0x3d, 0xea, 0xa5, 0x33, 0x05, 0x24, 0x41,
+	0xf3, 0x70, 0x84, 0xcf, 0x64, 0xdc, 0xed, 0x75, 0x83, 0x04, 0x1e, 0x74, 0x92, 0xa0, 0x6a, 0x31,
+	0x50, 0x0f, 0x74, 0x73, 0xf2, 0x6b, 0xe8, 0x2e, 0x34, 0xf2, 0xf3, 0xe3, 0xea, 0x3a, 0x7a, 0x17,
+	0xf6, 0xb2, 0x80, 0xb1, 0x32, 0xc2, 0x32, 0xb5, 0x74, 0x32, 0x7a, 0x32, 0xe8, 0x7c, 0x59, 0x2d,
+	0xa1, 0x6d, 0x40, 0x27, 0x78, 0xa4, 0x8c, 0x3a, 0xa3, 0x27, 0x6a, 0x5f, 0x1e, 0x76, 0xc7, 0x7d,
+	0xf9, 0x71, 0xaf, 0x5a, 0x0e, 0x5e, 0x93, 0x5b, 0x99, 0xbe, 0xd1, 0xa2, 0x95, 0xa9, 0xed, 0xa8,
+	0xdf, 0x98, 0x9e, 0x6f, 0xbb, 0x0e, 0x5b, 0x2a, 0xb7, 0x30, 0xd0, 0xd0, 0x17, 0x61, 0x84, 0x01,
+	0xb4, 0x17, 0x73, 0xc0, 0x2a, 0x07, 0x68, 0x2f, 0x22, 0x80, 0x08, 0xa5, 0x28, 0x59, 0x60, 0xc9,
+	0xe8, 0x96, 0x36, 0x65, 0xd3, 0xd0, 0x66, 0x9a, 0x6e, 0x5f, 0xda, 0xc4, 0x36, 0x83, 0x99, 0x2d,
+	0x3c, 0xd8, 0xc0, 0x89, 0xd8, 0xc1, 0xe0, 0xd5, 0xdf, 0x4d, 0xe1, 0x0f, 0xfa, 0xf9, 0x8b, 0x7e,
+	0x7e, 0xfe, 0xa7, 0xb9, 0xf2, 0xd5, 0xa3, 0x6b, 0xfe, 0x05, 0xd4, 0xd7, 0xd9, 0xfd, 0xfe, 0xbf,
+	0xf2, 0xc4, 0x6d, 0x1f, 0x0c, 0x0b, 0x00, 0x00,
 }
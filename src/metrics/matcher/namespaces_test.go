@@ -395,3 +395,4 @@ type mockValue struct {
 func (v mockValue) Unmarshal(proto.Message) error { return errors.New("unimplemented") }
 func (v mockValue) Version() int                  { return v.version }
 func (v mockValue) IsNewer(other kv.Value) bool   { return v.version > other.Version() }
+func (v mockValue) IsStale() bool                 { return false }
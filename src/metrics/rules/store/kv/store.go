@@ -188,7 +188,7 @@ func (s *store) namespacesTransaction(nss *rules.Namespaces) (kv.Condition, kv.O
 }
 
 func wrapWriteError(err error) error {
-	if err == kv.ErrConditionCheckFailed {
+	if errors.Is(err, kv.ErrConditionCheckFailed) {
 		return merrors.NewStaleDataError(
 			fmt.Sprintf("stale write request: %s", err.Error()),
 		)
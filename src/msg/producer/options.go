@@ -20,14 +20,21 @@
 
 package producer
 
+import (
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
 type producerOptions struct {
-	buffer Buffer
-	writer Writer
+	buffer           Buffer
+	writer           Writer
+	routingKeyHashFn RoutingKeyHashFn
 }
 
 // NewOptions creates a Options.
 func NewOptions() Options {
-	return &producerOptions{}
+	return &producerOptions{
+		routingKeyHashFn: murmur3.Sum32,
+	}
 }
 
 func (opts *producerOptions) Buffer() Buffer {
@@ -49,3 +56,13 @@ func (opts *producerOptions) SetWriter(value Writer) Options {
 	o.writer = value
 	return &o
 }
+
+func (opts *producerOptions) RoutingKeyHashFn() RoutingKeyHashFn {
+	return opts.routingKeyHashFn
+}
+
+func (opts *producerOptions) SetRoutingKeyHashFn(value RoutingKeyHashFn) Options {
+	o := *opts
+	o.routingKeyHashFn = value
+	return &o
+}
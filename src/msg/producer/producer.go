@@ -20,16 +20,25 @@
 
 package producer
 
+import (
+	"errors"
+)
+
+var errNoShardsToRoute = errors.New("could not route message by routing key: topic has no shards")
+
 type producer struct {
 	Buffer
 	Writer
+
+	routingKeyHashFn RoutingKeyHashFn
 }
 
 // NewProducer returns a new producer.
 func NewProducer(opts Options) Producer {
 	return &producer{
-		Buffer: opts.Buffer(),
-		Writer: opts.Writer(),
+		Buffer:           opts.Buffer(),
+		Writer:           opts.Writer(),
+		routingKeyHashFn: opts.RoutingKeyHashFn(),
 	}
 }
 
@@ -39,13 +48,38 @@ func (p *producer) Init() error {
 }
 
 func (p *producer) Produce(m Message) error {
+	shard, routed, err := p.routedShard(m)
+	if err != nil {
+		return err
+	}
 	rm, err := p.Buffer.Add(m)
 	if err != nil {
 		return err
 	}
+	if routed {
+		rm.routeToShard(shard)
+	}
 	return p.Writer.Write(rm)
 }
 
+// routedShard returns the shard a message with a routing key is routed to,
+// and false if the message should be routed to its own shard.
+func (p *producer) routedShard(m Message) (uint32, bool, error) {
+	rkm, ok := m.(RoutingKeyMessage)
+	if !ok || p.routingKeyHashFn == nil {
+		return 0, false, nil
+	}
+	key := rkm.RoutingKey()
+	if key == nil {
+		return 0, false, nil
+	}
+	numShards := p.Writer.NumShards()
+	if numShards == 0 {
+		return 0, false, errNoShardsToRoute
+	}
+	return p.routingKeyHashFn(key) % numShards, true, nil
+}
+
 func (p *producer) Close(ct CloseType) {
 	// NB: Must close buffer first, it will start returning errors on
 	// new writes immediately. Then if the close type is to wait for consumption
@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package producer
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testRoutingKeyMessage struct {
+	Message
+
+	key []byte
+}
+
+func (m testRoutingKeyMessage) RoutingKey() []byte {
+	return m.key
+}
+
+type testBuffer struct {
+	Buffer
+}
+
+func (b testBuffer) Add(m Message) (*RefCountedMessage, error) {
+	return NewRefCountedMessage(m, nil), nil
+}
+
+type testWriter struct {
+	Writer
+
+	numShards uint32
+	written   []*RefCountedMessage
+}
+
+func (w *testWriter) Write(rm *RefCountedMessage) error {
+	w.written = append(w.written, rm)
+	return nil
+}
+
+func (w *testWriter) NumShards() uint32 {
+	return w.numShards
+}
+
+func TestProducerRoutingKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	w := &testWriter{numShards: 8}
+	p := NewProducer(NewOptions().
+		SetBuffer(testBuffer{}).
+		SetWriter(w).
+		SetRoutingKeyHashFn(func(key []byte) uint32 { return uint32(len(key)) }))
+
+	mm := NewMockMessage(ctrl)
+	mm.EXPECT().Shard().Return(uint32(1)).AnyTimes()
+	mm.EXPECT().Size().Return(0).AnyTimes()
+
+	require.NoError(t, p.Produce(mm))
+	require.NoError(t, p.Produce(testRoutingKeyMessage{Message: mm}))
+	require.NoError(t, p.Produce(testRoutingKeyMessage{Message: mm, key: []byte("tenant-a")}))
+	require.NoError(t, p.Produce(testRoutingKeyMessage{Message: mm, key: []byte("tenant-abc")}))
+
+	require.Equal(t, 4, len(w.written))
+	require.Equal(t, uint32(1), w.written[0].Shard())
+	require.Equal(t, uint32(1), w.written[1].Shard())
+	require.Equal(t, uint32(0), w.written[2].Shard())
+	require.Equal(t, uint32(2), w.written[3].Shard())
+}
+
+func TestProducerRoutingKeyNoShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	w := &testWriter{}
+	p := NewProducer(NewOptions().SetBuffer(testBuffer{}).SetWriter(w))
+
+	mm := NewMockMessage(ctrl)
+	err := p.Produce(testRoutingKeyMessage{Message: mm, key: []byte("tenant-a")})
+	require.Equal(t, errNoShardsToRoute, err)
+	require.Equal(t, 0, len(w.written))
+}
@@ -39,6 +39,8 @@ type RefCountedMessage struct {
 	refCount            atomic.Int32
 	isDroppedOrConsumed atomic.Bool
 	mu                  sync.RWMutex
+	routedShard         uint32
+	routed              bool
 }
 
 // NewRefCountedMessage creates RefCountedMessage.
@@ -87,6 +89,22 @@ func (rm *RefCountedMessage) NumRef() int32 {
 	return rm.refCount.Load()
 }
 
+// Shard returns the shard the message is routed to, which is the shard of
+// the message unless it was overridden by a routing key.
+func (rm *RefCountedMessage) Shard() uint32 {
+	if rm.routed {
+		return rm.routedShard
+	}
+	return rm.Message.Shard()
+}
+
+// routeToShard overrides the shard of the message, which must be done
+// before the message is written.
+func (rm *RefCountedMessage) routeToShard(shard uint32) {
+	rm.routedShard = shard
+	rm.routed = true
+}
+
 // Size returns the size of the message.
 func (rm *RefCountedMessage) Size() uint64 {
 	return rm.size
@@ -51,6 +51,21 @@ type Message interface {
 	Finalize(FinalizeReason)
 }
 
+// RoutingKeyMessage is a message that can override the shard it is routed to
+// with a routing key that is separate from its ID, e.g. to route all metrics
+// of a tenant to the same consumer shard for locality.
+type RoutingKeyMessage interface {
+	Message
+
+	// RoutingKey returns the key the message is routed by, or nil if the
+	// message should be routed to its own shard.
+	RoutingKey() []byte
+}
+
+// RoutingKeyHashFn hashes a routing key, the message is routed to the shard
+// of the hash modulo the number of shards of the topic.
+type RoutingKeyHashFn func(key []byte) uint32
+
 // CloseType decides how the producer should be closed.
 type CloseType int
 
@@ -101,6 +116,12 @@ type Options interface {
 
 	// SetWriter sets the writer.
 	SetWriter(value Writer) Options
+
+	// RoutingKeyHashFn returns the hash function for routing keys.
+	RoutingKeyHashFn() RoutingKeyHashFn
+
+	// SetRoutingKeyHashFn sets the hash function for routing keys.
+	SetRoutingKeyHashFn(value RoutingKeyHashFn) Options
 }
 
 // Buffer buffers all the messages in the producer.
@@ -0,0 +1,125 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package accesscontrol enforces tag matchers on queries based on the
+// identity of the requester.
+package accesscontrol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+)
+
+type key int
+
+const identityKey key = iota
+
+// NewContext returns a new context with the identity of the requester.
+func NewContext(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// IdentityFromContext extracts the identity of the requester, or false if it
+// doesn't exist.
+func IdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(identityKey).(string)
+	return identity, ok
+}
+
+// Policy determines the tag matchers that are enforced on the queries of
+// each identity.
+type Policy struct {
+	// Identities are the matchers enforced on queries keyed by identity.
+	Identities map[string]models.Matchers
+	// Default are the matchers enforced on queries from identities that are
+	// not listed in Identities, including requests without an identity.
+	Default models.Matchers
+	// DenyUnlisted rejects queries from identities that are not listed in
+	// Identities, including requests without an identity.
+	DenyUnlisted bool
+}
+
+// Matchers returns the matchers that must be enforced on the queries of the
+// identity in the context.
+func (p *Policy) Matchers(ctx context.Context) (models.Matchers, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if ok {
+		if matchers, listed := p.Identities[identity]; listed {
+			return matchers, nil
+		}
+	}
+
+	if p.DenyUnlisted {
+		if !ok {
+			return nil, xhttp.NewError(
+				fmt.Errorf("query access denied: no identity"),
+				http.StatusForbidden)
+		}
+		return nil, xhttp.NewError(
+			fmt.Errorf("query access denied: identity %s not permitted", identity),
+			http.StatusForbidden)
+	}
+
+	return p.Default, nil
+}
+
+// Enforce restricts the fetch options to series that match all of the
+// given matchers. Matchers on the same tag names in the query are replaced
+// and any tag restrictions already present in the fetch options still apply,
+// so neither the query nor restrict by tags headers can widen the results.
+func Enforce(opts *storage.FetchOptions, matchers models.Matchers) {
+	if len(matchers) == 0 {
+		return
+	}
+
+	var (
+		existing = opts.RestrictQueryOptions.GetRestrictByTag()
+		restrict = &storage.RestrictByTag{
+			// Enforced tags are not stripped from results, keep only the
+			// tags stripped by any existing restrictions.
+			Strip: [][]byte{},
+		}
+	)
+	if existing != nil {
+		restrict.Restrict = make(models.Matchers, 0,
+			len(existing.Restrict)+len(matchers))
+		restrict.Restrict = append(restrict.Restrict, existing.Restrict...)
+		if existing.Strip != nil {
+			restrict.Strip = append(restrict.Strip, existing.Strip...)
+		} else {
+			for _, m := range existing.Restrict {
+				restrict.Strip = append(restrict.Strip, m.Name)
+			}
+		}
+	}
+	restrict.Restrict = append(restrict.Restrict, matchers...)
+
+	var restrictOpts storage.RestrictQueryOptions
+	if opts.RestrictQueryOptions != nil {
+		restrictOpts = *opts.RestrictQueryOptions
+	}
+	restrictOpts.RestrictByTag = restrict
+	opts.RestrictQueryOptions = &restrictOpts
+}
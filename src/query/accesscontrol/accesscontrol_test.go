@@ -0,0 +1,257 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package accesscontrol
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustMatcher(t *testing.T, matchType models.MatchType, name, value string) models.Matcher {
+	m, err := models.NewMatcher(matchType, []byte(name), []byte(value))
+	require.NoError(t, err)
+	return m
+}
+
+// matches evaluates the matchers against the tags the same way storage does.
+func matches(t *testing.T, matchers models.Matchers, tags map[string]string) bool {
+	for _, m := range matchers {
+		value, ok := tags[string(m.Name)]
+		switch m.Type {
+		case models.MatchEqual:
+			if !ok || !bytes.Equal(m.Value, []byte(value)) {
+				return false
+			}
+		case models.MatchNotEqual:
+			if ok && bytes.Equal(m.Value, []byte(value)) {
+				return false
+			}
+		case models.MatchRegexp, models.MatchNotRegexp:
+			re, err := regexp.Compile("^(?:" + string(m.Value) + ")$")
+			require.NoError(t, err)
+			if re.MatchString(value) != (m.Type == models.MatchRegexp) {
+				return false
+			}
+		case models.MatchField:
+			if !ok {
+				return false
+			}
+		case models.MatchNotField:
+			if ok {
+				return false
+			}
+		default:
+			require.FailNow(t, "unexpected matcher type", m.Type.String())
+		}
+	}
+	return true
+}
+
+func TestPolicyMatchers(t *testing.T) {
+	var (
+		teamX    = models.Matchers{mustMatcher(t, models.MatchEqual, "team", "X")}
+		teamNone = models.Matchers{mustMatcher(t, models.MatchNotField, "team", "")}
+		policy   = &Policy{
+			Identities: map[string]models.Matchers{"alice": teamX},
+			Default:    teamNone,
+		}
+	)
+
+	matchers, err := policy.Matchers(NewContext(context.Background(), "alice"))
+	require.NoError(t, err)
+	assert.Equal(t, teamX, matchers)
+
+	matchers, err = policy.Matchers(NewContext(context.Background(), "bob"))
+	require.NoError(t, err)
+	assert.Equal(t, teamNone, matchers)
+
+	matchers, err = policy.Matchers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, teamNone, matchers)
+
+	policy.DenyUnlisted = true
+	for _, ctx := range []context.Context{
+		NewContext(context.Background(), "bob"),
+		context.Background(),
+	} {
+		_, err = policy.Matchers(ctx)
+		require.Error(t, err)
+		httpErr, ok := err.(xhttp.Error) // nolint:errorlint
+		require.True(t, ok)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code())
+	}
+}
+
+func TestEnforceBypassAttempts(t *testing.T) {
+	var (
+		teamX    = mustMatcher(t, models.MatchEqual, "team", "X")
+		seriesX  = map[string]string{"__name__": "up", "team": "X"}
+		seriesY  = map[string]string{"__name__": "up", "team": "Y"}
+		seriesXY = map[string]string{"__name__": "up", "team": "XY"}
+		noTeam   = map[string]string{"__name__": "up"}
+	)
+
+	tests := []struct {
+		name        string
+		enforced    models.Matchers
+		query       models.Matchers
+		restrict    *storage.RestrictByTag
+		expectMatch []map[string]string
+	}{
+		{
+			name:        "no tag matcher",
+			enforced:    models.Matchers{teamX},
+			query:       models.Matchers{mustMatcher(t, models.MatchEqual, "__name__", "up")},
+			expectMatch: []map[string]string{seriesX},
+		},
+		{
+			name:        "match all regexp",
+			enforced:    models.Matchers{teamX},
+			query:       models.Matchers{mustMatcher(t, models.MatchRegexp, "team", ".*")},
+			expectMatch: []map[string]string{seriesX},
+		},
+		{
+			name:        "alternation regexp",
+			enforced:    models.Matchers{teamX},
+			query:       models.Matchers{mustMatcher(t, models.MatchRegexp, "team", "X|Y")},
+			expectMatch: []map[string]string{seriesX},
+		},
+		{
+			name:        "other team regexp",
+			enforced:    models.Matchers{teamX},
+			query:       models.Matchers{mustMatcher(t, models.MatchRegexp, "team", "Y")},
+			expectMatch: []map[string]string{seriesX},
+		},
+		{
+			name:        "negated equal",
+			enforced:    models.Matchers{teamX},
+			query:       models.Matchers{mustMatcher(t, models.MatchNotEqual, "team", "X")},
+			expectMatch: []map[string]string{seriesX},
+		},
+		{
+			name:        "negated regexp",
+			enforced:    models.Matchers{teamX},
+			query:       models.Matchers{mustMatcher(t, models.MatchNotRegexp, "team", "X")},
+			expectMatch: []map[string]string{seriesX},
+		},
+		{
+			name:        "tag not exists",
+			enforced:    models.Matchers{teamX},
+			query:       models.Matchers{mustMatcher(t, models.MatchNotField, "team", "")},
+			expectMatch: []map[string]string{seriesX},
+		},
+		{
+			name:     "restrict header match all regexp",
+			enforced: models.Matchers{teamX},
+			restrict: &storage.RestrictByTag{
+				Restrict: models.Matchers{mustMatcher(t, models.MatchRegexp, "team", ".*")},
+			},
+			expectMatch: []map[string]string{seriesX},
+		},
+		{
+			name:     "restrict header other team",
+			enforced: models.Matchers{teamX},
+			restrict: &storage.RestrictByTag{
+				Restrict: models.Matchers{mustMatcher(t, models.MatchEqual, "team", "Y")},
+			},
+		},
+		{
+			name:        "enforced regexp is anchored",
+			enforced:    models.Matchers{mustMatcher(t, models.MatchRegexp, "team", "X")},
+			query:       models.Matchers{mustMatcher(t, models.MatchRegexp, "team", "X.*")},
+			expectMatch: []map[string]string{seriesX},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := storage.NewFetchOptions()
+			if tt.restrict != nil {
+				opts.RestrictQueryOptions = &storage.RestrictQueryOptions{
+					RestrictByTag: tt.restrict,
+				}
+			}
+			Enforce(opts, tt.enforced)
+
+			query := (&storage.FetchQuery{TagMatchers: tt.query}).
+				WithAppliedOptions(opts)
+			for _, series := range []map[string]string{seriesX, seriesY, seriesXY, noTeam} {
+				expected := false
+				for _, s := range tt.expectMatch {
+					if assert.ObjectsAreEqual(s, series) {
+						expected = true
+					}
+				}
+				assert.Equal(t, expected, matches(t, query.TagMatchers, series),
+					"series %v, matchers %v", series, query.TagMatchers)
+			}
+		})
+	}
+}
+
+func TestEnforceKeepsExistingRestrictions(t *testing.T) {
+	var (
+		teamX    = mustMatcher(t, models.MatchEqual, "team", "X")
+		envProd  = mustMatcher(t, models.MatchEqual, "env", "prod")
+		existing = &storage.RestrictByTag{
+			Restrict: models.Matchers{envProd},
+		}
+		opts = storage.NewFetchOptions()
+	)
+	opts.RestrictQueryOptions = &storage.RestrictQueryOptions{
+		RestrictByTag: existing,
+	}
+
+	Enforce(opts, models.Matchers{teamX})
+
+	restrict := opts.RestrictQueryOptions.GetRestrictByTag()
+	assert.Equal(t, models.Matchers{envProd, teamX}, restrict.GetMatchers())
+	// The existing restrictions stripped their own tags and enforced tags
+	// are not stripped.
+	assert.Equal(t, [][]byte{[]byte("env")}, restrict.GetFilterByNames())
+
+	// The existing restrictions, which may be shared defaults, are unchanged.
+	assert.Equal(t, models.Matchers{envProd}, existing.Restrict)
+	assert.Nil(t, existing.Strip)
+}
+
+func TestEnforceWithoutExistingRestrictions(t *testing.T) {
+	teamX := mustMatcher(t, models.MatchEqual, "team", "X")
+	opts := storage.NewFetchOptions()
+
+	Enforce(opts, nil)
+	assert.Nil(t, opts.RestrictQueryOptions)
+
+	Enforce(opts, models.Matchers{teamX})
+	restrict := opts.RestrictQueryOptions.GetRestrictByTag()
+	assert.Equal(t, models.Matchers{teamX}, restrict.GetMatchers())
+	assert.Empty(t, restrict.GetFilterByNames())
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package analytics contains API endpoints reporting how series use storage.
+package analytics
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/cardinality"
+	"github.com/m3db/m3/src/query/storage/m3"
+	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/instrument"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+)
+
+const (
+	// CardinalityURL is the url to report the cardinality and churn of the
+	// series of namespaces.
+	CardinalityURL = route.Prefix + "/analytics/cardinality"
+
+	// CardinalityHTTPMethod is the HTTP method used with this resource.
+	CardinalityHTTPMethod = http.MethodGet
+
+	// NamespaceParam is the query param of the namespaces to report on, it
+	// can be repeated and defaults to all namespaces.
+	NamespaceParam = "namespace"
+)
+
+var errNoNamespaces = errors.New("no namespaces to report on")
+
+// CardinalityResponse is the response of a cardinality report request.
+type CardinalityResponse struct {
+	// Namespaces are the reports of each requested namespace.
+	Namespaces []cardinality.Report `json:"namespaces"`
+}
+
+type cardinalityHandler struct {
+	analyzer            *cardinality.Analyzer
+	clusters            m3.Clusters
+	fetchOptionsBuilder handleroptions.FetchOptionsBuilder
+	instrumentOpts      instrument.Options
+}
+
+// NewCardinalityHandler returns a new handler that reports the top label
+// names by distinct values, the top metric names by series and the hourly
+// series churn of namespaces.
+func NewCardinalityHandler(opts options.HandlerOptions) http.Handler {
+	analyzerOpts := opts.Config().Query.CardinalityAnalytics.AnalyzerOptions()
+	analyzerOpts.Storage = opts.Storage()
+	analyzerOpts.TagOptions = opts.TagOptions()
+	analyzerOpts.NowFn = opts.NowFn()
+	return &cardinalityHandler{
+		analyzer:            cardinality.NewAnalyzer(analyzerOpts),
+		clusters:            opts.Clusters(),
+		fetchOptionsBuilder: opts.FetchOptionsBuilder(),
+		instrumentOpts:      opts.InstrumentOpts(),
+	}
+}
+
+func (h *cardinalityHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context(), h.instrumentOpts)
+
+	ctx, fetchOpts, err := h.fetchOptionsBuilder.NewFetchOptions(r.Context(), r)
+	if err != nil {
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	namespaces := r.URL.Query()[NamespaceParam]
+	if len(namespaces) == 0 {
+		namespaces = h.namespaces()
+	}
+	if len(namespaces) == 0 {
+		xhttp.WriteError(w, xerrors.NewInvalidParamsError(errNoNamespaces))
+		return
+	}
+
+	reports := make([]cardinality.Report, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		report, err := h.analyzer.Report(ctx, namespace, fetchOpts)
+		if err != nil {
+			logger.Error("unable to report cardinality",
+				zap.String("namespace", namespace), zap.Error(err))
+			xhttp.WriteError(w, err)
+			return
+		}
+		reports = append(reports, report)
+	}
+
+	xhttp.WriteJSONResponse(w, CardinalityResponse{Namespaces: reports}, logger)
+}
+
+// namespaces returns the IDs of all ready namespaces.
+func (h *cardinalityHandler) namespaces() []string {
+	if h.clusters == nil {
+		return nil
+	}
+
+	var (
+		seen       = make(map[string]struct{})
+		namespaces []string
+	)
+	for _, ns := range h.clusters.ClusterNamespaces() {
+		id := ns.NamespaceID().String()
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		namespaces = append(namespaces, id)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
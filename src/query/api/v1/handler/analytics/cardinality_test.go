@@ -0,0 +1,153 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package analytics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
+	"github.com/m3db/m3/src/query/storage/m3/consolidators"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHandler(
+	t *testing.T,
+	ctrl *gomock.Controller,
+	clusters m3.Clusters,
+) (http.Handler, *storage.MockStorage) {
+	store := storage.NewMockStorage(ctrl)
+	fb, err := handleroptions.NewFetchOptionsBuilder(
+		handleroptions.FetchOptionsBuilderOptions{Timeout: 15 * time.Second})
+	require.NoError(t, err)
+
+	opts := options.EmptyHandlerOptions().
+		SetStorage(store).
+		SetFetchOptionsBuilder(fb).
+		SetTagOptions(models.NewTagOptions()).
+		SetClusters(clusters)
+	return NewCardinalityHandler(opts), store
+}
+
+type restrictedMatcher struct {
+	namespace string
+}
+
+func (m restrictedMatcher) String() string {
+	return "fetch options restricted to namespace " + m.namespace
+}
+
+func (m restrictedMatcher) Matches(x interface{}) bool {
+	opts, ok := x.(*storage.FetchOptions)
+	if !ok {
+		return false
+	}
+	restrict := opts.RestrictQueryOptions.GetRestrictByNamespace()
+	return restrict != nil && restrict.Namespace == m.namespace
+}
+
+var _ gomock.Matcher = restrictedMatcher{}
+
+func expectEmptyReports(store *storage.MockStorage, namespaces ...string) {
+	for _, namespace := range namespaces {
+		restricted := restrictedMatcher{namespace: namespace}
+		store.EXPECT().
+			CompleteTags(gomock.Any(), gomock.Any(), restricted).
+			Return(&consolidators.CompleteTagsResult{
+				Metadata: block.NewResultMetadata(),
+			}, nil)
+		store.EXPECT().
+			SearchSeries(gomock.Any(), gomock.Any(), restricted).
+			Return(&storage.SearchResults{
+				Metadata: block.NewResultMetadata(),
+			}, nil).
+			AnyTimes()
+	}
+}
+
+func serve(t *testing.T, h http.Handler, target string) (int, CardinalityResponse) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(CardinalityHTTPMethod, target, nil))
+
+	var resp CardinalityResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func TestCardinalityHandlerNamespaceParam(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, store := newTestHandler(t, ctrl, nil)
+	expectEmptyReports(store, "foo", "bar")
+
+	code, resp := serve(t, h, CardinalityURL+"?namespace=foo&namespace=bar")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Namespaces, 2)
+	require.Equal(t, "foo", resp.Namespaces[0].Namespace)
+	require.Equal(t, "bar", resp.Namespaces[1].Namespace)
+	require.True(t, resp.Namespaces[0].Exhaustive)
+}
+
+func TestCardinalityHandlerAllNamespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var namespaces m3.ClusterNamespaces
+	for _, id := range []string{"unagg", "agg", "unagg"} {
+		ns := m3.NewMockClusterNamespace(ctrl)
+		ns.EXPECT().NamespaceID().Return(ident.StringID(id)).AnyTimes()
+		namespaces = append(namespaces, ns)
+	}
+	clusters := m3.NewMockClusters(ctrl)
+	clusters.EXPECT().ClusterNamespaces().Return(namespaces)
+
+	h, store := newTestHandler(t, ctrl, clusters)
+	expectEmptyReports(store, "agg", "unagg")
+
+	code, resp := serve(t, h, CardinalityURL)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Namespaces, 2)
+	require.Equal(t, "agg", resp.Namespaces[0].Namespace)
+	require.Equal(t, "unagg", resp.Namespaces[1].Namespace)
+}
+
+func TestCardinalityHandlerNoNamespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, _ := newTestHandler(t, ctrl, nil)
+	code, _ := serve(t, h, CardinalityURL)
+	require.Equal(t, http.StatusBadRequest, code)
+}
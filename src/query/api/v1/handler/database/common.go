@@ -57,6 +57,8 @@ func RegisterRoutes(
 	}
 
 	kvStoreHandler := NewKeyValueStoreHandler(client, instrumentOpts, kvStoreProtoParser)
+	kvHistoryHandler := NewKeyValueHistoryHandler(client, instrumentOpts, kvStoreProtoParser)
+	kvRollbackHandler := NewKeyValueRollbackHandler(client, instrumentOpts, kvStoreProtoParser)
 
 	// Register the same handler under two different endpoints. This just makes explaining things in
 	// our documentation easier so we can separate out concepts, but share the underlying code.
@@ -81,6 +83,20 @@ func RegisterRoutes(
 	}); err != nil {
 		return err
 	}
+	if err := r.Register(queryhttp.RegisterOptions{
+		Path:    KeyValueHistoryURL,
+		Handler: kvHistoryHandler,
+		Methods: []string{KeyValueHistoryHTTPMethod},
+	}); err != nil {
+		return err
+	}
+	if err := r.Register(queryhttp.RegisterOptions{
+		Path:    KeyValueRollbackURL,
+		Handler: kvRollbackHandler,
+		Methods: []string{KeyValueRollbackHTTPMethod},
+	}); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -180,7 +180,8 @@ func testLocalType(t *testing.T, providedType string, placementExists bool) {
 						"snapshotEnabled": true,
 						"indexOptions": {
 							"enabled": true,
-							"blockSizeNanos": "3600000000000"
+							"blockSizeNanos": "3600000000000",
+							"excludedTags": []
 						},
 						"runtimeOptions": null,
 						"schemaOptions": null,
@@ -356,7 +357,8 @@ func TestLocalTypeWithNumShards(t *testing.T) {
 						"snapshotEnabled": true,
 						"indexOptions": {
 							"enabled": true,
-							"blockSizeNanos": "3600000000000"
+							"blockSizeNanos": "3600000000000",
+							"excludedTags": []
 						},
 						"runtimeOptions": null,
 						"schemaOptions": null,
@@ -485,7 +487,8 @@ func TestLocalWithBlockSizeNanos(t *testing.T) {
 						"snapshotEnabled": true,
 						"indexOptions": {
 							"enabled": true,
-							"blockSizeNanos": "10800000000000"
+							"blockSizeNanos": "10800000000000",
+							"excludedTags": []
 						},
 						"runtimeOptions": null,
 						"schemaOptions": null,
@@ -619,7 +622,8 @@ func TestLocalWithBlockSizeExpectedSeriesDatapointsPerHour(t *testing.T) {
 						"snapshotEnabled": true,
 						"indexOptions": {
 							"enabled": true,
-							"blockSizeNanos": "%d"
+							"blockSizeNanos": "%d",
+							"excludedTags": []
 						},
 						"runtimeOptions": null,
 						"schemaOptions": null,
@@ -883,7 +887,8 @@ func testClusterTypeHosts(t *testing.T, placementExists bool) {
 						"snapshotEnabled": true,
 						"indexOptions": {
 							"enabled": true,
-							"blockSizeNanos": "3600000000000"
+							"blockSizeNanos": "3600000000000",
+							"excludedTags": []
 						},
 						"runtimeOptions": null,
 						"schemaOptions": null,
@@ -1040,7 +1045,8 @@ func TestClusterTypeHostsWithIsolationGroup(t *testing.T) {
 						"snapshotEnabled": true,
 						"indexOptions": {
 							"enabled": true,
-							"blockSizeNanos": "3600000000000"
+							"blockSizeNanos": "3600000000000",
+							"excludedTags": []
 						},
 						"runtimeOptions": null,
 						"schemaOptions": null,
@@ -1259,7 +1265,8 @@ func TestLocalTypeWithAggregatedNamespace(t *testing.T) {
 						"snapshotEnabled": true,
 						"indexOptions": {
 							"enabled": true,
-							"blockSizeNanos": "3600000000000"
+							"blockSizeNanos": "3600000000000",
+							"excludedTags": []
 						},
 						"runtimeOptions": null,
 						"schemaOptions": null,
@@ -1301,7 +1308,8 @@ func TestLocalTypeWithAggregatedNamespace(t *testing.T) {
 						"snapshotEnabled": true,
 						"indexOptions": {
 							"enabled": true,
-							"blockSizeNanos": "86400000000000"
+							"blockSizeNanos": "86400000000000",
+							"excludedTags": []
 						},
 						"runtimeOptions": null,
 						"schemaOptions": null,
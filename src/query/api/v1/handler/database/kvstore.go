@@ -36,6 +36,7 @@ import (
 	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
 	"github.com/m3db/m3/src/cluster/generated/proto/kvpb"
 	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
 	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
 	"github.com/m3db/m3/src/dbnode/kvconfig"
 	"github.com/m3db/m3/src/query/api/v1/options"
@@ -216,6 +217,8 @@ func (h *KeyValueStoreHandler) newKVProtoMessage(key string) (protoiface.Message
 		return &commonpb.StringProto{}, nil
 	case kvconfig.QueryLimits:
 		return &kvpb.QueryLimits{}, nil
+	case ingest.NamespaceProvisioningApprovalsKey:
+		return &commonpb.StringArrayProto{}, nil
 	}
 	return nil, fmt.Errorf("unsupported kvstore key %s", key)
 }
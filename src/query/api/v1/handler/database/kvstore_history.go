@@ -0,0 +1,374 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/runtime/protoiface"
+
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/instrument"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+)
+
+const (
+	// KeyValueHistoryURL is the url to list past versions of a key's value.
+	KeyValueHistoryURL = route.Prefix + "/kvstore/history"
+	// KeyValueHistoryHTTPMethod is the HTTP method used with this resource.
+	KeyValueHistoryHTTPMethod = http.MethodGet
+
+	// KeyValueRollbackURL is the url to roll a key back to a past version.
+	KeyValueRollbackURL = route.Prefix + "/kvstore/rollback"
+	// KeyValueRollbackHTTPMethod is the HTTP method used with this resource.
+	KeyValueRollbackHTTPMethod = http.MethodPost
+
+	keyParam  = "key"
+	fromParam = "from"
+	toParam   = "to"
+)
+
+var errKeyValueVersionNotFound = xhttp.NewError(
+	errors.New("unable to find the specified version of the key"),
+	http.StatusNotFound)
+
+// KeyValueHistoryResult defines the past versions of a key's value.
+type KeyValueHistoryResult struct {
+	// Key the versions are of.
+	Key string `json:"key"`
+	// Versions of the key in ascending order.
+	Versions []KeyValueVersion `json:"versions"`
+}
+
+// KeyValueVersion defines a single version of a key's value.
+type KeyValueVersion struct {
+	// Version of the key.
+	Version int `json:"version"`
+	// Value of the key at the version.
+	Value json.RawMessage `json:"value"`
+}
+
+// KeyValueRollback defines a rollback of a key to a past version.
+type KeyValueRollback struct {
+	// Key to roll back.
+	Key string `json:"key"`
+	// Version to roll the key back to.
+	Version int `json:"version"`
+	// Commit, if false, will not persist the rollback. If true, the
+	// rollback will be persisted. Used to preview the rollback.
+	Commit bool `json:"commit"`
+}
+
+// KeyValueHistoryHandler represents a handler for the key/value history
+// endpoint.
+type KeyValueHistoryHandler struct {
+	kvStoreHandler *KeyValueStoreHandler
+}
+
+// NewKeyValueHistoryHandler returns a new instance of handler.
+func NewKeyValueHistoryHandler(
+	client clusterclient.Client,
+	instrumentOpts instrument.Options,
+	kvStoreProtoParser options.KVStoreProtoParser,
+) http.Handler {
+	return &KeyValueHistoryHandler{
+		kvStoreHandler: &KeyValueStoreHandler{
+			client:             client,
+			instrumentOpts:     instrumentOpts,
+			kvStoreProtoParser: kvStoreProtoParser,
+		},
+	}
+}
+
+func (h *KeyValueHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context(), h.kvStoreHandler.instrumentOpts)
+
+	key, from, to, err := parseHistoryParams(r)
+	if err != nil {
+		logger.Error("unable to parse request", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	kvStore, err := h.kvStoreHandler.client.KV()
+	if err != nil {
+		logger.Error("unable to get kv store", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	result, err := h.history(kvStore, key, from, to)
+	if err != nil {
+		logger.Error("kv store error", zap.Error(err), zap.String("key", key))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	xhttp.WriteJSONResponse(w, result, logger)
+}
+
+// parseHistoryParams parses the key and the version range [from, to), which
+// defaults to all versions of the key, versions start at 1.
+func parseHistoryParams(r *http.Request) (string, int, int, error) {
+	key := r.FormValue(keyParam)
+	if key == "" {
+		return "", 0, 0, xerrors.NewInvalidParamsError(
+			fmt.Errorf("%s must be specified", keyParam))
+	}
+
+	from, to := 1, -1
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{
+		{name: fromParam, value: &from},
+		{name: toParam, value: &to},
+	} {
+		str := r.FormValue(param.name)
+		if str == "" {
+			continue
+		}
+		v, err := strconv.Atoi(str)
+		if err != nil || v <= 0 {
+			return "", 0, 0, xerrors.NewInvalidParamsError(
+				fmt.Errorf("%s must be a positive integer: %s", param.name, str))
+		}
+		*param.value = v
+	}
+
+	return key, from, to, nil
+}
+
+func (h *KeyValueHistoryHandler) history(
+	kvStore kv.Store,
+	key string,
+	from, to int,
+) (*KeyValueHistoryResult, error) {
+	if to < 0 {
+		latest, err := kvStore.Get(key)
+		if err != nil {
+			return nil, notFoundToHTTPError(err)
+		}
+		to = latest.Version() + 1
+	}
+	if from > to {
+		return nil, xerrors.NewInvalidParamsError(
+			fmt.Errorf("%s must not be after %s", fromParam, toParam))
+	}
+
+	values, err := kvStore.History(key, from, to)
+	if err != nil {
+		return nil, notFoundToHTTPError(err)
+	}
+
+	result := &KeyValueHistoryResult{
+		Key:      key,
+		Versions: make([]KeyValueVersion, 0, len(values)),
+	}
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		marshalled, err := h.kvStoreHandler.marshalValue(key, value)
+		if err != nil {
+			return nil, err
+		}
+		result.Versions = append(result.Versions, KeyValueVersion{
+			Version: value.Version(),
+			Value:   marshalled,
+		})
+	}
+
+	return result, nil
+}
+
+// KeyValueRollbackHandler represents a handler for the key/value rollback
+// endpoint.
+type KeyValueRollbackHandler struct {
+	kvStoreHandler *KeyValueStoreHandler
+}
+
+// NewKeyValueRollbackHandler returns a new instance of handler.
+func NewKeyValueRollbackHandler(
+	client clusterclient.Client,
+	instrumentOpts instrument.Options,
+	kvStoreProtoParser options.KVStoreProtoParser,
+) http.Handler {
+	return &KeyValueRollbackHandler{
+		kvStoreHandler: &KeyValueStoreHandler{
+			client:             client,
+			instrumentOpts:     instrumentOpts,
+			kvStoreProtoParser: kvStoreProtoParser,
+		},
+	}
+}
+
+func (h *KeyValueRollbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context(), h.kvStoreHandler.instrumentOpts)
+
+	rollback, err := parseRollbackBody(r)
+	if err != nil {
+		logger.Error("unable to parse request", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	kvStore, err := h.kvStoreHandler.client.KV()
+	if err != nil {
+		logger.Error("unable to get kv store", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	result, err := h.rollback(logger, kvStore, rollback)
+	if err != nil {
+		logger.Error("kv store error",
+			zap.Error(err),
+			zap.Any("rollback", rollback))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	xhttp.WriteJSONResponse(w, result, logger)
+}
+
+func parseRollbackBody(r *http.Request) (*KeyValueRollback, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, xerrors.NewInvalidParamsError(err)
+	}
+	defer r.Body.Close()
+
+	var parsed KeyValueRollback
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, xerrors.NewInvalidParamsError(err)
+	}
+	if parsed.Key == "" {
+		return nil, xerrors.NewInvalidParamsError(errors.New("key must be specified"))
+	}
+	if parsed.Version <= 0 {
+		return nil, xerrors.NewInvalidParamsError(errors.New("version must be positive"))
+	}
+
+	return &parsed, nil
+}
+
+// rollback sets the key to its value at a past version, the rollback is
+// a new version of the key so it can itself be rolled back.
+func (h *KeyValueRollbackHandler) rollback(
+	logger *zap.Logger,
+	kvStore kv.Store,
+	rollback *KeyValueRollback,
+) (*KeyValueUpdateResult, error) {
+	current, err := kvStore.Get(rollback.Key)
+	if err != nil {
+		return nil, notFoundToHTTPError(err)
+	}
+
+	values, err := kvStore.History(rollback.Key, rollback.Version, rollback.Version+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 || values[0] == nil {
+		return nil, errKeyValueVersionNotFound
+	}
+	target := values[0]
+
+	oldMarshalled, err := h.kvStoreHandler.marshalValue(rollback.Key, current)
+	if err != nil {
+		return nil, err
+	}
+
+	targetProto, err := h.kvStoreHandler.newKVProtoMessage(rollback.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := target.Unmarshal(targetProto); err != nil {
+		return nil, err
+	}
+	newMarshalled, err := marshalProto(targetProto)
+	if err != nil {
+		return nil, err
+	}
+
+	var version int
+	if rollback.Commit {
+		// Only roll back from the version that was read so that concurrent
+		// updates are not silently overwritten.
+		version, err = kvStore.CheckAndSet(rollback.Key, current.Version(), targetProto)
+		if err != nil {
+			if errors.Is(err, kv.ErrVersionMismatch) {
+				return nil, xhttp.NewError(err, http.StatusConflict)
+			}
+			return nil, err
+		}
+	}
+
+	result := KeyValueUpdateResult{
+		Key:     rollback.Key,
+		Old:     oldMarshalled,
+		New:     newMarshalled,
+		Version: version,
+	}
+
+	logger.Info("kv store rollback", zap.Any("rollback", *rollback), zap.Any("result", result))
+
+	return &result, nil
+}
+
+func (h *KeyValueStoreHandler) marshalValue(key string, value kv.Value) (json.RawMessage, error) {
+	msg, err := h.newKVProtoMessage(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := value.Unmarshal(msg); err != nil {
+		return nil, err
+	}
+	return marshalProto(msg)
+}
+
+func marshalProto(msg protoiface.MessageV1) (json.RawMessage, error) {
+	marshalled := bytes.NewBuffer(nil)
+	if err := (&jsonpb.Marshaler{}).Marshal(marshalled, msg); err != nil {
+		return nil, err
+	}
+	return marshalled.Bytes(), nil
+}
+
+func notFoundToHTTPError(err error) error {
+	if errors.Is(err, kv.ErrNotFound) {
+		return xhttp.NewError(err, http.StatusNotFound)
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package database
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+)
+
+func newTestHistoryStore(t *testing.T, values ...int64) kv.Store {
+	store := mem.NewStore()
+	for _, v := range values {
+		_, err := store.Set(kvconfig.EncodersPerBlockLimitKey, &commonpb.Int64Proto{Value: v})
+		require.NoError(t, err)
+	}
+	return store
+}
+
+func TestKeyValueHistory(t *testing.T) {
+	var (
+		store   = newTestHistoryStore(t, 10, 20, 30)
+		handler = &KeyValueHistoryHandler{kvStoreHandler: &KeyValueStoreHandler{}}
+	)
+
+	result, err := handler.history(store, kvconfig.EncodersPerBlockLimitKey, 1, -1)
+	require.NoError(t, err)
+	require.Equal(t, &KeyValueHistoryResult{
+		Key: kvconfig.EncodersPerBlockLimitKey,
+		Versions: []KeyValueVersion{
+			{Version: 1, Value: json.RawMessage(`{"value":"10"}`)},
+			{Version: 2, Value: json.RawMessage(`{"value":"20"}`)},
+			{Version: 3, Value: json.RawMessage(`{"value":"30"}`)},
+		},
+	}, result)
+
+	result, err = handler.history(store, kvconfig.EncodersPerBlockLimitKey, 2, 3)
+	require.NoError(t, err)
+	require.Equal(t, []KeyValueVersion{
+		{Version: 2, Value: json.RawMessage(`{"value":"20"}`)},
+	}, result.Versions)
+
+	_, err = handler.history(store, kvconfig.EncodersPerBlockLimitKey, 3, 2)
+	require.Error(t, err)
+
+	_, err = handler.history(store, kvconfig.QueryLimits, 1, -1)
+	require.Error(t, err)
+}
+
+func TestKeyValueHistoryParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, KeyValueHistoryURL+"?key=foo&from=2", nil)
+	key, from, to, err := parseHistoryParams(req)
+	require.NoError(t, err)
+	require.Equal(t, "foo", key)
+	require.Equal(t, 2, from)
+	require.Equal(t, -1, to)
+
+	for _, query := range []string{"", "?from=1", "?key=foo&from=0", "?key=foo&from=bar"} {
+		req := httptest.NewRequest(http.MethodGet, KeyValueHistoryURL+query, nil)
+		_, _, _, err := parseHistoryParams(req)
+		require.Error(t, err, query)
+	}
+}
+
+func TestKeyValueRollback(t *testing.T) {
+	var (
+		store   = newTestHistoryStore(t, 10, 20, 30)
+		handler = &KeyValueRollbackHandler{kvStoreHandler: &KeyValueStoreHandler{}}
+	)
+
+	// Without commit the rollback is only previewed.
+	result, err := handler.rollback(zap.NewNop(), store, &KeyValueRollback{
+		Key:     kvconfig.EncodersPerBlockLimitKey,
+		Version: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &KeyValueUpdateResult{
+		Key: kvconfig.EncodersPerBlockLimitKey,
+		Old: json.RawMessage(`{"value":"30"}`),
+		New: json.RawMessage(`{"value":"10"}`),
+	}, result)
+
+	result, err = handler.rollback(zap.NewNop(), store, &KeyValueRollback{
+		Key:     kvconfig.EncodersPerBlockLimitKey,
+		Version: 1,
+		Commit:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Version)
+
+	value, err := store.Get(kvconfig.EncodersPerBlockLimitKey)
+	require.NoError(t, err)
+	require.Equal(t, 4, value.Version())
+	var limit commonpb.Int64Proto
+	require.NoError(t, value.Unmarshal(&limit))
+	require.Equal(t, int64(10), limit.Value)
+
+	// Rolling back to a version that doesn't exist is not found.
+	_, err = handler.rollback(zap.NewNop(), store, &KeyValueRollback{
+		Key:     kvconfig.EncodersPerBlockLimitKey,
+		Version: 5,
+		Commit:  true,
+	})
+	require.Error(t, err)
+	httpErr, ok := err.(xhttp.Error)
+	require.True(t, ok)
+	require.Equal(t, http.StatusNotFound, httpErr.Code())
+}
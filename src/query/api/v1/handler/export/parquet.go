@@ -0,0 +1,306 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package export contains handlers that export stored series in bulk.
+package export
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/instrument"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+	"github.com/m3db/m3/src/x/parquet"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ParquetURL is the url for the Parquet export handler.
+	ParquetURL = route.Prefix + "/export/parquet"
+
+	// ColumnParam maps a tag to a column, formatted as tag or tag:column. When
+	// not specified every tag of the exported series is mapped to a column of
+	// the same name.
+	ColumnParam = "column"
+
+	// TimestampColumn is the name of the timestamp column.
+	TimestampColumn = "timestamp"
+	// ValueColumn is the name of the value column.
+	ValueColumn = "value"
+
+	// ContentTypeParquet is the Content-Type value for a Parquet file.
+	ContentTypeParquet = "application/vnd.apache.parquet"
+
+	// seriesBatchSize is the number of series written to a row group before
+	// it is flushed to the response.
+	seriesBatchSize = 256
+)
+
+// ParquetHTTPMethods are the HTTP methods for this handler.
+var ParquetHTTPMethods = []string{http.MethodGet, http.MethodPost}
+
+// ParquetHandler exports the samples of the series matching the match[]
+// selectors between start and end as a Parquet file with a row per sample.
+// The selectors are first resolved against the index, which determines the
+// columns when no tags are mapped and the result metadata returned in the
+// response headers, then the samples are fetched a selector at a time and
+// streamed as a row group per batch of series.
+type ParquetHandler struct {
+	storage             storage.Storage
+	fetchOptionsBuilder handleroptions.FetchOptionsBuilder
+	parseOpts           promql.ParseOptions
+	tagOptions          models.TagOptions
+	instrumentOpts      instrument.Options
+}
+
+// NewParquetHandler returns a new Parquet export handler.
+func NewParquetHandler(opts options.HandlerOptions) http.Handler {
+	return &ParquetHandler{
+		storage:             opts.Storage(),
+		fetchOptionsBuilder: opts.FetchOptionsBuilder(),
+		parseOpts: promql.NewParseOptions().
+			SetRequireStartEndTime(true).
+			SetNowFn(opts.NowFn()),
+		tagOptions:     opts.TagOptions(),
+		instrumentOpts: opts.InstrumentOpts(),
+	}
+}
+
+// columnMapping maps a tag to a column.
+type columnMapping struct {
+	tag    string
+	column string
+}
+
+func (h *ParquetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, fetchOpts, rErr := h.fetchOptionsBuilder.NewFetchOptions(r.Context(), r)
+	if rErr != nil {
+		xhttp.WriteError(w, rErr)
+		return
+	}
+
+	logger := logging.WithContext(ctx, h.instrumentOpts)
+
+	queries, err := prometheus.ParseSeriesMatchQuery(r, h.parseOpts, h.tagOptions)
+	if err != nil {
+		logger.Error("unable to parse export query", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	mappings, err := parseColumnMappings(r.Form[ColumnParam])
+	if err != nil {
+		xhttp.WriteError(w, xerrors.NewInvalidParamsError(err))
+		return
+	}
+
+	var (
+		tagNames = make(map[string]struct{})
+		meta     = block.NewResultMetadata()
+	)
+	for _, query := range queries {
+		result, err := h.storage.CompleteTags(ctx, &storage.CompleteTagsQuery{
+			CompleteNameOnly: true,
+			TagMatchers:      query.TagMatchers,
+			Start:            xtime.ToUnixNano(query.Start),
+			End:              xtime.ToUnixNano(query.End),
+		}, fetchOpts)
+		if err != nil {
+			logger.Error("unable to resolve export series", zap.Error(err))
+			xhttp.WriteError(w, err)
+			return
+		}
+		for _, tag := range result.CompletedTags {
+			tagNames[string(tag.Name)] = struct{}{}
+		}
+		meta = meta.CombineMetadata(result.Metadata)
+	}
+
+	if len(mappings) == 0 {
+		mappings = tagColumnMappings(tagNames)
+	}
+	columns, columnIdxByTag, err := parquetColumns(mappings)
+	if err != nil {
+		xhttp.WriteError(w, xerrors.NewInvalidParamsError(err))
+		return
+	}
+
+	if err := handleroptions.AddDBResultResponseHeaders(w, meta, fetchOpts); err != nil {
+		logger.Error("error writing database limit headers", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	writer, err := parquet.NewWriter(w, columns, parquet.WriterOptions{})
+	if err != nil {
+		xhttp.WriteError(w, xerrors.NewInvalidParamsError(err))
+		return
+	}
+
+	// NB: the file is streamed so errors once a row group has been written can
+	// only be logged, clients detect a truncated response by the missing
+	// Parquet footer.
+	w.Header().Set(xhttp.HeaderContentType, ContentTypeParquet)
+
+	var (
+		flusher, _ = w.(http.Flusher)
+		row        = make([]parquet.Value, len(columns))
+		streaming  bool
+	)
+	for _, query := range queries {
+		result, err := h.storage.FetchProm(ctx, query, fetchOpts)
+		if err != nil {
+			logger.Error("unable to fetch export data", zap.Error(err))
+			if !streaming {
+				xhttp.WriteError(w, err)
+			}
+			return
+		}
+
+		series := result.PromResult.GetTimeseries()
+		for len(series) > 0 {
+			batch := series
+			if len(batch) > seriesBatchSize {
+				batch = batch[:seriesBatchSize]
+			}
+			series = series[len(batch):]
+
+			if err := writeSeries(writer, row, columnIdxByTag, batch); err != nil {
+				logger.Error("unable to write export row", zap.Error(err))
+				return
+			}
+			if err := writer.Flush(); err != nil {
+				logger.Error("unable to write export row group", zap.Error(err))
+				return
+			}
+			streaming = true
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		logger.Error("unable to write export footer", zap.Error(err))
+	}
+}
+
+// writeSeries writes a row per sample of the series, row is reused across
+// calls to avoid allocating a row per sample.
+func writeSeries(
+	writer *parquet.Writer,
+	row []parquet.Value,
+	columnIdxByTag map[string]int,
+	series []*prompb.TimeSeries,
+) error {
+	for _, s := range series {
+		for i := range row {
+			row[i] = parquet.Value{Null: true}
+		}
+		for _, l := range s.Labels {
+			if idx, ok := columnIdxByTag[string(l.Name)]; ok {
+				row[idx] = parquet.Value{Bytes: l.Value}
+			}
+		}
+		for _, sample := range s.Samples {
+			row[len(row)-2] = parquet.Value{Int64: sample.Timestamp}
+			row[len(row)-1] = parquet.Value{Double: sample.Value}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func parseColumnMappings(values []string) ([]columnMapping, error) {
+	mappings := make([]columnMapping, 0, len(values))
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v == "" {
+				continue
+			}
+			tag, column := v, v
+			if idx := strings.Index(v, ":"); idx >= 0 {
+				tag, column = v[:idx], v[idx+1:]
+			}
+			if tag == "" || column == "" {
+				return nil, fmt.Errorf("invalid column mapping: %s", v)
+			}
+			mappings = append(mappings, columnMapping{tag: tag, column: column})
+		}
+	}
+	return mappings, nil
+}
+
+// tagColumnMappings maps every tag to a column of the same name.
+func tagColumnMappings(tags map[string]struct{}) []columnMapping {
+	mappings := make([]columnMapping, 0, len(tags))
+	for tag := range tags {
+		mappings = append(mappings, columnMapping{tag: tag, column: tag})
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].tag < mappings[j].tag
+	})
+	return mappings
+}
+
+// parquetColumns returns a column per mapping followed by the timestamp and
+// value columns.
+func parquetColumns(mappings []columnMapping) ([]parquet.Column, map[string]int, error) {
+	var (
+		columns        = make([]parquet.Column, 0, len(mappings)+2)
+		columnIdxByTag = make(map[string]int, len(mappings))
+	)
+	for _, m := range mappings {
+		if _, ok := columnIdxByTag[m.tag]; ok {
+			return nil, nil, fmt.Errorf("tag mapped to multiple columns: %s", m.tag)
+		}
+		if m.column == TimestampColumn || m.column == ValueColumn {
+			return nil, nil, fmt.Errorf(
+				"tag %s must be mapped to a column other than %s", m.tag, m.column)
+		}
+		columnIdxByTag[m.tag] = len(columns)
+		columns = append(columns, parquet.Column{
+			Name:     m.column,
+			Type:     parquet.StringColumnType,
+			Optional: true,
+		})
+	}
+	columns = append(columns,
+		parquet.Column{Name: TimestampColumn, Type: parquet.TimestampMillisColumnType},
+		parquet.Column{Name: ValueColumn, Type: parquet.DoubleColumnType})
+	return columns, columnIdxByTag, nil
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3/consolidators"
+	"github.com/m3db/m3/src/x/parquet"
+	xtest "github.com/m3db/m3/src/x/test"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParquetHandler(t *testing.T, store storage.Storage) http.Handler {
+	fb, err := handleroptions.NewFetchOptionsBuilder(
+		handleroptions.FetchOptionsBuilderOptions{
+			Timeout: 15 * time.Second,
+		})
+	require.NoError(t, err)
+
+	return NewParquetHandler(options.EmptyHandlerOptions().
+		SetStorage(store).
+		SetTagOptions(models.NewTagOptions()).
+		SetFetchOptionsBuilder(fb))
+}
+
+func TestParquetHandler(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&consolidators.CompleteTagsResult{
+			CompleteNameOnly: true,
+			Metadata:         block.NewResultMetadata(),
+		}, nil)
+	store.EXPECT().FetchProm(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(storage.PromResult{
+			PromResult: &prompb.QueryResult{
+				Timeseries: []*prompb.TimeSeries{
+					{
+						Labels: []prompb.Label{
+							{Name: []byte("__name__"), Value: []byte("up")},
+							{Name: []byte("job"), Value: []byte("a")},
+						},
+						Samples: []prompb.Sample{{Timestamp: 1000, Value: 1}},
+					},
+				},
+			},
+			Metadata: block.NewResultMetadata(),
+		}, nil)
+
+	params := url.Values{
+		"match[]": []string{"up"},
+		"start":   []string{"1"},
+		"end":     []string{"100"},
+		"column":  []string{"__name__:metric,job"},
+	}
+	req := httptest.NewRequest(http.MethodGet, ParquetURL+"?"+params.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	newTestParquetHandler(t, store).ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, ContentTypeParquet, recorder.Header().Get("Content-Type"))
+	body := recorder.Body.Bytes()
+	require.Equal(t, "PAR1", string(body[:4]))
+	require.Equal(t, "PAR1", string(body[len(body)-4:]))
+}
+
+func TestParquetHandlerStreamsSeriesBatches(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	newSeries := func(n int, name string) []*prompb.TimeSeries {
+		series := make([]*prompb.TimeSeries, 0, n)
+		for i := 0; i < n; i++ {
+			series = append(series, &prompb.TimeSeries{
+				Labels:  []prompb.Label{{Name: []byte(name), Value: []byte("a")}},
+				Samples: []prompb.Sample{{Timestamp: int64(i), Value: float64(i)}},
+			})
+		}
+		return series
+	}
+
+	// columns are resolved from the tags of both selectors before any data
+	// is fetched.
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&consolidators.CompleteTagsResult{
+			CompleteNameOnly: true,
+			CompletedTags:    []consolidators.CompletedTag{{Name: []byte("job")}},
+			Metadata:         block.NewResultMetadata(),
+		}, nil)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&consolidators.CompleteTagsResult{
+			CompleteNameOnly: true,
+			CompletedTags:    []consolidators.CompletedTag{{Name: []byte("dc")}},
+			Metadata:         block.NewResultMetadata(),
+		}, nil)
+	gomock.InOrder(
+		store.EXPECT().FetchProm(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(storage.PromResult{
+				PromResult: &prompb.QueryResult{Timeseries: newSeries(seriesBatchSize+1, "job")},
+				Metadata:   block.NewResultMetadata(),
+			}, nil),
+		store.EXPECT().FetchProm(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(storage.PromResult{
+				PromResult: &prompb.QueryResult{Timeseries: newSeries(1, "dc")},
+				Metadata:   block.NewResultMetadata(),
+			}, nil),
+	)
+
+	params := url.Values{
+		"match[]": []string{"up", "down"},
+		"start":   []string{"1"},
+		"end":     []string{"100"},
+	}
+	req := httptest.NewRequest(http.MethodGet, ParquetURL+"?"+params.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	newTestParquetHandler(t, store).ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, recorder.Flushed)
+
+	// a row group is written per batch of series of each selector, each row
+	// group has a column chunk for the dc, job, timestamp and value columns.
+	body := recorder.Body.Bytes()
+	require.Equal(t, "PAR1", string(body[len(body)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(body[len(body)-8:]))
+	footer := body[len(body)-8-footerLen : len(body)-8]
+	require.Equal(t, 3, bytes.Count(footer, []byte("timestamp"))-1)
+	require.Equal(t, 4, bytes.Count(footer, []byte("dc")))
+}
+
+func TestParquetHandlerFetchError(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&consolidators.CompleteTagsResult{
+			CompleteNameOnly: true,
+			Metadata:         block.NewResultMetadata(),
+		}, nil)
+	store.EXPECT().FetchProm(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(storage.PromResult{}, errors.New("fetch error"))
+
+	params := url.Values{
+		"match[]": []string{"up"},
+		"start":   []string{"1"},
+		"end":     []string{"100"},
+	}
+	req := httptest.NewRequest(http.MethodGet, ParquetURL+"?"+params.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	newTestParquetHandler(t, store).ServeHTTP(recorder, req)
+
+	// nothing has been streamed yet so the error is returned to the client.
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestParquetHandlerInvalidColumns(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&consolidators.CompleteTagsResult{
+			CompleteNameOnly: true,
+			Metadata:         block.NewResultMetadata(),
+		}, nil)
+
+	params := url.Values{
+		"match[]": []string{"up"},
+		"start":   []string{"1"},
+		"end":     []string{"100"},
+		"column":  []string{"job:value"},
+	}
+	req := httptest.NewRequest(http.MethodGet, ParquetURL+"?"+params.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	newTestParquetHandler(t, store).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestParquetColumns(t *testing.T) {
+	mappings, err := parseColumnMappings([]string{"__name__:metric,job", "dc"})
+	require.NoError(t, err)
+
+	columns, idxByTag, err := parquetColumns(mappings)
+	require.NoError(t, err)
+	require.Equal(t, []parquet.Column{
+		{Name: "metric", Type: parquet.StringColumnType, Optional: true},
+		{Name: "job", Type: parquet.StringColumnType, Optional: true},
+		{Name: "dc", Type: parquet.StringColumnType, Optional: true},
+		{Name: TimestampColumn, Type: parquet.TimestampMillisColumnType},
+		{Name: ValueColumn, Type: parquet.DoubleColumnType},
+	}, columns)
+	require.Equal(t, map[string]int{"__name__": 0, "job": 1, "dc": 2}, idxByTag)
+
+	_, err = parseColumnMappings([]string{":metric"})
+	require.Error(t, err)
+	_, _, err = parquetColumns([]columnMapping{{tag: "a", column: "x"}, {tag: "a", column: "y"}})
+	require.Error(t, err)
+}
@@ -27,6 +27,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/graphite/graphite"
 	graphitestorage "github.com/m3db/m3/src/query/graphite/storage"
@@ -69,7 +70,11 @@ func parseFindParamsToQueries(r *http.Request) (
 			xerrors.NewInvalidParamsError(errors.ErrNoQueryFound)
 	}
 
-	now := time.Now()
+	now, err := handleroptions.ParseEvaluationTime(r, time.Now())
+	if err != nil {
+		return nil, nil, "", err
+	}
+
 	fromString, untilString := r.FormValue("from"), r.FormValue("until")
 	if len(fromString) == 0 {
 		fromString = "0"
@@ -100,12 +100,14 @@ func ParseRenderRequest(
 		return nil, RenderRequest{}, nil, err
 	}
 
-	var (
-		p = RenderRequest{
-			Timeout: fetchOpts.Timeout,
-		}
-		now = time.Now()
-	)
+	p := RenderRequest{
+		Timeout: fetchOpts.Timeout,
+	}
+	now, err := handleroptions.ParseEvaluationTime(r, time.Now())
+	if err != nil {
+		return nil, p, nil, err
+	}
+
 	p.Targets = r.Form["target"]
 	if len(p.Targets) == 0 {
 		return nil, p, nil, errNoTarget
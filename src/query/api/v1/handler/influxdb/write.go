@@ -285,6 +285,20 @@ func (ii *ingestIterator) CurrentMetadata() ts.Metadata {
 	return ii.metadatas[ii.pointIndex]
 }
 
+// NewPointsIter returns an iterator over the fields of line protocol points
+// for writing with a downsampler and writer.
+func NewPointsIter(
+	points []imodels.Point,
+	tagOpts models.TagOptions,
+) ingest.DownsampleAndWriteIter {
+	return &ingestIterator{
+		points:       points,
+		tagOpts:      tagOpts,
+		promRewriter: newPromRewriter(),
+		writeTags:    models.NewTags(0, tagOpts),
+	}
+}
+
 // NewInfluxWriterHandler returns a new influx write handler.
 func NewInfluxWriterHandler(options options.HandlerOptions) http.Handler {
 	return &ingestWriteHandler{
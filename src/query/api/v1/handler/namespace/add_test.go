@@ -147,6 +147,7 @@ func TestNamespaceAddHandler(t *testing.T) {
 						"indexOptions": xjson.Map{
 							"enabled":        true,
 							"blockSizeNanos": "7200000000000",
+							"excludedTags":   xjson.Array{},
 						},
 						"runtimeOptions":    nil,
 						"schemaOptions":     nil,
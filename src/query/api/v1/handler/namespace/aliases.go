@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/instrument"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+)
+
+var (
+	// M3DBAliasesURL is the url for the namespace aliases handler.
+	M3DBAliasesURL = path.Join(route.Prefix, M3DBServiceNamespacePathName, "aliases")
+
+	// AliasesHTTPMethod is the HTTP method used with this resource.
+	AliasesHTTPMethod = http.MethodGet
+)
+
+// AliasesHandler is the handler for listing the aliases of renamed
+// namespaces along with how many times each alias has been resolved, which
+// helps to plan the removal of aliases that are no longer used.
+type AliasesHandler struct {
+	aliases        *storage.NamespaceAliases
+	instrumentOpts instrument.Options
+}
+
+// AliasesResponse is the response of the namespace aliases handler.
+type AliasesResponse struct {
+	Aliases []AliasResponse `json:"aliases"`
+}
+
+// AliasResponse describes a single namespace alias.
+type AliasResponse struct {
+	Alias     string `json:"alias"`
+	Namespace string `json:"namespace"`
+	Resolved  int64  `json:"resolved"`
+}
+
+// NewAliasesHandler returns a new instance of AliasesHandler.
+func NewAliasesHandler(
+	aliases *storage.NamespaceAliases,
+	instrumentOpts instrument.Options,
+) *AliasesHandler {
+	return &AliasesHandler{
+		aliases:        aliases,
+		instrumentOpts: instrumentOpts,
+	}
+}
+
+func (h *AliasesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context(), h.instrumentOpts)
+
+	aliases := h.aliases.Aliases()
+	resp := AliasesResponse{Aliases: make([]AliasResponse, 0, len(aliases))}
+	for _, alias := range aliases {
+		resp.Aliases = append(resp.Aliases, AliasResponse{
+			Alias:     alias.Alias,
+			Namespace: alias.Namespace,
+			Resolved:  alias.Resolved,
+		})
+	}
+
+	xhttp.WriteJSONResponse(w, resp, logger)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestAliasesHandler(t *testing.T) {
+	aliases, err := storage.NewNamespaceAliases(map[string]string{
+		"metrics_old":    "metrics",
+		"metrics_legacy": "metrics",
+	}, tally.NoopScope)
+	require.NoError(t, err)
+	aliases.Resolve("metrics_old")
+
+	handler := NewAliasesHandler(aliases, instrument.NewOptions())
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(AliasesHTTPMethod, M3DBAliasesURL, nil)
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp AliasesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, AliasesResponse{
+		Aliases: []AliasResponse{
+			{Alias: "metrics_legacy", Namespace: "metrics"},
+			{Alias: "metrics_old", Namespace: "metrics", Resolved: 1},
+		},
+	}, resp)
+}
+
+func TestAliasesHandlerNoAliases(t *testing.T) {
+	handler := NewAliasesHandler(nil, instrument.NewOptions())
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(AliasesHTTPMethod, M3DBAliasesURL, nil)
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"aliases":[]}`, w.Body.String())
+}
@@ -33,6 +33,7 @@ import (
 	nsproto "github.com/m3db/m3/src/dbnode/generated/proto/namespace"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/util/queryhttp"
 	"github.com/m3db/m3/src/x/instrument"
@@ -115,6 +116,7 @@ func RegisterRoutes(
 	defaults []handleroptions.ServiceOptionsDefault,
 	instrumentOpts instrument.Options,
 	namespaceValidator options.NamespaceValidator,
+	namespaceAliases *storage.NamespaceAliases,
 ) error {
 	applyMiddleware := func(
 		f func(svc handleroptions.ServiceNameAndDefaults,
@@ -193,6 +195,15 @@ func RegisterRoutes(
 		return err
 	}
 
+	// List namespace aliases.
+	if err := r.Register(queryhttp.RegisterOptions{
+		Path:    M3DBAliasesURL,
+		Handler: NewAliasesHandler(namespaceAliases, instrumentOpts),
+		Methods: []string{AliasesHTTPMethod},
+	}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -233,6 +233,9 @@ func (h *UpdateHandler) Update(
 		if v := newRuntimeOpts.FlushIndexingPerCPUConcurrency; v != nil {
 			runtimeOpts = runtimeOpts.SetFlushIndexingPerCPUConcurrency(&v.Value)
 		}
+		if v := newRuntimeOpts.IndexingPaused; v != nil {
+			runtimeOpts = runtimeOpts.SetIndexingPaused(&v.Value)
+		}
 		opts := ns.Options().
 			SetRuntimeOptions(runtimeOpts)
 		ns, err = namespace.NewMetadata(ns.ID(), opts)
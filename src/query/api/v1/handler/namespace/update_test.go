@@ -194,9 +194,11 @@ func TestNamespaceUpdateHandler(t *testing.T) {
 						"indexOptions": xjson.Map{
 							"enabled":        false,
 							"blockSizeNanos": "7200000000000",
+							"excludedTags":   xjson.Array{},
 						},
 						"runtimeOptions": xjson.Map{
 							"flushIndexingPerCPUConcurrency": nil,
+							"indexingPaused":                 nil,
 							"writeIndexingPerCPUConcurrency": 16,
 						},
 						"schemaOptions":     nil,
@@ -255,6 +257,7 @@ func TestNamespaceUpdateHandler(t *testing.T) {
 						"indexOptions": xjson.Map{
 							"enabled":        false,
 							"blockSizeNanos": "7200000000000",
+							"excludedTags":   xjson.Array{},
 						},
 						"runtimeOptions":    nil,
 						"schemaOptions":     nil,
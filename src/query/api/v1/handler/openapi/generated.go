@@ -0,0 +1,202 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/query/util/queryhttp"
+	"github.com/m3db/m3/src/x/instrument"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+)
+
+const (
+	// GeneratedURL is the url for the OpenAPI spec generated from the
+	// registered endpoints.
+	GeneratedURL = "/api/docs"
+
+	generatedSpecVersion = "3.0.3"
+	generatedSpecTitle   = "M3 Coordinator API"
+)
+
+// EndpointLister lists the registered endpoints.
+type EndpointLister interface {
+	// Endpoints returns the endpoints registered by path and method.
+	Endpoints() []queryhttp.Endpoint
+}
+
+// Spec is a minimal OpenAPI spec that describes the paths, methods and path
+// parameters of the registered endpoints.
+type Spec struct {
+	OpenAPI string                              `json:"openapi"`
+	Info    SpecInfo                            `json:"info"`
+	Paths   map[string]map[string]SpecOperation `json:"paths"`
+}
+
+// SpecInfo is the metadata of an OpenAPI spec.
+type SpecInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// SpecOperation is an operation on a path of an OpenAPI spec.
+type SpecOperation struct {
+	OperationID string                  `json:"operationId"`
+	Parameters  []SpecParameter         `json:"parameters,omitempty"`
+	Responses   map[string]SpecResponse `json:"responses"`
+}
+
+// SpecParameter is a parameter of an operation of an OpenAPI spec.
+type SpecParameter struct {
+	Name     string     `json:"name"`
+	In       string     `json:"in"`
+	Required bool       `json:"required"`
+	Schema   SpecSchema `json:"schema"`
+}
+
+// SpecSchema is the schema of a parameter of an OpenAPI spec.
+type SpecSchema struct {
+	Type string `json:"type"`
+}
+
+// SpecResponse is a response of an operation of an OpenAPI spec.
+type SpecResponse struct {
+	Description string `json:"description"`
+}
+
+// GenerateSpec generates an OpenAPI spec for the endpoints.
+func GenerateSpec(endpoints []queryhttp.Endpoint) Spec {
+	spec := Spec{
+		OpenAPI: generatedSpecVersion,
+		Info: SpecInfo{
+			Title:   generatedSpecTitle,
+			Version: instrument.Version,
+		},
+		Paths: make(map[string]map[string]SpecOperation, len(endpoints)),
+	}
+
+	for _, endpoint := range endpoints {
+		path, params := specPath(endpoint.Path)
+		operations, ok := spec.Paths[path]
+		if !ok {
+			operations = make(map[string]SpecOperation)
+			spec.Paths[path] = operations
+		}
+
+		method := strings.ToLower(endpoint.Method)
+		op := SpecOperation{
+			OperationID: operationID(method, path),
+			Responses: map[string]SpecResponse{
+				"default": {Description: "response"},
+			},
+		}
+		for _, param := range params {
+			op.Parameters = append(op.Parameters, SpecParameter{
+				Name:     param,
+				In:       "path",
+				Required: true,
+				Schema:   SpecSchema{Type: "string"},
+			})
+		}
+		operations[method] = op
+	}
+
+	return spec
+}
+
+// specPath converts a route path template to an OpenAPI path template by
+// dropping any variable patterns, e.g. "/label/{name:.+}/values" becomes
+// "/label/{name}/values", and returns the names of the path variables.
+func specPath(path string) (string, []string) {
+	var (
+		b      strings.Builder
+		params []string
+		depth  int
+		name   strings.Builder
+		inName bool
+	)
+	for _, c := range path {
+		switch {
+		case c == '{':
+			depth++
+			if depth == 1 {
+				inName = true
+				name.Reset()
+				continue
+			}
+		case c == '}':
+			depth--
+			if depth == 0 {
+				params = append(params, name.String())
+				b.WriteString("{" + name.String() + "}")
+				inName = false
+				continue
+			}
+		}
+
+		if depth == 0 {
+			b.WriteRune(c)
+			continue
+		}
+		if inName {
+			if c == ':' {
+				inName = false
+				continue
+			}
+			name.WriteRune(c)
+		}
+	}
+	return b.String(), params
+}
+
+func operationID(method, path string) string {
+	id := strings.NewReplacer("/", "_", "{", "", "}", "", "-", "_", ".", "_").
+		Replace(strings.Trim(path, "/"))
+	return method + "_" + id
+}
+
+// GeneratedHandler serves an OpenAPI spec generated from the registered
+// endpoints.
+type GeneratedHandler struct {
+	lister         EndpointLister
+	instrumentOpts instrument.Options
+}
+
+// NewGeneratedHandler returns a new generated spec handler, the spec is
+// generated on every request so that it includes endpoints registered after
+// the handler.
+func NewGeneratedHandler(
+	lister EndpointLister,
+	instrumentOpts instrument.Options,
+) http.Handler {
+	return &GeneratedHandler{
+		lister:         lister,
+		instrumentOpts: instrumentOpts,
+	}
+}
+
+// ServeHTTP serves the generated OpenAPI spec.
+func (h *GeneratedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context(), h.instrumentOpts)
+	xhttp.WriteJSONResponse(w, GenerateSpec(h.lister.Endpoints()), logger)
+}
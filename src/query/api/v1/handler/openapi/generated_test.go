@@ -0,0 +1,100 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/query/util/queryhttp"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		expect string
+		params []string
+	}{
+		{path: "/api/v1/query", expect: "/api/v1/query"},
+		{
+			path:   "/api/v1/label/{name}/values",
+			expect: "/api/v1/label/{name}/values",
+			params: []string{"name"},
+		},
+		{
+			path:   "/api/v1/{kind:[a-z]{2,}}/{id:.+}",
+			expect: "/api/v1/{kind}/{id}",
+			params: []string{"kind", "id"},
+		},
+	}
+
+	for _, tt := range tests {
+		path, params := specPath(tt.path)
+		assert.Equal(t, tt.expect, path)
+		assert.Equal(t, tt.params, params)
+	}
+}
+
+func TestGeneratedHandler(t *testing.T) {
+	registry := queryhttp.NewEndpointRegistry(mux.NewRouter())
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	require.NoError(t, registry.Register(queryhttp.RegisterOptions{
+		Path:    "/api/v1/label/{name}/values",
+		Handler: handler,
+		Methods: []string{http.MethodGet, http.MethodPost},
+	}))
+	require.NoError(t, registry.Register(queryhttp.RegisterOptions{
+		PathPrefix: StaticURLPrefix,
+		Handler:    handler,
+	}))
+	require.NoError(t, registry.Register(queryhttp.RegisterOptions{
+		Path:    GeneratedURL,
+		Handler: NewGeneratedHandler(registry, instrument.NewOptions()),
+		Methods: []string{http.MethodGet},
+	}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, GeneratedURL, nil)
+	NewGeneratedHandler(registry, instrument.NewOptions()).ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var spec Spec
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, generatedSpecVersion, spec.OpenAPI)
+	require.Len(t, spec.Paths, 2)
+	require.Contains(t, spec.Paths, GeneratedURL)
+
+	operations := spec.Paths["/api/v1/label/{name}/values"]
+	require.Len(t, operations, 2)
+	get := operations["get"]
+	assert.Equal(t, "get_api_v1_label_name_values", get.OperationID)
+	require.Len(t, get.Parameters, 1)
+	assert.Equal(t, "name", get.Parameters[0].Name)
+	assert.Equal(t, "path", get.Parameters[0].In)
+	assert.Contains(t, operations, "post")
+}
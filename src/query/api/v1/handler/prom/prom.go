@@ -67,10 +67,15 @@ func withEngine(promQLEngineFn options.PromQLEngineFn, instant bool) Option {
 }
 
 func newDefaultOptions(hOpts options.HandlerOptions) opts {
+	cfg := hOpts.Config()
+	// NB: the lookback is validated when the engines are created.
+	lookback, _ := cfg.LookbackDurationOrDefault()
 	queryable := prometheus.NewPrometheusQueryable(
 		prometheus.PrometheusOptions{
-			Storage:           hOpts.Storage(),
-			InstrumentOptions: hOpts.InstrumentOpts(),
+			Storage:                  hOpts.Storage(),
+			InstrumentOptions:        hOpts.InstrumentOpts(),
+			IndexAggregationPushdown: cfg.Query.Prometheus.IndexAggregationPushdown,
+			LookbackDuration:         lookback,
 		})
 	return opts{
 		queryable:  queryable,
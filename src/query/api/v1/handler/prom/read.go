@@ -25,6 +25,7 @@ package prom
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"sync"
 
@@ -138,7 +139,16 @@ func (h *readHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx = context.WithValue(ctx, prometheus.FetchOptionsContextKey, fetchOptions)
 	ctx = context.WithValue(ctx, prometheus.BlockResultMetadataFnKey, resultMetadataReceiveFn)
 
-	qry, err := h.opts.newQueryFn(params)
+	queryParams := params
+	if fetchOptions.Sampled() {
+		queryParams.Query, err = scaleSampledAggregations(params.Query, fetchOptions.SampleRate)
+		if err != nil {
+			xhttp.WriteError(w, xerrors.NewInvalidParamsError(err))
+			return
+		}
+	}
+
+	qry, err := h.opts.newQueryFn(queryParams)
 	if err != nil {
 		h.logger.Error("error creating query",
 			zap.Error(err), zap.String("query", params.Query),
@@ -177,10 +187,6 @@ func (h *readHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for _, warn := range resultMetadata.Warnings {
-		res.Warnings = append(res.Warnings, errors.New(warn.Message))
-	}
-
 	query := params.Query
 	err = ApplyRangeWarnings(query, &resultMetadata)
 	if err != nil {
@@ -188,6 +194,7 @@ func (h *readHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			zap.Error(err), zap.String("query", query),
 			zap.Bool("instant", h.opts.instant))
 	}
+	resultMetadata.AddWarnings(handleroptions.RequestWarnings(ctx)...)
 
 	err = handleroptions.AddDBResultResponseHeaders(w, resultMetadata, fetchOptions)
 	if err != nil {
@@ -214,11 +221,14 @@ func (h *readHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		xhttp.WriteError(w, err)
 		return
 	}
+	if warning, ok := limited.Warning(); ok {
+		resultMetadata.AddWarnings(warning)
+	}
 
 	if err := Respond(w, &QueryData{
 		Result:     res.Value,
 		ResultType: res.Value.Type(),
-	}, res.Warnings); err != nil {
+	}, responseWarnings(resultMetadata, res.Warnings, fetchOptions)); err != nil {
 		h.logger.Error("error writing prom response",
 			zap.Error(err),
 			zap.String("query", params.Query),
@@ -226,6 +236,36 @@ func (h *readHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// responseWarnings returns the warnings of the response, the warnings of the
+// result metadata followed by the warnings raised by the engine that are not
+// already part of them.
+func responseWarnings(
+	meta block.ResultMetadata,
+	engineWarnings promstorage.Warnings,
+	fetchOptions *storage.FetchOptions,
+) promstorage.Warnings {
+	metaWarnings := meta.WarningStrings()
+	warnings := make(promstorage.Warnings, 0, len(metaWarnings)+len(engineWarnings)+1)
+	seen := make(map[string]struct{}, len(metaWarnings)+len(engineWarnings))
+	for _, warning := range metaWarnings {
+		seen[warning] = struct{}{}
+		warnings = append(warnings, errors.New(warning))
+	}
+	for _, warning := range engineWarnings {
+		if _, ok := seen[warning.Error()]; ok {
+			continue
+		}
+		seen[warning.Error()] = struct{}{}
+		warnings = append(warnings, warning)
+	}
+	if fetchOptions.Sampled() {
+		warnings = append(warnings, fmt.Errorf(
+			"approximate result: evaluated over a %v sample of series",
+			fetchOptions.SampleRate))
+	}
+	return warnings
+}
+
 func (h *readHandler) limitReturnedData(query string,
 	res *promql.Result,
 	fetchOpts *storage.FetchOptions,
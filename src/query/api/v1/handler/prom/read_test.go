@@ -23,6 +23,7 @@ package prom
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -33,10 +34,12 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
 	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/prometheus"
 	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/headers"
 	"github.com/m3db/m3/src/x/instrument"
 
 	"github.com/prometheus/prometheus/model/labels"
@@ -147,6 +150,68 @@ func TestPromReadHandlerInvalidQuery(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, recorder.Code)
 }
 
+func TestPromReadHandlerSampled(t *testing.T) {
+	setup := setupTest(t)
+
+	vals := defaultParams()
+	vals.Set(queryParam, "sum("+promQuery+")")
+	// NB: the scaled aggregation evaluates a scalar at each step so use a
+	// step that keeps the query within the max samples of the engine.
+	vals.Set(handleroptions.StepParam, time.Minute.String())
+	req, _ := http.NewRequest("GET", native.PromReadURL, nil)
+	req.URL.RawQuery = vals.Encode()
+	req.Header.Set(headers.SampleRateHeader, "0.1")
+
+	recorder := httptest.NewRecorder()
+	setup.readHandler.ServeHTTP(recorder, req)
+
+	var resp response
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, statusSuccess, resp.Status)
+	require.Equal(t, "0.1", recorder.Header().Get(headers.ResultsSampledHeader))
+	require.Equal(t, []string{"approximate result: evaluated over a 0.1 sample of series"},
+		resp.Warnings)
+}
+
+func TestPromReadHandlerRequestWarnings(t *testing.T) {
+	setup := setupTest(t)
+
+	req, _ := http.NewRequest("GET", native.PromReadURL, nil)
+	req.URL.RawQuery = defaultParams().Encode()
+	req = req.WithContext(handleroptions.WithRequestWarnings(req.Context(),
+		block.Warning{Name: "foo", Message: "bar"}))
+
+	recorder := httptest.NewRecorder()
+	setup.readHandler.ServeHTTP(recorder, req)
+
+	var resp response
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, statusSuccess, resp.Status)
+	require.Equal(t, "foo_bar", recorder.Header().Get(headers.LimitHeader))
+	require.Equal(t, []string{"foo_bar"}, resp.Warnings)
+}
+
+func TestResponseWarnings(t *testing.T) {
+	meta := block.NewResultMetadata()
+	meta.Exhaustive = false
+	meta.AddWarning("foo", "bar")
+
+	warnings := responseWarnings(meta, promstorage.Warnings{
+		errors.New("foo_bar"),
+		errors.New("baz"),
+	}, &storage.FetchOptions{})
+
+	var strs []string
+	for _, warning := range warnings {
+		strs = append(strs, warning.Error())
+	}
+	require.Equal(t, []string{
+		"foo_bar",
+		"m3db exceeded query limit: results not exhaustive",
+		"baz",
+	}, strs)
+}
+
 func TestPromReadHandlerErrors(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package prom
+
+import (
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// scaleSampledAggregations rewrites a query that is evaluated over a sample
+// of series so that sum and count aggregations estimate the result over all
+// series. Only the innermost sum and count aggregations are scaled since
+// aggregations over them already operate on estimates, other aggregations
+// such as avg, min and max are left as approximated by the sample.
+func scaleSampledAggregations(query string, rate float64) (string, error) {
+	expr, err := parser.ParseExpr(query)
+	if err != nil {
+		return "", err
+	}
+
+	expr, _ = scaleSampledExpr(expr, 1/rate)
+	return expr.String(), nil
+}
+
+// scaleSampledExpr scales the innermost sum and count aggregations of the
+// expression by the factor, returning the rewritten expression and whether
+// it contains any aggregation.
+func scaleSampledExpr(expr parser.Expr, factor float64) (parser.Expr, bool) {
+	switch e := expr.(type) {
+	case *parser.AggregateExpr:
+		var nested bool
+		e.Expr, nested = scaleSampledExpr(e.Expr, factor)
+		if nested || (e.Op != parser.SUM && e.Op != parser.COUNT) {
+			return e, true
+		}
+		return &parser.ParenExpr{
+			Expr: &parser.BinaryExpr{
+				Op:  parser.MUL,
+				LHS: e,
+				RHS: &parser.NumberLiteral{Val: factor},
+			},
+		}, true
+	case *parser.BinaryExpr:
+		var lhs, rhs bool
+		e.LHS, lhs = scaleSampledExpr(e.LHS, factor)
+		e.RHS, rhs = scaleSampledExpr(e.RHS, factor)
+		return e, lhs || rhs
+	case *parser.Call:
+		var found bool
+		for i, arg := range e.Args {
+			var nested bool
+			e.Args[i], nested = scaleSampledExpr(arg, factor)
+			found = found || nested
+		}
+		return e, found
+	case *parser.ParenExpr:
+		var nested bool
+		e.Expr, nested = scaleSampledExpr(e.Expr, factor)
+		return e, nested
+	case *parser.UnaryExpr:
+		var nested bool
+		e.Expr, nested = scaleSampledExpr(e.Expr, factor)
+		return e, nested
+	case *parser.SubqueryExpr:
+		var nested bool
+		e.Expr, nested = scaleSampledExpr(e.Expr, factor)
+		return e, nested
+	default:
+		return expr, false
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package prom
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleSampledAggregations(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "foo", expected: "foo"},
+		{query: "rate(foo[5m])", expected: "rate(foo[5m])"},
+		{query: "avg(foo)", expected: "avg(foo)"},
+		{query: "sum(foo)", expected: "(sum(foo) * 4)"},
+		{
+			query:    "sum by (a) (rate(foo[5m]))",
+			expected: "(sum by (a) (rate(foo[5m])) * 4)",
+		},
+		{
+			query:    "sum(count by (a) (foo))",
+			expected: "sum((count by (a) (foo) * 4))",
+		},
+		{
+			query:    "max(sum by (a) (foo)) / count(bar)",
+			expected: "max((sum by (a) (foo) * 4)) / (count(bar) * 4)",
+		},
+		{
+			query:    "abs(sum(foo) ^ 2)",
+			expected: "abs((sum(foo) * 4) ^ 2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expected, err := parser.ParseExpr(tt.expected)
+			require.NoError(t, err)
+
+			actual, err := scaleSampledAggregations(tt.query, 0.25)
+			require.NoError(t, err)
+			assert.Equal(t, expected.String(), actual)
+		})
+	}
+
+	_, err := scaleSampledAggregations("sum(", 0.25)
+	require.Error(t, err)
+}
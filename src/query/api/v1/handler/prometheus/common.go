@@ -28,6 +28,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/models"
 	xpromql "github.com/m3db/m3/src/query/parser/promql"
@@ -113,8 +114,12 @@ func ParseTagCompletionParamsToQueries(
 		return tagCompletionQueries, xerrors.NewInvalidParamsError(err)
 	}
 
-	end, err := util.ParseTimeStringWithDefault(r.FormValue("end"),
-		time.Now())
+	now, err := handleroptions.ParseEvaluationTime(r, time.Now())
+	if err != nil {
+		return tagCompletionQueries, err
+	}
+
+	end, err := util.ParseTimeStringWithDefault(r.FormValue("end"), now)
 	if err != nil {
 		return tagCompletionQueries, xerrors.NewInvalidParamsError(err)
 	}
@@ -198,8 +203,12 @@ func ParseStartAndEnd(
 			goerrors.New("invalid start time. start time must be set"))
 	}
 
-	end, err := util.ParseTimeStringWithDefault(r.FormValue("end"),
-		parseOpts.NowFn()())
+	now, err := handleroptions.ParseEvaluationTime(r, parseOpts.NowFn()())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end, err := util.ParseTimeStringWithDefault(r.FormValue("end"), now)
 	if err != nil {
 		return time.Time{}, time.Time{}, xerrors.NewInvalidParamsError(err)
 	}
@@ -631,7 +640,11 @@ func ParseTimeParams(r *http.Request) (TimeParams, error) {
 		err    error
 	)
 
-	params.Now = time.Now()
+	params.Now, err = handleroptions.ParseEvaluationTime(r, time.Now())
+	if err != nil {
+		return params, err
+	}
+
 	if v := r.FormValue(timeParam); v != "" {
 		var err error
 		params.Now, err = ParseTime(r, timeParam, params.Now)
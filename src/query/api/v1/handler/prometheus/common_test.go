@@ -106,6 +106,8 @@ func TestParseStartAndEnd(t *testing.T) {
 		{querystring: "start=200&end=100", exErr: true},
 		{querystring: "start=foo&end=100", exErr: true},
 		{querystring: "start=100&end=bar", exErr: true},
+		{querystring: "start=100&evaluationTime=300", exStart: time.Unix(100, 0), exEnd: time.Unix(300, 0)},
+		{querystring: "start=100&evaluationTime=foo", exErr: true},
 	}
 
 	for _, tt := range tests {
@@ -33,6 +33,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/accesscontrol"
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
@@ -54,6 +55,8 @@ const (
 	LookbackParam = "lookback"
 	// TimeoutParam is the timeout parameter.
 	TimeoutParam = "timeout"
+	// EvaluationTimeParam is the evaluation time parameter.
+	EvaluationTimeParam = "evaluationTime"
 
 	requireExhaustiveParam = "requireExhaustive"
 	requireNoWaitParam     = "requireNoWait"
@@ -78,6 +81,12 @@ type FetchOptionsBuilderOptions struct {
 	Limits        FetchOptionsBuilderLimitsOptions
 	RestrictByTag *storage.RestrictByTag
 	Timeout       time.Duration
+	// AccessControl is the optional policy of tag matchers enforced on the
+	// queries of each requester identity.
+	AccessControl *accesscontrol.Policy
+	// NamespaceAliases are the optional aliases of renamed namespaces that
+	// are resolved when restricting queries by namespace.
+	NamespaceAliases *storage.NamespaceAliases
 }
 
 // Validate validates the fetch options builder options.
@@ -293,6 +302,14 @@ func (b fetchOptionsBuilder) NewFetchOptions(
 		// Always invalid request if parsing fails params.
 		return nil, nil, xerrors.NewInvalidParamsError(err)
 	}
+	if accessControl := b.opts.AccessControl; accessControl != nil {
+		// Enforce access control last so that no other options can override it.
+		matchers, err := accessControl.Matchers(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		accesscontrol.Enforce(fetchOpts, matchers)
+	}
 	return ctx, fetchOpts, nil
 }
 
@@ -471,6 +488,21 @@ func (b fetchOptionsBuilder) newFetchOptions(
 		)
 	}
 
+	if str := req.Header.Get(headers.MetricsRestrictByNamespaceHeader); str != "" {
+		if metricsTypeHeaderFound || metricsStoragePolicyHeaderFound ||
+			metricsRestrictByStoragePoliciesHeaderFound {
+			err = fmt.Errorf(
+				"restrict by namespace is incompatible with M3-Metrics-Type, " +
+					"M3-Storage-Policy and M3-Restrict-By-Storage-Policies headers")
+			return nil, nil, err
+		}
+
+		fetchOpts.RestrictQueryOptions = newOrExistingRestrictQueryOptions(fetchOpts)
+		fetchOpts.RestrictQueryOptions.RestrictByNamespace = &storage.RestrictByNamespace{
+			Namespace: b.opts.NamespaceAliases.Resolve(str),
+		}
+	}
+
 	if str := req.Header.Get(headers.RestrictByTagsJSONHeader); str != "" {
 		// Allow header to override any default restrict by tags config.
 		var opts StringTagOptions
@@ -530,6 +562,20 @@ func (b fetchOptionsBuilder) newFetchOptions(
 		fetchOpts.RelatedQueryOptions = relatedQueryOpts
 	}
 
+	if stitchingOpts, ok, err := ParseStitchingOptions(req); err != nil {
+		err = fmt.Errorf(
+			"could not parse stitching options: err=%w", err)
+		return nil, nil, err
+	} else if ok {
+		fetchOpts.StitchingOptions = stitchingOpts
+	}
+
+	if sampleRate, ok, err := ParseSampleRate(req); err != nil {
+		return nil, nil, err
+	} else if ok {
+		fetchOpts.SampleRate = sampleRate
+	}
+
 	fetchOpts.Timeout, err = ParseRequestTimeout(req, b.opts.Timeout)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not parse timeout: err=%w", err)
@@ -692,6 +738,29 @@ func ParseRequestTimeout(
 	return duration, nil
 }
 
+// ParseEvaluationTime parses the pinned evaluation time of a request, which
+// is used in place of the current time when resolving "now", falling back to
+// now if the request does not pin the evaluation time. The header takes
+// precedence over the parameter.
+func ParseEvaluationTime(r *http.Request, now time.Time) (time.Time, error) {
+	str := r.FormValue(EvaluationTimeParam)
+	if v := r.Header.Get(headers.EvaluationTimeHeader); v != "" {
+		str = v
+	}
+
+	if str == "" {
+		return now, nil
+	}
+
+	t, err := util.ParseTimeString(str)
+	if err != nil {
+		return time.Time{}, xerrors.NewInvalidParamsError(
+			fmt.Errorf("invalid '%s': %s", EvaluationTimeParam, str))
+	}
+
+	return t, nil
+}
+
 // ParseRelatedQueryOptions parses the RelatedQueryOptions struct out of the request
 // it returns ok==false if no such options exist
 func ParseRelatedQueryOptions(r *http.Request) (*storage.RelatedQueryOptions, bool, error) {
@@ -740,6 +809,67 @@ func ParseRelatedQueryOptions(r *http.Request) (*storage.RelatedQueryOptions, bo
 	}, true, nil
 }
 
+// ParseStitchingOptions parses the StitchingOptions struct out of the request
+// it returns ok==false if no such options exist
+func ParseStitchingOptions(r *http.Request) (*storage.StitchingOptions, bool, error) {
+	policyStr := r.Header.Get(headers.StitchingPolicyHeader)
+	cutoverStr := r.Header.Get(headers.StitchingCutoverHeader)
+	if policyStr == "" && cutoverStr == "" {
+		return nil, false, nil
+	}
+
+	var opts storage.StitchingOptions
+	if policyStr != "" {
+		policy, err := storage.ParseStitchingPolicy(policyStr)
+		if err != nil {
+			return nil, false, xerrors.NewInvalidParamsError(
+				fmt.Errorf("invalid '%s': %w", headers.StitchingPolicyHeader, err))
+		}
+		opts.Policy = policy
+	} else {
+		// A cut-over on its own implies the cutover policy.
+		opts.Policy = storage.StitchingCutover
+	}
+
+	if cutoverStr != "" {
+		cutover, err := util.ParseTimeString(cutoverStr)
+		if err != nil {
+			return nil, false, xerrors.NewInvalidParamsError(
+				fmt.Errorf("invalid '%s': cannot parse %v to time",
+					headers.StitchingCutoverHeader, cutoverStr))
+		}
+		opts.Cutover = xtime.ToUnixNano(cutover)
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, false, xerrors.NewInvalidParamsError(err)
+	}
+
+	return &opts, true, nil
+}
+
+// ParseSampleRate parses the sample rate out of the request, it returns
+// ok==false if no sample rate was specified.
+func ParseSampleRate(r *http.Request) (float64, bool, error) {
+	str := r.Header.Get(headers.SampleRateHeader)
+	if str == "" {
+		return 0, false, nil
+	}
+
+	rate, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false, xerrors.NewInvalidParamsError(
+			fmt.Errorf("invalid '%s': cannot parse %v to float",
+				headers.SampleRateHeader, str))
+	}
+	if err := storage.ValidateSampleRate(rate); err != nil {
+		return 0, false, xerrors.NewInvalidParamsError(
+			fmt.Errorf("invalid '%s': %w", headers.SampleRateHeader, err))
+	}
+
+	return rate, true, nil
+}
+
 func validateTimeout(v time.Duration) error {
 	if v <= 0 {
 		return xerrors.NewInvalidParamsError(
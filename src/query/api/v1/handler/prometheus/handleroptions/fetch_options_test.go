@@ -36,15 +36,18 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/accesscontrol"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
 	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/headers"
 	xhttp "github.com/m3db/m3/src/x/net/http"
+	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 func TestFetchOptionsBuilder(t *testing.T) {
@@ -414,6 +417,89 @@ func TestParseLookbackDuration(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestParseEvaluationTime(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	r := httptest.NewRequest(http.MethodGet, "/foo", nil)
+	v, err := ParseEvaluationTime(r, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, v)
+
+	r = httptest.NewRequest(http.MethodGet, "/foo?evaluationTime=100", nil)
+	v, err = ParseEvaluationTime(r, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(100, 0), v)
+
+	r = httptest.NewRequest(http.MethodGet, "/foo?evaluationTime=1970-01-01T00:02:00Z", nil)
+	v, err = ParseEvaluationTime(r, now)
+	require.NoError(t, err)
+	assert.True(t, time.Unix(120, 0).Equal(v))
+
+	// Header takes precedence over the parameter.
+	r.Header.Set(headers.EvaluationTimeHeader, "200")
+	v, err = ParseEvaluationTime(r, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(200, 0), v)
+
+	r = httptest.NewRequest(http.MethodGet, "/foo?evaluationTime=foobar", nil)
+	_, err = ParseEvaluationTime(r, now)
+	require.Error(t, err)
+	assert.True(t, xerrors.IsInvalidParams(err))
+}
+
+func TestParseStitchingOptions(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/foo", nil)
+	_, ok, err := ParseStitchingOptions(r)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	r.Header.Set(headers.StitchingPolicyHeader, "prefer_unaggregated")
+	v, ok, err := ParseStitchingOptions(r)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, storage.StitchingOptions{
+		Policy: storage.StitchingPreferUnaggregated,
+	}, *v)
+
+	// A cut-over on its own implies the cutover policy.
+	r = httptest.NewRequest(http.MethodGet, "/foo", nil)
+	r.Header.Set(headers.StitchingCutoverHeader, "100")
+	v, ok, err = ParseStitchingOptions(r)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, storage.StitchingCutover, v.Policy)
+	assert.Equal(t, xtime.ToUnixNano(time.Unix(100, 0)), v.Cutover)
+
+	r.Header.Set(headers.StitchingPolicyHeader, "prefer_aggregated")
+	_, _, err = ParseStitchingOptions(r)
+	require.Error(t, err)
+
+	r = httptest.NewRequest(http.MethodGet, "/foo", nil)
+	r.Header.Set(headers.StitchingPolicyHeader, "foobar")
+	_, _, err = ParseStitchingOptions(r)
+	require.Error(t, err)
+}
+
+func TestParseSampleRate(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/foo", nil)
+	_, ok, err := ParseSampleRate(r)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	r.Header.Set(headers.SampleRateHeader, "0.25")
+	v, ok, err := ParseSampleRate(r)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 0.25, v)
+
+	for _, invalid := range []string{"0", "-0.5", "1.5", "foo"} {
+		r.Header.Set(headers.SampleRateHeader, invalid)
+		_, _, err = ParseSampleRate(r)
+		require.Error(t, err, invalid)
+		assert.True(t, xerrors.IsInvalidParams(err), invalid)
+	}
+}
+
 func TestParseDuration(t *testing.T) {
 	r, err := http.NewRequest(http.MethodGet, "/foo?step=10s", nil)
 	require.NoError(t, err)
@@ -528,6 +614,80 @@ func TestFetchOptionsWithHeader(t *testing.T) {
 	require.Equal(t, encoding.IterateLowestValue, *opts.IterateEqualTimestampStrategy)
 }
 
+func TestFetchOptionsWithAccessControl(t *testing.T) {
+	teamX := mustMatcher("team", "X", models.MatchEqual)
+	builder, err := NewFetchOptionsBuilder(FetchOptionsBuilderOptions{
+		RestrictByTag: &storage.RestrictByTag{
+			Restrict: models.Matchers{mustMatcher("env", "prod", models.MatchEqual)},
+		},
+		Timeout: 10 * time.Second,
+		AccessControl: &accesscontrol.Policy{
+			Identities:   map[string]models.Matchers{"alice": {teamX}},
+			DenyUnlisted: true,
+		},
+	})
+	require.NoError(t, err)
+
+	// Restrict by tags header cannot remove the enforced matchers.
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add(headers.RestrictByTagsJSONHeader,
+		`{"match":[{"name":"team", "value":".*", "type":"REGEXP"}]}`)
+	ctx := accesscontrol.NewContext(context.Background(), "alice")
+	_, opts, err := builder.NewFetchOptions(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, models.Matchers{
+		mustMatcher("team", ".*", models.MatchRegexp),
+		teamX,
+	}, opts.RestrictQueryOptions.GetRestrictByTag().GetMatchers())
+	require.Equal(t, toStrip("team"),
+		opts.RestrictQueryOptions.GetRestrictByTag().GetFilterByNames())
+
+	// Default restrict by tags still applies.
+	req = httptest.NewRequest("GET", "/", nil)
+	_, opts, err = builder.NewFetchOptions(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, models.Matchers{
+		mustMatcher("env", "prod", models.MatchEqual),
+		teamX,
+	}, opts.RestrictQueryOptions.GetRestrictByTag().GetMatchers())
+
+	// Unlisted identities are denied.
+	ctx = accesscontrol.NewContext(context.Background(), "bob")
+	_, _, err = builder.NewFetchOptions(ctx, req)
+	require.Error(t, err)
+	httpErr, ok := err.(xhttp.Error) // nolint:errorlint
+	require.True(t, ok)
+	require.Equal(t, http.StatusForbidden, httpErr.Code())
+}
+
+func TestFetchOptionsWithRestrictByNamespace(t *testing.T) {
+	aliases, err := storage.NewNamespaceAliases(map[string]string{
+		"metrics_old": "metrics",
+	}, tally.NoopScope)
+	require.NoError(t, err)
+
+	builder, err := NewFetchOptionsBuilder(FetchOptionsBuilderOptions{
+		Timeout:          10 * time.Second,
+		NamespaceAliases: aliases,
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"metrics", "metrics_old"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Add(headers.MetricsRestrictByNamespaceHeader, name)
+		_, opts, err := builder.NewFetchOptions(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, &storage.RestrictByNamespace{Namespace: "metrics"},
+			opts.RestrictQueryOptions.GetRestrictByNamespace())
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add(headers.MetricsRestrictByNamespaceHeader, "metrics")
+	req.Header.Add(headers.MetricsTypeHeader, "unaggregated")
+	_, _, err = builder.NewFetchOptions(context.Background(), req)
+	require.Error(t, err)
+}
+
 func stripSpace(str string) string {
 	return regexp.MustCompile(`\s+`).ReplaceAllString(str, "")
 }
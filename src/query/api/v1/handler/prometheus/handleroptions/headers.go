@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/m3db/m3/src/query/block"
@@ -81,6 +82,11 @@ func AddDBResultResponseHeaders(
 		w.Header().Set(headers.TimeoutHeader, fetchOpts.Timeout.String())
 	}
 
+	if fetchOpts.Sampled() {
+		w.Header().Set(headers.ResultsSampledHeader,
+			strconv.FormatFloat(fetchOpts.SampleRate, 'f', -1, 64))
+	}
+
 	waiting := Waiting{
 		WaitedIndex:      meta.WaitedIndex,
 		WaitedSeriesRead: meta.WaitedSeriesRead,
@@ -114,6 +120,10 @@ func AddDBResultResponseHeaders(
 		w.Header().Add(headers.NamespacesHeader, strings.Join(namespaces, ","))
 	}
 
+	if policies := meta.GetStitchingPolicies(); len(policies) > 0 {
+		w.Header().Add(headers.StitchingPoliciesHeader, strings.Join(policies, ","))
+	}
+
 	if meta.FetchedResponses > 0 {
 		w.Header().Add(headers.FetchedResponsesHeader, fmt.Sprint(meta.FetchedResponses))
 	}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package handleroptions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m3db/m3/src/query/block"
+)
+
+const (
+	// ReturnedDataLimitedWarningName is the name of the warning added when the
+	// returned series are truncated by the returned data limits.
+	ReturnedDataLimitedWarningName = "returned data limited"
+)
+
+type requestWarningsKey struct{}
+
+// WithRequestWarnings returns a copy of the context with warnings about how
+// the request is served, e.g. by a different engine than the one requested,
+// which are surfaced along with the warnings of the result of the request.
+func WithRequestWarnings(ctx context.Context, warnings ...block.Warning) context.Context {
+	existing := RequestWarnings(ctx)
+	combined := make(block.Warnings, 0, len(existing)+len(warnings))
+	combined = append(combined, existing...)
+	combined = append(combined, warnings...)
+	return context.WithValue(ctx, requestWarningsKey{}, combined)
+}
+
+// RequestWarnings returns the warnings about how the request is served set on
+// the context, if any.
+func RequestWarnings(ctx context.Context) block.Warnings {
+	warnings, _ := ctx.Value(requestWarningsKey{}).(block.Warnings)
+	return warnings
+}
+
+// Warning returns the warning to surface when the returned data was limited.
+func (l ReturnedDataLimited) Warning() (block.Warning, bool) {
+	if !l.Limited {
+		return block.Warning{}, false
+	}
+	return block.Warning{
+		Name: ReturnedDataLimitedWarningName,
+		Message: fmt.Sprintf("returned %d of %d series, %d datapoints",
+			l.Series, l.TotalSeries, l.Datapoints),
+	}, true
+}
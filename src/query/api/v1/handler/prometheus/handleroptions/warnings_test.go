@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package handleroptions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m3db/m3/src/query/block"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestWarnings(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RequestWarnings(ctx))
+
+	foo := block.Warning{Name: "foo", Message: "bar"}
+	baz := block.Warning{Name: "baz", Message: "qux"}
+	fooCtx := WithRequestWarnings(ctx, foo)
+	bazCtx := WithRequestWarnings(fooCtx, baz)
+	assert.Equal(t, block.Warnings{foo}, RequestWarnings(fooCtx))
+	assert.Equal(t, block.Warnings{foo, baz}, RequestWarnings(bazCtx))
+}
+
+func TestReturnedDataLimitedWarning(t *testing.T) {
+	_, ok := ReturnedDataLimited{Series: 10, TotalSeries: 10}.Warning()
+	assert.False(t, ok)
+
+	warning, ok := ReturnedDataLimited{
+		Series:      5,
+		TotalSeries: 10,
+		Datapoints:  100,
+		Limited:     true,
+	}.Warning()
+	assert.True(t, ok)
+	assert.Equal(t, "returned data limited_returned 5 of 10 series, 100 datapoints",
+		warning.Header())
+}
@@ -0,0 +1,326 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+)
+
+const (
+	// PromBatchQueryURL is the url for the batch query handler, this executes
+	// multiple queries from a single request body.
+	PromBatchQueryURL = route.Prefix + "/query/batch"
+
+	// PromBatchQueryHTTPMethod is the HTTP method for the batch query handler.
+	PromBatchQueryHTTPMethod = http.MethodPost
+
+	// maxBatchQueries is the max number of queries in a single batch.
+	maxBatchQueries = 128
+	// batchQueryConcurrency is the max number of queries of a single batch
+	// executed concurrently.
+	batchQueryConcurrency = 8
+	// batchResultHeaderPrefix is the prefix of the response headers of each
+	// query returned in the batch response, such as limit warnings.
+	batchResultHeaderPrefix = "M3-"
+)
+
+var (
+	errBatchNoQueries = xerrors.NewInvalidParamsError(
+		errors.New("batch must contain at least one query"))
+	errBatchTooManyQueries = xerrors.NewInvalidParamsError(
+		fmt.Errorf("batch must contain at most %d queries", maxBatchQueries))
+)
+
+// BatchQueryRequest is the body of a batch query request.
+type BatchQueryRequest struct {
+	// Start, End and Step apply to each query that does not set its own.
+	Start   string       `json:"start,omitempty"`
+	End     string       `json:"end,omitempty"`
+	Step    string       `json:"step,omitempty"`
+	Queries []BatchQuery `json:"queries"`
+}
+
+// BatchQuery is a single query of a batch, queries without a start and end
+// are executed as instant queries at Time.
+type BatchQuery struct {
+	ID    string `json:"id"`
+	Query string `json:"query"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	Step  string `json:"step,omitempty"`
+	Time  string `json:"time,omitempty"`
+}
+
+// BatchQueryResponse is the response of a batch query request, keyed by
+// query ID.
+type BatchQueryResponse struct {
+	Results map[string]BatchQueryResult `json:"results"`
+}
+
+// BatchQueryResult is the result of a single query of a batch, the response
+// and M3 headers are the same as the query or query_range endpoint would have
+// returned.
+type BatchQueryResult struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Response   json.RawMessage   `json:"response"`
+}
+
+// promBatchQueryHandler represents a handler for the batch query endpoint.
+type promBatchQueryHandler struct {
+	opts options.HandlerOptions
+}
+
+// NewPromBatchQueryHandler returns a new batch query handler.
+func NewPromBatchQueryHandler(opts options.HandlerOptions) http.Handler {
+	return &promBatchQueryHandler{
+		opts: opts,
+	}
+}
+
+func (h *promBatchQueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context(), h.opts.InstrumentOpts())
+
+	var req BatchQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		xhttp.WriteError(w, xerrors.NewInvalidParamsError(err))
+		return
+	}
+	if err := validateBatchQueryRequest(req); err != nil {
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	// Identical queries, which are common across the panels of a dashboard,
+	// are only executed once.
+	var (
+		byKey = make(map[string]*batchQueryExecution, len(req.Queries))
+		execs = make([]*batchQueryExecution, 0, len(req.Queries))
+		ids   = make(map[string]*batchQueryExecution, len(req.Queries))
+	)
+	for _, query := range req.Queries {
+		exec := newBatchQueryExecution(req, query)
+		key := exec.key()
+		if existing, ok := byKey[key]; ok {
+			exec = existing
+		} else {
+			byKey[key] = exec
+			execs = append(execs, exec)
+		}
+		ids[query.ID] = exec
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, batchQueryConcurrency)
+	)
+	for _, exec := range execs {
+		exec := exec
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			h.execute(r, exec)
+		}()
+	}
+	wg.Wait()
+
+	resp := BatchQueryResponse{
+		Results: make(map[string]BatchQueryResult, len(ids)),
+	}
+	for id, exec := range ids {
+		resp.Results[id] = exec.result()
+	}
+
+	xhttp.WriteJSONResponse(w, resp, logger)
+}
+
+func validateBatchQueryRequest(req BatchQueryRequest) error {
+	if len(req.Queries) == 0 {
+		return errBatchNoQueries
+	}
+	if len(req.Queries) > maxBatchQueries {
+		return errBatchTooManyQueries
+	}
+
+	ids := make(map[string]struct{}, len(req.Queries))
+	for _, query := range req.Queries {
+		if query.ID == "" {
+			return xerrors.NewInvalidParamsError(
+				errors.New("batch query must have an id"))
+		}
+		if _, ok := ids[query.ID]; ok {
+			return xerrors.NewInvalidParamsError(
+				fmt.Errorf("duplicate batch query id: %s", query.ID))
+		}
+		ids[query.ID] = struct{}{}
+	}
+
+	return nil
+}
+
+// execute runs the query through the same query router as the query and
+// query_range endpoints, so that the engine used and the response are the
+// same as if the query was issued on its own.
+func (h *promBatchQueryHandler) execute(r *http.Request, exec *batchQueryExecution) {
+	router, path := h.opts.QueryRouter(), PromReadURL
+	if exec.instant {
+		router, path = h.opts.InstantQueryRouter(), PromReadInstantURL
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, path,
+		strings.NewReader(exec.values.Encode()))
+	if err != nil {
+		xhttp.WriteError(&exec.response, err)
+		return
+	}
+
+	// Headers such as limits and the read consistency apply to each query.
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+	req.Header.Del("Content-Length")
+	req.Header.Set(xhttp.HeaderContentType, xhttp.ContentTypeFormURLEncoded)
+
+	router.ServeHTTP(&exec.response, req)
+}
+
+type batchQueryExecution struct {
+	instant  bool
+	values   url.Values
+	response batchResponseWriter
+}
+
+func newBatchQueryExecution(
+	req BatchQueryRequest,
+	query BatchQuery,
+) *batchQueryExecution {
+	start, end, step := query.Start, query.End, query.Step
+	if start == "" {
+		start = req.Start
+	}
+	if end == "" {
+		end = req.End
+	}
+	if step == "" {
+		step = req.Step
+	}
+
+	values := url.Values{}
+	values.Set(QueryParam, query.Query)
+	instant := start == "" && end == ""
+	if instant {
+		if query.Time != "" {
+			values.Set(timeParam, query.Time)
+		}
+	} else {
+		values.Set(startParam, start)
+		values.Set(endParam, end)
+		values.Set(handleroptions.StepParam, step)
+	}
+
+	return &batchQueryExecution{
+		instant: instant,
+		values:  values,
+	}
+}
+
+func (e *batchQueryExecution) key() string {
+	return fmt.Sprintf("%t?%s", e.instant, e.values.Encode())
+}
+
+func (e *batchQueryExecution) result() BatchQueryResult {
+	statusCode := e.response.statusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	body := bytes.TrimSpace(e.response.body.Bytes())
+	if !json.Valid(body) {
+		// Wrap responses that are not JSON, such as an error written as
+		// plain text, so that the batch response remains valid JSON.
+		body, _ = json.Marshal(map[string]string{
+			"status": "error",
+			"error":  string(body),
+		})
+	}
+
+	var headers map[string]string
+	for name := range e.response.header {
+		if !strings.HasPrefix(name, batchResultHeaderPrefix) {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = e.response.header.Get(name)
+	}
+
+	return BatchQueryResult{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Response:   body,
+	}
+}
+
+// batchResponseWriter buffers the response of a single query of a batch.
+type batchResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *batchResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *batchResponseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *batchResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m3db/m3/src/query/api/v1/options"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/instrument"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testBatchQueryRouter struct {
+	calls int32
+}
+
+func (r *testBatchQueryRouter) Setup(options.QueryRouterOptions) {}
+
+func (r *testBatchQueryRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	atomic.AddInt32(&r.calls, 1)
+	query := req.FormValue(QueryParam)
+	if query == "bad" {
+		xhttp.WriteError(w, xerrors.NewInvalidParamsError(errors.New("bad query")))
+		return
+	}
+
+	w.Header().Set("M3-Test", req.FormValue(startParam))
+	xhttp.WriteJSONResponse(w, map[string]string{
+		"status": "success",
+		"query":  query,
+		"path":   req.URL.Path,
+		"step":   req.FormValue("step"),
+	}, nil)
+}
+
+func TestPromBatchQueryHandler(t *testing.T) {
+	var (
+		rangeRouter   = &testBatchQueryRouter{}
+		instantRouter = &testBatchQueryRouter{}
+		opts          = options.EmptyHandlerOptions().
+				SetInstrumentOpts(instrument.NewOptions()).
+				SetQueryRouter(rangeRouter).
+				SetInstantQueryRouter(instantRouter)
+	)
+
+	body := `{
+		"start": "1600000000",
+		"end": "1600003600",
+		"step": "15s",
+		"queries": [
+			{"id": "a", "query": "up"},
+			{"id": "b", "query": "up"},
+			{"id": "c", "query": "up", "step": "1m"},
+			{"id": "d", "query": "bad"}
+		]
+	}`
+	req := httptest.NewRequest(PromBatchQueryHTTPMethod, PromBatchQueryURL,
+		strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	NewPromBatchQueryHandler(opts).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var resp BatchQueryResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, 4, len(resp.Results))
+
+	// Identical queries a and b are only executed once.
+	require.Equal(t, int32(3), atomic.LoadInt32(&rangeRouter.calls))
+	require.Equal(t, resp.Results["a"], resp.Results["b"])
+
+	result := resp.Results["c"]
+	require.Equal(t, http.StatusOK, result.StatusCode)
+	require.Equal(t, map[string]string{"M3-Test": "1600000000"}, result.Headers)
+	require.JSONEq(t, `{"status":"success","query":"up","path":"`+PromReadURL+
+		`","step":"1m"}`, string(result.Response))
+
+	// Errors are isolated to the query that failed.
+	require.Equal(t, http.StatusBadRequest, resp.Results["d"].StatusCode)
+	var errResp map[string]interface{}
+	require.NoError(t, json.Unmarshal(resp.Results["d"].Response, &errResp))
+	require.Equal(t, "error", errResp["status"])
+
+	require.Equal(t, int32(0), atomic.LoadInt32(&instantRouter.calls))
+}
+
+func TestPromBatchQueryHandlerInstant(t *testing.T) {
+	var (
+		rangeRouter   = &testBatchQueryRouter{}
+		instantRouter = &testBatchQueryRouter{}
+		opts          = options.EmptyHandlerOptions().
+				SetInstrumentOpts(instrument.NewOptions()).
+				SetQueryRouter(rangeRouter).
+				SetInstantQueryRouter(instantRouter)
+	)
+
+	body := `{"queries": [{"id": "a", "query": "up", "time": "1600000000"}]}`
+	req := httptest.NewRequest(PromBatchQueryHTTPMethod, PromBatchQueryURL,
+		strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	NewPromBatchQueryHandler(opts).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var resp BatchQueryResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.JSONEq(t, `{"status":"success","query":"up","path":"`+PromReadInstantURL+
+		`","step":""}`, string(resp.Results["a"].Response))
+	require.Equal(t, int32(0), atomic.LoadInt32(&rangeRouter.calls))
+	require.Equal(t, int32(1), atomic.LoadInt32(&instantRouter.calls))
+}
+
+func TestPromBatchQueryHandlerInvalidRequests(t *testing.T) {
+	opts := options.EmptyHandlerOptions().
+		SetInstrumentOpts(instrument.NewOptions())
+
+	tooMany := make([]string, 0, maxBatchQueries+1)
+	for i := 0; i <= maxBatchQueries; i++ {
+		tooMany = append(tooMany, `{"id": "`+strings.Repeat("a", i+1)+`", "query": "up"}`)
+	}
+
+	for _, body := range []string{
+		`not json`,
+		`{"queries": []}`,
+		`{"queries": [{"query": "up"}]}`,
+		`{"queries": [{"id": "a", "query": "up"}, {"id": "a", "query": "down"}]}`,
+		`{"queries": [` + strings.Join(tooMany, ",") + `]}`,
+	} {
+		req := httptest.NewRequest(PromBatchQueryHTTPMethod, PromBatchQueryURL,
+			strings.NewReader(body))
+		recorder := httptest.NewRecorder()
+		NewPromBatchQueryHandler(opts).ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusBadRequest, recorder.Code, body)
+	}
+}
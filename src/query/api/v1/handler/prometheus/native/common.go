@@ -254,77 +254,21 @@ func RenderResultsJSON(
 	jw.BeginObjectField("result")
 	jw.BeginArray()
 	for _, s := range series {
-		vals := s.Values()
-		length := s.Len()
-
 		// If a limit of the number of datapoints is present, then write
 		// out series' data up until that limit is hit.
 		if opts.ReturnedSeriesLimit > 0 && seriesRendered+1 > opts.ReturnedSeriesLimit {
 			limited = true
 			break
 		}
-		if opts.ReturnedDatapointsLimit > 0 && datapointsRendered+length > opts.ReturnedDatapointsLimit {
+		if opts.ReturnedDatapointsLimit > 0 && datapointsRendered+s.Len() > opts.ReturnedDatapointsLimit {
 			limited = true
 			break
 		}
 
-		hasData := false
-		for i := 0; i < length; i++ {
-			dp := vals.DatapointAt(i)
-
-			// If keepNaNs is set to false and the value is NaN, drop it from the response.
-			// If the series has no datapoints at all then this datapoint iteration will
-			// count zero total and end up skipping writing the series entirely.
-			if !opts.KeepNaNs && math.IsNaN(dp.Value) {
-				continue
-			}
-
-			// Skip points before the query boundary. Ideal place to adjust these
-			// would be at the result node but that would make it inefficient since
-			// we would need to create another block just for the sake of restricting
-			// the bounds.
-			if dp.Timestamp.Before(opts.Start) || dp.Timestamp.After(opts.End) {
-				continue
-			}
-
-			// On first datapoint for the series, write out the series beginning content.
-			if !hasData {
-				jw.BeginObject()
-				jw.BeginObjectField("metric")
-				jw.BeginObject()
-				for _, t := range s.Tags.Tags {
-					jw.BeginObjectBytesField(t.Name)
-					jw.WriteBytesString(t.Value)
-				}
-				jw.EndObject()
-
-				jw.BeginObjectField("values")
-				jw.BeginArray()
-
-				seriesRendered++
-				hasData = true
-			}
-			datapointsRendered++
-
-			jw.BeginArray()
-			jw.WriteInt(int(dp.Timestamp.Seconds()))
-			jw.WriteString(utils.FormatFloat(dp.Value))
-			jw.EndArray()
-		}
-
-		if !hasData {
-			// No datapoints written for series so continue to
-			// next instead of writing the end content.
-			continue
+		if rendered := renderSeriesJSON(jw, s, opts); rendered > 0 {
+			seriesRendered++
+			datapointsRendered += rendered
 		}
-
-		jw.EndArray()
-		fixedStep, ok := s.Values().(ts.FixedResolutionMutableValues)
-		if ok {
-			jw.BeginObjectField("step_size_ms")
-			jw.WriteInt(int(fixedStep.Resolution() / time.Millisecond))
-		}
-		jw.EndObject()
 	}
 	jw.EndArray()
 	jw.EndObject()
@@ -338,6 +282,74 @@ func RenderResultsJSON(
 	}
 }
 
+// renderSeriesJSON renders a series of a range query as a JSON object, returning
+// the count of datapoints rendered. Nothing is rendered for series without any
+// datapoints to render.
+func renderSeriesJSON(
+	jw json.Writer,
+	s *ts.Series,
+	opts RenderResultsOptions,
+) int {
+	var (
+		vals               = s.Values()
+		length             = s.Len()
+		datapointsRendered = 0
+	)
+	for i := 0; i < length; i++ {
+		dp := vals.DatapointAt(i)
+
+		// If keepNaNs is set to false and the value is NaN, drop it from the response.
+		// If the series has no datapoints at all then this datapoint iteration will
+		// count zero total and end up skipping writing the series entirely.
+		if !opts.KeepNaNs && math.IsNaN(dp.Value) {
+			continue
+		}
+
+		// Skip points before the query boundary. Ideal place to adjust these
+		// would be at the result node but that would make it inefficient since
+		// we would need to create another block just for the sake of restricting
+		// the bounds.
+		if dp.Timestamp.Before(opts.Start) || dp.Timestamp.After(opts.End) {
+			continue
+		}
+
+		// On first datapoint for the series, write out the series beginning content.
+		if datapointsRendered == 0 {
+			jw.BeginObject()
+			jw.BeginObjectField("metric")
+			jw.BeginObject()
+			for _, t := range s.Tags.Tags {
+				jw.BeginObjectBytesField(t.Name)
+				jw.WriteBytesString(t.Value)
+			}
+			jw.EndObject()
+
+			jw.BeginObjectField("values")
+			jw.BeginArray()
+		}
+		datapointsRendered++
+
+		jw.BeginArray()
+		jw.WriteInt(int(dp.Timestamp.Seconds()))
+		jw.WriteString(utils.FormatFloat(dp.Value))
+		jw.EndArray()
+	}
+
+	if datapointsRendered == 0 {
+		// No datapoints written for series so nothing to end.
+		return 0
+	}
+
+	jw.EndArray()
+	fixedStep, ok := vals.(ts.FixedResolutionMutableValues)
+	if ok {
+		jw.BeginObjectField("step_size_ms")
+		jw.WriteInt(int(fixedStep.Resolution() / time.Millisecond))
+	}
+	jw.EndObject()
+	return datapointsRendered
+}
+
 // renderResultsInstantaneousJSON renders results in JSON for instant queries.
 func renderResultsInstantaneousJSON(
 	jw json.Writer,
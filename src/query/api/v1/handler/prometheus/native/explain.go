@@ -0,0 +1,230 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/plan"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// PromExplainURL is the url for the query explain handler, this plans
+	// the fanout of a query and returns it without fetching any data.
+	PromExplainURL = route.Prefix + "/query/explain"
+)
+
+// PromExplainHTTPMethods are the HTTP methods for the explain handler.
+var PromExplainHTTPMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+}
+
+// promExplainHandler represents a handler for the query explain endpoint.
+type promExplainHandler struct {
+	opts options.HandlerOptions
+}
+
+// NewPromExplainHandler returns a new query explain handler.
+func NewPromExplainHandler(opts options.HandlerOptions) http.Handler {
+	return &promExplainHandler{
+		opts: opts,
+	}
+}
+
+// ExplainResult is the planned fanout of a query.
+type ExplainResult struct {
+	Query   string         `json:"query"`
+	Start   time.Time      `json:"start"`
+	End     time.Time      `json:"end"`
+	Step    string         `json:"step"`
+	Fetches []FetchExplain `json:"fetches"`
+}
+
+// FetchExplain is the planned fanout of a single series selector.
+type FetchExplain struct {
+	Matchers   string             `json:"matchers"`
+	Start      time.Time          `json:"start"`
+	End        time.Time          `json:"end"`
+	Range      string             `json:"range,omitempty"`
+	Offset     string             `json:"offset,omitempty"`
+	Namespaces []NamespaceExplain `json:"namespaces"`
+}
+
+// NamespaceExplain is the planned fetch of a series selector from a
+// single namespace.
+type NamespaceExplain struct {
+	Storage         string    `json:"storage"`
+	Namespace       string    `json:"namespace"`
+	MetricsType     string    `json:"metricsType"`
+	Resolution      string    `json:"resolution"`
+	Retention       string    `json:"retention"`
+	FanoutType      string    `json:"fanoutType"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	IndexQuery      string    `json:"indexQuery"`
+	EstimatedSeries int       `json:"estimatedSeries"`
+	Exhaustive      bool      `json:"exhaustive"`
+}
+
+func (h *promExplainHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context(), h.opts.InstrumentOpts())
+
+	// Explain as an instant query unless a range is specified.
+	instant := r.FormValue(startParam) == "" && r.FormValue(endParam) == ""
+	ctx, parsed, err := ParseRequest(r.Context(), r, instant, h.opts)
+	if err != nil {
+		logger.Error("could not parse request", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	result, err := explain(ctx, parsed, h.opts)
+	if err != nil {
+		logger.Error("could not explain query", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	xhttp.WriteJSONResponse(w, result, logger)
+}
+
+// explain plans the query and resolves the fanout of each of its series
+// selectors, estimating the matching series from the index only.
+func explain(
+	ctx context.Context,
+	parsed ParsedOptions,
+	handlerOpts options.HandlerOptions,
+) (ExplainResult, error) {
+	var (
+		params    = parsed.Params
+		engine    = handlerOpts.Engine()
+		parseOpts = engine.Options().ParseOptions()
+	)
+	parser, err := promql.Parse(params.Query, params.Step,
+		handlerOpts.TagOptions(), parseOpts)
+	if err != nil {
+		return ExplainResult{}, xerrors.NewInvalidParamsError(err)
+	}
+
+	nodes, edges, err := parser.DAG()
+	if err != nil {
+		return ExplainResult{}, xerrors.NewInvalidParamsError(err)
+	}
+
+	lp, err := plan.NewLogicalPlan(nodes, edges)
+	if err != nil {
+		return ExplainResult{}, err
+	}
+
+	// The physical plan shifts the start back by the range or lookback.
+	pp, err := plan.NewPhysicalPlan(lp, params)
+	if err != nil {
+		return ExplainResult{}, err
+	}
+
+	queryCtx := models.NewQueryContext(ctx,
+		handlerOpts.InstrumentOpts().MetricsScope(),
+		parsed.QueryOpts.QueryContextOptions)
+	fetchOpts, err := parsed.FetchOpts.QueryFetchOptions(queryCtx, params.BlockType)
+	if err != nil {
+		return ExplainResult{}, err
+	}
+
+	result := ExplainResult{
+		Query:   params.Query,
+		Start:   params.Start.ToTime(),
+		End:     params.End.ToTime(),
+		Step:    params.Step.String(),
+		Fetches: make([]FetchExplain, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		op, ok := node.Op.(functions.FetchOp)
+		if !ok {
+			continue
+		}
+
+		query := &storage.FetchQuery{
+			Start:       pp.TimeSpec.Start.Add(-1 * op.Offset).ToTime(),
+			End:         pp.TimeSpec.End.Add(-1 * op.Offset).ToTime(),
+			TagMatchers: op.Matchers,
+			Interval:    pp.TimeSpec.Step,
+		}
+		explanation, err := handlerOpts.Storage().ExplainQuery(ctx, query, fetchOpts)
+		if err != nil {
+			return ExplainResult{}, err
+		}
+
+		result.Fetches = append(result.Fetches, newFetchExplain(op, query, explanation))
+	}
+
+	return result, nil
+}
+
+func newFetchExplain(
+	op functions.FetchOp,
+	query *storage.FetchQuery,
+	explanation storage.QueryExplanation,
+) FetchExplain {
+	fetch := FetchExplain{
+		Matchers:   op.Matchers.String(),
+		Start:      query.Start,
+		End:        query.End,
+		Namespaces: make([]NamespaceExplain, 0, len(explanation.Namespaces)),
+	}
+	if op.Range > 0 {
+		fetch.Range = op.Range.String()
+	}
+	if op.Offset != 0 {
+		fetch.Offset = op.Offset.String()
+	}
+
+	for _, ns := range explanation.Namespaces {
+		fetch.Namespaces = append(fetch.Namespaces, NamespaceExplain{
+			Storage:         ns.Storage,
+			Namespace:       ns.Namespace,
+			MetricsType:     ns.Attributes.MetricsType.String(),
+			Resolution:      ns.Attributes.Resolution.String(),
+			Retention:       ns.Attributes.Retention.String(),
+			FanoutType:      ns.FanoutType,
+			Start:           ns.Start,
+			End:             ns.End,
+			IndexQuery:      ns.IndexQuery,
+			EstimatedSeries: ns.EstimatedSeries,
+			Exhaustive:      ns.Exhaustive,
+		})
+	}
+
+	return fetch
+}
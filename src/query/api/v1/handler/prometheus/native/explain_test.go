@@ -0,0 +1,115 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromExplainHandler(t *testing.T) {
+	setup := newTestSetup(t, nil)
+	setup.Storage.SetExplainQueryResult(storage.QueryExplanation{
+		Namespaces: []storage.NamespaceExplanation{
+			{
+				Storage:    "local_store",
+				FanoutType: "coversAllQueryRange",
+				Namespace:  "metrics_1m",
+				Attributes: storagemetadata.Attributes{
+					MetricsType: storagemetadata.AggregatedMetricsType,
+					Resolution:  time.Minute,
+					Retention:   48 * time.Hour,
+				},
+				IndexQuery:      "conjunction(term(__name__, foo))",
+				EstimatedSeries: 42,
+				Exhaustive:      true,
+			},
+		},
+	}, nil)
+
+	start := time.Unix(1600000000, 0).UTC()
+	vals := url.Values{}
+	vals.Add(QueryParam, "sum(rate(foo[5m])) + bar offset 1h")
+	vals.Add(startParam, start.Format(time.RFC3339))
+	vals.Add(endParam, start.Add(time.Hour).Format(time.RFC3339))
+	vals.Add(handleroptions.StepParam, "10s")
+
+	req := httptest.NewRequest(http.MethodGet, PromExplainURL+"?"+vals.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	NewPromExplainHandler(setup.options).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var result ExplainResult
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	require.Equal(t, "10s", result.Step)
+	require.Equal(t, 2, len(result.Fetches))
+
+	byRange := make(map[string]FetchExplain, len(result.Fetches))
+	for _, fetch := range result.Fetches {
+		require.Equal(t, 1, len(fetch.Namespaces))
+		require.Equal(t, NamespaceExplain{
+			Storage:         "local_store",
+			Namespace:       "metrics_1m",
+			MetricsType:     storagemetadata.AggregatedMetricsType.String(),
+			Resolution:      "1m0s",
+			Retention:       "48h0m0s",
+			FanoutType:      "coversAllQueryRange",
+			IndexQuery:      "conjunction(term(__name__, foo))",
+			EstimatedSeries: 42,
+			Exhaustive:      true,
+		}, fetch.Namespaces[0])
+		byRange[fetch.Range] = fetch
+	}
+
+	// The start is shifted back by the largest range in the query.
+	rate := byRange["5m0s"]
+	require.True(t, start.Add(-5*time.Minute).Equal(rate.Start))
+	// The end is exclusive so includes the last step.
+	require.True(t, start.Add(time.Hour+10*time.Second).Equal(rate.End))
+
+	offset := byRange[""]
+	require.Equal(t, "1h0m0s", offset.Offset)
+	require.True(t, start.Add(-5*time.Minute-time.Hour).Equal(offset.Start))
+	require.True(t, start.Add(10*time.Second).Equal(offset.End))
+}
+
+func TestPromExplainHandlerStorageError(t *testing.T) {
+	setup := newTestSetup(t, nil)
+	setup.Storage.SetExplainQueryResult(storage.QueryExplanation{},
+		errors.New("no namespaces configured"))
+
+	vals := defaultParams()
+	req := httptest.NewRequest(http.MethodGet, PromExplainURL+"?"+vals.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	NewPromExplainHandler(setup.options).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/api/v1/middleware"
 
 	"go.uber.org/zap"
@@ -47,28 +48,32 @@ var WithQueryParams middleware.OverrideOptions = func(opts middleware.Options) m
 		}
 	})
 	opts.Metrics.ParseQueryParams = middlewareParseParams
+	opts.QueryAudit.ParseQueryParams = middlewareParseParams
 	return opts
 }
 
 // WithRangeQueryParamsAndRangeRewriting adds the range query request parameters to the
-// middleware options and enables range rewriting
+// middleware options and enables range rewriting and query cost estimation
 var WithRangeQueryParamsAndRangeRewriting middleware.OverrideOptions = func(
 	opts middleware.Options,
 ) middleware.Options {
 	opts = WithQueryParams(opts)
 	opts.PrometheusRangeRewrite.Enabled = true
+	opts.QueryCost.Enabled = true
 
 	return opts
 }
 
 // WithInstantQueryParamsAndRangeRewriting adds the instant query request parameters to the
-// middleware options and enables range rewriting
+// middleware options and enables range rewriting and query cost estimation
 var WithInstantQueryParamsAndRangeRewriting middleware.OverrideOptions = func(
 	opts middleware.Options,
 ) middleware.Options {
 	opts = WithQueryParams(opts)
 	opts.PrometheusRangeRewrite.Enabled = true
 	opts.PrometheusRangeRewrite.Instant = true
+	opts.QueryCost.Enabled = true
+	opts.QueryCost.Instant = true
 
 	return opts
 }
@@ -81,13 +86,19 @@ var middlewareParseParams middleware.ParseQueryParams = func(r *http.Request, re
 	}
 
 	// N.B - instant queries set startParam/endParam to "now" if not set. ParseTime can handle this special
-	// "now" value. Use when this middleware ran as the approximate now value.
-	start, err := prometheus.ParseTime(r, startParam, requestStart)
+	// "now" value. Use when this middleware ran as the approximate now value, unless the request pins
+	// the evaluation time.
+	now, err := handleroptions.ParseEvaluationTime(r, requestStart)
 	if err != nil {
 		return middleware.QueryParams{}, err
 	}
 
-	end, err := prometheus.ParseTime(r, endParam, requestStart)
+	start, err := prometheus.ParseTime(r, startParam, now)
+	if err != nil {
+		return middleware.QueryParams{}, err
+	}
+
+	end, err := prometheus.ParseTime(r, endParam, now)
 	if err != nil {
 		return middleware.QueryParams{}, err
 	}
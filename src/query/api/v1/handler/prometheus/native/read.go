@@ -21,6 +21,8 @@
 package native
 
 import (
+	"context"
+	goerrors "errors"
 	"net/http"
 
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
@@ -29,6 +31,7 @@ import (
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/util/json"
 	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
 	xhttp "github.com/m3db/m3/src/x/net/http"
 	xopentracing "github.com/m3db/m3/src/x/opentracing"
 
@@ -61,6 +64,9 @@ const (
 )
 
 var (
+	errSampledQueryUnsupported = xerrors.NewInvalidParamsError(
+		goerrors.New("sampled queries are only supported by the prometheus engine"))
+
 	// PromReadHTTPMethods are the HTTP methods for the read handler.
 	PromReadHTTPMethods = []string{
 		http.MethodGet,
@@ -122,6 +128,11 @@ func (h *promReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		xhttp.WriteError(w, rErr)
 		return
 	}
+	if parsedOptions.FetchOpts.Sampled() {
+		h.promReadMetrics.incError(errSampledQueryUnsupported)
+		xhttp.WriteError(w, errSampledQueryUnsupported)
+		return
+	}
 	ctx = logging.NewContext(ctx,
 		iOpts,
 		zap.String("query", parsedOptions.Params.Query),
@@ -132,20 +143,14 @@ func (h *promReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		zap.Duration("fetchTimeout", parsedOptions.FetchOpts.Timeout),
 	)
 
+	if format, ok := parseStreamFormat(r); ok && !h.instant {
+		h.serveStream(ctx, w, parsedOptions, format)
+		return
+	}
+
 	result, err := read(ctx, parsedOptions, h.opts)
 	if err != nil {
-		sp := xopentracing.SpanFromContextOrNoop(ctx)
-		sp.LogFields(opentracinglog.Error(err))
-		opentracingext.Error.Set(sp, true)
-		logger.Error("m3 query error",
-			zap.Error(err),
-			zap.Any("parsedOptions", parsedOptions))
-		h.promReadMetrics.incError(err)
-
-		if errors.IsTimeout(err) {
-			err = errors.NewErrQueryTimeout(err)
-		}
-		xhttp.WriteError(w, err)
+		h.writeReadError(ctx, w, parsedOptions, err)
 		return
 	}
 
@@ -153,6 +158,7 @@ func (h *promReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	h.promReadMetrics.fetchSuccess.Inc(1)
 
+	result.Meta.AddWarnings(handleroptions.RequestWarnings(ctx)...)
 	err = handleroptions.AddDBResultResponseHeaders(w, result.Meta, parsedOptions.FetchOpts)
 	if err != nil {
 		logger.Error("error writing database limit headers", zap.Error(err))
@@ -201,6 +207,11 @@ func (h *promReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		xhttp.WriteError(w, err)
 		return
 	}
+	if warning, ok := limited.Warning(); ok {
+		// NB: the returned data limits are reported by their own header, only
+		// add the warning to the warnings of the response body.
+		result.Meta.AddWarnings(warning)
+	}
 
 	// Write the actual results after having checked for limits and wrote headers if needed.
 	responseWriter := json.NewWriter(w)
@@ -217,3 +228,23 @@ func (h *promReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+func (h *promReadHandler) writeReadError(
+	ctx context.Context,
+	w http.ResponseWriter,
+	parsedOptions ParsedOptions,
+	err error,
+) {
+	sp := xopentracing.SpanFromContextOrNoop(ctx)
+	sp.LogFields(opentracinglog.Error(err))
+	opentracingext.Error.Set(sp, true)
+	logging.WithContext(ctx, h.opts.InstrumentOpts()).Error("m3 query error",
+		zap.Error(err),
+		zap.Any("parsedOptions", parsedOptions))
+	h.promReadMetrics.incError(err)
+
+	if errors.IsTimeout(err) {
+		err = errors.NewErrQueryTimeout(err)
+	}
+	xhttp.WriteError(w, err)
+}
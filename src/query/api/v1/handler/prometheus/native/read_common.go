@@ -177,6 +177,44 @@ func read(
 	parsed ParsedOptions,
 	handlerOpts options.HandlerOptions,
 ) (ReadResult, error) {
+	emptyResult := ReadResult{
+		Meta:      block.NewResultMetadata(),
+		BlockType: block.BlockEmpty,
+	}
+
+	bl, err := executeRead(ctx, parsed, handlerOpts)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	resultMeta := bl.Meta().ResultMetadata
+	seriesList, err := materializeSeries(bl)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	if err := bl.Close(); err != nil {
+		return emptyResult, err
+	}
+
+	seriesList = prometheus.FilterSeriesByOptions(seriesList, parsed.FetchOpts)
+
+	blockType := bl.Info().Type()
+
+	return ReadResult{
+		Series:    seriesList,
+		Meta:      resultMeta,
+		BlockType: blockType,
+	}, nil
+}
+
+// executeRead parses and executes the query, returning the resulting block
+// which must be closed by the caller.
+func executeRead(
+	ctx context.Context,
+	parsed ParsedOptions,
+	handlerOpts options.HandlerOptions,
+) (block.Block, error) {
 	var (
 		opts      = parsed.QueryOpts
 		fetchOpts = parsed.FetchOpts
@@ -194,27 +232,22 @@ func read(
 		xopentracing.Duration("params.step", params.Step),
 	)
 
-	emptyResult := ReadResult{
-		Meta:      block.NewResultMetadata(),
-		BlockType: block.BlockEmpty,
-	}
-
 	// TODO: Capture timing
 	parseOpts := engine.Options().ParseOptions()
 	parser, err := promql.Parse(params.Query, params.Step, tagOpts, parseOpts)
 	if err != nil {
-		return emptyResult, xerrors.NewInvalidParamsError(err)
+		return nil, xerrors.NewInvalidParamsError(err)
 	}
 
-	bl, err := engine.ExecuteExpr(ctx, parser, opts, fetchOpts, params)
-	if err != nil {
-		return emptyResult, err
-	}
+	return engine.ExecuteExpr(ctx, parser, opts, fetchOpts, params)
+}
 
-	resultMeta := bl.Meta().ResultMetadata
+// materializeSeries reads the consolidated values of every series in the
+// block stepwise.
+func materializeSeries(bl block.Block) ([]*ts.Series, error) {
 	it, err := bl.StepIter()
 	if err != nil {
-		return emptyResult, err
+		return nil, err
 	}
 
 	seriesMeta := it.SeriesMeta()
@@ -240,7 +273,7 @@ func read(
 	}
 
 	if err := it.Err(); err != nil {
-		return emptyResult, err
+		return nil, err
 	}
 
 	seriesList := make([]*ts.Series, 0, len(data))
@@ -254,19 +287,7 @@ func read(
 		seriesList = append(seriesList, series)
 	}
 
-	if err := bl.Close(); err != nil {
-		return emptyResult, err
-	}
-
-	seriesList = prometheus.FilterSeriesByOptions(seriesList, fetchOpts)
-
-	blockType := bl.Info().Type()
-
-	return ReadResult{
-		Series:    seriesList,
-		Meta:      resultMeta,
-		BlockType: blockType,
-	}, nil
+	return seriesList, nil
 }
 
 // ReturnedDataLimited are parsed options for the query.
@@ -0,0 +1,373 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"errors"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/json"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/headers"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ContentTypeNDJSON is the content type requested with the Accept header
+	// to stream range query results as newline delimited JSON, with a frame
+	// for each series followed by a final frame with the status of the query.
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// ContentTypeStreamJSON is the content type requested with the Accept
+	// header to stream range query results as a Prometheus compatible JSON
+	// document, flushed to the client after each series is rendered.
+	ContentTypeStreamJSON = "application/stream+json"
+)
+
+type streamFormat uint
+
+const (
+	streamFormatNDJSON streamFormat = iota
+	streamFormatJSON
+)
+
+func (f streamFormat) contentType() string {
+	if f == streamFormatNDJSON {
+		return ContentTypeNDJSON
+	}
+	return ContentTypeStreamJSON
+}
+
+var errStopStream = errors.New("stop stream")
+
+// parseStreamFormat returns the streaming format requested by the Accept
+// header of the request, if any.
+func parseStreamFormat(r *http.Request) (streamFormat, bool) {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			switch mediaType {
+			case ContentTypeNDJSON:
+				return streamFormatNDJSON, true
+			case ContentTypeStreamJSON:
+				return streamFormatJSON, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// streamSeries calls fn with each series of the block in turn, filtered by
+// the fetch options. Blocks that can consolidate their values series by
+// series are streamed without materializing every series, otherwise the
+// block is materialized stepwise first. The series passed to fn is only
+// valid until fn returns. Returning errStopStream from fn stops the stream
+// without an error. Returns the count of series in the block.
+func streamSeries(
+	bl block.Block,
+	fetchOpts *storage.FetchOptions,
+	fn func(s *ts.Series) error,
+) (int, error) {
+	seriesBlock, ok := bl.(block.ConsolidatedSeriesBlock)
+	if !ok {
+		seriesList, err := materializeSeries(bl)
+		if err != nil {
+			return 0, err
+		}
+		seriesList = prometheus.FilterSeriesByOptions(seriesList, fetchOpts)
+		for _, s := range seriesList {
+			if err := fn(s); err != nil {
+				if err == errStopStream {
+					break
+				}
+				return 0, err
+			}
+		}
+		return len(seriesList), nil
+	}
+
+	it, err := seriesBlock.ConsolidatedSeriesIter()
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	var (
+		seriesMeta = it.SeriesMeta()
+		blockTags  = bl.Meta().Tags.Tags
+		bounds     = bl.Meta().Bounds
+		values     = ts.NewFixedStepValues(bounds.StepSize, bounds.Steps(),
+			math.NaN(), bounds.Start)
+		idx = 0
+	)
+	for it.Next() {
+		for i, v := range it.Current() {
+			values.SetValueAt(i, v)
+		}
+
+		var (
+			meta   = seriesMeta[idx]
+			series = []*ts.Series{
+				ts.NewSeries(meta.Name, values, meta.Tags.AddTags(blockTags)),
+			}
+		)
+		idx++
+
+		series = prometheus.FilterSeriesByOptions(series, fetchOpts)
+		if err := fn(series[0]); err != nil {
+			if err == errStopStream {
+				break
+			}
+			return 0, err
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+
+	return len(seriesMeta), nil
+}
+
+// serveStream executes a range query and streams the results series by series
+// instead of buffering the whole response. Since the returned data limits are
+// only known once every series is rendered they are sent as a trailer.
+func (h *promReadHandler) serveStream(
+	ctx context.Context,
+	w http.ResponseWriter,
+	parsedOptions ParsedOptions,
+	format streamFormat,
+) {
+	logger := logging.WithContext(ctx, h.opts.InstrumentOpts())
+
+	bl, err := executeRead(ctx, parsedOptions, h.opts)
+	if err != nil {
+		h.writeReadError(ctx, w, parsedOptions, err)
+		return
+	}
+
+	defer func() {
+		if err := bl.Close(); err != nil {
+			logger.Error("could not close block", zap.Error(err))
+		}
+	}()
+
+	resultMeta := bl.Meta().ResultMetadata
+	resultMeta.AddWarnings(handleroptions.RequestWarnings(ctx)...)
+	w.Header().Set(xhttp.HeaderContentType, format.contentType())
+	w.Header().Set("Trailer", headers.ReturnedDataLimitedHeader)
+	err = handleroptions.AddDBResultResponseHeaders(w, resultMeta, parsedOptions.FetchOpts)
+	if err != nil {
+		logger.Error("error writing database limit headers", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+
+	keepNaNs := h.opts.Config().ResultOptions.KeepNaNs
+	if !keepNaNs {
+		keepNaNs = resultMeta.KeepNaNs
+	}
+
+	var (
+		renderOpts = RenderResultsOptions{
+			Start:                   parsedOptions.Params.Start,
+			End:                     parsedOptions.Params.End,
+			KeepNaNs:                keepNaNs,
+			ReturnedSeriesLimit:     parsedOptions.FetchOpts.ReturnedSeriesLimit,
+			ReturnedDatapointsLimit: parsedOptions.FetchOpts.ReturnedDatapointsLimit,
+		}
+		stream       = newSeriesStream(w, format)
+		renderResult RenderResultsResult
+	)
+
+	stream.begin()
+	renderResult.TotalSeries, err = streamSeries(bl, parsedOptions.FetchOpts,
+		func(s *ts.Series) error {
+			if renderOpts.ReturnedSeriesLimit > 0 &&
+				renderResult.Series+1 > renderOpts.ReturnedSeriesLimit {
+				renderResult.LimitedMaxReturnedData = true
+				return errStopStream
+			}
+			if renderOpts.ReturnedDatapointsLimit > 0 &&
+				renderResult.Datapoints+s.Len() > renderOpts.ReturnedDatapointsLimit {
+				renderResult.LimitedMaxReturnedData = true
+				return errStopStream
+			}
+
+			if rendered := stream.series(s, renderOpts); rendered > 0 {
+				renderResult.Series++
+				renderResult.Datapoints += rendered
+			}
+			return stream.err
+		})
+	if err != nil {
+		h.promReadMetrics.incError(err)
+		logger.Error("m3 query stream error", zap.Error(err))
+	} else {
+		h.promReadMetrics.fetchSuccess.Inc(1)
+	}
+	stream.end(resultMeta.WarningStrings(), err)
+
+	h.promReadMetrics.returnedDataMetrics.FetchDatapoints.RecordValue(float64(renderResult.Datapoints))
+	h.promReadMetrics.returnedDataMetrics.FetchSeries.RecordValue(float64(renderResult.Series))
+
+	limited := &handleroptions.ReturnedDataLimited{
+		Limited:     renderResult.LimitedMaxReturnedData,
+		Series:      renderResult.Series,
+		TotalSeries: renderResult.TotalSeries,
+		Datapoints:  renderResult.Datapoints,
+	}
+	if err := handleroptions.AddReturnedLimitResponseHeaders(w, limited, nil); err != nil {
+		logger.Error("error writing returned data limited trailer", zap.Error(err))
+	}
+
+	if stream.err != nil {
+		logger.Error("failed to stream results", zap.Error(stream.err))
+	}
+}
+
+// seriesStream renders the series of a range query to a response as they
+// are produced, flushing each series to the client.
+type seriesStream struct {
+	w       http.ResponseWriter
+	format  streamFormat
+	jw      json.Writer
+	flusher http.Flusher
+	err     error
+}
+
+func newSeriesStream(w http.ResponseWriter, format streamFormat) *seriesStream {
+	flusher, _ := w.(http.Flusher)
+	return &seriesStream{
+		w:       w,
+		format:  format,
+		jw:      json.NewWriter(w),
+		flusher: flusher,
+	}
+}
+
+func (s *seriesStream) begin() {
+	if s.format == streamFormatNDJSON {
+		return
+	}
+
+	// The status is written last since it is only known after every series
+	// has been streamed.
+	s.jw.BeginObject()
+	s.jw.BeginObjectField("data")
+	s.jw.BeginObject()
+	s.jw.BeginObjectField("resultType")
+	s.jw.WriteString("matrix")
+	s.jw.BeginObjectField("result")
+	s.jw.BeginArray()
+}
+
+func (s *seriesStream) series(series *ts.Series, opts RenderResultsOptions) int {
+	if s.err != nil {
+		return 0
+	}
+
+	if s.format == streamFormatJSON {
+		rendered := renderSeriesJSON(s.jw, series, opts)
+		if rendered > 0 {
+			s.flush()
+		}
+		return rendered
+	}
+
+	// Each frame is a separate JSON document.
+	s.jw = json.NewWriter(s.w)
+	rendered := renderSeriesJSON(s.jw, series, opts)
+	if rendered > 0 {
+		s.endFrame()
+	}
+	return rendered
+}
+
+func (s *seriesStream) end(warnings []string, err error) {
+	if s.format == streamFormatNDJSON {
+		s.jw = json.NewWriter(s.w)
+		s.jw.BeginObject()
+	} else {
+		s.jw.EndArray()
+		s.jw.EndObject()
+	}
+
+	if err != nil {
+		s.jw.BeginObjectField("status")
+		s.jw.WriteString("error")
+		s.jw.BeginObjectField("error")
+		s.jw.WriteString(err.Error())
+	} else {
+		s.jw.BeginObjectField("status")
+		s.jw.WriteString("success")
+	}
+
+	if len(warnings) > 0 {
+		s.jw.BeginObjectField("warnings")
+		s.jw.BeginArray()
+		for _, warn := range warnings {
+			s.jw.WriteString(warn)
+		}
+		s.jw.EndArray()
+	}
+
+	s.jw.EndObject()
+	s.endFrame()
+}
+
+func (s *seriesStream) endFrame() {
+	if s.err = s.jw.Close(); s.err != nil {
+		return
+	}
+	if s.format == streamFormatNDJSON {
+		if _, s.err = s.w.Write([]byte("\n")); s.err != nil {
+			return
+		}
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+func (s *seriesStream) flush() {
+	if s.err = s.jw.Flush(); s.err != nil {
+		return
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/ts"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStreamSeries struct {
+	Metric     map[string]string `json:"metric"`
+	Values     [][]interface{}   `json:"values"`
+	StepSizeMs int               `json:"step_size_ms"`
+}
+
+type testStreamStatus struct {
+	Status   string   `json:"status"`
+	Error    string   `json:"error"`
+	Warnings []string `json:"warnings"`
+}
+
+// nonSeriesBlock hides the series-wise iteration of the wrapped block.
+type nonSeriesBlock struct {
+	block.Block
+}
+
+func newTestStreamBlock() (block.Block, RenderResultsOptions) {
+	start := xtime.Now().Truncate(time.Minute)
+	bounds := models.Bounds{
+		Start:    start,
+		Duration: 3 * time.Minute,
+		StepSize: time.Minute,
+	}
+	bl := test.NewBlockFromValues(bounds, [][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 8, 9},
+	})
+	return bl, RenderResultsOptions{
+		Start: start,
+		End:   bounds.End(),
+	}
+}
+
+func streamTestBlock(
+	t *testing.T,
+	bl block.Block,
+	format streamFormat,
+	opts RenderResultsOptions,
+) (*httptest.ResponseRecorder, RenderResultsResult) {
+	var (
+		w      = httptest.NewRecorder()
+		stream = newSeriesStream(w, format)
+		result RenderResultsResult
+		err    error
+	)
+	stream.begin()
+	result.TotalSeries, err = streamSeries(bl, nil, func(s *ts.Series) error {
+		if opts.ReturnedSeriesLimit > 0 && result.Series+1 > opts.ReturnedSeriesLimit {
+			result.LimitedMaxReturnedData = true
+			return errStopStream
+		}
+		if rendered := stream.series(s, opts); rendered > 0 {
+			result.Series++
+			result.Datapoints += rendered
+		}
+		return stream.err
+	})
+	require.NoError(t, err)
+	stream.end([]string{"warning"}, nil)
+	require.NoError(t, stream.err)
+	return w, result
+}
+
+func TestParseStreamFormat(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected streamFormat
+		ok       bool
+	}{
+		{accept: "", ok: false},
+		{accept: "application/json", ok: false},
+		{accept: "application/x-ndjson", expected: streamFormatNDJSON, ok: true},
+		{accept: "text/plain, application/x-ndjson; q=0.9", expected: streamFormatNDJSON, ok: true},
+		{accept: "application/stream+json", expected: streamFormatJSON, ok: true},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, PromReadURL, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		format, ok := parseStreamFormat(req)
+		assert.Equal(t, tt.ok, ok, tt.accept)
+		if tt.ok {
+			assert.Equal(t, tt.expected, format, tt.accept)
+		}
+	}
+}
+
+func TestStreamSeriesNDJSON(t *testing.T) {
+	consolidated, opts := newTestStreamBlock()
+	for _, bl := range []block.Block{consolidated, nonSeriesBlock{Block: consolidated}} {
+		w, result := streamTestBlock(t, bl, streamFormatNDJSON, opts)
+		assert.Equal(t, RenderResultsResult{
+			Series:      3,
+			Datapoints:  9,
+			TotalSeries: 3,
+		}, result)
+
+		lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+		require.Len(t, lines, 4)
+		for i, line := range lines[:3] {
+			var s testStreamSeries
+			require.NoError(t, json.Unmarshal([]byte(line), &s))
+			name := "dummy" + string(rune('0'+i))
+			assert.Equal(t, map[string]string{"__name__": name, name: name}, s.Metric)
+			require.Len(t, s.Values, 3)
+			assert.Equal(t, 60000, s.StepSizeMs)
+		}
+
+		var status testStreamStatus
+		require.NoError(t, json.Unmarshal([]byte(lines[3]), &status))
+		assert.Equal(t, testStreamStatus{
+			Status:   "success",
+			Warnings: []string{"warning"},
+		}, status)
+	}
+}
+
+func TestStreamSeriesJSON(t *testing.T) {
+	bl, opts := newTestStreamBlock()
+	opts.ReturnedSeriesLimit = 2
+	w, result := streamTestBlock(t, bl, streamFormatJSON, opts)
+	assert.Equal(t, RenderResultsResult{
+		Series:                 2,
+		Datapoints:             6,
+		TotalSeries:            3,
+		LimitedMaxReturnedData: true,
+	}, result)
+
+	var resp struct {
+		testStreamStatus
+		Data struct {
+			ResultType string             `json:"resultType"`
+			Result     []testStreamSeries `json:"result"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(bytes.NewReader(w.Body.Bytes())).Decode(&resp))
+	assert.Equal(t, "success", resp.Status)
+	assert.Equal(t, []string{"warning"}, resp.Warnings)
+	assert.Equal(t, "matrix", resp.Data.ResultType)
+	require.Len(t, resp.Data.Result, 2)
+	assert.Equal(t, []interface{}{
+		float64(opts.Start.Seconds()), "1",
+	}, resp.Data.Result[0].Values[0])
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// customBucketsSchema is the schema of histograms with custom bucket
+	// boundaries rather than exponential buckets.
+	customBucketsSchema   = -53
+	minExponentialSchema  = -4
+	maxExponentialSchema  = 8
+	bucketSuffix          = "_bucket"
+	negativeBucketSuffix  = "_negative_bucket"
+	countSuffix           = "_count"
+	sumSuffix             = "_sum"
+	zeroCountSuffix       = "_zero_count"
+	schemaLabel           = "schema"
+	bucketIndexLabel      = "bucket"
+	positiveInfinityLabel = "+Inf"
+)
+
+var (
+	errHistogramNoMetricName       = errors.New("histogram series has no metric name")
+	errCustomBucketsNegativeBucket = errors.New("histogram with custom buckets has negative buckets")
+	errCustomBucketsUnsorted       = errors.New("histogram custom bucket boundaries are not sorted")
+)
+
+// histogramBucket is a native histogram bucket with its absolute count.
+type histogramBucket struct {
+	index int32
+	count float64
+}
+
+func (t writeV2Translator) histogramSeries(
+	labels []prompb.Label,
+	series writeV2TimeSeries,
+) ([]prompb.TimeSeries, error) {
+	metricType := series.metricType
+	if metricType != prompb.MetricType_GAUGE_HISTOGRAM {
+		metricType = prompb.MetricType_HISTOGRAM
+		if series.histograms[0].resetHint == writeV2ResetHintGauge {
+			metricType = prompb.MetricType_GAUGE_HISTOGRAM
+		}
+	}
+
+	b, err := newHistogramSeriesBuilder(labels, metricType)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range series.histograms {
+		if h.schema != customBucketsSchema &&
+			(h.schema < minExponentialSchema || h.schema > maxExponentialSchema) {
+			return nil, fmt.Errorf("invalid histogram schema: %d", h.schema)
+		}
+
+		positive, err := expandHistogramBuckets(h.positiveSpans, h.positiveDeltas,
+			h.positiveCounts, h.isFloat)
+		if err != nil {
+			return nil, err
+		}
+		negative, err := expandHistogramBuckets(h.negativeSpans, h.negativeDeltas,
+			h.negativeCounts, h.isFloat)
+		if err != nil {
+			return nil, err
+		}
+
+		if h.schema == customBucketsSchema ||
+			t.histograms == config.BucketSeriesNativeHistogramRepresentation {
+			err = b.addBucketSeries(h, positive, negative)
+		} else {
+			b.addNativeBuckets(h, positive, negative)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if t.ingestCreatedTimestamps && metricType == prompb.MetricType_HISTOGRAM {
+		for i := range b.series {
+			b.series[i].Samples = withCreatedTimestampSample(b.series[i].Samples,
+				series.createdTimestamp)
+		}
+	}
+	return b.series, nil
+}
+
+// expandHistogramBuckets returns the buckets described by the spans with
+// absolute counts, integer histograms have delta encoded bucket counts.
+func expandHistogramBuckets(
+	spans []writeV2BucketSpan,
+	deltas []int64,
+	counts []float64,
+	isFloat bool,
+) ([]histogramBucket, error) {
+	var numBuckets int
+	for _, span := range spans {
+		numBuckets += int(span.length)
+	}
+	numCounts := len(deltas)
+	if isFloat {
+		numCounts = len(counts)
+	}
+	if numBuckets != numCounts {
+		return nil, fmt.Errorf("histogram spans describe %d buckets but have %d counts",
+			numBuckets, numCounts)
+	}
+
+	var (
+		buckets = make([]histogramBucket, 0, numBuckets)
+		index   int32
+		count   int64
+	)
+	for _, span := range spans {
+		// NB: the offset of the first span is the index of the first bucket,
+		// subsequent offsets are the gap since the previous span.
+		index += span.offset
+		for i := uint32(0); i < span.length; i++ {
+			bucket := histogramBucket{index: index}
+			if isFloat {
+				bucket.count = counts[len(buckets)]
+			} else {
+				count += deltas[len(buckets)]
+				if count < 0 {
+					return nil, fmt.Errorf("histogram bucket has negative count: %d", count)
+				}
+				bucket.count = float64(count)
+			}
+			buckets = append(buckets, bucket)
+			index++
+		}
+	}
+	return buckets, nil
+}
+
+// exponentialBucketUpperBound returns the upper bound of the positive bucket
+// with the index, bucket boundaries are powers of 2^(2^-schema).
+func exponentialBucketUpperBound(schema, index int32) float64 {
+	return math.Exp2(float64(index) * math.Exp2(-float64(schema)))
+}
+
+func formatBucketBound(v float64) string {
+	if math.IsInf(v, 1) {
+		return positiveInfinityLabel
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// histogramSeriesBuilder builds the series that native histogram samples of
+// a series are translated to.
+type histogramSeriesBuilder struct {
+	labels     []prompb.Label
+	name       []byte
+	metricType prompb.MetricType
+	series     []prompb.TimeSeries
+	indexByKey map[string]int
+}
+
+func newHistogramSeriesBuilder(
+	labels []prompb.Label,
+	metricType prompb.MetricType,
+) (*histogramSeriesBuilder, error) {
+	b := &histogramSeriesBuilder{
+		labels:     make([]prompb.Label, 0, len(labels)),
+		metricType: metricType,
+		indexByKey: make(map[string]int),
+	}
+	for _, l := range labels {
+		if string(l.Name) == model.MetricNameLabel {
+			b.name = l.Value
+			continue
+		}
+		b.labels = append(b.labels, l)
+	}
+	if len(b.name) == 0 {
+		return nil, errHistogramNoMetricName
+	}
+	return b, nil
+}
+
+func (b *histogramSeriesBuilder) add(
+	suffix string,
+	extra []prompb.Label,
+	sample prompb.Sample,
+) {
+	key := suffix
+	for _, l := range extra {
+		key += "\xff" + string(l.Name) + "=" + string(l.Value)
+	}
+
+	idx, ok := b.indexByKey[key]
+	if !ok {
+		name := make([]byte, 0, len(b.name)+len(suffix))
+		name = append(append(name, b.name...), suffix...)
+
+		labels := make([]prompb.Label, 0, len(b.labels)+1+len(extra))
+		labels = append(labels, prompb.Label{Name: []byte(model.MetricNameLabel), Value: name})
+		labels = append(labels, b.labels...)
+		labels = append(labels, extra...)
+
+		idx = len(b.series)
+		b.indexByKey[key] = idx
+		b.series = append(b.series, prompb.TimeSeries{
+			Labels: labels,
+			Type:   b.metricType,
+		})
+	}
+	b.series[idx].Samples = append(b.series[idx].Samples, sample)
+}
+
+// addBucketSeries adds the histogram as classic histogram series with a
+// cumulative bucket per bucket boundary.
+func (b *histogramSeriesBuilder) addBucketSeries(
+	h writeV2Histogram,
+	positive, negative []histogramBucket,
+) error {
+	var cumulative float64
+	addBucket := func(upperBound, count float64) {
+		cumulative += count
+		b.add(bucketSuffix, []prompb.Label{{
+			Name:  []byte(model.BucketLabel),
+			Value: []byte(formatBucketBound(upperBound)),
+		}}, prompb.Sample{Timestamp: h.timestamp, Value: cumulative})
+	}
+
+	if h.schema == customBucketsSchema {
+		if len(negative) > 0 {
+			return errCustomBucketsNegativeBucket
+		}
+		for i := 1; i < len(h.customValues); i++ {
+			if h.customValues[i] <= h.customValues[i-1] {
+				return errCustomBucketsUnsorted
+			}
+		}
+		for _, bucket := range positive {
+			if bucket.index < 0 || int(bucket.index) > len(h.customValues) {
+				return fmt.Errorf("histogram custom bucket index out of range: %d", bucket.index)
+			}
+			if int(bucket.index) == len(h.customValues) {
+				// NB: the last bucket is the +Inf bucket added below.
+				continue
+			}
+			addBucket(h.customValues[bucket.index], bucket.count)
+		}
+	} else {
+		// Negative buckets in increasing order of upper bound, the upper bound
+		// of a negative bucket is the negated lower bound of the positive
+		// bucket with the same index.
+		for i := len(negative) - 1; i >= 0; i-- {
+			bucket := negative[i]
+			addBucket(-exponentialBucketUpperBound(h.schema, bucket.index-1), bucket.count)
+		}
+		addBucket(h.zeroThreshold, h.zeroCount())
+		for _, bucket := range positive {
+			addBucket(exponentialBucketUpperBound(h.schema, bucket.index), bucket.count)
+		}
+	}
+
+	b.add(bucketSuffix, []prompb.Label{{
+		Name:  []byte(model.BucketLabel),
+		Value: []byte(positiveInfinityLabel),
+	}}, prompb.Sample{Timestamp: h.timestamp, Value: h.count()})
+	b.addCountAndSum(h)
+	return nil
+}
+
+// addNativeBuckets adds the histogram in its native bucket layout with a
+// series per bucket index.
+func (b *histogramSeriesBuilder) addNativeBuckets(
+	h writeV2Histogram,
+	positive, negative []histogramBucket,
+) {
+	schema := []byte(strconv.Itoa(int(h.schema)))
+	addBuckets := func(suffix string, buckets []histogramBucket) {
+		for _, bucket := range buckets {
+			b.add(suffix, []prompb.Label{
+				{Name: []byte(schemaLabel), Value: schema},
+				{Name: []byte(bucketIndexLabel), Value: []byte(strconv.Itoa(int(bucket.index)))},
+			}, prompb.Sample{Timestamp: h.timestamp, Value: bucket.count})
+		}
+	}
+	addBuckets(negativeBucketSuffix, negative)
+	addBuckets(bucketSuffix, positive)
+	b.add(zeroCountSuffix, []prompb.Label{{
+		Name:  []byte(model.BucketLabel),
+		Value: []byte(formatBucketBound(h.zeroThreshold)),
+	}}, prompb.Sample{Timestamp: h.timestamp, Value: h.zeroCount()})
+	b.addCountAndSum(h)
+}
+
+func (b *histogramSeriesBuilder) addCountAndSum(h writeV2Histogram) {
+	b.add(countSuffix, nil, prompb.Sample{Timestamp: h.timestamp, Value: h.count()})
+	b.add(sumSuffix, nil, prompb.Sample{Timestamp: h.timestamp, Value: h.sum})
+}
@@ -27,8 +27,10 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -82,6 +84,18 @@ const (
 	// literalPrefixLength is the length of the label literal prefix that is logged upon
 	// "literal is too long" error.
 	literalPrefixLength = 100
+
+	// remoteWriteContentType is the media type of remote write requests, the
+	// proto parameter names the message of the request body.
+	remoteWriteContentType    = "application/x-protobuf"
+	remoteWriteV1ProtoMessage = "prometheus.WriteRequest"
+	remoteWriteV2ProtoMessage = "io.prometheus.write.v2.Request"
+	remoteWriteEncoding       = "snappy"
+
+	// Remote write 2.0 responses report what was written with these headers.
+	remoteWriteSamplesWrittenHeader    = "X-Prometheus-Remote-Write-Samples-Written"
+	remoteWriteHistogramsWrittenHeader = "X-Prometheus-Remote-Write-Histograms-Written"
+	remoteWriteExemplarsWrittenHeader  = "X-Prometheus-Remote-Write-Exemplars-Written"
 )
 
 var (
@@ -89,6 +103,7 @@ var (
 	errNoTagOptions                 = errors.New("no tag options set")
 	errNoNowFn                      = errors.New("no now fn set")
 	errUnaggregatedStoragePolicySet = errors.New("storage policy should not be set for unaggregated metrics")
+	errUnsupportedMediaType         = errors.New("unsupported remote write media type")
 
 	defaultForwardingRetryForever = false
 	defaultForwardingRetryJitter  = true
@@ -131,6 +146,9 @@ type PromWriteHandler struct {
 	nowFn                  clock.NowFn
 	instrumentOpts         instrument.Options
 	metrics                promWriteMetrics
+	writeV2Translator      writeV2Translator
+	writeAuditor           ingest.WriteAuditor
+	namespaceProvisioner   ingest.NamespaceProvisioner
 
 	// Counting the number of times of "literal is too long" error for log sampling purposes.
 	numLiteralIsTooLong uint32
@@ -143,6 +161,7 @@ func NewPromWriteHandler(options options.HandlerOptions) (http.Handler, error) {
 		tagOptions           = options.TagOptions()
 		nowFn                = options.NowFn()
 		forwarding           = options.Config().WriteForwarding.PromRemoteWrite
+		remoteWrite          = options.Config().PrometheusRemoteWrite
 		instrumentOpts       = options.InstrumentOpts()
 	)
 
@@ -204,6 +223,12 @@ func NewPromWriteHandler(options options.HandlerOptions) (http.Handler, error) {
 		nowFn:                  nowFn,
 		metrics:                metrics,
 		instrumentOpts:         instrumentOpts,
+		writeV2Translator: writeV2Translator{
+			histograms:              remoteWrite.NativeHistogramsOrDefault(),
+			ingestCreatedTimestamps: remoteWrite.IngestCreatedTimestamps,
+		},
+		writeAuditor:         options.WriteAuditor(),
+		namespaceProvisioner: options.NamespaceProvisioner(),
 	}, nil
 }
 
@@ -326,6 +351,9 @@ func (h *PromWriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	h.maybeAudit(r, req, opts)
+	h.maybeProvisionNamespaces(req)
+
 	batchErr := h.write(r.Context(), req, opts)
 
 	// Record ingestion delay latency
@@ -403,6 +431,12 @@ func (h *PromWriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if stats := checkedReq.WriteV2Stats; stats != nil {
+		w.Header().Set(remoteWriteSamplesWrittenHeader, strconv.Itoa(stats.samples))
+		w.Header().Set(remoteWriteHistogramsWrittenHeader, strconv.Itoa(stats.histograms))
+		w.Header().Set(remoteWriteExemplarsWrittenHeader, strconv.Itoa(stats.exemplars))
+	}
+
 	// NB(schallert): this is frustrating but if we don't explicitly write an HTTP
 	// status code (or via Write()), OpenTracing middleware reports code=0 and
 	// shows up as error.
@@ -414,6 +448,8 @@ type parseRequestResult struct {
 	Request        *prompb.WriteRequest
 	Options        ingest.WriteOptions
 	CompressResult prometheus.ParsePromCompressedRequestResult
+	// WriteV2Stats is set for remote write 2.0 requests.
+	WriteV2Stats *writeV2Stats
 }
 
 func (h *PromWriteHandler) checkedParseRequest(
@@ -421,12 +457,38 @@ func (h *PromWriteHandler) checkedParseRequest(
 ) (parseRequestResult, error) {
 	result, err := h.parseRequest(r)
 	if err != nil {
+		if errors.Is(err, errUnsupportedMediaType) {
+			return parseRequestResult{}, xhttp.NewError(err, http.StatusUnsupportedMediaType)
+		}
 		// Always invalid request if parsing fails params.
 		return parseRequestResult{}, xerrors.NewInvalidParamsError(err)
 	}
 	return result, nil
 }
 
+// remoteWriteProtoMessage returns the proto message of the request body
+// negotiated by the request Content-Type. Requests without a remote write
+// Content-Type are remote write 1.0 requests for backwards compatibility.
+func remoteWriteProtoMessage(r *http.Request) (string, error) {
+	if v := r.Header.Get("Content-Encoding"); v != "" && !strings.EqualFold(v, remoteWriteEncoding) {
+		return "", fmt.Errorf("%w: content encoding %s", errUnsupportedMediaType, v)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get(xhttp.HeaderContentType))
+	if err != nil || mediaType != remoteWriteContentType {
+		return remoteWriteV1ProtoMessage, nil
+	}
+
+	switch message := params["proto"]; message {
+	case "", remoteWriteV1ProtoMessage:
+		return remoteWriteV1ProtoMessage, nil
+	case remoteWriteV2ProtoMessage:
+		return remoteWriteV2ProtoMessage, nil
+	default:
+		return "", fmt.Errorf("%w: proto %s", errUnsupportedMediaType, message)
+	}
+}
+
 // parseRequest extracts the Prometheus write request from the request body and
 // headers. WARNING: it is not guaranteed that the tags returned in the request
 // body are in sorted order. It is expected that the caller ensures the tags are
@@ -437,6 +499,11 @@ func (h *PromWriteHandler) checkedParseRequest(
 func (h *PromWriteHandler) parseRequest(
 	r *http.Request,
 ) (parseRequestResult, error) {
+	protoMessage, err := remoteWriteProtoMessage(r)
+	if err != nil {
+		return parseRequestResult{}, err
+	}
+
 	var opts ingest.WriteOptions
 	if v := strings.TrimSpace(r.Header.Get(headers.MetricsTypeHeader)); v != "" {
 		// Allow the metrics type and storage policies to override
@@ -489,9 +556,35 @@ func (h *PromWriteHandler) parseRequest(
 		return parseRequestResult{}, err
 	}
 
-	var req prompb.WriteRequest
-	if err := proto.Unmarshal(result.UncompressedBody, &req); err != nil {
-		return parseRequestResult{}, err
+	var (
+		req          prompb.WriteRequest
+		writeV2Stats *writeV2Stats
+	)
+	switch protoMessage {
+	case remoteWriteV2ProtoMessage:
+		writeV2Req, err := unmarshalWriteV2Request(result.UncompressedBody)
+		if err != nil {
+			return parseRequestResult{}, err
+		}
+		translated, stats, err := h.writeV2Translator.translate(writeV2Req)
+		if err != nil {
+			return parseRequestResult{}, err
+		}
+		req, writeV2Stats = translated, &stats
+
+		if len(h.forwarding.Targets) > 0 {
+			// Forward the translated request since forwarding targets are
+			// only guaranteed to accept remote write 1.0 requests.
+			encoded, err := proto.Marshal(&req)
+			if err != nil {
+				return parseRequestResult{}, err
+			}
+			result.CompressedBody = snappy.Encode(nil, encoded)
+		}
+	default:
+		if err := proto.Unmarshal(result.UncompressedBody, &req); err != nil {
+			return parseRequestResult{}, err
+		}
 	}
 
 	if mapStr := r.Header.Get(headers.MapTagsByJSONHeader); mapStr != "" {
@@ -533,6 +626,7 @@ func (h *PromWriteHandler) parseRequest(
 		Request:        &req,
 		Options:        opts,
 		CompressResult: result,
+		WriteV2Stats:   writeV2Stats,
 	}, nil
 }
 
@@ -549,6 +643,50 @@ func (h *PromWriteHandler) write(
 	return h.downsamplerAndWriter.WriteBatch(ctx, iter, opts)
 }
 
+// maybeAudit logs a sample of the series in the request that match the
+// write audit matchers, if write auditing is enabled.
+func (h *PromWriteHandler) maybeAudit(
+	r *http.Request,
+	req *prompb.WriteRequest,
+	opts ingest.WriteOptions,
+) {
+	if h.writeAuditor == nil || !h.writeAuditor.Enabled() {
+		return
+	}
+
+	identity := r.Header.Get(headers.SourceHeader)
+	if identity == "" {
+		identity = r.UserAgent()
+	}
+	source := ingest.WriteAuditSource{
+		RemoteAddr: r.RemoteAddr,
+		Identity:   identity,
+		Options:    opts,
+	}
+	for _, series := range req.Timeseries {
+		h.writeAuditor.Audit(storage.PromLabelsToM3Tags(series.Labels, h.tagOptions), source)
+	}
+}
+
+// maybeProvisionNamespaces notes the tenants of the series in the request
+// so that namespaces are provisioned for new tenants, if namespace
+// provisioning is enabled.
+func (h *PromWriteHandler) maybeProvisionNamespaces(req *prompb.WriteRequest) {
+	if h.namespaceProvisioner == nil {
+		return
+	}
+
+	tenantTag := h.namespaceProvisioner.TenantTag()
+	for _, series := range req.Timeseries {
+		for _, label := range series.Labels {
+			if bytes.Equal(label.Name, tenantTag) {
+				h.namespaceProvisioner.Observe(label.Value)
+				break
+			}
+		}
+	}
+}
+
 func (h *PromWriteHandler) forward(
 	ctx context.Context,
 	res parseRequestResult,
@@ -719,6 +857,16 @@ func (h *PromWriteHandler) maybeLogLabelsWithTooLongLiterals(logger *zap.Logger,
 	)
 }
 
+// NewWriteRequestIter returns an iterator over the series of a remote write
+// request for writing with a downsampler and writer.
+func NewWriteRequestIter(
+	req *prompb.WriteRequest,
+	tagOpts models.TagOptions,
+	storeMetricsType bool,
+) (ingest.DownsampleAndWriteIter, error) {
+	return newPromTSIter(req.Timeseries, tagOpts, storeMetricsType)
+}
+
 func newPromTSIter(
 	timeseries []prompb.TimeSeries,
 	tagOpts models.TagOptions,
@@ -111,6 +111,85 @@ func TestPromWrite(t *testing.T) {
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+type testWriteAuditor struct {
+	audited []ingest.WriteAuditSource
+}
+
+func (a *testWriteAuditor) Enabled() bool { return true }
+
+func (a *testWriteAuditor) Audit(_ models.Tags, source ingest.WriteAuditSource) {
+	a.audited = append(a.audited, source)
+}
+
+func (a *testWriteAuditor) Close() {}
+
+func TestPromWriteAudit(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.
+		EXPECT().
+		WriteBatch(gomock.Any(), gomock.Any(), gomock.Any())
+
+	auditor := &testWriteAuditor{}
+	opts := makeOptions(mockDownsamplerAndWriter).SetWriteAuditor(auditor)
+	handler, err := NewPromWriteHandler(opts)
+	require.NoError(t, err)
+
+	promReq := test.GeneratePromWriteRequest()
+	promReqBody := test.GeneratePromWriteRequestBody(t, promReq)
+	req := httptest.NewRequest(PromWriteHTTPMethod, PromWriteURL, promReqBody)
+	req.Header.Set(headers.SourceHeader, "test-producer")
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, req)
+	require.Equal(t, http.StatusOK, writer.Result().StatusCode)
+
+	require.Len(t, auditor.audited, len(promReq.Timeseries))
+	for _, source := range auditor.audited {
+		assert.Equal(t, req.RemoteAddr, source.RemoteAddr)
+		assert.Equal(t, "test-producer", source.Identity)
+	}
+}
+
+type testNamespaceProvisioner struct {
+	observed []string
+}
+
+func (p *testNamespaceProvisioner) TenantTag() []byte { return []byte("foo") }
+
+func (p *testNamespaceProvisioner) Observe(tenant []byte) {
+	p.observed = append(p.observed, string(tenant))
+}
+
+func (p *testNamespaceProvisioner) Close() {}
+
+func TestPromWriteProvisionsNamespaces(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.
+		EXPECT().
+		WriteBatch(gomock.Any(), gomock.Any(), gomock.Any())
+
+	provisioner := &testNamespaceProvisioner{}
+	opts := makeOptions(mockDownsamplerAndWriter).SetNamespaceProvisioner(provisioner)
+	handler, err := NewPromWriteHandler(opts)
+	require.NoError(t, err)
+
+	promReq := test.GeneratePromWriteRequest()
+	promReqBody := test.GeneratePromWriteRequestBody(t, promReq)
+	req := httptest.NewRequest(PromWriteHTTPMethod, PromWriteURL, promReqBody)
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, req)
+	require.Equal(t, http.StatusOK, writer.Result().StatusCode)
+
+	assert.Equal(t, []string{"bar", "qux"}, provisioner.observed)
+}
+
 func TestPromWriteError(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,503 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var errWriteV2InvalidSymbols = errors.New("symbols must start with an empty string")
+
+// The remote write 2.0 messages are decoded by hand from the wire format
+// since only the subset of fields that can be stored is retained, see
+// https://prometheus.io/docs/specs/remote_write_spec_2_0/ for the schema.
+
+// writeV2Request is an io.prometheus.write.v2.Request.
+type writeV2Request struct {
+	symbols    [][]byte
+	timeseries []writeV2TimeSeries
+}
+
+// writeV2TimeSeries is an io.prometheus.write.v2.TimeSeries.
+type writeV2TimeSeries struct {
+	labelRefs        []uint32
+	samples          []prompb.Sample
+	histograms       []writeV2Histogram
+	numExemplars     int
+	metricType       prompb.MetricType
+	helpRef          uint32
+	unitRef          uint32
+	createdTimestamp int64
+}
+
+// writeV2Histogram is an io.prometheus.write.v2.Histogram, integer
+// histograms have their bucket counts delta encoded while float histograms
+// have absolute bucket counts.
+type writeV2Histogram struct {
+	isFloat        bool
+	countInt       uint64
+	countFloat     float64
+	sum            float64
+	schema         int32
+	zeroThreshold  float64
+	zeroCountInt   uint64
+	zeroCountFloat float64
+	negativeSpans  []writeV2BucketSpan
+	negativeDeltas []int64
+	negativeCounts []float64
+	positiveSpans  []writeV2BucketSpan
+	positiveDeltas []int64
+	positiveCounts []float64
+	resetHint      writeV2ResetHint
+	timestamp      int64
+	customValues   []float64
+}
+
+func (h writeV2Histogram) count() float64 {
+	if h.isFloat {
+		return h.countFloat
+	}
+	return float64(h.countInt)
+}
+
+func (h writeV2Histogram) zeroCount() float64 {
+	if h.isFloat {
+		return h.zeroCountFloat
+	}
+	return float64(h.zeroCountInt)
+}
+
+type writeV2BucketSpan struct {
+	offset int32
+	length uint32
+}
+
+type writeV2ResetHint int32
+
+const writeV2ResetHintGauge writeV2ResetHint = 3
+
+// writeV2Stats are the number of samples, histograms and exemplars written
+// for a remote write 2.0 request, reported back to the client in response
+// headers.
+type writeV2Stats struct {
+	samples    int
+	histograms int
+	exemplars  int
+}
+
+// writeV2Translator translates remote write 2.0 requests to the remote write
+// 1.0 series that are written.
+type writeV2Translator struct {
+	histograms              config.NativeHistogramRepresentation
+	ingestCreatedTimestamps bool
+}
+
+func (t writeV2Translator) translate(
+	req writeV2Request,
+) (prompb.WriteRequest, writeV2Stats, error) {
+	var (
+		result prompb.WriteRequest
+		stats  writeV2Stats
+	)
+	if len(req.symbols) > 0 && len(req.symbols[0]) != 0 {
+		return result, stats, errWriteV2InvalidSymbols
+	}
+
+	for _, series := range req.timeseries {
+		labels, err := req.labels(series.labelRefs)
+		if err != nil {
+			return result, stats, err
+		}
+		help, err := req.symbol(series.helpRef)
+		if err != nil {
+			return result, stats, err
+		}
+		unit, err := req.symbol(series.unitRef)
+		if err != nil {
+			return result, stats, err
+		}
+
+		if len(series.samples) > 0 {
+			samples := series.samples
+			if t.ingestCreatedTimestamps && isCumulativeMetricType(series.metricType) {
+				samples = withCreatedTimestampSample(samples, series.createdTimestamp)
+			}
+			result.Timeseries = append(result.Timeseries, prompb.TimeSeries{
+				Labels:  labels,
+				Samples: samples,
+				Type:    series.metricType,
+				Unit:    string(unit),
+				Help:    string(help),
+			})
+			stats.samples += len(series.samples)
+		}
+
+		if len(series.histograms) > 0 {
+			histogramSeries, err := t.histogramSeries(labels, series)
+			if err != nil {
+				return result, stats, err
+			}
+			result.Timeseries = append(result.Timeseries, histogramSeries...)
+			stats.histograms += len(series.histograms)
+		}
+	}
+
+	return result, stats, nil
+}
+
+func (r writeV2Request) symbol(ref uint32) ([]byte, error) {
+	if int(ref) >= len(r.symbols) {
+		if ref == 0 {
+			// NB: an empty symbols table is valid for series without labels.
+			return nil, nil
+		}
+		return nil, fmt.Errorf("symbol reference out of range: ref=%d, symbols=%d",
+			ref, len(r.symbols))
+	}
+	return r.symbols[ref], nil
+}
+
+func (r writeV2Request) labels(refs []uint32) ([]prompb.Label, error) {
+	if len(refs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of label references: %d", len(refs))
+	}
+	labels := make([]prompb.Label, 0, len(refs)/2)
+	for i := 0; i < len(refs); i += 2 {
+		name, err := r.symbol(refs[i])
+		if err != nil {
+			return nil, err
+		}
+		value, err := r.symbol(refs[i+1])
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, prompb.Label{Name: name, Value: value})
+	}
+	return labels, nil
+}
+
+func isCumulativeMetricType(t prompb.MetricType) bool {
+	switch t {
+	case prompb.MetricType_COUNTER, prompb.MetricType_HISTOGRAM, prompb.MetricType_SUMMARY:
+		return true
+	}
+	return false
+}
+
+// withCreatedTimestampSample prepends a zero sample at the created timestamp
+// if it precedes the first sample.
+func withCreatedTimestampSample(samples []prompb.Sample, createdTimestamp int64) []prompb.Sample {
+	if createdTimestamp <= 0 || len(samples) == 0 || createdTimestamp >= samples[0].Timestamp {
+		return samples
+	}
+	withCreated := make([]prompb.Sample, 0, len(samples)+1)
+	withCreated = append(withCreated, prompb.Sample{Timestamp: createdTimestamp})
+	return append(withCreated, samples...)
+}
+
+func unmarshalWriteV2Request(b []byte) (writeV2Request, error) {
+	var req writeV2Request
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 4 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n >= 0 {
+				req.symbols = append(req.symbols, v)
+			}
+			return n, nil
+		case num == 5 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			series, err := unmarshalWriteV2TimeSeries(v)
+			if err != nil {
+				return 0, err
+			}
+			req.timeseries = append(req.timeseries, series)
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	return req, err
+}
+
+func unmarshalWriteV2TimeSeries(b []byte) (writeV2TimeSeries, error) {
+	var series writeV2TimeSeries
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && isVarintOrPacked(typ):
+			return consumeVarints(typ, b, func(v uint64) {
+				series.labelRefs = append(series.labelRefs, uint32(v))
+			}), nil
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			sample, err := unmarshalWriteV2Sample(v)
+			if err != nil {
+				return 0, err
+			}
+			series.samples = append(series.samples, sample)
+			return n, nil
+		case num == 3 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			histogram, err := unmarshalWriteV2Histogram(v)
+			if err != nil {
+				return 0, err
+			}
+			series.histograms = append(series.histograms, histogram)
+			return n, nil
+		case num == 4 && typ == protowire.BytesType:
+			// NB: exemplars are not stored.
+			series.numExemplars++
+		case num == 5 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			err := consumeMessage(v, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+				if typ != protowire.VarintType {
+					return protowire.ConsumeFieldValue(num, typ, b), nil
+				}
+				v, n := protowire.ConsumeVarint(b)
+				switch num {
+				case 1:
+					series.metricType = prompb.MetricType(v)
+				case 3:
+					series.helpRef = uint32(v)
+				case 4:
+					series.unitRef = uint32(v)
+				}
+				return n, nil
+			})
+			return n, err
+		case num == 6 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			series.createdTimestamp = int64(v)
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	return series, err
+}
+
+func unmarshalWriteV2Sample(b []byte) (prompb.Sample, error) {
+	var sample prompb.Sample
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			sample.Value = math.Float64frombits(v)
+			return n, nil
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			sample.Timestamp = int64(v)
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	return sample, err
+}
+
+func unmarshalWriteV2Histogram(b []byte) (writeV2Histogram, error) {
+	var h writeV2Histogram
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			switch num {
+			case 1:
+				h.countInt = v
+			case 4:
+				h.schema = int32(protowire.DecodeZigZag(v))
+			case 6:
+				h.zeroCountInt = v
+			case 9:
+				h.negativeDeltas = append(h.negativeDeltas, protowire.DecodeZigZag(v))
+			case 12:
+				h.positiveDeltas = append(h.positiveDeltas, protowire.DecodeZigZag(v))
+			case 14:
+				h.resetHint = writeV2ResetHint(v)
+			case 15:
+				h.timestamp = int64(v)
+			}
+			return n, nil
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			f := math.Float64frombits(v)
+			switch num {
+			case 2:
+				h.isFloat = true
+				h.countFloat = f
+			case 3:
+				h.sum = f
+			case 5:
+				h.zeroThreshold = f
+			case 7:
+				h.zeroCountFloat = f
+			case 10:
+				h.negativeCounts = append(h.negativeCounts, f)
+			case 13:
+				h.positiveCounts = append(h.positiveCounts, f)
+			case 16:
+				h.customValues = append(h.customValues, f)
+			}
+			return n, nil
+		case protowire.BytesType:
+			switch num {
+			case 8, 11:
+				v, n := protowire.ConsumeBytes(b)
+				if n < 0 {
+					return n, nil
+				}
+				span, err := unmarshalWriteV2BucketSpan(v)
+				if err != nil {
+					return 0, err
+				}
+				if num == 8 {
+					h.negativeSpans = append(h.negativeSpans, span)
+				} else {
+					h.positiveSpans = append(h.positiveSpans, span)
+				}
+				return n, nil
+			case 9:
+				return consumeVarints(typ, b, func(v uint64) {
+					h.negativeDeltas = append(h.negativeDeltas, protowire.DecodeZigZag(v))
+				}), nil
+			case 12:
+				return consumeVarints(typ, b, func(v uint64) {
+					h.positiveDeltas = append(h.positiveDeltas, protowire.DecodeZigZag(v))
+				}), nil
+			case 10:
+				return consumeDoubles(b, &h.negativeCounts), nil
+			case 13:
+				return consumeDoubles(b, &h.positiveCounts), nil
+			case 16:
+				return consumeDoubles(b, &h.customValues), nil
+			}
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	return h, err
+}
+
+func unmarshalWriteV2BucketSpan(b []byte) (writeV2BucketSpan, error) {
+	var span writeV2BucketSpan
+	err := consumeMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if typ != protowire.VarintType {
+			return protowire.ConsumeFieldValue(num, typ, b), nil
+		}
+		v, n := protowire.ConsumeVarint(b)
+		switch num {
+		case 1:
+			span.offset = int32(protowire.DecodeZigZag(v))
+		case 2:
+			span.length = uint32(v)
+		}
+		return n, nil
+	})
+	return span, err
+}
+
+// consumeMessage calls fn with the remainder of the message following each
+// field tag, fn returns the length of the field value consumed or a negative
+// length if the value is malformed.
+func consumeMessage(
+	b []byte,
+	fn func(num protowire.Number, typ protowire.Type, b []byte) (int, error),
+) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		n, err := fn(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func isVarintOrPacked(typ protowire.Type) bool {
+	return typ == protowire.VarintType || typ == protowire.BytesType
+}
+
+// consumeVarints consumes a repeated varint field that is either packed or
+// a single element.
+func consumeVarints(typ protowire.Type, b []byte, fn func(v uint64)) int {
+	if typ == protowire.VarintType {
+		v, n := protowire.ConsumeVarint(b)
+		if n >= 0 {
+			fn(v)
+		}
+		return n
+	}
+
+	packed, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return n
+	}
+	for len(packed) > 0 {
+		v, m := protowire.ConsumeVarint(packed)
+		if m < 0 {
+			return m
+		}
+		fn(v)
+		packed = packed[m:]
+	}
+	return n
+}
+
+// consumeDoubles consumes a packed repeated double field.
+func consumeDoubles(b []byte, values *[]float64) int {
+	packed, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return n
+	}
+	for len(packed) > 0 {
+		v, m := protowire.ConsumeFixed64(packed)
+		if m < 0 {
+			return m
+		}
+		*values = append(*values, math.Float64frombits(v))
+		packed = packed[m:]
+	}
+	return n
+}
@@ -0,0 +1,501 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	xtest "github.com/m3db/m3/src/x/test"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const writeV2ContentType = "application/x-protobuf;proto=io.prometheus.write.v2.Request"
+
+func appendWriteV2Message(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendWriteV2Varint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendWriteV2Double(b []byte, num protowire.Number, v float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func encodeWriteV2Request(req writeV2Request) []byte {
+	var b []byte
+	for _, s := range req.symbols {
+		b = appendWriteV2Message(b, 4, s)
+	}
+	for _, series := range req.timeseries {
+		b = appendWriteV2Message(b, 5, encodeWriteV2TimeSeries(series))
+	}
+	return b
+}
+
+func encodeWriteV2TimeSeries(series writeV2TimeSeries) []byte {
+	var refs, b []byte
+	for _, ref := range series.labelRefs {
+		refs = protowire.AppendVarint(refs, uint64(ref))
+	}
+	b = appendWriteV2Message(b, 1, refs)
+	for _, s := range series.samples {
+		var sample []byte
+		sample = appendWriteV2Double(sample, 1, s.Value)
+		sample = appendWriteV2Varint(sample, 2, uint64(s.Timestamp))
+		b = appendWriteV2Message(b, 2, sample)
+	}
+	for _, h := range series.histograms {
+		b = appendWriteV2Message(b, 3, encodeWriteV2Histogram(h))
+	}
+	for i := 0; i < series.numExemplars; i++ {
+		b = appendWriteV2Message(b, 4, nil)
+	}
+	var metadata []byte
+	metadata = appendWriteV2Varint(metadata, 1, uint64(series.metricType))
+	metadata = appendWriteV2Varint(metadata, 3, uint64(series.helpRef))
+	metadata = appendWriteV2Varint(metadata, 4, uint64(series.unitRef))
+	b = appendWriteV2Message(b, 5, metadata)
+	return appendWriteV2Varint(b, 6, uint64(series.createdTimestamp))
+}
+
+func encodeWriteV2Histogram(h writeV2Histogram) []byte {
+	var b []byte
+	if h.isFloat {
+		b = appendWriteV2Double(b, 2, h.countFloat)
+		b = appendWriteV2Double(b, 7, h.zeroCountFloat)
+	} else {
+		b = appendWriteV2Varint(b, 1, h.countInt)
+		b = appendWriteV2Varint(b, 6, h.zeroCountInt)
+	}
+	b = appendWriteV2Double(b, 3, h.sum)
+	b = appendWriteV2Varint(b, 4, protowire.EncodeZigZag(int64(h.schema)))
+	b = appendWriteV2Double(b, 5, h.zeroThreshold)
+	appendSpans := func(num protowire.Number, spans []writeV2BucketSpan) {
+		for _, span := range spans {
+			var s []byte
+			s = appendWriteV2Varint(s, 1, protowire.EncodeZigZag(int64(span.offset)))
+			s = appendWriteV2Varint(s, 2, uint64(span.length))
+			b = appendWriteV2Message(b, num, s)
+		}
+	}
+	appendDeltas := func(num protowire.Number, deltas []int64) {
+		if len(deltas) == 0 {
+			return
+		}
+		var packed []byte
+		for _, d := range deltas {
+			packed = protowire.AppendVarint(packed, protowire.EncodeZigZag(d))
+		}
+		b = appendWriteV2Message(b, num, packed)
+	}
+	appendDoubles := func(num protowire.Number, values []float64) {
+		if len(values) == 0 {
+			return
+		}
+		var packed []byte
+		for _, v := range values {
+			packed = protowire.AppendFixed64(packed, math.Float64bits(v))
+		}
+		b = appendWriteV2Message(b, num, packed)
+	}
+	appendSpans(8, h.negativeSpans)
+	appendDeltas(9, h.negativeDeltas)
+	appendDoubles(10, h.negativeCounts)
+	appendSpans(11, h.positiveSpans)
+	appendDeltas(12, h.positiveDeltas)
+	appendDoubles(13, h.positiveCounts)
+	b = appendWriteV2Varint(b, 14, uint64(h.resetHint))
+	b = appendWriteV2Varint(b, 15, uint64(h.timestamp))
+	appendDoubles(16, h.customValues)
+	return b
+}
+
+// testWriteV2Histogram is an integer histogram with a negative bucket, a
+// zero bucket and two positive buckets.
+func testWriteV2Histogram() writeV2Histogram {
+	return writeV2Histogram{
+		countInt:       6,
+		sum:            3,
+		schema:         0,
+		zeroThreshold:  0.5,
+		zeroCountInt:   1,
+		negativeSpans:  []writeV2BucketSpan{{offset: 1, length: 1}},
+		negativeDeltas: []int64{2},
+		positiveSpans:  []writeV2BucketSpan{{offset: 0, length: 2}},
+		positiveDeltas: []int64{1, 1},
+		timestamp:      1000,
+	}
+}
+
+func testWriteV2Request(series ...writeV2TimeSeries) writeV2Request {
+	return writeV2Request{
+		symbols: [][]byte{
+			[]byte(""), []byte("__name__"), []byte("http_requests"),
+			[]byte("job"), []byte("api"), []byte("Requests served."), []byte("seconds"),
+		},
+		timeseries: series,
+	}
+}
+
+// testSeriesValues returns the values of each series keyed by its labels.
+func testSeriesValues(series []prompb.TimeSeries) map[string][]float64 {
+	result := make(map[string][]float64, len(series))
+	for _, s := range series {
+		labels := make([]string, 0, len(s.Labels))
+		for _, l := range s.Labels {
+			labels = append(labels, fmt.Sprintf("%s=%s", l.Name, l.Value))
+		}
+		sort.Strings(labels)
+		values := make([]float64, 0, len(s.Samples))
+		for _, sample := range s.Samples {
+			values = append(values, sample.Value)
+		}
+		result[strings.Join(labels, ",")] = values
+	}
+	return result
+}
+
+func TestWriteV2RequestDecode(t *testing.T) {
+	floatHistogram := writeV2Histogram{
+		isFloat:        true,
+		countFloat:     3.5,
+		sum:            -1.25,
+		schema:         -53,
+		zeroCountFloat: 0,
+		positiveSpans:  []writeV2BucketSpan{{offset: 0, length: 2}},
+		positiveCounts: []float64{1.5, 2},
+		resetHint:      writeV2ResetHintGauge,
+		timestamp:      2000,
+		customValues:   []float64{0.25, 1},
+	}
+	expected := testWriteV2Request(
+		writeV2TimeSeries{
+			labelRefs:        []uint32{1, 2, 3, 4},
+			samples:          []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: 2, Timestamp: 2000}},
+			numExemplars:     2,
+			metricType:       prompb.MetricType_COUNTER,
+			helpRef:          5,
+			unitRef:          6,
+			createdTimestamp: 500,
+		},
+		writeV2TimeSeries{
+			labelRefs:  []uint32{1, 2},
+			histograms: []writeV2Histogram{testWriteV2Histogram(), floatHistogram},
+			metricType: prompb.MetricType_HISTOGRAM,
+		},
+	)
+
+	actual, err := unmarshalWriteV2Request(encodeWriteV2Request(expected))
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	_, err = unmarshalWriteV2Request([]byte{0x2a, 0x05, 0x01})
+	require.Error(t, err)
+}
+
+func TestWriteV2TranslateSamples(t *testing.T) {
+	req := testWriteV2Request(
+		writeV2TimeSeries{
+			labelRefs:        []uint32{1, 2, 3, 4},
+			samples:          []prompb.Sample{{Value: 1, Timestamp: 1000}},
+			numExemplars:     1,
+			metricType:       prompb.MetricType_COUNTER,
+			helpRef:          5,
+			unitRef:          6,
+			createdTimestamp: 500,
+		},
+		writeV2TimeSeries{
+			labelRefs:        []uint32{1, 3},
+			samples:          []prompb.Sample{{Value: 2, Timestamp: 1000}},
+			metricType:       prompb.MetricType_GAUGE,
+			createdTimestamp: 500,
+		},
+	)
+
+	for _, ingestCreatedTimestamps := range []bool{false, true} {
+		t.Run(fmt.Sprintf("created timestamps %v", ingestCreatedTimestamps), func(t *testing.T) {
+			translator := writeV2Translator{ingestCreatedTimestamps: ingestCreatedTimestamps}
+			result, stats, err := translator.translate(req)
+			require.NoError(t, err)
+			require.Equal(t, writeV2Stats{samples: 2}, stats)
+			require.Len(t, result.Timeseries, 2)
+
+			counter := result.Timeseries[0]
+			require.Equal(t, []prompb.Label{
+				{Name: []byte("__name__"), Value: []byte("http_requests")},
+				{Name: []byte("job"), Value: []byte("api")},
+			}, counter.Labels)
+			require.Equal(t, prompb.MetricType_COUNTER, counter.Type)
+			require.Equal(t, "Requests served.", counter.Help)
+			require.Equal(t, "seconds", counter.Unit)
+			if ingestCreatedTimestamps {
+				require.Equal(t, []prompb.Sample{{Timestamp: 500}, {Value: 1, Timestamp: 1000}},
+					counter.Samples)
+			} else {
+				require.Equal(t, []prompb.Sample{{Value: 1, Timestamp: 1000}}, counter.Samples)
+			}
+
+			// Created timestamps only apply to cumulative metric types.
+			gauge := result.Timeseries[1]
+			require.Equal(t, []prompb.Sample{{Value: 2, Timestamp: 1000}}, gauge.Samples)
+		})
+	}
+}
+
+func TestWriteV2TranslateInvalid(t *testing.T) {
+	invalidSymbols := testWriteV2Request()
+	invalidSymbols.symbols[0] = []byte("foo")
+
+	invalidRef := testWriteV2Request(writeV2TimeSeries{labelRefs: []uint32{1, 7}})
+	oddRefs := testWriteV2Request(writeV2TimeSeries{labelRefs: []uint32{1}})
+
+	invalidSchema := testWriteV2Histogram()
+	invalidSchema.schema = 9
+	mismatchedSpans := testWriteV2Histogram()
+	mismatchedSpans.positiveDeltas = []int64{1}
+	negativeCount := testWriteV2Histogram()
+	negativeCount.positiveDeltas = []int64{1, -2}
+	unsortedCustom := writeV2Histogram{schema: customBucketsSchema, customValues: []float64{1, 0.5}}
+
+	tests := []struct {
+		name string
+		req  writeV2Request
+	}{
+		{name: "invalid symbols", req: invalidSymbols},
+		{name: "invalid label ref", req: invalidRef},
+		{name: "odd label refs", req: oddRefs},
+		{name: "no metric name", req: testWriteV2Request(writeV2TimeSeries{
+			labelRefs:  []uint32{3, 4},
+			histograms: []writeV2Histogram{testWriteV2Histogram()},
+		})},
+	}
+	for _, h := range []writeV2Histogram{invalidSchema, mismatchedSpans, negativeCount, unsortedCustom} {
+		tests = append(tests, struct {
+			name string
+			req  writeV2Request
+		}{
+			name: fmt.Sprintf("invalid histogram %d", len(tests)),
+			req: testWriteV2Request(writeV2TimeSeries{
+				labelRefs:  []uint32{1, 2},
+				histograms: []writeV2Histogram{h},
+			}),
+		})
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := writeV2Translator{}.translate(test.req)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWriteV2TranslateHistogramBucketSeries(t *testing.T) {
+	req := testWriteV2Request(writeV2TimeSeries{
+		labelRefs:        []uint32{1, 2, 3, 4},
+		histograms:       []writeV2Histogram{testWriteV2Histogram()},
+		createdTimestamp: 500,
+	})
+
+	translator := writeV2Translator{
+		histograms:              config.BucketSeriesNativeHistogramRepresentation,
+		ingestCreatedTimestamps: true,
+	}
+	result, stats, err := translator.translate(req)
+	require.NoError(t, err)
+	require.Equal(t, writeV2Stats{histograms: 1}, stats)
+
+	// Created timestamps prepend a zero sample to each series.
+	require.Equal(t, map[string][]float64{
+		"__name__=http_requests_bucket,job=api,le=-1":   {0, 2},
+		"__name__=http_requests_bucket,job=api,le=0.5":  {0, 3},
+		"__name__=http_requests_bucket,job=api,le=1":    {0, 4},
+		"__name__=http_requests_bucket,job=api,le=2":    {0, 6},
+		"__name__=http_requests_bucket,job=api,le=+Inf": {0, 6},
+		"__name__=http_requests_count,job=api":          {0, 6},
+		"__name__=http_requests_sum,job=api":            {0, 3},
+	}, testSeriesValues(result.Timeseries))
+	for _, series := range result.Timeseries {
+		require.Equal(t, prompb.MetricType_HISTOGRAM, series.Type)
+		require.Equal(t, []int64{500, 1000},
+			[]int64{series.Samples[0].Timestamp, series.Samples[1].Timestamp})
+	}
+}
+
+func TestWriteV2TranslateHistogramNativeBuckets(t *testing.T) {
+	gauge := testWriteV2Histogram()
+	gauge.resetHint = writeV2ResetHintGauge
+	req := testWriteV2Request(writeV2TimeSeries{
+		labelRefs:        []uint32{1, 2},
+		histograms:       []writeV2Histogram{gauge},
+		createdTimestamp: 500,
+	})
+
+	translator := writeV2Translator{
+		histograms:              config.NativeBucketsNativeHistogramRepresentation,
+		ingestCreatedTimestamps: true,
+	}
+	result, _, err := translator.translate(req)
+	require.NoError(t, err)
+
+	// Created timestamps do not apply to gauge histograms.
+	require.Equal(t, map[string][]float64{
+		"__name__=http_requests_negative_bucket,bucket=1,schema=0": {2},
+		"__name__=http_requests_bucket,bucket=0,schema=0":          {1},
+		"__name__=http_requests_bucket,bucket=1,schema=0":          {2},
+		"__name__=http_requests_zero_count,le=0.5":                 {1},
+		"__name__=http_requests_count":                             {6},
+		"__name__=http_requests_sum":                               {3},
+	}, testSeriesValues(result.Timeseries))
+	for _, series := range result.Timeseries {
+		require.Equal(t, prompb.MetricType_GAUGE_HISTOGRAM, series.Type)
+	}
+}
+
+func TestWriteV2TranslateHistogramCustomBuckets(t *testing.T) {
+	req := testWriteV2Request(writeV2TimeSeries{
+		labelRefs: []uint32{1, 2},
+		histograms: []writeV2Histogram{{
+			isFloat:        true,
+			countFloat:     6,
+			sum:            2.5,
+			schema:         customBucketsSchema,
+			positiveSpans:  []writeV2BucketSpan{{offset: 0, length: 3}},
+			positiveCounts: []float64{1, 2, 3},
+			customValues:   []float64{0.1, 1},
+		}},
+	})
+
+	// Custom buckets are always written as bucket series.
+	translator := writeV2Translator{histograms: config.NativeBucketsNativeHistogramRepresentation}
+	result, _, err := translator.translate(req)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]float64{
+		"__name__=http_requests_bucket,le=0.1":  {1},
+		"__name__=http_requests_bucket,le=1":    {3},
+		"__name__=http_requests_bucket,le=+Inf": {6},
+		"__name__=http_requests_count":          {6},
+		"__name__=http_requests_sum":            {2.5},
+	}, testSeriesValues(result.Timeseries))
+}
+
+func TestExponentialBucketUpperBound(t *testing.T) {
+	require.Equal(t, 1.0, exponentialBucketUpperBound(0, 0))
+	require.Equal(t, 8.0, exponentialBucketUpperBound(0, 3))
+	require.Equal(t, 0.25, exponentialBucketUpperBound(0, -2))
+	require.Equal(t, 16.0, exponentialBucketUpperBound(-2, 1))
+	require.InDelta(t, math.Sqrt2, exponentialBucketUpperBound(1, 1), 1e-12)
+	require.Equal(t, "+Inf", formatBucketBound(math.Inf(1)))
+	require.Equal(t, "0.001", formatBucketBound(0.001))
+}
+
+func TestPromWriteV2(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var written int
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.
+		EXPECT().
+		WriteBatch(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			iter ingest.DownsampleAndWriteIter,
+			_ ingest.WriteOptions,
+		) ingest.BatchError {
+			for iter.Next() {
+				written++
+			}
+			return nil
+		})
+
+	handler, err := NewPromWriteHandler(makeOptions(mockDownsamplerAndWriter))
+	require.NoError(t, err)
+
+	body := encodeWriteV2Request(testWriteV2Request(
+		writeV2TimeSeries{
+			labelRefs:    []uint32{1, 2},
+			samples:      []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: 2, Timestamp: 2000}},
+			numExemplars: 1,
+		},
+		writeV2TimeSeries{
+			labelRefs:  []uint32{1, 2},
+			histograms: []writeV2Histogram{testWriteV2Histogram()},
+		},
+	))
+	req := httptest.NewRequest(PromWriteHTTPMethod, PromWriteURL,
+		bytes.NewReader(snappy.Encode(nil, body)))
+	req.Header.Set("Content-Type", writeV2ContentType)
+	req.Header.Set("Content-Encoding", "snappy")
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, req)
+	resp := writer.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "2", resp.Header.Get(remoteWriteSamplesWrittenHeader))
+	require.Equal(t, "1", resp.Header.Get(remoteWriteHistogramsWrittenHeader))
+	require.Equal(t, "0", resp.Header.Get(remoteWriteExemplarsWrittenHeader))
+	// One sample series and seven bucket series.
+	require.Equal(t, 8, written)
+}
+
+func TestPromWriteUnsupportedMediaType(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	handler, err := NewPromWriteHandler(makeOptions(ingest.NewMockDownsamplerAndWriter(ctrl)))
+	require.NoError(t, err)
+
+	for _, headers := range []map[string]string{
+		{"Content-Type": "application/x-protobuf;proto=io.prometheus.write.v3.Request"},
+		{"Content-Type": writeV2ContentType, "Content-Encoding": "zstd"},
+	} {
+		req := httptest.NewRequest(PromWriteHTTPMethod, PromWriteURL,
+			bytes.NewReader(snappy.Encode(nil, encodeWriteV2Request(testWriteV2Request()))))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, req)
+		require.Equal(t, http.StatusUnsupportedMediaType, writer.Result().StatusCode)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package rules contains API endpoints for working with downsample rules.
+package rules
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"go.uber.org/zap"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample/promrules"
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/api/v1/route"
+	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/instrument"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+)
+
+const (
+	// ImportPrometheusURL is the url to translate a Prometheus recording rule
+	// file into rollup rules.
+	ImportPrometheusURL = route.Prefix + "/rules/import/prometheus"
+
+	// ImportPrometheusHTTPMethod is the HTTP method used with this resource.
+	ImportPrometheusHTTPMethod = http.MethodPost
+
+	// StoragePolicyParam is the query param of the storage policies the
+	// imported rules keep rolled up metrics at, it can be repeated.
+	StoragePolicyParam = "storagePolicy"
+)
+
+// ImportPrometheusResponse is the response of a Prometheus rule file import.
+type ImportPrometheusResponse struct {
+	// Rules is the YAML of the translated rules, suitable for use as the
+	// downsample rules of the coordinator configuration.
+	Rules string `json:"rules"`
+
+	// Report is the compatibility report of the rule file.
+	Report promrules.Report `json:"report"`
+}
+
+type importPrometheusHandler struct {
+	instrumentOpts instrument.Options
+}
+
+// NewImportPrometheusHandler returns a new handler that translates the
+// Prometheus recording rule file in the request body into rollup rules.
+func NewImportPrometheusHandler(opts options.HandlerOptions) http.Handler {
+	return &importPrometheusHandler{
+		instrumentOpts: opts.InstrumentOpts(),
+	}
+}
+
+func (h *importPrometheusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context(), h.instrumentOpts)
+
+	values := r.URL.Query()[StoragePolicyParam]
+	storagePolicies := make([]downsample.StoragePolicyConfiguration, 0, len(values))
+	for _, value := range values {
+		sp, err := policy.ParseStoragePolicy(value)
+		if err != nil {
+			xhttp.WriteError(w, xerrors.NewInvalidParamsError(err))
+			return
+		}
+		storagePolicies = append(storagePolicies, downsample.StoragePolicyConfiguration{
+			Resolution: sp.Resolution().Window,
+			Retention:  sp.Retention().Duration(),
+		})
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	rules, report, err := promrules.Import(data, storagePolicies)
+	if err != nil {
+		xhttp.WriteError(w, xerrors.NewInvalidParamsError(err))
+		return
+	}
+
+	rulesYAML, err := yaml.Marshal(rules)
+	if err != nil {
+		logger.Error("unable to marshal imported rules", zap.Error(err))
+		xhttp.WriteError(w, err)
+		return
+	}
+
+	xhttp.WriteJSONResponse(w, ImportPrometheusResponse{
+		Rules:  string(rulesYAML),
+		Report: report,
+	}, logger)
+}
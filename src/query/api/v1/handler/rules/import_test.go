@@ -0,0 +1,103 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const testRuleFile = `
+groups:
+  - name: http
+    rules:
+      - record: http_requests:sum
+        expr: sum by (service) (rate(http_requests_total[1m]))
+      - alert: HighErrorRate
+        expr: http_requests:sum > 10
+`
+
+func newTestHandler() http.Handler {
+	opts := options.EmptyHandlerOptions().
+		SetInstrumentOpts(instrument.NewOptions())
+	return NewImportPrometheusHandler(opts)
+}
+
+func TestImportPrometheusHandler(t *testing.T) {
+	req := httptest.NewRequest(ImportPrometheusHTTPMethod,
+		ImportPrometheusURL+"?storagePolicy=1m:40d&storagePolicy=1h:1y",
+		strings.NewReader(testRuleFile))
+	w := httptest.NewRecorder()
+	newTestHandler().ServeHTTP(w, req)
+
+	resp := w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body ImportPrometheusResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 1, body.Report.Translated)
+	assert.Equal(t, 1, body.Report.Skipped)
+	assert.Contains(t, body.Rules, "http_requests:sum")
+	assert.Contains(t, body.Rules, "1m0s")
+	assert.Contains(t, body.Rules, "1h0m0s")
+}
+
+func TestImportPrometheusHandlerInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		body  string
+	}{
+		{
+			name:  "invalid storage policy",
+			query: "?storagePolicy=invalid",
+			body:  testRuleFile,
+		},
+		{
+			name: "no storage policies",
+			body: testRuleFile,
+		},
+		{
+			name:  "invalid rule file",
+			query: "?storagePolicy=1m:40d",
+			body:  "groups: [",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(ImportPrometheusHTTPMethod,
+				ImportPrometheusURL+test.query, strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+			newTestHandler().ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		})
+	}
+}
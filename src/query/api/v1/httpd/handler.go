@@ -32,7 +32,9 @@ import (
 	"github.com/m3db/m3/src/cluster/placementhandler/handleroptions"
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/analytics"
 	"github.com/m3db/m3/src/query/api/v1/handler/database"
+	"github.com/m3db/m3/src/query/api/v1/handler/export"
 	"github.com/m3db/m3/src/query/api/v1/handler/graphite"
 	"github.com/m3db/m3/src/query/api/v1/handler/influxdb"
 	m3json "github.com/m3db/m3/src/query/api/v1/handler/json"
@@ -41,11 +43,13 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler/prom"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote"
+	"github.com/m3db/m3/src/query/api/v1/handler/rules"
 	"github.com/m3db/m3/src/query/api/v1/handler/topic"
 	"github.com/m3db/m3/src/query/api/v1/middleware"
 	"github.com/m3db/m3/src/query/api/v1/options"
 	"github.com/m3db/m3/src/query/api/v1/route"
 	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/querycost"
 	"github.com/m3db/m3/src/query/util/queryhttp"
 	xdebug "github.com/m3db/m3/src/x/debug"
 	xhttp "github.com/m3db/m3/src/x/net/http"
@@ -125,6 +129,13 @@ func (h *Handler) RegisterRoutes() error {
 	}); err != nil {
 		return err
 	}
+	if err := h.registry.Register(queryhttp.RegisterOptions{
+		Path:    openapi.GeneratedURL,
+		Handler: openapi.NewGeneratedHandler(h.registry, instrumentOpts),
+		Methods: methods(openapi.HTTPMethod),
+	}); err != nil {
+		return err
+	}
 
 	// Prometheus remote read/write endpoints.
 	remoteSourceOpts := h.options.SetInstrumentOpts(instrumentOpts.
@@ -278,6 +289,24 @@ func (h *Handler) RegisterRoutes() error {
 		return err
 	}
 
+	// Analytics endpoints.
+	if err := h.registry.Register(queryhttp.RegisterOptions{
+		Path:    analytics.CardinalityURL,
+		Handler: analytics.NewCardinalityHandler(h.options),
+		Methods: methods(analytics.CardinalityHTTPMethod),
+	}); err != nil {
+		return err
+	}
+
+	// Rule import endpoints.
+	if err := h.registry.Register(queryhttp.RegisterOptions{
+		Path:    rules.ImportPrometheusURL,
+		Handler: rules.NewImportPrometheusHandler(h.options),
+		Methods: methods(rules.ImportPrometheusHTTPMethod),
+	}); err != nil {
+		return err
+	}
+
 	// Tag completion endpoints.
 	if err := h.registry.Register(queryhttp.RegisterOptions{
 		Path:               native.CompleteTagsURL,
@@ -314,6 +343,21 @@ func (h *Handler) RegisterRoutes() error {
 	}); err != nil {
 		return err
 	}
+	if err := h.registry.Register(queryhttp.RegisterOptions{
+		Path:               native.PromExplainURL,
+		Handler:            native.NewPromExplainHandler(h.options),
+		Methods:            native.PromExplainHTTPMethods,
+		MiddlewareOverride: native.WithQueryParams,
+	}); err != nil {
+		return err
+	}
+	if err := h.registry.Register(queryhttp.RegisterOptions{
+		Path:    native.PromBatchQueryURL,
+		Handler: native.NewPromBatchQueryHandler(h.options),
+		Methods: methods(native.PromBatchQueryHTTPMethod),
+	}); err != nil {
+		return err
+	}
 	if err := h.registry.Register(queryhttp.RegisterOptions{
 		Path:    native.PromThresholdURL,
 		Handler: native.NewPromThresholdHandler(h.options),
@@ -332,6 +376,16 @@ func (h *Handler) RegisterRoutes() error {
 		return err
 	}
 
+	// Export endpoints.
+	if err := h.registry.Register(queryhttp.RegisterOptions{
+		Path:               export.ParquetURL,
+		Handler:            export.NewParquetHandler(h.options),
+		Methods:            export.ParquetHTTPMethods,
+		MiddlewareOverride: native.WithQueryParams,
+	}); err != nil {
+		return err
+	}
+
 	// Graphite routable endpoints.
 	h.options.GraphiteRenderRouter().Setup(options.GraphiteRenderRouterOptions{
 		RenderHandler: graphite.NewRenderHandler(h.options).ServeHTTP,
@@ -403,7 +457,7 @@ func (h *Handler) RegisterRoutes() error {
 
 		err = namespace.RegisterRoutes(h.registry, clusterClient,
 			h.options.Clusters(), serviceOptionDefaults, instrumentOpts,
-			h.options.NamespaceValidator())
+			h.options.NamespaceValidator(), h.options.NamespaceAliases())
 		if err != nil {
 			return err
 		}
@@ -464,6 +518,30 @@ func (h *Handler) RegisterRoutes() error {
 	// Apply middleware after the custom handlers have overridden the previous handlers so the middleware functions
 	// are dispatched before the custom handler.
 	// req -> middleware fns -> custom handler -> previous handler.
+	var identityHeader string
+	if accessControl := h.options.Config().Query.AccessControl; accessControl != nil {
+		identityHeader = accessControl.IdentityHeader
+	}
+
+	queryCost := middleware.QueryCostOptions{
+		FetchOptionsBuilder: h.options.FetchOptionsBuilder(),
+		DefaultLookback:     h.options.DefaultLookback(),
+	}
+	estimatorOpts := querycost.EstimatorOptions{
+		Storage:    h.options.Storage(),
+		TagOptions: h.options.TagOptions(),
+	}
+	if costCfg := h.options.Config().Query.CostEstimation; costCfg != nil {
+		policy, err := costCfg.NewPolicy()
+		if err != nil {
+			return err
+		}
+		queryCost.Policy = policy
+		estimatorOpts.DefaultResolution = costCfg.DefaultResolution
+		estimatorOpts.BytesPerDatapoint = costCfg.BytesPerDatapoint
+	}
+	queryCost.Estimator = querycost.NewEstimator(estimatorOpts)
+
 	err = h.registry.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
 		handler := route.GetHandler()
 		opts := middleware.Options{
@@ -471,6 +549,9 @@ func (h *Handler) RegisterRoutes() error {
 			Route:          route,
 			Clock:          clockwork.NewRealClock(),
 			Logging:        middleware.NewLoggingOptions(h.middlewareConfig.Logging),
+			Identity: middleware.IdentityOptions{
+				Header: identityHeader,
+			},
 			Metrics: middleware.MetricsOptions{
 				Config: h.middlewareConfig.Metrics,
 				ParseOptions: promql.NewParseOptions().
@@ -484,6 +565,10 @@ func (h *Handler) RegisterRoutes() error {
 				Storage:              h.options.Storage(),
 				PrometheusEngineFn:   h.options.PrometheusEngineFn(),
 			},
+			QueryCost: queryCost,
+			QueryAudit: middleware.QueryAuditOptions{
+				Auditor: h.options.QueryAuditor(),
+			},
 		}
 		override := h.registry.MiddlewareOpts(route)
 		if override != nil {
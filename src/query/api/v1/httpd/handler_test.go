@@ -90,7 +90,7 @@ func setupHandler(
 	customHandlers ...options.CustomHandler,
 ) (*Handler, error) {
 	instrumentOpts := instrument.NewOptions()
-	downsamplerAndWriter := ingest.NewDownsamplerAndWriter(store, nil, testWorkerPool, instrument.NewOptions())
+	downsamplerAndWriter := ingest.NewDownsamplerAndWriter(store, nil, testWorkerPool, nil, instrument.NewOptions())
 	engine := newEngine(store, time.Minute, instrumentOpts)
 	fetchOptsBuilder, err := handleroptions.NewFetchOptionsBuilder(
 		handleroptions.FetchOptionsBuilderOptions{
@@ -388,7 +388,7 @@ func TestCustomRoutes(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	store, _ := m3.NewStorageAndSession(t, ctrl)
 	instrumentOpts := instrument.NewOptions()
-	downsamplerAndWriter := ingest.NewDownsamplerAndWriter(store, nil, testWorkerPool, instrument.NewOptions())
+	downsamplerAndWriter := ingest.NewDownsamplerAndWriter(store, nil, testWorkerPool, nil, instrument.NewOptions())
 	engine := newEngine(store, time.Minute, instrumentOpts)
 	fetchOptsBuilder, err := handleroptions.NewFetchOptionsBuilder(
 		handleroptions.FetchOptionsBuilderOptions{
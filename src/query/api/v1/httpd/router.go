@@ -22,13 +22,20 @@
 package httpd
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/x/headers"
 )
 
+// engineFallbackWarningName is the name of the warning added when a query is
+// served by the default engine since the requested engine is unknown.
+const engineFallbackWarningName = "engine fallback"
+
 type router struct {
 	promqlHandler      func(http.ResponseWriter, *http.Request)
 	m3QueryHandler     func(http.ResponseWriter, *http.Request)
@@ -59,6 +66,13 @@ func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 
 	if !options.IsQueryEngineSet(engine) {
+		if engine != "" {
+			req = req.WithContext(handleroptions.WithRequestWarnings(req.Context(), block.Warning{
+				Name: engineFallbackWarningName,
+				Message: fmt.Sprintf("unknown engine %s, using %s",
+					engine, r.defaultQueryEngine),
+			}))
+		}
 		engine = string(r.defaultQueryEngine)
 	}
 
@@ -28,7 +28,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/x/headers"
 )
 
@@ -84,3 +86,31 @@ func TestHandlerSwitch(t *testing.T) {
 	assert.Equal(t, 2, promqlCalled)
 	assert.Equal(t, 3, m3qCalled)
 }
+
+func TestHandlerSwitchUnknownEngine(t *testing.T) {
+	var warnings block.Warnings
+	promqlHandler := func(w http.ResponseWriter, req *http.Request) {
+		warnings = handleroptions.RequestWarnings(req.Context())
+	}
+
+	router := NewQueryRouter()
+	router.Setup(options.QueryRouterOptions{
+		DefaultQueryEngine: "prometheus",
+		PromqlHandler:      promqlHandler,
+	})
+
+	req, err := http.NewRequest("GET", "/query?query=sum(metric)", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, warnings)
+
+	req, err = http.NewRequest("GET", "/query?query=sum(metric)&engine=foo", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, "prometheus", rr.Header().Get(headers.EngineHeaderName))
+	assert.Equal(t, block.Warnings{{
+		Name:    engineFallbackWarningName,
+		Message: "unknown engine foo, using prometheus",
+	}}, warnings)
+}
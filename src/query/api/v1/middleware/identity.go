@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/m3db/m3/src/query/accesscontrol"
+
+	"github.com/gorilla/mux"
+)
+
+// IdentityOptions are the options for the identity middleware.
+type IdentityOptions struct {
+	// Header is the header containing the identity of the requester, it must
+	// be set by a trusted proxy that authenticates requests.
+	Header string
+}
+
+// Identity adds the requester identity from the IdentityOptions header to the
+// request context. Installing this middleware function allows application code
+// to access the identity using accesscontrol.IdentityFromContext.
+func Identity(opts Options) mux.MiddlewareFunc {
+	return func(base http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if opts.Identity.Header == "" {
+				base.ServeHTTP(w, r)
+				return
+			}
+			identity := r.Header.Get(opts.Identity.Header)
+			if identity == "" {
+				// bail early if the header is not set on the request.
+				base.ServeHTTP(w, r)
+				return
+			}
+			ctx := accesscontrol.NewContext(r.Context(), identity)
+			base.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
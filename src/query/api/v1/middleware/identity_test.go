@@ -0,0 +1,79 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/query/accesscontrol"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentity(t *testing.T) {
+	cases := []struct {
+		name           string
+		header         string
+		identityHeader string
+		expected       string
+	}{
+		{
+			name:           "happy path",
+			header:         "X-Auth-User",
+			identityHeader: "alice",
+			expected:       "alice",
+		},
+		{
+			name:   "no identity header",
+			header: "X-Auth-User",
+		},
+		{
+			name:           "header not configured",
+			identityHeader: "alice",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				identity, ok := accesscontrol.IdentityFromContext(r.Context())
+				require.Equal(t, tc.expected != "", ok)
+				require.Equal(t, tc.expected, identity)
+			})
+			r.Use(Identity(Options{
+				Identity: IdentityOptions{Header: tc.header},
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tc.identityHeader != "" {
+				req.Header.Set("X-Auth-User", tc.identityHeader)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+		})
+	}
+}
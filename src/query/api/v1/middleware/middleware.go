@@ -47,7 +47,10 @@ type Options struct {
 	Logging                LoggingOptions
 	Metrics                MetricsOptions
 	Source                 SourceOptions
+	Identity               IdentityOptions
 	PrometheusRangeRewrite PrometheusRangeRewriteOptions
+	QueryCost              QueryCostOptions
+	QueryAudit             QueryAuditOptions
 }
 
 // OverrideOptions is a function that returns new Options from the provided Options.
@@ -63,10 +66,15 @@ func Default(opts Options) []mux.MiddlewareFunc {
 		Tracing(opentracing.GlobalTracer(), opts.InstrumentOpts),
 		// install source before logging so the source is available for response logging.
 		Source(opts),
+		Identity(opts),
 		RequestID(opts.InstrumentOpts),
 		PrometheusRangeRewrite(opts),
 		ResponseLogging(opts),
 		ResponseMetrics(opts),
+		// install query audit before query cost so rejected queries are audited.
+		QueryAudit(opts),
+		// install query cost after response logging and metrics so rejected queries are logged and counted.
+		QueryCost(opts),
 		// install panic handler after any middleware that adds extra useful information to the context logger.
 		Panic(opts.InstrumentOpts),
 		Compression(),
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/m3db/m3/src/query/accesscontrol"
+	"github.com/m3db/m3/src/query/audit"
+	"github.com/m3db/m3/src/x/headers"
+	xhttp "github.com/m3db/m3/src/x/http"
+
+	"github.com/gorilla/mux"
+)
+
+// QueryAuditOptions are the options for the query audit middleware.
+type QueryAuditOptions struct {
+	Auditor          audit.Auditor
+	ParseQueryParams ParseQueryParams
+}
+
+// QueryAudit is middleware that records who queried what with the auditor,
+// only requests to endpoints that parse query parameters are audited.
+func QueryAudit(opts Options) mux.MiddlewareFunc {
+	var (
+		mwOpts = opts.QueryAudit
+		route  = opts.Route
+	)
+	return func(base http.Handler) http.Handler {
+		if mwOpts.Auditor == nil || mwOpts.ParseQueryParams == nil {
+			return base
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			statusCodeTracking := &xhttp.StatusCodeTracker{ResponseWriter: w}
+			w = statusCodeTracking.WrappedResponseWriter()
+
+			start := opts.Clock.Now()
+			base.ServeHTTP(w, r)
+			d := opts.Clock.Now().Sub(start)
+
+			record := audit.Record{
+				Time:       start,
+				RemoteAddr: r.RemoteAddr,
+				Status:     http.StatusOK,
+				Duration:   d,
+			}
+			if statusCodeTracking.WroteHeader {
+				record.Status = statusCodeTracking.Status
+			}
+			if identity, ok := accesscontrol.IdentityFromContext(r.Context()); ok {
+				record.Identity = identity
+			}
+			if route != nil {
+				if path, err := route.GetPathTemplate(); err == nil {
+					record.Path = path
+				}
+			}
+			if record.Path == "" {
+				record.Path = r.URL.Path
+			}
+			// NB: requests whose parameters cannot be parsed are still audited,
+			// without the query, since who attempted them is of interest too.
+			if params, err := mwOpts.ParseQueryParams(r, start); err == nil {
+				record.Query = params.Query
+				record.Start = params.Start
+				record.End = params.End
+			}
+			if series, err := strconv.Atoi(w.Header().Get(headers.FetchedSeriesCount)); err == nil {
+				record.Series = series
+			}
+
+			mwOpts.Auditor.Audit(record)
+		})
+	}
+}
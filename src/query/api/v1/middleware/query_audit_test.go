@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/accesscontrol"
+	"github.com/m3db/m3/src/query/audit"
+	"github.com/m3db/m3/src/x/headers"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/gorilla/mux"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+)
+
+type testAuditor struct {
+	sync.Mutex
+	records []audit.Record
+}
+
+func (a *testAuditor) Audit(r audit.Record) {
+	a.Lock()
+	defer a.Unlock()
+	a.records = append(a.records, r)
+}
+
+func (a *testAuditor) Close() error {
+	return nil
+}
+
+func TestQueryAudit(t *testing.T) {
+	var (
+		auditor = &testAuditor{}
+		r       = mux.NewRouter()
+		route   = r.NewRoute()
+	)
+	route.Path("/api/v1/query_range").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headers.FetchedSeriesCount, "15")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	r.Use(QueryAudit(Options{
+		InstrumentOpts: instrument.NewOptions(),
+		Clock:          clockwork.NewRealClock(),
+		Route:          route,
+		QueryAudit: QueryAuditOptions{
+			Auditor:          auditor,
+			ParseQueryParams: parseQueryParams,
+		},
+	}))
+
+	req := httptest.NewRequest("GET", "/api/v1/query_range?query=up&start=1&end=2", nil)
+	req = req.WithContext(accesscontrol.NewContext(req.Context(), "alice"))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusAccepted, rr.Code)
+
+	require.Len(t, auditor.records, 1)
+	record := auditor.records[0]
+	require.Equal(t, "alice", record.Identity)
+	require.Equal(t, "/api/v1/query_range", record.Path)
+	require.Equal(t, "up", record.Query)
+	require.Equal(t, time.Unix(1, 0), record.Start)
+	require.Equal(t, time.Unix(2, 0), record.End)
+	require.Equal(t, http.StatusAccepted, record.Status)
+	require.Equal(t, 15, record.Series)
+	require.Equal(t, req.RemoteAddr, record.RemoteAddr)
+}
+
+func TestQueryAuditWithoutQueryParams(t *testing.T) {
+	var (
+		auditor = &testAuditor{}
+		r       = mux.NewRouter()
+	)
+	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	r.Use(QueryAudit(Options{
+		InstrumentOpts: instrument.NewOptions(),
+		Clock:          clockwork.NewRealClock(),
+		QueryAudit: QueryAuditOptions{
+			Auditor: auditor,
+		},
+	}))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, auditor.records, 0)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/querycost"
+	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/headers"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	queryCostEstimateParam = "estimate"
+	queryCostForceParam    = "force"
+)
+
+// QueryCostOptions are the options for the query cost middleware.
+type QueryCostOptions struct {
+	Enabled             bool
+	Instant             bool
+	Estimator           *querycost.Estimator
+	Policy              *querycost.Policy
+	FetchOptionsBuilder handleroptions.FetchOptionsBuilder
+	DefaultLookback     time.Duration
+}
+
+// QueryCostResult is the response to a request for the estimated cost of
+// a query.
+type QueryCostResult struct {
+	Estimate   querycost.Estimate   `json:"estimate"`
+	Thresholds querycost.Thresholds `json:"thresholds"`
+	Admitted   bool                 `json:"admitted"`
+	Reason     string               `json:"reason,omitempty"`
+}
+
+type queryCostMetrics struct {
+	admitted tally.Counter
+	rejected tally.Counter
+	forced   tally.Counter
+	errors   tally.Counter
+}
+
+func newQueryCostMetrics(scope tally.Scope) queryCostMetrics {
+	buildCounter := func(status string) tally.Counter {
+		return scope.Tagged(map[string]string{"status": status}).Counter("count")
+	}
+
+	return queryCostMetrics{
+		admitted: buildCounter("admitted"),
+		rejected: buildCounter("rejected"),
+		forced:   buildCounter("forced"),
+		errors:   buildCounter("error"),
+	}
+}
+
+// QueryCost is middleware that, when enabled, estimates the cost of a query
+// from the index before it is executed. If the estimate is requested with
+// the headers.QueryCostEstimateHeader header or the estimate parameter it is
+// returned instead of executing the query, otherwise if a policy is set
+// queries estimated to exceed the thresholds of the requester are rejected
+// unless forced with the headers.QueryCostForceHeader header or the force
+// parameter.
+func QueryCost(opts Options) mux.MiddlewareFunc {
+	var (
+		mwOpts  = opts.QueryCost
+		metrics = newQueryCostMetrics(opts.InstrumentOpts.MetricsScope().
+			SubScope("query-cost"))
+	)
+	return func(base http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !mwOpts.Enabled || mwOpts.Estimator == nil {
+				base.ServeHTTP(w, r)
+				return
+			}
+
+			logger := logging.WithContext(r.Context(), opts.InstrumentOpts)
+			params, err := extractParams(r, mwOpts.Instant)
+			if err == nil {
+				resetRequestForm(r, mwOpts.Instant)
+			}
+
+			estimateOnly, flagErr := parseQueryCostFlag(r,
+				headers.QueryCostEstimateHeader, queryCostEstimateParam)
+			if flagErr != nil {
+				xhttp.WriteError(w, flagErr)
+				return
+			}
+			if !estimateOnly {
+				force, flagErr := parseQueryCostFlag(r,
+					headers.QueryCostForceHeader, queryCostForceParam)
+				if flagErr != nil {
+					xhttp.WriteError(w, flagErr)
+					return
+				}
+				if force {
+					metrics.forced.Inc(1)
+				}
+				if force || mwOpts.Policy == nil {
+					base.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			var estimate querycost.Estimate
+			if err == nil {
+				estimate, err = estimateQueryCost(r, params, mwOpts)
+			}
+			if err != nil {
+				if estimateOnly {
+					logger.Error("could not estimate query cost", zap.Error(err))
+					xhttp.WriteError(w, err)
+					return
+				}
+				// Estimation is best effort, queries that cannot be estimated
+				// are admitted and fail or succeed on their own.
+				metrics.errors.Inc(1)
+				logger.Warn("could not estimate query cost, admitting query",
+					zap.Error(err))
+				base.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(headers.EstimatedSeriesHeader,
+				strconv.FormatInt(estimate.Series, 10))
+			w.Header().Set(headers.EstimatedDatapointsHeader,
+				strconv.FormatInt(estimate.Datapoints, 10))
+			w.Header().Set(headers.EstimatedBytesHeader,
+				strconv.FormatInt(estimate.Bytes, 10))
+
+			var (
+				thresholds = mwOpts.Policy.Thresholds(r.Context())
+				exceeded   = thresholds.Check(estimate)
+			)
+			if estimateOnly {
+				result := QueryCostResult{
+					Estimate:   estimate,
+					Thresholds: thresholds,
+					Admitted:   exceeded == nil,
+				}
+				if exceeded != nil {
+					result.Reason = exceeded.Error()
+				}
+				xhttp.WriteJSONResponse(w, result, logger)
+				return
+			}
+
+			if exceeded != nil {
+				metrics.rejected.Inc(1)
+				logger.Warn("query rejected by estimated cost",
+					zap.String("query", params.query),
+					zap.Int64("estimatedSeries", estimate.Series),
+					zap.Int64("estimatedDatapoints", estimate.Datapoints),
+					zap.Int64("estimatedBytes", estimate.Bytes),
+					zap.Error(exceeded))
+				xhttp.WriteError(w, exceeded)
+				return
+			}
+
+			metrics.admitted.Inc(1)
+			base.ServeHTTP(w, r)
+		})
+	}
+}
+
+func estimateQueryCost(
+	r *http.Request,
+	params params,
+	opts QueryCostOptions,
+) (querycost.Estimate, error) {
+	ctx, fetchOpts, err := opts.FetchOptionsBuilder.NewFetchOptions(r.Context(), r)
+	if err != nil {
+		return querycost.Estimate{}, err
+	}
+
+	lookback := opts.DefaultLookback
+	if params.isLookbackSet {
+		lookback = params.lookback
+	}
+	return opts.Estimator.Estimate(ctx, querycost.Query{
+		Query:    params.query,
+		Start:    params.start,
+		End:      params.end,
+		Lookback: lookback,
+	}, fetchOpts)
+}
+
+func parseQueryCostFlag(r *http.Request, header, param string) (bool, error) {
+	value := r.Header.Get(header)
+	if value == "" {
+		value = r.FormValue(param)
+	}
+	if value == "" {
+		return false, nil
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, xerrors.NewInvalidParamsError(
+			fmt.Errorf("invalid %s header or %s parameter: %w", header, param, err))
+	}
+	return flag, nil
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
+	"github.com/m3db/m3/src/query/querycost"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/x/headers"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCost(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		maxSeries int64
+		header    string
+		param     string
+
+		expectedCode     int
+		expectedExecuted bool
+		expectedHeaders  bool
+	}{
+		{
+			name:      "disabled",
+			enabled:   false,
+			maxSeries: 10,
+
+			expectedCode:     http.StatusOK,
+			expectedExecuted: true,
+		},
+		{
+			name:      "under thresholds",
+			enabled:   true,
+			maxSeries: 1000,
+
+			expectedCode:     http.StatusOK,
+			expectedExecuted: true,
+			expectedHeaders:  true,
+		},
+		{
+			name:      "over thresholds",
+			enabled:   true,
+			maxSeries: 10,
+
+			expectedCode:    http.StatusBadRequest,
+			expectedHeaders: true,
+		},
+		{
+			name:      "over thresholds; forced with header",
+			enabled:   true,
+			maxSeries: 10,
+			header:    headers.QueryCostForceHeader,
+
+			expectedCode:     http.StatusOK,
+			expectedExecuted: true,
+		},
+		{
+			name:      "over thresholds; forced with param",
+			enabled:   true,
+			maxSeries: 10,
+			param:     queryCostForceParam,
+
+			expectedCode:     http.StatusOK,
+			expectedExecuted: true,
+		},
+		{
+			name:      "estimate only",
+			enabled:   true,
+			maxSeries: 10,
+			header:    headers.QueryCostEstimateHeader,
+
+			expectedCode:    http.StatusOK,
+			expectedHeaders: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := makeQueryCostOpts(t, tt.maxSeries)
+			opts.QueryCost.Enabled = tt.enabled
+
+			executed := false
+			h := QueryCost(opts).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The query must still be readable after estimation.
+				require.Equal(t, "foo", r.FormValue(queryParam))
+				executed = true
+			}))
+
+			params := queryCostParams()
+			if tt.param != "" {
+				params.Set(tt.param, "true")
+			}
+			req := httptest.NewRequest(http.MethodPost, "/query_range",
+				strings.NewReader(params.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.header != "" {
+				req.Header.Set(tt.header, "true")
+			}
+
+			recorder := httptest.NewRecorder()
+			h.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			assert.Equal(t, tt.expectedExecuted, executed)
+			if tt.expectedHeaders {
+				assert.Equal(t, "100", recorder.Header().Get(headers.EstimatedSeriesHeader))
+				assert.Equal(t, "36000", recorder.Header().Get(headers.EstimatedDatapointsHeader))
+				assert.Equal(t, "72000", recorder.Header().Get(headers.EstimatedBytesHeader))
+			} else {
+				assert.Empty(t, recorder.Header().Get(headers.EstimatedSeriesHeader))
+			}
+		})
+	}
+}
+
+func TestQueryCostEstimateOnly(t *testing.T) {
+	opts := makeQueryCostOpts(t, 10)
+	opts.QueryCost.Enabled = true
+
+	h := QueryCost(opts).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.FailNow(t, "query should not be executed")
+	}))
+
+	params := queryCostParams()
+	params.Set(queryCostEstimateParam, "true")
+	req := httptest.NewRequest(http.MethodGet, "/query_range?"+params.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var result QueryCostResult
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	assert.Equal(t, int64(100), result.Estimate.Series)
+	assert.Equal(t, int64(36000), result.Estimate.Datapoints)
+	assert.Equal(t, int64(72000), result.Estimate.Bytes)
+	assert.Equal(t, int64(10), result.Thresholds.MaxSeries)
+	assert.False(t, result.Admitted)
+	assert.Contains(t, result.Reason, "estimated_series")
+}
+
+func TestQueryCostEstimateErrorAdmitsQuery(t *testing.T) {
+	opts := makeQueryCostOpts(t, 10)
+	opts.QueryCost.Enabled = true
+
+	executed := false
+	h := QueryCost(opts).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		executed = true
+	}))
+
+	params := queryCostParams()
+	params.Set(queryParam, "foo{")
+	req := httptest.NewRequest(http.MethodGet, "/query_range?"+params.Encode(), nil)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, executed)
+}
+
+func makeQueryCostOpts(t *testing.T, maxSeries int64) Options {
+	start := time.Unix(1600000000, 0)
+	store := mock.NewMockStorage()
+	store.SetExplainQueryResult(storage.QueryExplanation{
+		Namespaces: []storage.NamespaceExplanation{
+			{
+				Attributes: storagemetadata.Attributes{
+					MetricsType: storagemetadata.UnaggregatedMetricsType,
+				},
+				Start:           start,
+				End:             start.Add(time.Hour),
+				EstimatedSeries: 100,
+				Exhaustive:      true,
+			},
+		},
+	}, nil)
+
+	fetchOptsBuilder, err := handleroptions.NewFetchOptionsBuilder(
+		handleroptions.FetchOptionsBuilderOptions{Timeout: 15 * time.Second})
+	require.NoError(t, err)
+
+	return Options{
+		InstrumentOpts: instrument.NewOptions(),
+		QueryCost: QueryCostOptions{
+			Estimator: querycost.NewEstimator(querycost.EstimatorOptions{
+				Storage: store,
+			}),
+			Policy: &querycost.Policy{
+				Default: querycost.Thresholds{MaxSeries: maxSeries},
+			},
+			FetchOptionsBuilder: fetchOptsBuilder,
+			DefaultLookback:     5 * time.Minute,
+		},
+	}
+}
+
+func queryCostParams() url.Values {
+	params := url.Values{}
+	params.Add(queryParam, "foo")
+	params.Add(startParam, "1600000000")
+	params.Add(endParam, "1600003600")
+	params.Add("step", "1m")
+	return params
+}
@@ -107,22 +107,7 @@ func RewriteRangeDuration(
 	if err != nil {
 		return err
 	}
-	defer func() {
-		// Reset the body on the request for any handlers that may want access to the raw body.
-		if opts.Instant {
-			// NB(nate): shared time param parsing logic modifies the form on the request to set these
-			// to the "now" string to aid in parsing. Remove these before resetting the body.
-			r.Form.Del(startParam)
-			r.Form.Del(endParam)
-		}
-
-		if r.Method == "GET" {
-			return
-		}
-
-		body := r.Form.Encode()
-		r.Body = ioutil.NopCloser(bytes.NewBufferString(body))
-	}()
+	defer resetRequestForm(r, opts.Instant)
 
 	// Query for namespace metadata of namespaces used to service the request
 	store := opts.Storage
@@ -208,6 +193,24 @@ func RewriteRangeDuration(
 	return nil
 }
 
+// resetRequestForm resets the body of a request whose form was parsed by
+// extractParams for any handlers that may want access to the raw body.
+func resetRequestForm(r *http.Request, instant bool) {
+	if instant {
+		// NB(nate): shared time param parsing logic modifies the form on the request to set these
+		// to the "now" string to aid in parsing. Remove these before resetting the body.
+		r.Form.Del(startParam)
+		r.Form.Del(endParam)
+	}
+
+	if r.Method == "GET" {
+		return
+	}
+
+	body := r.Form.Encode()
+	r.Body = ioutil.NopCloser(bytes.NewBufferString(body))
+}
+
 func findLargestQueryResolution(ctx context.Context,
 	store storage.Storage,
 	fetchOpts *storage.FetchOptions,
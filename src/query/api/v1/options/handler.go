@@ -37,6 +37,7 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/api/v1/middleware"
 	"github.com/m3db/m3/src/query/api/v1/validators"
+	"github.com/m3db/m3/src/query/audit"
 	"github.com/m3db/m3/src/query/executor"
 	graphite "github.com/m3db/m3/src/query/graphite/storage"
 	"github.com/m3db/m3/src/query/models"
@@ -282,6 +283,26 @@ type HandlerOptions interface {
 	DefaultLookback() time.Duration
 	// SetDefaultLookback sets the default value of lookback duration.
 	SetDefaultLookback(value time.Duration) HandlerOptions
+
+	// NamespaceAliases returns the aliases of renamed namespaces.
+	NamespaceAliases() *storage.NamespaceAliases
+	// SetNamespaceAliases sets the aliases of renamed namespaces.
+	SetNamespaceAliases(value *storage.NamespaceAliases) HandlerOptions
+
+	// WriteAuditor returns the write auditor.
+	WriteAuditor() ingest.WriteAuditor
+	// SetWriteAuditor sets the write auditor.
+	SetWriteAuditor(value ingest.WriteAuditor) HandlerOptions
+
+	// QueryAuditor returns the query auditor.
+	QueryAuditor() audit.Auditor
+	// SetQueryAuditor sets the query auditor.
+	SetQueryAuditor(value audit.Auditor) HandlerOptions
+
+	// NamespaceProvisioner returns the namespace provisioner.
+	NamespaceProvisioner() ingest.NamespaceProvisioner
+	// SetNamespaceProvisioner sets the namespace provisioner.
+	SetNamespaceProvisioner(value ingest.NamespaceProvisioner) HandlerOptions
 }
 
 // HandlerOptions represents handler options.
@@ -316,6 +337,10 @@ type handlerOptions struct {
 	graphiteRenderRouter              GraphiteRenderRouter
 	graphiteFindRouter                GraphiteFindRouter
 	defaultLookback                   time.Duration
+	namespaceAliases                  *storage.NamespaceAliases
+	writeAuditor                      ingest.WriteAuditor
+	queryAuditor                      audit.Auditor
+	namespaceProvisioner              ingest.NamespaceProvisioner
 }
 
 // EmptyHandlerOptions returns  default handler options.
@@ -713,5 +738,45 @@ func (o *handlerOptions) SetDefaultLookback(value time.Duration) HandlerOptions
 	return &opts
 }
 
+func (o *handlerOptions) NamespaceAliases() *storage.NamespaceAliases {
+	return o.namespaceAliases
+}
+
+func (o *handlerOptions) SetNamespaceAliases(value *storage.NamespaceAliases) HandlerOptions {
+	opts := *o
+	opts.namespaceAliases = value
+	return &opts
+}
+
+func (o *handlerOptions) WriteAuditor() ingest.WriteAuditor {
+	return o.writeAuditor
+}
+
+func (o *handlerOptions) SetWriteAuditor(value ingest.WriteAuditor) HandlerOptions {
+	opts := *o
+	opts.writeAuditor = value
+	return &opts
+}
+
+func (o *handlerOptions) QueryAuditor() audit.Auditor {
+	return o.queryAuditor
+}
+
+func (o *handlerOptions) SetQueryAuditor(value audit.Auditor) HandlerOptions {
+	opts := *o
+	opts.queryAuditor = value
+	return &opts
+}
+
+func (o *handlerOptions) NamespaceProvisioner() ingest.NamespaceProvisioner {
+	return o.namespaceProvisioner
+}
+
+func (o *handlerOptions) SetNamespaceProvisioner(value ingest.NamespaceProvisioner) HandlerOptions {
+	opts := *o
+	opts.namespaceProvisioner = value
+	return &opts
+}
+
 // KVStoreProtoParser parses protobuf messages based off specific keys.
 type KVStoreProtoParser func(key string) (protoiface.MessageV1, error)
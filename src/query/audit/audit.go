@@ -0,0 +1,202 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package audit records who queried what for security audits, queries are
+// sampled, the values of sensitive labels are redacted from them and the
+// records are written to a pluggable sink.
+package audit
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/sampler"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+// RedactedValue replaces the values of redacted labels in audited queries.
+const RedactedValue = "<redacted>"
+
+// Record is the audit record of a query.
+type Record struct {
+	// Time is when the query was received.
+	Time time.Time `json:"time"`
+	// Identity is the identity of the requester, if any.
+	Identity string `json:"identity,omitempty"`
+	// RemoteAddr is the remote address of the requester.
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+	// Path is the path of the endpoint queried.
+	Path string `json:"path"`
+	// Query is the query string, with the values of redacted labels replaced.
+	Query string `json:"query"`
+	// Redacted is true if any label value was redacted from the query, or the
+	// whole query if it could not be parsed.
+	Redacted bool `json:"redacted,omitempty"`
+	// Start and End are the time range of the query.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Status is the status code of the response.
+	Status int `json:"status"`
+	// Series is the number of series fetched to serve the query.
+	Series int `json:"series"`
+	// Duration is how long the query took.
+	Duration time.Duration `json:"duration"`
+}
+
+// Sink is where audit records are written to.
+type Sink interface {
+	// Write writes the audit record.
+	Write(r Record) error
+
+	// Close closes the sink.
+	Close() error
+}
+
+// Auditor audits a sample of queries.
+type Auditor interface {
+	// Audit redacts and writes the audit record of the query if it is sampled.
+	Audit(r Record)
+
+	// Close closes the sink of the auditor.
+	Close() error
+}
+
+// Options are the options of an auditor.
+type Options struct {
+	// Sink is the sink audit records are written to.
+	Sink Sink
+	// SampleRate is the fraction of queries audited.
+	SampleRate sampler.Rate
+	// RedactLabels are the names of the labels whose values are redacted from
+	// audited queries.
+	RedactLabels []string
+	// InstrumentOptions are the instrument options.
+	InstrumentOptions instrument.Options
+}
+
+type auditor struct {
+	sink         Sink
+	sampler      *sampler.Sampler
+	redactLabels map[string]struct{}
+	logger       *zap.Logger
+	metrics      auditorMetrics
+}
+
+type auditorMetrics struct {
+	audited    tally.Counter
+	redacted   tally.Counter
+	sinkErrors tally.Counter
+}
+
+func newAuditorMetrics(scope tally.Scope) auditorMetrics {
+	return auditorMetrics{
+		audited:    scope.Counter("audited"),
+		redacted:   scope.Counter("redacted"),
+		sinkErrors: scope.Counter("sink-errors"),
+	}
+}
+
+// NewAuditor returns a new auditor.
+func NewAuditor(opts Options) (Auditor, error) {
+	s, err := sampler.NewSampler(opts.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	iOpts := opts.InstrumentOptions
+	if iOpts == nil {
+		iOpts = instrument.NewOptions()
+	}
+
+	redactLabels := make(map[string]struct{}, len(opts.RedactLabels))
+	for _, name := range opts.RedactLabels {
+		redactLabels[name] = struct{}{}
+	}
+
+	return &auditor{
+		sink:         opts.Sink,
+		sampler:      s,
+		redactLabels: redactLabels,
+		logger:       iOpts.Logger().With(zap.String("component", "query-audit")),
+		metrics:      newAuditorMetrics(iOpts.MetricsScope().SubScope("query-audit")),
+	}, nil
+}
+
+func (a *auditor) Audit(r Record) {
+	if !a.sampler.Sample() {
+		return
+	}
+
+	r.Query, r.Redacted = a.redact(r.Query)
+	if r.Redacted {
+		a.metrics.redacted.Inc(1)
+	}
+	if err := a.sink.Write(r); err != nil {
+		a.metrics.sinkErrors.Inc(1)
+		a.logger.Error("could not write query audit record", zap.Error(err))
+		return
+	}
+	a.metrics.audited.Inc(1)
+}
+
+// redact replaces the values of the matchers of redacted labels in the
+// query. Queries that cannot be parsed are redacted as a whole since there
+// is no telling where label values are.
+func (a *auditor) redact(query string) (string, bool) {
+	if len(a.redactLabels) == 0 || query == "" {
+		return query, false
+	}
+
+	expr, err := parser.ParseExpr(query)
+	if err != nil {
+		return RedactedValue, true
+	}
+
+	var redacted bool
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		v, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		for _, m := range v.LabelMatchers {
+			if _, ok := a.redactLabels[m.Name]; ok {
+				m.Value = RedactedValue
+				redacted = true
+			}
+		}
+		if _, ok := a.redactLabels[labels.MetricName]; ok && v.Name != "" {
+			v.Name = RedactedValue
+			redacted = true
+		}
+		return nil
+	})
+	if !redacted {
+		return query, false
+	}
+	return expr.String(), true
+}
+
+func (a *auditor) Close() error {
+	return a.sink.Close()
+}
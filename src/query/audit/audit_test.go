@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/sampler"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type testSink struct {
+	sync.Mutex
+	records []Record
+	err     error
+}
+
+func (s *testSink) Write(r Record) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, r)
+	return nil
+}
+
+func (s *testSink) Close() error {
+	return nil
+}
+
+func newTestAuditor(
+	t *testing.T,
+	sink Sink,
+	rate float64,
+	redactLabels ...string,
+) (Auditor, tally.TestScope) {
+	scope := tally.NewTestScope("", nil)
+	a, err := NewAuditor(Options{
+		Sink:              sink,
+		SampleRate:        sampler.Rate(rate),
+		RedactLabels:      redactLabels,
+		InstrumentOptions: instrument.NewOptions().SetMetricsScope(scope),
+	})
+	require.NoError(t, err)
+	return a, scope
+}
+
+func TestAuditorRedactsLabelValues(t *testing.T) {
+	sink := &testSink{}
+	a, scope := newTestAuditor(t, sink, 1, "user", "email")
+
+	a.Audit(Record{Query: `sum(rate(http_requests{user="alice",job="api"}[5m])) / ` +
+		`sum(rate(http_requests{email=~".*@example.com"}[5m]))`})
+	a.Audit(Record{Query: `up{job="api"}`})
+
+	require.Len(t, sink.records, 2)
+	redacted := sink.records[0]
+	require.True(t, redacted.Redacted)
+	require.Contains(t, redacted.Query, `user="<redacted>"`)
+	require.Contains(t, redacted.Query, `email=~"<redacted>"`)
+	require.Contains(t, redacted.Query, `job="api"`)
+	require.NotContains(t, redacted.Query, "alice")
+	require.NotContains(t, redacted.Query, "example.com")
+
+	unredacted := sink.records[1]
+	require.False(t, unredacted.Redacted)
+	require.Equal(t, `up{job="api"}`, unredacted.Query)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(2), counters["query-audit.audited+"].Value())
+	require.Equal(t, int64(1), counters["query-audit.redacted+"].Value())
+}
+
+func TestAuditorRedactsMetricName(t *testing.T) {
+	sink := &testSink{}
+	a, _ := newTestAuditor(t, sink, 1, "__name__")
+
+	a.Audit(Record{Query: `secret_metric{job="api"}`})
+
+	require.Len(t, sink.records, 1)
+	require.True(t, sink.records[0].Redacted)
+	require.NotContains(t, sink.records[0].Query, "secret_metric")
+}
+
+func TestAuditorRedactsUnparseableQuery(t *testing.T) {
+	sink := &testSink{}
+	a, _ := newTestAuditor(t, sink, 1, "user")
+
+	a.Audit(Record{Query: `up{user="alice"`})
+
+	require.Len(t, sink.records, 1)
+	require.True(t, sink.records[0].Redacted)
+	require.Equal(t, RedactedValue, sink.records[0].Query)
+}
+
+func TestAuditorSamples(t *testing.T) {
+	sink := &testSink{}
+	a, _ := newTestAuditor(t, sink, 0.25)
+
+	for i := 0; i < 8; i++ {
+		a.Audit(Record{Query: "up"})
+	}
+	require.Len(t, sink.records, 2)
+}
+
+func TestAuditorSinkError(t *testing.T) {
+	sink := &testSink{err: errors.New("boom")}
+	a, scope := newTestAuditor(t, sink, 1)
+
+	a.Audit(Record{Query: "up"})
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["query-audit.sink-errors+"].Value())
+	require.Equal(t, int64(0), counters["query-audit.audited+"].Value())
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"errors"
+	"fmt"
+
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	producerconfig "github.com/m3db/m3/src/msg/producer/config"
+	"github.com/m3db/m3/src/x/instrument"
+	xio "github.com/m3db/m3/src/x/io"
+	"github.com/m3db/m3/src/x/sampler"
+
+	"go.uber.org/zap"
+)
+
+// SinkType is the type of a sink.
+type SinkType string
+
+const (
+	// LogSinkType logs audit records.
+	LogSinkType SinkType = "log"
+	// FileSinkType appends audit records to a file.
+	FileSinkType SinkType = "file"
+	// M3MsgSinkType produces audit records to an m3msg topic.
+	M3MsgSinkType SinkType = "m3msg"
+)
+
+var (
+	errNoFileSinkPath  = errors.New("query audit file sink requires a path")
+	errNoSinkProducer  = errors.New("query audit m3msg sink requires a producer")
+	errNoClusterClient = errors.New("query audit m3msg sink requires a cluster client")
+)
+
+// Configuration is the configuration of query auditing.
+type Configuration struct {
+	// Enabled enables query auditing.
+	Enabled bool `yaml:"enabled"`
+
+	// SampleRate is the fraction of queries audited, defaults to all of them.
+	SampleRate *sampler.Rate `yaml:"sampleRate"`
+
+	// RedactLabels are the names of the labels whose values are redacted from
+	// audited queries, e.g. labels holding user names or email addresses.
+	RedactLabels []string `yaml:"redactLabels"`
+
+	// Sink configures where audit records are written to.
+	Sink SinkConfiguration `yaml:"sink"`
+}
+
+// SinkConfiguration is the configuration of the sink of audit records.
+type SinkConfiguration struct {
+	// Type is the type of the sink, defaults to log.
+	Type SinkType `yaml:"type"`
+
+	// Path is the path of the file audit records are appended to by a file
+	// sink.
+	Path string `yaml:"path"`
+
+	// Producer configures the producer of an m3msg sink.
+	Producer *producerconfig.ProducerConfiguration `yaml:"producer"`
+}
+
+// NewAuditor returns a new auditor, the cluster client is only required by
+// m3msg sinks.
+func (c Configuration) NewAuditor(
+	clusterClient clusterclient.Client,
+	iOpts instrument.Options,
+) (Auditor, error) {
+	sink, err := c.Sink.NewSink(clusterClient, iOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleRate := sampler.Rate(1)
+	if c.SampleRate != nil {
+		sampleRate = *c.SampleRate
+	}
+	a, err := NewAuditor(Options{
+		Sink:              sink,
+		SampleRate:        sampleRate,
+		RedactLabels:      c.RedactLabels,
+		InstrumentOptions: iOpts,
+	})
+	if err != nil {
+		sink.Close() // nolint: errcheck
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewSink returns a new sink.
+func (c SinkConfiguration) NewSink(
+	clusterClient clusterclient.Client,
+	iOpts instrument.Options,
+) (Sink, error) {
+	switch c.Type {
+	case "", LogSinkType:
+		return NewLogSink(iOpts.Logger().With(zap.String("component", "query-audit"))), nil
+	case FileSinkType:
+		if c.Path == "" {
+			return nil, errNoFileSinkPath
+		}
+		return NewFileSink(c.Path)
+	case M3MsgSinkType:
+		if c.Producer == nil {
+			return nil, errNoSinkProducer
+		}
+		if clusterClient == nil {
+			return nil, errNoClusterClient
+		}
+		p, err := c.Producer.NewProducer(clusterClient, iOpts, xio.NewOptions())
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Init(); err != nil {
+			return nil, err
+		}
+		return NewProducerSink(p), nil
+	default:
+		return nil, fmt.Errorf("unknown query audit sink type: %s", c.Type)
+	}
+}
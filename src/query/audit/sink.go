@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/m3db/m3/src/msg/producer"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// NewLogSink returns a sink that logs audit records.
+func NewLogSink(logger *zap.Logger) Sink {
+	return &logSink{logger: logger}
+}
+
+type logSink struct {
+	logger *zap.Logger
+}
+
+func (s *logSink) Write(r Record) error {
+	s.logger.Info("audited query",
+		zap.Time("time", r.Time),
+		zap.String("identity", r.Identity),
+		zap.String("remoteAddr", r.RemoteAddr),
+		zap.String("path", r.Path),
+		zap.String("query", r.Query),
+		zap.Bool("redacted", r.Redacted),
+		zap.Time("start", r.Start),
+		zap.Time("end", r.End),
+		zap.Int("status", r.Status),
+		zap.Int("series", r.Series),
+		zap.Duration("duration", r.Duration))
+	return nil
+}
+
+func (s *logSink) Close() error {
+	return nil
+}
+
+// NewFileSink returns a sink that appends audit records to a file as JSON,
+// one record per line.
+func NewFileSink(path string) (Sink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{f: f}, nil
+}
+
+type fileSink struct {
+	sync.Mutex
+
+	f *os.File
+}
+
+func (s *fileSink) Write(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	s.Lock()
+	defer s.Unlock()
+	_, err = s.f.Write(b)
+	return err
+}
+
+func (s *fileSink) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	return s.f.Close()
+}
+
+// NewProducerSink returns a sink that produces audit records as JSON to an
+// m3msg topic, records are spread evenly across the shards of the topic. The
+// producer must be initialized and is closed with the sink.
+func NewProducerSink(p producer.Producer) Sink {
+	return &producerSink{p: p, next: atomic.NewUint32(0)}
+}
+
+type producerSink struct {
+	p    producer.Producer
+	next *atomic.Uint32
+}
+
+func (s *producerSink) Write(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	var shard uint32
+	if n := s.p.NumShards(); n > 0 {
+		shard = s.next.Inc() % n
+	}
+	return s.p.Produce(&recordMessage{shard: shard, data: b})
+}
+
+func (s *producerSink) Close() error {
+	// NB: wait for the buffered records to be consumed so that none are lost
+	// on shutdown.
+	s.p.Close(producer.WaitForConsumption)
+	return nil
+}
+
+// recordMessage is an audit record produced to m3msg.
+type recordMessage struct {
+	shard uint32
+	data  []byte
+}
+
+var _ producer.Message = (*recordMessage)(nil)
+
+func (m *recordMessage) Shard() uint32 {
+	return m.shard
+}
+
+func (m *recordMessage) Bytes() []byte {
+	return m.data
+}
+
+func (m *recordMessage) Size() int {
+	return len(m.data)
+}
+
+func (m *recordMessage) Finalize(producer.FinalizeReason) {}
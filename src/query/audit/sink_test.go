@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	records := []Record{
+		{
+			Time:     now,
+			Identity: "alice",
+			Path:     "/api/v1/query_range",
+			Query:    `up{job="api"}`,
+			Start:    now.Add(-time.Hour),
+			End:      now,
+			Status:   200,
+			Series:   3,
+			Duration: time.Second,
+		},
+		{
+			Time:     now,
+			Identity: "bob",
+			Path:     "/api/v1/query",
+			Query:    RedactedValue,
+			Redacted: true,
+			Status:   400,
+		},
+	}
+	for _, r := range records {
+		require.NoError(t, sink.Write(r))
+	}
+	require.NoError(t, sink.Close())
+
+	f, err := os.Open(path) // nolint: gosec
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	var (
+		actual  []Record
+		scanner = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		actual = append(actual, r)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, records, actual)
+}
@@ -23,6 +23,7 @@ package block
 import (
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/uber-go/tally"
 
@@ -76,6 +77,22 @@ func (c *columnBlock) MultiSeriesIter(_ int) ([]SeriesIterBatch, error) {
 	return nil, errors.New("multi series iterator undefined for a scalar block")
 }
 
+// ConsolidatedSeriesIter returns the values of the columns by series.
+func (c *columnBlock) ConsolidatedSeriesIter() (ConsolidatedSeriesIter, error) {
+	if len(c.columns) != c.meta.Bounds.Steps() {
+		return nil, fmt.
+			Errorf("mismatch in block columns and meta bounds, columns: %d, bounds: %v",
+				len(c.columns), c.meta.Bounds)
+	}
+
+	return &colBlockSeriesIter{
+		columns:    c.columns,
+		seriesMeta: c.seriesMeta,
+		values:     make([]float64, len(c.columns)),
+		idx:        -1,
+	}, nil
+}
+
 func (c *columnBlock) SeriesMeta() []SeriesMeta {
 	return c.seriesMeta
 }
@@ -144,6 +161,44 @@ func (c *colBlockIter) Current() Step {
 
 func (c *colBlockIter) Close() { /*no-op*/ }
 
+type colBlockSeriesIter struct {
+	idx        int
+	seriesMeta []SeriesMeta
+	columns    []column
+	values     []float64
+}
+
+func (c *colBlockSeriesIter) SeriesMeta() []SeriesMeta {
+	return c.seriesMeta
+}
+
+func (c *colBlockSeriesIter) Next() bool {
+	c.idx++
+	if c.idx >= len(c.seriesMeta) {
+		return false
+	}
+
+	for i, col := range c.columns {
+		if c.idx < len(col.Values) {
+			c.values[i] = col.Values[c.idx]
+		} else {
+			c.values[i] = math.NaN()
+		}
+	}
+
+	return true
+}
+
+func (c *colBlockSeriesIter) Err() error {
+	return nil
+}
+
+func (c *colBlockSeriesIter) Current() []float64 {
+	return c.values
+}
+
+func (c *colBlockSeriesIter) Close() { /*no-op*/ }
+
 // ColStep is a single column containing data from multiple series at a given time step
 type ColStep struct {
 	time   xtime.UnixNano
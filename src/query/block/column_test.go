@@ -105,3 +105,34 @@ func TestSetRow(t *testing.T) {
 	assert.False(t, it.Next())
 	assert.NoError(t, it.Err())
 }
+
+func TestColumnBlockConsolidatedSeriesIter(t *testing.T) {
+	metas := []SeriesMeta{
+		{Name: []byte("a"), Tags: models.MustMakeTags("name", "a")},
+		{Name: []byte("b"), Tags: models.MustMakeTags("name", "b")},
+	}
+
+	ctx := makeTestQueryContext()
+	builder := NewColumnBlockBuilder(ctx, Metadata{
+		Bounds: models.Bounds{StepSize: time.Minute, Duration: 3 * time.Minute},
+	}, metas)
+	require.NoError(t, builder.AddCols(3))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, builder.AppendValues(i, []float64{float64(i), float64(10 * i)}))
+	}
+
+	bl, ok := builder.Build().(ConsolidatedSeriesBlock)
+	require.True(t, ok)
+
+	it, err := bl.ConsolidatedSeriesIter()
+	require.NoError(t, err)
+	assert.Equal(t, metas, it.SeriesMeta())
+
+	require.True(t, it.Next())
+	assert.Equal(t, []float64{0, 1, 2}, it.Current())
+	require.True(t, it.Next())
+	assert.Equal(t, []float64{0, 10, 20}, it.Current())
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+	it.Close()
+}
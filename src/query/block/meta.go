@@ -113,6 +113,10 @@ type ResultMetadata struct {
 	// Namespaces are the set of namespaces queried.
 	// External users must access via `AddNamespace`
 	namespaces map[string]struct{}
+	// StitchingPolicies are the set of policies applied to stitch the results
+	// of unaggregated and aggregated namespaces.
+	// External users must access via `AddStitchingPolicy`
+	stitchingPolicies map[string]struct{}
 	// FetchedResponses is the number of M3 RPC fetch responses received.
 	FetchedResponses int
 	// FetchedBytesEstimate is the estimated number of bytes fetched.
@@ -165,6 +169,26 @@ func (m ResultMetadata) GetNamespaces() []string {
 	return namespaces
 }
 
+// AddStitchingPolicy adds a stitching policy to the stitching policy set,
+// initializing the underlying map if necessary.
+func (m *ResultMetadata) AddStitchingPolicy(policy string) {
+	if m.stitchingPolicies == nil {
+		m.stitchingPolicies = make(map[string]struct{})
+	}
+	m.stitchingPolicies[policy] = struct{}{}
+}
+
+// GetStitchingPolicies returns an array representing the set of stitching
+// policies added via AddStitchingPolicy.
+func (m ResultMetadata) GetStitchingPolicies() []string {
+	policies := make([]string, 0, len(m.stitchingPolicies))
+	for p := range m.stitchingPolicies {
+		policies = append(policies, p)
+	}
+	sort.Strings(policies)
+	return policies
+}
+
 // ByName returns the ResultMetricMetadata for a given metric name.
 func (m *ResultMetadata) ByName(nameTag []byte) *ResultMetricMetadata {
 	if m.metadataByName == nil {
@@ -340,6 +364,7 @@ func (m ResultMetadata) Equals(n ResultMetadata) bool {
 func (m ResultMetadata) CombineMetadata(other ResultMetadata) ResultMetadata {
 	return ResultMetadata{
 		namespaces:           combineNamespaces(m.namespaces, other.namespaces),
+		stitchingPolicies:    combineNamespaces(m.stitchingPolicies, other.stitchingPolicies),
 		FetchedResponses:     m.FetchedResponses + other.FetchedResponses,
 		FetchedBytesEstimate: m.FetchedBytesEstimate + other.FetchedBytesEstimate,
 		LocalOnly:            m.LocalOnly && other.LocalOnly,
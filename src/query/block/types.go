@@ -69,6 +69,28 @@ type Block interface {
 	Info() BlockInfo
 }
 
+// ConsolidatedSeriesBlock is implemented by blocks that can produce their
+// consolidated values one series at a time, allowing results to be rendered
+// without materializing the values of every series at once.
+type ConsolidatedSeriesBlock interface {
+	Block
+	// ConsolidatedSeriesIter returns a series-wise block iterator, giving
+	// consolidated values for each step of the block bounds by series.
+	ConsolidatedSeriesIter() (ConsolidatedSeriesIter, error)
+}
+
+// ConsolidatedSeriesIter iterates through the consolidated values of a block
+// horizontally.
+type ConsolidatedSeriesIter interface {
+	Iterator
+	// SeriesMeta returns the metadata for each series in the block.
+	SeriesMeta() []SeriesMeta
+	// Current returns the consolidated values of the current series, one for
+	// each step of the block bounds. The values are only valid until the next
+	// call to Next.
+	Current() []float64
+}
+
 // AccumulatorBlock accumulates incoming blocks and presents them as a single
 // Block.
 type AccumulatorBlock interface {
@@ -0,0 +1,418 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package cardinality reports the cardinality and churn of the series of
+// namespaces from the index, without fetching any series data, so that
+// cardinality issues can be investigated by the owners of the series.
+package cardinality
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/x/clock"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const (
+	// DefaultTopK is the default number of label and metric names reported.
+	DefaultTopK = 20
+	// DefaultMaxMetricNames is the default maximum number of metric names
+	// whose series are counted to find the metric names with most series.
+	DefaultMaxMetricNames = 1000
+	// DefaultWindow is the default time range that label and metric names
+	// are reported over.
+	DefaultWindow = time.Hour
+	// DefaultChurnHours is the default number of hours that churn is
+	// reported for.
+	DefaultChurnHours = 24
+	// DefaultSeriesLimit is the default maximum number of series matched by
+	// each index query.
+	DefaultSeriesLimit = 1000000
+	// DefaultCacheTTL is the default time reports are cached for.
+	DefaultCacheTTL = 10 * time.Minute
+)
+
+// Report is the cardinality report of a namespace.
+type Report struct {
+	// Namespace is the namespace the report is about.
+	Namespace string `json:"namespace"`
+	// Start is the inclusive start of the time range of labels and metrics.
+	Start time.Time `json:"start"`
+	// End is the exclusive end of the time range of labels and metrics.
+	End time.Time `json:"end"`
+	// Labels are the label names with the most distinct values.
+	Labels []LabelCardinality `json:"labels"`
+	// Metrics are the metric names with the most series.
+	Metrics []MetricCardinality `json:"metrics"`
+	// Churn is the number of series per hour along with how many of them are
+	// new or expired since the previous hour, oldest first.
+	Churn []HourChurn `json:"churn"`
+	// Exhaustive is false if any index query hit the series limit or only
+	// some metric names were counted, in which case counts are lower bounds.
+	Exhaustive bool `json:"exhaustive"`
+	// GeneratedAt is when the report was computed, reports are cached.
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// LabelCardinality is the number of distinct values of a label name.
+type LabelCardinality struct {
+	Name   string `json:"name"`
+	Values int    `json:"values"`
+}
+
+// MetricCardinality is the number of series of a metric name.
+type MetricCardinality struct {
+	Name   string `json:"name"`
+	Series int    `json:"series"`
+}
+
+// HourChurn is the series churn of an hour.
+type HourChurn struct {
+	// Start is the start of the hour.
+	Start time.Time `json:"start"`
+	// Series is the number of series written to in the hour.
+	Series int `json:"series"`
+	// New is the number of series written to in the hour but not the
+	// previous one.
+	New int `json:"new"`
+	// Expired is the number of series written to in the previous hour but
+	// not in the hour.
+	Expired int `json:"expired"`
+}
+
+// AnalyzerOptions are the options for an analyzer.
+type AnalyzerOptions struct {
+	// Storage resolves the labels and series of namespaces from the index.
+	Storage storage.Storage
+	// TagOptions are the tag options the metric name label comes from.
+	TagOptions models.TagOptions
+	// NowFn is the function returning the current time.
+	NowFn clock.NowFn
+	// TopK is the number of label and metric names reported.
+	TopK int
+	// MaxMetricNames is the maximum number of metric names whose series are
+	// counted to find the metric names with most series.
+	MaxMetricNames int
+	// Window is the time range that label and metric names are reported
+	// over, ending at the time of the report.
+	Window time.Duration
+	// ChurnHours is the number of hours that churn is reported for.
+	ChurnHours int
+	// SeriesLimit is the maximum number of series matched by each index
+	// query.
+	SeriesLimit int
+	// CacheTTL is the time reports are cached for.
+	CacheTTL time.Duration
+}
+
+// Analyzer computes the cardinality reports of namespaces with index
+// aggregate and series queries and caches them.
+type Analyzer struct {
+	opts AnalyzerOptions
+
+	lock  sync.Mutex
+	cache map[string]*cachedReport
+}
+
+type cachedReport struct {
+	done      chan struct{}
+	report    Report
+	err       error
+	expiresAt time.Time
+}
+
+// NewAnalyzer returns a new analyzer.
+func NewAnalyzer(opts AnalyzerOptions) *Analyzer {
+	if opts.TagOptions == nil {
+		opts.TagOptions = models.NewTagOptions()
+	}
+	if opts.NowFn == nil {
+		opts.NowFn = time.Now
+	}
+	if opts.TopK <= 0 {
+		opts.TopK = DefaultTopK
+	}
+	if opts.MaxMetricNames <= 0 {
+		opts.MaxMetricNames = DefaultMaxMetricNames
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+	if opts.ChurnHours <= 0 {
+		opts.ChurnHours = DefaultChurnHours
+	}
+	if opts.SeriesLimit <= 0 {
+		opts.SeriesLimit = DefaultSeriesLimit
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	return &Analyzer{
+		opts:  opts,
+		cache: make(map[string]*cachedReport),
+	}
+}
+
+// Report returns the cardinality report of the namespace, computing it if
+// there is no cached report. Concurrent requests for the same report share
+// a single computation and failed computations are not cached.
+func (a *Analyzer) Report(
+	ctx context.Context,
+	namespace string,
+	fetchOpts *storage.FetchOptions,
+) (Report, error) {
+	// Reports restricted to the series visible to a requester are cached
+	// separately from those of other requesters.
+	key := namespace
+	if restrict := fetchOpts.RestrictQueryOptions.GetRestrictByTag(); restrict != nil {
+		key += restrict.GetMatchers().String()
+	}
+
+	a.lock.Lock()
+	cached, ok := a.cache[key]
+	if ok {
+		select {
+		case <-cached.done:
+			if a.opts.NowFn().After(cached.expiresAt) {
+				ok = false
+			}
+		default:
+		}
+	}
+	if !ok {
+		cached = &cachedReport{done: make(chan struct{})}
+		a.cache[key] = cached
+		a.lock.Unlock()
+
+		cached.report, cached.err = a.analyze(ctx, namespace, fetchOpts)
+		cached.expiresAt = a.opts.NowFn().Add(a.opts.CacheTTL)
+
+		a.lock.Lock()
+		if cached.err != nil && a.cache[key] == cached {
+			delete(a.cache, key)
+		}
+		a.lock.Unlock()
+		close(cached.done)
+		return cached.report, cached.err
+	}
+	a.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return Report{}, ctx.Err()
+	case <-cached.done:
+		return cached.report, cached.err
+	}
+}
+
+func (a *Analyzer) analyze(
+	ctx context.Context,
+	namespace string,
+	fetchOpts *storage.FetchOptions,
+) (Report, error) {
+	var (
+		now    = a.opts.NowFn()
+		opts   = a.namespaceFetchOptions(namespace, fetchOpts)
+		report = Report{
+			Namespace:   namespace,
+			Start:       now.Add(-a.opts.Window),
+			End:         now,
+			Exhaustive:  true,
+			GeneratedAt: now,
+		}
+	)
+
+	tags, err := a.opts.Storage.CompleteTags(ctx, &storage.CompleteTagsQuery{
+		TagMatchers: models.Matchers{{Type: models.MatchAll}},
+		Start:       xtime.ToUnixNano(report.Start),
+		End:         xtime.ToUnixNano(report.End),
+	}, opts)
+	if err != nil {
+		return Report{}, err
+	}
+	report.Exhaustive = tags.Metadata.Exhaustive
+
+	var metricNames [][]byte
+	labels := make([]LabelCardinality, 0, len(tags.CompletedTags))
+	for _, tag := range tags.CompletedTags {
+		labels = append(labels, LabelCardinality{
+			Name:   string(tag.Name),
+			Values: len(tag.Values),
+		})
+		if bytes.Equal(tag.Name, a.opts.TagOptions.MetricName()) {
+			metricNames = tag.Values
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Values != labels[j].Values {
+			return labels[i].Values > labels[j].Values
+		}
+		return labels[i].Name < labels[j].Name
+	})
+	if len(labels) > a.opts.TopK {
+		labels = labels[:a.opts.TopK]
+	}
+	report.Labels = labels
+
+	metrics, exhaustive, err := a.metrics(ctx, metricNames, report.Start, report.End, opts)
+	if err != nil {
+		return Report{}, err
+	}
+	report.Metrics = metrics
+	report.Exhaustive = report.Exhaustive && exhaustive
+
+	churn, exhaustive, err := a.churn(ctx, now, opts)
+	if err != nil {
+		return Report{}, err
+	}
+	report.Churn = churn
+	report.Exhaustive = report.Exhaustive && exhaustive
+
+	return report, nil
+}
+
+// namespaceFetchOptions returns the fetch options of the request restricted
+// to the namespace.
+func (a *Analyzer) namespaceFetchOptions(
+	namespace string,
+	fetchOpts *storage.FetchOptions,
+) *storage.FetchOptions {
+	opts := fetchOpts.Clone()
+	opts.SeriesLimit = a.opts.SeriesLimit
+	opts.RequireExhaustive = false
+
+	var restrict storage.RestrictQueryOptions
+	if fetchOpts.RestrictQueryOptions != nil {
+		restrict = *fetchOpts.RestrictQueryOptions
+	}
+	restrict.RestrictByType = nil
+	restrict.RestrictByTypes = nil
+	restrict.RestrictByNamespace = &storage.RestrictByNamespace{Namespace: namespace}
+	opts.RestrictQueryOptions = &restrict
+	return opts
+}
+
+// metrics returns the metric names with the most series, counting the series
+// of at most the maximum number of metric names.
+func (a *Analyzer) metrics(
+	ctx context.Context,
+	names [][]byte,
+	start, end time.Time,
+	opts *storage.FetchOptions,
+) ([]MetricCardinality, bool, error) {
+	exhaustive := true
+	if len(names) > a.opts.MaxMetricNames {
+		names = names[:a.opts.MaxMetricNames]
+		exhaustive = false
+	}
+
+	metrics := make([]MetricCardinality, 0, len(names))
+	for _, name := range names {
+		explanation, err := a.opts.Storage.ExplainQuery(ctx, &storage.FetchQuery{
+			TagMatchers: models.Matchers{{
+				Type:  models.MatchEqual,
+				Name:  a.opts.TagOptions.MetricName(),
+				Value: name,
+			}},
+			Start: start,
+			End:   end,
+		}, opts)
+		if err != nil {
+			return nil, false, err
+		}
+
+		metric := MetricCardinality{Name: string(name)}
+		for _, ns := range explanation.Namespaces {
+			metric.Series += ns.EstimatedSeries
+			exhaustive = exhaustive && ns.Exhaustive
+		}
+		metrics = append(metrics, metric)
+	}
+
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Series != metrics[j].Series {
+			return metrics[i].Series > metrics[j].Series
+		}
+		return metrics[i].Name < metrics[j].Name
+	})
+	if len(metrics) > a.opts.TopK {
+		metrics = metrics[:a.opts.TopK]
+	}
+	return metrics, exhaustive, nil
+}
+
+// churn returns the series churn of each of the last hours, the series of
+// each hour are compared with those of the previous hour so the hour before
+// the oldest reported hour is also queried. Series are resolved at the
+// granularity of index blocks so hours within the same index block share
+// their series.
+func (a *Analyzer) churn(
+	ctx context.Context,
+	now time.Time,
+	opts *storage.FetchOptions,
+) ([]HourChurn, bool, error) {
+	var (
+		end        = now.Truncate(time.Hour)
+		start      = end.Add(-time.Duration(a.opts.ChurnHours+1) * time.Hour)
+		churn      = make([]HourChurn, 0, a.opts.ChurnHours)
+		exhaustive = true
+		prev       map[uint64]struct{}
+	)
+	for hour := start; hour.Before(end); hour = hour.Add(time.Hour) {
+		result, err := a.opts.Storage.SearchSeries(ctx, &storage.FetchQuery{
+			TagMatchers: models.Matchers{{Type: models.MatchAll}},
+			Start:       hour,
+			End:         hour.Add(time.Hour),
+		}, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		exhaustive = exhaustive && result.Metadata.Exhaustive
+
+		curr := make(map[uint64]struct{}, len(result.Metrics))
+		for _, metric := range result.Metrics {
+			curr[xxhash.Sum64(metric.ID)] = struct{}{}
+		}
+		if prev != nil {
+			hourChurn := HourChurn{Start: hour, Series: len(curr)}
+			for id := range curr {
+				if _, ok := prev[id]; !ok {
+					hourChurn.New++
+				}
+			}
+			for id := range prev {
+				if _, ok := curr[id]; !ok {
+					hourChurn.Expired++
+				}
+			}
+			churn = append(churn, hourChurn)
+		}
+		prev = curr
+	}
+	return churn, exhaustive, nil
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cardinality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3/consolidators"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMetadata(exhaustive bool) block.ResultMetadata {
+	meta := block.NewResultMetadata()
+	meta.Exhaustive = exhaustive
+	return meta
+}
+
+func values(prefix string, n int) [][]byte {
+	result := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		result = append(result, []byte(fmt.Sprintf("%s%d", prefix, i)))
+	}
+	return result
+}
+
+func metrics(ids ...string) models.Metrics {
+	result := make(models.Metrics, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, models.Metric{ID: []byte(id)})
+	}
+	return result
+}
+
+func requireNamespaceRestricted(t *testing.T, opts *storage.FetchOptions) {
+	require.Equal(t, "default", opts.RestrictQueryOptions.GetRestrictByNamespace().Namespace)
+	require.Nil(t, opts.RestrictQueryOptions.GetRestrictByType())
+	require.NoError(t, opts.RestrictQueryOptions.Validate())
+	require.Equal(t, 100, opts.SeriesLimit)
+}
+
+func expectReport(t *testing.T, store *storage.MockStorage, now time.Time) {
+	store.EXPECT().
+		CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			q *storage.CompleteTagsQuery,
+			opts *storage.FetchOptions,
+		) (*consolidators.CompleteTagsResult, error) {
+			requireNamespaceRestricted(t, opts)
+			require.False(t, q.CompleteNameOnly)
+			require.True(t, now.Add(-time.Hour).Equal(q.Start.ToTime()))
+			require.True(t, now.Equal(q.End.ToTime()))
+			return &consolidators.CompleteTagsResult{
+				CompletedTags: []consolidators.CompletedTag{
+					{Name: []byte("__name__"), Values: [][]byte{
+						[]byte("cpu"), []byte("disk"), []byte("mem"),
+					}},
+					{Name: []byte("dc"), Values: values("dc", 2)},
+					{Name: []byte("host"), Values: values("host", 10)},
+					{Name: []byte("region"), Values: values("region", 2)},
+				},
+				Metadata: newTestMetadata(true),
+			}, nil
+		})
+
+	series := map[string]int{"cpu": 20, "disk": 5, "mem": 20}
+	store.EXPECT().
+		ExplainQuery(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			q *storage.FetchQuery,
+			opts *storage.FetchOptions,
+		) (storage.QueryExplanation, error) {
+			requireNamespaceRestricted(t, opts)
+			require.Len(t, q.TagMatchers, 1)
+			require.Equal(t, models.MatchEqual, q.TagMatchers[0].Type)
+			return storage.QueryExplanation{
+				Namespaces: []storage.NamespaceExplanation{{
+					Namespace:       "default",
+					EstimatedSeries: series[string(q.TagMatchers[0].Value)],
+					Exhaustive:      true,
+				}},
+			}, nil
+		}).
+		Times(3)
+
+	hours := []models.Metrics{
+		metrics("a", "b", "c"),
+		metrics("a", "b", "c"),
+		metrics("b", "c", "d", "e"),
+	}
+	hourStart := now.Truncate(time.Hour).Add(-3 * time.Hour)
+	for i, hour := range hours {
+		hour := hour
+		start := hourStart.Add(time.Duration(i) * time.Hour)
+		store.EXPECT().
+			SearchSeries(gomock.Any(), &storage.FetchQuery{
+				TagMatchers: models.Matchers{{Type: models.MatchAll}},
+				Start:       start,
+				End:         start.Add(time.Hour),
+			}, gomock.Any()).
+			DoAndReturn(func(
+				_ context.Context,
+				_ *storage.FetchQuery,
+				opts *storage.FetchOptions,
+			) (*storage.SearchResults, error) {
+				requireNamespaceRestricted(t, opts)
+				return &storage.SearchResults{
+					Metrics:  hour,
+					Metadata: newTestMetadata(true),
+				}, nil
+			})
+	}
+}
+
+func newTestAnalyzer(store storage.Storage, now *time.Time) *Analyzer {
+	return NewAnalyzer(AnalyzerOptions{
+		Storage:     store,
+		NowFn:       func() time.Time { return *now },
+		TopK:        2,
+		ChurnHours:  2,
+		SeriesLimit: 100,
+		CacheTTL:    time.Minute,
+	})
+}
+
+func TestAnalyzerReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		store    = storage.NewMockStorage(ctrl)
+		now      = time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC)
+		analyzer = newTestAnalyzer(store, &now)
+	)
+	expectReport(t, store, now)
+
+	fetchOpts := storage.NewFetchOptions()
+	fetchOpts.RestrictQueryOptions = &storage.RestrictQueryOptions{
+		RestrictByType: &storage.RestrictByType{},
+	}
+	report, err := analyzer.Report(context.Background(), "default", fetchOpts)
+	require.NoError(t, err)
+
+	// The restriction of the request is left untouched.
+	require.Nil(t, fetchOpts.RestrictQueryOptions.RestrictByNamespace)
+
+	require.Equal(t, Report{
+		Namespace: "default",
+		Start:     now.Add(-time.Hour),
+		End:       now,
+		Labels: []LabelCardinality{
+			{Name: "host", Values: 10},
+			{Name: "__name__", Values: 3},
+		},
+		Metrics: []MetricCardinality{
+			{Name: "cpu", Series: 20},
+			{Name: "mem", Series: 20},
+		},
+		Churn: []HourChurn{
+			{Start: time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC), Series: 3},
+			{Start: time.Date(2021, 6, 1, 11, 0, 0, 0, time.UTC), Series: 4, New: 2, Expired: 1},
+		},
+		Exhaustive:  true,
+		GeneratedAt: now,
+	}, report)
+}
+
+func TestAnalyzerReportCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		store    = storage.NewMockStorage(ctrl)
+		now      = time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC)
+		analyzer = newTestAnalyzer(store, &now)
+		ctx      = context.Background()
+	)
+	expectReport(t, store, now)
+
+	report, err := analyzer.Report(ctx, "default", storage.NewFetchOptions())
+	require.NoError(t, err)
+
+	// Served from the cache until the report expires.
+	now = now.Add(30 * time.Second)
+	cached, err := analyzer.Report(ctx, "default", storage.NewFetchOptions())
+	require.NoError(t, err)
+	require.Equal(t, report, cached)
+
+	now = now.Add(time.Minute)
+	expectReport(t, store, now)
+	refreshed, err := analyzer.Report(ctx, "default", storage.NewFetchOptions())
+	require.NoError(t, err)
+	require.Equal(t, now, refreshed.GeneratedAt)
+}
+
+func TestAnalyzerReportErrorNotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		store    = storage.NewMockStorage(ctrl)
+		now      = time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC)
+		analyzer = newTestAnalyzer(store, &now)
+		ctx      = context.Background()
+	)
+	store.EXPECT().
+		CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("boom"))
+
+	_, err := analyzer.Report(ctx, "default", storage.NewFetchOptions())
+	require.Error(t, err)
+
+	expectReport(t, store, now)
+	_, err = analyzer.Report(ctx, "default", storage.NewFetchOptions())
+	require.NoError(t, err)
+}
+
+func TestAnalyzerReportMaxMetricNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		store = storage.NewMockStorage(ctrl)
+		now   = time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC)
+	)
+	analyzer := NewAnalyzer(AnalyzerOptions{
+		Storage:        store,
+		NowFn:          func() time.Time { return now },
+		MaxMetricNames: 2,
+		ChurnHours:     1,
+	})
+
+	store.EXPECT().
+		CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&consolidators.CompleteTagsResult{
+			CompletedTags: []consolidators.CompletedTag{
+				{Name: []byte("__name__"), Values: values("metric", 5)},
+			},
+			Metadata: newTestMetadata(true),
+		}, nil)
+	store.EXPECT().
+		ExplainQuery(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(storage.QueryExplanation{
+			Namespaces: []storage.NamespaceExplanation{{EstimatedSeries: 1, Exhaustive: true}},
+		}, nil).
+		Times(2)
+	store.EXPECT().
+		SearchSeries(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&storage.SearchResults{Metadata: newTestMetadata(true)}, nil).
+		Times(2)
+
+	report, err := analyzer.Report(context.Background(), "default", storage.NewFetchOptions())
+	require.NoError(t, err)
+	require.Len(t, report.Metrics, 2)
+	require.False(t, report.Exhaustive)
+}
@@ -261,47 +261,47 @@ d8dUBmQZxiF/+uI1I7E8TMF6pCyWxDpY7WPA8pmKZsl6ouawOaOS+Sj+BQAA//8by2IcfAIAAA==
 	"/spec.yml": {
 		name:    "spec.yml",
 		local:   "openapi/spec.yml",
-		size:    26055,
+		size:    26103,
 		modtime: 12345,
 		compressed: `
-H4sIAAAAAAAC/+xdX3PbuBF/16dAdX3oPUR07PQ6ozfJ8jmaOo7Hzt1M76YPELGkcCUBFgDjJDf97h2C
-lPgPJEFK/qMM9RKbWiyWi/3tb7GEGR4BwxGdo4vZ2ex8QpnH5xOEFFUBzNGHi9VyghAB6QoaKcrZHC0Q
-oVIJuokVEKRoCEiCoCARwQpvsAQUS8p89OHi08NvyAs4Vj+9Qy4PIwFSUs5m6F88Ri5mE4QQ8igjiMcK
-hVwAwpvkx2RehBX6fatUJOeOE16QzYxyh3BX/vtvpqs/amVcIM7Q79dUvY83uaBP1TbezFwe6jFOePHj
-bILQZxBS39Pb2dnsbIKQy5nCrpprXQyH2gXLFbrm3A8AXQseR/q7WARztNeeXJYzXwvpSTwu4tD54S/p
-v8mUE4QC6gKTUFS+iLC7BXSTfoPOtRFV7TXbnU3AN06IpQLh3Kwvr24friYK+zJR/WZv92qJbnEIMsIu
-aLWlZbzkzKN+LNKVWi31MC0rq1ruAuxCCExZaIl2sjUtqyw66kpKX795pASQFzM3+bKs5ZJzQSjDigt7
-o4qDGqwriJi0ZV+CRAIwkQgzgh4FVRVPLXxfgN/PuMKYBttyCYPjQAnqSoRzLYlthD8yicMoADGRIJIo
-TyNjH1dzx9lyqfQc/zg/e+vgiDqf304irLZa1knGURdkGnP72EiD14cMIhV7rkEhHASGeEo+uwhNP29M
-EYoQj0DgRNuazFF4sVruBa5BZTICZMSZhIK26fnZ2TT/tWLX9OM/p4XvEpQDU0VxhHAUBdTVUzt/SM7K
-3yIk3S2EuHoVob8K8OZo+oOTJDjOkvVzUlnpFG2/z4zODZm+O3vXYvMtV8jjMSMvYvo1MBDUvRKCi4LJ
-f29184MONgTlQS9qdcTlfl6rACxzHSEIs0o8d0bqgpB9pP43BqmWnHzNJzZ4o90XZk9YBd6CkPvUhukI
-nhE8fcETH4CdXyKCFQyATzrwtSAotWYE0QiiQVZPmyop58/9j+vV/7JbIhCAguGYW+nxAzCXDszEIixw
-CCorG3ezpwVpweiCXyibo6R2LFxKgEsFkDlSIoZJu1fV1wjmKNlVMv/UIJa6Tpf1ItSqR4A9o9UVfO13
-UbWdihFN5Y2aYTujtlDZ2DZhaa/qBDcqRdsNNPH9Vf2t655V/ZRJhZkLSHEdBvYR0LEBMN1LLkihsB4n
-XL93xNSYGZ/eaotyohUIWTkxIAemIxdB8F2x+QlTo5u3OHtxZHPL1SDRTqLGRqw5krToSKinQqgHB8kw
-xi1HyUi6I+meAukeDJYSKw9OqiNDvzKGzh/i9SLoxqeOdYF2el74flcELXx/JOVTIeUDA2P/6CuJi57E
-XIyTkZZHWj4FWj4QLiVS7plKRyp+xX1khzKamXNYV3HNqKI4oN8GNVSS0UfKo4mqMZGOifS4MBGgfz4G
-Uu5TVfvHmPvqg7KejfhM05GAk2kbsTNi52n6sdZcc3APoUZGg/sIXcT0DGd1TMxWuqMRqiNUjw3VHnx3
-MFpLhFiXbcXnSIEjrk6n4WnNgAdu12v813PLPm7HRjydBJ560NSBkCqRVE20BUsjQ42Ier0HtnMo/bnr
-Q/Q6r21zwKrW6PAED3u2OqxPcOd3MR7gHiH36iBn3m0NwN6xzllUN139UWo6eDECdQTqaQPVWG4OwOlx
-HrzWjyrYorP+JHbE5ojNU38wt3sXi+MKwMr2sVzpHR0NL64Amco+UrVFIZcKcS0hEQEPx4EC0lSsrnZW
-XWqjXvixwapkzKluGKt3MULvma3OBRItpa5EqtZwgG03X83kNnNNplql5OrBOVPr8XktqvU8G9o3z2tV
-vXWUiaS6DC/V2CdTzdx88we4uyojEkn6U7SYQXTtMGklfLTLpkU5q7/w/5iOm5ZtLb2+4JnMNahvmiJN
-tcnaUs4+1lW1qGtTWVJ7B4JysopTLqoLNtyY1hEzRUNoMMxqWe5LKiqrU1FsuS4bzpVUAkdXDG8CIHXf
-bzgPIHu/WvLxglhuraXTd1t94pc8DKm64X73EDf5LbY3SECEqegh3hwibatwXxmX04VkOJJbrqxNoIzA
-l57Trwtj8qmbg2pwQN03+McyoCygYoTIJuDufx7oN7AfEXseiJ9jFYu+g+6wVP0sS0qjqy8RFV+7l7cy
-YOEpELdcLVwXpOzhlrUhTCxXAWxDsZ/bGwKnp3E6g+ibo8y/A3F598slZ24sBDDX4F8WhxsQhcseFyFW
-c0R4vAmgnGuOrNf00pveiPCpVOXAsUNnNrCS6O8r+nowcPoOPQt+xYTQZGlxcNdAi/3riPoJ7553kHZ/
-OoLa1DzoOU/lT6Z6VH15Yt69DrRmK2UKfGPUUaYuzsv30NPwXSfp6Vd4nc00LbJwUkX/jF3FRfdtszh8
-2GJBZLcolVrSJpu5seKfQXyipmKz1fM/vSvM94Em2zGbCUP8RRv3AGpN2qfcuazvmpJOgqKSBxpP+p2y
-neLfOOsuxR+B+lvV7URgJOKUqU6FsmG1sRD4a7HxqSDsEYja+9PyLBaLkXz2by3tMj3iQtmt7bC90fey
-xEd0qF7WI7ixei9SYQW2aT1FdjKiEGA8Fi6su1csS0W3mHE5PBclWjzvACX5PZS9WbIWWBwW+7fr2/Wn
-9eJm/dv69rpwefHrYn2zWN5cFa7dXC1+3Um1NKcOJ7AD80QFofmCely4YFlSGHpcr/fGejCsqQYpcHqS
-d2x5vbWcMTfkLF0YAP5Mmb/ePy0b5kszWDEjlGB1AoFXOj/wuqOw2NvuaWmSp+Pi/Om4abpuuROhqNu4
-vMYnM0O3Trc27KYvTjo7guWkm2bTgLs4qFxzg1iqYZXzE+O61O4x19xNTR5bCl7uBkxLVcbxA/g930Xt
-smqjZZAoGwfAlwhcBeRB/y8XSWzq0kregXjPYzGM49/zpyg5MSECpDxCMffiBexTON38rHRoYundGzIe
-jRjeuCiq+38AAAD//3KwMhXHZQAA
+H4sIAAAAAAAC/+1d3XPbNhJ/91+BU/twfYjlOLl2Rm/yRx3NuY7HTjvTdvoAkaCEliR4ABjH6dz/3gVI
+iV8gCVCyY2Woh0QmF4vFYn+7iwUMs4TEOKEz9Ob45Pj0iMYBmx0hJKkMyQz99ObiDH7yifA4TSRl8QzN
+kU+F5HSZSuIDYUSQIJwSgXws8RILglJB4xU0/nD/GwpChuX3b5HHooQTIYDHMfqVpcjDMbBGKKCxj1gq
+UcQ4QXipvqp+EZbo97WUiZhNp9Ebf3lM2dRnnvjj36an32lmjCMWo9+vqHyXLgvCFZXrdHkMMug28M93
+x0D/kXChx/QaBn8CDzwWS+zJmeYV40ir4OwCXTG2Cgm64ixN9LuUhzO05a4ei+OVJtKdBIyn0fSbf2X/
+qy6hVUg9EgtSZj5PsLcm6Dp7g061EHXuDdmny5DB/1hIwqfXi/PLm/vLI4lXQrF+tZUbVHgDXwX0QTTb
+yjSeszigq5RnMwW08YZW1LnchvA0IrG04JJsaBtcLnLraDKpvH71QH2CgjT21Msql3PGuE9jLGGarYUq
+N2qRrkRi4pa/BAvnBPsCYTDYB05lTVPz1YqTlZtwpTYtshUUBsURwKEHAhVclGw+e4gFjpKQ8COAprLy
+zDK2dgVmtWZC6j5+OD15PQUXMP34+ijBcq1pp6odWKXIbG5rG5nxrkgOkZo8V0QiHIYGe1KfjYVmn1cm
+CwUAJ4RjxW3hz1AEFFsCYJ7TgBtJwDRIidvk9ORkUvxYk2vy/r+T0juFctBzmRwhnCQAUN319E8BrSpv
+ERIA1AjXnyL0LScB9PDNVDk4Fqv5m2a0YlqW/S4XuhBk8vbkbYfMN0yigKWx/0VEvyIxOHXvknPGSyL/
+p1PN99rYEKk2+qJSJ2DmMxcDrMY63wdE1ey511Kh1dZS/5cSIc+Y/1h0bNBGty7MmrAyPBDlLpNhMoJn
+BI8reNIdsPNzAvkgGQCfrOFLQVAmzQiiEUSDpJ60ZVLTv7dfFxf/z4fkk5BIMhxzF7r9AMxlDXOyBHN4
+LvO0cdN7lpCWhC7phULfKncsPVLApZxAR5Kn5Khbq/IxAd5qVRmvDg1imep0Ws8jzXoE2DNKXcPXdhXV
+WKkY0VRdqBmWMxKW59WFbRuWtqwOcKFSlt0QJr6+rL9z3vOsn8ZC4tgjSDJtBvYW0LMAMI2lIKSkNB8H
+nL/32NToGZ9eaot0ohMIeToxwAdmLedh+FVF8wMOjV5R4nSKke0lVwNFdxA1FmLNlqRJx4B6KAF1ZyMZ
+FnGrVjIG3THoHkLQ3Rkslag82KmOEfqFRehiE88pQLfuOjYJusMz0PVZEJCMQflQgvKOhrHd+lJ24RiY
+y3YyhuUxLB9CWN4RLpWg7OhKx1D8guvIUxrTXJzdqooL4ENxSD8PKqio1nvyo4rV6EhHR7pfmHCiv+8D
+KXcZq+025jb7oLFjIT7ntCfg5NxG7IzYeZp6rHWs2bmG0AhGg+sIfYHpGc7qmCJbZUQjVEeo7huqDvFu
+Z7RWAmKTthOfYwgccXU4BU/rCLjjcr0R/xyX7ONybMTTQeDJIUztCKlKkGqQdmBpjFAjol7uge0CSn9v
+6hBO57VtDlg1Ch0BZ5FjqcP6BHcxivEA9wi5Fwc582prAPb2dc6ivuhyR6np4MUI1BGohw1UY7o5AKf7
+2XhtHlWwRWdzJ3bE5ojNQ9+Y29zFMvU4wdJ2W65yR0fLxRVEZLQPVK5RBBwBUPqqDiAPcBpK4rclqxcb
+qc61UF942+CiIsyhLhjroxih98xSFwSKS6UqkbE1HGDb9NcQuUtck6hWLrl+cM5UenxeiRo1z5byzfNK
+1Swd5SQZL8OlGltnqiM3W/5JvE2WkXDl/iQtexCdOxx1Bny08aZlOqvf8H+ftZtUZa1cX/BM4hrYt3WR
+uVo1t8DkfZNVB7sulhW2twBW5l+kWSxqErYMTPNIY3XBWYtgVtNyV2FRm50aY8t5WTImQVicXMZ4GUJy
+2dA9UIQkv19NfYIwFWtr6uxuqw/snEURldds1d/EUz+l9gJxkmDKHcjbTaRrFu5q7YpwIWKciDWT1iLQ
+2CefHLtflNoUXbcb1WCDumvRj6VBWUDFCJFlyLy/7ulnYt8iDQLCf0wl5JGOjW6xkG6SqdTo8lNC+WP/
+9NYazANY90FuNPcgoxYOalkYzMRyFoitKbqpvcVwHIXTHkQPDviCPs5vf4YFgZdyTmLPoN84jZaElx4H
+TB0RniGfpTDKKqwUS5wKa9+0ZzlMl+Q4I2ilLsV8dEdz3rAWGO5q/BwidnbnnkU8xr5PlSng8LYljLrn
+Hc0T4Y4jyKpFPYZgKjY49lP7FSuHLLFw5JvrQxuyUshWV0argzdvTqtjcBR8U3l6+hle5D1NylFbZd0/
+Yk8y3j9swN79GnNf9JNSoSltPICXSgaa/0BNyWmn5r9/W+rvJ6qWbzYdRviTFu6eyIXf3eVGZa5z6vcG
+NCpYqPGk76DtJf8Mc9lL9EDoai37lUhiP2E0lr0MRctsY87xY7lQKknkYIha+5NqLxaToT7bW077RE8Y
+l3ZzO2wt9bVM8R4Vqqd1D2qsjwWmSRJbt54hW7UoGRhLuUcW/TOWu6IbHDMx3BcpLkGwA5NiDFVtVqQl
+4I3L9d7FzeLDYn69+G1xc1V6PP9lvrien11flp5dX85/2VB1FLN2D2A7+okaQosJBZV5xDKlMNTEXu7A
+HCKsKQcpxXTld2zjemc6Yy7gWaoQpuMjmOxiu7s2TJdmsOLYp6oY9vINr3Le4GVbYbkW7iip8tNpuf+s
+3SSbt0KJpMzbOL3GnZyhS6cbm+imH/YR1Z1u5k1h8Y7D2jMPVtdyWOb8xLiulIfMOXdbUcg2BJ9tGkwq
+Wcb+Dfgd21jtWV1GSyORNgognxLgQiBFVX8VQ9mmTq1UFekdZBbDYvw79hQpJywQ1d/k2EMy98UT2KdQ
+unlvdahjca4NGY9SDC9clNn9AxHJDuT3ZQAA
 `,
 	},
 
@@ -21,6 +21,7 @@
 package models
 
 import (
+	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/models/strconv"
 	"github.com/m3db/m3/src/query/util/writer"
 )
@@ -218,16 +219,35 @@ func idLenGraphite(t Tags) int {
 }
 
 func graphiteID(t Tags) []byte {
+	// NB: only graphite path tags make up the ID so that any other tags, such
+	// as tags mapped from path components at ingestion, do not alter the path.
+	// If there are no graphite path tags then all tag values are used.
+	pathTagsOnly := false
+	for _, tag := range t.Tags {
+		if isGraphitePathTag(tag.Name) {
+			pathTagsOnly = true
+			break
+		}
+	}
+
 	// TODO: pool these bytes.
-	id := make([]byte, idLenGraphite(t))
-	idx := 0
-	lastIndex := len(t.Tags) - 1
-	for _, tag := range t.Tags[:lastIndex] {
-		idx += copy(id[idx:], tag.Value)
-		id[idx] = graphiteSep
-		idx++
+	id := make([]byte, 0, idLenGraphite(t))
+	first := true
+	for _, tag := range t.Tags {
+		if pathTagsOnly && !isGraphitePathTag(tag.Name) {
+			continue
+		}
+		if !first {
+			id = append(id, graphiteSep)
+		}
+		id = append(id, tag.Value...)
+		first = false
 	}
 
-	copy(id[idx:], t.Tags[lastIndex].Value)
 	return id
 }
+
+func isGraphitePathTag(name []byte) bool {
+	_, ok := graphite.TagIndex(name)
+	return ok
+}
@@ -76,6 +76,27 @@ func TestLongTagNewIDOutOfOrderGraphite(t *testing.T) {
 	assert.Equal(t, []byte("v0.v1.v2.v3.v4.v5.v6.v7.v8.v9.v10.v11.v12"), actual)
 }
 
+func TestGraphiteIDIgnoresNonPathTags(t *testing.T) {
+	opts := NewTagOptions().SetIDSchemeType(TypeGraphite)
+	tags := NewTags(5, opts).AddTags([]Tag{
+		{Name: []byte("host"), Value: []byte("host1")},
+		{Name: []byte("__g2__"), Value: []byte("cpu")},
+		{Name: []byte("__g0__"), Value: []byte("servers")},
+		{Name: []byte("__name__"), Value: []byte("servers_cpu")},
+		{Name: []byte("__g1__"), Value: []byte("host1")},
+	})
+
+	assert.Equal(t, []byte("servers.host1.cpu"), tags.ID())
+	require.NoError(t, tags.Validate())
+
+	// Without any graphite path tags all tag values are used.
+	tags = NewTags(2, opts).AddTags([]Tag{
+		{Name: []byte("foo"), Value: []byte("a")},
+		{Name: []byte("bar"), Value: []byte("b")},
+	})
+	assert.Equal(t, []byte("b.a"), tags.ID())
+}
+
 func TestLongTagNewIDOutOfOrderQuotedWithEscape(t *testing.T) {
 	tags := testLongTagIDOutOfOrder(t, TypeQuoted)
 	tags = tags.AddTag(Tag{Name: []byte(`t5""`), Value: []byte(`v"5`)})
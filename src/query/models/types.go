@@ -62,6 +62,7 @@ const (
 	// ingestion path, as it ignores tag names and is very prone to collisions if
 	// used on non-graphite data.
 	// {__g0__:v1},{__g1__:v2} -> v1.v2
+	// {__g0__:v1},{__g1__:v2},{t1:v3} -> v1.v2
 	//
 	// NB: when TypeGraphite is specified, tags are ordered numerically rather
 	// than lexically.
@@ -0,0 +1,309 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package querycost estimates the cost of queries from the index before they
+// are executed and admits or rejects them based on the thresholds of the
+// identity of the requester.
+package querycost
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/query/accesscontrol"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/storage"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/headers"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+const (
+	// DefaultResolution is the default interval assumed between datapoints of
+	// series in namespaces without a resolution, i.e. unaggregated namespaces.
+	DefaultResolution = 10 * time.Second
+	// DefaultBytesPerDatapoint is the default number of compressed bytes
+	// assumed per datapoint.
+	DefaultBytesPerDatapoint = 2.0
+)
+
+// Estimate is the estimated cost of a query.
+type Estimate struct {
+	// Series is the estimated number of series fetched.
+	Series int64 `json:"series"`
+	// Datapoints is the estimated number of datapoints fetched.
+	Datapoints int64 `json:"datapoints"`
+	// Bytes is the estimated number of compressed bytes fetched.
+	Bytes int64 `json:"bytes"`
+	// Exhaustive is false if the series of any selector hit the series limit
+	// of the query, in which case the estimate is a lower bound.
+	Exhaustive bool `json:"exhaustive"`
+	// Selectors are the estimates of each series selector of the query.
+	Selectors []SelectorEstimate `json:"selectors"`
+}
+
+// SelectorEstimate is the estimated cost of a single series selector.
+type SelectorEstimate struct {
+	Matchers   string    `json:"matchers"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Series     int64     `json:"series"`
+	Datapoints int64     `json:"datapoints"`
+	Bytes      int64     `json:"bytes"`
+	Exhaustive bool      `json:"exhaustive"`
+}
+
+// Thresholds are the maximum estimated costs of queries that are admitted,
+// zero values are not enforced.
+type Thresholds struct {
+	MaxSeries     int64 `json:"maxSeries,omitempty"`
+	MaxDatapoints int64 `json:"maxDatapoints,omitempty"`
+	MaxBytes      int64 `json:"maxBytes,omitempty"`
+}
+
+// Check returns a resource exhausted error if the estimate exceeds any of the
+// thresholds.
+func (t Thresholds) Check(e Estimate) error {
+	switch {
+	case t.MaxSeries > 0 && e.Series > t.MaxSeries:
+		return newThresholdError("series", e.Series, t.MaxSeries)
+	case t.MaxDatapoints > 0 && e.Datapoints > t.MaxDatapoints:
+		return newThresholdError("datapoints", e.Datapoints, t.MaxDatapoints)
+	case t.MaxBytes > 0 && e.Bytes > t.MaxBytes:
+		return newThresholdError("bytes", e.Bytes, t.MaxBytes)
+	}
+	return nil
+}
+
+func newThresholdError(resource string, estimated, limit int64) error {
+	details := xerrors.NewErrorDetails(xerrors.CodeResourceExhausted)
+	details.Resource = "estimated_" + resource
+	details.Limit = limit
+	return xerrors.NewStructuredError(fmt.Errorf(
+		"query estimated to fetch %d %s, exceeding the %s limit of %d, "+
+			"set the %s header to true to execute it anyway",
+		estimated, resource, details.Resource, limit, headers.QueryCostForceHeader),
+		details)
+}
+
+// Policy determines the thresholds enforced on the queries of each identity.
+type Policy struct {
+	// Identities are the thresholds enforced on queries keyed by identity.
+	Identities map[string]Thresholds
+	// Default are the thresholds enforced on queries from identities that
+	// are not listed in Identities, including requests without an identity.
+	Default Thresholds
+}
+
+// Thresholds returns the thresholds that must be enforced on the queries of
+// the identity in the context.
+func (p *Policy) Thresholds(ctx context.Context) Thresholds {
+	if p == nil {
+		return Thresholds{}
+	}
+	if identity, ok := accesscontrol.IdentityFromContext(ctx); ok {
+		if thresholds, listed := p.Identities[identity]; listed {
+			return thresholds
+		}
+	}
+	return p.Default
+}
+
+// EstimatorOptions are the options for an estimator.
+type EstimatorOptions struct {
+	// Storage resolves the namespaces and series matched by selectors.
+	Storage storage.Storage
+	// TagOptions are the tag options used to convert selector matchers.
+	TagOptions models.TagOptions
+	// DefaultResolution is the interval assumed between datapoints of series
+	// in namespaces without a resolution, i.e. unaggregated namespaces.
+	DefaultResolution time.Duration
+	// BytesPerDatapoint is the number of compressed bytes assumed per
+	// datapoint.
+	BytesPerDatapoint float64
+}
+
+// Estimator estimates the cost of queries by resolving the series matched
+// by each of their selectors from the index only, without fetching any data.
+type Estimator struct {
+	opts EstimatorOptions
+}
+
+// NewEstimator returns a new estimator.
+func NewEstimator(opts EstimatorOptions) *Estimator {
+	if opts.DefaultResolution <= 0 {
+		opts.DefaultResolution = DefaultResolution
+	}
+	if opts.BytesPerDatapoint <= 0 {
+		opts.BytesPerDatapoint = DefaultBytesPerDatapoint
+	}
+	if opts.TagOptions == nil {
+		opts.TagOptions = models.NewTagOptions()
+	}
+	return &Estimator{opts: opts}
+}
+
+// Query is a query to estimate the cost of, instant queries have the
+// same start and end.
+type Query struct {
+	Query    string
+	Start    time.Time
+	End      time.Time
+	Lookback time.Duration
+}
+
+// Estimate estimates the cost of the query, the estimates of namespaces that
+// a selector fans out to are summed so the estimate is an upper bound when
+// results from several namespaces are stitched together.
+func (e *Estimator) Estimate(
+	ctx context.Context,
+	query Query,
+	fetchOpts *storage.FetchOptions,
+) (Estimate, error) {
+	expr, err := parser.ParseExpr(query.Query)
+	if err != nil {
+		return Estimate{}, xerrors.NewInvalidParamsError(err)
+	}
+
+	var (
+		fetches = selectorFetches(expr, query.Start, query.End, query.Lookback)
+		result  = Estimate{
+			Exhaustive: true,
+			Selectors:  make([]SelectorEstimate, 0, len(fetches)),
+		}
+	)
+	for _, fetch := range fetches {
+		matchers, err := promql.LabelMatchersToModelMatcher(fetch.matchers,
+			e.opts.TagOptions)
+		if err != nil {
+			return Estimate{}, xerrors.NewInvalidParamsError(err)
+		}
+
+		explanation, err := e.opts.Storage.ExplainQuery(ctx, &storage.FetchQuery{
+			Raw:         query.Query,
+			TagMatchers: matchers,
+			Start:       fetch.start,
+			End:         fetch.end,
+		}, fetchOpts)
+		if err != nil {
+			return Estimate{}, err
+		}
+
+		selector := SelectorEstimate{
+			Matchers:   matchers.String(),
+			Start:      fetch.start,
+			End:        fetch.end,
+			Exhaustive: true,
+		}
+		for _, ns := range explanation.Namespaces {
+			resolution := ns.Attributes.Resolution
+			if resolution <= 0 {
+				resolution = e.opts.DefaultResolution
+			}
+			var (
+				series     = int64(ns.EstimatedSeries)
+				perSeries  = int64(math.Ceil(float64(ns.End.Sub(ns.Start)) / float64(resolution)))
+				datapoints = series * perSeries
+			)
+			selector.Series += series
+			selector.Datapoints += datapoints
+			selector.Exhaustive = selector.Exhaustive && ns.Exhaustive
+		}
+		selector.Bytes = int64(math.Ceil(float64(selector.Datapoints) * e.opts.BytesPerDatapoint))
+
+		result.Series += selector.Series
+		result.Datapoints += selector.Datapoints
+		result.Bytes += selector.Bytes
+		result.Exhaustive = result.Exhaustive && selector.Exhaustive
+		result.Selectors = append(result.Selectors, selector)
+	}
+
+	return result, nil
+}
+
+// selectorFetch is a series selector with the time range it fetches.
+type selectorFetch struct {
+	matchers []*labels.Matcher
+	start    time.Time
+	end      time.Time
+}
+
+// selectorFetches returns the series selectors of an expression evaluated
+// over [start, end] along with the time range each one fetches, accounting
+// for ranges, subqueries, offsets and @ modifiers.
+func selectorFetches(
+	node parser.Node,
+	start, end time.Time,
+	lookback time.Duration,
+) []selectorFetch {
+	switch n := node.(type) {
+	case *parser.SubqueryExpr:
+		start, end = atModifier(n.Timestamp, n.StartOrEnd, start, end)
+		return selectorFetches(n.Expr,
+			start.Add(-n.Range-n.OriginalOffset),
+			end.Add(-n.OriginalOffset), lookback)
+	case *parser.MatrixSelector:
+		// nolint: forcetypeassert
+		vs := n.VectorSelector.(*parser.VectorSelector)
+		start, end = atModifier(vs.Timestamp, vs.StartOrEnd, start, end)
+		return []selectorFetch{{
+			matchers: vs.LabelMatchers,
+			start:    start.Add(-n.Range - vs.OriginalOffset),
+			end:      end.Add(-vs.OriginalOffset),
+		}}
+	case *parser.VectorSelector:
+		start, end = atModifier(n.Timestamp, n.StartOrEnd, start, end)
+		return []selectorFetch{{
+			matchers: n.LabelMatchers,
+			start:    start.Add(-lookback - n.OriginalOffset),
+			end:      end.Add(-n.OriginalOffset),
+		}}
+	}
+
+	var fetches []selectorFetch
+	for _, child := range parser.Children(node) {
+		fetches = append(fetches, selectorFetches(child, start, end, lookback)...)
+	}
+	return fetches
+}
+
+// atModifier returns the evaluation range of an expression with an @
+// modifier, which is evaluated at a single fixed time.
+func atModifier(
+	timestamp *int64,
+	startOrEnd parser.ItemType,
+	start, end time.Time,
+) (time.Time, time.Time) {
+	switch {
+	case timestamp != nil:
+		t := time.Unix(0, *timestamp*int64(time.Millisecond))
+		return t, t
+	case startOrEnd == parser.START:
+		return start, start
+	case startOrEnd == parser.END:
+		return end, end
+	}
+	return start, end
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package querycost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/accesscontrol"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	xtest "github.com/m3db/m3/src/x/test"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimate(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		end   = time.Unix(1600000000, 0)
+		start = end.Add(-time.Hour)
+		store = storage.NewMockStorage(ctrl)
+		seen  []*storage.FetchQuery
+	)
+	store.EXPECT().ExplainQuery(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			query *storage.FetchQuery,
+			_ *storage.FetchOptions,
+		) (storage.QueryExplanation, error) {
+			seen = append(seen, query)
+			return storage.QueryExplanation{
+				Namespaces: []storage.NamespaceExplanation{
+					{
+						Attributes: storagemetadata.Attributes{
+							MetricsType: storagemetadata.UnaggregatedMetricsType,
+						},
+						Start:           query.Start,
+						End:             query.End,
+						EstimatedSeries: 10,
+						Exhaustive:      true,
+					},
+					{
+						Attributes: storagemetadata.Attributes{
+							MetricsType: storagemetadata.AggregatedMetricsType,
+							Resolution:  time.Minute,
+						},
+						Start:           query.Start,
+						End:             query.End,
+						EstimatedSeries: 5,
+						Exhaustive:      true,
+					},
+				},
+			}, nil
+		}).Times(2)
+
+	estimator := NewEstimator(EstimatorOptions{Storage: store})
+	estimate, err := estimator.Estimate(context.Background(), Query{
+		Query:    `sum(rate(cpu{job="a"}[5m] offset 1m)) + up`,
+		Start:    start,
+		End:      end,
+		Lookback: 5 * time.Minute,
+	}, storage.NewFetchOptions())
+	require.NoError(t, err)
+
+	require.Len(t, seen, 2)
+	assert.Equal(t, start.Add(-6*time.Minute), seen[0].Start)
+	assert.Equal(t, end.Add(-time.Minute), seen[0].End)
+	assert.Equal(t, start.Add(-5*time.Minute), seen[1].Start)
+	assert.Equal(t, end, seen[1].End)
+
+	// Each selector fetches 65 minutes, 390 datapoints per unaggregated
+	// series at the default resolution and 65 per aggregated series.
+	require.Len(t, estimate.Selectors, 2)
+	assert.Equal(t, int64(15), estimate.Selectors[0].Series)
+	assert.Equal(t, int64(10*390+5*65), estimate.Selectors[0].Datapoints)
+	assert.Equal(t, int64(30), estimate.Series)
+	assert.Equal(t, int64(2*(10*390+5*65)), estimate.Datapoints)
+	assert.Equal(t, int64(4*(10*390+5*65)), estimate.Bytes)
+	assert.True(t, estimate.Exhaustive)
+}
+
+func TestEstimateAtModifierAndSubquery(t *testing.T) {
+	var (
+		end    = time.Unix(1600000000, 0)
+		start  = end.Add(-time.Hour)
+		at     = time.Unix(1500000000, 0)
+		expr   = `max_over_time(rate(a[1m])[10m:1m]) + b @ 1500000000`
+		parsed = mustParse(t, expr)
+	)
+	fetches := selectorFetches(parsed, start, end, 5*time.Minute)
+	require.Len(t, fetches, 2)
+	assert.Equal(t, start.Add(-11*time.Minute), fetches[0].start)
+	assert.Equal(t, end, fetches[0].end)
+	assert.Equal(t, at.Add(-5*time.Minute), fetches[1].start)
+	assert.Equal(t, at, fetches[1].end)
+}
+
+func TestEstimateInvalidQuery(t *testing.T) {
+	estimator := NewEstimator(EstimatorOptions{})
+	_, err := estimator.Estimate(context.Background(), Query{Query: "sum("},
+		storage.NewFetchOptions())
+	require.Error(t, err)
+	assert.True(t, xerrors.IsInvalidParams(err))
+}
+
+func TestThresholdsCheck(t *testing.T) {
+	thresholds := Thresholds{MaxSeries: 10, MaxBytes: 100}
+	require.NoError(t, thresholds.Check(Estimate{Series: 10, Datapoints: 1000, Bytes: 100}))
+
+	err := thresholds.Check(Estimate{Series: 11})
+	require.Error(t, err)
+	details, ok := xerrors.GetErrorDetails(err)
+	require.True(t, ok)
+	assert.Equal(t, xerrors.CodeResourceExhausted, details.Code)
+	assert.Equal(t, "estimated_series", details.Resource)
+	assert.Equal(t, int64(10), details.Limit)
+
+	err = thresholds.Check(Estimate{Bytes: 101})
+	require.Error(t, err)
+	details, ok = xerrors.GetErrorDetails(err)
+	require.True(t, ok)
+	assert.Equal(t, "estimated_bytes", details.Resource)
+
+	require.NoError(t, Thresholds{}.Check(Estimate{Series: 1 << 40}))
+}
+
+func TestPolicyThresholds(t *testing.T) {
+	policy := &Policy{
+		Identities: map[string]Thresholds{"dashboards": {MaxSeries: 100}},
+		Default:    Thresholds{MaxSeries: 10},
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, Thresholds{MaxSeries: 10}, policy.Thresholds(ctx))
+	assert.Equal(t, Thresholds{MaxSeries: 100},
+		policy.Thresholds(accesscontrol.NewContext(ctx, "dashboards")))
+	assert.Equal(t, Thresholds{MaxSeries: 10},
+		policy.Thresholds(accesscontrol.NewContext(ctx, "other")))
+
+	var nilPolicy *Policy
+	assert.Equal(t, Thresholds{}, nilPolicy.Thresholds(ctx))
+}
+
+func mustParse(t *testing.T, query string) parser.Expr {
+	expr, err := parser.ParseExpr(query)
+	require.NoError(t, err)
+	return expr
+}
@@ -62,6 +62,9 @@ var (
 	errQueryStorageMetadataAttributesNotImplemented = goerrors.New(
 		"remote storage does not implement QueryStorageMetadataAttributes",
 	)
+	errExplainQueryNotImplemented = goerrors.New(
+		"remote storage does not implement ExplainQuery",
+	)
 
 	// NB(r): These options tries to ensure we don't let connections go stale
 	// and cause failed RPCs as a result.
@@ -189,6 +192,14 @@ func (c *grpcClient) QueryStorageMetadataAttributes(
 	return nil, errQueryStorageMetadataAttributesNotImplemented
 }
 
+func (c *grpcClient) ExplainQuery(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (storage.QueryExplanation, error) {
+	return storage.QueryExplanation{}, errExplainQueryNotImplemented
+}
+
 func (c *grpcClient) healthCheckUntilClosed() {
 	ticker := time.NewTicker(healthCheckInterval)
 	defer ticker.Stop()
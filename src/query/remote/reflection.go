@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	gogoproto "github.com/gogo/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// registerReflection registers the gRPC server reflection service so that
+// tools such as grpcurl and evans can discover the services of the server.
+// The descriptors of the gogo generated files are registered with the global
+// registry used by the reflection service first, since gogo keeps its own
+// registry, otherwise only service names would be discoverable.
+func registerReflection(server *grpc.Server, fileDescriptors ...[]byte) error {
+	reflection.Register(server)
+	for _, gz := range fileDescriptors {
+		if err := registerGogoFileDescriptor("", gz); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func registerGogoFileDescriptor(name string, gz []byte) error {
+	fd, err := decodeFileDescriptor(gz)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		// NB: register the file under the path it is imported by since
+		// gogo registers its own files by their base name.
+		fd.Name = proto.String(name)
+	}
+
+	if _, err := protoregistry.GlobalFiles.FindFileByPath(fd.GetName()); err == nil {
+		// Already registered.
+		return nil
+	}
+
+	for _, dep := range fd.GetDependency() {
+		if _, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+			continue
+		}
+		depGz := gogoproto.FileDescriptor(dep)
+		if depGz == nil {
+			depGz = gogoproto.FileDescriptor(path.Base(dep))
+		}
+		if depGz == nil {
+			return fmt.Errorf("no file descriptor registered for %s, imported by %s",
+				dep, fd.GetName())
+		}
+		if err := registerGogoFileDescriptor(dep, depGz); err != nil {
+			return err
+		}
+	}
+
+	file, err := protodesc.NewFile(fd, protoregistry.GlobalFiles)
+	if err != nil {
+		return fmt.Errorf("invalid file descriptor for %s: %w", fd.GetName(), err)
+	}
+	return protoregistry.GlobalFiles.RegisterFile(file)
+}
+
+func decodeFileDescriptor(gz []byte) (*descriptorpb.FileDescriptorProto, error) {
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() // nolint:errcheck
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	fd := &descriptorpb.FileDescriptorProto{}
+	if err := proto.Unmarshal(b, fd); err != nil {
+		return nil, err
+	}
+	return fd, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"testing"
+
+	rpc "github.com/m3db/m3/src/query/generated/proto/rpcpb"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestRegisterReflection(t *testing.T) {
+	queryDescriptor, _ := (&rpc.HealthRequest{}).Descriptor()
+
+	server := grpc.NewServer()
+	require.NoError(t, registerReflection(server, queryDescriptor))
+	require.Contains(t, server.GetServiceInfo(), "grpc.reflection.v1alpha.ServerReflection")
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName("rpc.Query")
+	require.NoError(t, err)
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	require.True(t, ok)
+	require.NotNil(t, service.Methods().ByName("Fetch"))
+
+	// Registering the descriptors again is a no-op.
+	require.NoError(t, registerReflection(grpc.NewServer(), queryDescriptor))
+}
@@ -82,6 +82,14 @@ func NewGRPCServer(
 	}
 
 	rpc.RegisterQueryServer(server, grpcServer)
+
+	// NB: reflection is best effort, the query service is still served if the
+	// descriptors of its files cannot be registered.
+	queryDescriptor, _ := (&rpc.HealthRequest{}).Descriptor()
+	if err := registerReflection(server, queryDescriptor); err != nil {
+		instrumentOpts.Logger().Warn("could not register query service descriptors "+
+			"for grpc reflection", zap.Error(err))
+	}
 	return server
 }
 
@@ -0,0 +1,56 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/m3db/m3/src/query/storage"
+)
+
+// writeForwardingAppender is the storage the downsampler writes to. The
+// downsampler is created before the storages wrapping the backend storage
+// that need its cluster client, such as the result cache, so its writes are
+// forwarded to the wrapped storage once it is set.
+type writeForwardingAppender struct {
+	sync.RWMutex
+
+	appender storage.Appender
+}
+
+func newWriteForwardingAppender(appender storage.Appender) *writeForwardingAppender {
+	return &writeForwardingAppender{appender: appender}
+}
+
+// setAppender sets the storage writes are forwarded to.
+func (a *writeForwardingAppender) setAppender(appender storage.Appender) {
+	a.Lock()
+	a.appender = appender
+	a.Unlock()
+}
+
+func (a *writeForwardingAppender) Write(ctx context.Context, query *storage.WriteQuery) error {
+	a.RLock()
+	appender := a.appender
+	a.RUnlock()
+	return appender.Write(ctx, query)
+}
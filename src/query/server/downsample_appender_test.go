@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m3db/m3/src/query/storage"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingAppender struct {
+	writes int
+}
+
+func (a *countingAppender) Write(context.Context, *storage.WriteQuery) error {
+	a.writes++
+	return nil
+}
+
+func TestWriteForwardingAppender(t *testing.T) {
+	var (
+		backend = &countingAppender{}
+		wrapped = &countingAppender{}
+		a       = newWriteForwardingAppender(backend)
+	)
+	require.NoError(t, a.Write(context.Background(), nil))
+	require.Equal(t, 1, backend.writes)
+
+	a.setAppender(wrapped)
+	require.NoError(t, a.Write(context.Background(), nil))
+	require.Equal(t, 1, backend.writes)
+	require.Equal(t, 1, wrapped.writes)
+}
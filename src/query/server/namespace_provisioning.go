@@ -0,0 +1,85 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/placementhandler/handleroptions"
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/api/v1/handler/namespace"
+	"github.com/m3db/m3/src/query/api/v1/options"
+	"github.com/m3db/m3/src/query/generated/proto/admin"
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+// namespaceProvisioningAdmin lists and adds M3DB namespaces through the
+// namespace admin API on behalf of the namespace provisioner.
+type namespaceProvisioningAdmin struct {
+	client     clusterclient.Client
+	addHandler *namespace.AddHandler
+	svc        handleroptions.ServiceNameAndDefaults
+}
+
+var _ ingest.NamespaceAdmin = (*namespaceProvisioningAdmin)(nil)
+
+func newNamespaceProvisioningAdmin(
+	client clusterclient.Client,
+	validator options.NamespaceValidator,
+	defaults []handleroptions.ServiceOptionsDefault,
+	instrumentOpts instrument.Options,
+) *namespaceProvisioningAdmin {
+	return &namespaceProvisioningAdmin{
+		client:     client,
+		addHandler: namespace.NewAddHandler(client, instrumentOpts, validator),
+		svc: handleroptions.ServiceNameAndDefaults{
+			ServiceName: handleroptions.M3DBServiceName,
+			Defaults:    defaults,
+		},
+	}
+}
+
+func (a *namespaceProvisioningAdmin) serviceOptions() handleroptions.ServiceOptions {
+	return handleroptions.NewServiceOptions(a.svc, nil, nil)
+}
+
+func (a *namespaceProvisioningAdmin) Namespaces() ([]string, error) {
+	svcOpts := a.serviceOptions()
+	store, err := a.client.Store(svcOpts.KVOverrideOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	mds, _, err := namespace.Metadata(store)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(mds))
+	for _, md := range mds {
+		ids = append(ids, md.ID().String())
+	}
+	return ids, nil
+}
+
+func (a *namespaceProvisioningAdmin) Add(req *admin.NamespaceAddRequest) error {
+	_, err := a.addHandler.Add(req, a.serviceOptions())
+	return err
+}
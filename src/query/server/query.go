@@ -40,10 +40,12 @@ import (
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
 	ingestcarbon "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/carbon"
+	ingestkafka "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/kafka"
 	dbconfig "github.com/m3db/m3/src/cmd/services/m3dbnode/config"
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/query/accesscontrol"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/handleroptions"
 	"github.com/m3db/m3/src/query/api/v1/httpd"
 	"github.com/m3db/m3/src/query/api/v1/options"
@@ -336,6 +338,20 @@ func Run(runOpts RunOptions) RunResult {
 		logger.Fatal("could not parse query restrict tags config", zap.Error(err))
 	}
 
+	var accessControlPolicy *accesscontrol.Policy
+	if accessControlCfg := cfg.Query.AccessControl; accessControlCfg != nil {
+		accessControlPolicy, err = accessControlCfg.NewPolicy()
+		if err != nil {
+			logger.Fatal("could not parse query access control config", zap.Error(err))
+		}
+	}
+
+	namespaceAliases, err := storage.NewNamespaceAliases(cfg.NamespaceAliases,
+		instrumentOptions.MetricsScope())
+	if err != nil {
+		logger.Fatal("could not parse namespace aliases config", zap.Error(err))
+	}
+
 	timeout := cfg.Query.TimeoutOrDefault()
 	if runOpts.DBConfig != nil &&
 		runOpts.DBConfig.Client.FetchTimeout != nil &&
@@ -346,9 +362,11 @@ func Run(runOpts RunOptions) RunResult {
 	fetchOptsBuilderLimitsOpts := cfg.Limits.PerQuery.AsFetchOptionsBuilderLimitsOptions()
 	fetchOptsBuilder, err := handleroptions.NewFetchOptionsBuilder(
 		handleroptions.FetchOptionsBuilderOptions{
-			Limits:        fetchOptsBuilderLimitsOpts,
-			RestrictByTag: storageRestrictByTags,
-			Timeout:       timeout,
+			Limits:           fetchOptsBuilderLimitsOpts,
+			RestrictByTag:    storageRestrictByTags,
+			Timeout:          timeout,
+			AccessControl:    accessControlPolicy,
+			NamespaceAliases: namespaceAliases,
 		})
 	if err != nil {
 		logger.Fatal("could not set fetch options parser", zap.Error(err))
@@ -400,6 +418,10 @@ func Run(runOpts RunOptions) RunResult {
 		encodingOpts    = encoding.NewOptions()
 		m3dbClusters    m3.Clusters
 		m3dbPoolWrapper *pools.PoolWrapper
+		// downsampleStorage forwards the writes of the downsampler to the
+		// backend storage once it is wrapped, e.g. by the result cache which
+		// needs to invalidate cached results on late downsampled writes.
+		downsampleStorage *writeForwardingAppender
 	)
 
 	tsdbOpts := m3.NewOptions(encodingOpts).
@@ -409,7 +431,9 @@ func Run(runOpts RunOptions) RunResult {
 		SetReadWorkerPool(readWorkerPool).
 		SetWriteWorkerPool(writeWorkerPool).
 		SetSeriesConsolidationMatchOptions(matchOptions).
-		SetPromConvertOptions(promConvertOptions)
+		SetPromConvertOptions(promConvertOptions).
+		SetStitchingPolicy(cfg.Query.Stitching.Policy).
+		SetStitchingCutovers(cfg.Query.Stitching.StitchingCutovers())
 
 	if runOpts.ApplyCustomTSDBOptions != nil {
 		tsdbOpts, err = runOpts.ApplyCustomTSDBOptions(tsdbOpts, instrumentOptions)
@@ -500,7 +524,8 @@ func Run(runOpts RunOptions) RunResult {
 		logger.Info("configuring downsampler to use with aggregated cluster namespaces",
 			zap.Int("numAggregatedClusterNamespaces", len(m3dbClusters.ClusterNamespaces())))
 
-		downsampler, clusterClient, err = newDownsamplerAsync(cfg.Downsample, etcdConfig, backendStorage,
+		downsampleStorage = newWriteForwardingAppender(backendStorage)
+		downsampler, clusterClient, err = newDownsamplerAsync(cfg.Downsample, etcdConfig, downsampleStorage,
 			clusterNamespacesWatcher, tsdbOpts.TagOptions(), clockOpts, instrumentOptions, rwOpts, runOpts,
 			interruptOpts,
 		)
@@ -534,7 +559,8 @@ func Run(runOpts RunOptions) RunResult {
 			logger.Fatal("unable to update namespaces", zap.Error(err))
 		}
 
-		downsampler, clusterClient, err = newDownsamplerAsync(cfg.Downsample, cfg.ClusterManagement.Etcd, backendStorage,
+		downsampleStorage = newWriteForwardingAppender(backendStorage)
+		downsampler, clusterClient, err = newDownsamplerAsync(cfg.Downsample, cfg.ClusterManagement.Etcd, downsampleStorage,
 			clusterNamespacesWatcher, tsdbOpts.TagOptions(), clockOpts, instrumentOptions, rwOpts, runOpts,
 			interruptOpts,
 		)
@@ -545,6 +571,44 @@ func Run(runOpts RunOptions) RunResult {
 		logger.Fatal("unrecognized backend", zap.String("backend", string(cfg.Backend)))
 	}
 
+	if shadowCfg := cfg.ShadowRead; shadowCfg.Enabled {
+		var shadowStorage storage.Storage
+		if len(shadowCfg.RemoteAddresses) > 0 {
+			poolWrapper := pools.NewPoolsWrapper(
+				pools.BuildIteratorPools(encodingOpts, pools.BuildIteratorPoolsOptions{}))
+			shadowStorage, err = remoteZoneStorage(config.Remote{
+				Name:          "shadow",
+				Addresses:     shadowCfg.RemoteAddresses,
+				ErrorBehavior: storage.BehaviorFail,
+			}, poolWrapper, tsdbOpts, instrumentOptions)
+			if err != nil {
+				logger.Fatal("unable to setup shadow read remote storage", zap.Error(err))
+			}
+		}
+
+		backendStorage, err = shadowCfg.NewStorage(backendStorage, shadowStorage,
+			instrumentOptions)
+		if err != nil {
+			logger.Fatal("unable to setup shadow read storage", zap.Error(err))
+		}
+		logger.Info("shadow reads enabled",
+			zap.Float64("sampleRate", shadowCfg.SampleRate.Value()))
+	}
+
+	if cacheCfg := cfg.ResultCache; cacheCfg.Enabled {
+		var cleanup cleanupFn
+		backendStorage, cleanup, err = cacheCfg.NewStorage(backendStorage,
+			clusterClient, instrumentOptions)
+		if err != nil {
+			logger.Fatal("unable to setup result cache", zap.Error(err))
+		}
+
+		defer cleanup()
+
+		logger.Info("result cache enabled",
+			zap.String("backend", string(cacheCfg.Backend)))
+	}
+
 	if fn := runOpts.BackendStorageTransform; fn != nil {
 		backendStorage, err = fn(backendStorage, tsdbOpts, instrumentOptions)
 		if err != nil {
@@ -552,6 +616,10 @@ func Run(runOpts RunOptions) RunResult {
 		}
 	}
 
+	if downsampleStorage != nil {
+		downsampleStorage.setAppender(backendStorage)
+	}
+
 	engineOpts := executor.NewEngineOptions().
 		SetStore(backendStorage).
 		SetLookbackDuration(*cfg.LookbackDuration).
@@ -563,10 +631,16 @@ func Run(runOpts RunOptions) RunResult {
 	}
 
 	engine := executor.NewEngine(engineOpts)
+	writeSampler, err := cfg.WriteSampling.NewWriteSampler(instrumentOptions.MetricsScope())
+	if err != nil {
+		logger.Fatal("unable to create write sampler", zap.Error(err))
+	}
+
 	downsamplerAndWriter, err := newDownsamplerAndWriter(
 		backendStorage,
 		downsampler,
 		cfg.WriteWorkerPoolOrDefault(),
+		writeSampler,
 		instrumentOptions,
 	)
 	if err != nil {
@@ -627,9 +701,11 @@ func Run(runOpts RunOptions) RunResult {
 			fetchOptsBuilderLimitsOpts := limits.PerQuery.AsFetchOptionsBuilderLimitsOptions()
 			graphiteFindFetchOptsBuilder, err = handleroptions.NewFetchOptionsBuilder(
 				handleroptions.FetchOptionsBuilderOptions{
-					Limits:        fetchOptsBuilderLimitsOpts,
-					RestrictByTag: storageRestrictByTags,
-					Timeout:       timeout,
+					Limits:           fetchOptsBuilderLimitsOpts,
+					RestrictByTag:    storageRestrictByTags,
+					Timeout:          timeout,
+					AccessControl:    accessControlPolicy,
+					NamespaceAliases: namespaceAliases,
 				})
 			if err != nil {
 				logger.Fatal("could not set graphite find fetch options parser", zap.Error(err))
@@ -639,9 +715,11 @@ func Run(runOpts RunOptions) RunResult {
 			fetchOptsBuilderLimitsOpts := limits.PerQuery.AsFetchOptionsBuilderLimitsOptions()
 			graphiteRenderFetchOptsBuilder, err = handleroptions.NewFetchOptionsBuilder(
 				handleroptions.FetchOptionsBuilderOptions{
-					Limits:        fetchOptsBuilderLimitsOpts,
-					RestrictByTag: storageRestrictByTags,
-					Timeout:       timeout,
+					Limits:           fetchOptsBuilderLimitsOpts,
+					RestrictByTag:    storageRestrictByTags,
+					Timeout:          timeout,
+					AccessControl:    accessControlPolicy,
+					NamespaceAliases: namespaceAliases,
 				})
 			if err != nil {
 				logger.Fatal("could not set graphite find fetch options parser", zap.Error(err))
@@ -679,6 +757,54 @@ func Run(runOpts RunOptions) RunResult {
 	if err != nil {
 		logger.Fatal("unable to set up handler options", zap.Error(err))
 	}
+	handlerOptions = handlerOptions.SetNamespaceAliases(namespaceAliases)
+
+	if writeAuditCfg := cfg.WriteAudit; writeAuditCfg != nil {
+		writeAuditOpts := ingest.WriteAuditorOptions{
+			InstrumentOptions: instrumentOptions,
+		}
+		if clusterClient != nil {
+			writeAuditOpts.KVStore = clusterClient.KV
+		}
+		writeAuditor, err := writeAuditCfg.NewWriteAuditor(writeAuditOpts)
+		if err != nil {
+			logger.Fatal("unable to create write auditor", zap.Error(err))
+		}
+		defer writeAuditor.Close()
+		handlerOptions = handlerOptions.SetWriteAuditor(writeAuditor)
+	}
+
+	if queryAuditCfg := cfg.QueryAudit; queryAuditCfg.Enabled {
+		queryAuditor, err := queryAuditCfg.NewAuditor(clusterClient, instrumentOptions)
+		if err != nil {
+			logger.Fatal("unable to create query auditor", zap.Error(err))
+		}
+		defer queryAuditor.Close()
+		handlerOptions = handlerOptions.SetQueryAuditor(queryAuditor)
+	}
+
+	if provisioningCfg := cfg.NamespaceProvisioning; provisioningCfg.Enabled {
+		if clusterClient == nil {
+			logger.Fatal("namespace provisioning requires a cluster client")
+		}
+
+		provisioner, err := provisioningCfg.NewNamespaceProvisioner(ingest.NamespaceProvisionerOptions{
+			KVStore: clusterClient.KV,
+			Admin: newNamespaceProvisioningAdmin(clusterClient,
+				handlerOptions.NamespaceValidator(), serviceOptionDefaults, instrumentOptions),
+			InstrumentOptions: instrumentOptions,
+		})
+		if err != nil {
+			logger.Fatal("unable to create namespace provisioner", zap.Error(err))
+		}
+		defer provisioner.Close()
+		handlerOptions = handlerOptions.SetNamespaceProvisioner(provisioner)
+
+		logger.Info("namespace provisioning enabled",
+			zap.String("tenantTag", provisioningCfg.TenantTag),
+			zap.Int("maxNamespaces", provisioningCfg.MaxNamespaces),
+			zap.Bool("requireApproval", provisioningCfg.RequireApproval))
+	}
 
 	var customHandlerOpts options.CustomHandlerOptions
 	if runOpts.CustomHandlerOptions != nil {
@@ -771,6 +897,12 @@ func Run(runOpts RunOptions) RunResult {
 		defer server.Close()
 	}
 
+	if cfg.KafkaIngester != nil {
+		ingester := startKafkaIngestion(*cfg.KafkaIngester, instrumentOptions,
+			logger, tagOptions, downsamplerAndWriter)
+		defer ingester.Close()
+	}
+
 	// Stop our async watch and now block waiting for the interrupt.
 	intWatchCancel()
 	select {
@@ -1344,10 +1476,70 @@ func startCarbonIngestion(
 	return carbonServer
 }
 
+func startKafkaIngestion(
+	ingesterCfg config.KafkaIngesterConfiguration,
+	iOpts instrument.Options,
+	logger *zap.Logger,
+	tagOptions models.TagOptions,
+	downsamplerAndWriter ingest.DownsamplerAndWriter,
+) *ingestkafka.Ingester {
+	logger.Info("kafka ingestion enabled, configuring ingester")
+
+	kafkaIOpts := iOpts.SetMetricsScope(
+		iOpts.MetricsScope().SubScope("ingest-kafka"))
+
+	client, err := ingestkafka.NewClient(ingestkafka.ClientOptions{
+		Brokers:          ingesterCfg.Brokers,
+		ClientID:         ingesterCfg.ClientID,
+		Group:            ingesterCfg.Group,
+		DialTimeout:      ingesterCfg.DialTimeout,
+		RequestTimeout:   ingesterCfg.RequestTimeout,
+		FetchMaxWait:     ingesterCfg.FetchMaxWait,
+		FetchMaxBytes:    ingesterCfg.FetchMaxBytes,
+		SessionTimeout:   ingesterCfg.SessionTimeout,
+		RebalanceTimeout: ingesterCfg.RebalanceTimeout,
+	})
+	if err != nil {
+		logger.Fatal("unable to create kafka client", zap.Error(err))
+	}
+
+	topics := make([]ingestkafka.TopicOptions, 0, len(ingesterCfg.Topics))
+	for _, topic := range ingesterCfg.Topics {
+		topics = append(topics, ingestkafka.TopicOptions{
+			Name:   topic.Name,
+			Format: ingestkafka.Format(topic.Format),
+		})
+	}
+
+	ingester, err := ingestkafka.NewIngester(ingestkafka.Options{
+		Client:               client,
+		DownsamplerAndWriter: downsamplerAndWriter,
+		Topics:               topics,
+		InitialOffset:        ingestkafka.InitialOffset(ingesterCfg.InitialOffset),
+		CommitInterval:       ingesterCfg.CommitInterval,
+		HeartbeatInterval:    ingesterCfg.HeartbeatInterval,
+		RetryOptions:         ingesterCfg.Retry.NewOptions(kafkaIOpts.MetricsScope()),
+		TagOptions:           tagOptions,
+		InstrumentOptions:    kafkaIOpts,
+	})
+	if err != nil {
+		logger.Fatal("unable to create kafka ingester", zap.Error(err))
+	}
+
+	if err := ingester.Start(); err != nil {
+		logger.Fatal("unable to start kafka ingester", zap.Error(err))
+	}
+
+	logger.Info("started kafka ingestion", zap.Strings("brokers", ingesterCfg.Brokers))
+
+	return ingester
+}
+
 func newDownsamplerAndWriter(
 	storage storage.Storage,
 	downsampler downsample.Downsampler,
 	workerPoolPolicy xconfig.WorkerPoolPolicy,
+	writeSampler ingest.WriteSampler,
 	iOpts instrument.Options,
 ) (ingest.DownsamplerAndWriter, error) {
 	// Make sure the downsampler and writer gets its own PooledWorkerPool and that its not shared with any other
@@ -1361,7 +1553,7 @@ func newDownsamplerAndWriter(
 	}
 	downAndWriteWorkerPool.Init()
 
-	return ingest.NewDownsamplerAndWriter(storage, downsampler, downAndWriteWorkerPool, iOpts), nil
+	return ingest.NewDownsamplerAndWriter(storage, downsampler, downAndWriteWorkerPool, writeSampler, iOpts), nil
 }
 
 func newPromQLEngine(
@@ -107,6 +107,34 @@ func (s *fanoutStorage) QueryStorageMetadataAttributes(
 	return attrs, nil
 }
 
+func (s *fanoutStorage) ExplainQuery(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (storage.QueryExplanation, error) {
+	stores := filterStores(s.stores, s.fetchFilter, query)
+	// Optimization for the single store case
+	if len(stores) == 1 {
+		return stores[0].ExplainQuery(ctx, query, options)
+	}
+
+	var result storage.QueryExplanation
+	for _, store := range stores {
+		explanation, err := store.ExplainQuery(ctx, query, options)
+		if err != nil {
+			if store.ErrorBehavior() == storage.BehaviorWarn {
+				s.instrumentOpts.Logger().Warn("could not explain query for store",
+					zap.String("store", store.Name()), zap.Error(err))
+				continue
+			}
+			return storage.QueryExplanation{}, err
+		}
+		result.Namespaces = append(result.Namespaces, explanation.Namespaces...)
+	}
+
+	return result, nil
+}
+
 func (s *fanoutStorage) FetchProm(
 	ctx context.Context,
 	query *storage.FetchQuery,
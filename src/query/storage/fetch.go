@@ -21,6 +21,7 @@
 package storage
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/m3db/m3/src/query/models"
@@ -31,6 +32,10 @@ import (
 
 const (
 	defaultMaxMetricMetadataStats = 4
+
+	// sampleBuckets is the number of buckets series hashes are mod-ed into
+	// when selecting a sample of series.
+	sampleBuckets = 10000
 )
 
 // NewFetchOptions creates a new fetch options.
@@ -75,6 +80,7 @@ func (o *FetchOptions) QueryFetchOptions(
 
 	// Use inbuilt options for type restriction if none found.
 	if r.RestrictQueryOptions.GetRestrictByType() == nil &&
+		r.RestrictQueryOptions.GetRestrictByNamespace() == nil &&
 		queryCtx.Options.RestrictFetchType != nil {
 		v := queryCtx.Options.RestrictFetchType
 		restrict := &RestrictByType{
@@ -96,6 +102,25 @@ func (o *FetchOptions) QueryFetchOptions(
 	return r, nil
 }
 
+// Sampled returns whether the fetch is restricted to a sample of series.
+func (o *FetchOptions) Sampled() bool {
+	return o != nil && o.SampleRate > 0 && o.SampleRate < 1
+}
+
+// ValidateSampleRate validates a sample rate.
+func ValidateSampleRate(rate float64) error {
+	if !(rate > 0 && rate <= 1) {
+		return fmt.Errorf("sample rate must be in (0, 1]: %v", rate)
+	}
+	return nil
+}
+
+// SampleSeries returns whether a series with the given ID hash belongs to the
+// deterministic sample of series selected by the sample rate.
+func SampleSeries(hash uint64, rate float64) bool {
+	return hash%sampleBuckets < uint64(rate*sampleBuckets)
+}
+
 // Clone will clone and return the fetch options.
 func (o *FetchOptions) Clone() *FetchOptions {
 	result := *o
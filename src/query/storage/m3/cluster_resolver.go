@@ -116,13 +116,16 @@ func resolveClusterNamespacesForQuery(
 	// Now de-duplicate any namespaces that might be fetched twice due to
 	// the fact some of the same namespaces are reused once for unaggregated
 	// and another for aggregated rollups (which don't collide with timeseries).
+	// Namespaces are only duplicates if they share a session, since distinct
+	// clusters may each define a namespace with the same name.
 	filtered := namespaces[:0]
 	for _, ns := range namespaces {
 		keep := true
 		// Small enough that we can do n^2 here instead of creating a map,
 		// usually less than 4 namespaces resolved.
 		for _, existing := range filtered {
-			if ns.NamespaceID().Equal(existing.NamespaceID()) {
+			if ns.NamespaceID().Equal(existing.NamespaceID()) &&
+				ns.Session() == existing.Session() {
 				keep = false
 				break
 			}
@@ -145,6 +148,12 @@ func resolveClusterNamespacesForQueryLogicalPlan(
 	opts *storage.FanoutOptions,
 	restrict *storage.RestrictQueryOptions,
 ) (consolidators.QueryFanoutType, resolvedNamespaces, error) {
+	if namespaceRestrict := restrict.GetRestrictByNamespace(); namespaceRestrict != nil {
+		// If a specific namespace is set, then attempt to satisfy.
+		return resolveClusterNamespacesForQueryWithNamespaceRestrictQueryOptions(now,
+			start, clusters, *namespaceRestrict)
+	}
+
 	if typeRestrict := restrict.GetRestrictByType(); typeRestrict != nil {
 		// If a specific restriction is set, then attempt to satisfy.
 		return resolveClusterNamespacesForQueryWithTypeRestrictQueryOptions(now,
@@ -453,6 +462,37 @@ func resolveClusterNamespacesForQueryWithTypeRestrictQueryOptions(
 	}
 }
 
+// resolveClusterNamespacesForQueryWithNamespaceRestrictQueryOptions returns the
+// cluster namespace referred to by name in the restrict fetch options or an
+// error if it cannot be found.
+func resolveClusterNamespacesForQueryWithNamespaceRestrictQueryOptions(
+	now, start xtime.UnixNano,
+	clusters Clusters,
+	restrict storage.RestrictByNamespace,
+) (consolidators.QueryFanoutType, resolvedNamespaces, error) {
+	for _, ns := range clusters.ClusterNamespaces() {
+		if ns.NamespaceID().String() != restrict.Namespace {
+			continue
+		}
+
+		coversRangeFilter := newCoversRangeFilter(coversRangeFilterOptions{
+			now:        now,
+			queryStart: start,
+		})
+		if coversRangeFilter(ns) {
+			return consolidators.NamespaceCoversAllQueryRange,
+				resolvedNamespaces{resolved(ns)}, nil
+		}
+
+		return consolidators.NamespaceCoversPartialQueryRange,
+			resolvedNamespaces{resolved(ns)}, nil
+	}
+
+	err := xerrors.NewInvalidParamsError(
+		fmt.Errorf("could not find namespace: %s", restrict.Namespace))
+	return consolidators.NamespaceInvalid, nil, err
+}
+
 // resolveClusterNamespacesForQueryWithTypesRestrictQueryOptions returns the cluster
 // namespace referred to by the array of restrict fetch options or an error if it
 // cannot be found.
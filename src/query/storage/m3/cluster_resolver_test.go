@@ -571,6 +571,57 @@ func TestLongUnaggregatedRetention(t *testing.T) {
 	assert.Equal(t, consolidators.NamespaceCoversPartialQueryRange, fanoutType)
 }
 
+func TestResolveClusterNamespacesWithNamespaceRestrict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockSession(ctrl)
+	clusters, err := NewClusters(
+		UnaggregatedClusterNamespaceDefinition{
+			NamespaceID: ident.StringID("metrics_10s_24h"),
+			Retention:   24 * time.Hour,
+			Session:     session,
+		}, AggregatedClusterNamespaceDefinition{
+			NamespaceID: ident.StringID("metrics_180s_360h"),
+			Retention:   360 * time.Hour,
+			Resolution:  180 * time.Second,
+			Downsample:  &ClusterNamespaceDownsampleOptions{All: false},
+			Session:     session,
+		},
+	)
+	require.NoError(t, err)
+
+	now := xtime.Now()
+	end := now
+	restrict := func(namespace string) *storage.RestrictQueryOptions {
+		return &storage.RestrictQueryOptions{
+			RestrictByNamespace: &storage.RestrictByNamespace{Namespace: namespace},
+		}
+	}
+
+	fanoutType, ns, err := resolveClusterNamespacesForQuery(now,
+		now.Add(-time.Hour), end, clusters, &storage.FanoutOptions{},
+		restrict("metrics_180s_360h"), nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ns))
+	assert.Equal(t, "metrics_180s_360h", ns[0].NamespaceID().String())
+	assert.Equal(t, consolidators.NamespaceCoversAllQueryRange, fanoutType)
+
+	fanoutType, ns, err = resolveClusterNamespacesForQuery(now,
+		now.Add(-48*time.Hour), end, clusters, &storage.FanoutOptions{},
+		restrict("metrics_10s_24h"), nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ns))
+	assert.Equal(t, "metrics_10s_24h", ns[0].NamespaceID().String())
+	assert.Equal(t, consolidators.NamespaceCoversPartialQueryRange, fanoutType)
+
+	_, _, err = resolveClusterNamespacesForQuery(now,
+		now.Add(-time.Hour), end, clusters, &storage.FanoutOptions{},
+		restrict("unknown"), nil)
+	require.Error(t, err)
+	assert.True(t, xerrors.IsInvalidParams(err))
+}
+
 func TestExampleCase(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -664,6 +715,42 @@ func TestDeduplicatePartialAggregateNamespaces(t *testing.T) {
 	assert.Equal(t, consolidators.NamespaceCoversAllQueryRange, fanoutType)
 }
 
+func TestResolveSameNamespaceAcrossClusters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		shortTermSession = client.NewMockSession(ctrl)
+		longTermSession  = client.NewMockSession(ctrl)
+	)
+	ns, err := NewClusters(UnaggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("default"),
+		Retention:   24 * time.Hour,
+		Session:     shortTermSession,
+	}, AggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("default"),
+		Retention:   8760 * time.Hour,
+		Resolution:  time.Hour,
+		Downsample:  &ClusterNamespaceDownsampleOptions{All: false},
+		Session:     longTermSession,
+	})
+	require.NoError(t, err)
+
+	now := xtime.Now()
+	start := now.Add(-48 * time.Hour)
+	fanoutType, clusters, err := resolveClusterNamespacesForQuery(now, start, now, ns,
+		&storage.FanoutOptions{}, nil, nil)
+	require.NoError(t, err)
+
+	// Both clusters must be queried even though the namespace names collide.
+	require.Equal(t, 2, len(clusters))
+	for _, c := range clusters {
+		assert.Equal(t, "default", c.NamespaceID().String())
+	}
+	assert.True(t, clusters[0].Session() != clusters[1].Session())
+	assert.Equal(t, consolidators.NamespaceCoversPartialQueryRange, fanoutType)
+}
+
 func TestResolveNamespaceWithDataLatency(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
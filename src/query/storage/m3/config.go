@@ -51,13 +51,25 @@ type NewClientFromConfig func(
 	custom ...client.CustomAdminOption,
 ) (client.Client, error)
 
-// ClusterStaticConfiguration is a static cluster configuration.
+// ClusterStaticConfiguration is a static cluster configuration. Multiple
+// distinct M3DB clusters may be specified, for instance a short retention
+// unaggregated cluster alongside a long retention aggregated cluster, and
+// queries are fanned out across them by retention and resolution.
 type ClusterStaticConfiguration struct {
+	// Name is an optional name for the cluster used when reporting errors.
+	Name                string                                `yaml:"name"`
 	NewClientFromConfig NewClientFromConfig                   `yaml:"-"`
 	Namespaces          []ClusterStaticNamespaceConfiguration `yaml:"namespaces"`
 	Client              client.Configuration                  `yaml:"client"`
 }
 
+func (c ClusterStaticConfiguration) name(idx int) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("#%d", idx)
+}
+
 func (c ClusterStaticConfiguration) newClient(
 	params client.ConfigurationParameters,
 	custom ...client.CustomAdminOption,
@@ -136,12 +148,14 @@ func (c DownsampleClusterStaticNamespaceConfiguration) downsampleOptions() Clust
 }
 
 type unaggregatedClusterNamespaceConfiguration struct {
+	name      string
 	client    client.Client
 	namespace ClusterStaticNamespaceConfiguration
 	result    clusterConnectResult
 }
 
 type aggregatedClusterNamespacesConfiguration struct {
+	name       string
 	client     client.Client
 	namespaces []ClusterStaticNamespaceConfiguration
 	result     clusterConnectResult
@@ -176,7 +190,7 @@ func (c ClustersStaticConfiguration) NewStaticClusters(
 		unaggregatedClusterNamespace     UnaggregatedClusterNamespaceDefinition
 		aggregatedClusterNamespaces      []AggregatedClusterNamespaceDefinition
 	)
-	for _, clusterCfg := range c {
+	for i, clusterCfg := range c {
 		var (
 			result client.Client
 			err    error
@@ -194,6 +208,7 @@ func (c ClustersStaticConfiguration) NewStaticClusters(
 		}
 
 		aggregatedClusterNamespacesCfg := &aggregatedClusterNamespacesConfiguration{
+			name:   clusterCfg.name(i),
 			client: result,
 		}
 
@@ -211,6 +226,7 @@ func (c ClustersStaticConfiguration) NewStaticClusters(
 						"can be specified: specified %d", numUnaggregatedClusterNamespaces)
 				}
 
+				unaggregatedClusterNamespaceCfg.name = clusterCfg.name(i)
 				unaggregatedClusterNamespaceCfg.client = result
 				unaggregatedClusterNamespaceCfg.namespace = n
 
@@ -273,8 +289,8 @@ func (c ClustersStaticConfiguration) NewStaticClusters(
 	wg.Wait()
 
 	if unaggregatedClusterNamespaceCfg.result.err != nil {
-		return nil, fmt.Errorf("could not connect to unaggregated cluster: %v",
-			unaggregatedClusterNamespaceCfg.result.err)
+		return nil, fmt.Errorf("could not connect to unaggregated cluster %s: %v",
+			unaggregatedClusterNamespaceCfg.name, unaggregatedClusterNamespaceCfg.result.err)
 	}
 
 	unaggregatedClusterNamespace = UnaggregatedClusterNamespaceDefinition{
@@ -283,17 +299,17 @@ func (c ClustersStaticConfiguration) NewStaticClusters(
 		Retention:   unaggregatedClusterNamespaceCfg.namespace.Retention,
 	}
 
-	for i, cfg := range aggregatedClusterNamespacesCfgs {
+	for _, cfg := range aggregatedClusterNamespacesCfgs {
 		if cfg.result.err != nil {
-			return nil, fmt.Errorf("could not connect to aggregated cluster #%d: %v",
-				i, cfg.result.err)
+			return nil, fmt.Errorf("could not connect to aggregated cluster %s: %v",
+				cfg.name, cfg.result.err)
 		}
 
 		for _, n := range cfg.namespaces {
 			downsampleOpts, err := n.downsampleOptions()
 			if err != nil {
-				return nil, fmt.Errorf("error parse downsample options for cluster #%d namespace %s: %v",
-					i, n.Namespace, err)
+				return nil, fmt.Errorf("error parse downsample options for cluster %s namespace %s: %v",
+					cfg.name, n.Namespace, err)
 			}
 
 			def := AggregatedClusterNamespaceDefinition{
@@ -0,0 +1,105 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/storage/m3/consolidators"
+)
+
+// encodedConsolidatedSeriesIter consolidates the series of an encoded block
+// one at a time with the same lookback consolidation as the step iterator,
+// so only the values of the current series are held in memory.
+type encodedConsolidatedSeriesIter struct {
+	idx        int
+	err        error
+	block      *encodedBlock
+	seriesMeta []block.SeriesMeta
+	iters      []encoding.SeriesIterator
+	values     []float64
+}
+
+func (b *encodedBlock) ConsolidatedSeriesIter() (block.ConsolidatedSeriesIter, error) {
+	return &encodedConsolidatedSeriesIter{
+		idx:        -1,
+		block:      b,
+		seriesMeta: b.seriesMetas,
+		iters:      b.seriesBlockIterators,
+		values:     make([]float64, 0, b.meta.Bounds.Steps()),
+	}, nil
+}
+
+func (it *encodedConsolidatedSeriesIter) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.idx++
+	if it.idx >= len(it.iters) {
+		return false
+	}
+
+	var (
+		cs        = it.block.consolidation
+		bounds    = it.block.meta.Bounds
+		iter      = it.iters[it.idx]
+		peek      peekValue
+		collector = consolidators.NewStepLookbackConsolidator(
+			it.block.options.LookbackDuration(),
+			cs.bounds.StepSize,
+			cs.currentTime,
+			cs.consolidationFn,
+		)
+	)
+
+	it.values = it.values[:0]
+	for i := 0; i < bounds.Steps(); i++ {
+		stepTime := cs.currentTime.Add(time.Duration(i) * bounds.StepSize)
+		peek, _, it.err = nextForStep(peek, iter, collector, stepTime)
+		if it.err != nil {
+			return false
+		}
+
+		collector.BufferStep()
+		it.values = append(it.values, collector.ConsolidateAndMoveToNext())
+	}
+
+	return true
+}
+
+func (it *encodedConsolidatedSeriesIter) SeriesMeta() []block.SeriesMeta {
+	return it.seriesMeta
+}
+
+func (it *encodedConsolidatedSeriesIter) Current() []float64 {
+	return it.values
+}
+
+func (it *encodedConsolidatedSeriesIter) Err() error {
+	return it.err
+}
+
+func (it *encodedConsolidatedSeriesIter) Close() {
+	// noop, as the series iterators are closed by the encodedBlock.
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/test/compare"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsolidatedSeriesIteratorMatchesStepIterator(t *testing.T) {
+	for _, tt := range consolidatedStepIteratorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := newTestOpts().
+				SetLookbackDuration(1 * time.Minute).
+				SetSplitSeriesByBlock(false)
+			require.NoError(t, opts.Validate())
+
+			blocks, _ := generateBlocks(t, tt.stepSize, opts)
+			require.Len(t, blocks, 1)
+
+			bl, ok := blocks[0].(block.ConsolidatedSeriesBlock)
+			require.True(t, ok)
+
+			iter, err := bl.ConsolidatedSeriesIter()
+			require.NoError(t, err)
+			require.Len(t, iter.SeriesMeta(), len(tt.expected[0]))
+
+			idx := 0
+			for iter.Next() {
+				expected := make([]float64, 0, len(tt.expected))
+				for _, step := range tt.expected {
+					expected = append(expected, step[idx])
+				}
+				compare.EqualsWithNans(t, expected, iter.Current())
+				idx++
+			}
+
+			require.NoError(t, iter.Err())
+			require.Equal(t, len(tt.expected[0]), idx)
+		})
+	}
+}
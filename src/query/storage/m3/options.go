@@ -144,6 +144,8 @@ type encodedBlockOptions struct {
 	blockSeriesProcessor          BlockSeriesProcessor
 	adminOptions                  []client.CustomAdminOption
 	promConvertOptions            storage.PromConvertOptions
+	stitchingPolicy               storage.StitchingPolicy
+	stitchingCutovers             []StitchingCutover
 	instrumented                  bool
 }
 
@@ -341,6 +343,26 @@ func (o *encodedBlockOptions) PromConvertOptions() storage.PromConvertOptions {
 	return o.promConvertOptions
 }
 
+func (o *encodedBlockOptions) SetStitchingPolicy(value storage.StitchingPolicy) Options {
+	opts := *o
+	opts.stitchingPolicy = value
+	return &opts
+}
+
+func (o *encodedBlockOptions) StitchingPolicy() storage.StitchingPolicy {
+	return o.stitchingPolicy
+}
+
+func (o *encodedBlockOptions) SetStitchingCutovers(value []StitchingCutover) Options {
+	opts := *o
+	opts.stitchingCutovers = value
+	return &opts
+}
+
+func (o *encodedBlockOptions) StitchingCutovers() []StitchingCutover {
+	return o.stitchingCutovers
+}
+
 func (o *encodedBlockOptions) Validate() error {
 	if o.lookbackDuration < 0 {
 		return errors.New("unable to validate block options; negative lookback")
@@ -0,0 +1,167 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+// StitchingCutover is the cut-over timestamp between the unaggregated
+// namespace and an aggregated namespace used by the cutover stitching policy,
+// data before the cut-over is read from the aggregated namespace and data
+// from the cut-over onwards from the unaggregated namespace.
+type StitchingCutover struct {
+	// UnaggregatedNamespace is the ID of the unaggregated namespace.
+	UnaggregatedNamespace string
+	// AggregatedNamespace is the ID of the aggregated namespace.
+	AggregatedNamespace string
+	// Cutover is the cut-over timestamp.
+	Cutover xtime.UnixNano
+}
+
+func (s *m3storage) stitchingOptions(
+	opts *storage.FetchOptions,
+) storage.StitchingOptions {
+	if opts.StitchingOptions != nil {
+		return *opts.StitchingOptions
+	}
+	return storage.StitchingOptions{Policy: s.opts.StitchingPolicy()}
+}
+
+// applyStitchingPolicy narrows the resolved namespaces so that the results of
+// the unaggregated namespace and of the aggregated namespaces meet at a single
+// boundary rather than overlap, the boundary is chosen by the stitching
+// policy. Returns false if the policy did not change the namespaces, either
+// since the default policy is used or the query does not span both the
+// unaggregated namespace and aggregated namespaces.
+func applyStitchingPolicy(
+	now, start, end xtime.UnixNano,
+	namespaces resolvedNamespaces,
+	stitching storage.StitchingOptions,
+	cutovers []StitchingCutover,
+) (resolvedNamespaces, bool) {
+	if stitching.Policy == storage.StitchingDefault {
+		return namespaces, false
+	}
+
+	var (
+		unaggregated      resolvedNamespace
+		foundUnaggregated bool
+		foundAggregated   bool
+	)
+	for _, ns := range namespaces {
+		switch ns.Options().Attributes().MetricsType {
+		case storagemetadata.UnaggregatedMetricsType:
+			unaggregated = ns
+			foundUnaggregated = true
+		case storagemetadata.AggregatedMetricsType:
+			foundAggregated = true
+		}
+	}
+	if !foundUnaggregated || !foundAggregated {
+		return namespaces, false
+	}
+
+	var (
+		result            = make(resolvedNamespaces, 0, len(namespaces))
+		unaggregatedStart = end
+		applied           = false
+	)
+	for _, ns := range namespaces {
+		if ns.Options().Attributes().MetricsType == storagemetadata.UnaggregatedMetricsType {
+			continue
+		}
+
+		boundary, ok := stitchingBoundary(now, end, unaggregated, ns,
+			stitching, cutovers)
+		if !ok {
+			result = append(result, ns)
+			continue
+		}
+
+		applied = true
+		// The unaggregated namespace must cover any recent data not yet
+		// available in the aggregated namespace.
+		if !ns.narrowing.end.IsZero() && ns.narrowing.end.Before(boundary) {
+			boundary = ns.narrowing.end
+		}
+		if boundary.Before(unaggregatedStart) {
+			unaggregatedStart = boundary
+		}
+		if !boundary.After(start) {
+			// Entirely read from the unaggregated namespace.
+			continue
+		}
+		if boundary.Before(end) {
+			ns.narrowing.end = boundary
+		}
+		result = append(result, ns)
+	}
+
+	if !applied {
+		return namespaces, false
+	}
+
+	if unaggregatedStart.Before(end) {
+		unaggregated.narrowing.start = 0
+		if unaggregatedStart.After(start) {
+			unaggregated.narrowing.start = unaggregatedStart
+		}
+		result = append(result, unaggregated)
+	}
+
+	return result, true
+}
+
+// stitchingBoundary returns the timestamp before which the aggregated
+// namespace is read and from which the unaggregated namespace is read.
+func stitchingBoundary(
+	now, end xtime.UnixNano,
+	unaggregated resolvedNamespace,
+	aggregated resolvedNamespace,
+	stitching storage.StitchingOptions,
+	cutovers []StitchingCutover,
+) (xtime.UnixNano, bool) {
+	switch stitching.Policy {
+	case storage.StitchingPreferUnaggregated:
+		retention := unaggregated.Options().Attributes().Retention
+		return now.Add(-1 * retention), true
+	case storage.StitchingPreferAggregated:
+		return end, true
+	case storage.StitchingCutover:
+		if !stitching.Cutover.IsZero() {
+			return stitching.Cutover, true
+		}
+		var (
+			unaggregatedID = unaggregated.NamespaceID().String()
+			aggregatedID   = aggregated.NamespaceID().String()
+		)
+		for _, cutover := range cutovers {
+			if cutover.UnaggregatedNamespace == unaggregatedID &&
+				cutover.AggregatedNamespace == aggregatedID {
+				return cutover.Cutover, true
+			}
+		}
+	}
+	return 0, false
+}
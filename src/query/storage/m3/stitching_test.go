@@ -0,0 +1,170 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/x/ident"
+	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyStitchingPolicy(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockSession(ctrl)
+	clusters, err := NewClusters(UnaggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("raw"),
+		Retention:   48 * time.Hour,
+		Session:     session,
+	}, AggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("agg_1m"),
+		Retention:   30 * 24 * time.Hour,
+		Resolution:  time.Minute,
+		Session:     session,
+	})
+	require.NoError(t, err)
+
+	unaggregated, ok := clusters.UnaggregatedClusterNamespace()
+	require.True(t, ok)
+	aggregated, ok := clusters.AggregatedClusterNamespace(RetentionResolution{
+		Retention:  30 * 24 * time.Hour,
+		Resolution: time.Minute,
+	})
+	require.True(t, ok)
+
+	var (
+		now        = xtime.Now().Truncate(time.Hour)
+		start      = now.Add(-7 * 24 * time.Hour)
+		end        = now
+		cutover    = now.Add(-24 * time.Hour)
+		namespaces = resolvedNamespaces{resolved(aggregated), resolved(unaggregated)}
+		cutovers   = []StitchingCutover{
+			{
+				UnaggregatedNamespace: "raw",
+				AggregatedNamespace:   "agg_1m",
+				Cutover:               cutover,
+			},
+		}
+	)
+
+	narrowed := func(ns ClusterNamespace, start, end xtime.UnixNano) resolvedNamespace {
+		r := resolved(ns)
+		r.narrowing = narrowing{start: start, end: end}
+		return r
+	}
+
+	tests := []struct {
+		name       string
+		namespaces resolvedNamespaces
+		stitching  storage.StitchingOptions
+		cutovers   []StitchingCutover
+		expected   resolvedNamespaces
+		applied    bool
+	}{
+		{
+			name:       "default",
+			namespaces: namespaces,
+			expected:   namespaces,
+		},
+		{
+			name:       "prefer unaggregated",
+			namespaces: namespaces,
+			stitching:  storage.StitchingOptions{Policy: storage.StitchingPreferUnaggregated},
+			expected: resolvedNamespaces{
+				narrowed(aggregated, 0, now.Add(-48*time.Hour)),
+				narrowed(unaggregated, now.Add(-48*time.Hour), 0),
+			},
+			applied: true,
+		},
+		{
+			name:       "prefer aggregated",
+			namespaces: namespaces,
+			stitching:  storage.StitchingOptions{Policy: storage.StitchingPreferAggregated},
+			expected:   resolvedNamespaces{resolved(aggregated)},
+			applied:    true,
+		},
+		{
+			name: "prefer aggregated with data latency",
+			namespaces: resolvedNamespaces{
+				narrowed(aggregated, 0, now.Add(-time.Hour)),
+				narrowed(unaggregated, now.Add(-time.Hour), 0),
+			},
+			stitching: storage.StitchingOptions{Policy: storage.StitchingPreferAggregated},
+			expected: resolvedNamespaces{
+				narrowed(aggregated, 0, now.Add(-time.Hour)),
+				narrowed(unaggregated, now.Add(-time.Hour), 0),
+			},
+			applied: true,
+		},
+		{
+			name:       "configured cutover",
+			namespaces: namespaces,
+			stitching:  storage.StitchingOptions{Policy: storage.StitchingCutover},
+			cutovers:   cutovers,
+			expected: resolvedNamespaces{
+				narrowed(aggregated, 0, cutover),
+				narrowed(unaggregated, cutover, 0),
+			},
+			applied: true,
+		},
+		{
+			name:       "query cutover before start",
+			namespaces: namespaces,
+			stitching: storage.StitchingOptions{
+				Policy:  storage.StitchingCutover,
+				Cutover: start.Add(-time.Hour),
+			},
+			cutovers: cutovers,
+			expected: resolvedNamespaces{resolved(unaggregated)},
+			applied:  true,
+		},
+		{
+			name:       "cutover not configured",
+			namespaces: namespaces,
+			stitching:  storage.StitchingOptions{Policy: storage.StitchingCutover},
+			expected:   namespaces,
+		},
+		{
+			name:       "unaggregated only",
+			namespaces: resolvedNamespaces{resolved(unaggregated)},
+			stitching:  storage.StitchingOptions{Policy: storage.StitchingPreferAggregated},
+			expected:   resolvedNamespaces{resolved(unaggregated)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append(resolvedNamespaces(nil), tt.namespaces...)
+			result, applied := applyStitchingPolicy(now, start, end, input,
+				tt.stitching, tt.cutovers)
+			require.Equal(t, tt.applied, applied)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -25,11 +25,14 @@ import (
 	"context"
 	goerrors "errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	coordmodel "github.com/m3db/m3/src/cmd/services/m3coordinator/model"
 	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/errors"
@@ -46,6 +49,7 @@ import (
 	"github.com/m3db/m3/src/x/instrument"
 	xtime "github.com/m3db/m3/src/x/time"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/opentracing/opentracing-go/log"
 	"github.com/prometheus/common/model"
 	"go.uber.org/zap"
@@ -54,6 +58,10 @@ import (
 
 const (
 	minWriteWaitTimeout = time.Second
+
+	// unavailableNamespacesWarningName is the name of the warning added when
+	// namespaces that could serve a query are not ready to be queried.
+	unavailableNamespacesWarningName = "namespaces unavailable"
 )
 
 var (
@@ -122,6 +130,93 @@ func (s *m3storage) QueryStorageMetadataAttributes(
 	return results, nil
 }
 
+func (s *m3storage) ExplainQuery(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (storage.QueryExplanation, error) {
+	m3query, err := storage.FetchQueryToM3Query(query, options)
+	if err != nil {
+		return storage.QueryExplanation{}, err
+	}
+
+	m3opts, err := storage.FetchOptionsToM3Options(options, query)
+	if err != nil {
+		return storage.QueryExplanation{}, err
+	}
+
+	now := xtime.ToUnixNano(s.nowFn())
+	fanout, namespaces, err := resolveClusterNamespacesForQuery(
+		now,
+		m3opts.StartInclusive,
+		m3opts.EndExclusive,
+		s.clusters,
+		options.FanoutOptions,
+		options.RestrictQueryOptions,
+		options.RelatedQueryOptions,
+	)
+	if err != nil {
+		return storage.QueryExplanation{}, err
+	}
+
+	namespaces, _ = applyStitchingPolicy(now, m3opts.StartInclusive,
+		m3opts.EndExclusive, namespaces, s.stitchingOptions(options),
+		s.opts.StitchingCutovers())
+
+	if len(namespaces) == 0 {
+		return storage.QueryExplanation{}, errNoNamespacesConfigured
+	}
+
+	var (
+		indexQuery = m3query.String()
+		results    = make([]storage.NamespaceExplanation, len(namespaces))
+		errs       = make([]error, len(namespaces))
+		wg         sync.WaitGroup
+	)
+	for i, namespace := range namespaces {
+		i, namespace := i, namespace // Capture vars
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Estimate the series from the index only, no data is fetched.
+			narrowedM3Opts := narrowQueryOpts(m3opts, namespace)
+			iter, metadata, err := namespace.Session().FetchTaggedIDs(ctx,
+				namespace.NamespaceID(), m3query, narrowedM3Opts)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			results[i] = storage.NamespaceExplanation{
+				Storage:         s.Name(),
+				FanoutType:      fanout.String(),
+				Namespace:       namespace.NamespaceID().String(),
+				Attributes:      namespace.Options().Attributes(),
+				Start:           narrowedM3Opts.StartInclusive.ToTime(),
+				End:             narrowedM3Opts.EndExclusive.ToTime(),
+				IndexQuery:      indexQuery,
+				EstimatedSeries: iter.Remaining(),
+				Exhaustive:      metadata.Exhaustive,
+			}
+			iter.Finalize()
+		}()
+	}
+
+	wg.Wait()
+
+	var multiErr xerrors.MultiError
+	for _, err := range errs {
+		multiErr = multiErr.Add(err)
+	}
+	if err := multiErr.FinalError(); err != nil {
+		return storage.QueryExplanation{}, err
+	}
+
+	return storage.QueryExplanation{Namespaces: results}, nil
+}
+
 func (s *m3storage) ErrorBehavior() storage.ErrorBehavior {
 	return storage.BehaviorFail
 }
@@ -379,8 +474,9 @@ func (s *m3storage) fetchCompressed(
 	// cluster that can completely fulfill this range and then prefer the
 	// highest resolution (most fine grained) results.
 	// This needs to be optimized, however this is a start.
+	now := xtime.ToUnixNano(s.nowFn())
 	fanout, namespaces, err := resolveClusterNamespacesForQuery(
-		xtime.ToUnixNano(s.nowFn()),
+		now,
 		queryStart,
 		queryEnd,
 		s.clusters,
@@ -392,6 +488,10 @@ func (s *m3storage) fetchCompressed(
 		return nil, index.Query{}, err
 	}
 
+	stitching := s.stitchingOptions(options)
+	namespaces, stitched := applyStitchingPolicy(now, queryStart, queryEnd,
+		namespaces, stitching, s.opts.StitchingCutovers())
+
 	if s.logger.Core().Enabled(zapcore.DebugLevel) {
 		for _, n := range namespaces {
 			// NB(r): Need to perform log on inner loop, cannot reuse a
@@ -434,6 +534,9 @@ func (s *m3storage) fetchCompressed(
 		RequireExhaustive: queryOptions.InstanceMultiple > 0 && options.RequireExhaustive,
 	}
 	result := consolidators.NewMultiFetchResult(fanout, matchOpts, tagOpts, limitOpts)
+	if warning, ok := s.unavailableNamespacesWarning(now, queryStart); ok {
+		result.AddWarnings(warning)
+	}
 	for _, namespace := range namespaces {
 		namespace := namespace // Capture var
 
@@ -448,6 +551,9 @@ func (s *m3storage) fetchCompressed(
 			namespaceID := namespace.NamespaceID()
 			narrowedQueryOpts := narrowQueryOpts(queryOptions, namespace)
 			iters, metadata, err := session.FetchTagged(ctx, namespaceID, m3query, narrowedQueryOpts)
+			if err == nil && options.Sampled() {
+				iters = sampleSeriesIterators(iters, options.SampleRate)
+			}
 			if err == nil && sampled {
 				span.LogFields(
 					log.String("namespace", namespaceID.String()),
@@ -460,6 +566,9 @@ func (s *m3storage) fetchCompressed(
 
 			blockMeta := block.NewResultMetadata()
 			blockMeta.AddNamespace(namespaceID.String())
+			if stitched {
+				blockMeta.AddStitchingPolicy(stitching.Policy.String())
+			}
 			blockMeta.FetchedResponses = metadata.Responses
 			blockMeta.FetchedBytesEstimate = metadata.EstimateTotalBytes
 			blockMeta.Exhaustive = metadata.Exhaustive
@@ -488,6 +597,52 @@ func (s *m3storage) fetchCompressed(
 	return result, m3query, err
 }
 
+// sampleSeriesIterators returns the deterministic sample of the series
+// selected by the rate, sampling by series ID so that the same series are
+// selected from every namespace. The series left out are closed before any of
+// their datapoints are decoded.
+func sampleSeriesIterators(
+	iters encoding.SeriesIterators,
+	rate float64,
+) encoding.SeriesIterators {
+	sampled := make([]encoding.SeriesIterator, 0, int(float64(iters.Len())*rate))
+	for _, iter := range iters.Iters() {
+		if iter == nil {
+			continue
+		}
+		if !storage.SampleSeries(xxhash.Sum64(iter.ID().Bytes()), rate) {
+			iter.Close()
+			continue
+		}
+		sampled = append(sampled, iter)
+	}
+	return encoding.NewSeriesIterators(sampled)
+}
+
+// unavailableNamespacesWarning returns a warning listing the namespaces that
+// are not ready to be queried yet while their retention covers the start of
+// the query, the results may be missing the data they would have served.
+func (s *m3storage) unavailableNamespacesWarning(
+	now, start xtime.UnixNano,
+) (block.Warning, bool) {
+	var ids []string
+	for _, n := range s.clusters.NonReadyClusterNamespaces() {
+		if now.Add(-n.Options().Attributes().Retention).After(start) {
+			continue
+		}
+		ids = append(ids, n.NamespaceID().String())
+	}
+	if len(ids) == 0 {
+		return block.Warning{}, false
+	}
+
+	sort.Strings(ids)
+	return block.Warning{
+		Name:    unavailableNamespacesWarningName,
+		Message: strings.Join(ids, ", "),
+	}, true
+}
+
 func (s *m3storage) SearchSeries(
 	ctx context.Context,
 	query *storage.FetchQuery,
@@ -46,6 +46,7 @@ import (
 	xtest "github.com/m3db/m3/src/x/test"
 	xtime "github.com/m3db/m3/src/x/time"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -404,6 +405,59 @@ func TestLocalRead(t *testing.T) {
 	assertFetchResult(t, results, testTags)
 }
 
+type nonReadyClusters struct {
+	Clusters
+
+	nonReady ClusterNamespaces
+}
+
+func (c nonReadyClusters) NonReadyClusterNamespaces() ClusterNamespaces {
+	return c.nonReady
+}
+
+func TestLocalReadNonReadyNamespaces(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockSession(ctrl)
+	clusters, err := NewClusters(UnaggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("metrics_unaggregated"),
+		Session:     session,
+		Retention:   test1MonthRetention,
+	})
+	require.NoError(t, err)
+
+	nonReady, err := NewClusters(UnaggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("metrics_unaggregated_short"),
+		Session:     client.NewMockSession(ctrl),
+		Retention:   time.Minute,
+	}, AggregatedClusterNamespaceDefinition{
+		NamespaceID: ident.StringID("metrics_aggregated_1m:30d"),
+		Session:     client.NewMockSession(ctrl),
+		Retention:   test1MonthRetention,
+		Resolution:  time.Minute,
+	})
+	require.NoError(t, err)
+
+	store := newTestStorage(t, nonReadyClusters{
+		Clusters: clusters,
+		nonReady: nonReady.ClusterNamespaces(),
+	})
+
+	testTags := seriesiter.GenerateTag()
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(seriesiter.NewMockSeriesIters(ctrl, testTags, 1, 2),
+			testFetchResponseMetadata, nil)
+
+	// Only the non-ready namespace whose retention covers the query is listed.
+	results, err := store.FetchProm(context.TODO(), newFetchReq(), buildFetchOpts())
+	require.NoError(t, err)
+	require.Equal(t, block.Warnings{{
+		Name:    unavailableNamespacesWarningName,
+		Message: "metrics_aggregated_1m:30d",
+	}}, results.Metadata.Warnings)
+}
+
 func TestLocalReadExceedsRetention(t *testing.T) {
 	ctrl := xtest.NewController(t)
 	defer ctrl.Finish()
@@ -1148,3 +1202,60 @@ func TestFindReservedLabel(t *testing.T) {
 	assert.Nil(t, findReservedLabel(labels, nameLabel))
 	assert.Nil(t, findReservedLabel(labels, rollupLabel))
 }
+
+func TestSampleSeriesIterators(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	sampledIDs := func(rate float64) []string {
+		iters := make([]encoding.SeriesIterator, 0, 1000)
+		for i := 0; i < 1000; i++ {
+			iter := encoding.NewMockSeriesIterator(ctrl)
+			iter.EXPECT().ID().Return(ident.StringID(fmt.Sprintf("foo{id=\"%d\"}", i))).AnyTimes()
+			// Only the series left out of the sample are closed.
+			iter.EXPECT().Close().MaxTimes(1)
+			iters = append(iters, iter)
+		}
+
+		var ids []string
+		for _, iter := range sampleSeriesIterators(encoding.NewSeriesIterators(iters), rate).Iters() {
+			ids = append(ids, iter.ID().String())
+		}
+		return ids
+	}
+
+	sampled := sampledIDs(0.25)
+	assert.True(t, len(sampled) > 150 && len(sampled) < 350,
+		"unexpected sample size %d", len(sampled))
+
+	// Sampling is deterministic across queries.
+	assert.Equal(t, sampled, sampledIDs(0.25))
+}
+
+func TestLocalReadSampled(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	store, sessions := setup(t, ctrl)
+
+	// NB: the series is closed without being read since it is left out of
+	// the sample.
+	rate := 0.0001
+	require.False(t, storage.SampleSeries(xxhash.Sum64([]byte("foo")), rate))
+	iters := encoding.NewMockSeriesIterators(ctrl)
+	iter := encoding.NewMockSeriesIterator(ctrl)
+	iter.EXPECT().ID().Return(ident.StringID("foo")).AnyTimes()
+	iter.EXPECT().Close()
+	iters.EXPECT().Len().Return(1).AnyTimes()
+	iters.EXPECT().Iters().Return([]encoding.SeriesIterator{iter})
+
+	session := sessions.unaggregated1MonthRetention
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(iters, testFetchResponseMetadata, nil)
+
+	opts := buildFetchOpts()
+	opts.SampleRate = rate
+	results, err := store.FetchProm(context.TODO(), newFetchReq(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(results.PromResult.GetTimeseries()))
+}
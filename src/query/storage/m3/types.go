@@ -215,6 +215,18 @@ type Options interface {
 	// PromConvertOptions returns options for converting raw series iterators
 	// to a Prometheus-compatible result.
 	PromConvertOptions() storage.PromConvertOptions
+	// SetStitchingPolicy sets the policy for stitching results of the
+	// unaggregated namespace and aggregated namespaces.
+	SetStitchingPolicy(value storage.StitchingPolicy) Options
+	// StitchingPolicy returns the policy for stitching results of the
+	// unaggregated namespace and aggregated namespaces.
+	StitchingPolicy() storage.StitchingPolicy
+	// SetStitchingCutovers sets the cut-over timestamps between namespace
+	// pairs used by the cutover stitching policy.
+	SetStitchingCutovers(value []StitchingCutover) Options
+	// StitchingCutovers returns the cut-over timestamps between namespace
+	// pairs used by the cutover stitching policy.
+	StitchingCutovers() []StitchingCutover
 	// Validate ensures that the given block options are valid.
 	Validate() error
 }
@@ -47,6 +47,7 @@ type Storage interface {
 	SetWriteResult(error)
 	SetFetchBlocksResult(block.Result, error)
 	SetQueryStorageMetadataAttributesResult([]storagemetadata.Attributes, error)
+	SetExplainQueryResult(storage.QueryExplanation, error)
 	SetCloseResult(error)
 	Writes() []*storage.WriteQuery
 }
@@ -85,6 +86,10 @@ type mockStorage struct {
 		attrs []storagemetadata.Attributes
 		err   error
 	}
+	explainQueryResult struct {
+		result storage.QueryExplanation
+		err    error
+	}
 	writes []*storage.WriteQuery
 }
 
@@ -160,6 +165,13 @@ func (s *mockStorage) SetQueryStorageMetadataAttributesResult(attrs []storagemet
 	s.queryStorageMetadataAttributesResult.err = err
 }
 
+func (s *mockStorage) SetExplainQueryResult(result storage.QueryExplanation, err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.explainQueryResult.result = result
+	s.explainQueryResult.err = err
+}
+
 func (s *mockStorage) SetCloseResult(err error) {
 	s.Lock()
 	defer s.Unlock()
@@ -247,6 +259,17 @@ func (s *mockStorage) QueryStorageMetadataAttributes(
 	return s.queryStorageMetadataAttributesResult.attrs, s.queryStorageMetadataAttributesResult.err
 }
 
+func (s *mockStorage) ExplainQuery(
+	_ context.Context,
+	_ *storage.FetchQuery,
+	opts *storage.FetchOptions,
+) (storage.QueryExplanation, error) {
+	s.RLock()
+	defer s.RUnlock()
+	s.lastFetchOptions = opts
+	return s.explainQueryResult.result, s.explainQueryResult.err
+}
+
 func (s *mockStorage) Write(
 	ctx context.Context,
 	query *storage.WriteQuery,
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/atomic"
+)
+
+// NamespaceAliases resolves the previous names of renamed namespaces to the
+// current names of the namespaces, so that queries referencing a previous
+// name keep working after a rename.
+type NamespaceAliases struct {
+	aliases map[string]*namespaceAlias
+}
+
+type namespaceAlias struct {
+	namespace string
+	resolved  *atomic.Int64
+	counter   tally.Counter
+}
+
+// NamespaceAlias describes an alias of a namespace and how many times it
+// has been resolved.
+type NamespaceAlias struct {
+	Alias     string
+	Namespace string
+	Resolved  int64
+}
+
+// NewNamespaceAliases returns new namespace aliases from a map of alias to
+// the name of the namespace it refers to.
+func NewNamespaceAliases(
+	aliases map[string]string,
+	scope tally.Scope,
+) (*NamespaceAliases, error) {
+	result := &NamespaceAliases{
+		aliases: make(map[string]*namespaceAlias, len(aliases)),
+	}
+	for alias, namespace := range aliases {
+		if alias == "" || namespace == "" {
+			return nil, fmt.Errorf(
+				"invalid namespace alias: alias=%q, namespace=%q", alias, namespace)
+		}
+		if alias == namespace {
+			return nil, fmt.Errorf("namespace alias refers to itself: %s", alias)
+		}
+		if _, ok := aliases[namespace]; ok {
+			// Chained aliases are disallowed so that a rename of a renamed
+			// namespace must update the existing aliases.
+			return nil, fmt.Errorf(
+				"namespace alias %s refers to another alias: %s", alias, namespace)
+		}
+		result.aliases[alias] = &namespaceAlias{
+			namespace: namespace,
+			resolved:  atomic.NewInt64(0),
+			counter: scope.Tagged(map[string]string{
+				"alias":     alias,
+				"namespace": namespace,
+			}).Counter("namespace-alias-resolved"),
+		}
+	}
+	return result, nil
+}
+
+// Resolve returns the name of the namespace the name refers to, which is the
+// name itself if it is not an alias.
+func (a *NamespaceAliases) Resolve(name string) string {
+	if a == nil {
+		return name
+	}
+	alias, ok := a.aliases[name]
+	if !ok {
+		return name
+	}
+	alias.resolved.Inc()
+	alias.counter.Inc(1)
+	return alias.namespace
+}
+
+// Aliases returns the aliases sorted by alias.
+func (a *NamespaceAliases) Aliases() []NamespaceAlias {
+	if a == nil {
+		return nil
+	}
+	result := make([]NamespaceAlias, 0, len(a.aliases))
+	for alias, elem := range a.aliases {
+		result = append(result, NamespaceAlias{
+			Alias:     alias,
+			Namespace: elem.namespace,
+			Resolved:  elem.resolved.Load(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Alias < result[j].Alias
+	})
+	return result
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestNamespaceAliasesResolve(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	aliases, err := NewNamespaceAliases(map[string]string{
+		"metrics_old":    "metrics",
+		"metrics_legacy": "metrics",
+	}, scope)
+	require.NoError(t, err)
+
+	require.Equal(t, "metrics", aliases.Resolve("metrics_old"))
+	require.Equal(t, "metrics", aliases.Resolve("metrics_old"))
+	require.Equal(t, "metrics", aliases.Resolve("metrics"))
+	require.Equal(t, "other", aliases.Resolve("other"))
+
+	require.Equal(t, []NamespaceAlias{
+		{Alias: "metrics_legacy", Namespace: "metrics"},
+		{Alias: "metrics_old", Namespace: "metrics", Resolved: 2},
+	}, aliases.Aliases())
+
+	counter, ok := scope.Snapshot().Counters()["namespace-alias-resolved+alias=metrics_old,namespace=metrics"]
+	require.True(t, ok)
+	require.Equal(t, int64(2), counter.Value())
+}
+
+func TestNamespaceAliasesNil(t *testing.T) {
+	var aliases *NamespaceAliases
+	require.Equal(t, "metrics", aliases.Resolve("metrics"))
+	require.Nil(t, aliases.Aliases())
+}
+
+func TestNamespaceAliasesInvalid(t *testing.T) {
+	for _, aliases := range []map[string]string{
+		{"": "metrics"},
+		{"metrics_old": ""},
+		{"metrics": "metrics"},
+		{"a": "b", "b": "c"},
+	} {
+		_, err := NewNamespaceAliases(aliases, tally.NoopScope)
+		require.Error(t, err)
+	}
+}
@@ -48,6 +48,14 @@ func (noopStorage) QueryStorageMetadataAttributes(
 	return nil, errNoopClient
 }
 
+func (noopStorage) ExplainQuery(
+	ctx context.Context,
+	query *FetchQuery,
+	options *FetchOptions,
+) (QueryExplanation, error) {
+	return QueryExplanation{}, errNoopClient
+}
+
 func (noopStorage) Fetch(ctx context.Context, query *FetchQuery, options *FetchOptions) (*FetchResult, error) {
 	return nil, errNoopClient
 }
@@ -0,0 +1,160 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package prometheus
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	promstorage "github.com/prometheus/prometheus/storage"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/storage"
+)
+
+// indexOnlyAggregations are the aggregations whose result only depends on
+// which series are present and their labels, not on the series values.
+var indexOnlyAggregations = map[string]struct{}{
+	"count": {},
+	"group": {},
+}
+
+// canPushdownToIndex returns whether a selector can be answered from the
+// index alone. The engine sets the hint function to the closest function or
+// aggregation over the selector, stopping at binary operators, so the
+// selector is directly counted or grouped when it is one of the index only
+// aggregations.
+func canPushdownToIndex(hints *promstorage.SelectHints) bool {
+	if hints == nil || hints.Range != 0 {
+		return false
+	}
+	_, ok := indexOnlyAggregations[hints.Func]
+	return ok
+}
+
+type indexPushdown struct {
+	lookback time.Duration
+	pushed   tally.Counter
+	fallback tally.Counter
+}
+
+func newIndexPushdown(lookback time.Duration, scope tally.Scope) *indexPushdown {
+	scope = scope.SubScope("index-pushdown")
+	return &indexPushdown{
+		lookback: lookback,
+		pushed:   scope.Tagged(map[string]string{"result": "pushed"}).Counter("selects"),
+		fallback: scope.Tagged(map[string]string{"result": "fallback"}).Counter("selects"),
+	}
+}
+
+// selectFromIndex answers a selector from the index without fetching series
+// data, each matched series is given a sample at every evaluation step since
+// the aggregation over it only depends on its presence. It returns false when
+// the index results can not be relied on and the series data needs to be
+// fetched instead.
+func (p *indexPushdown) selectFromIndex(
+	ctx context.Context,
+	store storage.Storage,
+	sortSeries bool,
+	hints *promstorage.SelectHints,
+	query *storage.FetchQuery,
+	fetchOptions *storage.FetchOptions,
+	logger *zap.Logger,
+) (promstorage.SeriesSet, block.ResultMetadata, bool) {
+	result, err := store.SearchSeries(ctx, query, fetchOptions)
+	if err != nil {
+		logger.Debug("index pushdown failed, fetching series data", zap.Error(err))
+		p.fallback.Inc(1)
+		return nil, block.ResultMetadata{}, false
+	}
+	if !result.Metadata.Exhaustive {
+		// A namespace only returned part of the series matched by its index,
+		// counting them would silently undercount so fetch the data instead
+		// which applies the same limits and reports them consistently.
+		p.fallback.Inc(1)
+		return nil, block.ResultMetadata{}, false
+	}
+	p.pushed.Inc(1)
+
+	timestamps := p.sampleTimestamps(hints)
+	samples := make([]prompb.Sample, 0, len(timestamps))
+	for _, t := range timestamps {
+		samples = append(samples, prompb.Sample{Timestamp: t, Value: 1})
+	}
+
+	series := make([]promstorage.Series, 0, len(result.Metrics))
+	for _, metric := range result.Metrics {
+		seriesLabels := make(labels.Labels, 0, len(metric.Tags.Tags))
+		for _, tag := range metric.Tags.Tags {
+			seriesLabels = append(seriesLabels, labels.Label{
+				Name:  string(tag.Name),
+				Value: string(tag.Value),
+			})
+		}
+		sort.Sort(seriesLabels)
+		if err := validateLabelsAndMetricName(seriesLabels); err != nil {
+			return promstorage.ErrSeriesSet(err), result.Metadata, true
+		}
+
+		series = append(series, &concreteSeries{
+			labels:  seriesLabels,
+			samples: samples,
+		})
+	}
+
+	if sortSeries {
+		sort.Sort(byLabel(series))
+	}
+
+	return &concreteSeriesSet{
+		series:   series,
+		warnings: fromWarningStrings(result.Metadata.WarningStrings()),
+	}, result.Metadata, true
+}
+
+// sampleTimestamps returns the timestamps in milliseconds to place the samples
+// of series answered from the index at. They are spaced closer than the
+// lookback so every evaluation step finds one regardless of how the steps
+// align with the selected range.
+func (p *indexPushdown) sampleTimestamps(hints *promstorage.SelectHints) []int64 {
+	if hints.Step <= 0 || hints.Start >= hints.End {
+		return []int64{hints.End}
+	}
+
+	interval := hints.Step
+	if lookback := p.lookback.Milliseconds(); lookback > 0 && interval >= lookback {
+		interval = lookback / 2
+		if interval < 1 {
+			interval = 1
+		}
+	}
+
+	timestamps := make([]int64, 0, (hints.End-hints.Start)/interval+1)
+	for t := hints.Start + interval; t < hints.End; t += interval {
+		timestamps = append(timestamps, t)
+	}
+	return append(timestamps, hints.End)
+}
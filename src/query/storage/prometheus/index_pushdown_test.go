@@ -0,0 +1,222 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/prometheus/promql"
+	promstorage "github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/x/instrument"
+	xtest "github.com/m3db/m3/src/x/test"
+)
+
+func TestCanPushdownToIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		hints    *promstorage.SelectHints
+		expected bool
+	}{
+		{name: "no hints"},
+		{name: "no function", hints: &promstorage.SelectHints{}},
+		{name: "count", hints: &promstorage.SelectHints{Func: "count"}, expected: true},
+		{name: "group", hints: &promstorage.SelectHints{Func: "group"}, expected: true},
+		{name: "sum", hints: &promstorage.SelectHints{Func: "sum"}},
+		{name: "rate", hints: &promstorage.SelectHints{Func: "rate", Range: 60000}},
+		{name: "range", hints: &promstorage.SelectHints{Func: "count", Range: 60000}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, canPushdownToIndex(test.hints))
+		})
+	}
+}
+
+func TestIndexPushdownSampleTimestamps(t *testing.T) {
+	p := newIndexPushdown(time.Minute, instrument.NewOptions().MetricsScope())
+
+	// Instant selects only need a sample at the end.
+	assert.Equal(t, []int64{1000},
+		p.sampleTimestamps(&promstorage.SelectHints{Start: 0, End: 1000}))
+
+	// Steps shorter than the lookback are used as the interval.
+	assert.Equal(t, []int64{10000, 20000, 30000, 35000},
+		p.sampleTimestamps(&promstorage.SelectHints{Start: 0, End: 35000, Step: 10000}))
+
+	// Steps longer than the lookback are split so every step finds a sample.
+	assert.Equal(t, []int64{30000, 60000, 90000, 100000},
+		p.sampleTimestamps(&promstorage.SelectHints{Start: 0, End: 100000, Step: 120000}))
+}
+
+func newTestPushdownContext() context.Context {
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, FetchOptionsContextKey, storage.NewFetchOptions())
+	return context.WithValue(ctx, BlockResultMetadataFnKey, func(block.ResultMetadata) {})
+}
+
+func newTestMetric(dc, instance string) models.Metric {
+	tags := models.NewTags(3, models.NewTagOptions()).
+		AddTag(models.Tag{Name: []byte("__name__"), Value: []byte("up")}).
+		AddTag(models.Tag{Name: []byte("dc"), Value: []byte(dc)}).
+		AddTag(models.Tag{Name: []byte("instance"), Value: []byte(instance)})
+	return models.Metric{ID: tags.ID(), Tags: tags}
+}
+
+func TestIndexPushdownCountBy(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	queryable := NewPrometheusQueryable(PrometheusOptions{
+		Storage:                  store,
+		InstrumentOptions:        instrument.NewOptions(),
+		IndexAggregationPushdown: true,
+		LookbackDuration:         5 * time.Minute,
+	})
+
+	meta := block.NewResultMetadata()
+	store.EXPECT().SearchSeries(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&storage.SearchResults{
+			Metrics: models.Metrics{
+				newTestMetric("a", "1"),
+				newTestMetric("a", "2"),
+				newTestMetric("b", "1"),
+			},
+			Metadata: meta,
+		}, nil)
+
+	engine := promql.NewEngine(promql.EngineOpts{
+		MaxSamples:    10000,
+		Timeout:       time.Minute,
+		LookbackDelta: 5 * time.Minute,
+	})
+	end := time.Now().Truncate(time.Minute)
+	start := end.Add(-time.Hour)
+	query, err := engine.NewRangeQuery(queryable, "count by (dc) (up)",
+		start, end, 7*time.Minute)
+	require.NoError(t, err)
+
+	res := query.Exec(newTestPushdownContext())
+	require.NoError(t, res.Err)
+	matrix, err := res.Matrix()
+	require.NoError(t, err)
+	require.Len(t, matrix, 2)
+
+	expected := map[string]float64{"a": 2, "b": 1}
+	for _, series := range matrix {
+		value, ok := expected[series.Metric.Get("dc")]
+		require.True(t, ok)
+		// Every step has a value even though the step is not aligned with
+		// the end of the range.
+		require.Len(t, series.Points, 9)
+		for _, point := range series.Points {
+			assert.Equal(t, value, point.V)
+		}
+	}
+}
+
+func TestIndexPushdownFallsBackWhenNotExhaustive(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	queryable := NewPrometheusQueryable(PrometheusOptions{
+		Storage:                  store,
+		InstrumentOptions:        instrument.NewOptions(),
+		IndexAggregationPushdown: true,
+		LookbackDuration:         5 * time.Minute,
+	})
+
+	meta := block.NewResultMetadata()
+	meta.Exhaustive = false
+	store.EXPECT().SearchSeries(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&storage.SearchResults{
+			Metrics:  models.Metrics{newTestMetric("a", "1")},
+			Metadata: meta,
+		}, nil)
+
+	now := time.Now().Truncate(time.Minute)
+	store.EXPECT().FetchProm(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(storage.PromResult{
+			Metadata: block.NewResultMetadata(),
+			PromResult: &prompb.QueryResult{
+				Timeseries: []*prompb.TimeSeries{
+					{
+						Labels: []prompb.Label{
+							{Name: []byte("__name__"), Value: []byte("up")},
+							{Name: []byte("dc"), Value: []byte("a")},
+						},
+						Samples: []prompb.Sample{
+							{Value: 1, Timestamp: now.Add(-time.Minute).UnixNano() / int64(time.Millisecond)},
+						},
+					},
+				},
+			},
+		}, nil)
+
+	engine := promql.NewEngine(promql.EngineOpts{
+		MaxSamples:    10000,
+		Timeout:       time.Minute,
+		LookbackDelta: 5 * time.Minute,
+	})
+	query, err := engine.NewInstantQuery(queryable, "count(up)", now)
+	require.NoError(t, err)
+
+	res := query.Exec(newTestPushdownContext())
+	require.NoError(t, res.Err)
+	vector, err := res.Vector()
+	require.NoError(t, err)
+	require.Len(t, vector, 1)
+	assert.Equal(t, float64(1), vector[0].V)
+}
+
+func TestIndexPushdownDisabled(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	queryable := NewPrometheusQueryable(PrometheusOptions{
+		Storage:           store,
+		InstrumentOptions: instrument.NewOptions(),
+	})
+	store.EXPECT().FetchProm(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(storage.PromResult{
+			Metadata:   block.NewResultMetadata(),
+			PromResult: &prompb.QueryResult{},
+		}, nil)
+
+	q, err := queryable.Querier(newTestPushdownContext(), 0, 0)
+	require.NoError(t, err)
+	series := q.Select(false, &promstorage.SelectHints{Func: "count"})
+	require.NoError(t, series.Err())
+	require.False(t, series.Next())
+}
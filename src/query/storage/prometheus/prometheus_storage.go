@@ -42,9 +42,10 @@ import (
 )
 
 type prometheusQueryable struct {
-	storage storage.Storage
-	scope   tally.Scope
-	logger  *zap.Logger
+	storage       storage.Storage
+	scope         tally.Scope
+	logger        *zap.Logger
+	indexPushdown *indexPushdown
 }
 
 // PrometheusOptions are options to create a prometheus queryable backed by
@@ -52,6 +53,15 @@ type prometheusQueryable struct {
 type PrometheusOptions struct {
 	Storage           storage.Storage
 	InstrumentOptions instrument.Options
+
+	// IndexAggregationPushdown enables answering selectors that are only
+	// counted or grouped by labels, such as count by (label) (metric), from
+	// the index without fetching series data.
+	IndexAggregationPushdown bool
+	// LookbackDuration is the lookback of the engine, it places the samples
+	// of series answered from the index so that they are present at every
+	// evaluation step.
+	LookbackDuration time.Duration
 }
 
 // StorageErr wraps all errors returned by the storage layer.
@@ -80,35 +90,42 @@ func (e *StorageErr) Error() string {
 // storage.
 func NewPrometheusQueryable(opts PrometheusOptions) promstorage.Queryable {
 	scope := opts.InstrumentOptions.MetricsScope().Tagged(map[string]string{"storage": "prometheus_storage"})
-	return &prometheusQueryable{
+	queryable := &prometheusQueryable{
 		storage: opts.Storage,
 		scope:   scope,
 		logger:  opts.InstrumentOptions.Logger(),
 	}
+	if opts.IndexAggregationPushdown {
+		queryable.indexPushdown = newIndexPushdown(opts.LookbackDuration, scope)
+	}
+	return queryable
 }
 
 // Querier returns a prometheus storage Querier.
 func (q *prometheusQueryable) Querier(
 	ctx context.Context, _, _ int64,
 ) (promstorage.Querier, error) {
-	return newQuerier(ctx, q.storage, q.logger), nil
+	return newQuerier(ctx, q.storage, q.logger, q.indexPushdown), nil
 }
 
 type querier struct {
-	ctx     context.Context
-	storage storage.Storage
-	logger  *zap.Logger
+	ctx           context.Context
+	storage       storage.Storage
+	logger        *zap.Logger
+	indexPushdown *indexPushdown
 }
 
 func newQuerier(
 	ctx context.Context,
 	storage storage.Storage,
 	logger *zap.Logger,
+	indexPushdown *indexPushdown,
 ) promstorage.Querier {
 	return &querier{
-		ctx:     ctx,
-		storage: storage,
-		logger:  logger,
+		ctx:           ctx,
+		storage:       storage,
+		logger:        logger,
+		indexPushdown: indexPushdown,
 	}
 }
 
@@ -137,11 +154,10 @@ func (q *querier) Select(
 		return promstorage.ErrSeriesSet(err)
 	}
 
-	result, err := q.storage.FetchProm(q.ctx, query, fetchOptions)
+	seriesSet, metadata, err := q.fetch(sortSeries, hints, query, fetchOptions)
 	if err != nil {
 		return promstorage.ErrSeriesSet(NewStorageErr(err))
 	}
-	seriesSet := fromQueryResult(sortSeries, result.PromResult, result.Metadata)
 
 	receiveResultMetadataFn, err := resultMetadataReceiveFn(q.ctx)
 	if err != nil {
@@ -156,11 +172,34 @@ func (q *querier) Select(
 
 	// Pass the result.Metadata back using the receive function.
 	// This handles concurrent updates to a single result metadata.
-	receiveResultMetadataFn(result.Metadata)
+	receiveResultMetadataFn(metadata)
 
 	return seriesSet
 }
 
+func (q *querier) fetch(
+	sortSeries bool,
+	hints *promstorage.SelectHints,
+	query *storage.FetchQuery,
+	fetchOptions *storage.FetchOptions,
+) (promstorage.SeriesSet, block.ResultMetadata, error) {
+	// NB: index pushdown answers from every matched series so it cannot be
+	// used when the query is restricted to a sample of series.
+	if q.indexPushdown != nil && canPushdownToIndex(hints) && !fetchOptions.Sampled() {
+		seriesSet, metadata, ok := q.indexPushdown.selectFromIndex(q.ctx,
+			q.storage, sortSeries, hints, query, fetchOptions, q.logger)
+		if ok {
+			return seriesSet, metadata, nil
+		}
+	}
+
+	result, err := q.storage.FetchProm(q.ctx, query, fetchOptions)
+	if err != nil {
+		return nil, block.ResultMetadata{}, err
+	}
+	return fromQueryResult(sortSeries, result.PromResult, result.Metadata), result.Metadata, nil
+}
+
 func (q *querier) LabelValues(string, ...*labels.Matcher) ([]string, promstorage.Warnings, error) {
 	// TODO (@shreyas): Implement this.
 	q.logger.Warn("calling unsupported LabelValues method")
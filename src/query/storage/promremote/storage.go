@@ -242,6 +242,14 @@ func (p *unimplementedPromStorageMethods) QueryStorageMetadataAttributes(
 	return nil, unimplementedError("QueryStorageMetadataAttributes")
 }
 
+func (p *unimplementedPromStorageMethods) ExplainQuery(
+	_ context.Context,
+	_ *storage.FetchQuery,
+	_ *storage.FetchOptions,
+) (storage.QueryExplanation, error) {
+	return storage.QueryExplanation{}, unimplementedError("ExplainQuery")
+}
+
 func unimplementedError(name string) error {
 	return fmt.Errorf("promStorage: %s method is not supported", name)
 }
@@ -59,6 +59,14 @@ func (s *remoteStorage) QueryStorageMetadataAttributes(
 	return s.client.QueryStorageMetadataAttributes(ctx, queryStart, queryEnd, opts)
 }
 
+func (s *remoteStorage) ExplainQuery(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (storage.QueryExplanation, error) {
+	return s.client.ExplainQuery(ctx, query, options)
+}
+
 func (s *remoteStorage) FetchProm(
 	ctx context.Context,
 	query *storage.FetchQuery,
@@ -22,6 +22,7 @@ package storage
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
 	"github.com/m3db/m3/src/metrics/policy"
@@ -31,6 +32,13 @@ import (
 
 // Validate will validate the restrict fetch options.
 func (o *RestrictQueryOptions) Validate() error {
+	if o.RestrictByNamespace != nil {
+		if o.RestrictByType != nil || len(o.RestrictByTypes) > 0 {
+			return errors.New(
+				"restrict by namespace cannot be combined with restrict by type")
+		}
+		return o.RestrictByNamespace.Validate()
+	}
 	if o.RestrictByType != nil {
 		return o.RestrictByType.Validate()
 	}
@@ -60,6 +68,16 @@ func (o *RestrictQueryOptions) GetRestrictByTypes() []*RestrictByType {
 	return o.RestrictByTypes
 }
 
+// GetRestrictByNamespace provides the namespace restriction if present;
+// nil otherwise.
+func (o *RestrictQueryOptions) GetRestrictByNamespace() *RestrictByNamespace {
+	if o == nil {
+		return nil
+	}
+
+	return o.RestrictByNamespace
+}
+
 // GetRestrictByTag provides the tag restrictions if present; nil otherwise.
 func (o *RestrictQueryOptions) GetRestrictByTag() *RestrictByTag {
 	if o == nil {
@@ -108,6 +126,14 @@ func (o *RestrictByType) Validate() error {
 	return nil
 }
 
+// Validate will validate the restrict namespace restriction.
+func (o *RestrictByNamespace) Validate() error {
+	if o.Namespace == "" {
+		return errors.New("expected namespace for restrict by namespace")
+	}
+	return nil
+}
+
 // GetFilterByNames returns the tag names to filter out of the response.
 func (o *RestrictByTag) GetFilterByNames() [][]byte {
 	if o == nil {
@@ -149,7 +175,7 @@ func (q *FetchQuery) WithAppliedOptions(
 	// Since must apply matchers will always be small (usually 1)
 	// it's better to not allocate intermediate datastructure and just
 	// perform n^2 matching.
-	existing := result.TagMatchers
+	existing := make(models.Matchers, 0, len(result.TagMatchers)+len(restrict))
 	for _, existingMatcher := range result.TagMatchers {
 		willBeOverridden := false
 		for _, matcher := range restrict {
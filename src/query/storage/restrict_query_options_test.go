@@ -64,3 +64,22 @@ func TestGetRestrict(t *testing.T) {
 	opts.RestrictByTypes = byTypes
 	require.Equal(t, byTypes, opts.GetRestrictByTypes())
 }
+
+func TestRestrictByNamespace(t *testing.T) {
+	var opts *RestrictQueryOptions
+	require.Nil(t, opts.GetRestrictByNamespace())
+
+	byNamespace := &RestrictByNamespace{Namespace: "metrics"}
+	opts = &RestrictQueryOptions{RestrictByNamespace: byNamespace}
+	require.Equal(t, byNamespace, opts.GetRestrictByNamespace())
+	require.NoError(t, opts.Validate())
+
+	opts.RestrictByNamespace = &RestrictByNamespace{}
+	require.Error(t, opts.Validate())
+
+	opts.RestrictByNamespace = byNamespace
+	opts.RestrictByType = &RestrictByType{
+		MetricsType: storagemetadata.UnaggregatedMetricsType,
+	}
+	require.Error(t, opts.Validate())
+}
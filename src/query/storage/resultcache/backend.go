@@ -0,0 +1,114 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Backend stores encoded query results by key.
+type Backend interface {
+	// Get returns the value of the key, false if the key is not cached.
+	Get(key string) ([]byte, bool, error)
+
+	// Set sets the value of the key, the key expires after the TTL.
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Close closes the backend.
+	Close() error
+}
+
+type localEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+type localBackend struct {
+	sync.Mutex
+
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+	nowFn      func() time.Time
+}
+
+// NewLocalBackend returns a backend that caches results in memory, evicting
+// the least recently used results once it holds the max number of entries.
+func NewLocalBackend(maxEntries int) Backend {
+	return &localBackend{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		nowFn:      time.Now,
+	}
+}
+
+func (b *localBackend) Get(key string) ([]byte, bool, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	elem, ok := b.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*localEntry)
+	if !b.nowFn().Before(entry.expiresAt) {
+		b.remove(elem)
+		return nil, false, nil
+	}
+	b.lru.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+func (b *localBackend) Set(key string, value []byte, ttl time.Duration) error {
+	b.Lock()
+	defer b.Unlock()
+
+	expiresAt := b.nowFn().Add(ttl)
+	if elem, ok := b.entries[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		b.lru.MoveToFront(elem)
+		return nil
+	}
+
+	b.entries[key] = b.lru.PushFront(&localEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+	for b.lru.Len() > b.maxEntries {
+		b.remove(b.lru.Back())
+	}
+	return nil
+}
+
+func (b *localBackend) remove(elem *list.Element) {
+	b.lru.Remove(elem)
+	delete(b.entries, elem.Value.(*localEntry).key)
+}
+
+func (b *localBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBackend(t *testing.T) {
+	var (
+		now     = time.Now()
+		backend = NewLocalBackend(2).(*localBackend)
+	)
+	backend.nowFn = func() time.Time { return now }
+
+	require.NoError(t, backend.Set("a", []byte("a"), time.Minute))
+	require.NoError(t, backend.Set("b", []byte("b"), time.Minute))
+
+	// Getting a makes b the least recently used entry.
+	value, ok, err := backend.Get("a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("a"), value)
+
+	require.NoError(t, backend.Set("c", []byte("c"), time.Minute))
+	_, ok, err = backend.Get("b")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok, err = backend.Get("a")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Len(t, backend.entries, 1)
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/msg/consumer"
+	"github.com/m3db/m3/src/msg/producer"
+	producerconfig "github.com/m3db/m3/src/msg/producer/config"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/x/instrument"
+	xio "github.com/m3db/m3/src/x/io"
+	"github.com/m3db/m3/src/x/server"
+
+	"go.uber.org/zap"
+)
+
+// BackendType is the type of backend results are cached in.
+type BackendType string
+
+const (
+	// LocalBackendType caches results in the memory of each coordinator.
+	LocalBackendType BackendType = "local"
+	// MemcachedBackendType caches results in memcached shared by coordinators.
+	MemcachedBackendType BackendType = "memcached"
+	// RedisBackendType caches results in redis shared by coordinators.
+	RedisBackendType BackendType = "redis"
+
+	defaultMaxEntries = 10000
+)
+
+var (
+	errNoClusterClient         = errors.New("result cache invalidation producer requires a cluster client")
+	errSharedCacheInvalidation = errors.New("shared result cache requires an invalidation producer and server")
+)
+
+// Configuration configures caching the results of reads of windows of time
+// that are no longer receiving writes.
+type Configuration struct {
+	// Enabled enables the result cache.
+	Enabled bool `yaml:"enabled"`
+
+	// Backend is the type of backend results are cached in, defaults to local.
+	Backend BackendType `yaml:"backend"`
+
+	// Addresses are the addresses of the memcached or redis servers.
+	Addresses []string `yaml:"addresses"`
+
+	// MaxEntries is the maximum number of results cached by the local backend.
+	MaxEntries int `yaml:"maxEntries"`
+
+	// DialTimeout is the timeout for connecting to a memcached or redis server.
+	DialTimeout *time.Duration `yaml:"dialTimeout"`
+
+	// Timeout is the timeout for a get or set of a memcached or redis server.
+	Timeout *time.Duration `yaml:"timeout"`
+
+	// MaxIdleConns is the maximum number of idle connections kept per
+	// memcached or redis server.
+	MaxIdleConns int `yaml:"maxIdleConns"`
+
+	// TTL is how long results are cached for.
+	TTL *time.Duration `yaml:"ttl"`
+
+	// MinAge is how long ago a query must end for its results to be cached,
+	// writes older than the min age invalidate cached results.
+	MinAge *time.Duration `yaml:"minAge"`
+
+	// MaxEntryBytes is the maximum size of a cached result.
+	MaxEntryBytes int `yaml:"maxEntryBytes"`
+
+	// Invalidation configures invalidating cached results on late writes.
+	Invalidation InvalidationConfiguration `yaml:"invalidation"`
+}
+
+// InvalidationConfiguration configures invalidating cached results on late
+// writes, shared backends broadcast invalidations to the other coordinators
+// over an m3msg topic that has a single shard consumed by every coordinator.
+type InvalidationConfiguration struct {
+	// WindowSize is the size of the windows of time that are invalidated.
+	WindowSize *time.Duration `yaml:"windowSize"`
+
+	// FlushInterval is the interval at which invalidations are broadcast.
+	FlushInterval *time.Duration `yaml:"flushInterval"`
+
+	// Producer configures the producer that broadcasts invalidations.
+	Producer *producerconfig.ProducerConfiguration `yaml:"producer"`
+
+	// Server configures the m3msg server that receives invalidations.
+	Server *server.Configuration `yaml:"server"`
+
+	// Consumer configures the consumer of the m3msg server.
+	Consumer consumer.Configuration `yaml:"consumer"`
+}
+
+// NewStorage returns a storage that caches the results of reads of the
+// primary storage along with a function that closes the cache.
+func (c Configuration) NewStorage(
+	primary storage.Storage,
+	clusterClient clusterclient.Client,
+	instrumentOpts instrument.Options,
+) (storage.Storage, func() error, error) {
+	noop := func() error { return nil }
+	if !c.Enabled {
+		return primary, noop, nil
+	}
+
+	iOpts := instrumentOpts.SetMetricsScope(
+		instrumentOpts.MetricsScope().SubScope("result-cache"))
+	backend, shared, err := c.newBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts := NewOptions().
+		SetBackend(backend).
+		SetInstrumentOptions(instrumentOpts)
+	if c.TTL != nil {
+		opts = opts.SetTTL(*c.TTL)
+	}
+	if c.MinAge != nil {
+		opts = opts.SetMinAge(*c.MinAge)
+	}
+	if c.MaxEntryBytes > 0 {
+		opts = opts.SetMaxEntryBytes(c.MaxEntryBytes)
+	}
+	if err := opts.Validate(); err != nil {
+		backend.Close() // nolint: errcheck
+		return nil, nil, err
+	}
+
+	inv, invServer, err := c.Invalidation.newInvalidator(shared, opts.TTL(),
+		clusterClient, iOpts)
+	if err != nil {
+		backend.Close() // nolint: errcheck
+		return nil, nil, err
+	}
+
+	inv.Start()
+	cleanup := func() error {
+		if invServer != nil {
+			invServer.Close()
+		}
+		inv.Close()
+		if p := inv.opts.Producer; p != nil {
+			p.Close(producer.WaitForConsumption)
+		}
+		return backend.Close()
+	}
+	return NewStorage(primary, opts.SetInvalidator(inv)), cleanup, nil
+}
+
+func (c Configuration) newBackend() (Backend, bool, error) {
+	remoteOpts := RemoteBackendOptions{MaxIdleConns: c.MaxIdleConns}
+	if c.DialTimeout != nil {
+		remoteOpts.DialTimeout = *c.DialTimeout
+	}
+	if c.Timeout != nil {
+		remoteOpts.Timeout = *c.Timeout
+	}
+
+	switch c.Backend {
+	case "", LocalBackendType:
+		maxEntries := c.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultMaxEntries
+		}
+		return NewLocalBackend(maxEntries), false, nil
+	case MemcachedBackendType:
+		backend, err := NewMemcachedBackend(c.Addresses, remoteOpts)
+		return backend, true, err
+	case RedisBackendType:
+		backend, err := NewRedisBackend(c.Addresses, remoteOpts)
+		return backend, true, err
+	default:
+		return nil, false, fmt.Errorf("unknown result cache backend: %s", c.Backend)
+	}
+}
+
+func (c InvalidationConfiguration) newInvalidator(
+	shared bool,
+	retention time.Duration,
+	clusterClient clusterclient.Client,
+	iOpts instrument.Options,
+) (*Invalidator, server.Server, error) {
+	invOpts := InvalidatorOptions{
+		Retention:         retention,
+		InstrumentOptions: iOpts,
+	}
+	if c.WindowSize != nil {
+		invOpts.WindowSize = *c.WindowSize
+	}
+	if c.FlushInterval != nil {
+		invOpts.FlushInterval = *c.FlushInterval
+	}
+	if !shared {
+		// NB: a local cache only needs to apply its own invalidations.
+		return NewInvalidator(invOpts), nil, nil
+	}
+
+	if c.Producer == nil || c.Server == nil {
+		return nil, nil, errSharedCacheInvalidation
+	}
+	if clusterClient == nil {
+		return nil, nil, errNoClusterClient
+	}
+
+	p, err := c.Producer.NewProducer(clusterClient, iOpts, xio.NewOptions())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := p.Init(); err != nil {
+		return nil, nil, err
+	}
+	invOpts.Producer = p
+	inv := NewInvalidator(invOpts)
+
+	handler := consumer.NewMessageHandler(
+		consumer.SingletonMessageProcessor(inv.NewMessageProcessor()),
+		c.Consumer.NewOptions(iOpts))
+	invServer := c.Server.NewServer(handler, iOpts)
+	if err := invServer.ListenAndServe(); err != nil {
+		p.Close(producer.DropEverything)
+		return nil, nil, err
+	}
+
+	iOpts.Logger().Info("result cache invalidation server started",
+		zap.String("address", c.Server.ListenAddress))
+	return inv, invServer, nil
+}
@@ -0,0 +1,347 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/msg/consumer"
+	"github.com/m3db/m3/src/msg/producer"
+	"github.com/m3db/m3/src/x/instrument"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	invalidationMessageVersion = 1
+	// invalidationMessageHeaderSize is the size of the version and the time
+	// results are invalidated until.
+	invalidationMessageHeaderSize = 1 + 8
+
+	defaultWindowSize    = time.Hour
+	defaultFlushInterval = time.Second
+)
+
+var errInvalidInvalidationMessage = errors.New("invalid result cache invalidation message")
+
+// InvalidatorOptions are the options for an invalidator.
+type InvalidatorOptions struct {
+	// WindowSize is the size of the windows of time that are invalidated.
+	WindowSize time.Duration
+	// FlushInterval is the interval at which invalidations are broadcast.
+	FlushInterval time.Duration
+	// Retention is how long invalidations are retained, results cached
+	// longer ago than the retention have expired so need no invalidation.
+	Retention time.Duration
+	// Producer broadcasts invalidations to the other coordinators sharing
+	// the cache, invalidations are only applied locally if not set.
+	Producer producer.Producer
+	// InstrumentOptions are the instrument options.
+	InstrumentOptions instrument.Options
+}
+
+// Invalidator tracks the windows of time that received late writes so that
+// results cached before the late writes arrived are no longer served.
+//
+// Invalidations are broadcast over m3msg to the other coordinators sharing
+// the cache, the invalidation message marks results of the windows cached
+// until the flush after next as invalid. This covers reads that started
+// before the late writes were persisted and cached their results before the
+// invalidation was received.
+type Invalidator struct {
+	sync.RWMutex
+
+	opts        InvalidatorOptions
+	invalidated map[xtime.UnixNano]xtime.UnixNano
+	pending     map[xtime.UnixNano]struct{}
+	metrics     invalidatorMetrics
+	logger      *zap.Logger
+	nowFn       func() time.Time
+
+	closed  bool
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+type invalidatorMetrics struct {
+	invalidated  tally.Counter
+	broadcast    tally.Counter
+	broadcastErr tally.Counter
+	received     tally.Counter
+	receivedErr  tally.Counter
+}
+
+func newInvalidatorMetrics(scope tally.Scope) invalidatorMetrics {
+	return invalidatorMetrics{
+		invalidated:  scope.Counter("invalidated"),
+		broadcast:    scope.Counter("broadcast"),
+		broadcastErr: scope.Counter("broadcast-errors"),
+		received:     scope.Counter("received"),
+		receivedErr:  scope.Counter("received-errors"),
+	}
+}
+
+// NewInvalidator returns a new invalidator, the invalidator must be started
+// to broadcast invalidations and expire old invalidations.
+func NewInvalidator(opts InvalidatorOptions) *Invalidator {
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = defaultWindowSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.InstrumentOptions == nil {
+		opts.InstrumentOptions = instrument.NewOptions()
+	}
+	scope := opts.InstrumentOptions.MetricsScope().SubScope("invalidation")
+	return &Invalidator{
+		opts:        opts,
+		invalidated: make(map[xtime.UnixNano]xtime.UnixNano),
+		pending:     make(map[xtime.UnixNano]struct{}),
+		metrics:     newInvalidatorMetrics(scope),
+		logger:      opts.InstrumentOptions.Logger(),
+		nowFn:       time.Now,
+		closeCh:     make(chan struct{}),
+	}
+}
+
+// Start starts broadcasting invalidations.
+func (i *Invalidator) Start() {
+	i.wg.Add(1)
+	go i.flushLoop()
+}
+
+// Invalidate invalidates the results cached for the window containing the
+// timestamp of a late write.
+func (i *Invalidator) Invalidate(t xtime.UnixNano) {
+	window := t.Truncate(i.opts.WindowSize)
+
+	i.Lock()
+	_, ok := i.pending[window]
+	if !ok {
+		i.pending[window] = struct{}{}
+		i.invalidateWithLock(window, i.invalidatedUntil())
+	}
+	i.Unlock()
+
+	if !ok {
+		i.metrics.invalidated.Inc(1)
+	}
+}
+
+// invalidatedUntil returns the time until which cached results of windows
+// invalidated now are considered invalid.
+func (i *Invalidator) invalidatedUntil() xtime.UnixNano {
+	return xtime.ToUnixNano(i.nowFn().Add(2 * i.opts.FlushInterval))
+}
+
+func (i *Invalidator) invalidateWithLock(window, until xtime.UnixNano) {
+	if existing, ok := i.invalidated[window]; !ok || existing.Before(until) {
+		i.invalidated[window] = until
+	}
+}
+
+// Valid returns whether results for the time range that were cached at the
+// given time are still valid.
+func (i *Invalidator) Valid(start, end, cachedAt xtime.UnixNano) bool {
+	i.RLock()
+	defer i.RUnlock()
+
+	first := start.Truncate(i.opts.WindowSize)
+	if numWindows := int(end.Sub(first)/i.opts.WindowSize) + 1; numWindows > len(i.invalidated) {
+		// NB: fewer invalidations than windows of the range so check each
+		// of the invalidations rather than each of the windows.
+		for window, until := range i.invalidated {
+			if !window.Before(first) && !window.After(end) && !cachedAt.After(until) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for window := first; !window.After(end); window = window.Add(i.opts.WindowSize) {
+		if until, ok := i.invalidated[window]; ok && !cachedAt.After(until) {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *Invalidator) flushLoop() {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(i.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-i.closeCh:
+			i.flush()
+			return
+		case <-ticker.C:
+			i.flush()
+		}
+	}
+}
+
+func (i *Invalidator) flush() {
+	i.Lock()
+	windows := make([]xtime.UnixNano, 0, len(i.pending))
+	for window := range i.pending {
+		windows = append(windows, window)
+		delete(i.pending, window)
+	}
+	until := i.invalidatedUntil()
+	for _, window := range windows {
+		// NB: extend the local invalidation to match the broadcast so that
+		// reads of this coordinator are treated the same as the others.
+		i.invalidateWithLock(window, until)
+	}
+
+	expireBefore := xtime.ToUnixNano(i.nowFn().Add(-i.opts.Retention))
+	for window, invalidatedUntil := range i.invalidated {
+		if invalidatedUntil.Before(expireBefore) {
+			delete(i.invalidated, window)
+		}
+	}
+	i.Unlock()
+
+	if len(windows) == 0 || i.opts.Producer == nil {
+		return
+	}
+
+	msg := newInvalidationMessage(encodeInvalidation(until, windows))
+	if err := i.opts.Producer.Produce(msg); err != nil {
+		i.metrics.broadcastErr.Inc(1)
+		i.logger.Error("could not broadcast result cache invalidation", zap.Error(err))
+		return
+	}
+	i.metrics.broadcast.Inc(1)
+}
+
+// apply applies an invalidation broadcast by another coordinator.
+func (i *Invalidator) apply(b []byte) error {
+	until, windows, err := decodeInvalidation(b)
+	if err != nil {
+		return err
+	}
+
+	i.Lock()
+	for _, window := range windows {
+		i.invalidateWithLock(window, until)
+	}
+	i.Unlock()
+	return nil
+}
+
+// NewMessageProcessor returns a processor that applies the invalidations
+// broadcast by other coordinators.
+func (i *Invalidator) NewMessageProcessor() consumer.MessageProcessor {
+	return &invalidationProcessor{invalidator: i}
+}
+
+// Close stops broadcasting invalidations after broadcasting any pending.
+func (i *Invalidator) Close() {
+	i.Lock()
+	if i.closed {
+		i.Unlock()
+		return
+	}
+	i.closed = true
+	i.Unlock()
+
+	close(i.closeCh)
+	i.wg.Wait()
+}
+
+type invalidationProcessor struct {
+	invalidator *Invalidator
+}
+
+func (p *invalidationProcessor) Process(m consumer.Message) {
+	if err := p.invalidator.apply(m.Bytes()); err != nil {
+		p.invalidator.metrics.receivedErr.Inc(1)
+		p.invalidator.logger.Error("could not apply result cache invalidation", zap.Error(err))
+	} else {
+		p.invalidator.metrics.received.Inc(1)
+	}
+	m.Ack()
+}
+
+func (p *invalidationProcessor) Close() {}
+
+func encodeInvalidation(until xtime.UnixNano, windows []xtime.UnixNano) []byte {
+	b := make([]byte, invalidationMessageHeaderSize+8*len(windows))
+	b[0] = invalidationMessageVersion
+	binary.BigEndian.PutUint64(b[1:], uint64(until))
+	for idx, window := range windows {
+		binary.BigEndian.PutUint64(b[invalidationMessageHeaderSize+8*idx:], uint64(window))
+	}
+	return b
+}
+
+func decodeInvalidation(b []byte) (xtime.UnixNano, []xtime.UnixNano, error) {
+	if len(b) < invalidationMessageHeaderSize || (len(b)-invalidationMessageHeaderSize)%8 != 0 {
+		return 0, nil, errInvalidInvalidationMessage
+	}
+	if b[0] != invalidationMessageVersion {
+		return 0, nil, fmt.Errorf("unsupported result cache invalidation message version: %d", b[0])
+	}
+
+	until := xtime.UnixNano(binary.BigEndian.Uint64(b[1:]))
+	windows := make([]xtime.UnixNano, 0, (len(b)-invalidationMessageHeaderSize)/8)
+	for idx := invalidationMessageHeaderSize; idx < len(b); idx += 8 {
+		windows = append(windows, xtime.UnixNano(binary.BigEndian.Uint64(b[idx:])))
+	}
+	return until, windows, nil
+}
+
+// invalidationMessage is an invalidation broadcast to the other
+// coordinators, all invalidations are produced to the first shard so the
+// topic should have a single shard replicated to every coordinator.
+type invalidationMessage struct {
+	data []byte
+}
+
+var _ producer.Message = (*invalidationMessage)(nil)
+
+func newInvalidationMessage(data []byte) *invalidationMessage {
+	return &invalidationMessage{data: data}
+}
+
+func (m *invalidationMessage) Shard() uint32 {
+	return 0
+}
+
+func (m *invalidationMessage) Bytes() []byte {
+	return m.data
+}
+
+func (m *invalidationMessage) Size() int {
+	return len(m.data)
+}
+
+func (m *invalidationMessage) Finalize(producer.FinalizeReason) {}
@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/msg/consumer"
+	"github.com/m3db/m3/src/msg/producer"
+	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidationMessageRoundTrip(t *testing.T) {
+	windows := []xtime.UnixNano{
+		xtime.UnixNano(time.Hour),
+		xtime.UnixNano(3 * time.Hour),
+	}
+	until, decoded, err := decodeInvalidation(encodeInvalidation(42, windows))
+	require.NoError(t, err)
+	require.Equal(t, xtime.UnixNano(42), until)
+	require.Equal(t, windows, decoded)
+
+	_, _, err = decodeInvalidation([]byte{invalidationMessageVersion, 1, 2})
+	require.Error(t, err)
+
+	b := encodeInvalidation(42, windows)
+	b[0] = invalidationMessageVersion + 1
+	_, _, err = decodeInvalidation(b)
+	require.Error(t, err)
+}
+
+func TestInvalidatorValid(t *testing.T) {
+	var (
+		now = time.Now().Truncate(time.Hour)
+		inv = NewInvalidator(InvalidatorOptions{Retention: time.Hour})
+	)
+	inv.nowFn = func() time.Time { return now }
+
+	start := xtime.ToUnixNano(now.Add(-3 * time.Hour))
+	end := xtime.ToUnixNano(now.Add(-time.Hour))
+	cachedAt := xtime.ToUnixNano(now)
+	require.True(t, inv.Valid(start, end, cachedAt))
+
+	inv.Invalidate(xtime.ToUnixNano(now.Add(-150 * time.Minute)))
+	require.False(t, inv.Valid(start, end, cachedAt))
+	require.False(t, inv.Valid(start, start, cachedAt))
+
+	// Windows that were not invalidated and results cached after the
+	// invalidation remain valid.
+	require.True(t, inv.Valid(end, end, cachedAt))
+	require.True(t, inv.Valid(start, end, cachedAt.Add(time.Minute)))
+
+	// Long ranges check each invalidation rather than each window.
+	require.False(t, inv.Valid(0, end, cachedAt))
+	require.True(t, inv.Valid(0, start.Add(-time.Hour), cachedAt))
+}
+
+func TestInvalidatorBroadcast(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now      = time.Now().Truncate(time.Hour)
+		p        = producer.NewMockProducer(ctrl)
+		produced []byte
+		inv      = NewInvalidator(InvalidatorOptions{
+			Retention: time.Hour,
+			Producer:  p,
+		})
+	)
+	inv.nowFn = func() time.Time { return now }
+
+	p.EXPECT().Produce(gomock.Any()).DoAndReturn(func(m producer.Message) error {
+		require.Equal(t, uint32(0), m.Shard())
+		produced = m.Bytes()
+		return nil
+	})
+
+	late := xtime.ToUnixNano(now.Add(-90 * time.Minute))
+	inv.Invalidate(late)
+	inv.Invalidate(late.Add(time.Minute))
+	inv.flush()
+	require.NotNil(t, produced)
+
+	// Nothing is broadcast when there are no pending invalidations.
+	inv.flush()
+
+	until, windows, err := decodeInvalidation(produced)
+	require.NoError(t, err)
+	require.Equal(t, xtime.ToUnixNano(now.Add(2*time.Second)), until)
+	require.Equal(t, []xtime.UnixNano{late.Truncate(time.Hour)}, windows)
+
+	// Another coordinator applies the broadcast invalidation.
+	other := NewInvalidator(InvalidatorOptions{Retention: time.Hour})
+	msg := consumer.NewMockMessage(ctrl)
+	msg.EXPECT().Bytes().Return(produced)
+	msg.EXPECT().Ack()
+	other.NewMessageProcessor().Process(msg)
+
+	cachedAt := xtime.ToUnixNano(now.Add(time.Second))
+	require.False(t, other.Valid(late, late, cachedAt))
+	require.True(t, other.Valid(late, late, until.Add(time.Nanosecond)))
+}
+
+func TestInvalidatorExpiresInvalidations(t *testing.T) {
+	var (
+		now = time.Now().Truncate(time.Hour)
+		inv = NewInvalidator(InvalidatorOptions{Retention: time.Hour})
+	)
+	inv.nowFn = func() time.Time { return now }
+
+	inv.Invalidate(xtime.ToUnixNano(now.Add(-90 * time.Minute)))
+	inv.flush()
+	require.Len(t, inv.invalidated, 1)
+
+	now = now.Add(2 * time.Hour)
+	inv.flush()
+	require.Len(t, inv.invalidated, 0)
+}
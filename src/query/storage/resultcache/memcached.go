@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// maxMemcachedRelativeExpiry is the largest expiry memcached treats as
+// relative to now, larger values are treated as absolute unix times.
+const maxMemcachedRelativeExpiry = 30 * 24 * time.Hour
+
+var (
+	memcachedEnd    = []byte("END")
+	memcachedStored = []byte("STORED")
+	memcachedValue  = []byte("VALUE ")
+)
+
+// NewMemcachedBackend returns a backend that caches results in memcached,
+// keys are distributed across the servers at the addresses.
+func NewMemcachedBackend(addresses []string, opts RemoteBackendOptions) (Backend, error) {
+	return newRemoteBackend(addresses, memcachedProtocol{}, opts)
+}
+
+// memcachedProtocol implements the memcached text protocol.
+type memcachedProtocol struct{}
+
+func (memcachedProtocol) get(c *conn, key string) ([]byte, bool, error) {
+	if _, err := fmt.Fprintf(c.w, "get %s\r\n", key); err != nil {
+		return nil, false, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, false, err
+	}
+
+	line, err := readLine(c)
+	if err != nil {
+		return nil, false, err
+	}
+	if bytes.Equal(line, memcachedEnd) {
+		return nil, false, nil
+	}
+
+	// VALUE <key> <flags> <bytes>
+	if !bytes.HasPrefix(line, memcachedValue) {
+		return nil, false, fmt.Errorf("unexpected memcached response: %q", line)
+	}
+	fields := bytes.Fields(line)
+	if len(fields) < 4 {
+		return nil, false, fmt.Errorf("unexpected memcached response: %q", line)
+	}
+	size, err := strconv.Atoi(string(fields[3]))
+	if err != nil {
+		return nil, false, fmt.Errorf("unexpected memcached value size: %q", line)
+	}
+
+	value := make([]byte, size+2)
+	if _, err := io.ReadFull(c.r, value); err != nil {
+		return nil, false, err
+	}
+	if line, err = readLine(c); err != nil {
+		return nil, false, err
+	}
+	if !bytes.Equal(line, memcachedEnd) {
+		return nil, false, fmt.Errorf("unexpected memcached response: %q", line)
+	}
+	return value[:size], true, nil
+}
+
+func (memcachedProtocol) set(c *conn, key string, value []byte, ttl time.Duration) error {
+	if ttl > maxMemcachedRelativeExpiry {
+		ttl = maxMemcachedRelativeExpiry
+	}
+	// NB: an expiry of zero never expires so round up to whole seconds.
+	expiry := int64(math.Ceil(ttl.Seconds()))
+	if _, err := fmt.Fprintf(c.w, "set %s 0 %d %d\r\n", key, expiry, len(value)); err != nil {
+		return err
+	}
+	if _, err := c.w.Write(value); err != nil {
+		return err
+	}
+	if _, err := c.w.WriteString("\r\n"); err != nil {
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+
+	line, err := readLine(c)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(line, memcachedStored) {
+		return fmt.Errorf("unexpected memcached response: %q", line)
+	}
+	return nil
+}
+
+// readLine reads a CRLF terminated line, returning it without the CRLF.
+func readLine(c *conn) ([]byte, error) {
+	line, err := c.r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(line, []byte("\r\n")), nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+)
+
+const (
+	defaultTTL           = 10 * time.Minute
+	defaultMinAge        = 5 * time.Minute
+	defaultMaxEntryBytes = 1 << 20
+)
+
+var (
+	errNoBackend            = errors.New("result cache backend not set")
+	errInvalidTTL           = errors.New("result cache TTL must be positive")
+	errInvalidMinAge        = errors.New("result cache min age must not be negative")
+	errInvalidMaxEntryBytes = errors.New("result cache max entry bytes must be positive")
+)
+
+// Options are the options for the result cache.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetBackend sets the backend results are cached in.
+	SetBackend(value Backend) Options
+
+	// Backend returns the backend results are cached in.
+	Backend() Backend
+
+	// SetInvalidator sets the invalidator of results that received late
+	// writes, results are only invalidated by expiring if not set.
+	SetInvalidator(value *Invalidator) Options
+
+	// Invalidator returns the invalidator of results that received late
+	// writes.
+	Invalidator() *Invalidator
+
+	// SetTTL sets how long results are cached for.
+	SetTTL(value time.Duration) Options
+
+	// TTL returns how long results are cached for.
+	TTL() time.Duration
+
+	// SetMinAge sets how long ago a query must end for its results to be
+	// cached, writes older than the min age are late writes.
+	SetMinAge(value time.Duration) Options
+
+	// MinAge returns how long ago a query must end for its results to be
+	// cached.
+	MinAge() time.Duration
+
+	// SetMaxEntryBytes sets the maximum size of a cached result.
+	SetMaxEntryBytes(value int) Options
+
+	// MaxEntryBytes returns the maximum size of a cached result.
+	MaxEntryBytes() int
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+}
+
+type options struct {
+	backend        Backend
+	invalidator    *Invalidator
+	ttl            time.Duration
+	minAge         time.Duration
+	maxEntryBytes  int
+	instrumentOpts instrument.Options
+}
+
+// NewOptions returns new result cache options.
+func NewOptions() Options {
+	return &options{
+		ttl:            defaultTTL,
+		minAge:         defaultMinAge,
+		maxEntryBytes:  defaultMaxEntryBytes,
+		instrumentOpts: instrument.NewOptions(),
+	}
+}
+
+func (o *options) Validate() error {
+	if o.backend == nil {
+		return errNoBackend
+	}
+	if o.ttl <= 0 {
+		return errInvalidTTL
+	}
+	if o.minAge < 0 {
+		return errInvalidMinAge
+	}
+	if o.maxEntryBytes <= 0 {
+		return errInvalidMaxEntryBytes
+	}
+	return nil
+}
+
+func (o *options) SetBackend(value Backend) Options {
+	opts := *o
+	opts.backend = value
+	return &opts
+}
+
+func (o *options) Backend() Backend {
+	return o.backend
+}
+
+func (o *options) SetInvalidator(value *Invalidator) Options {
+	opts := *o
+	opts.invalidator = value
+	return &opts
+}
+
+func (o *options) Invalidator() *Invalidator {
+	return o.invalidator
+}
+
+func (o *options) SetTTL(value time.Duration) Options {
+	opts := *o
+	opts.ttl = value
+	return &opts
+}
+
+func (o *options) TTL() time.Duration {
+	return o.ttl
+}
+
+func (o *options) SetMinAge(value time.Duration) Options {
+	opts := *o
+	opts.minAge = value
+	return &opts
+}
+
+func (o *options) MinAge() time.Duration {
+	return o.minAge
+}
+
+func (o *options) SetMaxEntryBytes(value int) Options {
+	opts := *o
+	opts.maxEntryBytes = value
+	return &opts
+}
+
+func (o *options) MaxEntryBytes() int {
+	return o.maxEntryBytes
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
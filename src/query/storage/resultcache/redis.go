@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// NewRedisBackend returns a backend that caches results in redis, keys are
+// distributed across the servers at the addresses.
+func NewRedisBackend(addresses []string, opts RemoteBackendOptions) (Backend, error) {
+	return newRemoteBackend(addresses, redisProtocol{}, opts)
+}
+
+// redisProtocol implements the subset of the redis serialization protocol
+// required to get and set keys.
+type redisProtocol struct{}
+
+func (redisProtocol) get(c *conn, key string) ([]byte, bool, error) {
+	if err := writeRedisCommand(c, []byte("GET"), []byte(key)); err != nil {
+		return nil, false, err
+	}
+
+	line, err := readLine(c)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(line) == 0 || line[0] != '$' {
+		return nil, false, redisError(line)
+	}
+	size, err := strconv.Atoi(string(line[1:]))
+	if err != nil {
+		return nil, false, fmt.Errorf("unexpected redis bulk string size: %q", line)
+	}
+	if size < 0 {
+		return nil, false, nil
+	}
+
+	value := make([]byte, size+2)
+	if _, err := io.ReadFull(c.r, value); err != nil {
+		return nil, false, err
+	}
+	return value[:size], true, nil
+}
+
+func (redisProtocol) set(c *conn, key string, value []byte, ttl time.Duration) error {
+	ttlMillis := strconv.FormatInt(ttl.Milliseconds(), 10)
+	if err := writeRedisCommand(c, []byte("SET"), []byte(key), value,
+		[]byte("PX"), []byte(ttlMillis)); err != nil {
+		return err
+	}
+
+	line, err := readLine(c)
+	if err != nil {
+		return err
+	}
+	if len(line) == 0 || line[0] != '+' {
+		return redisError(line)
+	}
+	return nil
+}
+
+func writeRedisCommand(c *conn, args ...[]byte) error {
+	if _, err := fmt.Fprintf(c.w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if _, err := fmt.Fprintf(c.w, "$%d\r\n", len(arg)); err != nil {
+			return err
+		}
+		if _, err := c.w.Write(arg); err != nil {
+			return err
+		}
+		if _, err := c.w.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+	return c.w.Flush()
+}
+
+func redisError(line []byte) error {
+	if len(line) > 0 && line[0] == '-' {
+		return fmt.Errorf("redis error: %s", line[1:])
+	}
+	return fmt.Errorf("unexpected redis response: %q", line)
+}
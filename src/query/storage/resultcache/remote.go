@@ -0,0 +1,199 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"bufio"
+	"errors"
+	"hash/fnv"
+	"net"
+	"time"
+
+	"github.com/m3db/m3/src/x/hash/jump"
+)
+
+const (
+	defaultDialTimeout  = time.Second
+	defaultTimeout      = 100 * time.Millisecond
+	defaultMaxIdleConns = 16
+)
+
+var errNoAddresses = errors.New("remote result cache backend requires at least one address")
+
+// RemoteBackendOptions are the options for a backend that caches results in
+// a remote memcached or redis cluster.
+type RemoteBackendOptions struct {
+	// DialTimeout is the timeout for connecting to a server.
+	DialTimeout time.Duration
+	// Timeout is the timeout for a single get or set.
+	Timeout time.Duration
+	// MaxIdleConns is the maximum number of idle connections kept per server.
+	MaxIdleConns int
+}
+
+func (o RemoteBackendOptions) withDefaults() RemoteBackendOptions {
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = defaultDialTimeout
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+	if o.MaxIdleConns <= 0 {
+		o.MaxIdleConns = defaultMaxIdleConns
+	}
+	return o
+}
+
+// protocol implements the requests of a remote cache server.
+type protocol interface {
+	get(c *conn, key string) ([]byte, bool, error)
+	set(c *conn, key string, value []byte, ttl time.Duration) error
+}
+
+type conn struct {
+	net.Conn
+
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+type connPool struct {
+	address string
+	opts    RemoteBackendOptions
+	idle    chan *conn
+}
+
+func newConnPool(address string, opts RemoteBackendOptions) *connPool {
+	return &connPool{
+		address: address,
+		opts:    opts,
+		idle:    make(chan *conn, opts.MaxIdleConns),
+	}
+}
+
+func (p *connPool) get() (*conn, error) {
+	select {
+	case c := <-p.idle:
+		return c, nil
+	default:
+	}
+
+	c, err := net.DialTimeout("tcp", p.address, p.opts.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, r: bufio.NewReader(c), w: bufio.NewWriter(c)}, nil
+}
+
+func (p *connPool) put(c *conn) {
+	select {
+	case p.idle <- c:
+	default:
+		c.Close() // nolint: errcheck
+	}
+}
+
+// do runs the request on a pooled connection, connections that fail a
+// request are closed rather than returned to the pool since they may be
+// left with a partially read response.
+func (p *connPool) do(fn func(c *conn) error) error {
+	c, err := p.get()
+	if err != nil {
+		return err
+	}
+	if err := c.SetDeadline(time.Now().Add(p.opts.Timeout)); err != nil {
+		c.Close() // nolint: errcheck
+		return err
+	}
+	if err := fn(c); err != nil {
+		c.Close() // nolint: errcheck
+		return err
+	}
+	p.put(c)
+	return nil
+}
+
+func (p *connPool) close() {
+	for {
+		select {
+		case c := <-p.idle:
+			c.Close() // nolint: errcheck
+		default:
+			return
+		}
+	}
+}
+
+type remoteBackend struct {
+	pools    []*connPool
+	protocol protocol
+}
+
+func newRemoteBackend(
+	addresses []string,
+	protocol protocol,
+	opts RemoteBackendOptions,
+) (Backend, error) {
+	if len(addresses) == 0 {
+		return nil, errNoAddresses
+	}
+
+	opts = opts.withDefaults()
+	pools := make([]*connPool, 0, len(addresses))
+	for _, addr := range addresses {
+		pools = append(pools, newConnPool(addr, opts))
+	}
+	return &remoteBackend{pools: pools, protocol: protocol}, nil
+}
+
+// pool returns the pool of the server that owns the key, keys are
+// distributed with a consistent hash so that few keys move between servers
+// when servers are added to the end of the list of addresses.
+func (b *remoteBackend) pool(key string) *connPool {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return b.pools[jump.Hash(h.Sum64(), int64(len(b.pools)))]
+}
+
+func (b *remoteBackend) Get(key string) ([]byte, bool, error) {
+	var (
+		value []byte
+		found bool
+	)
+	err := b.pool(key).do(func(c *conn) error {
+		var err error
+		value, found, err = b.protocol.get(c, key)
+		return err
+	})
+	return value, found, err
+}
+
+func (b *remoteBackend) Set(key string, value []byte, ttl time.Duration) error {
+	return b.pool(key).do(func(c *conn) error {
+		return b.protocol.set(c, key, value, ttl)
+	})
+}
+
+func (b *remoteBackend) Close() error {
+	for _, p := range b.pools {
+		p.close()
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer is a cache server that serves each connection with a
+// protocol specific handler backed by an in memory map.
+type fakeServer struct {
+	sync.Mutex
+
+	listener net.Listener
+	values   map[string][]byte
+	ttls     map[string]string
+}
+
+func newFakeServer(
+	t *testing.T,
+	handle func(s *fakeServer, r *bufio.Reader, w *bufio.Writer) error,
+) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeServer{
+		listener: listener,
+		values:   make(map[string][]byte),
+		ttls:     make(map[string]string),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+				for handle(s, r, w) == nil {
+					if err := w.Flush(); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeServer) get(key string) ([]byte, bool) {
+	s.Lock()
+	defer s.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func (s *fakeServer) set(key string, value []byte, ttl string) {
+	s.Lock()
+	defer s.Unlock()
+	s.values[key] = value
+	s.ttls[key] = ttl
+}
+
+func readFakeLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	return strings.TrimSuffix(line, "\r\n"), err
+}
+
+func handleMemcached(s *fakeServer, r *bufio.Reader, w *bufio.Writer) error {
+	line, err := readFakeLine(r)
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "get":
+		if v, ok := s.get(fields[1]); ok {
+			fmt.Fprintf(w, "VALUE %s 0 %d\r\n%s\r\n", fields[1], len(v), v)
+		}
+		_, err = w.WriteString("END\r\n")
+	case "set":
+		size, _ := strconv.Atoi(fields[4])
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return err
+		}
+		s.set(fields[1], value[:size], fields[3])
+		_, err = w.WriteString("STORED\r\n")
+	default:
+		_, err = w.WriteString("ERROR\r\n")
+	}
+	return err
+}
+
+func handleRedis(s *fakeServer, r *bufio.Reader, w *bufio.Writer) error {
+	line, err := readFakeLine(r)
+	if err != nil {
+		return err
+	}
+	numArgs, _ := strconv.Atoi(line[1:])
+	args := make([][]byte, 0, numArgs)
+	for i := 0; i < numArgs; i++ {
+		if line, err = readFakeLine(r); err != nil {
+			return err
+		}
+		size, _ := strconv.Atoi(line[1:])
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return err
+		}
+		args = append(args, arg[:size])
+	}
+
+	switch string(args[0]) {
+	case "GET":
+		v, ok := s.get(string(args[1]))
+		if !ok {
+			_, err = w.WriteString("$-1\r\n")
+			return err
+		}
+		_, err = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case "SET":
+		s.set(string(args[1]), args[2], string(args[4]))
+		_, err = w.WriteString("+OK\r\n")
+	default:
+		_, err = w.WriteString("-ERR unknown command\r\n")
+	}
+	return err
+}
+
+func TestRemoteBackends(t *testing.T) {
+	tests := []struct {
+		name        string
+		handle      func(s *fakeServer, r *bufio.Reader, w *bufio.Writer) error
+		newBackend  func(addresses []string, opts RemoteBackendOptions) (Backend, error)
+		expectedTTL string
+	}{
+		{
+			name:        "memcached",
+			handle:      handleMemcached,
+			newBackend:  NewMemcachedBackend,
+			expectedTTL: "2",
+		},
+		{
+			name:        "redis",
+			handle:      handleRedis,
+			newBackend:  NewRedisBackend,
+			expectedTTL: "1500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := []*fakeServer{newFakeServer(t, tt.handle), newFakeServer(t, tt.handle)}
+			backend, err := tt.newBackend([]string{
+				servers[0].listener.Addr().String(),
+				servers[1].listener.Addr().String(),
+			}, RemoteBackendOptions{Timeout: time.Second})
+			require.NoError(t, err)
+			defer backend.Close()
+
+			_, ok, err := backend.Get("missing")
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			// Values may contain the protocol delimiters.
+			value := []byte("some\r\nvalue\r\nEND\r\n")
+			for i := 0; i < 10; i++ {
+				key := fmt.Sprintf("key-%d", i)
+				require.NoError(t, backend.Set(key, value, 1500*time.Millisecond))
+
+				actual, ok, err := backend.Get(key)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, value, actual)
+			}
+
+			// Keys are distributed across the servers.
+			for _, s := range servers {
+				s.Lock()
+				require.NotEmpty(t, s.values)
+				for _, ttl := range s.ttls {
+					require.Equal(t, tt.expectedTTL, ttl)
+				}
+				s.Unlock()
+			}
+		})
+	}
+}
+
+func TestRemoteBackendRequiresAddresses(t *testing.T) {
+	_, err := NewMemcachedBackend(nil, RemoteBackendOptions{})
+	require.Equal(t, errNoAddresses, err)
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+// Package resultcache provides a storage that caches the results of
+// Prometheus reads of windows of time that are no longer receiving writes.
+// The cache is either local to a coordinator or shared by coordinators in
+// memcached or redis, in which case late writes received by any coordinator
+// invalidate the cached results of all coordinators via m3msg broadcasts.
+package resultcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/storage"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	keyPrefix         = "m3query:result:"
+	entryVersion      = 1
+	entryKeepNaNsFlag = 1
+)
+
+var errInvalidEntry = errors.New("invalid result cache entry")
+
+type cachingStorage struct {
+	storage.Storage
+
+	opts    Options
+	metrics cacheMetrics
+	logger  *zap.Logger
+	nowFn   func() time.Time
+}
+
+type cacheMetrics struct {
+	uncacheable tally.Counter
+	hits        tally.Counter
+	misses      tally.Counter
+	invalidated tally.Counter
+	stored      tally.Counter
+	skipped     tally.Counter
+	tooLarge    tally.Counter
+	errors      tally.Counter
+	lateWrites  tally.Counter
+}
+
+func newCacheMetrics(scope tally.Scope) cacheMetrics {
+	return cacheMetrics{
+		uncacheable: scope.Counter("uncacheable"),
+		hits:        scope.Counter("hits"),
+		misses:      scope.Counter("misses"),
+		invalidated: scope.Counter("invalidated-hits"),
+		stored:      scope.Counter("stored"),
+		skipped:     scope.Counter("skipped"),
+		tooLarge:    scope.Counter("too-large"),
+		errors:      scope.Counter("errors"),
+		lateWrites:  scope.Counter("late-writes"),
+	}
+}
+
+// NewStorage returns a storage that caches the results of Prometheus reads
+// of the underlying storage that end at least the min age ago. Writes older
+// than the min age invalidate the cached results of their window of time.
+// Errors of the cache backend are not returned, reads fall through to the
+// underlying storage instead.
+func NewStorage(underlying storage.Storage, opts Options) storage.Storage {
+	iOpts := opts.InstrumentOptions()
+	return &cachingStorage{
+		Storage: underlying,
+		opts:    opts,
+		metrics: newCacheMetrics(iOpts.MetricsScope().SubScope("result-cache")),
+		logger:  iOpts.Logger(),
+		nowFn:   time.Now,
+	}
+}
+
+func (s *cachingStorage) FetchProm(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (storage.PromResult, error) {
+	now := s.nowFn()
+	if query.End.After(now.Add(-s.opts.MinAge())) {
+		s.metrics.uncacheable.Inc(1)
+		return s.Storage.FetchProm(ctx, query, options)
+	}
+
+	key := cacheKey(query, options)
+	if result, ok := s.get(key, query); ok {
+		s.metrics.hits.Inc(1)
+		return result, nil
+	}
+
+	s.metrics.misses.Inc(1)
+	result, err := s.Storage.FetchProm(ctx, query, options)
+	if err != nil {
+		return result, err
+	}
+
+	// NB: the result is cached as of the start of the read so that late
+	// writes that arrived during the read invalidate it.
+	s.set(key, now, result)
+	return result, nil
+}
+
+func (s *cachingStorage) get(key string, query *storage.FetchQuery) (storage.PromResult, bool) {
+	value, ok, err := s.opts.Backend().Get(key)
+	if err != nil {
+		s.metrics.errors.Inc(1)
+		s.logger.Debug("could not get cached result", zap.Error(err))
+		return storage.PromResult{}, false
+	}
+	if !ok {
+		return storage.PromResult{}, false
+	}
+
+	cachedAt, result, err := decodeEntry(value)
+	if err != nil {
+		s.metrics.errors.Inc(1)
+		s.logger.Debug("could not decode cached result", zap.Error(err))
+		return storage.PromResult{}, false
+	}
+
+	if inv := s.opts.Invalidator(); inv != nil &&
+		!inv.Valid(xtime.ToUnixNano(query.Start), xtime.ToUnixNano(query.End), cachedAt) {
+		s.metrics.invalidated.Inc(1)
+		return storage.PromResult{}, false
+	}
+	return result, true
+}
+
+func (s *cachingStorage) set(key string, cachedAt time.Time, result storage.PromResult) {
+	// NB: only cache complete results, partial results should be retried.
+	if result.PromResult == nil || !result.Metadata.Exhaustive ||
+		len(result.Metadata.Warnings) > 0 {
+		s.metrics.skipped.Inc(1)
+		return
+	}
+
+	value, err := encodeEntry(xtime.ToUnixNano(cachedAt), result)
+	if err != nil {
+		s.metrics.errors.Inc(1)
+		s.logger.Debug("could not encode result", zap.Error(err))
+		return
+	}
+	if len(value) > s.opts.MaxEntryBytes() {
+		s.metrics.tooLarge.Inc(1)
+		return
+	}
+
+	if err := s.opts.Backend().Set(key, value, s.opts.TTL()); err != nil {
+		s.metrics.errors.Inc(1)
+		s.logger.Debug("could not cache result", zap.Error(err))
+		return
+	}
+	s.metrics.stored.Inc(1)
+}
+
+func (s *cachingStorage) Write(ctx context.Context, query *storage.WriteQuery) error {
+	if inv := s.opts.Invalidator(); inv != nil {
+		lateBefore := xtime.ToUnixNano(s.nowFn().Add(-s.opts.MinAge()))
+		for _, dp := range query.Datapoints() {
+			if !dp.Timestamp.After(lateBefore) {
+				s.metrics.lateWrites.Inc(1)
+				inv.Invalidate(dp.Timestamp)
+			}
+		}
+	}
+	return s.Storage.Write(ctx, query)
+}
+
+func (s *cachingStorage) Name() string {
+	return "result-cache:" + s.Storage.Name()
+}
+
+// cacheKey returns the key of the results of the query, the key covers
+// each of the options that changes the results returned by the storage.
+func cacheKey(query *storage.FetchQuery, options *storage.FetchOptions) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%d|%d|%d", query.TagMatchers.String(),
+		query.Start.UnixNano(), query.End.UnixNano(), query.Interval)
+	if options != nil {
+		fmt.Fprintf(h, "|%d|%d|%d|%d|%d|%v", options.SeriesLimit,
+			options.DocsLimit, options.ReturnedSeriesLimit,
+			options.ReturnedDatapointsLimit, options.RangeLimit,
+			options.RequireExhaustive)
+		if options.Sampled() {
+			fmt.Fprintf(h, "|sample=%v", options.SampleRate)
+		}
+		if v := options.LookbackDuration; v != nil {
+			fmt.Fprintf(h, "|lookback=%d", *v)
+		}
+		if v := options.FanoutOptions; v != nil {
+			fmt.Fprintf(h, "|fanout=%v", *v)
+		}
+		if v := options.StitchingOptions; v != nil {
+			fmt.Fprintf(h, "|stitching=%v/%d", v.Policy, v.Cutover)
+		}
+		if r := options.RestrictQueryOptions; r != nil {
+			if v := r.RestrictByType; v != nil {
+				fmt.Fprintf(h, "|type=%v/%v", v.MetricsType, v.StoragePolicy)
+			}
+			for _, v := range r.RestrictByTypes {
+				fmt.Fprintf(h, "|types=%v/%v", v.MetricsType, v.StoragePolicy)
+			}
+			if v := r.RestrictByNamespace; v != nil {
+				fmt.Fprintf(h, "|namespace=%s", v.Namespace)
+			}
+			if v := r.RestrictByTag; v != nil {
+				fmt.Fprintf(h, "|tag=%s/%q", v.Restrict.String(), v.Strip)
+			}
+		}
+	}
+	return keyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// encodeEntry encodes the result with the time it was cached, the entry is
+// the version, the cached at time, the flags and resolutions of the result
+// metadata followed by the result in protobuf format.
+func encodeEntry(cachedAt xtime.UnixNano, result storage.PromResult) ([]byte, error) {
+	data, err := result.PromResult.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	var flags byte
+	if result.Metadata.KeepNaNs {
+		flags |= entryKeepNaNsFlag
+	}
+
+	resolutions := result.Metadata.Resolutions
+	b := make([]byte, 1+8+1+binary.MaxVarintLen64*(1+len(resolutions))+len(data))
+	b[0] = entryVersion
+	binary.BigEndian.PutUint64(b[1:], uint64(cachedAt))
+	b[9] = flags
+	n := 10
+	n += binary.PutUvarint(b[n:], uint64(len(resolutions)))
+	for _, res := range resolutions {
+		n += binary.PutVarint(b[n:], int64(res))
+	}
+	n += copy(b[n:], data)
+	return b[:n], nil
+}
+
+func decodeEntry(b []byte) (xtime.UnixNano, storage.PromResult, error) {
+	if len(b) < 1+8+1 || b[0] != entryVersion {
+		return 0, storage.PromResult{}, errInvalidEntry
+	}
+
+	cachedAt := xtime.UnixNano(binary.BigEndian.Uint64(b[1:]))
+	meta := block.NewResultMetadata()
+	meta.KeepNaNs = b[9]&entryKeepNaNsFlag != 0
+	b = b[10:]
+
+	numResolutions, n := binary.Uvarint(b)
+	if n <= 0 || numResolutions > uint64(len(b)) {
+		return 0, storage.PromResult{}, errInvalidEntry
+	}
+	b = b[n:]
+	for i := uint64(0); i < numResolutions; i++ {
+		res, n := binary.Varint(b)
+		if n <= 0 {
+			return 0, storage.PromResult{}, errInvalidEntry
+		}
+		meta.Resolutions = append(meta.Resolutions, time.Duration(res))
+		b = b[n:]
+	}
+
+	var result prompb.QueryResult
+	if err := result.Unmarshal(b); err != nil {
+		return 0, storage.PromResult{}, err
+	}
+	return cachedAt, storage.PromResult{PromResult: &result, Metadata: meta}, nil
+}
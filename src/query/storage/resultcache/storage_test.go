@@ -0,0 +1,287 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package resultcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/x/instrument"
+	xtest "github.com/m3db/m3/src/x/test"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func testResult(samples ...prompb.Sample) storage.PromResult {
+	meta := block.NewResultMetadata()
+	meta.Resolutions = []time.Duration{time.Minute}
+	return storage.PromResult{
+		PromResult: &prompb.QueryResult{
+			Timeseries: []*prompb.TimeSeries{{
+				Labels: []prompb.Label{
+					{Name: []byte("__name__"), Value: []byte("a")},
+				},
+				Samples: samples,
+			}},
+		},
+		Metadata: meta,
+	}
+}
+
+func newTestStorage(
+	t *testing.T,
+	underlying storage.Storage,
+	now time.Time,
+	scope tally.Scope,
+) *cachingStorage {
+	inv := NewInvalidator(InvalidatorOptions{
+		Retention:         time.Hour,
+		InstrumentOptions: instrument.NewOptions().SetMetricsScope(scope),
+	})
+	inv.nowFn = func() time.Time { return now }
+
+	opts := NewOptions().
+		SetBackend(NewLocalBackend(10)).
+		SetInvalidator(inv).
+		SetMinAge(time.Minute).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+	require.NoError(t, opts.Validate())
+
+	s := NewStorage(underlying, opts).(*cachingStorage)
+	s.nowFn = func() time.Time { return now }
+	return s
+}
+
+func TestCachingStorageFetchProm(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ctx        = context.Background()
+		now        = time.Now().Truncate(time.Hour)
+		underlying = storage.NewMockStorage(ctrl)
+		scope      = tally.NewTestScope("", nil)
+		s          = newTestStorage(t, underlying, now, scope)
+		query      = &storage.FetchQuery{
+			TagMatchers: models.Matchers{{
+				Type:  models.MatchEqual,
+				Name:  []byte("__name__"),
+				Value: []byte("a"),
+			}},
+			Start: now.Add(-2 * time.Hour),
+			End:   now.Add(-time.Hour),
+		}
+		expected = testResult(prompb.Sample{Timestamp: 1, Value: 1})
+	)
+
+	underlying.EXPECT().FetchProm(ctx, query, gomock.Any()).Return(expected, nil)
+	for i := 0; i < 2; i++ {
+		result, err := s.FetchProm(ctx, query, storage.NewFetchOptions())
+		require.NoError(t, err)
+		require.Equal(t, expected.PromResult, result.PromResult)
+		require.Equal(t, expected.Metadata.Resolutions, result.Metadata.Resolutions)
+		require.True(t, result.Metadata.Exhaustive)
+	}
+
+	// Different options are cached separately.
+	opts := storage.NewFetchOptions()
+	opts.SeriesLimit = 1
+	underlying.EXPECT().FetchProm(ctx, query, gomock.Any()).Return(expected, nil)
+	_, err := s.FetchProm(ctx, query, opts)
+	require.NoError(t, err)
+
+	// Sampled queries are cached separately from each other and from
+	// unsampled queries.
+	for _, rate := range []float64{0.5, 0.25} {
+		opts := storage.NewFetchOptions()
+		opts.SampleRate = rate
+		underlying.EXPECT().FetchProm(ctx, query, gomock.Any()).Return(expected, nil)
+		_, err := s.FetchProm(ctx, query, opts)
+		require.NoError(t, err)
+	}
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["result-cache.hits+"].Value())
+	require.Equal(t, int64(4), counters["result-cache.misses+"].Value())
+	require.Equal(t, int64(4), counters["result-cache.stored+"].Value())
+}
+
+func TestCachingStorageFetchPromStitchingOptions(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ctx        = context.Background()
+		now        = time.Now().Truncate(time.Hour)
+		underlying = storage.NewMockStorage(ctrl)
+		scope      = tally.NewTestScope("", nil)
+		s          = newTestStorage(t, underlying, now, scope)
+		query      = &storage.FetchQuery{
+			Start: now.Add(-2 * time.Hour),
+			End:   now.Add(-time.Hour),
+		}
+		cutover = xtime.ToUnixNano(now.Add(-90 * time.Minute))
+	)
+
+	// Queries differing only by their stitching options miss each other's
+	// cached results.
+	optsFn := []func() *storage.FetchOptions{
+		storage.NewFetchOptions,
+		func() *storage.FetchOptions {
+			opts := storage.NewFetchOptions()
+			opts.StitchingOptions = &storage.StitchingOptions{Policy: storage.StitchingCutover}
+			return opts
+		},
+		func() *storage.FetchOptions {
+			opts := storage.NewFetchOptions()
+			opts.StitchingOptions = &storage.StitchingOptions{
+				Policy:  storage.StitchingCutover,
+				Cutover: cutover,
+			}
+			return opts
+		},
+	}
+	for i, fn := range optsFn {
+		expected := testResult(prompb.Sample{Timestamp: int64(i), Value: float64(i)})
+		underlying.EXPECT().FetchProm(ctx, query, gomock.Any()).Return(expected, nil)
+		result, err := s.FetchProm(ctx, query, fn())
+		require.NoError(t, err)
+		require.Equal(t, expected.PromResult, result.PromResult)
+	}
+
+	// Each stitching option is served from its own entry.
+	for i, fn := range optsFn {
+		result, err := s.FetchProm(ctx, query, fn())
+		require.NoError(t, err)
+		require.Equal(t, testResult(prompb.Sample{Timestamp: int64(i), Value: float64(i)}).PromResult,
+			result.PromResult)
+	}
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(3), counters["result-cache.hits+"].Value())
+	require.Equal(t, int64(3), counters["result-cache.misses+"].Value())
+}
+
+func TestCachingStorageFetchPromUncacheable(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ctx        = context.Background()
+		now        = time.Now()
+		underlying = storage.NewMockStorage(ctrl)
+		scope      = tally.NewTestScope("", nil)
+		s          = newTestStorage(t, underlying, now, scope)
+		recent     = &storage.FetchQuery{Start: now.Add(-time.Hour), End: now}
+		old        = &storage.FetchQuery{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}
+	)
+
+	// Results of recent windows are never cached.
+	underlying.EXPECT().FetchProm(ctx, recent, gomock.Any()).Return(testResult(), nil).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := s.FetchProm(ctx, recent, storage.NewFetchOptions())
+		require.NoError(t, err)
+	}
+
+	// Partial results are not cached.
+	partial := testResult()
+	partial.Metadata.Exhaustive = false
+	underlying.EXPECT().FetchProm(ctx, old, gomock.Any()).Return(partial, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := s.FetchProm(ctx, old, storage.NewFetchOptions())
+		require.NoError(t, err)
+	}
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(2), counters["result-cache.uncacheable+"].Value())
+	require.Equal(t, int64(2), counters["result-cache.skipped+"].Value())
+}
+
+func TestCachingStorageLateWriteInvalidates(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ctx        = context.Background()
+		now        = time.Now().Truncate(time.Hour)
+		underlying = storage.NewMockStorage(ctrl)
+		scope      = tally.NewTestScope("", nil)
+		s          = newTestStorage(t, underlying, now, scope)
+		query      = &storage.FetchQuery{
+			Start: now.Add(-2 * time.Hour),
+			End:   now.Add(-time.Hour),
+		}
+	)
+
+	underlying.EXPECT().FetchProm(ctx, query, gomock.Any()).Return(testResult(), nil)
+	_, err := s.FetchProm(ctx, query, storage.NewFetchOptions())
+	require.NoError(t, err)
+
+	write, err := storage.NewWriteQuery(storage.WriteQueryOptions{
+		Tags: models.Tags{
+			Opts: models.NewTagOptions(),
+			Tags: []models.Tag{{Name: []byte("__name__"), Value: []byte("a")}},
+		},
+		Datapoints: ts.Datapoints{
+			{Timestamp: xtime.ToUnixNano(now.Add(-90 * time.Minute)), Value: 1},
+			{Timestamp: xtime.ToUnixNano(now), Value: 1},
+		},
+		Unit: xtime.Millisecond,
+	})
+	require.NoError(t, err)
+	underlying.EXPECT().Write(ctx, write).Return(nil)
+	require.NoError(t, s.Write(ctx, write))
+
+	// Results cached before the late write are no longer served.
+	underlying.EXPECT().FetchProm(ctx, query, gomock.Any()).Return(testResult(), nil)
+	_, err = s.FetchProm(ctx, query, storage.NewFetchOptions())
+	require.NoError(t, err)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["result-cache.late-writes+"].Value())
+	require.Equal(t, int64(1), counters["result-cache.invalidated-hits+"].Value())
+}
+
+func TestEntryRoundTrip(t *testing.T) {
+	result := testResult(prompb.Sample{Timestamp: 1, Value: 2})
+	result.Metadata.KeepNaNs = true
+
+	b, err := encodeEntry(42, result)
+	require.NoError(t, err)
+
+	cachedAt, decoded, err := decodeEntry(b)
+	require.NoError(t, err)
+	require.Equal(t, xtime.UnixNano(42), cachedAt)
+	require.Equal(t, result.PromResult, decoded.PromResult)
+	require.Equal(t, result.Metadata.Resolutions, decoded.Metadata.Resolutions)
+	require.True(t, decoded.Metadata.KeepNaNs)
+
+	_, _, err = decodeEntry(b[:5])
+	require.Error(t, err)
+}
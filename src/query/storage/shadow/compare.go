@@ -0,0 +1,193 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package shadow
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+)
+
+type mismatchType string
+
+const (
+	// missingSeriesMismatch is a series of the primary result absent from the
+	// shadow result.
+	missingSeriesMismatch mismatchType = "missing_series"
+	// extraSeriesMismatch is a series of the shadow result absent from the
+	// primary result.
+	extraSeriesMismatch mismatchType = "extra_series"
+	// sampleMismatch is a sample present in only one of the results or with
+	// values that differ beyond the tolerance.
+	sampleMismatch mismatchType = "sample"
+)
+
+var mismatchTypes = []mismatchType{
+	missingSeriesMismatch,
+	extraSeriesMismatch,
+	sampleMismatch,
+}
+
+// seriesSet holds a copy of the samples of a result keyed by series labels,
+// the primary result is handed back to the caller and may be reused before
+// the shadow read completes.
+type seriesSet map[string][]prompb.Sample
+
+func newSeriesSet(result *prompb.QueryResult) seriesSet {
+	set := make(seriesSet, len(result.GetTimeseries()))
+	for _, s := range result.GetTimeseries() {
+		samples := make([]prompb.Sample, len(s.Samples))
+		copy(samples, s.Samples)
+		set[seriesKey(s.Labels)] = samples
+	}
+	return set
+}
+
+func seriesKey(labels []prompb.Label) string {
+	pairs := make([]string, 0, len(labels))
+	for _, l := range labels {
+		pairs = append(pairs, string(l.Name)+"="+string(l.Value))
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// mismatch describes a single difference between the results.
+type mismatch struct {
+	mismatchType mismatchType
+	series       string
+	timestamp    int64
+	expected     float64
+	actual       float64
+}
+
+// diff is the difference between the primary and shadow results.
+type diff struct {
+	counts   map[mismatchType]int
+	exemplar *mismatch
+}
+
+func (d *diff) add(m mismatch) {
+	d.counts[m.mismatchType]++
+	if d.exemplar == nil {
+		d.exemplar = &m
+	}
+}
+
+func (d diff) matches() bool {
+	return d.exemplar == nil
+}
+
+// compare returns the difference between the expected primary result and the
+// actual shadow result.
+func compare(expected, actual seriesSet, tolerance Tolerance) diff {
+	d := diff{counts: make(map[mismatchType]int, len(mismatchTypes))}
+
+	// Iterate in key order so that the exemplar is deterministic.
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		actualSamples, ok := actual[key]
+		if !ok {
+			d.add(mismatch{mismatchType: missingSeriesMismatch, series: key})
+			continue
+		}
+		compareSamples(&d, key, expected[key], actualSamples, tolerance)
+	}
+
+	var extra []string
+	for key := range actual {
+		if _, ok := expected[key]; !ok {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		d.add(mismatch{mismatchType: extraSeriesMismatch, series: key})
+	}
+
+	return d
+}
+
+func compareSamples(
+	d *diff,
+	series string,
+	expected, actual []prompb.Sample,
+	tolerance Tolerance,
+) {
+	var i, j int
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case j == len(actual) ||
+			(i < len(expected) && expected[i].Timestamp < actual[j].Timestamp):
+			d.add(mismatch{
+				mismatchType: sampleMismatch,
+				series:       series,
+				timestamp:    expected[i].Timestamp,
+				expected:     expected[i].Value,
+				actual:       math.NaN(),
+			})
+			i++
+		case i == len(expected) || actual[j].Timestamp < expected[i].Timestamp:
+			d.add(mismatch{
+				mismatchType: sampleMismatch,
+				series:       series,
+				timestamp:    actual[j].Timestamp,
+				expected:     math.NaN(),
+				actual:       actual[j].Value,
+			})
+			j++
+		default:
+			if !tolerance.equal(expected[i].Value, actual[j].Value) {
+				d.add(mismatch{
+					mismatchType: sampleMismatch,
+					series:       series,
+					timestamp:    expected[i].Timestamp,
+					expected:     expected[i].Value,
+					actual:       actual[j].Value,
+				})
+			}
+			i++
+			j++
+		}
+	}
+}
+
+func (t Tolerance) equal(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	if a == b {
+		// NB: handles infinities of the same sign.
+		return true
+	}
+	delta := math.Abs(a - b)
+	if delta <= t.Absolute {
+		return true
+	}
+	return delta <= t.Relative*math.Max(math.Abs(a), math.Abs(b))
+}
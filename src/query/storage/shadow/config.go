@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package shadow
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/sampler"
+)
+
+const (
+	defaultTimeout             = time.Minute
+	defaultMaxConcurrency      = 8
+	defaultExemplarLogInterval = 10 * time.Second
+)
+
+var (
+	errNoShadowTarget        = errors.New("shadow read requires either a namespace or remote addresses")
+	errMultipleShadowTargets = errors.New("shadow read requires only one of a namespace or remote addresses")
+)
+
+// Configuration configures mirroring a sample of reads to a shadow
+// namespace or cluster and comparing the results with the primary results.
+type Configuration struct {
+	// Enabled enables shadow reads.
+	Enabled bool `yaml:"enabled"`
+
+	// SampleRate is the fraction of reads mirrored to the shadow target.
+	SampleRate sampler.Rate `yaml:"sampleRate"`
+
+	// Namespace mirrors reads to a namespace of the local cluster.
+	Namespace *NamespaceConfiguration `yaml:"namespace"`
+
+	// RemoteAddresses mirrors reads to the coordinators of another cluster
+	// over gRPC.
+	RemoteAddresses []string `yaml:"remoteAddresses"`
+
+	// Tolerance is the tolerance within which sample values are considered
+	// to match.
+	Tolerance Tolerance `yaml:"tolerance"`
+
+	// Timeout is the timeout for a shadow read.
+	Timeout *time.Duration `yaml:"timeout"`
+
+	// MaxConcurrency is the maximum number of in flight shadow reads, reads
+	// sampled while at the limit are skipped.
+	MaxConcurrency int `yaml:"maxConcurrency"`
+
+	// ExemplarLogInterval is the minimum interval between logging exemplar
+	// mismatches.
+	ExemplarLogInterval *time.Duration `yaml:"exemplarLogInterval"`
+}
+
+// NamespaceConfiguration is the namespace of the local cluster that reads
+// are mirrored to.
+type NamespaceConfiguration struct {
+	// MetricsType is the metrics type of the namespace.
+	MetricsType storagemetadata.MetricsType `yaml:"metricsType"`
+
+	// StoragePolicy is the storage policy of the namespace, required for
+	// aggregated namespaces.
+	StoragePolicy policy.StoragePolicy `yaml:"storagePolicy"`
+}
+
+// Tolerance is the tolerance within which sample values are considered to
+// match, values match if within either the absolute or relative tolerance.
+type Tolerance struct {
+	// Absolute is the absolute difference tolerated.
+	Absolute float64 `yaml:"absolute"`
+
+	// Relative is the difference tolerated relative to the larger magnitude
+	// of the two values.
+	Relative float64 `yaml:"relative"`
+}
+
+// NewStorage returns a storage that mirrors a sample of the reads of the
+// primary storage. The remote storage is the shadow target when remote
+// addresses are configured, otherwise reads are mirrored to the configured
+// namespace of the primary storage.
+func (c Configuration) NewStorage(
+	primary storage.Storage,
+	remote storage.Storage,
+	instrumentOpts instrument.Options,
+) (storage.Storage, error) {
+	if !c.Enabled {
+		return primary, nil
+	}
+
+	if c.Namespace == nil && len(c.RemoteAddresses) == 0 {
+		return nil, errNoShadowTarget
+	}
+	if c.Namespace != nil && len(c.RemoteAddresses) > 0 {
+		return nil, errMultipleShadowTargets
+	}
+
+	readSampler, err := sampler.NewSampler(c.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := NewOptions().
+		SetSampler(readSampler).
+		SetTolerance(c.Tolerance).
+		SetInstrumentOptions(instrumentOpts)
+	if c.Timeout != nil {
+		opts = opts.SetTimeout(*c.Timeout)
+	}
+	if c.MaxConcurrency > 0 {
+		opts = opts.SetMaxConcurrency(c.MaxConcurrency)
+	}
+	if c.ExemplarLogInterval != nil {
+		opts = opts.SetExemplarLogInterval(*c.ExemplarLogInterval)
+	}
+
+	if c.Namespace != nil {
+		restrict := &storage.RestrictByType{
+			MetricsType:   c.Namespace.MetricsType,
+			StoragePolicy: c.Namespace.StoragePolicy,
+		}
+		if err := restrict.Validate(); err != nil {
+			return nil, err
+		}
+		opts = opts.SetRestrictByType(restrict)
+		remote = primary
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return NewStorage(primary, remote, opts), nil
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package shadow
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/sampler"
+)
+
+var (
+	errNoSampler                = errors.New("shadow read sampler not set")
+	errInvalidTimeout           = errors.New("shadow read timeout must be positive")
+	errInvalidMaxConcurrency    = errors.New("shadow read max concurrency must be positive")
+	errInvalidNegativeTolerance = errors.New("shadow read tolerance must not be negative")
+)
+
+// Options are the options for shadow reads.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetSampler sets the sampler that selects the reads to mirror.
+	SetSampler(value *sampler.Sampler) Options
+
+	// Sampler returns the sampler that selects the reads to mirror.
+	Sampler() *sampler.Sampler
+
+	// SetRestrictByType sets the restriction applied to shadow reads.
+	SetRestrictByType(value *storage.RestrictByType) Options
+
+	// RestrictByType returns the restriction applied to shadow reads.
+	RestrictByType() *storage.RestrictByType
+
+	// SetTolerance sets the tolerance within which sample values match.
+	SetTolerance(value Tolerance) Options
+
+	// Tolerance returns the tolerance within which sample values match.
+	Tolerance() Tolerance
+
+	// SetTimeout sets the timeout for a shadow read.
+	SetTimeout(value time.Duration) Options
+
+	// Timeout returns the timeout for a shadow read.
+	Timeout() time.Duration
+
+	// SetMaxConcurrency sets the maximum number of in flight shadow reads.
+	SetMaxConcurrency(value int) Options
+
+	// MaxConcurrency returns the maximum number of in flight shadow reads.
+	MaxConcurrency() int
+
+	// SetExemplarLogInterval sets the minimum interval between logging
+	// exemplar mismatches.
+	SetExemplarLogInterval(value time.Duration) Options
+
+	// ExemplarLogInterval returns the minimum interval between logging
+	// exemplar mismatches.
+	ExemplarLogInterval() time.Duration
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+}
+
+type options struct {
+	sampler             *sampler.Sampler
+	restrictByType      *storage.RestrictByType
+	tolerance           Tolerance
+	timeout             time.Duration
+	maxConcurrency      int
+	exemplarLogInterval time.Duration
+	instrumentOpts      instrument.Options
+}
+
+// NewOptions returns new shadow read options.
+func NewOptions() Options {
+	return &options{
+		timeout:             defaultTimeout,
+		maxConcurrency:      defaultMaxConcurrency,
+		exemplarLogInterval: defaultExemplarLogInterval,
+		instrumentOpts:      instrument.NewOptions(),
+	}
+}
+
+func (o *options) Validate() error {
+	if o.sampler == nil {
+		return errNoSampler
+	}
+	if o.timeout <= 0 {
+		return errInvalidTimeout
+	}
+	if o.maxConcurrency <= 0 {
+		return errInvalidMaxConcurrency
+	}
+	if o.tolerance.Absolute < 0 || o.tolerance.Relative < 0 {
+		return errInvalidNegativeTolerance
+	}
+	return nil
+}
+
+func (o *options) SetSampler(value *sampler.Sampler) Options {
+	opts := *o
+	opts.sampler = value
+	return &opts
+}
+
+func (o *options) Sampler() *sampler.Sampler {
+	return o.sampler
+}
+
+func (o *options) SetRestrictByType(value *storage.RestrictByType) Options {
+	opts := *o
+	opts.restrictByType = value
+	return &opts
+}
+
+func (o *options) RestrictByType() *storage.RestrictByType {
+	return o.restrictByType
+}
+
+func (o *options) SetTolerance(value Tolerance) Options {
+	opts := *o
+	opts.tolerance = value
+	return &opts
+}
+
+func (o *options) Tolerance() Tolerance {
+	return o.tolerance
+}
+
+func (o *options) SetTimeout(value time.Duration) Options {
+	opts := *o
+	opts.timeout = value
+	return &opts
+}
+
+func (o *options) Timeout() time.Duration {
+	return o.timeout
+}
+
+func (o *options) SetMaxConcurrency(value int) Options {
+	opts := *o
+	opts.maxConcurrency = value
+	return &opts
+}
+
+func (o *options) MaxConcurrency() int {
+	return o.maxConcurrency
+}
+
+func (o *options) SetExemplarLogInterval(value time.Duration) Options {
+	opts := *o
+	opts.exemplarLogInterval = value
+	return &opts
+}
+
+func (o *options) ExemplarLogInterval() time.Duration {
+	return o.exemplarLogInterval
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
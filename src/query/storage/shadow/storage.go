@@ -0,0 +1,213 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package shadow provides a storage that mirrors a sample of reads to a
+// shadow namespace or cluster to continuously verify that it returns the same
+// results as the primary storage.
+package shadow
+
+import (
+	"context"
+	"time"
+
+	"github.com/m3db/m3/src/query/storage"
+	xerrors "github.com/m3db/m3/src/x/errors"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+type shadowStorage struct {
+	storage.Storage
+
+	shadow              storage.Storage
+	opts                Options
+	inFlight            chan struct{}
+	metrics             shadowMetrics
+	logger              *zap.Logger
+	nowFn               func() time.Time
+	lastExemplarLogNano *atomic.Int64
+}
+
+type shadowMetrics struct {
+	sampled          tally.Counter
+	skipped          tally.Counter
+	errors           tally.Counter
+	matches          tally.Counter
+	mismatches       tally.Counter
+	mismatchesByType map[mismatchType]tally.Counter
+	latency          tally.Timer
+}
+
+func newShadowMetrics(scope tally.Scope) shadowMetrics {
+	m := shadowMetrics{
+		sampled:          scope.Counter("sampled"),
+		skipped:          scope.Counter("skipped"),
+		errors:           scope.Counter("errors"),
+		matches:          scope.Counter("matches"),
+		mismatches:       scope.Counter("mismatches"),
+		mismatchesByType: make(map[mismatchType]tally.Counter, len(mismatchTypes)),
+		latency:          scope.Timer("latency"),
+	}
+	for _, t := range mismatchTypes {
+		m.mismatchesByType[t] = scope.Tagged(map[string]string{
+			"type": string(t),
+		}).Counter("mismatched-items")
+	}
+	return m
+}
+
+// NewStorage returns a storage that serves reads from the primary storage and
+// mirrors a sample of Prometheus reads to the shadow storage, comparing the
+// shadow results with the primary results in the background. Mismatches are
+// reported as metrics with exemplars logged at a limited rate.
+func NewStorage(primary, shadow storage.Storage, opts Options) storage.Storage {
+	iOpts := opts.InstrumentOptions()
+	return &shadowStorage{
+		Storage:             primary,
+		shadow:              shadow,
+		opts:                opts,
+		inFlight:            make(chan struct{}, opts.MaxConcurrency()),
+		metrics:             newShadowMetrics(iOpts.MetricsScope().SubScope("shadow-read")),
+		logger:              iOpts.Logger(),
+		nowFn:               time.Now,
+		lastExemplarLogNano: atomic.NewInt64(0),
+	}
+}
+
+func (s *shadowStorage) FetchProm(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (storage.PromResult, error) {
+	result, err := s.Storage.FetchProm(ctx, query, options)
+	if err != nil || !s.opts.Sampler().Sample() {
+		return result, err
+	}
+
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		s.metrics.skipped.Inc(1)
+		return result, err
+	}
+
+	s.metrics.sampled.Inc(1)
+	var (
+		shadowQuery = *query
+		expected    = newSeriesSet(result.PromResult)
+	)
+	go func() {
+		s.compare(&shadowQuery, s.shadowFetchOptions(options), expected)
+		<-s.inFlight
+	}()
+
+	return result, err
+}
+
+func (s *shadowStorage) shadowFetchOptions(
+	options *storage.FetchOptions,
+) *storage.FetchOptions {
+	opts := options.Clone()
+	opts.Timeout = s.opts.Timeout()
+	if restrict := s.opts.RestrictByType(); restrict != nil {
+		restrictOpts := &storage.RestrictQueryOptions{}
+		if existing := options.RestrictQueryOptions; existing != nil {
+			*restrictOpts = *existing
+		}
+		restrictOpts.RestrictByType = restrict
+		restrictOpts.RestrictByTypes = nil
+		restrictOpts.RestrictByNamespace = nil
+		opts.RestrictQueryOptions = restrictOpts
+	}
+	return opts
+}
+
+func (s *shadowStorage) compare(
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+	expected seriesSet,
+) {
+	// NB: the shadow read must outlive the request that it mirrors.
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout())
+	defer cancel()
+
+	start := s.nowFn()
+	result, err := s.shadow.FetchProm(ctx, query, options)
+	s.metrics.latency.Record(s.nowFn().Sub(start))
+	if err != nil {
+		s.metrics.errors.Inc(1)
+		s.logger.Debug("shadow read failed",
+			zap.String("query", query.Raw), zap.Error(err))
+		return
+	}
+
+	d := compare(expected, newSeriesSet(result.PromResult), s.opts.Tolerance())
+	if d.matches() {
+		s.metrics.matches.Inc(1)
+		return
+	}
+
+	s.metrics.mismatches.Inc(1)
+	for t, count := range d.counts {
+		s.metrics.mismatchesByType[t].Inc(int64(count))
+	}
+	s.logExemplar(query, d)
+}
+
+func (s *shadowStorage) logExemplar(query *storage.FetchQuery, d diff) {
+	var (
+		now  = s.nowFn().UnixNano()
+		last = s.lastExemplarLogNano.Load()
+	)
+	if now-last < int64(s.opts.ExemplarLogInterval()) ||
+		!s.lastExemplarLogNano.CAS(last, now) {
+		return
+	}
+
+	e := d.exemplar
+	fields := []zap.Field{
+		zap.String("query", query.Raw),
+		zap.Stringer("matchers", query.TagMatchers),
+		zap.Time("start", query.Start),
+		zap.Time("end", query.End),
+		zap.String("type", string(e.mismatchType)),
+		zap.String("series", e.series),
+		zap.Int("missingSeries", d.counts[missingSeriesMismatch]),
+		zap.Int("extraSeries", d.counts[extraSeriesMismatch]),
+		zap.Int("mismatchedSamples", d.counts[sampleMismatch]),
+	}
+	if e.mismatchType == sampleMismatch {
+		fields = append(fields,
+			zap.Time("timestamp", time.Unix(0, e.timestamp*int64(time.Millisecond))),
+			zap.Float64("expected", e.expected),
+			zap.Float64("actual", e.actual))
+	}
+	s.logger.Warn("shadow read mismatch", fields...)
+}
+
+func (s *shadowStorage) Close() error {
+	multiErr := xerrors.NewMultiError().Add(s.Storage.Close())
+	if s.shadow != s.Storage {
+		multiErr = multiErr.Add(s.shadow.Close())
+	}
+	return multiErr.FinalError()
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package shadow
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3/storagemetadata"
+	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/sampler"
+	xtest "github.com/m3db/m3/src/x/test"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func testSeries(name string, samples ...prompb.Sample) *prompb.TimeSeries {
+	return &prompb.TimeSeries{
+		Labels: []prompb.Label{
+			{Name: []byte("__name__"), Value: []byte(name)},
+			{Name: []byte("job"), Value: []byte("test")},
+		},
+		Samples: samples,
+	}
+}
+
+func testResult(series ...*prompb.TimeSeries) storage.PromResult {
+	return storage.PromResult{
+		PromResult: &prompb.QueryResult{Timeseries: series},
+		Metadata:   block.NewResultMetadata(),
+	}
+}
+
+func TestCompare(t *testing.T) {
+	expected := newSeriesSet(testResult(
+		testSeries("a", prompb.Sample{Timestamp: 1, Value: 100}, prompb.Sample{Timestamp: 2, Value: math.NaN()}),
+		testSeries("b", prompb.Sample{Timestamp: 1, Value: 1}),
+	).PromResult)
+
+	actual := newSeriesSet(testResult(
+		testSeries("a", prompb.Sample{Timestamp: 1, Value: 100.5}, prompb.Sample{Timestamp: 2, Value: math.NaN()}),
+		testSeries("b", prompb.Sample{Timestamp: 1, Value: 1}),
+	).PromResult)
+	d := compare(expected, actual, Tolerance{Relative: 0.01})
+	require.True(t, d.matches())
+
+	d = compare(expected, actual, Tolerance{Absolute: 0.1})
+	require.False(t, d.matches())
+	require.Equal(t, 1, d.counts[sampleMismatch])
+	require.Equal(t, mismatch{
+		mismatchType: sampleMismatch,
+		series:       "{__name__=a,job=test}",
+		timestamp:    1,
+		expected:     100,
+		actual:       100.5,
+	}, *d.exemplar)
+
+	actual = newSeriesSet(testResult(
+		testSeries("a", prompb.Sample{Timestamp: 2, Value: math.NaN()}, prompb.Sample{Timestamp: 3, Value: 1}),
+		testSeries("c"),
+	).PromResult)
+	d = compare(expected, actual, Tolerance{})
+	require.Equal(t, 1, d.counts[missingSeriesMismatch])
+	require.Equal(t, 1, d.counts[extraSeriesMismatch])
+	require.Equal(t, 2, d.counts[sampleMismatch])
+	require.Equal(t, sampleMismatch, d.exemplar.mismatchType)
+	require.True(t, math.IsNaN(d.exemplar.actual))
+}
+
+func newTestStorage(
+	t *testing.T,
+	primary, shadow storage.Storage,
+	scope tally.Scope,
+) *shadowStorage {
+	opts := NewOptions().
+		SetSampler(sampler.MustNewSampler(1)).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+	require.NoError(t, opts.Validate())
+	return NewStorage(primary, shadow, opts).(*shadowStorage)
+}
+
+// waitForShadowReads waits for the in flight shadow reads to complete, the
+// slot of a shadow read is acquired before the primary read returns.
+func waitForShadowReads(t *testing.T, s *shadowStorage) {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.inFlight) > 0 {
+		require.True(t, time.Now().Before(deadline), "shadow reads did not complete")
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestShadowStorageFetchProm(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ctx     = context.Background()
+		query   = &storage.FetchQuery{Raw: "a"}
+		primary = storage.NewMockStorage(ctrl)
+		shadow  = storage.NewMockStorage(ctrl)
+		scope   = tally.NewTestScope("", nil)
+		s       = newTestStorage(t, primary, shadow, scope)
+		sample  = prompb.Sample{Timestamp: 1, Value: 1}
+	)
+
+	primary.EXPECT().FetchProm(ctx, query, gomock.Any()).
+		Return(testResult(testSeries("a", sample)), nil).Times(2)
+	shadow.EXPECT().FetchProm(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(testResult(testSeries("a", sample)), nil)
+	shadow.EXPECT().FetchProm(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(testResult(testSeries("b", sample)), nil)
+
+	for i := 0; i < 2; i++ {
+		result, err := s.FetchProm(ctx, query, storage.NewFetchOptions())
+		require.NoError(t, err)
+		require.Len(t, result.PromResult.Timeseries, 1)
+		waitForShadowReads(t, s)
+	}
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(2), counters["shadow-read.sampled+"].Value())
+	require.Equal(t, int64(1), counters["shadow-read.matches+"].Value())
+	require.Equal(t, int64(1), counters["shadow-read.mismatches+"].Value())
+	require.Equal(t, int64(1),
+		counters["shadow-read.mismatched-items+type=missing_series"].Value())
+	require.Equal(t, int64(1),
+		counters["shadow-read.mismatched-items+type=extra_series"].Value())
+}
+
+func TestShadowStorageFetchPromSkipsWhenAtMaxConcurrency(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ctx     = context.Background()
+		query   = &storage.FetchQuery{Raw: "a"}
+		primary = storage.NewMockStorage(ctrl)
+		scope   = tally.NewTestScope("", nil)
+		s       = newTestStorage(t, primary, storage.NewMockStorage(ctrl), scope)
+	)
+
+	for i := 0; i < cap(s.inFlight); i++ {
+		s.inFlight <- struct{}{}
+	}
+
+	primary.EXPECT().FetchProm(ctx, query, gomock.Any()).Return(testResult(), nil)
+	_, err := s.FetchProm(ctx, query, storage.NewFetchOptions())
+	require.NoError(t, err)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["shadow-read.skipped+"].Value())
+}
+
+func TestConfigurationNewStorageNamespace(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	primary := storage.NewMockStorage(ctrl)
+	cfg := Configuration{
+		Enabled:    true,
+		SampleRate: 1,
+		Namespace: &NamespaceConfiguration{
+			MetricsType:   storagemetadata.AggregatedMetricsType,
+			StoragePolicy: policy.MustParseStoragePolicy("1m:40d"),
+		},
+	}
+	store, err := cfg.NewStorage(primary, nil, instrument.NewOptions())
+	require.NoError(t, err)
+
+	s := store.(*shadowStorage)
+	require.Equal(t, primary, s.shadow)
+
+	opts := s.shadowFetchOptions(&storage.FetchOptions{
+		RestrictQueryOptions: &storage.RestrictQueryOptions{
+			RestrictByType: &storage.RestrictByType{
+				MetricsType: storagemetadata.UnaggregatedMetricsType,
+			},
+		},
+	})
+	require.Equal(t, &storage.RestrictByType{
+		MetricsType:   storagemetadata.AggregatedMetricsType,
+		StoragePolicy: policy.MustParseStoragePolicy("1m:40d"),
+	}, opts.RestrictQueryOptions.RestrictByType)
+	require.Equal(t, defaultTimeout, opts.Timeout)
+
+	primary.EXPECT().Close().Return(nil)
+	require.NoError(t, store.Close())
+}
+
+func TestConfigurationNewStorageInvalid(t *testing.T) {
+	ctrl := xtest.NewController(t)
+	defer ctrl.Finish()
+
+	primary := storage.NewMockStorage(ctrl)
+	store, err := Configuration{}.NewStorage(primary, nil, instrument.NewOptions())
+	require.NoError(t, err)
+	require.Equal(t, primary, store)
+
+	_, err = Configuration{Enabled: true}.NewStorage(primary, nil, instrument.NewOptions())
+	require.Error(t, err)
+
+	_, err = Configuration{
+		Enabled:         true,
+		Namespace:       &NamespaceConfiguration{MetricsType: storagemetadata.UnaggregatedMetricsType},
+		RemoteAddresses: []string{"localhost:7201"},
+	}.NewStorage(primary, nil, instrument.NewOptions())
+	require.Error(t, err)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"fmt"
+
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+// StitchingPolicy describes how results are stitched together when a query
+// spans both the unaggregated namespace and aggregated namespaces.
+type StitchingPolicy uint
+
+const (
+	// StitchingDefault leaves the namespace boundaries as resolved by the
+	// fanout, overlapping results are deduplicated by retention and resolution.
+	StitchingDefault StitchingPolicy = iota
+	// StitchingPreferUnaggregated reads the unaggregated namespace for as much
+	// of the query range as it retains and aggregated namespaces only before.
+	StitchingPreferUnaggregated
+	// StitchingPreferAggregated reads aggregated namespaces for the query range
+	// and the unaggregated namespace only for data not yet aggregated.
+	StitchingPreferAggregated
+	// StitchingCutover reads aggregated namespaces before a cut-over timestamp
+	// and the unaggregated namespace from the cut-over timestamp onwards.
+	StitchingCutover
+)
+
+var validStitchingPolicies = []StitchingPolicy{
+	StitchingDefault,
+	StitchingPreferUnaggregated,
+	StitchingPreferAggregated,
+	StitchingCutover,
+}
+
+func (p StitchingPolicy) String() string {
+	switch p {
+	case StitchingDefault:
+		return "default"
+	case StitchingPreferUnaggregated:
+		return "prefer_unaggregated"
+	case StitchingPreferAggregated:
+		return "prefer_aggregated"
+	case StitchingCutover:
+		return "cutover"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseStitchingPolicy parses a stitching policy.
+func ParseStitchingPolicy(str string) (StitchingPolicy, error) {
+	for _, valid := range validStitchingPolicies {
+		if str == valid.String() {
+			return valid, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unrecognized stitching policy: %v", str)
+}
+
+// MarshalYAML marshals a StitchingPolicy.
+func (p *StitchingPolicy) MarshalYAML() (interface{}, error) {
+	return p.String(), nil
+}
+
+// UnmarshalYAML unmarshals a stitching policy.
+func (p *StitchingPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+
+	if str == "" {
+		*p = StitchingDefault
+		return nil
+	}
+
+	if value, err := ParseStitchingPolicy(str); err == nil {
+		*p = value
+		return nil
+	}
+
+	return fmt.Errorf("invalid StitchingPolicy '%s' valid types are: %v",
+		str, validStitchingPolicies)
+}
+
+// StitchingOptions overrides the stitching policy for a single query.
+type StitchingOptions struct {
+	// Policy is the stitching policy to apply.
+	Policy StitchingPolicy
+	// Cutover is the cut-over timestamp used by the cutover policy for all
+	// namespace pairs, the configured cut-over of each pair is used if zero.
+	Cutover xtime.UnixNano
+}
+
+// Validate validates the stitching options.
+func (o StitchingOptions) Validate() error {
+	if o.Policy != StitchingCutover && !o.Cutover.IsZero() {
+		return fmt.Errorf("stitching cutover can only be set with the %v policy",
+			StitchingCutover)
+	}
+	return nil
+}
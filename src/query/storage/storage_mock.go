@@ -102,6 +102,21 @@ func (mr *MockStorageMockRecorder) ErrorBehavior() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ErrorBehavior", reflect.TypeOf((*MockStorage)(nil).ErrorBehavior))
 }
 
+// ExplainQuery mocks base method.
+func (m *MockStorage) ExplainQuery(arg0 context.Context, arg1 *FetchQuery, arg2 *FetchOptions) (QueryExplanation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExplainQuery", arg0, arg1, arg2)
+	ret0, _ := ret[0].(QueryExplanation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExplainQuery indicates an expected call of ExplainQuery.
+func (mr *MockStorageMockRecorder) ExplainQuery(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExplainQuery", reflect.TypeOf((*MockStorage)(nil).ExplainQuery), arg0, arg1, arg2)
+}
+
 // FetchBlocks mocks base method.
 func (m *MockStorage) FetchBlocks(arg0 context.Context, arg1 *FetchQuery, arg2 *FetchOptions) (block.Result, error) {
 	m.ctrl.T.Helper()
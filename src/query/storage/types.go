@@ -134,6 +134,9 @@ type FetchOptions struct {
 	BlockType models.FetchedBlockType
 	// FanoutOptions are the options for the fetch namespace fanout.
 	FanoutOptions *FanoutOptions
+	// StitchingOptions if set overrides the configured policy for stitching
+	// results of unaggregated and aggregated namespaces.
+	StitchingOptions *StitchingOptions
 	// RestrictQueryOptions restricts the fetch to a specific set of
 	// conditions.
 	RestrictQueryOptions *RestrictQueryOptions
@@ -151,6 +154,9 @@ type FetchOptions struct {
 	IterateEqualTimestampStrategy *encoding.IterateEqualTimestampStrategy
 	// Source is the source for the query.
 	Source []byte
+	// SampleRate if set below one restricts the query to a deterministic
+	// sample of the matched series.
+	SampleRate float64
 
 	RelatedQueryOptions *RelatedQueryOptions
 }
@@ -226,6 +232,17 @@ type RestrictQueryOptions struct {
 	// RestrictByTypes are specific restrictions to query from specified data
 	// types.
 	RestrictByTypes []*RestrictByType
+	// RestrictByNamespace is a specific restriction to query from a single
+	// namespace by name.
+	RestrictByNamespace *RestrictByNamespace
+}
+
+// RestrictByNamespace is a specific restriction to query from a single
+// namespace by name.
+type RestrictByNamespace struct {
+	// Namespace is the name of the namespace to query from, aliases of the
+	// namespace are resolved before the restriction is set.
+	Namespace string
 }
 
 // Querier handles queries against a storage.
@@ -274,6 +291,45 @@ type Querier interface {
 		queryStart, queryEnd time.Time,
 		opts *FetchOptions,
 	) ([]storagemetadata.Attributes, error)
+
+	// ExplainQuery returns the planned fanout for a query, resolving the
+	// namespaces and time ranges it would fetch from without fetching
+	// any data.
+	ExplainQuery(
+		ctx context.Context,
+		query *FetchQuery,
+		options *FetchOptions,
+	) (QueryExplanation, error)
+}
+
+// QueryExplanation is the planned fanout of a query.
+type QueryExplanation struct {
+	// Namespaces are the namespaces the query fans out to.
+	Namespaces []NamespaceExplanation
+}
+
+// NamespaceExplanation is the planned fetch from a single namespace.
+type NamespaceExplanation struct {
+	// Storage is the name of the storage the namespace belongs to.
+	Storage string
+	// FanoutType is how results from the namespace are combined with the
+	// results of other namespaces.
+	FanoutType string
+	// Namespace is the ID of the namespace.
+	Namespace string
+	// Attributes are the storage metadata attributes of the namespace.
+	Attributes storagemetadata.Attributes
+	// Start is the inclusive start of the time range fetched.
+	Start time.Time
+	// End is the exclusive end of the time range fetched.
+	End time.Time
+	// IndexQuery is the index query the matchers are pushed down as.
+	IndexQuery string
+	// EstimatedSeries is the number of series matched by the index query,
+	// bounded by the series limit of the query.
+	EstimatedSeries int
+	// Exhaustive is false if the estimated series count hit the series limit.
+	Exhaustive bool
 }
 
 // WriteQuery represents the input timeseries that is written to the database.
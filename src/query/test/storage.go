@@ -103,6 +103,14 @@ func (s *slowStorage) QueryStorageMetadataAttributes(
 	return nil, errors.New("not implemented")
 }
 
+func (s *slowStorage) ExplainQuery(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (storage.QueryExplanation, error) {
+	return storage.QueryExplanation{}, errors.New("not implemented")
+}
+
 func (s *slowStorage) Type() storage.Type {
 	return storage.TypeMultiDC
 }
@@ -23,6 +23,7 @@ package queryhttp
 import (
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/gorilla/mux"
 
@@ -133,6 +134,35 @@ func (r *EndpointRegistry) PathEntry(path, method string) (*mux.Route, bool) {
 	return e, ok
 }
 
+// Endpoint is a route registered by path and method.
+type Endpoint struct {
+	Path   string
+	Method string
+}
+
+// Endpoints returns the routes that were registered by path and method, not
+// by path prefix, sorted by path and method.
+func (r *EndpointRegistry) Endpoints() []Endpoint {
+	endpoints := make([]Endpoint, 0, len(r.registeredByRoute))
+	for key := range r.registeredByRoute {
+		if key.path == "" {
+			continue
+		}
+		endpoints = append(endpoints, Endpoint{
+			Path:   key.path,
+			Method: key.method,
+		})
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+	return endpoints
+}
+
 // Walk walks the router and all its sub-routers, calling walkFn for each route
 // in the tree. The routes are walked in the order they were added. Sub-routers
 // are explored depth-first.
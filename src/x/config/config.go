@@ -22,12 +22,15 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"io"
 	"os"
 	"reflect"
 	"strings"
 
+	"github.com/m3db/m3/src/x/secrets"
+
 	"go.uber.org/config"
 	"go.uber.org/zap"
 	validator "gopkg.in/validator.v2"
@@ -55,6 +58,14 @@ type Options struct {
 	// Expand provides values for templated strings of the form ${KEY}.
 	// By default, we extract these values from the environment.
 	Expand config.LookupFunc
+
+	// DisableSecrets disables resolving secret references of the form
+	// secret://<provider>/<path> in string values.
+	DisableSecrets bool
+
+	// Secrets resolves secret references, by default secrets are resolved
+	// from the environment, files and Vault if VAULT_ADDR is set.
+	Secrets *secrets.Resolver
 }
 
 // LoadFile loads a config from a file.
@@ -95,6 +106,16 @@ func LoadFiles(dst interface{}, files []string, opts Options) error {
 		return err
 	}
 
+	if !opts.DisableSecrets {
+		resolver := opts.Secrets
+		if resolver == nil {
+			resolver = secrets.DefaultResolver()
+		}
+		if err := secrets.ResolveReferences(context.Background(), resolver, dst); err != nil {
+			return err
+		}
+	}
+
 	if opts.DisableValidate {
 		return nil
 	}
@@ -27,6 +27,8 @@ import (
 	"os"
 	"testing"
 
+	"github.com/m3db/m3/src/x/secrets"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/config"
@@ -406,6 +408,46 @@ func TestLoadFilesEnvExpansion(t *testing.T) {
 	})
 }
 
+func TestLoadFilesSecrets(t *testing.T) {
+	const withSecrets = `
+    listen_address: secret://env/LISTEN_ADDRESS
+    buffer_space: 1024
+    servers:
+      - secret://env/SERVER
+    `
+
+	fname := writeFile(t, withSecrets)
+	defer func() {
+		require.NoError(t, os.Remove(fname))
+	}()
+
+	env := map[string]string{
+		"LISTEN_ADDRESS": "localhost:4385",
+		"SERVER":         "server1:8090",
+	}
+	resolver := secrets.NewResolver(map[string]secrets.Provider{
+		secrets.EnvProviderName: secrets.NewEnvProvider(func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		}),
+	})
+
+	var cfg configuration
+	require.NoError(t, LoadFile(&cfg, fname, Options{Secrets: resolver}))
+	assert.Equal(t, configuration{
+		ListenAddress: "localhost:4385",
+		BufferSpace:   1024,
+		Servers:       []string{"server1:8090"},
+	}, cfg)
+
+	cfg = configuration{}
+	require.NoError(t, LoadFile(&cfg, fname, Options{DisableSecrets: true}))
+	assert.Equal(t, "secret://env/LISTEN_ADDRESS", cfg.ListenAddress)
+
+	delete(env, "SERVER")
+	require.Error(t, LoadFile(&cfg, fname, Options{Secrets: resolver}))
+}
+
 func TestDeprecationCheck(t *testing.T) {
 	t.Run("StandardConfig", func(t *testing.T) {
 		// OK
@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package configvalidate provides the validate subcommand of the service
+// binaries, which loads a configuration, checks it without starting the
+// service and reports all the problems found.
+package configvalidate
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/m3db/m3/src/x/config"
+	"github.com/m3db/m3/src/x/config/configflag"
+)
+
+// Command is the name of the validate subcommand.
+const Command = "validate"
+
+// Options are the options of a validation.
+type Options struct {
+	// ResolveKV resolves references to kv, such as dynamic namespaces, by
+	// reading them from the configured kv store without modifying it.
+	ResolveKV bool
+}
+
+// Problem is a single problem found in a configuration.
+type Problem struct {
+	// Path is the path of the offending field, e.g. "db.filesystem".
+	Path string `json:"path"`
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// Report is the structured result of a validation.
+type Report struct {
+	Service  string    `json:"service"`
+	Files    []string  `json:"files"`
+	Valid    bool      `json:"valid"`
+	Problems []Problem `json:"problems"`
+}
+
+// Add adds a problem to the report if the error is not nil.
+func (r *Report) Add(path string, err error) {
+	if err == nil {
+		return
+	}
+	r.Problems = append(r.Problems, Problem{Path: path, Message: err.Error()})
+}
+
+// Addf adds a problem to the report.
+func (r *Report) Addf(path string, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateFn checks the loaded configuration and adds the problems found to
+// the report.
+type ValidateFn func(report *Report, opts Options)
+
+// IsCommand returns whether the command line arguments, including the
+// program name, invoke the validate subcommand.
+func IsCommand(args []string) bool {
+	return len(args) > 1 && args[1] == Command
+}
+
+// Main runs the validate subcommand with the command line arguments and
+// exits with a non zero status if the configuration is invalid.
+func Main(service string, args []string, target interface{}, fn ValidateFn) {
+	os.Exit(Run(service, args[2:], os.Stdout, target, fn))
+}
+
+// Run loads the configuration files passed in the arguments of the validate
+// subcommand into the target, validates it with the function, writes the
+// report as JSON and returns the exit status.
+func Run(
+	service string,
+	args []string,
+	out io.Writer,
+	target interface{},
+	fn ValidateFn,
+) int {
+	var (
+		cmd   = flag.NewFlagSet(service+" "+Command, flag.ContinueOnError)
+		files configflag.FlagStringSlice
+		opts  Options
+	)
+	cmd.SetOutput(out)
+	cmd.Var(&files, "f", "Configuration files to validate")
+	cmd.BoolVar(&opts.ResolveKV, "kv", false,
+		"Resolve references to kv in read only mode")
+	if err := cmd.Parse(args); err != nil {
+		return 2
+	}
+
+	report := &Report{
+		Service:  service,
+		Files:    files.Value,
+		Problems: []Problem{},
+	}
+	// NB: secrets are not resolved so that validating does not require
+	// access to them.
+	err := config.LoadFiles(target, files.Value, config.Options{DisableSecrets: true})
+	if err != nil {
+		report.Add("", err)
+	} else {
+		fn(report, opts)
+	}
+	report.Valid = len(report.Problems) == 0
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return 2
+	}
+	if !report.Valid {
+		return 1
+	}
+	return 0
+}
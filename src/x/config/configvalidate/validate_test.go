@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package configvalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testConfiguration struct {
+	BlockSize int `yaml:"blockSize" validate:"min=1"`
+	Retention int `yaml:"retention"`
+}
+
+func TestIsCommand(t *testing.T) {
+	require.True(t, IsCommand([]string{"m3dbnode", "validate", "-f", "a.yml"}))
+	require.False(t, IsCommand([]string{"m3dbnode", "-f", "a.yml"}))
+	require.False(t, IsCommand([]string{"m3dbnode"}))
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		args     []string
+		status   int
+		problems []Problem
+		resolve  bool
+	}{
+		{
+			name:     "valid",
+			config:   "blockSize: 2\nretention: 4\n",
+			problems: []Problem{},
+		},
+		{
+			name:   "cross field problem",
+			config: "blockSize: 8\nretention: 4\n",
+			status: 1,
+			problems: []Problem{
+				{Path: "retention", Message: "retention 4 is less than block size 8"},
+			},
+		},
+		{
+			name:    "resolve kv",
+			config:  "blockSize: 2\nretention: 4\n",
+			args:    []string{"-kv"},
+			resolve: true,
+			status:  1,
+			problems: []Problem{
+				{Path: "kv", Message: "kv unavailable"},
+			},
+		},
+		{
+			name:   "load error",
+			config: "blockSize: 0\n",
+			status: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.yml")
+			require.NoError(t, os.WriteFile(file, []byte(test.config), 0600))
+
+			var (
+				out bytes.Buffer
+				cfg testConfiguration
+			)
+			args := append([]string{"-f", file}, test.args...)
+			status := Run("test", args, &out, &cfg, func(r *Report, opts Options) {
+				require.Equal(t, test.resolve, opts.ResolveKV)
+				if cfg.Retention < cfg.BlockSize {
+					r.Addf("retention", "retention %d is less than block size %d",
+						cfg.Retention, cfg.BlockSize)
+				}
+				if opts.ResolveKV {
+					r.Add("kv", errors.New("kv unavailable"))
+				}
+				r.Add("ignored", nil)
+			})
+			require.Equal(t, test.status, status)
+
+			var report Report
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+			require.Equal(t, "test", report.Service)
+			require.Equal(t, []string{file}, report.Files)
+			require.Equal(t, test.status == 0, report.Valid)
+			if test.problems != nil {
+				require.Equal(t, test.problems, report.Problems)
+			} else {
+				require.Len(t, report.Problems, 1)
+			}
+		})
+	}
+}
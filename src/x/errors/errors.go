@@ -23,6 +23,7 @@ package errors
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 )
@@ -288,6 +289,138 @@ func GetInnerNonRetryableError(err error) error {
 	return nil
 }
 
+// Code classifies an error so that callers can decide how to handle it,
+// e.g. whether to retry, without inspecting the error message.
+type Code int
+
+const (
+	// CodeUnknown is an error that has not been classified.
+	CodeUnknown Code = iota
+	// CodeInvalidParams is an error caused by invalid request parameters.
+	CodeInvalidParams
+	// CodeNotFound is an error caused by a resource that does not exist.
+	CodeNotFound
+	// CodeResourceExhausted is an error caused by exceeding a limit.
+	CodeResourceExhausted
+	// CodeTimeout is an error caused by a request not completing in time.
+	CodeTimeout
+	// CodeUnavailable is an error caused by a service not able to serve
+	// requests yet, e.g. while bootstrapping.
+	CodeUnavailable
+	// CodeInternal is an error caused by an internal failure.
+	CodeInternal
+)
+
+var codeNames = map[Code]string{
+	CodeUnknown:           "unknown",
+	CodeInvalidParams:     "invalid_params",
+	CodeNotFound:          "not_found",
+	CodeResourceExhausted: "resource_exhausted",
+	CodeTimeout:           "timeout",
+	CodeUnavailable:       "unavailable",
+	CodeInternal:          "internal",
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return codeNames[CodeUnknown]
+}
+
+// Retryable returns whether errors with the code are retryable by default.
+func (c Code) Retryable() bool {
+	switch c {
+	case CodeInvalidParams, CodeNotFound, CodeResourceExhausted:
+		return false
+	}
+	return true
+}
+
+// ErrorDetails is the structured description of an error.
+type ErrorDetails struct {
+	// Code classifies the error.
+	Code Code
+	// Retryable is whether the request that failed may succeed if retried.
+	Retryable bool
+	// Resource is the resource the error relates to, e.g. the name of the
+	// limit that was exceeded.
+	Resource string
+	// Limit is the value of the limit that was exceeded, if any.
+	Limit int64
+}
+
+// NewErrorDetails returns the error details for a code, retryable
+// as per the default of the code.
+func NewErrorDetails(code Code) ErrorDetails {
+	return ErrorDetails{Code: code, Retryable: code.Retryable()}
+}
+
+type structuredError struct {
+	containedError
+	details ErrorDetails
+}
+
+// NewStructuredError creates a new error carrying structured details.
+func NewStructuredError(inner error, details ErrorDetails) error {
+	return structuredError{containedError: containedError{inner}, details: details}
+}
+
+func (e structuredError) Error() string {
+	return e.inner.Error()
+}
+
+func (e structuredError) InnerError() error {
+	return e.inner
+}
+
+// GetErrorDetails returns the details of the outermost structured error
+// contained by this error, false if none is contained.
+func GetErrorDetails(err error) (ErrorDetails, bool) {
+	for err != nil {
+		// nolint:errorlint
+		if e, ok := err.(structuredError); ok {
+			return e.details, true
+		}
+		// nolint:errorlint
+		if multiErr, ok := err.(MultiError); ok {
+			for _, e := range multiErr.Errors() {
+				if details, ok := GetErrorDetails(e); ok {
+					return details, true
+				}
+			}
+		}
+		err = InnerError(err)
+	}
+	return ErrorDetails{}, false
+}
+
+// ErrorDetailsOf returns the structured details of the error, derived from
+// how the error is classified if it does not carry structured details.
+func ErrorDetailsOf(err error) ErrorDetails {
+	if details, ok := GetErrorDetails(err); ok {
+		return details
+	}
+
+	var details ErrorDetails
+	switch {
+	case IsResourceExhausted(err):
+		details = NewErrorDetails(CodeResourceExhausted)
+	case IsInvalidParams(err):
+		details = NewErrorDetails(CodeInvalidParams)
+	case Is(err, context.DeadlineExceeded):
+		details = NewErrorDetails(CodeTimeout)
+	default:
+		details = NewErrorDetails(CodeUnknown)
+	}
+	if IsNonRetryableError(err) {
+		details.Retryable = false
+	} else if IsRetryableError(err) {
+		details.Retryable = true
+	}
+	return details
+}
+
 // IsMultiError returns true if this is a multi-error error.
 func IsMultiError(err error) bool {
 	_, ok := GetInnerMultiError(err)
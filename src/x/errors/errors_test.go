@@ -21,6 +21,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -130,3 +131,75 @@ func TestErrorsIsAnErrorAndFormatsErrors(t *testing.T) {
 	assert.Equal(t, "[<some error: foo=2, bar=baz>, "+
 		"<some other error: foo=42, bar=qux>]", errs.Error())
 }
+
+func TestStructuredError(t *testing.T) {
+	inner := errors.New("query limit exceeded")
+	err := NewStructuredError(inner, ErrorDetails{
+		Code:     CodeResourceExhausted,
+		Resource: "docs-matched",
+		Limit:    100,
+	})
+	assert.Equal(t, inner.Error(), err.Error())
+	assert.Equal(t, inner, InnerError(err))
+
+	wrapped := NewInvalidParamsError(Wrap(err, "fetch failed"))
+	details, ok := GetErrorDetails(wrapped)
+	require.True(t, ok)
+	assert.Equal(t, ErrorDetails{
+		Code:     CodeResourceExhausted,
+		Resource: "docs-matched",
+		Limit:    100,
+	}, details)
+	assert.Equal(t, details, ErrorDetailsOf(wrapped))
+
+	multiErr := NewMultiError().Add(errors.New("other")).Add(err)
+	details, ok = GetErrorDetails(multiErr)
+	require.True(t, ok)
+	assert.Equal(t, CodeResourceExhausted, details.Code)
+
+	_, ok = GetErrorDetails(inner)
+	assert.False(t, ok)
+}
+
+func TestErrorDetailsOf(t *testing.T) {
+	inner := errors.New("error")
+	tests := []struct {
+		err      error
+		expected ErrorDetails
+	}{
+		{
+			err:      inner,
+			expected: ErrorDetails{Code: CodeUnknown, Retryable: true},
+		},
+		{
+			err:      NewNonRetryableError(inner),
+			expected: ErrorDetails{Code: CodeUnknown, Retryable: false},
+		},
+		{
+			err:      NewInvalidParamsError(inner),
+			expected: ErrorDetails{Code: CodeInvalidParams, Retryable: false},
+		},
+		{
+			err:      NewResourceExhaustedError(inner),
+			expected: ErrorDetails{Code: CodeResourceExhausted, Retryable: false},
+		},
+		{
+			err:      Wrap(context.DeadlineExceeded, "fetch"),
+			expected: ErrorDetails{Code: CodeTimeout, Retryable: true},
+		},
+		{
+			err:      NewRetryableError(NewInvalidParamsError(inner)),
+			expected: ErrorDetails{Code: CodeInvalidParams, Retryable: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			assert.Equal(t, test.expected, ErrorDetailsOf(test.err))
+		})
+	}
+}
+
+func TestCodeString(t *testing.T) {
+	assert.Equal(t, "resource_exhausted", CodeResourceExhausted.String())
+	assert.Equal(t, "unknown", Code(-1).String())
+}
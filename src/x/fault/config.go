@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Configuration is the configuration for injecting faults, it must be
+// explicitly enabled and should only be used for testing.
+type Configuration struct {
+	// Enabled enables fault injection.
+	Enabled bool `yaml:"enabled"`
+
+	// Faults are the faults injected from startup, faults can also be set
+	// and cleared while running.
+	Faults []FaultConfiguration `yaml:"faults"`
+}
+
+// FaultConfiguration is the configuration of a fault injected at a point.
+type FaultConfiguration struct {
+	// Point is the point the fault is injected at.
+	Point Point `yaml:"point" validate:"nonzero"`
+
+	// Latency is the latency injected.
+	Latency time.Duration `yaml:"latency"`
+
+	// Error is the message of the error injected, if empty only latency is
+	// injected.
+	Error string `yaml:"error"`
+
+	// After is the number of hits to skip before injecting.
+	After int `yaml:"after"`
+
+	// Times is the number of hits to inject the fault for, zero injects it
+	// until cleared.
+	Times int `yaml:"times"`
+}
+
+// Fault returns the fault.
+func (c FaultConfiguration) Fault() Fault {
+	f := Fault{
+		Latency: c.Latency,
+		After:   c.After,
+		Times:   c.Times,
+	}
+	if c.Error != "" {
+		f.Error = errors.New(c.Error)
+	}
+	return f
+}
+
+// NewInjector returns a new injector with the configured faults set.
+func (c Configuration) NewInjector() (*Injector, error) {
+	injector := NewInjector()
+	for _, f := range c.Faults {
+		if err := injector.Set(f.Point, f.Fault()); err != nil {
+			return nil, fmt.Errorf("unable to set configured fault: %w", err)
+		}
+	}
+	return injector, nil
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package fault injects latency and errors at defined points of the code so
+// that integration tests can deterministically exercise recovery paths. It
+// does nothing unless an injector is explicitly enabled.
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Point is a point in the code that faults can be injected at.
+type Point string
+
+const (
+	// KVGet is the point before reading a key from etcd.
+	KVGet Point = "kv.get"
+	// KVWrite is the point before setting, deleting or transacting keys in etcd.
+	KVWrite Point = "kv.write"
+	// PeerStreamMetadata is the point before streaming a batch of block
+	// metadata from a peer.
+	PeerStreamMetadata Point = "peers.stream-metadata"
+	// PeerStreamBlocks is the point before streaming a batch of blocks from a peer.
+	PeerStreamBlocks Point = "peers.stream-blocks"
+	// CommitLogFsync is the point before fsyncing a commit log file.
+	CommitLogFsync Point = "commitlog.fsync"
+	// BlockRead is the point before reading a block from a fileset.
+	BlockRead Point = "block.read"
+)
+
+var (
+	validPoints = []Point{
+		KVGet,
+		KVWrite,
+		PeerStreamMetadata,
+		PeerStreamBlocks,
+		CommitLogFsync,
+		BlockRead,
+	}
+
+	// ErrInjected is the error injected when a fault has no error message.
+	ErrInjected = errors.New("injected fault")
+)
+
+// ValidPoints returns the points faults can be injected at.
+func ValidPoints() []Point {
+	return append([]Point(nil), validPoints...)
+}
+
+// Validate validates the point is one faults can be injected at.
+func (p Point) Validate() error {
+	for _, valid := range validPoints {
+		if p == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown fault injection point %q, valid points: %v",
+		string(p), validPoints)
+}
+
+// Fault is a fault injected each time a point is hit.
+type Fault struct {
+	// Latency is the latency injected before returning.
+	Latency time.Duration
+	// Error is the error returned, if nil only latency is injected.
+	Error error
+	// After is the number of hits of the point to skip before injecting.
+	After int
+	// Times is the number of hits to inject the fault for once injecting
+	// starts, zero injects the fault until it is cleared.
+	Times int
+}
+
+type injection struct {
+	fault    Fault
+	hits     int
+	injected int
+}
+
+// Injector injects faults at points, faults can be set and cleared at any
+// time to control the injection while the process is running.
+type Injector struct {
+	sync.Mutex
+
+	sleepFn func(time.Duration)
+	faults  map[Point]*injection
+}
+
+// NewInjector returns a new injector with no faults set.
+func NewInjector() *Injector {
+	return &Injector{
+		sleepFn: time.Sleep,
+		faults:  make(map[Point]*injection),
+	}
+}
+
+// Set sets the fault injected at a point, replacing any existing fault and
+// resetting the count of hits.
+func (i *Injector) Set(p Point, f Fault) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if f.Latency < 0 || f.After < 0 || f.Times < 0 {
+		return fmt.Errorf("invalid fault for point %q: latency, after and "+
+			"times must not be negative", string(p))
+	}
+
+	i.Lock()
+	i.faults[p] = &injection{fault: f}
+	i.Unlock()
+	return nil
+}
+
+// Clear clears the fault injected at a point.
+func (i *Injector) Clear(p Point) {
+	i.Lock()
+	delete(i.faults, p)
+	i.Unlock()
+}
+
+// ClearAll clears the faults injected at all points.
+func (i *Injector) ClearAll() {
+	i.Lock()
+	i.faults = make(map[Point]*injection)
+	i.Unlock()
+}
+
+// Status is the status of the fault set at a point.
+type Status struct {
+	Point    Point         `json:"point"`
+	Latency  time.Duration `json:"latency"`
+	Error    string        `json:"error,omitempty"`
+	After    int           `json:"after"`
+	Times    int           `json:"times"`
+	Hits     int           `json:"hits"`
+	Injected int           `json:"injected"`
+}
+
+// Status returns the status of the faults set, ordered by point.
+func (i *Injector) Status() []Status {
+	i.Lock()
+	result := make([]Status, 0, len(i.faults))
+	for p, inj := range i.faults {
+		status := Status{
+			Point:    p,
+			Latency:  inj.fault.Latency,
+			After:    inj.fault.After,
+			Times:    inj.fault.Times,
+			Hits:     inj.hits,
+			Injected: inj.injected,
+		}
+		if inj.fault.Error != nil {
+			status.Error = inj.fault.Error.Error()
+		}
+		result = append(result, status)
+	}
+	i.Unlock()
+
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].Point < result[b].Point
+	})
+	return result
+}
+
+// Inject injects the fault set at a point, if any, sleeping for its latency
+// and returning its error.
+func (i *Injector) Inject(p Point) error {
+	i.Lock()
+	inj, ok := i.faults[p]
+	if !ok {
+		i.Unlock()
+		return nil
+	}
+	inj.hits++
+	if inj.hits <= inj.fault.After ||
+		(inj.fault.Times > 0 && inj.injected >= inj.fault.Times) {
+		i.Unlock()
+		return nil
+	}
+	inj.injected++
+	f := inj.fault
+	i.Unlock()
+
+	if f.Latency > 0 {
+		i.sleepFn(f.Latency)
+	}
+	return f.Error
+}
+
+// NB: The enabled injector is process wide so that faults can be injected
+// deep in the code without threading an injector through the options of
+// every component.
+var global atomic.Value
+
+type globalInjector struct {
+	injector *Injector
+}
+
+// Enable enables injecting faults with the injector process wide.
+func Enable(i *Injector) {
+	global.Store(globalInjector{injector: i})
+}
+
+// Disable disables injecting faults process wide.
+func Disable() {
+	global.Store(globalInjector{})
+}
+
+// Enabled returns the injector enabled process wide, if any.
+func Enabled() (*Injector, bool) {
+	v, ok := global.Load().(globalInjector)
+	if !ok || v.injector == nil {
+		return nil, false
+	}
+	return v.injector, true
+}
+
+// Inject injects the fault set at a point with the injector enabled process
+// wide, it returns immediately when no injector is enabled.
+func Inject(p Point) error {
+	i, ok := Enabled()
+	if !ok {
+		return nil
+	}
+	return i.Inject(p)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fault
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectorInject(t *testing.T) {
+	var slept []time.Duration
+	injector := NewInjector()
+	injector.sleepFn = func(d time.Duration) {
+		slept = append(slept, d)
+	}
+
+	// Nothing is injected at points without a fault.
+	require.NoError(t, injector.Inject(KVGet))
+
+	injectedErr := errors.New("fsync failed")
+	require.NoError(t, injector.Set(CommitLogFsync, Fault{
+		Latency: time.Second,
+		Error:   injectedErr,
+		After:   1,
+		Times:   2,
+	}))
+
+	var errs []error
+	for i := 0; i < 4; i++ {
+		errs = append(errs, injector.Inject(CommitLogFsync))
+	}
+	assert.Equal(t, []error{nil, injectedErr, injectedErr, nil}, errs)
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, slept)
+	assert.Equal(t, []Status{
+		{
+			Point:    CommitLogFsync,
+			Latency:  time.Second,
+			Error:    "fsync failed",
+			After:    1,
+			Times:    2,
+			Hits:     4,
+			Injected: 2,
+		},
+	}, injector.Status())
+
+	injector.Clear(CommitLogFsync)
+	require.NoError(t, injector.Inject(CommitLogFsync))
+	assert.Empty(t, injector.Status())
+}
+
+func TestInjectorSetInvalid(t *testing.T) {
+	injector := NewInjector()
+	require.Error(t, injector.Set(Point("unknown"), Fault{Error: ErrInjected}))
+	require.Error(t, injector.Set(BlockRead, Fault{Times: -1}))
+	assert.Empty(t, injector.Status())
+}
+
+func TestInjectGlobal(t *testing.T) {
+	defer Disable()
+
+	injector := NewInjector()
+	require.NoError(t, injector.Set(BlockRead, Fault{Error: ErrInjected}))
+
+	// Faults are only injected once enabled.
+	require.NoError(t, Inject(BlockRead))
+
+	Enable(injector)
+	enabled, ok := Enabled()
+	require.True(t, ok)
+	assert.Equal(t, injector, enabled)
+	assert.Equal(t, ErrInjected, Inject(BlockRead))
+
+	Disable()
+	_, ok = Enabled()
+	require.False(t, ok)
+	require.NoError(t, Inject(BlockRead))
+}
+
+func TestConfigurationNewInjector(t *testing.T) {
+	cfg := Configuration{
+		Enabled: true,
+		Faults: []FaultConfiguration{
+			{Point: KVWrite, Error: "etcd unavailable", Times: 1},
+			{Point: PeerStreamBlocks, Latency: time.Millisecond},
+		},
+	}
+	injector, err := cfg.NewInjector()
+	require.NoError(t, err)
+	injector.sleepFn = func(time.Duration) {}
+
+	err = injector.Inject(KVWrite)
+	require.Error(t, err)
+	assert.Equal(t, "etcd unavailable", err.Error())
+	require.NoError(t, injector.Inject(KVWrite))
+	require.NoError(t, injector.Inject(PeerStreamBlocks))
+
+	cfg.Faults = append(cfg.Faults, FaultConfiguration{Point: "unknown"})
+	_, err = cfg.NewInjector()
+	require.Error(t, err)
+}
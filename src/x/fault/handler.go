@@ -0,0 +1,95 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HandlerURL is the URL the handler is conventionally registered at.
+const HandlerURL = "/debug/fault"
+
+type setFaultRequest struct {
+	Point   Point  `json:"point"`
+	Latency string `json:"latency"`
+	Error   string `json:"error"`
+	After   int    `json:"after"`
+	Times   int    `json:"times"`
+}
+
+// NewHandler returns a handler that controls the faults injected by the
+// injector at runtime. GET returns the status of the faults set, POST sets a
+// fault and DELETE clears the fault of the point query parameter, or all
+// faults if no point is specified.
+func NewHandler(injector *Injector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			if err := setFault(injector, r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			if p := r.URL.Query().Get("point"); p != "" {
+				injector.Clear(Point(p))
+			} else {
+				injector.ClearAll()
+			}
+		default:
+			http.Error(w, fmt.Sprintf("unsupported method: %s", r.Method),
+				http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(injector.Status()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func setFault(injector *Injector, r *http.Request) error {
+	var req setFaultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return fmt.Errorf("unable to decode fault: %w", err)
+	}
+
+	f := Fault{
+		After: req.After,
+		Times: req.Times,
+	}
+	if req.Latency != "" {
+		latency, err := time.ParseDuration(req.Latency)
+		if err != nil {
+			return fmt.Errorf("invalid fault latency: %w", err)
+		}
+		f.Latency = latency
+	}
+	if req.Error != "" {
+		f.Error = errors.New(req.Error)
+	}
+	return injector.Set(req.Point, f)
+}
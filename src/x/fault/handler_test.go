@@ -0,0 +1,78 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	injector := NewInjector()
+	handler := NewHandler(injector)
+
+	serve := func(method, target, body string) ([]Status, int) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			return nil, w.Code
+		}
+		var statuses []Status
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+		return statuses, w.Code
+	}
+
+	statuses, code := serve(http.MethodPost, HandlerURL,
+		`{"point":"block.read","latency":"10ms","error":"read failed","times":3}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []Status{
+		{
+			Point:   BlockRead,
+			Latency: 10 * time.Millisecond,
+			Error:   "read failed",
+			Times:   3,
+		},
+	}, statuses)
+
+	_, code = serve(http.MethodPost, HandlerURL, `{"point":"unknown"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	_, code = serve(http.MethodPost, HandlerURL, `{"point":"kv.get","latency":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	statuses, code = serve(http.MethodGet, HandlerURL, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, statuses, 1)
+
+	statuses, code = serve(http.MethodDelete, HandlerURL+"?point=block.read", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, statuses)
+
+	_, code = serve(http.MethodPut, HandlerURL, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
@@ -81,6 +81,21 @@ const (
 	// "1m:14d;5m:60d"
 	MetricsRestrictByStoragePoliciesHeader = M3HeaderPrefix + "Restrict-By-Storage-Policies"
 
+	// MetricsRestrictByNamespaceHeader provides the namespace to restrict
+	// queries to by name, aliases of renamed namespaces are resolved to the
+	// current name of the namespace.
+	MetricsRestrictByNamespaceHeader = M3HeaderPrefix + "Restrict-By-Namespace"
+
+	// StitchingPolicyHeader overrides the policy used to stitch the results of
+	// unaggregated and aggregated namespaces for a query, valid values are
+	// "default", "prefer_unaggregated", "prefer_aggregated" and "cutover".
+	StitchingPolicyHeader = M3HeaderPrefix + "Stitching-Policy"
+
+	// StitchingCutoverHeader provides the cut-over timestamp used by the
+	// "cutover" stitching policy for a query, overriding the cut-over
+	// configured for each namespace pair.
+	StitchingCutoverHeader = M3HeaderPrefix + "Stitching-Cutover"
+
 	// RestrictByTagsJSONHeader provides tag options to enforces on queries,
 	// in JSON format. See `handler.stringTagOptions` for definitions.`
 	RestrictByTagsJSONHeader = M3HeaderPrefix + "Restrict-By-Tags-JSON"
@@ -134,6 +149,24 @@ const (
 	// the number of metric metadata stats returned in M3-Metric-Stats.
 	LimitMaxMetricMetadataStatsHeader = M3HeaderPrefix + "Limit-Max-Metric-Metadata-Stats"
 
+	// EvaluationTimeHeader pins the time a query is evaluated relative to,
+	// i.e. the value used for "now" when resolving the default query time
+	// and relative ranges, so that a query can be reproduced later.
+	EvaluationTimeHeader = M3HeaderPrefix + "Evaluation-Time"
+
+	// QueryCostEstimateHeader if set to true returns the estimated cost of a
+	// query instead of executing it.
+	QueryCostEstimateHeader = M3HeaderPrefix + "Query-Cost-Estimate"
+
+	// QueryCostForceHeader if set to true executes a query even if its
+	// estimated cost exceeds the query cost thresholds of the requester.
+	QueryCostForceHeader = M3HeaderPrefix + "Query-Cost-Force"
+
+	// SampleRateHeader evaluates a query over a deterministic sample of the
+	// matched series, given as a rate in (0, 1], and scales sum and count
+	// aggregations so that the results approximate the full query.
+	SampleRateHeader = M3HeaderPrefix + "Sample-Rate"
+
 	// UnaggregatedStoragePolicy specifies the unaggregated storage policy.
 	UnaggregatedStoragePolicy = "unaggregated"
 
@@ -168,6 +201,10 @@ const (
 	// TimeoutHeader is the header added with the effective timeout.
 	TimeoutHeader = M3HeaderPrefix + "Timeout"
 
+	// ResultsSampledHeader is the header added with the sample rate used when
+	// results are approximate.
+	ResultsSampledHeader = M3HeaderPrefix + "Results-Sampled"
+
 	// LimitHeaderSeriesLimitApplied is the header applied when fetch results
 	// are maxed.
 	LimitHeaderSeriesLimitApplied = "max_fetch_series_limit_applied"
@@ -203,6 +240,10 @@ const (
 	// read by this query.
 	NamespacesHeader = M3HeaderPrefix + "Namespaces"
 
+	// StitchingPoliciesHeader is the header added that tracks the unique set of
+	// stitching policies applied to the results of this query.
+	StitchingPoliciesHeader = M3HeaderPrefix + "Stitching-Policies"
+
 	// FetchedResponsesHeader is the header added that tracks the number of M3DB responses
 	// read by this query.
 	FetchedResponsesHeader = M3HeaderPrefix + "Fetched-Responses"
@@ -211,6 +252,18 @@ const (
 	// of bytes returned by all fetch responses (counted by FetchedResponsesHeader).
 	FetchedBytesEstimateHeader = M3HeaderPrefix + "Fetched-Bytes-Estimate"
 
+	// EstimatedSeriesHeader is the header added with the estimated number of
+	// series fetched by a query, before it is executed.
+	EstimatedSeriesHeader = M3HeaderPrefix + "Estimated-Series"
+
+	// EstimatedDatapointsHeader is the header added with the estimated number
+	// of datapoints fetched by a query, before it is executed.
+	EstimatedDatapointsHeader = M3HeaderPrefix + "Estimated-Datapoints"
+
+	// EstimatedBytesHeader is the header added with the estimated number of
+	// compressed bytes fetched by a query, before it is executed.
+	EstimatedBytesHeader = M3HeaderPrefix + "Estimated-Bytes"
+
 	// FetchedMetadataCount is the header added that tracks the total amount of
 	// metadata that was fetched by the query, before computation.
 	FetchedMetadataCount = M3HeaderPrefix + "Metadata-Count"
@@ -28,6 +28,8 @@ import (
 	"sync"
 
 	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
 	xerrors "github.com/m3db/m3/src/x/errors"
 
 	"github.com/prometheus/prometheus/promql"
@@ -80,8 +82,35 @@ func (e errorWithCode) Code() int {
 
 // ErrorResponse is a generic response for an HTTP error.
 type ErrorResponse struct {
-	Status string `json:"status"`
-	Error  string `json:"error"`
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+	Code      string `json:"code,omitempty"`
+	Retryable *bool  `json:"retryable,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Limit     int64  `json:"limit,omitempty"`
+}
+
+func newErrorResponse(err error) ErrorResponse {
+	resp := ErrorResponse{Status: "error", Error: err.Error()}
+	details, ok := client.StructuredErrorDetails(err)
+
+	// NB: the thrift rendering of an RPC error includes its type and flags,
+	// which are already surfaced as details, so only its message is returned.
+	var rpcErr *rpc.Error
+	if errors.As(err, &rpcErr) {
+		resp.Error = rpcErr.Message
+		if !ok {
+			details, ok = tterrors.ErrorDetails(rpcErr), true
+		}
+	}
+
+	if ok {
+		resp.Code = details.Code.String()
+		resp.Retryable = &details.Retryable
+		resp.Resource = details.Resource
+		resp.Limit = details.Limit
+	}
+	return resp
 }
 
 type options struct {
@@ -113,7 +142,7 @@ func WriteError(w http.ResponseWriter, err error, opts ...WriteErrorOption) {
 	if o.response == nil {
 		w.Header().Set(HeaderContentType, ContentTypeJSON)
 		w.WriteHeader(statusCode)
-		json.NewEncoder(w).Encode(ErrorResponse{Status: "error", Error: err.Error()}) //nolint:errcheck
+		json.NewEncoder(w).Encode(newErrorResponse(err)) //nolint:errcheck
 	} else {
 		w.WriteHeader(statusCode)
 		w.Write(o.response)
@@ -146,11 +175,27 @@ func getStatusCode(err error) int {
 			// Also check for prom errors, which can be either a cancellation or a timeout.
 		} else if _, ok := err.(promql.ErrQueryCanceled); ok { // nolint:errorlint
 			return 499
+		} else if details, ok := client.StructuredErrorDetails(err); ok {
+			return statusCodeFromDetails(details)
 		}
 	}
 	return http.StatusInternalServerError
 }
 
+func statusCodeFromDetails(details xerrors.ErrorDetails) int {
+	switch details.Code {
+	case xerrors.CodeInvalidParams, xerrors.CodeResourceExhausted:
+		return http.StatusBadRequest
+	case xerrors.CodeNotFound:
+		return http.StatusNotFound
+	case xerrors.CodeTimeout:
+		return http.StatusGatewayTimeout
+	case xerrors.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
 // IsClientError returns true if this error would result in 4xx status code.
 func IsClientError(err error) bool {
 	code := getStatusCode(err)
@@ -84,6 +84,23 @@ func TestErrorStatus(t *testing.T) {
 			err:            NewError(errors.New("some error"), 504),
 			expectedStatus: 504,
 		},
+		{
+			name: "structured not found",
+			err: terrors.NewStructuredError(errors.New("not found"),
+				xerrors.NewErrorDetails(xerrors.CodeNotFound)),
+			expectedStatus: 404,
+		},
+		{
+			name: "structured unavailable",
+			err: xerrors.Wrap(terrors.NewStructuredError(errors.New("bootstrapping"),
+				xerrors.NewErrorDetails(xerrors.CodeUnavailable)), "wrapped"),
+			expectedStatus: 503,
+		},
+		{
+			name:           "resource exhausted",
+			err:            terrors.NewResourceExhaustedError(errors.New("limit")),
+			expectedStatus: 400,
+		},
 	}
 
 	for _, tt := range tests {
@@ -136,6 +153,25 @@ func TestErrorRewrite(t *testing.T) {
 	}
 }
 
+func TestErrorResponseStructuredDetails(t *testing.T) {
+	details := xerrors.NewErrorDetails(xerrors.CodeResourceExhausted)
+	details.Resource = "docs-matched"
+	details.Limit = 100
+	err := terrors.NewStructuredError(errors.New("query exceeded limit"), details)
+
+	recorder := httptest.NewRecorder()
+	WriteError(recorder, err)
+	assert.Equal(t, 400, recorder.Code)
+	assert.JSONEq(t, `{
+		"status":"error",
+		"error":"query exceeded limit",
+		"code":"resource_exhausted",
+		"retryable":false,
+		"resource":"docs-matched",
+		"limit":100
+	}`, recorder.Body.String())
+}
+
 func TestIsClientError(t *testing.T) {
 	tests := []struct {
 		err      error
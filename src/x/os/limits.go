@@ -24,10 +24,18 @@ package xos
 type ProcessLimits struct {
 	NoFileCurr    uint64 // RLIMIT_NOFILE Current
 	NoFileMax     uint64 // RLIMIT_NOFILE Max
+	MemlockCurr   uint64 // RLIMIT_MEMLOCK Current
+	MemlockMax    uint64 // RLIMIT_MEMLOCK Max
 	VMMaxMapCount int64  // corresponds to /proc/sys/vm/max_map_count
 	VMSwappiness  int64  // corresponds to /proc/sys/vm/swappiness
 }
 
+// DiskUsage captures the space usage of the filesystem holding a path.
+type DiskUsage struct {
+	TotalBytes     uint64
+	AvailableBytes uint64
+}
+
 // RaiseProcessNoFileToNROpenResult captures the result of trying to
 // raise the process num files open limit to the nr_open system value.
 type RaiseProcessNoFileToNROpenResult struct {
@@ -26,6 +26,8 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -49,6 +51,11 @@ func GetProcessLimits() (ProcessLimits, error) {
 		return ProcessLimits{}, err
 	}
 
+	var memlock unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_MEMLOCK, &memlock); err != nil {
+		return ProcessLimits{}, err
+	}
+
 	maxMap, err := sysctlInt64(vmMaxMapCountKey)
 	if err != nil {
 		return ProcessLimits{}, err
@@ -62,11 +69,26 @@ func GetProcessLimits() (ProcessLimits, error) {
 	return ProcessLimits{
 		NoFileCurr:    noFile.Cur,
 		NoFileMax:     noFile.Max,
+		MemlockCurr:   memlock.Cur,
+		MemlockMax:    memlock.Max,
 		VMMaxMapCount: maxMap,
 		VMSwappiness:  swap,
 	}, nil
 }
 
+// GetDiskUsage returns the space usage of the filesystem holding the path.
+func GetDiskUsage(path string) (DiskUsage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return DiskUsage{}, err
+	}
+	blockSize := uint64(stat.Bsize)
+	return DiskUsage{
+		TotalBytes:     stat.Blocks * blockSize,
+		AvailableBytes: stat.Bavail * blockSize,
+	}, nil
+}
+
 // RaiseProcessNoFileToNROpen first determines the NROpen limit by reading
 // the corresponding proc sys file and then if the hard or soft limits
 // are below this number, the limits are raised using a call to setrlimit.
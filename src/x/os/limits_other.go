@@ -32,6 +32,7 @@ const (
 
 var (
 	errUnableToDetermineProcessLimits     = errors.New(nonLinuxWarning)
+	errUnableToDetermineDiskUsage         = errors.New("unable to determine disk usage on non-linux os")
 	errUnableToRaiseProcessNoFileNonLinux = errors.New("unable to raise no file limits on non-linux os")
 )
 
@@ -46,6 +47,11 @@ func GetProcessLimits() (ProcessLimits, error) {
 	return ProcessLimits{}, errUnableToDetermineProcessLimits
 }
 
+// GetDiskUsage returns the space usage of the filesystem holding the path.
+func GetDiskUsage(path string) (DiskUsage, error) {
+	return DiskUsage{}, errUnableToDetermineDiskUsage
+}
+
 // RaiseProcessNoFileToNROpen attempts to raise the process num files
 // open limit to the nr_open system value.
 func RaiseProcessNoFileToNROpen() (RaiseProcessNoFileToNROpenResult, error) {
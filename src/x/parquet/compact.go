@@ -0,0 +1,140 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package parquet
+
+// Thrift compact protocol type identifiers.
+const (
+	compactTypeI32    byte = 5
+	compactTypeI64    byte = 6
+	compactTypeBinary byte = 8
+	compactTypeList   byte = 9
+	compactTypeStruct byte = 12
+)
+
+// compactWriter encodes the subset of the Thrift compact protocol needed to
+// write Parquet page headers and file metadata.
+type compactWriter struct {
+	buf         []byte
+	lastFieldID int16
+	fieldIDs    []int16
+}
+
+func (c *compactWriter) reset() {
+	c.buf = c.buf[:0]
+	c.lastFieldID = 0
+	c.fieldIDs = c.fieldIDs[:0]
+}
+
+func (c *compactWriter) varint(v uint64) {
+	for v >= 0x80 {
+		c.buf = append(c.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	c.buf = append(c.buf, byte(v))
+}
+
+func (c *compactWriter) zigzag32(v int32) {
+	c.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (c *compactWriter) zigzag64(v int64) {
+	c.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (c *compactWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - c.lastFieldID; delta > 0 && delta <= 15 {
+		c.buf = append(c.buf, byte(delta)<<4|typ)
+	} else {
+		c.buf = append(c.buf, typ)
+		c.zigzag32(int32(id))
+	}
+	c.lastFieldID = id
+}
+
+func (c *compactWriter) listHeader(elemType byte, size int) {
+	if size < 15 {
+		c.buf = append(c.buf, byte(size)<<4|elemType)
+		return
+	}
+	c.buf = append(c.buf, 0xf0|elemType)
+	c.varint(uint64(size))
+}
+
+func (c *compactWriter) i32Field(id int16, v int32) {
+	c.fieldHeader(id, compactTypeI32)
+	c.zigzag32(v)
+}
+
+func (c *compactWriter) i64Field(id int16, v int64) {
+	c.fieldHeader(id, compactTypeI64)
+	c.zigzag64(v)
+}
+
+func (c *compactWriter) binaryField(id int16, v string) {
+	c.fieldHeader(id, compactTypeBinary)
+	c.binary(v)
+}
+
+func (c *compactWriter) binary(v string) {
+	c.varint(uint64(len(v)))
+	c.buf = append(c.buf, v...)
+}
+
+func (c *compactWriter) i32ListField(id int16, values ...int32) {
+	c.fieldHeader(id, compactTypeList)
+	c.listHeader(compactTypeI32, len(values))
+	for _, v := range values {
+		c.zigzag32(v)
+	}
+}
+
+func (c *compactWriter) binaryListField(id int16, values ...string) {
+	c.fieldHeader(id, compactTypeList)
+	c.listHeader(compactTypeBinary, len(values))
+	for _, v := range values {
+		c.binary(v)
+	}
+}
+
+// structListField begins a list of size structs, each must be written
+// between calls to structBegin and structEnd.
+func (c *compactWriter) structListField(id int16, size int) {
+	c.fieldHeader(id, compactTypeList)
+	c.listHeader(compactTypeStruct, size)
+}
+
+func (c *compactWriter) structField(id int16) {
+	c.fieldHeader(id, compactTypeStruct)
+	c.structBegin()
+}
+
+func (c *compactWriter) structBegin() {
+	c.fieldIDs = append(c.fieldIDs, c.lastFieldID)
+	c.lastFieldID = 0
+}
+
+func (c *compactWriter) structEnd() {
+	c.buf = append(c.buf, 0)
+	if n := len(c.fieldIDs); n > 0 {
+		c.lastFieldID = c.fieldIDs[n-1]
+		c.fieldIDs = c.fieldIDs[:n-1]
+	}
+}
@@ -0,0 +1,423 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package parquet provides a minimal writer for flat, uncompressed Parquet
+// files with PLAIN encoded columns.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	defaultRowGroupSize = 1 << 16
+	defaultCreatedBy    = "m3"
+)
+
+var (
+	magic = []byte("PAR1")
+
+	errWriterClosed   = errors.New("parquet writer is closed")
+	errNoColumns      = errors.New("parquet schema must have at least one column")
+	errEmptyColumn    = errors.New("parquet column name must not be empty")
+	errNullNotAllowed = errors.New("null value for required parquet column")
+)
+
+// Parquet physical types, converted types and encodings, see parquet.thrift.
+const (
+	physicalTypeInt64     int32 = 2
+	physicalTypeDouble    int32 = 5
+	physicalTypeByteArray int32 = 6
+
+	convertedTypeUTF8            int32 = 0
+	convertedTypeTimestampMillis int32 = 9
+
+	repetitionRequired int32 = 0
+	repetitionOptional int32 = 1
+
+	encodingPlain int32 = 0
+	encodingRLE   int32 = 3
+
+	pageTypeData int32 = 0
+)
+
+// ColumnType is the type of a column.
+type ColumnType int
+
+const (
+	// StringColumnType is a UTF8 string column.
+	StringColumnType ColumnType = iota
+	// Int64ColumnType is a 64 bit integer column.
+	Int64ColumnType
+	// TimestampMillisColumnType is a timestamp column in milliseconds since the epoch.
+	TimestampMillisColumnType
+	// DoubleColumnType is a 64 bit floating point column.
+	DoubleColumnType
+)
+
+// Column describes a column of the file schema.
+type Column struct {
+	Name     string
+	Type     ColumnType
+	Optional bool
+}
+
+// Value is the value of a column in a row, only the field matching the
+// column type is used.
+type Value struct {
+	Bytes  []byte
+	Int64  int64
+	Double float64
+	Null   bool
+}
+
+// WriterOptions are options for a writer.
+type WriterOptions struct {
+	// RowGroupSize is the number of rows buffered before they are written as
+	// a row group.
+	RowGroupSize int
+	// CreatedBy is recorded in the file metadata.
+	CreatedBy string
+}
+
+// Writer writes rows to a Parquet file, rows are buffered in memory until a
+// row group is full or is flushed so memory use is bounded by the row group
+// size.
+type Writer struct {
+	w         io.Writer
+	offset    int64
+	columns   []Column
+	opts      WriterOptions
+	chunks    []columnChunk
+	rowGroups []rowGroupMetadata
+	rows      int
+	numRows   int64
+	compact   compactWriter
+	scratch   [8]byte
+	started   bool
+	closed    bool
+}
+
+type columnChunk struct {
+	defLevels []bool
+	values    []byte
+}
+
+type rowGroupMetadata struct {
+	columns       []columnChunkMetadata
+	totalByteSize int64
+	numRows       int64
+}
+
+type columnChunkMetadata struct {
+	dataPageOffset int64
+	totalSize      int64
+	numValues      int64
+}
+
+// NewWriter returns a new writer of a file with the given columns.
+func NewWriter(w io.Writer, columns []Column, opts WriterOptions) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errNoColumns
+	}
+	seen := make(map[string]struct{}, len(columns))
+	for _, c := range columns {
+		if c.Name == "" {
+			return nil, errEmptyColumn
+		}
+		if _, ok := seen[c.Name]; ok {
+			return nil, fmt.Errorf("duplicate parquet column: %s", c.Name)
+		}
+		seen[c.Name] = struct{}{}
+	}
+	if opts.RowGroupSize <= 0 {
+		opts.RowGroupSize = defaultRowGroupSize
+	}
+	if opts.CreatedBy == "" {
+		opts.CreatedBy = defaultCreatedBy
+	}
+
+	return &Writer{
+		w:       w,
+		columns: append([]Column(nil), columns...),
+		opts:    opts,
+		chunks:  make([]columnChunk, len(columns)),
+	}, nil
+}
+
+// Write writes a row, the values must be in the order of the columns.
+func (w *Writer) Write(row []Value) error {
+	if w.closed {
+		return errWriterClosed
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("expected %d parquet values, got %d", len(w.columns), len(row))
+	}
+	for i, v := range row {
+		if v.Null && !w.columns[i].Optional {
+			return fmt.Errorf("%w: %s", errNullNotAllowed, w.columns[i].Name)
+		}
+	}
+
+	for i, v := range row {
+		chunk := &w.chunks[i]
+		if w.columns[i].Optional {
+			chunk.defLevels = append(chunk.defLevels, !v.Null)
+		}
+		if v.Null {
+			continue
+		}
+		switch w.columns[i].Type {
+		case StringColumnType:
+			binary.LittleEndian.PutUint32(w.scratch[:4], uint32(len(v.Bytes)))
+			chunk.values = append(chunk.values, w.scratch[:4]...)
+			chunk.values = append(chunk.values, v.Bytes...)
+		case Int64ColumnType, TimestampMillisColumnType:
+			binary.LittleEndian.PutUint64(w.scratch[:], uint64(v.Int64))
+			chunk.values = append(chunk.values, w.scratch[:]...)
+		case DoubleColumnType:
+			binary.LittleEndian.PutUint64(w.scratch[:], math.Float64bits(v.Double))
+			chunk.values = append(chunk.values, w.scratch[:]...)
+		}
+	}
+
+	w.rows++
+	if w.rows >= w.opts.RowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+// Flush writes any buffered rows as a row group, this lets callers that
+// produce rows in batches write a row group per batch rather than buffering
+// rows across batches.
+func (w *Writer) Flush() error {
+	if w.closed {
+		return errWriterClosed
+	}
+	return w.flushRowGroup()
+}
+
+// Close writes any buffered rows and the file footer, it does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return errWriterClosed
+	}
+	w.closed = true
+
+	if err := w.flushRowGroup(); err != nil {
+		return err
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+
+	w.encodeFileMetadata()
+	footerLen := len(w.compact.buf)
+	if err := w.write(w.compact.buf); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(w.scratch[:4], uint32(footerLen))
+	if err := w.write(w.scratch[:4]); err != nil {
+		return err
+	}
+	return w.write(magic)
+}
+
+func (w *Writer) writeMagic() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.write(magic)
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// flushRowGroup writes the buffered rows as a row group with a single data
+// page per column.
+func (w *Writer) flushRowGroup() error {
+	if w.rows == 0 {
+		return nil
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+
+	rowGroup := rowGroupMetadata{
+		columns: make([]columnChunkMetadata, 0, len(w.columns)),
+		numRows: int64(w.rows),
+	}
+	for i, c := range w.columns {
+		chunk := &w.chunks[i]
+
+		var levels []byte
+		if c.Optional {
+			levels = encodeDefinitionLevels(chunk.defLevels)
+		}
+		pageSize := len(levels) + len(chunk.values)
+
+		w.compact.reset()
+		w.compact.structBegin()
+		w.compact.i32Field(1, pageTypeData)
+		w.compact.i32Field(2, int32(pageSize))
+		w.compact.i32Field(3, int32(pageSize))
+		w.compact.structField(5)
+		w.compact.i32Field(1, int32(w.rows))
+		w.compact.i32Field(2, encodingPlain)
+		w.compact.i32Field(3, encodingRLE)
+		w.compact.i32Field(4, encodingRLE)
+		w.compact.structEnd()
+		w.compact.structEnd()
+
+		pageOffset := w.offset
+		if err := w.write(w.compact.buf); err != nil {
+			return err
+		}
+		if err := w.write(levels); err != nil {
+			return err
+		}
+		if err := w.write(chunk.values); err != nil {
+			return err
+		}
+
+		totalSize := w.offset - pageOffset
+		rowGroup.columns = append(rowGroup.columns, columnChunkMetadata{
+			dataPageOffset: pageOffset,
+			totalSize:      totalSize,
+			numValues:      int64(w.rows),
+		})
+		rowGroup.totalByteSize += totalSize
+
+		chunk.defLevels = chunk.defLevels[:0]
+		chunk.values = chunk.values[:0]
+	}
+
+	w.rowGroups = append(w.rowGroups, rowGroup)
+	w.numRows += int64(w.rows)
+	w.rows = 0
+	return nil
+}
+
+func (w *Writer) encodeFileMetadata() {
+	c := &w.compact
+	c.reset()
+	c.structBegin()
+	c.i32Field(1, 1)
+
+	c.structListField(2, len(w.columns)+1)
+	c.structBegin()
+	c.binaryField(4, "schema")
+	c.i32Field(5, int32(len(w.columns)))
+	c.structEnd()
+	for _, col := range w.columns {
+		physicalType, convertedType, hasConvertedType := columnTypes(col.Type)
+		repetition := repetitionRequired
+		if col.Optional {
+			repetition = repetitionOptional
+		}
+		c.structBegin()
+		c.i32Field(1, physicalType)
+		c.i32Field(3, repetition)
+		c.binaryField(4, col.Name)
+		if hasConvertedType {
+			c.i32Field(6, convertedType)
+		}
+		c.structEnd()
+	}
+
+	c.i64Field(3, w.numRows)
+
+	c.structListField(4, len(w.rowGroups))
+	for _, rowGroup := range w.rowGroups {
+		c.structBegin()
+		c.structListField(1, len(rowGroup.columns))
+		for i, chunk := range rowGroup.columns {
+			physicalType, _, _ := columnTypes(w.columns[i].Type)
+			c.structBegin()
+			c.i64Field(2, chunk.dataPageOffset)
+			c.structField(3)
+			c.i32Field(1, physicalType)
+			c.i32ListField(2, encodingPlain, encodingRLE)
+			c.binaryListField(3, w.columns[i].Name)
+			c.i32Field(4, 0) // Uncompressed.
+			c.i64Field(5, chunk.numValues)
+			c.i64Field(6, chunk.totalSize)
+			c.i64Field(7, chunk.totalSize)
+			c.i64Field(9, chunk.dataPageOffset)
+			c.structEnd()
+			c.structEnd()
+		}
+		c.i64Field(2, rowGroup.totalByteSize)
+		c.i64Field(3, rowGroup.numRows)
+		c.structEnd()
+	}
+
+	c.binaryField(6, w.opts.CreatedBy)
+	c.structEnd()
+}
+
+func columnTypes(t ColumnType) (physicalType int32, convertedType int32, hasConvertedType bool) {
+	switch t {
+	case StringColumnType:
+		return physicalTypeByteArray, convertedTypeUTF8, true
+	case TimestampMillisColumnType:
+		return physicalTypeInt64, convertedTypeTimestampMillis, true
+	case DoubleColumnType:
+		return physicalTypeDouble, 0, false
+	default:
+		return physicalTypeInt64, 0, false
+	}
+}
+
+// encodeDefinitionLevels encodes definition levels with a maximum level of one
+// using the RLE/bit-packing hybrid encoding prefixed by its length.
+func encodeDefinitionLevels(levels []bool) []byte {
+	encoded := make([]byte, 4, 16)
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		run := uint64(j-i) << 1
+		for run >= 0x80 {
+			encoded = append(encoded, byte(run)|0x80)
+			run >>= 7
+		}
+		encoded = append(encoded, byte(run))
+		if levels[i] {
+			encoded = append(encoded, 1)
+		} else {
+			encoded = append(encoded, 0)
+		}
+		i = j
+	}
+	binary.LittleEndian.PutUint32(encoded[:4], uint32(len(encoded)-4))
+	return encoded
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// compactReader decodes the Thrift compact protocol into generic values to
+// verify the encoded metadata, structs decode to maps keyed by field ID.
+type compactReader struct {
+	buf []byte
+}
+
+func (r *compactReader) byte() byte {
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *compactReader) varint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *compactReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) value(typ byte) interface{} {
+	switch typ {
+	case compactTypeI32, compactTypeI64:
+		return r.zigzag()
+	case compactTypeBinary:
+		n := r.varint()
+		v := string(r.buf[:n])
+		r.buf = r.buf[n:]
+		return v
+	case compactTypeList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			list = append(list, r.value(header&0x0f))
+		}
+		return list
+	case compactTypeStruct:
+		return r.structValue()
+	}
+	panic("unexpected compact type")
+}
+
+func (r *compactReader) structValue() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var lastID int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0f)
+		lastID = id
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []Column{
+		{Name: "name", Type: StringColumnType, Optional: true},
+		{Name: "timestamp", Type: TimestampMillisColumnType},
+		{Name: "value", Type: DoubleColumnType},
+	}, WriterOptions{RowGroupSize: 2})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write([]Value{{Bytes: []byte("a")}, {Int64: 1000}, {Double: 1.5}}))
+	require.NoError(t, w.Write([]Value{{Null: true}, {Int64: 2000}, {Double: 2.5}}))
+	require.NoError(t, w.Write([]Value{{Bytes: []byte("bc")}, {Int64: 3000}, {Double: 3.5}}))
+	require.Error(t, w.Write([]Value{{Bytes: []byte("a")}, {Null: true}, {Double: 1}}))
+	require.NoError(t, w.Close())
+	require.Error(t, w.Write([]Value{{Null: true}, {Int64: 1}, {Double: 1}}))
+
+	data := buf.Bytes()
+	require.Equal(t, magic, data[:4])
+	require.Equal(t, magic, data[len(data)-4:])
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &compactReader{buf: data[len(data)-8-footerLen : len(data)-8]}
+	meta := footer.structValue()
+	require.Empty(t, footer.buf)
+
+	require.Equal(t, int64(3), meta[3])
+	schema := meta[2].([]interface{})
+	require.Len(t, schema, 4)
+	require.Equal(t, int64(3), schema[0].(map[int16]interface{})[5])
+	require.Equal(t, "name", schema[1].(map[int16]interface{})[4])
+	require.Equal(t, int64(repetitionOptional), schema[1].(map[int16]interface{})[3])
+	require.Equal(t, int64(convertedTypeTimestampMillis), schema[2].(map[int16]interface{})[6])
+
+	rowGroups := meta[4].([]interface{})
+	require.Len(t, rowGroups, 2)
+	require.Equal(t, int64(2), rowGroups[0].(map[int16]interface{})[3])
+	require.Equal(t, int64(1), rowGroups[1].(map[int16]interface{})[3])
+
+	columnMeta := func(rowGroup, column int) map[int16]interface{} {
+		chunks := rowGroups[rowGroup].(map[int16]interface{})[1].([]interface{})
+		return chunks[column].(map[int16]interface{})[3].(map[int16]interface{})
+	}
+	readPage := func(rowGroup, column int) []byte {
+		offset := columnMeta(rowGroup, column)[9].(int64)
+		page := &compactReader{buf: data[offset:]}
+		header := page.structValue()
+		require.Equal(t, header[2], header[3])
+		return page.buf[:header[2].(int64)]
+	}
+
+	// The name column of the first row group has a null second value.
+	page := readPage(0, 0)
+	levelsLen := binary.LittleEndian.Uint32(page)
+	require.Equal(t, []byte{1 << 1, 1, 1 << 1, 0}, page[4:4+levelsLen])
+	require.Equal(t, []byte{1, 0, 0, 0, 'a'}, page[4+levelsLen:])
+
+	page = readPage(1, 0)
+	levelsLen = binary.LittleEndian.Uint32(page)
+	require.Equal(t, []byte{1 << 1, 1}, page[4:4+levelsLen])
+	require.Equal(t, []byte{2, 0, 0, 0, 'b', 'c'}, page[4+levelsLen:])
+
+	page = readPage(0, 1)
+	require.Equal(t, uint64(1000), binary.LittleEndian.Uint64(page))
+	require.Equal(t, uint64(2000), binary.LittleEndian.Uint64(page[8:]))
+
+	page = readPage(1, 2)
+	require.Equal(t, 3.5, math.Float64frombits(binary.LittleEndian.Uint64(page)))
+	require.Equal(t, int64(1), columnMeta(1, 2)[5])
+}
+
+func TestWriterFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []Column{{Name: "value", Type: Int64ColumnType}}, WriterOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write([]Value{{Int64: 1}}))
+	require.NoError(t, w.Write([]Value{{Int64: 2}}))
+	require.NoError(t, w.Flush())
+	// The buffered rows are written once flushed.
+	flushed := buf.Len()
+	require.True(t, flushed > len(magic))
+	// Flushing without buffered rows does not write an empty row group.
+	require.NoError(t, w.Flush())
+	require.Equal(t, flushed, buf.Len())
+	require.NoError(t, w.Write([]Value{{Int64: 3}}))
+	require.NoError(t, w.Close())
+	require.Error(t, w.Flush())
+
+	data := buf.Bytes()
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &compactReader{buf: data[len(data)-8-footerLen : len(data)-8]}
+	meta := footer.structValue()
+	require.Equal(t, int64(3), meta[3])
+	rowGroups := meta[4].([]interface{})
+	require.Len(t, rowGroups, 2)
+	require.Equal(t, int64(2), rowGroups[0].(map[int16]interface{})[3])
+	require.Equal(t, int64(1), rowGroups[1].(map[int16]interface{})[3])
+}
+
+func TestWriterInvalidSchema(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, nil, WriterOptions{})
+	require.Error(t, err)
+	_, err = NewWriter(&bytes.Buffer{}, []Column{{Name: ""}}, WriterOptions{})
+	require.Error(t, err)
+	_, err = NewWriter(&bytes.Buffer{}, []Column{{Name: "a"}, {Name: "a"}}, WriterOptions{})
+	require.Error(t, err)
+}
+
+func TestWriterLongList(t *testing.T) {
+	columns := make([]Column, 0, 20)
+	row := make([]Value, 0, 20)
+	for i := 0; i < 20; i++ {
+		columns = append(columns, Column{Name: string(rune('a' + i)), Type: Int64ColumnType})
+		row = append(row, Value{Int64: int64(i)})
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, columns, WriterOptions{})
+	require.NoError(t, err)
+	require.NoError(t, w.Write(row))
+	require.NoError(t, w.Close())
+
+	data := buf.Bytes()
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &compactReader{buf: data[len(data)-8-footerLen : len(data)-8]}
+	meta := footer.structValue()
+	require.Len(t, meta[2].([]interface{}), 21)
+	require.Equal(t, defaultCreatedBy, meta[6])
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ratelimit
+
+// Configuration is the configuration of a limiter and its children.
+type Configuration struct {
+	// Rate is the number of tokens per second, zero is unlimited.
+	Rate float64 `yaml:"rate" validate:"min=0"`
+
+	// Burst is the number of tokens that accumulate while the limiter is
+	// unused, if zero then one second worth of tokens.
+	Burst int64 `yaml:"burst" validate:"min=0"`
+
+	// Children are the configurations of the children of the limiter by name.
+	Children map[string]Configuration `yaml:"children"`
+}
+
+// Limit returns the limit of the configured limiter.
+func (c Configuration) Limit() Limit {
+	return Limit{Rate: c.Rate, Burst: c.Burst}
+}
+
+// NewLimiter returns a new root limiter with the given name along with its
+// configured descendants.
+func (c Configuration) NewLimiter(name string, opts Options) Limiter {
+	l := NewLimiter(name, c.Limit(), opts)
+	c.newChildren(l)
+	return l
+}
+
+func (c Configuration) newChildren(parent Limiter) {
+	for name, child := range c.Children {
+		child.newChildren(parent.Child(name, child.Limit()))
+	}
+}
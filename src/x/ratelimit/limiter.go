@@ -0,0 +1,429 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv"
+	"github.com/m3db/m3/src/cluster/kv/util"
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+var (
+	errAlreadyWatching = errors.New("rate limiters are already watching kv")
+	errNoKVStore       = errors.New("no kv store to watch")
+	errLimitersClosed  = errors.New("rate limiters are closed")
+)
+
+// tree is shared by all limiters of a tree, its lock guards the buckets of
+// all of them so that tokens are taken from a limiter and its ancestors
+// atomically.
+type tree struct {
+	sync.Mutex
+
+	root      *limiter
+	store     kv.Store
+	keyPrefix string
+	nowFn     clock.NowFn
+	logger    *zap.Logger
+	utilOpts  util.Options
+	scope     tally.Scope
+
+	watches  []kv.ValueWatch
+	closed   bool
+	closedCh chan struct{}
+}
+
+type limiterMetrics struct {
+	consumed  tally.Counter
+	throttled tally.Counter
+	wait      tally.Timer
+	rate      tally.Gauge
+}
+
+func newLimiterMetrics(scope tally.Scope, name string) limiterMetrics {
+	scope = scope.Tagged(map[string]string{"limiter": name})
+	return limiterMetrics{
+		consumed:  scope.Counter("consumed"),
+		throttled: scope.Counter("throttled"),
+		wait:      scope.Timer("wait-latency"),
+		rate:      scope.Gauge("rate"),
+	}
+}
+
+type limiter struct {
+	tree     *tree
+	name     string
+	key      string
+	parent   *limiter
+	children map[string]*limiter
+	metrics  limiterMetrics
+
+	defaultLimit Limit
+	kvRate       *float64
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	consumed     int64
+}
+
+// NewLimiter returns a new root limiter.
+func NewLimiter(name string, defaultLimit Limit, opts Options) Limiter {
+	logger := opts.InstrumentOptions().Logger()
+	t := &tree{
+		keyPrefix: opts.KeyPrefix(),
+		nowFn:     opts.ClockOptions().NowFn(),
+		logger:    logger,
+		utilOpts:  util.NewOptions().SetLogger(logger).SetValidateFn(validateRate),
+		scope:     opts.InstrumentOptions().MetricsScope().SubScope("rate-limiter"),
+		closedCh:  make(chan struct{}),
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.root = t.newLimiterWithLock(nil, name, defaultLimit)
+	return t.root
+}
+
+func (l *limiter) Name() string {
+	return l.name
+}
+
+func (l *limiter) Limit() Limit {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	if l.rate <= 0 {
+		return Limit{Burst: l.defaultLimit.Burst}
+	}
+	return Limit{Rate: l.rate, Burst: int64(l.burst)}
+}
+
+func (l *limiter) SetLimit(value Limit) {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	l.defaultLimit = value
+	l.updateWithLock()
+}
+
+func (l *limiter) WaitN(ctx context.Context, n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	wait := l.reserve(n)
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.refund(n)
+			return ctx.Err()
+		case <-timer.C:
+		}
+		l.metrics.throttled.Inc(1)
+	}
+
+	l.metrics.wait.Record(wait)
+	for curr := l; curr != nil; curr = curr.parent {
+		curr.metrics.consumed.Inc(n)
+	}
+	return nil
+}
+
+// reserve takes n tokens from the limiter and its ancestors, going into debt
+// if they do not have enough, and returns how long to wait until the longest
+// of the debts is paid off.
+func (l *limiter) reserve(n int64) time.Duration {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	var (
+		now  = l.tree.nowFn()
+		wait time.Duration
+	)
+	for curr := l; curr != nil; curr = curr.parent {
+		curr.advanceWithLock(now)
+		curr.consumed += n
+		if curr.rate <= 0 {
+			continue
+		}
+		curr.tokens -= float64(n)
+		if curr.tokens >= 0 {
+			continue
+		}
+		if w := time.Duration(-curr.tokens / curr.rate * float64(time.Second)); w > wait {
+			wait = w
+		}
+	}
+	return wait
+}
+
+// refund returns n tokens reserved but not used to the limiter and its
+// ancestors.
+func (l *limiter) refund(n int64) {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	now := l.tree.nowFn()
+	for curr := l; curr != nil; curr = curr.parent {
+		curr.advanceWithLock(now)
+		curr.consumed -= n
+		if curr.rate <= 0 {
+			continue
+		}
+		curr.tokens = math.Min(curr.tokens+float64(n), curr.burst)
+	}
+}
+
+func (l *limiter) advanceWithLock(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 && l.rate > 0 {
+		l.tokens = math.Min(l.tokens+elapsed.Seconds()*l.rate, l.burst)
+	}
+	l.last = now
+}
+
+// updateWithLock applies a change of the default limit or of the rate in kv.
+func (l *limiter) updateWithLock() {
+	rate := l.defaultLimit.Rate
+	if l.kvRate != nil {
+		rate = *l.kvRate
+	}
+
+	// Accrue the tokens earned at the previous rate before changing it.
+	wasUnlimited := l.rate <= 0
+	l.advanceWithLock(l.tree.nowFn())
+
+	l.rate = rate
+	l.burst = float64(l.defaultLimit.Burst)
+	if l.burst <= 0 {
+		l.burst = math.Max(math.Ceil(rate), 1)
+	}
+	if wasUnlimited {
+		l.tokens = l.burst
+	}
+	l.tokens = math.Min(l.tokens, l.burst)
+	l.metrics.rate.Update(math.Max(rate, 0))
+}
+
+func (l *limiter) update(v kv.Value) {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	l.updateFromValueWithLock(v)
+}
+
+func (l *limiter) updateFromValueWithLock(v kv.Value) {
+	if v == nil {
+		// The key does not exist or was deleted, use the default rate.
+		l.kvRate = nil
+		l.updateWithLock()
+		return
+	}
+
+	rate, err := util.Float64FromValue(v, l.key, 0, l.tree.utilOpts)
+	if err != nil {
+		// Malformed and invalid values are logged by the parse function, keep
+		// the current rate.
+		return
+	}
+	l.kvRate = &rate
+	l.updateWithLock()
+}
+
+func (l *limiter) Child(name string, defaultLimit Limit) Limiter {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	if child, ok := l.children[name]; ok {
+		return child
+	}
+	return l.tree.newLimiterWithLock(l, name, defaultLimit)
+}
+
+func (l *limiter) States() []State {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	var states []State
+	l.appendStatesWithLock(&states, l.tree.nowFn())
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+func (l *limiter) appendStatesWithLock(states *[]State, now time.Time) {
+	l.advanceWithLock(now)
+	state := State{
+		Name:     l.name,
+		Default:  l.defaultLimit.Rate,
+		Source:   DefaultSource,
+		Consumed: l.consumed,
+	}
+	if l.tree.store != nil {
+		state.Key = l.key
+	}
+	if l.kvRate != nil {
+		state.Source = KVSource
+	}
+	if l.rate > 0 {
+		state.Rate = l.rate
+		state.Burst = int64(l.burst)
+		state.Tokens = l.tokens
+	}
+	*states = append(*states, state)
+
+	for _, child := range l.children {
+		child.appendStatesWithLock(states, now)
+	}
+}
+
+func (l *limiter) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(l.States()); err != nil {
+			l.tree.logger.Error("unable to encode rate limiters", zap.Error(err))
+		}
+	})
+}
+
+func (l *limiter) Watch(store kv.Store) error {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	if store == nil {
+		return errNoKVStore
+	}
+	if l.tree.closed {
+		return errLimitersClosed
+	}
+	if l.tree.store != nil {
+		return errAlreadyWatching
+	}
+	l.tree.store = store
+	l.tree.root.watchAllWithLock()
+	return nil
+}
+
+func (l *limiter) watchAllWithLock() {
+	l.tree.watchWithLock(l)
+	for _, child := range l.children {
+		child.watchAllWithLock()
+	}
+}
+
+func (l *limiter) Close() {
+	l.tree.Lock()
+	defer l.tree.Unlock()
+
+	if l.tree.closed {
+		return
+	}
+	l.tree.closed = true
+	close(l.tree.closedCh)
+	for _, w := range l.tree.watches {
+		w.Close()
+	}
+	l.tree.watches = nil
+}
+
+func (t *tree) newLimiterWithLock(parent *limiter, name string, defaultLimit Limit) *limiter {
+	fullName := name
+	if parent != nil {
+		fullName = parent.name + "." + name
+	}
+
+	l := &limiter{
+		tree:         t,
+		name:         fullName,
+		key:          t.keyPrefix + fullName,
+		parent:       parent,
+		children:     make(map[string]*limiter),
+		metrics:      newLimiterMetrics(t.scope, fullName),
+		defaultLimit: defaultLimit,
+		last:         t.nowFn(),
+	}
+	l.updateWithLock()
+	if parent != nil {
+		parent.children[name] = l
+	}
+
+	if t.store != nil && !t.closed {
+		t.watchWithLock(l)
+	}
+	return l
+}
+
+func (t *tree) watchWithLock(l *limiter) {
+	// Failing to watch kv only prevents runtime adjustments of the rate so
+	// keep using the default rate rather than failing.
+	v, err := t.store.Get(l.key)
+	if err != nil && err != kv.ErrNotFound {
+		t.logger.Error("could not get rate limit, using default",
+			zap.String("key", l.key), zap.Error(err))
+	} else {
+		l.updateFromValueWithLock(v)
+	}
+
+	watch, err := t.store.Watch(l.key)
+	if err != nil {
+		t.logger.Error("could not watch rate limit, using default",
+			zap.String("key", l.key), zap.Error(err))
+		return
+	}
+	t.watches = append(t.watches, watch)
+
+	go func() {
+		for {
+			select {
+			case <-t.closedCh:
+				return
+			case _, ok := <-watch.C():
+				if !ok {
+					return
+				}
+				l.update(watch.Get())
+			}
+		}
+	}()
+}
+
+func validateRate(v interface{}) error {
+	if rate := v.(float64); rate < 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return fmt.Errorf("invalid rate limit: value=%f", rate)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/x/clock"
+
+	"github.com/fortytw2/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestOptions() (Options, *testClock) {
+	c := &testClock{now: time.Unix(1000, 0)}
+	opts := NewOptions().SetClockOptions(clock.NewOptions().SetNowFn(c.Now))
+	return opts, c
+}
+
+func waitFor(t *testing.T, fn func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !fn() {
+		require.True(t, time.Now().Before(deadline), "condition not met in time")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	opts, c := newTestOptions()
+	root := NewLimiter("disk", Limit{Rate: 10}, opts).(*limiter)
+	defer root.Close()
+
+	require.Equal(t, Limit{Rate: 10, Burst: 10}, root.Limit())
+	require.Equal(t, time.Duration(0), root.reserve(10))
+	require.Equal(t, 500*time.Millisecond, root.reserve(5))
+
+	// Tokens are earned back at the rate and debt delays later reservations.
+	c.now = c.now.Add(time.Second)
+	require.Equal(t, time.Duration(0), root.reserve(5))
+	require.Equal(t, time.Second, root.reserve(10))
+
+	// Tokens do not accumulate past the burst.
+	c.now = c.now.Add(time.Minute)
+	require.Equal(t, time.Duration(0), root.reserve(10))
+	require.Equal(t, 100*time.Millisecond, root.reserve(1))
+}
+
+func TestLimiterChildrenShareParentBudget(t *testing.T) {
+	opts, c := newTestOptions()
+	root := NewLimiter("disk", Limit{Rate: 10}, opts)
+	defer root.Close()
+
+	flush := root.Child("flush", Limit{}).(*limiter)
+	repair := root.Child("repair", Limit{Rate: 2, Burst: 4}).(*limiter)
+	require.Equal(t, "disk.flush", flush.Name())
+	require.Equal(t, "disk.repair", repair.Name())
+	require.Equal(t, flush, root.Child("flush", Limit{Rate: 1}))
+
+	// The child without a limit of its own is only limited by its parent.
+	require.Equal(t, time.Duration(0), flush.reserve(8))
+
+	// The limited child waits for the longest of its own and its parent's
+	// debts.
+	require.Equal(t, time.Duration(0), repair.reserve(2))
+	require.Equal(t, time.Second, repair.reserve(4))
+
+	c.now = c.now.Add(time.Second)
+	require.Equal(t, 400*time.Millisecond, flush.reserve(10))
+
+	states := root.States()
+	require.Len(t, states, 3)
+	require.Equal(t, "disk", states[0].Name)
+	require.Equal(t, int64(24), states[0].Consumed)
+	require.Equal(t, "disk.flush", states[1].Name)
+	require.Equal(t, int64(18), states[1].Consumed)
+	require.Equal(t, float64(0), states[1].Rate)
+	require.Equal(t, "disk.repair", states[2].Name)
+	require.Equal(t, int64(6), states[2].Consumed)
+	require.Equal(t, int64(4), states[2].Burst)
+}
+
+func TestLimiterSetLimit(t *testing.T) {
+	opts, c := newTestOptions()
+	root := NewLimiter("disk", Limit{}, opts).(*limiter)
+	defer root.Close()
+
+	require.True(t, root.Limit().Unlimited())
+	require.Equal(t, time.Duration(0), root.reserve(1000))
+
+	// Becoming limited starts with a full bucket.
+	root.SetLimit(Limit{Rate: 100, Burst: 50})
+	require.Equal(t, Limit{Rate: 100, Burst: 50}, root.Limit())
+	require.Equal(t, time.Duration(0), root.reserve(50))
+	require.Equal(t, 500*time.Millisecond, root.reserve(50))
+
+	// Lowering the rate slows down paying off the debt.
+	root.SetLimit(Limit{Rate: 10, Burst: 50})
+	c.now = c.now.Add(time.Second)
+	require.Equal(t, 9*time.Second, root.reserve(50))
+}
+
+func TestLimiterWaitN(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	root := NewLimiter("disk", Limit{Rate: 1000, Burst: 10}, NewOptions())
+	defer root.Close()
+
+	start := time.Now()
+	require.NoError(t, root.WaitN(context.Background(), 10))
+	require.NoError(t, root.WaitN(context.Background(), 50))
+	require.True(t, time.Since(start) >= 40*time.Millisecond)
+	require.NoError(t, root.WaitN(context.Background(), 0))
+}
+
+func TestLimiterWaitNContextDone(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	root := NewLimiter("disk", Limit{Rate: 1, Burst: 1}, NewOptions())
+	defer root.Close()
+
+	require.NoError(t, root.WaitN(context.Background(), 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, root.WaitN(ctx, 100))
+
+	// The tokens reserved by the canceled wait are given back.
+	states := root.States()
+	require.Len(t, states, 1)
+	require.Equal(t, int64(1), states[0].Consumed)
+	require.True(t, states[0].Tokens > -1)
+}
+
+func TestLimiterKVOverride(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	store := mem.NewStore()
+	_, err := store.Set("test.disk", &commonpb.Float64Proto{Value: 100})
+	require.NoError(t, err)
+
+	root := NewLimiter("disk", Limit{Rate: 10}, NewOptions().SetKeyPrefix("test."))
+	defer root.Close()
+
+	// Limiters created before watching kv start using the rates in kv once
+	// watching.
+	peers := root.Child("peers", Limit{Rate: 1})
+	_, err = store.Set("test.disk.peers", &commonpb.Float64Proto{Value: 2})
+	require.NoError(t, err)
+	require.Equal(t, float64(10), root.Limit().Rate)
+	require.Equal(t, DefaultSource, root.States()[0].Source)
+
+	require.Equal(t, errNoKVStore, root.Watch(nil))
+	require.NoError(t, peers.Watch(store))
+	require.Equal(t, errAlreadyWatching, root.Watch(store))
+	require.Equal(t, float64(100), root.Limit().Rate)
+	require.Equal(t, float64(2), peers.Limit().Rate)
+	require.Equal(t, KVSource, root.States()[0].Source)
+	require.Equal(t, "test.disk", root.States()[0].Key)
+
+	flush := root.Child("flush", Limit{Rate: 5})
+	require.Equal(t, float64(5), flush.Limit().Rate)
+
+	_, err = store.Set("test.disk.flush", &commonpb.Float64Proto{Value: 50})
+	require.NoError(t, err)
+	waitFor(t, func() bool { return flush.Limit().Rate == 50 })
+
+	// The default limit does not take precedence over kv.
+	flush.SetLimit(Limit{Rate: 20})
+	require.Equal(t, float64(50), flush.Limit().Rate)
+
+	// Invalid updates are not applied.
+	_, err = store.Set("test.disk.flush", &commonpb.Float64Proto{Value: -1})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, float64(50), flush.Limit().Rate)
+
+	// Zero in kv disables the limit.
+	_, err = store.Set("test.disk", &commonpb.Float64Proto{Value: 0})
+	require.NoError(t, err)
+	waitFor(t, func() bool { return root.Limit().Unlimited() })
+
+	// Deleting the key reverts to the default limit.
+	_, err = store.Delete("test.disk.flush")
+	require.NoError(t, err)
+	waitFor(t, func() bool { return flush.Limit().Rate == 20 })
+}
+
+func TestLimiterHandler(t *testing.T) {
+	opts, _ := newTestOptions()
+	root := Configuration{
+		Rate: 100,
+		Children: map[string]Configuration{
+			"flush": {Rate: 50, Burst: 10},
+			"peers": {
+				Children: map[string]Configuration{
+					"bootstrap": {Rate: 20},
+				},
+			},
+		},
+	}.NewLimiter("disk", opts)
+	defer root.Close()
+
+	w := httptest.NewRecorder()
+	root.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var states []State
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	require.Equal(t, []State{
+		{Name: "disk", Rate: 100, Burst: 100, Default: 100, Source: DefaultSource, Tokens: 100},
+		{Name: "disk.flush", Rate: 50, Burst: 10, Default: 50, Source: DefaultSource, Tokens: 10},
+		{Name: "disk.peers", Source: DefaultSource},
+		{Name: "disk.peers.bootstrap", Rate: 20, Burst: 20, Default: 20, Source: DefaultSource, Tokens: 20},
+	}, states)
+}
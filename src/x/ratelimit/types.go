@@ -0,0 +1,114 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package ratelimit provides hierarchical token bucket rate limiters.
+//
+// Limiters form a tree where every limiter draws the tokens it hands out from
+// its own bucket and from the buckets of all of its ancestors, so that the
+// children of a limiter share its budget while each child can also be capped
+// on its own. For instance a limiter of disk bytes per second can be split
+// among flushes, repairs and peer streaming. The rate of every limiter can be
+// overridden at runtime by setting a value in kv.
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m3db/m3/src/cluster/kv"
+)
+
+// Limit is the limit of a limiter.
+type Limit struct {
+	// Rate is the number of tokens per second, zero or less is unlimited.
+	Rate float64
+	// Burst is the number of tokens that accumulate while the limiter is
+	// unused, if zero or less then one second worth of tokens.
+	Burst int64
+}
+
+// Unlimited returns whether the limit does not limit.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// Source is where the effective rate of a limiter comes from.
+type Source string
+
+const (
+	// DefaultSource means the limiter has no rate in kv and uses its default.
+	DefaultSource Source = "default"
+	// KVSource means the limiter uses the rate set in kv.
+	KVSource Source = "kv"
+)
+
+// Limiter is a token bucket rate limiter that also draws its tokens from the
+// buckets of its ancestors.
+type Limiter interface {
+	// Name returns the name of the limiter, that is the names of its
+	// ancestors and its own separated by dots.
+	Name() string
+
+	// Limit returns the effective limit.
+	Limit() Limit
+
+	// SetLimit sets the default limit, the rate is overridden by the rate set
+	// in kv if any.
+	SetLimit(value Limit)
+
+	// WaitN blocks until n tokens are available from the limiter and all of
+	// its ancestors or the context is done. Tokens are reserved up front so
+	// requests of more tokens than the burst of a limiter are allowed and
+	// delay the requests that follow them.
+	WaitN(ctx context.Context, n int64) error
+
+	// Child returns the child limiter with the given name, creating it with
+	// the given default limit if it does not exist yet.
+	Child(name string, defaultLimit Limit) Limiter
+
+	// States returns the state of the limiter and all of its descendants
+	// sorted by name.
+	States() []State
+
+	// Handler returns an HTTP handler listing the states of the limiter and
+	// all of its descendants as JSON.
+	Handler() http.Handler
+
+	// Watch starts applying the rates set in kv to the limiter and all other
+	// limiters of its tree, including the ones created later. Limiters keep
+	// their default rates until then.
+	Watch(store kv.Store) error
+
+	// Close stops watching kv for rate updates of the limiter and all other
+	// limiters of its tree.
+	Close()
+}
+
+// State is the state of a limiter.
+type State struct {
+	Name     string  `json:"name"`
+	Key      string  `json:"key,omitempty"`
+	Rate     float64 `json:"rate"`
+	Burst    int64   `json:"burst"`
+	Default  float64 `json:"default"`
+	Source   Source  `json:"source"`
+	Tokens   float64 `json:"tokens"`
+	Consumed int64   `json:"consumed"`
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package retry
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBudgetRatio               = 0.1
+	defaultBudgetMinRetriesPerSecond = 10
+
+	// budgetWindow is how long retries are accumulated for while there are no
+	// retries, it bounds the burst of retries after a quiet period.
+	budgetWindow = 10 * time.Second
+)
+
+type budget struct {
+	sync.Mutex
+
+	ratio               float64
+	minRetriesPerSecond float64
+	maxBalance          float64
+	balance             float64
+	lastRefill          time.Time
+	nowFn               func() time.Time
+}
+
+// NewBudget returns a retry budget that allows retries for the given ratio of
+// requests, e.g. 0.1 allows one retry for every ten requests, and at least the
+// given number of retries per second regardless of the number of requests.
+// Retriers sharing a budget share the retries, which keeps a failing
+// dependency from being overwhelmed by the retries of all its callers.
+func NewBudget(ratio float64, minRetriesPerSecond float64) Budget {
+	return newBudget(ratio, minRetriesPerSecond, time.Now)
+}
+
+func newBudget(ratio float64, minRetriesPerSecond float64, nowFn func() time.Time) *budget {
+	maxBalance := math.Max(1, minRetriesPerSecond*budgetWindow.Seconds())
+	return &budget{
+		ratio:               ratio,
+		minRetriesPerSecond: minRetriesPerSecond,
+		maxBalance:          maxBalance,
+		balance:             maxBalance,
+		lastRefill:          nowFn(),
+		nowFn:               nowFn,
+	}
+}
+
+func (b *budget) OnRequest() {
+	b.Lock()
+	b.deposit(b.ratio)
+	b.Unlock()
+}
+
+func (b *budget) TryRetry() bool {
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.nowFn()
+	if elapsed := now.Sub(b.lastRefill); elapsed > 0 {
+		b.deposit(b.minRetriesPerSecond * elapsed.Seconds())
+	}
+	b.lastRefill = now
+
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
+
+func (b *budget) deposit(value float64) {
+	b.balance = math.Min(b.maxBalance, b.balance+value)
+}
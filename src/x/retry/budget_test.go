@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newBudget(0.5, 0.1, func() time.Time { return now })
+
+	// The budget starts with the retries of a window without requests.
+	require.True(t, b.TryRetry())
+	require.False(t, b.TryRetry())
+
+	// Every other request earns a retry.
+	b.OnRequest()
+	require.False(t, b.TryRetry())
+	b.OnRequest()
+	require.True(t, b.TryRetry())
+	require.False(t, b.TryRetry())
+
+	// Retries are earned over time without requests.
+	now = now.Add(10 * time.Second)
+	require.True(t, b.TryRetry())
+	require.False(t, b.TryRetry())
+}
+
+func TestBudgetMaxBalance(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newBudget(1, 1, func() time.Time { return now })
+
+	for i := 0; i < 100; i++ {
+		b.OnRequest()
+	}
+	now = now.Add(time.Hour)
+
+	retries := 0
+	for b.TryRetry() {
+		retries++
+	}
+	require.Equal(t, 10, retries)
+}
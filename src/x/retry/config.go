@@ -46,6 +46,14 @@ type Configuration struct {
 
 	// Whether jittering is applied during retries.
 	Jitter *bool `yaml:"jitter"`
+
+	// Budget limits retries to a share of requests, the budget is shared by
+	// all the retriers created from the same options.
+	Budget *BudgetConfiguration `yaml:"budget"`
+
+	// Whether context retries are skipped when the deadline of the context is
+	// expected to expire before they complete.
+	DeadlineAware *bool `yaml:"deadlineAware"`
 }
 
 // NewOptions creates a new retry options based on the configuration.
@@ -69,6 +77,12 @@ func (c Configuration) NewOptions(scope tally.Scope) Options {
 	if c.Jitter != nil {
 		opts = opts.SetJitter(*c.Jitter)
 	}
+	if c.Budget != nil {
+		opts = opts.SetBudget(c.Budget.NewBudget())
+	}
+	if c.DeadlineAware != nil {
+		opts = opts.SetDeadlineAware(*c.DeadlineAware)
+	}
 
 	return opts
 }
@@ -77,3 +91,27 @@ func (c Configuration) NewOptions(scope tally.Scope) Options {
 func (c Configuration) NewRetrier(scope tally.Scope) Retrier {
 	return NewRetrier(c.NewOptions(scope))
 }
+
+// BudgetConfiguration configures a retry budget.
+type BudgetConfiguration struct {
+	// Ratio of retries to requests allowed, e.g. 0.1 allows one retry for
+	// every ten requests.
+	Ratio float64 `yaml:"ratio" validate:"min=0"`
+
+	// Minimum number of retries allowed per second regardless of the number
+	// of requests, so that retries are allowed when requests are rare.
+	MinRetriesPerSecond float64 `yaml:"minRetriesPerSecond" validate:"min=0"`
+}
+
+// NewBudget creates a new retry budget based on the configuration.
+func (c BudgetConfiguration) NewBudget() Budget {
+	ratio := c.Ratio
+	if ratio == 0 {
+		ratio = defaultBudgetRatio
+	}
+	minRetriesPerSecond := c.MinRetriesPerSecond
+	if minRetriesPerSecond == 0 {
+		minRetriesPerSecond = defaultBudgetMinRetriesPerSecond
+	}
+	return NewBudget(ratio, minRetriesPerSecond)
+}
@@ -38,6 +38,7 @@ func TestRetryConfig(t *testing.T) {
 		MaxRetries:     3,
 		Forever:        &b1,
 		Jitter:         &b2,
+		DeadlineAware:  &b1,
 	}
 	retrier := cfg.NewRetrier(tally.NoopScope).(*retrier)
 	require.Equal(t, time.Second, retrier.initialBackoff)
@@ -46,4 +47,19 @@ func TestRetryConfig(t *testing.T) {
 	require.Equal(t, 3, retrier.maxRetries)
 	require.Equal(t, b1, retrier.forever)
 	require.Equal(t, b2, retrier.jitter)
+	require.Equal(t, b1, retrier.deadlineAware)
+}
+
+func TestRetryBudgetConfig(t *testing.T) {
+	cfg := Configuration{Budget: &BudgetConfiguration{MinRetriesPerSecond: 2}}
+	opts := cfg.NewOptions(tally.NoopScope)
+
+	b, ok := opts.Budget().(*budget)
+	require.True(t, ok)
+	require.Equal(t, defaultBudgetRatio, b.ratio)
+	require.Equal(t, 2.0, b.minRetriesPerSecond)
+	require.Equal(t, 20.0, b.maxBalance)
+
+	// Retriers created from the same options share the budget.
+	require.True(t, b == NewRetrier(opts).(*retrier).budget)
 }
@@ -46,6 +46,8 @@ type options struct {
 	forever        bool
 	jitter         bool
 	rngFn          RngFn
+	budget         Budget
+	deadlineAware  bool
 }
 
 // NewOptions creates new retry options.
@@ -141,3 +143,23 @@ func (o *options) SetRngFn(value RngFn) Options {
 func (o *options) RngFn() RngFn {
 	return o.rngFn
 }
+
+func (o *options) SetBudget(value Budget) Options {
+	opts := *o
+	opts.budget = value
+	return &opts
+}
+
+func (o *options) Budget() Budget {
+	return o.budget
+}
+
+func (o *options) SetDeadlineAware(value bool) Options {
+	opts := *o
+	opts.deadlineAware = value
+	return &opts
+}
+
+func (o *options) DeadlineAware() bool {
+	return o.deadlineAware
+}
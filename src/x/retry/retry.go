@@ -30,8 +30,13 @@ import (
 	xerrors "github.com/m3db/m3/src/x/errors"
 
 	"github.com/uber-go/tally"
+	"go.uber.org/atomic"
 )
 
+// expectedLatencyWeight is the weight of the latency of an attempt in the
+// moving average of the latency expected of the next attempt.
+const expectedLatencyWeight = 0.2
+
 var (
 	// ErrWhileConditionFalse is returned when the while condition to a while retry
 	// method evaluates false.
@@ -47,8 +52,16 @@ type retrier struct {
 	forever        bool
 	jitter         bool
 	rngFn          RngFn
+	budget         Budget
+	deadlineAware  bool
 	sleepFn        func(t time.Duration)
+	nowFn          func() time.Time
 	metrics        retrierMetrics
+
+	// expectedLatency is the moving average of the latency of attempts, in
+	// nanoseconds, used to skip retries that would not complete before the
+	// deadline of the context.
+	expectedLatency *atomic.Int64
 }
 
 type retrierMetrics struct {
@@ -61,6 +74,8 @@ type retrierMetrics struct {
 	errorsFinal        tally.Counter
 	errorsLatency      tally.Histogram
 	retries            tally.Counter
+	retriesNoBudget    tally.Counter
+	retriesNoDeadline  tally.Counter
 }
 
 // NewRetrier creates a new retrier.
@@ -87,7 +102,10 @@ func NewRetrier(opts Options) Retrier {
 		forever:        opts.Forever(),
 		jitter:         opts.Jitter(),
 		rngFn:          opts.RngFn(),
+		budget:         opts.Budget(),
+		deadlineAware:  opts.DeadlineAware(),
 		sleepFn:        time.Sleep,
+		nowFn:          time.Now,
 		metrics: retrierMetrics{
 			calls:              scope.Counter("calls"),
 			attempts:           scope.Counter("attempts"),
@@ -98,7 +116,10 @@ func NewRetrier(opts Options) Retrier {
 			errorsFinal:        scope.Counter("errors-final"),
 			errorsLatency:      histogramWithDurationBuckets(scope, "errors-latency"),
 			retries:            scope.Counter("retries"),
+			retriesNoBudget:    scope.Tagged(map[string]string{"reason": "budget"}).Counter("retries-skipped"),
+			retriesNoDeadline:  scope.Tagged(map[string]string{"reason": "deadline"}).Counter("retries-skipped"),
 		},
+		expectedLatency: atomic.NewInt64(0),
 	}
 }
 
@@ -107,11 +128,11 @@ func (r *retrier) Options() Options {
 }
 
 func (r *retrier) Attempt(fn Fn) error {
-	return r.attempt(nil, fn)
+	return r.attempt(nil, time.Time{}, fn)
 }
 
 func (r *retrier) AttemptWhile(continueFn ContinueFn, fn Fn) error {
-	return r.attempt(continueFn, fn)
+	return r.attempt(continueFn, time.Time{}, fn)
 }
 
 func (r *retrier) AttemptContext(ctx context.Context, fn Fn) error {
@@ -123,7 +144,11 @@ func (r *retrier) AttemptContext(ctx context.Context, fn Fn) error {
 			return true
 		}
 	}
-	err := r.attempt(contextNotCancelled, fn)
+	var deadline time.Time
+	if r.deadlineAware {
+		deadline, _ = ctx.Deadline()
+	}
+	err := r.attempt(contextNotCancelled, deadline, fn)
 	if err != nil {
 		if errors.Is(err, ErrWhileConditionFalse) {
 			return fmt.Errorf("context canceled while retrying: %w", ctx.Err())
@@ -133,9 +158,12 @@ func (r *retrier) AttemptContext(ctx context.Context, fn Fn) error {
 	return nil
 }
 
-func (r *retrier) attempt(continueFn ContinueFn, fn Fn) error {
+func (r *retrier) attempt(continueFn ContinueFn, deadline time.Time, fn Fn) error {
 	// Always track a call, useful for counting number of total operations.
 	r.metrics.calls.Inc(1)
+	if r.budget != nil {
+		r.budget.OnRequest()
+	}
 
 	attempt := 0
 
@@ -146,6 +174,7 @@ func (r *retrier) attempt(continueFn ContinueFn, fn Fn) error {
 	start := time.Now()
 	err := fn()
 	duration := time.Since(start)
+	r.recordLatency(duration)
 	r.metrics.attempts.Inc(1)
 	attempt++
 	if err == nil {
@@ -161,14 +190,27 @@ func (r *retrier) attempt(continueFn ContinueFn, fn Fn) error {
 	r.metrics.errors.Inc(1)
 
 	for i := 1; r.forever || i <= r.maxRetries; i++ {
-		r.sleepFn(time.Duration(BackoffNanos(
+		backoff := time.Duration(BackoffNanos(
 			i,
 			r.jitter,
 			r.backoffFactor,
 			r.initialBackoff,
 			r.maxBackoff,
 			r.rngFn,
-		)))
+		))
+		if !deadline.IsZero() &&
+			deadline.Sub(r.nowFn()) < backoff+time.Duration(r.expectedLatency.Load()) {
+			// NB: the retry would not complete before the deadline, return
+			// the error of the last attempt rather than waiting for the
+			// deadline to expire.
+			r.metrics.retriesNoDeadline.Inc(1)
+			break
+		}
+		if r.budget != nil && !r.budget.TryRetry() {
+			r.metrics.retriesNoBudget.Inc(1)
+			break
+		}
+		r.sleepFn(backoff)
 
 		if continueFn != nil && !continueFn(attempt) {
 			return ErrWhileConditionFalse
@@ -178,6 +220,7 @@ func (r *retrier) attempt(continueFn ContinueFn, fn Fn) error {
 		start := time.Now()
 		err = fn()
 		duration := time.Since(start)
+		r.recordLatency(duration)
 		r.metrics.attempts.Inc(1)
 		attempt++
 		if err == nil {
@@ -197,6 +240,18 @@ func (r *retrier) attempt(continueFn ContinueFn, fn Fn) error {
 	return err
 }
 
+// recordLatency updates the latency expected of attempts with the latency of
+// an attempt.
+func (r *retrier) recordLatency(latency time.Duration) {
+	expected := r.expectedLatency.Load()
+	if expected == 0 {
+		r.expectedLatency.Store(int64(latency))
+		return
+	}
+	r.expectedLatency.Store(int64(
+		(1-expectedLatencyWeight)*float64(expected) + expectedLatencyWeight*float64(latency)))
+}
+
 // BackoffNanos calculates the backoff for a retry in nanoseconds.
 func BackoffNanos(
 	retry int,
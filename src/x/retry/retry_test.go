@@ -21,6 +21,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -200,6 +201,68 @@ func TestRetryForever(t *testing.T) {
 	assert.Equal(t, time.Duration(1023*time.Second), totalSlept)
 }
 
+func TestRetrierBudget(t *testing.T) {
+	slept := time.Duration(0)
+	b := newBudget(0, 0, time.Now)
+	r := NewRetrier(testOptions().SetBudget(b)).(*retrier)
+	r.sleepFn = func(t time.Duration) {
+		slept += t
+	}
+
+	// The budget only has a single retry.
+	err := r.Attempt(newTestFn(testFnOpts{}))
+	assert.Equal(t, errTestFn, err)
+	assert.Equal(t, time.Second, slept)
+
+	err = r.Attempt(newTestFn(testFnOpts{}))
+	assert.Equal(t, errTestFn, err)
+	assert.Equal(t, time.Second, slept)
+}
+
+func TestRetrierContextDeadline(t *testing.T) {
+	now := time.Now()
+	slept := time.Duration(0)
+	r := NewRetrier(testOptions().SetDeadlineAware(true)).(*retrier)
+	r.sleepFn = func(t time.Duration) {
+		slept += t
+	}
+	r.nowFn = func() time.Time {
+		return now
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(1500*time.Millisecond))
+	defer cancel()
+
+	// Only the first retry, after a second, completes before the deadline.
+	err := r.AttemptContext(ctx, newTestFn(testFnOpts{}))
+	assert.Equal(t, errTestFn, err)
+	assert.Equal(t, time.Second, slept)
+
+	// Retries are skipped once attempts are expected to outlast the deadline.
+	r.expectedLatency.Store(int64(time.Second))
+	slept = 0
+	err = r.AttemptContext(ctx, newTestFn(testFnOpts{}))
+	assert.Equal(t, errTestFn, err)
+	assert.Equal(t, time.Duration(0), slept)
+}
+
+func TestRetrierContextDeadlineNotAware(t *testing.T) {
+	slept := time.Duration(0)
+	r := NewRetrier(testOptions()).(*retrier)
+	r.sleepFn = func(t time.Duration) {
+		slept += t
+	}
+	r.expectedLatency.Store(int64(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// Retries are attempted regardless of the deadline by default.
+	err := r.AttemptContext(ctx, newTestFn(testFnOpts{}))
+	assert.Equal(t, errTestFn, err)
+	assert.Equal(t, 3*time.Second, slept)
+}
+
 func TestBackoffValidResult(t *testing.T) {
 	seed := time.Now().UnixNano()
 	parameters := gopter.DefaultTestParameters()
@@ -49,6 +49,17 @@ type Fn func() error
 // ContinueFn is a function that returns whether to continue attempting an operation.
 type ContinueFn func(attempt int) bool
 
+// Budget limits the retries of the retriers sharing it to a share of their
+// requests.
+type Budget interface {
+	// OnRequest records a request, which earns a share of a retry.
+	OnRequest()
+
+	// TryRetry returns whether a retry is within the budget and takes it from
+	// the budget if so.
+	TryRetry() bool
+}
+
 // Retrier is a executor that can retry attempts on executing methods.
 type Retrier interface {
 	// Options returns the options used to construct the retrier, useful
@@ -62,6 +73,9 @@ type Retrier interface {
 	AttemptWhile(continueFn ContinueFn, fn Fn) error
 
 	// AttemptContext attempts fn with retries until ctx is canceled (e.g., due to timeout).
+	// If the retrier is deadline aware, retries are not attempted if the deadline
+	// of ctx is expected to expire before they complete, based on the latency of
+	// the previous attempts.
 	AttemptContext(ctx context.Context, fn Fn) error
 }
 
@@ -118,4 +132,20 @@ type Options interface {
 
 	// RngFn returns the RngFn.
 	RngFn() RngFn
+
+	// SetBudget sets the retry budget, retries are not attempted once the
+	// budget is exhausted. A budget may be shared by the retriers of
+	// different options, nil disables the budget.
+	SetBudget(value Budget) Options
+
+	// Budget returns the retry budget.
+	Budget() Budget
+
+	// SetDeadlineAware sets whether context retries are skipped when the
+	// deadline of the context is expected to expire before they complete.
+	SetDeadlineAware(value bool) Options
+
+	// DeadlineAware returns whether context retries are skipped when the
+	// deadline of the context is expected to expire before they complete.
+	DeadlineAware() bool
 }
@@ -0,0 +1,187 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	vaultAddressEnvVar   = "VAULT_ADDR"
+	vaultTokenEnvVar     = "VAULT_TOKEN"
+	vaultTokenFileEnvVar = "VAULT_TOKEN_FILE"
+	vaultTokenHeader     = "X-Vault-Token"
+	vaultFieldSeparator  = "#"
+
+	defaultVaultTimeout = 10 * time.Second
+)
+
+var errVaultNoToken = errors.New("no vault token: set VAULT_TOKEN or VAULT_TOKEN_FILE")
+
+// LookupEnvFn looks up an environment variable.
+type LookupEnvFn func(key string) (string, bool)
+
+type envProvider struct {
+	lookupEnv LookupEnvFn
+}
+
+// NewEnvProvider returns a provider resolving secrets from the environment
+// variable named by the path.
+func NewEnvProvider(lookupEnv LookupEnvFn) Provider {
+	return envProvider{lookupEnv: lookupEnv}
+}
+
+func (p envProvider) Resolve(_ context.Context, path string) ([]byte, error) {
+	value, ok := p.lookupEnv(path)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", path)
+	}
+	return []byte(value), nil
+}
+
+type fileProvider struct{}
+
+// NewFileProvider returns a provider resolving secrets from the contents of
+// the file at the path, paths are absolute.
+func NewFileProvider() Provider {
+	return fileProvider{}
+}
+
+func (fileProvider) Resolve(_ context.Context, path string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join("/", path))
+}
+
+// VaultProvider resolves secrets from fields of Vault secrets, the path is the
+// API path of the secret followed by the field, e.g. secret/data/m3/etcd#password.
+// Both version 1 and version 2 KV secrets engines are supported.
+type VaultProvider struct {
+	address   string
+	token     string
+	tokenFile string
+	client    *http.Client
+}
+
+// NewVaultProvider returns a provider resolving secrets from the Vault
+// server at the address, authenticating with the token.
+func NewVaultProvider(address, token string, client *http.Client) *VaultProvider {
+	return &VaultProvider{
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+// NewVaultProviderFromEnv returns a Vault provider configured by the standard
+// VAULT_ADDR and VAULT_TOKEN environment variables, or VAULT_TOKEN_FILE for a
+// token that is rotated, and false if VAULT_ADDR is not set.
+func NewVaultProviderFromEnv(lookupEnv LookupEnvFn) (*VaultProvider, bool) {
+	address, ok := lookupEnv(vaultAddressEnvVar)
+	if !ok || address == "" {
+		return nil, false
+	}
+
+	token, _ := lookupEnv(vaultTokenEnvVar)
+	p := NewVaultProvider(address, token, &http.Client{Timeout: defaultVaultTimeout})
+	if tokenFile, ok := lookupEnv(vaultTokenFileEnvVar); ok {
+		// NB: the token file is read on each request so that it can be rotated.
+		p.tokenFile = tokenFile
+	}
+	return p, true
+}
+
+// Resolve returns the field of the Vault secret at the path.
+func (p *VaultProvider) Resolve(ctx context.Context, path string) ([]byte, error) {
+	secretPath, field, ok := strings.Cut(path, vaultFieldSeparator)
+	if !ok || field == "" {
+		return nil, fmt.Errorf("vault secret path has no field: %s", path)
+	}
+
+	token, err := p.resolveToken()
+	if err != nil {
+		return nil, err
+	}
+
+	url := p.address + "/v1/" + strings.TrimLeft(secretPath, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(vaultTokenHeader, token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d for secret %s", resp.StatusCode, secretPath)
+	}
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("could not decode vault secret %s: %w", secretPath, err)
+	}
+
+	data := secret.Data
+	if _, ok := data["metadata"]; ok {
+		// KV version 2 secrets nest the secret data with its metadata.
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(data["data"], &nested); err != nil {
+			return nil, fmt.Errorf("could not decode vault secret %s: %w", secretPath, err)
+		}
+		data = nested
+	}
+
+	raw, ok := data[field]
+	if !ok {
+		return nil, fmt.Errorf("vault secret %s has no field %s", secretPath, field)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("vault secret %s field %s is not a string: %w", secretPath, field, err)
+	}
+	return []byte(value), nil
+}
+
+func (p *VaultProvider) resolveToken() (string, error) {
+	if p.tokenFile != "" {
+		b, err := ioutil.ReadFile(p.tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("could not read vault token: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	if p.token == "" {
+		return "", errVaultNoToken
+	}
+	return p.token, nil
+}
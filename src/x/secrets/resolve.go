@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// TagName is the struct tag that controls how the secret references of a
+	// config field are resolved.
+	TagName = "secret"
+	// TagRefresh marks a config field whose secret references are resolved
+	// and refreshed by the consumer of the config with a Value, rather than
+	// being replaced when the config is loaded.
+	TagRefresh = "refresh"
+)
+
+// ResolveReferences replaces the secret references of the string fields,
+// slice elements and map values reachable from dst, which must be a pointer,
+// with the secrets they reference. Fields tagged secret:"refresh" are skipped
+// since their consumer re-resolves them.
+func ResolveReferences(ctx context.Context, resolver *Resolver, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("secrets can only be resolved through a non-nil pointer: %T", dst)
+	}
+	return resolveReferences(ctx, resolver, v.Elem(), "")
+}
+
+func resolveReferences(
+	ctx context.Context,
+	resolver *Resolver,
+	v reflect.Value,
+	path string,
+) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			// NB: the value of an interface is not addressable, only secrets
+			// reachable through pointers are resolved.
+			if elem := v.Elem(); elem.Kind() == reflect.Ptr {
+				return resolveReferences(ctx, resolver, elem, path)
+			}
+			return nil
+		}
+		return resolveReferences(ctx, resolver, v.Elem(), path)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" || field.Tag.Get(TagName) == TagRefresh {
+				continue
+			}
+			if err := resolveReferences(ctx, resolver, v.Field(i), path+"."+field.Name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveReferences(ctx, resolver, v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			value := iter.Value()
+			if value.Kind() != reflect.String || !IsReference(value.String()) {
+				// NB: map values are not addressable so only secret strings are
+				// resolved, other values are resolved through their pointers.
+				if err := resolveReferences(ctx, resolver, value, path); err != nil {
+					return err
+				}
+				continue
+			}
+			secret, err := resolver.ResolveString(ctx, value.String())
+			if err != nil {
+				return fmt.Errorf("%s[%v]: %w", strings.TrimPrefix(path, "."), iter.Key(), err)
+			}
+			resolved := reflect.New(value.Type()).Elem()
+			resolved.SetString(secret)
+			v.SetMapIndex(iter.Key(), resolved)
+		}
+	case reflect.String:
+		if !v.CanSet() || !IsReference(v.String()) {
+			return nil
+		}
+		secret, err := resolver.ResolveString(ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.TrimPrefix(path, "."), err)
+		}
+		v.SetString(secret)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package secrets resolves secret references in configuration, such as etcd
+// credentials and TLS keys, from the environment, files or Vault so that they
+// do not need to be stored in plaintext.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// ReferencePrefix is the prefix of secret references, a reference has the
+	// form secret://<provider>/<path> where the path is interpreted by the
+	// provider, e.g. secret://env/ETCD_PASSWORD.
+	ReferencePrefix = "secret://"
+
+	// EnvProviderName is the name of the provider resolving secrets from
+	// environment variables, e.g. secret://env/ETCD_PASSWORD.
+	EnvProviderName = "env"
+	// FileProviderName is the name of the provider resolving secrets from
+	// absolute file paths, e.g. secret://file/etc/m3/etcd-password.
+	FileProviderName = "file"
+	// VaultProviderName is the name of the provider resolving secrets from a
+	// Vault secret field, e.g. secret://vault/secret/data/m3/etcd#password.
+	VaultProviderName = "vault"
+)
+
+var (
+	errInvalidReference = errors.New("invalid secret reference")
+
+	defaultResolver     *Resolver
+	defaultResolverOnce sync.Once
+)
+
+// Provider resolves secrets by path.
+type Provider interface {
+	// Resolve returns the secret at the path.
+	Resolve(ctx context.Context, path string) ([]byte, error)
+}
+
+// IsReference returns whether the value is a secret reference.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, ReferencePrefix)
+}
+
+// ParseReference returns the provider name and path of a secret reference.
+func ParseReference(ref string) (string, string, error) {
+	if !IsReference(ref) {
+		return "", "", fmt.Errorf("%w: %s", errInvalidReference, ref)
+	}
+	provider, path, ok := strings.Cut(strings.TrimPrefix(ref, ReferencePrefix), "/")
+	if !ok || provider == "" || path == "" {
+		return "", "", fmt.Errorf("%w: %s", errInvalidReference, ref)
+	}
+	return provider, path, nil
+}
+
+// Resolver resolves secret references with the provider they name.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver returns a resolver for the named providers.
+func NewResolver(providers map[string]Provider) *Resolver {
+	return &Resolver{providers: providers}
+}
+
+// DefaultResolver returns the resolver for the env and file providers, and
+// the Vault provider if VAULT_ADDR is set in the environment.
+func DefaultResolver() *Resolver {
+	defaultResolverOnce.Do(func() {
+		providers := map[string]Provider{
+			EnvProviderName:  NewEnvProvider(os.LookupEnv),
+			FileProviderName: NewFileProvider(),
+		}
+		if vault, ok := NewVaultProviderFromEnv(os.LookupEnv); ok {
+			providers[VaultProviderName] = vault
+		}
+		defaultResolver = NewResolver(providers)
+	})
+	return defaultResolver
+}
+
+// Resolve returns the secret a value references, values that are not secret
+// references are returned as is.
+func (r *Resolver) Resolve(ctx context.Context, value string) ([]byte, error) {
+	if !IsReference(value) {
+		return []byte(value), nil
+	}
+
+	name, path, err := ParseReference(value)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("no secrets provider %s for reference: %s", name, value)
+	}
+
+	secret, err := provider.Resolve(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve secret %s: %w", value, err)
+	}
+	return secret, nil
+}
+
+// ResolveString returns the secret a value references as a string with any
+// trailing newline trimmed, values that are not secret references are
+// returned as is.
+func (r *Resolver) ResolveString(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	secret, err := r.Resolve(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(secret), "\r\n"), nil
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package secrets
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLookupEnv(env map[string]string) LookupEnvFn {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func testResolver(env map[string]string) *Resolver {
+	return NewResolver(map[string]Provider{
+		EnvProviderName:  NewEnvProvider(testLookupEnv(env)),
+		FileProviderName: NewFileProvider(),
+	})
+}
+
+func TestParseReference(t *testing.T) {
+	provider, path, err := ParseReference("secret://vault/secret/data/m3#password")
+	require.NoError(t, err)
+	assert.Equal(t, "vault", provider)
+	assert.Equal(t, "secret/data/m3#password", path)
+
+	for _, ref := range []string{"password", "secret://", "secret://env", "secret://env/", "secret:///path"} {
+		_, _, err := ParseReference(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+func TestResolverResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(file, []byte("file-secret\n"), 0600))
+
+	ctx := context.Background()
+	r := testResolver(map[string]string{"PASSWORD": "env-secret"})
+
+	secret, err := r.ResolveString(ctx, "secret://env/PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "env-secret", secret)
+
+	secret, err = r.ResolveString(ctx, "secret://file"+file)
+	require.NoError(t, err)
+	assert.Equal(t, "file-secret", secret)
+
+	raw, err := r.Resolve(ctx, "secret://file"+file)
+	require.NoError(t, err)
+	assert.Equal(t, "file-secret\n", string(raw))
+
+	secret, err = r.ResolveString(ctx, "plaintext")
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", secret)
+
+	_, err = r.ResolveString(ctx, "secret://env/MISSING")
+	assert.Error(t, err)
+	_, err = r.ResolveString(ctx, "secret://unknown/foo")
+	assert.Error(t, err)
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(vaultTokenHeader) != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/m3":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"v2-secret"},"metadata":{"version":3}}}`))
+		case "/v1/kv/m3":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, ok := NewVaultProviderFromEnv(testLookupEnv(map[string]string{
+		"VAULT_ADDR":  server.URL,
+		"VAULT_TOKEN": "token",
+	}))
+	require.True(t, ok)
+
+	ctx := context.Background()
+	secret, err := p.Resolve(ctx, "secret/data/m3#password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2-secret", string(secret))
+
+	secret, err = p.Resolve(ctx, "kv/m3#password")
+	require.NoError(t, err)
+	assert.Equal(t, "v1-secret", string(secret))
+
+	for _, path := range []string{"kv/m3", "kv/m3#username", "kv/missing#password"} {
+		_, err := p.Resolve(ctx, path)
+		assert.Error(t, err, path)
+	}
+
+	_, err = NewVaultProvider(server.URL, "bad", http.DefaultClient).Resolve(ctx, "kv/m3#password")
+	assert.Error(t, err)
+
+	_, ok = NewVaultProviderFromEnv(testLookupEnv(nil))
+	assert.False(t, ok)
+}
+
+func TestValueRefresh(t *testing.T) {
+	env := map[string]string{"PASSWORD": "first"}
+	v, err := NewValue(context.Background(), testResolver(env), "secret://env/PASSWORD",
+		time.Minute, instrument.NewOptions())
+	require.NoError(t, err)
+
+	now := time.Now()
+	v.nowFn = func() time.Time { return now }
+	v.resolvedAt = now
+
+	ctx := context.Background()
+	env["PASSWORD"] = "second"
+	assert.Equal(t, "first", string(v.Get(ctx)))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, "second", string(v.Get(ctx)))
+
+	// A secret that can no longer be resolved keeps the previous secret.
+	delete(env, "PASSWORD")
+	now = now.Add(time.Minute)
+	assert.Equal(t, "second", string(v.Get(ctx)))
+}
+
+func TestValuePlaintext(t *testing.T) {
+	v, err := NewValue(context.Background(), testResolver(nil), "plaintext",
+		time.Minute, instrument.NewOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(v.Get(context.Background())))
+
+	_, err = NewValue(context.Background(), testResolver(nil), "secret://env/MISSING",
+		time.Minute, instrument.NewOptions())
+	assert.Error(t, err)
+}
+
+func TestResolveReferences(t *testing.T) {
+	type tlsConfig struct {
+		Key     string `yaml:"key" secret:"refresh"`
+		KeyPath string `yaml:"keyPath"`
+	}
+	type config struct {
+		Password  string
+		Passwords []string
+		Headers   map[string]string
+		TLS       *tlsConfig
+		Clusters  []tlsConfig
+		unused    string
+	}
+
+	cfg := config{
+		Password:  "secret://env/PASSWORD",
+		Passwords: []string{"plaintext", "secret://env/PASSWORD"},
+		Headers:   map[string]string{"Authorization": "secret://env/PASSWORD"},
+		TLS: &tlsConfig{
+			Key:     "secret://env/KEY",
+			KeyPath: "secret://env/KEY",
+		},
+		Clusters: []tlsConfig{{KeyPath: "secret://env/KEY"}},
+		unused:   "secret://env/PASSWORD",
+	}
+	r := testResolver(map[string]string{"PASSWORD": "password", "KEY": "key"})
+	require.NoError(t, ResolveReferences(context.Background(), r, &cfg))
+
+	assert.Equal(t, config{
+		Password:  "password",
+		Passwords: []string{"plaintext", "password"},
+		Headers:   map[string]string{"Authorization": "password"},
+		TLS: &tlsConfig{
+			Key:     "secret://env/KEY",
+			KeyPath: "key",
+		},
+		Clusters: []tlsConfig{{KeyPath: "key"}},
+		unused:   "secret://env/PASSWORD",
+	}, cfg)
+
+	cfg.Password = "secret://env/MISSING"
+	err := ResolveReferences(context.Background(), r, &cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Password")
+
+	assert.Error(t, ResolveReferences(context.Background(), r, cfg))
+}
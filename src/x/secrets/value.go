@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/x/instrument"
+
+	"go.uber.org/zap"
+)
+
+// Value is a secret that is re-resolved periodically so that rotated secrets
+// are picked up without a restart. A value is re-resolved when it is read
+// after the refresh interval has elapsed, if re-resolving fails the previously
+// resolved secret continues to be used.
+type Value struct {
+	sync.Mutex
+
+	resolver        *Resolver
+	ref             string
+	refreshInterval time.Duration
+	nowFn           func() time.Time
+	logger          *zap.Logger
+
+	secret     []byte
+	resolvedAt time.Time
+}
+
+// NewValue resolves the secret a value references and returns it as a Value
+// that is re-resolved every refresh interval, a non-positive refresh interval
+// disables re-resolution. Values that are not secret references are never
+// re-resolved.
+func NewValue(
+	ctx context.Context,
+	resolver *Resolver,
+	value string,
+	refreshInterval time.Duration,
+	iOpts instrument.Options,
+) (*Value, error) {
+	secret, err := resolver.Resolve(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+	if !IsReference(value) {
+		refreshInterval = 0
+	}
+
+	return &Value{
+		resolver:        resolver,
+		ref:             value,
+		refreshInterval: refreshInterval,
+		nowFn:           time.Now,
+		logger:          iOpts.Logger(),
+		secret:          secret,
+		resolvedAt:      time.Now(),
+	}, nil
+}
+
+// Get returns the secret, re-resolving it if the refresh interval has elapsed
+// since it was last resolved.
+func (v *Value) Get(ctx context.Context) []byte {
+	v.Lock()
+	defer v.Unlock()
+
+	now := v.nowFn()
+	if v.refreshInterval <= 0 || now.Sub(v.resolvedAt) < v.refreshInterval {
+		return v.secret
+	}
+
+	// NB: the resolution time is updated on failure too so that an unavailable
+	// provider is not retried on every read.
+	v.resolvedAt = now
+	secret, err := v.resolver.Resolve(ctx, v.ref)
+	if err != nil {
+		v.logger.Error("could not re-resolve secret, using previous secret",
+			zap.String("ref", v.ref), zap.Error(err))
+		return v.secret
+	}
+	if !bytes.Equal(secret, v.secret) {
+		v.logger.Info("secret rotated", zap.String("ref", v.ref))
+		v.secret = secret
+	}
+	return v.secret
+}